@@ -0,0 +1,267 @@
+package controllers
+
+import (
+	"errors"
+	"strings"
+	"town-planning-backend/addresses/repositories"
+	"town-planning-backend/addresses/services"
+	"town-planning-backend/db/models"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AddressController struct {
+	SuburbRepo     repositories.SuburbRepository
+	AddressService *services.AddressService
+	DB             *gorm.DB
+}
+
+// StandardizeAddressRequest is an address to check before it is submitted
+type StandardizeAddressRequest struct {
+	Address       string `json:"address" validate:"required"`
+	City          string `json:"city"`
+	RequireSuburb *bool  `json:"require_suburb"` // Defaults to true unless the address is a post box
+}
+
+// SuburbRequest creates a suburb, or on update changes the fields sent
+type SuburbRequest struct {
+	Name       *string `json:"name"`
+	City       *string `json:"city"`
+	Ward       *string `json:"ward"`
+	PostalCode *string `json:"postal_code"`
+	IsActive   *bool   `json:"is_active"`
+}
+
+func suburbErrorStatus(err error) int {
+	switch err.Error() {
+	case "suburb not found":
+		return fiber.StatusNotFound
+	case "suburb already exists in this city":
+		return fiber.StatusConflict
+	case "suburb name and city are required":
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// SearchSuburbsController suggests suburbs for the frontend's address fields
+func (ac *AddressController) SearchSuburbsController(c *fiber.Ctx) error {
+	suburbs, err := ac.SuburbRepo.SearchSuburbs(c.Query("q"), c.Query("city"), c.QueryInt("limit", 10))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to search suburbs",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Suburbs retrieved",
+		"data":    suburbs,
+	})
+}
+
+// StandardizeAddressController shows how an address will be stored and whether it is
+// recognised, without saving anything
+func (ac *AddressController) StandardizeAddressController(c *fiber.Ctx) error {
+	var request StandardizeAddressRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+	if strings.TrimSpace(request.Address) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Address is required",
+		})
+	}
+
+	requireSuburb := !services.IsPostBox(request.Address)
+	if request.RequireSuburb != nil {
+		requireSuburb = *request.RequireSuburb
+	}
+
+	standardized, err := ac.AddressService.Standardize(request.Address, request.City, requireSuburb)
+	if err != nil {
+		if errors.Is(err, services.ErrAddressNotRecognised) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"success": false,
+				"message": "Address not recognised",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to standardize address",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Address standardized",
+		"data":    standardized,
+	})
+}
+
+// GetSuburbsController lists the suburb reference table, optionally for one city
+func (ac *AddressController) GetSuburbsController(c *fiber.Ctx) error {
+	suburbs, err := ac.SuburbRepo.GetSuburbs(c.Query("city"), c.QueryBool("include_inactive", false))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch suburbs",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Suburbs retrieved",
+		"data":    suburbs,
+	})
+}
+
+// CreateSuburbController adds a suburb to the reference table
+func (ac *AddressController) CreateSuburbController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	var request SuburbRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+	if request.Name == nil || request.City == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Suburb name and city are required",
+		})
+	}
+
+	createdBy := payload.UserID.String()
+	suburb := &models.Suburb{
+		Name:      *request.Name,
+		City:      *request.City,
+		IsActive:  true,
+		CreatedBy: createdBy,
+	}
+	if request.Ward != nil && strings.TrimSpace(*request.Ward) != "" {
+		ward := strings.TrimSpace(*request.Ward)
+		suburb.Ward = &ward
+	}
+	if request.PostalCode != nil && strings.TrimSpace(*request.PostalCode) != "" {
+		postalCode := strings.TrimSpace(*request.PostalCode)
+		suburb.PostalCode = &postalCode
+	}
+	if request.IsActive != nil {
+		suburb.IsActive = *request.IsActive
+	}
+
+	return ac.saveSuburb(c, fiber.StatusCreated, "Suburb created", func(tx *gorm.DB) (*models.Suburb, error) {
+		return ac.SuburbRepo.CreateSuburb(tx, suburb)
+	})
+}
+
+// UpdateSuburbController renames a suburb, moves it to another ward or deactivates it.
+// Deactivated suburbs are no longer suggested or matched; addresses already stored keep their text.
+func (ac *AddressController) UpdateSuburbController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	suburbID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid suburb ID",
+		})
+	}
+
+	var request SuburbRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+
+	updates := repositories.SuburbUpdate{
+		Name:       request.Name,
+		City:       request.City,
+		Ward:       request.Ward,
+		PostalCode: request.PostalCode,
+		IsActive:   request.IsActive,
+	}
+	return ac.saveSuburb(c, fiber.StatusOK, "Suburb updated", func(tx *gorm.DB) (*models.Suburb, error) {
+		return ac.SuburbRepo.UpdateSuburb(tx, suburbID, updates, payload.UserID.String())
+	})
+}
+
+func (ac *AddressController) saveSuburb(
+	c *fiber.Ctx,
+	status int,
+	message string,
+	save func(tx *gorm.DB) (*models.Suburb, error),
+) error {
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	suburb, err := save(tx)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(suburbErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to save suburb",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not commit database transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"success": true,
+		"message": message,
+		"data":    suburb,
+	})
+}
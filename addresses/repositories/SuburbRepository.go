@@ -0,0 +1,194 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"town-planning-backend/addresses/services"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxSuburbSuggestions bounds the typeahead's results
+const MaxSuburbSuggestions = 25
+
+type SuburbRepository interface {
+	services.SuburbSource
+
+	// Reference table management
+	CreateSuburb(tx *gorm.DB, suburb *models.Suburb) (*models.Suburb, error)
+	UpdateSuburb(tx *gorm.DB, suburbID uuid.UUID, updates SuburbUpdate, updatedBy string) (*models.Suburb, error)
+	GetSuburbs(city string, includeInactive bool) ([]models.Suburb, error)
+
+	// Typeahead for the frontend's address fields
+	SearchSuburbs(query string, city string, limit int) ([]models.Suburb, error)
+}
+
+// SuburbUpdate holds the fields of a suburb to change; nil leaves a field alone
+type SuburbUpdate struct {
+	Name       *string
+	City       *string
+	Ward       *string
+	PostalCode *string
+	IsActive   *bool
+}
+
+type suburbRepository struct {
+	db *gorm.DB
+}
+
+func NewSuburbRepository(db *gorm.DB) SuburbRepository {
+	return &suburbRepository{
+		db: db,
+	}
+}
+
+// CreateSuburb adds a suburb, with its name and city normalized
+func (r *suburbRepository) CreateSuburb(tx *gorm.DB, suburb *models.Suburb) (*models.Suburb, error) {
+	suburb.Name = services.NormalizeName(suburb.Name)
+	suburb.City = services.NormalizeName(suburb.City)
+	suburb.NormalizedName = services.MatchKey(suburb.Name)
+	if suburb.NormalizedName == "" || suburb.City == "" {
+		return nil, errors.New("suburb name and city are required")
+	}
+
+	if err := r.ensureUnique(tx, suburb.NormalizedName, suburb.City, uuid.Nil); err != nil {
+		return nil, err
+	}
+	if err := tx.Create(suburb).Error; err != nil {
+		return nil, fmt.Errorf("failed to create suburb: %w", err)
+	}
+	return suburb, nil
+}
+
+// UpdateSuburb changes a suburb. Deactivated suburbs are no longer suggested or matched.
+func (r *suburbRepository) UpdateSuburb(tx *gorm.DB, suburbID uuid.UUID, updates SuburbUpdate, updatedBy string) (*models.Suburb, error) {
+	var suburb models.Suburb
+	if err := tx.Where("id = ?", suburbID).First(&suburb).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("suburb not found")
+		}
+		return nil, err
+	}
+
+	if updates.Name != nil {
+		suburb.Name = services.NormalizeName(*updates.Name)
+		suburb.NormalizedName = services.MatchKey(suburb.Name)
+	}
+	if updates.City != nil {
+		suburb.City = services.NormalizeName(*updates.City)
+	}
+	if suburb.NormalizedName == "" || suburb.City == "" {
+		return nil, errors.New("suburb name and city are required")
+	}
+	if updates.Ward != nil {
+		suburb.Ward = emptyToNil(*updates.Ward)
+	}
+	if updates.PostalCode != nil {
+		suburb.PostalCode = emptyToNil(*updates.PostalCode)
+	}
+	if updates.IsActive != nil {
+		suburb.IsActive = *updates.IsActive
+	}
+	suburb.UpdatedBy = &updatedBy
+
+	if err := r.ensureUnique(tx, suburb.NormalizedName, suburb.City, suburb.ID); err != nil {
+		return nil, err
+	}
+	if err := tx.Save(&suburb).Error; err != nil {
+		return nil, fmt.Errorf("failed to update suburb: %w", err)
+	}
+	return &suburb, nil
+}
+
+func (r *suburbRepository) ensureUnique(tx *gorm.DB, normalizedName string, city string, exceptID uuid.UUID) error {
+	var count int64
+	if err := tx.Model(&models.Suburb{}).
+		Where("normalized_name = ? AND city = ? AND id <> ?", normalizedName, city, exceptID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("suburb already exists in this city")
+	}
+	return nil
+}
+
+// GetSuburbs lists the reference table, optionally for one city, by city and name
+func (r *suburbRepository) GetSuburbs(city string, includeInactive bool) ([]models.Suburb, error) {
+	query := r.db.Model(&models.Suburb{})
+	if city = services.NormalizeName(city); city != "" {
+		query = query.Where("city = ?", city)
+	}
+	if !includeInactive {
+		query = query.Where("is_active = ?", true)
+	}
+
+	var suburbs []models.Suburb
+	if err := query.Order("city ASC, name ASC").Find(&suburbs).Error; err != nil {
+		return nil, fmt.Errorf("failed to load suburbs: %w", err)
+	}
+	return suburbs, nil
+}
+
+// SearchSuburbs suggests active suburbs whose name starts with the query, then those containing
+// it, optionally within one city
+func (r *suburbRepository) SearchSuburbs(query string, city string, limit int) ([]models.Suburb, error) {
+	key := services.MatchKey(query)
+	if key == "" {
+		return []models.Suburb{}, nil
+	}
+	if limit <= 0 || limit > MaxSuburbSuggestions {
+		limit = MaxSuburbSuggestions
+	}
+	pattern := "%" + escapeLike(key) + "%"
+	prefix := escapeLike(key) + "%"
+
+	db := r.db.Model(&models.Suburb{}).
+		Where("is_active = ?", true).
+		Where("normalized_name LIKE ? OR LOWER(city) LIKE ?", pattern, prefix)
+	if city = services.NormalizeName(city); city != "" {
+		db = db.Where("city = ?", city)
+	}
+
+	var suburbs []models.Suburb
+	if err := db.Order(gorm.Expr("CASE WHEN normalized_name LIKE ? THEN 0 ELSE 1 END, name ASC", prefix)).
+		Limit(limit).
+		Find(&suburbs).Error; err != nil {
+		return nil, fmt.Errorf("failed to search suburbs: %w", err)
+	}
+	return suburbs, nil
+}
+
+// ActiveSuburbs returns every active suburb of a city
+func (r *suburbRepository) ActiveSuburbs(city string) ([]models.Suburb, error) {
+	var suburbs []models.Suburb
+	if err := r.db.Where("city = ? AND is_active = ?", city, true).Find(&suburbs).Error; err != nil {
+		return nil, fmt.Errorf("failed to load suburbs: %w", err)
+	}
+	return suburbs, nil
+}
+
+// HasReferenceData reports whether any suburb is active. Until the table is filled addresses
+// are only normalized.
+func (r *suburbRepository) HasReferenceData() (bool, error) {
+	var count int64
+	if err := r.db.Model(&models.Suburb{}).Where("is_active = ?", true).Limit(1).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check suburbs: %w", err)
+	}
+	return count > 0, nil
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+func emptyToNil(value string) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
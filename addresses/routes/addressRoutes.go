@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"town-planning-backend/addresses/controllers"
+	"town-planning-backend/addresses/repositories"
+	"town-planning-backend/addresses/services"
+	"town-planning-backend/middleware"
+	user_repository "town-planning-backend/users/repositories"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+func AddressRouterInit(
+	app *fiber.App,
+	db *gorm.DB,
+	suburbRepository repositories.SuburbRepository,
+	addressService *services.AddressService,
+	userRepo user_repository.UserRepository,
+) {
+	addressController := &controllers.AddressController{
+		SuburbRepo:     suburbRepository,
+		AddressService: addressService,
+		DB:             db,
+	}
+
+	addressRoutes := app.Group("/api/v1/addresses")
+	addressRoutes.Get("/suburbs/search", addressController.SearchSuburbsController)
+	addressRoutes.Post("/standardize", addressController.StandardizeAddressController)
+
+	// Suburb reference table management
+	addressRoutes.Get("/suburbs", middleware.RequirePermission(userRepo, "settings.manage"), addressController.GetSuburbsController)
+	addressRoutes.Post("/suburbs", middleware.RequirePermission(userRepo, "settings.manage"), addressController.CreateSuburbController)
+	addressRoutes.Put("/suburbs/:id", middleware.RequirePermission(userRepo, "settings.manage"), addressController.UpdateSuburbController)
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"town-planning-backend/db/models"
+	"town-planning-backend/settings"
+)
+
+// ErrAddressNotRecognised is returned, under STRICT address validation, for an address whose
+// city or suburb is not in the reference table
+var ErrAddressNotRecognised = errors.New("address not recognised")
+
+// SuburbSource supplies the suburb reference table
+type SuburbSource interface {
+	ActiveSuburbs(city string) ([]models.Suburb, error)
+	HasReferenceData() (bool, error)
+}
+
+// StandardizedAddress is an address in its normalized form with the suburb it was matched to.
+// Warnings list what could not be recognised when validation only warns.
+type StandardizedAddress struct {
+	Address  string         `json:"address"`
+	City     string         `json:"city"`
+	Suburb   *models.Suburb `json:"suburb,omitempty"`
+	Ward     *string        `json:"ward,omitempty"` // Ward of the matched suburb
+	Warnings []string       `json:"warnings,omitempty"`
+}
+
+// AddressService normalizes addresses and checks them against the suburb reference table
+type AddressService struct {
+	suburbs SuburbSource
+}
+
+func NewAddressService(suburbs SuburbSource) *AddressService {
+	return &AddressService{suburbs: suburbs}
+}
+
+// Standardize normalizes an address and its city and matches the address to a suburb of the
+// city. requireSuburb is false for addresses that need not name a suburb, such as post boxes.
+// How unrecognised cities and suburbs are treated follows the addresses.validation setting;
+// under STRICT they are returned as ErrAddressNotRecognised.
+func (s *AddressService) Standardize(address string, city string, requireSuburb bool) (*StandardizedAddress, error) {
+	result := &StandardizedAddress{
+		Address: NormalizeAddress(address),
+		City:    NormalizeName(city),
+	}
+
+	mode := settings.String(settings.AddressValidation)
+	if mode == settings.AddressValidationOff || s == nil || s.suburbs == nil {
+		return result, nil
+	}
+	hasReference, err := s.suburbs.HasReferenceData()
+	if err != nil {
+		return nil, err
+	}
+	if !hasReference {
+		return result, nil
+	}
+
+	var problems []string
+	if result.City == "" {
+		problems = append(problems, "city is required")
+	} else {
+		suburbs, err := s.suburbs.ActiveSuburbs(result.City)
+		if err != nil {
+			return nil, err
+		}
+		if len(suburbs) == 0 {
+			problems = append(problems, fmt.Sprintf("%s is not a recognised city", result.City))
+		} else if suburb := matchSuburb(result.Address, suburbs); suburb != nil {
+			result.Suburb = suburb
+			result.Ward = suburb.Ward
+		} else if requireSuburb {
+			problems = append(problems, fmt.Sprintf("the address does not name a recognised suburb of %s", result.City))
+		}
+	}
+
+	if len(problems) > 0 {
+		if mode == settings.AddressValidationStrict {
+			return nil, fmt.Errorf("%w: %s", ErrAddressNotRecognised, strings.Join(problems, "; "))
+		}
+		result.Warnings = problems
+	}
+	return result, nil
+}
+
+// matchSuburb finds the suburb named in the address, preferring the longest name so "Mabelreign
+// North" wins over "Mabelreign"
+func matchSuburb(address string, suburbs []models.Suburb) *models.Suburb {
+	key := " " + MatchKey(address) + " "
+	var best *models.Suburb
+	for i := range suburbs {
+		name := suburbs[i].NormalizedName
+		if name == "" || !strings.Contains(key, " "+name+" ") {
+			continue
+		}
+		if best == nil || len(name) > len(best.NormalizedName) {
+			best = &suburbs[i]
+		}
+	}
+	return best
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+)
+
+// streetTypes expands the abbreviations of street types. They are only expanded as the last word
+// of an address line that has other words, so "St Marys" keeps its "St".
+var streetTypes = map[string]string{
+	"st":   "Street",
+	"str":  "Street",
+	"rd":   "Road",
+	"ave":  "Avenue",
+	"av":   "Avenue",
+	"cres": "Crescent",
+	"cr":   "Crescent",
+	"dr":   "Drive",
+	"cl":   "Close",
+	"ln":   "Lane",
+	"hwy":  "Highway",
+	"pl":   "Place",
+	"blvd": "Boulevard",
+}
+
+// wordAbbreviations are expanded wherever they appear
+var wordAbbreviations = map[string]string{
+	"ext":  "Extension",
+	"extn": "Extension",
+	"ctr":  "Centre",
+	"nth":  "North",
+	"sth":  "South",
+	"pvt":  "Private",
+	"po":   "P.O.",
+	"p.o":  "P.O.",
+	"p.o.": "P.O.",
+}
+
+var ordinalSuffixes = []string{"st", "nd", "rd", "th"}
+
+// NormalizeAddress tidies a free-text address: one space between words, one comma and space
+// between lines, words in title case, unit numbers such as "12a" in upper case, and street type
+// and common abbreviations written out.
+func NormalizeAddress(raw string) string {
+	var lines []string
+	for _, line := range strings.Split(raw, ",") {
+		words := strings.Fields(line)
+		if len(words) == 0 {
+			continue
+		}
+		for i, word := range words {
+			key := strings.ToLower(strings.TrimSuffix(word, "."))
+			if i > 0 && i == len(words)-1 {
+				if expanded, ok := streetTypes[key]; ok {
+					words[i] = expanded
+					continue
+				}
+			}
+			if expanded, ok := wordAbbreviations[strings.ToLower(word)]; ok {
+				words[i] = expanded
+				continue
+			}
+			if expanded, ok := wordAbbreviations[key]; ok {
+				words[i] = expanded
+				continue
+			}
+			words[i] = normalizeWord(word)
+		}
+		lines = append(lines, strings.Join(words, " "))
+	}
+	return strings.Join(lines, ", ")
+}
+
+// NormalizeName tidies the name of a suburb or city: one space between words, in title case
+func NormalizeName(raw string) string {
+	words := strings.Fields(raw)
+	for i, word := range words {
+		words[i] = normalizeWord(word)
+	}
+	return strings.Join(words, " ")
+}
+
+// MatchKey reduces a name or address to lower-case words without punctuation, so "Mt. Pleasant"
+// and "mt pleasant" match
+func MatchKey(raw string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(raw) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// IsPostBox reports whether the address is a post office box or private bag, which names no
+// suburb
+func IsPostBox(address string) bool {
+	key := " " + MatchKey(address) + " "
+	return strings.Contains(key, " box ") || strings.Contains(key, " private bag ") || strings.Contains(key, " pvt bag ")
+}
+
+// normalizeWord writes a word in title case. Words with digits ("12a", "4th") keep their case
+// apart from unit letters, and short all-capital words such as "CBD" are kept as they are.
+func normalizeWord(word string) string {
+	if hasDigit(word) {
+		lower := strings.ToLower(word)
+		for _, suffix := range ordinalSuffixes {
+			if strings.HasSuffix(lower, suffix) {
+				return lower
+			}
+		}
+		return strings.ToUpper(word)
+	}
+	if len(word) <= 4 && word == strings.ToUpper(word) {
+		return word
+	}
+
+	runes := []rune(strings.ToLower(word))
+	capitalize := true
+	for i, r := range runes {
+		if capitalize && unicode.IsLetter(r) {
+			runes[i] = unicode.ToUpper(r)
+			capitalize = false
+		}
+		// Capitalize after hyphens and apostrophes too: "Mbare-Musika", "O'Brien"
+		if r == '-' || r == '\'' {
+			capitalize = true
+		}
+	}
+	return string(runes)
+}
+
+func hasDigit(word string) bool {
+	return strings.IndexFunc(word, unicode.IsDigit) >= 0
+}
@@ -2,8 +2,12 @@ package controllers
 
 import (
 	"context"
+	"errors"
+	"strings"
+	address_services "town-planning-backend/addresses/services"
 	"town-planning-backend/applicants/repositories"
 	"town-planning-backend/applicants/services"
+	application_services "town-planning-backend/applications/services"
 	indexing_repository "town-planning-backend/bleve/repositories"
 	"town-planning-backend/config"
 	"town-planning-backend/db/models"
@@ -16,10 +20,11 @@ import (
 )
 
 type ApplicantController struct {
-	ApplicantRepo repositories.ApplicantRepository
-	DB            *gorm.DB
-	Ctx           context.Context
-	BleveRepo     indexing_repository.BleveRepositoryInterface
+	ApplicantRepo  repositories.ApplicantRepository
+	DB             *gorm.DB
+	Ctx            context.Context
+	BleveRepo      indexing_repository.BleveRepositoryInterface
+	AddressService *address_services.AddressService
 	// DocumentSvc        *documents_services.DocumentService
 	// ApplicationService services.ApplicationService
 }
@@ -38,6 +43,7 @@ type CreateApplicantRequest struct {
 	PostalAddress                   *string                             `json:"postal_address"`
 	City                            *string                             `json:"city"`
 	Gender                          *string                             `json:"gender"`
+	PreferredLanguage               string                              `json:"preferred_language"` // en, sn or nd
 	CreatedBy                       string                              `json:"created_by"`
 	OrganisationRepresentatives     []OrganisationRepresentativeRequest `json:"organisation_representatives"`
 	ApplicantAdditionalPhoneNumbers []AdditionalPhoneRequest            `json:"applicant_additional_phone_numbers"`
@@ -66,6 +72,18 @@ func (ac *ApplicantController) CreateApplicantController(c *fiber.Ctx) error {
 		})
 	}
 
+	// Letters and emails default to English
+	preferredLanguage := application_services.DefaultLanguage
+	if request.PreferredLanguage != "" {
+		if !application_services.SupportedLanguage(request.PreferredLanguage) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "Validation failed",
+				"error":   "Unsupported preferred language",
+			})
+		}
+		preferredLanguage = strings.ToLower(strings.TrimSpace(request.PreferredLanguage))
+	}
+
 	// Map DTO to GORM model
 	applicant := models.Applicant{
 		ApplicantType:           request.ApplicantType,
@@ -81,6 +99,7 @@ func (ac *ApplicantController) CreateApplicantController(c *fiber.Ctx) error {
 		PostalAddress:           request.PostalAddress,
 		City:                    request.City,
 		Gender:                  request.Gender,
+		PreferredLanguage:       preferredLanguage,
 		CreatedBy:               request.CreatedBy,
 		Status:                  models.ProspectiveApplicant, // Set default status
 	}
@@ -123,8 +142,36 @@ func (ac *ApplicantController) CreateApplicantController(c *fiber.Ctx) error {
 		})
 	}
 
+	// Standardize the postal address against the suburb reference table. Post boxes name no suburb.
+	var addressWarnings []string
+	if applicant.PostalAddress != nil && strings.TrimSpace(*applicant.PostalAddress) != "" {
+		city := ""
+		if applicant.City != nil {
+			city = *applicant.City
+		}
+		standardized, err := ac.AddressService.Standardize(*applicant.PostalAddress, city, !address_services.IsPostBox(*applicant.PostalAddress))
+		if err != nil {
+			if errors.Is(err, address_services.ErrAddressNotRecognised) {
+				return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+					"message": "Validation failed",
+					"error":   err.Error(),
+				})
+			}
+			config.Logger.Error("Failed to standardize applicant address", zap.Error(err))
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"message": "Failed to standardize address",
+				"error":   err.Error(),
+			})
+		}
+		applicant.PostalAddress = &standardized.Address
+		if standardized.City != "" {
+			applicant.City = &standardized.City
+		}
+		addressWarnings = standardized.Warnings
+	}
+
 	// --- Start Database Transaction ---
-	tx := ac.DB.Begin()
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
 	if tx.Error != nil {
 		config.Logger.Error("Failed to begin database transaction", zap.Error(tx.Error))
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -180,7 +227,8 @@ func (ac *ApplicantController) CreateApplicantController(c *fiber.Ctx) error {
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"message": "Applicant successfully created",
-		"data":    createdApplicant,
+		"message":  "Applicant successfully created",
+		"data":     createdApplicant,
+		"warnings": addressWarnings,
 	})
 }
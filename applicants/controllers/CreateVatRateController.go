@@ -23,7 +23,7 @@ func (pc *ApplicantController) CreateVATRateController(c *fiber.Ctx) error {
 
 	// Start transaction
 	config.Logger.Info("Starting transaction for VAT rate creation")
-	tx := pc.DB.Session(&gorm.Session{}).WithContext(c.Context()).Begin()
+	tx := pc.DB.Session(&gorm.Session{}).WithContext(c.UserContext()).Begin()
 	if tx.Error != nil {
 		config.Logger.Error("Failed to start transaction", zap.Error(tx.Error))
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
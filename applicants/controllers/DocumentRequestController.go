@@ -0,0 +1,290 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"town-planning-backend/applicants/services"
+	application_services "town-planning-backend/applications/services"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	sms_services "town-planning-backend/sms/services"
+	"town-planning-backend/token"
+	"town-planning-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+)
+
+// DocumentRequestRequest asks the applicant for outstanding checklist documents. Items are
+// checklist codes and default to every mandatory document still missing.
+type DocumentRequestRequest struct {
+	Channel models.DocumentRequestChannel `json:"channel"`
+	Items   []string                      `json:"items"`
+	Note    *string                       `json:"note"`
+}
+
+// RequestOutstandingDocumentsController composes a request for the checklist documents missing
+// from an application, each with a portal upload link, and records it on the application's
+// timeline. EMAIL requests are sent straight away; WHATSAPP requests come back as a
+// click-to-chat link for staff to send from their own WhatsApp.
+func (pc *PortalController) RequestOutstandingDocumentsController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "User not authenticated",
+		})
+	}
+
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid application ID",
+			"error":   err.Error(),
+		})
+	}
+
+	var request DocumentRequestRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "Invalid request payload",
+				"error":   err.Error(),
+			})
+		}
+	}
+	request.Channel = models.DocumentRequestChannel(strings.ToUpper(strings.TrimSpace(string(request.Channel))))
+	if request.Channel == "" {
+		request.Channel = models.DocumentRequestByEmail
+	}
+	if request.Channel != models.DocumentRequestByEmail && request.Channel != models.DocumentRequestByWhatsApp {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Validation failed",
+			"error":   "channel must be EMAIL or WHATSAPP",
+		})
+	}
+
+	application, err := pc.ApplicantRepo.GetApplicationForDocumentRequest(applicationID)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if err.Error() == "application not found" {
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"message": "Failed to fetch application",
+			"error":   err.Error(),
+		})
+	}
+	applicant := application.Applicant
+
+	items := application_services.OutstandingDocuments(application)
+	if len(request.Items) > 0 {
+		checklist := map[string]application_services.DocumentChecklistItem{}
+		for _, item := range application_services.DocumentChecklist(application) {
+			checklist[item.Code] = item
+		}
+		items = items[:0]
+		seen := map[string]bool{}
+		for _, code := range request.Items {
+			code = strings.ToUpper(strings.TrimSpace(code))
+			item, known := checklist[code]
+			if !known {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"message": "Validation failed",
+					"error":   fmt.Sprintf("%s is not on the document checklist", code),
+				})
+			}
+			if item.Provided {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"message": "Validation failed",
+					"error":   fmt.Sprintf("%s has already been provided", item.Label),
+				})
+			}
+			if !seen[code] {
+				seen[code] = true
+				items = append(items, item)
+			}
+		}
+	}
+	if len(items) == 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"message": "The application has no outstanding documents",
+			"error":   "no_outstanding_documents",
+		})
+	}
+
+	var recipient string
+	switch request.Channel {
+	case models.DocumentRequestByEmail:
+		recipient = strings.TrimSpace(applicant.Email)
+		if recipient == "" {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"message": "The applicant has no email address on record",
+				"error":   "missing_email",
+			})
+		}
+	case models.DocumentRequestByWhatsApp:
+		number := applicant.PhoneNumber
+		if applicant.WhatsAppNumber != nil && strings.TrimSpace(*applicant.WhatsAppNumber) != "" {
+			number = *applicant.WhatsAppNumber
+		}
+		if recipient, err = sms_services.NormalizePhoneNumber(number); err != nil {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"message": "The applicant has no valid WhatsApp number on record",
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	data := utils.DocumentRequestEmail{
+		ApplicantName: applicant.FullName,
+		PlanNumber:    application.PlanNumber,
+		ExpiresIn:     fmt.Sprintf("%d days", int(services.DocumentUploadLinkTTL.Hours()/24)),
+	}
+	if request.Note != nil {
+		data.Note = strings.TrimSpace(*request.Note)
+	}
+	for _, item := range items {
+		link, err := pc.LoginService.CreateDocumentUploadLink(applicant.ID, application.ID, item.Code)
+		if err != nil {
+			config.Logger.Error("Failed to create document upload link",
+				zap.Error(err),
+				zap.String("applicationID", application.ID.String()))
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"message": "Failed to create upload links",
+				"error":   err.Error(),
+			})
+		}
+		data.Items = append(data.Items, utils.DocumentRequestItem{Label: item.Label, UploadURL: link})
+	}
+
+	var subject, message string
+	if request.Channel == models.DocumentRequestByEmail {
+		subject, message, _, err = utils.RenderEmailTemplate(utils.EmailDocumentRequest, applicant.PreferredLanguage, data)
+	} else {
+		message, _, err = utils.RenderSMSTemplate(utils.EmailDocumentRequest, applicant.PreferredLanguage, data)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to compose document request",
+			"error":   err.Error(),
+		})
+	}
+
+	// The links are one-time sign-in tokens, so only the checklist items are kept on record
+	requestedItems, err := json.Marshal(items)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to compose document request",
+			"error":   err.Error(),
+		})
+	}
+	documentRequest := &models.DocumentRequest{
+		ApplicationID: application.ID,
+		ApplicantID:   applicant.ID,
+		RequestedByID: payload.UserID,
+		Channel:       request.Channel,
+		Recipient:     recipient,
+		Items:         datatypes.JSON(requestedItems),
+		Message:       redactUploadLinks(message, data.Items),
+	}
+	if data.Note != "" {
+		documentRequest.Note = &data.Note
+	}
+	if err := pc.ApplicantRepo.CreateDocumentRequest(documentRequest); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to record document request",
+			"error":   err.Error(),
+		})
+	}
+
+	response := fiber.Map{
+		"request": documentRequest,
+		"items":   items,
+	}
+	if request.Channel == models.DocumentRequestByEmail {
+		go func() {
+			if err := utils.SendApplicantEmail(utils.ApplicantEmail{
+				To:            recipient,
+				Subject:       subject,
+				Body:          message,
+				Template:      utils.EmailDocumentRequest,
+				ApplicantID:   &applicant.ID,
+				ApplicationID: &application.ID,
+				TrackClicks:   true,
+			}); err != nil {
+				config.Logger.Warn("Failed to send document request email",
+					zap.Error(err),
+					zap.String("applicationID", application.ID.String()),
+					zap.String("documentRequestID", documentRequest.ID.String()))
+			}
+		}()
+	} else {
+		response["whatsapp_url"] = fmt.Sprintf("https://wa.me/%s?text=%s", strings.TrimPrefix(recipient, "+"), url.QueryEscape(message))
+		response["message"] = message
+	}
+
+	config.Logger.Info("Outstanding documents requested from applicant",
+		zap.String("applicationID", application.ID.String()),
+		zap.String("channel", string(request.Channel)),
+		zap.Int("items", len(items)),
+		zap.String("userID", payload.UserID.String()))
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Document request sent",
+		"data":    response,
+	})
+}
+
+// GetDocumentRequestsController returns an application's document checklist, what is still
+// outstanding and the requests already sent to the applicant
+func (pc *PortalController) GetDocumentRequestsController(c *fiber.Ctx) error {
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid application ID",
+			"error":   err.Error(),
+		})
+	}
+
+	application, err := pc.ApplicantRepo.GetApplicationForDocumentRequest(applicationID)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if err.Error() == "application not found" {
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"message": "Failed to fetch application",
+			"error":   err.Error(),
+		})
+	}
+
+	requests, err := pc.ApplicantRepo.GetDocumentRequests(applicationID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to fetch document requests",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Document requests retrieved",
+		"data": fiber.Map{
+			"checklist":   application_services.DocumentChecklist(application),
+			"outstanding": application_services.OutstandingDocuments(application),
+			"requests":    requests,
+		},
+	})
+}
+
+// redactUploadLinks replaces the one-time upload links in a composed request so the copy kept
+// on record cannot be used to sign in as the applicant
+func redactUploadLinks(message string, items []utils.DocumentRequestItem) string {
+	for _, item := range items {
+		message = strings.ReplaceAll(message, item.UploadURL, "[upload link]")
+	}
+	return message
+}
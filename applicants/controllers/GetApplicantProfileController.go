@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"town-planning-backend/config"
+	"town-planning-backend/utils/fieldset"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// GetApplicantProfileController returns an applicant with their representatives, phone numbers
+// and applications. The fields parameter trims the response, e.g.
+// ?fields=full_name,email,applications.plan_number,applications.status
+func (ac *ApplicantController) GetApplicantProfileController(c *fiber.Ctx) error {
+	applicantID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid applicant ID",
+			"error":   err.Error(),
+		})
+	}
+
+	fields, err := fieldset.FromQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid fields parameter",
+			"error":   err.Error(),
+		})
+	}
+
+	applicant, err := ac.ApplicantRepo.GetApplicantProfile(applicantID)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if err.Error() == "applicant not found" {
+			status = fiber.StatusNotFound
+		} else {
+			config.Logger.Error("Failed to fetch applicant profile",
+				zap.Error(err),
+				zap.String("applicantID", applicantID.String()))
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"message": "Failed to fetch applicant",
+			"error":   err.Error(),
+		})
+	}
+
+	data, err := fields.Apply(applicant)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to prepare applicant profile",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Applicant retrieved",
+		"data":    data,
+	})
+}
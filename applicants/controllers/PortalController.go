@@ -0,0 +1,789 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"town-planning-backend/applicants/repositories"
+	"town-planning-backend/applicants/services"
+	application_services "town-planning-backend/applications/services"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	documents_requests "town-planning-backend/documents/requests"
+	documents_services "town-planning-backend/documents/services"
+	"town-planning-backend/middleware"
+	"town-planning-backend/token"
+	"town-planning-backend/utils"
+	"town-planning-backend/utils/pagination"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// maxPortalUploadSize caps documents applicants upload through the portal
+const maxPortalUploadSize = 10 * 1024 * 1024
+
+// isProduction reports whether the server runs behind HTTPS, so the portal cookie is only sent
+// over it
+func isProduction() bool {
+	return os.Getenv("APP_ENV") == "production"
+}
+
+// portalUploadTypes are the file types applicants may upload through the portal
+var portalUploadTypes = map[string]bool{".pdf": true, ".jpg": true, ".jpeg": true, ".png": true}
+
+// PortalController serves the applicant portal. Applicants sign in with an emailed link and get
+// a token scoped to their own applications; staff tokens are never accepted here.
+type PortalController struct {
+	ApplicantRepo   repositories.ApplicantRepository
+	DB              *gorm.DB
+	DocumentSvc     *documents_services.DocumentService
+	TokenMaker      token.Maker
+	RedisClient     *redis.Client
+	Ctx             context.Context
+	LoginService    *services.PortalLoginService
+	FrontendBaseURL string
+	Estimator       *application_services.ProcessingEstimator
+}
+
+type PortalSignInRequest struct {
+	Email string `json:"email"`
+}
+
+type PortalMessageRequest struct {
+	Content string `json:"content"`
+}
+
+// PortalApplication is the part of an application an applicant sees on the portal
+type PortalApplication struct {
+	ID                  uuid.UUID                `json:"id"`
+	PlanNumber          string                   `json:"plan_number"`
+	PermitNumber        string                   `json:"permit_number,omitempty"`
+	Status              models.ApplicationStatus `json:"status"`
+	PaymentStatus       models.PaymentStatus     `json:"payment_status"`
+	SubmissionDate      time.Time                `json:"submission_date"`
+	FinalApprovalDate   *time.Time               `json:"final_approval_date"`
+	RejectionDate       *time.Time               `json:"rejection_date"`
+	StandNumber         string                   `json:"stand_number,omitempty"`
+	DevelopmentCategory string                   `json:"development_category,omitempty"`
+	Documents           []PortalDocument         `json:"documents,omitempty"`
+
+	// When a decision is expected, while the application is still being processed
+	ProcessingEstimate *application_services.ProcessingEstimate `json:"processing_estimate,omitempty"`
+}
+
+// PortalDocument lists a document on file without exposing where it is stored
+type PortalDocument struct {
+	ID        uuid.UUID `json:"id"`
+	FileName  string    `json:"file_name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newPortalApplication(application models.Application) PortalApplication {
+	view := PortalApplication{
+		ID:                application.ID,
+		PlanNumber:        application.PlanNumber,
+		PermitNumber:      application.PermitNumber,
+		Status:            application.Status,
+		PaymentStatus:     application.PaymentStatus,
+		SubmissionDate:    application.SubmissionDate,
+		FinalApprovalDate: application.FinalApprovalDate,
+		RejectionDate:     application.RejectionDate,
+	}
+	if application.Stand != nil {
+		view.StandNumber = application.Stand.StandNumber
+	}
+	if application.Tariff != nil && application.Tariff.DevelopmentCategory.Name != "" {
+		view.DevelopmentCategory = application.Tariff.DevelopmentCategory.Name
+	}
+	for _, link := range application.ApplicationDocuments {
+		view.Documents = append(view.Documents, PortalDocument{
+			ID:        link.Document.ID,
+			FileName:  link.Document.FileName,
+			CreatedAt: link.Document.CreatedAt,
+		})
+	}
+	return view
+}
+
+func portalApplicant(c *fiber.Ctx) (*token.Payload, bool) {
+	payload, ok := c.Locals("applicant").(*token.Payload)
+	return payload, ok && payload != nil
+}
+
+// RequestPortalLinkController emails a sign-in link to every applicant with the address. The
+// response is the same whether or not the address is known, so it cannot be used to find out
+// who has applied.
+func (pc *PortalController) RequestPortalLinkController(c *fiber.Ctx) error {
+	var request PortalSignInRequest
+	if err := c.BodyParser(&request); err != nil || strings.TrimSpace(request.Email) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request payload",
+			"error":   "email is required",
+		})
+	}
+
+	applicants, err := pc.ApplicantRepo.GetPortalApplicantsByEmail(request.Email)
+	if err != nil {
+		config.Logger.Error("Failed to look up applicants for portal sign-in", zap.Error(err))
+	}
+
+	for _, applicant := range applicants {
+		link, err := pc.LoginService.CreateLoginLink(applicant.ID)
+		if err != nil {
+			config.Logger.Error("Failed to create portal sign-in link",
+				zap.Error(err),
+				zap.String("applicantID", applicant.ID.String()))
+			continue
+		}
+
+		applicant := applicant
+		go func() {
+			subject, message, _, err := utils.RenderEmailTemplate(utils.EmailPortalSignIn, applicant.PreferredLanguage, utils.PortalSignInEmail{
+				ApplicantName: applicant.FullName,
+				SignInURL:     link,
+				ExpiresIn:     "15 minutes",
+			})
+			if err != nil {
+				config.Logger.Warn("Failed to render portal sign-in email",
+					zap.Error(err),
+					zap.String("applicantID", applicant.ID.String()))
+				return
+			}
+			if err := utils.SendApplicantEmail(utils.ApplicantEmail{
+				To:          applicant.Email,
+				Subject:     subject,
+				Body:        message,
+				Template:    utils.EmailPortalSignIn,
+				ApplicantID: &applicant.ID,
+			}); err != nil {
+				config.Logger.Warn("Failed to send portal sign-in email",
+					zap.Error(err),
+					zap.String("applicantID", applicant.ID.String()))
+			}
+		}()
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "If the address is on record, a sign-in link has been sent to it",
+	})
+}
+
+// VerifyPortalLinkController exchanges a sign-in link for a portal token. The token only carries
+// the applicant scopes, so it cannot reach staff routes.
+func (pc *PortalController) VerifyPortalLinkController(c *fiber.Ctx) error {
+	applicantID, err := pc.LoginService.ConsumeLoginLink(c.Query("token"))
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if err.Error() == "invalid or expired link" {
+			status = fiber.StatusUnauthorized
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"message": "Failed to sign in",
+			"error":   err.Error(),
+		})
+	}
+
+	applicant, err := pc.ApplicantRepo.GetPortalApplicant(applicantID)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		switch err.Error() {
+		case "applicant not found", "applicant cannot use the portal":
+			status = fiber.StatusForbidden
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"message": "Failed to sign in",
+			"error":   err.Error(),
+		})
+	}
+
+	portalToken, err := pc.TokenMaker.CreateApplicantToken(applicant.ID, token.ApplicantPortalScopes, services.PortalSessionDuration)
+	if err != nil {
+		config.Logger.Error("Failed to create portal token",
+			zap.Error(err),
+			zap.String("applicantID", applicant.ID.String()))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to sign in",
+			"error":   err.Error(),
+		})
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     middleware.PortalTokenCookie,
+		Value:    portalToken,
+		Expires:  time.Now().Add(services.PortalSessionDuration),
+		HTTPOnly: true,
+		Secure:   isProduction(), // secure in production
+		SameSite: "Lax",
+		Path:     "/portal",
+	})
+
+	config.Logger.Info("Applicant signed in to the portal",
+		zap.String("applicantID", applicant.ID.String()))
+
+	return c.JSON(fiber.Map{
+		"message": "Signed in",
+		"data": fiber.Map{
+			"applicant": fiber.Map{
+				"id":        applicant.ID,
+				"full_name": applicant.FullName,
+				"email":     applicant.Email,
+			},
+			"token":      portalToken,
+			"expires_at": time.Now().Add(services.PortalSessionDuration),
+			"scopes":     token.ApplicantPortalScopes,
+		},
+	})
+}
+
+// PortalLogoutController revokes the applicant's token until it would have expired anyway
+func (pc *PortalController) PortalLogoutController(c *fiber.Ctx) error {
+	payload, ok := portalApplicant(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "Applicant not authenticated",
+		})
+	}
+
+	if remaining := time.Until(payload.ExpiredAt); remaining > 0 {
+		if err := pc.RedisClient.Set(pc.Ctx, middleware.RevokedPortalTokenKey(payload.ID.String()), payload.ApplicantID.String(), remaining).Err(); err != nil {
+			config.Logger.Error("Failed to revoke portal token",
+				zap.Error(err),
+				zap.String("applicantID", payload.ApplicantID.String()))
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"message": "Failed to sign out",
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     middleware.PortalTokenCookie,
+		Value:    "",
+		Expires:  time.Now().Add(-time.Hour),
+		HTTPOnly: true,
+		Secure:   isProduction(), // secure in production
+		SameSite: "Lax",
+		Path:     "/portal",
+	})
+
+	return c.JSON(fiber.Map{
+		"message": "Signed out",
+	})
+}
+
+// GetPortalProfileController returns the signed-in applicant
+func (pc *PortalController) GetPortalProfileController(c *fiber.Ctx) error {
+	payload, ok := portalApplicant(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "Applicant not authenticated",
+		})
+	}
+
+	applicant, err := pc.ApplicantRepo.GetPortalApplicant(payload.ApplicantID)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		switch err.Error() {
+		case "applicant not found", "applicant cannot use the portal":
+			status = fiber.StatusForbidden
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"message": "Failed to fetch profile",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Profile retrieved",
+		"data": fiber.Map{
+			"id":                 applicant.ID,
+			"full_name":          applicant.FullName,
+			"email":              applicant.Email,
+			"preferred_language": applicant.PreferredLanguage,
+			"scopes":             payload.Scopes,
+		},
+	})
+}
+
+// GetPortalApplicationsController lists the applications the applicant owns, alone or jointly
+func (pc *PortalController) GetPortalApplicationsController(c *fiber.Ctx) error {
+	payload, ok := portalApplicant(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "Applicant not authenticated",
+		})
+	}
+
+	page, err := pagination.ParseRequest(c, 20)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid pagination parameters",
+			"error":   err.Error(),
+		})
+	}
+
+	applications, total, err := pc.ApplicantRepo.GetPortalApplications(payload.ApplicantID, page)
+	if err != nil {
+		config.Logger.Error("Failed to fetch portal applications",
+			zap.Error(err),
+			zap.String("applicantID", payload.ApplicantID.String()))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to fetch applications",
+			"error":   err.Error(),
+		})
+	}
+
+	views := make([]PortalApplication, 0, len(applications))
+	for _, application := range applications {
+		views = append(views, newPortalApplication(application))
+	}
+
+	var next *pagination.Cursor
+	if len(applications) > 0 {
+		last := applications[len(applications)-1]
+		next = page.NextCursor(len(applications), last.CreatedAt, last.ID)
+	}
+	return c.JSON(pagination.NewEnvelope(c, page, views, total, next))
+}
+
+// GetPortalApplicationController returns one of the applicant's applications with its documents
+func (pc *PortalController) GetPortalApplicationController(c *fiber.Ctx) error {
+	payload, ok := portalApplicant(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "Applicant not authenticated",
+		})
+	}
+
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid application ID",
+			"error":   err.Error(),
+		})
+	}
+
+	application, err := pc.ApplicantRepo.GetPortalApplication(payload.ApplicantID, applicationID)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if err.Error() == "application not found" {
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"message": "Failed to fetch application",
+			"error":   err.Error(),
+		})
+	}
+
+	view := newPortalApplication(*application)
+	estimate, err := pc.Estimator.EstimateForApplication(application)
+	if err != nil {
+		config.Logger.Warn("Failed to estimate application processing time",
+			zap.Error(err),
+			zap.String("applicationID", application.ID.String()))
+	}
+	view.ProcessingEstimate = estimate
+
+	return c.JSON(fiber.Map{
+		"message": "Application retrieved",
+		"data":    view,
+	})
+}
+
+// UploadPortalDocumentController stores a document the applicant uploads to their application.
+// Staff file it under the right category when they review it.
+func (pc *PortalController) UploadPortalDocumentController(c *fiber.Ctx) error {
+	payload, ok := portalApplicant(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "Applicant not authenticated",
+		})
+	}
+
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid application ID",
+			"error":   err.Error(),
+		})
+	}
+
+	application, err := pc.ApplicantRepo.GetPortalApplication(payload.ApplicantID, applicationID)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if err.Error() == "application not found" {
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"message": "Failed to fetch application",
+			"error":   err.Error(),
+		})
+	}
+
+	fileHeader, err := c.FormFile("document")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "A document is required",
+			"error":   err.Error(),
+		})
+	}
+	if !portalUploadTypes[strings.ToLower(filepath.Ext(fileHeader.Filename))] {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Validation failed",
+			"error":   "Only PDF, JPEG and PNG files can be uploaded",
+		})
+	}
+	if fileHeader.Size > maxPortalUploadSize {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Validation failed",
+			"error":   fmt.Sprintf("Documents must be smaller than %d MB", maxPortalUploadSize/(1024*1024)),
+		})
+	}
+
+	categoryCode := strings.ToUpper(strings.TrimSpace(c.FormValue("category_code")))
+	if categoryCode == "" {
+		categoryCode = documents_services.OtherCategoryCode
+	}
+
+	tx := pc.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	applicantID := payload.ApplicantID
+	document, err := pc.DocumentSvc.UnifiedCreateDocument(tx, c, &documents_requests.CreateDocumentRequest{
+		CategoryCode:  categoryCode,
+		FileName:      fileHeader.Filename,
+		ApplicationID: &application.ID,
+		ApplicantID:   &applicantID,
+		CreatedBy:     "applicant:" + applicantID.String(),
+		FileType:      fileHeader.Header.Get("Content-Type"),
+	}, nil, fileHeader)
+	if err != nil {
+		tx.Rollback()
+		config.Logger.Error("Failed to store portal document",
+			zap.Error(err),
+			zap.String("applicationID", application.ID.String()),
+			zap.String("applicantID", applicantID.String()))
+		var quotaErr *documents_services.StorageQuotaError
+		if errors.As(err, &quotaErr) {
+			return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+				"message": quotaErr.Error(),
+				"error":   "storage_quota_exceeded",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to upload document",
+			"error":   err.Error(),
+		})
+	}
+
+	// Documents from the applicant end any wait on them
+	if _, err := application_services.ResumeApplicationSLA(tx, application.ID, models.SLAResumeDocumentsReceived, nil, time.Now()); err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to upload document",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Internal server error: Could not commit database transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("Applicant uploaded a document through the portal",
+		zap.String("applicationID", application.ID.String()),
+		zap.String("applicantID", applicantID.String()),
+		zap.String("documentID", document.ID.String()))
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Document uploaded",
+		"data": PortalDocument{
+			ID:        document.ID,
+			FileName:  document.Document.FileName,
+			CreatedAt: document.Document.CreatedAt,
+		},
+	})
+}
+
+// GetPortalMessagesController returns the applicant's conversation with staff about an application
+func (pc *PortalController) GetPortalMessagesController(c *fiber.Ctx) error {
+	payload, ok := portalApplicant(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "Applicant not authenticated",
+		})
+	}
+
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid application ID",
+			"error":   err.Error(),
+		})
+	}
+
+	if _, err := pc.ApplicantRepo.GetPortalApplication(payload.ApplicantID, applicationID); err != nil {
+		status := fiber.StatusInternalServerError
+		if err.Error() == "application not found" {
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"message": "Failed to fetch messages",
+			"error":   err.Error(),
+		})
+	}
+
+	messages, err := pc.ApplicantRepo.GetApplicantMessages(applicationID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to fetch messages",
+			"error":   err.Error(),
+		})
+	}
+	if err := pc.ApplicantRepo.MarkApplicantMessagesRead(applicationID, models.ApplicantMessageFromApplicant); err != nil {
+		config.Logger.Warn("Failed to mark portal messages read",
+			zap.Error(err),
+			zap.String("applicationID", applicationID.String()))
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Messages retrieved",
+		"data":    messages,
+	})
+}
+
+// SendPortalMessageController posts an applicant's message to staff about an application
+func (pc *PortalController) SendPortalMessageController(c *fiber.Ctx) error {
+	payload, ok := portalApplicant(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "Applicant not authenticated",
+		})
+	}
+
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid application ID",
+			"error":   err.Error(),
+		})
+	}
+
+	var request PortalMessageRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+	content := strings.TrimSpace(request.Content)
+	if content == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Validation failed",
+			"error":   "Message content is required",
+		})
+	}
+
+	application, err := pc.ApplicantRepo.GetPortalApplication(payload.ApplicantID, applicationID)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if err.Error() == "application not found" {
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"message": "Failed to send message",
+			"error":   err.Error(),
+		})
+	}
+
+	applicant, err := pc.ApplicantRepo.GetPortalApplicant(payload.ApplicantID)
+	if err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"message": "Failed to send message",
+			"error":   err.Error(),
+		})
+	}
+
+	message, err := pc.ApplicantRepo.CreateApplicantMessage(application, applicant, content)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to send message",
+			"error":   err.Error(),
+		})
+	}
+
+	// The applicant's reply ends any wait on them. The message is already saved, so a failure
+	// here is only logged.
+	if _, err := application_services.ResumeApplicationSLA(pc.DB.WithContext(c.UserContext()), application.ID,
+		models.SLAResumeApplicantResponded, nil, time.Now()); err != nil {
+		config.Logger.Error("Failed to resume SLA after applicant reply",
+			zap.Error(err),
+			zap.String("applicationID", application.ID.String()))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Message sent",
+		"data":    message,
+	})
+}
+
+// GetApplicantMessagesController returns the portal conversation with the applicant to staff
+func (pc *PortalController) GetApplicantMessagesController(c *fiber.Ctx) error {
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid application ID",
+			"error":   err.Error(),
+		})
+	}
+
+	messages, err := pc.ApplicantRepo.GetApplicantMessages(applicationID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to fetch applicant messages",
+			"error":   err.Error(),
+		})
+	}
+	if err := pc.ApplicantRepo.MarkApplicantMessagesRead(applicationID, models.ApplicantMessageFromStaff); err != nil {
+		config.Logger.Warn("Failed to mark applicant messages read",
+			zap.Error(err),
+			zap.String("applicationID", applicationID.String()))
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Applicant messages retrieved",
+		"data":    messages,
+	})
+}
+
+// SendApplicantMessageController posts a staff reply to the applicant and emails them that it
+// is waiting on the portal
+func (pc *PortalController) SendApplicantMessageController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "User not authenticated",
+		})
+	}
+
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid application ID",
+			"error":   err.Error(),
+		})
+	}
+
+	var request PortalMessageRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+	content := strings.TrimSpace(request.Content)
+	if content == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Validation failed",
+			"error":   "Message content is required",
+		})
+	}
+
+	message, application, err := pc.ApplicantRepo.CreateStaffApplicantMessage(applicationID, payload.UserID, content)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if err.Error() == "application not found" {
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"message": "Failed to send message",
+			"error":   err.Error(),
+		})
+	}
+
+	applicant := application.Applicant
+	if email := strings.TrimSpace(applicant.Email); email != "" {
+		go func() {
+			subject, body, _, err := utils.RenderEmailTemplate(utils.EmailPortalNewMessage, applicant.PreferredLanguage, utils.PortalNewMessageEmail{
+				ApplicantName: applicant.FullName,
+				PlanNumber:    application.PlanNumber,
+				StaffName:     message.SenderName,
+				PortalURL:     pc.FrontendBaseURL + "/portal",
+			})
+			if err != nil {
+				config.Logger.Warn("Failed to render portal message email",
+					zap.Error(err),
+					zap.String("applicationID", application.ID.String()))
+				return
+			}
+			if err := utils.SendApplicantEmail(utils.ApplicantEmail{
+				To:            email,
+				Subject:       subject,
+				Body:          body,
+				Template:      utils.EmailPortalNewMessage,
+				ApplicantID:   &applicant.ID,
+				ApplicationID: &application.ID,
+				TrackClicks:   true,
+			}); err != nil {
+				config.Logger.Warn("Failed to notify applicant of portal message",
+					zap.Error(err),
+					zap.String("applicationID", application.ID.String()))
+			}
+		}()
+	}
+	if phone := strings.TrimSpace(applicant.PhoneNumber); phone != "" && utils.SMSEnabled() {
+		go func() {
+			body, language, err := utils.RenderSMSTemplate(utils.EmailPortalNewMessage, applicant.PreferredLanguage, utils.PortalNewMessageEmail{
+				ApplicantName: applicant.FullName,
+				PlanNumber:    application.PlanNumber,
+				StaffName:     message.SenderName,
+				PortalURL:     pc.FrontendBaseURL + "/portal",
+			})
+			if err != nil {
+				config.Logger.Warn("Failed to render portal message SMS",
+					zap.Error(err),
+					zap.String("applicationID", application.ID.String()))
+				return
+			}
+			if err := utils.SendApplicantSMS(utils.ApplicantSMS{
+				To:            phone,
+				Body:          body,
+				Language:      language,
+				Template:      utils.EmailPortalNewMessage,
+				ApplicantID:   &applicant.ID,
+				ApplicationID: &application.ID,
+			}); err != nil {
+				config.Logger.Warn("Failed to text applicant about portal message",
+					zap.Error(err),
+					zap.String("applicationID", application.ID.String()))
+			}
+		}()
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Message sent",
+		"data":    message,
+	})
+}
@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"strings"
+	application_services "town-planning-backend/applications/services"
+	"town-planning-backend/config"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type UpdateApplicantLanguageRequest struct {
+	PreferredLanguage string `json:"preferred_language"` // en, sn or nd
+}
+
+// UpdateApplicantLanguageController changes the language an applicant's letters and emails are sent in
+func (ac *ApplicantController) UpdateApplicantLanguageController(c *fiber.Ctx) error {
+	applicantID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid applicant ID",
+			"error":   err.Error(),
+		})
+	}
+
+	var request UpdateApplicantLanguageRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+	if !application_services.SupportedLanguage(request.PreferredLanguage) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Validation failed",
+			"error":   "Unsupported preferred language",
+		})
+	}
+
+	applicant, err := ac.ApplicantRepo.UpdateApplicantPreferredLanguage(applicantID, strings.ToLower(strings.TrimSpace(request.PreferredLanguage)))
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if err.Error() == "applicant not found" {
+			status = fiber.StatusNotFound
+		}
+		config.Logger.Error("Failed to update applicant preferred language",
+			zap.Error(err),
+			zap.String("applicantID", applicantID.String()))
+		return c.Status(status).JSON(fiber.Map{
+			"message": "Failed to update preferred language",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Preferred language updated",
+		"data":    applicant,
+	})
+}
@@ -1,11 +1,13 @@
 package repositories
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
 	"town-planning-backend/config"
 	"town-planning-backend/db/models"
+	"town-planning-backend/utils/pagination"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -15,13 +17,33 @@ import (
 type ApplicantRepository interface {
 	CreateApplicant(tx *gorm.DB, applicant *models.Applicant) (*models.Applicant, error)
 	GetAllApplicants() ([]models.Applicant, error)
-	GetFilteredApplicants(limit, offset int) ([]models.Applicant, int64, error)
+	GetFilteredApplicants(ctx context.Context, page pagination.Request) ([]models.Applicant, int64, error)
 	GetActiveVATRate(tx *gorm.DB) (*models.VATRate, error)
 	DeactivateVATRate(tx *gorm.DB, vatRateID uuid.UUID, createdBy string) (*models.VATRate, error)
 	CreateVATRate(tx *gorm.DB, vatRate *models.VATRate) (*models.VATRate, error)
 	GetFilteredVatRates(limit, offset int, filters map[string]string) ([]models.VATRate, int64, error)
 	AssignApplicationToGroup(tx *gorm.DB, applicationID string, groupID uuid.UUID, assignedBy string, reassignReason *string, userUUID uuid.UUID) (*models.ApplicationGroupAssignment, error)
 	CreateInitialDecisions(tx *gorm.DB, assignmentID uuid.UUID, groupID uuid.UUID) error
+	UpdateApplicantPreferredLanguage(applicantID uuid.UUID, language string) (*models.Applicant, error)
+	GetApplicantProfile(applicantID uuid.UUID) (*models.Applicant, error)
+
+	// Applicant portal
+	GetPortalApplicantsByEmail(email string) ([]models.Applicant, error)
+	GetPortalApplicant(applicantID uuid.UUID) (*models.Applicant, error)
+	GetPortalApplications(applicantID uuid.UUID, page pagination.Request) ([]models.Application, int64, error)
+	GetPortalApplication(applicantID uuid.UUID, applicationID uuid.UUID) (*models.Application, error)
+	GetApplicantMessages(applicationID uuid.UUID) ([]models.ApplicantMessage, error)
+	MarkApplicantMessagesRead(applicationID uuid.UUID, reader models.ApplicantMessageSender) error
+	CreateApplicantMessage(application *models.Application, applicant *models.Applicant, content string) (*models.ApplicantMessage, error)
+	CreateStaffApplicantMessage(applicationID uuid.UUID, staffUserID uuid.UUID, content string) (*models.ApplicantMessage, *models.Application, error)
+
+	// Requests for outstanding documents
+	GetApplicationForDocumentRequest(applicationID uuid.UUID) (*models.Application, error)
+	CreateDocumentRequest(request *models.DocumentRequest) error
+	GetDocumentRequests(applicationID uuid.UUID) ([]models.DocumentRequest, error)
+
+	// Approval group quorum
+	CheckGroupQuorum(groupID uuid.UUID) (*models.QuorumCheck, []models.QuorumCheck, error)
 }
 
 type applicantRepository struct {
@@ -117,6 +139,11 @@ func (r *applicantRepository) AssignApplicationToGroup(tx *gorm.DB, applicationI
 		zap.String("applicationID", application.ID.String()),
 		zap.String("applicationStatus", string(application.Status)))
 
+	// Applications in the triage queue reach a group only by passing pre-screening
+	if application.Status == models.PreScreeningApplication || application.Status == models.ReturnedToApplicantApplication {
+		return nil, errors.New("application has not passed pre-screening")
+	}
+
 	config.Logger.Info("Looking up approval group", zap.String("groupID", groupID.String()))
 	if err := tx.Where("id = ?", groupID).First(&group).Error; err != nil {
 		config.Logger.Error("Approval group not found", zap.String("groupID", groupID.String()), zap.Error(err))
@@ -126,6 +153,12 @@ func (r *applicantRepository) AssignApplicationToGroup(tx *gorm.DB, applicationI
 		zap.String("groupID", group.ID.String()),
 		zap.String("groupName", group.Name))
 
+	// Refuse, or flag, a group whose available members cannot reach a decision
+	quorum, err := enforceGroupQuorum(tx, &group)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check for existing active assignment
 	var existingAssignment models.ApplicationGroupAssignment
 
@@ -206,6 +239,7 @@ func (r *applicantRepository) AssignApplicationToGroup(tx *gorm.DB, applicationI
 		IssuesResolved:        0,
 		ReadyForFinalApproval: false,
 		UsedBackupMembers:     false,
+		Quorum:                quorum,
 	}
 
 	config.Logger.Info("Creating new group assignment", 
@@ -270,26 +304,31 @@ func reassignReasonOrDefault(reason *string) string {
 	return "No reason provided"
 }
 
+// GetAllApplicants loads every applicant for the search index, with only the archived_at of
+// their applications
 func (ar *applicantRepository) GetAllApplicants() ([]models.Applicant, error) {
 	var applicants []models.Applicant
-	if err := ar.DB.Find(&applicants).Error; err != nil {
+	if err := ar.DB.Preload("Applications", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "applicant_id", "archived_at")
+	}).Find(&applicants).Error; err != nil {
 		config.Logger.Error("Failed to get all applicants", zap.Error(err))
 		return nil, fmt.Errorf("failed to get all applicants: %w", err)
 	}
 	return applicants, nil
 }
 
-func (ar *applicantRepository) GetFilteredApplicants(limit, offset int) ([]models.Applicant, int64, error) {
+func (ar *applicantRepository) GetFilteredApplicants(ctx context.Context, page pagination.Request) ([]models.Applicant, int64, error) {
 	var applicants []models.Applicant
 	var total int64
+	db := ar.DB.WithContext(ctx)
 
 	// Count total number of applicants
-	if err := ar.DB.Model(&models.Applicant{}).Count(&total).Error; err != nil {
+	if err := db.Model(&models.Applicant{}).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// Fetch paginated applicants, ordered by UpdatedAt and CreatedAt (descending)
-	if err := ar.DB.Order("updated_at DESC, created_at DESC").Limit(limit).Offset(offset).Find(&applicants).Error; err != nil {
+	if err := page.Window(db, "applicants", "updated_at DESC, created_at DESC").Find(&applicants).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -344,4 +383,23 @@ func (ar *applicantRepository) CreateApplicant(tx *gorm.DB, applicant *models.Ap
 		zap.Int("phoneNumbers", len(applicant.AdditionalPhoneNumbers)))
 
 	return applicant, nil
-}
\ No newline at end of file
+}
+
+// UpdateApplicantPreferredLanguage sets the language the applicant's letters and emails are sent in
+func (ar *applicantRepository) UpdateApplicantPreferredLanguage(applicantID uuid.UUID, language string) (*models.Applicant, error) {
+	result := ar.DB.Model(&models.Applicant{}).
+		Where("id = ?", applicantID).
+		Update("preferred_language", language)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to update preferred language: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("applicant not found")
+	}
+
+	var applicant models.Applicant
+	if err := ar.DB.Where("id = ?", applicantID).First(&applicant).Error; err != nil {
+		return nil, fmt.Errorf("failed to reload applicant: %w", err)
+	}
+	return &applicant, nil
+}
@@ -0,0 +1,33 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GetApplicantProfile returns an applicant with their representatives, phone numbers and
+// applications, newest application first
+func (ar *applicantRepository) GetApplicantProfile(applicantID uuid.UUID) (*models.Applicant, error) {
+	var applicant models.Applicant
+	err := ar.DB.
+		Preload("OrganisationRepresentatives").
+		Preload("AdditionalPhoneNumbers").
+		Preload("Applications", func(db *gorm.DB) *gorm.DB {
+			return db.Order("applications.created_at DESC")
+		}).
+		Preload("Applications.Stand").
+		Preload("Applications.Tariff.DevelopmentCategory").
+		Where("id = ?", applicantID).
+		First(&applicant).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("applicant not found")
+		}
+		return nil, fmt.Errorf("failed to fetch applicant: %w", err)
+	}
+	return &applicant, nil
+}
@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GetApplicationForDocumentRequest returns an application with its primary applicant, whom
+// requests for outstanding documents go to
+func (ar *applicantRepository) GetApplicationForDocumentRequest(applicationID uuid.UUID) (*models.Application, error) {
+	var application models.Application
+	if err := ar.DB.Preload("Applicant").Where("id = ?", applicationID).First(&application).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("application not found")
+		}
+		return nil, fmt.Errorf("failed to fetch application: %w", err)
+	}
+	return &application, nil
+}
+
+// CreateDocumentRequest records a request sent to the applicant for outstanding documents
+func (ar *applicantRepository) CreateDocumentRequest(request *models.DocumentRequest) error {
+	if err := ar.DB.Create(request).Error; err != nil {
+		return fmt.Errorf("failed to save document request: %w", err)
+	}
+	return nil
+}
+
+// GetDocumentRequests lists the requests for outstanding documents sent about an application,
+// newest first
+func (ar *applicantRepository) GetDocumentRequests(applicationID uuid.UUID) ([]models.DocumentRequest, error) {
+	var requests []models.DocumentRequest
+	if err := ar.DB.
+		Preload("RequestedBy").
+		Where("application_id = ?", applicationID).
+		Order("created_at DESC").
+		Find(&requests).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch document requests: %w", err)
+	}
+	return requests, nil
+}
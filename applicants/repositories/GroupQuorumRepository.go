@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/settings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// maxQuorumAlternatives bounds how many other groups are suggested when a group lacks a quorum
+const maxQuorumAlternatives = 5
+
+// QuorumNotMetError refuses an assignment to a group that cannot decide, suggesting groups of
+// the same type that can
+type QuorumNotMetError struct {
+	Check        models.QuorumCheck
+	Alternatives []models.QuorumCheck
+}
+
+func (e *QuorumNotMetError) Error() string {
+	return fmt.Sprintf("approval group %s does not have a quorum: %s", e.Check.GroupName, strings.Join(e.Check.Problems, "; "))
+}
+
+// CheckGroupQuorum works out whether the group can decide an application now and, when it
+// cannot, which other active groups of the same type could
+func (r *applicantRepository) CheckGroupQuorum(groupID uuid.UUID) (*models.QuorumCheck, []models.QuorumCheck, error) {
+	var group models.ApprovalGroup
+	if err := r.DB.Preload("Members", "is_active = ?", true).
+		Where("id = ?", groupID).
+		First(&group).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New("approval group not found")
+		}
+		return nil, nil, fmt.Errorf("failed to load approval group: %w", err)
+	}
+
+	check := group.CheckQuorum(time.Now())
+	if check.Met() {
+		return &check, []models.QuorumCheck{}, nil
+	}
+	alternatives, err := quorumAlternatives(r.DB, &group)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &check, alternatives, nil
+}
+
+// enforceGroupQuorum applies the quorum enforcement setting to an assignment. It returns the
+// group's check when the group lacks a quorum but the assignment may go ahead, and a
+// QuorumNotMetError when it may not.
+func enforceGroupQuorum(tx *gorm.DB, group *models.ApprovalGroup) (*models.QuorumCheck, error) {
+	mode := settings.String(settings.ApprovalQuorumEnforcement)
+	if mode == settings.ApprovalQuorumOff {
+		return nil, nil
+	}
+
+	if err := tx.Model(group).Association("Members").Find(&group.Members, "is_active = ?", true); err != nil {
+		return nil, fmt.Errorf("failed to load group members: %w", err)
+	}
+	check := group.CheckQuorum(time.Now())
+	if check.Met() {
+		return nil, nil
+	}
+
+	if mode == settings.ApprovalQuorumReject {
+		alternatives, err := quorumAlternatives(tx, group)
+		if err != nil {
+			return nil, err
+		}
+		return nil, &QuorumNotMetError{Check: check, Alternatives: alternatives}
+	}
+
+	config.Logger.Warn("Assigning approval group without a quorum",
+		zap.String("groupID", group.ID.String()),
+		zap.Strings("problems", check.Problems))
+	return &check, nil
+}
+
+// quorumAlternatives lists active groups of the same type that have a quorum, those with the
+// most spare approval weight first
+func quorumAlternatives(db *gorm.DB, group *models.ApprovalGroup) ([]models.QuorumCheck, error) {
+	var groups []models.ApprovalGroup
+	if err := db.Preload("Members", "is_active = ?", true).
+		Where("id <> ? AND type = ? AND is_active = ?", group.ID, group.Type, true).
+		Find(&groups).Error; err != nil {
+		return nil, fmt.Errorf("failed to load alternative groups: %w", err)
+	}
+
+	now := time.Now()
+	alternatives := []models.QuorumCheck{}
+	for i := range groups {
+		if check := groups[i].CheckQuorum(now); check.Met() {
+			alternatives = append(alternatives, check)
+		}
+	}
+	sort.SliceStable(alternatives, func(i, j int) bool {
+		return alternatives[i].AvailableWeight-alternatives[i].RequiredWeight >
+			alternatives[j].AvailableWeight-alternatives[j].RequiredWeight
+	})
+	if len(alternatives) > maxQuorumAlternatives {
+		alternatives = alternatives[:maxQuorumAlternatives]
+	}
+	return alternatives, nil
+}
@@ -0,0 +1,161 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"town-planning-backend/db/models"
+	"town-planning-backend/utils/pagination"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ownApplications selects the IDs of applications the applicant owns, alone or jointly
+func (ar *applicantRepository) ownApplications(applicantID uuid.UUID) *gorm.DB {
+	return ar.DB.Model(&models.Application{}).
+		Select("applications.id").
+		Where("applications.applicant_id = ? OR applications.id IN (?)", applicantID,
+			ar.DB.Model(&models.ApplicationCoApplicant{}).
+				Select("application_id").
+				Where("applicant_id = ?", applicantID))
+}
+
+// GetPortalApplicantsByEmail returns the applicants that may sign in to the portal with the
+// email. Blacklisted applicants are left out.
+func (ar *applicantRepository) GetPortalApplicantsByEmail(email string) ([]models.Applicant, error) {
+	var applicants []models.Applicant
+	err := ar.DB.
+		Where("LOWER(email) = ?", strings.ToLower(strings.TrimSpace(email))).
+		Where("status IS NULL OR status <> ?", models.BlacklistedApplicant).
+		Find(&applicants).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch applicants by email: %w", err)
+	}
+	return applicants, nil
+}
+
+// GetPortalApplicant returns the applicant signed in to the portal
+func (ar *applicantRepository) GetPortalApplicant(applicantID uuid.UUID) (*models.Applicant, error) {
+	var applicant models.Applicant
+	if err := ar.DB.Where("id = ?", applicantID).First(&applicant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("applicant not found")
+		}
+		return nil, fmt.Errorf("failed to fetch applicant: %w", err)
+	}
+	if applicant.Status == models.BlacklistedApplicant {
+		return nil, errors.New("applicant cannot use the portal")
+	}
+	return &applicant, nil
+}
+
+// GetPortalApplications lists the applications the applicant owns, alone or jointly
+func (ar *applicantRepository) GetPortalApplications(applicantID uuid.UUID, page pagination.Request) ([]models.Application, int64, error) {
+	query := ar.DB.Model(&models.Application{}).
+		Where("applications.id IN (?)", ar.ownApplications(applicantID))
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var applications []models.Application
+	if err := page.Window(query, "applications", "applications.created_at DESC").
+		Preload("Stand").
+		Preload("Tariff.DevelopmentCategory").
+		Find(&applications).Error; err != nil {
+		return nil, 0, err
+	}
+	return applications, total, nil
+}
+
+// GetPortalApplication returns one of the applicant's applications with its documents. Other
+// applicants' applications are reported as not found.
+func (ar *applicantRepository) GetPortalApplication(applicantID uuid.UUID, applicationID uuid.UUID) (*models.Application, error) {
+	var application models.Application
+	err := ar.DB.
+		Where("applications.id = ? AND applications.id IN (?)", applicationID, ar.ownApplications(applicantID)).
+		Preload("Stand").
+		Preload("Tariff.DevelopmentCategory").
+		Preload("ApplicationDocuments.Document").
+		First(&application).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("application not found")
+		}
+		return nil, fmt.Errorf("failed to fetch application: %w", err)
+	}
+	return &application, nil
+}
+
+// GetApplicantMessages returns the portal conversation about an application, oldest first
+func (ar *applicantRepository) GetApplicantMessages(applicationID uuid.UUID) ([]models.ApplicantMessage, error) {
+	var messages []models.ApplicantMessage
+	if err := ar.DB.
+		Where("application_id = ?", applicationID).
+		Order("created_at ASC").
+		Find(&messages).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch applicant messages: %w", err)
+	}
+	return messages, nil
+}
+
+// MarkApplicantMessagesRead marks the messages the reader's side received as read
+func (ar *applicantRepository) MarkApplicantMessagesRead(applicationID uuid.UUID, reader models.ApplicantMessageSender) error {
+	sentBy := models.ApplicantMessageFromStaff
+	if reader == models.ApplicantMessageFromStaff {
+		sentBy = models.ApplicantMessageFromApplicant
+	}
+	if err := ar.DB.Model(&models.ApplicantMessage{}).
+		Where("application_id = ? AND sender_type = ? AND read_at IS NULL", applicationID, sentBy).
+		Update("read_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to mark applicant messages read: %w", err)
+	}
+	return nil
+}
+
+// CreateApplicantMessage saves a portal message from the applicant
+func (ar *applicantRepository) CreateApplicantMessage(application *models.Application, applicant *models.Applicant, content string) (*models.ApplicantMessage, error) {
+	message := models.ApplicantMessage{
+		ApplicationID: application.ID,
+		ApplicantID:   applicant.ID,
+		SenderType:    models.ApplicantMessageFromApplicant,
+		SenderName:    applicant.FullName,
+		Content:       content,
+	}
+	if err := ar.DB.Create(&message).Error; err != nil {
+		return nil, fmt.Errorf("failed to save applicant message: %w", err)
+	}
+	return &message, nil
+}
+
+// CreateStaffApplicantMessage saves a staff reply to the application's primary applicant
+func (ar *applicantRepository) CreateStaffApplicantMessage(applicationID uuid.UUID, staffUserID uuid.UUID, content string) (*models.ApplicantMessage, *models.Application, error) {
+	var application models.Application
+	if err := ar.DB.Preload("Applicant").Where("id = ?", applicationID).First(&application).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New("application not found")
+		}
+		return nil, nil, fmt.Errorf("failed to fetch application: %w", err)
+	}
+
+	var staff models.User
+	if err := ar.DB.Where("id = ?", staffUserID).First(&staff).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch staff user: %w", err)
+	}
+
+	message := models.ApplicantMessage{
+		ApplicationID: application.ID,
+		ApplicantID:   application.ApplicantID,
+		SenderType:    models.ApplicantMessageFromStaff,
+		StaffUserID:   &staff.ID,
+		SenderName:    strings.TrimSpace(staff.FirstName + " " + staff.LastName),
+		Content:       content,
+	}
+	if err := ar.DB.Create(&message).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to save staff message: %w", err)
+	}
+	return &message, &application, nil
+}
@@ -1,6 +1,7 @@
 package routes
 
 import (
+	address_services "town-planning-backend/addresses/services"
 	controllers "town-planning-backend/applicants/controllers"
 	"town-planning-backend/applicants/repositories"
 	indexing_repository "town-planning-backend/bleve/repositories"
@@ -14,11 +15,13 @@ func ApplicantInitRoutes(
 	applicantRepo repositories.ApplicantRepository,
 	bleveInterfaceRepo indexing_repository.BleveRepositoryInterface,
 	db *gorm.DB,
+	addressService *address_services.AddressService,
 ) {
 	applicantController := &controllers.ApplicantController{
-		ApplicantRepo: applicantRepo,
-		DB:            db,
-		BleveRepo:     bleveInterfaceRepo,
+		ApplicantRepo:  applicantRepo,
+		DB:             db,
+		BleveRepo:      bleveInterfaceRepo,
+		AddressService: addressService,
 	}
 
 	// Create API v1 group
@@ -26,6 +29,8 @@ func ApplicantInitRoutes(
 
 	api.Post("/applicants", applicantController.CreateApplicantController)
 	api.Get("/applicants/filtered", applicantController.GetFilteredApplicantsController)
+	api.Get("/applicants/:id", applicantController.GetApplicantProfileController)
+	api.Patch("/applicants/:id/preferred-language", applicantController.UpdateApplicantLanguageController)
 	api.Post("/applicants/vat-rates", applicantController.CreateVATRateController)
 	api.Get("/applicants/vat-rates/filtered", applicantController.GetFilteredVatRatesController)
 	api.Get("/applicants/vat-rates/active", applicantController.GetActiveVATRateController)
@@ -0,0 +1,69 @@
+package routes
+
+import (
+	"context"
+	controllers "town-planning-backend/applicants/controllers"
+	"town-planning-backend/applicants/repositories"
+	"town-planning-backend/applicants/services"
+	application_services "town-planning-backend/applications/services"
+	documents_services "town-planning-backend/documents/services"
+	"town-planning-backend/middleware"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// PortalInitRoutes registers the applicant portal. Portal routes live outside /api/v1 so they
+// never pass through the staff middleware, and each group requires its own token scope.
+func PortalInitRoutes(
+	app *fiber.App,
+	db *gorm.DB,
+	applicantRepo repositories.ApplicantRepository,
+	documentService *documents_services.DocumentService,
+	tokenMaker token.Maker,
+	redisClient *redis.Client,
+	ctx context.Context,
+	baseFrontendURL string,
+) {
+	portalController := &controllers.PortalController{
+		ApplicantRepo:   applicantRepo,
+		DB:              db,
+		DocumentSvc:     documentService,
+		TokenMaker:      tokenMaker,
+		RedisClient:     redisClient,
+		Ctx:             ctx,
+		LoginService:    services.NewPortalLoginService(redisClient, ctx, baseFrontendURL),
+		FrontendBaseURL: baseFrontendURL,
+		Estimator:       application_services.NewProcessingEstimator(db),
+	}
+
+	appContext := &middleware.AppContext{
+		PasetoMaker: tokenMaker,
+		Ctx:         ctx,
+		RedisClient: redisClient,
+	}
+
+	// Sign-in routes are public and must be registered before the portal middleware
+	app.Post("/portal/auth/request-link", portalController.RequestPortalLinkController)
+	app.Get("/portal/auth/verify", portalController.VerifyPortalLinkController)
+
+	portal := app.Group("/portal", middleware.ApplicantPortalRoute(appContext))
+	portal.Post("/auth/logout", portalController.PortalLogoutController)
+	portal.Get("/me", portalController.GetPortalProfileController)
+
+	applications := portal.Group("/applications")
+	applications.Get("/", middleware.RequireScope(token.ScopeApplicationsRead), portalController.GetPortalApplicationsController)
+	applications.Get("/:id", middleware.RequireScope(token.ScopeApplicationsRead), portalController.GetPortalApplicationController)
+	applications.Post("/:id/documents", middleware.Upload(), middleware.RequireScope(token.ScopeDocumentsUpload), portalController.UploadPortalDocumentController)
+	applications.Get("/:id/messages", middleware.RequireScope(token.ScopeChatStaff), portalController.GetPortalMessagesController)
+	applications.Post("/:id/messages", middleware.RequireScope(token.ScopeChatStaff), portalController.SendPortalMessageController)
+
+	// Staff side of the portal conversation, behind the staff middleware on /api/v1
+	api := app.Group("/api/v1")
+	api.Get("/applications/:id/applicant-messages", portalController.GetApplicantMessagesController)
+	api.Post("/applications/:id/applicant-messages", portalController.SendApplicantMessageController)
+	api.Get("/applications/:id/document-requests", portalController.GetDocumentRequestsController)
+	api.Post("/applications/:id/document-requests", portalController.RequestOutstandingDocumentsController)
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// PortalLinkTTL is how long an emailed portal sign-in link can be used
+const PortalLinkTTL = 15 * time.Minute
+
+// DocumentUploadLinkTTL is how long the upload links in a request for outstanding documents can
+// be used. Applicants often gather documents over several days, so they outlive sign-in links.
+const DocumentUploadLinkTTL = 7 * 24 * time.Hour
+
+// PortalSessionDuration is how long an applicant stays signed in to the portal
+const PortalSessionDuration = 12 * time.Hour
+
+// PortalLoginService issues the one-time links applicants sign in to the portal with. Applicants
+// have no passwords; owning the email address on their record is what proves who they are.
+type PortalLoginService struct {
+	redisClient     *redis.Client
+	ctx             context.Context
+	frontendBaseURL string
+}
+
+func NewPortalLoginService(redisClient *redis.Client, ctx context.Context, frontendBaseURL string) *PortalLoginService {
+	return &PortalLoginService{
+		redisClient:     redisClient,
+		ctx:             ctx,
+		frontendBaseURL: frontendBaseURL,
+	}
+}
+
+// CreateLoginLink stores a one-time token for the applicant and returns the URL to email them
+func (pls *PortalLoginService) CreateLoginLink(applicantID uuid.UUID) (string, error) {
+	token, err := pls.storeLinkToken(applicantID, PortalLinkTTL)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/portal/sign-in?token=%s", pls.frontendBaseURL, token), nil
+}
+
+// CreateDocumentUploadLink returns a one-time sign-in link that lands the applicant on the
+// upload form of their application, with the document category chosen
+func (pls *PortalLoginService) CreateDocumentUploadLink(applicantID uuid.UUID, applicationID uuid.UUID, categoryCode string) (string, error) {
+	token, err := pls.storeLinkToken(applicantID, DocumentUploadLinkTTL)
+	if err != nil {
+		return "", err
+	}
+	next := fmt.Sprintf("/portal/applications/%s/documents?category=%s", applicationID, url.QueryEscape(categoryCode))
+	return fmt.Sprintf("%s/portal/sign-in?token=%s&next=%s", pls.frontendBaseURL, token, url.QueryEscape(next)), nil
+}
+
+func (pls *PortalLoginService) storeLinkToken(applicantID uuid.UUID, ttl time.Duration) (string, error) {
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", fmt.Errorf("failed to generate portal link token: %w", err)
+	}
+	token := base64.URLEncoding.EncodeToString(tokenBytes)
+
+	if err := pls.redisClient.Set(pls.ctx, "portal_link:"+token, applicantID.String(), ttl).Err(); err != nil {
+		return "", fmt.Errorf("failed to store portal link: %w", err)
+	}
+	return token, nil
+}
+
+// ConsumeLoginLink returns the applicant a link was issued to. The token is deleted as it is
+// read, so each link signs in once.
+func (pls *PortalLoginService) ConsumeLoginLink(token string) (uuid.UUID, error) {
+	if token == "" {
+		return uuid.Nil, errors.New("invalid or expired link")
+	}
+	value, err := pls.redisClient.GetDel(pls.ctx, "portal_link:"+token).Result()
+	if err == redis.Nil {
+		return uuid.Nil, errors.New("invalid or expired link")
+	}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to read portal link: %w", err)
+	}
+
+	applicantID, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, errors.New("invalid or expired link")
+	}
+	return applicantID, nil
+}
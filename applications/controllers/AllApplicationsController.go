@@ -3,20 +3,32 @@ package controllers
 import (
 	applicant_repository "town-planning-backend/applicants/repositories"
 	"town-planning-backend/applications/repositories"
+	application_services "town-planning-backend/applications/services"
 	indexing_repository "town-planning-backend/bleve/repositories"
 	documents_services "town-planning-backend/documents/services"
 	user_repository "town-planning-backend/users/repositories"
+	"town-planning-backend/utils"
 	websocket "town-planning-backend/websocket"
 
 	"gorm.io/gorm"
 )
 
 type ApplicationController struct {
-	ApplicationRepo repositories.ApplicationRepository
-	ApplicantRepo   applicant_repository.ApplicantRepository
-	DB              *gorm.DB
-	BleveRepo       indexing_repository.BleveRepositoryInterface
-	UserRepo        user_repository.UserRepository
-	DocumentSvc     *documents_services.DocumentService
-	WsHub           *websocket.Hub // Added WebSocket hub for real-time features
+	ApplicationRepo   repositories.ApplicationRepository
+	ApplicantRepo     applicant_repository.ApplicantRepository
+	DB                *gorm.DB
+	BleveRepo         indexing_repository.BleveRepositoryInterface
+	UserRepo          user_repository.UserRepository
+	DocumentSvc       *documents_services.DocumentService
+	WsHub             *websocket.Hub // Added WebSocket hub for real-time features
+	ReadReceiptSvc    *application_services.ReadReceiptService
+	RatesClearanceSvc *application_services.RatesClearanceService
+	DeedsRegistrySvc  *application_services.DeedsRegistryService
+	BoundaryValidator *application_services.BoundaryValidator
+	PackStorage       utils.FileStorage // Generated committee packs, not served statically
+	Estimator         *application_services.ProcessingEstimator
+	LinkPreviewSvc    *application_services.LinkPreviewService
+
+	// Replays approve, reject and revoke results for repeated idempotency keys
+	DecisionIdempotency *application_services.DecisionIdempotency
 }
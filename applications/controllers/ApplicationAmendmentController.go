@@ -0,0 +1,580 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"town-planning-backend/applications/requests"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	documents_requests "town-planning-backend/documents/requests"
+	documents_services "town-planning-backend/documents/services"
+	planningscheme_repositories "town-planning-backend/planningschemes/repositories"
+	"town-planning-backend/token"
+	"town-planning-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ReviewApplicationAmendmentRequest is the body for approving or rejecting an amendment
+type ReviewApplicationAmendmentRequest struct {
+	Comment *string `json:"comment"`
+	Reason  string  `json:"reason"`
+}
+
+// amendmentErrorStatus maps amendment repository errors to HTTP status codes
+func amendmentErrorStatus(err error) int {
+	switch err.Error() {
+	case "application not found", "amendment not found":
+		return fiber.StatusNotFound
+	case "amendment cannot be approved by the officer who requested it":
+		return fiber.StatusForbidden
+	case "only approved applications can be amended", "application has no final approval",
+		"application has no tariff to price the amendment", "payment amount must equal the amendment fee":
+		return fiber.StatusBadRequest
+	case "permit is suspended", "permit is revoked", "an amendment is already open for this application",
+		"amendment is not awaiting payment", "amendment fee has not been paid", "amendment has already been reviewed":
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// RequestApplicationAmendmentController opens a minor amendment to an approved application.
+// Expects multipart form data with the variation under "description" and the revised plan under
+// "document". The response carries the reduced fee to collect before the amendment is approved.
+func (ac *ApplicationController) RequestApplicationAmendmentController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	description := strings.TrimSpace(c.FormValue("description"))
+	if description == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "A description of the variation is required",
+		})
+	}
+
+	fileHeader, err := c.FormFile("document")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "A revised plan document is required",
+			"error":   err.Error(),
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	application, approval, err := ac.ApplicationRepo.ValidateApplicationAmendment(tx, applicationID)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(amendmentErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": fmt.Sprintf("Cannot amend application: %s", err.Error()),
+			"error":   err.Error(),
+		})
+	}
+
+	createdBy := payload.UserID.String()
+
+	documentResponse, err := ac.DocumentSvc.UnifiedCreateDocument(tx, c, &documents_requests.CreateDocumentRequest{
+		FileName:      fileHeader.Filename,
+		CategoryCode:  "AMENDED_PLAN",
+		ApplicationID: &application.ID,
+		ApplicantID:   &application.ApplicantID,
+		CreatedBy:     createdBy,
+	}, nil, fileHeader)
+	if err != nil {
+		tx.Rollback()
+		config.Logger.Error("Failed to store revised plan",
+			zap.Error(err),
+			zap.String("applicationID", applicationID.String()))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to store revised plan",
+			"error":   err.Error(),
+		})
+	}
+
+	amendment, err := ac.ApplicationRepo.CreateApplicationAmendment(tx, application, &models.ApplicationAmendment{
+		ParentApprovalID:      approval.ID,
+		Description:           description,
+		RevisedPlanDocumentID: documentResponse.ID,
+		RequestedByID:         payload.UserID,
+		CreatedBy:             createdBy,
+	})
+	if err != nil {
+		tx.Rollback()
+		return c.Status(amendmentErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to create amendment request",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not commit database transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("Application amendment requested",
+		zap.String("applicationID", applicationID.String()),
+		zap.String("amendmentID", amendment.ID.String()),
+		zap.String("totalFee", amendment.TotalFee.String()),
+		zap.String("requestedBy", createdBy))
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Amendment request submitted",
+		"data":    amendment,
+	})
+}
+
+// GetApplicationAmendmentsController returns the amendments made to an application
+func (ac *ApplicationController) GetApplicationAmendmentsController(c *fiber.Ctx) error {
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	amendments, err := ac.ApplicationRepo.GetApplicationAmendments(applicationID)
+	if err != nil {
+		config.Logger.Error("Failed to fetch application amendments",
+			zap.Error(err),
+			zap.String("applicationID", applicationID.String()))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch application amendments",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Application amendments retrieved successfully",
+		"data":    amendments,
+	})
+}
+
+// RecordAmendmentPaymentController records payment of an amendment's fee
+func (ac *ApplicationController) RecordAmendmentPaymentController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	amendmentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid amendment ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var request requests.RecordAmendmentPaymentRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+	if request.ReceiptNumber == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Receipt number is required",
+			"error":   "missing_receipt_number",
+		})
+	}
+	if request.PaymentDate != nil && request.PaymentDate.After(time.Now()) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Payment date cannot be in the future",
+			"error":   "invalid_payment_date",
+		})
+	}
+
+	payment := models.Payment{
+		Amount:            request.Amount,
+		PaymentMethod:     request.PaymentMethod,
+		ReceiptNumber:     request.ReceiptNumber,
+		ExternalReference: request.ExternalReference,
+		BankAccountID:     request.BankAccountID,
+		Notes:             request.Notes,
+		CreatedBy:         payload.UserID.String(),
+	}
+	if payment.PaymentMethod == "" {
+		payment.PaymentMethod = models.CashPaymentMethod
+	}
+	if request.PaymentDate != nil {
+		payment.PaymentDate = *request.PaymentDate
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	amendment, err := ac.ApplicationRepo.RecordAmendmentPayment(tx, amendmentID, &payment)
+	if err != nil {
+		tx.Rollback()
+		if handled, response := ac.respondToDuplicatePayment(c, err); handled {
+			return response
+		}
+		return c.Status(amendmentErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to record amendment payment",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Amendment fee recorded, amendment is awaiting approval",
+		"data": fiber.Map{
+			"amendment": amendment,
+			"payment":   payment,
+		},
+	})
+}
+
+// ApproveApplicationAmendmentController signs off an amendment and re-issues the permit with the
+// variation noted. A single approver with the application.approve permission is enough; the
+// approval groups are not involved.
+func (ac *ApplicationController) ApproveApplicationAmendmentController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	amendmentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid amendment ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var request ReviewApplicationAmendmentRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": "Invalid request payload",
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	user, err := ac.UserRepo.GetUserProfileByID(payload.UserID.String())
+	if err != nil {
+		config.Logger.Error("Failed to get user by UUID", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to get user by UUID",
+			"error":   err.Error(),
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	amendment, err := ac.ApplicationRepo.ApproveApplicationAmendment(tx, amendmentID, payload.UserID, request.Comment)
+	if err != nil {
+		tx.Rollback()
+		config.Logger.Error("Failed to approve application amendment",
+			zap.Error(err),
+			zap.String("amendmentID", amendmentID.String()),
+			zap.String("userID", payload.UserID.String()))
+		return c.Status(amendmentErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": fmt.Sprintf("Failed to approve amendment: %s", err.Error()),
+			"error":   err.Error(),
+		})
+	}
+
+	response, filename, err := ac.issueAmendedPermit(tx, c, amendment, user)
+	if err != nil {
+		tx.Rollback()
+		config.Logger.Error("Failed to issue amended permit",
+			zap.Error(err),
+			zap.String("amendmentID", amendmentID.String()))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to issue amended permit",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := ac.ApplicationRepo.RecordAmendmentPermit(tx, amendment.ID, response.ID); err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to record amended permit",
+			"error":   err.Error(),
+		})
+	}
+	amendment.PermitDocumentID = &response.ID
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not commit database transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("Application amendment approved",
+		zap.String("amendmentID", amendment.ID.String()),
+		zap.String("applicationID", amendment.ApplicationID.String()),
+		zap.Int("amendmentNumber", amendment.AmendmentNumber),
+		zap.String("approvedBy", payload.UserID.String()))
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Amendment approved and amended permit issued",
+		"data": fiber.Map{
+			"amendment": amendment,
+			"permit": fiber.Map{
+				"document_id":  response.ID,
+				"filename":     filename,
+				"file_path":    response.Document.FilePath,
+				"generated_at": time.Now().Format(time.RFC3339),
+			},
+		},
+	})
+}
+
+// RejectApplicationAmendmentController rejects an open amendment
+func (ac *ApplicationController) RejectApplicationAmendmentController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	amendmentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid amendment ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var request ReviewApplicationAmendmentRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+
+	if strings.TrimSpace(request.Reason) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Rejection reason is required",
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	amendment, err := ac.ApplicationRepo.RejectApplicationAmendment(tx, amendmentID, payload.UserID, request.Reason)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(amendmentErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": fmt.Sprintf("Failed to reject amendment: %s", err.Error()),
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not commit database transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("Application amendment rejected",
+		zap.String("amendmentID", amendmentID.String()),
+		zap.String("rejectedBy", payload.UserID.String()))
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Amendment rejected",
+		"data":    amendment,
+	})
+}
+
+// issueAmendedPermit generates the permit with the amendment noted and stores it as a new
+// version of the DEVELOPMENT_PERMIT document
+func (ac *ApplicationController) issueAmendedPermit(
+	tx *gorm.DB,
+	c *fiber.Ctx,
+	amendment *models.ApplicationAmendment,
+	user *models.User,
+) (*documents_services.CreateDocumentResponse, string, error) {
+	var application models.Application
+	if err := tx.
+		Preload("Applicant").
+		Preload("CoApplicants.Applicant").
+		Preload("Stand.StandType").
+		Preload("Tariff.DevelopmentCategory").
+		Preload("VATRate").
+		Preload("FinalApprover").
+		First(&application, "id = ?", amendment.ApplicationID).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to load application: %w", err)
+	}
+
+	createdBy := user.ID.String()
+	if _, err := ac.ApplicationRepo.IssuePermit(tx, &application, createdBy); err != nil {
+		return nil, "", err
+	}
+
+	var approval models.FinalApproval
+	if err := tx.
+		Preload("Approver").
+		Preload("Approver.Department").
+		Preload("PlanningSchemeVersions.Scheme").
+		Where("id = ?", amendment.ParentApprovalID).
+		First(&approval).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to load parent approval: %w", err)
+	}
+	if len(approval.PlanningSchemeVersions) == 0 {
+		versions, err := planningscheme_repositories.VersionsInEffect(tx, application.SubmissionDate)
+		if err != nil {
+			config.Logger.Warn("Failed to load planning schemes in effect",
+				zap.String("applicationID", application.ID.String()),
+				zap.Error(err))
+		}
+		approval.PlanningSchemeVersions = versions
+	}
+
+	filename := fmt.Sprintf("AMENDED_DEVELOPMENT_PERMIT_%d_%s_%s.pdf",
+		amendment.AmendmentNumber,
+		cleanStringForFilename(application.Applicant.FullName),
+		time.Now().Format("20060102_150405"))
+
+	pdfPath, err := utils.GenerateAmendedDevelopmentPermit(application, approval, *amendment, filename, user)
+	if err != nil {
+		return nil, "", err
+	}
+	defer os.Remove(pdfPath)
+
+	pdfBytes, err := os.ReadFile(pdfPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read generated permit: %w", err)
+	}
+	if len(pdfBytes) == 0 {
+		return nil, "", errors.New("generated permit PDF is empty")
+	}
+
+	response, err := ac.DocumentSvc.UnifiedCreateDocument(tx, c, &documents_requests.CreateDocumentRequest{
+		CategoryCode:  "DEVELOPMENT_PERMIT",
+		FileName:      filename,
+		ApplicationID: &application.ID,
+		ApplicantID:   &application.ApplicantID,
+		CreatedBy:     createdBy,
+		FileType:      "application/pdf",
+	}, pdfBytes, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if response == nil || response.Document == nil {
+		return nil, "", errors.New("document service returned invalid response")
+	}
+
+	return response, filename, nil
+}
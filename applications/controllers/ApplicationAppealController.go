@@ -0,0 +1,450 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"town-planning-backend/applications/requests"
+	application_services "town-planning-backend/applications/services"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// appealErrorStatus maps appeal repository errors to HTTP status codes
+func appealErrorStatus(err error) int {
+	switch err.Error() {
+	case "application not found", "appeal not found", "appeals group not found":
+		return fiber.StatusNotFound
+	case "only members of the appeals group can handle this appeal":
+		return fiber.StatusForbidden
+	case "only rejected applications can be appealed", "application has no final rejection",
+		"the appeal fee must be paid when lodging", "payment amount must equal the appeal fee",
+		"appeals must be heard by a group other than the one that reviewed the application":
+		return fiber.StatusBadRequest
+	case "the appeal window has closed", "an appeal is already open for this application",
+		"appeal has not been heard yet", "appeal has already been decided":
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// LodgeApplicationAppealController lodges an appeal against the rejection of an application.
+// The appeal goes to an appeals group, never the group that rejected the application, and the
+// appeal fee, if the council charges one, is recorded with it.
+func (ac *ApplicationController) LodgeApplicationAppealController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var request requests.LodgeApplicationAppealRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+
+	grounds := strings.TrimSpace(request.Grounds)
+	if grounds == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Grounds of appeal are required",
+		})
+	}
+	if request.PaymentDate != nil && request.PaymentDate.After(time.Now()) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Payment date cannot be in the future",
+			"error":   "invalid_payment_date",
+		})
+	}
+
+	policy := application_services.LoadAppealPolicy()
+	createdBy := payload.UserID.String()
+
+	var payment *models.Payment
+	if policy.Fee.IsPositive() {
+		if request.ReceiptNumber == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": "Receipt number for the appeal fee is required",
+				"error":   "missing_receipt_number",
+			})
+		}
+		payment = &models.Payment{
+			Amount:            request.Amount,
+			PaymentMethod:     request.PaymentMethod,
+			ReceiptNumber:     request.ReceiptNumber,
+			ExternalReference: request.ExternalReference,
+			BankAccountID:     request.BankAccountID,
+			CreatedBy:         createdBy,
+		}
+		if payment.PaymentMethod == "" {
+			payment.PaymentMethod = models.CashPaymentMethod
+		}
+		if request.PaymentDate != nil {
+			payment.PaymentDate = *request.PaymentDate
+		}
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	application, rejection, group, err := ac.ApplicationRepo.ValidateApplicationAppeal(tx, applicationID, request.AppealsGroupID, policy.Window)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(appealErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": fmt.Sprintf("Cannot appeal application: %s", err.Error()),
+			"error":   err.Error(),
+		})
+	}
+
+	appeal := &models.ApplicationAppeal{
+		ApplicationID:       application.ID,
+		RejectionApprovalID: rejection.ID,
+		Grounds:             grounds,
+		Fee:                 policy.Fee,
+		AppealsGroupID:      group.ID,
+		LodgedByID:          payload.UserID,
+		CreatedBy:           createdBy,
+	}
+	if application.Tariff != nil {
+		appeal.Currency = application.Tariff.Currency
+	}
+	if payment != nil {
+		payment.TariffID = application.TariffID
+	}
+
+	appeal, err = ac.ApplicationRepo.CreateApplicationAppeal(tx, appeal, payment)
+	if err != nil {
+		tx.Rollback()
+		if handled, response := ac.respondToDuplicatePayment(c, err); handled {
+			return response
+		}
+		return c.Status(appealErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to lodge appeal",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not commit database transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("Application appeal lodged",
+		zap.String("applicationID", applicationID.String()),
+		zap.String("appealID", appeal.ID.String()),
+		zap.String("appealsGroupID", group.ID.String()),
+		zap.String("lodgedBy", createdBy))
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": fmt.Sprintf("Appeal lodged with %s", group.Name),
+		"data":    appeal,
+	})
+}
+
+// GetApplicationAppealsController returns the appeals lodged against an application's rejections
+func (ac *ApplicationController) GetApplicationAppealsController(c *fiber.Ctx) error {
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	appeals, err := ac.ApplicationRepo.GetApplicationAppeals(applicationID)
+	if err != nil {
+		config.Logger.Error("Failed to fetch application appeals",
+			zap.Error(err),
+			zap.String("applicationID", applicationID.String()))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch application appeals",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Application appeals retrieved successfully",
+		"data":    appeals,
+	})
+}
+
+// ScheduleAppealHearingController sets the hearing date of an appeal, postponing any hearing
+// already set. Only members of the appeals group hearing the appeal can schedule it.
+func (ac *ApplicationController) ScheduleAppealHearingController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	appealID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid appeal ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var request requests.ScheduleAppealHearingRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+	if !request.ScheduledAt.After(time.Now()) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Hearing must be scheduled in the future",
+			"error":   "invalid_hearing_date",
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	appeal, err := ac.ApplicationRepo.ScheduleAppealHearing(tx, appealID, payload.UserID, &models.AppealHearing{
+		ScheduledAt: request.ScheduledAt,
+		Venue:       request.Venue,
+		Notes:       request.Notes,
+		CreatedBy:   payload.UserID.String(),
+	})
+	if err != nil {
+		tx.Rollback()
+		return c.Status(appealErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": fmt.Sprintf("Failed to schedule hearing: %s", err.Error()),
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not commit database transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("Appeal hearing scheduled",
+		zap.String("appealID", appealID.String()),
+		zap.Time("scheduledAt", request.ScheduledAt),
+		zap.String("scheduledBy", payload.UserID.String()))
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Appeal hearing scheduled",
+		"data":    appeal,
+	})
+}
+
+// DecideApplicationAppealController records the outcome of an appeal hearing. An upheld appeal
+// sets the rejection aside and reopens the application for review by the group that reviewed it,
+// with the appeal linked to the new assignment.
+func (ac *ApplicationController) DecideApplicationAppealController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	appealID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid appeal ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var request requests.DecideApplicationAppealRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+	if request.Outcome != models.AppealStatusUpheld && request.Outcome != models.AppealStatusDismissed {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Outcome must be UPHELD or DISMISSED",
+			"error":   "invalid_outcome",
+		})
+	}
+	reason := strings.TrimSpace(request.Reason)
+	if reason == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "A reason for the appeal decision is required",
+		})
+	}
+
+	user, err := ac.UserRepo.GetUserProfileByID(payload.UserID.String())
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not found",
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	upheld := request.Outcome == models.AppealStatusUpheld
+	appeal, err := ac.ApplicationRepo.DecideApplicationAppeal(tx, appealID, payload.UserID, upheld, reason)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(appealErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": fmt.Sprintf("Failed to decide appeal: %s", err.Error()),
+			"error":   err.Error(),
+		})
+	}
+
+	if upheld {
+		var application models.Application
+		if err := tx.Select("id", "assigned_group_id").First(&application, "id = ?", appeal.ApplicationID).Error; err != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"message": "Failed to load appealed application",
+				"error":   err.Error(),
+			})
+		}
+		if application.AssignedGroupID == nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"success": false,
+				"message": "Application has no review group to reopen it with",
+			})
+		}
+
+		reopenReason := fmt.Sprintf("Appeal upheld: %s", reason)
+		assignment, err := ac.ApplicantRepo.AssignApplicationToGroup(tx, application.ID.String(), *application.AssignedGroupID, user.Email, &reopenReason, payload.UserID)
+		if err != nil {
+			tx.Rollback()
+			config.Logger.Error("Failed to reopen application after upheld appeal",
+				zap.Error(err),
+				zap.String("appealID", appealID.String()))
+			if quorumErr := quorumNotMet(err); quorumErr != nil {
+				return c.Status(fiber.StatusConflict).JSON(quorumConflictBody("Review group cannot decide applications right now", quorumErr))
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"message": "Failed to reopen application for review",
+				"error":   err.Error(),
+			})
+		}
+		if err := ac.ApplicationRepo.RecordAppealReopening(tx, appeal.ID, assignment.ID); err != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"message": "Failed to link appeal to the reopened review",
+				"error":   err.Error(),
+			})
+		}
+		appeal.ReopenedAssignmentID = &assignment.ID
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not commit database transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("Application appeal decided",
+		zap.String("appealID", appeal.ID.String()),
+		zap.String("applicationID", appeal.ApplicationID.String()),
+		zap.String("outcome", string(appeal.Status)),
+		zap.String("decidedBy", payload.UserID.String()))
+
+	message := "Appeal dismissed, the rejection stands"
+	if upheld {
+		message = "Appeal upheld, application reopened for review"
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": message,
+		"data":    appeal,
+	})
+}
@@ -0,0 +1,306 @@
+package controllers
+
+import (
+	"os"
+	"strings"
+	"time"
+	"town-planning-backend/applications/requests"
+	application_services "town-planning-backend/applications/services"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+// applicationArchivalSchedule moves closed applications to cold storage nightly, out of hours
+const applicationArchivalSchedule = "0 2 * * *"
+
+// processApplicationArchival archives one batch of terminal applications that closed longer ago
+// than the archive policy allows
+func (ac *ApplicationController) processApplicationArchival() {
+	policy := application_services.LoadArchivePolicy()
+	now := time.Now()
+
+	applications, err := ac.ApplicationRepo.GetArchivableApplications(now.Add(-policy.After), now.Add(-policy.RehydrationHold), policy.BatchSize)
+	if err != nil {
+		config.Logger.Error("Failed to fetch applications for archival", zap.Error(err))
+		return
+	}
+
+	archived := 0
+	for _, application := range applications {
+		if _, err := ac.archiveApplication(application.ID, "system"); err != nil {
+			config.Logger.Error("Failed to archive application",
+				zap.Error(err),
+				zap.String("applicationID", application.ID.String()),
+				zap.String("planNumber", application.PlanNumber))
+			continue
+		}
+		archived++
+	}
+
+	if len(applications) > 0 {
+		config.Logger.Info("Application archival completed",
+			zap.Int("candidates", len(applications)),
+			zap.Int("archived", archived))
+	}
+}
+
+// archiveApplication compresses the application's own documents and marks it archived. The
+// originals are only removed once the archive is committed, so a failure leaves the application
+// as it was.
+func (ac *ApplicationController) archiveApplication(applicationID uuid.UUID, archivedBy string) (*models.ApplicationArchive, error) {
+	documents, err := ac.ApplicationRepo.GetArchivableDocuments(applicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	archive := &models.ApplicationArchive{
+		ID:            uuid.New(),
+		ApplicationID: applicationID,
+		ArchivedAt:    time.Now(),
+		ArchivedBy:    archivedBy,
+	}
+	removeCompressed := func() {
+		for _, document := range archive.Documents {
+			os.Remove(document.ArchivePath)
+		}
+	}
+
+	for _, document := range documents {
+		archivePath, originalSize, archivedSize, err := application_services.CompressArchiveFile(document.FilePath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				// Nothing on disk to compress; the document record is kept as it is
+				config.Logger.Warn("Archived application document is missing from storage",
+					zap.String("applicationID", applicationID.String()),
+					zap.String("documentID", document.ID.String()),
+					zap.String("path", document.FilePath))
+				continue
+			}
+			removeCompressed()
+			return nil, err
+		}
+		archive.Documents = append(archive.Documents, models.ApplicationArchiveDocument{
+			ArchiveID:    archive.ID,
+			DocumentID:   document.ID,
+			OriginalPath: document.FilePath,
+			ArchivePath:  archivePath,
+			OriginalSize: originalSize,
+			ArchivedSize: archivedSize,
+		})
+		archive.OriginalBytes += originalSize
+		archive.ArchivedBytes += archivedSize
+	}
+	archive.DocumentCount = len(archive.Documents)
+
+	tx := ac.DB.Begin()
+	if tx.Error != nil {
+		removeCompressed()
+		return nil, tx.Error
+	}
+	if err := ac.ApplicationRepo.RecordApplicationArchive(tx, archive); err != nil {
+		tx.Rollback()
+		removeCompressed()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		removeCompressed()
+		return nil, err
+	}
+
+	for _, document := range archive.Documents {
+		if err := os.Remove(document.OriginalPath); err != nil && !os.IsNotExist(err) {
+			config.Logger.Warn("Failed to remove archived document original",
+				zap.Error(err),
+				zap.String("path", document.OriginalPath))
+		}
+	}
+
+	config.Logger.Info("Application archived",
+		zap.String("applicationID", applicationID.String()),
+		zap.Int("documents", archive.DocumentCount),
+		zap.Int64("originalBytes", archive.OriginalBytes),
+		zap.Int64("archivedBytes", archive.ArchivedBytes))
+	return archive, nil
+}
+
+// RunApplicationArchival moves closed applications to cold storage
+func (ac *ApplicationController) RunApplicationArchival() {
+	c := cron.New()
+
+	c.AddFunc(applicationArchivalSchedule, ac.processApplicationArchival)
+
+	c.Start()
+
+	// Keep running so the cron jobs can execute
+	select {}
+}
+
+// RehydrateApplicationController restores an archived application on demand, e.g. for a legal
+// query: its documents are decompressed and it returns to the default lists. Rehydrated
+// applications are not archived again until the rehydration hold has passed.
+func (ac *ApplicationController) RehydrateApplicationController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var request requests.RehydrateApplicationRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+	reason := strings.TrimSpace(request.Reason)
+	if reason == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Validation failed",
+			"error":   "A reason is required to rehydrate an archived application",
+		})
+	}
+
+	archive, err := ac.ApplicationRepo.GetOpenApplicationArchive(applicationID)
+	if err != nil {
+		statusCode := fiber.StatusInternalServerError
+		if err.Error() == "application is not archived" {
+			statusCode = fiber.StatusConflict
+		}
+		return c.Status(statusCode).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to rehydrate application",
+			"error":   err.Error(),
+		})
+	}
+
+	// Decompress first; the compressed copies are kept until the rehydration is committed
+	restored := []string{}
+	removeRestored := func() {
+		for _, path := range restored {
+			os.Remove(path)
+		}
+	}
+	for _, document := range archive.Documents {
+		if err := application_services.RestoreArchiveFile(document.ArchivePath, document.OriginalPath); err != nil {
+			removeRestored()
+			config.Logger.Error("Failed to restore archived document",
+				zap.Error(err),
+				zap.String("applicationID", applicationID.String()),
+				zap.String("documentID", document.DocumentID.String()))
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"message": "Failed to restore archived documents",
+				"error":   err.Error(),
+			})
+		}
+		restored = append(restored, document.OriginalPath)
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		removeRestored()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	if err := ac.ApplicationRepo.RecordApplicationRehydration(tx, archive, payload.UserID, reason); err != nil {
+		tx.Rollback()
+		removeRestored()
+		statusCode := fiber.StatusInternalServerError
+		if err.Error() == "application is not archived" {
+			statusCode = fiber.StatusConflict
+		}
+		return c.Status(statusCode).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to rehydrate application",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		removeRestored()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	for _, document := range archive.Documents {
+		if err := os.Remove(document.ArchivePath); err != nil && !os.IsNotExist(err) {
+			config.Logger.Warn("Failed to remove compressed copy of rehydrated document",
+				zap.Error(err),
+				zap.String("path", document.ArchivePath))
+		}
+	}
+
+	config.Logger.Info("Archived application rehydrated",
+		zap.String("applicationID", applicationID.String()),
+		zap.String("userID", payload.UserID.String()),
+		zap.Int("documents", len(archive.Documents)))
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Application rehydrated successfully",
+		"data":    archive,
+	})
+}
+
+// GetApplicationArchivesController lists when an application was archived and rehydrated
+func (ac *ApplicationController) GetApplicationArchivesController(c *fiber.Ctx) error {
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	archives, err := ac.ApplicationRepo.GetApplicationArchives(applicationID)
+	if err != nil {
+		config.Logger.Error("Failed to fetch application archives",
+			zap.Error(err),
+			zap.String("applicationID", applicationID.String()))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch archives",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Archives retrieved successfully",
+		"data":    archives,
+	})
+}
@@ -0,0 +1,392 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"town-planning-backend/applications/requests"
+	application_services "town-planning-backend/applications/services"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+// applicationDraftExpirySchedule expires stale drafts nightly
+const applicationDraftExpirySchedule = "0 3 * * *"
+
+// draftRequiredFields are the create-application fields a draft must have before it is submitted
+var draftRequiredFields = []string{
+	"plan_area",
+	"stand_id",
+	"applicant_id",
+	"assigned_group_id",
+	"tariff_id",
+	"property_type_id",
+	"development_levy",
+	"vat_amount",
+	"total_cost",
+	"estimated_cost",
+	"status",
+	"payment_status",
+	"created_by",
+}
+
+// CreateApplicationDraftController starts a draft with whatever has been entered so far
+func (ac *ApplicationController) CreateApplicationDraftController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	var request requests.SaveApplicationDraftRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+
+	data, err := application_services.MergeApplicationDraftData(nil, request.Data)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid draft data",
+			"error":   err.Error(),
+		})
+	}
+
+	now := time.Now()
+	draft := &models.ApplicationDraft{
+		ID:          uuid.New(),
+		OwnerID:     payload.UserID,
+		Label:       trimmedLabel(request.Label),
+		Status:      models.DraftApplicationDraft,
+		Data:        data,
+		Version:     1,
+		LastSavedAt: now,
+		ExpiresAt:   now.Add(application_services.LoadApplicationDraftPolicy().Expiry),
+	}
+	if err := ac.ApplicationRepo.CreateApplicationDraft(draft); err != nil {
+		config.Logger.Error("Failed to create application draft",
+			zap.Error(err),
+			zap.String("userID", payload.UserID.String()))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to save draft",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Draft saved",
+		"data":    draft,
+	})
+}
+
+// UpdateApplicationDraftController autosaves changes to a draft. Only the fields sent are
+// changed, and nothing is validated.
+func (ac *ApplicationController) UpdateApplicationDraftController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	draft, status, response := ac.openApplicationDraft(c, payload.UserID)
+	if response != nil {
+		return c.Status(status).JSON(response)
+	}
+
+	var request requests.SaveApplicationDraftRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+
+	data, err := application_services.MergeApplicationDraftData(draft.Data, request.Data)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid draft data",
+			"error":   err.Error(),
+		})
+	}
+
+	expectedVersion := draft.Version
+	if request.Version != nil {
+		expectedVersion = *request.Version
+	}
+
+	now := time.Now()
+	draft.Data = data
+	if request.Label != nil {
+		draft.Label = trimmedLabel(request.Label)
+	}
+	draft.LastSavedAt = now
+	draft.ExpiresAt = now.Add(application_services.LoadApplicationDraftPolicy().Expiry)
+
+	if err := ac.ApplicationRepo.SaveApplicationDraft(draft, expectedVersion); err != nil {
+		statusCode := fiber.StatusInternalServerError
+		if err.Error() == "application draft was changed by another save" {
+			statusCode = fiber.StatusConflict
+		}
+		return c.Status(statusCode).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to save draft",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Draft saved",
+		"data":    draft,
+	})
+}
+
+// GetMyApplicationDraftsController lists the user's open drafts
+func (ac *ApplicationController) GetMyApplicationDraftsController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	drafts, err := ac.ApplicationRepo.GetUserApplicationDrafts(payload.UserID)
+	if err != nil {
+		config.Logger.Error("Failed to fetch application drafts",
+			zap.Error(err),
+			zap.String("userID", payload.UserID.String()))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch drafts",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Drafts retrieved successfully",
+		"data":    drafts,
+	})
+}
+
+// GetApplicationDraftController returns one of the user's drafts
+func (ac *ApplicationController) GetApplicationDraftController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	draftID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid draft ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	draft, err := ac.ApplicationRepo.GetApplicationDraft(draftID, payload.UserID)
+	if err != nil {
+		statusCode := fiber.StatusInternalServerError
+		if err.Error() == "application draft not found" {
+			statusCode = fiber.StatusNotFound
+		}
+		return c.Status(statusCode).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch draft",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Draft retrieved successfully",
+		"data":    draft,
+	})
+}
+
+// DeleteApplicationDraftController discards one of the user's open drafts
+func (ac *ApplicationController) DeleteApplicationDraftController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	draftID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid draft ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	if err := ac.ApplicationRepo.DeleteApplicationDraft(draftID, payload.UserID); err != nil {
+		statusCode := fiber.StatusInternalServerError
+		if err.Error() == "application draft not found" {
+			statusCode = fiber.StatusNotFound
+		}
+		return c.Status(statusCode).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to discard draft",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Draft discarded",
+	})
+}
+
+// SubmitApplicationDraftController promotes a draft to a submitted application. The draft is
+// validated in full first and every problem is returned at once, so the form can be completed in
+// one go.
+func (ac *ApplicationController) SubmitApplicationDraftController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	draft, status, response := ac.openApplicationDraft(c, payload.UserID)
+	if response != nil {
+		return c.Status(status).JSON(response)
+	}
+
+	fields, err := application_services.ParseApplicationDraftData(draft.Data)
+	if err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"success": false,
+			"message": "Draft is incomplete",
+			"error":   err.Error(),
+		})
+	}
+
+	problems := []string{}
+	for _, name := range draftRequiredFields {
+		if value, ok := fields[name]; !ok || string(value) == "null" || string(value) == `""` {
+			problems = append(problems, fmt.Sprintf("%s is required", name))
+		}
+	}
+
+	var req CreateApplicationRequest
+	if len(problems) == 0 {
+		if err := json.Unmarshal(draft.Data, &req); err != nil {
+			problems = append(problems, err.Error())
+		} else {
+			problems = append(problems, validateCreateApplicationRequest(req)...)
+		}
+	}
+
+	if len(problems) > 0 {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"success": false,
+			"message": "Draft is incomplete",
+			"error":   "validation_failed",
+			"data":    fiber.Map{"errors": problems},
+		})
+	}
+
+	return ac.submitApplication(c, req, &draft.ID)
+}
+
+// openApplicationDraft loads the draft named in the route for changing or submitting. A draft
+// already submitted, or past its expiry, can no longer be changed.
+func (ac *ApplicationController) openApplicationDraft(c *fiber.Ctx, ownerID uuid.UUID) (*models.ApplicationDraft, int, fiber.Map) {
+	draftID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, fiber.StatusBadRequest, fiber.Map{
+			"success": false,
+			"message": "Invalid draft ID",
+			"error":   "invalid_uuid",
+		}
+	}
+
+	draft, err := ac.ApplicationRepo.GetApplicationDraft(draftID, ownerID)
+	if err != nil {
+		statusCode := fiber.StatusInternalServerError
+		if err.Error() == "application draft not found" {
+			statusCode = fiber.StatusNotFound
+		}
+		return nil, statusCode, fiber.Map{
+			"success": false,
+			"message": "Failed to fetch draft",
+			"error":   err.Error(),
+		}
+	}
+
+	if draft.Status != models.DraftApplicationDraft || !draft.ExpiresAt.After(time.Now()) {
+		return nil, fiber.StatusConflict, fiber.Map{
+			"success": false,
+			"message": "Draft can no longer be changed",
+			"error":   "application draft is no longer open",
+			"data":    draft,
+		}
+	}
+	return draft, 0, nil
+}
+
+// trimmedLabel tidies a draft label, dropping it when blank
+func trimmedLabel(label *string) *string {
+	if label == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*label)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+// processApplicationDraftExpiry marks drafts nobody has saved within the draft policy as expired
+func (ac *ApplicationController) processApplicationDraftExpiry() {
+	expired, err := ac.ApplicationRepo.ExpireApplicationDrafts(time.Now())
+	if err != nil {
+		config.Logger.Error("Failed to expire application drafts", zap.Error(err))
+		return
+	}
+	if expired > 0 {
+		config.Logger.Info("Expired stale application drafts", zap.Int64("count", expired))
+	}
+}
+
+// RunApplicationDraftExpiry expires stale application drafts
+func (ac *ApplicationController) RunApplicationDraftExpiry() {
+	c := cron.New()
+
+	c.AddFunc(applicationDraftExpirySchedule, ac.processApplicationDraftExpiry)
+
+	c.Start()
+
+	// Keep running so the cron jobs can execute
+	select {}
+}
@@ -0,0 +1,475 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	documents_requests "town-planning-backend/documents/requests"
+	documents_services "town-planning-backend/documents/services"
+	"town-planning-backend/token"
+	"town-planning-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ReviewApplicationTransferRequest is the body for approving or rejecting a transfer
+type ReviewApplicationTransferRequest struct {
+	Comment *string `json:"comment"`
+	Reason  string  `json:"reason"`
+}
+
+// RequestApplicationTransferController opens a transfer of an application to a new applicant.
+// Expects multipart form data with the sale agreement or title deed under "document".
+func (ac *ApplicationController) RequestApplicationTransferController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	toApplicantID, err := uuid.Parse(c.FormValue("to_applicant_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "A valid to_applicant_id is required",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	documentType := models.TransferSupportingDocumentType(strings.ToUpper(c.FormValue("supporting_document_type")))
+	if documentType != models.TransferDocumentSaleAgreement && documentType != models.TransferDocumentTitleDeed {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "supporting_document_type must be SALE_AGREEMENT or TITLE_DEED",
+		})
+	}
+
+	var newCategoryID *uuid.UUID
+	if raw := strings.TrimSpace(c.FormValue("new_development_category_id")); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": "Invalid new_development_category_id",
+				"error":   "invalid_uuid",
+			})
+		}
+		newCategoryID = &parsed
+	}
+
+	var reason *string
+	if raw := strings.TrimSpace(c.FormValue("reason")); raw != "" {
+		reason = &raw
+	}
+
+	fileHeader, err := c.FormFile("document")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "A sale agreement or title deed document is required",
+			"error":   err.Error(),
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	application, err := ac.ApplicationRepo.ValidateApplicationTransfer(tx, applicationID, toApplicantID)
+	if err != nil {
+		tx.Rollback()
+		statusCode := fiber.StatusInternalServerError
+		switch err.Error() {
+		case "application not found", "new applicant not found":
+			statusCode = fiber.StatusNotFound
+		case "application can no longer be transferred", "application already belongs to this applicant":
+			statusCode = fiber.StatusBadRequest
+		case "a transfer is already pending for this application":
+			statusCode = fiber.StatusConflict
+		}
+		return c.Status(statusCode).JSON(fiber.Map{
+			"success": false,
+			"message": fmt.Sprintf("Cannot transfer application: %s", err.Error()),
+			"error":   err.Error(),
+		})
+	}
+
+	createdBy := payload.UserID.String()
+
+	documentResponse, err := ac.DocumentSvc.UnifiedCreateDocument(tx, c, &documents_requests.CreateDocumentRequest{
+		FileName:      fileHeader.Filename,
+		CategoryCode:  string(documentType),
+		ApplicationID: &application.ID,
+		ApplicantID:   &toApplicantID,
+		CreatedBy:     createdBy,
+	}, nil, fileHeader)
+	if err != nil {
+		tx.Rollback()
+		config.Logger.Error("Failed to store transfer supporting document",
+			zap.Error(err),
+			zap.String("applicationID", applicationID.String()))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to store supporting document",
+			"error":   err.Error(),
+		})
+	}
+
+	transfer, err := ac.ApplicationRepo.CreateApplicationTransfer(tx, &models.ApplicationTransfer{
+		ApplicationID:            application.ID,
+		FromApplicantID:          application.ApplicantID,
+		ToApplicantID:            toApplicantID,
+		SupportingDocumentType:   documentType,
+		SupportingDocumentID:     documentResponse.ID,
+		Reason:                   reason,
+		NewDevelopmentCategoryID: newCategoryID,
+		RequestedByID:            payload.UserID,
+		CreatedBy:                createdBy,
+	})
+	if err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to create transfer request",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not commit database transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("Application transfer requested",
+		zap.String("applicationID", applicationID.String()),
+		zap.String("transferID", transfer.ID.String()),
+		zap.String("fromApplicantID", transfer.FromApplicantID.String()),
+		zap.String("toApplicantID", transfer.ToApplicantID.String()),
+		zap.String("requestedBy", createdBy))
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Transfer request submitted for approval",
+		"data":    transfer,
+	})
+}
+
+// ApproveApplicationTransferController approves a pending transfer. Requires the application.transfer permission.
+func (ac *ApplicationController) ApproveApplicationTransferController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	transferID := c.Params("id")
+
+	var request ReviewApplicationTransferRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": "Invalid request payload",
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	result, err := ac.ApplicationRepo.ApproveApplicationTransfer(tx, transferID, payload.UserID, request.Comment)
+	if err != nil {
+		tx.Rollback()
+		config.Logger.Error("Failed to approve application transfer",
+			zap.Error(err),
+			zap.String("transferID", transferID),
+			zap.String("userID", payload.UserID.String()))
+
+		statusCode := fiber.StatusInternalServerError
+		switch err.Error() {
+		case "transfer not found", "application not found":
+			statusCode = fiber.StatusNotFound
+		case "transfer cannot be approved by the officer who requested it":
+			statusCode = fiber.StatusForbidden
+		case "transfer has already been reviewed", "application applicant changed since the transfer was requested":
+			statusCode = fiber.StatusConflict
+		case "application can no longer be transferred", "no active tariff for the new development category",
+			"application is missing VAT rate or plan area for re-pricing":
+			statusCode = fiber.StatusBadRequest
+		}
+
+		return c.Status(statusCode).JSON(fiber.Map{
+			"success": false,
+			"message": fmt.Sprintf("Failed to approve transfer: %s", err.Error()),
+			"error":   err.Error(),
+		})
+	}
+
+	var quotation fiber.Map
+	if result.RequiresQuotation {
+		response, filename, err := ac.regenerateApplicationQuotation(tx, c, result.Transfer.ApplicationID, payload.UserID.String())
+		if err != nil {
+			tx.Rollback()
+			config.Logger.Error("Failed to regenerate quotation after transfer",
+				zap.Error(err),
+				zap.String("transferID", transferID))
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"message": "Failed to regenerate quotation for the new tariff",
+				"error":   err.Error(),
+			})
+		}
+
+		if err := ac.ApplicationRepo.RecordTransferQuotation(tx, result.Transfer.ID, response.ID); err != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"message": "Failed to record regenerated quotation",
+				"error":   err.Error(),
+			})
+		}
+		result.Transfer.QuotationRegenerated = true
+		result.Transfer.QuotationDocumentID = &response.ID
+
+		quotation = fiber.Map{
+			"document_id":  response.ID,
+			"filename":     filename,
+			"file_path":    response.Document.FilePath,
+			"generated_at": time.Now().Format(time.RFC3339),
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not commit database transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("Application transfer approved",
+		zap.String("transferID", transferID),
+		zap.String("applicationID", result.Transfer.ApplicationID.String()),
+		zap.String("previousApplicantID", result.PreviousApplicant.String()),
+		zap.String("newApplicantID", result.NewApplicant.String()),
+		zap.Bool("tariffChanged", result.TariffChanged),
+		zap.String("approvedBy", payload.UserID.String()))
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Transfer approved successfully",
+		"data": fiber.Map{
+			"transfer":       result.Transfer,
+			"tariff_changed": result.TariffChanged,
+			"costs":          result.CostCalculation,
+			"quotation":      quotation,
+		},
+	})
+}
+
+// RejectApplicationTransferController rejects a pending transfer. Requires the application.transfer permission.
+func (ac *ApplicationController) RejectApplicationTransferController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	transferID := c.Params("id")
+
+	var request ReviewApplicationTransferRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+
+	if strings.TrimSpace(request.Reason) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Rejection reason is required",
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	transfer, err := ac.ApplicationRepo.RejectApplicationTransfer(tx, transferID, payload.UserID, request.Reason)
+	if err != nil {
+		tx.Rollback()
+		statusCode := fiber.StatusInternalServerError
+		if err.Error() == "transfer not found" {
+			statusCode = fiber.StatusNotFound
+		} else if err.Error() == "transfer has already been reviewed" {
+			statusCode = fiber.StatusConflict
+		}
+		return c.Status(statusCode).JSON(fiber.Map{
+			"success": false,
+			"message": fmt.Sprintf("Failed to reject transfer: %s", err.Error()),
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not commit database transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("Application transfer rejected",
+		zap.String("transferID", transferID),
+		zap.String("rejectedBy", payload.UserID.String()))
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Transfer rejected",
+		"data":    transfer,
+	})
+}
+
+// GetApplicationTransfersController returns the transfer (ownership) history of an application
+func (ac *ApplicationController) GetApplicationTransfersController(c *fiber.Ctx) error {
+	applicationID := c.Params("id")
+	if _, err := uuid.Parse(applicationID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	transfers, err := ac.ApplicationRepo.GetApplicationTransfers(applicationID)
+	if err != nil {
+		config.Logger.Error("Failed to fetch application transfers",
+			zap.Error(err),
+			zap.String("applicationID", applicationID))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch application transfers",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Application transfers retrieved successfully",
+		"data":    transfers,
+	})
+}
+
+// regenerateApplicationQuotation builds a fresh quotation PDF from the application's current
+// costs and stores it as a new version of the DEVELOPMENT_PERMIT_QUOTATION document.
+func (ac *ApplicationController) regenerateApplicationQuotation(
+	tx *gorm.DB,
+	c *fiber.Ctx,
+	applicationID uuid.UUID,
+	createdBy string,
+) (*documents_services.CreateDocumentResponse, string, error) {
+	var application models.Application
+	if err := tx.
+		Preload("Applicant").
+		Preload("Stand").
+		Preload("Tariff").
+		Preload("Tariff.DevelopmentCategory").
+		Preload("VATRate").
+		First(&application, "id = ?", applicationID).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to load application: %w", err)
+	}
+
+	if application.Tariff == nil || application.Stand == nil || application.EstimatedCost == nil {
+		return nil, "", errors.New("application is missing tariff, stand or estimated cost for quotation")
+	}
+
+	safePlanNumber := strings.ReplaceAll(application.PlanNumber, "/", "_")
+	filename := fmt.Sprintf("quotation_%s_%s.pdf", safePlanNumber, time.Now().Format("20060102_150405"))
+
+	pdfPath, err := utils.GenerateDevelopmentPermitQuotation(application, filename)
+	if err != nil {
+		return nil, "", err
+	}
+	defer os.Remove(pdfPath)
+
+	pdfBytes, err := os.ReadFile(pdfPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read generated quotation: %w", err)
+	}
+	if len(pdfBytes) == 0 {
+		return nil, "", errors.New("generated quotation PDF is empty")
+	}
+
+	response, err := ac.DocumentSvc.UnifiedCreateDocument(tx, c, &documents_requests.CreateDocumentRequest{
+		CategoryCode:  "DEVELOPMENT_PERMIT_QUOTATION",
+		FileName:      filename,
+		ApplicationID: &application.ID,
+		CreatedBy:     createdBy,
+		FileType:      "application/pdf",
+	}, pdfBytes, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if response == nil || response.Document == nil {
+		return nil, "", errors.New("document service returned invalid response")
+	}
+
+	return response, filename, nil
+}
@@ -2,8 +2,11 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
 	"mime/multipart"
+	"strings"
+	applicationRepositories "town-planning-backend/applications/repositories"
 	"town-planning-backend/config"
 	"town-planning-backend/db/models"
 	"town-planning-backend/token"
@@ -14,8 +17,9 @@ import (
 )
 
 type ApproveApplicationRequest struct {
-	Comment     *string            `json:"comment"`
-	CommentType models.CommentType `json:"comment_type"`
+	Comment          *string            `json:"comment"`
+	CommentType      models.CommentType `json:"comment_type"`
+	ChecklistItemIDs []uuid.UUID        `json:"checklist_item_ids"` // Review checklist items the member ticked
 }
 
 type RejectApplicationRequest struct {
@@ -41,8 +45,15 @@ type ResolveIssueRequest struct {
 	Comment    *string `json:"comment"`
 }
 
-// ApproveApplication handles application approval by a group member
+// ApproveApplication handles application approval by a group member. Sending an
+// Idempotency-Key makes retries of the same approval replay the first result.
 func (ac *ApplicationController) ApproveRejectApplicationController(c *fiber.Ctx) error {
+	return ac.idempotentDecision(c, "approve", func(idempotencyKey *string) error {
+		return ac.approveApplication(c, idempotencyKey)
+	})
+}
+
+func (ac *ApplicationController) approveApplication(c *fiber.Ctx, idempotencyKey *string) error {
 	var request ApproveApplicationRequest
 	applicationID := c.Params("id")
 
@@ -74,41 +85,18 @@ func (ac *ApplicationController) ApproveRejectApplicationController(c *fiber.Ctx
 
 	userUUID := payload.UserID
 
-	// --- Start Database Transaction ---
-	tx := ac.DB.Begin()
-	if tx.Error != nil {
-		config.Logger.Error("Failed to begin database transaction for approval",
-			zap.Error(tx.Error),
-			zap.String("applicationID", applicationID),
-			zap.String("userID", userUUID.String()))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Internal server error: Could not start database transaction",
-			"error":   tx.Error.Error(),
-		})
-	}
-
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-			config.Logger.Error("Panic detected during approval, rolling back transaction",
-				zap.Any("panic_reason", r),
-				zap.String("applicationID", applicationID),
-				zap.String("userID", userUUID.String()))
-			panic(r)
-		}
-	}()
-
-	// Process the approval
+	// Process the approval. The repository writes the decision in its own short transaction once
+	// it has worked out the outcome, so no transaction is held here.
 	approvalResult, err := ac.ApplicationRepo.ProcessApplicationApproval(
-		tx,
+		c.UserContext(),
 		applicationID,
 		userUUID,
 		request.Comment,
 		request.CommentType,
+		request.ChecklistItemIDs,
+		idempotencyKey,
 	)
 	if err != nil {
-		tx.Rollback()
 		config.Logger.Error("Failed to process application approval",
 			zap.Error(err),
 			zap.String("applicationID", applicationID),
@@ -119,6 +107,18 @@ func (ac *ApplicationController) ApproveRejectApplicationController(c *fiber.Ctx
 			statusCode = fiber.StatusForbidden
 		} else if err.Error() == "application not found" {
 			statusCode = fiber.StatusNotFound
+		} else if err.Error() == "member is recused due to a declared conflict of interest" {
+			statusCode = fiber.StatusForbidden
+		} else if err.Error() == "conflict of interest declaration required" {
+			statusCode = fiber.StatusConflict
+		} else if errors.Is(err, applicationRepositories.ErrDecisionConflict) {
+			statusCode = fiber.StatusConflict
+		} else if err.Error() == "development levy installments must be settled before final approval" {
+			statusCode = fiber.StatusConflict
+		} else if strings.HasPrefix(err.Error(), "review checklist incomplete") ||
+			err.Error() == "checklist contains items that are not on this approval group's checklist" ||
+			errors.Is(err, applicationRepositories.ErrApprovalCommentRequired) {
+			statusCode = fiber.StatusUnprocessableEntity
 		}
 
 		return c.Status(statusCode).JSON(fiber.Map{
@@ -128,25 +128,14 @@ func (ac *ApplicationController) ApproveRejectApplicationController(c *fiber.Ctx
 		})
 	}
 
-	// --- Commit Database Transaction ---
-	if err := tx.Commit().Error; err != nil {
-		config.Logger.Error("Failed to commit database transaction for approval",
-			zap.Error(err),
-			zap.String("applicationID", applicationID),
-			zap.String("userID", userUUID.String()))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Internal server error: Could not commit database transaction",
-			"error":   err.Error(),
-		})
-	}
-
 	config.Logger.Info("Application approved successfully",
 		zap.String("applicationID", applicationID),
 		zap.String("userID", userUUID.String()),
 		zap.Bool("isFinalApprover", approvalResult.IsFinalApprover),
 		zap.Bool("readyForFinalApproval", approvalResult.ReadyForFinalApproval))
 
+	ac.broadcastClosingMessages(approvalResult.ClosingMessages, userUUID)
+
 	response := fiber.Map{
 		"success": true,
 		"message": "Application approved successfully",
@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"town-planning-backend/applications/repositories"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	config.Logger = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+// conflictingDecisions is a repository whose decisions lose every commit to another member's
+type conflictingDecisions struct {
+	repositories.ApplicationRepository
+}
+
+func (conflictingDecisions) ProcessApplicationApproval(context.Context, string, uuid.UUID, *string, models.CommentType, []uuid.UUID, *string) (*repositories.ApprovalResult, error) {
+	return nil, repositories.ErrDecisionConflict
+}
+
+func (conflictingDecisions) ProcessApplicationRejection(context.Context, string, uuid.UUID, string, *string, models.CommentType, *string) (*repositories.RejectionResult, error) {
+	return nil, repositories.ErrDecisionConflict
+}
+
+func TestDecisionConflictReturns409(t *testing.T) {
+	ac := &ApplicationController{ApplicationRepo: conflictingDecisions{}}
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user", &token.Payload{UserID: uuid.New()})
+		return c.Next()
+	})
+	app.Post("/applications/:id/approve", ac.ApproveRejectApplicationController)
+	app.Post("/applications/:id/reject", ac.RejectApplicationController)
+
+	for _, tc := range []struct {
+		path string
+		body string
+	}{
+		{path: "approve", body: `{}`},
+		{path: "reject", body: `{"reason":"Encroaches on the building line"}`},
+	} {
+		t.Run(tc.path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/applications/"+uuid.NewString()+"/"+tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != fiber.StatusConflict {
+				t.Fatalf("expected status 409, got %d", resp.StatusCode)
+			}
+			var body struct {
+				Error string `json:"error"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("invalid response body: %v", err)
+			}
+			if body.Error != repositories.ErrDecisionConflict.Error() {
+				t.Fatalf("expected error %q, got %q", repositories.ErrDecisionConflict.Error(), body.Error)
+			}
+		})
+	}
+}
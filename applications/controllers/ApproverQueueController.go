@@ -0,0 +1,386 @@
+package controllers
+
+import (
+	"encoding/json"
+	"strings"
+	"town-planning-backend/applications/repositories"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/token"
+	"town-planning-backend/utils/pagination"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+)
+
+// SaveQueueFilterRequest creates or replaces a saved queue filter
+type SaveQueueFilterRequest struct {
+	Queue     models.ApproverQueue `json:"queue"`
+	Name      string               `json:"name"`
+	Filters   map[string]string    `json:"filters"`
+	IsDefault bool                 `json:"is_default"`
+}
+
+func queueErrorStatus(err error) int {
+	switch {
+	case err.Error() == "saved filter not found":
+		return fiber.StatusNotFound
+	case err.Error() == "a saved filter with this name already exists":
+		return fiber.StatusConflict
+	case strings.HasPrefix(err.Error(), "unknown "),
+		err.Error() == "order must be asc or desc",
+		err.Error() == "filters must be an object of strings":
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// queueFilters builds a queue's filters from a saved filter and the query string. filter_id
+// picks a saved filter; without one the user's default filter for the queue applies unless
+// the request sets filters of its own. Query parameters override the saved values.
+func (ac *ApplicationController) queueFilters(c *fiber.Ctx, userID uuid.UUID, queue models.ApproverQueue) (map[string]string, *models.SavedQueueFilter, error) {
+	filters := make(map[string]string)
+	for _, key := range repositories.QueueFilterKeys(queue) {
+		if value := strings.TrimSpace(c.Query(key)); value != "" {
+			filters[key] = value
+		}
+	}
+
+	var saved *models.SavedQueueFilter
+	var err error
+	if filterID := c.Query("filter_id"); filterID != "" {
+		parsed, parseErr := uuid.Parse(filterID)
+		if parseErr != nil {
+			return nil, nil, fiber.NewError(fiber.StatusBadRequest, "invalid filter_id")
+		}
+		saved, err = ac.ApplicationRepo.GetSavedQueueFilter(userID, parsed)
+		if err != nil {
+			return nil, nil, err
+		}
+		if saved.Queue != queue {
+			return nil, nil, fiber.NewError(fiber.StatusBadRequest, "saved filter belongs to another queue")
+		}
+	} else if len(filters) == 0 {
+		saved, err = ac.ApplicationRepo.GetDefaultQueueFilter(userID, queue)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if saved != nil {
+		var values map[string]string
+		if err := json.Unmarshal(saved.Filters, &values); err != nil {
+			return nil, nil, err
+		}
+		for key, value := range values {
+			if _, set := filters[key]; !set {
+				filters[key] = value
+			}
+		}
+	}
+	return filters, saved, nil
+}
+
+// queueRequest reads the user, page and filters shared by the queue endpoints, writing the
+// error response itself when any of them is invalid
+func (ac *ApplicationController) queueRequest(c *fiber.Ctx, queue models.ApproverQueue) (uuid.UUID, pagination.Request, map[string]string, *models.SavedQueueFilter, bool) {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+		return uuid.Nil, pagination.Request{}, nil, nil, false
+	}
+
+	page, err := pagination.ParseRequest(c, 20)
+	if err != nil {
+		c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid pagination parameters",
+			"error":   err.Error(),
+		})
+		return uuid.Nil, pagination.Request{}, nil, nil, false
+	}
+
+	filters, saved, err := ac.queueFilters(c, payload.UserID, queue)
+	if err != nil {
+		status := queueErrorStatus(err)
+		if fiberErr, isFiber := err.(*fiber.Error); isFiber {
+			status = fiberErr.Code
+		}
+		c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid queue filter",
+			"error":   err.Error(),
+		})
+		return uuid.Nil, pagination.Request{}, nil, nil, false
+	}
+	return payload.UserID, page, filters, saved, true
+}
+
+// GetPendingDecisionsQueueController lists the applications awaiting the user's decision
+func (ac *ApplicationController) GetPendingDecisionsQueueController(c *fiber.Ctx) error {
+	userID, page, filters, saved, ok := ac.queueRequest(c, models.QueuePendingDecisions)
+	if !ok {
+		return nil
+	}
+
+	applications, total, err := ac.ApplicationRepo.GetPendingDecisionsQueue(userID, page, filters)
+	if err != nil {
+		config.Logger.Error("Failed to fetch pending decisions queue", zap.Error(err), zap.String("userID", userID.String()))
+		return c.Status(queueErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch pending decisions",
+			"error":   err.Error(),
+		})
+	}
+
+	var next *pagination.Cursor
+	if len(applications) > 0 {
+		last := applications[len(applications)-1]
+		next = page.NextCursor(len(applications), last.CreatedAt, last.ID)
+	}
+
+	return c.JSON(fiber.Map{
+		"success":        true,
+		"message":        "Pending decisions retrieved successfully",
+		"data":           pagination.NewEnvelope(c, page, applications, total, next),
+		"filters":        filters,
+		"applied_filter": saved,
+	})
+}
+
+// GetAssignedIssuesQueueController lists the unresolved issues assigned to the user
+func (ac *ApplicationController) GetAssignedIssuesQueueController(c *fiber.Ctx) error {
+	userID, page, filters, saved, ok := ac.queueRequest(c, models.QueueAssignedIssues)
+	if !ok {
+		return nil
+	}
+
+	issues, total, err := ac.ApplicationRepo.GetAssignedIssuesQueue(userID, page, filters)
+	if err != nil {
+		config.Logger.Error("Failed to fetch assigned issues queue", zap.Error(err), zap.String("userID", userID.String()))
+		return c.Status(queueErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch assigned issues",
+			"error":   err.Error(),
+		})
+	}
+
+	var next *pagination.Cursor
+	if len(issues) > 0 {
+		last := issues[len(issues)-1]
+		next = page.NextCursor(len(issues), last.CreatedAt, last.ID)
+	}
+
+	return c.JSON(fiber.Map{
+		"success":        true,
+		"message":        "Assigned issues retrieved successfully",
+		"data":           pagination.NewEnvelope(c, page, issues, total, next),
+		"filters":        filters,
+		"applied_filter": saved,
+	})
+}
+
+// GetUnreadMentionsQueueController lists the threads where the user has unread mentions
+func (ac *ApplicationController) GetUnreadMentionsQueueController(c *fiber.Ctx) error {
+	userID, page, filters, saved, ok := ac.queueRequest(c, models.QueueUnreadMentions)
+	if !ok {
+		return nil
+	}
+
+	threads, total, err := ac.ApplicationRepo.GetUnreadMentionsQueue(userID, page, filters)
+	if err != nil {
+		config.Logger.Error("Failed to fetch unread mentions queue", zap.Error(err), zap.String("userID", userID.String()))
+		return c.Status(queueErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch unread mentions",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success":        true,
+		"message":        "Unread mentions retrieved successfully",
+		"data":           pagination.NewEnvelope(c, page, threads, total, nil),
+		"filters":        filters,
+		"applied_filter": saved,
+	})
+}
+
+// GetSavedQueueFiltersController lists the user's saved filters, optionally for one queue
+func (ac *ApplicationController) GetSavedQueueFiltersController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	var queue *models.ApproverQueue
+	if value := c.Query("queue"); value != "" {
+		parsed := models.ApproverQueue(strings.ToUpper(value))
+		if repositories.QueueFilterKeys(parsed) == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": "Unknown queue",
+			})
+		}
+		queue = &parsed
+	}
+
+	filters, err := ac.ApplicationRepo.GetSavedQueueFilters(payload.UserID, queue)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch saved filters",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Saved filters retrieved successfully",
+		"data":    filters,
+	})
+}
+
+// CreateSavedQueueFilterController saves a named filter for one of the user's queues
+func (ac *ApplicationController) CreateSavedQueueFilterController(c *fiber.Ctx) error {
+	return ac.saveQueueFilter(c, uuid.Nil)
+}
+
+// UpdateSavedQueueFilterController replaces a saved filter's name, filters or default flag
+func (ac *ApplicationController) UpdateSavedQueueFilterController(c *fiber.Ctx) error {
+	filterID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid filter ID",
+			"error":   "invalid_uuid",
+		})
+	}
+	return ac.saveQueueFilter(c, filterID)
+}
+
+func (ac *ApplicationController) saveQueueFilter(c *fiber.Ctx, filterID uuid.UUID) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	var request SaveQueueFilterRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+	request.Name = strings.TrimSpace(request.Name)
+	request.Queue = models.ApproverQueue(strings.ToUpper(string(request.Queue)))
+	if request.Name == "" || len(request.Name) > 100 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Name is required and cannot be longer than 100 characters",
+		})
+	}
+	if request.Filters == nil {
+		request.Filters = map[string]string{}
+	}
+
+	encoded, err := json.Marshal(request.Filters)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid filters",
+			"error":   err.Error(),
+		})
+	}
+
+	filter := &models.SavedQueueFilter{
+		ID:        filterID,
+		UserID:    payload.UserID,
+		Queue:     request.Queue,
+		Name:      request.Name,
+		Filters:   datatypes.JSON(encoded),
+		IsDefault: request.IsDefault,
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if err := ac.ApplicationRepo.SaveQueueFilter(tx, filter); err != nil {
+		tx.Rollback()
+		return c.Status(queueErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to save filter",
+			"error":   err.Error(),
+		})
+	}
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to save filter",
+			"error":   err.Error(),
+		})
+	}
+
+	saved, err := ac.ApplicationRepo.GetSavedQueueFilter(payload.UserID, filter.ID)
+	if err != nil {
+		return c.Status(queueErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch saved filter",
+			"error":   err.Error(),
+		})
+	}
+
+	status := fiber.StatusOK
+	if filterID == uuid.Nil {
+		status = fiber.StatusCreated
+	}
+	return c.Status(status).JSON(fiber.Map{
+		"success": true,
+		"message": "Filter saved successfully",
+		"data":    saved,
+	})
+}
+
+// DeleteSavedQueueFilterController removes one of the user's saved filters
+func (ac *ApplicationController) DeleteSavedQueueFilterController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	filterID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid filter ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	if err := ac.ApplicationRepo.DeleteQueueFilter(payload.UserID, filterID); err != nil {
+		return c.Status(queueErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to delete filter",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Filter deleted successfully",
+	})
+}
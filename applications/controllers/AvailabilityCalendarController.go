@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"fmt"
+	"time"
+	application_services "town-planning-backend/applications/services"
+	"town-planning-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// GetAvailabilityCalendarController shows when approval group members are away over the next
+// weeks (default 4) and flags the days a group would lack quorum or its final approver, so
+// directors can arrange cover ahead of time. group_id narrows it to one group.
+func (ac *ApplicationController) GetAvailabilityCalendarController(c *fiber.Ctx) error {
+	weeks := c.QueryInt("weeks", 4)
+	if weeks < 1 || weeks > application_services.MaxCalendarWeeks {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid number of weeks",
+			"error":   fmt.Sprintf("weeks must be between 1 and %d", application_services.MaxCalendarWeeks),
+		})
+	}
+
+	var groupID *uuid.UUID
+	if raw := c.Query("group_id"); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": "Invalid approval group ID",
+			})
+		}
+		groupID = &parsed
+	}
+
+	groups, err := ac.ApplicationRepo.GetApprovalGroupAvailability(groupID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch approval groups",
+			"error":   err.Error(),
+		})
+	}
+	if groupID != nil && len(groups) == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": "Approval group not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Availability calendar retrieved",
+		"data":    application_services.BuildAvailabilityCalendar(groups, time.Now(), weeks, utils.DateLocation),
+	})
+}
@@ -0,0 +1,529 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"town-planning-backend/applications/requests"
+	application_services "town-planning-backend/applications/services"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// maxBoundaryLayerSize caps uploaded GeoJSON; council ward layers are a few megabytes at most
+const maxBoundaryLayerSize = 20 * 1024 * 1024
+
+// boundaryErrorStatus maps boundary repository errors to HTTP status codes
+func boundaryErrorStatus(err error) int {
+	switch err.Error() {
+	case "application not found", "boundary layer not found", "no boundary check recorded":
+		return fiber.StatusNotFound
+	case "application is not awaiting boundary review":
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// checkApplicationBoundary geo-validates the stand against the active boundary layers and
+// records the result, flagging the application for boundary review when it falls outside them.
+// It returns nil when no boundary layer has been uploaded.
+func (ac *ApplicationController) checkApplicationBoundary(tx *gorm.DB, applicationID uuid.UUID, stand *models.Stand, createdBy string) (*models.BoundaryCheck, error) {
+	jurisdiction, ward, err := ac.ApplicationRepo.GetActiveBoundaryLayers()
+	if err != nil {
+		return nil, err
+	}
+
+	check, err := ac.BoundaryValidator.CheckStand(stand, jurisdiction, ward)
+	if err != nil || check == nil {
+		return nil, err
+	}
+
+	recorded, err := ac.ApplicationRepo.RecordBoundaryCheck(tx, applicationID, check, createdBy)
+	if err != nil {
+		return nil, err
+	}
+	if recorded.RequiresReview() {
+		config.Logger.Warn("Application flagged for boundary review",
+			zap.String("applicationID", applicationID.String()),
+			zap.String("standID", stand.ID.String()),
+			zap.String("status", string(recorded.Status)))
+	}
+	return recorded, nil
+}
+
+// UploadBoundaryLayerController stores a GeoJSON boundary layer as the active layer of its kind.
+// Multipart form: file (GeoJSON), name, kind (JURISDICTION or WARD) and name_property, the
+// feature property holding each ward's name (default "name").
+func (ac *ApplicationController) UploadBoundaryLayerController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	kind := models.BoundaryLayerKind(strings.ToUpper(strings.TrimSpace(c.FormValue("kind"))))
+	if kind != models.BoundaryLayerJurisdiction && kind != models.BoundaryLayerWard {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": fmt.Sprintf("Invalid kind, expected %s or %s", models.BoundaryLayerJurisdiction, models.BoundaryLayerWard),
+		})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "GeoJSON file is required",
+			"error":   err.Error(),
+		})
+	}
+	if ext := strings.ToLower(filepath.Ext(fileHeader.Filename)); ext != ".geojson" && ext != ".json" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Unsupported boundary file format",
+			"error":   fmt.Sprintf("file type %s is not allowed", ext),
+		})
+	}
+	if fileHeader.Size > maxBoundaryLayerSize {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+			"success": false,
+			"message": "Boundary file is too large",
+		})
+	}
+
+	src, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to read boundary file",
+			"error":   err.Error(),
+		})
+	}
+	defer src.Close()
+
+	data, err := io.ReadAll(io.LimitReader(src, maxBoundaryLayerSize))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to read boundary file",
+			"error":   err.Error(),
+		})
+	}
+
+	nameProperty := strings.TrimSpace(c.FormValue("name_property"))
+	if nameProperty == "" {
+		nameProperty = "name"
+	}
+	featureCount, err := application_services.ValidateBoundaryLayer(kind, data, nameProperty)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid boundary layer",
+			"error":   err.Error(),
+		})
+	}
+
+	name := strings.TrimSpace(c.FormValue("name"))
+	if name == "" {
+		name = fileHeader.Filename
+	}
+
+	layer := models.BoundaryLayer{
+		Name:         name,
+		Kind:         kind,
+		GeoJSON:      data,
+		NameProperty: nameProperty,
+		FeatureCount: featureCount,
+		CreatedBy:    payload.UserID.String(),
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	if err := ac.ApplicationRepo.CreateBoundaryLayer(tx, &layer); err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to save boundary layer",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("Boundary layer uploaded",
+		zap.String("layerID", layer.ID.String()),
+		zap.String("kind", string(layer.Kind)),
+		zap.Int("features", layer.FeatureCount),
+		zap.String("uploadedBy", payload.UserID.String()))
+
+	layer.GeoJSON = nil
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Boundary layer uploaded",
+		"data":    layer,
+	})
+}
+
+// GetBoundaryLayersController lists the boundary layers without their geometry
+func (ac *ApplicationController) GetBoundaryLayersController(c *fiber.Ctx) error {
+	layers, err := ac.ApplicationRepo.GetBoundaryLayers()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch boundary layers",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Boundary layers retrieved successfully",
+		"data":    layers,
+	})
+}
+
+// GetBoundaryLayerController returns a boundary layer with its GeoJSON, e.g. to draw it on a map
+func (ac *ApplicationController) GetBoundaryLayerController(c *fiber.Ctx) error {
+	layerID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid boundary layer ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	layer, err := ac.ApplicationRepo.GetBoundaryLayer(layerID)
+	if err != nil {
+		return c.Status(boundaryErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch boundary layer",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Boundary layer retrieved successfully",
+		"data":    layer,
+	})
+}
+
+// SetBoundaryLayerStatusController activates or deactivates a boundary layer
+func (ac *ApplicationController) SetBoundaryLayerStatusController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	layerID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid boundary layer ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var request requests.BoundaryLayerStatusRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	layer, err := ac.ApplicationRepo.SetBoundaryLayerActive(tx, layerID, request.IsActive, payload.UserID.String())
+	if err != nil {
+		tx.Rollback()
+		return c.Status(boundaryErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to update boundary layer",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Boundary layer updated",
+		"data":    layer,
+	})
+}
+
+// CheckApplicationBoundaryController re-validates the application's stand against the active
+// boundary layers, e.g. after its coordinates or ward have been corrected
+func (ac *ApplicationController) CheckApplicationBoundaryController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var application models.Application
+	if err := ac.DB.Preload("Stand").Where("id = ?", applicationID).First(&application).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"success": false,
+				"message": "Application not found",
+				"error":   "application_not_found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to load application",
+			"error":   err.Error(),
+		})
+	}
+	if application.Stand == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Application has no stand to check",
+			"error":   "missing_stand",
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	check, err := ac.checkApplicationBoundary(tx, applicationID, application.Stand, payload.UserID.String())
+	if err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to check application boundary",
+			"error":   err.Error(),
+		})
+	}
+	if check == nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"message": "No boundary layers have been uploaded",
+			"error":   "boundary_layers_missing",
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Application boundary checked",
+		"data":    check,
+	})
+}
+
+// GetApplicationBoundaryChecksController lists an application's boundary check history
+func (ac *ApplicationController) GetApplicationBoundaryChecksController(c *fiber.Ctx) error {
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	checks, err := ac.ApplicationRepo.GetApplicationBoundaryChecks(applicationID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch boundary checks",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Boundary checks retrieved successfully",
+		"data":    checks,
+	})
+}
+
+// GetBoundaryReviewQueueController lists the applications whose stands fall outside the council
+// or ward boundaries and await special review
+func (ac *ApplicationController) GetBoundaryReviewQueueController(c *fiber.Ctx) error {
+	applications, err := ac.ApplicationRepo.GetBoundaryReviewQueue()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch boundary review queue",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Boundary review queue retrieved successfully",
+		"data":    applications,
+	})
+}
+
+// ClearBoundaryReviewController releases an application from the boundary review queue
+func (ac *ApplicationController) ClearBoundaryReviewController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var request requests.ClearBoundaryReviewRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+	note := strings.TrimSpace(request.Note)
+	if note == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "A note is required to clear boundary review",
+			"error":   "missing_note",
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	check, err := ac.ApplicationRepo.ClearBoundaryReview(tx, applicationID, payload.UserID, note)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(boundaryErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to clear boundary review",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("Boundary review cleared",
+		zap.String("applicationID", applicationID.String()),
+		zap.String("reviewedBy", payload.UserID.String()))
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Boundary review cleared",
+		"data":    check,
+	})
+}
@@ -0,0 +1,250 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	applicationRepositories "town-planning-backend/applications/repositories"
+	application_services "town-planning-backend/applications/services"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// chatCommandError is a command that was refused, with the status to answer with
+type chatCommandError struct {
+	status int
+	err    error
+}
+
+func (e *chatCommandError) Error() string {
+	return e.err.Error()
+}
+
+func refuseChatCommand(status int, format string, args ...interface{}) error {
+	return &chatCommandError{status: status, err: fmt.Errorf(format, args...)}
+}
+
+// runChatCommand executes a command sent to an issue's thread in place of a message. The
+// command itself is not posted; the conversation sees the confirmation system message instead.
+// It owns the transaction from here on: it commits or rolls back and writes the response.
+func (ac *ApplicationController) runChatCommand(
+	c *fiber.Ctx,
+	tx *gorm.DB,
+	thread *models.ChatThread,
+	sender *models.User,
+	command *application_services.ChatCommand,
+) error {
+	threadID := thread.ID.String()
+
+	message, err := ac.executeChatCommand(tx, thread, sender, command)
+	if err != nil {
+		tx.Rollback()
+		status := fiber.StatusInternalServerError
+		var refused *chatCommandError
+		if errors.As(err, &refused) {
+			status = refused.status
+		} else {
+			config.Logger.Error("Failed to execute chat command",
+				zap.Error(err),
+				zap.String("command", command.Name),
+				zap.String("threadID", threadID),
+				zap.String("userID", sender.ID.String()))
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"message": fmt.Sprintf("/%s failed: %s", command.Name, err.Error()),
+			"error":   "command_failed",
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		config.Logger.Error("Failed to commit database transaction for chat command",
+			zap.Error(err),
+			zap.String("command", command.Name),
+			zap.String("threadID", threadID))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not commit database transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	ac.broadcastNewMessage(threadID, *message, sender.ID)
+
+	config.Logger.Info("Chat command executed",
+		zap.String("command", command.Name),
+		zap.String("threadID", threadID),
+		zap.String("issueID", thread.IssueID.String()),
+		zap.String("userID", sender.ID.String()))
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Command executed successfully",
+		"data":    message,
+	})
+}
+
+// executeChatCommand checks the sender may run the command on the thread's issue and runs it
+// through the same repository operations as the issue endpoints
+func (ac *ApplicationController) executeChatCommand(
+	tx *gorm.DB,
+	thread *models.ChatThread,
+	sender *models.User,
+	command *application_services.ChatCommand,
+) (*applicationRepositories.EnhancedChatMessage, error) {
+	issue, err := ac.ApplicationRepo.GetIssueByID(thread.IssueID.String())
+	if err != nil {
+		return nil, refuseChatCommand(fiber.StatusNotFound, "this conversation has no issue")
+	}
+	if issue.IsResolved {
+		return nil, refuseChatCommand(fiber.StatusConflict, "the issue is already resolved")
+	}
+
+	switch command.Name {
+	case application_services.ChatCommandResolve:
+		if !issue.CanUserResolveIssue(sender.ID) {
+			return nil, refuseChatCommand(fiber.StatusForbidden, "%s", issue.GetRequiredResolver())
+		}
+
+		reason := command.Argument
+		resolvedIssue, err := ac.ApplicationRepo.MarkIssueAsResolved(tx, issue.ID.String(), sender.ID, &reason)
+		if err != nil {
+			return nil, err
+		}
+		message, err := ac.createResolutionMessage(tx, resolvedIssue, sender.ID, sender.Email, reason)
+		if err != nil {
+			return nil, err
+		}
+		if err := ac.markThreadAsResolved(tx, thread.ID.String()); err != nil {
+			return nil, err
+		}
+		return message, nil
+
+	case application_services.ChatCommandAssign:
+		if err := ac.requireIssueManager(thread, issue, sender); err != nil {
+			return nil, err
+		}
+
+		participants, err := ac.ApplicationRepo.GetThreadParticipants(thread.ID.String())
+		if err != nil {
+			return nil, err
+		}
+		candidates := make([]models.User, 0, len(participants))
+		for _, participant := range participants {
+			candidates = append(candidates, participant.User)
+		}
+		mentioned := application_services.FindMentionedUsers(command.Argument, candidates)
+		if len(mentioned) != 1 {
+			return nil, refuseChatCommand(fiber.StatusBadRequest, "mention exactly one participant of this conversation")
+		}
+
+		assignedIssue, err := ac.ApplicationRepo.AssignIssueToUser(tx, issue.ID, mentioned[0])
+		if err != nil {
+			return nil, err
+		}
+		return ac.postChatCommandMessage(tx, thread.ID, sender, models.SystemEventIssueAssigned,
+			application_services.SystemEventParams{
+				ActorName:   userFullName(sender),
+				TargetNames: []string{userFullName(assignedIssue.AssignedToUser)},
+			})
+
+	case application_services.ChatCommandPriority:
+		if err := ac.requireIssueManager(thread, issue, sender); err != nil {
+			return nil, err
+		}
+		if issue.Priority == command.Argument {
+			return nil, refuseChatCommand(fiber.StatusConflict, "the issue is already %s priority", command.Argument)
+		}
+
+		if _, err := ac.ApplicationRepo.SetIssuePriority(tx, issue.ID, command.Argument); err != nil {
+			return nil, err
+		}
+		return ac.postChatCommandMessage(tx, thread.ID, sender, models.SystemEventIssuePriorityChanged,
+			application_services.SystemEventParams{
+				ActorName:   userFullName(sender),
+				Description: command.Argument,
+			})
+	}
+
+	return nil, refuseChatCommand(fiber.StatusBadRequest, "unknown command /%s", command.Name)
+}
+
+// requireIssueManager allows the issue's raiser and participants who can manage the thread
+func (ac *ApplicationController) requireIssueManager(thread *models.ChatThread, issue *models.ApplicationIssue, sender *models.User) error {
+	if issue.RaisedByUserID == sender.ID {
+		return nil
+	}
+	canManage, err := ac.ApplicationRepo.CanUserManageParticipants(thread.ID.String(), sender.ID, "manage")
+	if err != nil {
+		return err
+	}
+	if !canManage {
+		return refuseChatCommand(fiber.StatusForbidden, "only the issue's raiser or a thread manager can do this")
+	}
+	return nil
+}
+
+// postChatCommandMessage saves the system message confirming a command and marks it unread for
+// everyone else in the thread
+func (ac *ApplicationController) postChatCommandMessage(
+	tx *gorm.DB,
+	threadID uuid.UUID,
+	sender *models.User,
+	eventType models.SystemEventType,
+	params application_services.SystemEventParams,
+) (*applicationRepositories.EnhancedChatMessage, error) {
+	message, err := ac.buildSystemMessage(threadID, sender.ID, eventType, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build confirmation message: %w", err)
+	}
+	if err := tx.Create(&message).Error; err != nil {
+		return nil, fmt.Errorf("failed to create confirmation message: %w", err)
+	}
+
+	now := time.Now()
+	if err := tx.Model(&models.ChatThread{}).
+		Where("id = ?", threadID).
+		Updates(map[string]interface{}{
+			"updated_at":       now,
+			"last_activity_at": now,
+		}).Error; err != nil {
+		config.Logger.Warn("Failed to update thread timestamps for chat command",
+			zap.Error(err),
+			zap.String("threadID", threadID.String()))
+	}
+
+	if err := ac.incrementUnreadCounts(tx, threadID.String(), sender.ID); err != nil {
+		config.Logger.Warn("Failed to increment unread counts for chat command",
+			zap.Error(err),
+			zap.String("threadID", threadID.String()))
+	}
+
+	return &applicationRepositories.EnhancedChatMessage{
+		ID:          message.ID,
+		Content:     message.Content,
+		MessageType: message.MessageType,
+		EventType:   message.EventType,
+		EventParams: message.EventParams,
+		Status:      message.Status,
+		Origin:      message.Origin,
+		CreatedAt:   message.CreatedAt.Format(time.RFC3339),
+		Sender: &applicationRepositories.UserSummary{
+			ID:        sender.ID,
+			FirstName: sender.FirstName,
+			LastName:  sender.LastName,
+			Email:     sender.Email,
+			Department: utils.DerefString(func() *string {
+				if sender.Department != nil {
+					return &sender.Department.Name
+				}
+				return nil
+			}()),
+		},
+	}, nil
+}
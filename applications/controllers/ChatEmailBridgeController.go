@@ -0,0 +1,388 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/settings"
+	"town-planning-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+const (
+	chatEmailBridgeSchedule = "*/5 * * * *"
+
+	// maxChatEmailsPerRun stops one run from holding the bridge when many participants are away
+	maxChatEmailsPerRun = 200
+
+	// maxChatEmailMessages keeps a digest readable; the rest wait for the participant in the app
+	maxChatEmailMessages = 20
+
+	// chatReplyMarker separates the reply from the quoted digest in the participant's answer
+	chatReplyMarker = "## Reply above this line to post in the thread ##"
+)
+
+// quotedReplyHeader matches the line mail clients put above the message being replied to
+var quotedReplyHeader = regexp.MustCompile(`(?i)^on .+ wrote:$`)
+
+// chatReplyAddress is the reply-by-email address for a participant's reply token, the
+// CHAT_REPLY_ADDRESS mailbox with the token as a plus tag, e.g. chat+abc123@reply.example.org
+func chatReplyAddress(replyToken string) (string, error) {
+	mailbox := strings.TrimSpace(config.GetEnv("CHAT_REPLY_ADDRESS"))
+	local, domain, ok := strings.Cut(mailbox, "@")
+	if !ok || local == "" || domain == "" {
+		return "", fmt.Errorf("CHAT_REPLY_ADDRESS is not a valid address")
+	}
+	return fmt.Sprintf("%s+%s@%s", local, replyToken, domain), nil
+}
+
+// chatReplyToken finds the reply token among the addresses an inbound email was sent to
+func chatReplyToken(recipients []string) string {
+	local, domain, ok := strings.Cut(strings.TrimSpace(config.GetEnv("CHAT_REPLY_ADDRESS")), "@")
+	if !ok {
+		return ""
+	}
+	prefix := strings.ToLower(local) + "+"
+
+	for _, recipient := range recipients {
+		addresses, err := mail.ParseAddressList(recipient)
+		if err != nil {
+			continue
+		}
+		for _, address := range addresses {
+			addressLocal, addressDomain, ok := strings.Cut(strings.ToLower(address.Address), "@")
+			if !ok || !strings.EqualFold(addressDomain, domain) || !strings.HasPrefix(addressLocal, prefix) {
+				continue
+			}
+			if replyToken := strings.TrimPrefix(addressLocal, prefix); replyToken != "" {
+				return replyToken
+			}
+		}
+	}
+	return ""
+}
+
+// stripQuotedReply keeps only what the participant wrote above the quoted digest
+func stripQuotedReply(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	kept := make([]string, 0, len(lines))
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.Contains(trimmed, chatReplyMarker) ||
+			strings.HasPrefix(trimmed, ">") ||
+			quotedReplyHeader.MatchString(trimmed) ||
+			strings.HasPrefix(trimmed, "-----Original Message-----") ||
+			strings.HasPrefix(trimmed, "________________________________") ||
+			trimmed == "--" {
+			break
+		}
+		kept = append(kept, strings.TrimRight(line, " \t"))
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
+
+// bridgeUnreadChatMessages emails participants the messages that have waited unread for longer
+// than the configured delay while they were away from the thread
+func (ac *ApplicationController) bridgeUnreadChatMessages() {
+	if !settings.Bool(settings.ChatEmailBridge) {
+		return
+	}
+	if _, err := chatReplyAddress("check"); err != nil {
+		config.Logger.Warn("Chat email bridge is on but replies cannot be received", zap.Error(err))
+		return
+	}
+
+	now := time.Now()
+	cutoff := now.Add(-time.Duration(settings.Int(settings.ChatEmailBridgeAfterMinutes)) * time.Minute)
+	threadState := ac.ReadReceiptSvc.ThreadState()
+
+	states, err := threadState.EmailBridgeCandidates(now, cutoff, maxChatEmailsPerRun)
+	if err != nil {
+		config.Logger.Error("Failed to find chat participants to email", zap.Error(err))
+		return
+	}
+
+	emailed := 0
+	for i := range states {
+		state := &states[i]
+
+		// Connected to the thread right now, so the messages reach them in the app
+		if ac.WsHub != nil && ac.WsHub.IsUserInThread(state.ThreadID.String(), state.UserID) {
+			continue
+		}
+		if ac.emailUnreadChatMessages(state, now) {
+			emailed++
+		}
+	}
+
+	if emailed > 0 {
+		config.Logger.Info("Unread chat messages emailed", zap.Int("participants", emailed))
+	}
+}
+
+// emailUnreadChatMessages sends one participant a digest of their unread messages in a thread
+func (ac *ApplicationController) emailUnreadChatMessages(state *models.ParticipantThreadState, now time.Time) bool {
+	threadState := ac.ReadReceiptSvc.ThreadState()
+
+	user, err := ac.UserRepo.GetUserProfileByID(state.UserID.String())
+	if err != nil || strings.TrimSpace(user.Email) == "" || !user.Active {
+		return false
+	}
+
+	var thread models.ChatThread
+	if err := ac.DB.Select("id", "title", "application_id").Where("id = ?", state.ThreadID).First(&thread).Error; err != nil {
+		config.Logger.Warn("Failed to load thread for chat email",
+			zap.Error(err),
+			zap.String("threadID", state.ThreadID.String()))
+		return false
+	}
+
+	messages, err := threadState.UnbridgedMessages(state, maxChatEmailMessages)
+	if err != nil || len(messages) == 0 {
+		if err != nil {
+			config.Logger.Warn("Failed to load unread messages for chat email",
+				zap.Error(err),
+				zap.String("threadID", state.ThreadID.String()))
+		}
+		return false
+	}
+
+	replyToken, err := threadState.EnsureEmailReplyToken(state)
+	if err != nil {
+		config.Logger.Error("Failed to create chat reply address", zap.Error(err))
+		return false
+	}
+	replyTo, err := chatReplyAddress(replyToken)
+	if err != nil {
+		return false
+	}
+
+	var body strings.Builder
+	body.WriteString(chatReplyMarker + "\n\n")
+	fmt.Fprintf(&body, "Dear %s,\n\nYou have unread messages in \"%s\":\n\n", userFullName(user), thread.Title)
+	for _, message := range messages {
+		fmt.Fprintf(&body, "%s, %s:\n%s\n\n",
+			userFullName(&message.Sender),
+			message.CreatedAt.Format("02 Jan 2006 15:04"),
+			message.Content)
+	}
+	if state.UnreadCount > len(messages) {
+		fmt.Fprintf(&body, "Open the thread to see all %d unread messages.\n\n", state.UnreadCount)
+	}
+	body.WriteString("Reply to this email to answer in the thread. Your reply is posted as your message.")
+
+	var applicationID *uuid.UUID
+	if thread.ApplicationID != uuid.Nil {
+		applicationID = &thread.ApplicationID
+	}
+
+	if err := utils.SendChatDigestEmail(utils.ChatDigestEmail{
+		To:            user.Email,
+		Subject:       fmt.Sprintf("New messages in %s", thread.Title),
+		Body:          body.String(),
+		ReplyTo:       replyTo,
+		ApplicationID: applicationID,
+	}); err != nil {
+		config.Logger.Warn("Failed to email unread chat messages",
+			zap.Error(err),
+			zap.String("threadID", state.ThreadID.String()),
+			zap.String("userID", state.UserID.String()))
+		return false
+	}
+
+	if err := threadState.MarkEmailBridged(state, now); err != nil {
+		config.Logger.Error("Failed to record chat email",
+			zap.Error(err),
+			zap.String("threadID", state.ThreadID.String()))
+	}
+	return true
+}
+
+// RunChatEmailBridge emails unread chat messages to participants who have not opened the thread
+func (ac *ApplicationController) RunChatEmailBridge() {
+	c := cron.New()
+
+	c.AddFunc(chatEmailBridgeSchedule, ac.bridgeUnreadChatMessages)
+
+	c.Start()
+
+	// Keep running so the cron jobs can execute
+	select {}
+}
+
+// ChatEmailReplyController receives replies to chat digests from SendGrid Inbound Parse,
+// configured with CHAT_INBOUND_EMAIL_TOKEN, e.g. /emails/inbound/chat?token=... A reply from
+// the participant the address was issued to is posted into the thread as their message.
+// Replies that cannot be posted are acknowledged anyway so SendGrid does not retry them.
+func (ac *ApplicationController) ChatEmailReplyController(c *fiber.Ctx) error {
+	secret := config.GetEnv("CHAT_INBOUND_EMAIL_TOKEN")
+	if secret == "" || subtle.ConstantTimeCompare([]byte(c.Query("token")), []byte(secret)) != 1 {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid webhook token",
+		})
+	}
+
+	ignore := func(reason string) error {
+		config.Logger.Warn("Chat email reply ignored", zap.String("reason", reason))
+		return c.JSON(fiber.Map{
+			"success": false,
+			"message": "Reply ignored",
+			"error":   reason,
+		})
+	}
+
+	if !settings.Bool(settings.ChatEmailBridge) {
+		return ignore("chat email bridge is off")
+	}
+
+	recipients := []string{c.FormValue("to"), c.FormValue("cc")}
+	var envelope struct {
+		To []string `json:"to"`
+	}
+	if raw := c.FormValue("envelope"); raw != "" && json.Unmarshal([]byte(raw), &envelope) == nil {
+		recipients = append(envelope.To, recipients...)
+	}
+
+	replyToken := chatReplyToken(recipients)
+	if replyToken == "" {
+		return ignore("no reply address among the recipients")
+	}
+
+	state, err := ac.ReadReceiptSvc.ThreadState().GetStateByEmailReplyToken(replyToken)
+	if err != nil {
+		return ignore(err.Error())
+	}
+
+	user, err := ac.UserRepo.GetUserProfileByID(state.UserID.String())
+	if err != nil {
+		return ignore("participant not found")
+	}
+
+	// The address is only a secret until it is forwarded, so the sender must also match
+	from, err := mail.ParseAddress(c.FormValue("from"))
+	if err != nil || !strings.EqualFold(from.Address, strings.TrimSpace(user.Email)) {
+		return ignore("reply was not sent from the participant's address")
+	}
+
+	content := stripQuotedReply(c.FormValue("text"))
+	if content == "" {
+		return ignore("reply has no text above the quoted messages")
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	threadID := state.ThreadID.String()
+
+	// The participant may have left the thread, or it may have been frozen, since the digest
+	thread, err := ac.ApplicationRepo.VerifyThreadAccess(tx, threadID, user.ID)
+	if err != nil {
+		tx.Rollback()
+		return ignore(err.Error())
+	}
+	if err := ac.ensureThreadWritable(thread, user.ID); err != nil {
+		tx.Rollback()
+		return ignore(err.Error())
+	}
+
+	var applicationID *uuid.UUID
+	if thread.ApplicationID != uuid.Nil {
+		applicationID = &thread.ApplicationID
+	}
+
+	enhancedMessage, err := ac.ApplicationRepo.CreateMessageWithAttachments(
+		tx,
+		nil, // Email attachments are not carried over
+		threadID,
+		content,
+		models.MessageTypeText,
+		user.ID,
+		nil,
+		nil,
+		applicationID,
+		user.Email,
+	)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to post reply",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Model(&models.ChatMessage{}).
+		Where("id = ?", enhancedMessage.ID).
+		Update("origin", models.MessageOriginEmail).Error; err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to post reply",
+			"error":   err.Error(),
+		})
+	}
+	enhancedMessage.Origin = models.MessageOriginEmail
+
+	now := time.Now()
+	if err := tx.Model(&models.ChatThread{}).
+		Where("id = ?", threadID).
+		Updates(map[string]interface{}{
+			"updated_at":       now,
+			"last_activity_at": now,
+		}).Error; err != nil {
+		config.Logger.Warn("Failed to update thread timestamps",
+			zap.Error(err),
+			zap.String("threadID", threadID))
+	}
+
+	if err := ac.incrementUnreadCounts(tx, threadID, user.ID); err != nil {
+		config.Logger.Warn("Failed to increment unread counts",
+			zap.Error(err),
+			zap.String("threadID", threadID))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	ac.broadcastNewMessage(threadID, *enhancedMessage, user.ID)
+
+	config.Logger.Info("Chat email reply posted",
+		zap.String("messageID", enhancedMessage.ID.String()),
+		zap.String("threadID", threadID),
+		zap.String("userID", user.ID.String()))
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Reply posted",
+		"data":    fiber.Map{"message_id": enhancedMessage.ID},
+	})
+}
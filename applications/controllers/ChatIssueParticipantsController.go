@@ -8,6 +8,7 @@ import (
 	"time"
 	applicationRepositories "town-planning-backend/applications/repositories"
 	"town-planning-backend/applications/requests"
+	application_services "town-planning-backend/applications/services"
 	"town-planning-backend/config"
 	"town-planning-backend/db/models"
 	"town-planning-backend/token"
@@ -49,7 +50,7 @@ func (ac *ApplicationController) UnifiedParticipantController(c *fiber.Ctx) erro
 	currentUserID := payload.UserID
 
 	// Get user details for audit
-	user, err := ac.UserRepo.GetUserByID(currentUserID.String())
+	user, err := ac.UserRepo.GetUserProfileByID(currentUserID.String())
 	if err != nil {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"success": false,
@@ -83,7 +84,7 @@ func (ac *ApplicationController) UnifiedParticipantController(c *fiber.Ctx) erro
 	}
 
 	// Start transaction
-	tx := ac.DB.Begin()
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
 	if tx.Error != nil {
 		config.Logger.Error("Failed to begin database transaction for participant operation",
 			zap.Error(tx.Error),
@@ -212,7 +213,7 @@ func (ac *ApplicationController) handleAddSingleParticipant(
 ) (interface{}, string, error) {
 
 	// Verify target user exists
-	targetUser, err := ac.UserRepo.GetUserByID(request.UserID.String())
+	targetUser, err := ac.UserRepo.GetUserProfileByID(request.UserID.String())
 	if err != nil {
 		return nil, "", fmt.Errorf("target user not found")
 	}
@@ -229,7 +230,7 @@ func (ac *ApplicationController) handleAddSingleParticipant(
 	canManage := getBoolOrDefault(request.CanManage, false)
 
 	// Add participant with specific permissions
-	if err := ac.ApplicationRepo.AddParticipantToThread(
+	participant, err := ac.ApplicationRepo.AddParticipantToThread(
 		tx,
 		threadUUID,
 		request.UserID,
@@ -238,25 +239,26 @@ func (ac *ApplicationController) handleAddSingleParticipant(
 		canInvite,
 		canRemove,
 		canManage,
-	); err != nil {
+	)
+	if err != nil {
 		return nil, "", err
 	}
+	invited := participant.InvitationStatus == models.InvitationPending
 
 	// ==================== CREATE SINGLE PROFESSIONAL SYSTEM MESSAGE ====================
-	messageContent := ac.formatAddParticipantMessage(addedBy, targetUser, canInvite, canRemove, canManage)
-
-	systemMessage := models.ChatMessage{
-		ID:          uuid.New(),
-		ThreadID:    threadUUID,
-		SenderID:    addedBy.ID,
-		Content:     messageContent,
-		MessageType: models.MessageTypeSystem,
-		Status:      models.MessageStatusSent,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+	eventType := models.SystemEventParticipantAdded
+	if invited {
+		eventType = models.SystemEventParticipantInvited
+	}
+	systemMessage, err := ac.buildSystemMessage(threadUUID, addedBy.ID, eventType, application_services.SystemEventParams{
+		ActorName:   userFullName(addedBy),
+		TargetNames: []string{userFullName(targetUser)},
+		Permissions: application_services.ParticipantPermissions(canInvite, canRemove, canManage),
+	})
+	if err == nil {
+		err = tx.Create(&systemMessage).Error
 	}
-
-	if err := tx.Create(&systemMessage).Error; err != nil {
+	if err != nil {
 		config.Logger.Warn("Failed to create participant added message", zap.Error(err))
 	} else {
 		// Increment unread counts and broadcast
@@ -268,9 +270,10 @@ func (ac *ApplicationController) handleAddSingleParticipant(
 	}
 
 	result := fiber.Map{
-		"thread_id": threadUUID,
-		"user_id":   request.UserID,
-		"role":      role,
+		"thread_id":         threadUUID,
+		"user_id":           request.UserID,
+		"role":              role,
+		"invitation_status": participant.InvitationStatus,
 		"permissions": fiber.Map{
 			"can_invite": canInvite,
 			"can_remove": canRemove,
@@ -278,6 +281,10 @@ func (ac *ApplicationController) handleAddSingleParticipant(
 		},
 	}
 
+	if invited {
+		ac.notifyThreadInvitation(*participant, addedBy)
+		return result, "Invitation sent, the participant will join once they accept", nil
+	}
 	return result, "Participant added successfully", nil
 }
 
@@ -291,7 +298,7 @@ func (ac *ApplicationController) handleAddBulkParticipants(
 	// Verify all users exist and collect their details
 	addedUsers := make([]*models.User, 0, len(request.Participants))
 	for _, participant := range request.Participants {
-		user, err := ac.UserRepo.GetUserByID(participant.UserID.String())
+		user, err := ac.UserRepo.GetUserProfileByID(participant.UserID.String())
 		if err != nil {
 			return nil, "", fmt.Errorf("user %s not found", participant.UserID)
 		}
@@ -325,23 +332,43 @@ func (ac *ApplicationController) handleAddBulkParticipants(
 		return nil, "", err
 	}
 
-	// ==================== CREATE SINGLE PROFESSIONAL BULK ADD MESSAGE ====================
-	messageContent := ac.formatBulkAddParticipantsMessage(addedBy, addedUsers)
-
-	systemMessage := models.ChatMessage{
-		ID:          uuid.New(),
-		ThreadID:    threadUUID,
-		SenderID:    addedBy.ID,
-		Content:     messageContent,
-		MessageType: models.MessageTypeSystem,
-		Status:      models.MessageStatusSent,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+	// Participants from other departments were invited rather than added
+	usersByID := make(map[uuid.UUID]*models.User, len(addedUsers))
+	for _, user := range addedUsers {
+		usersByID[user.ID] = user
+	}
+	var joinedUsers, invitedUsers []*models.User
+	for _, participant := range createdParticipants {
+		if participant.InvitationStatus == models.InvitationPending {
+			invitedUsers = append(invitedUsers, usersByID[participant.UserID])
+			ac.notifyThreadInvitation(participant, addedBy)
+		} else {
+			joinedUsers = append(joinedUsers, usersByID[participant.UserID])
+		}
 	}
 
-	if err := tx.Create(&systemMessage).Error; err != nil {
-		config.Logger.Warn("Failed to create bulk participant added message", zap.Error(err))
-	} else {
+	// ==================== CREATE SINGLE PROFESSIONAL BULK ADD MESSAGE ====================
+	for _, event := range []struct {
+		eventType models.SystemEventType
+		users     []*models.User
+	}{
+		{models.SystemEventParticipantsAdded, joinedUsers},
+		{models.SystemEventParticipantInvited, invitedUsers},
+	} {
+		if len(event.users) == 0 {
+			continue
+		}
+		systemMessage, err := ac.buildSystemMessage(threadUUID, addedBy.ID, event.eventType, application_services.SystemEventParams{
+			ActorName:   userFullName(addedBy),
+			TargetNames: userFullNames(event.users),
+		})
+		if err == nil {
+			err = tx.Create(&systemMessage).Error
+		}
+		if err != nil {
+			config.Logger.Warn("Failed to create bulk participant added message", zap.Error(err))
+			continue
+		}
 		// Increment unread counts and broadcast
 		if err := ac.incrementUnreadCounts(tx, threadUUID.String(), addedBy.ID); err != nil {
 			config.Logger.Warn("Failed to increment unread counts for bulk add message", zap.Error(err))
@@ -354,17 +381,22 @@ func (ac *ApplicationController) handleAddBulkParticipants(
 	participantResponses := make([]fiber.Map, len(createdParticipants))
 	for i, participant := range createdParticipants {
 		participantResponses[i] = fiber.Map{
-			"user_id": participant.UserID,
-			"role":    participant.Role,
+			"user_id":           participant.UserID,
+			"role":              participant.Role,
+			"invitation_status": participant.InvitationStatus,
 		}
 	}
 
 	result := fiber.Map{
-		"thread_id":    threadUUID,
-		"added_count":  len(createdParticipants),
-		"participants": participantResponses,
+		"thread_id":     threadUUID,
+		"added_count":   len(joinedUsers),
+		"invited_count": len(invitedUsers),
+		"participants":  participantResponses,
 	}
 
+	if len(invitedUsers) > 0 {
+		return result, fmt.Sprintf("%d participants added successfully, %d invited", len(joinedUsers), len(invitedUsers)), nil
+	}
 	return result, fmt.Sprintf("%d participants added successfully", len(createdParticipants)), nil
 }
 
@@ -387,7 +419,7 @@ func (ac *ApplicationController) handleRemoveSingleParticipant(
 	}
 
 	// Get user who is being removed
-	removedUser, err := ac.UserRepo.GetUserByID(request.UserID.String())
+	removedUser, err := ac.UserRepo.GetUserProfileByID(request.UserID.String())
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to get removed user details")
 	}
@@ -403,20 +435,14 @@ func (ac *ApplicationController) handleRemoveSingleParticipant(
 	}
 
 	// ==================== CREATE SINGLE PROFESSIONAL REMOVAL MESSAGE ====================
-	messageContent := ac.formatRemoveParticipantMessage(removedBy, removedUser)
-
-	systemMessage := models.ChatMessage{
-		ID:          uuid.New(),
-		ThreadID:    threadUUID,
-		SenderID:    removedBy.ID,
-		Content:     messageContent,
-		MessageType: models.MessageTypeSystem,
-		Status:      models.MessageStatusSent,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+	systemMessage, err := ac.buildSystemMessage(threadUUID, removedBy.ID, models.SystemEventParticipantRemoved, application_services.SystemEventParams{
+		ActorName:   userFullName(removedBy),
+		TargetNames: []string{userFullName(removedUser)},
+	})
+	if err == nil {
+		err = tx.Create(&systemMessage).Error
 	}
-
-	if err := tx.Create(&systemMessage).Error; err != nil {
+	if err != nil {
 		config.Logger.Warn("Failed to create participant removed message", zap.Error(err))
 	} else {
 		// Increment unread counts and broadcast
@@ -445,7 +471,7 @@ func (ac *ApplicationController) handleRemoveBulkParticipants(
 	// Get removed users details for the message
 	removedUsers := make([]*models.User, 0, len(request.UserIDs))
 	for _, userID := range request.UserIDs {
-		user, err := ac.UserRepo.GetUserByID(userID.String())
+		user, err := ac.UserRepo.GetUserProfileByID(userID.String())
 		if err != nil {
 			config.Logger.Warn("Failed to get removed user details", zap.String("userID", userID.String()))
 			continue
@@ -465,20 +491,14 @@ func (ac *ApplicationController) handleRemoveBulkParticipants(
 	}
 
 	// ==================== CREATE SINGLE PROFESSIONAL BULK REMOVAL MESSAGE ====================
-	messageContent := ac.formatBulkRemoveParticipantsMessage(removedBy, removedUsers)
-
-	systemMessage := models.ChatMessage{
-		ID:          uuid.New(),
-		ThreadID:    threadUUID,
-		SenderID:    removedBy.ID,
-		Content:     messageContent,
-		MessageType: models.MessageTypeSystem,
-		Status:      models.MessageStatusSent,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+	systemMessage, err := ac.buildSystemMessage(threadUUID, removedBy.ID, models.SystemEventParticipantsRemoved, application_services.SystemEventParams{
+		ActorName:   userFullName(removedBy),
+		TargetNames: userFullNames(removedUsers),
+	})
+	if err == nil {
+		err = tx.Create(&systemMessage).Error
 	}
-
-	if err := tx.Create(&systemMessage).Error; err != nil {
+	if err != nil {
 		config.Logger.Warn("Failed to create bulk removal message", zap.Error(err))
 	} else {
 		// Increment unread counts and broadcast
@@ -540,6 +560,8 @@ func handleParticipantError(err error, operation string) *fiber.Error {
 	switch {
 	case strings.Contains(errorMsg, "already a participant"):
 		return fiber.NewError(fiber.StatusConflict, "User is already a participant in this thread")
+	case strings.Contains(errorMsg, "already invited"):
+		return fiber.NewError(fiber.StatusConflict, "User has already been invited to this thread")
 	case strings.Contains(errorMsg, "cannot remove thread owner"):
 		return fiber.NewError(fiber.StatusForbidden, "Cannot remove thread owner")
 	case strings.Contains(errorMsg, "participant not found"):
@@ -554,7 +576,8 @@ func handleParticipantError(err error, operation string) *fiber.Error {
 	}
 }
 
-// GetThreadParticipantsController gets all participants for a thread (unchanged)
+// GetThreadParticipantsController gets all participants for a thread, with whether each has it
+// open now and when they last did
 func (ac *ApplicationController) GetThreadParticipantsController(c *fiber.Ctx) error {
 	threadID := c.Params("threadId")
 	if threadID == "" {
@@ -573,16 +596,36 @@ func (ac *ApplicationController) GetThreadParticipantsController(c *fiber.Ctx) e
 		})
 	}
 
+	// Last seen comes from the participants' thread state; online from their open connections
+	lastSeen := map[uuid.UUID]*time.Time{}
+	if threadUUID, err := uuid.Parse(threadID); err == nil && ac.ReadReceiptSvc != nil {
+		states, err := ac.ReadReceiptSvc.ThreadState().GetThreadStates(threadUUID)
+		if err != nil {
+			config.Logger.Warn("Failed to load participants' last seen",
+				zap.Error(err),
+				zap.String("threadID", threadID))
+		}
+		for _, state := range states {
+			lastSeen[state.UserID] = state.LastSeenAt
+		}
+	}
+
 	// Transform response
 	participantResponses := make([]fiber.Map, len(participants))
 	for i, participant := range participants {
+		isOnline := false
+		if ac.WsHub != nil {
+			isOnline = ac.WsHub.IsUserInThread(threadID, participant.UserID)
+		}
 		participantResponses[i] = fiber.Map{
-			"id":        participant.ID,
-			"user_id":   participant.UserID,
-			"role":      participant.Role,
-			"is_active": participant.IsActive,
-			"added_at":  participant.AddedAt,
-			"added_by":  participant.AddedBy,
+			"id":           participant.ID,
+			"user_id":      participant.UserID,
+			"role":         participant.Role,
+			"is_active":    participant.IsActive,
+			"added_at":     participant.AddedAt,
+			"added_by":     participant.AddedBy,
+			"is_online":    isOnline,
+			"last_seen_at": lastSeen[participant.UserID],
 			"user": fiber.Map{
 				"id":         participant.User.ID,
 				"first_name": participant.User.FirstName,
@@ -617,7 +660,10 @@ func (ac *ApplicationController) createEnhancedMessage(message models.ChatMessag
 		ID:          message.ID,
 		Content:     message.Content,
 		MessageType: message.MessageType,
+		EventType:   message.EventType,
+		EventParams: message.EventParams,
 		Status:      message.Status,
+		Origin:      message.Origin,
 		CreatedAt:   message.CreatedAt.Format(time.RFC3339),
 		Sender: &applicationRepositories.UserSummary{
 			ID:        sender.ID,
@@ -639,74 +685,35 @@ func (ac *ApplicationController) createEnhancedMessage(message models.ChatMessag
 // controllers/chat_controller.go
 
 // Professional message formatting functions
-func (ac *ApplicationController) formatAddParticipantMessage(addedBy, targetUser *models.User, canInvite, canRemove, canManage bool) string {
-	baseMessage := fmt.Sprintf("%s %s added %s %s to the conversation",
-		addedBy.FirstName, addedBy.LastName,
-		targetUser.FirstName, targetUser.LastName)
-
-	// Add permission details if non-default
-	if canInvite || canRemove || canManage {
-		permissions := []string{}
-		if canInvite {
-			permissions = append(permissions, "invite")
-		}
-		if canRemove {
-			permissions = append(permissions, "remove")
-		}
-		if canManage {
-			permissions = append(permissions, "manage permissions")
-		}
-
-		if len(permissions) > 0 {
-			baseMessage += fmt.Sprintf(" with %s permissions", strings.Join(permissions, "/"))
-		}
+// buildSystemMessage creates an unsaved SYSTEM message for a thread from a structured event
+func (ac *ApplicationController) buildSystemMessage(
+	threadID uuid.UUID,
+	senderID uuid.UUID,
+	eventType models.SystemEventType,
+	params application_services.SystemEventParams,
+) (models.ChatMessage, error) {
+	message, err := application_services.NewSystemMessage(eventType, params)
+	if err != nil {
+		return message, err
 	}
 
-	return baseMessage
+	now := time.Now()
+	message.ID = uuid.New()
+	message.ThreadID = threadID
+	message.SenderID = senderID
+	message.CreatedAt = now
+	message.UpdatedAt = now
+	return message, nil
 }
 
-func (ac *ApplicationController) formatBulkAddParticipantsMessage(addedBy *models.User, addedUsers []*models.User) string {
-	if len(addedUsers) == 1 {
-		return fmt.Sprintf("%s %s added %s %s to the conversation",
-			addedBy.FirstName, addedBy.LastName,
-			addedUsers[0].FirstName, addedUsers[0].LastName)
-	} else if len(addedUsers) <= 3 {
-		userNames := make([]string, len(addedUsers))
-		for i, user := range addedUsers {
-			userNames[i] = fmt.Sprintf("%s %s", user.FirstName, user.LastName)
-		}
-		return fmt.Sprintf("%s %s added %s to the conversation",
-			addedBy.FirstName, addedBy.LastName,
-			strings.Join(userNames, ", "))
-	} else {
-		return fmt.Sprintf("%s %s added %d participants to the conversation",
-			addedBy.FirstName, addedBy.LastName,
-			len(addedUsers))
-	}
-}
-
-func (ac *ApplicationController) formatRemoveParticipantMessage(removedBy, removedUser *models.User) string {
-	return fmt.Sprintf("%s %s removed %s %s from the conversation",
-		removedBy.FirstName, removedBy.LastName,
-		removedUser.FirstName, removedUser.LastName)
+func userFullName(user *models.User) string {
+	return fmt.Sprintf("%s %s", user.FirstName, user.LastName)
 }
 
-func (ac *ApplicationController) formatBulkRemoveParticipantsMessage(removedBy *models.User, removedUsers []*models.User) string {
-	if len(removedUsers) == 1 {
-		return fmt.Sprintf("%s %s removed %s %s from the conversation",
-			removedBy.FirstName, removedBy.LastName,
-			removedUsers[0].FirstName, removedUsers[0].LastName)
-	} else if len(removedUsers) <= 3 {
-		userNames := make([]string, len(removedUsers))
-		for i, user := range removedUsers {
-			userNames[i] = fmt.Sprintf("%s %s", user.FirstName, user.LastName)
-		}
-		return fmt.Sprintf("%s %s removed %s from the conversation",
-			removedBy.FirstName, removedBy.LastName,
-			strings.Join(userNames, ", "))
-	} else {
-		return fmt.Sprintf("%s %s removed %d participants from the conversation",
-			removedBy.FirstName, removedBy.LastName,
-			len(removedUsers))
+func userFullNames(users []*models.User) []string {
+	names := make([]string, len(users))
+	for i, user := range users {
+		names[i] = userFullName(user)
 	}
+	return names
 }
@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	applicationRepositories "town-planning-backend/applications/repositories"
+	application_services "town-planning-backend/applications/services"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// requesterLanguage returns the language system messages should be rendered in: the
+// authenticated user's preference, falling back to the Accept-Language header.
+func (ac *ApplicationController) requesterLanguage(c *fiber.Ctx) string {
+	if payload, ok := c.Locals("user").(*token.Payload); ok && payload != nil {
+		if user, err := ac.UserRepo.GetUserProfileByID(payload.UserID.String()); err == nil && user.PreferredLanguage != "" {
+			return application_services.NormalizeLanguage(user.PreferredLanguage)
+		}
+	}
+	return application_services.NormalizeLanguage(c.AcceptsLanguages(
+		application_services.LanguageEnglish,
+		application_services.LanguageShona,
+		application_services.LanguageNdebele,
+	))
+}
+
+// localizeFrontendMessages renders structured system messages in the requester's language
+func localizeFrontendMessages(messages []applicationRepositories.FrontendChatMessage, language string) {
+	for i := range messages {
+		messages[i].Content = application_services.LocalizeSystemMessage(
+			messages[i].EventType, messages[i].EventParams, messages[i].Content, language)
+
+		if parent := messages[i].Parent; parent != nil {
+			parent.Content = application_services.LocalizeSystemMessage(
+				parent.EventType, parent.EventParams, parent.Content, language)
+		}
+	}
+}
+
+// localizeEnhancedMessages renders structured system messages in the requester's language
+func localizeEnhancedMessages(messages []*applicationRepositories.EnhancedChatMessage, language string) {
+	for _, message := range messages {
+		message.Content = application_services.LocalizeSystemMessage(
+			message.EventType, message.EventParams, message.Content, language)
+	}
+}
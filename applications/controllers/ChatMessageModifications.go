@@ -3,6 +3,7 @@ package controllers
 import (
 	"mime/multipart"
 	"time"
+	application_services "town-planning-backend/applications/services"
 	"town-planning-backend/config"
 	"town-planning-backend/db/models"
 	"town-planning-backend/token"
@@ -43,7 +44,7 @@ func (ac *ApplicationController) StarMessageController(c *fiber.Ctx) error {
 	}
 
 	// Start transaction
-	tx := ac.DB.Begin()
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
 	if tx.Error != nil {
 		config.Logger.Error("Failed to begin transaction for starring message",
 			zap.Error(tx.Error),
@@ -151,6 +152,25 @@ func (ac *ApplicationController) ReplyToMessageController(c *fiber.Ctx) error {
 		})
 	}
 
+	// Voice notes must be readable and within the council's length and size limits
+	if err := application_services.LoadVoiceNoteLimits().ValidateVoiceNotes(files); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+			"error":   "invalid_voice_note",
+		})
+	}
+
+	// Each attachment may be filed under its own document category, e.g. a revised certificate
+	categoryCodes, err := ac.parseAttachmentCategories(c, form, files)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+			"error":   "invalid_attachment_category",
+		})
+	}
+
 	// Get user from context
 	payload, ok := c.Locals("user").(*token.Payload)
 	if !ok || payload == nil {
@@ -163,7 +183,7 @@ func (ac *ApplicationController) ReplyToMessageController(c *fiber.Ctx) error {
 	userUUID := payload.UserID
 
 	// Get user details
-	user, err := ac.UserRepo.GetUserByID(userUUID.String())
+	user, err := ac.UserRepo.GetUserProfileByID(userUUID.String())
 	if err != nil {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"success": false,
@@ -180,7 +200,7 @@ func (ac *ApplicationController) ReplyToMessageController(c *fiber.Ctx) error {
 	}
 
 	// Start transaction
-	tx := ac.DB.Begin()
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
 	if tx.Error != nil {
 		config.Logger.Error("Failed to begin transaction for replying to message",
 			zap.Error(tx.Error),
@@ -203,7 +223,7 @@ func (ac *ApplicationController) ReplyToMessageController(c *fiber.Ctx) error {
 
 	// First, get the parent message to determine the thread ID
 	var parentMessage models.ChatMessage
-	if err := tx.Where("id = ? AND is_deleted = ?", parentMessageUUID, false).First(&parentMessage).Error; err != nil {
+	if err := tx.Preload("Thread").Where("id = ? AND is_deleted = ?", parentMessageUUID, false).First(&parentMessage).Error; err != nil {
 		tx.Rollback()
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"success": false,
@@ -211,6 +231,11 @@ func (ac *ApplicationController) ReplyToMessageController(c *fiber.Ctx) error {
 		})
 	}
 
+	if err := ac.ensureThreadWritable(&parentMessage.Thread, userUUID); err != nil {
+		tx.Rollback()
+		return threadNotWritableResponse(c, err)
+	}
+
 	// Get application ID from thread if available
 	var applicationID *uuid.UUID
 	if parentMessage.Thread.ApplicationID != uuid.Nil {
@@ -227,6 +252,7 @@ func (ac *ApplicationController) ReplyToMessageController(c *fiber.Ctx) error {
 		chatMessageType,
 		userUUID,
 		files,
+		categoryCodes,
 		applicationID,
 		user.Email,
 	)
@@ -265,6 +291,8 @@ func (ac *ApplicationController) ReplyToMessageController(c *fiber.Ctx) error {
 		})
 	}
 
+	ac.previewLinksAsync(parentMessage.ThreadID.String(), replyMessage.ID, replyMessage.Content)
+
 	config.Logger.Info("Reply message sent successfully",
 		zap.String("parentMessageID", messageID),
 		zap.String("replyMessageID", replyMessage.ID.String()),
@@ -307,7 +335,7 @@ func (ac *ApplicationController) DeleteMessageController(c *fiber.Ctx) error {
 	}
 
 	// Start transaction
-	tx := ac.DB.Begin()
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
 	if tx.Error != nil {
 		config.Logger.Error("Failed to begin transaction for deleting message",
 			zap.Error(tx.Error),
@@ -474,6 +502,8 @@ func (ac *ApplicationController) GetMessageThreadController(c *fiber.Ctx) error
 		})
 	}
 
+	localizeEnhancedMessages(threadMessages, ac.requesterLanguage(c))
+
 	return c.JSON(fiber.Map{
 		"success": true,
 		"data": fiber.Map{
@@ -0,0 +1,176 @@
+package controllers
+
+import (
+	"errors"
+	"strings"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ChatModeratePermission lets a user post in frozen threads and unfreeze them
+const ChatModeratePermission = "chat.moderate"
+
+// errThreadFrozen is returned when a message is sent to a frozen thread without chat.moderate
+var errThreadFrozen = errors.New("this conversation is read-only because the application has been finally decided")
+
+type UnfreezeThreadRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ensureThreadWritable refuses messages to a frozen thread unless the sender may moderate chats
+func (ac *ApplicationController) ensureThreadWritable(thread *models.ChatThread, userID uuid.UUID) error {
+	if !thread.IsFrozen {
+		return nil
+	}
+	allowed, err := ac.UserRepo.UserHasPermission(userID.String(), ChatModeratePermission)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return errThreadFrozen
+	}
+	return nil
+}
+
+// threadNotWritableResponse answers a message refused by ensureThreadWritable
+func threadNotWritableResponse(c *fiber.Ctx, err error) error {
+	if errors.Is(err, errThreadFrozen) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+			"error":   "thread_frozen",
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"success": false,
+		"message": "Failed to verify permissions",
+		"error":   err.Error(),
+	})
+}
+
+// broadcastClosingMessages pushes the closing messages of threads a final decision froze
+func (ac *ApplicationController) broadcastClosingMessages(messages []models.ChatMessage, deciderID uuid.UUID) {
+	if len(messages) == 0 {
+		return
+	}
+	decider, err := ac.UserRepo.GetUserProfileByID(deciderID.String())
+	if err != nil {
+		config.Logger.Warn("Failed to load decider for closing messages",
+			zap.Error(err),
+			zap.String("userID", deciderID.String()))
+		return
+	}
+	for _, message := range messages {
+		ac.broadcastNewMessage(message.ThreadID.String(), *ac.createEnhancedMessage(message, *decider), deciderID)
+	}
+}
+
+// UnfreezeThreadController reopens a thread frozen by the application's final decision, for
+// exceptional cases such as a decision being revisited. A reason is required and is posted in
+// the thread.
+func (ac *ApplicationController) UnfreezeThreadController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	threadID, err := uuid.Parse(c.Params("threadId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid thread ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var request UnfreezeThreadRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+	reason := strings.TrimSpace(request.Reason)
+	if reason == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "A reason for reopening the conversation is required",
+			"error":   "missing_reason",
+		})
+	}
+
+	user, err := ac.UserRepo.GetUserProfileByID(payload.UserID.String())
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Please log out and log in again",
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	thread, message, err := ac.ApplicationRepo.UnfreezeThread(tx, threadID, user, reason)
+	if err != nil {
+		tx.Rollback()
+		status := fiber.StatusInternalServerError
+		switch err.Error() {
+		case "chat thread not found":
+			status = fiber.StatusNotFound
+		case "chat thread is not frozen":
+			status = fiber.StatusConflict
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to reopen conversation",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("Frozen chat thread reopened",
+		zap.String("threadID", threadID.String()),
+		zap.String("userID", user.ID.String()),
+		zap.String("reason", reason))
+
+	enhanced := ac.createEnhancedMessage(*message, *user)
+	ac.broadcastNewMessage(threadID.String(), *enhanced, user.ID)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Conversation reopened",
+		"data": fiber.Map{
+			"thread":  thread,
+			"message": enhanced,
+		},
+	})
+}
@@ -0,0 +1,229 @@
+package controllers
+
+import (
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	documents_requests "town-planning-backend/documents/requests"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// coApplicantErrorStatus maps co-applicant repository errors to HTTP status codes
+func coApplicantErrorStatus(err error) int {
+	switch err.Error() {
+	case "application not found", "applicant not found", "co-applicant not found":
+		return fiber.StatusNotFound
+	case "applicant is already on the application", "application owners can no longer be changed",
+		"primary applicant cannot be removed":
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// AddCoApplicantController adds a joint owner to an application. The co-owner's ID document
+// is required and is uploaded in the same multipart request.
+func (ac *ApplicationController) AddCoApplicantController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	applicantID, err := uuid.Parse(c.FormValue("applicant_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid applicant ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	idDocument, err := c.FormFile("id_document")
+	if err != nil || idDocument == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "An ID document is required for each co-applicant",
+			"error":   "missing_id_document",
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	// The ID document belongs to the co-owner and is also filed against the application
+	documentRequest := &documents_requests.CreateDocumentRequest{
+		CategoryCode:  models.CoApplicantIDCategoryCode,
+		FileName:      idDocument.Filename,
+		ApplicantID:   &applicantID,
+		ApplicationID: &applicationID,
+		CreatedBy:     payload.UserID.String(),
+		FileType:      idDocument.Header.Get("Content-Type"),
+	}
+	response, err := ac.DocumentSvc.UnifiedCreateDocument(tx, c, documentRequest, nil, idDocument)
+	if err != nil || response == nil || response.Document == nil {
+		tx.Rollback()
+		message := "document service returned no document"
+		if err != nil {
+			message = err.Error()
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to store ID document",
+			"error":   message,
+		})
+	}
+
+	coApplicant, err := ac.ApplicationRepo.AddCoApplicant(tx, applicationID, applicantID, response.Document.ID, payload.UserID.String())
+	if err != nil {
+		tx.Rollback()
+		return c.Status(coApplicantErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to add co-applicant",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("Co-applicant added",
+		zap.String("applicationID", applicationID.String()),
+		zap.String("applicantID", applicantID.String()))
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Co-applicant added",
+		"data":    coApplicant,
+	})
+}
+
+// RemoveCoApplicantController takes a co-owner off an application
+func (ac *ApplicationController) RemoveCoApplicantController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+	applicantID, err := uuid.Parse(c.Params("applicantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid applicant ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	if err := ac.ApplicationRepo.RemoveCoApplicant(tx, applicationID, applicantID); err != nil {
+		tx.Rollback()
+		return c.Status(coApplicantErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to remove co-applicant",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("Co-applicant removed",
+		zap.String("applicationID", applicationID.String()),
+		zap.String("applicantID", applicantID.String()),
+		zap.String("removedBy", payload.UserID.String()))
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Co-applicant removed",
+	})
+}
+
+// GetApplicationCoApplicantsController lists an application's owners, primary applicant first
+func (ac *ApplicationController) GetApplicationCoApplicantsController(c *fiber.Ctx) error {
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	coApplicants, err := ac.ApplicationRepo.GetApplicationCoApplicants(applicationID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch co-applicants",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Co-applicants retrieved successfully",
+		"data":    coApplicants,
+	})
+}
@@ -0,0 +1,518 @@
+package controllers
+
+import (
+	"strings"
+	"time"
+	"town-planning-backend/applications/requests"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/token"
+	"town-planning-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// UpsertCollectionCalendarController creates or updates a department's collection calendar
+func (ac *ApplicationController) UpsertCollectionCalendarController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	var request requests.CollectionCalendarRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+
+	calendar := &models.CollectionCalendar{
+		DepartmentID:        request.DepartmentID,
+		OpeningTime:         request.OpeningTime,
+		ClosingTime:         request.ClosingTime,
+		WorkingDays:         request.WorkingDays,
+		SlotDurationMinutes: request.SlotDurationMinutes,
+		SlotCapacity:        request.SlotCapacity,
+		BookingHorizonDays:  request.BookingHorizonDays,
+		IsActive:            true,
+	}
+	if request.IsActive != nil {
+		calendar.IsActive = *request.IsActive
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	saved, err := ac.ApplicationRepo.UpsertCollectionCalendar(tx, calendar, payload.UserID.String())
+	if err != nil {
+		tx.Rollback()
+		statusCode := fiber.StatusBadRequest
+		if err.Error() == "department not found" {
+			statusCode = fiber.StatusNotFound
+		}
+		return c.Status(statusCode).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to save collection calendar",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Collection calendar saved successfully",
+		"data":    saved,
+	})
+}
+
+// GetCollectionCalendarsController lists every department's collection calendar
+func (ac *ApplicationController) GetCollectionCalendarsController(c *fiber.Ctx) error {
+	calendars, err := ac.ApplicationRepo.GetCollectionCalendars()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch collection calendars",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Collection calendars retrieved successfully",
+		"data":    calendars,
+	})
+}
+
+// GetAvailableCollectionSlotsController lists a department's open collection slots.
+// Query: days (optional, capped at the calendar's booking horizon).
+func (ac *ApplicationController) GetAvailableCollectionSlotsController(c *fiber.Ctx) error {
+	departmentID, err := uuid.Parse(c.Params("departmentId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid department ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	slots, err := ac.ApplicationRepo.GetAvailableCollectionSlots(departmentID, c.QueryInt("days", 0))
+	if err != nil {
+		statusCode := fiber.StatusInternalServerError
+		if err.Error() == "department has no active collection calendar" {
+			statusCode = fiber.StatusNotFound
+		}
+		return c.Status(statusCode).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch collection slots",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Collection slots retrieved successfully",
+		"data":    slots,
+	})
+}
+
+// BookCollectionAppointmentController books a collection slot for a permit that is ready and
+// emails the applicant a confirmation
+func (ac *ApplicationController) BookCollectionAppointmentController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var request requests.BookCollectionAppointmentRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	appointment, err := ac.ApplicationRepo.BookCollectionAppointment(
+		tx,
+		applicationID,
+		request.DepartmentID,
+		request.SlotStart,
+		request.Notes,
+		payload.UserID.String(),
+	)
+	if err != nil {
+		tx.Rollback()
+
+		statusCode := fiber.StatusInternalServerError
+		switch err.Error() {
+		case "application not found", "department has no active collection calendar":
+			statusCode = fiber.StatusNotFound
+		case "application already has a booked collection appointment", "slot is fully booked":
+			statusCode = fiber.StatusConflict
+		case "permit is not ready for collection", "slot is in the past",
+			"slot is beyond the booking horizon", "slot is not on the department calendar":
+			statusCode = fiber.StatusBadRequest
+		default:
+			config.Logger.Error("Failed to book collection appointment",
+				zap.Error(err),
+				zap.String("applicationID", applicationID.String()))
+		}
+		return c.Status(statusCode).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to book collection appointment",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	ac.sendCollectionConfirmation(appointment)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Collection appointment booked successfully",
+		"data":    appointment,
+	})
+}
+
+// sendCollectionConfirmation emails and texts the applicant in the background and records when
+// the confirmation went out
+func (ac *ApplicationController) sendCollectionConfirmation(appointment *models.CollectionAppointment) {
+	if appointment.Application == nil {
+		return
+	}
+	applicant := appointment.Application.Applicant
+	email := strings.TrimSpace(applicant.Email)
+	phone := strings.TrimSpace(applicant.PhoneNumber)
+	if phone != "" && !utils.SMSEnabled() {
+		phone = ""
+	}
+	if email == "" && phone == "" {
+		return
+	}
+
+	emailData := utils.CollectionConfirmationEmail{
+		ApplicantName: applicant.FullName,
+		PlanNumber:    appointment.Application.PlanNumber,
+		LongDate:      appointment.SlotStart.Format("Monday 2 January 2006"),
+		ShortDate:     appointment.SlotStart.Format("02/01/2006"),
+		StartTime:     appointment.SlotStart.Format("15:04"),
+		EndTime:       appointment.SlotEnd.Format("15:04"),
+	}
+	if appointment.Department != nil {
+		emailData.DepartmentName = appointment.Department.Name
+		if appointment.Department.OfficeLocation != nil {
+			emailData.Location = *appointment.Department.OfficeLocation
+		}
+	}
+
+	go func(appointmentID uuid.UUID) {
+		confirmed := false
+		if email != "" {
+			// Sent in the applicant's preferred language, or English if it has no translation
+			subject, message, _, err := utils.RenderEmailTemplate(utils.EmailCollectionConfirmation, applicant.PreferredLanguage, emailData)
+			if err != nil {
+				config.Logger.Warn("Failed to render collection confirmation",
+					zap.Error(err),
+					zap.String("appointmentID", appointmentID.String()))
+			} else if err := utils.SendApplicantEmail(utils.ApplicantEmail{
+				To:            email,
+				Subject:       subject,
+				Body:          message,
+				Template:      utils.EmailCollectionConfirmation,
+				ApplicantID:   &applicant.ID,
+				ApplicationID: &appointment.ApplicationID,
+				TrackClicks:   true,
+			}); err != nil {
+				config.Logger.Warn("Failed to send collection confirmation",
+					zap.Error(err),
+					zap.String("appointmentID", appointmentID.String()))
+			} else {
+				confirmed = true
+			}
+		}
+		if phone != "" {
+			body, language, err := utils.RenderSMSTemplate(utils.EmailCollectionConfirmation, applicant.PreferredLanguage, emailData)
+			if err != nil {
+				config.Logger.Warn("Failed to render collection confirmation SMS",
+					zap.Error(err),
+					zap.String("appointmentID", appointmentID.String()))
+			} else if err := utils.SendApplicantSMS(utils.ApplicantSMS{
+				To:            phone,
+				Body:          body,
+				Language:      language,
+				Template:      utils.EmailCollectionConfirmation,
+				ApplicantID:   &applicant.ID,
+				ApplicationID: &appointment.ApplicationID,
+			}); err != nil {
+				config.Logger.Warn("Failed to text collection confirmation",
+					zap.Error(err),
+					zap.String("appointmentID", appointmentID.String()))
+			} else {
+				confirmed = true
+			}
+		}
+
+		if !confirmed {
+			return
+		}
+		if err := ac.ApplicationRepo.MarkCollectionAppointmentConfirmed(appointmentID); err != nil {
+			config.Logger.Warn("Failed to record collection confirmation",
+				zap.Error(err),
+				zap.String("appointmentID", appointmentID.String()))
+		}
+	}(appointment.ID)
+}
+
+// CancelCollectionAppointmentController frees a booked collection slot
+func (ac *ApplicationController) CancelCollectionAppointmentController(c *fiber.Ctx) error {
+	return ac.collectionAppointmentAction(c, "cancel")
+}
+
+// MarkCollectionNoShowController flags a booked appointment that nobody turned up for
+func (ac *ApplicationController) MarkCollectionNoShowController(c *fiber.Ctx) error {
+	return ac.collectionAppointmentAction(c, "no-show")
+}
+
+// CompleteCollectionFollowUpController closes the follow-up on a no-show
+func (ac *ApplicationController) CompleteCollectionFollowUpController(c *fiber.Ctx) error {
+	return ac.collectionAppointmentAction(c, "follow-up")
+}
+
+func (ac *ApplicationController) collectionAppointmentAction(c *fiber.Ctx, action string) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	appointmentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid appointment ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var request requests.CollectionAppointmentActionRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": "Invalid request payload",
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	userID := payload.UserID.String()
+	var appointment *models.CollectionAppointment
+	switch action {
+	case "cancel":
+		appointment, err = ac.ApplicationRepo.CancelCollectionAppointment(tx, appointmentID, request.Reason, userID)
+	case "no-show":
+		appointment, err = ac.ApplicationRepo.MarkCollectionNoShow(tx, appointmentID, userID)
+	default:
+		appointment, err = ac.ApplicationRepo.CompleteCollectionFollowUp(tx, appointmentID, request.Notes, userID)
+	}
+	if err != nil {
+		tx.Rollback()
+		statusCode := fiber.StatusInternalServerError
+		switch err.Error() {
+		case "appointment not found":
+			statusCode = fiber.StatusNotFound
+		case "appointment is no longer booked", "appointment is not a no-show", "follow-up already completed":
+			statusCode = fiber.StatusConflict
+		case "appointment slot has not ended yet":
+			statusCode = fiber.StatusBadRequest
+		default:
+			config.Logger.Error("Failed to update collection appointment",
+				zap.Error(err),
+				zap.String("action", action),
+				zap.String("appointmentID", appointmentID.String()))
+		}
+		return c.Status(statusCode).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to update collection appointment",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Collection appointment updated successfully",
+		"data":    appointment,
+	})
+}
+
+// GetCollectionAppointmentsController lists the expected collections for the front desk.
+// Query: date (YYYY-MM-DD, defaults to today), department_id (optional).
+func (ac *ApplicationController) GetCollectionAppointmentsController(c *fiber.Ctx) error {
+	departmentID, err := optionalDepartmentID(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid department ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	day := time.Now()
+	if raw := c.Query("date"); raw != "" {
+		location := utils.DateLocation
+		if location == nil {
+			location = time.Local
+		}
+		day, err = time.ParseInLocation("2006-01-02", raw, location)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": "Invalid date, expected YYYY-MM-DD",
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	appointments, err := ac.ApplicationRepo.GetCollectionAppointmentsForDay(day, departmentID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch collection appointments",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Collection appointments retrieved successfully",
+		"data":    appointments,
+	})
+}
+
+// GetCollectionNoShowsController lists no-shows still awaiting follow-up.
+// Query: department_id (optional).
+func (ac *ApplicationController) GetCollectionNoShowsController(c *fiber.Ctx) error {
+	departmentID, err := optionalDepartmentID(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid department ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	appointments, err := ac.ApplicationRepo.GetCollectionNoShows(departmentID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch no-shows",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "No-shows retrieved successfully",
+		"data":    appointments,
+	})
+}
+
+func optionalDepartmentID(c *fiber.Ctx) (*uuid.UUID, error) {
+	raw := c.Query("department_id")
+	if raw == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
@@ -0,0 +1,307 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/token"
+	"town-planning-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// committeePackMaxImageBytes keeps very large scans out of the pack, which is rendered in memory
+const committeePackMaxImageBytes = 15 << 20
+
+// committeePackImageTypes are the key document formats the pack reproduces
+var committeePackImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// committeePackErrorStatus maps committee pack repository errors to HTTP status codes
+func committeePackErrorStatus(err error) int {
+	switch err.Error() {
+	case "application not found", "committee pack not found":
+		return fiber.StatusNotFound
+	case "committee pack is not ready":
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// RequestCommitteePackController queues a committee pack for an application and returns it to
+// poll. The pack is generated in the background and kept for download.
+func (ac *ApplicationController) RequestCommitteePackController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	user, err := ac.UserRepo.GetUserProfileByID(payload.UserID.String())
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not found",
+		})
+	}
+
+	pack, err := ac.ApplicationRepo.CreateCommitteePack(applicationID, user.ID, user.ID.String())
+	if err != nil {
+		return c.Status(committeePackErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to queue committee pack",
+			"error":   err.Error(),
+		})
+	}
+
+	go ac.generateCommitteePack(pack.ID, userFullName(user))
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"success": true,
+		"message": "Committee pack queued for generation",
+		"data":    pack,
+	})
+}
+
+// GetApplicationCommitteePacksController lists the committee packs generated for an application
+func (ac *ApplicationController) GetApplicationCommitteePacksController(c *fiber.Ctx) error {
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	packs, err := ac.ApplicationRepo.GetApplicationCommitteePacks(applicationID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch committee packs",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"packs":       packs,
+			"total_count": len(packs),
+		},
+	})
+}
+
+// GetCommitteePackController returns a committee pack's generation status
+func (ac *ApplicationController) GetCommitteePackController(c *fiber.Ctx) error {
+	packID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid committee pack ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	pack, err := ac.ApplicationRepo.GetCommitteePack(packID)
+	if err != nil {
+		return c.Status(committeePackErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch committee pack",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    pack,
+	})
+}
+
+// DownloadCommitteePackController streams a generated committee pack
+func (ac *ApplicationController) DownloadCommitteePackController(c *fiber.Ctx) error {
+	packID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid committee pack ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	pack, err := ac.ApplicationRepo.GetCommitteePack(packID)
+	if err == nil && (pack.Status != models.CommitteePackCompleted || pack.FilePath == nil) {
+		err = errors.New("committee pack is not ready")
+	}
+	if err != nil {
+		return c.Status(committeePackErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to download committee pack",
+			"error":   err.Error(),
+		})
+	}
+
+	file, err := ac.PackStorage.DownloadFile(*pack.FilePath)
+	if err != nil {
+		config.Logger.Error("Failed to open committee pack", zap.Error(err), zap.String("packID", packID.String()))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to download committee pack",
+			"error":   err.Error(),
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	if pack.FileName != nil {
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, *pack.FileName))
+	}
+	return c.Status(fiber.StatusOK).SendStream(file, int(pack.FileSize))
+}
+
+// generateCommitteePack renders and stores a queued pack. It is meant to run in its own
+// goroutine; failures are recorded on the pack.
+func (ac *ApplicationController) generateCommitteePack(packID uuid.UUID, preparedBy string) {
+	if err := ac.ApplicationRepo.StartCommitteePack(packID); err != nil {
+		config.Logger.Error("Failed to start committee pack", zap.Error(err), zap.String("packID", packID.String()))
+		return
+	}
+
+	fail := func(err error) {
+		config.Logger.Error("Committee pack generation failed", zap.Error(err), zap.String("packID", packID.String()))
+		if err := ac.ApplicationRepo.FailCommitteePack(packID, err.Error()); err != nil {
+			config.Logger.Error("Failed to record committee pack failure", zap.Error(err), zap.String("packID", packID.String()))
+		}
+	}
+
+	pack, err := ac.ApplicationRepo.GetCommitteePack(packID)
+	if err != nil {
+		fail(err)
+		return
+	}
+	contents, err := ac.ApplicationRepo.GetCommitteePackContents(pack.ApplicationID)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	source := utils.CommitteePackSource{
+		Application:    contents.Application,
+		Assignments:    contents.Assignments,
+		Decisions:      contents.Decisions,
+		FinalApprovals: contents.FinalApprovals,
+		Issues:         contents.Issues,
+		PreparedBy:     preparedBy,
+	}
+	var notReproduced []string
+	for _, document := range contents.KeyDocuments {
+		packDocument := utils.CommitteePackDocument{Document: document}
+		image, err := loadCommitteePackImage(document)
+		if err != nil {
+			config.Logger.Warn("Failed to load key document for committee pack",
+				zap.Error(err),
+				zap.String("packID", packID.String()),
+				zap.String("documentID", document.ID.String()))
+		}
+		if image == "" {
+			notReproduced = append(notReproduced, fmt.Sprintf("%s: %s", document.FileName, utils.CommitteePackDocumentNote(document)))
+		}
+		packDocument.Image = image
+		source.Documents = append(source.Documents, packDocument)
+	}
+
+	var pdf bytes.Buffer
+	if err := utils.GenerateCommitteePack(source, &pdf); err != nil {
+		fail(err)
+		return
+	}
+
+	fileName := fmt.Sprintf("COMMITTEE_PACK_%s_%s.pdf",
+		cleanStringForFilename(contents.Application.PlanNumber),
+		time.Now().Format("20060102_150405"))
+	filePath := filepath.Join(pack.ApplicationID.String(), fileName)
+	if _, err := ac.PackStorage.UploadFileFromReader(bytes.NewReader(pdf.Bytes()), filePath); err != nil {
+		fail(fmt.Errorf("failed to store committee pack: %w", err))
+		return
+	}
+
+	var pageNotes *string
+	if len(notReproduced) > 0 {
+		notes := strings.Join(notReproduced, "\n")
+		pageNotes = &notes
+	}
+	if err := ac.ApplicationRepo.CompleteCommitteePack(packID, fileName, filePath, int64(pdf.Len()), pageNotes); err != nil {
+		_ = ac.PackStorage.DeleteFile(filePath)
+		fail(fmt.Errorf("failed to complete committee pack: %w", err))
+		return
+	}
+
+	config.Logger.Info("Committee pack generated",
+		zap.String("packID", packID.String()),
+		zap.String("applicationID", pack.ApplicationID.String()),
+		zap.Int("keyDocuments", len(source.Documents)),
+		zap.Int("fileSize", pdf.Len()))
+}
+
+// loadCommitteePackImage reads an image key document as a data URI. Other formats, and images
+// too large to embed, come back empty and are only listed in the pack.
+func loadCommitteePackImage(document models.Document) (template.URL, error) {
+	if !committeePackImageTypes[document.MimeType] {
+		return "", nil
+	}
+
+	// Document paths are local upload paths
+	file, err := os.Open(document.FilePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, committeePackMaxImageBytes+1))
+	if err != nil {
+		return "", err
+	}
+	if len(data) > committeePackMaxImageBytes {
+		return "", nil
+	}
+	return template.URL(fmt.Sprintf("data:%s;base64,%s", document.MimeType, base64.StdEncoding.EncodeToString(data))), nil
+}
+
+// FailInterruptedCommitteePacks fails packs a previous run queued but never finished, so their
+// requesters know to ask again
+func (ac *ApplicationController) FailInterruptedCommitteePacks() {
+	failed, err := ac.ApplicationRepo.FailInterruptedCommitteePacks()
+	if err != nil {
+		config.Logger.Error("Failed to fail interrupted committee packs", zap.Error(err))
+		return
+	}
+	if failed > 0 {
+		config.Logger.Warn("Failed committee packs interrupted by restart", zap.Int64("count", failed))
+	}
+}
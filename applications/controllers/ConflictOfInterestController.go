@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"fmt"
+	"town-planning-backend/applications/requests"
+	"town-planning-backend/config"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// DeclareConflictOfInterestController records the current reviewer's conflict-of-interest declaration.
+// Declaring a conflict recuses the reviewer and passes their review to a backup member.
+func (ac *ApplicationController) DeclareConflictOfInterestController(c *fiber.Ctx) error {
+	var request requests.ConflictOfInterestDeclarationRequest
+	applicationID := c.Params("id")
+
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	userUUID := payload.UserID
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	result, err := ac.ApplicationRepo.DeclareConflictOfInterest(
+		tx,
+		applicationID,
+		userUUID,
+		request.HasConflict,
+		request.Relationship,
+		request.Details,
+	)
+	if err != nil {
+		tx.Rollback()
+
+		statusCode := fiber.StatusInternalServerError
+		switch err.Error() {
+		case "application not found":
+			statusCode = fiber.StatusNotFound
+		case "user not authorized to review this application", "final approver cannot be recused automatically":
+			statusCode = fiber.StatusForbidden
+		case "conflict of interest already declared", "decision already made, revoke it before declaring a conflict":
+			statusCode = fiber.StatusConflict
+		case "no active group assignment found for this application",
+			"details are required when confirming no conflict",
+			"relationship is required when declaring a conflict":
+			statusCode = fiber.StatusBadRequest
+		default:
+			config.Logger.Error("Failed to record conflict of interest declaration",
+				zap.Error(err),
+				zap.String("applicationID", applicationID),
+				zap.String("userID", userUUID.String()))
+		}
+
+		return c.Status(statusCode).JSON(fiber.Map{
+			"success": false,
+			"message": fmt.Sprintf("Failed to record declaration: %s", err.Error()),
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not commit database transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	message := "No conflict of interest recorded"
+	if result.Recused {
+		message = "Conflict of interest declared, reviewer recused"
+		if result.BackupMember == nil {
+			message = "Conflict of interest declared, reviewer recused but no backup member was available"
+		}
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": message,
+		"data": fiber.Map{
+			"declaration":   result.Declaration,
+			"recused":       result.Recused,
+			"backup_member": result.BackupMember,
+		},
+	})
+}
+
+// GetConflictDeclarationsController lists the conflict-of-interest declarations on an application
+func (ac *ApplicationController) GetConflictDeclarationsController(c *fiber.Ctx) error {
+	applicationID := c.Params("id")
+
+	declarations, err := ac.ApplicationRepo.GetConflictDeclarations(applicationID)
+	if err != nil {
+		config.Logger.Error("Failed to fetch conflict of interest declarations",
+			zap.Error(err),
+			zap.String("applicationID", applicationID))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch declarations",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Declarations retrieved successfully",
+		"data":    declarations,
+	})
+}
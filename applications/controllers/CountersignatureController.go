@@ -0,0 +1,428 @@
+package controllers
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"town-planning-backend/applications/requests"
+	"town-planning-backend/config"
+	documents_requests "town-planning-backend/documents/requests"
+	"town-planning-backend/token"
+	"town-planning-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// countersignatureErrorStatus maps countersignature repository errors to HTTP status codes
+func countersignatureErrorStatus(err error) int {
+	switch err.Error() {
+	case "certificate not found", "engineer not found", "countersignature not found":
+		return fiber.StatusNotFound
+	case "certificate was routed to a different engineer":
+		return fiber.StatusForbidden
+	case "certificate already awaiting countersignature", "certificate already countersigned",
+		"countersignature is not pending", "certificate changed since it was routed":
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// RouteCertificateForCountersignController sends an application's engineering certificate
+// to a council engineer for countersigning
+func (ac *ApplicationController) RouteCertificateForCountersignController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var request requests.RouteCountersignRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+	if request.DocumentID == uuid.Nil || request.EngineerID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Document and engineer are required",
+			"error":   "missing_fields",
+		})
+	}
+
+	canSign, err := ac.UserRepo.UserHasPermission(request.EngineerID.String(), "document.countersign")
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to check engineer permissions",
+			"error":   err.Error(),
+		})
+	}
+	if !canSign {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Selected user cannot countersign engineering certificates",
+			"error":   "engineer_not_authorised",
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	countersignature, err := ac.ApplicationRepo.RouteCertificateForCountersign(
+		tx,
+		applicationID,
+		request.DocumentID,
+		request.EngineerID,
+		request.Notes,
+		payload.UserID,
+	)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(countersignatureErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to route certificate for countersigning",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("Engineering certificate routed for countersigning",
+		zap.String("applicationID", applicationID.String()),
+		zap.String("documentID", request.DocumentID.String()),
+		zap.String("engineerID", request.EngineerID.String()))
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Certificate routed for countersigning",
+		"data":    countersignature,
+	})
+}
+
+// CountersignCertificateController records the engineer's countersignature and stores the
+// generated countersignature sheet against the application
+func (ac *ApplicationController) CountersignCertificateController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	countersignatureID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid countersignature ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var request requests.CountersignDecisionRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": "Invalid request payload",
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	pdfPath := ""
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			if pdfPath != "" {
+				os.Remove(pdfPath)
+			}
+			panic(r)
+		}
+	}()
+
+	countersignature, err := ac.ApplicationRepo.CountersignCertificate(tx, countersignatureID, payload.UserID, request.Comment)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(countersignatureErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to countersign certificate",
+			"error":   err.Error(),
+		})
+	}
+
+	safePlanNumber := strings.ReplaceAll(countersignature.Application.PlanNumber, "/", "_")
+	filename := fmt.Sprintf("Countersignature_%s_%s.pdf", safePlanNumber, time.Now().Format("20060102_150405"))
+
+	pdfPath, err = utils.GenerateCountersignatureSheet(countersignature, filename)
+	if err != nil {
+		tx.Rollback()
+		config.Logger.Error("Failed to generate countersignature sheet",
+			zap.String("countersignatureID", countersignatureID.String()),
+			zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to generate countersignature sheet",
+			"error":   err.Error(),
+		})
+	}
+
+	pdfBytes, err := os.ReadFile(pdfPath)
+	if err != nil {
+		tx.Rollback()
+		os.Remove(pdfPath)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to read countersignature sheet",
+			"error":   err.Error(),
+		})
+	}
+
+	response, err := ac.DocumentSvc.UnifiedCreateDocument(
+		tx,
+		c,
+		&documents_requests.CreateDocumentRequest{
+			CategoryCode:  "COUNTERSIGNED_CERTIFICATE",
+			FileName:      filename,
+			ApplicationID: &countersignature.ApplicationID,
+			ApplicantID:   &countersignature.Application.Applicant.ID,
+			CreatedBy:     payload.UserID.String(),
+			FileType:      "application/pdf",
+		},
+		pdfBytes,
+		nil,
+	)
+	if err != nil {
+		tx.Rollback()
+		os.Remove(pdfPath)
+		config.Logger.Error("Failed to store countersignature sheet",
+			zap.String("countersignatureID", countersignatureID.String()),
+			zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to store countersignature sheet",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := ac.ApplicationRepo.AttachCountersignatureDocument(tx, countersignatureID, response.ID); err != nil {
+		tx.Rollback()
+		os.Remove(pdfPath)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to attach countersignature sheet",
+			"error":   err.Error(),
+		})
+	}
+	countersignature.AnnotatedDocumentID = &response.ID
+
+	if err := tx.Commit().Error; err != nil {
+		os.Remove(pdfPath)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	// The document service keeps its own copy
+	if err := os.Remove(pdfPath); err != nil {
+		config.Logger.Warn("Failed to cleanup countersignature sheet",
+			zap.String("pdfPath", pdfPath),
+			zap.Error(err))
+	}
+
+	config.Logger.Info("Engineering certificate countersigned",
+		zap.String("countersignatureID", countersignatureID.String()),
+		zap.String("applicationID", countersignature.ApplicationID.String()),
+		zap.String("engineerID", payload.UserID.String()))
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Certificate countersigned successfully",
+		"data": fiber.Map{
+			"countersignature": countersignature,
+			"document_id":      response.ID,
+			"file_path":        response.Document.FilePath,
+		},
+	})
+}
+
+// DeclineCountersignatureController records the engineer declining to countersign a certificate
+func (ac *ApplicationController) DeclineCountersignatureController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	countersignatureID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid countersignature ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var request requests.CountersignDecisionRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+	if request.Comment == nil || strings.TrimSpace(*request.Comment) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "A reason is required to decline a countersignature",
+			"error":   "missing_reason",
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	countersignature, err := ac.ApplicationRepo.DeclineCountersignature(tx, countersignatureID, payload.UserID, strings.TrimSpace(*request.Comment))
+	if err != nil {
+		tx.Rollback()
+		return c.Status(countersignatureErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to decline countersignature",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("Engineering certificate countersignature declined",
+		zap.String("countersignatureID", countersignatureID.String()),
+		zap.String("engineerID", payload.UserID.String()))
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Countersignature declined",
+		"data":    countersignature,
+	})
+}
+
+// GetApplicationCountersignaturesController lists the countersignature history of an application
+func (ac *ApplicationController) GetApplicationCountersignaturesController(c *fiber.Ctx) error {
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	countersignatures, err := ac.ApplicationRepo.GetApplicationCountersignatures(applicationID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch countersignatures",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Countersignatures retrieved successfully",
+		"data":    countersignatures,
+	})
+}
+
+// GetMyPendingCountersignaturesController lists certificates waiting on the current engineer
+func (ac *ApplicationController) GetMyPendingCountersignaturesController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	countersignatures, err := ac.ApplicationRepo.GetPendingCountersignatures(payload.UserID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch pending countersignatures",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Pending countersignatures retrieved successfully",
+		"data":    countersignatures,
+	})
+}
@@ -9,7 +9,9 @@ import (
 	"town-planning-backend/config"
 	"town-planning-backend/db/models"
 	documents_requests "town-planning-backend/documents/requests"
+	"town-planning-backend/settings"
 	"town-planning-backend/token"
+	user_services "town-planning-backend/users/services"
 	"town-planning-backend/utils"
 
 	"github.com/gofiber/fiber/v2"
@@ -37,6 +39,9 @@ type CreateApplicationRequest struct {
 	Status               string          `json:"status" validate:"required"`
 	PaymentStatus        string          `json:"payment_status" validate:"required"`
 	CreatedBy            string          `json:"created_by" validate:"required,email"`
+
+	// Required to accept the application when the stand's rates account does not clear
+	RatesOverrideReason *string `json:"rates_override_reason"`
 }
 
 // CreateApplication handles the creation of a new application
@@ -53,6 +58,73 @@ func (ac *ApplicationController) CreateApplicationController(c *fiber.Ctx) error
 		})
 	}
 
+	if problems := validateCreateApplicationRequest(req); len(problems) > 0 {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application",
+			"error":   "validation_failed",
+			"data":    fiber.Map{"errors": problems},
+		})
+	}
+
+	return ac.submitApplication(c, req, nil)
+}
+
+// validateCreateApplicationRequest checks a create-application form against the rules in its
+// validate tags, returning every problem found. Forms sent directly and forms submitted from a
+// draft both go through it.
+func validateCreateApplicationRequest(req CreateApplicationRequest) []string {
+	problems := []string{}
+
+	if !req.PlanArea.IsPositive() {
+		problems = append(problems, "plan_area must be greater than zero")
+	}
+	if req.StandID == uuid.Nil {
+		problems = append(problems, "stand_id is required")
+	}
+	if _, err := uuid.Parse(req.ApplicantID); err != nil {
+		problems = append(problems, "applicant_id must be a valid ID")
+	}
+	if req.AssignedGroupID == nil || *req.AssignedGroupID == uuid.Nil {
+		problems = append(problems, "assigned_group_id is required")
+	}
+	if _, err := uuid.Parse(req.TariffID); err != nil {
+		problems = append(problems, "tariff_id must be a valid ID")
+	}
+	if _, err := uuid.Parse(req.PropertyTypeID); err != nil {
+		problems = append(problems, "property_type_id must be a valid ID")
+	}
+	if req.DevelopmentLevy.IsNegative() {
+		problems = append(problems, "development_levy cannot be negative")
+	}
+	if req.VATAmount.IsNegative() {
+		problems = append(problems, "vat_amount cannot be negative")
+	}
+	if req.TotalCost.IsNegative() {
+		problems = append(problems, "total_cost cannot be negative")
+	}
+	if req.EstimatedCost.IsNegative() {
+		problems = append(problems, "estimated_cost cannot be negative")
+	}
+	if strings.TrimSpace(req.Status) == "" {
+		problems = append(problems, "status is required")
+	}
+	if strings.TrimSpace(req.PaymentStatus) == "" {
+		problems = append(problems, "payment_status is required")
+	}
+	if !user_services.ValidateEmailFormat(req.CreatedBy) {
+		problems = append(problems, "created_by must be a valid email address")
+	}
+	if req.ArchitectEmail != nil && *req.ArchitectEmail != "" && !user_services.ValidateEmailFormat(*req.ArchitectEmail) {
+		problems = append(problems, "architect_email must be a valid email address")
+	}
+
+	return problems
+}
+
+// submitApplication creates an application from a create-application form, either sent directly
+// or submitted from a draft. A submitted draft is closed in the same transaction.
+func (ac *ApplicationController) submitApplication(c *fiber.Ctx, req CreateApplicationRequest, draftID *uuid.UUID) error {
 	// Get authenticated user
 	payload, ok := c.Locals("user").(*token.Payload)
 	if !ok || payload == nil {
@@ -71,9 +143,66 @@ func (ac *ApplicationController) CreateApplicationController(c *fiber.Ctx) error
 		})
 	}
 
+	// Check the stand's rates account before accepting the application. Billing is queried
+	// outside the transaction so a slow billing system holds no locks.
+	var ratesClearance *models.RatesClearance
+	ratesOverrideReason := ""
+	if ac.RatesClearanceSvc.Enabled() {
+		var stand models.Stand
+		if err := ac.DB.Where("id = ?", req.StandID).First(&stand).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"success": false,
+					"message": "Stand not found",
+					"error":   "invalid_stand",
+				})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"message": "Failed to verify stand",
+				"error":   err.Error(),
+			})
+		}
+
+		ratesClearance = ac.RatesClearanceSvc.CheckStand(c.Context(), &stand)
+		if !ratesClearance.AllowsAcceptance() {
+			if req.RatesOverrideReason != nil {
+				ratesOverrideReason = strings.TrimSpace(*req.RatesOverrideReason)
+			}
+			if ratesOverrideReason == "" {
+				return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+					"success": false,
+					"message": "Stand rates account is not clear",
+					"error":   "rates_not_cleared",
+					"data":    ratesClearance,
+				})
+			}
+
+			canOverride, err := ac.UserRepo.UserHasPermission(userUUID.String(), RatesOverridePermission)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"success": false,
+					"message": "Failed to check rates override permission",
+					"error":   err.Error(),
+				})
+			}
+			if !canOverride {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+					"success": false,
+					"message": "You are not allowed to override rates clearance",
+					"error":   "rates_override_forbidden",
+					"data":    ratesClearance,
+				})
+			}
+		}
+	}
+
+	// Check the applicant owns the stand, also outside the transaction
+	deedsVerification := ac.verifyStandOwnership(c, req.StandID, req.ApplicantID)
+
 	// Start transaction
 	config.Logger.Info("Starting transaction for application creation")
-	tx := ac.DB.Session(&gorm.Session{}).WithContext(c.Context()).Begin()
+	tx := ac.DB.Session(&gorm.Session{}).WithContext(c.UserContext()).Begin()
 	if tx.Error != nil {
 		config.Logger.Error("Failed to start transaction", zap.Error(tx.Error))
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -225,6 +354,88 @@ func (ac *ApplicationController) CreateApplicationController(c *fiber.Ctx) error
 		})
 	}
 
+	// The applicant is the application's primary owner; co-owners are added afterwards
+	if err := ac.ApplicationRepo.SetPrimaryCoApplicant(tx, createdApplication.ID, createdApplication.ApplicantID, req.CreatedBy); err != nil {
+		config.Logger.Error("Failed to record primary applicant", zap.Error(err))
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to record primary applicant",
+			"error":   err.Error(),
+		})
+	}
+
+	// Record the rates clearance, and the override when the stand did not clear
+	if ratesClearance != nil {
+		if _, err := ac.ApplicationRepo.RecordRatesClearance(tx, createdApplication.ID, ratesClearance, req.CreatedBy); err != nil {
+			config.Logger.Error("Failed to record rates clearance", zap.Error(err))
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"message": "Failed to record rates clearance",
+				"error":   err.Error(),
+			})
+		}
+		if ratesOverrideReason != "" {
+			if _, err := ac.ApplicationRepo.OverrideRatesClearance(tx, createdApplication.ID, ratesOverrideReason, userUUID); err != nil {
+				config.Logger.Error("Failed to record rates clearance override", zap.Error(err))
+				tx.Rollback()
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"success": false,
+					"message": "Failed to record rates clearance override",
+					"error":   err.Error(),
+				})
+			}
+		}
+	}
+
+	// Record the ownership check; approvers are warned when it failed
+	if deedsVerification != nil {
+		if _, err := ac.ApplicationRepo.RecordDeedsVerification(tx, createdApplication.ID, deedsVerification, req.CreatedBy); err != nil {
+			config.Logger.Error("Failed to record deeds verification", zap.Error(err))
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"message": "Failed to record deeds verification",
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	// Geo-validate the stand; stands outside the council or ward boundaries go to boundary review
+	var stand models.Stand
+	if err := tx.Where("id = ?", req.StandID).First(&stand).Error; err == nil {
+		if _, err := ac.checkApplicationBoundary(tx, createdApplication.ID, &stand, req.CreatedBy); err != nil {
+			config.Logger.Error("Failed to check application boundary", zap.Error(err))
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"message": "Failed to check application boundary",
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	// Score the application's risk; high-risk applications go to the senior approval group
+	assignedGroupID := *req.AssignedGroupID
+	riskAssessment, _, err := ac.assessApplicationRisk(tx, createdApplication, req.AssignedGroupID, req.CreatedBy)
+	if err != nil {
+		config.Logger.Error("Failed to assess application risk", zap.Error(err))
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to assess application risk",
+			"error":   err.Error(),
+		})
+	}
+	if riskAssessment.RoutedToGroupID != nil {
+		assignedGroupID = *riskAssessment.RoutedToGroupID
+		config.Logger.Info("Routing high-risk application to senior approval group",
+			zap.String("applicationID", createdApplication.ID.String()),
+			zap.String("requestedGroupID", req.AssignedGroupID.String()),
+			zap.String("seniorGroupID", assignedGroupID.String()))
+	}
+
 	// Generate quotation filename - remove slashes from plan number
 	safePlanNumber := strings.ReplaceAll(createdApplication.PlanNumber, "/", "_")
 	filename := fmt.Sprintf("quotation_%s_%s.pdf", safePlanNumber, time.Now().Format("20060102_150405"))
@@ -370,6 +581,12 @@ func (ac *ApplicationController) CreateApplicationController(c *fiber.Ctx) error
 		Preload("Stand").
 		Preload("Tariff.DevelopmentCategory").
 		Preload("VATRate").
+		Preload("RatesClearances", func(db *gorm.DB) *gorm.DB {
+			return db.Order("checked_at DESC, created_at DESC")
+		}).
+		Preload("BoundaryChecks", func(db *gorm.DB) *gorm.DB {
+			return db.Order("checked_at DESC, created_at DESC")
+		}).
 		First(createdApplication, createdApplication.ID).Error; err != nil {
 		config.Logger.Error("Failed to preload application relationships", zap.Error(err))
 		tx.Rollback()
@@ -380,16 +597,52 @@ func (ac *ApplicationController) CreateApplicationController(c *fiber.Ctx) error
 		})
 	}
 
-	// Assign the application to the approval group
-	_, err = ac.ApplicantRepo.AssignApplicationToGroup(tx, createdApplication.ID.String(), *req.AssignedGroupID, req.CreatedBy, nil, userUUID)
-	if err != nil {
-		config.Logger.Error("Failed to assign application to group", zap.Error(err))
-		tx.Rollback()
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to assign application to group",
-			"error":   err.Error(),
-		})
+	// Assign the application to the approval group, or queue it for technicians to pre-screen
+	// first; it reaches the group once it passes
+	var quorumWarning *models.QuorumCheck
+	if settings.Bool(settings.ApplicationPreScreening) {
+		if _, err := ac.ApplicationRepo.QueueForPreScreening(tx, createdApplication.ID, assignedGroupID); err != nil {
+			config.Logger.Error("Failed to queue application for pre-screening", zap.Error(err))
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"message": "Failed to queue application for pre-screening",
+				"error":   err.Error(),
+			})
+		}
+		createdApplication.Status = models.PreScreeningApplication
+	} else {
+		assignment, err := ac.ApplicantRepo.AssignApplicationToGroup(tx, createdApplication.ID.String(), assignedGroupID, req.CreatedBy, nil, userUUID)
+		if err != nil {
+			config.Logger.Error("Failed to assign application to group", zap.Error(err))
+			tx.Rollback()
+			if quorumErr := quorumNotMet(err); quorumErr != nil {
+				return c.Status(fiber.StatusConflict).JSON(quorumConflictBody("Approval group cannot decide applications right now", quorumErr))
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"message": "Failed to assign application to group",
+				"error":   err.Error(),
+			})
+		}
+		quorumWarning = assignment.Quorum
+	}
+
+	// Close the draft the application was entered in
+	if draftID != nil {
+		if err := ac.ApplicationRepo.PromoteApplicationDraft(tx, *draftID, createdApplication.ID); err != nil {
+			config.Logger.Error("Failed to promote application draft", zap.Error(err))
+			tx.Rollback()
+			statusCode := fiber.StatusInternalServerError
+			if err.Error() == "application draft is no longer open" {
+				statusCode = fiber.StatusConflict
+			}
+			return c.Status(statusCode).JSON(fiber.Map{
+				"success": false,
+				"message": "Failed to submit application draft",
+				"error":   err.Error(),
+			})
+		}
 	}
 
 	// Commit the transaction after all operations succeed
@@ -408,7 +661,11 @@ func (ac *ApplicationController) CreateApplicationController(c *fiber.Ctx) error
 		"success": true,
 		"message": "Application created successfully",
 		"data": fiber.Map{
-			"application": createdApplication,
+			"application":         createdApplication,
+			"risk_assessment":     riskAssessment,
+			"processing_estimate": ac.processingEstimateFor(createdApplication),
+			"quorum_warning":      quorumWarning,
+			"deeds_verification":  deedsVerification,
 			"quotation": fiber.Map{
 				"document_id":  response.ID,
 				"filename":     filename,
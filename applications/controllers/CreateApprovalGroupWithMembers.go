@@ -11,15 +11,16 @@ import (
 )
 
 type CreateApprovalGroupRequest struct {
-	Name                 string                       `json:"name"`
-	Description          *string                      `json:"description"`
-	Type                 models.ApprovalGroupType     `json:"type"`
-	RequiresAllApprovals bool                         `json:"requires_all_approvals"`
-	MinimumApprovals     int                          `json:"minimum_approvals"`
-	AutoAssignBackups    bool                         `json:"auto_assign_backups"`
-	IsActive             bool                         `json:"is_active"`
-	CreatedBy            string                       `json:"created_by"`
-	Members              []ApprovalGroupMemberRequest `json:"members"`
+	Name                 string                        `json:"name"`
+	Description          *string                       `json:"description"`
+	Type                 models.ApprovalGroupType      `json:"type"`
+	RequiresAllApprovals *bool                         `json:"requires_all_approvals"` // Defaults to true
+	MinimumApprovals     int                           `json:"minimum_approvals"`
+	AutoAssignBackups    bool                          `json:"auto_assign_backups"`
+	CommentPolicy        *models.DecisionCommentPolicy `json:"comment_policy"` // Defaults to REJECTIONS
+	IsActive             bool                          `json:"is_active"`
+	CreatedBy            string                        `json:"created_by"`
+	Members              []ApprovalGroupMemberRequest  `json:"members"`
 }
 
 type ApprovalGroupMemberRequest struct {
@@ -35,6 +36,8 @@ type ApprovalGroupMemberRequest struct {
 	AddedBy            string                    `json:"added_by"`
 	// NEW: Flag to mark as final approver
 	IsFinalApprover bool `json:"is_final_approver"`
+	// Optional vote weight toward the group's minimum approvals, defaults to 1
+	DecisionWeight *int `json:"decision_weight"`
 }
 
 func (ac *ApplicationController) CreateApprovalGroupWithMembers(c *fiber.Ctx) error {
@@ -75,11 +78,38 @@ func (ac *ApplicationController) CreateApprovalGroupWithMembers(c *fiber.Ctx) er
 				"message": fmt.Sprintf("Invalid user ID for member at index %d", i),
 			})
 		}
+		if member.DecisionWeight != nil && *member.DecisionWeight < 1 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": fmt.Sprintf("Decision weight for member at index %d must be at least 1", i),
+			})
+		}
 		if member.IsFinalApprover {
 			finalApproverCount++
 		}
 	}
 
+	if request.MinimumApprovals < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Minimum approvals cannot be negative",
+		})
+	}
+
+	// Older clients omit the flag; groups then need every member's approval as before
+	requiresAllApprovals := true
+	if request.RequiresAllApprovals != nil {
+		requiresAllApprovals = *request.RequiresAllApprovals
+	}
+
+	commentPolicy := models.CommentsRequiredRejections
+	if request.CommentPolicy != nil {
+		commentPolicy = *request.CommentPolicy
+		if !commentPolicy.Valid() {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "Comment policy must be NONE, APPROVALS, REJECTIONS or BOTH",
+			})
+		}
+	}
+
 	// Validate exactly one final approver
 	if finalApproverCount != 1 {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -94,15 +124,21 @@ func (ac *ApplicationController) CreateApprovalGroupWithMembers(c *fiber.Ctx) er
 		Name:                 request.Name,
 		Description:          request.Description,
 		Type:                 request.Type,
-		RequiresAllApprovals: request.RequiresAllApprovals,
+		RequiresAllApprovals: requiresAllApprovals,
 		MinimumApprovals:     request.MinimumApprovals,
 		AutoAssignBackups:    request.AutoAssignBackups,
+		CommentPolicy:        commentPolicy,
 		IsActive:             request.IsActive,
 		CreatedBy:            request.CreatedBy,
 	}
 
 	// Map members - now including final approver flag
 	for _, memberReq := range request.Members {
+		decisionWeight := 1
+		if memberReq.DecisionWeight != nil {
+			decisionWeight = *memberReq.DecisionWeight
+		}
+
 		member := models.ApprovalGroupMember{
 			UserID:             memberReq.UserID,
 			Role:               memberReq.Role,
@@ -117,12 +153,13 @@ func (ac *ApplicationController) CreateApprovalGroupWithMembers(c *fiber.Ctx) er
 			AddedBy:            memberReq.AddedBy,
 			// NEW: Set final approver flag
 			IsFinalApprover: memberReq.IsFinalApprover,
+			DecisionWeight:  decisionWeight,
 		}
 		approvalGroup.Members = append(approvalGroup.Members, member)
 	}
 
 	// --- Start Database Transaction ---
-	tx := ac.DB.Begin()
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
 	if tx.Error != nil {
 		config.Logger.Error("Failed to begin database transaction", zap.Error(tx.Error))
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -23,6 +23,9 @@ type CreateTariffRequest struct {
 	DevelopmentLevyPercent decimal.Decimal `json:"development_levy_percent" validate:"required,min=0,max=100"`
 	IsActive               bool            `json:"is_active"`
 	CreatedBy              string          `json:"created_by" validate:"required,email"`
+
+	// Share of the permit and inspection fees charged for a minor amendment, 25% when not sent
+	AmendmentFeePercent *decimal.Decimal `json:"amendment_fee_percent" validate:"omitempty,min=0,max=100"`
 }
 
 // CreateNewTariff handles the creation of a new tariff
@@ -39,9 +42,21 @@ func (ac *ApplicationController) CreateNewTariff(c *fiber.Ctx) error {
 		})
 	}
 
+	amendmentFeePercent := decimal.NewFromInt(25)
+	if req.AmendmentFeePercent != nil {
+		if req.AmendmentFeePercent.IsNegative() || req.AmendmentFeePercent.GreaterThan(decimal.NewFromInt(100)) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": "Amendment fee percent must be between 0 and 100",
+				"error":   "invalid_amendment_fee_percent",
+			})
+		}
+		amendmentFeePercent = *req.AmendmentFeePercent
+	}
+
 	// Start transaction
 	config.Logger.Info("Starting transaction for tariff creation")
-	tx := ac.DB.Session(&gorm.Session{}).WithContext(c.Context()).Begin()
+	tx := ac.DB.Session(&gorm.Session{}).WithContext(c.UserContext()).Begin()
 	if tx.Error != nil {
 		config.Logger.Error("Failed to start transaction", zap.Error(tx.Error))
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -131,6 +146,7 @@ func (ac *ApplicationController) CreateNewTariff(c *fiber.Ctx) error {
 		InspectionFee:          req.InspectionFee,
 		Currency:               req.Currency,
 		DevelopmentLevyPercent: req.DevelopmentLevyPercent,
+		AmendmentFeePercent:    amendmentFeePercent,
 		ValidFrom:              time.Now(),
 		ValidTo:                nil, // NULL means currently active
 		IsActive:               req.IsActive,
@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	application_services "town-planning-backend/applications/services"
+	"town-planning-backend/config"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// idempotentDecision runs a decision handler under the request's Idempotency-Key. A key seen
+// before with the same request replays the stored response without deciding again; requests
+// without a key are handled as they always were. The key is handed to the handler so it lands
+// on the decision's audit record.
+func (ac *ApplicationController) idempotentDecision(c *fiber.Ctx, action string, handle func(idempotencyKey *string) error) error {
+	key := strings.TrimSpace(c.Get(application_services.IdempotencyKeyHeader))
+	payload, ok := c.Locals("user").(*token.Payload)
+	if key == "" || ac.DecisionIdempotency == nil || !ok || payload == nil {
+		return handle(nil)
+	}
+	if len(key) > application_services.MaxIdempotencyKeyLength {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": fmt.Sprintf("Idempotency key must be at most %d characters", application_services.MaxIdempotencyKeyLength),
+			"error":   "invalid_idempotency_key",
+		})
+	}
+
+	scope := fmt.Sprintf("%s:%s", payload.UserID, action)
+	fingerprint := application_services.RequestFingerprint(action, c.Params("id"), string(c.Body()))
+
+	stored, err := ac.DecisionIdempotency.Begin(c.UserContext(), scope, key, fingerprint)
+	switch {
+	case errors.Is(err, application_services.ErrIdempotencyKeyInFlight):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+			"error":   "idempotency_key_in_flight",
+		})
+	case errors.Is(err, application_services.ErrIdempotencyKeyReused):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+			"error":   "idempotency_key_reused",
+		})
+	case err != nil:
+		// Without Redis the decision still goes through; the assignment's version claim keeps a
+		// repeated request from being counted twice
+		config.Logger.Warn("Idempotency store unavailable, deciding without replay protection",
+			zap.Error(err),
+			zap.String("action", action),
+			zap.String("userID", payload.UserID.String()))
+		return handle(&key)
+	case stored != nil:
+		config.Logger.Info("Replaying decision for repeated idempotency key",
+			zap.String("action", action),
+			zap.String("applicationID", c.Params("id")),
+			zap.String("userID", payload.UserID.String()),
+			zap.String("idempotencyKey", key))
+		c.Set("Idempotent-Replayed", "true")
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+		return c.Status(stored.Status).Send(stored.Body)
+	}
+
+	handleErr := handle(&key)
+
+	// The request may be gone by now, but its outcome still has to be recorded against the key
+	status := c.Response().StatusCode()
+	if handleErr == nil && status >= fiber.StatusOK && status < fiber.StatusMultipleChoices {
+		body := append([]byte(nil), c.Response().Body()...)
+		if err := ac.DecisionIdempotency.Complete(context.Background(), scope, key, fingerprint, status, body); err != nil {
+			config.Logger.Warn("Failed to store idempotent decision response",
+				zap.Error(err),
+				zap.String("action", action),
+				zap.String("idempotencyKey", key))
+		}
+	} else if err := ac.DecisionIdempotency.Release(context.Background(), scope, key); err != nil {
+		config.Logger.Warn("Failed to release idempotency key",
+			zap.Error(err),
+			zap.String("action", action),
+			zap.String("idempotencyKey", key))
+	}
+	return handleErr
+}
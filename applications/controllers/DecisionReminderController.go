@@ -0,0 +1,235 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	applicationRepositories "town-planning-backend/applications/repositories"
+	application_services "town-planning-backend/applications/services"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/utils"
+
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+// decisionReminderSchedule checks for overdue decisions hourly during office hours on weekdays
+const decisionReminderSchedule = "0 8-17 * * 1-5"
+
+// processDecisionReminders reminds approvers of decisions pending past the reminder threshold
+// and escalates those still ignored past the escalation threshold to the department head
+func (ac *ApplicationController) processDecisionReminders() {
+	policy := application_services.LoadDecisionReminderPolicy()
+	now := time.Now()
+
+	decisions, err := ac.ApplicationRepo.GetPendingDecisionsForReminder(now.Add(-policy.RemindAfter))
+	if err != nil {
+		config.Logger.Error("Failed to fetch pending decisions for reminders", zap.Error(err))
+		return
+	}
+
+	decisionIDs := make([]uuid.UUID, len(decisions))
+	for i, decision := range decisions {
+		decisionIDs[i] = decision.ID
+	}
+	reminders, err := ac.ApplicationRepo.GetDecisionReminders(decisionIDs)
+	if err != nil {
+		config.Logger.Error("Failed to fetch decision reminders", zap.Error(err))
+		return
+	}
+
+	// Time spent waiting on the applicant does not count against the approver
+	applicationIDs := make([]uuid.UUID, len(decisions))
+	for i, decision := range decisions {
+		applicationIDs[i] = decision.Assignment.ApplicationID
+	}
+	pauses, err := ac.ApplicationRepo.GetSLAPauses(applicationIDs)
+	if err != nil {
+		config.Logger.Error("Failed to fetch SLA pauses", zap.Error(err))
+		return
+	}
+
+	reminded, escalated := 0, 0
+	for i := range decisions {
+		decision := &decisions[i]
+		pendingSince, due := applicationRepositories.PendingDecisionSince(decision)
+		if !due {
+			continue
+		}
+		pendingSince, paused := application_services.SLAClockStart(pendingSince, pauses[decision.Assignment.ApplicationID], now)
+		if paused {
+			continue
+		}
+		reminder := reminders[decision.ID]
+
+		if reminder.EscalatedAt == nil && policy.DueForEscalation(pendingSince, reminder.ReminderCount, now) {
+			if ac.escalateOverdueDecision(decision, pendingSince, now) {
+				escalated++
+			}
+		}
+		if policy.DueForReminder(pendingSince, reminder.LastRemindedAt, now) {
+			if ac.remindApprover(decision, pendingSince, now) {
+				reminded++
+			}
+		}
+	}
+
+	if reminded > 0 || escalated > 0 {
+		config.Logger.Info("Decision reminders processed",
+			zap.Int("reminded", reminded),
+			zap.Int("escalated", escalated))
+	}
+}
+
+// remindApprover emails the approver about their pending decision and counts the reminder
+func (ac *ApplicationController) remindApprover(decision *models.MemberApprovalDecision, pendingSince time.Time, now time.Time) bool {
+	email := strings.TrimSpace(decision.User.Email)
+	if email == "" {
+		return false
+	}
+
+	application := &decision.Assignment.Application
+	subject := fmt.Sprintf("Reminder: decision pending on %s", application.PlanNumber)
+	message := fmt.Sprintf("Dear %s,\n\nYour decision on application %s has been pending for %d days.\n\nPlease review it in your decisions queue. Decisions left pending are escalated to your head of department.",
+		userFullName(&decision.User),
+		application_services.DecisionEscalationSummary(application, pendingSince, now),
+		application_services.PendingDays(pendingSince, now))
+
+	if err := utils.SendEmail(email, message, subject, "", ""); err != nil {
+		config.Logger.Warn("Failed to send decision reminder",
+			zap.Error(err),
+			zap.String("decisionID", decision.ID.String()))
+		return false
+	}
+
+	if err := ac.ApplicationRepo.RecordDecisionReminder(decision, now); err != nil {
+		config.Logger.Error("Failed to record decision reminder",
+			zap.Error(err),
+			zap.String("decisionID", decision.ID.String()))
+		return false
+	}
+	return true
+}
+
+// escalateOverdueDecision opens (or reuses) the assignment's escalation issue, adds the
+// approver's department head to its thread and posts the pending application summary there
+func (ac *ApplicationController) escalateOverdueDecision(decision *models.MemberApprovalDecision, pendingSince time.Time, now time.Time) bool {
+	head, err := ac.ApplicationRepo.GetDepartmentHead(decision.UserID)
+	if err != nil {
+		config.Logger.Warn("Overdue decision cannot be escalated",
+			zap.Error(err),
+			zap.String("decisionID", decision.ID.String()),
+			zap.String("userID", decision.UserID.String()))
+		return false
+	}
+	if head.ID == decision.UserID {
+		config.Logger.Warn("Overdue decision belongs to the department head and cannot be escalated",
+			zap.String("decisionID", decision.ID.String()),
+			zap.String("userID", decision.UserID.String()))
+		return false
+	}
+
+	summary := application_services.DecisionEscalationSummary(&decision.Assignment.Application, pendingSince, now)
+
+	tx := ac.DB.Begin()
+	if tx.Error != nil {
+		config.Logger.Error("Failed to start escalation transaction", zap.Error(tx.Error))
+		return false
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	issue, thread, initialMessage, err := ac.ApplicationRepo.OpenDecisionEscalation(tx, decision, head, summary)
+	if err != nil {
+		tx.Rollback()
+		config.Logger.Error("Failed to open decision escalation",
+			zap.Error(err),
+			zap.String("decisionID", decision.ID.String()))
+		return false
+	}
+
+	raisedBy, err := ac.UserRepo.GetUserProfileByID(issue.RaisedByUserID.String())
+	if err != nil {
+		tx.Rollback()
+		config.Logger.Error("Failed to fetch escalation issue author",
+			zap.Error(err),
+			zap.String("issueID", issue.ID.String()))
+		return false
+	}
+
+	note, err := ac.buildSystemMessage(thread.ID, raisedBy.ID, models.SystemEventDecisionEscalated,
+		application_services.SystemEventParams{
+			ActorName:   userFullName(&decision.User),
+			TargetNames: []string{userFullName(head)},
+			Description: summary,
+		})
+	if err == nil {
+		err = tx.Create(&note).Error
+	}
+	if err != nil {
+		tx.Rollback()
+		config.Logger.Error("Failed to post decision escalation",
+			zap.Error(err),
+			zap.String("threadID", thread.ID.String()))
+		return false
+	}
+
+	if err := tx.Model(&models.ChatThread{}).
+		Where("id = ?", thread.ID).
+		Update("last_activity_at", time.Now()).Error; err != nil {
+		config.Logger.Warn("Failed to update thread activity for escalation",
+			zap.Error(err),
+			zap.String("threadID", thread.ID.String()))
+	}
+
+	if err := ac.incrementUnreadCounts(tx, thread.ID.String(), raisedBy.ID); err != nil {
+		config.Logger.Warn("Failed to increment unread counts for escalation",
+			zap.Error(err),
+			zap.String("threadID", thread.ID.String()))
+	}
+
+	if err := ac.ApplicationRepo.MarkDecisionEscalated(tx, decision.ID, head.ID, issue.ID); err != nil {
+		tx.Rollback()
+		config.Logger.Error("Failed to record decision escalation",
+			zap.Error(err),
+			zap.String("decisionID", decision.ID.String()))
+		return false
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		config.Logger.Error("Failed to commit decision escalation", zap.Error(err))
+		return false
+	}
+
+	if initialMessage != nil {
+		ac.broadcastNewMessage(thread.ID.String(), *ac.createEnhancedMessage(*initialMessage, *raisedBy), raisedBy.ID)
+	}
+	ac.broadcastNewMessage(thread.ID.String(), *ac.createEnhancedMessage(note, *raisedBy), raisedBy.ID)
+
+	config.Logger.Info("Overdue decision escalated to department head",
+		zap.String("decisionID", decision.ID.String()),
+		zap.String("issueID", issue.ID.String()),
+		zap.String("chatThreadID", thread.ID.String()),
+		zap.String("headUserID", head.ID.String()),
+		zap.Bool("reusedIssue", initialMessage == nil))
+	return true
+}
+
+// RunDecisionReminders reminds approvers of overdue decisions and escalates ignored ones
+func (ac *ApplicationController) RunDecisionReminders() {
+	c := cron.New()
+
+	c.AddFunc(decisionReminderSchedule, ac.processDecisionReminders)
+
+	c.Start()
+
+	// Keep running so the cron jobs can execute
+	select {}
+}
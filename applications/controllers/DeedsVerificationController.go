@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"errors"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// verifyStandOwnership checks the applicant against the deeds registry for the stand, or returns
+// nil when the registry is not configured or either record cannot be loaded. A failed check does
+// not stop an application; approvers are warned instead.
+func (ac *ApplicationController) verifyStandOwnership(c *fiber.Ctx, standID uuid.UUID, applicantID string) *models.DeedsVerification {
+	if !ac.DeedsRegistrySvc.Enabled() {
+		return nil
+	}
+
+	var stand models.Stand
+	if err := ac.DB.Where("id = ?", standID).First(&stand).Error; err != nil {
+		return nil
+	}
+	var applicant models.Applicant
+	if err := ac.DB.Where("id = ?", applicantID).First(&applicant).Error; err != nil {
+		return nil
+	}
+	return ac.DeedsRegistrySvc.VerifyOwnership(c.Context(), &stand, &applicant)
+}
+
+// CheckApplicationDeedsVerificationController re-checks with the deeds registry that the
+// applicant owns the application's stand, e.g. after a transfer has been registered or once the
+// last check has gone stale
+func (ac *ApplicationController) CheckApplicationDeedsVerificationController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	if !ac.DeedsRegistrySvc.Enabled() {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"message": "Deeds registry checks are not configured",
+			"error":   "deeds_registry_disabled",
+		})
+	}
+
+	var application models.Application
+	if err := ac.DB.Preload("Stand").Preload("Applicant").Where("id = ?", applicationID).First(&application).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"success": false,
+				"message": "Application not found",
+				"error":   "application_not_found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to load application",
+			"error":   err.Error(),
+		})
+	}
+	if application.Stand == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Application has no stand to check",
+			"error":   "missing_stand",
+		})
+	}
+
+	// Query the registry before opening the transaction so a slow registry holds no locks
+	verification := ac.DeedsRegistrySvc.VerifyOwnership(c.Context(), application.Stand, &application.Applicant)
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	recorded, err := ac.ApplicationRepo.RecordDeedsVerification(tx, applicationID, verification, payload.UserID.String())
+	if err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to record deeds verification",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("Stand ownership checked",
+		zap.String("applicationID", applicationID.String()),
+		zap.String("status", string(recorded.Status)))
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Stand ownership checked",
+		"data":    recorded,
+	})
+}
+
+// GetApplicationDeedsVerificationsController lists an application's deeds registry checks
+func (ac *ApplicationController) GetApplicationDeedsVerificationsController(c *fiber.Ctx) error {
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	verifications, err := ac.ApplicationRepo.GetApplicationDeedsVerifications(applicationID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch deeds verifications",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Deeds verifications retrieved successfully",
+		"data": fiber.Map{
+			"enabled":       ac.DeedsRegistrySvc.Enabled(),
+			"verifications": verifications,
+		},
+	})
+}
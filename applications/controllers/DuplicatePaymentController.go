@@ -0,0 +1,249 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"town-planning-backend/applications/repositories"
+	"town-planning-backend/applications/requests"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/token"
+	"town-planning-backend/utils"
+	"town-planning-backend/websocket"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// duplicatePaymentErrorStatus maps duplicate payment alert repository errors to HTTP status codes
+func duplicatePaymentErrorStatus(err error) int {
+	switch err.Error() {
+	case "duplicate payment alert not found":
+		return fiber.StatusNotFound
+	case "invalid resolution":
+		return fiber.StatusBadRequest
+	case "duplicate payment alert has already been resolved":
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// paymentCaptureDetails is what the finance officer needs to tell two payment captures apart
+func paymentCaptureDetails(payment models.Payment) fiber.Map {
+	return fiber.Map{
+		"receipt_number":     payment.ReceiptNumber,
+		"amount":             payment.Amount,
+		"payment_for":        payment.PaymentFor,
+		"payment_method":     payment.PaymentMethod,
+		"payment_date":       payment.PaymentDate,
+		"external_reference": payment.ExternalReference,
+		"application_id":     payment.ApplicationID,
+		"captured_by":        payment.CreatedBy,
+	}
+}
+
+// respondToDuplicatePayment answers a capture blocked as a duplicate with a conflict carrying
+// both payments, records it and alerts the finance officers. It reports false, without
+// responding, for any other error.
+func (ac *ApplicationController) respondToDuplicatePayment(c *fiber.Ctx, err error) (bool, error) {
+	var duplicate *repositories.DuplicatePaymentError
+	if !errors.As(err, &duplicate) {
+		return false, nil
+	}
+
+	existing := paymentCaptureDetails(duplicate.Existing)
+	existing["id"] = duplicate.Existing.ID
+	existing["transaction_number"] = duplicate.Existing.TransactionNumber
+	existing["captured_at"] = duplicate.Existing.CreatedAt
+	data := fiber.Map{
+		"reason":            duplicate.Reason,
+		"existing_payment":  existing,
+		"attempted_payment": paymentCaptureDetails(duplicate.Attempt),
+	}
+
+	alert, alertErr := ac.ApplicationRepo.RecordDuplicatePaymentAlert(duplicate)
+	if alertErr != nil {
+		config.Logger.Error("Failed to record duplicate payment alert",
+			zap.Error(alertErr),
+			zap.String("existingPaymentID", duplicate.Existing.ID.String()))
+	} else {
+		data["alert_id"] = alert.ID
+		ac.alertFinanceOfficers(alert)
+	}
+
+	config.Logger.Warn("Duplicate payment capture blocked",
+		zap.String("reason", string(duplicate.Reason)),
+		zap.String("existingPaymentID", duplicate.Existing.ID.String()),
+		zap.String("receiptNumber", duplicate.Attempt.ReceiptNumber),
+		zap.String("amount", duplicate.Attempt.Amount.String()))
+
+	return true, c.Status(fiber.StatusConflict).JSON(fiber.Map{
+		"success": false,
+		"message": "Payment was not captured because it duplicates a payment already on record",
+		"error":   err.Error(),
+		"data":    data,
+	})
+}
+
+// alertFinanceOfficers emails the finance officers both captures of a blocked duplicate and
+// pushes the alert to those online
+func (ac *ApplicationController) alertFinanceOfficers(alert *models.DuplicatePaymentAlert) {
+	go func() {
+		officers, err := ac.ApplicationRepo.GetFinanceOfficers()
+		if err != nil {
+			config.Logger.Warn("Failed to fetch finance officers for duplicate payment",
+				zap.Error(err),
+				zap.String("alertID", alert.ID.String()))
+			return
+		}
+
+		existing := alert.ExistingPayment
+		reason := "its receipt number has already been captured"
+		if alert.Reason == models.DuplicateAmountForApplicant {
+			reason = "the same amount was captured for the same applicant moments earlier"
+		}
+		subject := fmt.Sprintf("Duplicate payment blocked: receipt %s", alert.AttemptedReceiptNumber)
+		message := fmt.Sprintf(
+			"A payment capture was blocked because %s.\n\n"+
+				"Payment on record:\n  Transaction: %s\n  Receipt: %s\n  Amount: %s\n  For: %s\n  Method: %s\n  Paid on: %s\n  Captured by: %s at %s\n\n"+
+				"Blocked capture:\n  Receipt: %s\n  Amount: %s\n  For: %s\n  Method: %s\n  Paid on: %s\n  Captured by: %s at %s\n\n"+
+				"Please check both with the cashier and resolve the alert as a duplicate or as a separate payment.",
+			reason,
+			existing.TransactionNumber, existing.ReceiptNumber, existing.Amount.StringFixed(2), existing.PaymentFor,
+			existing.PaymentMethod, existing.PaymentDate.Format("2 January 2006"), existing.CreatedBy,
+			existing.CreatedAt.Format("2 January 2006 15:04"),
+			alert.AttemptedReceiptNumber, alert.AttemptedAmount.StringFixed(2), alert.AttemptedPaymentFor,
+			alert.AttemptedPaymentMethod, alert.AttemptedPaymentDate.Format("2 January 2006"), alert.AttemptedBy,
+			alert.AttemptedAt.Format("2 January 2006 15:04"),
+		)
+
+		notified := 0
+		for _, officer := range officers {
+			if ac.WsHub != nil {
+				ac.WsHub.SendToUser(officer.ID, websocket.WebSocketMessage{
+					Type:      websocket.MessageTypePaymentAlert,
+					Payload:   alert,
+					Timestamp: time.Now(),
+				})
+			}
+			if strings.TrimSpace(officer.Email) == "" {
+				continue
+			}
+			if err := utils.SendEmail(officer.Email, message, subject, "", ""); err != nil {
+				config.Logger.Warn("Failed to alert finance officer of duplicate payment",
+					zap.Error(err),
+					zap.String("alertID", alert.ID.String()),
+					zap.String("officerID", officer.ID.String()))
+				continue
+			}
+			notified++
+		}
+
+		if err := ac.ApplicationRepo.RecordDuplicatePaymentNotifications(alert.ID, notified); err != nil {
+			config.Logger.Warn("Failed to record duplicate payment notifications",
+				zap.Error(err),
+				zap.String("alertID", alert.ID.String()))
+		}
+	}()
+}
+
+// GetDuplicatePaymentAlertsController lists blocked duplicate captures.
+// Query: status (optional) OPEN, CONFIRMED_DUPLICATE or NOT_DUPLICATE.
+func (ac *ApplicationController) GetDuplicatePaymentAlertsController(c *fiber.Ctx) error {
+	alerts, err := ac.ApplicationRepo.GetDuplicatePaymentAlerts(strings.ToUpper(c.Query("status")))
+	if err != nil {
+		config.Logger.Error("Failed to fetch duplicate payment alerts", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch duplicate payment alerts",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"alerts":      alerts,
+			"total_count": len(alerts),
+		},
+	})
+}
+
+// ResolveDuplicatePaymentAlertController records the finance officer's resolution of a blocked
+// capture. Resolving it as NOT_DUPLICATE lets the same amount be captured again.
+func (ac *ApplicationController) ResolveDuplicatePaymentAlertController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	alertID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid alert ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var request requests.ResolveDuplicatePaymentRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	alert, err := ac.ApplicationRepo.ResolveDuplicatePaymentAlert(tx, alertID, payload.UserID, request.Resolution, request.Notes)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(duplicatePaymentErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to resolve duplicate payment alert",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("Duplicate payment alert resolved",
+		zap.String("alertID", alert.ID.String()),
+		zap.String("resolution", string(alert.Status)),
+		zap.String("resolvedBy", payload.UserID.String()))
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Duplicate payment alert resolved",
+		"data":    alert,
+	})
+}
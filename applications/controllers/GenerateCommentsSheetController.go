@@ -68,7 +68,7 @@ func (ac *ApplicationController) GenerateCommentsSheetController(c *fiber.Ctx) e
 
 	userUUID := payload.UserID
 
-	user, err := ac.UserRepo.GetUserByID(userUUID.String())
+	user, err := ac.UserRepo.GetUserProfileByID(userUUID.String())
 	if err != nil {
 		config.Logger.Error("Failed to get user by UUID", zap.Error(err))
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -79,7 +79,7 @@ func (ac *ApplicationController) GenerateCommentsSheetController(c *fiber.Ctx) e
 	}
 
 	// Start transaction
-	tx := ac.DB.Session(&gorm.Session{}).WithContext(c.Context()).Begin()
+	tx := ac.DB.Session(&gorm.Session{}).WithContext(c.UserContext()).Begin()
 	if tx.Error != nil {
 		config.Logger.Error("Failed to start transaction", zap.Error(tx.Error))
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -101,6 +101,7 @@ func (ac *ApplicationController) GenerateCommentsSheetController(c *fiber.Ctx) e
 	var application models.Application
 	if err := tx.
 		Preload("Applicant").
+		Preload("CoApplicants.Applicant").
 		Preload("Stand.StandType").
 		Preload("Tariff.DevelopmentCategory").
 		First(&application, "id = ?", appUUID).Error; err != nil {
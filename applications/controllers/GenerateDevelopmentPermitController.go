@@ -7,6 +7,7 @@ import (
 	"town-planning-backend/config"
 	"town-planning-backend/db/models"
 	documents_requests "town-planning-backend/documents/requests"
+	planningscheme_repositories "town-planning-backend/planningschemes/repositories"
 	"town-planning-backend/token"
 	"town-planning-backend/utils"
 
@@ -68,7 +69,7 @@ func (ac *ApplicationController) GenerateDevelopmentPermitController(c *fiber.Ct
 
 	userUUID := payload.UserID
 
-	user, err := ac.UserRepo.GetUserByID(userUUID.String())
+	user, err := ac.UserRepo.GetUserProfileByID(userUUID.String())
 	if err != nil {
 		config.Logger.Error("Failed to get user by UUID", zap.Error(err))
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -79,7 +80,7 @@ func (ac *ApplicationController) GenerateDevelopmentPermitController(c *fiber.Ct
 	}
 
 	// Start transaction
-	tx := ac.DB.Session(&gorm.Session{}).WithContext(c.Context()).Begin()
+	tx := ac.DB.Session(&gorm.Session{}).WithContext(c.UserContext()).Begin()
 	if tx.Error != nil {
 		config.Logger.Error("Failed to start transaction", zap.Error(tx.Error))
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -101,6 +102,7 @@ func (ac *ApplicationController) GenerateDevelopmentPermitController(c *fiber.Ct
 	var application models.Application
 	if err := tx.
 		Preload("Applicant").
+		Preload("CoApplicants.Applicant").
 		Preload("Stand.StandType").
 		Preload("Tariff.DevelopmentCategory").
 		Preload("VATRate").
@@ -128,11 +130,46 @@ func (ac *ApplicationController) GenerateDevelopmentPermitController(c *fiber.Ct
 		})
 	}
 
+	// Levies paid in installments must be settled before the permit is issued
+	if err := ac.ApplicationRepo.EnsureInstallmentsSettled(tx, appUUID); err != nil {
+		config.Logger.Warn("Development permit withheld",
+			zap.String("applicationID", applicationID),
+			zap.Error(err))
+		tx.Rollback()
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"message": "Development permit cannot be issued yet",
+			"error":   err.Error(),
+		})
+	}
+
+	// Record the issued permit; suspended and revoked permits cannot be printed again
+	permit, err := ac.ApplicationRepo.IssuePermit(tx, &application, req.CreatedBy)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		switch err.Error() {
+		case "permit is suspended", "permit is revoked":
+			status = fiber.StatusConflict
+		case "application has no permit number", "application has no development category":
+			status = fiber.StatusBadRequest
+		}
+		config.Logger.Warn("Failed to issue permit",
+			zap.String("applicationID", applicationID),
+			zap.Error(err))
+		tx.Rollback()
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"message": "Development permit cannot be issued",
+			"error":   err.Error(),
+		})
+	}
+
 	// Get final approval decision
 	var finalApproval models.FinalApproval
 	if err := tx.
 		Preload("Approver").
 		Preload("Approver.Department").
+		Preload("PlanningSchemeVersions.Scheme").
 		Where("application_id = ?", appUUID).
 		First(&finalApproval).Error; err != nil && err != gorm.ErrRecordNotFound {
 		config.Logger.Error("Failed to fetch final approval",
@@ -146,6 +183,18 @@ func (ac *ApplicationController) GenerateDevelopmentPermitController(c *fiber.Ct
 		})
 	}
 
+	// Decisions record the planning scheme versions they were made under; older decisions
+	// predate that, so cite what was in force at submission instead
+	if len(finalApproval.PlanningSchemeVersions) == 0 {
+		versions, err := planningscheme_repositories.VersionsInEffect(tx, application.SubmissionDate)
+		if err != nil {
+			config.Logger.Warn("Failed to load planning schemes in effect",
+				zap.String("applicationID", applicationID),
+				zap.Error(err))
+		}
+		finalApproval.PlanningSchemeVersions = versions
+	}
+
 	// Generate standardized filename
 	filename := generateDevelopmentPermitFilename(application)
 
@@ -261,6 +310,8 @@ func (ac *ApplicationController) GenerateDevelopmentPermitController(c *fiber.Ct
 			"application_id": application.ID,
 			"plan_number":    application.PlanNumber,
 			"permit_number":  application.PermitNumber,
+			"permit_id":      permit.ID,
+			"valid_until":    permit.ValidUntil,
 			"applicant_name": application.Applicant.FullName,
 			"pdf_path":       response.Document.FilePath,
 			"filename":       filename,
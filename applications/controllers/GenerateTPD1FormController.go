@@ -59,7 +59,7 @@ func (ac *ApplicationController) GenerateTPD1FormController(c *fiber.Ctx) error
 	}
 
 	// Start transaction for document creation
-	tx := ac.DB.Session(&gorm.Session{}).WithContext(c.Context()).Begin()
+	tx := ac.DB.Session(&gorm.Session{}).WithContext(c.UserContext()).Begin()
 	if tx.Error != nil {
 		config.Logger.Error("Failed to start transaction", zap.Error(tx.Error))
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"town-planning-backend/token"
+	"town-planning-backend/utils/fieldset"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -22,6 +23,15 @@ func (pc *ApplicationController) GetApplicationApprovalDataController(c *fiber.C
 	}
 	senderUUID := payload.UserID
 
+	// Mobile clients can ask for just the fields they show, e.g. ?fields=application.plan_number,decisions
+	fields, err := fieldset.FromQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid fields parameter",
+			"error":   err.Error(),
+		})
+	}
+
 	// Fetch the Application from the repository using the ID
 	application, err := pc.ApplicationRepo.GetEnhancedApplicationApprovalData(applicationID, senderUUID)
 	if err != nil {
@@ -32,10 +42,18 @@ func (pc *ApplicationController) GetApplicationApprovalDataController(c *fiber.C
 		})
 	}
 
+	data, err := fields.Apply(application)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to prepare application approval data",
+			"error":   err.Error(),
+		})
+	}
+
 	// Return the Application data in the response
 	return c.JSON(fiber.Map{
 		"message": "Application approval data retrieved successfully",
-		"data":    application,
+		"data":    data,
 		"error":   nil,
 	})
 }
@@ -2,7 +2,8 @@
 package controllers
 
 import (
-	"strconv"
+	"town-planning-backend/token"
+	"town-planning-backend/utils/pagination"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -17,45 +18,66 @@ func (cc *ApplicationController) GetChatMessagesController(c *fiber.Ctx) error {
 		})
 	}
 
-	// Get pagination parameters
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	limit, _ := strconv.Atoi(c.Query("limit", "50"))
-
-	if page < 1 {
-		page = 1
-	}
-	if limit < 1 || limit > 100 {
-		limit = 50
+	// People invited from another department only see the thread once they accept
+	if payload, ok := c.Locals("user").(*token.Payload); ok && payload != nil {
+		unaccepted, err := cc.ApplicationRepo.HasUnacceptedThreadInvitation(threadID, payload.UserID)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		if unaccepted {
+			return c.Status(403).JSON(fiber.Map{
+				"message": "Accept the invitation to this conversation to see its messages",
+				"error":   "invitation_not_accepted",
+			})
+		}
 	}
 
-	offset := (page - 1) * limit
+	// Get pagination parameters; cursor paging keeps history stable while new messages arrive
+	page, err := pagination.ParseRequest(c, 50)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"message": "Invalid pagination parameters",
+			"error":   err.Error(),
+		})
+	}
 
 	// Use repository method
-	messages, total, err := cc.ApplicationRepo.GetChatMessagesWithPreload(threadID, limit, offset)
+	messages, total, err := cc.ApplicationRepo.GetChatMessagesWithPreload(c.UserContext(), threadID, page)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	// System messages are stored as events and rendered per reader
+	localizeFrontendMessages(messages, cc.requesterLanguage(c))
+
+	var next *pagination.Cursor
+	if len(messages) > 0 {
+		last := messages[len(messages)-1]
+		next = page.NextCursor(len(messages), last.SentAt, last.ID)
+	}
+
 	// Calculate pagination
-	totalInt := int(total)
-	totalPages := (totalInt + limit - 1) / limit
+	envelope := pagination.NewEnvelope(c, page, messages, total, next)
+	totalPages := envelope.TotalPages
 	if totalPages == 0 {
 		totalPages = 1
 	}
 
+	// messages and pagination are kept for clients written before the shared envelope
+	data := envelope.Map()
+	data["messages"] = messages
+	data["pagination"] = fiber.Map{
+		"page":       page.Page,
+		"limit":      page.Limit,
+		"total":      int(total),
+		"totalPages": totalPages,
+		"hasNext":    envelope.Links.Next != nil,
+		"hasPrev":    envelope.Links.Prev != nil,
+	}
+
 	return c.JSON(fiber.Map{
 		"success": true,
-		"data": fiber.Map{
-			"messages": messages,
-			"pagination": fiber.Map{
-				"page":       page,
-				"limit":      limit,
-				"total":      totalInt,
-				"totalPages": totalPages,
-				"hasNext":    page < totalPages,
-				"hasPrev":    page > 1,
-			},
-		},
+		"data":    data,
 		"message": "Chat messages retrieved successfully",
 	})
 }
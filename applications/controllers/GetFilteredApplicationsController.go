@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"town-planning-backend/config"
+	"town-planning-backend/utils/pagination"
 
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
@@ -9,22 +10,13 @@ import (
 
 // GetFilteredApplicationsController handles the fetching of filtered applications
 func (ac *ApplicationController) GetFilteredApplicationsController(c *fiber.Ctx) error {
-	// Parse query parameters
-	pageSize := c.QueryInt("page_size", 10) // Default to 10 if not provided
-	if pageSize <= 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid page_size parameter",
-			"error":   "page_size must be greater than 0",
-		})
-	}
-
-	page := c.QueryInt("page", 1) // Default to page 1 if not provided
-	if page <= 0 {
+	// Parse page, limit (or page_size) and cursor
+	page, err := pagination.ParseRequest(c, 10)
+	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
-			"message": "Invalid page parameter",
-			"error":   "page must be greater than 0",
+			"message": "Invalid pagination parameters",
+			"error":   err.Error(),
 		})
 	}
 
@@ -39,9 +31,9 @@ func (ac *ApplicationController) GetFilteredApplicationsController(c *fiber.Ctx)
 	dateFrom := c.Query("date_from")
 	dateTo := c.Query("date_to")
 	isCollected := c.Query("is_collected")
-
-	// Calculate offset for pagination
-	offset := (page - 1) * pageSize
+	riskLevel := c.Query("risk_level")
+	archived := c.Query("archived") // "true" for archived only, "include" for both
+	sort := c.Query("sort")
 
 	// Build filters map
 	filters := make(map[string]string)
@@ -75,9 +67,18 @@ func (ac *ApplicationController) GetFilteredApplicationsController(c *fiber.Ctx)
 	if isCollected != "" {
 		filters["is_collected"] = isCollected
 	}
+	if riskLevel != "" {
+		filters["risk_level"] = riskLevel
+	}
+	if archived != "" {
+		filters["archived"] = archived
+	}
+	if sort != "" {
+		filters["sort"] = sort
+	}
 
 	// Fetch filtered applications from the repository
-	applications, total, err := ac.ApplicationRepo.GetFilteredApplications(pageSize, offset, filters)
+	applications, total, err := ac.ApplicationRepo.GetFilteredApplications(c.UserContext(), page, filters)
 	if err != nil {
 		config.Logger.Error("Failed to fetch filtered applications", zap.Error(err))
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -87,12 +88,15 @@ func (ac *ApplicationController) GetFilteredApplicationsController(c *fiber.Ctx)
 		})
 	}
 
-	// Calculate total pages
-	totalPages := (total + int64(pageSize) - 1) / int64(pageSize)
+	var next *pagination.Cursor
+	if len(applications) > 0 {
+		last := applications[len(applications)-1]
+		next = page.NextCursor(len(applications), last.CreatedAt, last.ID)
+	}
 
 	config.Logger.Info("Successfully fetched filtered applications",
-		zap.Int("page", page),
-		zap.Int("pageSize", pageSize),
+		zap.Int("page", page.Page),
+		zap.Int("limit", page.Limit),
 		zap.Int64("total", total),
 		zap.Int("resultsCount", len(applications)))
 
@@ -100,14 +104,6 @@ func (ac *ApplicationController) GetFilteredApplicationsController(c *fiber.Ctx)
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
 		"message": "Applications fetched successfully",
-		"data": fiber.Map{
-			"data": applications,
-			"meta": fiber.Map{
-				"current_page": page,
-				"page_size":    pageSize,
-				"total":        total,
-				"total_pages":  totalPages,
-			},
-		},
+		"data":    pagination.NewEnvelope(c, page, applications, total, next),
 	})
 }
@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"errors"
+	applicant_repository "town-planning-backend/applicants/repositories"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// quorumNotMet returns the error refusing an assignment because the group lacks a quorum, or
+// nil for any other error
+func quorumNotMet(err error) *applicant_repository.QuorumNotMetError {
+	var quorumErr *applicant_repository.QuorumNotMetError
+	if errors.As(err, &quorumErr) {
+		return quorumErr
+	}
+	return nil
+}
+
+// quorumConflictBody answers a refused assignment with the shortfall and the groups that could
+// take the application instead
+func quorumConflictBody(message string, quorumErr *applicant_repository.QuorumNotMetError) fiber.Map {
+	return fiber.Map{
+		"success": false,
+		"message": message,
+		"error":   quorumErr.Error(),
+		"data": fiber.Map{
+			"quorum":       quorumErr.Check,
+			"alternatives": quorumErr.Alternatives,
+		},
+	}
+}
+
+// GetGroupQuorumController reports whether an approval group could decide an application now,
+// so a group lacking available approvers or a final approver can be avoided before assigning
+func (ac *ApplicationController) GetGroupQuorumController(c *fiber.Ctx) error {
+	groupID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid approval group ID",
+		})
+	}
+
+	check, alternatives, err := ac.ApplicantRepo.CheckGroupQuorum(groupID)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if err.Error() == "approval group not found" {
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to check approval group quorum",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Approval group quorum retrieved",
+		"data": fiber.Map{
+			"quorum":       check,
+			"alternatives": alternatives,
+		},
+	})
+}
@@ -0,0 +1,276 @@
+package controllers
+
+import (
+	"time"
+	"town-planning-backend/applications/repositories"
+	"town-planning-backend/applications/requests"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// installmentPlanErrorStatus maps installment plan repository errors to HTTP status codes
+func installmentPlanErrorStatus(err error) int {
+	switch err.Error() {
+	case "application not found", "installment plan not found":
+		return fiber.StatusNotFound
+	case "a plan needs at least two installments", "payment amount must be positive":
+		return fiber.StatusBadRequest
+	case "application has no total cost to schedule", "application is already paid",
+		"application already has an active installment plan", "payment exceeds outstanding balance":
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// CreateInstallmentPlanController schedules an application's levy over installments
+func (ac *ApplicationController) CreateInstallmentPlanController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var request requests.CreateInstallmentPlanRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+	if request.FirstDueDate.IsZero() {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "First due date is required",
+			"error":   "missing_first_due_date",
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	plan, err := ac.ApplicationRepo.CreateInstallmentPlan(
+		tx,
+		applicationID,
+		request.Installments,
+		request.FirstDueDate,
+		request.IntervalMonths,
+		request.AllowConditionalApproval,
+		payload.UserID.String(),
+	)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(installmentPlanErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to create installment plan",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("Installment plan created",
+		zap.String("applicationID", applicationID.String()),
+		zap.String("planID", plan.ID.String()),
+		zap.Int("installments", len(plan.Installments)))
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Installment plan created successfully",
+		"data":    repositories.NewInstallmentPlanSummary(plan, time.Now()),
+	})
+}
+
+// GetApplicationInstallmentPlanController returns an application's plan with its balances and arrears
+func (ac *ApplicationController) GetApplicationInstallmentPlanController(c *fiber.Ctx) error {
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	plan, err := ac.ApplicationRepo.GetApplicationInstallmentPlan(applicationID)
+	if err != nil {
+		return c.Status(installmentPlanErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch installment plan",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Installment plan retrieved successfully",
+		"data":    repositories.NewInstallmentPlanSummary(plan, time.Now()),
+	})
+}
+
+// RecordInstallmentPaymentController records a levy payment against an application's plan
+func (ac *ApplicationController) RecordInstallmentPaymentController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var request requests.RecordInstallmentPaymentRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+	if request.ReceiptNumber == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Receipt number is required",
+			"error":   "missing_receipt_number",
+		})
+	}
+	if request.PaymentDate != nil && request.PaymentDate.After(time.Now()) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Payment date cannot be in the future",
+			"error":   "invalid_payment_date",
+		})
+	}
+
+	payment := models.Payment{
+		Amount:            request.Amount,
+		PaymentMethod:     request.PaymentMethod,
+		ReceiptNumber:     request.ReceiptNumber,
+		ExternalReference: request.ExternalReference,
+		BankAccountID:     request.BankAccountID,
+		Notes:             request.Notes,
+		CreatedBy:         payload.UserID.String(),
+	}
+	if payment.PaymentMethod == "" {
+		payment.PaymentMethod = models.CashPaymentMethod
+	}
+	if request.PaymentDate != nil {
+		payment.PaymentDate = *request.PaymentDate
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	plan, err := ac.ApplicationRepo.RecordInstallmentPayment(tx, applicationID, &payment)
+	if err != nil {
+		tx.Rollback()
+		if handled, response := ac.respondToDuplicatePayment(c, err); handled {
+			return response
+		}
+		return c.Status(installmentPlanErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to record installment payment",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("Installment payment recorded",
+		zap.String("applicationID", applicationID.String()),
+		zap.String("paymentID", payment.ID.String()),
+		zap.String("amount", payment.Amount.String()),
+		zap.Bool("settled", plan.IsSettled()))
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Installment payment recorded successfully",
+		"data": fiber.Map{
+			"payment": payment,
+			"plan":    repositories.NewInstallmentPlanSummary(plan, time.Now()),
+		},
+	})
+}
+
+// GetOutstandingInstallmentPlansController reports active plans with what is still owed.
+// Query: overdue_only (optional) limits the report to plans in arrears.
+func (ac *ApplicationController) GetOutstandingInstallmentPlansController(c *fiber.Ctx) error {
+	summaries, err := ac.ApplicationRepo.GetOutstandingInstallmentPlans(c.QueryBool("overdue_only", false))
+	if err != nil {
+		config.Logger.Error("Failed to fetch outstanding installment plans", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch outstanding installment plans",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Outstanding installment plans retrieved successfully",
+		"data":    summaries,
+	})
+}
@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"context"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/websocket"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// linkPreviewTimeout bounds fetching every link in one message
+const linkPreviewTimeout = 30 * time.Second
+
+// previewLinksAsync fetches previews for the links in a sent message in the background and
+// pushes them to the thread, sender included, once they are stored
+func (ac *ApplicationController) previewLinksAsync(threadID string, messageID uuid.UUID, content string) {
+	if !ac.LinkPreviewSvc.Enabled() || content == "" {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), linkPreviewTimeout)
+		defer cancel()
+
+		previews, err := ac.LinkPreviewSvc.PreviewMessage(ctx, messageID, content)
+		if err != nil {
+			config.Logger.Error("Failed to preview links in chat message",
+				zap.Error(err),
+				zap.String("threadID", threadID),
+				zap.String("messageID", messageID.String()))
+			return
+		}
+		if len(previews) == 0 || ac.WsHub == nil {
+			return
+		}
+
+		ac.WsHub.BroadcastToThread(threadID, websocket.WebSocketMessage{
+			Type: websocket.MessageTypeLinkPreview,
+			Payload: map[string]interface{}{
+				"message_id":    messageID,
+				"link_previews": previews,
+			},
+			Timestamp: time.Now(),
+			ThreadID:  threadID,
+		})
+	}()
+}
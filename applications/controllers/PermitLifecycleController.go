@@ -0,0 +1,386 @@
+package controllers
+
+import (
+	"fmt"
+	"mime/multipart"
+	"strings"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	documents_requests "town-planning-backend/documents/requests"
+	"town-planning-backend/token"
+	"town-planning-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// permitEvidenceCategory is the document category for court orders, investigation reports and
+// other documents supporting a permit status change
+const permitEvidenceCategory = "PERMIT_STATUS_EVIDENCE"
+
+func permitErrorStatus(err error) int {
+	switch err.Error() {
+	case "permit not found":
+		return fiber.StatusNotFound
+	case "permit is already revoked", "only active permits can be suspended", "only suspended permits can be reinstated":
+		return fiber.StatusConflict
+	case "supporting document not found":
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// permitStatusWords is how a permit status change reads in notifications
+var permitStatusWords = map[models.PermitAction]string{
+	models.PermitActionSuspend:   "suspended",
+	models.PermitActionReinstate: "reinstated",
+	models.PermitActionRevoke:    "revoked",
+}
+
+// SuspendPermitController suspends an active permit, e.g. while an investigation is under way
+func (ac *ApplicationController) SuspendPermitController(c *fiber.Ctx) error {
+	return ac.changePermitStatus(c, models.PermitActionSuspend)
+}
+
+// ReinstatePermitController lifts a suspension
+func (ac *ApplicationController) ReinstatePermitController(c *fiber.Ctx) error {
+	return ac.changePermitStatus(c, models.PermitActionReinstate)
+}
+
+// RevokePermitController revokes a permit for good, e.g. after a court order
+func (ac *ApplicationController) RevokePermitController(c *fiber.Ctx) error {
+	return ac.changePermitStatus(c, models.PermitActionRevoke)
+}
+
+// changePermitStatus takes a multipart form with the reason and any supporting documents,
+// records the change and notifies the applicant and the application's inspectors
+func (ac *ApplicationController) changePermitStatus(c *fiber.Ctx, action models.PermitAction) error {
+	permitID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid permit ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid form data",
+			"error":   err.Error(),
+		})
+	}
+
+	reason := strings.TrimSpace(getFormValue(form, "reason"))
+	if reason == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "A reason is required",
+		})
+	}
+	files := form.File["documents"]
+
+	permit, err := ac.ApplicationRepo.GetPermit(permitID)
+	if err != nil {
+		return c.Status(permitErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch permit",
+			"error":   err.Error(),
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	documentIDs, err := ac.uploadPermitEvidence(tx, c, files, permit, payload.UserID.String())
+	if err != nil {
+		tx.Rollback()
+		config.Logger.Error("Failed to store permit evidence",
+			zap.Error(err),
+			zap.String("permitID", permitID.String()))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to store supporting documents",
+			"error":   err.Error(),
+		})
+	}
+
+	updated, change, err := ac.ApplicationRepo.ChangePermitStatus(tx, permitID, action, reason, documentIDs, payload.UserID)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(permitErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": fmt.Sprintf("Failed to %s permit", strings.ToLower(string(action))),
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit permit status change",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("Permit status changed",
+		zap.String("permitID", permitID.String()),
+		zap.String("action", string(action)),
+		zap.String("changedBy", payload.UserID.String()))
+
+	ac.notifyPermitStatusChange(permit, change)
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": fmt.Sprintf("Permit %s successfully", permitStatusWords[action]),
+		"data": fiber.Map{
+			"permit":        updated,
+			"status_change": change,
+		},
+	})
+}
+
+// uploadPermitEvidence stores the supporting documents against the permit's application. Unlike
+// chat attachments, a document that fails to store fails the whole change.
+func (ac *ApplicationController) uploadPermitEvidence(
+	tx *gorm.DB,
+	c *fiber.Ctx,
+	files []*multipart.FileHeader,
+	permit *models.Permit,
+	createdBy string,
+) ([]uuid.UUID, error) {
+	var documentIDs []uuid.UUID
+	for _, fileHeader := range files {
+		documentRequest := &documents_requests.CreateDocumentRequest{
+			CategoryCode:  permitEvidenceCategory,
+			FileName:      fileHeader.Filename,
+			CreatedBy:     createdBy,
+			ApplicationID: &permit.ApplicationID,
+			ApplicantID:   &permit.Application.ApplicantID,
+			FileType:      fileHeader.Header.Get("Content-Type"),
+		}
+
+		response, err := ac.DocumentSvc.UnifiedCreateDocument(tx, c, documentRequest, nil, fileHeader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to store %s: %w", fileHeader.Filename, err)
+		}
+		documentIDs = append(documentIDs, response.Document.ID)
+	}
+	return documentIDs, nil
+}
+
+// notifyPermitStatusChange emails and texts the applicant and emails the inspectors on the
+// application in the background, then records who was told
+func (ac *ApplicationController) notifyPermitStatusChange(permit *models.Permit, change *models.PermitStatusChange) {
+	applicant := permit.Application.Applicant
+	emailData := utils.PermitStatusChangeEmail{
+		ApplicantName: applicant.FullName,
+		PermitNumber:  permit.PermitNumber,
+		PlanNumber:    permit.Application.PlanNumber,
+		Status:        permitStatusWords[change.Action],
+		Reason:        change.Reason,
+		ChangedOn:     change.ChangedAt.Format("Monday 2 January 2006"),
+	}
+
+	go func() {
+		applicantNotified := false
+		if email := strings.TrimSpace(applicant.Email); email != "" {
+			subject, message, _, err := utils.RenderEmailTemplate(utils.EmailPermitStatusChange, applicant.PreferredLanguage, emailData)
+			if err != nil {
+				config.Logger.Warn("Failed to render permit status email",
+					zap.Error(err),
+					zap.String("permitID", permit.ID.String()))
+			} else if err := utils.SendApplicantEmail(utils.ApplicantEmail{
+				To:            email,
+				Subject:       subject,
+				Body:          message,
+				Template:      utils.EmailPermitStatusChange,
+				ApplicantID:   &applicant.ID,
+				ApplicationID: &permit.ApplicationID,
+				TrackClicks:   true,
+			}); err != nil {
+				config.Logger.Warn("Failed to notify applicant of permit status change",
+					zap.Error(err),
+					zap.String("permitID", permit.ID.String()))
+			} else {
+				applicantNotified = true
+			}
+		}
+		if phone := strings.TrimSpace(applicant.PhoneNumber); phone != "" && utils.SMSEnabled() {
+			body, language, err := utils.RenderSMSTemplate(utils.EmailPermitStatusChange, applicant.PreferredLanguage, emailData)
+			if err != nil {
+				config.Logger.Warn("Failed to render permit status SMS",
+					zap.Error(err),
+					zap.String("permitID", permit.ID.String()))
+			} else if err := utils.SendApplicantSMS(utils.ApplicantSMS{
+				To:            phone,
+				Body:          body,
+				Language:      language,
+				Template:      utils.EmailPermitStatusChange,
+				ApplicantID:   &applicant.ID,
+				ApplicationID: &permit.ApplicationID,
+			}); err != nil {
+				config.Logger.Warn("Failed to text applicant of permit status change",
+					zap.Error(err),
+					zap.String("permitID", permit.ID.String()))
+			} else {
+				applicantNotified = true
+			}
+		}
+
+		inspectorsNotified := 0
+		inspectors, err := ac.ApplicationRepo.GetPermitInspectors(permit.ApplicationID)
+		if err != nil {
+			config.Logger.Warn("Failed to fetch inspectors for permit status change",
+				zap.Error(err),
+				zap.String("permitID", permit.ID.String()))
+		}
+		subject := fmt.Sprintf("Permit %s has been %s", permit.PermitNumber, emailData.Status)
+		message := fmt.Sprintf(
+			"Permit %s for plan %s was %s on %s.\n\nReason: %s\n\nPlease take this into account before further site inspections.",
+			permit.PermitNumber, emailData.PlanNumber, emailData.Status, emailData.ChangedOn, change.Reason,
+		)
+		for _, inspector := range inspectors {
+			if strings.TrimSpace(inspector.Email) == "" {
+				continue
+			}
+			if err := utils.SendEmail(inspector.Email, message, subject, "", ""); err != nil {
+				config.Logger.Warn("Failed to notify inspector of permit status change",
+					zap.Error(err),
+					zap.String("permitID", permit.ID.String()),
+					zap.String("inspectorID", inspector.ID.String()))
+				continue
+			}
+			inspectorsNotified++
+		}
+
+		if err := ac.ApplicationRepo.RecordPermitNotifications(change.ID, applicantNotified, inspectorsNotified); err != nil {
+			config.Logger.Warn("Failed to record permit status notifications",
+				zap.Error(err),
+				zap.String("statusChangeID", change.ID.String()))
+		}
+	}()
+}
+
+// GetPermitController returns a permit with its status history
+func (ac *ApplicationController) GetPermitController(c *fiber.Ctx) error {
+	permitID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid permit ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	permit, err := ac.ApplicationRepo.GetPermit(permitID)
+	if err != nil {
+		return c.Status(permitErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch permit",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Permit retrieved successfully",
+		"data": fiber.Map{
+			"permit":           permit,
+			"effective_status": permit.EffectiveStatus(time.Now()),
+		},
+	})
+}
+
+// GetApplicationPermitController returns the permit issued for an application
+func (ac *ApplicationController) GetApplicationPermitController(c *fiber.Ctx) error {
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	permit, err := ac.ApplicationRepo.GetApplicationPermit(applicationID)
+	if err != nil {
+		return c.Status(permitErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch permit",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Permit retrieved successfully",
+		"data": fiber.Map{
+			"permit":           permit,
+			"effective_status": permit.EffectiveStatus(time.Now()),
+		},
+	})
+}
+
+// VerifyPermitController is the public check of a permit number. It shows whether the permit is
+// valid, suspended, revoked or expired, but not the reasons or who changed it.
+func (ac *ApplicationController) VerifyPermitController(c *fiber.Ctx) error {
+	permitNumber := strings.TrimSpace(c.Query("number"))
+	if permitNumber == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Permit number is required",
+		})
+	}
+
+	permit, err := ac.ApplicationRepo.VerifyPermit(permitNumber)
+	if err != nil {
+		return c.Status(permitErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to verify permit",
+			"error":   err.Error(),
+		})
+	}
+
+	status := permit.EffectiveStatus(time.Now())
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Permit verified",
+		"data": fiber.Map{
+			"permit_number":        permit.PermitNumber,
+			"status":               status,
+			"valid":                status == models.PermitActive,
+			"plan_number":          permit.Application.PlanNumber,
+			"development_category": permit.DevelopmentCategory.Name,
+			"issue_date":           permit.IssueDate,
+			"valid_until":          permit.ValidUntil,
+			"status_changed_at":    permit.StatusChangedAt,
+		},
+	})
+}
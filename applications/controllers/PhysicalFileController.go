@@ -0,0 +1,319 @@
+package controllers
+
+import (
+	"time"
+	"town-planning-backend/applications/repositories"
+	"town-planning-backend/applications/requests"
+	"town-planning-backend/config"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// physicalFileErrorStatus maps physical file repository errors to HTTP status codes
+func physicalFileErrorStatus(err error) int {
+	switch err.Error() {
+	case "application not found", "physical file not found", "holder not found", "recipient not found":
+		return fiber.StatusNotFound
+	case "physical file already registered", "file is already in transit", "file is flagged as missing",
+		"file is already with this holder", "file is not in transit":
+		return fiber.StatusConflict
+	case "file was sent to a different holder":
+		return fiber.StatusForbidden
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// RegisterPhysicalFileController assigns a barcode to an application's paper file
+func (ac *ApplicationController) RegisterPhysicalFileController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var request requests.RegisterPhysicalFileRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+
+	holderID := payload.UserID
+	if request.HolderID != nil {
+		holderID = *request.HolderID
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	file, err := ac.ApplicationRepo.RegisterPhysicalFile(tx, applicationID, holderID, request.Location, payload.UserID.String())
+	if err != nil {
+		tx.Rollback()
+		return c.Status(physicalFileErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to register physical file",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("Physical file registered",
+		zap.String("applicationID", applicationID.String()),
+		zap.String("barcode", file.Barcode))
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Physical file registered successfully",
+		"data":    file,
+	})
+}
+
+// GetApplicationPhysicalFileController returns an application's paper file with its custody trail
+func (ac *ApplicationController) GetApplicationPhysicalFileController(c *fiber.Ctx) error {
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	file, err := ac.ApplicationRepo.GetApplicationPhysicalFile(applicationID)
+	if err != nil {
+		return c.Status(physicalFileErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch physical file",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Physical file retrieved successfully",
+		"data":    file,
+	})
+}
+
+// GetPhysicalFileByBarcodeController looks up a scanned file
+func (ac *ApplicationController) GetPhysicalFileByBarcodeController(c *fiber.Ctx) error {
+	file, err := ac.ApplicationRepo.GetPhysicalFileByBarcode(c.Params("barcode"))
+	if err != nil {
+		return c.Status(physicalFileErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch physical file",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Physical file retrieved successfully",
+		"data":    file,
+	})
+}
+
+// DispatchPhysicalFileController records a file leaving its current holder for another
+func (ac *ApplicationController) DispatchPhysicalFileController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	var request requests.DispatchPhysicalFileRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+	if request.ToHolderID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Recipient is required",
+			"error":   "missing_to_holder_id",
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	movement, err := ac.ApplicationRepo.DispatchPhysicalFile(tx, c.Params("barcode"), request.ToHolderID, request.ToLocation, request.Notes, payload.UserID)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(physicalFileErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to dispatch physical file",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Physical file dispatched successfully",
+		"data":    movement,
+	})
+}
+
+// ReceivePhysicalFileController records the recipient scanning in a dispatched file
+func (ac *ApplicationController) ReceivePhysicalFileController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	var request requests.ReceivePhysicalFileRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": "Invalid request payload",
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	file, err := ac.ApplicationRepo.ReceivePhysicalFile(tx, c.Params("barcode"), payload.UserID, request.Location)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(physicalFileErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to receive physical file",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Physical file received successfully",
+		"data":    file,
+	})
+}
+
+// GetMissingPhysicalFilesController flags files that have been in transit too long and lists
+// every file flagged missing. Query: hours (optional, defaults to 48).
+func (ac *ApplicationController) GetMissingPhysicalFilesController(c *fiber.Ctx) error {
+	threshold := repositories.DefaultPhysicalFileMissingThreshold
+	if hours := c.QueryInt("hours", 0); hours > 0 {
+		threshold = time.Duration(hours) * time.Hour
+	}
+
+	flagged, err := ac.ApplicationRepo.FlagMissingPhysicalFiles(ac.DB, threshold)
+	if err != nil {
+		config.Logger.Error("Failed to flag missing physical files", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to check for missing files",
+			"error":   err.Error(),
+		})
+	}
+	if flagged > 0 {
+		config.Logger.Warn("Physical files flagged as missing",
+			zap.Int64("count", flagged),
+			zap.Duration("threshold", threshold))
+	}
+
+	files, err := ac.ApplicationRepo.GetMissingPhysicalFiles()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch missing files",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Missing files retrieved successfully",
+		"data":    files,
+	})
+}
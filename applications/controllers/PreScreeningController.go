@@ -0,0 +1,354 @@
+package controllers
+
+import (
+	"strings"
+	"town-planning-backend/applications/repositories"
+	"town-planning-backend/applications/requests"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/token"
+	"town-planning-backend/utils"
+	"town-planning-backend/utils/pagination"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func preScreeningErrorStatus(err error) int {
+	switch {
+	case err.Error() == "application not found":
+		return fiber.StatusNotFound
+	case err.Error() == "application is not waiting for pre-screening",
+		err.Error() == "application was not returned to the applicant",
+		err.Error() == "application was never pre-screened":
+		return fiber.StatusConflict
+	case strings.HasPrefix(err.Error(), "unknown "),
+		strings.HasPrefix(err.Error(), "every checklist item must pass"),
+		err.Error() == "a passed application cannot have bounce reasons",
+		err.Error() == "at least one bounce reason is required",
+		err.Error() == "a note is required when the bounce reason is OTHER":
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// GetPreScreeningQueueController lists the submissions waiting for a technician's triage,
+// longest waiting first
+func (ac *ApplicationController) GetPreScreeningQueueController(c *fiber.Ctx) error {
+	page, err := pagination.ParseRequest(c, 20)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid pagination parameters",
+			"error":   err.Error(),
+		})
+	}
+
+	screenings, total, err := ac.ApplicationRepo.GetPreScreeningQueue(page)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch pre-screening queue",
+			"error":   err.Error(),
+		})
+	}
+
+	var next *pagination.Cursor
+	if len(screenings) > 0 {
+		last := screenings[len(screenings)-1]
+		next = page.NextCursor(len(screenings), last.CreatedAt, last.ID)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Pre-screening queue retrieved successfully",
+		"data":    pagination.NewEnvelope(c, page, screenings, total, next),
+	})
+}
+
+// GetApplicationPreScreeningsController lists an application's passes through pre-screening
+func (ac *ApplicationController) GetApplicationPreScreeningsController(c *fiber.Ctx) error {
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	screenings, err := ac.ApplicationRepo.GetApplicationPreScreenings(applicationID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch pre-screenings",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Pre-screenings retrieved successfully",
+		"data":    screenings,
+	})
+}
+
+// PreScreenApplicationController records a technician's triage of a queued application. A pass
+// assigns it to the approval group it was submitted for; a bounce returns it to the applicant,
+// who is told why by email and SMS.
+func (ac *ApplicationController) PreScreenApplicationController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var request requests.PreScreenApplicationRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+	outcome := strings.ToUpper(strings.TrimSpace(request.Outcome))
+	if outcome != requests.PreScreeningOutcomePass && outcome != requests.PreScreeningOutcomeBounce {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Outcome must be PASS or BOUNCE",
+			"error":   "invalid_outcome",
+		})
+	}
+	if request.Note != nil {
+		note := strings.TrimSpace(*request.Note)
+		request.Note = &note
+		if note == "" {
+			request.Note = nil
+		}
+	}
+
+	user, err := ac.UserRepo.GetUserProfileByID(payload.UserID.String())
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not found",
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	screening, err := ac.ApplicationRepo.RecordPreScreening(tx, applicationID, repositories.PreScreeningResult{
+		Checks:        request.Checks,
+		Pass:          outcome == requests.PreScreeningOutcomePass,
+		BounceReasons: request.BounceReasons,
+		Note:          request.Note,
+	}, payload.UserID)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(preScreeningErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to record pre-screening",
+			"error":   err.Error(),
+		})
+	}
+
+	var quorumWarning *models.QuorumCheck
+	if screening.Outcome == models.PreScreeningPassed {
+		assignment, err := ac.ApplicantRepo.AssignApplicationToGroup(tx, applicationID.String(), screening.ApprovalGroupID, user.Email, nil, payload.UserID)
+		if err != nil {
+			tx.Rollback()
+			if quorumErr := quorumNotMet(err); quorumErr != nil {
+				return c.Status(fiber.StatusConflict).JSON(quorumConflictBody("Approval group cannot decide applications right now", quorumErr))
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"message": "Failed to assign application to approval group",
+				"error":   err.Error(),
+			})
+		}
+		quorumWarning = assignment.Quorum
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("Application pre-screened",
+		zap.String("applicationID", applicationID.String()),
+		zap.String("outcome", string(screening.Outcome)),
+		zap.String("screenedBy", payload.UserID.String()))
+
+	if screening.Outcome == models.PreScreeningBounced {
+		if application, err := ac.ApplicationRepo.GetApplicationById(applicationID.String()); err != nil {
+			config.Logger.Warn("Failed to load application for pre-screening notification",
+				zap.Error(err),
+				zap.String("applicationID", applicationID.String()))
+		} else {
+			ac.notifyPreScreeningReturned(application, request.BounceReasons, request.Note)
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Pre-screening recorded",
+		"data": fiber.Map{
+			"screening":      screening,
+			"quorum_warning": quorumWarning,
+		},
+	})
+}
+
+// RequeuePreScreeningController puts a returned application back in the triage queue once the
+// applicant has fixed what it was bounced for
+func (ac *ApplicationController) RequeuePreScreeningController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	screening, err := ac.ApplicationRepo.RequeuePreScreening(tx, applicationID)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(preScreeningErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to requeue application for pre-screening",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("Application requeued for pre-screening",
+		zap.String("applicationID", applicationID.String()),
+		zap.String("requeuedBy", payload.UserID.String()))
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Application requeued for pre-screening",
+		"data":    screening,
+	})
+}
+
+// notifyPreScreeningReturned tells the applicant in the background why their submission was
+// returned
+func (ac *ApplicationController) notifyPreScreeningReturned(application *models.Application, reasons []models.PreScreeningBounceReason, note *string) {
+	applicant := application.Applicant
+	emailData := utils.PreScreeningReturnedEmail{
+		ApplicantName: applicant.FullName,
+		PlanNumber:    application.PlanNumber,
+		Reasons:       make([]string, 0, len(reasons)),
+	}
+	for _, reason := range reasons {
+		emailData.Reasons = append(emailData.Reasons, models.PreScreeningBounceReasons[reason])
+	}
+	if note != nil {
+		emailData.Note = *note
+	}
+
+	go func() {
+		if email := strings.TrimSpace(applicant.Email); email != "" {
+			subject, message, _, err := utils.RenderEmailTemplate(utils.EmailPreScreeningReturned, applicant.PreferredLanguage, emailData)
+			if err != nil {
+				config.Logger.Warn("Failed to render pre-screening email",
+					zap.Error(err),
+					zap.String("applicationID", application.ID.String()))
+			} else if err := utils.SendApplicantEmail(utils.ApplicantEmail{
+				To:            email,
+				Subject:       subject,
+				Body:          message,
+				Template:      utils.EmailPreScreeningReturned,
+				ApplicantID:   &applicant.ID,
+				ApplicationID: &application.ID,
+				TrackClicks:   true,
+			}); err != nil {
+				config.Logger.Warn("Failed to notify applicant of returned application",
+					zap.Error(err),
+					zap.String("applicationID", application.ID.String()))
+			}
+		}
+		if phone := strings.TrimSpace(applicant.PhoneNumber); phone != "" && utils.SMSEnabled() {
+			body, language, err := utils.RenderSMSTemplate(utils.EmailPreScreeningReturned, applicant.PreferredLanguage, emailData)
+			if err != nil {
+				config.Logger.Warn("Failed to render pre-screening SMS",
+					zap.Error(err),
+					zap.String("applicationID", application.ID.String()))
+			} else if err := utils.SendApplicantSMS(utils.ApplicantSMS{
+				To:            phone,
+				Body:          body,
+				Language:      language,
+				Template:      utils.EmailPreScreeningReturned,
+				ApplicantID:   &applicant.ID,
+				ApplicationID: &application.ID,
+			}); err != nil {
+				config.Logger.Warn("Failed to text applicant of returned application",
+					zap.Error(err),
+					zap.String("applicationID", application.ID.String()))
+			}
+		}
+	}()
+}
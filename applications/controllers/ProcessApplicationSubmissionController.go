@@ -81,7 +81,7 @@ func (ac *ApplicationController) ProcessApplicationSubmissionController(c *fiber
 	}
 
 	// Start transaction
-	tx := ac.DB.Session(&gorm.Session{}).WithContext(c.Context()).Begin()
+	tx := ac.DB.Session(&gorm.Session{}).WithContext(c.UserContext()).Begin()
 	if tx.Error != nil {
 		config.Logger.Error("Failed to start transaction", zap.Error(tx.Error))
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -234,6 +234,9 @@ func (ac *ApplicationController) ProcessApplicationSubmissionController(c *fiber
 		payment, err := ac.createPaymentRecordFromReceipt(tx, &application, req, updatedByStr)
 		if err != nil {
 			tx.Rollback()
+			if handled, response := ac.respondToDuplicatePayment(c, err); handled {
+				return response
+			}
 			config.Logger.Error("Failed to create payment record",
 				zap.String("applicationID", applicationID),
 				zap.Error(err))
@@ -359,8 +362,12 @@ func (ac *ApplicationController) createPaymentRecordFromReceipt(
 		existingPayment.PaymentStatus = models.PaidPayment
 		existingPayment.UpdatedAt = time.Now()
 
+		if err := ac.ApplicationRepo.CheckDuplicatePayment(tx, &existingPayment, existingPayment.ID); err != nil {
+			return nil, err
+		}
+
 		if err := tx.Save(&existingPayment).Error; err != nil {
-			return nil, fmt.Errorf("failed to update payment record: %w", err)
+			return nil, ac.ApplicationRepo.DuplicateReceiptError(&existingPayment, fmt.Errorf("failed to update payment record: %w", err))
 		}
 
 		return &existingPayment, nil
@@ -382,6 +389,10 @@ func (ac *ApplicationController) createPaymentRecordFromReceipt(
 		CreatedBy:       createdBy,
 	}
 
+	if err := ac.ApplicationRepo.CheckDuplicatePayment(tx, &payment, uuid.Nil); err != nil {
+		return nil, err
+	}
+
 	// Use the BeforeCreate hook to generate TransactionNumber
 	if err := payment.BeforeCreate(tx); err != nil {
 		return nil, fmt.Errorf("failed to prepare payment: %w", err)
@@ -389,7 +400,7 @@ func (ac *ApplicationController) createPaymentRecordFromReceipt(
 
 	// Create the payment record
 	if err := tx.Create(&payment).Error; err != nil {
-		return nil, fmt.Errorf("failed to create payment record: %w", err)
+		return nil, ac.ApplicationRepo.DuplicateReceiptError(&payment, fmt.Errorf("failed to create payment record: %w", err))
 	}
 
 	config.Logger.Info("New payment record created",
@@ -457,7 +468,8 @@ func (ac *ApplicationController) updateReadyForReviewFlag(
 		}
 	}
 
-	readyForReview := paymentStatus == models.PaidPayment && allDocsProvided
+	// An agreed installment plan stands in for upfront payment
+	readyForReview := (paymentStatus == models.PaidPayment || application.OnInstallmentPlan) && allDocsProvided
 
 	updates["ready_for_review"] = readyForReview
 
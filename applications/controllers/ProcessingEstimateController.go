@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	application_services "town-planning-backend/applications/services"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// GetProcessingEstimateController estimates how long a decision on a new application in a
+// development category will take and what it will cost. It is public so applicants can check
+// before applying; plan_area is optional and without it only the fixed fees are priced.
+func (ac *ApplicationController) GetProcessingEstimateController(c *fiber.Ctx) error {
+	categoryID, err := uuid.Parse(c.Query("development_category_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid development category ID",
+			"error":   "development_category_id must be a UUID",
+		})
+	}
+
+	var planArea *decimal.Decimal
+	if raw := c.Query("plan_area"); raw != "" {
+		area, err := decimal.NewFromString(raw)
+		if err != nil || !area.IsPositive() {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": "Invalid plan area",
+				"error":   "plan_area must be a positive number of square metres",
+			})
+		}
+		planArea = &area
+	}
+
+	estimate, err := ac.Estimator.Estimate(categoryID, planArea)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if err.Error() == "development category not found" {
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to estimate processing",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Processing estimate calculated",
+		"data":    estimate,
+	})
+}
+
+// processingEstimateFor estimates a submitted application's decision date. Estimates are a
+// courtesy, so a failure is logged and the estimate left out.
+func (ac *ApplicationController) processingEstimateFor(application *models.Application) *application_services.ProcessingEstimate {
+	estimate, err := ac.Estimator.EstimateForApplication(application)
+	if err != nil {
+		config.Logger.Warn("Failed to estimate application processing time",
+			zap.Error(err),
+			zap.String("applicationID", application.ID.String()))
+		return nil
+	}
+	return estimate
+}
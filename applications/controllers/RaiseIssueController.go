@@ -77,7 +77,7 @@ func (ac *ApplicationController) RaiseIssueController(c *fiber.Ctx) error {
 
 	userUUID := payload.UserID
 
-	user, err := ac.UserRepo.GetUserByID(userUUID.String())
+	user, err := ac.UserRepo.GetUserProfileByID(userUUID.String())
 	if err != nil {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"success": false,
@@ -86,7 +86,7 @@ func (ac *ApplicationController) RaiseIssueController(c *fiber.Ctx) error {
 	}
 
 	// --- Start Database Transaction ---
-	tx := ac.DB.Begin()
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
 	if tx.Error != nil {
 		config.Logger.Error("Failed to begin database transaction for raising issue",
 			zap.Error(tx.Error),
@@ -170,6 +170,8 @@ func (ac *ApplicationController) RaiseIssueController(c *fiber.Ctx) error {
 		ID:          initialMessage.ID, // Generate a new message ID
 		Content:     initialMessage.Content,
 		MessageType: initialMessage.MessageType,
+		EventType:   initialMessage.EventType,
+		EventParams: initialMessage.EventParams,
 		Status:      "SENT", // Or the appropriate status
 		CreatedAt:   initialMessage.CreatedAt.Format(time.RFC3339),
 		Sender: &applicationRepositories.UserSummary{
@@ -190,6 +192,18 @@ func (ac *ApplicationController) RaiseIssueController(c *fiber.Ctx) error {
 	// Now broadcast the message
 	ac.broadcastNewMessage(chatThread.ID.String(), *enhancedMessage, userUUID)
 
+	// An assignee from another department has to accept before joining the thread
+	var invitations []models.ChatParticipant
+	if err := tx.Where("thread_id = ? AND invitation_status = ?", chatThread.ID, models.InvitationPending).
+		Find(&invitations).Error; err != nil {
+		config.Logger.Warn("Failed to load thread invitations",
+			zap.Error(err),
+			zap.String("threadID", chatThread.ID.String()))
+	}
+	for _, invitation := range invitations {
+		ac.notifyThreadInvitation(invitation, user)
+	}
+
 	// --- Commit Database Transaction ---
 	if err := tx.Commit().Error; err != nil {
 		config.Logger.Error("Failed to commit database transaction for issue creation",
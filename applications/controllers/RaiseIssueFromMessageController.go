@@ -0,0 +1,213 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	applicationRepositories "town-planning-backend/applications/repositories"
+	"town-planning-backend/applications/requests"
+	application_services "town-planning-backend/applications/services"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// raiseIssueFromMessageErrorStatus maps raise-from-message repository errors to HTTP status codes
+func raiseIssueFromMessageErrorStatus(err error) int {
+	switch err.Error() {
+	case "message not found", "application not found":
+		return fiber.StatusNotFound
+	case "user is not a participant in this thread", "user not authorized to raise issues for this application",
+		"user does not have permission to raise issues":
+		return fiber.StatusForbidden
+	case "deleted messages cannot be raised as issues", "system messages cannot be raised as issues",
+		"message has no text to use as the issue description":
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// RaiseIssueFromMessageController converts a chat message into a formal issue. The new issue
+// links the message, copies its attachments and notes the conversion in both threads.
+func (ac *ApplicationController) RaiseIssueFromMessageController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	messageID, err := uuid.Parse(c.Params("messageId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid message ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var request requests.RaiseIssueFromMessageRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+	request.Title = strings.TrimSpace(request.Title)
+	if request.Title == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Issue title is required",
+		})
+	}
+	if request.AssignmentType == "" {
+		request.AssignmentType = models.IssueAssignment_COLLABORATIVE
+	}
+
+	user, err := ac.UserRepo.GetUserProfileByID(payload.UserID.String())
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Please log out and log in again",
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	sourceMessage, err := ac.ApplicationRepo.GetChatMessageForIssue(tx, messageID, payload.UserID)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(raiseIssueFromMessageErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to raise issue from message",
+			"error":   err.Error(),
+		})
+	}
+
+	issue, chatThread, initialMessage, err := ac.ApplicationRepo.RaiseIssueFromChatMessage(tx, sourceMessage, payload.UserID, request, user.Email)
+	if err != nil {
+		tx.Rollback()
+		config.Logger.Error("Failed to raise issue from chat message",
+			zap.Error(err),
+			zap.String("messageID", messageID.String()),
+			zap.String("userID", payload.UserID.String()))
+		return c.Status(raiseIssueFromMessageErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": fmt.Sprintf("Failed to raise issue: %s", err.Error()),
+			"error":   err.Error(),
+		})
+	}
+
+	if err := ac.incrementUnreadCounts(tx, chatThread.ID.String(), payload.UserID); err != nil {
+		config.Logger.Warn("Failed to increment unread counts",
+			zap.Error(err),
+			zap.String("threadID", chatThread.ID.String()))
+	}
+
+	// Note the conversion where the message was sent and in the new issue's thread
+	sourceNote, err := ac.postIssueLinkMessage(tx, sourceMessage.ThreadID, user, models.SystemEventIssueFromMessage, issue.Title)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to post note in the source thread",
+			"error":   err.Error(),
+		})
+	}
+	issueNote, err := ac.postIssueLinkMessage(tx, chatThread.ID, user, models.SystemEventIssueSourceMessage, sourceMessage.Thread.Title)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to post note in the issue thread",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not commit database transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	ac.broadcastNewMessage(chatThread.ID.String(), *ac.createEnhancedMessage(*initialMessage, *user), payload.UserID)
+	ac.broadcastNewMessage(chatThread.ID.String(), *issueNote, payload.UserID)
+	ac.broadcastNewMessage(sourceMessage.ThreadID.String(), *sourceNote, payload.UserID)
+
+	config.Logger.Info("Issue raised from chat message",
+		zap.String("messageID", messageID.String()),
+		zap.String("issueID", issue.ID.String()),
+		zap.String("chatThreadID", chatThread.ID.String()),
+		zap.Int("attachmentCount", len(initialMessage.Attachments)))
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Issue raised successfully",
+		"data": fiber.Map{
+			"issue":      issue,
+			"chatThread": chatThread,
+		},
+	})
+}
+
+// postIssueLinkMessage saves a system note linking a thread to an issue raised from a message
+func (ac *ApplicationController) postIssueLinkMessage(
+	tx *gorm.DB,
+	threadID uuid.UUID,
+	user *models.User,
+	eventType models.SystemEventType,
+	description string,
+) (*applicationRepositories.EnhancedChatMessage, error) {
+	message, err := ac.buildSystemMessage(threadID, user.ID, eventType,
+		application_services.SystemEventParams{
+			ActorName:   userFullName(user),
+			Description: description,
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build issue note: %w", err)
+	}
+
+	if err := tx.Create(&message).Error; err != nil {
+		return nil, fmt.Errorf("failed to create issue note: %w", err)
+	}
+
+	if err := tx.Model(&models.ChatThread{}).
+		Where("id = ?", threadID).
+		Update("last_activity_at", time.Now()).Error; err != nil {
+		config.Logger.Warn("Failed to update thread activity for issue note",
+			zap.Error(err),
+			zap.String("threadID", threadID.String()))
+	}
+
+	if err := ac.incrementUnreadCounts(tx, threadID.String(), user.ID); err != nil {
+		config.Logger.Warn("Failed to increment unread counts for issue note",
+			zap.Error(err),
+			zap.String("threadID", threadID.String()))
+	}
+
+	return ac.createEnhancedMessage(message, *user), nil
+}
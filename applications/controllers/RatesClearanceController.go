@@ -0,0 +1,241 @@
+package controllers
+
+import (
+	"errors"
+	"strings"
+	"town-planning-backend/applications/requests"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// RatesOverridePermission lets an officer accept an application whose stand's rates are not clear
+const RatesOverridePermission = "application.rates_override"
+
+// ratesClearanceErrorStatus maps rates clearance repository errors to HTTP status codes
+func ratesClearanceErrorStatus(err error) int {
+	switch err.Error() {
+	case "application not found", "no rates clearance check recorded":
+		return fiber.StatusNotFound
+	case "rates clearance does not need an override":
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// CheckApplicationRatesClearanceController re-checks the application's stand with the billing
+// system, e.g. after the owner has settled their rates
+func (ac *ApplicationController) CheckApplicationRatesClearanceController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	if !ac.RatesClearanceSvc.Enabled() {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"message": "Rates clearance checks are not configured",
+			"error":   "rates_clearance_disabled",
+		})
+	}
+
+	var application models.Application
+	if err := ac.DB.Preload("Stand").Where("id = ?", applicationID).First(&application).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"success": false,
+				"message": "Application not found",
+				"error":   "application_not_found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to load application",
+			"error":   err.Error(),
+		})
+	}
+	if application.Stand == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Application has no stand to check",
+			"error":   "missing_stand",
+		})
+	}
+
+	// Query billing before opening the transaction so a slow billing system holds no locks
+	clearance := ac.RatesClearanceSvc.CheckStand(c.Context(), application.Stand)
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	recorded, err := ac.ApplicationRepo.RecordRatesClearance(tx, applicationID, clearance, payload.UserID.String())
+	if err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to record rates clearance",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("Rates clearance checked",
+		zap.String("applicationID", applicationID.String()),
+		zap.String("status", string(recorded.Status)))
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Rates clearance checked",
+		"data":    recorded,
+	})
+}
+
+// OverrideRatesClearanceController accepts the application despite its latest clearance check,
+// recording who overrode it and why
+func (ac *ApplicationController) OverrideRatesClearanceController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var request requests.RatesClearanceOverrideRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+	reason := strings.TrimSpace(request.Reason)
+	if reason == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "A reason is required to override rates clearance",
+			"error":   "missing_reason",
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	override, err := ac.ApplicationRepo.OverrideRatesClearance(tx, applicationID, reason, payload.UserID)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(ratesClearanceErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to override rates clearance",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("Rates clearance overridden",
+		zap.String("applicationID", applicationID.String()),
+		zap.String("overriddenBy", payload.UserID.String()))
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Rates clearance overridden",
+		"data":    override,
+	})
+}
+
+// GetApplicationRatesClearancesController lists an application's rates clearance history
+func (ac *ApplicationController) GetApplicationRatesClearancesController(c *fiber.Ctx) error {
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	clearances, err := ac.ApplicationRepo.GetApplicationRatesClearances(applicationID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch rates clearances",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Rates clearances retrieved successfully",
+		"data": fiber.Map{
+			"enabled":    ac.RatesClearanceSvc.Enabled(),
+			"clearances": clearances,
+		},
+	})
+}
@@ -1,7 +1,9 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
+	applicationRepositories "town-planning-backend/applications/repositories"
 	"town-planning-backend/config"
 	"town-planning-backend/token"
 
@@ -9,8 +11,15 @@ import (
 	"go.uber.org/zap"
 )
 
-// RejectApplication handles application rejection by a group member
+// RejectApplication handles application rejection by a group member. Sending an
+// Idempotency-Key makes retries of the same rejection replay the first result.
 func (ac *ApplicationController) RejectApplicationController(c *fiber.Ctx) error {
+	return ac.idempotentDecision(c, "reject", func(idempotencyKey *string) error {
+		return ac.rejectApplication(c, idempotencyKey)
+	})
+}
+
+func (ac *ApplicationController) rejectApplication(c *fiber.Ctx, idempotencyKey *string) error {
 	var request RejectApplicationRequest
 	applicationID := c.Params("id")
 
@@ -31,12 +40,8 @@ func (ac *ApplicationController) RejectApplicationController(c *fiber.Ctx) error
 		})
 	}
 
-	if request.Reason == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Rejection reason is required",
-		})
-	}
+	// Whether a reason is required is up to the approval group's comment policy, checked by the
+	// repository
 
 	// Get user from context
 	payload, ok := c.Locals("user").(*token.Payload)
@@ -49,42 +54,18 @@ func (ac *ApplicationController) RejectApplicationController(c *fiber.Ctx) error
 
 	userUUID := payload.UserID
 
-	// --- Start Database Transaction ---
-	tx := ac.DB.Begin()
-	if tx.Error != nil {
-		config.Logger.Error("Failed to begin database transaction for rejection",
-			zap.Error(tx.Error),
-			zap.String("applicationID", applicationID),
-			zap.String("userID", userUUID.String()))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Internal server error: Could not start database transaction",
-			"error":   tx.Error.Error(),
-		})
-	}
-
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-			config.Logger.Error("Panic detected during rejection, rolling back transaction",
-				zap.Any("panic_reason", r),
-				zap.String("applicationID", applicationID),
-				zap.String("userID", userUUID.String()))
-			panic(r)
-		}
-	}()
-
-	// Process the rejection
+	// Process the rejection. The repository writes the decision in its own short transaction once
+	// it has worked out the outcome, so no transaction is held here.
 	rejectionResult, err := ac.ApplicationRepo.ProcessApplicationRejection(
-		tx,
+		c.UserContext(),
 		applicationID,
 		userUUID,
 		request.Reason,
 		request.Comment,
 		request.CommentType,
+		idempotencyKey,
 	)
 	if err != nil {
-		tx.Rollback()
 		config.Logger.Error("Failed to process application rejection",
 			zap.Error(err),
 			zap.String("applicationID", applicationID),
@@ -95,6 +76,14 @@ func (ac *ApplicationController) RejectApplicationController(c *fiber.Ctx) error
 			statusCode = fiber.StatusForbidden
 		} else if err.Error() == "application not found" {
 			statusCode = fiber.StatusNotFound
+		} else if err.Error() == "member is recused due to a declared conflict of interest" {
+			statusCode = fiber.StatusForbidden
+		} else if err.Error() == "conflict of interest declaration required" {
+			statusCode = fiber.StatusConflict
+		} else if errors.Is(err, applicationRepositories.ErrDecisionConflict) {
+			statusCode = fiber.StatusConflict
+		} else if errors.Is(err, applicationRepositories.ErrRejectionReasonRequired) {
+			statusCode = fiber.StatusUnprocessableEntity
 		}
 
 		return c.Status(statusCode).JSON(fiber.Map{
@@ -104,24 +93,13 @@ func (ac *ApplicationController) RejectApplicationController(c *fiber.Ctx) error
 		})
 	}
 
-	// --- Commit Database Transaction ---
-	if err := tx.Commit().Error; err != nil {
-		config.Logger.Error("Failed to commit database transaction for rejection",
-			zap.Error(err),
-			zap.String("applicationID", applicationID),
-			zap.String("userID", userUUID.String()))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Internal server error: Could not commit database transaction",
-			"error":   err.Error(),
-		})
-	}
-
 	config.Logger.Info("Application rejected successfully",
 		zap.String("applicationID", applicationID),
 		zap.String("userID", userUUID.String()),
 		zap.Bool("isFinalApprover", rejectionResult.IsFinalApprover))
 
+	ac.broadcastClosingMessages(rejectionResult.ClosingMessages, userUUID)
+
 	response := fiber.Map{
 		"success": true,
 		"message": "Application rejected successfully",
@@ -6,6 +6,7 @@ import (
 	"time"
 	applicationRepositories "town-planning-backend/applications/repositories"
 	"town-planning-backend/applications/requests"
+	application_services "town-planning-backend/applications/services"
 	"town-planning-backend/config"
 	"town-planning-backend/db/models"
 	"town-planning-backend/token"
@@ -41,7 +42,7 @@ func (ac *ApplicationController) ResolveIssueController(c *fiber.Ctx) error {
 
 	userUUID := payload.UserID
 
-	user, err := ac.UserRepo.GetUserByID(userUUID.String())
+	user, err := ac.UserRepo.GetUserProfileByID(userUUID.String())
 	if err != nil {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"success": false,
@@ -50,7 +51,7 @@ func (ac *ApplicationController) ResolveIssueController(c *fiber.Ctx) error {
 	}
 
 	// Start transaction
-	tx := ac.DB.Begin()
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
 	if tx.Error != nil {
 		config.Logger.Error("Failed to begin database transaction for resolving issue",
 			zap.Error(tx.Error),
@@ -214,7 +215,7 @@ func (ac *ApplicationController) ReopenIssueController(c *fiber.Ctx) error {
 
 	userUUID := payload.UserID
 
-	user, err := ac.UserRepo.GetUserByID(userUUID.String())
+	user, err := ac.UserRepo.GetUserProfileByID(userUUID.String())
 	if err != nil {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"success": false,
@@ -223,7 +224,7 @@ func (ac *ApplicationController) ReopenIssueController(c *fiber.Ctx) error {
 	}
 
 	// Start transaction
-	tx := ac.DB.Begin()
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
 	if tx.Error != nil {
 		config.Logger.Error("Failed to begin database transaction for reopening issue",
 			zap.Error(tx.Error),
@@ -378,24 +379,18 @@ func (ac *ApplicationController) createReopenMessage(
 		return nil, fmt.Errorf("issue has no chat thread")
 	}
 
-	user, err := ac.UserRepo.GetUserByID(reopenedByID.String())
+	user, err := ac.UserRepo.GetUserProfileByID(reopenedByID.String())
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
-	// Create professional reopen message content
-	messageContent := fmt.Sprintf("Issue reopened by %s %s", firstName, lastName)
-
 	// Create system message
-	message := models.ChatMessage{
-		ID:          uuid.New(),
-		ThreadID:    *issue.ChatThreadID,
-		SenderID:    reopenedByID,
-		Content:     messageContent,
-		MessageType: models.MessageTypeSystem,
-		Status:      models.MessageStatusSent,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+	message, err := ac.buildSystemMessage(*issue.ChatThreadID, reopenedByID, models.SystemEventIssueReopened,
+		application_services.SystemEventParams{
+			ActorName: fmt.Sprintf("%s %s", firstName, lastName),
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build reopen message: %w", err)
 	}
 
 	// Save message to database
@@ -427,7 +422,10 @@ func (ac *ApplicationController) createReopenMessage(
 		ID:          message.ID,
 		Content:     message.Content,
 		MessageType: message.MessageType,
+		EventType:   message.EventType,
+		EventParams: message.EventParams,
 		Status:      message.Status,
+		Origin:      message.Origin,
 		CreatedAt:   message.CreatedAt.Format(time.RFC3339),
 		Sender: &applicationRepositories.UserSummary{
 			ID:        message.SenderID,
@@ -483,27 +481,19 @@ func (ac *ApplicationController) createResolutionMessage(
 		return nil, fmt.Errorf("issue has no chat thread")
 	}
 
-	user, err := ac.UserRepo.GetUserByID(resolvedByID.String())
+	user, err := ac.UserRepo.GetUserProfileByID(resolvedByID.String())
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
-	// Create resolution message content
-	messageContent := fmt.Sprintf("Issue resolved by %s %s", user.FirstName, user.LastName)
-	if resolutionComment != "" {
-		messageContent = fmt.Sprintf("Issue resolved by %s %s:\n%s", user.FirstName, user.LastName, resolutionComment)
-	}
-
 	// Create system message
-	message := models.ChatMessage{
-		ID:          uuid.New(),
-		ThreadID:    *issue.ChatThreadID,
-		SenderID:    resolvedByID,
-		Content:     messageContent,
-		MessageType: models.MessageTypeSystem,
-		Status:      models.MessageStatusSent,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+	message, err := ac.buildSystemMessage(*issue.ChatThreadID, resolvedByID, models.SystemEventIssueResolved,
+		application_services.SystemEventParams{
+			ActorName: userFullName(user),
+			Comment:   resolutionComment,
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build resolution message: %w", err)
 	}
 
 	// Save message to database
@@ -536,7 +526,10 @@ func (ac *ApplicationController) createResolutionMessage(
 		ID:          message.ID,
 		Content:     message.Content,
 		MessageType: message.MessageType,
+		EventType:   message.EventType,
+		EventParams: message.EventParams,
 		Status:      message.Status,
+		Origin:      message.Origin,
 		CreatedAt:   message.CreatedAt.Format(time.RFC3339),
 		Sender: &applicationRepositories.UserSummary{
 			ID:        message.SenderID,
@@ -578,3 +571,158 @@ func (ac *ApplicationController) markThreadAsResolved(tx *gorm.DB, threadID stri
 
 	return nil
 }
+
+// BulkResolveIssuesController resolves several COLLABORATIVE issues at once, such as every
+// logistics issue about printing paper once stock arrives. Each issue's thread gets its own
+// resolution message.
+func (ac *ApplicationController) BulkResolveIssuesController(c *fiber.Ctx) error {
+	var request requests.BulkResolveIssuesRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+
+	request.ResolutionComment = strings.TrimSpace(request.ResolutionComment)
+	if request.ResolutionComment == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "A resolution note is required",
+		})
+	}
+	if request.Category != nil {
+		category := strings.ToUpper(strings.TrimSpace(*request.Category))
+		request.Category = &category
+		if category == "" {
+			request.Category = nil
+		}
+	}
+
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	userUUID := payload.UserID
+
+	user, err := ac.UserRepo.GetUserProfileByID(userUUID.String())
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Please log out and log in again",
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		config.Logger.Error("Failed to begin database transaction for bulk issue resolution",
+			zap.Error(tx.Error),
+			zap.String("userID", userUUID.String()))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			config.Logger.Error("Panic detected during bulk issue resolution, rolling back transaction",
+				zap.Any("panic_reason", r),
+				zap.String("userID", userUUID.String()))
+			panic(r)
+		}
+	}()
+
+	resolvedIssues, err := ac.ApplicationRepo.BulkResolveIssues(
+		tx,
+		request.IssueIDs,
+		request.Category,
+		userUUID,
+		request.ResolutionComment,
+	)
+	if err != nil {
+		tx.Rollback()
+		statusCode := fiber.StatusBadRequest
+		switch {
+		case strings.HasPrefix(err.Error(), "failed to"):
+			statusCode = fiber.StatusInternalServerError
+		case strings.Contains(err.Error(), "already resolved"), strings.Contains(err.Error(), "no open issues"):
+			statusCode = fiber.StatusConflict
+		}
+		return c.Status(statusCode).JSON(fiber.Map{
+			"success": false,
+			"message": fmt.Sprintf("Failed to resolve issues: %s", err.Error()),
+			"error":   err.Error(),
+		})
+	}
+
+	// Post a resolution message to every affected thread, broadcast once committed
+	resolutionMessages := map[string]applicationRepositories.EnhancedChatMessage{}
+	for i := range resolvedIssues {
+		issue := &resolvedIssues[i]
+		if issue.ChatThreadID == nil {
+			continue
+		}
+
+		resolutionMessage, err := ac.createResolutionMessage(tx, issue, userUUID, user.Email, request.ResolutionComment)
+		if err != nil {
+			tx.Rollback()
+			config.Logger.Error("Failed to create resolution message - rolling back bulk issue resolution",
+				zap.Error(err),
+				zap.String("issueID", issue.ID.String()),
+				zap.String("threadID", issue.ChatThreadID.String()))
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"message": "Failed to create resolution notification",
+				"error":   err.Error(),
+			})
+		}
+
+		if err := ac.markThreadAsResolved(tx, issue.ChatThreadID.String()); err != nil {
+			tx.Rollback()
+			config.Logger.Error("Failed to mark thread as resolved - rolling back bulk issue resolution",
+				zap.Error(err),
+				zap.String("threadID", issue.ChatThreadID.String()))
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"message": "Failed to update thread status",
+				"error":   err.Error(),
+			})
+		}
+		resolutionMessages[issue.ChatThreadID.String()] = *resolutionMessage
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		config.Logger.Error("Failed to commit database transaction for bulk issue resolution",
+			zap.Error(err),
+			zap.String("userID", userUUID.String()))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not commit database transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	for threadID, message := range resolutionMessages {
+		ac.broadcastNewMessage(threadID, message, userUUID)
+	}
+
+	config.Logger.Info("Issues resolved in bulk",
+		zap.Int("issueCount", len(resolvedIssues)),
+		zap.Int("threadCount", len(resolutionMessages)),
+		zap.String("category", utils.DerefString(request.Category)),
+		zap.String("resolvedBy", user.Email))
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": fmt.Sprintf("%d issues resolved", len(resolvedIssues)),
+		"data":    resolvedIssues,
+	})
+}
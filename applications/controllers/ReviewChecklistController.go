@@ -0,0 +1,338 @@
+package controllers
+
+import (
+	"strings"
+	"time"
+	"town-planning-backend/applications/repositories"
+	"town-planning-backend/applications/requests"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// reviewChecklistErrorStatus maps review checklist repository errors to HTTP statuses
+func reviewChecklistErrorStatus(err error) int {
+	switch err.Error() {
+	case "approval group not found", "checklist item not found":
+		return fiber.StatusNotFound
+	}
+	return fiber.StatusInternalServerError
+}
+
+// GetReviewChecklistController lists an approval group's review checklist. Retired items are
+// included with ?include_inactive=true.
+func (ac *ApplicationController) GetReviewChecklistController(c *fiber.Ctx) error {
+	groupID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid approval group ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	items, err := ac.ApplicationRepo.GetReviewChecklist(groupID, c.QueryBool("include_inactive", false))
+	if err != nil {
+		config.Logger.Error("Failed to fetch review checklist",
+			zap.Error(err),
+			zap.String("approvalGroupID", groupID.String()))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch review checklist",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Review checklist retrieved successfully",
+		"data":    items,
+	})
+}
+
+// CreateReviewChecklistItemController adds an item to an approval group's review checklist
+func (ac *ApplicationController) CreateReviewChecklistItemController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	groupID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid approval group ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var request requests.ReviewChecklistItemRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+
+	label := strings.TrimSpace(request.Label)
+	if label == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Label is required",
+			"error":   "missing_label",
+		})
+	}
+
+	isMandatory := true
+	if request.IsMandatory != nil {
+		isMandatory = *request.IsMandatory
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	item, err := ac.ApplicationRepo.CreateReviewChecklistItem(tx, &models.ReviewChecklistItem{
+		ApprovalGroupID: groupID,
+		Label:           label,
+		Description:     request.Description,
+		IsMandatory:     isMandatory,
+		SortOrder:       request.SortOrder,
+		IsActive:        true,
+		CreatedBy:       payload.UserID.String(),
+	})
+	if err != nil {
+		tx.Rollback()
+		return c.Status(reviewChecklistErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to create checklist item",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Checklist item created",
+		"data":    item,
+	})
+}
+
+// UpdateReviewChecklistItemController changes or retires an item of an approval group's review
+// checklist
+func (ac *ApplicationController) UpdateReviewChecklistItemController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	groupID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid approval group ID",
+			"error":   "invalid_uuid",
+		})
+	}
+	itemID, err := uuid.Parse(c.Params("itemId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid checklist item ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var request requests.UpdateReviewChecklistItemRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+
+	updatedBy := payload.UserID.String()
+	updates := map[string]interface{}{
+		"updated_by": &updatedBy,
+		"updated_at": time.Now(),
+	}
+	if request.Label != nil {
+		label := strings.TrimSpace(*request.Label)
+		if label == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": "Label cannot be empty",
+				"error":   "missing_label",
+			})
+		}
+		updates["label"] = label
+	}
+	if request.Description != nil {
+		updates["description"] = request.Description
+	}
+	if request.IsMandatory != nil {
+		updates["is_mandatory"] = *request.IsMandatory
+	}
+	if request.SortOrder != nil {
+		updates["sort_order"] = *request.SortOrder
+	}
+	if request.IsActive != nil {
+		updates["is_active"] = *request.IsActive
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	item, err := ac.ApplicationRepo.UpdateReviewChecklistItem(tx, groupID, itemID, updates)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(reviewChecklistErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to update checklist item",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Checklist item updated",
+		"data":    item,
+	})
+}
+
+// UpdateDecisionCommentPolicyController sets which of an approval group's member decisions must
+// come with a comment. Approvals need a comment and rejections a reason under the policy.
+func (ac *ApplicationController) UpdateDecisionCommentPolicyController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	groupID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid approval group ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var request requests.DecisionCommentPolicyRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+	policy := models.DecisionCommentPolicy(strings.ToUpper(strings.TrimSpace(string(request.CommentPolicy))))
+	if !policy.Valid() {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Comment policy must be NONE, APPROVALS, REJECTIONS or BOTH",
+			"error":   "invalid_comment_policy",
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	group, err := ac.ApplicationRepo.UpdateDecisionCommentPolicy(tx, groupID, policy, payload.UserID.String())
+	if err != nil {
+		tx.Rollback()
+		return c.Status(reviewChecklistErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to update comment policy",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	repositories.InvalidateApprovalGroupCache(groupID.String())
+
+	config.Logger.Info("Decision comment policy updated",
+		zap.String("approvalGroupID", groupID.String()),
+		zap.String("commentPolicy", string(policy)),
+		zap.String("updatedBy", payload.UserID.String()))
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Comment policy updated",
+		"data":    group,
+	})
+}
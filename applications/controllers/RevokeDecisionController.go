@@ -10,8 +10,15 @@ import (
 	"go.uber.org/zap"
 )
 
-// RevokeDecisionController handles revoking a user's decision on an application
+// RevokeDecisionController handles revoking a user's decision on an application. Sending an
+// Idempotency-Key makes retries of the same revocation replay the first result.
 func (ac *ApplicationController) RevokeDecisionController(c *fiber.Ctx) error {
+	return ac.idempotentDecision(c, "revoke", func(idempotencyKey *string) error {
+		return ac.revokeDecision(c, idempotencyKey)
+	})
+}
+
+func (ac *ApplicationController) revokeDecision(c *fiber.Ctx, idempotencyKey *string) error {
 	var request requests.RevokeDecisionRequest
 	applicationID := c.Params("id")
 
@@ -51,7 +58,7 @@ func (ac *ApplicationController) RevokeDecisionController(c *fiber.Ctx) error {
 	userUUID := payload.UserID
 
 	// --- Start Database Transaction ---
-	tx := ac.DB.Begin()
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
 	if tx.Error != nil {
 		config.Logger.Error("Failed to begin database transaction for revocation",
 			zap.Error(tx.Error),
@@ -81,6 +88,7 @@ func (ac *ApplicationController) RevokeDecisionController(c *fiber.Ctx) error {
 		applicationID,
 		userUUID,
 		request.Reason,
+		idempotencyKey,
 	)
 	if err != nil {
 		tx.Rollback()
@@ -0,0 +1,415 @@
+package controllers
+
+import (
+	"strings"
+	"town-planning-backend/applications/requests"
+	application_services "town-planning-backend/applications/services"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// riskScoringErrorStatus maps risk scoring repository errors to HTTP status codes
+func riskScoringErrorStatus(err error) int {
+	switch err.Error() {
+	case "development category not found":
+		return fiber.StatusNotFound
+	case "senior approval group not found":
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// assessApplicationRisk scores the application with the active profile and records the result.
+// When routeFromGroupID is given (at submission) and the application scores high, the assessment
+// names the senior approval group the application should be assigned to instead.
+func (ac *ApplicationController) assessApplicationRisk(
+	tx *gorm.DB,
+	application *models.Application,
+	routeFromGroupID *uuid.UUID,
+	createdBy string,
+) (*models.ApplicationRiskAssessment, []application_services.RiskFactorScore, error) {
+	profile, err := ac.ApplicationRepo.GetActiveRiskScoringProfile(tx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	inputs := application_services.RiskInputs{PlanArea: application.PlanArea}
+
+	var applicant models.Applicant
+	if err := tx.Select("id", "debtor").Where("id = ?", application.ApplicantID).First(&applicant).Error; err == nil {
+		inputs.ApplicantDebtor = applicant.Debtor
+	}
+
+	if application.TariffID != nil {
+		var tariff models.Tariff
+		if err := tx.Preload("DevelopmentCategory").Where("id = ?", *application.TariffID).First(&tariff).Error; err == nil {
+			inputs.Category = &tariff.DevelopmentCategory
+		}
+	}
+
+	if application.StandID != nil {
+		var stand models.Stand
+		if err := tx.Where("id = ?", *application.StandID).First(&stand).Error; err == nil {
+			inputs.Stand = &stand
+		}
+	}
+
+	rejections, err := ac.ApplicationRepo.CountApplicantRejections(tx, application.ApplicantID, application.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	inputs.PriorRejections = int(rejections)
+
+	assessment, factors, err := application_services.ScoreApplicationRisk(profile, inputs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if routeFromGroupID != nil && assessment.Level == models.RiskLevelHigh &&
+		profile.SeniorApprovalGroupID != nil && *profile.SeniorApprovalGroupID != *routeFromGroupID {
+		assessment.RoutedToGroupID = profile.SeniorApprovalGroupID
+	}
+
+	recorded, err := ac.ApplicationRepo.RecordRiskAssessment(tx, application.ID, assessment, createdBy)
+	if err != nil {
+		return nil, nil, err
+	}
+	application.RiskScore = &recorded.Score
+	application.RiskLevel = &recorded.Level
+	application.RiskAssessedAt = &recorded.AssessedAt
+
+	if recorded.Level == models.RiskLevelHigh {
+		config.Logger.Info("Application scored high risk",
+			zap.String("applicationID", application.ID.String()),
+			zap.Int("score", recorded.Score),
+			zap.Bool("routedToSeniorGroup", recorded.RoutedToGroupID != nil))
+	}
+	return recorded, factors, nil
+}
+
+// GetRiskScoringProfileController returns the active risk scoring profile, or the defaults when
+// none has been saved yet
+func (ac *ApplicationController) GetRiskScoringProfileController(c *fiber.Ctx) error {
+	var profile models.RiskScoringProfile
+	err := ac.DB.Preload("SeniorApprovalGroup").
+		Where("is_active = ?", true).
+		Order("created_at DESC").
+		First(&profile).Error
+	if err == gorm.ErrRecordNotFound {
+		return c.JSON(fiber.Map{
+			"success": true,
+			"message": "No risk scoring profile saved, showing defaults",
+			"data":    application_services.DefaultRiskScoringProfile(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch risk scoring profile",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Risk scoring profile retrieved successfully",
+		"data":    profile,
+	})
+}
+
+// UpdateRiskScoringProfileController replaces the active risk scoring profile. Existing scores
+// are kept; applications are rescored with the new profile when next assessed.
+func (ac *ApplicationController) UpdateRiskScoringProfileController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	var request requests.RiskScoringProfileRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+
+	user, err := ac.UserRepo.GetUserProfileByID(payload.UserID.String())
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not found",
+		})
+	}
+
+	profile := &models.RiskScoringProfile{
+		PlanAreaThreshold:     request.PlanAreaThreshold,
+		PlanAreaPoints:        request.PlanAreaPoints,
+		ApplicantDebtorPoints: request.ApplicantDebtorPoints,
+		PriorRejectionPoints:  request.PriorRejectionPoints,
+		PriorRejectionCap:     request.PriorRejectionCap,
+		UnservicedStandPoints: request.UnservicedStandPoints,
+		MediumRiskThreshold:   request.MediumRiskThreshold,
+		HighRiskThreshold:     request.HighRiskThreshold,
+		SeniorApprovalGroupID: request.SeniorApprovalGroupID,
+		CreatedBy:             user.Email,
+	}
+	if err := application_services.ValidateRiskScoringProfile(profile); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid risk scoring profile",
+			"error":   err.Error(),
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	if err := ac.ApplicationRepo.SaveRiskScoringProfile(tx, profile); err != nil {
+		tx.Rollback()
+		return c.Status(riskScoringErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to save risk scoring profile",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("Risk scoring profile updated",
+		zap.String("profileID", profile.ID.String()),
+		zap.String("updatedBy", user.Email))
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Risk scoring profile saved successfully",
+		"data":    profile,
+	})
+}
+
+// SetDevelopmentCategoryRiskController sets the points a development category adds to risk scores
+func (ac *ApplicationController) SetDevelopmentCategoryRiskController(c *fiber.Ctx) error {
+	categoryID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid development category ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var request requests.DevelopmentCategoryRiskRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+	if request.RiskPoints < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Risk points cannot be negative",
+			"error":   "invalid_risk_points",
+		})
+	}
+
+	category, err := ac.ApplicationRepo.SetDevelopmentCategoryRiskPoints(categoryID, request.RiskPoints)
+	if err != nil {
+		return c.Status(riskScoringErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to update development category",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Development category risk points updated",
+		"data":    category,
+	})
+}
+
+// AssessApplicationRiskController rescores an application with the active profile, e.g. after
+// the applicant settles their debt. Rescoring does not reassign the application.
+func (ac *ApplicationController) AssessApplicationRiskController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	user, err := ac.UserRepo.GetUserProfileByID(payload.UserID.String())
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not found",
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	var application models.Application
+	if err := tx.Where("id = ?", applicationID).First(&application).Error; err != nil {
+		tx.Rollback()
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"success": false,
+				"message": "Application not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to load application",
+			"error":   err.Error(),
+		})
+	}
+
+	assessment, factors, err := ac.assessApplicationRisk(tx, &application, nil, user.Email)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(riskScoringErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to assess application risk",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Application risk assessed",
+		"data": fiber.Map{
+			"assessment": assessment,
+			"factors":    factors,
+		},
+	})
+}
+
+// GetApplicationRiskAssessmentsController lists an application's risk assessments, newest first
+func (ac *ApplicationController) GetApplicationRiskAssessmentsController(c *fiber.Ctx) error {
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	assessments, err := ac.ApplicationRepo.GetApplicationRiskAssessments(applicationID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch risk assessments",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Risk assessments retrieved successfully",
+		"data":    assessments,
+	})
+}
+
+// GetApproverQueueController lists the applications awaiting the current user's decision,
+// highest risk first. Query: risk_level (LOW, MEDIUM or HIGH) to narrow the queue.
+func (ac *ApplicationController) GetApproverQueueController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	var level *models.RiskLevel
+	if value := strings.ToUpper(strings.TrimSpace(c.Query("risk_level"))); value != "" {
+		parsed := models.RiskLevel(value)
+		if parsed != models.RiskLevelLow && parsed != models.RiskLevelMedium && parsed != models.RiskLevelHigh {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": "Invalid risk level, expected LOW, MEDIUM or HIGH",
+				"error":   "invalid_risk_level",
+			})
+		}
+		level = &parsed
+	}
+
+	applications, err := ac.ApplicationRepo.GetApproverQueue(payload.UserID, level)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch approval queue",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Approval queue retrieved successfully",
+		"data":    applications,
+	})
+}
@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"math"
+	"strings"
+	"time"
+	application_services "town-planning-backend/applications/services"
+	"town-planning-backend/config"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// AwaitingApplicantRequest flags an issue as waiting on the applicant, or clears the flag
+type AwaitingApplicantRequest struct {
+	AwaitingApplicant *bool `json:"awaiting_applicant"`
+}
+
+// SetIssueAwaitingApplicantController flags an issue as waiting on the applicant, which pauses
+// the application's SLA clocks until the applicant replies or sends documents, or clears the
+// flag. The issue's raiser and whoever may resolve it can change the flag.
+func (ac *ApplicationController) SetIssueAwaitingApplicantController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	issueID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid issue ID",
+		})
+	}
+
+	var request AwaitingApplicantRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+	if request.AwaitingApplicant == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "awaiting_applicant is required",
+		})
+	}
+
+	issue, err := ac.ApplicationRepo.GetIssueByID(issueID.String())
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": "Issue not found",
+			"error":   err.Error(),
+		})
+	}
+	if issue.RaisedByUserID != payload.UserID && !issue.CanUserResolveIssue(payload.UserID) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"message": "You are not authorized to change this issue",
+			"details": issue.GetRequiredResolver(),
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	updated, pause, err := ac.ApplicationRepo.SetIssueAwaitingApplicant(tx, issueID, *request.AwaitingApplicant, payload.UserID)
+	if err != nil {
+		tx.Rollback()
+		status := fiber.StatusInternalServerError
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			status = fiber.StatusNotFound
+		case strings.HasPrefix(err.Error(), "issue is"):
+			status = fiber.StatusConflict
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to update issue",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not commit database transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	if pause != nil {
+		config.Logger.Info("Application SLA clocks changed",
+			zap.String("applicationID", updated.ApplicationID.String()),
+			zap.String("issueID", updated.ID.String()),
+			zap.Bool("paused", pause.IsOpen()),
+			zap.String("userID", payload.UserID.String()))
+	}
+
+	message := "Issue is no longer awaiting the applicant"
+	if updated.AwaitingApplicant {
+		message = "Issue is awaiting the applicant"
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": message,
+		"data": fiber.Map{
+			"issue":     updated,
+			"sla_pause": pause,
+		},
+	})
+}
+
+// GetApplicationSLAPausesController lists the periods an application's SLA clocks were paused
+// waiting on the applicant, with the paused time in total
+func (ac *ApplicationController) GetApplicationSLAPausesController(c *fiber.Ctx) error {
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+		})
+	}
+
+	pauses, err := ac.ApplicationRepo.GetApplicationSLAPauses(applicationID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch SLA pauses",
+			"error":   err.Error(),
+		})
+	}
+
+	var paused time.Duration
+	if len(pauses) > 0 {
+		paused = application_services.PausedDuration(pauses, pauses[0].PausedAt, time.Now())
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "SLA pauses retrieved",
+		"data": fiber.Map{
+			"is_paused":      application_services.IsSLAPaused(pauses),
+			"paused_seconds": int64(paused.Seconds()),
+			"paused_days":    math.Round(paused.Hours()/24*10) / 10,
+			"pauses":         pauses,
+		},
+	})
+}
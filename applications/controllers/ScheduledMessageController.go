@@ -0,0 +1,394 @@
+package controllers
+
+import (
+	"strings"
+	"time"
+	"town-planning-backend/applications/requests"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxScheduleAhead limits how far ahead a message can be scheduled
+	maxScheduleAhead = 90 * 24 * time.Hour
+
+	// scheduledMessageDispatchSchedule checks for due messages every minute
+	scheduledMessageDispatchSchedule = "* * * * *"
+
+	// maxScheduledMessagesPerRun stops one run from holding the dispatcher when a backlog builds up
+	maxScheduledMessagesPerRun = 200
+)
+
+// scheduledMessageErrorStatus maps scheduled message repository errors to HTTP status codes
+func scheduledMessageErrorStatus(err error) int {
+	switch err.Error() {
+	case "scheduled message not found", "thread not found or inactive":
+		return fiber.StatusNotFound
+	case "only the author can cancel a scheduled message", "user is not a participant in this thread":
+		return fiber.StatusForbidden
+	case "scheduled message has already been sent", "scheduled message is already cancelled",
+		"scheduled message could not be sent":
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// ScheduleMessageController composes a text message to be posted to the thread later, as the
+// current user
+func (ac *ApplicationController) ScheduleMessageController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	threadID, err := uuid.Parse(c.Params("threadId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid thread ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var request requests.ScheduleMessageRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+	content := strings.TrimSpace(request.Content)
+	if content == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Message content is required",
+			"error":   "empty_message",
+		})
+	}
+	now := time.Now()
+	if !request.ScheduledFor.After(now) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "scheduled_for must be in the future",
+			"error":   "invalid_schedule",
+		})
+	}
+	if request.ScheduledFor.Sub(now) > maxScheduleAhead {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Messages cannot be scheduled more than 90 days ahead",
+			"error":   "invalid_schedule",
+		})
+	}
+
+	user, err := ac.UserRepo.GetUserProfileByID(payload.UserID.String())
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not found",
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	if thread, err := ac.ApplicationRepo.VerifyThreadAccess(tx, threadID.String(), payload.UserID); err == nil {
+		if err := ac.ensureThreadWritable(thread, payload.UserID); err != nil {
+			tx.Rollback()
+			return threadNotWritableResponse(c, err)
+		}
+	}
+
+	scheduled, err := ac.ApplicationRepo.CreateScheduledMessage(tx, threadID, payload.UserID, content, request.ScheduledFor, user.Email)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(scheduledMessageErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to schedule message",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("Chat message scheduled",
+		zap.String("scheduledMessageID", scheduled.ID.String()),
+		zap.String("threadID", threadID.String()),
+		zap.String("userID", payload.UserID.String()),
+		zap.Time("scheduledFor", scheduled.ScheduledFor))
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Message scheduled successfully",
+		"data":    scheduled,
+	})
+}
+
+// GetScheduledMessagesController lists the current user's unsent scheduled messages.
+// Query: thread_id to limit the list to one thread.
+func (ac *ApplicationController) GetScheduledMessagesController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	var threadID *uuid.UUID
+	if value := c.Query("thread_id"); value != "" {
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": "Invalid thread ID",
+				"error":   "invalid_uuid",
+			})
+		}
+		threadID = &parsed
+	}
+
+	scheduled, err := ac.ApplicationRepo.GetScheduledMessages(payload.UserID, threadID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch scheduled messages",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Scheduled messages retrieved successfully",
+		"data":    scheduled,
+	})
+}
+
+// CancelScheduledMessageController withdraws a scheduled message before it is sent
+func (ac *ApplicationController) CancelScheduledMessageController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	scheduledID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid scheduled message ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	scheduled, err := ac.ApplicationRepo.CancelScheduledMessage(tx, scheduledID, payload.UserID)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(scheduledMessageErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to cancel scheduled message",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Scheduled message cancelled",
+		"data":    scheduled,
+	})
+}
+
+// dispatchDueScheduledMessages posts every message that has fallen due, each in its own
+// transaction so one failure does not hold back the rest
+func (ac *ApplicationController) dispatchDueScheduledMessages() {
+	for i := 0; i < maxScheduledMessagesPerRun; i++ {
+		if !ac.dispatchNextScheduledMessage() {
+			return
+		}
+	}
+	config.Logger.Warn("Scheduled message dispatch stopped at the per-run limit",
+		zap.Int("limit", maxScheduledMessagesPerRun))
+}
+
+// dispatchNextScheduledMessage sends one due message as its author and reports whether the
+// dispatcher should continue
+func (ac *ApplicationController) dispatchNextScheduledMessage() bool {
+	tx := ac.DB.Begin()
+	if tx.Error != nil {
+		config.Logger.Error("Failed to start scheduled message transaction", zap.Error(tx.Error))
+		return false
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	scheduled, err := ac.ApplicationRepo.ClaimDueScheduledMessage(tx, time.Now())
+	if err != nil || scheduled == nil {
+		tx.Rollback()
+		if err != nil {
+			config.Logger.Error("Failed to claim scheduled message", zap.Error(err))
+		}
+		return false
+	}
+
+	fail := func(reason string) bool {
+		config.Logger.Warn("Scheduled message could not be sent",
+			zap.String("scheduledMessageID", scheduled.ID.String()),
+			zap.String("reason", reason))
+		if err := ac.ApplicationRepo.MarkScheduledMessageFailed(tx, scheduled, reason); err != nil {
+			tx.Rollback()
+			config.Logger.Error("Failed to record scheduled message failure", zap.Error(err))
+			return false
+		}
+		return tx.Commit().Error == nil
+	}
+
+	threadID := scheduled.ThreadID.String()
+	user, err := ac.UserRepo.GetUserProfileByID(scheduled.SenderID.String())
+	if err != nil {
+		return fail("author not found")
+	}
+
+	// The author may have left the thread since composing the message
+	thread, err := ac.ApplicationRepo.VerifyThreadAccess(tx, threadID, scheduled.SenderID)
+	if err != nil {
+		return fail(err.Error())
+	}
+	if err := ac.ensureThreadWritable(thread, scheduled.SenderID); err != nil {
+		return fail(err.Error())
+	}
+
+	var applicationID *uuid.UUID
+	if thread.ApplicationID != uuid.Nil {
+		applicationID = &thread.ApplicationID
+	}
+
+	enhancedMessage, err := ac.ApplicationRepo.CreateMessageWithAttachments(
+		tx,
+		nil, // No attachments, so no request context is needed
+		threadID,
+		scheduled.Content,
+		models.MessageTypeText,
+		scheduled.SenderID,
+		nil,
+		nil,
+		applicationID,
+		user.Email,
+	)
+	if err != nil {
+		tx.Rollback()
+		config.Logger.Error("Failed to post scheduled message",
+			zap.Error(err),
+			zap.String("scheduledMessageID", scheduled.ID.String()))
+		return false
+	}
+
+	now := time.Now()
+	if err := tx.Model(&models.ChatThread{}).
+		Where("id = ?", threadID).
+		Updates(map[string]interface{}{
+			"updated_at":       now,
+			"last_activity_at": now,
+		}).Error; err != nil {
+		config.Logger.Warn("Failed to update thread timestamps",
+			zap.Error(err),
+			zap.String("threadID", threadID))
+	}
+
+	if err := ac.incrementUnreadCounts(tx, threadID, scheduled.SenderID); err != nil {
+		config.Logger.Warn("Failed to increment unread counts",
+			zap.Error(err),
+			zap.String("threadID", threadID))
+	}
+
+	if err := ac.ApplicationRepo.MarkScheduledMessageSent(tx, scheduled, enhancedMessage.ID); err != nil {
+		tx.Rollback()
+		config.Logger.Error("Failed to mark scheduled message sent", zap.Error(err))
+		return false
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		config.Logger.Error("Failed to commit scheduled message", zap.Error(err))
+		return false
+	}
+
+	ac.broadcastNewMessage(threadID, *enhancedMessage, scheduled.SenderID)
+
+	config.Logger.Info("Scheduled message sent",
+		zap.String("scheduledMessageID", scheduled.ID.String()),
+		zap.String("messageID", enhancedMessage.ID.String()),
+		zap.String("threadID", threadID))
+	return true
+}
+
+// RunScheduledMessageDispatch posts scheduled chat messages as they fall due
+func (ac *ApplicationController) RunScheduledMessageDispatch() {
+	c := cron.New()
+
+	c.AddFunc(scheduledMessageDispatchSchedule, ac.dispatchDueScheduledMessages)
+
+	c.Start()
+
+	// Keep running so the cron jobs can execute
+	select {}
+}
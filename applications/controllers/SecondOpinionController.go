@@ -0,0 +1,348 @@
+package controllers
+
+import (
+	"strings"
+	"time"
+	"town-planning-backend/applications/requests"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// secondOpinionErrorStatus maps second opinion repository errors to HTTP status codes
+func secondOpinionErrorStatus(err error) int {
+	switch err.Error() {
+	case "application not found", "reviewer not found", "second opinion not found":
+		return fiber.StatusNotFound
+	case "only members of the approval group can request a second opinion",
+		"second opinion was requested from a different reviewer", "second opinion was requested by another user":
+		return fiber.StatusForbidden
+	case "reviewer is a member of the approval group", "application has no approval group":
+		return fiber.StatusBadRequest
+	case "application is no longer under review", "second opinion is not pending",
+		"reviewer already has a pending second opinion for this application":
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// RequestSecondOpinionController lets a member of the application's approval group ask a
+// colleague outside the group for a non-binding second opinion by a due date
+func (ac *ApplicationController) RequestSecondOpinionController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var request requests.RequestSecondOpinionRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+	question := strings.TrimSpace(request.Question)
+	if request.ReviewerID == uuid.Nil || question == "" || request.DueDate.IsZero() {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Reviewer, question and due date are required",
+			"error":   "missing_fields",
+		})
+	}
+	if !request.DueDate.After(time.Now()) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Due date must be in the future",
+			"error":   "invalid_due_date",
+		})
+	}
+	if request.ReviewerID == payload.UserID {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "You cannot request a second opinion from yourself",
+			"error":   "invalid_reviewer",
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	secondOpinion, err := ac.ApplicationRepo.RequestSecondOpinion(
+		tx,
+		applicationID,
+		request.ReviewerID,
+		payload.UserID,
+		question,
+		request.DueDate,
+	)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(secondOpinionErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to request second opinion",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("Second opinion requested",
+		zap.String("applicationID", applicationID.String()),
+		zap.String("reviewerID", request.ReviewerID.String()),
+		zap.String("requestedByID", payload.UserID.String()))
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Second opinion requested",
+		"data":    secondOpinion,
+	})
+}
+
+// SubmitSecondOpinionController records the reviewer's advisory comment. It is shown to the
+// approval group but does not count towards its decision.
+func (ac *ApplicationController) SubmitSecondOpinionController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	requestID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid second opinion ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var request requests.SubmitSecondOpinionRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+	opinion := strings.TrimSpace(request.Opinion)
+	if opinion == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "An opinion is required",
+			"error":   "missing_opinion",
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	secondOpinion, err := ac.ApplicationRepo.SubmitSecondOpinion(tx, requestID, payload.UserID, opinion)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(secondOpinionErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to submit second opinion",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("Second opinion submitted",
+		zap.String("secondOpinionID", requestID.String()),
+		zap.String("applicationID", secondOpinion.ApplicationID.String()),
+		zap.String("reviewerID", payload.UserID.String()))
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Second opinion submitted",
+		"data":    secondOpinion,
+	})
+}
+
+// WithdrawSecondOpinionController cancels a pending second opinion that is no longer needed
+func (ac *ApplicationController) WithdrawSecondOpinionController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	requestID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid second opinion ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	secondOpinion, err := ac.ApplicationRepo.WithdrawSecondOpinion(tx, requestID, payload.UserID)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(secondOpinionErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to withdraw second opinion",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Second opinion withdrawn",
+		"data":    secondOpinion,
+	})
+}
+
+// GetApplicationSecondOpinionsController lists the second opinions asked on an application,
+// with which are still outstanding and overdue
+func (ac *ApplicationController) GetApplicationSecondOpinionsController(c *fiber.Ctx) error {
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	secondOpinions, err := ac.ApplicationRepo.GetApplicationSecondOpinions(applicationID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch second opinions",
+			"error":   err.Error(),
+		})
+	}
+
+	now := time.Now()
+	pending, overdue := 0, 0
+	for i := range secondOpinions {
+		if secondOpinions[i].IsOverdue(now) {
+			overdue++
+		}
+		if secondOpinions[i].Status == models.SecondOpinionPending {
+			pending++
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Second opinions retrieved successfully",
+		"data": fiber.Map{
+			"second_opinions": secondOpinions,
+			"pending":         pending,
+			"overdue":         overdue,
+		},
+	})
+}
+
+// GetMyPendingSecondOpinionsController lists the second opinions waiting on the current user
+func (ac *ApplicationController) GetMyPendingSecondOpinionsController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	secondOpinions, err := ac.ApplicationRepo.GetPendingSecondOpinions(payload.UserID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch pending second opinions",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Pending second opinions retrieved successfully",
+		"data":    secondOpinions,
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"mime/multipart"
 	"time"
 	applicationRepositories "town-planning-backend/applications/repositories"
+	application_services "town-planning-backend/applications/services"
 	"town-planning-backend/config"
 	"town-planning-backend/db/models"
 	"town-planning-backend/token"
@@ -61,6 +62,45 @@ func (ac *ApplicationController) SendMessageController(c *fiber.Ctx) error {
 		})
 	}
 
+	// A message starting with "/" is a command on the thread's issue, e.g. "/priority HIGH"
+	command, err := application_services.ParseChatCommand(content)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+			"error":   "invalid_command",
+		})
+	}
+	if command != nil && len(files) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Commands cannot be sent with attachments",
+			"error":   "invalid_command",
+		})
+	}
+	if command == nil {
+		content = application_services.UnescapeChatCommand(content)
+	}
+
+	// Voice notes must be readable and within the council's length and size limits
+	if err := application_services.LoadVoiceNoteLimits().ValidateVoiceNotes(files); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+			"error":   "invalid_voice_note",
+		})
+	}
+
+	// Each attachment may be filed under its own document category, e.g. a revised certificate
+	categoryCodes, err := ac.parseAttachmentCategories(c, form, files)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+			"error":   "invalid_attachment_category",
+		})
+	}
+
 	// Get user from context
 	payload, ok := c.Locals("user").(*token.Payload)
 	if !ok || payload == nil {
@@ -72,7 +112,7 @@ func (ac *ApplicationController) SendMessageController(c *fiber.Ctx) error {
 	senderUUID := payload.UserID
 
 	// Get user details
-	user, err := ac.UserRepo.GetUserByID(senderUUID.String())
+	user, err := ac.UserRepo.GetUserProfileByID(senderUUID.String())
 	if err != nil {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"success": false,
@@ -84,7 +124,7 @@ func (ac *ApplicationController) SendMessageController(c *fiber.Ctx) error {
 	chatMessageType := models.ChatMessageType(messageType)
 
 	// --- Start Database Transaction ---
-	tx := ac.DB.Begin()
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
 	if tx.Error != nil {
 		config.Logger.Error("Failed to begin database transaction for sending message",
 			zap.Error(tx.Error),
@@ -119,6 +159,15 @@ func (ac *ApplicationController) SendMessageController(c *fiber.Ctx) error {
 		})
 	}
 
+	if err := ac.ensureThreadWritable(thread, senderUUID); err != nil {
+		tx.Rollback()
+		return threadNotWritableResponse(c, err)
+	}
+
+	if command != nil {
+		return ac.runChatCommand(c, tx, thread, user, command)
+	}
+
 	// Get application ID from thread if available
 	var applicationID *uuid.UUID
 	if thread.ApplicationID != uuid.Nil {
@@ -134,6 +183,7 @@ func (ac *ApplicationController) SendMessageController(c *fiber.Ctx) error {
 		chatMessageType,
 		senderUUID,
 		files,
+		categoryCodes,
 		applicationID,
 		user.Email,
 	)
@@ -190,6 +240,9 @@ func (ac *ApplicationController) SendMessageController(c *fiber.Ctx) error {
 	// Also send typing stop indicator
 	ac.broadcastTypingIndicator(threadID, senderUUID, false)
 
+	// Cards for any links follow over the socket once fetched
+	ac.previewLinksAsync(threadID, enhancedMessage.ID, enhancedMessage.Content)
+
 	config.Logger.Info("Message sent and broadcasted successfully",
 		zap.String("threadID", threadID),
 		zap.String("userID", senderUUID.String()),
@@ -226,6 +279,22 @@ func (ac *ApplicationController) HandleTypingIndicator(c *fiber.Ctx) error {
 		})
 	}
 
+	threadUUID, err := uuid.Parse(threadID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid thread ID format",
+		})
+	}
+
+	if _, err := ac.ReadReceiptSvc.ThreadState().RecordTyping(threadUUID, payload.UserID, req.IsTyping); err != nil {
+		return c.Status(threadStateErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to record typing",
+			"error":   err.Error(),
+		})
+	}
+
 	ac.broadcastTypingIndicator(threadID, payload.UserID, req.IsTyping)
 
 	return c.JSON(fiber.Map{
@@ -261,9 +330,9 @@ func (ac *ApplicationController) MarkMessagesAsRead(c *fiber.Ctx) error {
 	}
 
 	// Process read receipts
-	processedCount, err := ac.processReadReceipts(threadID, payload.UserID, req.MessageIDs, req.IsRealtime)
+	processedCount, state, err := ac.ReadReceiptSvc.ProcessReadReceipts(threadID, payload.UserID, req.MessageIDs, req.IsRealtime)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(threadStateErrorStatus(err)).JSON(fiber.Map{
 			"success": false,
 			"message": "Failed to mark messages as read",
 			"error":   err.Error(),
@@ -274,6 +343,7 @@ func (ac *ApplicationController) MarkMessagesAsRead(c *fiber.Ctx) error {
 	if req.IsRealtime {
 		ac.broadcastReadReceipt(threadID, payload.UserID, req.MessageIDs)
 	}
+	ac.broadcastThreadState(threadID, state)
 
 	return c.JSON(fiber.Map{
 		"success": true,
@@ -281,100 +351,11 @@ func (ac *ApplicationController) MarkMessagesAsRead(c *fiber.Ctx) error {
 		"data": fiber.Map{
 			"processedCount": processedCount,
 			"threadId":       threadID,
+			"state":          application_services.NewThreadStateView(state),
 		},
 	})
 }
 
-// ==================== PROCESS READ RECEIPTS ====================
-func (ac *ApplicationController) processReadReceipts(threadID string, userID uuid.UUID, messageIDs []string, isRealtime bool) (int, error) {
-	processedCount := 0
-	readAt := time.Now()
-
-	// Start transaction
-	tx := ac.DB.Begin()
-	if tx.Error != nil {
-		return 0, tx.Error
-	}
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-		}
-	}()
-
-	for _, msgID := range messageIDs {
-		messageUUID, err := uuid.Parse(msgID)
-		if err != nil {
-			config.Logger.Warn("Invalid message ID for read receipt",
-				zap.String("messageID", msgID),
-				zap.String("userID", userID.String()))
-			continue
-		}
-
-		// Check if read receipt already exists
-		var existingReceipt models.ReadReceipt
-		result := tx.Where("message_id = ? AND user_id = ?", messageUUID, userID).First(&existingReceipt)
-
-		if result.Error == nil {
-			// Update existing receipt
-			if err := tx.Model(&existingReceipt).Updates(map[string]interface{}{
-				"read_at":     readAt,
-				"is_realtime": isRealtime,
-			}).Error; err != nil {
-				config.Logger.Warn("Failed to update read receipt",
-					zap.Error(err),
-					zap.String("messageID", msgID),
-					zap.String("userID", userID.String()))
-				continue
-			}
-		} else {
-			// Create new read receipt
-			readReceipt := models.ReadReceipt{
-				ID:         uuid.New(),
-				MessageID:  messageUUID,
-				UserID:     userID,
-				ReadAt:     readAt,
-				IsRealtime: isRealtime,
-			}
-
-			if err := tx.Create(&readReceipt).Error; err != nil {
-				config.Logger.Warn("Failed to create read receipt",
-					zap.Error(err),
-					zap.String("messageID", msgID),
-					zap.String("userID", userID.String()))
-				continue
-			}
-
-			// Update message read count
-			if err := tx.Model(&models.ChatMessage{}).
-				Where("id = ?", messageUUID).
-				UpdateColumn("read_count", gorm.Expr("read_count + ?", 1)).Error; err != nil {
-				config.Logger.Warn("Failed to update message read count",
-					zap.Error(err),
-					zap.String("messageID", msgID))
-			}
-		}
-		processedCount++
-	}
-
-	// Reset unread count for this user in this thread
-	if err := tx.Model(&models.ChatParticipant{}).
-		Where("thread_id = ? AND user_id = ?", threadID, userID).
-		Updates(map[string]interface{}{
-			"unread_count": 0,
-			"last_read_at": readAt,
-		}).Error; err != nil {
-		tx.Rollback()
-		return processedCount, fmt.Errorf("failed to reset unread count: %w", err)
-	}
-
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		return processedCount, fmt.Errorf("failed to commit read receipts: %w", err)
-	}
-
-	return processedCount, nil
-}
-
 // GetUnreadCount returns unread message count for a thread
 func (ac *ApplicationController) GetUnreadCount(c *fiber.Ctx) error {
 	threadID := c.Params("threadId")
@@ -441,7 +422,7 @@ func (ac *ApplicationController) broadcastTypingIndicator(threadID string, userI
 	}
 
 	// Get user details for the indicator
-	user, err := ac.UserRepo.GetUserByID(userID.String())
+	user, err := ac.UserRepo.GetUserProfileByID(userID.String())
 	if err != nil {
 		config.Logger.Warn("Failed to get user details for typing indicator",
 			zap.Error(err),
@@ -477,7 +458,7 @@ func (ac *ApplicationController) broadcastReadReceipt(threadID string, userID uu
 		return
 	}
 
-	user, err := ac.UserRepo.GetUserByID(userID.String())
+	user, err := ac.UserRepo.GetUserProfileByID(userID.String())
 	if err != nil {
 		config.Logger.Warn("Failed to get user details for read receipt",
 			zap.Error(err),
@@ -515,11 +496,14 @@ func (ac *ApplicationController) broadcastReadReceipt(threadID string, userID uu
 
 // incrementUnreadCounts increments unread counts for all participants except sender
 func (ac *ApplicationController) incrementUnreadCounts(tx *gorm.DB, threadID string, senderID uuid.UUID) error {
+	threadUUID, err := uuid.Parse(threadID)
+	if err != nil {
+		return fmt.Errorf("invalid thread ID: %w", err)
+	}
+
 	// Increment participant unread counts
-	if err := tx.Model(&models.ChatParticipant{}).
-		Where("thread_id = ? AND user_id != ? AND is_active = ?", threadID, senderID, true).
-		UpdateColumn("unread_count", gorm.Expr("unread_count + ?", 1)).Error; err != nil {
-		return fmt.Errorf("failed to increment participant unread counts: %w", err)
+	if err := ac.ReadReceiptSvc.ThreadState().IncrementUnread(tx, threadUUID, senderID); err != nil {
+		return err
 	}
 
 	// Increment thread unread count
@@ -532,6 +516,28 @@ func (ac *ApplicationController) incrementUnreadCounts(tx *gorm.DB, threadID str
 	return nil
 }
 
+// parseAttachmentCategories reads the document category chosen for each attachment and checks
+// that every category exists
+func (ac *ApplicationController) parseAttachmentCategories(c *fiber.Ctx, form *multipart.Form, files []*multipart.FileHeader) ([]string, error) {
+	categoryCodes, err := application_services.ParseAttachmentCategories(form.Value["attachment_categories"], len(files))
+	if err != nil {
+		return nil, err
+	}
+
+	db := ac.DB.WithContext(c.UserContext())
+	checked := map[string]bool{application_services.DefaultChatAttachmentCategory: true}
+	for _, code := range categoryCodes {
+		if checked[code] {
+			continue
+		}
+		if _, err := ac.DocumentSvc.DocumentRepo.GetCategoryByCode(db, code); err != nil {
+			return nil, fmt.Errorf("unknown document category %s", code)
+		}
+		checked[code] = true
+	}
+	return categoryCodes, nil
+}
+
 // Helper function to get form value
 func getFormValue(form *multipart.Form, key string) string {
 	if values, exists := form.Value[key]; exists && len(values) > 0 {
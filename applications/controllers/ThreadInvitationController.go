@@ -0,0 +1,263 @@
+package controllers
+
+import (
+	"time"
+	application_services "town-planning-backend/applications/services"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/token"
+	"town-planning-backend/websocket"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+// invitationExpirySchedule closes unanswered thread invitations once an hour
+const invitationExpirySchedule = "0 * * * *"
+
+// notifyThreadInvitation tells the invitee about a thread they have been invited to
+func (ac *ApplicationController) notifyThreadInvitation(participant models.ChatParticipant, invitedBy *models.User) {
+	if ac.WsHub == nil || invitedBy == nil {
+		return
+	}
+
+	ac.WsHub.SendToUser(participant.UserID, websocket.WebSocketMessage{
+		Type: websocket.MessageTypeInvitation,
+		Payload: fiber.Map{
+			"thread_id":  participant.ThreadID,
+			"role":       participant.Role,
+			"invited_by": userFullName(invitedBy),
+			"invited_at": participant.InvitedAt,
+			"expires_at": participant.InvitationExpiresAt,
+		},
+		Timestamp: time.Now(),
+		ThreadID:  participant.ThreadID.String(),
+	})
+
+	config.Logger.Info("Thread invitation sent",
+		zap.String("threadID", participant.ThreadID.String()),
+		zap.String("inviteeID", participant.UserID.String()),
+		zap.String("invitedBy", invitedBy.ID.String()))
+}
+
+// GetThreadInvitationsController lists the invitations of a thread still waiting for an answer
+func (ac *ApplicationController) GetThreadInvitationsController(c *fiber.Ctx) error {
+	threadID, err := uuid.Parse(c.Params("threadId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid thread ID",
+		})
+	}
+
+	invitations, err := ac.ApplicationRepo.GetThreadInvitations(threadID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch invitations",
+			"error":   err.Error(),
+		})
+	}
+
+	responses := make([]fiber.Map, len(invitations))
+	for i, invitation := range invitations {
+		department := ""
+		if invitation.User.Department != nil {
+			department = invitation.User.Department.Name
+		}
+		responses[i] = fiber.Map{
+			"id":         invitation.ID,
+			"user_id":    invitation.UserID,
+			"role":       invitation.Role,
+			"invited_at": invitation.InvitedAt,
+			"expires_at": invitation.InvitationExpiresAt,
+			"added_by":   invitation.AddedBy,
+			"user": fiber.Map{
+				"id":         invitation.User.ID,
+				"first_name": invitation.User.FirstName,
+				"last_name":  invitation.User.LastName,
+				"email":      invitation.User.Email,
+				"department": department,
+			},
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"invitations": responses,
+			"total_count": len(invitations),
+		},
+	})
+}
+
+// GetMyThreadInvitationsController lists the thread invitations waiting for the current user
+func (ac *ApplicationController) GetMyThreadInvitationsController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	invitations, err := ac.ApplicationRepo.GetUserThreadInvitations(payload.UserID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch invitations",
+			"error":   err.Error(),
+		})
+	}
+
+	responses := make([]fiber.Map, len(invitations))
+	for i, invitation := range invitations {
+		responses[i] = fiber.Map{
+			"id":           invitation.ID,
+			"thread_id":    invitation.ThreadID,
+			"thread_title": invitation.Thread.Title,
+			"role":         invitation.Role,
+			"invited_at":   invitation.InvitedAt,
+			"expires_at":   invitation.InvitationExpiresAt,
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"invitations": responses,
+			"total_count": len(invitations),
+		},
+	})
+}
+
+// AcceptThreadInvitationController makes the current user an active participant of a thread
+// they were invited to
+func (ac *ApplicationController) AcceptThreadInvitationController(c *fiber.Ctx) error {
+	return ac.respondToThreadInvitation(c, true)
+}
+
+// DeclineThreadInvitationController turns down an invitation to a thread
+func (ac *ApplicationController) DeclineThreadInvitationController(c *fiber.Ctx) error {
+	return ac.respondToThreadInvitation(c, false)
+}
+
+func (ac *ApplicationController) respondToThreadInvitation(c *fiber.Ctx, accept bool) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	threadID, err := uuid.Parse(c.Params("threadId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid thread ID",
+		})
+	}
+
+	user, err := ac.UserRepo.GetUserProfileByID(payload.UserID.String())
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not found",
+		})
+	}
+
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	participant, err := ac.ApplicationRepo.RespondToThreadInvitation(tx, threadID, payload.UserID, accept)
+	if err != nil {
+		tx.Rollback()
+		statusCode := fiber.StatusInternalServerError
+		switch err.Error() {
+		case "invitation not found":
+			statusCode = fiber.StatusNotFound
+		case "invitation is no longer pending", "invitation has expired":
+			statusCode = fiber.StatusConflict
+		}
+		return c.Status(statusCode).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to respond to invitation",
+			"error":   err.Error(),
+		})
+	}
+
+	eventType := models.SystemEventInvitationDeclined
+	if accept {
+		eventType = models.SystemEventInvitationAccepted
+	}
+	systemMessage, err := ac.buildSystemMessage(threadID, user.ID, eventType, application_services.SystemEventParams{
+		ActorName: userFullName(user),
+	})
+	if err == nil {
+		err = tx.Create(&systemMessage).Error
+	}
+	if err != nil {
+		config.Logger.Warn("Failed to create invitation response message", zap.Error(err))
+	} else {
+		if err := ac.incrementUnreadCounts(tx, threadID.String(), user.ID); err != nil {
+			config.Logger.Warn("Failed to increment unread counts for invitation response", zap.Error(err))
+		}
+		enhancedMessage := ac.createEnhancedMessage(systemMessage, *user)
+		ac.broadcastNewMessage(threadID.String(), *enhancedMessage, user.ID)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	message := "Invitation declined"
+	if accept {
+		message = "Invitation accepted"
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": message,
+		"data": fiber.Map{
+			"thread_id":         participant.ThreadID,
+			"user_id":           participant.UserID,
+			"invitation_status": participant.InvitationStatus,
+			"is_active":         participant.IsActive,
+		},
+	})
+}
+
+// expireThreadInvitations closes the invitations left unanswered past their expiry
+func (ac *ApplicationController) expireThreadInvitations() {
+	expired, err := ac.ApplicationRepo.ExpireThreadInvitations(time.Now())
+	if err != nil {
+		config.Logger.Error("Failed to expire thread invitations", zap.Error(err))
+		return
+	}
+	if expired > 0 {
+		config.Logger.Info("Expired unanswered thread invitations", zap.Int64("count", expired))
+	}
+}
+
+// RunInvitationExpiry expires unanswered thread invitations
+func (ac *ApplicationController) RunInvitationExpiry() {
+	c := cron.New()
+
+	c.AddFunc(invitationExpirySchedule, ac.expireThreadInvitations)
+
+	c.Start()
+
+	// Keep running so the cron jobs can execute
+	select {}
+}
@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"time"
+	application_services "town-planning-backend/applications/services"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/token"
+	"town-planning-backend/websocket"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type UpdateThreadMuteRequest struct {
+	Muted      bool       `json:"muted"`
+	MutedUntil *time.Time `json:"mutedUntil"`
+}
+
+// threadStateErrorStatus maps thread state service errors to HTTP status codes
+func threadStateErrorStatus(err error) int {
+	switch err.Error() {
+	case "user is not a participant in this thread":
+		return fiber.StatusForbidden
+	case "mute end must be in the future":
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// GetThreadStateController returns the caller's read, unread, typing and mute state for a thread,
+// along with who else is currently typing
+func (ac *ApplicationController) GetThreadStateController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	threadID, err := uuid.Parse(c.Params("threadId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid thread ID format",
+		})
+	}
+
+	state, err := ac.ReadReceiptSvc.ThreadState().GetState(threadID, payload.UserID)
+	if err != nil {
+		return c.Status(threadStateErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to get thread state",
+			"error":   err.Error(),
+		})
+	}
+
+	states, err := ac.ReadReceiptSvc.ThreadState().GetThreadStates(threadID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to get thread state",
+			"error":   err.Error(),
+		})
+	}
+
+	typingUserIDs := []uuid.UUID{}
+	for _, other := range states {
+		if other.UserID != payload.UserID && other.IsTyping(application_services.TypingWindow) {
+			typingUserIDs = append(typingUserIDs, other.UserID)
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"state":         application_services.NewThreadStateView(state),
+			"typingUserIds": typingUserIDs,
+		},
+	})
+}
+
+// UpdateThreadMuteController mutes or unmutes notifications for the caller in a thread
+func (ac *ApplicationController) UpdateThreadMuteController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	threadID, err := uuid.Parse(c.Params("threadId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid thread ID format",
+		})
+	}
+
+	var req UpdateThreadMuteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	state, err := ac.ReadReceiptSvc.ThreadState().SetMute(threadID, payload.UserID, req.Muted, req.MutedUntil)
+	if err != nil {
+		return c.Status(threadStateErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to update notification mute",
+			"error":   err.Error(),
+		})
+	}
+
+	ac.broadcastThreadState(threadID.String(), state)
+
+	message := "Thread notifications unmuted"
+	if req.Muted {
+		message = "Thread notifications muted"
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": message,
+		"data":    application_services.NewThreadStateView(state),
+	})
+}
+
+// broadcastThreadState pushes a user's thread state to all of their connected sessions
+func (ac *ApplicationController) broadcastThreadState(threadID string, state *models.ParticipantThreadState) {
+	if ac.WsHub == nil || state == nil {
+		return
+	}
+
+	ac.WsHub.SendToUser(state.UserID, websocket.WebSocketMessage{
+		Type:      websocket.MessageTypeThreadState,
+		Payload:   application_services.NewThreadStateView(state),
+		Timestamp: time.Now(),
+		ThreadID:  threadID,
+	})
+
+	config.Logger.Debug("Thread state broadcasted",
+		zap.String("threadID", threadID),
+		zap.String("userID", state.UserID.String()))
+}
@@ -0,0 +1,38 @@
+package controllers
+
+import (
+	"town-planning-backend/config"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// GetUnreadSummaryController returns the current user's badge counts in one call: unread
+// messages overall and per application, unresolved issues assigned to them and pending decisions
+func (ac *ApplicationController) GetUnreadSummaryController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	summary, err := ac.ApplicationRepo.GetUnreadSummary(payload.UserID)
+	if err != nil {
+		config.Logger.Error("Failed to build unread summary",
+			zap.Error(err),
+			zap.String("userID", payload.UserID.String()))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to get unread summary",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    summary,
+	})
+}
@@ -52,7 +52,7 @@ func (ac *ApplicationController) UpdateApplicationStatusController(c *fiber.Ctx)
 	}
 
 	// Start transaction
-	tx := ac.DB.Begin()
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
@@ -137,7 +137,7 @@ func (ac *ApplicationController) UpdateApplicationArchitectController(c *fiber.C
 	}
 
 	// Start transaction
-	tx := ac.DB.Begin()
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
@@ -223,7 +223,7 @@ func (ac *ApplicationController) RecalculateApplicationCostsController(c *fiber.
 	}
 
 	// Start transaction
-	tx := ac.DB.Begin()
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
@@ -314,7 +314,7 @@ func (ac *ApplicationController) MarkApplicationCollectedController(c *fiber.Ctx
 	}
 
 	// Start transaction
-	tx := ac.DB.Begin()
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
@@ -424,7 +424,7 @@ func (ac *ApplicationController) UpdateDocumentFlagsController(c *fiber.Ctx) err
 	}
 
 	// Start transaction
-	tx := ac.DB.Begin()
+	tx := ac.DB.WithContext(c.UserContext()).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
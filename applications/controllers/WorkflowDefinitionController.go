@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"fmt"
+	"time"
+	application_services "town-planning-backend/applications/services"
+	"town-planning-backend/config"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// GetWorkflowDefinitionController exports the configured approval workflow (groups, members,
+// decision policies, reminder SLAs and auto-rejection rules) with a process graph per group.
+// Inactive groups are left out unless include_inactive=true; download=true returns the
+// definition as a JSON file for diffing against another council's.
+func (ac *ApplicationController) GetWorkflowDefinitionController(c *fiber.Ctx) error {
+	includeInactive := c.QueryBool("include_inactive", false)
+
+	groups, checklists, err := ac.ApplicationRepo.GetWorkflowConfiguration(includeInactive)
+	if err != nil {
+		config.Logger.Error("Failed to load workflow configuration", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to load workflow configuration",
+			"error":   err.Error(),
+		})
+	}
+
+	now := time.Now()
+	definition, err := application_services.BuildWorkflowDefinition(
+		groups, checklists, application_services.LoadDecisionReminderPolicy(), now)
+	if err != nil {
+		config.Logger.Error("Failed to build workflow definition", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to build workflow definition",
+			"error":   err.Error(),
+		})
+	}
+
+	if c.QueryBool("download", false) {
+		c.Set(fiber.HeaderContentDisposition,
+			fmt.Sprintf(`attachment; filename="workflow-definition-%s.json"`, now.Format("20060102_150405")))
+		return c.JSON(definition)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Workflow definition retrieved successfully",
+		"data":    definition,
+	})
+}
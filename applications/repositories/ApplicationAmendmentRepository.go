@@ -0,0 +1,273 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// applicationStatusesAllowingAmendment are the states of an approved application whose plans
+// can still be varied
+var applicationStatusesAllowingAmendment = map[models.ApplicationStatus]bool{
+	models.ApprovedApplication:           true,
+	models.ReadyForCollectionApplication: true,
+	models.CollectedApplication:          true,
+}
+
+// openAmendmentStatuses are the statuses of an amendment that has not been decided yet
+var openAmendmentStatuses = []models.ApplicationAmendmentStatus{
+	models.AmendmentStatusPendingPayment,
+	models.AmendmentStatusPendingApproval,
+}
+
+// ValidateApplicationAmendment checks that an application's approval can be varied and returns
+// the application with the approval being varied
+func (r *applicationRepository) ValidateApplicationAmendment(tx *gorm.DB, applicationID uuid.UUID) (*models.Application, *models.FinalApproval, error) {
+	var application models.Application
+	if err := tx.Preload("Tariff").Preload("VATRate").Where("id = ?", applicationID).First(&application).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New("application not found")
+		}
+		return nil, nil, err
+	}
+
+	if !applicationStatusesAllowingAmendment[application.Status] {
+		return nil, nil, errors.New("only approved applications can be amended")
+	}
+
+	var approval models.FinalApproval
+	if err := tx.Where("application_id = ? AND decision = ?", applicationID, models.ApprovedApplication).
+		Order("decision_at DESC").
+		First(&approval).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New("application has no final approval")
+		}
+		return nil, nil, err
+	}
+
+	if err := ensurePermitAmendable(tx, applicationID); err != nil {
+		return nil, nil, err
+	}
+
+	var openCount int64
+	if err := tx.Model(&models.ApplicationAmendment{}).
+		Where("application_id = ? AND status IN ?", applicationID, openAmendmentStatuses).
+		Count(&openCount).Error; err != nil {
+		return nil, nil, err
+	}
+	if openCount > 0 {
+		return nil, nil, errors.New("an amendment is already open for this application")
+	}
+
+	return &application, &approval, nil
+}
+
+// ensurePermitAmendable refuses amendments to suspended and revoked permits. Applications whose
+// permit has not been printed yet have no permit record, which is fine.
+func ensurePermitAmendable(tx *gorm.DB, applicationID uuid.UUID) error {
+	var permit models.Permit
+	err := tx.Where("application_id = ?", applicationID).First(&permit).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load permit: %w", err)
+	}
+	switch permit.Status {
+	case models.PermitSuspended:
+		return errors.New("permit is suspended")
+	case models.PermitRevoked:
+		return errors.New("permit is revoked")
+	}
+	return nil
+}
+
+// CreateApplicationAmendment prices and stores an amendment request. The fee is the tariff's
+// amendment share of its permit and inspection fees plus VAT; amendments with no fee go
+// straight to approval.
+func (r *applicationRepository) CreateApplicationAmendment(
+	tx *gorm.DB,
+	application *models.Application,
+	amendment *models.ApplicationAmendment,
+) (*models.ApplicationAmendment, error) {
+	if application.Tariff == nil {
+		return nil, errors.New("application has no tariff to price the amendment")
+	}
+	tariff := application.Tariff
+
+	fee := tariff.PermitFee.Add(tariff.InspectionFee).
+		Mul(tariff.AmendmentFeePercent).
+		Div(decimal.NewFromInt(100)).
+		Round(2)
+	vatAmount := decimal.Zero
+	if application.VATRate != nil {
+		vatAmount = fee.Mul(application.VATRate.Rate).Round(2)
+	}
+
+	amendment.ApplicationID = application.ID
+	amendment.TariffID = &tariff.ID
+	amendment.Currency = tariff.Currency
+	amendment.Fee = fee
+	amendment.VATAmount = vatAmount
+	amendment.TotalFee = fee.Add(vatAmount)
+	amendment.Status = models.AmendmentStatusPendingPayment
+	if !amendment.TotalFee.IsPositive() {
+		amendment.Status = models.AmendmentStatusPendingApproval
+	}
+
+	if err := tx.Create(amendment).Error; err != nil {
+		return nil, fmt.Errorf("failed to create application amendment: %w", err)
+	}
+	return amendment, nil
+}
+
+// GetApplicationAmendments returns the amendments of an application, oldest first
+func (r *applicationRepository) GetApplicationAmendments(applicationID uuid.UUID) ([]models.ApplicationAmendment, error) {
+	var amendments []models.ApplicationAmendment
+	err := r.db.
+		Preload("RevisedPlanDocument").
+		Preload("PermitDocument").
+		Preload("Payment").
+		Preload("RequestedBy").
+		Preload("ReviewedBy").
+		Where("application_id = ?", applicationID).
+		Order("created_at ASC").
+		Find(&amendments).Error
+	return amendments, err
+}
+
+func (r *applicationRepository) getAmendmentForUpdate(tx *gorm.DB, amendmentID uuid.UUID) (*models.ApplicationAmendment, error) {
+	var amendment models.ApplicationAmendment
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", amendmentID).
+		First(&amendment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("amendment not found")
+		}
+		return nil, err
+	}
+	return &amendment, nil
+}
+
+// RecordAmendmentPayment records the payment of an amendment's fee, which must be paid in full,
+// and passes the amendment on for approval
+func (r *applicationRepository) RecordAmendmentPayment(tx *gorm.DB, amendmentID uuid.UUID, payment *models.Payment) (*models.ApplicationAmendment, error) {
+	amendment, err := r.getAmendmentForUpdate(tx, amendmentID)
+	if err != nil {
+		return nil, err
+	}
+	if amendment.Status != models.AmendmentStatusPendingPayment {
+		return nil, errors.New("amendment is not awaiting payment")
+	}
+	if !payment.Amount.Equal(amendment.TotalFee) {
+		return nil, errors.New("payment amount must equal the amendment fee")
+	}
+
+	payment.ApplicationID = &amendment.ApplicationID
+	payment.TariffID = amendment.TariffID
+	payment.PaymentFor = models.PaymentForAmendmentFee
+	payment.PaymentStatus = models.PaidPayment
+	payment.TransactionType = models.OrdinaryTransactionType
+	if err := r.CheckDuplicatePayment(tx, payment, uuid.Nil); err != nil {
+		return nil, err
+	}
+	if err := tx.Create(payment).Error; err != nil {
+		return nil, r.DuplicateReceiptError(payment, fmt.Errorf("failed to record payment: %w", err))
+	}
+
+	now := time.Now()
+	amendment.PaymentID = &payment.ID
+	amendment.PaidAt = &now
+	amendment.Status = models.AmendmentStatusPendingApproval
+	if err := tx.Save(amendment).Error; err != nil {
+		return nil, fmt.Errorf("failed to update amendment: %w", err)
+	}
+	return amendment, nil
+}
+
+// ApproveApplicationAmendment signs off an amendment and numbers it. The amended permit is
+// generated by the caller and linked with RecordAmendmentPermit.
+func (r *applicationRepository) ApproveApplicationAmendment(
+	tx *gorm.DB,
+	amendmentID uuid.UUID,
+	reviewerID uuid.UUID,
+	comment *string,
+) (*models.ApplicationAmendment, error) {
+	amendment, err := r.getAmendmentForUpdate(tx, amendmentID)
+	if err != nil {
+		return nil, err
+	}
+	switch amendment.Status {
+	case models.AmendmentStatusPendingApproval:
+	case models.AmendmentStatusPendingPayment:
+		return nil, errors.New("amendment fee has not been paid")
+	default:
+		return nil, errors.New("amendment has already been reviewed")
+	}
+	if amendment.RequestedByID == reviewerID {
+		return nil, errors.New("amendment cannot be approved by the officer who requested it")
+	}
+	if err := ensurePermitAmendable(tx, amendment.ApplicationID); err != nil {
+		return nil, err
+	}
+
+	var approvedCount int64
+	if err := tx.Model(&models.ApplicationAmendment{}).
+		Where("application_id = ? AND status = ?", amendment.ApplicationID, models.AmendmentStatusApproved).
+		Count(&approvedCount).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	amendment.AmendmentNumber = int(approvedCount) + 1
+	amendment.Status = models.AmendmentStatusApproved
+	amendment.ReviewedByID = &reviewerID
+	amendment.ReviewedAt = &now
+	amendment.ReviewComment = comment
+
+	if err := tx.Save(amendment).Error; err != nil {
+		return nil, fmt.Errorf("failed to update amendment: %w", err)
+	}
+	return amendment, nil
+}
+
+// RecordAmendmentPermit links the amended permit document to an approved amendment
+func (r *applicationRepository) RecordAmendmentPermit(tx *gorm.DB, amendmentID uuid.UUID, documentID uuid.UUID) error {
+	return tx.Model(&models.ApplicationAmendment{}).
+		Where("id = ?", amendmentID).
+		Update("permit_document_id", documentID).Error
+}
+
+// RejectApplicationAmendment closes an open amendment without touching the permit. A fee
+// already paid is not refunded here.
+func (r *applicationRepository) RejectApplicationAmendment(
+	tx *gorm.DB,
+	amendmentID uuid.UUID,
+	reviewerID uuid.UUID,
+	reason string,
+) (*models.ApplicationAmendment, error) {
+	amendment, err := r.getAmendmentForUpdate(tx, amendmentID)
+	if err != nil {
+		return nil, err
+	}
+	if amendment.Status != models.AmendmentStatusPendingPayment && amendment.Status != models.AmendmentStatusPendingApproval {
+		return nil, errors.New("amendment has already been reviewed")
+	}
+
+	now := time.Now()
+	amendment.Status = models.AmendmentStatusRejected
+	amendment.ReviewedByID = &reviewerID
+	amendment.ReviewedAt = &now
+	amendment.ReviewComment = &reason
+
+	if err := tx.Save(amendment).Error; err != nil {
+		return nil, fmt.Errorf("failed to update amendment: %w", err)
+	}
+	return amendment, nil
+}
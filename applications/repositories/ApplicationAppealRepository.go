@@ -0,0 +1,279 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// openAppealStatuses are the statuses of an appeal that has not been decided yet
+var openAppealStatuses = []models.ApplicationAppealStatus{
+	models.AppealStatusLodged,
+	models.AppealStatusHearingScheduled,
+}
+
+// ValidateApplicationAppeal checks that a rejected application can be appealed and returns the
+// application, the rejection being appealed and the appeals group that will hear it. Without an
+// appealsGroupID the first active appeals group is used.
+func (r *applicationRepository) ValidateApplicationAppeal(
+	tx *gorm.DB,
+	applicationID uuid.UUID,
+	appealsGroupID *uuid.UUID,
+	window time.Duration,
+) (*models.Application, *models.FinalApproval, *models.ApprovalGroup, error) {
+	var application models.Application
+	if err := tx.Preload("Tariff").Where("id = ?", applicationID).First(&application).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, nil, errors.New("application not found")
+		}
+		return nil, nil, nil, err
+	}
+
+	if application.Status != models.RejectedApplication {
+		return nil, nil, nil, errors.New("only rejected applications can be appealed")
+	}
+
+	var rejection models.FinalApproval
+	if err := tx.Where("application_id = ? AND decision = ?", applicationID, models.RejectedApplication).
+		Order("decision_at DESC").
+		First(&rejection).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, nil, errors.New("application has no final rejection")
+		}
+		return nil, nil, nil, err
+	}
+	if time.Since(rejection.DecisionAt) > window {
+		return nil, nil, nil, errors.New("the appeal window has closed")
+	}
+
+	var openCount int64
+	if err := tx.Model(&models.ApplicationAppeal{}).
+		Where("application_id = ? AND status IN ?", applicationID, openAppealStatuses).
+		Count(&openCount).Error; err != nil {
+		return nil, nil, nil, err
+	}
+	if openCount > 0 {
+		return nil, nil, nil, errors.New("an appeal is already open for this application")
+	}
+
+	query := tx.Where("type = ? AND is_active = ?", models.ApprovalGroupAppeals, true)
+	if appealsGroupID != nil {
+		query = query.Where("id = ?", *appealsGroupID)
+	}
+	var group models.ApprovalGroup
+	if err := query.Order("created_at ASC").First(&group).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, nil, errors.New("appeals group not found")
+		}
+		return nil, nil, nil, err
+	}
+	if application.AssignedGroupID != nil && *application.AssignedGroupID == group.ID {
+		return nil, nil, nil, errors.New("appeals must be heard by a group other than the one that reviewed the application")
+	}
+
+	return &application, &rejection, &group, nil
+}
+
+// CreateApplicationAppeal lodges an appeal. When the appeal carries a fee the payment must be
+// for the full fee and is recorded with the appeal.
+func (r *applicationRepository) CreateApplicationAppeal(
+	tx *gorm.DB,
+	appeal *models.ApplicationAppeal,
+	payment *models.Payment,
+) (*models.ApplicationAppeal, error) {
+	if appeal.Fee.IsPositive() {
+		if payment == nil {
+			return nil, errors.New("the appeal fee must be paid when lodging")
+		}
+		if !payment.Amount.Equal(appeal.Fee) {
+			return nil, errors.New("payment amount must equal the appeal fee")
+		}
+
+		payment.ApplicationID = &appeal.ApplicationID
+		payment.PaymentFor = models.PaymentForAppealFee
+		payment.PaymentStatus = models.PaidPayment
+		payment.TransactionType = models.OrdinaryTransactionType
+		if err := r.CheckDuplicatePayment(tx, payment, uuid.Nil); err != nil {
+			return nil, err
+		}
+		if err := tx.Create(payment).Error; err != nil {
+			return nil, r.DuplicateReceiptError(payment, fmt.Errorf("failed to record payment: %w", err))
+		}
+		appeal.PaymentID = &payment.ID
+	}
+
+	appeal.Status = models.AppealStatusLodged
+	if err := tx.Create(appeal).Error; err != nil {
+		return nil, fmt.Errorf("failed to create application appeal: %w", err)
+	}
+	return appeal, nil
+}
+
+// GetApplicationAppeals returns the appeals of an application with their hearings, oldest first
+func (r *applicationRepository) GetApplicationAppeals(applicationID uuid.UUID) ([]models.ApplicationAppeal, error) {
+	var appeals []models.ApplicationAppeal
+	err := r.db.
+		Preload("RejectionApproval", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped() // Set aside once the appeal was upheld
+		}).
+		Preload("Payment").
+		Preload("AppealsGroup").
+		Preload("LodgedBy").
+		Preload("DecidedBy").
+		Preload("Hearings", func(db *gorm.DB) *gorm.DB {
+			return db.Order("scheduled_at ASC")
+		}).
+		Where("application_id = ?", applicationID).
+		Order("created_at ASC").
+		Find(&appeals).Error
+	return appeals, err
+}
+
+func (r *applicationRepository) getAppealForUpdate(tx *gorm.DB, appealID uuid.UUID) (*models.ApplicationAppeal, error) {
+	var appeal models.ApplicationAppeal
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", appealID).
+		First(&appeal).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("appeal not found")
+		}
+		return nil, err
+	}
+	return &appeal, nil
+}
+
+// ensureAppealsGroupMember checks that the user sits on the group hearing the appeal
+func ensureAppealsGroupMember(tx *gorm.DB, groupID uuid.UUID, userID uuid.UUID) error {
+	var count int64
+	if err := tx.Model(&models.ApprovalGroupMember{}).
+		Where("approval_group_id = ? AND user_id = ? AND is_active = ?", groupID, userID, true).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("only members of the appeals group can handle this appeal")
+	}
+	return nil
+}
+
+// ScheduleAppealHearing sets the hearing date of an open appeal. A hearing already scheduled is
+// marked postponed.
+func (r *applicationRepository) ScheduleAppealHearing(
+	tx *gorm.DB,
+	appealID uuid.UUID,
+	userID uuid.UUID,
+	hearing *models.AppealHearing,
+) (*models.ApplicationAppeal, error) {
+	appeal, err := r.getAppealForUpdate(tx, appealID)
+	if err != nil {
+		return nil, err
+	}
+	if appeal.Status != models.AppealStatusLodged && appeal.Status != models.AppealStatusHearingScheduled {
+		return nil, errors.New("appeal has already been decided")
+	}
+	if err := ensureAppealsGroupMember(tx, appeal.AppealsGroupID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Model(&models.AppealHearing{}).
+		Where("appeal_id = ? AND status = ?", appeal.ID, models.AppealHearingScheduled).
+		Update("status", models.AppealHearingPostponed).Error; err != nil {
+		return nil, fmt.Errorf("failed to postpone earlier hearing: %w", err)
+	}
+
+	hearing.AppealID = appeal.ID
+	hearing.Status = models.AppealHearingScheduled
+	if err := tx.Create(hearing).Error; err != nil {
+		return nil, fmt.Errorf("failed to schedule hearing: %w", err)
+	}
+
+	appeal.Status = models.AppealStatusHearingScheduled
+	if err := tx.Save(appeal).Error; err != nil {
+		return nil, fmt.Errorf("failed to update appeal: %w", err)
+	}
+	appeal.Hearings = []models.AppealHearing{*hearing}
+	return appeal, nil
+}
+
+// DecideApplicationAppeal records the outcome of a heard appeal. Upholding it sets the rejection
+// aside; the caller reopens the application for review and links the new assignment with
+// RecordAppealReopening.
+func (r *applicationRepository) DecideApplicationAppeal(
+	tx *gorm.DB,
+	appealID uuid.UUID,
+	userID uuid.UUID,
+	upheld bool,
+	reason string,
+) (*models.ApplicationAppeal, error) {
+	appeal, err := r.getAppealForUpdate(tx, appealID)
+	if err != nil {
+		return nil, err
+	}
+	switch appeal.Status {
+	case models.AppealStatusHearingScheduled:
+	case models.AppealStatusLodged:
+		return nil, errors.New("appeal has not been heard yet")
+	default:
+		return nil, errors.New("appeal has already been decided")
+	}
+	if err := ensureAppealsGroupMember(tx, appeal.AppealsGroupID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Model(&models.AppealHearing{}).
+		Where("appeal_id = ? AND status = ?", appeal.ID, models.AppealHearingScheduled).
+		Update("status", models.AppealHearingHeld).Error; err != nil {
+		return nil, fmt.Errorf("failed to close hearing: %w", err)
+	}
+
+	now := time.Now()
+	appeal.Status = models.AppealStatusDismissed
+	if upheld {
+		appeal.Status = models.AppealStatusUpheld
+
+		// The rejection is kept for the record but no longer stands as the application's decision
+		if err := tx.Delete(&models.FinalApproval{}, "id = ?", appeal.RejectionApprovalID).Error; err != nil {
+			return nil, fmt.Errorf("failed to set aside rejection: %w", err)
+		}
+	}
+	appeal.DecidedByID = &userID
+	appeal.DecidedAt = &now
+	appeal.OutcomeReason = &reason
+
+	if err := tx.Save(appeal).Error; err != nil {
+		return nil, fmt.Errorf("failed to update appeal: %w", err)
+	}
+
+	comment := models.Comment{
+		ID:            uuid.New(),
+		ApplicationID: appeal.ApplicationID,
+		CommentType:   models.CommentTypeGeneral,
+		Content:       fmt.Sprintf("Appeal %s. Reason: %s", appealOutcomeText(upheld), reason),
+		UserID:        userID,
+		CreatedBy:     userID.String(),
+	}
+	if err := tx.Create(&comment).Error; err != nil {
+		return nil, fmt.Errorf("failed to record appeal decision: %w", err)
+	}
+
+	return appeal, nil
+}
+
+func appealOutcomeText(upheld bool) string {
+	if upheld {
+		return "upheld, application reopened for review"
+	}
+	return "dismissed, rejection stands"
+}
+
+// RecordAppealReopening links the review assignment opened for an upheld appeal
+func (r *applicationRepository) RecordAppealReopening(tx *gorm.DB, appealID uuid.UUID, assignmentID uuid.UUID) error {
+	return tx.Model(&models.ApplicationAppeal{}).
+		Where("id = ?", appealID).
+		Update("reopened_assignment_id", assignmentID).Error
+}
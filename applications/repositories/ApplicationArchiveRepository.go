@@ -0,0 +1,138 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	application_services "town-planning-backend/applications/services"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GetArchivableApplications finds terminal applications that closed before closedBefore and are
+// not archived yet, oldest first. Applications rehydrated after rehydratedAfter are left alone
+// so a legal query is not cut short by the next run.
+func (r *applicationRepository) GetArchivableApplications(closedBefore time.Time, rehydratedAfter time.Time, limit int) ([]models.Application, error) {
+	var applications []models.Application
+	if err := r.db.
+		Where("archived_at IS NULL AND status IN ?", application_services.ArchiveTerminalStatuses).
+		Where("COALESCE(collection_date, rejection_date, updated_at) < ?", closedBefore).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM application_archives aa
+			WHERE aa.application_id = applications.id AND aa.rehydrated_at > ?
+		)`, rehydratedAfter).
+		Order("COALESCE(collection_date, rejection_date, updated_at) ASC").
+		Limit(limit).
+		Find(&applications).Error; err != nil {
+		return nil, fmt.Errorf("failed to find archivable applications: %w", err)
+	}
+	return applications, nil
+}
+
+// GetArchivableDocuments lists the application's documents that belong to it alone. Documents
+// also filed against the applicant, a stand or another application stay uncompressed.
+func (r *applicationRepository) GetArchivableDocuments(applicationID uuid.UUID) ([]models.Document, error) {
+	var documents []models.Document
+	if err := r.db.
+		Joins("JOIN application_documents ad ON ad.document_id = documents.id AND ad.application_id = ?", applicationID).
+		Where("documents.file_path <> ''").
+		Where("NOT EXISTS (SELECT 1 FROM application_documents o WHERE o.document_id = documents.id AND o.application_id <> ?)", applicationID).
+		Where("NOT EXISTS (SELECT 1 FROM applicant_documents o WHERE o.document_id = documents.id)").
+		Where("NOT EXISTS (SELECT 1 FROM stand_documents o WHERE o.document_id = documents.id)").
+		Distinct().
+		Find(&documents).Error; err != nil {
+		return nil, fmt.Errorf("failed to load application documents: %w", err)
+	}
+	return documents, nil
+}
+
+// RecordApplicationArchive saves an archive with its compressed documents and marks the
+// application archived. The documents keep their original file_path; downloads find the
+// compressed copy through the archive.
+func (r *applicationRepository) RecordApplicationArchive(tx *gorm.DB, archive *models.ApplicationArchive) error {
+	if err := tx.Create(archive).Error; err != nil {
+		return fmt.Errorf("failed to record archive: %w", err)
+	}
+
+	result := tx.Model(&models.Application{}).
+		Where("id = ? AND archived_at IS NULL", archive.ApplicationID).
+		Update("archived_at", archive.ArchivedAt)
+	if result.Error != nil {
+		return fmt.Errorf("failed to mark application archived: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("application is already archived")
+	}
+	return nil
+}
+
+// GetOpenApplicationArchive returns the archive holding the application, with its documents
+func (r *applicationRepository) GetOpenApplicationArchive(applicationID uuid.UUID) (*models.ApplicationArchive, error) {
+	var archive models.ApplicationArchive
+	if err := r.db.
+		Preload("Documents").
+		Where("application_id = ? AND rehydrated_at IS NULL", applicationID).
+		Order("archived_at DESC").
+		First(&archive).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("application is not archived")
+		}
+		return nil, fmt.Errorf("failed to load archive: %w", err)
+	}
+	return &archive, nil
+}
+
+// RecordApplicationRehydration closes an archive and returns the application to the default
+// lists. Documents archived while file_path was repointed at the compressed copy get their
+// original path back.
+func (r *applicationRepository) RecordApplicationRehydration(tx *gorm.DB, archive *models.ApplicationArchive, userID uuid.UUID, reason string) error {
+	now := time.Now()
+	result := tx.Model(&models.ApplicationArchive{}).
+		Where("id = ? AND rehydrated_at IS NULL", archive.ID).
+		Updates(map[string]interface{}{
+			"rehydrated_at":      now,
+			"rehydrated_by_id":   userID,
+			"rehydration_reason": reason,
+			"updated_at":         now,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to record rehydration: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("application is not archived")
+	}
+
+	for _, document := range archive.Documents {
+		if err := tx.Model(&models.Document{}).
+			Where("id = ?", document.DocumentID).
+			Update("file_path", document.OriginalPath).Error; err != nil {
+			return fmt.Errorf("failed to restore document path: %w", err)
+		}
+	}
+
+	if err := tx.Model(&models.Application{}).
+		Where("id = ?", archive.ApplicationID).
+		Update("archived_at", nil).Error; err != nil {
+		return fmt.Errorf("failed to unarchive application: %w", err)
+	}
+
+	archive.RehydratedAt = &now
+	archive.RehydratedByID = &userID
+	archive.RehydrationReason = &reason
+	return nil
+}
+
+// GetApplicationArchives lists the application's archives, newest first
+func (r *applicationRepository) GetApplicationArchives(applicationID uuid.UUID) ([]models.ApplicationArchive, error) {
+	var archives []models.ApplicationArchive
+	if err := r.db.
+		Preload("RehydratedBy").
+		Where("application_id = ?", applicationID).
+		Order("archived_at DESC").
+		Find(&archives).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch application archives: %w", err)
+	}
+	return archives, nil
+}
@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CreateApplicationDraft saves a new draft
+func (r *applicationRepository) CreateApplicationDraft(draft *models.ApplicationDraft) error {
+	if err := r.db.Create(draft).Error; err != nil {
+		return fmt.Errorf("failed to create application draft: %w", err)
+	}
+	return nil
+}
+
+// GetApplicationDraft returns one of the user's drafts
+func (r *applicationRepository) GetApplicationDraft(draftID uuid.UUID, ownerID uuid.UUID) (*models.ApplicationDraft, error) {
+	var draft models.ApplicationDraft
+	if err := r.db.Where("id = ? AND owner_id = ?", draftID, ownerID).First(&draft).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("application draft not found")
+		}
+		return nil, fmt.Errorf("failed to load application draft: %w", err)
+	}
+	return &draft, nil
+}
+
+// GetUserApplicationDrafts lists the user's open drafts, most recently saved first
+func (r *applicationRepository) GetUserApplicationDrafts(ownerID uuid.UUID) ([]models.ApplicationDraft, error) {
+	var drafts []models.ApplicationDraft
+	if err := r.db.
+		Where("owner_id = ? AND status = ? AND expires_at > ?", ownerID, models.DraftApplicationDraft, time.Now()).
+		Order("last_saved_at DESC").
+		Find(&drafts).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch application drafts: %w", err)
+	}
+	return drafts, nil
+}
+
+// SaveApplicationDraft writes an autosave over the draft, provided nobody saved it since
+// expectedVersion was read. The draft's version is moved on.
+func (r *applicationRepository) SaveApplicationDraft(draft *models.ApplicationDraft, expectedVersion int) error {
+	result := r.db.Model(&models.ApplicationDraft{}).
+		Where("id = ? AND status = ? AND version = ?", draft.ID, models.DraftApplicationDraft, expectedVersion).
+		Updates(map[string]interface{}{
+			"label":         draft.Label,
+			"data":          draft.Data,
+			"version":       expectedVersion + 1,
+			"last_saved_at": draft.LastSavedAt,
+			"expires_at":    draft.ExpiresAt,
+			"updated_at":    time.Now(),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to save application draft: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("application draft was changed by another save")
+	}
+	draft.Version = expectedVersion + 1
+	return nil
+}
+
+// DeleteApplicationDraft discards one of the user's open drafts
+func (r *applicationRepository) DeleteApplicationDraft(draftID uuid.UUID, ownerID uuid.UUID) error {
+	result := r.db.
+		Where("id = ? AND owner_id = ? AND status = ?", draftID, ownerID, models.DraftApplicationDraft).
+		Delete(&models.ApplicationDraft{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete application draft: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("application draft not found")
+	}
+	return nil
+}
+
+// PromoteApplicationDraft closes a draft against the application it was submitted as, in the
+// transaction that creates the application
+func (r *applicationRepository) PromoteApplicationDraft(tx *gorm.DB, draftID uuid.UUID, applicationID uuid.UUID) error {
+	now := time.Now()
+	result := tx.Model(&models.ApplicationDraft{}).
+		Where("id = ? AND status = ?", draftID, models.DraftApplicationDraft).
+		Updates(map[string]interface{}{
+			"status":                  models.PromotedApplicationDraft,
+			"promoted_at":             now,
+			"promoted_application_id": applicationID,
+			"updated_at":              now,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to promote application draft: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("application draft is no longer open")
+	}
+	return nil
+}
+
+// ExpireApplicationDrafts marks open drafts whose expiry has passed as expired
+func (r *applicationRepository) ExpireApplicationDrafts(now time.Time) (int64, error) {
+	result := r.db.Model(&models.ApplicationDraft{}).
+		Where("status = ? AND expires_at <= ?", models.DraftApplicationDraft, now).
+		Updates(map[string]interface{}{
+			"status":     models.ExpiredApplicationDraft,
+			"updated_at": now,
+		})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to expire application drafts: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,277 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TransferApprovalResult describes what changed when a transfer was approved
+type TransferApprovalResult struct {
+	Transfer          *models.ApplicationTransfer
+	TariffChanged     bool
+	CostCalculation   *CostCalculation
+	RequiresQuotation bool
+	PreviousApplicant uuid.UUID
+	NewApplicant      uuid.UUID
+}
+
+// applicationStatusesBlockingTransfer are final states in which ownership can no longer change
+var applicationStatusesBlockingTransfer = map[models.ApplicationStatus]bool{
+	models.CollectedApplication: true,
+	models.RejectedApplication:  true,
+	models.ExpiredApplication:   true,
+}
+
+// ValidateApplicationTransfer checks that an application can be transferred to the given applicant.
+// The application stays locked for the rest of tx, so concurrent requests for it cannot both
+// find no pending transfer and create one each.
+func (r *applicationRepository) ValidateApplicationTransfer(tx *gorm.DB, applicationID uuid.UUID, toApplicantID uuid.UUID) (*models.Application, error) {
+	var application models.Application
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", applicationID).
+		First(&application).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("application not found")
+		}
+		return nil, err
+	}
+
+	if applicationStatusesBlockingTransfer[application.Status] {
+		return nil, errors.New("application can no longer be transferred")
+	}
+
+	if application.ApplicantID == toApplicantID {
+		return nil, errors.New("application already belongs to this applicant")
+	}
+
+	var newApplicant models.Applicant
+	if err := tx.Where("id = ?", toApplicantID).First(&newApplicant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("new applicant not found")
+		}
+		return nil, err
+	}
+
+	var pendingCount int64
+	if err := tx.Model(&models.ApplicationTransfer{}).
+		Where("application_id = ? AND status = ?", applicationID, models.TransferStatusPending).
+		Count(&pendingCount).Error; err != nil {
+		return nil, err
+	}
+	if pendingCount > 0 {
+		return nil, errors.New("a transfer is already pending for this application")
+	}
+
+	return &application, nil
+}
+
+// CreateApplicationTransfer stores a pending transfer request
+func (r *applicationRepository) CreateApplicationTransfer(tx *gorm.DB, transfer *models.ApplicationTransfer) (*models.ApplicationTransfer, error) {
+	transfer.Status = models.TransferStatusPending
+	if err := tx.Create(transfer).Error; err != nil {
+		return nil, fmt.Errorf("failed to create application transfer: %w", err)
+	}
+	return transfer, nil
+}
+
+// GetApplicationTransfers returns the full ownership history of an application, oldest first
+func (r *applicationRepository) GetApplicationTransfers(applicationID string) ([]models.ApplicationTransfer, error) {
+	var transfers []models.ApplicationTransfer
+	err := r.db.
+		Preload("FromApplicant").
+		Preload("ToApplicant").
+		Preload("SupportingDocument").
+		Preload("RequestedBy").
+		Preload("ReviewedBy").
+		Where("application_id = ?", applicationID).
+		Order("created_at ASC").
+		Find(&transfers).Error
+	return transfers, err
+}
+
+// getPendingTransfer locks a transfer for review so it cannot be approved and rejected, or
+// approved twice, at the same time
+func (r *applicationRepository) getPendingTransfer(tx *gorm.DB, transferID string) (*models.ApplicationTransfer, error) {
+	var transfer models.ApplicationTransfer
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", transferID).
+		First(&transfer).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("transfer not found")
+		}
+		return nil, err
+	}
+	if transfer.Status != models.TransferStatusPending {
+		return nil, errors.New("transfer has already been reviewed")
+	}
+	return &transfer, nil
+}
+
+// ApproveApplicationTransfer re-links the application to the new applicant. When the transfer
+// carries a development category whose active tariff differs from the application's tariff, the
+// application is re-priced and flagged as needing a fresh quotation.
+func (r *applicationRepository) ApproveApplicationTransfer(
+	tx *gorm.DB,
+	transferID string,
+	reviewerID uuid.UUID,
+	comment *string,
+) (*TransferApprovalResult, error) {
+	transfer, err := r.getPendingTransfer(tx, transferID)
+	if err != nil {
+		return nil, err
+	}
+
+	if transfer.RequestedByID == reviewerID {
+		return nil, errors.New("transfer cannot be approved by the officer who requested it")
+	}
+
+	var application models.Application
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Tariff").
+		Where("id = ?", transfer.ApplicationID).
+		First(&application).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("application not found")
+		}
+		return nil, err
+	}
+
+	if applicationStatusesBlockingTransfer[application.Status] {
+		return nil, errors.New("application can no longer be transferred")
+	}
+
+	// The application may have changed hands another way since the request was made
+	if application.ApplicantID != transfer.FromApplicantID {
+		return nil, errors.New("application applicant changed since the transfer was requested")
+	}
+
+	result := &TransferApprovalResult{
+		PreviousApplicant: application.ApplicantID,
+		NewApplicant:      transfer.ToApplicantID,
+	}
+
+	transfer.PreviousTariffID = application.TariffID
+	transfer.PreviousTotalCost = application.TotalCost
+
+	// Re-price if the new applicant falls under a different tariff
+	if transfer.NewDevelopmentCategoryID != nil &&
+		(application.Tariff == nil || application.Tariff.DevelopmentCategoryID != *transfer.NewDevelopmentCategoryID) {
+
+		newTariff, err := r.getActiveTariffForCategory(tx, *transfer.NewDevelopmentCategoryID)
+		if err != nil {
+			return nil, err
+		}
+
+		if application.TariffID == nil || *application.TariffID != newTariff.ID {
+			if application.VATRateID == nil || application.PlanArea == nil {
+				return nil, errors.New("application is missing VAT rate or plan area for re-pricing")
+			}
+
+			costs, err := r.RecalculateApplicationCosts(tx, application.ID, newTariff.ID, *application.VATRateID, *application.PlanArea)
+			if err != nil {
+				return nil, err
+			}
+
+			result.TariffChanged = true
+			result.CostCalculation = costs
+			result.RequiresQuotation = true
+			transfer.NewTariffID = &newTariff.ID
+			newTotal := costs.TotalCost
+			transfer.NewTotalCost = &newTotal
+		}
+	}
+
+	reviewer := reviewerID.String()
+	updates := map[string]interface{}{
+		"applicant_id": transfer.ToApplicantID,
+		"updated_by":   reviewer,
+	}
+	if result.RequiresQuotation {
+		// The old quotation was issued at the previous tariff
+		updates["processed_quotation_provided"] = false
+	}
+
+	if err := tx.Model(&models.Application{}).
+		Where("id = ?", application.ID).
+		Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to update application applicant: %w", err)
+	}
+
+	// The sale transfers the whole property, so the sellers' co-owners leave with them
+	if err := tx.Where("application_id = ?", application.ID).
+		Delete(&models.ApplicationCoApplicant{}).Error; err != nil {
+		return nil, fmt.Errorf("failed to clear previous co-applicants: %w", err)
+	}
+	if err := r.SetPrimaryCoApplicant(tx, application.ID, transfer.ToApplicantID, reviewer); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	transfer.Status = models.TransferStatusApproved
+	transfer.ReviewedByID = &reviewerID
+	transfer.ReviewedAt = &now
+	transfer.ReviewComment = comment
+
+	if err := tx.Save(transfer).Error; err != nil {
+		return nil, fmt.Errorf("failed to update transfer: %w", err)
+	}
+
+	result.Transfer = transfer
+	return result, nil
+}
+
+// RecordTransferQuotation links the regenerated quotation document to an approved transfer
+func (r *applicationRepository) RecordTransferQuotation(tx *gorm.DB, transferID uuid.UUID, documentID uuid.UUID) error {
+	return tx.Model(&models.ApplicationTransfer{}).
+		Where("id = ?", transferID).
+		Updates(map[string]interface{}{
+			"quotation_regenerated": true,
+			"quotation_document_id": documentID,
+		}).Error
+}
+
+// RejectApplicationTransfer closes a pending transfer without touching the application
+func (r *applicationRepository) RejectApplicationTransfer(
+	tx *gorm.DB,
+	transferID string,
+	reviewerID uuid.UUID,
+	reason string,
+) (*models.ApplicationTransfer, error) {
+	transfer, err := r.getPendingTransfer(tx, transferID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	transfer.Status = models.TransferStatusRejected
+	transfer.ReviewedByID = &reviewerID
+	transfer.ReviewedAt = &now
+	transfer.ReviewComment = &reason
+
+	if err := tx.Save(transfer).Error; err != nil {
+		return nil, fmt.Errorf("failed to update transfer: %w", err)
+	}
+	return transfer, nil
+}
+
+func (r *applicationRepository) getActiveTariffForCategory(tx *gorm.DB, developmentCategoryID uuid.UUID) (*models.Tariff, error) {
+	var tariff models.Tariff
+	now := time.Now()
+	err := tx.Where("development_category_id = ? AND is_active = ? AND valid_from <= ? AND (valid_to IS NULL OR valid_to >= ?)",
+		developmentCategoryID, true, now, now).
+		Order("valid_from DESC").
+		First(&tariff).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("no active tariff for the new development category")
+		}
+		return nil, err
+	}
+	return &tariff, nil
+}
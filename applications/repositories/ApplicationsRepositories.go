@@ -2,6 +2,7 @@
 package repositories
 
 import (
+	"context"
 	"fmt"
 	"mime/multipart"
 	"strings"
@@ -9,6 +10,7 @@ import (
 	"town-planning-backend/applications/requests"
 	"town-planning-backend/db/models"
 	documents_services "town-planning-backend/documents/services"
+	"town-planning-backend/utils/pagination"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -31,7 +33,7 @@ type ApplicationRepository interface {
 	GetTariffByID(tariffID string) (*models.Tariff, error)
 
 	// Application query methods
-	GetFilteredApplications(limit, offset int, filters map[string]string) ([]models.Application, int64, error)
+	GetFilteredApplications(ctx context.Context, page pagination.Request, filters map[string]string) ([]models.Application, int64, error)
 	GetApplicationById(applicationID string) (*models.Application, error)
 	GetApplicationForUpdate(applicationID string) (*models.Application, error)
 	GetApplicationsByStatus(status models.ApplicationStatus, limit, offset int) ([]models.Application, int64, error)
@@ -56,20 +58,28 @@ type ApplicationRepository interface {
 
 	// Approval workflow methods
 	GetEnhancedApplicationApprovalData(applicationID string, currentUserID uuid.UUID) (*ApplicationApprovalData, error)
-	ProcessApplicationApproval(tx *gorm.DB, applicationID string, userID uuid.UUID, comment *string, commentType models.CommentType) (*ApprovalResult, error)
-	ProcessApplicationRejection(tx *gorm.DB, applicationID string, userID uuid.UUID, reason string, comment *string, commentType models.CommentType) (*RejectionResult, error)
+	ProcessApplicationApproval(ctx context.Context, applicationID string, userID uuid.UUID, comment *string, commentType models.CommentType, checkedItemIDs []uuid.UUID, idempotencyKey *string) (*ApprovalResult, error)
+	ProcessApplicationRejection(ctx context.Context, applicationID string, userID uuid.UUID, reason string, comment *string, commentType models.CommentType, idempotencyKey *string) (*RejectionResult, error)
 	RaiseApplicationIssueWithChatAndAttachments(tx *gorm.DB, applicationID string, userID uuid.UUID, title string, description string, priority string, category *string, assignmentType models.IssueAssignmentType, assignedToUserID *uuid.UUID, assignedToGroupMemberID *uuid.UUID, attachmentDocumentIDs []uuid.UUID, createdBy string) (*models.ApplicationIssue, *models.ChatThread, *models.ChatMessage, error)
-	GetChatMessagesWithPreload(threadID string, limit, offset int) ([]FrontendChatMessage, int64, error)
-	CreateMessageWithAttachments(tx *gorm.DB, c *fiber.Ctx, threadID string, content string, messageType models.ChatMessageType, senderID uuid.UUID, files []*multipart.FileHeader, applicationID *uuid.UUID, createdBy string) (*EnhancedChatMessage, error)
-	AddParticipantToThread(tx *gorm.DB, threadID uuid.UUID, userID uuid.UUID, role models.ParticipantRole, addedBy string, canInvite bool, canRemove bool, canManage bool) error
+	GetChatMessagesWithPreload(ctx context.Context, threadID string, page pagination.Request) ([]FrontendChatMessage, int64, error)
+	CreateMessageWithAttachments(tx *gorm.DB, c *fiber.Ctx, threadID string, content string, messageType models.ChatMessageType, senderID uuid.UUID, files []*multipart.FileHeader, categoryCodes []string, applicationID *uuid.UUID, createdBy string) (*EnhancedChatMessage, error)
+	AddParticipantToThread(tx *gorm.DB, threadID uuid.UUID, userID uuid.UUID, role models.ParticipantRole, addedBy string, canInvite bool, canRemove bool, canManage bool) (*models.ChatParticipant, error)
+	GetThreadInvitations(threadID uuid.UUID) ([]models.ChatParticipant, error)
+	GetUserThreadInvitations(userID uuid.UUID) ([]models.ChatParticipant, error)
+	HasUnacceptedThreadInvitation(threadID string, userID uuid.UUID) (bool, error)
+	RespondToThreadInvitation(tx *gorm.DB, threadID uuid.UUID, userID uuid.UUID, accept bool) (*models.ChatParticipant, error)
+	ExpireThreadInvitations(now time.Time) (int64, error)
 	CanUserManageParticipants(threadID string, userID uuid.UUID, action string) (bool, error)
 	GetThreadParticipants(threadID string) ([]models.ChatParticipant, error)
 	MarkIssueAsResolved(tx *gorm.DB, issueID string, resolvedByUserID uuid.UUID, resolutionComment *string) (*models.ApplicationIssue, error)
 	ReopenIssue(tx *gorm.DB, issueID string, reopenedByUserID uuid.UUID) (*models.ApplicationIssue, error)
+	BulkResolveIssues(tx *gorm.DB, issueIDs []uuid.UUID, category *string, resolvedByUserID uuid.UUID, resolutionComment string) ([]models.ApplicationIssue, error)
 	GetIssueByID(issueID string) (*models.ApplicationIssue, error)
+	AssignIssueToUser(tx *gorm.DB, issueID uuid.UUID, userID uuid.UUID) (*models.ApplicationIssue, error)
+	SetIssuePriority(tx *gorm.DB, issueID uuid.UUID, priority string) (*models.ApplicationIssue, error)
 	DeleteMessage(tx *gorm.DB, messageID uuid.UUID, userID uuid.UUID) error
 	StarMessage(tx *gorm.DB, messageID uuid.UUID, userID uuid.UUID) (bool, error)
-	CreateReplyMessage(tx *gorm.DB, threadID string, parentMessageID uuid.UUID, content string, messageType models.ChatMessageType, senderID uuid.UUID, files []*multipart.FileHeader, applicationID *uuid.UUID, createdBy string) (*EnhancedChatMessage, error)
+	CreateReplyMessage(tx *gorm.DB, threadID string, parentMessageID uuid.UUID, content string, messageType models.ChatMessageType, senderID uuid.UUID, files []*multipart.FileHeader, categoryCodes []string, applicationID *uuid.UUID, createdBy string) (*EnhancedChatMessage, error)
 	GetMessageStars(messageID uuid.UUID) ([]models.MessageStar, error)
 	GetMessageThread(messageID uuid.UUID) ([]*EnhancedChatMessage, error)
 	IsMessageStarredByUser(messageID uuid.UUID, userID uuid.UUID) (bool, error)
@@ -78,7 +88,212 @@ type ApplicationRepository interface {
 	AddMultipleParticipantsToThread(tx *gorm.DB, threadID uuid.UUID, participants []requests.ParticipantRequest, addedBy *models.User) ([]models.ChatParticipant, error)
 	RemoveParticipantFromThread(tx *gorm.DB, threadID uuid.UUID, userID uuid.UUID, removedBy *models.User) error
 	RemoveMultipleParticipantsFromThread(tx *gorm.DB, threadID uuid.UUID, userIDs []uuid.UUID, userRemoving *models.User) (int, error)
-	ProcessDecisionRevocation(tx *gorm.DB, applicationID string, userID uuid.UUID, reason string) (*requests.RevocationResult, error)
+	ProcessDecisionRevocation(tx *gorm.DB, applicationID string, userID uuid.UUID, reason string, idempotencyKey *string) (*requests.RevocationResult, error)
+	DeclareConflictOfInterest(tx *gorm.DB, applicationID string, userID uuid.UUID, hasConflict bool, relationship *string, details *string) (*ConflictDeclarationResult, error)
+	GetConflictDeclarations(applicationID string) ([]models.ConflictOfInterestDeclaration, error)
+	GetReviewChecklist(groupID uuid.UUID, includeInactive bool) ([]models.ReviewChecklistItem, error)
+	CreateReviewChecklistItem(tx *gorm.DB, item *models.ReviewChecklistItem) (*models.ReviewChecklistItem, error)
+	UpdateReviewChecklistItem(tx *gorm.DB, groupID uuid.UUID, itemID uuid.UUID, updates map[string]interface{}) (*models.ReviewChecklistItem, error)
+	UpdateDecisionCommentPolicy(tx *gorm.DB, groupID uuid.UUID, policy models.DecisionCommentPolicy, updatedBy string) (*models.ApprovalGroup, error)
+	GetWorkflowConfiguration(includeInactive bool) ([]models.ApprovalGroup, map[uuid.UUID][]models.ReviewChecklistItem, error)
+	GetApprovalGroupAvailability(groupID *uuid.UUID) ([]models.ApprovalGroup, error)
+
+	// Application transfer methods
+	ValidateApplicationTransfer(tx *gorm.DB, applicationID uuid.UUID, toApplicantID uuid.UUID) (*models.Application, error)
+	CreateApplicationTransfer(tx *gorm.DB, transfer *models.ApplicationTransfer) (*models.ApplicationTransfer, error)
+	GetApplicationTransfers(applicationID string) ([]models.ApplicationTransfer, error)
+	ApproveApplicationTransfer(tx *gorm.DB, transferID string, reviewerID uuid.UUID, comment *string) (*TransferApprovalResult, error)
+	RejectApplicationTransfer(tx *gorm.DB, transferID string, reviewerID uuid.UUID, reason string) (*models.ApplicationTransfer, error)
+	RecordTransferQuotation(tx *gorm.DB, transferID uuid.UUID, documentID uuid.UUID) error
+
+	// Minor amendments after approval
+	ValidateApplicationAmendment(tx *gorm.DB, applicationID uuid.UUID) (*models.Application, *models.FinalApproval, error)
+	CreateApplicationAmendment(tx *gorm.DB, application *models.Application, amendment *models.ApplicationAmendment) (*models.ApplicationAmendment, error)
+	GetApplicationAmendments(applicationID uuid.UUID) ([]models.ApplicationAmendment, error)
+	RecordAmendmentPayment(tx *gorm.DB, amendmentID uuid.UUID, payment *models.Payment) (*models.ApplicationAmendment, error)
+	ApproveApplicationAmendment(tx *gorm.DB, amendmentID uuid.UUID, reviewerID uuid.UUID, comment *string) (*models.ApplicationAmendment, error)
+	RecordAmendmentPermit(tx *gorm.DB, amendmentID uuid.UUID, documentID uuid.UUID) error
+	RejectApplicationAmendment(tx *gorm.DB, amendmentID uuid.UUID, reviewerID uuid.UUID, reason string) (*models.ApplicationAmendment, error)
+
+	// Appeals against rejections, heard by an appeals group
+	ValidateApplicationAppeal(tx *gorm.DB, applicationID uuid.UUID, appealsGroupID *uuid.UUID, window time.Duration) (*models.Application, *models.FinalApproval, *models.ApprovalGroup, error)
+	CreateApplicationAppeal(tx *gorm.DB, appeal *models.ApplicationAppeal, payment *models.Payment) (*models.ApplicationAppeal, error)
+	GetApplicationAppeals(applicationID uuid.UUID) ([]models.ApplicationAppeal, error)
+	ScheduleAppealHearing(tx *gorm.DB, appealID uuid.UUID, userID uuid.UUID, hearing *models.AppealHearing) (*models.ApplicationAppeal, error)
+	DecideApplicationAppeal(tx *gorm.DB, appealID uuid.UUID, userID uuid.UUID, upheld bool, reason string) (*models.ApplicationAppeal, error)
+	RecordAppealReopening(tx *gorm.DB, appealID uuid.UUID, assignmentID uuid.UUID) error
+
+	// Permit collection appointments
+	UpsertCollectionCalendar(tx *gorm.DB, calendar *models.CollectionCalendar, updatedBy string) (*models.CollectionCalendar, error)
+	GetCollectionCalendars() ([]models.CollectionCalendar, error)
+	GetAvailableCollectionSlots(departmentID uuid.UUID, days int) ([]CollectionSlot, error)
+	BookCollectionAppointment(tx *gorm.DB, applicationID uuid.UUID, departmentID uuid.UUID, slotStart time.Time, notes *string, bookedBy string) (*models.CollectionAppointment, error)
+	CancelCollectionAppointment(tx *gorm.DB, appointmentID uuid.UUID, reason *string, cancelledBy string) (*models.CollectionAppointment, error)
+	MarkCollectionNoShow(tx *gorm.DB, appointmentID uuid.UUID, markedBy string) (*models.CollectionAppointment, error)
+	CompleteCollectionFollowUp(tx *gorm.DB, appointmentID uuid.UUID, notes *string, completedBy string) (*models.CollectionAppointment, error)
+	GetCollectionAppointmentsForDay(day time.Time, departmentID *uuid.UUID) ([]models.CollectionAppointment, error)
+	GetCollectionNoShows(departmentID *uuid.UUID) ([]models.CollectionAppointment, error)
+	MarkCollectionAppointmentConfirmed(appointmentID uuid.UUID) error
+
+	// Hard-copy file tracking
+	RegisterPhysicalFile(tx *gorm.DB, applicationID uuid.UUID, holderID uuid.UUID, location *string, createdBy string) (*models.ApplicationPhysicalFile, error)
+	GetPhysicalFileByBarcode(barcode string) (*models.ApplicationPhysicalFile, error)
+	GetApplicationPhysicalFile(applicationID uuid.UUID) (*models.ApplicationPhysicalFile, error)
+	DispatchPhysicalFile(tx *gorm.DB, barcode string, toHolderID uuid.UUID, toLocation *string, notes *string, dispatchedByID uuid.UUID) (*models.PhysicalFileMovement, error)
+	ReceivePhysicalFile(tx *gorm.DB, barcode string, receiverID uuid.UUID, location *string) (*models.ApplicationPhysicalFile, error)
+	FlagMissingPhysicalFiles(tx *gorm.DB, threshold time.Duration) (int64, error)
+	GetMissingPhysicalFiles() ([]models.ApplicationPhysicalFile, error)
+
+	// Development levy installment plans
+	CreateInstallmentPlan(tx *gorm.DB, applicationID uuid.UUID, count int, firstDueDate time.Time, intervalMonths int, allowConditionalApproval bool, createdBy string) (*models.InstallmentPlan, error)
+	GetApplicationInstallmentPlan(applicationID uuid.UUID) (*models.InstallmentPlan, error)
+	RecordInstallmentPayment(tx *gorm.DB, applicationID uuid.UUID, payment *models.Payment) (*models.InstallmentPlan, error)
+	GetOutstandingInstallmentPlans(overdueOnly bool) ([]*InstallmentPlanSummary, error)
+	EnsureInstallmentsSettled(tx *gorm.DB, applicationID uuid.UUID) error
+
+	// Duplicate payment detection
+	CheckDuplicatePayment(tx *gorm.DB, payment *models.Payment, excludePaymentID uuid.UUID) error
+	DuplicateReceiptError(payment *models.Payment, err error) error
+	RecordDuplicatePaymentAlert(duplicate *DuplicatePaymentError) (*models.DuplicatePaymentAlert, error)
+	RecordDuplicatePaymentNotifications(alertID uuid.UUID, notified int) error
+	GetFinanceOfficers() ([]models.User, error)
+	GetDuplicatePaymentAlerts(status string) ([]models.DuplicatePaymentAlert, error)
+	ResolveDuplicatePaymentAlert(tx *gorm.DB, alertID uuid.UUID, resolverID uuid.UUID, status models.DuplicatePaymentAlertStatus, notes *string) (*models.DuplicatePaymentAlert, error)
+
+	// Committee meeting packs
+	CreateCommitteePack(applicationID uuid.UUID, requestedByID uuid.UUID, createdBy string) (*models.CommitteePack, error)
+	GetCommitteePack(packID uuid.UUID) (*models.CommitteePack, error)
+	GetApplicationCommitteePacks(applicationID uuid.UUID) ([]models.CommitteePack, error)
+	GetCommitteePackContents(applicationID uuid.UUID) (*CommitteePackContents, error)
+	StartCommitteePack(packID uuid.UUID) error
+	CompleteCommitteePack(packID uuid.UUID, fileName, filePath string, fileSize int64, pageNotes *string) error
+	FailCommitteePack(packID uuid.UUID, reason string) error
+	FailInterruptedCommitteePacks() (int64, error)
+
+	// Cold storage of closed applications
+	GetArchivableApplications(closedBefore time.Time, rehydratedAfter time.Time, limit int) ([]models.Application, error)
+	GetArchivableDocuments(applicationID uuid.UUID) ([]models.Document, error)
+	RecordApplicationArchive(tx *gorm.DB, archive *models.ApplicationArchive) error
+	GetOpenApplicationArchive(applicationID uuid.UUID) (*models.ApplicationArchive, error)
+	RecordApplicationRehydration(tx *gorm.DB, archive *models.ApplicationArchive, userID uuid.UUID, reason string) error
+	GetApplicationArchives(applicationID uuid.UUID) ([]models.ApplicationArchive, error)
+
+	// Application drafts autosaved while an application is being entered
+	CreateApplicationDraft(draft *models.ApplicationDraft) error
+	GetApplicationDraft(draftID uuid.UUID, ownerID uuid.UUID) (*models.ApplicationDraft, error)
+	GetUserApplicationDrafts(ownerID uuid.UUID) ([]models.ApplicationDraft, error)
+	SaveApplicationDraft(draft *models.ApplicationDraft, expectedVersion int) error
+	DeleteApplicationDraft(draftID uuid.UUID, ownerID uuid.UUID) error
+	PromoteApplicationDraft(tx *gorm.DB, draftID uuid.UUID, applicationID uuid.UUID) error
+	ExpireApplicationDrafts(now time.Time) (int64, error)
+
+	// Advisory second opinions from outside the approval group
+	RequestSecondOpinion(tx *gorm.DB, applicationID, reviewerID, requestedByID uuid.UUID, question string, dueDate time.Time) (*models.SecondOpinionRequest, error)
+	GetApplicationSecondOpinions(applicationID uuid.UUID) ([]models.SecondOpinionRequest, error)
+	GetPendingSecondOpinions(reviewerID uuid.UUID) ([]models.SecondOpinionRequest, error)
+	SubmitSecondOpinion(tx *gorm.DB, requestID, reviewerID uuid.UUID, opinion string) (*models.SecondOpinionRequest, error)
+	WithdrawSecondOpinion(tx *gorm.DB, requestID, userID uuid.UUID) (*models.SecondOpinionRequest, error)
+
+	// Badge counts for the current user
+	GetUnreadSummary(userID uuid.UUID) (*UnreadSummary, error)
+
+	// Engineering certificate countersigning
+	RouteCertificateForCountersign(tx *gorm.DB, applicationID, documentID, engineerID uuid.UUID, notes *string, routedByID uuid.UUID) (*models.CertificateCountersignature, error)
+	GetApplicationCountersignatures(applicationID uuid.UUID) ([]models.CertificateCountersignature, error)
+	GetPendingCountersignatures(engineerID uuid.UUID) ([]models.CertificateCountersignature, error)
+	CountersignCertificate(tx *gorm.DB, countersignatureID, engineerID uuid.UUID, comment *string) (*models.CertificateCountersignature, error)
+	AttachCountersignatureDocument(tx *gorm.DB, countersignatureID, documentID uuid.UUID) error
+	DeclineCountersignature(tx *gorm.DB, countersignatureID, engineerID uuid.UUID, reason string) (*models.CertificateCountersignature, error)
+
+	// Stand rates clearance
+	RecordRatesClearance(tx *gorm.DB, applicationID uuid.UUID, clearance *models.RatesClearance, createdBy string) (*models.RatesClearance, error)
+	GetApplicationRatesClearances(applicationID uuid.UUID) ([]models.RatesClearance, error)
+	OverrideRatesClearance(tx *gorm.DB, applicationID uuid.UUID, reason string, overriddenByID uuid.UUID) (*models.RatesClearance, error)
+
+	// Deeds registry ownership checks
+	RecordDeedsVerification(tx *gorm.DB, applicationID uuid.UUID, verification *models.DeedsVerification, createdBy string) (*models.DeedsVerification, error)
+	GetApplicationDeedsVerifications(applicationID uuid.UUID) ([]models.DeedsVerification, error)
+
+	// Council boundary layers and stand geo-validation
+	CreateBoundaryLayer(tx *gorm.DB, layer *models.BoundaryLayer) error
+	GetBoundaryLayers() ([]models.BoundaryLayer, error)
+	GetBoundaryLayer(layerID uuid.UUID) (*models.BoundaryLayer, error)
+	SetBoundaryLayerActive(tx *gorm.DB, layerID uuid.UUID, active bool, updatedBy string) (*models.BoundaryLayer, error)
+	GetActiveBoundaryLayers() (*models.BoundaryLayer, *models.BoundaryLayer, error)
+	RecordBoundaryCheck(tx *gorm.DB, applicationID uuid.UUID, check *models.BoundaryCheck, createdBy string) (*models.BoundaryCheck, error)
+	GetApplicationBoundaryChecks(applicationID uuid.UUID) ([]models.BoundaryCheck, error)
+	GetBoundaryReviewQueue() ([]models.Application, error)
+	ClearBoundaryReview(tx *gorm.DB, applicationID uuid.UUID, reviewerID uuid.UUID, note string) (*models.BoundaryCheck, error)
+
+	// Scheduled chat messages
+	CreateScheduledMessage(tx *gorm.DB, threadID uuid.UUID, senderID uuid.UUID, content string, scheduledFor time.Time, createdBy string) (*models.ScheduledChatMessage, error)
+	GetScheduledMessages(senderID uuid.UUID, threadID *uuid.UUID) ([]models.ScheduledChatMessage, error)
+	CancelScheduledMessage(tx *gorm.DB, scheduledID uuid.UUID, userID uuid.UUID) (*models.ScheduledChatMessage, error)
+	ClaimDueScheduledMessage(tx *gorm.DB, now time.Time) (*models.ScheduledChatMessage, error)
+	MarkScheduledMessageSent(tx *gorm.DB, scheduled *models.ScheduledChatMessage, messageID uuid.UUID) error
+	MarkScheduledMessageFailed(tx *gorm.DB, scheduled *models.ScheduledChatMessage, reason string) error
+
+	// Joint owners
+	SetPrimaryCoApplicant(tx *gorm.DB, applicationID, applicantID uuid.UUID, createdBy string) error
+	AddCoApplicant(tx *gorm.DB, applicationID, applicantID, idDocumentID uuid.UUID, createdBy string) (*models.ApplicationCoApplicant, error)
+	RemoveCoApplicant(tx *gorm.DB, applicationID, applicantID uuid.UUID) error
+	GetApplicationCoApplicants(applicationID uuid.UUID) ([]models.ApplicationCoApplicant, error)
+
+	// Issues raised from chat messages
+	GetChatMessageForIssue(tx *gorm.DB, messageID, userID uuid.UUID) (*models.ChatMessage, error)
+	RaiseIssueFromChatMessage(tx *gorm.DB, message *models.ChatMessage, userID uuid.UUID, request requests.RaiseIssueFromMessageRequest, createdBy string) (*models.ApplicationIssue, *models.ChatThread, *models.ChatMessage, error)
+
+	// Application risk scoring
+	GetActiveRiskScoringProfile(tx *gorm.DB) (*models.RiskScoringProfile, error)
+	SaveRiskScoringProfile(tx *gorm.DB, profile *models.RiskScoringProfile) error
+	SetDevelopmentCategoryRiskPoints(categoryID uuid.UUID, points int) (*models.DevelopmentCategory, error)
+	CountApplicantRejections(tx *gorm.DB, applicantID uuid.UUID, excludeApplicationID uuid.UUID) (int64, error)
+	RecordRiskAssessment(tx *gorm.DB, applicationID uuid.UUID, assessment *models.ApplicationRiskAssessment, createdBy string) (*models.ApplicationRiskAssessment, error)
+	GetApplicationRiskAssessments(applicationID uuid.UUID) ([]models.ApplicationRiskAssessment, error)
+	GetApproverQueue(userID uuid.UUID, level *models.RiskLevel) ([]models.Application, error)
+
+	// Permit lifecycle after issuance
+	IssuePermit(tx *gorm.DB, application *models.Application, issuedBy string) (*models.Permit, error)
+	GetPermit(permitID uuid.UUID) (*models.Permit, error)
+	GetApplicationPermit(applicationID uuid.UUID) (*models.Permit, error)
+	ChangePermitStatus(tx *gorm.DB, permitID uuid.UUID, action models.PermitAction, reason string, documentIDs []uuid.UUID, changedByID uuid.UUID) (*models.Permit, *models.PermitStatusChange, error)
+	RecordPermitNotifications(statusChangeID uuid.UUID, applicantNotified bool, inspectorsNotified int) error
+	GetPermitInspectors(applicationID uuid.UUID) ([]models.User, error)
+	VerifyPermit(permitNumber string) (*models.Permit, error)
+
+	// Approver queues and saved filters
+	GetPendingDecisionsQueue(userID uuid.UUID, page pagination.Request, filters map[string]string) ([]models.Application, int64, error)
+	GetAssignedIssuesQueue(userID uuid.UUID, page pagination.Request, filters map[string]string) ([]models.ApplicationIssue, int64, error)
+	GetUnreadMentionsQueue(userID uuid.UUID, page pagination.Request, filters map[string]string) ([]MentionedThread, int64, error)
+	GetSavedQueueFilters(userID uuid.UUID, queue *models.ApproverQueue) ([]models.SavedQueueFilter, error)
+	GetSavedQueueFilter(userID uuid.UUID, filterID uuid.UUID) (*models.SavedQueueFilter, error)
+	GetDefaultQueueFilter(userID uuid.UUID, queue models.ApproverQueue) (*models.SavedQueueFilter, error)
+	SaveQueueFilter(tx *gorm.DB, filter *models.SavedQueueFilter) error
+	DeleteQueueFilter(userID uuid.UUID, filterID uuid.UUID) error
+
+	// Decision reminders and escalation to department heads
+	GetPendingDecisionsForReminder(pendingBefore time.Time) ([]models.MemberApprovalDecision, error)
+	GetDecisionReminders(decisionIDs []uuid.UUID) (map[uuid.UUID]models.DecisionReminder, error)
+	RecordDecisionReminder(decision *models.MemberApprovalDecision, remindedAt time.Time) error
+	GetDepartmentHead(userID uuid.UUID) (*models.User, error)
+	OpenDecisionEscalation(tx *gorm.DB, decision *models.MemberApprovalDecision, head *models.User, summary string) (*models.ApplicationIssue, *models.ChatThread, *models.ChatMessage, error)
+	MarkDecisionEscalated(tx *gorm.DB, decisionID uuid.UUID, headID uuid.UUID, issueID uuid.UUID) error
+
+	// Read-only threads once an application is finally decided
+	FreezeApplicationThreads(tx *gorm.DB, application *models.Application, outcome models.ApplicationStatus, senderID uuid.UUID, now time.Time) ([]models.ChatMessage, error)
+	UnfreezeThread(tx *gorm.DB, threadID uuid.UUID, user *models.User, reason string) (*models.ChatThread, *models.ChatMessage, error)
+
+	// SLA clocks paused while staff wait on the applicant
+	SetIssueAwaitingApplicant(tx *gorm.DB, issueID uuid.UUID, awaiting bool, userID uuid.UUID) (*models.ApplicationIssue, *models.ApplicationSLAPause, error)
+	GetSLAPauses(applicationIDs []uuid.UUID) (map[uuid.UUID][]models.ApplicationSLAPause, error)
+	GetApplicationSLAPauses(applicationID uuid.UUID) ([]models.ApplicationSLAPause, error)
+
+	// Pre-screening triage before approval group assignment
+	QueueForPreScreening(tx *gorm.DB, applicationID uuid.UUID, groupID uuid.UUID) (*models.ApplicationPreScreening, error)
+	GetPreScreeningQueue(page pagination.Request) ([]models.ApplicationPreScreening, int64, error)
+	GetApplicationPreScreenings(applicationID uuid.UUID) ([]models.ApplicationPreScreening, error)
+	RecordPreScreening(tx *gorm.DB, applicationID uuid.UUID, result PreScreeningResult, screenedByID uuid.UUID) (*models.ApplicationPreScreening, error)
+	RequeuePreScreening(tx *gorm.DB, applicationID uuid.UUID) (*models.ApplicationPreScreening, error)
 }
 
 type applicationRepository struct {
@@ -114,6 +329,13 @@ func (r *applicationRepository) CreateApprovalGroup(tx *gorm.DB, group *models.A
 	if err := tx.Create(group).Error; err != nil {
 		return nil, err
 	}
+	// GORM skips zero values for columns with a default, so an explicit false would be
+	// stored as the column default of true
+	if !group.RequiresAllApprovals {
+		if err := tx.Model(group).Update("requires_all_approvals", false).Error; err != nil {
+			return nil, err
+		}
+	}
 	return group, nil
 }
 
@@ -181,6 +403,17 @@ func (r *applicationRepository) GetApplicationById(applicationID string) (*model
 		Preload("ApplicationDocuments.Document").
 		Preload("Payment").
 		Preload("ApprovalGroup.Members.User.Department").
+		Preload("PhysicalFile.CurrentHolder").
+		Preload("PhysicalFile.CurrentDepartment").
+		Preload("Countersignatures.Engineer").
+		Preload("RatesClearances", func(db *gorm.DB) *gorm.DB {
+			return db.Order(ratesClearanceOrder)
+		}).
+		Preload("RatesClearances.OverriddenBy").
+		Preload("BoundaryChecks", func(db *gorm.DB) *gorm.DB {
+			return db.Order(boundaryCheckOrder)
+		}).
+		Preload("CoApplicants.Applicant").
 		Where("id = ?", applicationID).
 		First(&application).Error; err != nil {
 		return nil, err
@@ -288,12 +521,12 @@ func (r *applicationRepository) GetFilteredApprovalGroups(limit, offset int, fil
 }
 
 // GetFilteredApplications fetches applications with filtering and pagination
-func (r *applicationRepository) GetFilteredApplications(limit, offset int, filters map[string]string) ([]models.Application, int64, error) {
+func (r *applicationRepository) GetFilteredApplications(ctx context.Context, page pagination.Request, filters map[string]string) ([]models.Application, int64, error) {
 	var applications []models.Application
 	var total int64
 
 	// Start building the query with preloads
-	query := r.db.Model(&models.Application{}).
+	query := r.db.WithContext(ctx).Model(&models.Application{}).
 		Preload("Applicant").
 		Preload("Tariff").
 		Preload("Tariff.DevelopmentCategory").
@@ -352,16 +585,31 @@ func (r *applicationRepository) GetFilteredApplications(limit, offset int, filte
 		}
 	}
 
+	if riskLevel, exists := filters["risk_level"]; exists && riskLevel != "" {
+		query = query.Where("risk_level = ?", riskLevel)
+	}
+
+	// Archived applications are in cold storage and only listed when asked for
+	switch filters["archived"] {
+	case "true":
+		query = query.Where("archived_at IS NOT NULL")
+	case "include":
+	default:
+		query = query.Where("archived_at IS NULL")
+	}
+
 	// Count total number of records matching the filters
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	// Fetch paginated applications, ordered by submission date (descending) to show latest first
-	if err := query.
-		Order("submission_date DESC, created_at DESC").
-		Limit(limit).
-		Offset(offset).
+	// Fetch paginated applications, ordered by submission date (descending) to show latest first,
+	// or highest risk first when reviewers prioritise by risk
+	order := "submission_date DESC, created_at DESC"
+	if filters["sort"] == "risk" {
+		order = "risk_score DESC NULLS LAST, submission_date ASC"
+	}
+	if err := page.Window(query, "applications", order).
 		Find(&applications).Error; err != nil {
 		return nil, 0, err
 	}
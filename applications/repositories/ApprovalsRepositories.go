@@ -1,239 +1,212 @@
 package repositories
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 	"town-planning-backend/config"
 	"town-planning-backend/db/models"
+	planningscheme_repositories "town-planning-backend/planningschemes/repositories"
+	"town-planning-backend/settings"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
-// ProcessApplicationApproval handles the approval of an application by a group member
+// Member decisions are worked out from a read taken outside any transaction, then written in a
+// short transaction that first claims the assignment's DecisionVersion. When another decision
+// on the same assignment committed in between, the claim fails and the decision is worked out
+// again from fresh state, up to decisionCommitAttempts times.
+const decisionCommitAttempts = 3
+
+// ErrDecisionConflict is returned when the assignment kept changing under every attempt
+var ErrDecisionConflict = errors.New("application was updated by another decision, please try again")
+
+// Returned when a decision lacks the explanation its approval group's comment policy requires
+var (
+	ErrApprovalCommentRequired = errors.New("this approval group requires a comment to approve")
+	ErrRejectionReasonRequired = errors.New("this approval group requires a reason to reject")
+)
+
+// decisionSnapshot is the state a member's decision is worked out from
+type decisionSnapshot struct {
+	application         models.Application
+	assignment          models.ApplicationGroupAssignment
+	member              models.ApprovalGroupMember
+	existingDecision    *models.MemberApprovalDecision
+	regularMembers      []models.ApprovalGroupMember
+	decisionStatuses    map[uuid.UUID]models.MemberDecisionStatus // by member ID
+	finalApprover       *models.ApprovalGroupMember
+	activeFinalApproval *models.FinalApproval
+	checklist           []models.ReviewChecklistItem // the group's active review checklist
+}
+
+// decisionTally counts the regular members' decisions with the deciding member's own applied
+type decisionTally struct {
+	regularMembers int64
+	decided        int64
+	rejected       int64
+	weights        DecisionWeightTally
+}
+
+// decisionPlan is every write a member's decision needs
+type decisionPlan struct {
+	decision          models.MemberApprovalDecision
+	comment           *models.Comment
+	applicationStatus models.ApplicationStatus // empty leaves the status alone
+	assignmentUpdates map[string]interface{}
+	finalApproval     *models.FinalApproval          // saved when the decision settles the application
+	checklist         []models.DecisionChecklistItem // replaces the items the member had ticked
+	closingMessages   []models.ChatMessage           // posted when the decision froze the application's threads
+}
+
+// ProcessApplicationApproval handles the approval of an application by a group member. The
+// idempotency key the request was sent with, if any, is kept on the decision for the audit log.
 func (r *applicationRepository) ProcessApplicationApproval(
-	tx *gorm.DB,
+	ctx context.Context,
 	applicationID string,
 	userID uuid.UUID,
 	comment *string,
 	commentType models.CommentType,
+	checkedItemIDs []uuid.UUID,
+	idempotencyKey *string,
 ) (*ApprovalResult, error) {
-	// Fetch application with group assignment and members
-	var application models.Application
-	err := tx.
-		Preload("ApprovalGroup.Members", "is_active = ?", true).
-		Preload("GroupAssignments", "is_active = ?", true).
-		Preload("GroupAssignments.Decisions").
-		Where("id = ?", applicationID).
-		First(&application).Error
+	var result *ApprovalResult
+	err := r.retryDecision(applicationID, userID, func() error {
+		var err error
+		result, err = r.processApplicationApproval(ctx, applicationID, userID, comment, commentType, checkedItemIDs, idempotencyKey)
+		return err
+	})
+	return result, err
+}
 
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("application not found")
+// ProcessApplicationRejection handles the rejection of an application by a group member. The
+// idempotency key the request was sent with, if any, is kept on the decision for the audit log.
+func (r *applicationRepository) ProcessApplicationRejection(
+	ctx context.Context,
+	applicationID string,
+	userID uuid.UUID,
+	reason string,
+	comment *string,
+	commentType models.CommentType,
+	idempotencyKey *string,
+) (*RejectionResult, error) {
+	var result *RejectionResult
+	err := r.retryDecision(applicationID, userID, func() error {
+		var err error
+		result, err = r.processApplicationRejection(ctx, applicationID, userID, reason, comment, commentType, idempotencyKey)
+		return err
+	})
+	return result, err
+}
+
+// retryDecision runs a decision again when a concurrent decision won the commit
+func (r *applicationRepository) retryDecision(applicationID string, userID uuid.UUID, decide func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := decide()
+		if !errors.Is(err, ErrDecisionConflict) || attempt == decisionCommitAttempts {
+			return err
 		}
-		return nil, err
+		config.Logger.Info("Assignment changed while recording decision, retrying",
+			zap.String("applicationID", applicationID),
+			zap.String("userID", userID.String()),
+			zap.Int("attempt", attempt))
 	}
+}
 
-	// Check if user is a member of the approval group
-	var groupMember models.ApprovalGroupMember
-	err = tx.
-		Where("approval_group_id = ? AND user_id = ? AND is_active = ?",
-			application.ApprovalGroup.ID, userID, true).
-		First(&groupMember).Error
-
+func (r *applicationRepository) processApplicationApproval(
+	ctx context.Context,
+	applicationID string,
+	userID uuid.UUID,
+	comment *string,
+	commentType models.CommentType,
+	checkedItemIDs []uuid.UUID,
+	idempotencyKey *string,
+) (*ApprovalResult, error) {
+	snapshot, err := r.loadDecisionSnapshot(ctx, applicationID, userID, "approve")
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not authorized to approve this application")
-		}
 		return nil, err
 	}
-
-	// Check if user can approve
-	if !groupMember.CanApprove {
-		return nil, errors.New("user does not have permission to approve applications")
-	}
-
-	// Check if there's an active group assignment
-	if len(application.GroupAssignments) == 0 {
-		return nil, errors.New("no active group assignment found for this application")
+	if snapshot.application.ApprovalGroup.CommentPolicy.RequiresApprovalComment() &&
+		(comment == nil || strings.TrimSpace(*comment) == "") {
+		return nil, ErrApprovalCommentRequired
 	}
 
-	assignment := application.GroupAssignments[0]
-
-	// Check if user already made a decision
-	var existingDecision models.MemberApprovalDecision
-	err = tx.
-		Where("assignment_id = ? AND member_id = ?", assignment.ID, groupMember.ID).
-		First(&existingDecision).Error
-
 	now := time.Now()
-	var decision models.MemberApprovalDecision
-
-	if err == nil {
-		// Update existing decision
-		decision = existingDecision
-		decision.Status = models.DecisionApproved
-		decision.DecidedAt = &now
-		decision.UpdatedAt = now
-	} else if errors.Is(err, gorm.ErrRecordNotFound) {
-		// Create new decision
-		decision = models.MemberApprovalDecision{
-			ID:                      uuid.New(),
-			AssignmentID:            assignment.ID,
-			MemberID:                groupMember.ID,
-			UserID:                  userID,
-			Status:                  models.DecisionApproved,
-			DecidedAt:               &now,
-			AssignedAs:              groupMember.Role,
-			IsFinalApproverDecision: groupMember.IsFinalApprover,
-			WasAvailable:            groupMember.AvailabilityStatus == models.AvailabilityAvailable,
-		}
-	} else {
-		return nil, err
+	member := snapshot.member
+	assignment := snapshot.assignment
+	plan := &decisionPlan{
+		decision:          snapshot.memberDecision(userID, models.DecisionApproved, now),
+		assignmentUpdates: map[string]interface{}{},
 	}
+	plan.decision.IdempotencyKey = idempotencyKey
 
-	// Save decision
-	if err := tx.Save(&decision).Error; err != nil {
+	// The group's mandatory review checklist must be ticked for the approval to be accepted
+	if plan.checklist, err = tickChecklist(snapshot.checklist, checkedItemIDs, plan.decision.ID, now); err != nil {
 		return nil, err
 	}
-
-	// Add comment if provided
 	if comment != nil && *comment != "" {
-		approvalComment := models.Comment{
+		plan.comment = &models.Comment{
 			ID:            uuid.New(),
-			ApplicationID: application.ID,
-			DecisionID:    &decision.ID,
+			ApplicationID: snapshot.application.ID,
+			DecisionID:    &plan.decision.ID,
 			CommentType:   commentType,
 			Content:       *comment,
 			UserID:        userID,
-			CreatedBy:     fmt.Sprintf("%s %s", groupMember.User.FirstName, groupMember.User.LastName),
-		}
-		if err := tx.Create(&approvalComment).Error; err != nil {
-			return nil, err
+			CreatedBy:     fmt.Sprintf("%s %s", member.User.FirstName, member.User.LastName),
 		}
 	}
 
-	// Update assignment statistics
-	if err := r.updateAssignmentStatistics(tx, assignment.ID); err != nil {
-		return nil, err
+	result := &ApprovalResult{
+		ApplicationStatus:     snapshot.application.Status,
+		IsFinalApprover:       member.IsFinalApprover,
+		ReadyForFinalApproval: assignment.ReadyForFinalApproval,
+		UnresolvedIssues:      assignment.IssuesRaised - assignment.IssuesResolved,
 	}
 
 	// ========================================
 	// AUTO-REJECTION CHECK FOR APPROVALS
 	// ========================================
 
-	if !groupMember.IsFinalApprover {
-		// Get all regular members (excluding final approver)
-		var regularMembers []models.ApprovalGroupMember
-		if err := tx.
-			Where("approval_group_id = ? AND is_active = ? AND is_final_approver = ?",
-				assignment.ApprovalGroupID, true, false).
-			Find(&regularMembers).Error; err != nil {
-			return nil, err
-		}
-
-		// Count how many regular members have made decisions (not pending)
-		var decidedCount int64
-		var rejectedCount int64
-
-		if err := tx.Model(&models.MemberApprovalDecision{}).
-			Joins("JOIN approval_group_members ON approval_group_members.id = member_approval_decisions.member_id").
-			Where("member_approval_decisions.assignment_id = ? AND member_approval_decisions.deleted_at IS NULL", assignment.ID).
-			Where("approval_group_members.is_final_approver = ? AND approval_group_members.is_active = ?", false, true).
-			Where("member_approval_decisions.status != ?", models.DecisionPending).
-			Count(&decidedCount).Error; err != nil {
-			return nil, err
-		}
-
-		if err := tx.Model(&models.MemberApprovalDecision{}).
-			Joins("JOIN approval_group_members ON approval_group_members.id = member_approval_decisions.member_id").
-			Where("member_approval_decisions.assignment_id = ? AND member_approval_decisions.deleted_at IS NULL", assignment.ID).
-			Where("approval_group_members.is_final_approver = ? AND approval_group_members.is_active = ?", false, true).
-			Where("member_approval_decisions.status = ?", models.DecisionRejected).
-			Count(&rejectedCount).Error; err != nil {
-			return nil, err
-		}
-
-		regularMemberCount := int64(len(regularMembers))
-		allRegularMembersDecided := decidedCount >= regularMemberCount
-		hasAnyRejection := rejectedCount > 0
+	if !member.IsFinalApprover {
+		tally := snapshot.tallyWith(models.DecisionApproved)
+		allRegularMembersDecided := tally.decided >= tally.regularMembers
+		hasAnyRejection := tally.rejected > 0
 
 		config.Logger.Info("Auto-rejection check on approval",
 			zap.String("applicationID", applicationID),
-			zap.Int64("regularMemberCount", regularMemberCount),
-			zap.Int64("decidedCount", decidedCount),
-			zap.Int64("rejectedCount", rejectedCount),
+			zap.Int64("regularMemberCount", tally.regularMembers),
+			zap.Int64("decidedCount", tally.decided),
+			zap.Int64("rejectedCount", tally.rejected),
 			zap.Bool("allDecided", allRegularMembersDecided),
 			zap.Bool("hasRejection", hasAnyRejection))
 
-		// If all regular members decided AND there's any rejection -> AUTO-REJECT
-		if allRegularMembersDecided && hasAnyRejection {
-			application.Status = models.RejectedApplication
-			assignment.CompletedAt = &now
-			assignment.FinalDecisionAt = &now
-			assignment.ReadyForFinalApproval = false
-
-			// Get the actual final approver from the group
-			var finalApproverMember models.ApprovalGroupMember
-			err = tx.
-				Where("approval_group_id = ? AND is_final_approver = ? AND is_active = ?",
-					assignment.ApprovalGroupID, true, true).
-				First(&finalApproverMember).Error
-
-			if err != nil {
-				return nil, fmt.Errorf("failed to find final approver for auto-rejection: %w", err)
-			}
-
-			// Create final approval record for the auto-rejection
-			rejectionReason := "Application auto-rejected due to member rejections"
-			finalApproval := models.FinalApproval{
-				ID:                    uuid.New(),
-				ApplicationID:         application.ID,
-				ApproverID:            finalApproverMember.UserID,
-				Decision:              models.RejectedApplication,
-				DecisionAt:            now,
-				Comment:               &rejectionReason,
-				OverrodeGroupDecision: false,
-				IsSystemAutoDecision:  true,
-			}
-			if err := tx.Create(&finalApproval).Error; err != nil {
-				return nil, err
-			}
-			assignment.FinalDecisionID = &finalApproval.ID
+		// If all regular members decided AND there's any rejection -> AUTO-REJECT, unless the
+		// council has turned auto-rejection off and leaves the decision to the final approver
+		if allRegularMembersDecided && hasAnyRejection && !settings.Bool(settings.AutoRejectOnMemberRejection) {
+			snapshot.planFinalApproverHandover(plan, now)
+			result.ReadyForFinalApproval = true
 
-			if err := tx.Save(&application).Error; err != nil {
-				return nil, err
-			}
-			if err := tx.Save(&assignment).Error; err != nil {
+			config.Logger.Info("All regular members decided with rejections, auto-rejection is off, handing to final approver",
+				zap.String("applicationID", applicationID))
+		} else if allRegularMembersDecided && hasAnyRejection {
+			if err := snapshot.planAutoRejection(plan, "Application auto-rejected due to member rejections", now); err != nil {
 				return nil, err
 			}
+			result.ApplicationStatus = models.RejectedApplication
+			result.ReadyForFinalApproval = false
 
-			config.Logger.Info("Auto-rejected application after approval (other members rejected)",
+			config.Logger.Info("Auto-rejecting application after approval (other members rejected)",
 				zap.String("applicationID", applicationID),
-				zap.String("approvingMember", groupMember.User.FirstName+" "+groupMember.User.LastName))
-
-			// Prepare result
-			result := &ApprovalResult{
-				ApplicationStatus:     application.Status,
-				IsFinalApprover:       false,
-				ReadyForFinalApproval: false,
-				ApprovedCount:         assignment.ApprovedCount,
-				TotalMembers:          assignment.TotalMembers,
-				UnresolvedIssues:      assignment.IssuesRaised - assignment.IssuesResolved,
-			}
-
-			return result, nil
-		}
-
-		// If all regular members decided AND no rejections -> Ready for final approval
-		if allRegularMembersDecided && !hasAnyRejection {
-			assignment.ReadyForFinalApproval = true
-			assignment.FinalApproverAssignedAt = &now
-			if err := tx.Save(&assignment).Error; err != nil {
-				return nil, err
-			}
+				zap.String("approvingMember", member.User.FirstName+" "+member.User.LastName))
+		} else if snapshot.application.ApprovalGroup.QuorumReached(tally.weights.ApprovedWeight, tally.weights.RejectedWeight, tally.weights.PendingWeight) && !hasAnyRejection {
+			// The weighted approvals meet the group's rule AND no rejections -> Ready for final approval
+			plan.assignmentUpdates["ready_for_final_approval"] = true
+			plan.assignmentUpdates["final_approver_assigned_at"] = now
+			result.ReadyForFinalApproval = true
 
 			config.Logger.Info("All regular members approved, ready for final approval",
 				zap.String("applicationID", applicationID))
@@ -241,78 +214,48 @@ func (r *applicationRepository) ProcessApplicationApproval(
 	}
 
 	// Update application status if final approver
-	if groupMember.IsFinalApprover {
-		isReadyForFinalApproval := r.isAssignmentReadyForFinalApproval(tx, &assignment)
-
-		if isReadyForFinalApproval {
-			application.Status = models.ApprovedApplication
-			assignment.CompletedAt = &now
-			assignment.FinalDecisionAt = &now
-
-			// Get application's final approver
-			var finalApproverMember models.ApprovalGroupMember
-			err = tx.
-				Where("approval_group_id = ? AND is_final_approver = ? AND is_active = ?",
-					assignment.ApprovalGroupID, true, true).
-				First(&finalApproverMember).Error
+	if member.IsFinalApprover {
+		ready := snapshot.readyForFinalApproval()
+		result.ReadyForFinalApproval = ready
 
-			if err != nil {
-				return nil, fmt.Errorf("failed to find final approver: %w", err)
+		if ready {
+			if err := r.checkInstallmentPolicy(r.db.WithContext(ctx), snapshot.application.ID); err != nil {
+				return nil, err
+			}
+			if snapshot.finalApprover == nil {
+				return nil, errors.New("failed to find final approver: record not found")
 			}
 
-			// Check if there's an active final approval (non-deleted)
-			var existingActiveFinalApproval models.FinalApproval
-			err = tx.Where("application_id = ? AND deleted_at IS NULL", application.ID).
-				First(&existingActiveFinalApproval).Error
-
-			if err == nil {
+			if existing := snapshot.activeFinalApproval; existing != nil {
 				// Active final approval exists - this shouldn't happen after revocation
 				config.Logger.Warn("Active final approval already exists, but proceeding",
 					zap.String("applicationID", applicationID),
-					zap.String("finalApprovalID", existingActiveFinalApproval.ID.String()))
-
-				// Option 1: Update existing one
-				existingActiveFinalApproval.ApproverID = finalApproverMember.UserID
-				existingActiveFinalApproval.Decision = models.ApprovedApplication
-				existingActiveFinalApproval.DecisionAt = now
-				existingActiveFinalApproval.Comment = comment
-				existingActiveFinalApproval.UpdatedAt = now
-
-				if err := tx.Save(&existingActiveFinalApproval).Error; err != nil {
-					return nil, fmt.Errorf("failed to update final approval: %w", err)
-				}
-				assignment.FinalDecisionID = &existingActiveFinalApproval.ID
-
-			} else if errors.Is(err, gorm.ErrRecordNotFound) {
+					zap.String("finalApprovalID", existing.ID.String()))
+
+				finalApproval := *existing
+				finalApproval.ApproverID = snapshot.finalApprover.UserID
+				finalApproval.Decision = models.ApprovedApplication
+				finalApproval.DecisionAt = now
+				finalApproval.Comment = comment
+				finalApproval.UpdatedAt = now
+				plan.finalApproval = &finalApproval
+			} else {
 				// No active final approval exists - create new one (NORMAL CASE after revocation)
-				finalApproval := models.FinalApproval{
+				plan.finalApproval = &models.FinalApproval{
 					ID:            uuid.New(),
-					ApplicationID: application.ID,
-					ApproverID:    finalApproverMember.UserID,
+					ApplicationID: snapshot.application.ID,
+					ApproverID:    snapshot.finalApprover.UserID,
 					Decision:      models.ApprovedApplication,
 					DecisionAt:    now,
 					Comment:       comment,
 				}
-				if err := tx.Create(&finalApproval).Error; err != nil {
-					return nil, fmt.Errorf("failed to create final approval: %w", err)
-				}
-
-				assignment.FinalDecisionID = &finalApproval.ID
-
-				config.Logger.Info("Created new final approval",
-					zap.String("applicationID", applicationID),
-					zap.String("finalApprovalID", finalApproval.ID.String()),
-					zap.String("approverID", userID.String()))
-			} else {
-				return nil, err
 			}
 
-			if err := tx.Save(&application).Error; err != nil {
-				return nil, err
-			}
-			if err := tx.Save(&assignment).Error; err != nil {
-				return nil, err
-			}
+			plan.applicationStatus = models.ApprovedApplication
+			plan.assignmentUpdates["completed_at"] = now
+			plan.assignmentUpdates["final_decision_at"] = now
+			plan.assignmentUpdates["final_decision_id"] = plan.finalApproval.ID
+			result.ApplicationStatus = models.ApprovedApplication
 		} else {
 			config.Logger.Warn("Final approver attempted to approve application not ready for final approval",
 				zap.String("applicationID", applicationID),
@@ -320,307 +263,517 @@ func (r *applicationRepository) ProcessApplicationApproval(
 		}
 	}
 
-	// Prepare result
-	result := &ApprovalResult{
-		ApplicationStatus:     application.Status,
-		IsFinalApprover:       groupMember.IsFinalApprover,
-		ReadyForFinalApproval: assignment.ReadyForFinalApproval,
-		ApprovedCount:         assignment.ApprovedCount,
-		TotalMembers:          assignment.TotalMembers,
-		UnresolvedIssues:      assignment.IssuesRaised - assignment.IssuesResolved,
+	committed, err := r.commitDecisionPlan(ctx, snapshot, plan)
+	if err != nil {
+		return nil, err
 	}
+	result.ApprovedCount = committed.ApprovedCount
+	result.TotalMembers = committed.TotalMembers
+	result.ClosingMessages = plan.closingMessages
 
-	// If final approver just approved, update the ready status
-	if groupMember.IsFinalApprover {
-		result.ReadyForFinalApproval = r.isAssignmentReadyForFinalApproval(tx, &assignment)
+	if plan.finalApproval != nil {
+		config.Logger.Info("Recorded final approval",
+			zap.String("applicationID", applicationID),
+			zap.String("finalApprovalID", plan.finalApproval.ID.String()),
+			zap.String("status", string(result.ApplicationStatus)))
 	}
 
 	return result, nil
 }
 
-// ProcessApplicationRejection handles the rejection of an application by a group member
-func (r *applicationRepository) ProcessApplicationRejection(
-	tx *gorm.DB,
+func (r *applicationRepository) processApplicationRejection(
+	ctx context.Context,
 	applicationID string,
 	userID uuid.UUID,
 	reason string,
 	comment *string,
 	commentType models.CommentType,
+	idempotencyKey *string,
 ) (*RejectionResult, error) {
+	snapshot, err := r.loadDecisionSnapshot(ctx, applicationID, userID, "reject")
+	if err != nil {
+		return nil, err
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" && snapshot.application.ApprovalGroup.CommentPolicy.RequiresRejectionReason() {
+		return nil, ErrRejectionReasonRequired
+	}
+
+	now := time.Now()
+	member := snapshot.member
+
+	// Add rejection comment
+	var lines []string
+	if reason != "" {
+		lines = append(lines, fmt.Sprintf("REJECTION REASON: %s", reason))
+	}
+	if comment != nil && *comment != "" {
+		lines = append(lines, fmt.Sprintf("ADDITIONAL COMMENTS: %s", *comment))
+	}
+	rejectionContent := strings.Join(lines, "\n")
+
+	plan := &decisionPlan{
+		decision:          snapshot.memberDecision(userID, models.DecisionRejected, now),
+		assignmentUpdates: map[string]interface{}{},
+	}
+	plan.decision.IdempotencyKey = idempotencyKey
+	if rejectionContent != "" {
+		plan.comment = &models.Comment{
+			ID:            uuid.New(),
+			ApplicationID: snapshot.application.ID,
+			DecisionID:    &plan.decision.ID,
+			CommentType:   commentType,
+			Content:       rejectionContent,
+			UserID:        userID,
+			CreatedBy:     fmt.Sprintf("%s %s", member.User.FirstName, member.User.LastName),
+		}
+	}
+
+	// ========================================
+	// AUTO-REJECTION LOGIC FOR REJECTIONS
+	// ========================================
+
+	tally := snapshot.tallyWith(models.DecisionRejected)
+	allRegularMembersDecided := tally.decided >= tally.regularMembers
+	hasAnyRejection := tally.rejected > 0
+
+	config.Logger.Info("Auto-rejection check on rejection",
+		zap.String("applicationID", applicationID),
+		zap.Int64("regularMemberCount", tally.regularMembers),
+		zap.Int64("decidedCount", tally.decided),
+		zap.Int64("rejectedCount", tally.rejected),
+		zap.Bool("allDecided", allRegularMembersDecided),
+		zap.Bool("hasRejection", hasAnyRejection),
+		zap.Bool("isFinalApprover", member.IsFinalApprover))
+
+	// PHASE 1: Regular member rejects, but not all members have decided yet
+	if !member.IsFinalApprover && !allRegularMembersDecided {
+		// Keep application under review - other members still need to review
+		plan.applicationStatus = models.UnderReviewApplication
+		plan.assignmentUpdates["ready_for_final_approval"] = false
+
+		config.Logger.Info("Regular member rejected, waiting for other members",
+			zap.String("applicationID", applicationID),
+			zap.String("rejectingMember", member.User.FirstName+" "+member.User.LastName))
+
+		// PHASE 2: All regular members decided, check if we should auto-reject
+	} else if !member.IsFinalApprover && allRegularMembersDecided && hasAnyRejection && !settings.Bool(settings.AutoRejectOnMemberRejection) {
+		// Auto-rejection is turned off: the final approver decides on the rejections
+		snapshot.planFinalApproverHandover(plan, now)
+
+		config.Logger.Info("All regular members decided with rejections, auto-rejection is off, handing to final approver",
+			zap.String("applicationID", applicationID),
+			zap.Int64("rejectedCount", tally.rejected))
+	} else if !member.IsFinalApprover && allRegularMembersDecided && hasAnyRejection {
+		// AUTO-REJECT: At least one regular member rejected, no need for final approver
+		autoRejectionReason := rejectionContent
+		if autoRejectionReason == "" {
+			autoRejectionReason = "Application auto-rejected due to member rejections"
+		}
+		if err := snapshot.planAutoRejection(plan, autoRejectionReason, now); err != nil {
+			return nil, err
+		}
+
+		config.Logger.Info("Auto-rejecting application due to regular member rejection",
+			zap.String("applicationID", applicationID),
+			zap.String("finalApproverID", snapshot.finalApprover.UserID.String()),
+			zap.Int64("rejectedCount", tally.rejected))
+
+		// PHASE 3: All regular members approved, ready for final approver (shouldn't happen in rejection flow)
+	} else if !member.IsFinalApprover && allRegularMembersDecided && !hasAnyRejection {
+		// All regular members approved - ready for final approval
+		plan.applicationStatus = models.UnderReviewApplication
+		plan.assignmentUpdates["ready_for_final_approval"] = true
+		plan.assignmentUpdates["final_approver_assigned_at"] = now
+
+		config.Logger.Info("All regular members approved, ready for final approval",
+			zap.String("applicationID", applicationID))
+
+		// FINAL APPROVER rejection
+	} else if member.IsFinalApprover {
+		// Final approver rejection - normal process
+		if existing := snapshot.activeFinalApproval; existing != nil {
+			finalApproval := *existing
+			finalApproval.ApproverID = userID
+			finalApproval.Decision = models.RejectedApplication
+			finalApproval.DecisionAt = now
+			finalApproval.Comment = &rejectionContent
+			finalApproval.UpdatedAt = now
+			plan.finalApproval = &finalApproval
+		} else {
+			plan.finalApproval = &models.FinalApproval{
+				ID:            uuid.New(),
+				ApplicationID: snapshot.application.ID,
+				ApproverID:    userID,
+				Decision:      models.RejectedApplication,
+				DecisionAt:    now,
+				Comment:       &rejectionContent,
+			}
+		}
+
+		plan.applicationStatus = models.RejectedApplication
+		plan.assignmentUpdates["completed_at"] = now
+		plan.assignmentUpdates["final_decision_at"] = now
+		plan.assignmentUpdates["final_decision_id"] = plan.finalApproval.ID
+	}
+
+	if _, err := r.commitDecisionPlan(ctx, snapshot, plan); err != nil {
+		return nil, err
+	}
+
+	if plan.finalApproval != nil {
+		config.Logger.Info("Recorded final approval for rejection",
+			zap.String("applicationID", applicationID),
+			zap.String("finalApprovalID", plan.finalApproval.ID.String()))
+	}
+
+	status := snapshot.application.Status
+	if plan.applicationStatus != "" {
+		status = plan.applicationStatus
+	}
+	return &RejectionResult{
+		ApplicationStatus: status,
+		IsFinalApprover:   member.IsFinalApprover,
+		ClosingMessages:   plan.closingMessages,
+	}, nil
+}
+
+// loadDecisionSnapshot reads everything a decision depends on without holding a transaction,
+// and checks the user may make it. action is "approve" or "reject".
+func (r *applicationRepository) loadDecisionSnapshot(ctx context.Context, applicationID string, userID uuid.UUID, action string) (*decisionSnapshot, error) {
+	db := r.db.WithContext(ctx)
+	snapshot := &decisionSnapshot{decisionStatuses: map[uuid.UUID]models.MemberDecisionStatus{}}
+
 	// Fetch application with group assignment
-	var application models.Application
-	err := tx.
-		Preload("ApprovalGroup.Members", "is_active = ?", true).
+	err := db.
+		Preload("ApprovalGroup").
 		Preload("GroupAssignments", "is_active = ?", true).
-		Preload("FinalApproval").
 		Where("id = ?", applicationID).
-		First(&application).Error
-
+		First(&snapshot.application).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("application not found")
 		}
 		return nil, err
 	}
+	application := &snapshot.application
 
 	// Check if user is a member of the approval group
-	var groupMember models.ApprovalGroupMember
-	err = tx.
+	err = db.
+		Preload("User").
 		Where("approval_group_id = ? AND user_id = ? AND is_active = ?",
 			application.ApprovalGroup.ID, userID, true).
-		First(&groupMember).Error
-
+		First(&snapshot.member).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not authorized to reject this application")
+			return nil, fmt.Errorf("user not authorized to %s this application", action)
 		}
 		return nil, err
 	}
 
-	// Check if user can reject
-	if !groupMember.CanReject {
-		return nil, errors.New("user does not have permission to reject applications")
+	// Check if user can make this decision
+	allowed := snapshot.member.CanApprove
+	if action == "reject" {
+		allowed = snapshot.member.CanReject
+	}
+	if !allowed {
+		return nil, fmt.Errorf("user does not have permission to %s applications", action)
 	}
 
 	// Check if there's an active group assignment
 	if len(application.GroupAssignments) == 0 {
 		return nil, errors.New("no active group assignment found for this application")
 	}
+	snapshot.assignment = application.GroupAssignments[0]
+	assignmentID := snapshot.assignment.ID
 
-	assignment := application.GroupAssignments[0]
-	now := time.Now()
-
-	// Check if user already made a decision
-	var existingDecision models.MemberApprovalDecision
-	err = tx.
-		Where("assignment_id = ? AND member_id = ?", assignment.ID, groupMember.ID).
-		First(&existingDecision).Error
-
-	var decision models.MemberApprovalDecision
-
-	if err == nil {
-		// Update existing decision
-		decision = existingDecision
-		decision.Status = models.DecisionRejected
-		decision.DecidedAt = &now
-		decision.UpdatedAt = now
-	} else if errors.Is(err, gorm.ErrRecordNotFound) {
-		// Create new decision only if none exists
-		decision = models.MemberApprovalDecision{
-			ID:                      uuid.New(),
-			AssignmentID:            assignment.ID,
-			MemberID:                groupMember.ID,
-			UserID:                  userID,
-			Status:                  models.DecisionRejected,
-			DecidedAt:               &now,
-			AssignedAs:              groupMember.Role,
-			IsFinalApproverDecision: groupMember.IsFinalApprover,
-			WasAvailable:            groupMember.AvailabilityStatus == models.AvailabilityAvailable,
-		}
-	} else {
+	// Reviewers related to the applicant must declare it before deciding
+	if err := r.checkConflictOfInterest(db, application, assignmentID, &snapshot.member); err != nil {
 		return nil, err
 	}
 
-	// Save decision (this will update if existing, create if new)
-	if err := tx.Save(&decision).Error; err != nil {
+	var decisions []models.MemberApprovalDecision
+	if err := db.Where("assignment_id = ?", assignmentID).Find(&decisions).Error; err != nil {
 		return nil, err
 	}
+	for i := range decisions {
+		snapshot.decisionStatuses[decisions[i].MemberID] = decisions[i].Status
+		if decisions[i].MemberID == snapshot.member.ID {
+			snapshot.existingDecision = &decisions[i]
+		}
+	}
 
-	// Add rejection comment
-	rejectionContent := fmt.Sprintf("REJECTION REASON: %s", reason)
-	if comment != nil && *comment != "" {
-		rejectionContent = fmt.Sprintf("%s\nADDITIONAL COMMENTS: %s", rejectionContent, *comment)
+	if snapshot.regularMembers, err = r.getRegularMembers(db, snapshot.assignment.ApprovalGroupID); err != nil {
+		return nil, err
 	}
 
-	rejectionComment := models.Comment{
-		ID:            uuid.New(),
-		ApplicationID: application.ID,
-		DecisionID:    &decision.ID,
-		CommentType:   commentType,
-		Content:       rejectionContent,
-		UserID:        userID,
-		CreatedBy:     fmt.Sprintf("%s %s", groupMember.User.FirstName, groupMember.User.LastName),
+	finalApprover, err := r.getFinalApprover(db, snapshot.assignment.ApprovalGroupID)
+	if err == nil {
+		snapshot.finalApprover = finalApprover
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
 	}
-	if err := tx.Create(&rejectionComment).Error; err != nil {
+
+	if snapshot.checklist, err = reviewChecklist(db, snapshot.assignment.ApprovalGroupID, false); err != nil {
 		return nil, err
 	}
 
-	// Update assignment statistics
-	if err := r.updateAssignmentStatistics(tx, assignment.ID); err != nil {
+	var activeFinalApproval models.FinalApproval
+	err = db.Where("application_id = ? AND deleted_at IS NULL", application.ID).
+		First(&activeFinalApproval).Error
+	if err == nil {
+		snapshot.activeFinalApproval = &activeFinalApproval
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, err
 	}
 
-	// ========================================
-	// AUTO-REJECTION LOGIC FOR REJECTIONS
-	// ========================================
+	return snapshot, nil
+}
 
-	// Get all regular members (excluding final approver)
-	var regularMembers []models.ApprovalGroupMember
-	if err := tx.
-		Where("approval_group_id = ? AND is_active = ? AND is_final_approver = ?",
-			assignment.ApprovalGroupID, true, false).
-		Find(&regularMembers).Error; err != nil {
-		return nil, err
+// memberDecision updates the member's existing decision or starts a new one
+func (s *decisionSnapshot) memberDecision(userID uuid.UUID, status models.MemberDecisionStatus, now time.Time) models.MemberApprovalDecision {
+	if s.existingDecision != nil {
+		decision := *s.existingDecision
+		decision.Status = status
+		decision.DecidedAt = &now
+		decision.UpdatedAt = now
+		return decision
 	}
+	return models.MemberApprovalDecision{
+		ID:                      uuid.New(),
+		AssignmentID:            s.assignment.ID,
+		MemberID:                s.member.ID,
+		UserID:                  userID,
+		Status:                  status,
+		DecidedAt:               &now,
+		AssignedAs:              s.member.Role,
+		IsFinalApproverDecision: s.member.IsFinalApprover,
+		WasAvailable:            s.member.AvailabilityStatus == models.AvailabilityAvailable,
+	}
+}
 
-	// Count how many regular members have made decisions (not pending)
-	var decidedCount int64
-	var rejectedCount int64
+// tallyWith counts the regular members' decisions as they will be once the deciding member's
+// status is saved. It matches what updateAssignmentStatistics and getDecisionWeightTally read
+// back after the commit.
+func (s *decisionSnapshot) tallyWith(status models.MemberDecisionStatus) decisionTally {
+	tally := decisionTally{regularMembers: int64(len(s.regularMembers))}
+	skippedWeight := 0
+	for _, member := range s.regularMembers {
+		memberStatus, decided := s.decisionStatuses[member.ID]
+		if member.ID == s.member.ID {
+			memberStatus, decided = status, true
+		}
 
-	if err := tx.Model(&models.MemberApprovalDecision{}).
-		Joins("JOIN approval_group_members ON approval_group_members.id = member_approval_decisions.member_id").
-		Where("member_approval_decisions.assignment_id = ? AND member_approval_decisions.deleted_at IS NULL", assignment.ID).
-		Where("approval_group_members.is_final_approver = ? AND approval_group_members.is_active = ?", false, true).
-		Where("member_approval_decisions.status != ?", models.DecisionPending).
-		Count(&decidedCount).Error; err != nil {
-		return nil, err
+		weight := member.Weight()
+		tally.weights.TotalWeight += weight
+		if !decided {
+			continue
+		}
+		if memberStatus != models.DecisionPending {
+			tally.decided++
+		}
+		switch memberStatus {
+		case models.DecisionApproved:
+			tally.weights.ApprovedWeight += weight
+		case models.DecisionRejected:
+			tally.rejected++
+			tally.weights.RejectedWeight += weight
+		case models.DecisionSkipped:
+			skippedWeight += weight
+		}
 	}
 
-	if err := tx.Model(&models.MemberApprovalDecision{}).
-		Joins("JOIN approval_group_members ON approval_group_members.id = member_approval_decisions.member_id").
-		Where("member_approval_decisions.assignment_id = ? AND member_approval_decisions.deleted_at IS NULL", assignment.ID).
-		Where("approval_group_members.is_final_approver = ? AND approval_group_members.is_active = ?", false, true).
-		Where("member_approval_decisions.status = ?", models.DecisionRejected).
-		Count(&rejectedCount).Error; err != nil {
-		return nil, err
+	tally.weights.TotalWeight -= skippedWeight
+	tally.weights.PendingWeight = tally.weights.TotalWeight - tally.weights.ApprovedWeight - tally.weights.RejectedWeight
+	if tally.weights.PendingWeight < 0 {
+		tally.weights.PendingWeight = 0
 	}
+	return tally
+}
 
-	regularMemberCount := int64(len(regularMembers))
-	allRegularMembersDecided := decidedCount >= regularMemberCount
-	hasAnyRejection := rejectedCount > 0
+// readyForFinalApproval mirrors isAssignmentReadyForFinalApproval on the snapshot, for the
+// final approver's decision
+func (s *decisionSnapshot) readyForFinalApproval() bool {
+	if s.assignment.IssuesRaised != s.assignment.IssuesResolved || len(s.regularMembers) == 0 {
+		return false
+	}
+	// The final approver is not a regular member, so their own decision leaves the tally alone
+	weights := s.tallyWith(models.DecisionApproved).weights
+	return s.application.ApprovalGroup.QuorumReached(weights.ApprovedWeight, weights.RejectedWeight, weights.PendingWeight)
+}
 
-	config.Logger.Info("Auto-rejection check on rejection",
-		zap.String("applicationID", applicationID),
-		zap.Int64("regularMemberCount", regularMemberCount),
-		zap.Int64("decidedCount", decidedCount),
-		zap.Int64("rejectedCount", rejectedCount),
-		zap.Bool("allDecided", allRegularMembersDecided),
-		zap.Bool("hasRejection", hasAnyRejection),
-		zap.Bool("isFinalApprover", groupMember.IsFinalApprover))
+// planAutoRejection rejects the application on the final approver's behalf once every regular
+// member has decided and at least one rejected
+func (s *decisionSnapshot) planAutoRejection(plan *decisionPlan, reason string, now time.Time) error {
+	// Get the actual final approver from the group
+	if s.finalApprover == nil {
+		return errors.New("failed to find final approver for auto-rejection: record not found")
+	}
 
-	// PHASE 1: Regular member rejects, but not all members have decided yet
-	if !groupMember.IsFinalApprover && !allRegularMembersDecided {
-		// Keep application under review - other members still need to review
-		application.Status = models.UnderReviewApplication
-		assignment.ReadyForFinalApproval = false
+	plan.finalApproval = &models.FinalApproval{
+		ID:                    uuid.New(),
+		ApplicationID:         s.application.ID,
+		ApproverID:            s.finalApprover.UserID,
+		Decision:              models.RejectedApplication,
+		DecisionAt:            now,
+		Comment:               &reason,
+		OverrodeGroupDecision: false,
+		IsSystemAutoDecision:  true,
+	}
+	plan.applicationStatus = models.RejectedApplication
+	plan.assignmentUpdates["completed_at"] = now
+	plan.assignmentUpdates["final_decision_at"] = now
+	plan.assignmentUpdates["ready_for_final_approval"] = false
+	plan.assignmentUpdates["final_decision_id"] = plan.finalApproval.ID
+	return nil
+}
 
-		config.Logger.Info("Regular member rejected, waiting for other members",
-			zap.String("applicationID", applicationID),
-			zap.String("rejectingMember", groupMember.User.FirstName+" "+groupMember.User.LastName))
+// planFinalApproverHandover leaves an application whose members rejected it under review and
+// passes it to the final approver, for councils that have turned auto-rejection off
+func (s *decisionSnapshot) planFinalApproverHandover(plan *decisionPlan, now time.Time) {
+	plan.applicationStatus = models.UnderReviewApplication
+	plan.assignmentUpdates["ready_for_final_approval"] = true
+	plan.assignmentUpdates["final_approver_assigned_at"] = now
+}
 
-		// PHASE 2: All regular members decided, check if we should auto-reject
-	} else if !groupMember.IsFinalApprover && allRegularMembersDecided && hasAnyRejection {
-		// AUTO-REJECT: At least one regular member rejected, no need for final approver
-		application.Status = models.RejectedApplication
-		assignment.CompletedAt = &now
-		assignment.FinalDecisionAt = &now
-		assignment.ReadyForFinalApproval = false
-
-		// Get the actual final approver from the group
-		var finalApproverMember models.ApprovalGroupMember
-		err = tx.
-			Where("approval_group_id = ? AND is_final_approver = ? AND is_active = ?",
-				assignment.ApprovalGroupID, true, true).
-			First(&finalApproverMember).Error
+// commitDecisionPlan writes a decision in one short transaction. It claims the assignment's
+// DecisionVersion first, which also locks the row; if the version, the issue counts or the
+// final decision moved since the snapshot, ErrDecisionConflict is returned so the decision is
+// worked out again. It returns the assignment as committed.
+func (r *applicationRepository) commitDecisionPlan(ctx context.Context, snapshot *decisionSnapshot, plan *decisionPlan) (*models.ApplicationGroupAssignment, error) {
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
 
-		if err != nil {
-			return nil, fmt.Errorf("failed to find final approver for auto-rejection: %w", err)
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
 		}
+	}()
+
+	assignmentID := snapshot.assignment.ID
+	claim := tx.Model(&models.ApplicationGroupAssignment{}).
+		Where("id = ? AND is_active = ? AND decision_version = ?", assignmentID, true, snapshot.assignment.DecisionVersion).
+		Update("decision_version", gorm.Expr("decision_version + 1"))
+	if claim.Error != nil {
+		tx.Rollback()
+		return nil, claim.Error
+	}
+	if claim.RowsAffected == 0 {
+		tx.Rollback()
+		return nil, ErrDecisionConflict
+	}
 
-		// Create final approval record for the auto-rejection using FINAL APPROVER's ID
-		finalApproval := models.FinalApproval{
-			ID:                    uuid.New(),
-			ApplicationID:         application.ID,
-			ApproverID:            finalApproverMember.UserID,
-			Decision:              models.RejectedApplication,
-			DecisionAt:            now,
-			Comment:               &rejectionContent,
-			OverrodeGroupDecision: false,
-			IsSystemAutoDecision:  true,
-		}
-		if err := tx.Create(&finalApproval).Error; err != nil {
+	var current models.ApplicationGroupAssignment
+	if err := tx.Where("id = ?", assignmentID).First(&current).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if current.IssuesRaised != snapshot.assignment.IssuesRaised ||
+		current.IssuesResolved != snapshot.assignment.IssuesResolved ||
+		!sameUUID(current.FinalDecisionID, snapshot.assignment.FinalDecisionID) {
+		tx.Rollback()
+		return nil, ErrDecisionConflict
+	}
+
+	// Save decision (this will update if existing, create if new)
+	if err := tx.Save(&plan.decision).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if plan.comment != nil {
+		if err := tx.Create(plan.comment).Error; err != nil {
+			tx.Rollback()
 			return nil, err
 		}
-		assignment.FinalDecisionID = &finalApproval.ID
+	}
 
-		config.Logger.Info("Auto-rejected application due to regular member rejection",
-			zap.String("applicationID", applicationID),
-			zap.String("finalApproverID", finalApproverMember.UserID.String()),
-			zap.Int64("rejectedCount", rejectedCount))
+	// Ticked checklist items belong to an approval; a rejection clears them
+	if err := tx.Where("decision_id = ?", plan.decision.ID).Delete(&models.DecisionChecklistItem{}).Error; err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to clear review checklist: %w", err)
+	}
+	if len(plan.checklist) > 0 {
+		if err := tx.Create(&plan.checklist).Error; err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("failed to record review checklist: %w", err)
+		}
+	}
 
-		// PHASE 3: All regular members approved, ready for final approver (shouldn't happen in rejection flow)
-	} else if !groupMember.IsFinalApprover && allRegularMembersDecided && !hasAnyRejection {
-		// All regular members approved - ready for final approval
-		application.Status = models.UnderReviewApplication
-		assignment.ReadyForFinalApproval = true
-		assignment.FinalApproverAssignedAt = &now
+	// Update assignment statistics
+	if err := r.updateAssignmentStatistics(tx, assignmentID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
 
-		config.Logger.Info("All regular members approved, ready for final approval",
-			zap.String("applicationID", applicationID))
+	if plan.finalApproval != nil {
+		if err := tx.Save(plan.finalApproval).Error; err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("failed to save final approval: %w", err)
+		}
 
-		// FINAL APPROVER rejection
-	} else if groupMember.IsFinalApprover {
-		// Final approver rejection - normal process
-		application.Status = models.RejectedApplication
-		assignment.CompletedAt = &now
-		assignment.FinalDecisionAt = &now
-
-		// Handle final approval record (update existing or create new)
-		var existingActiveFinalApproval models.FinalApproval
-		err = tx.Where("application_id = ? AND deleted_at IS NULL", application.ID).
-			First(&existingActiveFinalApproval).Error
-
-		if err == nil {
-			// Update existing final approval
-			existingActiveFinalApproval.ApproverID = userID
-			existingActiveFinalApproval.Decision = models.RejectedApplication
-			existingActiveFinalApproval.DecisionAt = now
-			existingActiveFinalApproval.Comment = &rejectionContent
-			existingActiveFinalApproval.UpdatedAt = now
-
-			if err := tx.Save(&existingActiveFinalApproval).Error; err != nil {
-				return nil, err
-			}
-			assignment.FinalDecisionID = &existingActiveFinalApproval.ID
+		// Record the planning scheme versions the application was assessed under, so decisions
+		// and permits can cite them after the schemes are amended
+		schemeVersions, err := planningscheme_repositories.VersionsInEffect(tx, snapshot.application.SubmissionDate)
+		if err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("failed to load planning schemes in effect: %w", err)
+		}
+		if err := tx.Model(plan.finalApproval).Association("PlanningSchemeVersions").Replace(schemeVersions); err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("failed to record planning scheme versions: %w", err)
+		}
+	}
 
-			config.Logger.Info("Updated existing final approval for rejection",
-				zap.String("applicationID", applicationID),
-				zap.String("finalApprovalID", existingActiveFinalApproval.ID.String()))
-		} else if errors.Is(err, gorm.ErrRecordNotFound) {
-			// Create new final approval
-			finalApproval := models.FinalApproval{
-				ID:            uuid.New(),
-				ApplicationID: application.ID,
-				ApproverID:    userID,
-				Decision:      models.RejectedApplication,
-				DecisionAt:    now,
-				Comment:       &rejectionContent,
-			}
-			if err := tx.Create(&finalApproval).Error; err != nil {
+	if len(plan.assignmentUpdates) > 0 {
+		if err := tx.Model(&models.ApplicationGroupAssignment{}).
+			Where("id = ?", assignmentID).
+			Updates(plan.assignmentUpdates).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if plan.applicationStatus != "" {
+		update := tx.Model(&models.Application{}).
+			Where("id = ? AND status = ?", snapshot.application.ID, snapshot.application.Status).
+			Update("status", plan.applicationStatus)
+		if update.Error != nil {
+			tx.Rollback()
+			return nil, update.Error
+		}
+		if update.RowsAffected == 0 {
+			tx.Rollback()
+			return nil, ErrDecisionConflict
+		}
+
+		// A final outcome closes the application's conversations
+		if FreezesThreads(plan.applicationStatus) {
+			messages, err := r.FreezeApplicationThreads(tx, &snapshot.application, plan.applicationStatus, plan.decision.UserID, time.Now())
+			if err != nil {
+				tx.Rollback()
 				return nil, err
 			}
-			assignment.FinalDecisionID = &finalApproval.ID
-
-			config.Logger.Info("Created new final approval for rejection",
-				zap.String("applicationID", applicationID),
-				zap.String("finalApprovalID", finalApproval.ID.String()))
-		} else {
-			return nil, err
+			plan.closingMessages = messages
 		}
 	}
 
-	// Save changes
-	if err := tx.Save(&application).Error; err != nil {
+	if err := tx.Where("id = ?", assignmentID).First(&current).Error; err != nil {
+		tx.Rollback()
 		return nil, err
 	}
-	if err := tx.Save(&assignment).Error; err != nil {
+
+	if err := tx.Commit().Error; err != nil {
 		return nil, err
 	}
+	return &current, nil
+}
 
-	result := &RejectionResult{
-		ApplicationStatus: application.Status,
-		IsFinalApprover:   groupMember.IsFinalApprover,
+func sameUUID(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
 	}
-
-	return result, nil
+	return *a == *b
 }
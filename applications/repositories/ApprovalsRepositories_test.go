@@ -0,0 +1,332 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestMain(m *testing.M) {
+	config.Logger = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+func TestRetryDecisionGivesUpAfterThreeConflicts(t *testing.T) {
+	r := &applicationRepository{}
+
+	attempts := 0
+	err := r.retryDecision("application", uuid.New(), func() error {
+		attempts++
+		return ErrDecisionConflict
+	})
+	if !errors.Is(err, ErrDecisionConflict) {
+		t.Fatalf("expected ErrDecisionConflict, got %v", err)
+	}
+	if attempts != decisionCommitAttempts {
+		t.Fatalf("expected %d attempts, got %d", decisionCommitAttempts, attempts)
+	}
+}
+
+func TestRetryDecisionStopsOnceCommitted(t *testing.T) {
+	r := &applicationRepository{}
+
+	attempts := 0
+	err := r.retryDecision("application", uuid.New(), func() error {
+		attempts++
+		if attempts < decisionCommitAttempts {
+			return ErrDecisionConflict
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected the last attempt to commit, got %v", err)
+	}
+	if attempts != decisionCommitAttempts {
+		t.Fatalf("expected %d attempts, got %d", decisionCommitAttempts, attempts)
+	}
+}
+
+func TestRetryDecisionDoesNotRetryOtherErrors(t *testing.T) {
+	r := &applicationRepository{}
+
+	denied := errors.New("user not authorized to approve this application")
+	attempts := 0
+	err := r.retryDecision("application", uuid.New(), func() error {
+		attempts++
+		return denied
+	})
+	if !errors.Is(err, denied) || attempts != 1 {
+		t.Fatalf("expected one attempt returning %v, got %d attempts returning %v", denied, attempts, err)
+	}
+}
+
+// The race tests below need Postgres for its row locking. Point TEST_DATABASE_URL at a scratch
+// database, e.g. "host=localhost user=postgres dbname=town_planning_test sslmode=disable"; the
+// schema is migrated and every run seeds its own group and application.
+
+const raceMembers = 6
+
+func TestConcurrentApprovalsRecordEveryDecision(t *testing.T) {
+	db := openDecisionTestDB(t)
+	seed := seedDecisionRace(t, db, raceMembers)
+	r := &applicationRepository{db: db}
+
+	decide := func(userID uuid.UUID) error {
+		_, err := r.ProcessApplicationApproval(context.Background(), seed.applicationID.String(), userID, nil, "", nil, nil)
+		return err
+	}
+	committed := raceDecisions(t, seed.memberUserIDs, decide)
+
+	// Every member's decision is recorded exactly once
+	assertDecisionCount(t, db, seed.assignmentID, models.DecisionApproved, raceMembers)
+	assignment := loadAssignment(t, db, seed.assignmentID)
+	if assignment.DecisionVersion != committed {
+		t.Fatalf("expected decision version %d after %d commits, got %d", committed, committed, assignment.DecisionVersion)
+	}
+	if !assignment.ReadyForFinalApproval {
+		t.Fatal("expected the assignment to be ready for final approval once every member approved")
+	}
+	assertApplicationStatus(t, db, seed.applicationID, models.UnderReviewApplication)
+
+	// A final approver double-submitting settles the application once
+	raceDecisions(t, []uuid.UUID{seed.finalApproverID, seed.finalApproverID, seed.finalApproverID}, decide)
+
+	assertApplicationStatus(t, db, seed.applicationID, models.ApprovedApplication)
+	assertFinalApprovals(t, db, seed.applicationID, 1)
+}
+
+func TestConcurrentRejectionsSettleApplicationOnce(t *testing.T) {
+	db := openDecisionTestDB(t)
+	seed := seedDecisionRace(t, db, raceMembers)
+	r := &applicationRepository{db: db}
+
+	raceDecisions(t, seed.memberUserIDs, func(userID uuid.UUID) error {
+		_, err := r.ProcessApplicationRejection(context.Background(), seed.applicationID.String(), userID, "Encroaches on the building line", nil, "", nil)
+		return err
+	})
+
+	assertDecisionCount(t, db, seed.assignmentID, models.DecisionRejected, raceMembers)
+	assertApplicationStatus(t, db, seed.applicationID, models.RejectedApplication)
+	assertFinalApprovals(t, db, seed.applicationID, 1)
+}
+
+// raceDecisions starts every decision at once. A decision that still conflicts after its retries
+// is sent again, as a client does on a 409, until it commits. It returns how many commits there
+// were.
+func raceDecisions(t *testing.T, userIDs []uuid.UUID, decide func(userID uuid.UUID) error) int {
+	t.Helper()
+
+	start := make(chan struct{})
+	errs := make([]error, len(userIDs))
+	var wg sync.WaitGroup
+	for i, userID := range userIDs {
+		wg.Add(1)
+		go func(i int, userID uuid.UUID) {
+			defer wg.Done()
+			<-start
+			errs[i] = decide(userID)
+		}(i, userID)
+	}
+	close(start)
+	wg.Wait()
+
+	committed := 0
+	for i, err := range errs {
+		for attempt := 0; errors.Is(err, ErrDecisionConflict); attempt++ {
+			if attempt == raceMembers {
+				t.Fatalf("decision by %s kept conflicting with no other decisions in flight", userIDs[i])
+			}
+			err = decide(userIDs[i])
+		}
+		if err != nil {
+			t.Fatalf("decision by %s failed: %v", userIDs[i], err)
+		}
+		committed++
+	}
+	return committed
+}
+
+type decisionRaceSeed struct {
+	applicationID   uuid.UUID
+	assignmentID    uuid.UUID
+	memberUserIDs   []uuid.UUID
+	finalApproverID uuid.UUID
+}
+
+func openDecisionTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set, skipping decision race test")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(config.MigratedModels()...); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+	return db
+}
+
+// seedDecisionRace creates a group of regular members plus a final approver, all needing to
+// approve, and an application under review assigned to it
+func seedDecisionRace(t *testing.T, db *gorm.DB, members int) decisionRaceSeed {
+	t.Helper()
+
+	suffix := uuid.NewString()[:8]
+	phoneBase := time.Now().UnixNano() % 1_000_000_000
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("failed to seed decision race: %v", err)
+		}
+	}
+
+	role := models.Role{ID: uuid.New(), Name: "Reviewer " + suffix, CreatedBy: "test"}
+	must(db.Create(&role).Error)
+
+	group := models.ApprovalGroup{
+		ID:                   uuid.New(),
+		Name:                 "Decision race " + suffix,
+		Type:                 models.ApprovalGroupGlobal,
+		RequiresAllApprovals: true,
+		MinimumApprovals:     1,
+		CommentPolicy:        models.CommentsOptional,
+		IsActive:             true,
+		CreatedBy:            "test",
+	}
+	must(db.Create(&group).Error)
+
+	seed := decisionRaceSeed{}
+	for i := 0; i <= members; i++ {
+		user := models.User{
+			ID:        uuid.New(),
+			FirstName: "Reviewer",
+			LastName:  fmt.Sprintf("Number%d", i),
+			Email:     fmt.Sprintf("reviewer%d-%s@example.test", i, suffix),
+			Phone:     fmt.Sprintf("+263%09d%02d", phoneBase, i),
+			RoleID:    role.ID,
+			CreatedBy: "test",
+		}
+		must(db.Create(&user).Error)
+
+		finalApprover := i == members
+		must(db.Create(&models.ApprovalGroupMember{
+			ID:              uuid.New(),
+			ApprovalGroupID: group.ID,
+			UserID:          user.ID,
+			Role:            models.MemberRolePrimary,
+			CanApprove:      true,
+			CanReject:       true,
+			IsActive:        true,
+			IsFinalApprover: finalApprover,
+			DecisionWeight:  1,
+			AddedBy:         "test",
+		}).Error)
+
+		if finalApprover {
+			seed.finalApproverID = user.ID
+		} else {
+			seed.memberUserIDs = append(seed.memberUserIDs, user.ID)
+		}
+	}
+
+	firstName, lastName := "Tendai", "Applicant"
+	applicant := models.Applicant{
+		ID:            uuid.New(),
+		ApplicantType: models.IndividualApplicant,
+		FirstName:     &firstName,
+		LastName:      &lastName,
+		FullName:      firstName + " " + lastName,
+		Email:         fmt.Sprintf("applicant-%s@example.test", suffix),
+		PhoneNumber:   fmt.Sprintf("+263%09d99", phoneBase),
+		Status:        models.ActiveApplicant,
+		CreatedBy:     "test",
+	}
+	must(db.Create(&applicant).Error)
+
+	application := models.Application{
+		ID:              uuid.New(),
+		PlanNumber:      "RACE-" + suffix,
+		SubmissionDate:  time.Now(),
+		ApplicantID:     applicant.ID,
+		AssignedGroupID: &group.ID,
+		Status:          models.UnderReviewApplication,
+		CreatedBy:       "test",
+	}
+	must(db.Create(&application).Error)
+
+	assignment := models.ApplicationGroupAssignment{
+		ID:              uuid.New(),
+		ApplicationID:   application.ID,
+		ApprovalGroupID: group.ID,
+		IsActive:        true,
+		AssignedAt:      time.Now(),
+		AssignedBy:      "test",
+	}
+	must(db.Create(&assignment).Error)
+
+	seed.applicationID = application.ID
+	seed.assignmentID = assignment.ID
+	return seed
+}
+
+func assertDecisionCount(t *testing.T, db *gorm.DB, assignmentID uuid.UUID, status models.MemberDecisionStatus, want int) {
+	t.Helper()
+	var got int64
+	if err := db.Model(&models.MemberApprovalDecision{}).
+		Where("assignment_id = ? AND status = ?", assignmentID, status).
+		Count(&got).Error; err != nil {
+		t.Fatalf("failed to count decisions: %v", err)
+	}
+	if got != int64(want) {
+		t.Fatalf("expected %d %s decisions, got %d", want, status, got)
+	}
+}
+
+func assertApplicationStatus(t *testing.T, db *gorm.DB, applicationID uuid.UUID, want models.ApplicationStatus) {
+	t.Helper()
+	var application models.Application
+	if err := db.Where("id = ?", applicationID).First(&application).Error; err != nil {
+		t.Fatalf("failed to load application: %v", err)
+	}
+	if application.Status != want {
+		t.Fatalf("expected application status %s, got %s", want, application.Status)
+	}
+}
+
+func assertFinalApprovals(t *testing.T, db *gorm.DB, applicationID uuid.UUID, want int) {
+	t.Helper()
+	var got int64
+	if err := db.Model(&models.FinalApproval{}).
+		Where("application_id = ?", applicationID).
+		Count(&got).Error; err != nil {
+		t.Fatalf("failed to count final approvals: %v", err)
+	}
+	if got != int64(want) {
+		t.Fatalf("expected %d final approval, got %d", want, got)
+	}
+}
+
+func loadAssignment(t *testing.T, db *gorm.DB, assignmentID uuid.UUID) models.ApplicationGroupAssignment {
+	t.Helper()
+	var assignment models.ApplicationGroupAssignment
+	if err := db.Where("id = ?", assignmentID).First(&assignment).Error; err != nil {
+		t.Fatalf("failed to load assignment: %v", err)
+	}
+	return assignment
+}
@@ -0,0 +1,429 @@
+package repositories
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	application_services "town-planning-backend/applications/services"
+	"town-planning-backend/db/models"
+	"town-planning-backend/utils/pagination"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MentionedThread is a chat thread where the user is mentioned in messages they have not read
+type MentionedThread struct {
+	ThreadID       uuid.UUID `json:"thread_id"`
+	Title          string    `json:"title"`
+	IsResolved     bool      `json:"is_resolved"`
+	IssueID        uuid.UUID `json:"issue_id"`
+	ApplicationID  uuid.UUID `json:"application_id"`
+	PlanNumber     string    `json:"plan_number"`
+	UnreadMentions int64     `json:"unread_mentions"`
+	LastMentionAt  time.Time `json:"last_mention_at"`
+}
+
+// queueSort is a sort a queue offers: the column and its usual direction
+type queueSort struct {
+	column     string
+	descending bool
+}
+
+// queueSorts lists the sorts each queue accepts in its sort parameter. queueDefaultSorts names
+// the one used when none is given; order=asc|desc overrides the usual direction.
+var queueSorts = map[models.ApproverQueue]map[string]queueSort{
+	models.QueuePendingDecisions: {
+		"submitted":   {column: "applications.submission_date", descending: false},
+		"risk":        {column: "applications.risk_score", descending: true},
+		"plan_number": {column: "applications.plan_number", descending: false},
+		"updated":     {column: "applications.updated_at", descending: true},
+	},
+	models.QueueAssignedIssues: {
+		"raised":   {column: "application_issues.created_at", descending: true},
+		"priority": {column: issuePriorityRank, descending: true},
+		"updated":  {column: "application_issues.updated_at", descending: true},
+	},
+	models.QueueUnreadMentions: {
+		"latest": {column: "last_mention_at", descending: true},
+		"count":  {column: "unread_mentions", descending: true},
+	},
+}
+
+var queueDefaultSorts = map[models.ApproverQueue]string{
+	models.QueuePendingDecisions: "submitted",
+	models.QueueAssignedIssues:   "raised",
+	models.QueueUnreadMentions:   "latest",
+}
+
+// queueFilterKeys lists the filters each queue accepts, so saved filters cannot carry keys the
+// queue would silently ignore
+var queueFilterKeys = map[models.ApproverQueue][]string{
+	models.QueuePendingDecisions: {"status", "risk_level", "plan_number", "applicant", "category_id", "date_from", "date_to", "sort", "order"},
+	models.QueueAssignedIssues:   {"priority", "category", "application_id", "plan_number", "sort", "order"},
+	models.QueueUnreadMentions:   {"application_id", "plan_number", "is_resolved", "sort", "order"},
+}
+
+// issuePriorityRank orders issues from CRITICAL down to LOW
+const issuePriorityRank = "CASE application_issues.priority WHEN 'CRITICAL' THEN 4 WHEN 'HIGH' THEN 3 WHEN 'MEDIUM' THEN 2 WHEN 'LOW' THEN 1 ELSE 0 END"
+
+// queueOrder builds the ORDER BY for a queue from the sort and order filters
+func queueOrder(queue models.ApproverQueue, filters map[string]string) (string, error) {
+	name := filters["sort"]
+	if name == "" {
+		name = queueDefaultSorts[queue]
+	}
+	sort, ok := queueSorts[queue][name]
+	if !ok {
+		return "", fmt.Errorf("unknown sort %s", name)
+	}
+
+	descending := sort.descending
+	switch strings.ToLower(filters["order"]) {
+	case "":
+	case "asc":
+		descending = false
+	case "desc":
+		descending = true
+	default:
+		return "", errors.New("order must be asc or desc")
+	}
+
+	if descending {
+		return sort.column + " DESC NULLS LAST", nil
+	}
+	return sort.column + " ASC NULLS LAST", nil
+}
+
+// QueueFilterKeys returns the query parameters a queue accepts as filters, or nil for an
+// unknown queue
+func QueueFilterKeys(queue models.ApproverQueue) []string {
+	return queueFilterKeys[queue]
+}
+
+// ValidateQueueFilters checks a queue name and its filters before they are saved
+func ValidateQueueFilters(queue models.ApproverQueue, filters map[string]string) error {
+	allowed, ok := queueFilterKeys[queue]
+	if !ok {
+		return fmt.Errorf("unknown queue %s", queue)
+	}
+	for key := range filters {
+		known := false
+		for _, candidate := range allowed {
+			if key == candidate {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown filter %s", key)
+		}
+	}
+	_, err := queueOrder(queue, filters)
+	return err
+}
+
+// GetPendingDecisionsQueue lists the applications waiting for the user's own decision as a
+// member of the approval group they are assigned to
+func (r *applicationRepository) GetPendingDecisionsQueue(userID uuid.UUID, page pagination.Request, filters map[string]string) ([]models.Application, int64, error) {
+	order, err := queueOrder(models.QueuePendingDecisions, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	pendingForUser := r.db.Model(&models.MemberApprovalDecision{}).
+		Select("application_group_assignments.application_id").
+		Joins("JOIN application_group_assignments ON application_group_assignments.id = member_approval_decisions.assignment_id").
+		Where("member_approval_decisions.user_id = ? AND member_approval_decisions.status = ?", userID, models.DecisionPending).
+		Where("application_group_assignments.is_active = ? AND application_group_assignments.deleted_at IS NULL", true)
+
+	query := r.db.Model(&models.Application{}).
+		Where("applications.id IN (?)", pendingForUser).
+		Where("applications.status NOT IN ?", []models.ApplicationStatus{models.ApprovedApplication, models.RejectedApplication})
+
+	if status := filters["status"]; status != "" {
+		query = query.Where("applications.status = ?", status)
+	}
+	if riskLevel := filters["risk_level"]; riskLevel != "" {
+		query = query.Where("applications.risk_level = ?", riskLevel)
+	}
+	if planNumber := filters["plan_number"]; planNumber != "" {
+		query = query.Where("applications.plan_number ILIKE ?", "%"+planNumber+"%")
+	}
+	if applicant := filters["applicant"]; applicant != "" {
+		query = query.Where("applications.applicant_id IN (?)", r.db.Model(&models.Applicant{}).
+			Select("id").
+			Where("full_name ILIKE ?", "%"+applicant+"%"))
+	}
+	if categoryID := filters["category_id"]; categoryID != "" {
+		query = query.Where("applications.tariff_id IN (?)", r.db.Model(&models.Tariff{}).
+			Select("id").
+			Where("development_category_id = ?", categoryID))
+	}
+	if dateFrom := filters["date_from"]; dateFrom != "" {
+		if parsed, err := time.Parse("2006-01-02", dateFrom); err == nil {
+			query = query.Where("applications.submission_date >= ?", parsed)
+		}
+	}
+	if dateTo := filters["date_to"]; dateTo != "" {
+		if parsed, err := time.Parse("2006-01-02", dateTo); err == nil {
+			query = query.Where("applications.submission_date < ?", parsed.Add(24*time.Hour))
+		}
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var applications []models.Application
+	if err := page.Window(query, "applications", order).
+		Preload("Applicant").
+		Preload("Stand").
+		Preload("Tariff.DevelopmentCategory").
+		Preload("ApprovalGroup").
+		Find(&applications).Error; err != nil {
+		return nil, 0, err
+	}
+	return applications, total, nil
+}
+
+// GetAssignedIssuesQueue lists the unresolved issues assigned to the user, directly or through
+// their approval group membership
+func (r *applicationRepository) GetAssignedIssuesQueue(userID uuid.UUID, page pagination.Request, filters map[string]string) ([]models.ApplicationIssue, int64, error) {
+	order, err := queueOrder(models.QueueAssignedIssues, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	userMemberships := r.db.Model(&models.ApprovalGroupMember{}).
+		Select("id").
+		Where("user_id = ?", userID)
+
+	query := r.db.Model(&models.ApplicationIssue{}).
+		Where("application_issues.is_resolved = ?", false).
+		Where("application_issues.assigned_to_user_id = ? OR application_issues.assigned_to_group_member_id IN (?)", userID, userMemberships)
+
+	if priority := filters["priority"]; priority != "" {
+		query = query.Where("application_issues.priority = ?", strings.ToUpper(priority))
+	}
+	if category := filters["category"]; category != "" {
+		query = query.Where("application_issues.category = ?", category)
+	}
+	if applicationID := filters["application_id"]; applicationID != "" {
+		query = query.Where("application_issues.application_id = ?", applicationID)
+	}
+	if planNumber := filters["plan_number"]; planNumber != "" {
+		query = query.Where("application_issues.application_id IN (?)", r.db.Model(&models.Application{}).
+			Select("id").
+			Where("plan_number ILIKE ?", "%"+planNumber+"%"))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var issues []models.ApplicationIssue
+	if err := page.Window(query, "application_issues", order).
+		Preload("Application").
+		Preload("RaisedByUser").
+		Find(&issues).Error; err != nil {
+		return nil, 0, err
+	}
+	return issues, total, nil
+}
+
+// GetUnreadMentionsQueue lists the threads where the user is mentioned in messages they have
+// not read, by receipt or by their read position in the thread
+func (r *applicationRepository) GetUnreadMentionsQueue(userID uuid.UUID, page pagination.Request, filters map[string]string) ([]MentionedThread, int64, error) {
+	order, err := queueOrder(models.QueueUnreadMentions, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := r.db.Model(&models.ChatMessageMention{}).
+		Select("chat_threads.id AS thread_id, chat_threads.title, chat_threads.is_resolved, chat_threads.issue_id, "+
+			"chat_threads.application_id, applications.plan_number, "+
+			"COUNT(chat_message_mentions.id) AS unread_mentions, MAX(chat_message_mentions.created_at) AS last_mention_at").
+		Joins("JOIN chat_messages ON chat_messages.id = chat_message_mentions.message_id").
+		Joins("JOIN chat_threads ON chat_threads.id = chat_message_mentions.thread_id").
+		Joins("JOIN applications ON applications.id = chat_threads.application_id").
+		Joins("LEFT JOIN read_receipts ON read_receipts.message_id = chat_message_mentions.message_id AND read_receipts.user_id = chat_message_mentions.user_id").
+		Joins("LEFT JOIN participant_thread_states ON participant_thread_states.thread_id = chat_message_mentions.thread_id AND participant_thread_states.user_id = chat_message_mentions.user_id").
+		Where("chat_message_mentions.user_id = ?", userID).
+		Where("chat_messages.is_deleted = ?", false).
+		Where("read_receipts.id IS NULL").
+		Where("participant_thread_states.last_read_at IS NULL OR chat_messages.created_at > participant_thread_states.last_read_at").
+		Group("chat_threads.id, chat_threads.title, chat_threads.is_resolved, chat_threads.issue_id, chat_threads.application_id, applications.plan_number")
+
+	if applicationID := filters["application_id"]; applicationID != "" {
+		query = query.Where("chat_threads.application_id = ?", applicationID)
+	}
+	if planNumber := filters["plan_number"]; planNumber != "" {
+		query = query.Where("applications.plan_number ILIKE ?", "%"+planNumber+"%")
+	}
+	switch filters["is_resolved"] {
+	case "true":
+		query = query.Where("chat_threads.is_resolved = ?", true)
+	case "false":
+		query = query.Where("chat_threads.is_resolved = ?", false)
+	}
+
+	var total int64
+	if err := r.db.Table("(?) AS mentioned_threads", query).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Grouped rows have no created_at to key a cursor on, so this queue always pages by offset
+	var threads []MentionedThread
+	if err := query.
+		Order(order).
+		Order("chat_threads.id").
+		Limit(page.Limit).
+		Offset(page.Offset()).
+		Scan(&threads).Error; err != nil {
+		return nil, 0, err
+	}
+	return threads, total, nil
+}
+
+// recordMessageMentions stores who a new message mentions. Only active participants of the
+// thread other than the sender can be mentioned.
+func (r *applicationRepository) recordMessageMentions(tx *gorm.DB, message *models.ChatMessage) error {
+	if message.MessageType != models.MessageTypeText || !strings.Contains(message.Content, "@") {
+		return nil
+	}
+
+	var participants []models.User
+	if err := tx.Model(&models.User{}).
+		Joins("JOIN chat_participants ON chat_participants.user_id = users.id").
+		Where("chat_participants.thread_id = ? AND chat_participants.is_active = ?", message.ThreadID, true).
+		Where("users.id <> ?", message.SenderID).
+		Find(&participants).Error; err != nil {
+		return fmt.Errorf("failed to load thread participants: %w", err)
+	}
+
+	mentioned := application_services.FindMentionedUsers(message.Content, participants)
+	if len(mentioned) == 0 {
+		return nil
+	}
+
+	mentions := make([]models.ChatMessageMention, 0, len(mentioned))
+	for _, userID := range mentioned {
+		mentions = append(mentions, models.ChatMessageMention{
+			MessageID: message.ID,
+			ThreadID:  message.ThreadID,
+			UserID:    userID,
+		})
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&mentions).Error; err != nil {
+		return fmt.Errorf("failed to record mentions: %w", err)
+	}
+	return nil
+}
+
+// GetSavedQueueFilters lists the user's saved filters, optionally for one queue
+func (r *applicationRepository) GetSavedQueueFilters(userID uuid.UUID, queue *models.ApproverQueue) ([]models.SavedQueueFilter, error) {
+	query := r.db.Where("user_id = ?", userID)
+	if queue != nil {
+		query = query.Where("queue = ?", *queue)
+	}
+
+	var filters []models.SavedQueueFilter
+	err := query.Order("queue ASC, name ASC").Find(&filters).Error
+	return filters, err
+}
+
+// GetSavedQueueFilter returns one of the user's saved filters
+func (r *applicationRepository) GetSavedQueueFilter(userID uuid.UUID, filterID uuid.UUID) (*models.SavedQueueFilter, error) {
+	var filter models.SavedQueueFilter
+	if err := r.db.Where("id = ? AND user_id = ?", filterID, userID).First(&filter).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("saved filter not found")
+		}
+		return nil, err
+	}
+	return &filter, nil
+}
+
+// GetDefaultQueueFilter returns the filter the user marked as default for a queue, or nil
+func (r *applicationRepository) GetDefaultQueueFilter(userID uuid.UUID, queue models.ApproverQueue) (*models.SavedQueueFilter, error) {
+	var filter models.SavedQueueFilter
+	err := r.db.Where("user_id = ? AND queue = ? AND is_default = ?", userID, queue, true).First(&filter).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &filter, nil
+}
+
+// SaveQueueFilter creates or updates a saved filter. filter.ID is nil for a new filter. Marking
+// a filter as default clears the default on the user's other filters for the same queue.
+func (r *applicationRepository) SaveQueueFilter(tx *gorm.DB, filter *models.SavedQueueFilter) error {
+	var values map[string]string
+	if err := json.Unmarshal(filter.Filters, &values); err != nil {
+		return errors.New("filters must be an object of strings")
+	}
+	if err := ValidateQueueFilters(filter.Queue, values); err != nil {
+		return err
+	}
+
+	var clash int64
+	if err := tx.Model(&models.SavedQueueFilter{}).
+		Where("user_id = ? AND queue = ? AND name = ? AND id <> ?", filter.UserID, filter.Queue, filter.Name, filter.ID).
+		Count(&clash).Error; err != nil {
+		return err
+	}
+	if clash > 0 {
+		return errors.New("a saved filter with this name already exists")
+	}
+
+	if filter.IsDefault {
+		if err := tx.Model(&models.SavedQueueFilter{}).
+			Where("user_id = ? AND queue = ? AND id <> ?", filter.UserID, filter.Queue, filter.ID).
+			Update("is_default", false).Error; err != nil {
+			return fmt.Errorf("failed to clear default filter: %w", err)
+		}
+	}
+
+	if filter.ID == uuid.Nil {
+		if err := tx.Create(filter).Error; err != nil {
+			return fmt.Errorf("failed to save filter: %w", err)
+		}
+		return nil
+	}
+
+	result := tx.Model(&models.SavedQueueFilter{}).
+		Where("id = ? AND user_id = ?", filter.ID, filter.UserID).
+		Updates(map[string]interface{}{
+			"queue":      filter.Queue,
+			"name":       filter.Name,
+			"filters":    filter.Filters,
+			"is_default": filter.IsDefault,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update filter: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("saved filter not found")
+	}
+	return nil
+}
+
+// DeleteQueueFilter removes one of the user's saved filters
+func (r *applicationRepository) DeleteQueueFilter(userID uuid.UUID, filterID uuid.UUID) error {
+	result := r.db.Where("id = ? AND user_id = ?", filterID, userID).Delete(&models.SavedQueueFilter{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("saved filter not found")
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"fmt"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GetApprovalGroupAvailability loads the active approval groups, or just the given one, with
+// their active members and the members' names, for laying out who is away when
+func (r *applicationRepository) GetApprovalGroupAvailability(groupID *uuid.UUID) ([]models.ApprovalGroup, error) {
+	query := r.db.
+		Preload("Members", func(db *gorm.DB) *gorm.DB {
+			return db.Where("is_active = ? AND role <> ?", true, models.MemberRoleRetired).Order("review_order ASC")
+		}).
+		Preload("Members.User", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "first_name", "last_name", "email")
+		}).
+		Where("is_active = ?", true)
+	if groupID != nil {
+		query = query.Where("id = ?", *groupID)
+	}
+
+	var groups []models.ApprovalGroup
+	if err := query.Order("name ASC").Find(&groups).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch approval groups: %w", err)
+	}
+	return groups, nil
+}
@@ -0,0 +1,202 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// boundaryCheckOrder puts an application's current boundary check first
+const boundaryCheckOrder = "checked_at DESC, created_at DESC"
+
+// CreateBoundaryLayer stores an uploaded layer as the active layer of its kind
+func (r *applicationRepository) CreateBoundaryLayer(tx *gorm.DB, layer *models.BoundaryLayer) error {
+	if err := tx.Model(&models.BoundaryLayer{}).
+		Where("kind = ? AND is_active = ?", layer.Kind, true).
+		Updates(map[string]interface{}{"is_active": false, "updated_by": layer.CreatedBy}).Error; err != nil {
+		return fmt.Errorf("failed to deactivate previous boundary layer: %w", err)
+	}
+
+	layer.IsActive = true
+	if err := tx.Create(layer).Error; err != nil {
+		return fmt.Errorf("failed to create boundary layer: %w", err)
+	}
+	return nil
+}
+
+// GetBoundaryLayers lists the boundary layers without their geometry, active layers first
+func (r *applicationRepository) GetBoundaryLayers() ([]models.BoundaryLayer, error) {
+	var layers []models.BoundaryLayer
+	if err := r.db.
+		Omit("geo_json").
+		Order("is_active DESC, created_at DESC").
+		Find(&layers).Error; err != nil {
+		return nil, err
+	}
+	return layers, nil
+}
+
+// GetBoundaryLayer returns a boundary layer with its geometry
+func (r *applicationRepository) GetBoundaryLayer(layerID uuid.UUID) (*models.BoundaryLayer, error) {
+	var layer models.BoundaryLayer
+	if err := r.db.Where("id = ?", layerID).First(&layer).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("boundary layer not found")
+		}
+		return nil, fmt.Errorf("failed to load boundary layer: %w", err)
+	}
+	return &layer, nil
+}
+
+// SetBoundaryLayerActive activates or deactivates a layer. Activating a layer deactivates the
+// other layer of its kind, e.g. to roll back to an earlier ward layer.
+func (r *applicationRepository) SetBoundaryLayerActive(tx *gorm.DB, layerID uuid.UUID, active bool, updatedBy string) (*models.BoundaryLayer, error) {
+	var layer models.BoundaryLayer
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Omit("geo_json").
+		Where("id = ?", layerID).
+		First(&layer).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("boundary layer not found")
+		}
+		return nil, fmt.Errorf("failed to load boundary layer: %w", err)
+	}
+
+	if active {
+		if err := tx.Model(&models.BoundaryLayer{}).
+			Where("kind = ? AND is_active = ? AND id <> ?", layer.Kind, true, layer.ID).
+			Updates(map[string]interface{}{"is_active": false, "updated_by": updatedBy}).Error; err != nil {
+			return nil, fmt.Errorf("failed to deactivate previous boundary layer: %w", err)
+		}
+	}
+
+	if err := tx.Model(&layer).Updates(map[string]interface{}{
+		"is_active":  active,
+		"updated_by": updatedBy,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update boundary layer: %w", err)
+	}
+	layer.IsActive = active
+	layer.UpdatedBy = &updatedBy
+	return &layer, nil
+}
+
+// GetActiveBoundaryLayers returns the active jurisdiction and ward layers; either is nil when
+// none has been uploaded
+func (r *applicationRepository) GetActiveBoundaryLayers() (*models.BoundaryLayer, *models.BoundaryLayer, error) {
+	var layers []models.BoundaryLayer
+	if err := r.db.Where("is_active = ?", true).Find(&layers).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to load boundary layers: %w", err)
+	}
+
+	var jurisdiction, ward *models.BoundaryLayer
+	for i := range layers {
+		switch layers[i].Kind {
+		case models.BoundaryLayerJurisdiction:
+			jurisdiction = &layers[i]
+		case models.BoundaryLayerWard:
+			ward = &layers[i]
+		}
+	}
+	return jurisdiction, ward, nil
+}
+
+// RecordBoundaryCheck stores a geo-validation against the application and flags or clears it
+// for boundary review according to the result
+func (r *applicationRepository) RecordBoundaryCheck(tx *gorm.DB, applicationID uuid.UUID, check *models.BoundaryCheck, createdBy string) (*models.BoundaryCheck, error) {
+	check.ID = uuid.Nil
+	check.ApplicationID = applicationID
+	check.CreatedBy = createdBy
+	if err := tx.Create(check).Error; err != nil {
+		return nil, fmt.Errorf("failed to record boundary check: %w", err)
+	}
+
+	if err := tx.Model(&models.Application{}).
+		Where("id = ?", applicationID).
+		Update("requires_boundary_review", check.RequiresReview()).Error; err != nil {
+		return nil, fmt.Errorf("failed to update boundary review flag: %w", err)
+	}
+	return check, nil
+}
+
+// GetApplicationBoundaryChecks lists every boundary check for an application, newest first
+func (r *applicationRepository) GetApplicationBoundaryChecks(applicationID uuid.UUID) ([]models.BoundaryCheck, error) {
+	var checks []models.BoundaryCheck
+	if err := r.db.
+		Preload("ReviewedBy").
+		Where("application_id = ?", applicationID).
+		Order(boundaryCheckOrder).
+		Find(&checks).Error; err != nil {
+		return nil, err
+	}
+	return checks, nil
+}
+
+// GetBoundaryReviewQueue lists the applications flagged for boundary review, oldest submission
+// first, with the check that flagged them
+func (r *applicationRepository) GetBoundaryReviewQueue() ([]models.Application, error) {
+	var applications []models.Application
+	if err := r.db.
+		Preload("Applicant").
+		Preload("Stand").
+		Preload("BoundaryChecks", func(db *gorm.DB) *gorm.DB {
+			return db.Order(boundaryCheckOrder)
+		}).
+		Where("requires_boundary_review = ?", true).
+		Order("submission_date ASC").
+		Find(&applications).Error; err != nil {
+		return nil, err
+	}
+	return applications, nil
+}
+
+// ClearBoundaryReview records the reviewer's decision on the latest flagged check and releases
+// the application from the boundary review queue
+func (r *applicationRepository) ClearBoundaryReview(tx *gorm.DB, applicationID uuid.UUID, reviewerID uuid.UUID, note string) (*models.BoundaryCheck, error) {
+	var application models.Application
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "requires_boundary_review").
+		Where("id = ?", applicationID).
+		First(&application).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("application not found")
+		}
+		return nil, fmt.Errorf("failed to load application: %w", err)
+	}
+	if !application.RequiresBoundaryReview {
+		return nil, errors.New("application is not awaiting boundary review")
+	}
+
+	var latest models.BoundaryCheck
+	if err := tx.Where("application_id = ?", applicationID).
+		Order(boundaryCheckOrder).
+		First(&latest).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("no boundary check recorded")
+		}
+		return nil, fmt.Errorf("failed to load boundary check: %w", err)
+	}
+
+	now := time.Now()
+	if err := tx.Model(&latest).Updates(map[string]interface{}{
+		"reviewed_by_id": reviewerID,
+		"reviewed_at":    now,
+		"review_note":    note,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to record boundary review: %w", err)
+	}
+
+	if err := tx.Model(&application).Update("requires_boundary_review", false).Error; err != nil {
+		return nil, fmt.Errorf("failed to clear boundary review flag: %w", err)
+	}
+
+	if err := tx.Preload("ReviewedBy").Where("id = ?", latest.ID).First(&latest).Error; err != nil {
+		return nil, fmt.Errorf("failed to reload boundary check: %w", err)
+	}
+	return &latest, nil
+}
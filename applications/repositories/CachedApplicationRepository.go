@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"town-planning-backend/cache"
+	"town-planning-backend/db/models"
+
+	"gorm.io/gorm"
+)
+
+// cachedApplicationRepository serves approval groups with their members from Redis.
+// Groups are read on every assignment and approval decision but change rarely.
+type cachedApplicationRepository struct {
+	ApplicationRepository
+	cache *cache.RepositoryCache
+}
+
+// NewCachedApplicationRepository wraps an application repository with the repository cache
+func NewCachedApplicationRepository(repo ApplicationRepository, repoCache *cache.RepositoryCache) ApplicationRepository {
+	return &cachedApplicationRepository{ApplicationRepository: repo, cache: repoCache}
+}
+
+// GetApprovalGroupWithMembers caches the group with its members' credentials cleared. Reads
+// made inside a transaction are not cached, since they may see rows that are never committed.
+func (r *cachedApplicationRepository) GetApprovalGroupWithMembers(db *gorm.DB, groupID string) (*models.ApprovalGroup, error) {
+	var group models.ApprovalGroup
+	if r.cache.Get(cache.EntityApprovalGroup, groupID, &group) {
+		return &group, nil
+	}
+
+	found, err := r.ApplicationRepository.GetApprovalGroupWithMembers(db, groupID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range found.Members {
+		found.Members[i].User.ClearCredentials()
+	}
+	if _, inTransaction := db.Statement.ConnPool.(gorm.TxCommitter); !inTransaction {
+		r.cache.Set(cache.EntityApprovalGroup, groupID, found)
+	}
+	return found, nil
+}
+
+func (r *cachedApplicationRepository) CreateApprovalGroup(tx *gorm.DB, group *models.ApprovalGroup) (*models.ApprovalGroup, error) {
+	created, err := r.ApplicationRepository.CreateApprovalGroup(tx, group)
+	if err != nil {
+		return nil, err
+	}
+	r.cache.Invalidate(cache.EntityApprovalGroup, created.ID.String())
+	return created, nil
+}
+
+// InvalidateApprovalGroupCache drops cached approval groups. Call it after committing writes to
+// a group or its members, including their decision weights and availability.
+func InvalidateApprovalGroupCache(groupIDs ...string) {
+	cache.Invalidate(cache.EntityApprovalGroup, groupIDs...)
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 	"town-planning-backend/applications/requests"
+	application_services "town-planning-backend/applications/services"
 	"town-planning-backend/config"
 	"town-planning-backend/db/models"
 
@@ -182,14 +183,14 @@ func (r *applicationRepository) RaiseApplicationIssueWithChatAndAttachments(
 	// UPDATE ASSIGNMENT COUNTS
 	// ========================================
 	assignment.IssuesRaised++
-	if err := tx.Save(&assignment).Error; err != nil {
+	if err := tx.Omit("decision_version").Save(&assignment).Error; err != nil {
 		return nil, nil, nil, fmt.Errorf("failed to update assignment issue count: %w", err)
 	}
 
 	// Update final approval status if needed
 	if assignment.ReadyForFinalApproval && !assignment.IsReadyForFinalApproval() {
 		assignment.ReadyForFinalApproval = false
-		if err := tx.Save(&assignment).Error; err != nil {
+		if err := tx.Omit("decision_version").Save(&assignment).Error; err != nil {
 			return nil, nil, nil, fmt.Errorf("failed to update final approval status: %w", err)
 		}
 	}
@@ -233,6 +234,17 @@ func (r *applicationRepository) createChatThreadForIssue(
 	case models.IssueAssignment_SPECIFIC_USER:
 		threadType = models.ChatThreadSpecificUser
 		participants = r.getSpecificUserParticipants(tx, raisedByMember.UserID, assignedToUserID)
+
+		// An assignee from another department is invited rather than added
+		now := time.Now()
+		for i := range participants {
+			if participants[i].UserID == raisedByMember.UserID {
+				continue
+			}
+			if err := inviteIfExternal(tx, &participants[i], raisedByMember.UserID, now); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	// Create the chat thread WITH THE VALID ISSUE ID
@@ -259,7 +271,7 @@ func (r *applicationRepository) createChatThreadForIssue(
 
 	// Create participants
 	for _, participant := range participants {
-		if err := tx.Create(&participant).Error; err != nil {
+		if err := createParticipant(tx, &participant); err != nil {
 			config.Logger.Warn("Failed to create chat participant, continuing",
 				zap.Error(err),
 				zap.String("userID", participant.UserID.String()))
@@ -285,14 +297,14 @@ func (r *applicationRepository) createInitialChatMessageWithAttachments(
 ) (*models.ChatMessage, error) {
 
 	// Create the initial chat message
-	initialMessage := models.ChatMessage{
-		ID:          uuid.New(),
-		ThreadID:    chatThread.ID,
-		SenderID:    raisedByMember.UserID,
-		Content:     fmt.Sprintf("Issue created: %s", description),
-		MessageType: models.MessageTypeSystem,
-		Status:      models.MessageStatusSent,
+	initialMessage, err := application_services.NewSystemMessage(models.SystemEventIssueCreated,
+		application_services.SystemEventParams{Description: description})
+	if err != nil {
+		return nil, err
 	}
+	initialMessage.ID = uuid.New()
+	initialMessage.ThreadID = chatThread.ID
+	initialMessage.SenderID = raisedByMember.UserID
 
 	if err := tx.Create(&initialMessage).Error; err != nil {
 		return nil, fmt.Errorf("failed to create initial chat message: %w", err)
@@ -471,26 +483,48 @@ func (repo *applicationRepository) AddParticipantToThread(
 	canInvite bool,
 	canRemove bool,
 	canManage bool,
-) error {
+) (*models.ChatParticipant, error) {
+
+	// Users from another department are invited; "system" additions never are
+	inviterID, _ := uuid.Parse(addedBy)
+	now := time.Now()
 
 	// Check if already a participant
 	var existing models.ChatParticipant
 	err := tx.Where("thread_id = ? AND user_id = ?", threadID, userID).First(&existing).Error
 
 	if err == nil {
+		// The system (e.g. escalation) brings a pending invitee straight in
+		if existing.InvitationStatus == models.InvitationPending && inviterID != uuid.Nil {
+			return nil, fmt.Errorf("user is already invited to this thread")
+		}
 		// Reactivate with updated permissions
 		if !existing.IsActive {
-			return tx.Model(&existing).Updates(map[string]interface{}{
-				"is_active":  true,
-				"role":       role,
-				"can_invite": canInvite,
-				"can_remove": canRemove,
-				"can_manage": canManage,
-				"removed_at": nil,
-				"updated_at": time.Now(),
+			existing.IsActive = true
+			if err := inviteIfExternal(tx, &existing, inviterID, now); err != nil {
+				return nil, err
+			}
+			existing.Role = role
+			existing.CanInvite = canInvite
+			existing.CanRemove = canRemove
+			existing.CanManage = canManage
+			existing.RemovedAt = nil
+			err := tx.Model(&existing).Updates(map[string]interface{}{
+				"is_active":               existing.IsActive,
+				"role":                    role,
+				"can_invite":              canInvite,
+				"can_remove":              canRemove,
+				"can_manage":              canManage,
+				"removed_at":              nil,
+				"invitation_status":       existing.InvitationStatus,
+				"invited_at":              existing.InvitedAt,
+				"invitation_expires_at":   existing.InvitationExpiresAt,
+				"invitation_responded_at": nil,
+				"updated_at":              now,
 			}).Error
+			return &existing, err
 		}
-		return fmt.Errorf("user is already an active participant")
+		return nil, fmt.Errorf("user is already an active participant")
 	}
 
 	// Create new participant with granular permissions
@@ -505,11 +539,17 @@ func (repo *applicationRepository) AddParticipantToThread(
 		CanManage:         canManage,
 		MuteNotifications: false,
 		AddedBy:           addedBy,
-		AddedAt:           time.Now(),
-		UpdatedAt:         time.Now(),
+		AddedAt:           now,
+		UpdatedAt:         now,
+	}
+	if err := inviteIfExternal(tx, &participant, inviterID, now); err != nil {
+		return nil, err
 	}
 
-	return tx.Create(&participant).Error
+	if err := createParticipant(tx, &participant); err != nil {
+		return nil, err
+	}
+	return &participant, nil
 }
 
 // RemoveParticipantFromThread removes a user from a chat thread (soft delete)
@@ -583,12 +623,22 @@ func (r *applicationRepository) AddMultipleParticipantsToThread(
 		err := tx.Where("thread_id = ? AND user_id = ?", threadID, participantReq.UserID).First(&existingParticipant).Error
 
 		if err == nil {
+			if existingParticipant.InvitationStatus == models.InvitationPending {
+				config.Logger.Warn("Participant already has a pending invitation",
+					zap.String("userID", participantReq.UserID.String()),
+					zap.String("threadID", threadID.String()))
+				continue
+			}
 			// Participant exists, reactivate if removed/inactive
 			if existingParticipant.RemovedAt != nil || !existingParticipant.IsActive {
 				existingParticipant.IsActive = true
 				existingParticipant.RemovedAt = nil
 				existingParticipant.Role = participantReq.Role
 				existingParticipant.UpdatedAt = time.Now()
+				if err := inviteIfExternal(tx, &existingParticipant, addedBy.ID, existingParticipant.UpdatedAt); err != nil {
+					errors = append(errors, fmt.Sprintf("failed to invite participant %s: %v", participantReq.UserID, err))
+					continue
+				}
 
 				if err := tx.Save(&existingParticipant).Error; err != nil {
 					errorMsg := fmt.Sprintf("failed to reactivate participant %s: %v", participantReq.UserID, err)
@@ -618,8 +668,12 @@ func (r *applicationRepository) AddMultipleParticipantsToThread(
 				AddedBy:   addedBy.ID.String(),
 				AddedAt:   time.Now(),
 			}
+			if err := inviteIfExternal(tx, &participant, addedBy.ID, participant.AddedAt); err != nil {
+				errors = append(errors, fmt.Sprintf("failed to invite participant %s: %v", participantReq.UserID, err))
+				continue
+			}
 
-			if err := tx.Create(&participant).Error; err != nil {
+			if err := createParticipant(tx, &participant); err != nil {
 				errorMsg := fmt.Sprintf("failed to create participant %s: %v", participantReq.UserID, err)
 				errors = append(errors, errorMsg)
 				config.Logger.Error("Failed to create participant",
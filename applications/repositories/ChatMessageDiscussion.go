@@ -2,13 +2,16 @@
 package repositories
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"mime/multipart"
+	application_services "town-planning-backend/applications/services"
 	"town-planning-backend/config"
 	"town-planning-backend/db/models"
 	documents_requests "town-planning-backend/documents/requests"
 	"town-planning-backend/utils"
+	"town-planning-backend/utils/pagination"
 
 	"time"
 
@@ -37,6 +40,7 @@ func (r *applicationRepository) CreateMessageWithAttachments(
 	messageType models.ChatMessageType,
 	senderID uuid.UUID,
 	files []*multipart.FileHeader,
+	categoryCodes []string,
 	applicationID *uuid.UUID,
 	createdBy string,
 ) (*EnhancedChatMessage, error) {
@@ -62,6 +66,10 @@ func (r *applicationRepository) CreateMessageWithAttachments(
 		return nil, fmt.Errorf("failed to create message: %w", err)
 	}
 
+	if err := r.recordMessageMentions(tx, &message); err != nil {
+		return nil, err
+	}
+
 	config.Logger.Info("Chat message created successfully",
 		zap.String("messageID", message.ID.String()),
 		zap.String("threadID", threadID))
@@ -70,10 +78,14 @@ func (r *applicationRepository) CreateMessageWithAttachments(
 	var attachments []*ChatAttachmentSummary
 	var attachmentErrors []string
 
-	for _, fileHeader := range files {
+	checklistFlags := map[string]bool{}
+
+	for i, fileHeader := range files {
+		categoryCode := chatAttachmentCategory(categoryCodes, i)
+
 		// Use the existing document service to create the document
 		documentRequest := &documents_requests.CreateDocumentRequest{
-			CategoryCode:  "CHAT_ATTACHMENT",
+			CategoryCode:  categoryCode,
 			FileName:      fileHeader.Filename,
 			CreatedBy:     createdBy,
 			ApplicationID: applicationID,
@@ -108,11 +120,7 @@ func (r *applicationRepository) CreateMessageWithAttachments(
 		}
 
 		// Create chat attachment linking to the document
-		chatAttachment := models.ChatAttachment{
-			ID:         uuid.New(),
-			MessageID:  message.ID,
-			DocumentID: response.Document.ID,
-		}
+		chatAttachment := newChatAttachment(message.ID, response.Document.ID, fileHeader)
 
 		if err := tx.Create(&chatAttachment).Error; err != nil {
 			errorMsg := fmt.Sprintf("failed to create chat attachment for %s: %v", fileHeader.Filename, err)
@@ -124,19 +132,13 @@ func (r *applicationRepository) CreateMessageWithAttachments(
 			continue
 		}
 
-		// Convert file size to string
-		fileSizeStr := response.Document.FileSize
+		summary := newChatAttachmentSummary(&chatAttachment, response.Document)
+		summary.CategoryCode = categoryCode
+		attachments = append(attachments, summary)
 
-		// For frontend response
-		attachments = append(attachments, &ChatAttachmentSummary{
-			ID:        chatAttachment.ID,
-			FileName:  response.Document.FileName,
-			FileSize:  fileSizeStr.String(),
-			FileType:  string(response.Document.DocumentType),
-			MimeType:  response.Document.MimeType,
-			FilePath:  response.Document.FilePath,
-			CreatedAt: string(response.Document.CreatedAt.Format(time.RFC3339)),
-		})
+		if flag, ok := application_services.ChecklistFlagForCategory(categoryCode); ok {
+			checklistFlags[flag] = true
+		}
 
 		config.Logger.Info("Chat attachment created successfully",
 			zap.String("filename", fileHeader.Filename),
@@ -154,6 +156,10 @@ func (r *applicationRepository) CreateMessageWithAttachments(
 			zap.Int("failedAttachments", len(attachmentErrors)))
 	}
 
+	if err := r.tickChecklistFromChat(tx, applicationID, checklistFlags, createdBy); err != nil {
+		return nil, err
+	}
+
 	// Use the same transaction to load the complete message
 	var completeMessage models.ChatMessage
 	if err := tx.
@@ -162,6 +168,7 @@ func (r *applicationRepository) CreateMessageWithAttachments(
 		Preload("Sender.Department").
 		Preload("Attachments").
 		Preload("Attachments.Document").
+		Preload("Attachments.Document.Category").
 		Where("id = ?", message.ID).
 		First(&completeMessage).Error; err != nil {
 		return nil, fmt.Errorf("failed to load complete message: %w", err)
@@ -171,17 +178,7 @@ func (r *applicationRepository) CreateMessageWithAttachments(
 	if len(completeMessage.Attachments) > 0 && len(attachments) == 0 {
 		attachments = make([]*ChatAttachmentSummary, len(completeMessage.Attachments))
 		for i, attachment := range completeMessage.Attachments {
-			fileSizeStr := attachment.Document.FileSize.String()
-
-			attachments[i] = &ChatAttachmentSummary{
-				ID:        attachment.ID,
-				FileName:  attachment.Document.FileName,
-				FileSize:  fileSizeStr,
-				FileType:  string(attachment.Document.DocumentType),
-				MimeType:  attachment.Document.MimeType,
-				FilePath:  attachment.Document.FilePath,
-				CreatedAt: attachment.Document.CreatedAt.Format(time.RFC3339),
-			}
+			attachments[i] = newChatAttachmentSummary(&attachment, &attachment.Document)
 		}
 	}
 
@@ -190,7 +187,10 @@ func (r *applicationRepository) CreateMessageWithAttachments(
 		ID:          completeMessage.ID,
 		Content:     completeMessage.Content,
 		MessageType: completeMessage.MessageType,
+		EventType:   completeMessage.EventType,
+		EventParams: completeMessage.EventParams,
 		Status:      completeMessage.Status,
+		Origin:      completeMessage.Origin,
 		IsEdited:    completeMessage.IsEdited,
 		EditedAt:    utils.FormatTimePointer(completeMessage.EditedAt),
 		IsDeleted:   completeMessage.IsDeleted,
@@ -217,32 +217,35 @@ func (r *applicationRepository) CreateMessageWithAttachments(
 // GetChatMessagesWithPreload gets messages with all relationships preloaded
 // repositories/application_repository.go
 
-func (r *applicationRepository) GetChatMessagesWithPreload(threadID string, limit, offset int) ([]FrontendChatMessage, int64, error) {
+func (r *applicationRepository) GetChatMessagesWithPreload(ctx context.Context, threadID string, page pagination.Request) ([]FrontendChatMessage, int64, error) {
 	var messages []models.ChatMessage
+	db := r.db.WithContext(ctx)
 
 	// Get total count
 	var total int64
-	if err := r.db.Model(&models.ChatMessage{}).
+	if err := db.Model(&models.ChatMessage{}).
 		Where("thread_id = ? AND is_deleted = ?", threadID, false).
 		Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// Get paginated messages with ALL relationships preloaded including read receipts
-	if err := r.db.
+	query := db.
 		Preload("Sender").
 		Preload("Sender.Role").
 		Preload("Sender.Department").
 		Preload("Attachments").
 		Preload("Attachments.Document").
+		Preload("Attachments.Document.Category").
 		Preload("Parent").
 		Preload("Parent.Sender").
 		Preload("ReadReceipts").      // NEW: Preload read receipts
 		Preload("ReadReceipts.User"). // NEW: Preload users who read
-		Where("thread_id = ? AND is_deleted = ?", threadID, false).
-		Order("created_at DESC").
-		Limit(limit).
-		Offset(offset).
+		Preload("LinkPreviews", func(db *gorm.DB) *gorm.DB {
+			return db.Where("status = ?", models.LinkPreviewReady).Order("position ASC")
+		}).
+		Where("thread_id = ? AND is_deleted = ?", threadID, false)
+	if err := page.Window(query, "chat_messages", "created_at DESC").
 		Find(&messages).Error; err != nil {
 		return nil, 0, err
 	}
@@ -275,20 +278,25 @@ func (r *applicationRepository) GetChatMessagesWithPreload(threadID string, limi
 		}
 
 		enhancedMessages[i] = FrontendChatMessage{
-			ID:          message.ID,
-			Content:     message.Content,
-			MessageType: message.MessageType,
-			Status:      message.Status,
-			IsEdited:    message.IsEdited,
-			EditedAt:    utils.FormatTimePointer(message.EditedAt),
-			IsDeleted:   message.IsDeleted,
-			CreatedAt:   message.CreatedAt.Format(time.RFC3339),
-			Sender:      &message.Sender,
-			ParentID:    message.ParentID,
-			Parent:      message.Parent,
-			Attachments: attachments,
-			ReadCount:   message.ReadCount,
-			StarCount:   message.StarCount,
+			ID:           message.ID,
+			Content:      message.Content,
+			MessageType:  message.MessageType,
+			EventType:    message.EventType,
+			EventParams:  message.EventParams,
+			Status:       message.Status,
+			Origin:       message.Origin,
+			IsEdited:     message.IsEdited,
+			EditedAt:     utils.FormatTimePointer(message.EditedAt),
+			IsDeleted:    message.IsDeleted,
+			CreatedAt:    message.CreatedAt.Format(time.RFC3339),
+			SentAt:       message.CreatedAt,
+			Sender:       &message.Sender,
+			ParentID:     message.ParentID,
+			Parent:       message.Parent,
+			Attachments:  attachments,
+			LinkPreviews: message.LinkPreviews,
+			ReadCount:    message.ReadCount,
+			StarCount:    message.StarCount,
 			// IsStarred:        message.IsStarred,
 			ReadBy:           readBy,
 			DeliveredToCount: int(participantCount) - 1, // All participants except sender
@@ -300,9 +308,19 @@ func (r *applicationRepository) GetChatMessagesWithPreload(threadID string, limi
 
 // GetUnreadMessageCount returns count of unread messages for a user in a thread
 func (r *applicationRepository) GetUnreadMessageCount(threadID string, userID uuid.UUID) (int, error) {
+	var state models.ParticipantThreadState
+	err := r.db.Where("thread_id = ? AND user_id = ?", threadID, userID).First(&state).Error
+	if err == nil {
+		return state.UnreadCount, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+
+	// No state row yet: count messages from others without a read receipt
 	var count int64
 
-	err := r.db.Model(&models.ChatMessage{}).
+	err = r.db.Model(&models.ChatMessage{}).
 		Joins("LEFT JOIN read_receipts ON chat_messages.id = read_receipts.message_id AND read_receipts.user_id = ?", userID).
 		Where("chat_messages.thread_id = ? AND chat_messages.sender_id != ? AND chat_messages.is_deleted = ? AND read_receipts.id IS NULL",
 			threadID, userID, false).
@@ -427,6 +445,7 @@ func (r *applicationRepository) CreateReplyMessage(
 	messageType models.ChatMessageType,
 	senderID uuid.UUID,
 	files []*multipart.FileHeader,
+	categoryCodes []string,
 	applicationID *uuid.UUID,
 	createdBy string,
 ) (*EnhancedChatMessage, error) {
@@ -454,6 +473,10 @@ func (r *applicationRepository) CreateReplyMessage(
 		return nil, fmt.Errorf("failed to create reply message: %w", err)
 	}
 
+	if err := r.recordMessageMentions(tx, &message); err != nil {
+		return nil, err
+	}
+
 	config.Logger.Info("Reply message created successfully",
 		zap.String("messageID", message.ID.String()),
 		zap.String("parentMessageID", parentMessageID.String()),
@@ -463,9 +486,13 @@ func (r *applicationRepository) CreateReplyMessage(
 	var attachments []*ChatAttachmentSummary
 	var attachmentErrors []string
 
-	for _, fileHeader := range files {
+	checklistFlags := map[string]bool{}
+
+	for i, fileHeader := range files {
+		categoryCode := chatAttachmentCategory(categoryCodes, i)
+
 		documentRequest := &documents_requests.CreateDocumentRequest{
-			CategoryCode:  "CHAT_ATTACHMENT",
+			CategoryCode:  categoryCode,
 			FileName:      fileHeader.Filename,
 			CreatedBy:     createdBy,
 			ApplicationID: applicationID,
@@ -495,11 +522,7 @@ func (r *applicationRepository) CreateReplyMessage(
 			continue
 		}
 
-		chatAttachment := models.ChatAttachment{
-			ID:         uuid.New(),
-			MessageID:  message.ID,
-			DocumentID: response.Document.ID,
-		}
+		chatAttachment := newChatAttachment(message.ID, response.Document.ID, fileHeader)
 
 		if err := tx.Create(&chatAttachment).Error; err != nil {
 			errorMsg := fmt.Sprintf("failed to create chat attachment for %s: %v", fileHeader.Filename, err)
@@ -507,17 +530,13 @@ func (r *applicationRepository) CreateReplyMessage(
 			continue
 		}
 
-		fileSizeStr := response.Document.FileSize.String()
+		summary := newChatAttachmentSummary(&chatAttachment, response.Document)
+		summary.CategoryCode = categoryCode
+		attachments = append(attachments, summary)
 
-		attachments = append(attachments, &ChatAttachmentSummary{
-			ID:        chatAttachment.ID,
-			FileName:  response.Document.FileName,
-			FileSize:  fileSizeStr,
-			FileType:  string(response.Document.DocumentType),
-			MimeType:  response.Document.MimeType,
-			FilePath:  response.Document.FilePath,
-			CreatedAt: response.Document.CreatedAt.Format(time.RFC3339),
-		})
+		if flag, ok := application_services.ChecklistFlagForCategory(categoryCode); ok {
+			checklistFlags[flag] = true
+		}
 	}
 
 	// Log attachment errors but don't fail
@@ -527,6 +546,10 @@ func (r *applicationRepository) CreateReplyMessage(
 			zap.String("messageID", message.ID.String()))
 	}
 
+	if err := r.tickChecklistFromChat(tx, applicationID, checklistFlags, createdBy); err != nil {
+		return nil, err
+	}
+
 	// Load the complete message with relationships
 	var completeMessage models.ChatMessage
 	if err := tx.
@@ -535,6 +558,7 @@ func (r *applicationRepository) CreateReplyMessage(
 		Preload("Sender.Department").
 		Preload("Attachments").
 		Preload("Attachments.Document").
+		Preload("Attachments.Document.Category").
 		Preload("Parent").
 		Preload("Parent.Sender").
 		Where("id = ?", message.ID).
@@ -546,16 +570,7 @@ func (r *applicationRepository) CreateReplyMessage(
 	if len(completeMessage.Attachments) > 0 && len(attachments) == 0 {
 		attachments = make([]*ChatAttachmentSummary, len(completeMessage.Attachments))
 		for i, attachment := range completeMessage.Attachments {
-			fileSizeStr := attachment.Document.FileSize.String()
-			attachments[i] = &ChatAttachmentSummary{
-				ID:        attachment.ID,
-				FileName:  attachment.Document.FileName,
-				FileSize:  fileSizeStr,
-				FileType:  string(attachment.Document.DocumentType),
-				MimeType:  attachment.Document.MimeType,
-				FilePath:  attachment.Document.FilePath,
-				CreatedAt: attachment.Document.CreatedAt.Format(time.RFC3339),
-			}
+			attachments[i] = newChatAttachmentSummary(&attachment, &attachment.Document)
 		}
 	}
 
@@ -580,7 +595,10 @@ func (r *applicationRepository) CreateReplyMessage(
 		ID:          completeMessage.ID,
 		Content:     completeMessage.Content,
 		MessageType: completeMessage.MessageType,
+		EventType:   completeMessage.EventType,
+		EventParams: completeMessage.EventParams,
 		Status:      completeMessage.Status,
+		Origin:      completeMessage.Origin,
 		IsEdited:    completeMessage.IsEdited,
 		EditedAt:    utils.FormatTimePointer(completeMessage.EditedAt),
 		IsDeleted:   completeMessage.IsDeleted,
@@ -616,6 +634,7 @@ func (r *applicationRepository) GetMessageThread(messageID uuid.UUID) ([]*Enhanc
 		Preload("Sender.Department").
 		Preload("Attachments").
 		Preload("Attachments.Document").
+		Preload("Attachments.Document.Category").
 		Preload("Parent").
 		Preload("Parent.Sender").
 		Where("id = ? OR parent_id = ?", messageID, messageID).
@@ -633,16 +652,7 @@ func (r *applicationRepository) GetMessageThread(messageID uuid.UUID) ([]*Enhanc
 		// Build attachments
 		attachments := make([]*ChatAttachmentSummary, len(message.Attachments))
 		for j, attachment := range message.Attachments {
-			fileSizeStr := attachment.Document.FileSize.String()
-			attachments[j] = &ChatAttachmentSummary{
-				ID:        attachment.ID,
-				FileName:  attachment.Document.FileName,
-				FileSize:  fileSizeStr,
-				FileType:  string(attachment.Document.DocumentType),
-				MimeType:  attachment.Document.MimeType,
-				FilePath:  attachment.Document.FilePath,
-				CreatedAt: attachment.Document.CreatedAt.Format(time.RFC3339),
-			}
+			attachments[j] = newChatAttachmentSummary(&attachment, &attachment.Document)
 		}
 
 		// Build parent summary if exists
@@ -665,7 +675,10 @@ func (r *applicationRepository) GetMessageThread(messageID uuid.UUID) ([]*Enhanc
 			ID:          message.ID,
 			Content:     message.Content,
 			MessageType: message.MessageType,
+			EventType:   message.EventType,
+			EventParams: message.EventParams,
 			Status:      message.Status,
+			Origin:      message.Origin,
 			IsEdited:    message.IsEdited,
 			EditedAt:    utils.FormatTimePointer(message.EditedAt),
 			IsDeleted:   message.IsDeleted,
@@ -690,3 +703,78 @@ func (r *applicationRepository) GetMessageThread(messageID uuid.UUID) ([]*Enhanc
 
 	return enhancedMessages, nil
 }
+
+// newChatAttachment links a stored document to a message. Audio uploads are recorded as
+// voice notes with their length; they have already been checked against the council limits.
+func newChatAttachment(messageID, documentID uuid.UUID, fileHeader *multipart.FileHeader) models.ChatAttachment {
+	attachment := models.ChatAttachment{
+		ID:         uuid.New(),
+		MessageID:  messageID,
+		DocumentID: documentID,
+		Kind:       models.ChatAttachmentFile,
+	}
+
+	if application_services.IsVoiceNote(fileHeader) {
+		attachment.Kind = models.ChatAttachmentVoiceNote
+		duration, err := application_services.LoadVoiceNoteLimits().InspectVoiceNote(fileHeader)
+		if err != nil {
+			config.Logger.Warn("Failed to read voice note duration",
+				zap.Error(err),
+				zap.String("filename", fileHeader.Filename))
+		} else {
+			durationMs := duration.Milliseconds()
+			attachment.DurationMs = &durationMs
+		}
+	}
+
+	return attachment
+}
+
+// newChatAttachmentSummary builds the frontend view of an attachment and its document
+// chatAttachmentCategory returns the document category chosen for the i-th attachment
+func chatAttachmentCategory(categoryCodes []string, i int) string {
+	if i < len(categoryCodes) && categoryCodes[i] != "" {
+		return categoryCodes[i]
+	}
+	return application_services.DefaultChatAttachmentCategory
+}
+
+// tickChecklistFromChat marks the application's document checklist for mandatory documents that
+// arrived as chat attachments, so they count the same as documents uploaded with the application
+func (r *applicationRepository) tickChecklistFromChat(tx *gorm.DB, applicationID *uuid.UUID, checklistFlags map[string]bool, updatedBy string) error {
+	if applicationID == nil || len(checklistFlags) == 0 {
+		return nil
+	}
+	if err := r.UpdateApplicationDocumentFlags(tx, *applicationID, checklistFlags, updatedBy); err != nil {
+		return fmt.Errorf("failed to update document checklist: %w", err)
+	}
+
+	config.Logger.Info("Document checklist updated from chat attachments",
+		zap.String("applicationID", applicationID.String()),
+		zap.Any("flags", checklistFlags))
+	return nil
+}
+
+func newChatAttachmentSummary(attachment *models.ChatAttachment, document *models.Document) *ChatAttachmentSummary {
+	summary := &ChatAttachmentSummary{
+		ID:        attachment.ID,
+		Kind:      attachment.Kind,
+		FileName:  document.FileName,
+		FileSize:  document.FileSize.String(),
+		FileType:  string(document.DocumentType),
+		MimeType:  document.MimeType,
+		FilePath:  document.FilePath,
+		CreatedAt: document.CreatedAt.Format(time.RFC3339),
+	}
+	if summary.Kind == "" {
+		summary.Kind = models.ChatAttachmentFile
+	}
+	if document.Category != nil {
+		summary.CategoryCode = document.Category.Code
+	}
+	if attachment.DurationMs != nil {
+		seconds := float64(*attachment.DurationMs) / 1000
+		summary.DurationSeconds = &seconds
+	}
+	return summary
+}
@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	application_services "town-planning-backend/applications/services"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// closingEvents are the closing messages posted when an application's threads are frozen, by
+// the final outcome that froze them
+var closingEvents = map[models.ApplicationStatus]models.SystemEventType{
+	models.ApprovedApplication: models.SystemEventApplicationApproved,
+	models.RejectedApplication: models.SystemEventApplicationRejected,
+}
+
+// FreezesThreads reports whether an application reaching this status freezes its threads
+func FreezesThreads(status models.ApplicationStatus) bool {
+	_, ok := closingEvents[status]
+	return ok
+}
+
+// FreezeApplicationThreads makes every open thread of the application read-only and posts the
+// closing message with the outcome in each, sent as senderID. The messages are returned for
+// broadcasting once the transaction commits.
+func (r *applicationRepository) FreezeApplicationThreads(
+	tx *gorm.DB,
+	application *models.Application,
+	outcome models.ApplicationStatus,
+	senderID uuid.UUID,
+	now time.Time,
+) ([]models.ChatMessage, error) {
+	eventType, ok := closingEvents[outcome]
+	if !ok {
+		return nil, fmt.Errorf("status %s does not freeze chat threads", outcome)
+	}
+
+	var threads []models.ChatThread
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("application_id = ? AND is_frozen = ?", application.ID, false).
+		Find(&threads).Error; err != nil {
+		return nil, fmt.Errorf("failed to load chat threads: %w", err)
+	}
+	if len(threads) == 0 {
+		return nil, nil
+	}
+
+	threadIDs := make([]uuid.UUID, len(threads))
+	messages := make([]models.ChatMessage, 0, len(threads))
+	for i, thread := range threads {
+		threadIDs[i] = thread.ID
+
+		message, err := application_services.NewSystemMessage(eventType,
+			application_services.SystemEventParams{Description: application.PlanNumber})
+		if err != nil {
+			return nil, err
+		}
+		message.ID = uuid.New()
+		message.ThreadID = thread.ID
+		message.SenderID = senderID
+		message.CreatedAt = now
+		message.UpdatedAt = now
+		messages = append(messages, message)
+	}
+
+	if err := tx.Create(&messages).Error; err != nil {
+		return nil, fmt.Errorf("failed to post closing messages: %w", err)
+	}
+	if err := tx.Model(&models.ChatThread{}).
+		Where("id IN ?", threadIDs).
+		Updates(map[string]interface{}{
+			"is_frozen":        true,
+			"frozen_at":        now,
+			"last_activity_at": now,
+		}).Error; err != nil {
+		return nil, fmt.Errorf("failed to freeze chat threads: %w", err)
+	}
+
+	return messages, nil
+}
+
+// UnfreezeThread reopens a frozen thread for everyone and posts who reopened it and why
+func (r *applicationRepository) UnfreezeThread(tx *gorm.DB, threadID uuid.UUID, user *models.User, reason string) (*models.ChatThread, *models.ChatMessage, error) {
+	var thread models.ChatThread
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", threadID).
+		First(&thread).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New("chat thread not found")
+		}
+		return nil, nil, err
+	}
+	if !thread.IsFrozen {
+		return nil, nil, errors.New("chat thread is not frozen")
+	}
+
+	message, err := application_services.NewSystemMessage(models.SystemEventThreadUnfrozen,
+		application_services.SystemEventParams{
+			ActorName: user.FirstName + " " + user.LastName,
+			Comment:   reason,
+		})
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	message.ID = uuid.New()
+	message.ThreadID = thread.ID
+	message.SenderID = user.ID
+	message.CreatedAt = now
+	message.UpdatedAt = now
+	if err := tx.Create(&message).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to post reopening message: %w", err)
+	}
+
+	if err := tx.Model(&thread).Updates(map[string]interface{}{
+		"is_frozen":        false,
+		"frozen_at":        nil,
+		"last_activity_at": now,
+	}).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to unfreeze chat thread: %w", err)
+	}
+	thread.IsFrozen = false
+	thread.FrozenAt = nil
+
+	return &thread, &message, nil
+}
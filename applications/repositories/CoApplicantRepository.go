@@ -0,0 +1,154 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// applicationStatusesLockingCoApplicants are decided applications whose owners are on record
+var applicationStatusesLockingCoApplicants = map[models.ApplicationStatus]bool{
+	models.ApprovedApplication:           true,
+	models.RejectedApplication:           true,
+	models.ReadyForCollectionApplication: true,
+	models.CollectedApplication:          true,
+	models.ExpiredApplication:            true,
+}
+
+// SetPrimaryCoApplicant makes the applicant the application's PRIMARY owner, replacing any
+// previous primary. Used when an application is created and when it changes hands.
+func (r *applicationRepository) SetPrimaryCoApplicant(tx *gorm.DB, applicationID, applicantID uuid.UUID, createdBy string) error {
+	if err := tx.Where("application_id = ? AND (role = ? OR applicant_id = ?)", applicationID, models.CoApplicantPrimary, applicantID).
+		Delete(&models.ApplicationCoApplicant{}).Error; err != nil {
+		return fmt.Errorf("failed to clear primary applicant: %w", err)
+	}
+
+	primary := models.ApplicationCoApplicant{
+		ApplicationID: applicationID,
+		ApplicantID:   applicantID,
+		Role:          models.CoApplicantPrimary,
+		CreatedBy:     createdBy,
+	}
+	if err := tx.Create(&primary).Error; err != nil {
+		return fmt.Errorf("failed to record primary applicant: %w", err)
+	}
+	return nil
+}
+
+// lockCoApplicantApplication loads an application whose owners may still change
+func lockCoApplicantApplication(tx *gorm.DB, applicationID uuid.UUID) (*models.Application, error) {
+	var application models.Application
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", applicationID).
+		First(&application).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("application not found")
+		}
+		return nil, fmt.Errorf("failed to load application: %w", err)
+	}
+	if applicationStatusesLockingCoApplicants[application.Status] {
+		return nil, errors.New("application owners can no longer be changed")
+	}
+	return &application, nil
+}
+
+// AddCoApplicant records a joint owner of the application together with their ID document
+func (r *applicationRepository) AddCoApplicant(tx *gorm.DB, applicationID, applicantID, idDocumentID uuid.UUID, createdBy string) (*models.ApplicationCoApplicant, error) {
+	application, err := lockCoApplicantApplication(tx, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	if application.ApplicantID == applicantID {
+		return nil, errors.New("applicant is already on the application")
+	}
+
+	var applicant models.Applicant
+	if err := tx.Select("id").Where("id = ?", applicantID).First(&applicant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("applicant not found")
+		}
+		return nil, fmt.Errorf("failed to load applicant: %w", err)
+	}
+
+	var existing int64
+	if err := tx.Model(&models.ApplicationCoApplicant{}).
+		Where("application_id = ? AND applicant_id = ?", applicationID, applicantID).
+		Count(&existing).Error; err != nil {
+		return nil, fmt.Errorf("failed to check co-applicants: %w", err)
+	}
+	if existing > 0 {
+		return nil, errors.New("applicant is already on the application")
+	}
+
+	// Applications created before joint ownership have no PRIMARY row yet
+	var primaries int64
+	if err := tx.Model(&models.ApplicationCoApplicant{}).
+		Where("application_id = ? AND role = ?", applicationID, models.CoApplicantPrimary).
+		Count(&primaries).Error; err != nil {
+		return nil, fmt.Errorf("failed to check primary applicant: %w", err)
+	}
+	if primaries == 0 {
+		if err := r.SetPrimaryCoApplicant(tx, applicationID, application.ApplicantID, createdBy); err != nil {
+			return nil, err
+		}
+	}
+
+	coApplicant := models.ApplicationCoApplicant{
+		ApplicationID: applicationID,
+		ApplicantID:   applicantID,
+		Role:          models.CoApplicantCoOwner,
+		IDDocumentID:  &idDocumentID,
+		CreatedBy:     createdBy,
+	}
+	if err := tx.Create(&coApplicant).Error; err != nil {
+		return nil, fmt.Errorf("failed to add co-applicant: %w", err)
+	}
+
+	if err := tx.Preload("Applicant").Preload("IDDocument").
+		Where("application_id = ? AND applicant_id = ?", applicationID, applicantID).
+		First(&coApplicant).Error; err != nil {
+		return nil, fmt.Errorf("failed to reload co-applicant: %w", err)
+	}
+	return &coApplicant, nil
+}
+
+// RemoveCoApplicant takes a co-owner off the application. The primary applicant can only be
+// replaced through an ownership transfer.
+func (r *applicationRepository) RemoveCoApplicant(tx *gorm.DB, applicationID, applicantID uuid.UUID) error {
+	application, err := lockCoApplicantApplication(tx, applicationID)
+	if err != nil {
+		return err
+	}
+	if application.ApplicantID == applicantID {
+		return errors.New("primary applicant cannot be removed")
+	}
+
+	result := tx.Where("application_id = ? AND applicant_id = ? AND role = ?", applicationID, applicantID, models.CoApplicantCoOwner).
+		Delete(&models.ApplicationCoApplicant{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove co-applicant: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("co-applicant not found")
+	}
+	return nil
+}
+
+// GetApplicationCoApplicants lists an application's owners, primary applicant first
+func (r *applicationRepository) GetApplicationCoApplicants(applicationID uuid.UUID) ([]models.ApplicationCoApplicant, error) {
+	var coApplicants []models.ApplicationCoApplicant
+	if err := r.db.
+		Preload("Applicant").
+		Preload("IDDocument").
+		Where("application_id = ?", applicationID).
+		Order("role DESC"). // PRIMARY before CO_OWNER
+		Order("created_at ASC").
+		Find(&coApplicants).Error; err != nil {
+		return nil, err
+	}
+	return coApplicants, nil
+}
@@ -0,0 +1,457 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"town-planning-backend/db/models"
+	"town-planning-backend/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CollectionSlot is a bookable window on a department's collection calendar
+type CollectionSlot struct {
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Capacity  int       `json:"capacity"`
+	Booked    int       `json:"booked"`
+	Available int       `json:"available"`
+}
+
+// collectionStatusesHoldingSlot are the appointments that take up a slot's capacity
+var collectionStatusesHoldingSlot = []models.CollectionAppointmentStatus{
+	models.CollectionBooked,
+	models.CollectionCollected,
+}
+
+func collectionLocation() *time.Location {
+	if utils.DateLocation != nil {
+		return utils.DateLocation
+	}
+	return time.Local
+}
+
+// parseClockTime reads an HH:MM value as minutes after midnight
+func parseClockTime(value string) (int, error) {
+	parsed, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, err
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+func parseWorkingDays(value string) (map[time.Weekday]bool, error) {
+	days := map[time.Weekday]bool{}
+	for _, part := range strings.Split(value, ",") {
+		day, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || day < 0 || day > 6 {
+			return nil, errors.New("invalid working days")
+		}
+		days[time.Weekday(day)] = true
+	}
+	if len(days) == 0 {
+		return nil, errors.New("invalid working days")
+	}
+	return days, nil
+}
+
+// calendarSlotStarts lists the slot start times of a calendar on the given day
+func calendarSlotStarts(calendar *models.CollectionCalendar, day time.Time) ([]time.Time, error) {
+	workingDays, err := parseWorkingDays(calendar.WorkingDays)
+	if err != nil {
+		return nil, err
+	}
+	location := collectionLocation()
+	day = day.In(location)
+	if !workingDays[day.Weekday()] {
+		return nil, nil
+	}
+
+	opening, err := parseClockTime(calendar.OpeningTime)
+	if err != nil {
+		return nil, errors.New("invalid opening or closing time")
+	}
+	closing, err := parseClockTime(calendar.ClosingTime)
+	if err != nil {
+		return nil, errors.New("invalid opening or closing time")
+	}
+
+	midnight := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, location)
+	var starts []time.Time
+	for minute := opening; minute+calendar.SlotDurationMinutes <= closing; minute += calendar.SlotDurationMinutes {
+		starts = append(starts, midnight.Add(time.Duration(minute)*time.Minute))
+	}
+	return starts, nil
+}
+
+// UpsertCollectionCalendar creates or replaces a department's collection calendar
+func (r *applicationRepository) UpsertCollectionCalendar(tx *gorm.DB, calendar *models.CollectionCalendar, updatedBy string) (*models.CollectionCalendar, error) {
+	opening, err := parseClockTime(calendar.OpeningTime)
+	if err != nil {
+		return nil, errors.New("invalid opening or closing time")
+	}
+	closing, err := parseClockTime(calendar.ClosingTime)
+	if err != nil {
+		return nil, errors.New("invalid opening or closing time")
+	}
+	if closing <= opening {
+		return nil, errors.New("closing time must be after opening time")
+	}
+	if calendar.SlotDurationMinutes <= 0 || calendar.SlotCapacity <= 0 || calendar.BookingHorizonDays <= 0 {
+		return nil, errors.New("slot duration, capacity and booking horizon must be positive")
+	}
+	if _, err := parseWorkingDays(calendar.WorkingDays); err != nil {
+		return nil, err
+	}
+
+	var department models.Department
+	if err := tx.Where("id = ?", calendar.DepartmentID).First(&department).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("department not found")
+		}
+		return nil, err
+	}
+
+	var existing models.CollectionCalendar
+	err = tx.Where("department_id = ?", calendar.DepartmentID).First(&existing).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		calendar.CreatedBy = updatedBy
+		if err := tx.Create(calendar).Error; err != nil {
+			return nil, fmt.Errorf("failed to create collection calendar: %w", err)
+		}
+		return calendar, nil
+	}
+
+	if err := tx.Model(&existing).Updates(map[string]interface{}{
+		"opening_time":          calendar.OpeningTime,
+		"closing_time":          calendar.ClosingTime,
+		"working_days":          calendar.WorkingDays,
+		"slot_duration_minutes": calendar.SlotDurationMinutes,
+		"slot_capacity":         calendar.SlotCapacity,
+		"booking_horizon_days":  calendar.BookingHorizonDays,
+		"is_active":             calendar.IsActive,
+		"updated_by":            updatedBy,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update collection calendar: %w", err)
+	}
+
+	if err := tx.Where("id = ?", existing.ID).First(&existing).Error; err != nil {
+		return nil, err
+	}
+	return &existing, nil
+}
+
+// GetCollectionCalendars lists every department's collection calendar
+func (r *applicationRepository) GetCollectionCalendars() ([]models.CollectionCalendar, error) {
+	var calendars []models.CollectionCalendar
+	err := r.db.Preload("Department").Order("created_at ASC").Find(&calendars).Error
+	return calendars, err
+}
+
+func (r *applicationRepository) getActiveCollectionCalendar(tx *gorm.DB, departmentID uuid.UUID) (*models.CollectionCalendar, error) {
+	var calendar models.CollectionCalendar
+	err := tx.Where("department_id = ? AND is_active = ?", departmentID, true).First(&calendar).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("department has no active collection calendar")
+		}
+		return nil, err
+	}
+	return &calendar, nil
+}
+
+// countSlotBookings returns how many appointments hold each slot start between from and to
+func (r *applicationRepository) countSlotBookings(tx *gorm.DB, departmentID uuid.UUID, from, to time.Time) (map[int64]int, error) {
+	var rows []struct {
+		SlotStart time.Time
+		Count     int
+	}
+	err := tx.Model(&models.CollectionAppointment{}).
+		Select("slot_start, COUNT(*) AS count").
+		Where("department_id = ? AND status IN ? AND slot_start >= ? AND slot_start < ?",
+			departmentID, collectionStatusesHoldingSlot, from, to).
+		Group("slot_start").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[int64]int, len(rows))
+	for _, row := range rows {
+		counts[row.SlotStart.Unix()] = row.Count
+	}
+	return counts, nil
+}
+
+// GetAvailableCollectionSlots returns the future slots with spare capacity over the next days,
+// never looking past the calendar's booking horizon
+func (r *applicationRepository) GetAvailableCollectionSlots(departmentID uuid.UUID, days int) ([]CollectionSlot, error) {
+	calendar, err := r.getActiveCollectionCalendar(r.db, departmentID)
+	if err != nil {
+		return nil, err
+	}
+	if days <= 0 || days > calendar.BookingHorizonDays {
+		days = calendar.BookingHorizonDays
+	}
+
+	now := time.Now().In(collectionLocation())
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	until := today.AddDate(0, 0, days)
+
+	counts, err := r.countSlotBookings(r.db, departmentID, today, until)
+	if err != nil {
+		return nil, err
+	}
+
+	slotLength := time.Duration(calendar.SlotDurationMinutes) * time.Minute
+	slots := []CollectionSlot{}
+	for day := today; day.Before(until); day = day.AddDate(0, 0, 1) {
+		starts, err := calendarSlotStarts(calendar, day)
+		if err != nil {
+			return nil, err
+		}
+		for _, start := range starts {
+			if !start.After(now) {
+				continue
+			}
+			booked := counts[start.Unix()]
+			if booked >= calendar.SlotCapacity {
+				continue
+			}
+			slots = append(slots, CollectionSlot{
+				Start:     start,
+				End:       start.Add(slotLength),
+				Capacity:  calendar.SlotCapacity,
+				Booked:    booked,
+				Available: calendar.SlotCapacity - booked,
+			})
+		}
+	}
+	return slots, nil
+}
+
+// BookCollectionAppointment reserves a slot for collecting an application's permit
+func (r *applicationRepository) BookCollectionAppointment(
+	tx *gorm.DB,
+	applicationID uuid.UUID,
+	departmentID uuid.UUID,
+	slotStart time.Time,
+	notes *string,
+	bookedBy string,
+) (*models.CollectionAppointment, error) {
+	var application models.Application
+	if err := tx.Preload("Applicant").Where("id = ?", applicationID).First(&application).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("application not found")
+		}
+		return nil, err
+	}
+	if application.Status != models.ReadyForCollectionApplication || application.IsCollected {
+		return nil, errors.New("permit is not ready for collection")
+	}
+
+	var existing int64
+	if err := tx.Model(&models.CollectionAppointment{}).
+		Where("application_id = ? AND status = ?", applicationID, models.CollectionBooked).
+		Count(&existing).Error; err != nil {
+		return nil, err
+	}
+	if existing > 0 {
+		return nil, errors.New("application already has a booked collection appointment")
+	}
+
+	// Lock the calendar so concurrent bookings cannot overfill a slot
+	calendar, err := r.getActiveCollectionCalendar(tx.Clauses(clause.Locking{Strength: "UPDATE"}), departmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	location := collectionLocation()
+	slotStart = slotStart.In(location)
+	now := time.Now().In(location)
+	if !slotStart.After(now) {
+		return nil, errors.New("slot is in the past")
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+	if !slotStart.Before(today.AddDate(0, 0, calendar.BookingHorizonDays)) {
+		return nil, errors.New("slot is beyond the booking horizon")
+	}
+
+	starts, err := calendarSlotStarts(calendar, slotStart)
+	if err != nil {
+		return nil, err
+	}
+	valid := false
+	for _, start := range starts {
+		if start.Equal(slotStart) {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return nil, errors.New("slot is not on the department calendar")
+	}
+
+	var booked int64
+	if err := tx.Model(&models.CollectionAppointment{}).
+		Where("department_id = ? AND slot_start = ? AND status IN ?", departmentID, slotStart, collectionStatusesHoldingSlot).
+		Count(&booked).Error; err != nil {
+		return nil, err
+	}
+	if int(booked) >= calendar.SlotCapacity {
+		return nil, errors.New("slot is fully booked")
+	}
+
+	appointment := &models.CollectionAppointment{
+		ApplicationID: applicationID,
+		DepartmentID:  departmentID,
+		SlotStart:     slotStart,
+		SlotEnd:       slotStart.Add(time.Duration(calendar.SlotDurationMinutes) * time.Minute),
+		Status:        models.CollectionBooked,
+		Notes:         notes,
+		CreatedBy:     bookedBy,
+	}
+	if err := tx.Create(appointment).Error; err != nil {
+		return nil, fmt.Errorf("failed to book collection appointment: %w", err)
+	}
+
+	var department models.Department
+	if err := tx.Where("id = ?", departmentID).First(&department).Error; err != nil {
+		return nil, err
+	}
+	appointment.Application = &application
+	appointment.Department = &department
+	return appointment, nil
+}
+
+func (r *applicationRepository) getBookedCollectionAppointment(tx *gorm.DB, appointmentID uuid.UUID) (*models.CollectionAppointment, error) {
+	var appointment models.CollectionAppointment
+	if err := tx.Where("id = ?", appointmentID).First(&appointment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("appointment not found")
+		}
+		return nil, err
+	}
+	if appointment.Status != models.CollectionBooked {
+		return nil, errors.New("appointment is no longer booked")
+	}
+	return &appointment, nil
+}
+
+// CancelCollectionAppointment frees a booked slot
+func (r *applicationRepository) CancelCollectionAppointment(tx *gorm.DB, appointmentID uuid.UUID, reason *string, cancelledBy string) (*models.CollectionAppointment, error) {
+	appointment, err := r.getBookedCollectionAppointment(tx, appointmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	appointment.Status = models.CollectionCancelled
+	appointment.CancelledAt = &now
+	appointment.CancellationReason = reason
+	appointment.UpdatedBy = &cancelledBy
+	if err := tx.Save(appointment).Error; err != nil {
+		return nil, fmt.Errorf("failed to cancel appointment: %w", err)
+	}
+	return appointment, nil
+}
+
+// MarkCollectionNoShow flags a booked appointment whose slot has ended without the permit being
+// collected, so the front desk can follow up with the applicant
+func (r *applicationRepository) MarkCollectionNoShow(tx *gorm.DB, appointmentID uuid.UUID, markedBy string) (*models.CollectionAppointment, error) {
+	appointment, err := r.getBookedCollectionAppointment(tx, appointmentID)
+	if err != nil {
+		return nil, err
+	}
+	if time.Now().Before(appointment.SlotEnd) {
+		return nil, errors.New("appointment slot has not ended yet")
+	}
+
+	now := time.Now()
+	appointment.Status = models.CollectionNoShow
+	appointment.NoShowMarkedAt = &now
+	appointment.NoShowMarkedBy = &markedBy
+	appointment.UpdatedBy = &markedBy
+	if err := tx.Save(appointment).Error; err != nil {
+		return nil, fmt.Errorf("failed to mark no-show: %w", err)
+	}
+	return appointment, nil
+}
+
+// CompleteCollectionFollowUp closes the follow-up on a no-show
+func (r *applicationRepository) CompleteCollectionFollowUp(tx *gorm.DB, appointmentID uuid.UUID, notes *string, completedBy string) (*models.CollectionAppointment, error) {
+	var appointment models.CollectionAppointment
+	if err := tx.Where("id = ?", appointmentID).First(&appointment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("appointment not found")
+		}
+		return nil, err
+	}
+	if appointment.Status != models.CollectionNoShow {
+		return nil, errors.New("appointment is not a no-show")
+	}
+	if appointment.FollowUpCompleted {
+		return nil, errors.New("follow-up already completed")
+	}
+
+	appointment.FollowUpCompleted = true
+	appointment.UpdatedBy = &completedBy
+	if notes != nil {
+		appointment.Notes = notes
+	}
+	if err := tx.Save(&appointment).Error; err != nil {
+		return nil, fmt.Errorf("failed to complete follow-up: %w", err)
+	}
+	return &appointment, nil
+}
+
+// GetCollectionAppointmentsForDay lists a day's appointments for the front desk, earliest first.
+// departmentID is optional.
+func (r *applicationRepository) GetCollectionAppointmentsForDay(day time.Time, departmentID *uuid.UUID) ([]models.CollectionAppointment, error) {
+	day = day.In(collectionLocation())
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+
+	query := r.db.
+		Preload("Application.Applicant").
+		Preload("Department").
+		Where("slot_start >= ? AND slot_start < ?", start, start.AddDate(0, 0, 1)).
+		Where("status <> ?", models.CollectionCancelled)
+	if departmentID != nil {
+		query = query.Where("department_id = ?", *departmentID)
+	}
+
+	var appointments []models.CollectionAppointment
+	err := query.Order("slot_start ASC").Find(&appointments).Error
+	return appointments, err
+}
+
+// GetCollectionNoShows lists no-shows still awaiting follow-up, oldest first. departmentID is optional.
+func (r *applicationRepository) GetCollectionNoShows(departmentID *uuid.UUID) ([]models.CollectionAppointment, error) {
+	query := r.db.
+		Preload("Application.Applicant").
+		Preload("Department").
+		Where("status = ? AND follow_up_completed = ?", models.CollectionNoShow, false)
+	if departmentID != nil {
+		query = query.Where("department_id = ?", *departmentID)
+	}
+
+	var appointments []models.CollectionAppointment
+	err := query.Order("slot_start ASC").Find(&appointments).Error
+	return appointments, err
+}
+
+// MarkCollectionAppointmentConfirmed records that the booking confirmation was sent
+func (r *applicationRepository) MarkCollectionAppointmentConfirmed(appointmentID uuid.UUID) error {
+	return r.db.Model(&models.CollectionAppointment{}).
+		Where("id = ?", appointmentID).
+		Update("confirmation_sent_at", time.Now()).Error
+}
@@ -0,0 +1,214 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CommitteePackDocumentCategories are the document categories reproduced in a committee pack,
+// in the order they are printed: plans first, then receipts
+var CommitteePackDocumentCategories = []string{
+	"INITIAL_PLAN",
+	"SITE_PLAN",
+	"BUILDING_PLAN",
+	"AMENDED_PLAN",
+	"PROCESSED_RECEIPT",
+}
+
+// CommitteePackContents is everything printed in an application's committee pack
+type CommitteePackContents struct {
+	Application    models.Application
+	Assignments    []models.ApplicationGroupAssignment
+	Decisions      []models.MemberApprovalDecision
+	FinalApprovals []models.FinalApproval
+	Issues         []models.ApplicationIssue
+	KeyDocuments   []models.Document
+}
+
+// CreateCommitteePack queues a pack for an application. The caller starts generating it.
+func (r *applicationRepository) CreateCommitteePack(applicationID uuid.UUID, requestedByID uuid.UUID, createdBy string) (*models.CommitteePack, error) {
+	var count int64
+	if err := r.db.Model(&models.Application{}).Where("id = ?", applicationID).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to check application: %w", err)
+	}
+	if count == 0 {
+		return nil, errors.New("application not found")
+	}
+
+	pack := &models.CommitteePack{
+		ApplicationID: applicationID,
+		Status:        models.CommitteePackPending,
+		RequestedByID: requestedByID,
+		CreatedBy:     createdBy,
+	}
+	if err := r.db.Create(pack).Error; err != nil {
+		return nil, fmt.Errorf("failed to create committee pack: %w", err)
+	}
+	return pack, nil
+}
+
+func (r *applicationRepository) GetCommitteePack(packID uuid.UUID) (*models.CommitteePack, error) {
+	var pack models.CommitteePack
+	if err := r.db.Preload("RequestedBy").Where("id = ?", packID).First(&pack).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("committee pack not found")
+		}
+		return nil, fmt.Errorf("failed to fetch committee pack: %w", err)
+	}
+	return &pack, nil
+}
+
+// GetApplicationCommitteePacks lists the packs generated for an application, newest first
+func (r *applicationRepository) GetApplicationCommitteePacks(applicationID uuid.UUID) ([]models.CommitteePack, error) {
+	var packs []models.CommitteePack
+	if err := r.db.
+		Preload("RequestedBy").
+		Where("application_id = ?", applicationID).
+		Order("created_at DESC").
+		Find(&packs).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch committee packs: %w", err)
+	}
+	return packs, nil
+}
+
+// GetCommitteePackContents loads the application with its decision history, issues and the
+// current versions of its plans and receipts
+func (r *applicationRepository) GetCommitteePackContents(applicationID uuid.UUID) (*CommitteePackContents, error) {
+	contents := &CommitteePackContents{}
+	if err := r.db.
+		Preload("Applicant").
+		Preload("CoApplicants.Applicant").
+		Preload("Stand.StandType").
+		Preload("Tariff.DevelopmentCategory").
+		Where("id = ?", applicationID).
+		First(&contents.Application).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("application not found")
+		}
+		return nil, fmt.Errorf("failed to load application: %w", err)
+	}
+
+	if err := r.db.
+		Preload("Group").
+		Where("application_id = ?", applicationID).
+		Order("assigned_at ASC").
+		Find(&contents.Assignments).Error; err != nil {
+		return nil, fmt.Errorf("failed to load group assignments: %w", err)
+	}
+
+	if err := r.db.
+		Preload("User").
+		Preload("Assignment.Group").
+		Preload("Comments", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		Joins("JOIN application_group_assignments ON application_group_assignments.id = member_approval_decisions.assignment_id").
+		Where("application_group_assignments.application_id = ?", applicationID).
+		Order("member_approval_decisions.decided_at ASC NULLS LAST, member_approval_decisions.created_at ASC").
+		Find(&contents.Decisions).Error; err != nil {
+		return nil, fmt.Errorf("failed to load decisions: %w", err)
+	}
+
+	if err := r.db.
+		Preload("Approver").
+		Where("application_id = ?", applicationID).
+		Order("decision_at ASC").
+		Find(&contents.FinalApprovals).Error; err != nil {
+		return nil, fmt.Errorf("failed to load final approvals: %w", err)
+	}
+
+	if err := r.db.
+		Preload("RaisedByUser").
+		Preload("AssignedToUser").
+		Preload("ResolvedByUser").
+		Where("application_id = ?", applicationID).
+		Order("created_at ASC").
+		Find(&contents.Issues).Error; err != nil {
+		return nil, fmt.Errorf("failed to load issues: %w", err)
+	}
+
+	if err := r.db.
+		Preload("Category").
+		Joins("JOIN application_documents ON application_documents.document_id = documents.id").
+		Joins("JOIN document_categories ON document_categories.id = documents.category_id").
+		Where("application_documents.application_id = ?", applicationID).
+		Where("documents.is_current_version = ? AND documents.is_active = ?", true, true).
+		Where("document_categories.code IN ?", CommitteePackDocumentCategories).
+		Order("documents.created_at ASC").
+		Find(&contents.KeyDocuments).Error; err != nil {
+		return nil, fmt.Errorf("failed to load key documents: %w", err)
+	}
+	order := make(map[string]int, len(CommitteePackDocumentCategories))
+	for i, code := range CommitteePackDocumentCategories {
+		order[code] = i
+	}
+	sort.SliceStable(contents.KeyDocuments, func(i, j int) bool {
+		return order[contents.KeyDocuments[i].Category.Code] < order[contents.KeyDocuments[j].Category.Code]
+	})
+
+	return contents, nil
+}
+
+// StartCommitteePack marks a pending pack as being generated
+func (r *applicationRepository) StartCommitteePack(packID uuid.UUID) error {
+	result := r.db.Model(&models.CommitteePack{}).
+		Where("id = ? AND status = ?", packID, models.CommitteePackPending).
+		Updates(map[string]interface{}{
+			"status":     models.CommitteePackGenerating,
+			"started_at": time.Now(),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to start committee pack: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("committee pack is not pending")
+	}
+	return nil
+}
+
+// CompleteCommitteePack records the stored file of a generated pack
+func (r *applicationRepository) CompleteCommitteePack(packID uuid.UUID, fileName, filePath string, fileSize int64, pageNotes *string) error {
+	return r.db.Model(&models.CommitteePack{}).
+		Where("id = ?", packID).
+		Updates(map[string]interface{}{
+			"status":       models.CommitteePackCompleted,
+			"file_name":    fileName,
+			"file_path":    filePath,
+			"file_size":    fileSize,
+			"page_notes":   pageNotes,
+			"completed_at": time.Now(),
+		}).Error
+}
+
+// FailCommitteePack records why a pack could not be generated
+func (r *applicationRepository) FailCommitteePack(packID uuid.UUID, reason string) error {
+	return r.db.Model(&models.CommitteePack{}).
+		Where("id = ?", packID).
+		Updates(map[string]interface{}{
+			"status":       models.CommitteePackFailed,
+			"error":        reason,
+			"completed_at": time.Now(),
+		}).Error
+}
+
+// FailInterruptedCommitteePacks fails packs left pending or generating by a server restart,
+// since nothing will pick them up again
+func (r *applicationRepository) FailInterruptedCommitteePacks() (int64, error) {
+	result := r.db.Model(&models.CommitteePack{}).
+		Where("status IN ?", []models.CommitteePackStatus{models.CommitteePackPending, models.CommitteePackGenerating}).
+		Updates(map[string]interface{}{
+			"status":       models.CommitteePackFailed,
+			"error":        "pack generation was interrupted by a server restart, please request it again",
+			"completed_at": time.Now(),
+		})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to fail interrupted committee packs: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
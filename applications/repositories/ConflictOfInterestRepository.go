@@ -0,0 +1,350 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	application_services "town-planning-backend/applications/services"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ConflictDeclarationResult describes the outcome of a conflict-of-interest declaration
+type ConflictDeclarationResult struct {
+	Declaration  *models.ConflictOfInterestDeclaration
+	Recused      bool
+	BackupMember *models.ApprovalGroupMember // nil when no backup was available
+}
+
+// checkConflictOfInterest blocks a decision from a recused member, and from a member who matches
+// the applicant on the conflict heuristics but has not yet made a declaration.
+func (r *applicationRepository) checkConflictOfInterest(
+	tx *gorm.DB,
+	application *models.Application,
+	assignmentID uuid.UUID,
+	member *models.ApprovalGroupMember,
+) error {
+	declaration, err := r.getMemberConflictDeclaration(tx, assignmentID, member.ID)
+	if err != nil {
+		return err
+	}
+	if declaration != nil {
+		if declaration.Status == models.ConflictDeclared {
+			return errors.New("member is recused due to a declared conflict of interest")
+		}
+		return nil
+	}
+
+	indicators, err := r.conflictIndicatorsFor(tx, application.ApplicantID, member.UserID)
+	if err != nil {
+		return err
+	}
+	if len(indicators) > 0 {
+		config.Logger.Info("Possible conflict of interest requires declaration",
+			zap.String("applicationID", application.ID.String()),
+			zap.String("userID", member.UserID.String()),
+			zap.Any("indicators", indicators))
+		return errors.New("conflict of interest declaration required")
+	}
+	return nil
+}
+
+func (r *applicationRepository) getMemberConflictDeclaration(
+	tx *gorm.DB,
+	assignmentID uuid.UUID,
+	memberID uuid.UUID,
+) (*models.ConflictOfInterestDeclaration, error) {
+	var declaration models.ConflictOfInterestDeclaration
+	err := tx.Where("assignment_id = ? AND member_id = ?", assignmentID, memberID).
+		Order("declared_at DESC").
+		First(&declaration).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &declaration, nil
+}
+
+func (r *applicationRepository) conflictIndicatorsFor(
+	tx *gorm.DB,
+	applicantID uuid.UUID,
+	userID uuid.UUID,
+) ([]models.ConflictIndicator, error) {
+	var reviewer models.User
+	if err := tx.Where("id = ?", userID).First(&reviewer).Error; err != nil {
+		return nil, fmt.Errorf("failed to load reviewer: %w", err)
+	}
+
+	var applicant models.Applicant
+	if err := tx.Preload("OrganisationRepresentatives").
+		Where("id = ?", applicantID).
+		First(&applicant).Error; err != nil {
+		return nil, fmt.Errorf("failed to load applicant: %w", err)
+	}
+
+	return application_services.DetectConflictIndicators(reviewer, applicant), nil
+}
+
+// DeclareConflictOfInterest records a reviewer's declaration on the active assignment. Declaring a
+// conflict recuses the member (decision SKIPPED) and hands their review to the next available backup.
+// Declaring no conflict clears a heuristic match, but must then be justified in the details.
+func (r *applicationRepository) DeclareConflictOfInterest(
+	tx *gorm.DB,
+	applicationID string,
+	userID uuid.UUID,
+	hasConflict bool,
+	relationship *string,
+	details *string,
+) (*ConflictDeclarationResult, error) {
+	var application models.Application
+	err := tx.
+		Preload("GroupAssignments", "is_active = ?", true).
+		Where("id = ?", applicationID).
+		First(&application).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("application not found")
+		}
+		return nil, err
+	}
+
+	if len(application.GroupAssignments) == 0 || application.AssignedGroupID == nil {
+		return nil, errors.New("no active group assignment found for this application")
+	}
+	assignment := application.GroupAssignments[0]
+
+	var groupMember models.ApprovalGroupMember
+	err = tx.
+		Preload("User").
+		Where("approval_group_id = ? AND user_id = ? AND is_active = ?",
+			assignment.ApprovalGroupID, userID, true).
+		First(&groupMember).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not authorized to review this application")
+		}
+		return nil, err
+	}
+
+	existing, err := r.getMemberConflictDeclaration(tx, assignment.ID, groupMember.ID)
+	if err != nil {
+		return nil, err
+	}
+	// A reviewer who earlier confirmed no conflict may still declare one later, but not the reverse
+	if existing != nil && (existing.Status == models.ConflictDeclared || !hasConflict) {
+		return nil, errors.New("conflict of interest already declared")
+	}
+
+	indicators, err := r.conflictIndicatorsFor(tx, application.ApplicantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	declaration := models.ConflictOfInterestDeclaration{
+		ID:            uuid.New(),
+		ApplicationID: application.ID,
+		AssignmentID:  assignment.ID,
+		MemberID:      groupMember.ID,
+		UserID:        userID,
+		Relationship:  relationship,
+		Details:       details,
+		DeclaredAt:    now,
+	}
+	if len(indicators) > 0 {
+		labels := make([]string, 0, len(indicators))
+		for _, indicator := range indicators {
+			labels = append(labels, string(indicator))
+		}
+		joined := strings.Join(labels, ",")
+		declaration.Indicators = &joined
+	}
+
+	result := &ConflictDeclarationResult{Declaration: &declaration}
+
+	if !hasConflict {
+		if len(indicators) > 0 && (details == nil || strings.TrimSpace(*details) == "") {
+			return nil, errors.New("details are required when confirming no conflict")
+		}
+		declaration.Status = models.ConflictNoConflict
+		if err := tx.Create(&declaration).Error; err != nil {
+			return nil, fmt.Errorf("failed to record declaration: %w", err)
+		}
+		return result, nil
+	}
+
+	if relationship == nil || strings.TrimSpace(*relationship) == "" {
+		return nil, errors.New("relationship is required when declaring a conflict")
+	}
+	if groupMember.IsFinalApprover {
+		return nil, errors.New("final approver cannot be recused automatically")
+	}
+
+	// Skip the member's decision
+	var decision models.MemberApprovalDecision
+	err = tx.Where("assignment_id = ? AND member_id = ?", assignment.ID, groupMember.ID).
+		First(&decision).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		decision = models.MemberApprovalDecision{
+			ID:           uuid.New(),
+			AssignmentID: assignment.ID,
+			MemberID:     groupMember.ID,
+			UserID:       userID,
+			AssignedAs:   groupMember.Role,
+			WasAvailable: groupMember.AvailabilityStatus == models.AvailabilityAvailable,
+		}
+	} else if decision.Status == models.DecisionApproved || decision.Status == models.DecisionRejected {
+		return nil, errors.New("decision already made, revoke it before declaring a conflict")
+	}
+	decision.Status = models.DecisionSkipped
+	decision.DecidedAt = &now
+	if err := tx.Save(&decision).Error; err != nil {
+		return nil, fmt.Errorf("failed to skip member decision: %w", err)
+	}
+	declaration.SkippedDecisionID = &decision.ID
+
+	backup, backupDecision, err := r.assignConflictBackup(tx, &application, &assignment, &groupMember)
+	if err != nil {
+		return nil, err
+	}
+	if backup != nil {
+		declaration.BackupMemberID = &backup.ID
+		declaration.BackupDecisionID = &backupDecision.ID
+		result.BackupMember = backup
+	} else {
+		config.Logger.Warn("No backup member available for recused reviewer",
+			zap.String("applicationID", application.ID.String()),
+			zap.String("memberID", groupMember.ID.String()))
+	}
+
+	declaration.Status = models.ConflictDeclared
+	if err := tx.Create(&declaration).Error; err != nil {
+		return nil, fmt.Errorf("failed to record declaration: %w", err)
+	}
+	result.Recused = true
+
+	assignmentUpdates := map[string]interface{}{
+		"recused_count": gorm.Expr("recused_count + 1"),
+		"updated_by":    userID.String(),
+	}
+	if backup != nil {
+		assignmentUpdates["used_backup_members"] = true
+	}
+	if err := tx.Model(&models.ApplicationGroupAssignment{}).
+		Where("id = ?", assignment.ID).
+		Updates(assignmentUpdates).Error; err != nil {
+		return nil, fmt.Errorf("failed to update assignment: %w", err)
+	}
+
+	if err := r.updateAssignmentStatistics(tx, assignment.ID); err != nil {
+		return nil, err
+	}
+
+	// Leave a trace on the application's comment history
+	recusalComment := models.Comment{
+		ID:            uuid.New(),
+		ApplicationID: application.ID,
+		DecisionID:    &decision.ID,
+		CommentType:   models.CommentTypeGeneral,
+		Content:       fmt.Sprintf("CONFLICT OF INTEREST DECLARED (%s): reviewer recused", strings.TrimSpace(*relationship)),
+		UserID:        userID,
+		CreatedBy:     fmt.Sprintf("%s %s", groupMember.User.FirstName, groupMember.User.LastName),
+	}
+	if err := tx.Create(&recusalComment).Error; err != nil {
+		return nil, fmt.Errorf("failed to record recusal comment: %w", err)
+	}
+
+	return result, nil
+}
+
+// assignConflictBackup hands a recused member's review to the highest priority backup who is
+// available, not conflicted themselves, still undecided and not already covering someone else.
+func (r *applicationRepository) assignConflictBackup(
+	tx *gorm.DB,
+	application *models.Application,
+	assignment *models.ApplicationGroupAssignment,
+	recused *models.ApprovalGroupMember,
+) (*models.ApprovalGroupMember, *models.MemberApprovalDecision, error) {
+	var candidates []models.ApprovalGroupMember
+	err := tx.
+		Where("approval_group_id = ? AND is_active = ? AND is_final_approver = ? AND role = ? AND id <> ?",
+			assignment.ApprovalGroupID, true, false, models.MemberRoleBackup, recused.ID).
+		Where("availability_status <> ?", models.AvailabilityUnavailable).
+		Where("id NOT IN (?)", tx.Model(&models.ConflictOfInterestDeclaration{}).
+			Select("member_id").
+			Where("assignment_id = ? AND status = ?", assignment.ID, models.ConflictDeclared)).
+		Where("id NOT IN (?)", tx.Model(&models.MemberApprovalDecision{}).
+			Select("member_id").
+			Where("assignment_id = ? AND (backup_assignment = ? OR status <> ?)", assignment.ID, true, models.DecisionPending)).
+		Order("backup_priority ASC").
+		Find(&candidates).Error
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find backup members: %w", err)
+	}
+
+	for i := range candidates {
+		candidate := &candidates[i]
+
+		indicators, err := r.conflictIndicatorsFor(tx, application.ApplicantID, candidate.UserID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(indicators) > 0 {
+			continue
+		}
+
+		var decision models.MemberApprovalDecision
+		err = tx.Where("assignment_id = ? AND member_id = ?", assignment.ID, candidate.ID).
+			First(&decision).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, err
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			decision = models.MemberApprovalDecision{
+				ID:           uuid.New(),
+				AssignmentID: assignment.ID,
+				MemberID:     candidate.ID,
+				UserID:       candidate.UserID,
+				Status:       models.DecisionPending,
+				WasAvailable: candidate.AvailabilityStatus == models.AvailabilityAvailable,
+			}
+		}
+		decision.AssignedAs = models.MemberRoleBackup
+		decision.BackupAssignment = true
+		decision.OriginalMemberID = &recused.ID
+
+		if err := tx.Save(&decision).Error; err != nil {
+			return nil, nil, fmt.Errorf("failed to assign backup member: %w", err)
+		}
+
+		config.Logger.Info("Backup member assigned for recused reviewer",
+			zap.String("applicationID", application.ID.String()),
+			zap.String("recusedMemberID", recused.ID.String()),
+			zap.String("backupMemberID", candidate.ID.String()))
+		return candidate, &decision, nil
+	}
+
+	return nil, nil, nil
+}
+
+// GetConflictDeclarations lists every conflict-of-interest declaration made on an application
+func (r *applicationRepository) GetConflictDeclarations(applicationID string) ([]models.ConflictOfInterestDeclaration, error) {
+	var declarations []models.ConflictOfInterestDeclaration
+	err := r.db.
+		Preload("User").
+		Preload("BackupMember.User").
+		Where("application_id = ?", applicationID).
+		Order("declared_at ASC").
+		Find(&declarations).Error
+	return declarations, err
+}
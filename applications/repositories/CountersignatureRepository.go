@@ -0,0 +1,213 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EngineeringCertificateCategoryCode is the document category that needs a council countersignature
+const EngineeringCertificateCategoryCode = "ENGINEERING_CERTIFICATE"
+
+// applicationEngineeringCertificate loads a current engineering certificate attached to the application
+func applicationEngineeringCertificate(tx *gorm.DB, applicationID, documentID uuid.UUID) (*models.Document, error) {
+	var document models.Document
+	err := tx.
+		Joins("JOIN application_documents ON application_documents.document_id = documents.id").
+		Joins("JOIN document_categories ON document_categories.id = documents.category_id").
+		Where("application_documents.application_id = ? AND documents.id = ?", applicationID, documentID).
+		Where("document_categories.code = ? AND documents.is_current_version = ? AND documents.is_active = ?", EngineeringCertificateCategoryCode, true, true).
+		First(&document).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("certificate not found")
+		}
+		return nil, fmt.Errorf("failed to load certificate: %w", err)
+	}
+	return &document, nil
+}
+
+// RouteCertificateForCountersign sends an application's engineering certificate to a council
+// engineer for review and countersignature
+func (r *applicationRepository) RouteCertificateForCountersign(tx *gorm.DB, applicationID, documentID, engineerID uuid.UUID, notes *string, routedByID uuid.UUID) (*models.CertificateCountersignature, error) {
+	if _, err := applicationEngineeringCertificate(tx, applicationID, documentID); err != nil {
+		return nil, err
+	}
+
+	var engineer models.User
+	if err := tx.Select("id").Where("id = ? AND active = ?", engineerID, true).First(&engineer).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("engineer not found")
+		}
+		return nil, fmt.Errorf("failed to load engineer: %w", err)
+	}
+
+	var existing models.CertificateCountersignature
+	err := tx.Where("document_id = ? AND status IN ?", documentID,
+		[]models.CountersignatureStatus{models.CountersignaturePending, models.CountersignatureSigned}).
+		First(&existing).Error
+	if err == nil {
+		if existing.Status == models.CountersignatureSigned {
+			return nil, errors.New("certificate already countersigned")
+		}
+		return nil, errors.New("certificate already awaiting countersignature")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to check existing countersignature: %w", err)
+	}
+
+	countersignature := models.CertificateCountersignature{
+		ApplicationID: applicationID,
+		DocumentID:    documentID,
+		EngineerID:    engineerID,
+		Status:        models.CountersignaturePending,
+		RoutedByID:    routedByID,
+		RoutedAt:      time.Now(),
+		RoutingNotes:  notes,
+	}
+	if err := tx.Create(&countersignature).Error; err != nil {
+		return nil, fmt.Errorf("failed to route certificate: %w", err)
+	}
+
+	return &countersignature, nil
+}
+
+func (r *applicationRepository) countersignatureQuery(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Application").
+		Preload("Application.Applicant").
+		Preload("Application.CoApplicants.Applicant").
+		Preload("Document").
+		Preload("Engineer").
+		Preload("RoutedBy").
+		Preload("AnnotatedDocument")
+}
+
+// GetApplicationCountersignatures lists every countersignature request for an application, newest first
+func (r *applicationRepository) GetApplicationCountersignatures(applicationID uuid.UUID) ([]models.CertificateCountersignature, error) {
+	var countersignatures []models.CertificateCountersignature
+	if err := r.countersignatureQuery(r.db).
+		Where("application_id = ?", applicationID).
+		Order("routed_at DESC").
+		Find(&countersignatures).Error; err != nil {
+		return nil, err
+	}
+	return countersignatures, nil
+}
+
+// GetPendingCountersignatures lists certificates waiting on an engineer, oldest first
+func (r *applicationRepository) GetPendingCountersignatures(engineerID uuid.UUID) ([]models.CertificateCountersignature, error) {
+	var countersignatures []models.CertificateCountersignature
+	if err := r.countersignatureQuery(r.db).
+		Where("engineer_id = ? AND status = ?", engineerID, models.CountersignaturePending).
+		Order("routed_at ASC").
+		Find(&countersignatures).Error; err != nil {
+		return nil, err
+	}
+	return countersignatures, nil
+}
+
+// lockPendingCountersignature loads a pending request for the engineer it was routed to
+func lockPendingCountersignature(tx *gorm.DB, countersignatureID, engineerID uuid.UUID) (*models.CertificateCountersignature, error) {
+	var countersignature models.CertificateCountersignature
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", countersignatureID).
+		First(&countersignature).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("countersignature not found")
+		}
+		return nil, fmt.Errorf("failed to load countersignature: %w", err)
+	}
+	if countersignature.EngineerID != engineerID {
+		return nil, errors.New("certificate was routed to a different engineer")
+	}
+	if countersignature.Status != models.CountersignaturePending {
+		return nil, errors.New("countersignature is not pending")
+	}
+	return &countersignature, nil
+}
+
+// CountersignCertificate records the engineer's signature over the certificate as it stands now
+// and marks the application's certificate as countersigned. The annotated PDF is attached
+// afterwards with AttachCountersignatureDocument.
+func (r *applicationRepository) CountersignCertificate(tx *gorm.DB, countersignatureID, engineerID uuid.UUID, comment *string) (*models.CertificateCountersignature, error) {
+	countersignature, err := lockPendingCountersignature(tx, countersignatureID, engineerID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A certificate replaced by a newer version while waiting must be routed again
+	document, err := applicationEngineeringCertificate(tx, countersignature.ApplicationID, countersignature.DocumentID)
+	if err != nil {
+		if err.Error() == "certificate not found" {
+			return nil, errors.New("certificate changed since it was routed")
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	countersignature.Status = models.CountersignatureSigned
+	countersignature.ReviewComment = comment
+	countersignature.SignedAt = &now
+	countersignature.DocumentHash = document.FileHash
+	countersignature.SignatureHash = countersignature.ComputeSignatureHash()
+
+	if err := tx.Model(countersignature).Updates(map[string]interface{}{
+		"status":         countersignature.Status,
+		"review_comment": comment,
+		"signed_at":      now,
+		"document_hash":  countersignature.DocumentHash,
+		"signature_hash": countersignature.SignatureHash,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to record countersignature: %w", err)
+	}
+
+	if err := tx.Model(&models.Application{}).
+		Where("id = ?", countersignature.ApplicationID).
+		Updates(map[string]interface{}{
+			"engineering_certificate_countersigned": true,
+			"updated_by":                            engineerID.String(),
+		}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update application: %w", err)
+	}
+
+	var signed models.CertificateCountersignature
+	if err := r.countersignatureQuery(tx).Where("id = ?", countersignature.ID).First(&signed).Error; err != nil {
+		return nil, fmt.Errorf("failed to reload countersignature: %w", err)
+	}
+	return &signed, nil
+}
+
+// AttachCountersignatureDocument links the generated countersignature PDF to its record
+func (r *applicationRepository) AttachCountersignatureDocument(tx *gorm.DB, countersignatureID, documentID uuid.UUID) error {
+	return tx.Model(&models.CertificateCountersignature{}).
+		Where("id = ?", countersignatureID).
+		Update("annotated_document_id", documentID).Error
+}
+
+// DeclineCountersignature records the engineer refusing to countersign, with their reason
+func (r *applicationRepository) DeclineCountersignature(tx *gorm.DB, countersignatureID, engineerID uuid.UUID, reason string) (*models.CertificateCountersignature, error) {
+	countersignature, err := lockPendingCountersignature(tx, countersignatureID, engineerID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := tx.Model(countersignature).Updates(map[string]interface{}{
+		"status":         models.CountersignatureDeclined,
+		"review_comment": reason,
+		"declined_at":    now,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to decline countersignature: %w", err)
+	}
+
+	var declined models.CertificateCountersignature
+	if err := r.countersignatureQuery(tx).Where("id = ?", countersignature.ID).First(&declined).Error; err != nil {
+		return nil, fmt.Errorf("failed to reload countersignature: %w", err)
+	}
+	return &declined, nil
+}
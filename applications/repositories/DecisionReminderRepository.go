@@ -0,0 +1,242 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PendingDecisionSince is when a pending decision became the member's to make: when it was
+// assigned, or for the final approver when the group passed the application to them. Final
+// approver decisions are not due until the application is ready for final approval.
+func PendingDecisionSince(decision *models.MemberApprovalDecision) (time.Time, bool) {
+	if !decision.IsFinalApproverDecision {
+		return decision.CreatedAt, true
+	}
+	if !decision.Assignment.ReadyForFinalApproval || decision.Assignment.FinalApproverAssignedAt == nil {
+		return time.Time{}, false
+	}
+	return *decision.Assignment.FinalApproverAssignedAt, true
+}
+
+// GetPendingDecisionsForReminder returns pending decisions by active users, assigned before
+// pendingBefore, on active assignments of applications still under review
+func (r *applicationRepository) GetPendingDecisionsForReminder(pendingBefore time.Time) ([]models.MemberApprovalDecision, error) {
+	var decisions []models.MemberApprovalDecision
+	err := r.db.
+		Joins("JOIN application_group_assignments ON application_group_assignments.id = member_approval_decisions.assignment_id").
+		Joins("JOIN applications ON applications.id = application_group_assignments.application_id").
+		Joins("JOIN users ON users.id = member_approval_decisions.user_id").
+		Where("member_approval_decisions.status = ? AND member_approval_decisions.created_at < ?", models.DecisionPending, pendingBefore).
+		Where("application_group_assignments.is_active = ? AND application_group_assignments.deleted_at IS NULL", true).
+		Where("applications.status NOT IN ? AND applications.deleted_at IS NULL",
+			[]models.ApplicationStatus{models.ApprovedApplication, models.RejectedApplication}).
+		Where("users.active = ? AND users.deleted_at IS NULL", true).
+		Preload("User").
+		Preload("Assignment.Application.Applicant").
+		Preload("Assignment.Application.Stand").
+		Preload("Assignment.Application.Tariff.DevelopmentCategory").
+		Order("member_approval_decisions.created_at ASC").
+		Find(&decisions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pending decisions: %w", err)
+	}
+	return decisions, nil
+}
+
+// GetDecisionReminders returns the reminder records of the given decisions, by decision ID
+func (r *applicationRepository) GetDecisionReminders(decisionIDs []uuid.UUID) (map[uuid.UUID]models.DecisionReminder, error) {
+	reminders := make(map[uuid.UUID]models.DecisionReminder, len(decisionIDs))
+	if len(decisionIDs) == 0 {
+		return reminders, nil
+	}
+
+	var found []models.DecisionReminder
+	if err := r.db.Where("decision_id IN ?", decisionIDs).Find(&found).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch decision reminders: %w", err)
+	}
+	for _, reminder := range found {
+		reminders[reminder.DecisionID] = reminder
+	}
+	return reminders, nil
+}
+
+// RecordDecisionReminder counts a reminder sent to the approver of a pending decision
+func (r *applicationRepository) RecordDecisionReminder(decision *models.MemberApprovalDecision, remindedAt time.Time) error {
+	reminder := models.DecisionReminder{
+		DecisionID:     decision.ID,
+		AssignmentID:   decision.AssignmentID,
+		ApplicationID:  decision.Assignment.ApplicationID,
+		UserID:         decision.UserID,
+		ReminderCount:  1,
+		LastRemindedAt: &remindedAt,
+	}
+	err := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "decision_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"reminder_count":   gorm.Expr("decision_reminders.reminder_count + 1"),
+			"last_reminded_at": remindedAt,
+			"updated_at":       time.Now(),
+		}),
+	}).Create(&reminder).Error
+	if err != nil {
+		return fmt.Errorf("failed to record decision reminder: %w", err)
+	}
+	return nil
+}
+
+// GetDepartmentHead returns the active head of the user's department
+func (r *applicationRepository) GetDepartmentHead(userID uuid.UUID) (*models.User, error) {
+	var head models.User
+	err := r.db.
+		Joins("JOIN departments ON departments.head_user_id = users.id").
+		Joins("JOIN users AS staff ON staff.department_id = departments.id").
+		Where("staff.id = ? AND departments.deleted_at IS NULL", userID).
+		Where("users.active = ?", true).
+		First(&head).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("department head not found")
+		}
+		return nil, fmt.Errorf("failed to fetch department head: %w", err)
+	}
+	return &head, nil
+}
+
+// OpenDecisionEscalation finds the open escalation issue on the decision's assignment, or
+// raises a COLLABORATIVE one with a group chat thread, and makes sure the department head is a
+// participant. New issues are raised by the group's final approver, or by the overdue member
+// when the group has none. The ISSUE_CREATED message is returned only for a new issue.
+func (r *applicationRepository) OpenDecisionEscalation(
+	tx *gorm.DB,
+	decision *models.MemberApprovalDecision,
+	head *models.User,
+	summary string,
+) (*models.ApplicationIssue, *models.ChatThread, *models.ChatMessage, error) {
+	var issue models.ApplicationIssue
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("assignment_id = ? AND category = ? AND is_resolved = ? AND chat_thread_id IS NOT NULL",
+			decision.AssignmentID, models.IssueCategoryDecisionEscalation, false).
+		Order("created_at DESC").
+		First(&issue).Error
+	if err == nil {
+		var thread models.ChatThread
+		if err := tx.Where("id = ?", *issue.ChatThreadID).First(&thread).Error; err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to fetch escalation thread: %w", err)
+		}
+		if err := r.addEscalationParticipant(tx, thread.ID, head.ID); err != nil {
+			return nil, nil, nil, err
+		}
+		return &issue, &thread, nil, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, nil, fmt.Errorf("failed to look up escalation issue: %w", err)
+	}
+
+	var application models.Application
+	if err := tx.
+		Preload("ApprovalGroup.Members", "is_active = ?", true).
+		Where("id = ?", decision.Assignment.ApplicationID).
+		First(&application).Error; err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to fetch application: %w", err)
+	}
+	if application.ApprovalGroup == nil {
+		return nil, nil, nil, errors.New("application has no approval group")
+	}
+
+	var assignment models.ApplicationGroupAssignment
+	if err := tx.Preload("Group").Where("id = ?", decision.AssignmentID).First(&assignment).Error; err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to fetch group assignment: %w", err)
+	}
+
+	raisedBy := application.ApprovalGroup.GetFinalApprover()
+	if raisedBy == nil {
+		var member models.ApprovalGroupMember
+		if err := tx.Where("id = ?", decision.MemberID).First(&member).Error; err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to fetch group member: %w", err)
+		}
+		raisedBy = &member
+	}
+
+	category := models.IssueCategoryDecisionEscalation
+	title := fmt.Sprintf("Overdue decision on %s", application.PlanNumber)
+	issue = models.ApplicationIssue{
+		ID:                    uuid.New(),
+		ApplicationID:         application.ID,
+		AssignmentID:          assignment.ID,
+		RaisedByGroupMemberID: raisedBy.ID,
+		RaisedByUserID:        raisedBy.UserID,
+		AssignmentType:        models.IssueAssignment_COLLABORATIVE,
+		Title:                 title,
+		Description:           summary,
+		Priority:              "HIGH",
+		Category:              &category,
+	}
+	if err := tx.Create(&issue).Error; err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create escalation issue: %w", err)
+	}
+
+	thread, err := r.createChatThreadForIssue(tx, &application, &assignment, raisedBy, &issue,
+		title, summary, models.IssueAssignment_COLLABORATIVE, nil, nil)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create escalation thread: %w", err)
+	}
+
+	issue.ChatThreadID = &thread.ID
+	if err := tx.Model(&issue).Update("chat_thread_id", thread.ID).Error; err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to link escalation thread: %w", err)
+	}
+
+	initialMessage, err := r.createInitialChatMessageWithAttachments(tx, thread, raisedBy, summary, nil)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create escalation message: %w", err)
+	}
+
+	if err := r.addEscalationParticipant(tx, thread.ID, head.ID); err != nil {
+		return nil, nil, nil, err
+	}
+
+	// The escalation is an issue like any other, so final approval waits for it to be resolved
+	assignment.IssuesRaised++
+	if assignment.ReadyForFinalApproval && !assignment.IsReadyForFinalApproval() {
+		assignment.ReadyForFinalApproval = false
+	}
+	if err := tx.Model(&assignment).Updates(map[string]interface{}{
+		"issues_raised":            assignment.IssuesRaised,
+		"ready_for_final_approval": assignment.ReadyForFinalApproval,
+	}).Error; err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to update assignment issue count: %w", err)
+	}
+
+	return &issue, thread, initialMessage, nil
+}
+
+// addEscalationParticipant adds the department head to an escalation thread, leaving them be
+// when they are already taking part
+func (r *applicationRepository) addEscalationParticipant(tx *gorm.DB, threadID uuid.UUID, headID uuid.UUID) error {
+	_, err := r.AddParticipantToThread(tx, threadID, headID, models.ParticipantRoleAdmin, "system", true, false, false)
+	if err != nil && err.Error() != "user is already an active participant" {
+		return fmt.Errorf("failed to add department head to escalation thread: %w", err)
+	}
+	return nil
+}
+
+// MarkDecisionEscalated records that a decision was escalated to the department head
+func (r *applicationRepository) MarkDecisionEscalated(tx *gorm.DB, decisionID uuid.UUID, headID uuid.UUID, issueID uuid.UUID) error {
+	now := time.Now()
+	if err := tx.Model(&models.DecisionReminder{}).
+		Where("decision_id = ?", decisionID).
+		Updates(map[string]interface{}{
+			"escalated_at":         now,
+			"escalated_to_user_id": headID,
+			"escalation_issue_id":  issueID,
+		}).Error; err != nil {
+		return fmt.Errorf("failed to mark decision escalated: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+	"town-planning-backend/db/models"
+	"town-planning-backend/settings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// deedsVerificationOrder puts an application's current verification first
+const deedsVerificationOrder = "checked_at DESC, created_at DESC"
+
+// RecordDeedsVerification stores a deeds registry check against the application
+func (r *applicationRepository) RecordDeedsVerification(tx *gorm.DB, applicationID uuid.UUID, verification *models.DeedsVerification, createdBy string) (*models.DeedsVerification, error) {
+	verification.ID = uuid.Nil
+	verification.ApplicationID = applicationID
+	verification.CreatedBy = createdBy
+	if err := tx.Create(verification).Error; err != nil {
+		return nil, fmt.Errorf("failed to record deeds verification: %w", err)
+	}
+	return verification, nil
+}
+
+// GetApplicationDeedsVerifications lists every deeds registry check for an application, newest first
+func (r *applicationRepository) GetApplicationDeedsVerifications(applicationID uuid.UUID) ([]models.DeedsVerification, error) {
+	var verifications []models.DeedsVerification
+	if err := r.db.
+		Where("application_id = ?", applicationID).
+		Order(deedsVerificationOrder).
+		Find(&verifications).Error; err != nil {
+		return nil, err
+	}
+	return verifications, nil
+}
+
+// deedsVerificationMaxAge is how long an ownership check stays current
+func deedsVerificationMaxAge() time.Duration {
+	return time.Duration(settings.Int(settings.DeedsVerificationMaxAgeDays)) * 24 * time.Hour
+}
+
+// deedsVerificationWarning tells approvers why the latest check does not confirm ownership, or
+// returns nil when it does
+func deedsVerificationWarning(verification *models.DeedsVerification, now time.Time) *string {
+	var warning string
+	switch {
+	case verification.Status != models.DeedsVerified && verification.Message != nil:
+		warning = fmt.Sprintf("Stand ownership not verified: %s", *verification.Message)
+	case verification.Status != models.DeedsVerified:
+		warning = fmt.Sprintf("Stand ownership not verified: %s", verification.Status)
+	case verification.IsStale(deedsVerificationMaxAge(), now):
+		warning = fmt.Sprintf("Stand ownership was last verified on %s and may have changed since", verification.CheckedAt.Format("02 Jan 2006"))
+	default:
+		return nil
+	}
+	return &warning
+}
@@ -0,0 +1,236 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	application_services "town-planning-backend/applications/services"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FinanceOfficerPermission is held by the officers alerted to duplicate payment captures
+const FinanceOfficerPermission = "payment.reconcile"
+
+// receiptNumberIndex is the unique index a racing capture of the same receipt number trips
+const receiptNumberIndex = "idx_payments_receipt_number"
+
+// DuplicatePaymentError is returned when a payment being captured matches one already on record.
+// It carries both payments so the capture can be reported to the finance officers.
+type DuplicatePaymentError struct {
+	Reason      models.DuplicatePaymentReason
+	Existing    models.Payment
+	Attempt     models.Payment
+	ApplicantID *uuid.UUID
+}
+
+func (e *DuplicatePaymentError) Error() string {
+	if e.Reason == models.DuplicateReceiptNumber {
+		return "duplicate payment: receipt number has already been captured"
+	}
+	return "duplicate payment: the same amount was captured for this applicant moments ago"
+}
+
+// CheckDuplicatePayment looks for a payment already on record that the payment about to be
+// captured duplicates: one with the same receipt number, or one of the same amount for the same
+// applicant within the duplicate window. excludePaymentID is the payment being updated, if any.
+// Amount matches a finance officer has dismissed for the application are let through.
+//
+// The applicant row is locked for the rest of tx so concurrent captures for the same applicant
+// are checked one after the other and the second sees the first.
+func (r *applicationRepository) CheckDuplicatePayment(tx *gorm.DB, payment *models.Payment, excludePaymentID uuid.UUID) error {
+	var applicantID *uuid.UUID
+	if payment.ApplicationID != nil {
+		var application models.Application
+		if err := tx.Select("id", "applicant_id").Where("id = ?", *payment.ApplicationID).First(&application).Error; err != nil {
+			return fmt.Errorf("failed to load application: %w", err)
+		}
+		applicantID = &application.ApplicantID
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			Where("id = ?", application.ApplicantID).
+			First(&models.Applicant{}).Error; err != nil {
+			return fmt.Errorf("failed to lock applicant: %w", err)
+		}
+	}
+
+	if payment.ReceiptNumber != "" {
+		var existing models.Payment
+		err := tx.Where("receipt_number = ? AND id != ?", payment.ReceiptNumber, excludePaymentID).First(&existing).Error
+		if err == nil {
+			return &DuplicatePaymentError{
+				Reason:      models.DuplicateReceiptNumber,
+				Existing:    existing,
+				Attempt:     *payment,
+				ApplicantID: applicantID,
+			}
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to check receipt number: %w", err)
+		}
+	}
+
+	if applicantID == nil {
+		return nil
+	}
+
+	var existing models.Payment
+	err := tx.Model(&models.Payment{}).
+		Joins("JOIN applications ON applications.id = payments.application_id").
+		Where("applications.applicant_id = ? AND payments.id != ?", *applicantID, excludePaymentID).
+		Where("payments.amount = ? AND payments.is_reversal = ?", payment.Amount, false).
+		Where("payments.payment_status NOT IN ?", []models.PaymentStatus{models.CancelledPayment, models.RefundedPayment}).
+		Where("payments.created_at >= ?", time.Now().Add(-application_services.DuplicatePaymentWindow())).
+		Where("NOT EXISTS (?)", tx.Model(&models.DuplicatePaymentAlert{}).
+			Select("1").
+			Where("duplicate_payment_alerts.existing_payment_id = payments.id").
+			Where("duplicate_payment_alerts.application_id = ? AND duplicate_payment_alerts.status = ?", *payment.ApplicationID, models.DuplicateAlertDismissed)).
+		Order("payments.created_at DESC").
+		First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check for duplicate payments: %w", err)
+	}
+	return &DuplicatePaymentError{
+		Reason:      models.DuplicateAmountForApplicant,
+		Existing:    existing,
+		Attempt:     *payment,
+		ApplicantID: applicantID,
+	}
+}
+
+// DuplicateReceiptError reports a payment write that failed on the receipt number unique index,
+// because a concurrent capture of the same receipt committed first, as the DuplicatePaymentError
+// CheckDuplicatePayment would have returned. Any other error is returned unchanged.
+func (r *applicationRepository) DuplicateReceiptError(payment *models.Payment, err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != "23505" || pgErr.ConstraintName != receiptNumberIndex {
+		return err
+	}
+
+	// The capture's transaction is aborted, so the payment that won is read outside it
+	var existing models.Payment
+	if lookupErr := r.db.Where("receipt_number = ? AND id != ?", payment.ReceiptNumber, payment.ID).First(&existing).Error; lookupErr != nil {
+		return err
+	}
+	duplicate := &DuplicatePaymentError{
+		Reason:   models.DuplicateReceiptNumber,
+		Existing: existing,
+		Attempt:  *payment,
+	}
+	if payment.ApplicationID != nil {
+		var application models.Application
+		if r.db.Select("id", "applicant_id").Where("id = ?", *payment.ApplicationID).First(&application).Error == nil {
+			duplicate.ApplicantID = &application.ApplicantID
+		}
+	}
+	return duplicate
+}
+
+// RecordDuplicatePaymentAlert stores a blocked capture. It is written outside the capture's
+// transaction, which has been rolled back.
+func (r *applicationRepository) RecordDuplicatePaymentAlert(duplicate *DuplicatePaymentError) (*models.DuplicatePaymentAlert, error) {
+	attempt := duplicate.Attempt
+	alert := models.DuplicatePaymentAlert{
+		Reason:                     duplicate.Reason,
+		Status:                     models.DuplicateAlertOpen,
+		ExistingPaymentID:          duplicate.Existing.ID,
+		ApplicationID:              attempt.ApplicationID,
+		ApplicantID:                duplicate.ApplicantID,
+		AttemptedPaymentFor:        attempt.PaymentFor,
+		AttemptedAmount:            attempt.Amount,
+		AttemptedReceiptNumber:     attempt.ReceiptNumber,
+		AttemptedPaymentMethod:     attempt.PaymentMethod,
+		AttemptedExternalReference: attempt.ExternalReference,
+		AttemptedPaymentDate:       attempt.PaymentDate,
+		AttemptedBy:                attempt.CreatedBy,
+		AttemptedAt:                time.Now(),
+	}
+	if err := r.db.Create(&alert).Error; err != nil {
+		return nil, fmt.Errorf("failed to record duplicate payment alert: %w", err)
+	}
+	alert.ExistingPayment = &duplicate.Existing
+	return &alert, nil
+}
+
+// RecordDuplicatePaymentNotifications counts the finance officers alerted to a blocked capture
+func (r *applicationRepository) RecordDuplicatePaymentNotifications(alertID uuid.UUID, notified int) error {
+	return r.db.Model(&models.DuplicatePaymentAlert{}).
+		Where("id = ?", alertID).
+		Update("finance_officers_notified", notified).Error
+}
+
+// GetFinanceOfficers returns the active users whose role may resolve duplicate payments
+func (r *applicationRepository) GetFinanceOfficers() ([]models.User, error) {
+	var officers []models.User
+	err := r.db.
+		Joins("JOIN role_permissions ON role_permissions.role_id = users.role_id").
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
+		Where("users.active = ? AND users.is_suspended = ?", true, false).
+		Where("permissions.name = ? AND permissions.is_active = ? AND permissions.deleted_at IS NULL", FinanceOfficerPermission, true).
+		Find(&officers).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch finance officers: %w", err)
+	}
+	return officers, nil
+}
+
+// GetDuplicatePaymentAlerts lists blocked captures, newest first, optionally by status
+func (r *applicationRepository) GetDuplicatePaymentAlerts(status string) ([]models.DuplicatePaymentAlert, error) {
+	var alerts []models.DuplicatePaymentAlert
+	query := r.db.
+		Preload("ExistingPayment").
+		Preload("Application").
+		Preload("ResolvedBy")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Order("attempted_at DESC").Find(&alerts).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch duplicate payment alerts: %w", err)
+	}
+	return alerts, nil
+}
+
+// ResolveDuplicatePaymentAlert closes an open alert as a confirmed duplicate or, for captures
+// that were genuinely separate payments, as not a duplicate
+func (r *applicationRepository) ResolveDuplicatePaymentAlert(
+	tx *gorm.DB,
+	alertID uuid.UUID,
+	resolverID uuid.UUID,
+	status models.DuplicatePaymentAlertStatus,
+	notes *string,
+) (*models.DuplicatePaymentAlert, error) {
+	if status != models.DuplicateAlertConfirmed && status != models.DuplicateAlertDismissed {
+		return nil, errors.New("invalid resolution")
+	}
+
+	var alert models.DuplicatePaymentAlert
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", alertID).
+		First(&alert).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("duplicate payment alert not found")
+		}
+		return nil, err
+	}
+	if alert.Status != models.DuplicateAlertOpen {
+		return nil, errors.New("duplicate payment alert has already been resolved")
+	}
+
+	now := time.Now()
+	alert.Status = status
+	alert.ResolvedByID = &resolverID
+	alert.ResolvedAt = &now
+	alert.ResolutionNotes = notes
+	if err := tx.Save(&alert).Error; err != nil {
+		return nil, fmt.Errorf("failed to resolve duplicate payment alert: %w", err)
+	}
+	return &alert, nil
+}
@@ -3,11 +3,14 @@ package repositories
 
 import (
 	"fmt"
+	"strings"
 	"time"
 	"town-planning-backend/db/models"
 	"town-planning-backend/utils"
 
 	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
 type WorkflowStatus struct {
@@ -21,6 +24,10 @@ type WorkflowStatus struct {
 	PendingApprovers    int        `json:"pending_approvers"`  // ADD THIS
 	ProgressPercentage  int        `json:"progress_percentage"`
 	ShouldAutoReject    bool       `json:"should_auto_reject"` // ADD THIS
+	TotalWeight         int        `json:"total_weight"`
+	ApprovedWeight      int        `json:"approved_weight"`
+	RejectedWeight      int        `json:"rejected_weight"`
+	PendingWeight       int        `json:"pending_weight"`
 }
 
 type ChatParticipantSummary struct {
@@ -83,10 +90,12 @@ type EnhancedApplicationView struct {
 	RingBeamCertificateProvided              bool `json:"ring_beam_certificate_provided"`
 
 	// Core relationships
-	Applicant     *EnhancedApplicantSummary `json:"applicant"`
-	Tariff        *EnhancedTariffSummary    `json:"tariff"`
-	VATRate       *VATRateSummary           `json:"vat_rate"`
-	ApprovalGroup *EnhancedApprovalGroup    `json:"approval_group"`
+	Applicant      *EnhancedApplicantSummary   `json:"applicant"`
+	CoApplicants   []*EnhancedApplicantSummary `json:"co_applicants"`
+	ApplicantNames []string                    `json:"applicant_names"`
+	Tariff         *EnhancedTariffSummary      `json:"tariff"`
+	VATRate        *VATRateSummary             `json:"vat_rate"`
+	ApprovalGroup  *EnhancedApprovalGroup      `json:"approval_group"`
 
 	// Assignment and decisions
 	GroupAssignments []*EnhancedGroupAssignment `json:"group_assignments"`
@@ -102,6 +111,16 @@ type EnhancedApplicationView struct {
 	// Payment
 	Payment *PaymentSummary `json:"payment"`
 
+	// Latest rates clearance check of the stand, nil when none was made
+	RatesClearance *RatesClearanceSummary `json:"rates_clearance"`
+
+	// Latest deeds registry ownership check, nil when none was made
+	DeedsVerification *DeedsVerificationSummary `json:"deeds_verification"`
+
+	// Latest risk assessment, nil when the application has not been scored
+	RiskScore *int              `json:"risk_score"`
+	RiskLevel *models.RiskLevel `json:"risk_level"`
+
 	// Audit
 	CreatedBy string  `json:"created_by"`
 	UpdatedBy *string `json:"updated_by"`
@@ -123,15 +142,20 @@ type FrontendChatMessage struct {
 	ID               uuid.UUID                `json:"id"`
 	Content          string                   `json:"content"`
 	MessageType      models.ChatMessageType   `json:"message_type"`
+	EventType        *models.SystemEventType  `json:"event_type,omitempty"`
+	EventParams      datatypes.JSON           `json:"event_params,omitempty"`
 	Status           models.MessageStatus     `json:"status"`
+	Origin           models.MessageOrigin     `json:"origin"`
 	IsEdited         bool                     `json:"is_edited"`
 	EditedAt         *string                  `json:"edited_at,omitempty"`
 	IsDeleted        bool                     `json:"is_deleted"`
 	CreatedAt        string                   `json:"created_at"`
+	SentAt           time.Time                `json:"-"` // Full precision CreatedAt for cursors
 	Sender           *models.User             `json:"sender"`
 	ParentID         *uuid.UUID               `json:"parent_id,omitempty"`
 	Parent           *models.ChatMessage      `json:"parent,omitempty"`
 	Attachments      []*models.ChatAttachment `json:"attachments,omitempty"`
+	LinkPreviews     []models.LinkPreview     `json:"link_previews,omitempty"`
 	ReadCount        int                      `json:"read_count,omitempty"`
 	StarCount        int                      `json:"star_count,omitempty"`
 	IsStarred        bool                     `json:"is_starred,omitempty"`
@@ -167,6 +191,10 @@ type EnhancedApplicantSummary struct {
 	City           string    `json:"city"`
 	Status         string    `json:"status"`
 	Debtor         bool      `json:"debtor"`
+
+	// Ownership of the application; the ID document is required for co-owners
+	Role         models.CoApplicantRole `json:"role"`
+	IDDocumentID *uuid.UUID             `json:"id_document_id,omitempty"`
 }
 
 // Enhanced tariff summary
@@ -188,15 +216,16 @@ type VATRateSummary struct {
 
 // Enhanced approval group
 type EnhancedApprovalGroup struct {
-	ID                   uuid.UUID                `json:"id"`
-	Name                 string                   `json:"name"`
-	Description          string                   `json:"description"`
-	Type                 models.ApprovalGroupType `json:"type"`
-	IsActive             bool                     `json:"is_active"`
-	RequiresAllApprovals bool                     `json:"requires_all_approvals"`
-	MinimumApprovals     int                      `json:"minimum_approvals"`
-	AutoAssignBackups    bool                     `json:"auto_assign_backups"`
-	Members              []*EnhancedGroupMember   `json:"members"`
+	ID                   uuid.UUID                    `json:"id"`
+	Name                 string                       `json:"name"`
+	Description          string                       `json:"description"`
+	Type                 models.ApprovalGroupType     `json:"type"`
+	IsActive             bool                         `json:"is_active"`
+	RequiresAllApprovals bool                         `json:"requires_all_approvals"`
+	MinimumApprovals     int                          `json:"minimum_approvals"`
+	AutoAssignBackups    bool                         `json:"auto_assign_backups"`
+	Members              []*EnhancedGroupMember       `json:"members"`
+	Checklist            []models.ReviewChecklistItem `json:"checklist"` // Items each member must tick
 }
 
 // Enhanced group member
@@ -213,6 +242,7 @@ type EnhancedGroupMember struct {
 	CanApprove         bool                      `json:"can_approve"`
 	CanReject          bool                      `json:"can_reject"`
 	IsFinalApprover    bool                      `json:"is_final_approver"`
+	DecisionWeight     int                       `json:"decision_weight"`
 	AvailabilityStatus models.AvailabilityStatus `json:"availability_status"`
 	Department         string                    `json:"department"`
 	RoleName           string                    `json:"role_name"` // From user's role
@@ -229,6 +259,10 @@ type EnhancedGroupAssignment struct {
 	ApprovedCount           int                 `json:"approved_count"`
 	RejectedCount           int                 `json:"rejected_count"`
 	PendingCount            int                 `json:"pending_count"`
+	TotalWeight             int                 `json:"total_weight"`
+	ApprovedWeight          int                 `json:"approved_weight"`
+	RejectedWeight          int                 `json:"rejected_weight"`
+	PendingWeight           int                 `json:"pending_weight"`
 	IssuesRaised            int                 `json:"issues_raised"`
 	IssuesResolved          int                 `json:"issues_resolved"`
 	ReadyForFinalApproval   bool                `json:"ready_for_final_approval"`
@@ -252,22 +286,24 @@ type EnhancedDecision struct {
 	AssignedAs              models.MemberRole           `json:"assigned_as"`
 	IsFinalApproverDecision bool                        `json:"is_final_approver_decision"`
 	WasAvailable            bool                        `json:"was_available"`
+	Checklist               *ChecklistCompletion        `json:"checklist"` // Nil until the member approves
 }
 
 // Enhanced issue summary
 type EnhancedIssueSummary struct {
-	ID             uuid.UUID                  `json:"id"`
-	Title          string                     `json:"title"`
-	Description    string                     `json:"description"`
-	Priority       string                     `json:"priority"`
-	Category       *string                    `json:"category"`
-	IsResolved     bool                       `json:"is_resolved"`
-	ResolvedAt     *string                    `json:"resolved_at"`
-	AssignmentType models.IssueAssignmentType `json:"assignment_type"`
-	CreatedAt      string                     `json:"created_at"`
-	RaisedByUser   *UserSummary               `json:"raised_by_user"`
-	AssignedToUser *UserSummary               `json:"assigned_to_user,omitempty"`
-	ChatThreadID   *uuid.UUID                 `json:"chat_thread_id"`
+	ID              uuid.UUID                  `json:"id"`
+	Title           string                     `json:"title"`
+	Description     string                     `json:"description"`
+	Priority        string                     `json:"priority"`
+	Category        *string                    `json:"category"`
+	IsResolved      bool                       `json:"is_resolved"`
+	ResolvedAt      *string                    `json:"resolved_at"`
+	AssignmentType  models.IssueAssignmentType `json:"assignment_type"`
+	CreatedAt       string                     `json:"created_at"`
+	RaisedByUser    *UserSummary               `json:"raised_by_user"`
+	AssignedToUser  *UserSummary               `json:"assigned_to_user,omitempty"`
+	ChatThreadID    *uuid.UUID                 `json:"chat_thread_id"`
+	SourceMessageID *uuid.UUID                 `json:"source_message_id,omitempty"`
 }
 
 // Enhanced comment summary
@@ -304,6 +340,34 @@ type PaymentSummary struct {
 	PaymentDate       string    `json:"payment_date"`
 }
 
+// Rates clearance summary
+type RatesClearanceSummary struct {
+	ID                 uuid.UUID                   `json:"id"`
+	Status             models.RatesClearanceStatus `json:"status"`
+	Cleared            bool                        `json:"cleared"`
+	AccountNumber      *string                     `json:"account_number"`
+	OutstandingBalance *string                     `json:"outstanding_balance"`
+	Currency           *string                     `json:"currency"`
+	BillingReference   *string                     `json:"billing_reference"`
+	Message            *string                     `json:"message"`
+	CheckedAt          string                      `json:"checked_at"`
+	OverrideReason     *string                     `json:"override_reason,omitempty"`
+	OverriddenBy       *string                     `json:"overridden_by,omitempty"`
+}
+
+// Deeds verification summary. Warning is set when approvers should not rely on the check.
+type DeedsVerificationSummary struct {
+	ID                uuid.UUID                      `json:"id"`
+	Status            models.DeedsVerificationStatus `json:"status"`
+	Verified          bool                           `json:"verified"`
+	Stale             bool                           `json:"stale"`
+	CertificateNumber *string                        `json:"certificate_number"`
+	RegisteredOwners  *string                        `json:"registered_owners"`
+	Message           *string                        `json:"message"`
+	CheckedAt         string                         `json:"checked_at"`
+	Warning           *string                        `json:"warning"`
+}
+
 // Enhanced chat thread with pagination support
 type EnhancedChatThread struct {
 	ID           uuid.UUID                 `json:"id"`
@@ -332,7 +396,10 @@ type EnhancedChatMessage struct {
 	ID          uuid.UUID                `json:"id"`
 	Content     string                   `json:"content"`
 	MessageType models.ChatMessageType   `json:"message_type"`
+	EventType   *models.SystemEventType  `json:"event_type,omitempty"`
+	EventParams datatypes.JSON           `json:"event_params,omitempty"`
 	Status      models.MessageStatus     `json:"status"`
+	Origin      models.MessageOrigin     `json:"origin"`
 	IsEdited    bool                     `json:"is_edited"`
 	EditedAt    *string                  `json:"edited_at,omitempty"`
 	IsDeleted   bool                     `json:"is_deleted"`
@@ -356,13 +423,16 @@ type EnhancedChatMessage struct {
 
 // Chat attachment summary
 type ChatAttachmentSummary struct {
-	ID        uuid.UUID `json:"id"`
-	FileName  string    `json:"file_name"`
-	FileSize  string    `json:"file_size"`
-	FileType  string    `json:"file_type"`
-	MimeType  string    `json:"mime_type"`
-	FilePath  string    `json:"file_path"`
-	CreatedAt string    `json:"created_at"`
+	ID              uuid.UUID                 `json:"id"`
+	Kind            models.ChatAttachmentKind `json:"kind"`
+	FileName        string                    `json:"file_name"`
+	FileSize        string                    `json:"file_size"`
+	FileType        string                    `json:"file_type"`
+	MimeType        string                    `json:"mime_type"`
+	FilePath        string                    `json:"file_path"`
+	CategoryCode    string                    `json:"category_code,omitempty"`
+	DurationSeconds *float64                  `json:"duration_seconds,omitempty"` // Voice notes only
+	CreatedAt       string                    `json:"created_at"`
 }
 
 // User summary (reusable)
@@ -384,6 +454,10 @@ func (r *applicationRepository) GetEnhancedApplicationApprovalData(applicationID
 	// Step 1: Get application with all necessary preloads
 	if err := r.db.
 		Preload("Applicant").
+		Preload("CoApplicants", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		Preload("CoApplicants.Applicant").
 		Preload("Tariff.DevelopmentCategory").
 		Preload("VATRate").
 		Preload("ApprovalGroup").
@@ -393,6 +467,7 @@ func (r *applicationRepository) GetEnhancedApplicationApprovalData(applicationID
 		Preload("GroupAssignments.Decisions.User").
 		Preload("GroupAssignments.Decisions.User.Role").
 		Preload("GroupAssignments.Decisions.User.Department").
+		Preload("GroupAssignments.Decisions.ChecklistItems").
 		Preload("Issues").
 		Preload("Issues.RaisedByUser").
 		Preload("Issues.RaisedByUser.Role").
@@ -404,16 +479,28 @@ func (r *applicationRepository) GetEnhancedApplicationApprovalData(applicationID
 		Preload("Comments.User").
 		Preload("Comments.User.Role").
 		Preload("Comments.User.Department").
+		Preload("SecondOpinions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("requested_at DESC")
+		}).
+		Preload("SecondOpinions.Reviewer").
 		Preload("ApplicationDocuments.Document").
 		Preload("Payment").
 		Preload("FinalApprover").
+		Preload("RatesClearances", func(db *gorm.DB) *gorm.DB {
+			return db.Order(ratesClearanceOrder).Limit(1)
+		}).
+		Preload("RatesClearances.OverriddenBy").
+		Preload("DeedsVerifications", func(db *gorm.DB) *gorm.DB {
+			return db.Order(deedsVerificationOrder).Limit(1)
+		}).
 		Where("id = ?", applicationID).
 		First(&application).Error; err != nil {
 		return nil, err
 	}
 
-	// Step 2: Load approval group members
+	// Step 2: Load approval group members and review checklist
 	var groupMembers []models.ApprovalGroupMember
+	var checklist []models.ReviewChecklistItem
 	if application.ApprovalGroup.ID != uuid.Nil {
 		if err := r.db.
 			Preload("User").
@@ -423,6 +510,11 @@ func (r *applicationRepository) GetEnhancedApplicationApprovalData(applicationID
 			Find(&groupMembers).Error; err != nil {
 			return nil, err
 		}
+
+		var err error
+		if checklist, err = reviewChecklist(r.db, application.ApprovalGroup.ID, false); err != nil {
+			return nil, err
+		}
 	}
 
 	// Step 3: Get accessible issues - EXCLUDE REMOVED PARTICIPANTS
@@ -473,7 +565,7 @@ func (r *applicationRepository) GetEnhancedApplicationApprovalData(applicationID
 	readyForFinalApproval := r.isReadyForFinalApproval(&application, groupMembers)
 
 	response := &ApplicationApprovalData{
-		Application:           r.buildEnhancedApplicationView(&application, groupMembers, nil),
+		Application:           r.buildEnhancedApplicationView(&application, groupMembers, checklist, nil),
 		ApprovalProgress:      r.calculateEnhancedApprovalProgress(&application, groupMembers),
 		UnresolvedIssues:      r.countUnresolvedIssues(application.Issues),
 		CanTakeAction:         r.canTakeAction(&application),
@@ -490,6 +582,7 @@ func (r *applicationRepository) GetEnhancedApplicationApprovalData(applicationID
 func (r *applicationRepository) buildEnhancedApplicationView(
 	app *models.Application,
 	members []models.ApprovalGroupMember,
+	checklist []models.ReviewChecklistItem,
 	threadMessageCounts map[uuid.UUID]int,
 ) *EnhancedApplicationView {
 	view := &EnhancedApplicationView{
@@ -523,13 +616,15 @@ func (r *applicationRepository) buildEnhancedApplicationView(
 		RingBeamCertificateProvided:              app.RingBeamCertificateProvided,
 
 		// Core relationships
-		Applicant:     r.buildEnhancedApplicantSummary(&app.Applicant),
-		Tariff:        r.buildEnhancedTariffSummary(app.Tariff),
-		VATRate:       r.buildVATRateSummary(app.VATRate),
-		ApprovalGroup: r.buildEnhancedApprovalGroup(app.ApprovalGroup, members),
+		Applicant:      r.buildEnhancedApplicantSummary(&app.Applicant),
+		CoApplicants:   r.buildCoApplicantSummaries(app),
+		ApplicantNames: app.ApplicantNames(),
+		Tariff:         r.buildEnhancedTariffSummary(app.Tariff),
+		VATRate:        r.buildVATRateSummary(app.VATRate),
+		ApprovalGroup:  r.buildEnhancedApprovalGroup(app.ApprovalGroup, members, checklist),
 
 		// Assignments and decisions
-		GroupAssignments: r.buildEnhancedGroupAssignments(app.GroupAssignments),
+		GroupAssignments: r.buildEnhancedGroupAssignments(app.GroupAssignments, checklist),
 		FinalApproverID:  app.FinalApproverID,
 
 		// Issues and comments
@@ -542,6 +637,16 @@ func (r *applicationRepository) buildEnhancedApplicationView(
 		// Payment
 		Payment: r.buildPaymentSummary(&app.Payment),
 
+		// Rates clearance
+		RatesClearance: r.buildRatesClearanceSummary(app.RatesClearances),
+
+		// Stand ownership
+		DeedsVerification: r.buildDeedsVerificationSummary(app.DeedsVerifications),
+
+		// Risk
+		RiskScore: app.RiskScore,
+		RiskLevel: app.RiskLevel,
+
 		// Audit
 		CreatedBy: app.CreatedBy,
 		UpdatedBy: app.UpdatedBy,
@@ -564,21 +669,38 @@ func (r *applicationRepository) buildEnhancedApplicantSummary(applicant *models.
 	if applicant == nil {
 		return nil
 	}
+	// Organisation co-owners have no personal names, so optional fields are dereferenced safely
 	return &EnhancedApplicantSummary{
 		ID:             applicant.ID,
 		ApplicantType:  string(applicant.ApplicantType),
-		FirstName:      *applicant.FirstName,
-		LastName:       *applicant.LastName,
+		FirstName:      utils.DerefString(applicant.FirstName),
+		LastName:       utils.DerefString(applicant.LastName),
 		FullName:       applicant.FullName,
 		Email:          applicant.Email,
 		PhoneNumber:    applicant.PhoneNumber,
 		WhatsAppNumber: applicant.WhatsAppNumber,
 		IDNumber:       utils.DerefString(applicant.IdNumber),
-		PostalAddress:  *applicant.PostalAddress,
-		City:           *applicant.City,
+		PostalAddress:  utils.DerefString(applicant.PostalAddress),
+		City:           utils.DerefString(applicant.City),
 		Status:         string(applicant.Status),
 		Debtor:         applicant.Debtor,
+		Role:           models.CoApplicantPrimary,
+	}
+}
+
+// Build co-owner summaries; the primary applicant is reported separately
+func (r *applicationRepository) buildCoApplicantSummaries(app *models.Application) []*EnhancedApplicantSummary {
+	result := []*EnhancedApplicantSummary{}
+	for _, coApplicant := range app.CoApplicants {
+		if coApplicant.Role != models.CoApplicantCoOwner || coApplicant.Applicant == nil {
+			continue
+		}
+		summary := r.buildEnhancedApplicantSummary(coApplicant.Applicant)
+		summary.Role = coApplicant.Role
+		summary.IDDocumentID = coApplicant.IDDocumentID
+		result = append(result, summary)
 	}
+	return result
 }
 
 // Build enhanced tariff summary
@@ -618,6 +740,7 @@ func (r *applicationRepository) buildVATRateSummary(vatRate *models.VATRate) *VA
 func (r *applicationRepository) buildEnhancedApprovalGroup(
 	group *models.ApprovalGroup,
 	members []models.ApprovalGroupMember,
+	checklist []models.ReviewChecklistItem,
 ) *EnhancedApprovalGroup {
 	if group == nil {
 		return nil
@@ -647,6 +770,7 @@ func (r *applicationRepository) buildEnhancedApprovalGroup(
 			CanApprove:         member.CanApprove,
 			CanReject:          member.CanReject,
 			IsFinalApprover:    member.IsFinalApprover,
+			DecisionWeight:     member.Weight(),
 			AvailabilityStatus: member.AvailabilityStatus,
 			Department:         department,
 			RoleName:           roleName,
@@ -663,11 +787,12 @@ func (r *applicationRepository) buildEnhancedApprovalGroup(
 		MinimumApprovals:     group.MinimumApprovals,
 		AutoAssignBackups:    group.AutoAssignBackups,
 		Members:              memberSummaries,
+		Checklist:            checklist,
 	}
 }
 
 // Build enhanced group assignments
-func (r *applicationRepository) buildEnhancedGroupAssignments(assignments []models.ApplicationGroupAssignment) []*EnhancedGroupAssignment {
+func (r *applicationRepository) buildEnhancedGroupAssignments(assignments []models.ApplicationGroupAssignment, checklist []models.ReviewChecklistItem) []*EnhancedGroupAssignment {
 	result := make([]*EnhancedGroupAssignment, len(assignments))
 	for i, assignment := range assignments {
 		decisionSummaries := make([]*EnhancedDecision, len(assignment.Decisions))
@@ -686,6 +811,9 @@ func (r *applicationRepository) buildEnhancedGroupAssignments(assignments []mode
 				IsFinalApproverDecision: decision.IsFinalApproverDecision,
 				WasAvailable:            decision.WasAvailable,
 			}
+			if decision.Status == models.DecisionApproved {
+				decisionSummaries[j].Checklist = checklistCompletion(checklist, decision.ChecklistItems)
+			}
 		}
 
 		result[i] = &EnhancedGroupAssignment{
@@ -698,6 +826,10 @@ func (r *applicationRepository) buildEnhancedGroupAssignments(assignments []mode
 			ApprovedCount:           assignment.ApprovedCount,
 			RejectedCount:           assignment.RejectedCount,
 			PendingCount:            assignment.PendingCount,
+			TotalWeight:             assignment.TotalWeight,
+			ApprovedWeight:          assignment.ApprovedWeight,
+			RejectedWeight:          assignment.RejectedWeight,
+			PendingWeight:           assignment.PendingWeight,
 			IssuesRaised:            assignment.IssuesRaised,
 			IssuesResolved:          assignment.IssuesResolved,
 			ReadyForFinalApproval:   assignment.ReadyForFinalApproval,
@@ -767,8 +899,9 @@ func (r *applicationRepository) buildEnhancedIssueSummaries(
 					return nil
 				}()),
 			},
-			AssignedToUser: assignedToUser,
-			ChatThreadID:   issue.ChatThreadID,
+			AssignedToUser:  assignedToUser,
+			ChatThreadID:    issue.ChatThreadID,
+			SourceMessageID: issue.SourceMessageID,
 		}
 	}
 	return result
@@ -842,6 +975,54 @@ func (r *applicationRepository) buildPaymentSummary(payment *models.Payment) *Pa
 	}
 }
 
+// Build rates clearance summary from clearances ordered newest first
+func (r *applicationRepository) buildRatesClearanceSummary(clearances []models.RatesClearance) *RatesClearanceSummary {
+	if len(clearances) == 0 {
+		return nil
+	}
+	latest := clearances[0]
+	summary := &RatesClearanceSummary{
+		ID:               latest.ID,
+		Status:           latest.Status,
+		Cleared:          latest.AllowsAcceptance(),
+		AccountNumber:    latest.AccountNumber,
+		Currency:         latest.Currency,
+		BillingReference: latest.BillingReference,
+		Message:          latest.Message,
+		CheckedAt:        latest.CheckedAt.Format(time.RFC3339),
+		OverrideReason:   latest.OverrideReason,
+	}
+	if latest.OutstandingBalance != nil {
+		balance := latest.OutstandingBalance.StringFixed(2)
+		summary.OutstandingBalance = &balance
+	}
+	if latest.OverriddenBy != nil {
+		name := strings.TrimSpace(latest.OverriddenBy.FirstName + " " + latest.OverriddenBy.LastName)
+		summary.OverriddenBy = &name
+	}
+	return summary
+}
+
+// Build deeds verification summary from verifications ordered newest first
+func (r *applicationRepository) buildDeedsVerificationSummary(verifications []models.DeedsVerification) *DeedsVerificationSummary {
+	if len(verifications) == 0 {
+		return nil
+	}
+	latest := verifications[0]
+	now := time.Now()
+	return &DeedsVerificationSummary{
+		ID:                latest.ID,
+		Status:            latest.Status,
+		Verified:          latest.Status == models.DeedsVerified,
+		Stale:             latest.IsStale(deedsVerificationMaxAge(), now),
+		CertificateNumber: latest.CertificateNumber,
+		RegisteredOwners:  latest.RegisteredOwners,
+		Message:           latest.Message,
+		CheckedAt:         latest.CheckedAt.Format(time.RFC3339),
+		Warning:           deedsVerificationWarning(&latest, now),
+	}
+}
+
 // Count unresolved issues
 func (r *applicationRepository) countUnresolvedIssues(issues []models.ApplicationIssue) int {
 	count := 0
@@ -855,7 +1036,7 @@ func (r *applicationRepository) countUnresolvedIssues(issues []models.Applicatio
 
 // Check if current user can take action
 func (r *applicationRepository) canTakeAction(app *models.Application) bool {
-	return app.PaymentStatus == models.PaidPayment &&
+	return app.PaymentPrerequisiteMet() &&
 		app.AllDocumentsProvided &&
 		app.Status == models.UnderReviewApplication
 }
@@ -869,13 +1050,13 @@ func (r *applicationRepository) calculateEnhancedApprovalProgress(
 		return 0
 	}
 
-	// Count ALL members (including final approver)
-	totalMemberCount := 0
-	decidedCount := 0 // Count both approvals and rejections as progress
+	// Weigh ALL members (including final approver) by their decision weight
+	totalWeight := 0
+	decidedWeight := 0 // Count both approvals and rejections as progress
 
 	for _, member := range members {
 		if member.IsActive && member.CanApprove {
-			totalMemberCount++
+			totalWeight += member.Weight()
 
 			// Check if this member has made any decision (approved or rejected)
 			memberDecided := false
@@ -893,16 +1074,16 @@ func (r *applicationRepository) calculateEnhancedApprovalProgress(
 			}
 
 			if memberDecided {
-				decidedCount++
+				decidedWeight += member.Weight()
 			}
 		}
 	}
 
-	if totalMemberCount == 0 {
+	if totalWeight == 0 {
 		return 0
 	}
 
-	progress := float64(decidedCount) / float64(totalMemberCount) * 100
+	progress := float64(decidedWeight) / float64(totalWeight) * 100
 	return int(progress + 0.5)
 }
 
@@ -917,11 +1098,19 @@ func (r *applicationRepository) getEnhancedWorkflowStatus(
 	rejectedApprovers := 0
 	pendingApprovers := 0
 
+	// Weighted counterparts drive the progress percentage
+	totalWeight := 0
+	approvedWeight := 0
+	rejectedWeight := 0
+	pendingWeight := 0
+
 	var finalApprover *models.ApprovalGroupMember
 
 	for _, member := range members {
 		if member.IsActive && member.CanApprove {
 			totalApprovers++
+			weight := member.Weight()
+			totalWeight += weight
 
 			if member.IsFinalApprover {
 				finalApprover = &member
@@ -937,9 +1126,11 @@ func (r *applicationRepository) getEnhancedWorkflowStatus(
 						case models.DecisionApproved:
 							memberDecided = true
 							approvedApprovers++
+							approvedWeight += weight
 						case models.DecisionRejected:
 							memberDecided = true
 							rejectedApprovers++
+							rejectedWeight += weight
 						}
 						break
 					}
@@ -951,15 +1142,16 @@ func (r *applicationRepository) getEnhancedWorkflowStatus(
 
 			if !memberDecided {
 				pendingApprovers++
+				pendingWeight += weight
 			}
 		}
 	}
 
-	// Calculate progress
+	// Calculate progress from decision weights so heavier votes move it further
 	progressPercentage := 0
-	if totalApprovers > 0 {
-		decidedCount := approvedApprovers + rejectedApprovers
-		progressPercentage = (decidedCount * 100) / totalApprovers
+	if totalWeight > 0 {
+		decidedWeight := approvedWeight + rejectedWeight
+		progressPercentage = (decidedWeight * 100) / totalWeight
 	}
 
 	// Auto-rejection logic (only for regular members, before final approver)
@@ -996,6 +1188,10 @@ func (r *applicationRepository) getEnhancedWorkflowStatus(
 		PendingApprovers:   pendingApprovers,
 		ProgressPercentage: progressPercentage,
 		ShouldAutoReject:   shouldAutoReject,
+		TotalWeight:        totalWeight,
+		ApprovedWeight:     approvedWeight,
+		RejectedWeight:     rejectedWeight,
+		PendingWeight:      pendingWeight,
 	}
 }
 
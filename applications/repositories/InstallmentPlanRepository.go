@@ -0,0 +1,345 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// InstallmentPlanSummary is a plan with its balances worked out as of a given time
+type InstallmentPlanSummary struct {
+	Plan                *models.InstallmentPlan `json:"plan"`
+	PlanNumber          string                  `json:"plan_number"`
+	ApplicantName       string                  `json:"applicant_name"`
+	Outstanding         decimal.Decimal         `json:"outstanding"`
+	Arrears             decimal.Decimal         `json:"arrears"`
+	OverdueInstallments int                     `json:"overdue_installments"`
+	NextDueDate         *time.Time              `json:"next_due_date"`
+	NextDueAmount       decimal.Decimal         `json:"next_due_amount"`
+}
+
+// NewInstallmentPlanSummary works out a plan's balances. Installments must be loaded in sequence order.
+func NewInstallmentPlanSummary(plan *models.InstallmentPlan, asOf time.Time) *InstallmentPlanSummary {
+	summary := &InstallmentPlanSummary{
+		Plan:          plan,
+		Outstanding:   plan.Outstanding(),
+		Arrears:       plan.Arrears(asOf),
+		NextDueAmount: decimal.Zero,
+	}
+	if plan.Application != nil {
+		summary.PlanNumber = plan.Application.PlanNumber
+		summary.ApplicantName = plan.Application.Applicant.FullName
+	}
+
+	for i := range plan.Installments {
+		installment := &plan.Installments[i]
+		if installment.IsOverdue(asOf) {
+			summary.OverdueInstallments++
+		}
+		if installment.Status != models.InstallmentPaid && summary.NextDueDate == nil {
+			dueDate := installment.DueDate
+			summary.NextDueDate = &dueDate
+			summary.NextDueAmount = installment.Outstanding()
+		}
+	}
+	return summary
+}
+
+// splitInstallments divides total into count amounts rounded down to cents, with the
+// remainder added to the last installment so the schedule sums exactly to total
+func splitInstallments(total decimal.Decimal, count int) []decimal.Decimal {
+	share := total.Div(decimal.NewFromInt(int64(count))).RoundDown(2)
+	amounts := make([]decimal.Decimal, count)
+	allocated := decimal.Zero
+	for i := 0; i < count-1; i++ {
+		amounts[i] = share
+		allocated = allocated.Add(share)
+	}
+	amounts[count-1] = total.Sub(allocated)
+	return amounts
+}
+
+// activeInstallmentPlan returns the application's unsettled plan, or nil when there is none
+func activeInstallmentPlan(tx *gorm.DB, applicationID uuid.UUID) (*models.InstallmentPlan, error) {
+	var plan models.InstallmentPlan
+	err := tx.Where("application_id = ? AND status = ?", applicationID, models.InstallmentPlanActive).
+		First(&plan).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to load installment plan: %w", err)
+	}
+	return &plan, nil
+}
+
+// CreateInstallmentPlan schedules an application's TotalCost over count installments, the first
+// due on firstDueDate and each following one intervalMonths later. While the plan is active the
+// levy no longer holds up review.
+func (r *applicationRepository) CreateInstallmentPlan(tx *gorm.DB, applicationID uuid.UUID, count int, firstDueDate time.Time, intervalMonths int, allowConditionalApproval bool, createdBy string) (*models.InstallmentPlan, error) {
+	if count < 2 {
+		return nil, errors.New("a plan needs at least two installments")
+	}
+	if intervalMonths < 1 {
+		intervalMonths = 1
+	}
+
+	var application models.Application
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", applicationID).
+		First(&application).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("application not found")
+		}
+		return nil, fmt.Errorf("failed to load application: %w", err)
+	}
+
+	if application.TotalCost == nil || !application.TotalCost.IsPositive() {
+		return nil, errors.New("application has no total cost to schedule")
+	}
+	if application.PaymentStatus == models.PaidPayment {
+		return nil, errors.New("application is already paid")
+	}
+
+	existing, err := activeInstallmentPlan(tx, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errors.New("application already has an active installment plan")
+	}
+
+	plan := models.InstallmentPlan{
+		ApplicationID:            applicationID,
+		Status:                   models.InstallmentPlanActive,
+		TotalAmount:              *application.TotalCost,
+		PaidAmount:               decimal.Zero,
+		IntervalMonths:           intervalMonths,
+		AllowConditionalApproval: allowConditionalApproval,
+		CreatedBy:                createdBy,
+	}
+	for i, amount := range splitInstallments(*application.TotalCost, count) {
+		plan.Installments = append(plan.Installments, models.Installment{
+			Sequence:   i + 1,
+			DueDate:    firstDueDate.AddDate(0, i*intervalMonths, 0),
+			AmountDue:  amount,
+			AmountPaid: decimal.Zero,
+			Status:     models.InstallmentPending,
+		})
+	}
+
+	if err := tx.Create(&plan).Error; err != nil {
+		return nil, fmt.Errorf("failed to create installment plan: %w", err)
+	}
+
+	updates := map[string]interface{}{
+		"on_installment_plan": true,
+		"updated_by":          createdBy,
+	}
+	// The plan satisfies the payment prerequisite, so documents alone decide readiness now
+	if application.AllDocumentsProvided && !application.ReadyForReview {
+		updates["ready_for_review"] = true
+		if application.ReviewStartedAt == nil {
+			updates["review_started_at"] = time.Now()
+		}
+	}
+	if err := tx.Model(&application).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to update application: %w", err)
+	}
+
+	return &plan, nil
+}
+
+func (r *applicationRepository) installmentPlanQuery(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Application").
+		Preload("Application.Applicant").
+		Preload("Installments", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sequence ASC")
+		}).
+		Preload("Installments.Allocations").
+		Preload("Installments.Allocations.Payment")
+}
+
+// GetApplicationInstallmentPlan returns the application's most recent plan that was not cancelled
+func (r *applicationRepository) GetApplicationInstallmentPlan(applicationID uuid.UUID) (*models.InstallmentPlan, error) {
+	var plan models.InstallmentPlan
+	if err := r.installmentPlanQuery(r.db).
+		Where("application_id = ? AND status != ?", applicationID, models.InstallmentPlanCancelled).
+		Order("created_at DESC").
+		First(&plan).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("installment plan not found")
+		}
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// RecordInstallmentPayment saves a levy payment against the application's active plan and
+// allocates it to the oldest unpaid installments first. Paying off the plan marks the
+// application as paid.
+func (r *applicationRepository) RecordInstallmentPayment(tx *gorm.DB, applicationID uuid.UUID, payment *models.Payment) (*models.InstallmentPlan, error) {
+	if !payment.Amount.IsPositive() {
+		return nil, errors.New("payment amount must be positive")
+	}
+
+	var plan models.InstallmentPlan
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("application_id = ? AND status = ?", applicationID, models.InstallmentPlanActive).
+		First(&plan).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("installment plan not found")
+		}
+		return nil, fmt.Errorf("failed to load installment plan: %w", err)
+	}
+
+	if payment.Amount.GreaterThan(plan.Outstanding()) {
+		return nil, errors.New("payment exceeds outstanding balance")
+	}
+
+	var application models.Application
+	if err := tx.Select("id", "tariff_id").Where("id = ?", applicationID).First(&application).Error; err != nil {
+		return nil, fmt.Errorf("failed to load application: %w", err)
+	}
+
+	payment.ApplicationID = &applicationID
+	payment.TariffID = application.TariffID
+	payment.PaymentFor = models.PaymentForDevelopmentLevy
+	payment.PaymentStatus = models.PaidPayment
+	payment.TransactionType = models.OrdinaryTransactionType
+	if err := r.CheckDuplicatePayment(tx, payment, uuid.Nil); err != nil {
+		return nil, err
+	}
+	if err := tx.Create(payment).Error; err != nil {
+		return nil, r.DuplicateReceiptError(payment, fmt.Errorf("failed to record payment: %w", err))
+	}
+
+	var installments []models.Installment
+	if err := tx.Where("plan_id = ? AND status != ?", plan.ID, models.InstallmentPaid).
+		Order("sequence ASC").
+		Find(&installments).Error; err != nil {
+		return nil, fmt.Errorf("failed to load installments: %w", err)
+	}
+
+	now := time.Now()
+	remaining := payment.Amount
+	for i := range installments {
+		if !remaining.IsPositive() {
+			break
+		}
+		installment := &installments[i]
+
+		applied := decimal.Min(remaining, installment.Outstanding())
+		allocation := models.InstallmentAllocation{
+			InstallmentID: installment.ID,
+			PaymentID:     payment.ID,
+			Amount:        applied,
+		}
+		if err := tx.Create(&allocation).Error; err != nil {
+			return nil, fmt.Errorf("failed to allocate payment: %w", err)
+		}
+
+		installment.AmountPaid = installment.AmountPaid.Add(applied)
+		updates := map[string]interface{}{"amount_paid": installment.AmountPaid}
+		if installment.Outstanding().IsZero() {
+			updates["status"] = models.InstallmentPaid
+			updates["paid_at"] = now
+		} else {
+			updates["status"] = models.InstallmentPartial
+		}
+		if err := tx.Model(installment).Updates(updates).Error; err != nil {
+			return nil, fmt.Errorf("failed to update installment: %w", err)
+		}
+
+		remaining = remaining.Sub(applied)
+	}
+
+	plan.PaidAmount = plan.PaidAmount.Add(payment.Amount)
+	planUpdates := map[string]interface{}{
+		"paid_amount": plan.PaidAmount,
+		"updated_by":  payment.CreatedBy,
+	}
+	applicationUpdates := map[string]interface{}{
+		"payment_status": models.PartialPayment,
+		"updated_by":     payment.CreatedBy,
+	}
+	if plan.Outstanding().IsZero() {
+		planUpdates["status"] = models.InstallmentPlanSettled
+		planUpdates["settled_at"] = now
+		applicationUpdates["payment_status"] = models.PaidPayment
+		applicationUpdates["payment_completed_at"] = now
+		applicationUpdates["on_installment_plan"] = false
+	}
+	if err := tx.Model(&plan).Updates(planUpdates).Error; err != nil {
+		return nil, fmt.Errorf("failed to update installment plan: %w", err)
+	}
+	if err := tx.Model(&application).Updates(applicationUpdates).Error; err != nil {
+		return nil, fmt.Errorf("failed to update application: %w", err)
+	}
+
+	var updated models.InstallmentPlan
+	if err := r.installmentPlanQuery(tx).Where("id = ?", plan.ID).First(&updated).Error; err != nil {
+		return nil, fmt.Errorf("failed to reload installment plan: %w", err)
+	}
+	return &updated, nil
+}
+
+// GetOutstandingInstallmentPlans lists active plans with their balances, largest arrears first.
+// With overdueOnly set, plans that are up to date are left out.
+func (r *applicationRepository) GetOutstandingInstallmentPlans(overdueOnly bool) ([]*InstallmentPlanSummary, error) {
+	var plans []models.InstallmentPlan
+	if err := r.installmentPlanQuery(r.db).
+		Where("status = ?", models.InstallmentPlanActive).
+		Find(&plans).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	summaries := make([]*InstallmentPlanSummary, 0, len(plans))
+	for i := range plans {
+		summary := NewInstallmentPlanSummary(&plans[i], now)
+		if overdueOnly && summary.OverdueInstallments == 0 {
+			continue
+		}
+		summaries = append(summaries, summary)
+	}
+
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].Arrears.GreaterThan(summaries[j].Arrears)
+	})
+	return summaries, nil
+}
+
+// EnsureInstallmentsSettled returns an error while the application still owes money on an
+// installment plan. The permit must not be issued until it passes.
+func (r *applicationRepository) EnsureInstallmentsSettled(tx *gorm.DB, applicationID uuid.UUID) error {
+	plan, err := activeInstallmentPlan(tx, applicationID)
+	if err != nil {
+		return err
+	}
+	if plan != nil {
+		return errors.New("development levy installments must be settled before the permit is issued")
+	}
+	return nil
+}
+
+// checkInstallmentPolicy blocks final approval of an application with an unsettled plan unless
+// the plan allows conditional approval
+func (r *applicationRepository) checkInstallmentPolicy(tx *gorm.DB, applicationID uuid.UUID) error {
+	plan, err := activeInstallmentPlan(tx, applicationID)
+	if err != nil {
+		return err
+	}
+	if plan != nil && !plan.AllowConditionalApproval {
+		return errors.New("development levy installments must be settled before final approval")
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AssignIssueToUser hands an open issue to one user, who then becomes the only one besides the
+// raiser who can resolve it
+func (r *applicationRepository) AssignIssueToUser(tx *gorm.DB, issueID uuid.UUID, userID uuid.UUID) (*models.ApplicationIssue, error) {
+	issue, err := lockOpenIssue(tx, issueID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Model(issue).Updates(map[string]interface{}{
+		"assignment_type":             models.IssueAssignment_SPECIFIC_USER,
+		"assigned_to_user_id":         userID,
+		"assigned_to_group_member_id": nil,
+		"updated_at":                  time.Now(),
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to assign issue: %w", err)
+	}
+
+	return reloadIssue(tx, issueID)
+}
+
+// SetIssuePriority changes the priority of an open issue
+func (r *applicationRepository) SetIssuePriority(tx *gorm.DB, issueID uuid.UUID, priority string) (*models.ApplicationIssue, error) {
+	issue, err := lockOpenIssue(tx, issueID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Model(issue).Updates(map[string]interface{}{
+		"priority":   priority,
+		"updated_at": time.Now(),
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update issue priority: %w", err)
+	}
+
+	return reloadIssue(tx, issueID)
+}
+
+// lockOpenIssue locks an issue for update, refusing resolved ones
+func lockOpenIssue(tx *gorm.DB, issueID uuid.UUID) (*models.ApplicationIssue, error) {
+	var issue models.ApplicationIssue
+	if err := tx.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", issueID).
+		First(&issue).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("issue not found")
+		}
+		return nil, fmt.Errorf("failed to load issue: %w", err)
+	}
+
+	if issue.IsResolved {
+		return nil, errors.New("issue is already resolved")
+	}
+
+	return &issue, nil
+}
+
+func reloadIssue(tx *gorm.DB, issueID uuid.UUID) (*models.ApplicationIssue, error) {
+	var issue models.ApplicationIssue
+	if err := tx.
+		Preload("RaisedByUser").
+		Preload("AssignedToUser").
+		Preload("AssignedToGroupMember").
+		Preload("AssignedToGroupMember.User").
+		Where("id = ?", issueID).
+		First(&issue).Error; err != nil {
+		return nil, fmt.Errorf("failed to load issue relationships: %w", err)
+	}
+	return &issue, nil
+}
@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"town-planning-backend/applications/requests"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GetChatMessageForIssue loads a message that the user may raise as an issue, together with
+// its thread and attachments
+func (r *applicationRepository) GetChatMessageForIssue(tx *gorm.DB, messageID, userID uuid.UUID) (*models.ChatMessage, error) {
+	var message models.ChatMessage
+	if err := tx.
+		Preload("Thread").
+		Preload("Attachments").
+		Where("id = ?", messageID).
+		First(&message).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("message not found")
+		}
+		return nil, fmt.Errorf("failed to load message: %w", err)
+	}
+
+	if message.IsDeleted {
+		return nil, errors.New("deleted messages cannot be raised as issues")
+	}
+	if message.MessageType == models.MessageTypeSystem {
+		return nil, errors.New("system messages cannot be raised as issues")
+	}
+
+	var participants int64
+	if err := tx.Model(&models.ChatParticipant{}).
+		Where("thread_id = ? AND user_id = ? AND is_active = ?", message.ThreadID, userID, true).
+		Count(&participants).Error; err != nil {
+		return nil, fmt.Errorf("failed to check thread participation: %w", err)
+	}
+	if participants == 0 {
+		return nil, errors.New("user is not a participant in this thread")
+	}
+
+	return &message, nil
+}
+
+// RaiseIssueFromChatMessage raises a formal issue on the message's application, links the
+// originating message and copies its attachments onto the new issue's opening message. An
+// empty description is pre-filled from the message content.
+func (r *applicationRepository) RaiseIssueFromChatMessage(
+	tx *gorm.DB,
+	message *models.ChatMessage,
+	userID uuid.UUID,
+	request requests.RaiseIssueFromMessageRequest,
+	createdBy string,
+) (*models.ApplicationIssue, *models.ChatThread, *models.ChatMessage, error) {
+	description := strings.TrimSpace(request.Description)
+	if description == "" {
+		description = strings.TrimSpace(message.Content)
+	}
+	if description == "" {
+		return nil, nil, nil, errors.New("message has no text to use as the issue description")
+	}
+
+	issue, chatThread, initialMessage, err := r.RaiseApplicationIssueWithChatAndAttachments(
+		tx,
+		message.Thread.ApplicationID.String(),
+		userID,
+		request.Title,
+		description,
+		request.Priority,
+		request.Category,
+		request.AssignmentType,
+		request.AssignedToUserID,
+		request.AssignedToGroupMemberID,
+		nil, // Attachments are copied below so voice notes keep their kind and duration
+		createdBy,
+	)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	issue.SourceMessageID = &message.ID
+	if err := tx.Model(issue).Update("source_message_id", message.ID).Error; err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to link source message: %w", err)
+	}
+
+	for _, attachment := range message.Attachments {
+		copied := models.ChatAttachment{
+			MessageID:  initialMessage.ID,
+			DocumentID: attachment.DocumentID,
+			Kind:       attachment.Kind,
+			DurationMs: attachment.DurationMs,
+		}
+		if err := tx.Create(&copied).Error; err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to copy message attachment: %w", err)
+		}
+		initialMessage.Attachments = append(initialMessage.Attachments, copied)
+	}
+
+	return issue, chatThread, initialMessage, nil
+}
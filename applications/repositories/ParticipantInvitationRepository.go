@@ -0,0 +1,162 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	application_services "town-planning-backend/applications/services"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// inviteIfExternal turns a participant about to be added into a pending invitation when they are
+// from another department than the user adding them. Participants added by the system, such as
+// department heads an overdue decision is escalated to, are never invited.
+func inviteIfExternal(tx *gorm.DB, participant *models.ChatParticipant, inviterID uuid.UUID, now time.Time) error {
+	participant.InvitationStatus = models.InvitationAccepted
+	participant.InvitedAt = nil
+	participant.InvitationExpiresAt = nil
+	participant.InvitationRespondedAt = nil
+
+	policy := application_services.LoadParticipantInvitationPolicy()
+	if !policy.Required || inviterID == uuid.Nil || inviterID == participant.UserID {
+		return nil
+	}
+
+	var users []models.User
+	if err := tx.Select("id", "department_id").
+		Where("id IN ?", []uuid.UUID{inviterID, participant.UserID}).
+		Find(&users).Error; err != nil {
+		return fmt.Errorf("failed to load participant departments: %w", err)
+	}
+	var inviter, invitee *models.User
+	for i := range users {
+		switch users[i].ID {
+		case inviterID:
+			inviter = &users[i]
+		case participant.UserID:
+			invitee = &users[i]
+		}
+	}
+	if !policy.RequiresInvitation(inviter, invitee) {
+		return nil
+	}
+
+	expiresAt := now.Add(policy.ExpiresAfter)
+	participant.IsActive = false
+	participant.InvitationStatus = models.InvitationPending
+	participant.InvitedAt = &now
+	participant.InvitationExpiresAt = &expiresAt
+	return nil
+}
+
+// createParticipant inserts a participant. GORM skips zero values for columns with a default,
+// so an invitee would be stored as active unless that is written separately.
+func createParticipant(tx *gorm.DB, participant *models.ChatParticipant) error {
+	if err := tx.Create(participant).Error; err != nil {
+		return err
+	}
+	if !participant.IsActive {
+		return tx.Model(participant).Update("is_active", false).Error
+	}
+	return nil
+}
+
+// GetThreadInvitations lists the open invitations of a thread, oldest first
+func (r *applicationRepository) GetThreadInvitations(threadID uuid.UUID) ([]models.ChatParticipant, error) {
+	var invitations []models.ChatParticipant
+	err := r.db.
+		Preload("User").
+		Preload("User.Department").
+		Where("thread_id = ? AND invitation_status = ? AND invitation_expires_at > ?", threadID, models.InvitationPending, time.Now()).
+		Order("invited_at ASC").
+		Find(&invitations).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch thread invitations: %w", err)
+	}
+	return invitations, nil
+}
+
+// GetUserThreadInvitations lists the open invitations waiting for a user, newest first
+func (r *applicationRepository) GetUserThreadInvitations(userID uuid.UUID) ([]models.ChatParticipant, error) {
+	var invitations []models.ChatParticipant
+	err := r.db.
+		Preload("Thread").
+		Where("user_id = ? AND invitation_status = ? AND invitation_expires_at > ?", userID, models.InvitationPending, time.Now()).
+		Order("invited_at DESC").
+		Find(&invitations).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch invitations: %w", err)
+	}
+	return invitations, nil
+}
+
+// HasUnacceptedThreadInvitation reports whether a user was invited to a thread and has not
+// accepted, in which case they may not read it
+func (r *applicationRepository) HasUnacceptedThreadInvitation(threadID string, userID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.ChatParticipant{}).
+		Where("thread_id = ? AND user_id = ? AND is_active = ? AND invitation_status IN ?", threadID, userID, false,
+			[]models.ParticipantInvitationStatus{models.InvitationPending, models.InvitationDeclined, models.InvitationExpired}).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check thread invitation: %w", err)
+	}
+	return count > 0, nil
+}
+
+// RespondToThreadInvitation accepts or declines an invitation. Accepting makes the invitee an
+// active participant who can read the whole thread.
+func (r *applicationRepository) RespondToThreadInvitation(tx *gorm.DB, threadID uuid.UUID, userID uuid.UUID, accept bool) (*models.ChatParticipant, error) {
+	var participant models.ChatParticipant
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("thread_id = ? AND user_id = ?", threadID, userID).
+		First(&participant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("invitation not found")
+		}
+		return nil, err
+	}
+	if participant.InvitationStatus != models.InvitationPending {
+		return nil, errors.New("invitation is no longer pending")
+	}
+
+	// Expired invitations are closed by ExpireThreadInvitations
+	now := time.Now()
+	if participant.InvitationExpiresAt != nil && !participant.InvitationExpiresAt.After(now) {
+		return nil, errors.New("invitation has expired")
+	}
+
+	participant.InvitationRespondedAt = &now
+	participant.InvitationStatus = models.InvitationDeclined
+	if accept {
+		participant.InvitationStatus = models.InvitationAccepted
+		participant.IsActive = true
+	}
+	if err := tx.Model(&participant).Updates(map[string]interface{}{
+		"invitation_status":       participant.InvitationStatus,
+		"invitation_responded_at": now,
+		"is_active":               participant.IsActive,
+		"updated_at":              now,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to record invitation response: %w", err)
+	}
+	return &participant, nil
+}
+
+// ExpireThreadInvitations closes the invitations left unanswered past their expiry
+func (r *applicationRepository) ExpireThreadInvitations(now time.Time) (int64, error) {
+	result := r.db.Model(&models.ChatParticipant{}).
+		Where("invitation_status = ? AND invitation_expires_at <= ?", models.InvitationPending, now).
+		Updates(map[string]interface{}{
+			"invitation_status": models.InvitationExpired,
+			"updated_at":        now,
+		})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to expire invitations: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,203 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// permitValidityMonths is how long a development permit is valid from the day it is issued
+const permitValidityMonths = 24
+
+// IssuePermit records the permit for an application the first time its development permit is
+// generated. Regenerating returns the same permit, unless it has since been suspended or revoked.
+func (r *applicationRepository) IssuePermit(tx *gorm.DB, application *models.Application, issuedBy string) (*models.Permit, error) {
+	var permit models.Permit
+	err := tx.Where("application_id = ?", application.ID).First(&permit).Error
+	if err == nil {
+		switch permit.Status {
+		case models.PermitSuspended:
+			return nil, errors.New("permit is suspended")
+		case models.PermitRevoked:
+			return nil, errors.New("permit is revoked")
+		}
+		return &permit, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to load permit: %w", err)
+	}
+
+	if application.PermitNumber == "" {
+		return nil, errors.New("application has no permit number")
+	}
+	if application.Tariff == nil || application.Tariff.DevelopmentCategoryID == uuid.Nil {
+		return nil, errors.New("application has no development category")
+	}
+
+	issueDate := time.Now()
+	validUntil := issueDate.AddDate(0, permitValidityMonths, 0)
+	permit = models.Permit{
+		PermitNumber:          application.PermitNumber,
+		ApplicationID:         application.ID,
+		IssueDate:             issueDate,
+		ValidUntil:            &validUntil,
+		Status:                models.PermitActive,
+		DevelopmentCategoryID: application.Tariff.DevelopmentCategoryID,
+		CreatedBy:             issuedBy,
+	}
+	if err := tx.Create(&permit).Error; err != nil {
+		return nil, fmt.Errorf("failed to create permit: %w", err)
+	}
+	return &permit, nil
+}
+
+// GetPermit returns a permit with its application and status history, newest change first
+func (r *applicationRepository) GetPermit(permitID uuid.UUID) (*models.Permit, error) {
+	return r.findPermit(r.db.Where("id = ?", permitID))
+}
+
+// GetApplicationPermit returns the permit issued for an application
+func (r *applicationRepository) GetApplicationPermit(applicationID uuid.UUID) (*models.Permit, error) {
+	return r.findPermit(r.db.Where("application_id = ?", applicationID))
+}
+
+func (r *applicationRepository) findPermit(query *gorm.DB) (*models.Permit, error) {
+	var permit models.Permit
+	err := query.
+		Preload("Application.Applicant").
+		Preload("DevelopmentCategory").
+		Preload("StatusChanges", func(db *gorm.DB) *gorm.DB {
+			return db.Order("changed_at DESC")
+		}).
+		Preload("StatusChanges.ChangedBy").
+		Preload("StatusChanges.Documents").
+		First(&permit).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("permit not found")
+		}
+		return nil, fmt.Errorf("failed to load permit: %w", err)
+	}
+	return &permit, nil
+}
+
+// ChangePermitStatus suspends, reinstates or revokes a permit and records the change with its
+// reason and supporting documents. Revocation is final; expired permits may still be revoked.
+func (r *applicationRepository) ChangePermitStatus(tx *gorm.DB, permitID uuid.UUID, action models.PermitAction, reason string, documentIDs []uuid.UUID, changedByID uuid.UUID) (*models.Permit, *models.PermitStatusChange, error) {
+	var permit models.Permit
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", permitID).First(&permit).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New("permit not found")
+		}
+		return nil, nil, fmt.Errorf("failed to load permit: %w", err)
+	}
+
+	now := time.Now()
+	fromStatus := permit.EffectiveStatus(now)
+	if fromStatus == models.PermitRevoked {
+		return nil, nil, errors.New("permit is already revoked")
+	}
+
+	var toStatus models.PermitStatus
+	switch action {
+	case models.PermitActionSuspend:
+		if fromStatus != models.PermitActive {
+			return nil, nil, errors.New("only active permits can be suspended")
+		}
+		toStatus = models.PermitSuspended
+	case models.PermitActionReinstate:
+		if fromStatus != models.PermitSuspended {
+			return nil, nil, errors.New("only suspended permits can be reinstated")
+		}
+		toStatus = models.PermitActive
+	case models.PermitActionRevoke:
+		toStatus = models.PermitRevoked
+	default:
+		return nil, nil, fmt.Errorf("unknown permit action %s", action)
+	}
+
+	var documents []models.Document
+	if len(documentIDs) > 0 {
+		if err := tx.Where("id IN ?", documentIDs).Find(&documents).Error; err != nil {
+			return nil, nil, fmt.Errorf("failed to load supporting documents: %w", err)
+		}
+		if len(documents) != len(documentIDs) {
+			return nil, nil, errors.New("supporting document not found")
+		}
+	}
+
+	change := models.PermitStatusChange{
+		PermitID:    permit.ID,
+		Action:      action,
+		FromStatus:  fromStatus,
+		ToStatus:    toStatus,
+		Reason:      reason,
+		ChangedByID: changedByID,
+		ChangedAt:   now,
+		Documents:   documents,
+	}
+	if err := tx.Create(&change).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to record permit status change: %w", err)
+	}
+
+	if err := tx.Model(&permit).Updates(map[string]interface{}{
+		"status":            toStatus,
+		"status_reason":     reason,
+		"status_changed_at": now,
+		"status_changed_by": changedByID,
+	}).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to update permit status: %w", err)
+	}
+	permit.Status = toStatus
+	permit.StatusReason = &reason
+	permit.StatusChangedAt = &now
+	permit.StatusChangedBy = &changedByID
+
+	return &permit, &change, nil
+}
+
+// RecordPermitNotifications notes who was told about a permit status change
+func (r *applicationRepository) RecordPermitNotifications(statusChangeID uuid.UUID, applicantNotified bool, inspectorsNotified int) error {
+	return r.db.Model(&models.PermitStatusChange{}).
+		Where("id = ?", statusChangeID).
+		Updates(map[string]interface{}{
+			"applicant_notified":  applicantNotified,
+			"inspectors_notified": inspectorsNotified,
+		}).Error
+}
+
+// GetPermitInspectors returns the inspectors with inspections on the application that were
+// not cancelled, so they know whether site work may continue
+func (r *applicationRepository) GetPermitInspectors(applicationID uuid.UUID) ([]models.User, error) {
+	var inspectors []models.User
+	err := r.db.
+		Where("id IN (?)", r.db.Model(&models.Inspection{}).
+			Select("inspector_id").
+			Where("application_id = ? AND status <> ?", applicationID, models.InspectionStatusCancelled)).
+		Where("active = ?", true).
+		Find(&inspectors).Error
+	return inspectors, err
+}
+
+// VerifyPermit looks a permit up by its number for the public verification page
+func (r *applicationRepository) VerifyPermit(permitNumber string) (*models.Permit, error) {
+	var permit models.Permit
+	err := r.db.
+		Preload("Application").
+		Preload("DevelopmentCategory").
+		Where("UPPER(permit_number) = ?", strings.ToUpper(strings.TrimSpace(permitNumber))).
+		First(&permit).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("permit not found")
+		}
+		return nil, fmt.Errorf("failed to load permit: %w", err)
+	}
+	return &permit, nil
+}
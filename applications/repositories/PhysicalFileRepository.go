@@ -0,0 +1,284 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultPhysicalFileMissingThreshold is how long a file may be in transit before it is flagged missing
+const DefaultPhysicalFileMissingThreshold = 48 * time.Hour
+
+// newPhysicalFileBarcode generates the code printed on a file cover. It avoids the plan number,
+// which may contain characters that do not scan reliably.
+func newPhysicalFileBarcode() string {
+	return "TPF-" + strings.ToUpper(strings.ReplaceAll(uuid.New().String(), "-", "")[:12])
+}
+
+// physicalFileHolder loads a user who can hold a file, along with their department
+func physicalFileHolder(tx *gorm.DB, userID uuid.UUID) (*models.User, error) {
+	var user models.User
+	if err := tx.Where("id = ? AND active = ?", userID, true).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("holder not found")
+		}
+		return nil, fmt.Errorf("failed to load holder: %w", err)
+	}
+	return &user, nil
+}
+
+// lockPhysicalFile loads a file by barcode with a row lock so concurrent scans serialize
+func lockPhysicalFile(tx *gorm.DB, barcode string) (*models.ApplicationPhysicalFile, error) {
+	var file models.ApplicationPhysicalFile
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("barcode = ?", strings.ToUpper(strings.TrimSpace(barcode))).
+		First(&file).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("physical file not found")
+		}
+		return nil, fmt.Errorf("failed to load physical file: %w", err)
+	}
+	return &file, nil
+}
+
+// openPhysicalFileMovement returns the dispatch that has not been received yet, if any
+func openPhysicalFileMovement(tx *gorm.DB, fileID uuid.UUID) (*models.PhysicalFileMovement, error) {
+	var movement models.PhysicalFileMovement
+	err := tx.Where("file_id = ? AND received_at IS NULL", fileID).
+		Order("dispatched_at DESC").
+		First(&movement).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to load file movement: %w", err)
+	}
+	return &movement, nil
+}
+
+// RegisterPhysicalFile assigns a barcode to an application's paper file and records who holds it
+func (r *applicationRepository) RegisterPhysicalFile(tx *gorm.DB, applicationID uuid.UUID, holderID uuid.UUID, location *string, createdBy string) (*models.ApplicationPhysicalFile, error) {
+	var application models.Application
+	if err := tx.Select("id").Where("id = ?", applicationID).First(&application).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("application not found")
+		}
+		return nil, fmt.Errorf("failed to load application: %w", err)
+	}
+
+	var existing int64
+	if err := tx.Model(&models.ApplicationPhysicalFile{}).
+		Where("application_id = ?", applicationID).
+		Count(&existing).Error; err != nil {
+		return nil, fmt.Errorf("failed to check existing physical file: %w", err)
+	}
+	if existing > 0 {
+		return nil, errors.New("physical file already registered")
+	}
+
+	holder, err := physicalFileHolder(tx, holderID)
+	if err != nil {
+		return nil, err
+	}
+
+	file := models.ApplicationPhysicalFile{
+		ApplicationID:       applicationID,
+		Barcode:             newPhysicalFileBarcode(),
+		Status:              models.PhysicalFileWithHolder,
+		CurrentHolderID:     &holder.ID,
+		CurrentDepartmentID: holder.DepartmentID,
+		CurrentLocation:     location,
+		HeldSince:           time.Now(),
+		CreatedBy:           createdBy,
+	}
+	if err := tx.Create(&file).Error; err != nil {
+		return nil, fmt.Errorf("failed to register physical file: %w", err)
+	}
+
+	return &file, nil
+}
+
+func (r *applicationRepository) physicalFileQuery() *gorm.DB {
+	return r.db.
+		Preload("Application").
+		Preload("CurrentHolder").
+		Preload("CurrentDepartment").
+		Preload("Movements", func(db *gorm.DB) *gorm.DB {
+			return db.Order("dispatched_at DESC")
+		}).
+		Preload("Movements.FromHolder").
+		Preload("Movements.ToHolder").
+		Preload("Movements.ToDepartment")
+}
+
+// GetPhysicalFileByBarcode looks up a scanned file with its custody trail
+func (r *applicationRepository) GetPhysicalFileByBarcode(barcode string) (*models.ApplicationPhysicalFile, error) {
+	var file models.ApplicationPhysicalFile
+	if err := r.physicalFileQuery().
+		Where("barcode = ?", strings.ToUpper(strings.TrimSpace(barcode))).
+		First(&file).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("physical file not found")
+		}
+		return nil, err
+	}
+	return &file, nil
+}
+
+// GetApplicationPhysicalFile returns the paper file of an application with its custody trail
+func (r *applicationRepository) GetApplicationPhysicalFile(applicationID uuid.UUID) (*models.ApplicationPhysicalFile, error) {
+	var file models.ApplicationPhysicalFile
+	if err := r.physicalFileQuery().
+		Where("application_id = ?", applicationID).
+		First(&file).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("physical file not found")
+		}
+		return nil, err
+	}
+	return &file, nil
+}
+
+// DispatchPhysicalFile sends a file to another holder. Custody stays with the sender until the
+// recipient scans it in with ReceivePhysicalFile.
+func (r *applicationRepository) DispatchPhysicalFile(tx *gorm.DB, barcode string, toHolderID uuid.UUID, toLocation *string, notes *string, dispatchedByID uuid.UUID) (*models.PhysicalFileMovement, error) {
+	file, err := lockPhysicalFile(tx, barcode)
+	if err != nil {
+		return nil, err
+	}
+
+	switch file.Status {
+	case models.PhysicalFileInTransit:
+		return nil, errors.New("file is already in transit")
+	case models.PhysicalFileMissing:
+		return nil, errors.New("file is flagged as missing")
+	}
+
+	if file.CurrentHolderID != nil && *file.CurrentHolderID == toHolderID {
+		return nil, errors.New("file is already with this holder")
+	}
+
+	recipient, err := physicalFileHolder(tx, toHolderID)
+	if err != nil {
+		if err.Error() == "holder not found" {
+			return nil, errors.New("recipient not found")
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	movement := models.PhysicalFileMovement{
+		FileID:           file.ID,
+		FromHolderID:     file.CurrentHolderID,
+		FromDepartmentID: file.CurrentDepartmentID,
+		ToHolderID:       recipient.ID,
+		ToDepartmentID:   recipient.DepartmentID,
+		ToLocation:       toLocation,
+		Notes:            notes,
+		DispatchedByID:   dispatchedByID,
+		DispatchedAt:     now,
+	}
+	if err := tx.Create(&movement).Error; err != nil {
+		return nil, fmt.Errorf("failed to record file movement: %w", err)
+	}
+
+	updatedBy := dispatchedByID.String()
+	if err := tx.Model(file).Updates(map[string]interface{}{
+		"status":     models.PhysicalFileInTransit,
+		"updated_by": updatedBy,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update physical file: %w", err)
+	}
+
+	return &movement, nil
+}
+
+// ReceivePhysicalFile records the recipient taking custody of a dispatched file. Receiving a file
+// that was flagged missing clears the flag.
+func (r *applicationRepository) ReceivePhysicalFile(tx *gorm.DB, barcode string, receiverID uuid.UUID, location *string) (*models.ApplicationPhysicalFile, error) {
+	file, err := lockPhysicalFile(tx, barcode)
+	if err != nil {
+		return nil, err
+	}
+
+	if file.Status == models.PhysicalFileWithHolder {
+		return nil, errors.New("file is not in transit")
+	}
+
+	movement, err := openPhysicalFileMovement(tx, file.ID)
+	if err != nil {
+		return nil, err
+	}
+	if movement == nil {
+		return nil, errors.New("file is not in transit")
+	}
+	if movement.ToHolderID != receiverID {
+		return nil, errors.New("file was sent to a different holder")
+	}
+
+	now := time.Now()
+	if err := tx.Model(movement).Update("received_at", now).Error; err != nil {
+		return nil, fmt.Errorf("failed to record receipt: %w", err)
+	}
+
+	if location == nil {
+		location = movement.ToLocation
+	}
+	updatedBy := receiverID.String()
+	if err := tx.Model(file).Updates(map[string]interface{}{
+		"status":                models.PhysicalFileWithHolder,
+		"current_holder_id":     movement.ToHolderID,
+		"current_department_id": movement.ToDepartmentID,
+		"current_location":      location,
+		"held_since":            now,
+		"missing_flagged_at":    nil,
+		"updated_by":            updatedBy,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update physical file: %w", err)
+	}
+
+	var received models.ApplicationPhysicalFile
+	if err := tx.Preload("CurrentHolder").
+		Preload("CurrentDepartment").
+		Where("id = ?", file.ID).
+		First(&received).Error; err != nil {
+		return nil, fmt.Errorf("failed to reload physical file: %w", err)
+	}
+	return &received, nil
+}
+
+// FlagMissingPhysicalFiles marks files that have been in transit longer than the threshold as missing
+func (r *applicationRepository) FlagMissingPhysicalFiles(tx *gorm.DB, threshold time.Duration) (int64, error) {
+	now := time.Now()
+	result := tx.Model(&models.ApplicationPhysicalFile{}).
+		Where("status = ?", models.PhysicalFileInTransit).
+		Where("id IN (?)", tx.Model(&models.PhysicalFileMovement{}).
+			Select("file_id").
+			Where("received_at IS NULL AND dispatched_at < ?", now.Add(-threshold))).
+		Updates(map[string]interface{}{
+			"status":             models.PhysicalFileMissing,
+			"missing_flagged_at": now,
+		})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to flag missing files: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// GetMissingPhysicalFiles lists files flagged missing, longest missing first
+func (r *applicationRepository) GetMissingPhysicalFiles() ([]models.ApplicationPhysicalFile, error) {
+	var files []models.ApplicationPhysicalFile
+	if err := r.physicalFileQuery().
+		Where("status = ?", models.PhysicalFileMissing).
+		Order("missing_flagged_at ASC").
+		Find(&files).Error; err != nil {
+		return nil, err
+	}
+	return files, nil
+}
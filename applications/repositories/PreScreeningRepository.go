@@ -0,0 +1,228 @@
+package repositories
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"town-planning-backend/db/models"
+	"town-planning-backend/utils/pagination"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PreScreeningResult is a technician's triage of a queued application. Passing needs every
+// checklist item ticked; bouncing needs at least one standard reason, and a note when the reason
+// is OTHER.
+type PreScreeningResult struct {
+	Checks        map[models.PreScreeningCheck]bool
+	Pass          bool
+	BounceReasons []models.PreScreeningBounceReason
+	Note          *string
+}
+
+// QueueForPreScreening puts a new submission in the triage queue instead of assigning it. The
+// group it was meant for is kept and assigned once it passes.
+func (r *applicationRepository) QueueForPreScreening(tx *gorm.DB, applicationID uuid.UUID, groupID uuid.UUID) (*models.ApplicationPreScreening, error) {
+	screening := models.ApplicationPreScreening{
+		ApplicationID:   applicationID,
+		ApprovalGroupID: groupID,
+		Outcome:         models.PreScreeningPending,
+		QueuedAt:        time.Now(),
+	}
+	if err := tx.Create(&screening).Error; err != nil {
+		return nil, fmt.Errorf("failed to queue application for pre-screening: %w", err)
+	}
+
+	if err := tx.Model(&models.Application{}).
+		Where("id = ?", applicationID).
+		Update("status", models.PreScreeningApplication).Error; err != nil {
+		return nil, fmt.Errorf("failed to update application status: %w", err)
+	}
+	return &screening, nil
+}
+
+// GetPreScreeningQueue lists the applications waiting to be pre-screened, longest waiting first
+func (r *applicationRepository) GetPreScreeningQueue(page pagination.Request) ([]models.ApplicationPreScreening, int64, error) {
+	query := r.db.Model(&models.ApplicationPreScreening{}).
+		Where("application_pre_screenings.outcome = ?", models.PreScreeningPending)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count pre-screening queue: %w", err)
+	}
+
+	var screenings []models.ApplicationPreScreening
+	if err := page.Window(query, "application_pre_screenings", "application_pre_screenings.queued_at ASC").
+		Preload("Application").
+		Preload("Application.Applicant").
+		Preload("Application.Stand").
+		Preload("Application.Tariff.DevelopmentCategory").
+		Preload("ApprovalGroup").
+		Find(&screenings).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to load pre-screening queue: %w", err)
+	}
+	return screenings, total, nil
+}
+
+// GetApplicationPreScreenings lists every pass of an application through pre-screening, latest
+// first
+func (r *applicationRepository) GetApplicationPreScreenings(applicationID uuid.UUID) ([]models.ApplicationPreScreening, error) {
+	var screenings []models.ApplicationPreScreening
+	if err := r.db.Preload("ScreenedBy").
+		Preload("ApprovalGroup").
+		Where("application_id = ?", applicationID).
+		Order("queued_at DESC").
+		Find(&screenings).Error; err != nil {
+		return nil, fmt.Errorf("failed to load pre-screenings: %w", err)
+	}
+	return screenings, nil
+}
+
+// RecordPreScreening records the triage of the application's pending screening. A bounced
+// application is returned to the applicant; a passed one is left SUBMITTED for the caller to
+// assign to the screening's approval group in the same transaction.
+func (r *applicationRepository) RecordPreScreening(
+	tx *gorm.DB,
+	applicationID uuid.UUID,
+	result PreScreeningResult,
+	screenedByID uuid.UUID,
+) (*models.ApplicationPreScreening, error) {
+	if err := validatePreScreeningResult(result); err != nil {
+		return nil, err
+	}
+
+	var screening models.ApplicationPreScreening
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("application_id = ? AND outcome = ?", applicationID, models.PreScreeningPending).
+		First(&screening).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("application is not waiting for pre-screening")
+		}
+		return nil, fmt.Errorf("failed to load pre-screening: %w", err)
+	}
+
+	checks, err := json.Marshal(result.Checks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode checklist: %w", err)
+	}
+	reasons := result.BounceReasons
+	if reasons == nil {
+		reasons = []models.PreScreeningBounceReason{}
+	}
+	encodedReasons, err := json.Marshal(reasons)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode bounce reasons: %w", err)
+	}
+
+	now := time.Now()
+	screening.Outcome = models.PreScreeningBounced
+	status := models.ReturnedToApplicantApplication
+	if result.Pass {
+		screening.Outcome = models.PreScreeningPassed
+		status = models.SubmittedApplication
+	}
+	screening.Checks = datatypes.JSON(checks)
+	screening.BounceReasons = datatypes.JSON(encodedReasons)
+	screening.Note = result.Note
+	screening.ScreenedByID = &screenedByID
+	screening.ScreenedAt = &now
+
+	if err := tx.Model(&screening).Updates(map[string]interface{}{
+		"outcome":        screening.Outcome,
+		"checks":         screening.Checks,
+		"bounce_reasons": screening.BounceReasons,
+		"note":           screening.Note,
+		"screened_by_id": screenedByID,
+		"screened_at":    now,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to record pre-screening: %w", err)
+	}
+
+	if err := tx.Model(&models.Application{}).
+		Where("id = ?", applicationID).
+		Update("status", status).Error; err != nil {
+		return nil, fmt.Errorf("failed to update application status: %w", err)
+	}
+	return &screening, nil
+}
+
+// RequeuePreScreening puts a returned application back in the triage queue once the applicant
+// has resubmitted it, for the group the first screening was meant for
+func (r *applicationRepository) RequeuePreScreening(tx *gorm.DB, applicationID uuid.UUID) (*models.ApplicationPreScreening, error) {
+	var application models.Application
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "status").
+		Where("id = ?", applicationID).
+		First(&application).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("application not found")
+		}
+		return nil, fmt.Errorf("failed to load application: %w", err)
+	}
+	if application.Status != models.ReturnedToApplicantApplication {
+		return nil, errors.New("application was not returned to the applicant")
+	}
+
+	var last models.ApplicationPreScreening
+	if err := tx.Where("application_id = ?", applicationID).
+		Order("queued_at DESC").
+		First(&last).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("application was never pre-screened")
+		}
+		return nil, fmt.Errorf("failed to load last pre-screening: %w", err)
+	}
+
+	return r.QueueForPreScreening(tx, applicationID, last.ApprovalGroupID)
+}
+
+func validatePreScreeningResult(result PreScreeningResult) error {
+	for check := range result.Checks {
+		known := false
+		for _, item := range models.PreScreeningChecklist {
+			if check == item {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown checklist item %s", check)
+		}
+	}
+	for _, reason := range result.BounceReasons {
+		if _, ok := models.PreScreeningBounceReasons[reason]; !ok {
+			return fmt.Errorf("unknown bounce reason %s", reason)
+		}
+	}
+
+	if result.Pass {
+		var failed []string
+		for _, item := range models.PreScreeningChecklist {
+			if !result.Checks[item] {
+				failed = append(failed, string(item))
+			}
+		}
+		if len(failed) > 0 {
+			return fmt.Errorf("every checklist item must pass, failed: %s", strings.Join(failed, ", "))
+		}
+		if len(result.BounceReasons) > 0 {
+			return errors.New("a passed application cannot have bounce reasons")
+		}
+		return nil
+	}
+
+	if len(result.BounceReasons) == 0 {
+		return errors.New("at least one bounce reason is required")
+	}
+	for _, reason := range result.BounceReasons {
+		if reason == models.BounceOther && (result.Note == nil || strings.TrimSpace(*result.Note) == "") {
+			return errors.New("a note is required when the bounce reason is OTHER")
+		}
+	}
+	return nil
+}
@@ -13,12 +13,14 @@ import (
 	"gorm.io/gorm"
 )
 
-// ProcessDecisionRevocation handles revoking a user's decision on an application
+// ProcessDecisionRevocation handles revoking a user's decision on an application. The idempotency
+// key the request was sent with, if any, is kept on the revocation for the audit log.
 func (r *applicationRepository) ProcessDecisionRevocation(
 	tx *gorm.DB,
 	applicationID string,
 	userID uuid.UUID,
 	reason string,
+	idempotencyKey *string,
 ) (*requests.RevocationResult, error) {
 	// Step 1: Fetch application with all necessary data
 	var application models.Application
@@ -81,6 +83,11 @@ func (r *applicationRepository) ProcessDecisionRevocation(
 		return nil, errors.New("decision is already revoked")
 	}
 
+	// A recusal is not a decision; it stands for as long as the conflict declaration does
+	if decision.Status == models.DecisionSkipped {
+		return nil, errors.New("cannot revoke a recusal for a declared conflict of interest")
+	}
+
 	// Store the previous status before revoking
 	previousDecisionStatus := decision.Status
 	now := time.Now()
@@ -121,6 +128,7 @@ func (r *applicationRepository) ProcessDecisionRevocation(
 			previousStatus,
 			previousDecisionStatus,
 			reason,
+			idempotencyKey,
 			now,
 		)
 	}
@@ -134,6 +142,7 @@ func (r *applicationRepository) ProcessDecisionRevocation(
 		previousStatus,
 		previousDecisionStatus,
 		reason,
+		idempotencyKey,
 		now,
 	)
 }
@@ -148,6 +157,7 @@ func (r *applicationRepository) handleFinalApproverRevocation(
 	previousStatus models.ApplicationStatus,
 	previousDecisionStatus models.MemberDecisionStatus,
 	reason string,
+	idempotencyKey *string,
 	now time.Time,
 ) (*requests.RevocationResult, error) {
 
@@ -176,6 +186,7 @@ func (r *applicationRepository) handleFinalApproverRevocation(
 		Reason:         reason,
 		RevokedAt:      now,
 		PreviousStatus: previousDecisionStatus,
+		IdempotencyKey: idempotencyKey,
 	}
 	if err := tx.Create(&revocation).Error; err != nil {
 		return nil, fmt.Errorf("failed to create revocation record: %w", err)
@@ -190,8 +201,11 @@ func (r *applicationRepository) handleFinalApproverRevocation(
 	// Count current decisions (excluding revoked ones)
 	decidedCount, rejectedCount := r.countActiveRegularDecisions(tx, assignment.ID, regularMembers)
 	regularMemberCount := int64(len(regularMembers))
-	allRegularApproved := decidedCount == regularMemberCount && rejectedCount == 0
 	allRegularDecided := decidedCount == regularMemberCount
+	allRegularApproved, err := r.weightedQuorumReached(tx, assignment)
+	if err != nil {
+		return nil, err
+	}
 
 	config.Logger.Info("Final approver revocation - checking regular member state",
 		zap.String("applicationID", application.ID.String()),
@@ -233,7 +247,7 @@ func (r *applicationRepository) handleFinalApproverRevocation(
 	if err := tx.Save(application).Error; err != nil {
 		return nil, fmt.Errorf("failed to update application: %w", err)
 	}
-	if err := tx.Save(assignment).Error; err != nil {
+	if err := tx.Omit("decision_version").Save(assignment).Error; err != nil {
 		return nil, fmt.Errorf("failed to update assignment: %w", err)
 	}
 
@@ -267,6 +281,7 @@ func (r *applicationRepository) handleRegularMemberRevocation(
 	previousStatus models.ApplicationStatus,
 	previousDecisionStatus models.MemberDecisionStatus,
 	reason string,
+	idempotencyKey *string,
 	now time.Time,
 ) (*requests.RevocationResult, error) {
 
@@ -295,6 +310,7 @@ func (r *applicationRepository) handleRegularMemberRevocation(
 		Reason:         reason,
 		RevokedAt:      now,
 		PreviousStatus: previousDecisionStatus,
+		IdempotencyKey: idempotencyKey,
 	}
 	if err := tx.Create(&revocation).Error; err != nil {
 		return nil, fmt.Errorf("failed to create revocation record: %w", err)
@@ -310,7 +326,10 @@ func (r *applicationRepository) handleRegularMemberRevocation(
 	decidedCount, rejectedCount := r.countActiveRegularDecisions(tx, assignment.ID, regularMembers)
 	regularMemberCount := int64(len(regularMembers))
 	allRegularMembersDecided := decidedCount >= regularMemberCount
-	allRegularApproved := decidedCount == regularMemberCount && rejectedCount == 0
+	allRegularApproved, err := r.weightedQuorumReached(tx, assignment)
+	if err != nil {
+		return nil, err
+	}
 
 	config.Logger.Info("Rechecking application state after revocation",
 		zap.String("applicationID", application.ID.String()),
@@ -351,7 +370,7 @@ func (r *applicationRepository) handleRegularMemberRevocation(
 	if err := tx.Save(application).Error; err != nil {
 		return nil, fmt.Errorf("failed to update application: %w", err)
 	}
-	if err := tx.Save(assignment).Error; err != nil {
+	if err := tx.Omit("decision_version").Save(assignment).Error; err != nil {
 		return nil, fmt.Errorf("failed to update assignment: %w", err)
 	}
 
@@ -419,6 +438,7 @@ func (r *applicationRepository) countActiveRegularDecisions(
 		Where("member_approval_decisions.status IN (?)", []models.MemberDecisionStatus{
 			models.DecisionApproved,
 			models.DecisionRejected,
+			models.DecisionSkipped, // Recused members count as decided
 		}).
 		Count(&decidedCount).Error; err != nil {
 		config.Logger.Error("Failed to count decided regular members", zap.Error(err))
@@ -454,7 +474,8 @@ func (r *applicationRepository) checkRegularMemberDecisions(
 			if decision.MemberID == member.ID &&
 				decision.Status != models.DecisionRevoked &&
 				decision.DeletedAt.Time.IsZero() {
-				if decision.Status == models.DecisionApproved {
+				// Recused (skipped) members are not expected to approve
+				if decision.Status == models.DecisionApproved || decision.Status == models.DecisionSkipped {
 					approvedCount++
 				} else if decision.Status == models.DecisionRejected {
 					rejectedCount++
@@ -511,20 +532,35 @@ func (r *applicationRepository) updateAssignmentStatistics(tx *gorm.DB, assignme
 		return err
 	}
 
-	// Calculate pending count
-	stats.PendingCount = regularMemberCount - stats.ApprovedCount - stats.RejectedCount
+	// Calculate pending count (recused members have nothing left to decide)
+	skippedCount, err := r.countSkippedRegularDecisions(tx, assignmentID)
+	if err != nil {
+		return err
+	}
+	stats.PendingCount = regularMemberCount - stats.ApprovedCount - stats.RejectedCount - skippedCount
 	if stats.PendingCount < 0 {
 		stats.PendingCount = 0
 	}
 
+	tally, err := r.getDecisionWeightTally(tx, assignmentID)
+	if err != nil {
+		return err
+	}
+
 	// Update the assignment
 	if err := tx.Model(&models.ApplicationGroupAssignment{}).
 		Where("id = ?", assignmentID).
 		Updates(map[string]interface{}{
-			"approved_count": stats.ApprovedCount,
-			"rejected_count": stats.RejectedCount,
-			"pending_count":  stats.PendingCount,
-			"total_members":  regularMemberCount,
+			"approved_count":  stats.ApprovedCount,
+			"rejected_count":  stats.RejectedCount,
+			"pending_count":   stats.PendingCount,
+			"total_members":   regularMemberCount,
+			"total_weight":    tally.TotalWeight,
+			"approved_weight": tally.ApprovedWeight,
+			"rejected_weight": tally.RejectedWeight,
+			"pending_weight":  tally.PendingWeight,
+			// Decisions worked out before this change must not commit over it
+			"decision_version": gorm.Expr("decision_version + 1"),
 		}).Error; err != nil {
 		return err
 	}
@@ -534,7 +570,7 @@ func (r *applicationRepository) updateAssignmentStatistics(tx *gorm.DB, assignme
 
 // isAssignmentReadyForFinalApproval checks if an assignment is ready for final approval
 // Returns true only if:
-// 1. All regular members have approved (no pending, no rejections, no revoked)
+// 1. Weighted member approvals meet the group rule (no rejections; see ApprovalGroup.QuorumReached)
 // 2. All issues are resolved
 // 3. Application is still in review state
 func (r *applicationRepository) isAssignmentReadyForFinalApproval(
@@ -552,33 +588,88 @@ func (r *applicationRepository) isAssignmentReadyForFinalApproval(
 		return false
 	}
 
-	// Count active approved decisions (excluding revoked and deleted)
-	var approvedCount int64
-	if err := tx.Model(&models.MemberApprovalDecision{}).
-		Joins("JOIN approval_group_members ON approval_group_members.id = member_approval_decisions.member_id").
-		Where("member_approval_decisions.assignment_id = ? AND member_approval_decisions.deleted_at IS NULL", assignment.ID).
-		Where("approval_group_members.is_final_approver = ? AND approval_group_members.is_active = ?", false, true).
-		Where("member_approval_decisions.status = ?", models.DecisionApproved).
-		Count(&approvedCount).Error; err != nil {
+	// Ready only if:
+	// - Weighted approvals meet the group's rule (recused members do not need to approve)
+	// - No rejections exist
+	// - All issues resolved (checked above)
+	quorumReached, err := r.weightedQuorumReached(tx, assignment)
+	if err != nil {
 		return false
 	}
+	return quorumReached
+}
 
-	// Count any rejections (excluding revoked)
-	var rejectedCount int64
-	if err := tx.Model(&models.MemberApprovalDecision{}).
+// weightedQuorumReached applies the group's approval rule to the assignment's weighted decisions
+func (r *applicationRepository) weightedQuorumReached(tx *gorm.DB, assignment *models.ApplicationGroupAssignment) (bool, error) {
+	var group models.ApprovalGroup
+	if err := tx.Select("id", "requires_all_approvals", "minimum_approvals").
+		Where("id = ?", assignment.ApprovalGroupID).
+		First(&group).Error; err != nil {
+		return false, err
+	}
+
+	tally, err := r.getDecisionWeightTally(tx, assignment.ID)
+	if err != nil {
+		return false, err
+	}
+	return group.QuorumReached(tally.ApprovedWeight, tally.RejectedWeight, tally.PendingWeight), nil
+}
+
+// countSkippedRegularDecisions counts regular members recused from the assignment
+func (r *applicationRepository) countSkippedRegularDecisions(tx *gorm.DB, assignmentID uuid.UUID) (int64, error) {
+	var skippedCount int64
+	err := tx.Model(&models.MemberApprovalDecision{}).
 		Joins("JOIN approval_group_members ON approval_group_members.id = member_approval_decisions.member_id").
-		Where("member_approval_decisions.assignment_id = ? AND member_approval_decisions.deleted_at IS NULL", assignment.ID).
+		Where("member_approval_decisions.assignment_id = ? AND member_approval_decisions.deleted_at IS NULL", assignmentID).
 		Where("approval_group_members.is_final_approver = ? AND approval_group_members.is_active = ?", false, true).
-		Where("member_approval_decisions.status = ?", models.DecisionRejected).
-		Count(&rejectedCount).Error; err != nil {
-		return false
-	}
+		Where("member_approval_decisions.status = ?", models.DecisionSkipped).
+		Count(&skippedCount).Error
+	return skippedCount, err
+}
 
-	regularMemberCount := int64(len(regularMembers))
+// DecisionWeightTally sums the decision weights of an assignment's regular members by outcome.
+// Recused members are left out of the total.
+type DecisionWeightTally struct {
+	TotalWeight    int `json:"total_weight"`
+	ApprovedWeight int `json:"approved_weight"`
+	RejectedWeight int `json:"rejected_weight"`
+	PendingWeight  int `json:"pending_weight"`
+}
 
-	// Ready only if:
-	// - All regular members approved (count matches)
-	// - No rejections exist
-	// - All issues resolved (checked above)
-	return approvedCount == regularMemberCount && rejectedCount == 0
-}
\ No newline at end of file
+// getDecisionWeightTally totals the weighted decisions of the active regular members of an assignment
+func (r *applicationRepository) getDecisionWeightTally(tx *gorm.DB, assignmentID uuid.UUID) (*DecisionWeightTally, error) {
+	var sums struct {
+		Total    int
+		Approved int
+		Rejected int
+		Skipped  int
+	}
+
+	if err := tx.Raw(`
+		SELECT
+			COALESCE(SUM(GREATEST(m.decision_weight, 1)), 0) AS total,
+			COALESCE(SUM(CASE WHEN d.status = ? THEN GREATEST(m.decision_weight, 1) END), 0) AS approved,
+			COALESCE(SUM(CASE WHEN d.status = ? THEN GREATEST(m.decision_weight, 1) END), 0) AS rejected,
+			COALESCE(SUM(CASE WHEN d.status = ? THEN GREATEST(m.decision_weight, 1) END), 0) AS skipped
+		FROM approval_group_members m
+		LEFT JOIN member_approval_decisions d
+			ON d.member_id = m.id AND d.assignment_id = ? AND d.deleted_at IS NULL
+		WHERE m.approval_group_id = (SELECT approval_group_id FROM application_group_assignments WHERE id = ?)
+			AND m.is_active = true AND m.is_final_approver = false AND m.deleted_at IS NULL`,
+		models.DecisionApproved, models.DecisionRejected, models.DecisionSkipped,
+		assignmentID, assignmentID,
+	).Scan(&sums).Error; err != nil {
+		return nil, err
+	}
+
+	tally := &DecisionWeightTally{
+		TotalWeight:    sums.Total - sums.Skipped,
+		ApprovedWeight: sums.Approved,
+		RejectedWeight: sums.Rejected,
+	}
+	tally.PendingWeight = tally.TotalWeight - tally.ApprovedWeight - tally.RejectedWeight
+	if tally.PendingWeight < 0 {
+		tally.PendingWeight = 0
+	}
+	return tally, nil
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ratesClearanceOrder puts an application's current clearance first
+const ratesClearanceOrder = "checked_at DESC, created_at DESC"
+
+// RecordRatesClearance stores a billing check against the application
+func (r *applicationRepository) RecordRatesClearance(tx *gorm.DB, applicationID uuid.UUID, clearance *models.RatesClearance, createdBy string) (*models.RatesClearance, error) {
+	clearance.ID = uuid.Nil
+	clearance.ApplicationID = applicationID
+	clearance.CreatedBy = createdBy
+	if err := tx.Create(clearance).Error; err != nil {
+		return nil, fmt.Errorf("failed to record rates clearance: %w", err)
+	}
+	return clearance, nil
+}
+
+// GetApplicationRatesClearances lists every rates clearance check for an application, newest first
+func (r *applicationRepository) GetApplicationRatesClearances(applicationID uuid.UUID) ([]models.RatesClearance, error) {
+	var clearances []models.RatesClearance
+	if err := r.db.
+		Preload("OverriddenBy").
+		Where("application_id = ?", applicationID).
+		Order(ratesClearanceOrder).
+		Find(&clearances).Error; err != nil {
+		return nil, err
+	}
+	return clearances, nil
+}
+
+// OverrideRatesClearance accepts an application whose stand did not clear, keeping the balance
+// from the latest check so the override shows what was waived
+func (r *applicationRepository) OverrideRatesClearance(tx *gorm.DB, applicationID uuid.UUID, reason string, overriddenByID uuid.UUID) (*models.RatesClearance, error) {
+	var application models.Application
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		Where("id = ?", applicationID).
+		First(&application).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("application not found")
+		}
+		return nil, fmt.Errorf("failed to load application: %w", err)
+	}
+
+	var latest models.RatesClearance
+	if err := tx.Where("application_id = ?", applicationID).
+		Order(ratesClearanceOrder).
+		First(&latest).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("no rates clearance check recorded")
+		}
+		return nil, fmt.Errorf("failed to load rates clearance: %w", err)
+	}
+	if latest.AllowsAcceptance() {
+		return nil, errors.New("rates clearance does not need an override")
+	}
+
+	message := fmt.Sprintf("overrides %s check", latest.Status)
+	override := models.RatesClearance{
+		ApplicationID:      applicationID,
+		StandID:            latest.StandID,
+		AccountNumber:      latest.AccountNumber,
+		Status:             models.RatesClearanceOverridden,
+		OutstandingBalance: latest.OutstandingBalance,
+		Currency:           latest.Currency,
+		BillingReference:   latest.BillingReference,
+		Message:            &message,
+		CheckedAt:          time.Now(),
+		OverrideReason:     &reason,
+		OverriddenByID:     &overriddenByID,
+		CreatedBy:          overriddenByID.String(),
+	}
+	if err := tx.Create(&override).Error; err != nil {
+		return nil, fmt.Errorf("failed to record rates clearance override: %w", err)
+	}
+
+	if err := tx.Preload("OverriddenBy").Where("id = ?", override.ID).First(&override).Error; err != nil {
+		return nil, fmt.Errorf("failed to reload rates clearance: %w", err)
+	}
+	return &override, nil
+}
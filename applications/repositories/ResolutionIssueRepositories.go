@@ -1,12 +1,15 @@
 package repositories
 
 import (
+	"errors"
 	"fmt"
 	"time"
+	application_services "town-planning-backend/applications/services"
 	"town-planning-backend/db/models"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // repositories/application_repository.go
@@ -39,6 +42,8 @@ func (r *applicationRepository) MarkIssueAsResolved(
 	issue.ResolvedBy = &resolvedByUserID
 	issue.Resolution = resolutionComment
 	issue.UpdatedAt = now
+	issue.AwaitingApplicant = false
+	issue.AwaitingApplicantSince = nil
 
 	// Update the associated chat thread using direct query
 	if issue.ChatThreadID != nil {
@@ -58,6 +63,11 @@ func (r *applicationRepository) MarkIssueAsResolved(
 		return nil, fmt.Errorf("failed to update issue: %w", err)
 	}
 
+	// Resolving the last issue waiting on the applicant restarts the SLA clocks
+	if _, err := application_services.ResumeApplicationSLA(tx, issue.ApplicationID, models.SLAResumeIssueResolved, &resolvedByUserID, now); err != nil {
+		return nil, err
+	}
+
 	// Load the updated issue with relationships using separate queries
 	var updatedIssue models.ApplicationIssue
 	if err := tx.
@@ -163,3 +173,101 @@ func (r *applicationRepository) GetIssueByID(issueID string) (*models.Applicatio
 
 	return &issue, nil
 }
+
+// MaxBulkResolveIssues caps how many issues one bulk resolution can close
+const MaxBulkResolveIssues = 200
+
+// BulkResolveIssues resolves several open COLLABORATIVE issues with one shared resolution note,
+// either the issues listed or every open COLLABORATIVE issue in a category. Each assignment's
+// resolved counter moves by the number of its issues closed. Threads are left to the caller,
+// which posts a resolution message to each.
+func (r *applicationRepository) BulkResolveIssues(
+	tx *gorm.DB,
+	issueIDs []uuid.UUID,
+	category *string,
+	resolvedByUserID uuid.UUID,
+	resolutionComment string,
+) ([]models.ApplicationIssue, error) {
+	if len(issueIDs) == 0 && category == nil {
+		return nil, errors.New("issue_ids or category is required")
+	}
+
+	query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("is_resolved = ?", false)
+	if len(issueIDs) > 0 {
+		query = query.Where("id IN ?", issueIDs)
+	}
+	if category != nil {
+		query = query.Where("category = ? AND assignment_type = ?", *category, models.IssueAssignment_COLLABORATIVE)
+	}
+
+	var issues []models.ApplicationIssue
+	if err := query.Order("created_at ASC").Limit(MaxBulkResolveIssues + 1).Find(&issues).Error; err != nil {
+		return nil, fmt.Errorf("failed to load issues: %w", err)
+	}
+	if len(issueIDs) > 0 && len(issues) != len(issueIDs) {
+		return nil, errors.New("some issues were not found or are already resolved")
+	}
+	if len(issues) == 0 {
+		return nil, errors.New("no open issues to resolve")
+	}
+	if len(issues) > MaxBulkResolveIssues {
+		return nil, fmt.Errorf("at most %d issues can be resolved at once", MaxBulkResolveIssues)
+	}
+
+	resolvedPerAssignment := map[uuid.UUID]int{}
+	ids := make([]uuid.UUID, 0, len(issues))
+	for _, issue := range issues {
+		// Issues assigned to a person are resolved by that person, one at a time
+		if issue.AssignmentType != models.IssueAssignment_COLLABORATIVE {
+			return nil, errors.New("only COLLABORATIVE issues can be resolved in bulk")
+		}
+		resolvedPerAssignment[issue.AssignmentID]++
+		ids = append(ids, issue.ID)
+	}
+
+	now := time.Now()
+	if err := tx.Model(&models.ApplicationIssue{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{
+			"is_resolved": true,
+			"resolved_at": now,
+			"resolved_by": resolvedByUserID,
+			"resolution":  resolutionComment,
+			"updated_at":  now,
+
+			"awaiting_applicant":       false,
+			"awaiting_applicant_since": nil,
+		}).Error; err != nil {
+		return nil, fmt.Errorf("failed to resolve issues: %w", err)
+	}
+
+	// Resolving the last issues waiting on the applicant restarts the SLA clocks
+	resumed := map[uuid.UUID]bool{}
+	for _, issue := range issues {
+		if issue.AwaitingApplicant && !resumed[issue.ApplicationID] {
+			resumed[issue.ApplicationID] = true
+			if _, err := application_services.ResumeApplicationSLA(tx, issue.ApplicationID, models.SLAResumeIssueResolved, &resolvedByUserID, now); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for assignmentID, count := range resolvedPerAssignment {
+		if err := tx.Model(&models.ApplicationGroupAssignment{}).
+			Where("id = ?", assignmentID).
+			UpdateColumn("issues_resolved", gorm.Expr("issues_resolved + ?", count)).Error; err != nil {
+			return nil, fmt.Errorf("failed to update assignment issue counts: %w", err)
+		}
+	}
+
+	var resolved []models.ApplicationIssue
+	if err := tx.
+		Preload("RaisedByUser").
+		Preload("ResolvedByUser").
+		Where("id IN ?", ids).
+		Order("created_at ASC").
+		Find(&resolved).Error; err != nil {
+		return nil, fmt.Errorf("failed to load resolved issues: %w", err)
+	}
+	return resolved, nil
+}
@@ -0,0 +1,168 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ChecklistCompletion is how much of the group's checklist a member ticked with their decision
+type ChecklistCompletion struct {
+	Total           int                            `json:"total"`
+	Mandatory       int                            `json:"mandatory"`
+	Ticked          int                            `json:"ticked"`
+	MandatoryTicked int                            `json:"mandatory_ticked"`
+	Complete        bool                           `json:"complete"` // Every mandatory item ticked
+	Items           []models.DecisionChecklistItem `json:"items"`
+}
+
+// GetReviewChecklist returns an approval group's checklist in display order
+func (r *applicationRepository) GetReviewChecklist(groupID uuid.UUID, includeInactive bool) ([]models.ReviewChecklistItem, error) {
+	return reviewChecklist(r.db, groupID, includeInactive)
+}
+
+func reviewChecklist(db *gorm.DB, groupID uuid.UUID, includeInactive bool) ([]models.ReviewChecklistItem, error) {
+	query := db.Where("approval_group_id = ?", groupID)
+	if !includeInactive {
+		query = query.Where("is_active = ?", true)
+	}
+
+	var items []models.ReviewChecklistItem
+	if err := query.Order("sort_order ASC, created_at ASC").Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch review checklist: %w", err)
+	}
+	return items, nil
+}
+
+// CreateReviewChecklistItem adds an item to an approval group's checklist
+func (r *applicationRepository) CreateReviewChecklistItem(tx *gorm.DB, item *models.ReviewChecklistItem) (*models.ReviewChecklistItem, error) {
+	var group models.ApprovalGroup
+	if err := tx.Where("id = ?", item.ApprovalGroupID).First(&group).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("approval group not found")
+		}
+		return nil, fmt.Errorf("failed to load approval group: %w", err)
+	}
+
+	if err := tx.Create(item).Error; err != nil {
+		return nil, fmt.Errorf("failed to create checklist item: %w", err)
+	}
+	// GORM skips zero values for columns with a default, so an optional item would be stored
+	// as mandatory
+	if !item.IsMandatory {
+		if err := tx.Model(item).Update("is_mandatory", false).Error; err != nil {
+			return nil, fmt.Errorf("failed to create checklist item: %w", err)
+		}
+	}
+	return item, nil
+}
+
+// UpdateReviewChecklistItem changes an item of an approval group's checklist. Decisions already
+// made keep the items as they were ticked.
+func (r *applicationRepository) UpdateReviewChecklistItem(tx *gorm.DB, groupID uuid.UUID, itemID uuid.UUID, updates map[string]interface{}) (*models.ReviewChecklistItem, error) {
+	var item models.ReviewChecklistItem
+	if err := tx.Where("id = ? AND approval_group_id = ?", itemID, groupID).First(&item).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("checklist item not found")
+		}
+		return nil, fmt.Errorf("failed to load checklist item: %w", err)
+	}
+
+	if err := tx.Model(&item).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to update checklist item: %w", err)
+	}
+	if err := tx.Where("id = ?", itemID).First(&item).Error; err != nil {
+		return nil, fmt.Errorf("failed to reload checklist item: %w", err)
+	}
+	return &item, nil
+}
+
+// UpdateDecisionCommentPolicy sets which of the group's member decisions must come with a comment
+func (r *applicationRepository) UpdateDecisionCommentPolicy(tx *gorm.DB, groupID uuid.UUID, policy models.DecisionCommentPolicy, updatedBy string) (*models.ApprovalGroup, error) {
+	var group models.ApprovalGroup
+	if err := tx.Where("id = ?", groupID).First(&group).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("approval group not found")
+		}
+		return nil, fmt.Errorf("failed to load approval group: %w", err)
+	}
+
+	if err := tx.Model(&group).Updates(map[string]interface{}{
+		"comment_policy": policy,
+		"updated_by":     &updatedBy,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update comment policy: %w", err)
+	}
+	group.CommentPolicy = policy
+	group.UpdatedBy = &updatedBy
+	return &group, nil
+}
+
+// tickChecklist records the items a member ticked, refusing the approval when a mandatory item
+// was left unticked or an item is not on the group's checklist
+func tickChecklist(checklist []models.ReviewChecklistItem, checkedItemIDs []uuid.UUID, decisionID uuid.UUID, now time.Time) ([]models.DecisionChecklistItem, error) {
+	checked := make(map[uuid.UUID]bool, len(checkedItemIDs))
+	for _, id := range checkedItemIDs {
+		checked[id] = true
+	}
+
+	var ticked []models.DecisionChecklistItem
+	var missing []string
+	for _, item := range checklist {
+		if !checked[item.ID] {
+			if item.IsMandatory {
+				missing = append(missing, item.Label)
+			}
+			continue
+		}
+		delete(checked, item.ID)
+		ticked = append(ticked, models.DecisionChecklistItem{
+			ID:              uuid.New(),
+			DecisionID:      decisionID,
+			ChecklistItemID: item.ID,
+			Label:           item.Label,
+			IsMandatory:     item.IsMandatory,
+			CheckedAt:       now,
+		})
+	}
+
+	if len(checked) > 0 {
+		return nil, errors.New("checklist contains items that are not on this approval group's checklist")
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("review checklist incomplete: %s", strings.Join(missing, ", "))
+	}
+	return ticked, nil
+}
+
+// checklistCompletion summarises a decision's ticked items against the group's checklist
+func checklistCompletion(checklist []models.ReviewChecklistItem, ticked []models.DecisionChecklistItem) *ChecklistCompletion {
+	completion := &ChecklistCompletion{Items: ticked}
+	if completion.Items == nil {
+		completion.Items = []models.DecisionChecklistItem{}
+	}
+
+	tickedIDs := make(map[uuid.UUID]bool, len(ticked))
+	for _, item := range ticked {
+		tickedIDs[item.ChecklistItemID] = true
+	}
+	for _, item := range checklist {
+		completion.Total++
+		if item.IsMandatory {
+			completion.Mandatory++
+		}
+		if tickedIDs[item.ID] {
+			completion.Ticked++
+			if item.IsMandatory {
+				completion.MandatoryTicked++
+			}
+		}
+	}
+	completion.Complete = completion.MandatoryTicked == completion.Mandatory
+	return completion
+}
@@ -0,0 +1,151 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	application_services "town-planning-backend/applications/services"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GetActiveRiskScoringProfile returns the active profile, saving the default profile the first
+// time applications are scored so every assessment can point at the profile it used
+func (r *applicationRepository) GetActiveRiskScoringProfile(tx *gorm.DB) (*models.RiskScoringProfile, error) {
+	var profile models.RiskScoringProfile
+	err := tx.Preload("SeniorApprovalGroup").
+		Where("is_active = ?", true).
+		Order("created_at DESC").
+		First(&profile).Error
+	if err == nil {
+		return &profile, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to load risk scoring profile: %w", err)
+	}
+
+	defaultProfile := application_services.DefaultRiskScoringProfile()
+	if err := tx.Create(defaultProfile).Error; err != nil {
+		return nil, fmt.Errorf("failed to create default risk scoring profile: %w", err)
+	}
+	return defaultProfile, nil
+}
+
+// SaveRiskScoringProfile stores a new active profile. The previous profile is kept, inactive,
+// because earlier assessments refer to it.
+func (r *applicationRepository) SaveRiskScoringProfile(tx *gorm.DB, profile *models.RiskScoringProfile) error {
+	if profile.SeniorApprovalGroupID != nil {
+		var group models.ApprovalGroup
+		if err := tx.Where("id = ? AND is_active = ?", *profile.SeniorApprovalGroupID, true).First(&group).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("senior approval group not found")
+			}
+			return fmt.Errorf("failed to load senior approval group: %w", err)
+		}
+	}
+
+	if err := tx.Model(&models.RiskScoringProfile{}).
+		Where("is_active = ?", true).
+		Updates(map[string]interface{}{"is_active": false, "updated_by": profile.CreatedBy}).Error; err != nil {
+		return fmt.Errorf("failed to deactivate previous risk scoring profile: %w", err)
+	}
+
+	profile.ID = uuid.Nil
+	profile.IsActive = true
+	if err := tx.Create(profile).Error; err != nil {
+		return fmt.Errorf("failed to save risk scoring profile: %w", err)
+	}
+	return nil
+}
+
+// SetDevelopmentCategoryRiskPoints sets the points a development category adds to risk scores
+func (r *applicationRepository) SetDevelopmentCategoryRiskPoints(categoryID uuid.UUID, points int) (*models.DevelopmentCategory, error) {
+	var category models.DevelopmentCategory
+	if err := r.db.Where("id = ?", categoryID).First(&category).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("development category not found")
+		}
+		return nil, fmt.Errorf("failed to load development category: %w", err)
+	}
+
+	if err := r.db.Model(&category).Update("risk_points", points).Error; err != nil {
+		return nil, fmt.Errorf("failed to update development category: %w", err)
+	}
+	category.RiskPoints = points
+	return &category, nil
+}
+
+// CountApplicantRejections counts the applicant's other applications that were rejected
+func (r *applicationRepository) CountApplicantRejections(tx *gorm.DB, applicantID uuid.UUID, excludeApplicationID uuid.UUID) (int64, error) {
+	var count int64
+	if err := tx.Model(&models.Application{}).
+		Where("applicant_id = ? AND id <> ? AND status = ?", applicantID, excludeApplicationID, models.RejectedApplication).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count prior rejections: %w", err)
+	}
+	return count, nil
+}
+
+// RecordRiskAssessment stores a risk assessment and makes it the application's current score
+func (r *applicationRepository) RecordRiskAssessment(tx *gorm.DB, applicationID uuid.UUID, assessment *models.ApplicationRiskAssessment, createdBy string) (*models.ApplicationRiskAssessment, error) {
+	assessment.ID = uuid.Nil
+	assessment.ApplicationID = applicationID
+	assessment.CreatedBy = createdBy
+	if err := tx.Create(assessment).Error; err != nil {
+		return nil, fmt.Errorf("failed to record risk assessment: %w", err)
+	}
+
+	if err := tx.Model(&models.Application{}).
+		Where("id = ?", applicationID).
+		Updates(map[string]interface{}{
+			"risk_score":       assessment.Score,
+			"risk_level":       assessment.Level,
+			"risk_assessed_at": assessment.AssessedAt,
+		}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update application risk score: %w", err)
+	}
+	return assessment, nil
+}
+
+// GetApplicationRiskAssessments lists every risk assessment for an application, newest first
+func (r *applicationRepository) GetApplicationRiskAssessments(applicationID uuid.UUID) ([]models.ApplicationRiskAssessment, error) {
+	var assessments []models.ApplicationRiskAssessment
+	if err := r.db.
+		Where("application_id = ?", applicationID).
+		Order("assessed_at DESC").
+		Find(&assessments).Error; err != nil {
+		return nil, err
+	}
+	return assessments, nil
+}
+
+// GetApproverQueue lists the applications awaiting the user's decision, highest risk first and
+// then oldest submission first. Applications not yet scored come last.
+func (r *applicationRepository) GetApproverQueue(userID uuid.UUID, level *models.RiskLevel) ([]models.Application, error) {
+	pendingForUser := r.db.Model(&models.MemberApprovalDecision{}).
+		Select("application_group_assignments.application_id").
+		Joins("JOIN application_group_assignments ON application_group_assignments.id = member_approval_decisions.assignment_id").
+		Where("member_approval_decisions.user_id = ? AND member_approval_decisions.status = ?", userID, models.DecisionPending).
+		Where("application_group_assignments.is_active = ? AND application_group_assignments.deleted_at IS NULL", true)
+
+	query := r.db.
+		Preload("Applicant").
+		Preload("Stand").
+		Preload("Tariff.DevelopmentCategory").
+		Preload("ApprovalGroup").
+		Where("id IN (?)", pendingForUser).
+		Where("status NOT IN ?", []models.ApplicationStatus{models.ApprovedApplication, models.RejectedApplication})
+	if level != nil {
+		query = query.Where("risk_level = ?", *level)
+	}
+
+	var applications []models.Application
+	if err := query.
+		Order("risk_score DESC NULLS LAST").
+		Order("submission_date ASC").
+		Find(&applications).Error; err != nil {
+		return nil, err
+	}
+	return applications, nil
+}
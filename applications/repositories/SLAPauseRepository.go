@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	application_services "town-planning-backend/applications/services"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SetIssueAwaitingApplicant flags an open issue as waiting on the applicant, pausing the
+// application's SLA clocks, or clears the flag, resuming them once no other issue is waiting.
+// The pause opened or closed by the change is returned, nil when the clocks did not change.
+func (r *applicationRepository) SetIssueAwaitingApplicant(
+	tx *gorm.DB,
+	issueID uuid.UUID,
+	awaiting bool,
+	userID uuid.UUID,
+) (*models.ApplicationIssue, *models.ApplicationSLAPause, error) {
+	var issue models.ApplicationIssue
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", issueID).
+		First(&issue).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New("issue not found")
+		}
+		return nil, nil, fmt.Errorf("failed to fetch issue: %w", err)
+	}
+	if issue.IsResolved {
+		return nil, nil, errors.New("issue is already resolved")
+	}
+	if issue.AwaitingApplicant == awaiting {
+		if awaiting {
+			return nil, nil, errors.New("issue is already awaiting the applicant")
+		}
+		return nil, nil, errors.New("issue is not awaiting the applicant")
+	}
+
+	now := time.Now()
+	issue.AwaitingApplicant = awaiting
+	issue.AwaitingApplicantSince = nil
+	if awaiting {
+		issue.AwaitingApplicantSince = &now
+	}
+	if err := tx.Model(&issue).Updates(map[string]interface{}{
+		"awaiting_applicant":       issue.AwaitingApplicant,
+		"awaiting_applicant_since": issue.AwaitingApplicantSince,
+		"updated_at":               now,
+	}).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to update issue: %w", err)
+	}
+
+	if awaiting {
+		pause, opened, err := application_services.PauseApplicationSLA(tx, issue.ApplicationID, issue.ID, userID, now)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !opened {
+			pause = nil
+		}
+		return &issue, pause, nil
+	}
+
+	pause, err := application_services.ResumeApplicationSLA(tx, issue.ApplicationID, models.SLAResumeFlagCleared, &userID, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &issue, pause, nil
+}
+
+// GetSLAPauses returns the SLA pauses of the given applications in the order they began, by
+// application ID
+func (r *applicationRepository) GetSLAPauses(applicationIDs []uuid.UUID) (map[uuid.UUID][]models.ApplicationSLAPause, error) {
+	pauses := make(map[uuid.UUID][]models.ApplicationSLAPause, len(applicationIDs))
+	if len(applicationIDs) == 0 {
+		return pauses, nil
+	}
+
+	var found []models.ApplicationSLAPause
+	if err := r.db.Where("application_id IN ?", applicationIDs).
+		Order("paused_at ASC").
+		Find(&found).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch SLA pauses: %w", err)
+	}
+	for _, pause := range found {
+		pauses[pause.ApplicationID] = append(pauses[pause.ApplicationID], pause)
+	}
+	return pauses, nil
+}
+
+// GetApplicationSLAPauses returns an application's SLA pauses with the issues that opened them,
+// in the order they began
+func (r *applicationRepository) GetApplicationSLAPauses(applicationID uuid.UUID) ([]models.ApplicationSLAPause, error) {
+	var pauses []models.ApplicationSLAPause
+	if err := r.db.Preload("Issue", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "application_id", "title", "awaiting_applicant", "awaiting_applicant_since", "is_resolved")
+	}).
+		Where("application_id = ?", applicationID).
+		Order("paused_at ASC").
+		Find(&pauses).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch SLA pauses: %w", err)
+	}
+	return pauses, nil
+}
@@ -0,0 +1,133 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateScheduledMessage stores a message to be posted to the thread at scheduledFor. The author
+// must be a participant in the thread now; they are checked again when the message is sent.
+func (r *applicationRepository) CreateScheduledMessage(
+	tx *gorm.DB,
+	threadID uuid.UUID,
+	senderID uuid.UUID,
+	content string,
+	scheduledFor time.Time,
+	createdBy string,
+) (*models.ScheduledChatMessage, error) {
+	if _, err := r.VerifyThreadAccess(tx, threadID.String(), senderID); err != nil {
+		return nil, err
+	}
+
+	scheduled := models.ScheduledChatMessage{
+		ThreadID:     threadID,
+		SenderID:     senderID,
+		Content:      content,
+		ScheduledFor: scheduledFor,
+		Status:       models.ScheduledMessagePending,
+		CreatedBy:    createdBy,
+	}
+	if err := tx.Create(&scheduled).Error; err != nil {
+		return nil, fmt.Errorf("failed to schedule message: %w", err)
+	}
+	return &scheduled, nil
+}
+
+// GetScheduledMessages lists the user's messages still waiting to be sent, soonest first,
+// optionally for one thread
+func (r *applicationRepository) GetScheduledMessages(senderID uuid.UUID, threadID *uuid.UUID) ([]models.ScheduledChatMessage, error) {
+	query := r.db.
+		Preload("Thread").
+		Where("sender_id = ? AND status = ?", senderID, models.ScheduledMessagePending)
+	if threadID != nil {
+		query = query.Where("thread_id = ?", *threadID)
+	}
+
+	var scheduled []models.ScheduledChatMessage
+	if err := query.Order("scheduled_for ASC").Find(&scheduled).Error; err != nil {
+		return nil, err
+	}
+	return scheduled, nil
+}
+
+// CancelScheduledMessage withdraws a message before it is sent. Only its author may cancel it.
+func (r *applicationRepository) CancelScheduledMessage(tx *gorm.DB, scheduledID uuid.UUID, userID uuid.UUID) (*models.ScheduledChatMessage, error) {
+	var scheduled models.ScheduledChatMessage
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", scheduledID).
+		First(&scheduled).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("scheduled message not found")
+		}
+		return nil, fmt.Errorf("failed to load scheduled message: %w", err)
+	}
+
+	if scheduled.SenderID != userID {
+		return nil, errors.New("only the author can cancel a scheduled message")
+	}
+	switch scheduled.Status {
+	case models.ScheduledMessageSent:
+		return nil, errors.New("scheduled message has already been sent")
+	case models.ScheduledMessageCancelled:
+		return nil, errors.New("scheduled message is already cancelled")
+	case models.ScheduledMessageFailed:
+		return nil, errors.New("scheduled message could not be sent")
+	}
+
+	now := time.Now()
+	if err := tx.Model(&scheduled).Updates(map[string]interface{}{
+		"status":       models.ScheduledMessageCancelled,
+		"cancelled_at": now,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to cancel scheduled message: %w", err)
+	}
+	scheduled.Status = models.ScheduledMessageCancelled
+	scheduled.CancelledAt = &now
+	return &scheduled, nil
+}
+
+// ClaimDueScheduledMessage locks the next message that is due, skipping any another dispatcher
+// holds. It returns nil when nothing is due.
+func (r *applicationRepository) ClaimDueScheduledMessage(tx *gorm.DB, now time.Time) (*models.ScheduledChatMessage, error) {
+	var scheduled models.ScheduledChatMessage
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status = ? AND scheduled_for <= ?", models.ScheduledMessagePending, now).
+		Order("scheduled_for ASC").
+		First(&scheduled).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim scheduled message: %w", err)
+	}
+	return &scheduled, nil
+}
+
+// MarkScheduledMessageSent links the scheduled message to the chat message it was posted as
+func (r *applicationRepository) MarkScheduledMessageSent(tx *gorm.DB, scheduled *models.ScheduledChatMessage, messageID uuid.UUID) error {
+	if err := tx.Model(scheduled).Updates(map[string]interface{}{
+		"status":          models.ScheduledMessageSent,
+		"sent_message_id": messageID,
+		"sent_at":         time.Now(),
+	}).Error; err != nil {
+		return fmt.Errorf("failed to mark scheduled message sent: %w", err)
+	}
+	return nil
+}
+
+// MarkScheduledMessageFailed records why a due message could not be posted
+func (r *applicationRepository) MarkScheduledMessageFailed(tx *gorm.DB, scheduled *models.ScheduledChatMessage, reason string) error {
+	if err := tx.Model(scheduled).Updates(map[string]interface{}{
+		"status": models.ScheduledMessageFailed,
+		"error":  reason,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to mark scheduled message failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// secondOpinionReviewStatuses are the statuses in which an application is still being reviewed
+// by its approval group
+var secondOpinionReviewStatuses = []models.ApplicationStatus{
+	models.SubmittedApplication,
+	models.UnderReviewApplication,
+	models.PendingApprovalApplication,
+	models.DepartmentReviewApplication,
+	models.FinalReviewApplication,
+}
+
+// isActiveGroupMember reports whether the user is an active member of the approval group
+func isActiveGroupMember(tx *gorm.DB, groupID, userID uuid.UUID) (bool, error) {
+	var count int64
+	if err := tx.Model(&models.ApprovalGroupMember{}).
+		Where("approval_group_id = ? AND user_id = ? AND is_active = ?", groupID, userID, true).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check group membership: %w", err)
+	}
+	return count > 0, nil
+}
+
+// RequestSecondOpinion asks a user outside the application's approval group for advice. Only
+// members of the group can ask, while the application is still under review.
+func (r *applicationRepository) RequestSecondOpinion(tx *gorm.DB, applicationID, reviewerID, requestedByID uuid.UUID, question string, dueDate time.Time) (*models.SecondOpinionRequest, error) {
+	var application models.Application
+	if err := tx.Select("id", "status", "assigned_group_id").Where("id = ?", applicationID).First(&application).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("application not found")
+		}
+		return nil, fmt.Errorf("failed to load application: %w", err)
+	}
+	if application.AssignedGroupID == nil {
+		return nil, errors.New("application has no approval group")
+	}
+
+	underReview := false
+	for _, status := range secondOpinionReviewStatuses {
+		if application.Status == status {
+			underReview = true
+			break
+		}
+	}
+	if !underReview {
+		return nil, errors.New("application is no longer under review")
+	}
+
+	isMember, err := isActiveGroupMember(tx, *application.AssignedGroupID, requestedByID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, errors.New("only members of the approval group can request a second opinion")
+	}
+
+	var reviewer models.User
+	if err := tx.Select("id").Where("id = ? AND active = ?", reviewerID, true).First(&reviewer).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("reviewer not found")
+		}
+		return nil, fmt.Errorf("failed to load reviewer: %w", err)
+	}
+
+	reviewerIsMember, err := isActiveGroupMember(tx, *application.AssignedGroupID, reviewerID)
+	if err != nil {
+		return nil, err
+	}
+	if reviewerIsMember {
+		return nil, errors.New("reviewer is a member of the approval group")
+	}
+
+	var pending int64
+	if err := tx.Model(&models.SecondOpinionRequest{}).
+		Where("application_id = ? AND reviewer_id = ? AND status = ?", applicationID, reviewerID, models.SecondOpinionPending).
+		Count(&pending).Error; err != nil {
+		return nil, fmt.Errorf("failed to check existing second opinions: %w", err)
+	}
+	if pending > 0 {
+		return nil, errors.New("reviewer already has a pending second opinion for this application")
+	}
+
+	request := models.SecondOpinionRequest{
+		ApplicationID:   applicationID,
+		ApprovalGroupID: *application.AssignedGroupID,
+		ReviewerID:      reviewerID,
+		Status:          models.SecondOpinionPending,
+		RequestedByID:   requestedByID,
+		RequestedAt:     time.Now(),
+		Question:        question,
+		DueDate:         dueDate,
+	}
+	if err := tx.Create(&request).Error; err != nil {
+		return nil, fmt.Errorf("failed to request second opinion: %w", err)
+	}
+
+	var created models.SecondOpinionRequest
+	if err := r.secondOpinionQuery(tx).Where("id = ?", request.ID).First(&created).Error; err != nil {
+		return nil, fmt.Errorf("failed to reload second opinion: %w", err)
+	}
+	return &created, nil
+}
+
+func (r *applicationRepository) secondOpinionQuery(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("ApprovalGroup").
+		Preload("Reviewer").
+		Preload("RequestedBy")
+}
+
+// GetApplicationSecondOpinions lists every second opinion asked on an application, newest first
+func (r *applicationRepository) GetApplicationSecondOpinions(applicationID uuid.UUID) ([]models.SecondOpinionRequest, error) {
+	var requests []models.SecondOpinionRequest
+	if err := r.secondOpinionQuery(r.db).
+		Where("application_id = ?", applicationID).
+		Order("requested_at DESC").
+		Find(&requests).Error; err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// GetPendingSecondOpinions lists the second opinions waiting on a reviewer, soonest due first
+func (r *applicationRepository) GetPendingSecondOpinions(reviewerID uuid.UUID) ([]models.SecondOpinionRequest, error) {
+	var requests []models.SecondOpinionRequest
+	if err := r.secondOpinionQuery(r.db).
+		Preload("Application").
+		Preload("Application.Applicant").
+		Where("reviewer_id = ? AND status = ?", reviewerID, models.SecondOpinionPending).
+		Order("due_date ASC").
+		Find(&requests).Error; err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// lockPendingSecondOpinion loads a pending second opinion for update
+func lockPendingSecondOpinion(tx *gorm.DB, requestID uuid.UUID) (*models.SecondOpinionRequest, error) {
+	var request models.SecondOpinionRequest
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", requestID).
+		First(&request).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("second opinion not found")
+		}
+		return nil, fmt.Errorf("failed to load second opinion: %w", err)
+	}
+	if request.Status != models.SecondOpinionPending {
+		return nil, errors.New("second opinion is not pending")
+	}
+	return &request, nil
+}
+
+// SubmitSecondOpinion records the reviewer's advice and posts it to the application's comments
+// so the approval group sees it. It is not a decision and leaves the group's counts untouched.
+func (r *applicationRepository) SubmitSecondOpinion(tx *gorm.DB, requestID, reviewerID uuid.UUID, opinion string) (*models.SecondOpinionRequest, error) {
+	request, err := lockPendingSecondOpinion(tx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if request.ReviewerID != reviewerID {
+		return nil, errors.New("second opinion was requested from a different reviewer")
+	}
+
+	var reviewer models.User
+	if err := tx.Select("id", "first_name", "last_name").Where("id = ?", reviewerID).First(&reviewer).Error; err != nil {
+		return nil, fmt.Errorf("failed to load reviewer: %w", err)
+	}
+
+	comment := models.Comment{
+		ID:            uuid.New(),
+		ApplicationID: request.ApplicationID,
+		CommentType:   models.CommentTypeSecondOpinion,
+		Content:       opinion,
+		UserID:        reviewerID,
+		CreatedBy:     fmt.Sprintf("%s %s", reviewer.FirstName, reviewer.LastName),
+	}
+	if err := tx.Create(&comment).Error; err != nil {
+		return nil, fmt.Errorf("failed to record second opinion comment: %w", err)
+	}
+
+	now := time.Now()
+	if err := tx.Model(request).Updates(map[string]interface{}{
+		"status":       models.SecondOpinionSubmitted,
+		"opinion":      opinion,
+		"comment_id":   comment.ID,
+		"submitted_at": now,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to record second opinion: %w", err)
+	}
+
+	var submitted models.SecondOpinionRequest
+	if err := r.secondOpinionQuery(tx).Where("id = ?", request.ID).First(&submitted).Error; err != nil {
+		return nil, fmt.Errorf("failed to reload second opinion: %w", err)
+	}
+	return &submitted, nil
+}
+
+// WithdrawSecondOpinion cancels a pending second opinion. Only the user who asked can withdraw it.
+func (r *applicationRepository) WithdrawSecondOpinion(tx *gorm.DB, requestID, userID uuid.UUID) (*models.SecondOpinionRequest, error) {
+	request, err := lockPendingSecondOpinion(tx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if request.RequestedByID != userID {
+		return nil, errors.New("second opinion was requested by another user")
+	}
+
+	if err := tx.Model(request).Updates(map[string]interface{}{
+		"status":       models.SecondOpinionWithdrawn,
+		"withdrawn_at": time.Now(),
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to withdraw second opinion: %w", err)
+	}
+
+	var withdrawn models.SecondOpinionRequest
+	if err := r.secondOpinionQuery(tx).Where("id = ?", request.ID).First(&withdrawn).Error; err != nil {
+		return nil, fmt.Errorf("failed to reload second opinion: %w", err)
+	}
+	return &withdrawn, nil
+}
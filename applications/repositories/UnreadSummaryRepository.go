@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"fmt"
+	"sort"
+	"time"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+)
+
+// ApplicationUnreadSummary is what is waiting for a user on one application
+type ApplicationUnreadSummary struct {
+	ApplicationID    uuid.UUID `json:"application_id"`
+	PlanNumber       string    `json:"plan_number"`
+	UnreadMessages   int64     `json:"unread_messages"`
+	MutedUnread      int64     `json:"muted_unread"`
+	AssignedIssues   int64     `json:"assigned_issues"`
+	PendingDecisions int64     `json:"pending_decisions"`
+}
+
+// UnreadSummary drives a user's badges. Unread messages in muted threads are kept out of
+// TotalUnread and counted in MutedUnread instead.
+type UnreadSummary struct {
+	TotalUnread      int64                      `json:"total_unread"`
+	MutedUnread      int64                      `json:"muted_unread"`
+	AssignedIssues   int64                      `json:"assigned_issues"`
+	PendingDecisions int64                      `json:"pending_decisions"`
+	Applications     []ApplicationUnreadSummary `json:"applications"`
+}
+
+// GetUnreadSummary counts a user's unread messages, unresolved issues assigned to them and
+// pending decisions, per application, in one query. Unread counts come from the participant
+// thread state; issues count when assigned to the user directly or to their group membership.
+func (r *applicationRepository) GetUnreadSummary(userID uuid.UUID) (*UnreadSummary, error) {
+	var rows []struct {
+		Kind          string
+		ApplicationID uuid.UUID
+		PlanNumber    string
+		Count         int64
+	}
+	now := time.Now()
+	if err := r.db.Raw(`
+		SELECT s.kind, s.application_id, a.plan_number, s.count
+		FROM (
+			SELECT CASE
+					WHEN ts.is_muted = true AND (ts.muted_until IS NULL OR ts.muted_until > ?) THEN 'MUTED'
+					ELSE 'UNREAD'
+				END AS kind,
+				t.application_id, SUM(ts.unread_count) AS count
+			FROM participant_thread_states ts
+			JOIN chat_threads t ON t.id = ts.thread_id
+			JOIN chat_participants p
+				ON p.thread_id = ts.thread_id AND p.user_id = ts.user_id AND p.is_active = true
+			WHERE ts.user_id = ? AND ts.unread_count > 0
+			GROUP BY 1, t.application_id
+
+			UNION ALL
+
+			SELECT 'ISSUE', i.application_id, COUNT(*)
+			FROM application_issues i
+			LEFT JOIN approval_group_members m
+				ON m.id = i.assigned_to_group_member_id AND m.deleted_at IS NULL
+			WHERE i.is_resolved = false AND i.deleted_at IS NULL
+				AND (i.assigned_to_user_id = ? OR m.user_id = ?)
+			GROUP BY i.application_id
+
+			UNION ALL
+
+			SELECT 'DECISION', g.application_id, COUNT(*)
+			FROM member_approval_decisions d
+			JOIN application_group_assignments g ON g.id = d.assignment_id
+			WHERE d.user_id = ? AND d.status = ? AND d.deleted_at IS NULL
+				AND g.is_active = true AND g.deleted_at IS NULL
+			GROUP BY g.application_id
+		) s
+		JOIN applications a ON a.id = s.application_id
+		WHERE a.deleted_at IS NULL AND (s.kind <> 'DECISION' OR a.status NOT IN ?)`,
+		now, userID, userID, userID, userID, models.DecisionPending,
+		[]models.ApplicationStatus{models.ApprovedApplication, models.RejectedApplication}).
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to summarise unread items: %w", err)
+	}
+
+	summary := &UnreadSummary{Applications: []ApplicationUnreadSummary{}}
+	byApplication := map[uuid.UUID]*ApplicationUnreadSummary{}
+	for _, row := range rows {
+		application, ok := byApplication[row.ApplicationID]
+		if !ok {
+			application = &ApplicationUnreadSummary{ApplicationID: row.ApplicationID, PlanNumber: row.PlanNumber}
+			byApplication[row.ApplicationID] = application
+		}
+		switch row.Kind {
+		case "UNREAD":
+			application.UnreadMessages += row.Count
+			summary.TotalUnread += row.Count
+		case "MUTED":
+			application.MutedUnread += row.Count
+			summary.MutedUnread += row.Count
+		case "ISSUE":
+			application.AssignedIssues += row.Count
+			summary.AssignedIssues += row.Count
+		case "DECISION":
+			application.PendingDecisions += row.Count
+			summary.PendingDecisions += row.Count
+		}
+	}
+
+	for _, application := range byApplication {
+		summary.Applications = append(summary.Applications, *application)
+	}
+	// Applications with the most unread messages first
+	sort.Slice(summary.Applications, func(i, j int) bool {
+		a, b := summary.Applications[i], summary.Applications[j]
+		if a.UnreadMessages != b.UnreadMessages {
+			return a.UnreadMessages > b.UnreadMessages
+		}
+		return a.PlanNumber < b.PlanNumber
+	})
+	return summary, nil
+}
@@ -3,6 +3,7 @@ package repositories
 import (
 	"fmt"
 	"time"
+	application_services "town-planning-backend/applications/services"
 	"town-planning-backend/db/models"
 
 	"github.com/google/uuid"
@@ -18,11 +19,13 @@ type ApprovalResult struct {
 	ApprovedCount         int
 	TotalMembers          int
 	UnresolvedIssues      int
+	ClosingMessages       []models.ChatMessage `json:"-"` // Posted in the threads frozen by the decision
 }
 
 type RejectionResult struct {
 	ApplicationStatus models.ApplicationStatus
 	IsFinalApprover   bool
+	ClosingMessages   []models.ChatMessage `json:"-"` // Posted in the threads frozen by the decision
 }
 
 
@@ -171,21 +174,16 @@ func (r *applicationRepository) RecalculateApplicationCosts(
 	}
 
 	// Calculate costs
-	areaCost := planArea.Mul(tariff.PricePerSquareMeter)
-	subtotal := areaCost.Add(tariff.PermitFee).Add(tariff.InspectionFee)
-	developmentLevy := subtotal.Mul(tariff.DevelopmentLevyPercent).Div(decimal.NewFromInt(100))
-	totalBeforeVAT := subtotal.Add(developmentLevy)
-	vatAmount := totalBeforeVAT.Mul(vatRate.Rate)
-	totalCost := totalBeforeVAT.Add(vatAmount)
+	costs := application_services.CalculateApplicationCosts(tariff, vatRate.Rate, planArea)
 
 	// Update application
 	updates := map[string]interface{}{
 		"plan_area":        planArea,
 		"tariff_id":        tariffID,
 		"vat_rate_id":      vatRateID,
-		"development_levy": developmentLevy,
-		"vat_amount":       vatAmount,
-		"total_cost":       totalCost,
+		"development_levy": costs.DevelopmentLevy,
+		"vat_amount":       costs.VATAmount,
+		"total_cost":       costs.TotalCost,
 	}
 
 	if err := tx.Model(&models.Application{}).
@@ -195,12 +193,12 @@ func (r *applicationRepository) RecalculateApplicationCosts(
 	}
 
 	return &CostCalculation{
-		AreaCost:        areaCost,
-		PermitFee:       tariff.PermitFee,
-		InspectionFee:   tariff.InspectionFee,
-		DevelopmentLevy: developmentLevy,
-		VATAmount:       vatAmount,
-		TotalCost:       totalCost,
+		AreaCost:        costs.AreaCost,
+		PermitFee:       costs.PermitFee,
+		InspectionFee:   costs.InspectionFee,
+		DevelopmentLevy: costs.DevelopmentLevy,
+		VATAmount:       costs.VATAmount,
+		TotalCost:       costs.TotalCost,
 	}, nil
 }
 
@@ -223,9 +221,20 @@ func (r *applicationRepository) MarkApplicationAsCollected(
 		"status":          models.CollectedApplication,
 	}
 
-	return tx.Model(&models.Application{}).
+	if err := tx.Model(&models.Application{}).
 		Where("id = ?", applicationID).
-		Updates(updates).Error
+		Updates(updates).Error; err != nil {
+		return err
+	}
+
+	// Close off the collection appointment the applicant came in for, if any
+	return tx.Model(&models.CollectionAppointment{}).
+		Where("application_id = ? AND status = ?", applicationID, models.CollectionBooked).
+		Updates(map[string]interface{}{
+			"status":       models.CollectionCollected,
+			"collected_at": collectionDate,
+			"updated_by":   collectedBy,
+		}).Error
 }
 
 // UpdateApplicationDocumentFlags updates document verification flags
@@ -273,7 +282,7 @@ func (r *applicationRepository) UpdateApplicationDocumentFlags(
 	}
 
 	// Check if ready for review (payment + docs)
-	if application.PaymentStatus == models.PaidPayment && allDocsProvided {
+	if application.PaymentPrerequisiteMet() && allDocsProvided {
 		updates["ready_for_review"] = true
 	}
 
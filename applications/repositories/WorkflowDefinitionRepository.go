@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"fmt"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GetWorkflowConfiguration loads the approval groups with their active members and the groups'
+// active checklist items, keyed by group, for exporting the workflow definition
+func (r *applicationRepository) GetWorkflowConfiguration(includeInactive bool) ([]models.ApprovalGroup, map[uuid.UUID][]models.ReviewChecklistItem, error) {
+	query := r.db.
+		Preload("Members", func(db *gorm.DB) *gorm.DB {
+			return db.Where("is_active = ?", true).Order("review_order ASC")
+		}).
+		Preload("Members.User").
+		Preload("Members.User.Department")
+	if !includeInactive {
+		query = query.Where("is_active = ?", true)
+	}
+
+	var groups []models.ApprovalGroup
+	if err := query.Order("name ASC").Find(&groups).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch approval groups: %w", err)
+	}
+
+	groupIDs := make([]uuid.UUID, 0, len(groups))
+	for _, group := range groups {
+		groupIDs = append(groupIDs, group.ID)
+	}
+
+	checklists := make(map[uuid.UUID][]models.ReviewChecklistItem, len(groups))
+	if len(groupIDs) == 0 {
+		return groups, checklists, nil
+	}
+
+	var items []models.ReviewChecklistItem
+	if err := r.db.Where("approval_group_id IN ? AND is_active = ?", groupIDs, true).
+		Order("sort_order ASC, created_at ASC").
+		Find(&items).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch review checklists: %w", err)
+	}
+	for _, item := range items {
+		checklists[item.ApprovalGroupID] = append(checklists[item.ApprovalGroupID], item)
+	}
+	return groups, checklists, nil
+}
@@ -1,9 +1,12 @@
 package requests
 
 import (
+	"encoding/json"
+	"time"
 	"town-planning-backend/db/models"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 // Request types
@@ -34,6 +37,14 @@ type ResolveIssueRequest struct {
 	ResolutionComment *string `json:"resolution_comment" form:"resolution_comment"`
 }
 
+// BulkResolveIssuesRequest resolves the listed issues, or every open COLLABORATIVE issue in a
+// category, with one resolution note
+type BulkResolveIssuesRequest struct {
+	IssueIDs          []uuid.UUID `json:"issue_ids"`
+	Category          *string     `json:"category"`
+	ResolutionComment string      `json:"resolution_comment"`
+}
+
 type ReopenIssueRequest struct {
 	ReopenReason *string `json:"reopen_reason" form:"reopen_reason"`
 }
@@ -70,4 +81,239 @@ type RevocationResult struct {
 	WasFinalApprover      bool                     `json:"was_final_approver"`
 	ReadyForFinalApproval bool                     `json:"ready_for_final_approval"`
 	Message               string                   `json:"message"` // Added this field
-}
\ No newline at end of file
+}
+// ConflictOfInterestDeclarationRequest is a reviewer's declaration about their relationship to the applicant
+type ConflictOfInterestDeclarationRequest struct {
+	HasConflict  bool    `json:"has_conflict"`
+	Relationship *string `json:"relationship"`
+	Details      *string `json:"details"`
+}
+
+// CollectionCalendarRequest configures a department's permit collection calendar
+type CollectionCalendarRequest struct {
+	DepartmentID        uuid.UUID `json:"department_id"`
+	OpeningTime         string    `json:"opening_time"`
+	ClosingTime         string    `json:"closing_time"`
+	WorkingDays         string    `json:"working_days"`
+	SlotDurationMinutes int       `json:"slot_duration_minutes"`
+	SlotCapacity        int       `json:"slot_capacity"`
+	BookingHorizonDays  int       `json:"booking_horizon_days"`
+	IsActive            *bool     `json:"is_active"`
+}
+
+// BookCollectionAppointmentRequest books a permit collection slot
+type BookCollectionAppointmentRequest struct {
+	DepartmentID uuid.UUID `json:"department_id"`
+	SlotStart    time.Time `json:"slot_start"`
+	Notes        *string   `json:"notes"`
+}
+
+// CollectionAppointmentActionRequest carries the optional reason or notes for cancelling
+// an appointment or closing a no-show follow-up
+type CollectionAppointmentActionRequest struct {
+	Reason *string `json:"reason"`
+	Notes  *string `json:"notes"`
+}
+
+// RegisterPhysicalFileRequest registers an application's paper file. The holder defaults to
+// the user registering it.
+type RegisterPhysicalFileRequest struct {
+	HolderID *uuid.UUID `json:"holder_id"`
+	Location *string    `json:"location"`
+}
+
+// DispatchPhysicalFileRequest sends a paper file to another holder
+type DispatchPhysicalFileRequest struct {
+	ToHolderID uuid.UUID `json:"to_holder_id"`
+	ToLocation *string   `json:"to_location"`
+	Notes      *string   `json:"notes"`
+}
+
+// ReceivePhysicalFileRequest confirms receipt of a dispatched paper file
+type ReceivePhysicalFileRequest struct {
+	Location *string `json:"location"`
+}
+
+// CreateInstallmentPlanRequest spreads an application's levy over scheduled installments.
+// IntervalMonths defaults to monthly.
+type CreateInstallmentPlanRequest struct {
+	Installments             int       `json:"installments"`
+	FirstDueDate             time.Time `json:"first_due_date"`
+	IntervalMonths           int       `json:"interval_months"`
+	AllowConditionalApproval bool      `json:"allow_conditional_approval"`
+}
+
+// RouteCountersignRequest sends an engineering certificate to a council engineer
+type RouteCountersignRequest struct {
+	DocumentID uuid.UUID `json:"document_id"`
+	EngineerID uuid.UUID `json:"engineer_id"`
+	Notes      *string   `json:"notes"`
+}
+
+// RequestSecondOpinionRequest asks a user outside the approval group for advisory input by DueDate
+type RequestSecondOpinionRequest struct {
+	ReviewerID uuid.UUID `json:"reviewer_id"`
+	Question   string    `json:"question"`
+	DueDate    time.Time `json:"due_date"`
+}
+
+// SubmitSecondOpinionRequest carries the reviewer's advisory comment
+type SubmitSecondOpinionRequest struct {
+	Opinion string `json:"opinion"`
+}
+
+// CountersignDecisionRequest carries the engineer's remarks when signing or reason when declining
+type CountersignDecisionRequest struct {
+	Comment *string `json:"comment"`
+}
+
+// RecordInstallmentPaymentRequest records a levy payment against an installment plan
+type RecordInstallmentPaymentRequest struct {
+	Amount            decimal.Decimal      `json:"amount"`
+	PaymentMethod     models.PaymentMethod `json:"payment_method"`
+	ReceiptNumber     string               `json:"receipt_number"`
+	PaymentDate       *time.Time           `json:"payment_date"`
+	ExternalReference *string              `json:"external_reference"`
+	BankAccountID     *uuid.UUID           `json:"bank_account_id"`
+	Notes             string               `json:"notes"`
+}
+
+// RecordAmendmentPaymentRequest records payment of an amendment's fee, which is paid in full
+type RecordAmendmentPaymentRequest struct {
+	Amount            decimal.Decimal      `json:"amount"`
+	PaymentMethod     models.PaymentMethod `json:"payment_method"`
+	ReceiptNumber     string               `json:"receipt_number"`
+	PaymentDate       *time.Time           `json:"payment_date"`
+	ExternalReference *string              `json:"external_reference"`
+	BankAccountID     *uuid.UUID           `json:"bank_account_id"`
+	Notes             string               `json:"notes"`
+}
+
+// LodgeApplicationAppealRequest lodges an appeal against a rejection. The payment fields are
+// required when the council charges an appeal fee.
+type LodgeApplicationAppealRequest struct {
+	Grounds           string               `json:"grounds"`
+	AppealsGroupID    *uuid.UUID           `json:"appeals_group_id"`
+	Amount            decimal.Decimal      `json:"amount"`
+	PaymentMethod     models.PaymentMethod `json:"payment_method"`
+	ReceiptNumber     string               `json:"receipt_number"`
+	PaymentDate       *time.Time           `json:"payment_date"`
+	ExternalReference *string              `json:"external_reference"`
+	BankAccountID     *uuid.UUID           `json:"bank_account_id"`
+}
+
+// ScheduleAppealHearingRequest sets or moves the hearing of an appeal
+type ScheduleAppealHearingRequest struct {
+	ScheduledAt time.Time `json:"scheduled_at"`
+	Venue       *string   `json:"venue"`
+	Notes       *string   `json:"notes"`
+}
+
+// DecideApplicationAppealRequest records the outcome of an appeal hearing
+type DecideApplicationAppealRequest struct {
+	Outcome models.ApplicationAppealStatus `json:"outcome"` // UPHELD or DISMISSED
+	Reason  string                         `json:"reason"`
+}
+
+// RatesClearanceOverrideRequest accepts an application despite its stand's rates account not clearing
+type RatesClearanceOverrideRequest struct {
+	Reason string `json:"reason"`
+}
+
+// RaiseIssueFromMessageRequest raises a formal issue from a chat message. Description defaults
+// to the message content.
+type RaiseIssueFromMessageRequest struct {
+	Title                   string                     `json:"title"`
+	Description             string                     `json:"description"`
+	Priority                string                     `json:"priority"`
+	Category                *string                    `json:"category"`
+	AssignmentType          models.IssueAssignmentType `json:"assignment_type"`
+	AssignedToUserID        *uuid.UUID                 `json:"assigned_to_user_id"`
+	AssignedToGroupMemberID *uuid.UUID                 `json:"assigned_to_group_member_id"`
+}
+
+// BoundaryLayerStatusRequest activates or deactivates a council boundary layer
+type BoundaryLayerStatusRequest struct {
+	IsActive bool `json:"is_active"`
+}
+
+// ClearBoundaryReviewRequest releases an application flagged by geo-validation, recording why
+// it may proceed, e.g. an agreed cross-boundary development or a corrected stand record
+type ClearBoundaryReviewRequest struct {
+	Note string `json:"note"`
+}
+
+// ScheduleMessageRequest composes a chat message to be sent at a future time, e.g. a reminder to
+// an assignee on Monday morning. ScheduledFor is RFC 3339 with the sender's UTC offset.
+type ScheduleMessageRequest struct {
+	Content      string    `json:"content"`
+	ScheduledFor time.Time `json:"scheduled_for"`
+}
+
+// RiskScoringProfileRequest replaces the active risk scoring profile. Points are added to an
+// application's score when a factor applies; see models.RiskScoringProfile.
+type RiskScoringProfileRequest struct {
+	PlanAreaThreshold     decimal.Decimal `json:"plan_area_threshold"`
+	PlanAreaPoints        int             `json:"plan_area_points"`
+	ApplicantDebtorPoints int             `json:"applicant_debtor_points"`
+	PriorRejectionPoints  int             `json:"prior_rejection_points"`
+	PriorRejectionCap     int             `json:"prior_rejection_cap"`
+	UnservicedStandPoints int             `json:"unserviced_stand_points"`
+	MediumRiskThreshold   int             `json:"medium_risk_threshold"`
+	HighRiskThreshold     int             `json:"high_risk_threshold"`
+
+	// Leave empty to stop routing high-risk applications automatically
+	SeniorApprovalGroupID *uuid.UUID `json:"senior_approval_group_id"`
+}
+
+// DevelopmentCategoryRiskRequest sets the points a development category adds to risk scores
+type DevelopmentCategoryRiskRequest struct {
+	RiskPoints int `json:"risk_points"`
+}
+
+// ReviewChecklistItemRequest adds an item to an approval group's review checklist. Items are
+// mandatory unless IsMandatory is false.
+type ReviewChecklistItemRequest struct {
+	Label       string  `json:"label"`
+	Description *string `json:"description"`
+	IsMandatory *bool   `json:"is_mandatory"`
+	SortOrder   int     `json:"sort_order"`
+}
+
+// UpdateReviewChecklistItemRequest changes a checklist item. Only the fields sent are updated;
+// setting is_active to false retires the item.
+type UpdateReviewChecklistItemRequest struct {
+	Label       *string `json:"label"`
+	Description *string `json:"description"`
+	IsMandatory *bool   `json:"is_mandatory"`
+	SortOrder   *int    `json:"sort_order"`
+	IsActive    *bool   `json:"is_active"`
+}
+
+// DecisionCommentPolicyRequest sets which of an approval group's member decisions must come with
+// a comment: NONE, APPROVALS, REJECTIONS or BOTH
+type DecisionCommentPolicyRequest struct {
+	CommentPolicy models.DecisionCommentPolicy `json:"comment_policy"`
+}
+
+// ResolveDuplicatePaymentRequest closes a duplicate payment alert. Resolution is
+// CONFIRMED_DUPLICATE or NOT_DUPLICATE.
+type ResolveDuplicatePaymentRequest struct {
+	Resolution models.DuplicatePaymentAlertStatus `json:"resolution"`
+	Notes      *string                            `json:"notes"`
+}
+
+// RehydrateApplicationRequest restores an archived application. Reason is required and kept on
+// the archive, e.g. the legal query it is needed for.
+type RehydrateApplicationRequest struct {
+	Reason string `json:"reason"`
+}
+
+// SaveApplicationDraftRequest creates or autosaves an application draft. Data holds the
+// create-application form fields entered so far; on update only the fields sent are changed and
+// a field sent as null is cleared. Version, when sent, must match the draft's current version.
+type SaveApplicationDraftRequest struct {
+	Label   *string         `json:"label"`
+	Data    json.RawMessage `json:"data"`
+	Version *int            `json:"version"`
+}
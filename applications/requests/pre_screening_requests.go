@@ -0,0 +1,19 @@
+package requests
+
+import "town-planning-backend/db/models"
+
+// Outcomes a technician can give a pre-screened application
+const (
+	PreScreeningOutcomePass   = "PASS"
+	PreScreeningOutcomeBounce = "BOUNCE"
+)
+
+// PreScreenApplicationRequest is a technician's triage of a queued application: the quick
+// checklist, and either PASS, which sends it on to its approval group, or BOUNCE with standard
+// reasons, which returns it to the applicant
+type PreScreenApplicationRequest struct {
+	Outcome       string                            `json:"outcome"`
+	Checks        map[models.PreScreeningCheck]bool `json:"checks"`
+	BounceReasons []models.PreScreeningBounceReason `json:"bounce_reasons"`
+	Note          *string                           `json:"note"`
+}
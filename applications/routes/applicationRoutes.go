@@ -4,12 +4,16 @@ import (
 	applicants_repositories "town-planning-backend/applicants/repositories"
 	controllers "town-planning-backend/applications/controllers"
 	repositories "town-planning-backend/applications/repositories"
+	application_services "town-planning-backend/applications/services"
 	indexing_repository "town-planning-backend/bleve/repositories"
 	documents_services "town-planning-backend/documents/services"
+	"town-planning-backend/middleware"
 	user_repository "town-planning-backend/users/repositories"
+	"town-planning-backend/utils"
 	"town-planning-backend/websocket"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
 
@@ -22,17 +26,51 @@ func ApplicationRouterInit(
 	documentService *documents_services.DocumentService,
 	applicantRepo applicants_repositories.ApplicantRepository,
 	wsHub *websocket.Hub, // Added WebSocket hub for real-time features
+	redisClient *redis.Client,
 ) {
 	applicationController := &controllers.ApplicationController{
-		ApplicationRepo: applicationRepository,
-		DB:              db,
-		BleveRepo:       bleveRepository,
-		UserRepo:        userRepo,
-		DocumentSvc:     documentService,
-		ApplicantRepo:   applicantRepo,
-		WsHub:           wsHub, // Added WebSocket hub to controller
+		ApplicationRepo:   applicationRepository,
+		DB:                db,
+		BleveRepo:         bleveRepository,
+		UserRepo:          userRepo,
+		DocumentSvc:       documentService,
+		ApplicantRepo:     applicantRepo,
+		WsHub:             wsHub, // Added WebSocket hub to controller
+		ReadReceiptSvc:    application_services.NewReadReceiptService(db),
+		RatesClearanceSvc: application_services.NewRatesClearanceService(application_services.LoadRatesBillingConfig()),
+		DeedsRegistrySvc:  application_services.NewDeedsRegistryService(application_services.LoadDeedsRegistryConfig()),
+		BoundaryValidator: application_services.NewBoundaryValidator(),
+		PackStorage:       utils.NewLocalFileStorage("./committee-packs"),
+		Estimator:         application_services.NewProcessingEstimator(db),
+		LinkPreviewSvc:    application_services.NewLinkPreviewService(db, application_services.LoadLinkPreviewConfig()),
+
+		DecisionIdempotency: application_services.NewDecisionIdempotency(redisClient),
 	}
 
+	// Post scheduled chat messages as they fall due
+	go applicationController.RunScheduledMessageDispatch()
+
+	// Remind approvers of overdue decisions and escalate ignored ones to department heads
+	go applicationController.RunDecisionReminders()
+
+	// Close thread invitations left unanswered
+	go applicationController.RunInvitationExpiry()
+
+	// Move applications closed for years to cold storage
+	go applicationController.RunApplicationArchival()
+
+	// Expire application drafts nobody has come back to
+	go applicationController.RunApplicationDraftExpiry()
+
+	// Email unread chat messages to participants who rarely open the app
+	go applicationController.RunChatEmailBridge()
+
+	// Packs still queued from the last run will never be generated
+	applicationController.FailInterruptedCommitteePacks()
+
+	// Replies to chat digests, posted by SendGrid Inbound Parse, so outside the staff routes
+	app.Post("/emails/inbound/chat", applicationController.ChatEmailReplyController)
+
 	applicationRoutes := app.Group("/api/v1")
 
 	// Development Categories
@@ -47,14 +85,29 @@ func ApplicationRouterInit(
 	// Approval Groups
 	applicationRoutes.Post("/approval-groups/create-with-members", applicationController.CreateApprovalGroupWithMembers)
 	applicationRoutes.Get("/filtered-approval-groups", applicationController.GetFilteredApprovalGroupsController)
+	applicationRoutes.Get("/approval-groups/availability-calendar", middleware.RequirePermission(userRepo, "user.read"), applicationController.GetAvailabilityCalendarController)
+	applicationRoutes.Get("/approval-groups/:id/quorum", applicationController.GetGroupQuorumController)
+	applicationRoutes.Get("/approval-groups/:id/checklist", applicationController.GetReviewChecklistController)
+	applicationRoutes.Post("/approval-groups/:id/checklist", middleware.RequirePermission(userRepo, "review_checklist.manage"), applicationController.CreateReviewChecklistItemController)
+	applicationRoutes.Put("/approval-groups/:id/checklist/:itemId", middleware.RequirePermission(userRepo, "review_checklist.manage"), applicationController.UpdateReviewChecklistItemController)
+	applicationRoutes.Put("/approval-groups/:id/comment-policy", middleware.RequirePermission(userRepo, "review_checklist.manage"), applicationController.UpdateDecisionCommentPolicyController)
+	applicationRoutes.Get("/workflow/definition", applicationController.GetWorkflowDefinitionController)
 
 	// Applications - Comprehensive endpoints
 	applicationRoutes.Post("/create-application", applicationController.CreateApplicationController)
+
+	// Drafts autosaved while an application is entered, submitted once complete
+	applicationRoutes.Get("/application-drafts", applicationController.GetMyApplicationDraftsController)
+	applicationRoutes.Post("/application-drafts", applicationController.CreateApplicationDraftController)
+	applicationRoutes.Get("/application-drafts/:id", applicationController.GetApplicationDraftController)
+	applicationRoutes.Patch("/application-drafts/:id", applicationController.UpdateApplicationDraftController)
+	applicationRoutes.Delete("/application-drafts/:id", applicationController.DeleteApplicationDraftController)
+	applicationRoutes.Post("/application-drafts/:id/submit", applicationController.SubmitApplicationDraftController)
 	applicationRoutes.Get("/filtered-applications", applicationController.GetFilteredApplicationsController)
 	applicationRoutes.Get("/application/:id", applicationController.GetApplicationByIdController)
 
 	// New comprehensive update endpoint - updates ALL fields
-	applicationRoutes.Post("/applications/:id/process-application-submission", applicationController.ProcessApplicationSubmissionController)
+	applicationRoutes.Post("/applications/:id/process-application-submission", middleware.Upload(), applicationController.ProcessApplicationSubmissionController)
 
 	// New granular update endpoints
 	applicationRoutes.Patch("/applications/:id/status", applicationController.UpdateApplicationStatusController)
@@ -64,34 +117,176 @@ func ApplicationRouterInit(
 	applicationRoutes.Patch("/applications/:id/document-flags", applicationController.UpdateDocumentFlagsController)
 
 	// Application Actions (MUST come before generic :id routes)
-	applicationRoutes.Post("/generate-tpd-1-form/:id", applicationController.GenerateTPD1FormController)
+	applicationRoutes.Post("/generate-tpd-1-form/:id", middleware.LongRunning(), applicationController.GenerateTPD1FormController)
 	applicationRoutes.Get("/application-approval-data/:id", applicationController.GetApplicationApprovalDataController)
 
 	// Generate Comments Sheet
-	applicationRoutes.Post("/generate-comments-sheet/:id", applicationController.GenerateCommentsSheetController)
+	applicationRoutes.Post("/generate-comments-sheet/:id", middleware.LongRunning(), applicationController.GenerateCommentsSheetController)
 
 	// Generate Development Permit
-	applicationRoutes.Post("/generate-development-permit/:id", applicationController.GenerateDevelopmentPermitController)
+	applicationRoutes.Post("/generate-development-permit/:id", middleware.LongRunning(), applicationController.GenerateDevelopmentPermitController)
 
 	// Chat Messages - ADDED THIS ROUTE
 	applicationRoutes.Get("/chat/threads/:threadId/messages", applicationController.GetChatMessagesController)
 
 	// Approval Workflow - Use POST for actions that change state
-	applicationRoutes.Post("/applications/:id/approve", applicationController.ApproveRejectApplicationController)
-	applicationRoutes.Post("/applications/:id/reject", applicationController.RejectApplicationController)
+	applicationRoutes.Post("/applications/:id/approve", middleware.Upload(), applicationController.ApproveRejectApplicationController)
+	applicationRoutes.Post("/applications/:id/reject", middleware.Upload(), applicationController.RejectApplicationController)
 	
 	// ADD REVOKE ENDPOINT HERE
 	applicationRoutes.Post("/applications/:id/revoke", applicationController.RevokeDecisionController)
+	applicationRoutes.Post("/applications/:id/conflict-of-interest", applicationController.DeclareConflictOfInterestController)
+	applicationRoutes.Get("/applications/:id/conflict-of-interest", applicationController.GetConflictDeclarationsController)
 	
-	applicationRoutes.Post("/applications/:id/raise-issue", applicationController.RaiseIssueController)
+	// Ownership transfers after a property sale
+	applicationRoutes.Post("/applications/:id/transfers", middleware.Upload(), applicationController.RequestApplicationTransferController)
+	applicationRoutes.Get("/applications/:id/transfers", applicationController.GetApplicationTransfersController)
+	applicationRoutes.Post("/application-transfers/:id/approve", middleware.RequirePermission(userRepo, "application.transfer"), applicationController.ApproveApplicationTransferController)
+	applicationRoutes.Post("/application-transfers/:id/reject", middleware.RequirePermission(userRepo, "application.transfer"), applicationController.RejectApplicationTransferController)
+
+	// Minor amendments after approval, signed off by a single approver
+	applicationRoutes.Post("/applications/:id/amendments", middleware.Upload(), middleware.RequirePermission(userRepo, "application.amend"), applicationController.RequestApplicationAmendmentController)
+	applicationRoutes.Get("/applications/:id/amendments", applicationController.GetApplicationAmendmentsController)
+	applicationRoutes.Post("/application-amendments/:id/payments", middleware.RequirePermission(userRepo, "payment.process"), applicationController.RecordAmendmentPaymentController)
+	applicationRoutes.Post("/application-amendments/:id/approve", middleware.RequirePermission(userRepo, "application.approve"), applicationController.ApproveApplicationAmendmentController)
+	applicationRoutes.Post("/application-amendments/:id/reject", middleware.RequirePermission(userRepo, "application.approve"), applicationController.RejectApplicationAmendmentController)
+
+	// Appeals against rejections, heard by an appeals group
+	applicationRoutes.Post("/applications/:id/appeals", middleware.RequirePermission(userRepo, "application.appeal"), applicationController.LodgeApplicationAppealController)
+	applicationRoutes.Get("/applications/:id/appeals", applicationController.GetApplicationAppealsController)
+	applicationRoutes.Post("/application-appeals/:id/hearings", middleware.RequirePermission(userRepo, "appeal.decide"), applicationController.ScheduleAppealHearingController)
+	applicationRoutes.Post("/application-appeals/:id/decision", middleware.RequirePermission(userRepo, "appeal.decide"), applicationController.DecideApplicationAppealController)
+
+	// Permit collection appointments
+	applicationRoutes.Get("/collection-calendars", applicationController.GetCollectionCalendarsController)
+	applicationRoutes.Post("/collection-calendars", middleware.RequirePermission(userRepo, "collection.manage"), applicationController.UpsertCollectionCalendarController)
+	applicationRoutes.Get("/collection-calendars/:departmentId/slots", applicationController.GetAvailableCollectionSlotsController)
+	applicationRoutes.Post("/applications/:id/collection-appointments", applicationController.BookCollectionAppointmentController)
+	applicationRoutes.Get("/collection-appointments", middleware.RequirePermission(userRepo, "collection.manage"), applicationController.GetCollectionAppointmentsController)
+	applicationRoutes.Get("/collection-appointments/no-shows", middleware.RequirePermission(userRepo, "collection.manage"), applicationController.GetCollectionNoShowsController)
+	applicationRoutes.Post("/collection-appointments/:id/cancel", applicationController.CancelCollectionAppointmentController)
+	applicationRoutes.Post("/collection-appointments/:id/no-show", middleware.RequirePermission(userRepo, "collection.manage"), applicationController.MarkCollectionNoShowController)
+	applicationRoutes.Post("/collection-appointments/:id/follow-up", middleware.RequirePermission(userRepo, "collection.manage"), applicationController.CompleteCollectionFollowUpController)
+
+	// Hard-copy file tracking
+	applicationRoutes.Post("/applications/:id/physical-file", applicationController.RegisterPhysicalFileController)
+	applicationRoutes.Get("/applications/:id/physical-file", applicationController.GetApplicationPhysicalFileController)
+	applicationRoutes.Get("/physical-files/missing", applicationController.GetMissingPhysicalFilesController)
+	applicationRoutes.Get("/physical-files/:barcode", applicationController.GetPhysicalFileByBarcodeController)
+	applicationRoutes.Post("/physical-files/:barcode/dispatch", applicationController.DispatchPhysicalFileController)
+	applicationRoutes.Post("/physical-files/:barcode/receive", applicationController.ReceivePhysicalFileController)
+
+	// Development levy installment plans
+	applicationRoutes.Post("/applications/:id/installment-plan", middleware.RequirePermission(userRepo, "payment.process"), applicationController.CreateInstallmentPlanController)
+	applicationRoutes.Get("/applications/:id/installment-plan", applicationController.GetApplicationInstallmentPlanController)
+	applicationRoutes.Post("/applications/:id/installment-plan/payments", middleware.RequirePermission(userRepo, "payment.process"), applicationController.RecordInstallmentPaymentController)
+	applicationRoutes.Get("/installment-plans/outstanding", middleware.RequirePermission(userRepo, "payment.verify"), applicationController.GetOutstandingInstallmentPlansController)
+
+	// Duplicate payment captures held for the finance officers
+	applicationRoutes.Get("/payments/duplicate-alerts", middleware.RequirePermission(userRepo, "payment.reconcile"), applicationController.GetDuplicatePaymentAlertsController)
+	applicationRoutes.Post("/payments/duplicate-alerts/:id/resolve", middleware.RequirePermission(userRepo, "payment.reconcile"), applicationController.ResolveDuplicatePaymentAlertController)
+
+	// Advisory second opinions from outside the approval group
+	applicationRoutes.Post("/applications/:id/second-opinions", applicationController.RequestSecondOpinionController)
+	applicationRoutes.Get("/applications/:id/second-opinions", applicationController.GetApplicationSecondOpinionsController)
+	applicationRoutes.Get("/second-opinions/pending", applicationController.GetMyPendingSecondOpinionsController)
+	applicationRoutes.Post("/second-opinions/:id/submit", applicationController.SubmitSecondOpinionController)
+	applicationRoutes.Post("/second-opinions/:id/withdraw", applicationController.WithdrawSecondOpinionController)
+
+	// Engineering certificate countersigning
+	applicationRoutes.Post("/applications/:id/countersignatures", middleware.RequirePermission(userRepo, "document.process"), applicationController.RouteCertificateForCountersignController)
+	applicationRoutes.Get("/applications/:id/countersignatures", applicationController.GetApplicationCountersignaturesController)
+	applicationRoutes.Get("/countersignatures/pending", middleware.RequirePermission(userRepo, "document.countersign"), applicationController.GetMyPendingCountersignaturesController)
+	applicationRoutes.Post("/countersignatures/:id/sign", middleware.RequirePermission(userRepo, "document.countersign"), applicationController.CountersignCertificateController)
+	applicationRoutes.Post("/countersignatures/:id/decline", middleware.RequirePermission(userRepo, "document.countersign"), applicationController.DeclineCountersignatureController)
+
+	// Joint owners
+	applicationRoutes.Get("/applications/:id/co-applicants", applicationController.GetApplicationCoApplicantsController)
+	applicationRoutes.Post("/applications/:id/co-applicants", middleware.Upload(), middleware.RequirePermission(userRepo, "application.update"), applicationController.AddCoApplicantController)
+	applicationRoutes.Delete("/applications/:id/co-applicants/:applicantId", middleware.RequirePermission(userRepo, "application.update"), applicationController.RemoveCoApplicantController)
+
+	// Stand rates clearance with council billing
+	applicationRoutes.Get("/applications/:id/rates-clearance", applicationController.GetApplicationRatesClearancesController)
+	applicationRoutes.Post("/applications/:id/rates-clearance", applicationController.CheckApplicationRatesClearanceController)
+	applicationRoutes.Post("/applications/:id/rates-clearance/override", middleware.RequirePermission(userRepo, controllers.RatesOverridePermission), applicationController.OverrideRatesClearanceController)
+	applicationRoutes.Get("/applications/:id/deeds-verification", applicationController.GetApplicationDeedsVerificationsController)
+	applicationRoutes.Post("/applications/:id/deeds-verification", applicationController.CheckApplicationDeedsVerificationController)
+
+	// Council boundary layers and stand geo-validation
+	applicationRoutes.Post("/admin/boundary-layers", middleware.Upload(), middleware.RequirePermission(userRepo, "user.manage"), applicationController.UploadBoundaryLayerController)
+	applicationRoutes.Get("/admin/boundary-layers", middleware.RequirePermission(userRepo, "user.manage"), applicationController.GetBoundaryLayersController)
+	applicationRoutes.Get("/admin/boundary-layers/:id", middleware.RequirePermission(userRepo, "user.manage"), applicationController.GetBoundaryLayerController)
+	applicationRoutes.Patch("/admin/boundary-layers/:id", middleware.RequirePermission(userRepo, "user.manage"), applicationController.SetBoundaryLayerStatusController)
+	applicationRoutes.Get("/applications/boundary-review", middleware.RequirePermission(userRepo, "application.review"), applicationController.GetBoundaryReviewQueueController)
+	applicationRoutes.Get("/applications/:id/boundary-checks", applicationController.GetApplicationBoundaryChecksController)
+	applicationRoutes.Post("/applications/:id/boundary-checks", applicationController.CheckApplicationBoundaryController)
+	applicationRoutes.Post("/applications/:id/boundary-review/clear", middleware.RequirePermission(userRepo, "application.review"), applicationController.ClearBoundaryReviewController)
+
+	// Pre-screening triage of new submissions before approval group assignment
+	applicationRoutes.Get("/applications/pre-screening", middleware.RequirePermission(userRepo, "application.prescreen"), applicationController.GetPreScreeningQueueController)
+	applicationRoutes.Get("/applications/:id/pre-screenings", applicationController.GetApplicationPreScreeningsController)
+	applicationRoutes.Post("/applications/:id/pre-screening", middleware.RequirePermission(userRepo, "application.prescreen"), applicationController.PreScreenApplicationController)
+	applicationRoutes.Post("/applications/:id/pre-screening/requeue", middleware.RequirePermission(userRepo, "application.prescreen"), applicationController.RequeuePreScreeningController)
+
+	// Application risk scoring and the risk-ordered approver queue
+	applicationRoutes.Get("/admin/risk-scoring", middleware.RequirePermission(userRepo, "user.manage"), applicationController.GetRiskScoringProfileController)
+	applicationRoutes.Put("/admin/risk-scoring", middleware.RequirePermission(userRepo, "user.manage"), applicationController.UpdateRiskScoringProfileController)
+	applicationRoutes.Patch("/development-categories/:id/risk-points", middleware.RequirePermission(userRepo, "user.manage"), applicationController.SetDevelopmentCategoryRiskController)
+	applicationRoutes.Get("/applications/:id/risk-assessments", applicationController.GetApplicationRiskAssessmentsController)
+	applicationRoutes.Post("/applications/:id/risk-assessments", middleware.RequirePermission(userRepo, "application.review"), applicationController.AssessApplicationRiskController)
+	applicationRoutes.Get("/approvals/queue", applicationController.GetApproverQueueController)
+
+	// Consolidated print packs for committee meetings
+	applicationRoutes.Post("/applications/:id/committee-pack", middleware.RequirePermission(userRepo, "application.review"), applicationController.RequestCommitteePackController)
+	applicationRoutes.Get("/applications/:id/committee-packs", middleware.RequirePermission(userRepo, "application.review"), applicationController.GetApplicationCommitteePacksController)
+	applicationRoutes.Get("/committee-packs/:id", middleware.RequirePermission(userRepo, "application.review"), applicationController.GetCommitteePackController)
+	applicationRoutes.Get("/committee-packs/:id/download", middleware.RequirePermission(userRepo, "application.review"), applicationController.DownloadCommitteePackController)
+
+	// Cold storage: closed applications are archived nightly and rehydrated on demand
+	applicationRoutes.Get("/applications/:id/archives", middleware.RequirePermission(userRepo, "application.read"), applicationController.GetApplicationArchivesController)
+	applicationRoutes.Post("/applications/:id/rehydrate", middleware.RequirePermission(userRepo, "application.rehydrate"), middleware.LongRunning(), applicationController.RehydrateApplicationController)
+
+	// Issued permits: suspension, reinstatement and revocation
+	applicationRoutes.Get("/applications/:id/permit", applicationController.GetApplicationPermitController)
+	applicationRoutes.Get("/permits/:id", applicationController.GetPermitController)
+	applicationRoutes.Post("/permits/:id/suspend", middleware.Upload(), middleware.RequirePermission(userRepo, "permit.manage"), applicationController.SuspendPermitController)
+	applicationRoutes.Post("/permits/:id/reinstate", middleware.Upload(), middleware.RequirePermission(userRepo, "permit.manage"), applicationController.ReinstatePermitController)
+	applicationRoutes.Post("/permits/:id/revoke", middleware.Upload(), middleware.RequirePermission(userRepo, "permit.manage"), applicationController.RevokePermitController)
+
+	// Public permit verification, usable without logging in
+	app.Get("/permits/verify", applicationController.VerifyPermitController)
+
+	// Public processing time and fee estimate for prospective applicants
+	app.Get("/estimates/processing", applicationController.GetProcessingEstimateController)
+
+	// Approver "my queue" views and the filters saved for them
+	applicationRoutes.Get("/queue/decisions", applicationController.GetPendingDecisionsQueueController)
+	applicationRoutes.Get("/queue/issues", applicationController.GetAssignedIssuesQueueController)
+	applicationRoutes.Get("/queue/mentions", applicationController.GetUnreadMentionsQueueController)
+	applicationRoutes.Get("/queue/filters", applicationController.GetSavedQueueFiltersController)
+	applicationRoutes.Post("/queue/filters", applicationController.CreateSavedQueueFilterController)
+	applicationRoutes.Put("/queue/filters/:id", applicationController.UpdateSavedQueueFilterController)
+	applicationRoutes.Delete("/queue/filters/:id", applicationController.DeleteSavedQueueFilterController)
+
+	applicationRoutes.Post("/applications/:id/raise-issue", middleware.Upload(), applicationController.RaiseIssueController)
 	applicationRoutes.Post("/issues/:id/resolve", applicationController.ResolveIssueController)
 	applicationRoutes.Post("/issues/:id/reopen", applicationController.ReopenIssueController)
-	applicationRoutes.Post("/chat/threads/:threadId/messages", applicationController.SendMessageController)
+	applicationRoutes.Patch("/issues/:id/awaiting-applicant", applicationController.SetIssueAwaitingApplicantController)
+	applicationRoutes.Get("/applications/:id/sla-pauses", applicationController.GetApplicationSLAPausesController)
+	applicationRoutes.Post("/issues/bulk-resolve", middleware.RequirePermission(userRepo, "issue.bulk_resolve"), applicationController.BulkResolveIssuesController)
+	applicationRoutes.Post("/chat/threads/:threadId/messages", middleware.Upload(), applicationController.SendMessageController)
+	applicationRoutes.Post("/chat/threads/:threadId/scheduled-messages", applicationController.ScheduleMessageController)
+	applicationRoutes.Post("/chat/threads/:threadId/unfreeze", middleware.RequirePermission(userRepo, controllers.ChatModeratePermission), applicationController.UnfreezeThreadController)
+	applicationRoutes.Get("/chat/scheduled-messages", applicationController.GetScheduledMessagesController)
+	applicationRoutes.Delete("/chat/scheduled-messages/:id", applicationController.CancelScheduledMessageController)
 
 	// Real-time Chat Features - ADDED THESE ROUTES
 	applicationRoutes.Post("/chat/threads/:threadId/typing", applicationController.HandleTypingIndicator) // Typing indicators
 	applicationRoutes.Post("/chat/threads/:threadId/read", applicationController.MarkMessagesAsRead)      // Read receipts
 	applicationRoutes.Get("/chat/threads/:threadId/unread", applicationController.GetUnreadCount)         // Unread message count
+	applicationRoutes.Get("/me/unread-summary", applicationController.GetUnreadSummaryController)         // Badge counts across applications
+	applicationRoutes.Get("/chat/threads/:threadId/state", applicationController.GetThreadStateController)
+	applicationRoutes.Patch("/chat/threads/:threadId/state/mute", applicationController.UpdateThreadMuteController)
 
 	// Unified Chat Participants Management (SINGLE ENDPOINT)
 	applicationRoutes.Post("/chat/threads/:threadId/participants", applicationController.UnifiedParticipantController)
@@ -99,13 +294,20 @@ func ApplicationRouterInit(
 	// Get Thread Participants (Separate GET endpoint)
 	applicationRoutes.Get("/chat/threads/:threadId/participants", applicationController.GetThreadParticipantsController)
 
+	// Invitations of participants from other departments
+	applicationRoutes.Get("/chat/threads/:threadId/invitations", applicationController.GetThreadInvitationsController)
+	applicationRoutes.Post("/chat/threads/:threadId/invitations/accept", applicationController.AcceptThreadInvitationController)
+	applicationRoutes.Post("/chat/threads/:threadId/invitations/decline", applicationController.DeclineThreadInvitationController)
+	applicationRoutes.Get("/chat/invitations", applicationController.GetMyThreadInvitationsController)
+
 	// New approval workflow endpoints
 	// applicationRoutes.Post("/applications/:id/assign-group", applicationController.AssignApplicationToGroupController)
 
 	// Chat Message Features - ADD THESE ROUTES
 	applicationRoutes.Post("/chat/messages/:messageId/star", applicationController.StarMessageController)
-	applicationRoutes.Post("/chat/messages/:messageId/reply", applicationController.ReplyToMessageController)
+	applicationRoutes.Post("/chat/messages/:messageId/reply", middleware.Upload(), applicationController.ReplyToMessageController)
 	applicationRoutes.Delete("/chat/messages/:messageId", applicationController.DeleteMessageController)
 	applicationRoutes.Get("/chat/messages/:messageId/stars", applicationController.GetMessageStarsController)
 	applicationRoutes.Get("/chat/messages/:messageId/thread", applicationController.GetMessageThreadController)
+	applicationRoutes.Post("/chat/messages/:messageId/raise-issue", applicationController.RaiseIssueFromMessageController)
 }
\ No newline at end of file
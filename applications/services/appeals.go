@@ -0,0 +1,42 @@
+package services
+
+import (
+	"os"
+	"time"
+	"town-planning-backend/config"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+const defaultAppealWindowDays = 30
+
+// AppealPolicy is the council's policy for appeals against rejected applications
+type AppealPolicy struct {
+	Window time.Duration   // Time after the rejection during which an appeal can be lodged
+	Fee    decimal.Decimal // Flat fee paid when lodging, in the application's tariff currency
+}
+
+// LoadAppealPolicy reads the appeal policy. Both variables are optional:
+//
+//	APPEAL_WINDOW_DAYS=30   days after the rejection an appeal can be lodged
+//	APPEAL_FEE=0            fee paid when lodging; appeals are free when unset
+func LoadAppealPolicy() AppealPolicy {
+	fee := decimal.Zero
+	if raw := os.Getenv("APPEAL_FEE"); raw != "" {
+		parsed, err := decimal.NewFromString(raw)
+		if err != nil || parsed.IsNegative() {
+			config.Logger.Warn("Invalid setting, using default",
+				zap.String("variable", "APPEAL_FEE"),
+				zap.String("value", raw),
+				zap.String("default", fee.String()))
+		} else {
+			fee = parsed.Round(2)
+		}
+	}
+
+	return AppealPolicy{
+		Window: time.Duration(positiveEnvInt("APPEAL_WINDOW_DAYS", defaultAppealWindowDays)) * 24 * time.Hour,
+		Fee:    fee,
+	}
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+	"town-planning-backend/settings"
+
+	"gorm.io/datatypes"
+)
+
+// ApplicationDraftPolicy says how long an untouched draft is kept
+type ApplicationDraftPolicy struct {
+	Expiry time.Duration // Time since the last save before a draft expires
+}
+
+// LoadApplicationDraftPolicy reads the draft policy from the applications.draft_expiry_days
+// setting, which falls back to APPLICATION_DRAFT_EXPIRY_DAYS until an administrator changes it
+func LoadApplicationDraftPolicy() ApplicationDraftPolicy {
+	return ApplicationDraftPolicy{
+		Expiry: time.Duration(settings.Int(settings.ApplicationDraftExpiryDays)) * 24 * time.Hour,
+	}
+}
+
+// ParseApplicationDraftData reads draft form data, which must be a JSON object. Nothing else is
+// checked; drafts are validated when they are submitted.
+func ParseApplicationDraftData(raw []byte) (map[string]json.RawMessage, error) {
+	fields := map[string]json.RawMessage{}
+	if len(raw) == 0 {
+		return fields, nil
+	}
+	if err := json.Unmarshal(raw, &fields); err != nil || fields == nil {
+		return nil, errors.New("draft data must be a JSON object")
+	}
+	return fields, nil
+}
+
+// MergeApplicationDraftData applies an autosave to the saved form data: fields in the patch
+// replace the saved ones and fields sent as null are removed
+func MergeApplicationDraftData(saved datatypes.JSON, patch []byte) (datatypes.JSON, error) {
+	fields, err := ParseApplicationDraftData(saved)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := ParseApplicationDraftData(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, value := range changes {
+		if string(value) == "null" {
+			delete(fields, name)
+			continue
+		}
+		fields[name] = value
+	}
+
+	merged, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	return datatypes.JSON(merged), nil
+}
@@ -0,0 +1,230 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+)
+
+// MaxCalendarWeeks bounds how far ahead the availability calendar looks
+const MaxCalendarWeeks = 26
+
+// MemberUnavailability is a window in which a group member is away or only handles critical
+// items. Until is nil when no return date was given.
+type MemberUnavailability struct {
+	MemberID        uuid.UUID                 `json:"member_id"`
+	UserID          uuid.UUID                 `json:"user_id"`
+	Name            string                    `json:"name"`
+	Role            models.MemberRole         `json:"role"`
+	IsFinalApprover bool                      `json:"is_final_approver"`
+	Weight          int                       `json:"weight"`
+	Status          models.AvailabilityStatus `json:"status"`
+	Reason          *string                   `json:"reason"`
+	From            string                    `json:"from"`
+	Until           *string                   `json:"until"` // Last day away
+}
+
+// CalendarDay is a day on which at least one member of the group is away
+type CalendarDay struct {
+	Date                 string      `json:"date"`
+	UnavailableMemberIDs []uuid.UUID `json:"unavailable_member_ids"`
+	CoveringBackupIDs    []uuid.UUID `json:"covering_backup_ids,omitempty"` // Backups standing in for absent primary members
+	AvailableWeight      int         `json:"available_weight"`
+	RequiredWeight       int         `json:"required_weight"`
+	QuorumMissing        bool        `json:"quorum_missing"`
+	FinalApproverMissing bool        `json:"final_approver_missing"`
+}
+
+// GroupAvailabilityCalendar is one approval group's calendar. Days lists only the days someone
+// is away; the flags on them say whether the group could still decide that day.
+type GroupAvailabilityCalendar struct {
+	GroupID              uuid.UUID              `json:"group_id"`
+	GroupName            string                 `json:"group_name"`
+	RequiresAllApprovals bool                   `json:"requires_all_approvals"`
+	MinimumApprovals     int                    `json:"minimum_approvals"`
+	AutoAssignBackups    bool                   `json:"auto_assign_backups"`
+	HasFinalApprover     bool                   `json:"has_final_approver"`
+	Unavailability       []MemberUnavailability `json:"unavailability"`
+	Days                 []CalendarDay          `json:"days"`
+	QuorumMissingDates   []string               `json:"quorum_missing_dates"`
+	FinalApproverMissing []string               `json:"final_approver_missing_dates"`
+}
+
+// AvailabilityCalendar covers every group from From to To, both inclusive
+type AvailabilityCalendar struct {
+	From   string                      `json:"from"`
+	To     string                      `json:"to"`
+	Weeks  int                         `json:"weeks"`
+	Groups []GroupAvailabilityCalendar `json:"groups"`
+}
+
+// BuildAvailabilityCalendar lays out when the groups' members are away over the next weeks,
+// starting from the day containing now. A member who is not AVAILABLE is away from today until
+// their UnavailableUntil, or for the whole calendar when no return date is set. Absent primary
+// members are covered by available backups, in backup priority order, when both the group
+// assigns backups automatically and the member allows reassignment. Groups' Members should be
+// loaded with their users.
+func BuildAvailabilityCalendar(groups []models.ApprovalGroup, now time.Time, weeks int, location *time.Location) AvailabilityCalendar {
+	if location == nil {
+		location = time.Local
+	}
+	now = now.In(location)
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+	days := weeks * 7
+
+	calendar := AvailabilityCalendar{
+		From:   start.Format("2006-01-02"),
+		To:     start.AddDate(0, 0, days-1).Format("2006-01-02"),
+		Weeks:  weeks,
+		Groups: make([]GroupAvailabilityCalendar, 0, len(groups)),
+	}
+	for i := range groups {
+		calendar.Groups = append(calendar.Groups, buildGroupCalendar(&groups[i], start, days, location))
+	}
+	sort.Slice(calendar.Groups, func(i, j int) bool {
+		return calendar.Groups[i].GroupName < calendar.Groups[j].GroupName
+	})
+	return calendar
+}
+
+func buildGroupCalendar(group *models.ApprovalGroup, start time.Time, days int, location *time.Location) GroupAvailabilityCalendar {
+	result := GroupAvailabilityCalendar{
+		GroupID:              group.ID,
+		GroupName:            group.Name,
+		RequiresAllApprovals: group.RequiresAllApprovals,
+		MinimumApprovals:     group.MinimumApprovals,
+		AutoAssignBackups:    group.AutoAssignBackups,
+		Unavailability:       []MemberUnavailability{},
+		Days:                 []CalendarDay{},
+		QuorumMissingDates:   []string{},
+		FinalApproverMissing: []string{},
+	}
+
+	var primaries, backups []models.ApprovalGroupMember
+	var finalApprover *models.ApprovalGroupMember
+	for i := range group.Members {
+		member := group.Members[i]
+		if !member.IsActive || member.Role == models.MemberRoleRetired {
+			continue
+		}
+		switch {
+		case member.IsFinalApprover:
+			finalApprover = &member
+		case member.Role == models.MemberRoleBackup:
+			backups = append(backups, member)
+		default:
+			primaries = append(primaries, member)
+		}
+
+		if member.AvailabilityStatus != models.AvailabilityAvailable && member.AvailabilityStatus != "" {
+			window := MemberUnavailability{
+				MemberID:        member.ID,
+				UserID:          member.UserID,
+				Name:            strings.TrimSpace(member.User.FirstName + " " + member.User.LastName),
+				Role:            member.Role,
+				IsFinalApprover: member.IsFinalApprover,
+				Weight:          member.Weight(),
+				Status:          member.AvailabilityStatus,
+				Reason:          member.UnavailableReason,
+				From:            start.Format("2006-01-02"),
+			}
+			if member.UnavailableUntil != nil {
+				until := lastDayAway(*member.UnavailableUntil, location).Format("2006-01-02")
+				window.Until = &until
+			}
+			if window.Until == nil || *window.Until >= window.From {
+				result.Unavailability = append(result.Unavailability, window)
+			}
+		}
+	}
+	result.HasFinalApprover = finalApprover != nil
+	sort.SliceStable(backups, func(i, j int) bool {
+		return backups[i].BackupPriority < backups[j].BackupPriority
+	})
+
+	totalWeight := 0
+	for _, member := range primaries {
+		totalWeight += member.Weight()
+	}
+	requiredWeight := totalWeight
+	if !group.RequiresAllApprovals {
+		requiredWeight = group.MinimumApprovals
+	}
+
+	for d := 0; d < days; d++ {
+		day := start.AddDate(0, 0, d)
+		calendarDay := CalendarDay{
+			Date:                 day.Format("2006-01-02"),
+			UnavailableMemberIDs: []uuid.UUID{},
+			RequiredWeight:       requiredWeight,
+		}
+
+		var availableBackups []models.ApprovalGroupMember
+		for _, backup := range backups {
+			if awayOn(&backup, day, location) {
+				calendarDay.UnavailableMemberIDs = append(calendarDay.UnavailableMemberIDs, backup.ID)
+			} else {
+				availableBackups = append(availableBackups, backup)
+			}
+		}
+
+		uncovered := false
+		for _, member := range primaries {
+			if !awayOn(&member, day, location) {
+				calendarDay.AvailableWeight += member.Weight()
+				continue
+			}
+			calendarDay.UnavailableMemberIDs = append(calendarDay.UnavailableMemberIDs, member.ID)
+			if group.AutoAssignBackups && member.AutoReassign && len(availableBackups) > 0 {
+				calendarDay.CoveringBackupIDs = append(calendarDay.CoveringBackupIDs, availableBackups[0].ID)
+				calendarDay.AvailableWeight += availableBackups[0].Weight()
+				availableBackups = availableBackups[1:]
+				continue
+			}
+			uncovered = true
+		}
+
+		if finalApprover != nil && awayOn(finalApprover, day, location) {
+			calendarDay.UnavailableMemberIDs = append(calendarDay.UnavailableMemberIDs, finalApprover.ID)
+		}
+		calendarDay.FinalApproverMissing = finalApprover == nil || awayOn(finalApprover, day, location)
+		if group.RequiresAllApprovals {
+			calendarDay.QuorumMissing = uncovered
+		} else {
+			calendarDay.QuorumMissing = calendarDay.AvailableWeight < requiredWeight
+		}
+
+		if calendarDay.QuorumMissing {
+			result.QuorumMissingDates = append(result.QuorumMissingDates, calendarDay.Date)
+		}
+		if calendarDay.FinalApproverMissing {
+			result.FinalApproverMissing = append(result.FinalApproverMissing, calendarDay.Date)
+		}
+		if len(calendarDay.UnavailableMemberIDs) > 0 || calendarDay.QuorumMissing || calendarDay.FinalApproverMissing {
+			result.Days = append(result.Days, calendarDay)
+		}
+	}
+
+	return result
+}
+
+// awayOn reports whether the member is away on the day starting at day. A member marked away
+// is back on the day their UnavailableUntil falls on.
+func awayOn(member *models.ApprovalGroupMember, day time.Time, location *time.Location) bool {
+	if member.AvailabilityStatus == models.AvailabilityAvailable || member.AvailabilityStatus == "" {
+		return false
+	}
+	if member.UnavailableUntil == nil {
+		return true
+	}
+	return !day.After(lastDayAway(*member.UnavailableUntil, location))
+}
+
+// lastDayAway is the last whole day before the member returns
+func lastDayAway(until time.Time, location *time.Location) time.Time {
+	until = until.In(location)
+	return time.Date(until.Year(), until.Month(), until.Day(), 0, 0, 0, 0, location).AddDate(0, 0, -1)
+}
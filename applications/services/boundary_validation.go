@@ -0,0 +1,269 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+)
+
+// BoundaryFeature is one polygon feature of a boundary layer. Coordinates are GeoJSON
+// [longitude, latitude] pairs; each polygon is an outer ring followed by any holes.
+type BoundaryFeature struct {
+	Name     string
+	Polygons [][][][2]float64
+}
+
+// Contains reports whether the point lies inside the feature, outside any of its holes
+func (f BoundaryFeature) Contains(longitude, latitude float64) bool {
+	for _, polygon := range f.Polygons {
+		if len(polygon) == 0 || !ringContains(polygon[0], longitude, latitude) {
+			continue
+		}
+		inHole := false
+		for _, hole := range polygon[1:] {
+			if ringContains(hole, longitude, latitude) {
+				inHole = true
+				break
+			}
+		}
+		if !inHole {
+			return true
+		}
+	}
+	return false
+}
+
+// ringContains is the even-odd ray casting test
+func ringContains(ring [][2]float64, x, y float64) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		xi, yi := ring[i][0], ring[i][1]
+		xj, yj := ring[j][0], ring[j][1]
+		if (yi > y) != (yj > y) && x < (xj-xi)*(y-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+type geoJSONObject struct {
+	Type        string                 `json:"type"`
+	Features    []geoJSONObject        `json:"features"`
+	Geometry    *geoJSONObject         `json:"geometry"`
+	Geometries  []geoJSONObject        `json:"geometries"`
+	Properties  map[string]interface{} `json:"properties"`
+	Coordinates json.RawMessage        `json:"coordinates"`
+}
+
+// ParseBoundaryGeoJSON reads the polygon features of a FeatureCollection, Feature or bare
+// Polygon/MultiPolygon geometry. Feature names are taken from nameProperty.
+func ParseBoundaryGeoJSON(data []byte, nameProperty string) ([]BoundaryFeature, error) {
+	var root geoJSONObject
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("invalid GeoJSON: %w", err)
+	}
+
+	var objects []geoJSONObject
+	switch root.Type {
+	case "FeatureCollection":
+		objects = root.Features
+	case "Feature", "Polygon", "MultiPolygon", "GeometryCollection":
+		objects = []geoJSONObject{root}
+	default:
+		return nil, fmt.Errorf("unsupported GeoJSON type %q", root.Type)
+	}
+
+	features := []BoundaryFeature{}
+	for i, object := range objects {
+		feature := BoundaryFeature{}
+		geometry := &object
+		if object.Type == "Feature" {
+			if object.Geometry == nil {
+				continue
+			}
+			geometry = object.Geometry
+			if value, ok := object.Properties[nameProperty]; ok && value != nil {
+				feature.Name = strings.TrimSpace(fmt.Sprint(value))
+			}
+		}
+
+		polygons, err := geoJSONPolygons(geometry)
+		if err != nil {
+			return nil, fmt.Errorf("feature %d: %w", i+1, err)
+		}
+		if len(polygons) == 0 {
+			continue
+		}
+		feature.Polygons = polygons
+		features = append(features, feature)
+	}
+
+	if len(features) == 0 {
+		return nil, errors.New("GeoJSON contains no polygons")
+	}
+	return features, nil
+}
+
+func geoJSONPolygons(geometry *geoJSONObject) ([][][][2]float64, error) {
+	switch geometry.Type {
+	case "Polygon":
+		var polygon [][][2]float64
+		if err := json.Unmarshal(geometry.Coordinates, &polygon); err != nil {
+			return nil, fmt.Errorf("invalid polygon coordinates: %w", err)
+		}
+		return [][][][2]float64{polygon}, nil
+	case "MultiPolygon":
+		var polygons [][][][2]float64
+		if err := json.Unmarshal(geometry.Coordinates, &polygons); err != nil {
+			return nil, fmt.Errorf("invalid multipolygon coordinates: %w", err)
+		}
+		return polygons, nil
+	case "GeometryCollection":
+		var polygons [][][][2]float64
+		for i := range geometry.Geometries {
+			found, err := geoJSONPolygons(&geometry.Geometries[i])
+			if err != nil {
+				return nil, err
+			}
+			polygons = append(polygons, found...)
+		}
+		return polygons, nil
+	}
+	// Points and lines cannot contain a stand
+	return nil, nil
+}
+
+// ValidateBoundaryLayer checks an upload before it is stored and returns its feature count.
+// Every feature of a ward layer must be named.
+func ValidateBoundaryLayer(kind models.BoundaryLayerKind, data []byte, nameProperty string) (int, error) {
+	features, err := ParseBoundaryGeoJSON(data, nameProperty)
+	if err != nil {
+		return 0, err
+	}
+	if kind == models.BoundaryLayerWard {
+		for i, feature := range features {
+			if feature.Name == "" {
+				return 0, fmt.Errorf("ward feature %d has no %q property", i+1, nameProperty)
+			}
+		}
+	}
+	return len(features), nil
+}
+
+// BoundaryValidator checks stand coordinates against the active boundary layers. Parsed layers
+// are cached until the layer is updated, since the GeoJSON can be large.
+type BoundaryValidator struct {
+	mu     sync.Mutex
+	layers map[uuid.UUID]parsedBoundaryLayer
+}
+
+type parsedBoundaryLayer struct {
+	updatedAt time.Time
+	features  []BoundaryFeature
+}
+
+func NewBoundaryValidator() *BoundaryValidator {
+	return &BoundaryValidator{
+		layers: map[uuid.UUID]parsedBoundaryLayer{},
+	}
+}
+
+func (v *BoundaryValidator) features(layer *models.BoundaryLayer) ([]BoundaryFeature, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if cached, ok := v.layers[layer.ID]; ok && cached.updatedAt.Equal(layer.UpdatedAt) {
+		return cached.features, nil
+	}
+
+	features, err := ParseBoundaryGeoJSON(layer.GeoJSON, layer.NameProperty)
+	if err != nil {
+		return nil, fmt.Errorf("boundary layer %s: %w", layer.Name, err)
+	}
+	v.layers[layer.ID] = parsedBoundaryLayer{updatedAt: layer.UpdatedAt, features: features}
+	return features, nil
+}
+
+// CheckStand validates the stand's coordinates against the jurisdiction and ward layers, either
+// of which may be nil. It returns nil when no layer is configured.
+func (v *BoundaryValidator) CheckStand(stand *models.Stand, jurisdiction, ward *models.BoundaryLayer) (*models.BoundaryCheck, error) {
+	if jurisdiction == nil && ward == nil {
+		return nil, nil
+	}
+
+	check := &models.BoundaryCheck{
+		StandID:      stand.ID,
+		Latitude:     stand.Latitude,
+		Longitude:    stand.Longitude,
+		RecordedWard: stand.Ward,
+		CheckedAt:    time.Now(),
+	}
+	if jurisdiction != nil {
+		check.JurisdictionLayerID = &jurisdiction.ID
+	}
+	if ward != nil {
+		check.WardLayerID = &ward.ID
+	}
+
+	result := func(status models.BoundaryCheckStatus, message string) (*models.BoundaryCheck, error) {
+		check.Status = status
+		check.Message = &message
+		return check, nil
+	}
+
+	if stand.Latitude == nil || stand.Longitude == nil {
+		return result(models.BoundaryNoCoordinates, fmt.Sprintf("stand %s has no coordinates", stand.StandNumber))
+	}
+	latitude, _ := stand.Latitude.Float64()
+	longitude, _ := stand.Longitude.Float64()
+
+	if jurisdiction != nil {
+		features, err := v.features(jurisdiction)
+		if err != nil {
+			return nil, err
+		}
+		inside := false
+		for _, feature := range features {
+			if feature.Contains(longitude, latitude) {
+				inside = true
+				break
+			}
+		}
+		if !inside {
+			return result(models.BoundaryOutsideJurisdiction,
+				fmt.Sprintf("stand %s lies outside the council's jurisdiction", stand.StandNumber))
+		}
+	}
+
+	if ward != nil {
+		features, err := v.features(ward)
+		if err != nil {
+			return nil, err
+		}
+		for _, feature := range features {
+			if feature.Contains(longitude, latitude) {
+				name := feature.Name
+				check.DetectedWard = &name
+				break
+			}
+		}
+		if check.DetectedWard == nil {
+			return result(models.BoundaryOutsideWards,
+				fmt.Sprintf("stand %s lies in no ward", stand.StandNumber))
+		}
+		if stand.Ward != nil && strings.TrimSpace(*stand.Ward) != "" &&
+			!strings.EqualFold(strings.TrimSpace(*stand.Ward), *check.DetectedWard) {
+			return result(models.BoundaryWardMismatch,
+				fmt.Sprintf("stand %s is recorded in ward %s but lies in ward %s",
+					stand.StandNumber, strings.TrimSpace(*stand.Ward), *check.DetectedWard))
+		}
+	}
+
+	return result(models.BoundaryWithin, fmt.Sprintf("stand %s lies within the council boundaries", stand.StandNumber))
+}
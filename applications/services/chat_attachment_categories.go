@@ -0,0 +1,48 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultChatAttachmentCategory is the document category used for chat files sent without one
+const DefaultChatAttachmentCategory = "CHAT_ATTACHMENT"
+
+// checklistFlagsByCategory maps the document categories on the application checklist to the
+// application flag that records the document was provided
+var checklistFlagsByCategory = map[string]string{
+	"PROCESSED_RECEIPT":       "processed_receipt_provided",
+	"TPD1_FORM":               "processed_tpd1_form_provided",
+	"PROCESSED_QUOTATION":     "processed_quotation_provided",
+	"QUOTATION":               "processed_quotation_provided",
+	"INITIAL_PLAN":            "initial_plan_provided",
+	"ENGINEERING_CERTIFICATE": "structural_engineering_certificate_provided",
+	"RING_BEAM_CERTIFICATE":   "ring_beam_certificate_provided",
+}
+
+// ChecklistFlagForCategory returns the application checklist flag a document of the category
+// satisfies, if any
+func ChecklistFlagForCategory(categoryCode string) (string, bool) {
+	flag, ok := checklistFlagsByCategory[categoryCode]
+	return flag, ok
+}
+
+// ParseAttachmentCategories reads the attachment_categories form values sent with a chat message.
+// They pair with the attachments by position; a blank or missing entry keeps the default chat
+// category. The result always has one category per file.
+func ParseAttachmentCategories(values []string, fileCount int) ([]string, error) {
+	if len(values) > fileCount {
+		return nil, fmt.Errorf("%d attachment categories were sent for %d attachments", len(values), fileCount)
+	}
+
+	categories := make([]string, fileCount)
+	for i := range categories {
+		categories[i] = DefaultChatAttachmentCategory
+		if i < len(values) {
+			if code := strings.ToUpper(strings.TrimSpace(values[i])); code != "" {
+				categories[i] = code
+			}
+		}
+	}
+	return categories, nil
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Commands that can be typed into an issue's chat in place of a message
+const (
+	ChatCommandAssign   = "assign"   // /assign @user
+	ChatCommandPriority = "priority" // /priority HIGH
+	ChatCommandResolve  = "resolve"  // /resolve reason...
+)
+
+// IssuePriorities are the priorities an issue can be given, lowest first
+var IssuePriorities = []string{"LOW", "MEDIUM", "HIGH", "CRITICAL"}
+
+// ChatCommand is a parsed chat command. Argument is everything after the command name, trimmed.
+type ChatCommand struct {
+	Name     string
+	Argument string
+}
+
+// ParseChatCommand reads a command from a chat message. It returns nil and no error for an
+// ordinary message, and an error for a message that starts with "/" but is not a valid command,
+// so a mistyped command is not posted to the conversation as text. "//" escapes a message that
+// should start with a slash; the caller strips one of them.
+func ParseChatCommand(content string) (*ChatCommand, error) {
+	content = strings.TrimSpace(content)
+	if !strings.HasPrefix(content, "/") || strings.HasPrefix(content, "//") {
+		return nil, nil
+	}
+
+	name, argument, _ := strings.Cut(content[1:], " ")
+	command := &ChatCommand{
+		Name:     strings.ToLower(name),
+		Argument: strings.TrimSpace(argument),
+	}
+
+	switch command.Name {
+	case ChatCommandAssign:
+		if !strings.HasPrefix(command.Argument, "@") {
+			return nil, errors.New("usage: /assign @user")
+		}
+	case ChatCommandPriority:
+		priority := strings.ToUpper(command.Argument)
+		if !isIssuePriority(priority) {
+			return nil, fmt.Errorf("usage: /priority %s", strings.Join(IssuePriorities, "|"))
+		}
+		command.Argument = priority
+	case ChatCommandResolve:
+		if command.Argument == "" {
+			return nil, errors.New("usage: /resolve reason")
+		}
+	default:
+		return nil, fmt.Errorf("unknown command /%s, available commands are /assign, /priority and /resolve", name)
+	}
+
+	return command, nil
+}
+
+// UnescapeChatCommand drops the extra slash from a message escaped with "//"
+func UnescapeChatCommand(content string) string {
+	if trimmed := strings.TrimLeft(content, " \t\r\n"); strings.HasPrefix(trimmed, "//") {
+		return trimmed[1:]
+	}
+	return content
+}
+
+func isIssuePriority(priority string) bool {
+	for _, valid := range IssuePriorities {
+		if priority == valid {
+			return true
+		}
+	}
+	return false
+}
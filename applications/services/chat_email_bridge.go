@@ -0,0 +1,120 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"town-planning-backend/db/models"
+
+	"gorm.io/gorm"
+)
+
+// replyTokenEncoding keeps reply tokens to characters mail servers accept in a local part
+// whatever their case handling
+var replyTokenEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// EmailBridgeCandidates returns the states of participants with text messages from others that
+// have been waiting since before cutoff, unread and not yet emailed. Participants who muted the
+// thread or had it open since cutoff are left out.
+func (s *ThreadStateService) EmailBridgeCandidates(now, cutoff time.Time, limit int) ([]models.ParticipantThreadState, error) {
+	var states []models.ParticipantThreadState
+	err := s.db.Raw(`
+		SELECT s.* FROM participant_thread_states s
+		WHERE s.unread_count > 0
+		AND NOT (s.is_muted AND (s.muted_until IS NULL OR s.muted_until > @now))
+		AND (s.last_seen_at IS NULL OR s.last_seen_at < @cutoff)
+		AND EXISTS (
+			SELECT 1 FROM chat_participants p
+			WHERE p.thread_id = s.thread_id AND p.user_id = s.user_id AND p.is_active = true
+		)
+		AND EXISTS (
+			SELECT 1 FROM chat_messages m
+			WHERE m.thread_id = s.thread_id AND m.sender_id <> s.user_id
+			AND m.is_deleted = false AND m.message_type = @text
+			AND m.created_at < @cutoff
+			AND m.created_at > COALESCE(GREATEST(s.last_read_at, s.email_bridged_at), '-infinity')
+		)
+		ORDER BY s.updated_at ASC
+		LIMIT @limit`,
+		map[string]interface{}{
+			"now":    now,
+			"cutoff": cutoff,
+			"text":   models.MessageTypeText,
+			"limit":  limit,
+		}).Scan(&states).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find participants to email: %w", err)
+	}
+	return states, nil
+}
+
+// UnbridgedMessages returns the participant's unread text messages that have not been emailed
+// yet, oldest first
+func (s *ThreadStateService) UnbridgedMessages(state *models.ParticipantThreadState, limit int) ([]models.ChatMessage, error) {
+	query := s.db.Preload("Sender").
+		Where("thread_id = ? AND sender_id <> ? AND is_deleted = ? AND message_type = ?",
+			state.ThreadID, state.UserID, false, models.MessageTypeText)
+	if since := latest(state.LastReadAt, state.EmailBridgedAt); since != nil {
+		query = query.Where("created_at > ?", *since)
+	}
+
+	var messages []models.ChatMessage
+	if err := query.Order("created_at ASC").Limit(limit).Find(&messages).Error; err != nil {
+		return nil, fmt.Errorf("failed to load unread messages: %w", err)
+	}
+	return messages, nil
+}
+
+// EnsureEmailReplyToken returns the secret in the participant's reply-by-email address for the
+// thread, creating it on first use
+func (s *ThreadStateService) EnsureEmailReplyToken(state *models.ParticipantThreadState) (string, error) {
+	if state.EmailReplyToken != nil {
+		return *state.EmailReplyToken, nil
+	}
+
+	secret := make([]byte, 15)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate reply token: %w", err)
+	}
+	replyToken := strings.ToLower(replyTokenEncoding.EncodeToString(secret))
+
+	if err := s.db.Model(state).Update("email_reply_token", replyToken).Error; err != nil {
+		return "", fmt.Errorf("failed to store reply token: %w", err)
+	}
+	state.EmailReplyToken = &replyToken
+	return replyToken, nil
+}
+
+// MarkEmailBridged records that the participant's unread messages up to at were emailed
+func (s *ThreadStateService) MarkEmailBridged(state *models.ParticipantThreadState, at time.Time) error {
+	if err := s.db.Model(state).Update("email_bridged_at", at).Error; err != nil {
+		return fmt.Errorf("failed to record emailed messages: %w", err)
+	}
+	state.EmailBridgedAt = &at
+	return nil
+}
+
+// GetStateByEmailReplyToken returns the state whose reply-by-email address carries the token
+func (s *ThreadStateService) GetStateByEmailReplyToken(replyToken string) (*models.ParticipantThreadState, error) {
+	var state models.ParticipantThreadState
+	if err := s.db.Where("email_reply_token = ?", strings.ToLower(replyToken)).First(&state).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("unknown reply address")
+		}
+		return nil, fmt.Errorf("failed to load thread state: %w", err)
+	}
+	return &state, nil
+}
+
+func latest(times ...*time.Time) *time.Time {
+	var result *time.Time
+	for _, t := range times {
+		if t != nil && (result == nil || t.After(*result)) {
+			result = t
+		}
+	}
+	return result
+}
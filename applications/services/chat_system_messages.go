@@ -0,0 +1,269 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"town-planning-backend/db/models"
+
+	"gorm.io/datatypes"
+)
+
+// Languages supported for chat system messages
+const (
+	LanguageEnglish = "en"
+	LanguageShona   = "sn"
+	LanguageNdebele = "nd"
+	DefaultLanguage = LanguageEnglish
+)
+
+const (
+	// Bulk add/remove messages name up to this many participants, then fall back to a count
+	maxNamesInline = 3
+
+	permissionInvite = "invite"
+	permissionRemove = "remove"
+	permissionManage = "manage"
+)
+
+// SystemEventParams are the values a system message is rendered from
+type SystemEventParams struct {
+	ActorName   string   `json:"actor_name"`
+	TargetNames []string `json:"target_names,omitempty"`
+	TargetCount int      `json:"target_count,omitempty"` // Set when only the number of targets is known
+	Permissions []string `json:"permissions,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Comment     string   `json:"comment,omitempty"`
+}
+
+// systemMessageCatalog holds the templates for one language. Placeholders are
+// {actor}, {targets}, {count}, {permissions}, {description} and {comment}.
+type systemMessageCatalog struct {
+	templates     map[models.SystemEventType]string
+	addedMany     string
+	removedMany   string
+	withPerms     string
+	withComment   string
+	listSeparator string
+	permissions   map[string]string
+}
+
+var systemMessageCatalogs = map[string]systemMessageCatalog{
+	LanguageEnglish: {
+		templates: map[models.SystemEventType]string{
+			models.SystemEventParticipantAdded:     "{actor} added {targets} to the conversation",
+			models.SystemEventParticipantsAdded:    "{actor} added {targets} to the conversation",
+			models.SystemEventParticipantRemoved:   "{actor} removed {targets} from the conversation",
+			models.SystemEventParticipantsRemoved:  "{actor} removed {targets} from the conversation",
+			models.SystemEventIssueCreated:         "Issue created: {description}",
+			models.SystemEventIssueResolved:        "Issue resolved by {actor}",
+			models.SystemEventIssueReopened:        "Issue reopened by {actor}",
+			models.SystemEventIssueFromMessage:     "{actor} raised an issue from a message: {description}",
+			models.SystemEventIssueSourceMessage:   "{actor} raised this issue from a message in \"{description}\"",
+			models.SystemEventDecisionEscalated:    "{actor}'s decision is overdue and has been escalated to {targets}: {description}",
+			models.SystemEventIssueAssigned:        "{actor} assigned the issue to {targets}",
+			models.SystemEventIssuePriorityChanged: "{actor} set the issue priority to {description}",
+			models.SystemEventParticipantInvited:   "{actor} invited {targets} to the conversation",
+			models.SystemEventInvitationAccepted:   "{actor} accepted the invitation and joined the conversation",
+			models.SystemEventInvitationDeclined:   "{actor} declined the invitation to the conversation",
+			models.SystemEventApplicationApproved:  "Application {description} was approved. This conversation is now read-only.",
+			models.SystemEventApplicationRejected:  "Application {description} was rejected. This conversation is now read-only.",
+			models.SystemEventThreadUnfrozen:       "{actor} reopened this conversation",
+		},
+		addedMany:     "{actor} added {count} participants to the conversation",
+		removedMany:   "{actor} removed {count} participants from the conversation",
+		withPerms:     " with {permissions} permissions",
+		withComment:   ":\n{comment}",
+		listSeparator: "/",
+		permissions: map[string]string{
+			permissionInvite: "invite",
+			permissionRemove: "remove",
+			permissionManage: "manage permissions",
+		},
+	},
+	LanguageShona: {
+		templates: map[models.SystemEventType]string{
+			models.SystemEventParticipantAdded:     "{actor} apinza {targets} muhurukuro",
+			models.SystemEventParticipantsAdded:    "{actor} apinza {targets} muhurukuro",
+			models.SystemEventParticipantRemoved:   "{actor} abvisa {targets} muhurukuro",
+			models.SystemEventParticipantsRemoved:  "{actor} abvisa {targets} muhurukuro",
+			models.SystemEventIssueCreated:         "Nyaya yavhurwa: {description}",
+			models.SystemEventIssueResolved:        "Nyaya yagadziriswa na{actor}",
+			models.SystemEventIssueReopened:        "Nyaya yavhurwazve na{actor}",
+			models.SystemEventIssueFromMessage:     "{actor} avhura nyaya kubva pamharidzo: {description}",
+			models.SystemEventIssueSourceMessage:   "{actor} avhura nyaya iyi kubva pamharidzo mu\"{description}\"",
+			models.SystemEventDecisionEscalated:    "Sarudzo ya{actor} yanonoka, yaendeswa kuna {targets}: {description}",
+			models.SystemEventIssueAssigned:        "{actor} apa nyaya iyi kuna {targets}",
+			models.SystemEventIssuePriorityChanged: "{actor} aisa kukosha kwenyaya pa{description}",
+			models.SystemEventParticipantInvited:   "{actor} akoka {targets} kuhurukuro",
+			models.SystemEventInvitationAccepted:   "{actor} abvuma kukokwa uye apinda muhurukuro",
+			models.SystemEventInvitationDeclined:   "{actor} aramba kukokwa kuhurukuro",
+			models.SystemEventApplicationApproved:  "Chikumbiro {description} chabvumidzwa. Hurukuro iyi yava yekuverenga chete.",
+			models.SystemEventApplicationRejected:  "Chikumbiro {description} charambwa. Hurukuro iyi yava yekuverenga chete.",
+			models.SystemEventThreadUnfrozen:       "{actor} avhurazve hurukuro iyi",
+		},
+		addedMany:     "{actor} apinza vanhu {count} muhurukuro",
+		removedMany:   "{actor} abvisa vanhu {count} muhurukuro",
+		withPerms:     " ane mvumo: {permissions}",
+		withComment:   ":\n{comment}",
+		listSeparator: ", ",
+		permissions: map[string]string{
+			permissionInvite: "kukoka",
+			permissionRemove: "kubvisa",
+			permissionManage: "kutonga mvumo",
+		},
+	},
+	LanguageNdebele: {
+		templates: map[models.SystemEventType]string{
+			models.SystemEventParticipantAdded:     "{actor} ufake {targets} engxoxweni",
+			models.SystemEventParticipantsAdded:    "{actor} ufake {targets} engxoxweni",
+			models.SystemEventParticipantRemoved:   "{actor} ususe {targets} engxoxweni",
+			models.SystemEventParticipantsRemoved:  "{actor} ususe {targets} engxoxweni",
+			models.SystemEventIssueCreated:         "Udaba ludaliwe: {description}",
+			models.SystemEventIssueResolved:        "Udaba luxazululwe ngu-{actor}",
+			models.SystemEventIssueReopened:        "Udaba luvulwe kutsha ngu-{actor}",
+			models.SystemEventIssueFromMessage:     "{actor} uvule udaba kusuka emlayezweni: {description}",
+			models.SystemEventIssueSourceMessage:   "{actor} uvule udaba lolu kusuka emlayezweni ku-\"{description}\"",
+			models.SystemEventDecisionEscalated:    "Isinqumo sika-{actor} sephuzile, sedluliselwe ku-{targets}: {description}",
+			models.SystemEventIssueAssigned:        "{actor} unike udaba lolu ku-{targets}",
+			models.SystemEventIssuePriorityChanged: "{actor} ubeke ukuqakatheka kodaba ku-{description}",
+			models.SystemEventParticipantInvited:   "{actor} umeme {targets} engxoxweni",
+			models.SystemEventInvitationAccepted:   "{actor} wamukele isimemo wangena engxoxweni",
+			models.SystemEventInvitationDeclined:   "{actor} walile isimemo sengxoxo",
+			models.SystemEventApplicationApproved:  "Isicelo {description} samukelwe. Ingxoxo le isingeyokubala kuphela.",
+			models.SystemEventApplicationRejected:  "Isicelo {description} salelwe. Ingxoxo le isingeyokubala kuphela.",
+			models.SystemEventThreadUnfrozen:       "{actor} uvule kutsha ingxoxo le",
+		},
+		addedMany:     "{actor} ufake abantu abangu-{count} engxoxweni",
+		removedMany:   "{actor} ususe abantu abangu-{count} engxoxweni",
+		withPerms:     " elemvumo: {permissions}",
+		withComment:   ":\n{comment}",
+		listSeparator: ", ",
+		permissions: map[string]string{
+			permissionInvite: "ukumema",
+			permissionRemove: "ukususa",
+			permissionManage: "ukuphatha imvumo",
+		},
+	},
+}
+
+// NormalizeLanguage maps a stored or requested language ("sn", "en-GB") to a supported one
+func NormalizeLanguage(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if idx := strings.IndexAny(language, "-_"); idx > 0 {
+		language = language[:idx]
+	}
+	if _, ok := systemMessageCatalogs[language]; ok {
+		return language
+	}
+	return DefaultLanguage
+}
+
+// SupportedLanguage reports whether system messages can be rendered in the language
+func SupportedLanguage(language string) bool {
+	_, ok := systemMessageCatalogs[strings.ToLower(strings.TrimSpace(language))]
+	return ok
+}
+
+// ParticipantPermissions lists the non-default permissions granted when adding a participant
+func ParticipantPermissions(canInvite, canRemove, canManage bool) []string {
+	permissions := []string{}
+	if canInvite {
+		permissions = append(permissions, permissionInvite)
+	}
+	if canRemove {
+		permissions = append(permissions, permissionRemove)
+	}
+	if canManage {
+		permissions = append(permissions, permissionManage)
+	}
+	return permissions
+}
+
+// NewSystemMessage builds a SYSTEM chat message carrying a structured event. Content is
+// the English rendering, kept for search and for clients that do not localize.
+func NewSystemMessage(eventType models.SystemEventType, params SystemEventParams) (models.ChatMessage, error) {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return models.ChatMessage{}, fmt.Errorf("failed to encode system event params: %w", err)
+	}
+
+	return models.ChatMessage{
+		Content:     renderSystemEvent(eventType, params, DefaultLanguage),
+		MessageType: models.MessageTypeSystem,
+		EventType:   &eventType,
+		EventParams: datatypes.JSON(raw),
+		Status:      models.MessageStatusSent,
+	}, nil
+}
+
+// LocalizeSystemMessage renders a structured system message in the given language. Messages
+// without an event (user messages, unmigrated legacy messages) keep their stored content.
+func LocalizeSystemMessage(eventType *models.SystemEventType, eventParams datatypes.JSON, content string, language string) string {
+	if eventType == nil || len(eventParams) == 0 {
+		return content
+	}
+
+	var params SystemEventParams
+	if err := json.Unmarshal(eventParams, &params); err != nil {
+		return content
+	}
+
+	rendered := renderSystemEvent(*eventType, params, NormalizeLanguage(language))
+	if rendered == "" {
+		return content
+	}
+	return rendered
+}
+
+func renderSystemEvent(eventType models.SystemEventType, params SystemEventParams, language string) string {
+	catalog, ok := systemMessageCatalogs[language]
+	if !ok {
+		catalog = systemMessageCatalogs[DefaultLanguage]
+	}
+
+	template, ok := catalog.templates[eventType]
+	if !ok {
+		return ""
+	}
+
+	targetCount := len(params.TargetNames)
+	if params.TargetCount > targetCount {
+		targetCount = params.TargetCount
+	}
+
+	switch eventType {
+	case models.SystemEventParticipantsAdded:
+		if targetCount > maxNamesInline {
+			template = catalog.addedMany
+		}
+	case models.SystemEventParticipantsRemoved:
+		if targetCount > maxNamesInline {
+			template = catalog.removedMany
+		}
+	case models.SystemEventParticipantAdded:
+		if len(params.Permissions) > 0 {
+			translated := make([]string, 0, len(params.Permissions))
+			for _, permission := range params.Permissions {
+				if label, ok := catalog.permissions[permission]; ok {
+					translated = append(translated, label)
+				} else {
+					translated = append(translated, permission)
+				}
+			}
+			template += strings.ReplaceAll(catalog.withPerms, "{permissions}", strings.Join(translated, catalog.listSeparator))
+		}
+	case models.SystemEventIssueResolved, models.SystemEventThreadUnfrozen:
+		if params.Comment != "" {
+			template += catalog.withComment
+		}
+	}
+
+	return strings.NewReplacer(
+		"{actor}", params.ActorName,
+		"{targets}", strings.Join(params.TargetNames, ", "),
+		"{count}", fmt.Sprintf("%d", targetCount),
+		"{description}", params.Description,
+		"{comment}", params.Comment,
+	).Replace(template)
+}
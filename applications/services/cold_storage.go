@@ -0,0 +1,117 @@
+package services
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"time"
+	"town-planning-backend/db/models"
+)
+
+const (
+	defaultArchiveAfterYears          = 7
+	defaultArchiveBatchSize           = 100
+	defaultArchiveRehydrationHoldDays = 90
+
+	archivedFileSuffix = ".gz"
+)
+
+// ArchiveTerminalStatuses are the statuses an application never leaves, and so can be archived
+var ArchiveTerminalStatuses = []models.ApplicationStatus{
+	models.RejectedApplication,
+	models.CollectedApplication,
+	models.ExpiredApplication,
+}
+
+// ArchivePolicy says which applications move to cold storage
+type ArchivePolicy struct {
+	After           time.Duration // Time since the application closed
+	BatchSize       int           // Applications archived per run
+	RehydrationHold time.Duration // A rehydrated application stays out of storage this long
+}
+
+// LoadArchivePolicy reads the archive policy. All variables are optional:
+//
+//	ARCHIVE_AFTER_YEARS=7                 archive terminal applications closed this long ago
+//	ARCHIVE_BATCH_SIZE=100                applications archived per nightly run
+//	ARCHIVE_REHYDRATION_HOLD_DAYS=90      keep rehydrated applications out of storage this long
+func LoadArchivePolicy() ArchivePolicy {
+	day := 24 * time.Hour
+	return ArchivePolicy{
+		After:           time.Duration(positiveEnvInt("ARCHIVE_AFTER_YEARS", defaultArchiveAfterYears)) * 365 * day,
+		BatchSize:       positiveEnvInt("ARCHIVE_BATCH_SIZE", defaultArchiveBatchSize),
+		RehydrationHold: time.Duration(positiveEnvInt("ARCHIVE_REHYDRATION_HOLD_DAYS", defaultArchiveRehydrationHoldDays)) * day,
+	}
+}
+
+// CompressArchiveFile writes a gzip copy of a document next to it and returns the copy's path
+// with both sizes. The original is left in place until the archive is committed.
+func CompressArchiveFile(path string) (string, int64, int64, error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	defer src.Close()
+
+	archivePath := path + archivedFileSuffix
+	dst, err := os.Create(archivePath)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("failed to create archive file: %w", err)
+	}
+
+	zw, err := gzip.NewWriterLevel(dst, gzip.BestCompression)
+	if err != nil {
+		dst.Close()
+		os.Remove(archivePath)
+		return "", 0, 0, err
+	}
+	originalSize, err := io.Copy(zw, src)
+	if err == nil {
+		err = zw.Close()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(archivePath)
+		return "", 0, 0, fmt.Errorf("failed to compress %s: %w", path, err)
+	}
+
+	info, err := os.Stat(archivePath)
+	if err != nil {
+		os.Remove(archivePath)
+		return "", 0, 0, err
+	}
+	return archivePath, originalSize, info.Size(), nil
+}
+
+// RestoreArchiveFile decompresses an archived document back to its original path. The archive
+// copy is left in place until the rehydration is committed.
+func RestoreArchiveFile(archivePath string, originalPath string) error {
+	src, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	zr, err := gzip.NewReader(src)
+	if err != nil {
+		return fmt.Errorf("failed to read archive file %s: %w", archivePath, err)
+	}
+	defer zr.Close()
+
+	dst, err := os.Create(originalPath)
+	if err != nil {
+		return fmt.Errorf("failed to restore %s: %w", originalPath, err)
+	}
+	_, err = io.Copy(dst, zr)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(originalPath)
+		return fmt.Errorf("failed to decompress %s: %w", archivePath, err)
+	}
+	return nil
+}
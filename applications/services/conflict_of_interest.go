@@ -0,0 +1,52 @@
+package services
+
+import (
+	"strings"
+	"town-planning-backend/db/models"
+	"unicode"
+)
+
+// DetectConflictIndicators compares a reviewer with the applicant and lists the heuristics that
+// suggest they may be related. A match is not proof of a conflict; it only obliges the reviewer
+// to make a declaration before deciding on the application.
+func DetectConflictIndicators(reviewer models.User, applicant models.Applicant) []models.ConflictIndicator {
+	indicators := []models.ConflictIndicator{}
+
+	reviewerSurname := normalizeForMatch(reviewer.LastName)
+	if reviewerSurname != "" {
+		surnames := []string{}
+		if applicant.LastName != nil {
+			surnames = append(surnames, *applicant.LastName)
+		}
+		// Organisations are checked against the people who represent them
+		for _, representative := range applicant.OrganisationRepresentatives {
+			surnames = append(surnames, representative.LastName)
+		}
+		for _, surname := range surnames {
+			if normalizeForMatch(surname) == reviewerSurname {
+				indicators = append(indicators, models.ConflictIndicatorSurname)
+				break
+			}
+		}
+	}
+
+	if reviewer.ResidentialAddress != nil && applicant.PostalAddress != nil {
+		reviewerAddress := normalizeForMatch(*reviewer.ResidentialAddress)
+		if reviewerAddress != "" && reviewerAddress == normalizeForMatch(*applicant.PostalAddress) {
+			indicators = append(indicators, models.ConflictIndicatorAddress)
+		}
+	}
+
+	return indicators
+}
+
+// normalizeForMatch lower-cases and keeps only letters and digits, so "12 Main St." matches "12 main st"
+func normalizeForMatch(value string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(value) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// IdempotencyKeyHeader carries the client's key for a decision request
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// MaxIdempotencyKeyLength bounds the keys clients may send
+const MaxIdempotencyKeyLength = 100
+
+// DecisionIdempotencyTTL is how long a decision's result is replayed for its key
+const DecisionIdempotencyTTL = 24 * time.Hour
+
+var (
+	// ErrIdempotencyKeyInFlight is returned while the first request with a key is still running
+	ErrIdempotencyKeyInFlight = errors.New("a request with this idempotency key is still being processed")
+
+	// ErrIdempotencyKeyReused is returned when a key comes back with a different request
+	ErrIdempotencyKeyReused = errors.New("idempotency key was already used for a different request")
+)
+
+// IdempotentResponse is a stored result, replayed to requests that repeat its key
+type IdempotentResponse struct {
+	Fingerprint string `json:"fingerprint"`
+	Completed   bool   `json:"completed"`
+	Status      int    `json:"status,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// DecisionIdempotency remembers the results of approve, reject and revoke requests by their
+// idempotency key, so a double-click or a retry gets the original result back instead of
+// deciding again. Keys are scoped to the user and action. Only successful results are kept:
+// a failed decision changed nothing, so retrying it with the same key runs it again.
+type DecisionIdempotency struct {
+	redisClient *redis.Client
+	ttl         time.Duration
+}
+
+func NewDecisionIdempotency(redisClient *redis.Client) *DecisionIdempotency {
+	return &DecisionIdempotency{
+		redisClient: redisClient,
+		ttl:         DecisionIdempotencyTTL,
+	}
+}
+
+// RequestFingerprint identifies what was asked, so a key reused for another request is caught
+func RequestFingerprint(parts ...string) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (di *DecisionIdempotency) redisKey(scope string, key string) string {
+	return fmt.Sprintf("idempotency:decision:%s:%s", scope, key)
+}
+
+// Begin claims a key for a request. It returns the stored response when the key already
+// completed, ErrIdempotencyKeyInFlight while another request holds it, and nil when the caller
+// now holds the key and must Complete or Release it.
+func (di *DecisionIdempotency) Begin(ctx context.Context, scope string, key string, fingerprint string) (*IdempotentResponse, error) {
+	pending, err := json.Marshal(IdempotentResponse{Fingerprint: fingerprint})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode idempotency record: %w", err)
+	}
+
+	claimed, err := di.redisClient.SetNX(ctx, di.redisKey(scope, key), pending, di.ttl).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+	if claimed {
+		return nil, nil
+	}
+
+	raw, err := di.redisClient.Get(ctx, di.redisKey(scope, key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		// Released or expired between the two calls; the client can simply retry
+		return nil, ErrIdempotencyKeyInFlight
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read idempotency key: %w", err)
+	}
+
+	var stored IdempotentResponse
+	if err := json.Unmarshal(raw, &stored); err != nil {
+		return nil, fmt.Errorf("failed to decode idempotency record: %w", err)
+	}
+	if stored.Fingerprint != fingerprint {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if !stored.Completed {
+		return nil, ErrIdempotencyKeyInFlight
+	}
+	return &stored, nil
+}
+
+// Complete stores the result of the request holding the key, for the rest of the TTL
+func (di *DecisionIdempotency) Complete(ctx context.Context, scope string, key string, fingerprint string, status int, body []byte) error {
+	raw, err := json.Marshal(IdempotentResponse{
+		Fingerprint: fingerprint,
+		Completed:   true,
+		Status:      status,
+		Body:        body,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode idempotency record: %w", err)
+	}
+	if err := di.redisClient.Set(ctx, di.redisKey(scope, key), raw, di.ttl).Err(); err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+	return nil
+}
+
+// Release frees a key whose request failed, so it can be retried
+func (di *DecisionIdempotency) Release(ctx context.Context, scope string, key string) error {
+	if err := di.redisClient.Del(ctx, di.redisKey(scope, key)).Err(); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
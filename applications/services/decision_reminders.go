@@ -0,0 +1,79 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"town-planning-backend/db/models"
+	"town-planning-backend/settings"
+)
+
+// DecisionReminderPolicy says when approvers are reminded of a pending decision and when an
+// ignored decision is escalated to their department head
+type DecisionReminderPolicy struct {
+	RemindAfter    time.Duration
+	RemindInterval time.Duration
+	EscalateAfter  time.Duration
+}
+
+// LoadDecisionReminderPolicy reads the reminder policy from the decision settings:
+//
+//	decisions.sla_days=3                 first reminder once a decision has been pending this long
+//	decisions.reminder_interval_days=2   gap between further reminders
+//	decisions.escalate_after_days=7      escalate to the department head once pending this long
+//
+// Until an administrator changes them they come from DECISION_REMINDER_AFTER_DAYS,
+// DECISION_REMINDER_INTERVAL_DAYS and DECISION_ESCALATE_AFTER_DAYS.
+// Escalation never comes before the first reminder.
+func LoadDecisionReminderPolicy() DecisionReminderPolicy {
+	day := 24 * time.Hour
+	policy := DecisionReminderPolicy{
+		RemindAfter:    time.Duration(settings.Int(settings.DecisionSLADays)) * day,
+		RemindInterval: time.Duration(settings.Int(settings.DecisionReminderIntervalDays)) * day,
+		EscalateAfter:  time.Duration(settings.Int(settings.DecisionEscalateAfterDays)) * day,
+	}
+	if policy.EscalateAfter <= policy.RemindAfter {
+		policy.EscalateAfter = policy.RemindAfter + policy.RemindInterval
+	}
+	return policy
+}
+
+// DueForReminder reports whether a decision pending since pendingSince should be reminded now
+func (p DecisionReminderPolicy) DueForReminder(pendingSince time.Time, lastRemindedAt *time.Time, now time.Time) bool {
+	if now.Sub(pendingSince) < p.RemindAfter {
+		return false
+	}
+	return lastRemindedAt == nil || now.Sub(*lastRemindedAt) >= p.RemindInterval
+}
+
+// DueForEscalation reports whether an approver has ignored their reminders long enough for the
+// decision to be escalated
+func (p DecisionReminderPolicy) DueForEscalation(pendingSince time.Time, reminderCount int, now time.Time) bool {
+	return reminderCount > 0 && now.Sub(pendingSince) >= p.EscalateAfter
+}
+
+// PendingDays is the number of whole days a decision has been pending
+func PendingDays(pendingSince time.Time, now time.Time) int {
+	return int(now.Sub(pendingSince).Hours() / 24)
+}
+
+// DecisionEscalationSummary describes the pending application for the escalation message,
+// e.g. "PLN-2025-0001 for Tendai Moyo, stand 1234 (Residential), submitted 3 March 2025,
+// pending 8 days". The application's Applicant, Stand and Tariff.DevelopmentCategory should
+// be loaded.
+func DecisionEscalationSummary(application *models.Application, pendingSince time.Time, now time.Time) string {
+	var b strings.Builder
+	b.WriteString(application.PlanNumber)
+	if name := strings.TrimSpace(application.Applicant.FullName); name != "" {
+		fmt.Fprintf(&b, " for %s", name)
+	}
+	if application.Stand != nil && application.Stand.StandNumber != "" {
+		fmt.Fprintf(&b, ", stand %s", application.Stand.StandNumber)
+	}
+	if application.Tariff != nil && application.Tariff.DevelopmentCategory.Name != "" {
+		fmt.Fprintf(&b, " (%s)", application.Tariff.DevelopmentCategory.Name)
+	}
+	fmt.Fprintf(&b, ", submitted %s, pending %d days",
+		application.SubmissionDate.Format("2 January 2006"), PendingDays(pendingSince, now))
+	return b.String()
+}
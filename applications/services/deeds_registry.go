@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+
+	"go.uber.org/zap"
+)
+
+const defaultDeedsRegistryTimeoutSeconds = 15
+
+// errTitleNotFound is returned by the registry client when no title is registered for a stand
+var errTitleNotFound = errors.New("no title registered for stand")
+
+// DeedsRegistryConfig points at the national deeds registry. Ownership verification is off
+// unless DEEDS_REGISTRY_API_URL is set.
+type DeedsRegistryConfig struct {
+	BaseURL string
+	APIKey  string
+	Timeout time.Duration
+}
+
+// LoadDeedsRegistryConfig reads the deeds registry integration settings:
+//
+//	DEEDS_REGISTRY_API_URL=https://deeds.example/api   enables the check
+//	DEEDS_REGISTRY_API_KEY=...                         sent as a bearer token
+//	DEEDS_REGISTRY_TIMEOUT_SECONDS=15                  per-request timeout
+func LoadDeedsRegistryConfig() DeedsRegistryConfig {
+	cfg := DeedsRegistryConfig{
+		BaseURL: strings.TrimRight(os.Getenv("DEEDS_REGISTRY_API_URL"), "/"),
+		APIKey:  os.Getenv("DEEDS_REGISTRY_API_KEY"),
+		Timeout: defaultDeedsRegistryTimeoutSeconds * time.Second,
+	}
+
+	if raw := os.Getenv("DEEDS_REGISTRY_TIMEOUT_SECONDS"); raw != "" {
+		seconds, err := strconv.Atoi(raw)
+		if err != nil || seconds <= 0 {
+			config.Logger.Warn("Invalid DEEDS_REGISTRY_TIMEOUT_SECONDS, using default",
+				zap.String("value", raw),
+				zap.Int("default", defaultDeedsRegistryTimeoutSeconds))
+		} else {
+			cfg.Timeout = time.Duration(seconds) * time.Second
+		}
+	}
+
+	return cfg
+}
+
+// DeedsRegistryService asks the deeds registry who a stand is registered to
+type DeedsRegistryService struct {
+	config     DeedsRegistryConfig
+	httpClient *http.Client
+}
+
+func NewDeedsRegistryService(cfg DeedsRegistryConfig) *DeedsRegistryService {
+	return &DeedsRegistryService{
+		config:     cfg,
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// Enabled reports whether applications should be checked against the deeds registry
+func (s *DeedsRegistryService) Enabled() bool {
+	return s != nil && s.config.BaseURL != ""
+}
+
+// registeredOwner is one holder of a title. People are identified by their national ID number,
+// companies and trusts by their registration or tax number.
+type registeredOwner struct {
+	Name               string `json:"name"`
+	IDNumber           string `json:"id_number"`
+	RegistrationNumber string `json:"registration_number"`
+}
+
+// titleResponse is the registry's title deed payload for a stand
+type titleResponse struct {
+	CertificateNumber string            `json:"certificate_number"`
+	StandNumber       string            `json:"stand_number"`
+	Owners            []registeredOwner `json:"registered_owners"`
+}
+
+// VerifyOwnership checks that the applicant is a registered owner of the stand. It always
+// returns a result: when the registry cannot answer, the status is UNAVAILABLE and Message says
+// why. The record is not saved and has no application or creator set.
+func (s *DeedsRegistryService) VerifyOwnership(ctx context.Context, stand *models.Stand, applicant *models.Applicant) *models.DeedsVerification {
+	verification := &models.DeedsVerification{
+		StandID:     stand.ID,
+		ApplicantID: applicant.ID,
+		StandNumber: stand.StandNumber,
+		CheckedAt:   time.Now(),
+	}
+
+	withStatus := func(status models.DeedsVerificationStatus, message string) *models.DeedsVerification {
+		verification.Status = status
+		verification.Message = &message
+		return verification
+	}
+
+	title, err := s.fetchTitle(ctx, stand.StandNumber)
+	if errors.Is(err, errTitleNotFound) {
+		return withStatus(models.DeedsNotFound, fmt.Sprintf("deeds registry has no title for stand %s", stand.StandNumber))
+	}
+	if err != nil {
+		config.Logger.Warn("Deeds registry check failed",
+			zap.String("standID", stand.ID.String()),
+			zap.String("standNumber", stand.StandNumber),
+			zap.Error(err))
+		return withStatus(models.DeedsUnavailable, err.Error())
+	}
+
+	if title.CertificateNumber != "" {
+		verification.CertificateNumber = &title.CertificateNumber
+	}
+	names := make([]string, 0, len(title.Owners))
+	for _, owner := range title.Owners {
+		names = append(names, owner.Name)
+	}
+	owners := strings.Join(names, ", ")
+	verification.RegisteredOwners = &owners
+
+	for _, owner := range title.Owners {
+		if ownerMatches(owner, applicant) {
+			verification.Status = models.DeedsVerified
+			return verification
+		}
+	}
+	return withStatus(models.DeedsMismatch, fmt.Sprintf("stand %s is registered to %s", stand.StandNumber, owners))
+}
+
+// ownerMatches compares identity numbers where both sides have one, and falls back to the
+// organisation's name for companies registered without a number on file
+func ownerMatches(owner registeredOwner, applicant *models.Applicant) bool {
+	if applicant.ApplicantType == models.OrganisationApplicant {
+		if applicant.TaxIdentificationNumber != nil && owner.RegistrationNumber != "" {
+			return normalizeIdentifier(*applicant.TaxIdentificationNumber) == normalizeIdentifier(owner.RegistrationNumber)
+		}
+		if applicant.OrganisationName != nil {
+			return strings.EqualFold(strings.Join(strings.Fields(*applicant.OrganisationName), " "),
+				strings.Join(strings.Fields(owner.Name), " "))
+		}
+		return false
+	}
+
+	if applicant.IdNumber == nil || owner.IDNumber == "" {
+		return false
+	}
+	return normalizeIdentifier(*applicant.IdNumber) == normalizeIdentifier(owner.IDNumber)
+}
+
+// normalizeIdentifier drops the spaces, dashes and case differences ID numbers are written with,
+// e.g. "63-123456 F 42" and "63123456f42"
+func normalizeIdentifier(value string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(value) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func (s *DeedsRegistryService) fetchTitle(ctx context.Context, standNumber string) (*titleResponse, error) {
+	endpoint := fmt.Sprintf("%s/titles/%s", s.config.BaseURL, url.PathEscape(standNumber))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build deeds registry request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if s.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.config.APIKey)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("deeds registry unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errTitleNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("deeds registry returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var title titleResponse
+	if err := json.NewDecoder(resp.Body).Decode(&title); err != nil {
+		return nil, fmt.Errorf("invalid deeds registry response: %w", err)
+	}
+	return &title, nil
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"town-planning-backend/db/models"
+)
+
+// DocumentChecklistItem is one document an application is expected to carry. Code is the
+// document category the applicant uploads it under.
+type DocumentChecklistItem struct {
+	Code      string `json:"code"`
+	Label     string `json:"label"`
+	Mandatory bool   `json:"mandatory"` // Counted towards AllDocumentsProvided
+	Provided  bool   `json:"provided"`
+}
+
+// DocumentChecklist lists the documents an application is expected to carry, in the order
+// staff review them, with whether each has been provided
+func DocumentChecklist(application *models.Application) []DocumentChecklistItem {
+	return []DocumentChecklistItem{
+		{Code: "PROCESSED_RECEIPT", Label: "Processed receipt", Mandatory: true, Provided: application.ProcessedReceiptProvided},
+		{Code: "INITIAL_PLAN", Label: "Initial building plan", Mandatory: true, Provided: application.InitialPlanProvided},
+		{Code: "TPD1_FORM", Label: "TPD-1 form", Mandatory: true, Provided: application.ProcessedTPD1FormProvided},
+		{Code: "PROCESSED_QUOTATION", Label: "Processed quotation", Mandatory: true, Provided: application.ProcessedQuotationProvided},
+		{Code: "ENGINEERING_CERTIFICATE", Label: "Structural engineering certificate", Provided: application.StructuralEngineeringCertificateProvided},
+		{Code: "RING_BEAM_CERTIFICATE", Label: "Ring beam certificate", Provided: application.RingBeamCertificateProvided},
+	}
+}
+
+// OutstandingDocuments lists the mandatory checklist documents the application is still missing
+func OutstandingDocuments(application *models.Application) []DocumentChecklistItem {
+	outstanding := []DocumentChecklistItem{}
+	for _, item := range DocumentChecklist(application) {
+		if item.Mandatory && !item.Provided {
+			outstanding = append(outstanding, item)
+		}
+	}
+	return outstanding
+}
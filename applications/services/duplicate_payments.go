@@ -0,0 +1,12 @@
+package services
+
+import "time"
+
+const defaultDuplicatePaymentWindowMinutes = 10
+
+// DuplicatePaymentWindow is how recently the same amount must have been captured for the same
+// applicant for a new capture to be held as a duplicate. Set with
+// PAYMENT_DUPLICATE_WINDOW_MINUTES, 10 by default.
+func DuplicatePaymentWindow() time.Duration {
+	return time.Duration(positiveEnvInt("PAYMENT_DUPLICATE_WINDOW_MINUTES", defaultDuplicatePaymentWindowMinutes)) * time.Minute
+}
@@ -0,0 +1,370 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/net/html"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultLinkPreviewTimeoutSeconds = 5
+	defaultLinkPreviewCacheHours     = 24
+
+	// MaxLinkPreviewsPerMessage caps how many links in one message get a card
+	MaxLinkPreviewsPerMessage = 3
+
+	maxLinkPreviewBodyBytes = 512 << 10 // Open Graph tags live in the head
+	maxLinkPreviewRedirects = 5
+	linkPreviewUserAgent    = "TownPlanningLinkPreview/1.0"
+)
+
+// errBlockedAddress stops previews from reaching the council's own network
+var errBlockedAddress = errors.New("link resolves to a non-public address")
+
+// linkPattern finds http and https URLs in message text
+var linkPattern = regexp.MustCompile(`https?://[^\s<>"'` + "`" + `]+`)
+
+// LinkPreviewConfig controls fetching previews of links posted in chat
+type LinkPreviewConfig struct {
+	Enabled  bool
+	Timeout  time.Duration
+	CacheTTL time.Duration // How long a fetched URL is reused before it is fetched again
+}
+
+// LoadLinkPreviewConfig reads the link preview settings:
+//
+//	LINK_PREVIEWS_ENABLED=true            set to false to stop fetching previews
+//	LINK_PREVIEW_TIMEOUT_SECONDS=5        per-link fetch timeout
+//	LINK_PREVIEW_CACHE_HOURS=24           reuse a URL's metadata for this long
+func LoadLinkPreviewConfig() LinkPreviewConfig {
+	cfg := LinkPreviewConfig{
+		Enabled:  os.Getenv("LINK_PREVIEWS_ENABLED") != "false",
+		Timeout:  defaultLinkPreviewTimeoutSeconds * time.Second,
+		CacheTTL: defaultLinkPreviewCacheHours * time.Hour,
+	}
+
+	if raw := os.Getenv("LINK_PREVIEW_TIMEOUT_SECONDS"); raw != "" {
+		seconds, err := strconv.Atoi(raw)
+		if err != nil || seconds <= 0 {
+			config.Logger.Warn("Invalid LINK_PREVIEW_TIMEOUT_SECONDS, using default",
+				zap.String("value", raw),
+				zap.Int("default", defaultLinkPreviewTimeoutSeconds))
+		} else {
+			cfg.Timeout = time.Duration(seconds) * time.Second
+		}
+	}
+
+	if raw := os.Getenv("LINK_PREVIEW_CACHE_HOURS"); raw != "" {
+		hours, err := strconv.Atoi(raw)
+		if err != nil || hours < 0 {
+			config.Logger.Warn("Invalid LINK_PREVIEW_CACHE_HOURS, using default",
+				zap.String("value", raw),
+				zap.Int("default", defaultLinkPreviewCacheHours))
+		} else {
+			cfg.CacheTTL = time.Duration(hours) * time.Hour
+		}
+	}
+
+	return cfg
+}
+
+// ExtractLinks returns the distinct http and https URLs in a message, in the order they appear,
+// without trailing punctuation, up to MaxLinkPreviewsPerMessage
+func ExtractLinks(content string) []string {
+	var links []string
+	seen := map[string]bool{}
+	for _, match := range linkPattern.FindAllString(content, -1) {
+		link := strings.TrimRight(match, ".,;:!?)]}")
+		parsed, err := url.Parse(link)
+		if err != nil || parsed.Hostname() == "" || seen[link] {
+			continue
+		}
+		seen[link] = true
+		links = append(links, link)
+		if len(links) == MaxLinkPreviewsPerMessage {
+			break
+		}
+	}
+	return links
+}
+
+// LinkPreviewService fetches Open Graph metadata for links posted in chat and stores it against
+// the message. Only public addresses are fetched.
+type LinkPreviewService struct {
+	db         *gorm.DB
+	config     LinkPreviewConfig
+	httpClient *http.Client
+}
+
+func NewLinkPreviewService(db *gorm.DB, cfg LinkPreviewConfig) *LinkPreviewService {
+	dialer := &net.Dialer{
+		Timeout: cfg.Timeout,
+		// Checked on the resolved address so DNS cannot point a public name at an internal host
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return errBlockedAddress
+			}
+			return nil
+		},
+	}
+
+	return &LinkPreviewService{
+		db:     db,
+		config: cfg,
+		httpClient: &http.Client{
+			Timeout: cfg.Timeout,
+			Transport: &http.Transport{
+				Proxy:                 nil, // A proxy would be dialled instead of the link's host
+				DialContext:           dialer.DialContext,
+				TLSHandshakeTimeout:   cfg.Timeout,
+				ResponseHeaderTimeout: cfg.Timeout,
+				MaxIdleConns:          10,
+				IdleConnTimeout:       30 * time.Second,
+			},
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= maxLinkPreviewRedirects {
+					return errors.New("too many redirects")
+				}
+				if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+					return fmt.Errorf("redirect to unsupported scheme %q", req.URL.Scheme)
+				}
+				return nil
+			},
+		},
+	}
+}
+
+// Enabled reports whether links in chat messages should be previewed
+func (s *LinkPreviewService) Enabled() bool {
+	return s != nil && s.config.Enabled
+}
+
+// PreviewMessage stores a preview for each link in a message's content, reusing metadata fetched
+// for the same URL within the cache period. The previews that have a card to show are returned.
+func (s *LinkPreviewService) PreviewMessage(ctx context.Context, messageID uuid.UUID, content string) ([]models.LinkPreview, error) {
+	if !s.Enabled() {
+		return nil, nil
+	}
+	links := ExtractLinks(content)
+	if len(links) == 0 {
+		return nil, nil
+	}
+
+	previews := make([]models.LinkPreview, 0, len(links))
+	for i, link := range links {
+		preview, err := s.cachedPreview(ctx, link)
+		if err != nil {
+			return nil, err
+		}
+		if preview == nil {
+			preview = s.fetchPreview(ctx, link)
+		}
+		preview.ID = uuid.Nil
+		preview.MessageID = messageID
+		preview.URL = link
+		preview.Position = i
+		previews = append(previews, *preview)
+	}
+
+	if err := s.db.WithContext(ctx).Create(&previews).Error; err != nil {
+		return nil, fmt.Errorf("failed to save link previews: %w", err)
+	}
+
+	ready := make([]models.LinkPreview, 0, len(previews))
+	for _, preview := range previews {
+		if preview.Status == models.LinkPreviewReady {
+			ready = append(ready, preview)
+		}
+	}
+	return ready, nil
+}
+
+// cachedPreview copies the newest preview of the URL fetched within the cache period
+func (s *LinkPreviewService) cachedPreview(ctx context.Context, link string) (*models.LinkPreview, error) {
+	if s.config.CacheTTL <= 0 {
+		return nil, nil
+	}
+
+	var cached models.LinkPreview
+	err := s.db.WithContext(ctx).
+		Where("url = ? AND fetched_at > ?", link, time.Now().Add(-s.config.CacheTTL)).
+		Order("fetched_at DESC").
+		First(&cached).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up cached link preview: %w", err)
+	}
+	return &cached, nil
+}
+
+// fetchPreview downloads the page and reads its metadata. Failures are recorded as a FAILED
+// preview so the URL is not fetched again until the cache period ends.
+func (s *LinkPreviewService) fetchPreview(ctx context.Context, link string) *models.LinkPreview {
+	preview := &models.LinkPreview{
+		Status:    models.LinkPreviewFailed,
+		FetchedAt: time.Now(),
+	}
+
+	metadata, finalURL, err := s.fetchMetadata(ctx, link)
+	if err != nil {
+		config.Logger.Info("Link preview not available",
+			zap.String("url", link),
+			zap.Error(err))
+		return preview
+	}
+
+	preview.FinalURL = &finalURL
+	preview.Title = truncatedPtr(firstNonEmpty(metadata["og:title"], metadata["twitter:title"], metadata["title"]), 300)
+	preview.Description = truncatedPtr(firstNonEmpty(metadata["og:description"], metadata["twitter:description"], metadata["description"]), 1000)
+	preview.SiteName = truncatedPtr(metadata["og:site_name"], 200)
+	if image := resolveLink(finalURL, firstNonEmpty(metadata["og:image"], metadata["twitter:image"])); image != "" {
+		preview.ImageURL = &image
+	}
+	if preview.Title != nil || preview.Description != nil {
+		preview.Status = models.LinkPreviewReady
+	}
+	return preview
+}
+
+func (s *LinkPreviewService) fetchMetadata(ctx context.Context, link string) (map[string]string, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("User-Agent", linkPreviewUserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, "", fmt.Errorf("page returned status %d", resp.StatusCode)
+	}
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || (mediaType != "text/html" && mediaType != "application/xhtml+xml") {
+		return nil, "", fmt.Errorf("page is not HTML (%s)", resp.Header.Get("Content-Type"))
+	}
+
+	return parsePageMetadata(io.LimitReader(resp.Body, maxLinkPreviewBodyBytes)), resp.Request.URL.String(), nil
+}
+
+// parsePageMetadata reads the Open Graph, Twitter card and description meta tags and the title
+// from a page's head
+func parsePageMetadata(body io.Reader) map[string]string {
+	metadata := map[string]string{}
+	tokenizer := html.NewTokenizer(body)
+	inTitle := false
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return metadata
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			switch token.Data {
+			case "title":
+				inTitle = true
+			case "meta":
+				var name, value string
+				for _, attr := range token.Attr {
+					switch strings.ToLower(attr.Key) {
+					case "property", "name":
+						name = strings.ToLower(strings.TrimSpace(attr.Val))
+					case "content":
+						value = strings.TrimSpace(attr.Val)
+					}
+				}
+				if name != "" && value != "" && metadata[name] == "" {
+					metadata[name] = value
+				}
+			case "body":
+				return metadata
+			}
+		case html.TextToken:
+			if inTitle && metadata["title"] == "" {
+				metadata["title"] = strings.TrimSpace(string(tokenizer.Text()))
+			}
+		case html.EndTagToken:
+			switch tokenizer.Token().Data {
+			case "title":
+				inTitle = false
+			case "head":
+				return metadata
+			}
+		}
+	}
+}
+
+// isPublicIP rejects loopback, private, link-local and carrier-grade NAT addresses
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	_, sharedAddressSpace, _ := net.ParseCIDR("100.64.0.0/10")
+	return !sharedAddressSpace.Contains(ip)
+}
+
+// resolveLink makes an image reference absolute against the page URL; only http and https
+// images are kept
+func resolveLink(pageURL, ref string) string {
+	if ref == "" {
+		return ""
+	}
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+	resolved, err := base.Parse(ref)
+	if err != nil || (resolved.Scheme != "http" && resolved.Scheme != "https") {
+		return ""
+	}
+	return resolved.String()
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func truncatedPtr(value string, max int) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	if runes := []rune(value); len(runes) > max {
+		value = string(runes[:max])
+	}
+	return &value
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"strings"
+	"town-planning-backend/db/models"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// FindMentionedUsers returns the candidates named in the message with @, either by email
+// ("@t.moyo@council.gov.zw") or by full name ("@Tendai Moyo"). Matching ignores case and a
+// name must not run on into another word, so "@Tendai Moyo" does not match "@Tendai Moyondo".
+func FindMentionedUsers(content string, candidates []models.User) []uuid.UUID {
+	if !strings.Contains(content, "@") {
+		return nil
+	}
+	lowered := strings.ToLower(content)
+
+	var mentioned []uuid.UUID
+	for _, user := range candidates {
+		handles := []string{strings.ToLower(strings.TrimSpace(user.Email))}
+		if fullName := strings.TrimSpace(user.FirstName + " " + user.LastName); fullName != "" {
+			handles = append(handles, strings.ToLower(fullName))
+		}
+		for _, handle := range handles {
+			if handle != "" && containsMention(lowered, "@"+handle) {
+				mentioned = append(mentioned, user.ID)
+				break
+			}
+		}
+	}
+	return mentioned
+}
+
+// containsMention reports whether mention appears in content followed by the end of the text
+// or a character that cannot continue a name
+func containsMention(content string, mention string) bool {
+	for offset := 0; ; {
+		index := strings.Index(content[offset:], mention)
+		if index < 0 {
+			return false
+		}
+		end := offset + index + len(mention)
+		next, _ := utf8.DecodeRuneInString(content[end:])
+		if end == len(content) || !(unicode.IsLetter(next) || unicode.IsDigit(next)) {
+			return true
+		}
+		offset += index + 1
+	}
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"os"
+	"strconv"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+
+	"go.uber.org/zap"
+)
+
+const defaultChatInvitationExpiryDays = 7
+
+// ParticipantInvitationPolicy says whether people added to a thread from another department
+// have to accept before they can read it, and how long the invitation stays open
+type ParticipantInvitationPolicy struct {
+	Required     bool
+	ExpiresAfter time.Duration
+}
+
+// LoadParticipantInvitationPolicy reads the invitation policy. Both variables are optional:
+//
+//	CHAT_INVITATIONS_REQUIRED=true   invite people from other departments instead of adding them
+//	CHAT_INVITATION_EXPIRY_DAYS=7    expire invitations left unanswered this long
+func LoadParticipantInvitationPolicy() ParticipantInvitationPolicy {
+	policy := ParticipantInvitationPolicy{
+		Required:     true,
+		ExpiresAfter: time.Duration(positiveEnvInt("CHAT_INVITATION_EXPIRY_DAYS", defaultChatInvitationExpiryDays)) * 24 * time.Hour,
+	}
+	if raw := os.Getenv("CHAT_INVITATIONS_REQUIRED"); raw != "" {
+		required, err := strconv.ParseBool(raw)
+		if err != nil {
+			config.Logger.Warn("Invalid setting, using default",
+				zap.String("variable", "CHAT_INVITATIONS_REQUIRED"),
+				zap.String("value", raw),
+				zap.Bool("default", policy.Required))
+		} else {
+			policy.Required = required
+		}
+	}
+	return policy
+}
+
+// RequiresInvitation reports whether the invitee is from a department other than the inviter's.
+// Users without a department are treated as belonging to the inviter's.
+func (p ParticipantInvitationPolicy) RequiresInvitation(inviter, invitee *models.User) bool {
+	if !p.Required || inviter == nil || invitee == nil || invitee.DepartmentID == nil || inviter.DepartmentID == nil {
+		return false
+	}
+	return *invitee.DepartmentID != *inviter.DepartmentID
+}
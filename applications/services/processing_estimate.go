@@ -0,0 +1,310 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultEstimateHistoryDays    = 365
+	defaultEstimateFallbackDays   = 30
+	estimateThroughputWindowDays  = 90
+	estimateMinimumHistorySamples = 5
+)
+
+// Where an estimate's processing time came from
+const (
+	EstimateBasisHistory = "HISTORY" // Decisions on the category over the history window
+	EstimateBasisDefault = "DEFAULT" // Too few past decisions, the council's default was used
+)
+
+// openApplicationStatuses are the statuses of applications still waiting for a decision
+var openApplicationStatuses = []models.ApplicationStatus{
+	models.SubmittedApplication,
+	models.UnderReviewApplication,
+	models.PendingApprovalApplication,
+	models.DepartmentReviewApplication,
+	models.FinalReviewApplication,
+}
+
+// ProcessingEstimatePolicy says how much history processing estimates are drawn from
+type ProcessingEstimatePolicy struct {
+	HistoryWindow time.Duration // How far back decided applications are used
+	FallbackDays  int           // Estimate used while a category has too few decisions
+}
+
+// LoadProcessingEstimatePolicy reads the estimate policy. Both variables are optional:
+//
+//	PROCESSING_ESTIMATE_HISTORY_DAYS=365   decisions made this recently feed the estimate
+//	PROCESSING_ESTIMATE_FALLBACK_DAYS=30   days quoted for categories with little history
+func LoadProcessingEstimatePolicy() ProcessingEstimatePolicy {
+	return ProcessingEstimatePolicy{
+		HistoryWindow: time.Duration(positiveEnvInt("PROCESSING_ESTIMATE_HISTORY_DAYS", defaultEstimateHistoryDays)) * 24 * time.Hour,
+		FallbackDays:  positiveEnvInt("PROCESSING_ESTIMATE_FALLBACK_DAYS", defaultEstimateFallbackDays),
+	}
+}
+
+// CostBreakdown is what an application on a tariff costs
+type CostBreakdown struct {
+	AreaCost        decimal.Decimal
+	PermitFee       decimal.Decimal
+	InspectionFee   decimal.Decimal
+	DevelopmentLevy decimal.Decimal
+	VATAmount       decimal.Decimal
+	TotalCost       decimal.Decimal
+}
+
+// CalculateApplicationCosts prices a plan of the given area on a tariff: the area cost and fixed
+// fees, the development levy on their sum, then VAT on the whole
+func CalculateApplicationCosts(tariff models.Tariff, vatRate decimal.Decimal, planArea decimal.Decimal) CostBreakdown {
+	areaCost := planArea.Mul(tariff.PricePerSquareMeter)
+	subtotal := areaCost.Add(tariff.PermitFee).Add(tariff.InspectionFee)
+	developmentLevy := subtotal.Mul(tariff.DevelopmentLevyPercent).Div(decimal.NewFromInt(100))
+	totalBeforeVAT := subtotal.Add(developmentLevy)
+	vatAmount := totalBeforeVAT.Mul(vatRate)
+
+	return CostBreakdown{
+		AreaCost:        areaCost,
+		PermitFee:       tariff.PermitFee,
+		InspectionFee:   tariff.InspectionFee,
+		DevelopmentLevy: developmentLevy,
+		VATAmount:       vatAmount,
+		TotalCost:       totalBeforeVAT.Add(vatAmount),
+	}
+}
+
+// FeeEstimate is the expected cost of an application under the category's current tariff.
+// Without a plan area only the fixed fees are priced.
+type FeeEstimate struct {
+	Currency         string          `json:"currency"`
+	PlanArea         *string         `json:"plan_area,omitempty"`
+	AreaCost         decimal.Decimal `json:"area_cost"`
+	PermitFee        decimal.Decimal `json:"permit_fee"`
+	InspectionFee    decimal.Decimal `json:"inspection_fee"`
+	DevelopmentLevy  decimal.Decimal `json:"development_levy"`
+	VATAmount        decimal.Decimal `json:"vat_amount"`
+	TotalCost        decimal.Decimal `json:"total_cost"`
+	IncludesAreaCost bool            `json:"includes_area_cost"`
+}
+
+// ProcessingEstimate is how long a decision on an application in a development category is
+// expected to take, and what it costs
+type ProcessingEstimate struct {
+	DevelopmentCategoryID uuid.UUID    `json:"development_category_id"`
+	DevelopmentCategory   string       `json:"development_category"`
+	ExpectedDays          int          `json:"expected_days"`
+	LowDays               int          `json:"low_days"`
+	HighDays              int          `json:"high_days"`
+	Basis                 string       `json:"basis"`
+	HistoricalSamples     int          `json:"historical_samples"`
+	HistoricalMedianDays  *float64     `json:"historical_median_days,omitempty"`
+	QueueDepth            int64        `json:"queue_depth"`        // Applications in the category awaiting a decision
+	DecisionsPerWeek      float64      `json:"decisions_per_week"` // Recent decision rate in the category
+	Fees                  *FeeEstimate `json:"fees,omitempty"`
+
+	// Set when the estimate is for a submitted application
+	ExpectedDecisionBy *time.Time `json:"expected_decision_by,omitempty"`
+	RemainingDays      *int       `json:"remaining_days,omitempty"`
+}
+
+// ProcessingEstimator estimates decision times from past decisions and the current queue
+type ProcessingEstimator struct {
+	db     *gorm.DB
+	policy ProcessingEstimatePolicy
+}
+
+func NewProcessingEstimator(db *gorm.DB) *ProcessingEstimator {
+	return &ProcessingEstimator{db: db, policy: LoadProcessingEstimatePolicy()}
+}
+
+// Estimate works out the processing time for a development category and, from its active
+// tariff, the fees for a plan of the given area.
+//
+// The expected time is the median time to decision over the history window. When the queue has
+// grown beyond what the category's recent decision rate clears in that time, the time to clear
+// the queue is quoted instead. The range runs from the 25th to the 75th percentile.
+func (e *ProcessingEstimator) Estimate(developmentCategoryID uuid.UUID, planArea *decimal.Decimal) (*ProcessingEstimate, error) {
+	var category models.DevelopmentCategory
+	if err := e.db.Where("id = ? AND is_active = ?", developmentCategoryID, true).First(&category).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("development category not found")
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	estimate := &ProcessingEstimate{
+		DevelopmentCategoryID: category.ID,
+		DevelopmentCategory:   category.Name,
+	}
+
+	days, err := e.decisionDays(category.ID, now.Add(-e.policy.HistoryWindow))
+	if err != nil {
+		return nil, err
+	}
+	estimate.HistoricalSamples = len(days)
+
+	if err := e.categoryApplications(category.ID).
+		Where("applications.status IN ?", openApplicationStatuses).
+		Count(&estimate.QueueDepth).Error; err != nil {
+		return nil, err
+	}
+
+	var recentDecisions int64
+	if err := e.categoryApplications(category.ID).
+		Where("COALESCE(applications.final_approval_date, applications.rejection_date) >= ?",
+			now.AddDate(0, 0, -estimateThroughputWindowDays)).
+		Count(&recentDecisions).Error; err != nil {
+		return nil, err
+	}
+	estimate.DecisionsPerWeek = math.Round(float64(recentDecisions)/(estimateThroughputWindowDays/7.0)*10) / 10
+
+	if len(days) < estimateMinimumHistorySamples {
+		estimate.Basis = EstimateBasisDefault
+		estimate.ExpectedDays = e.policy.FallbackDays
+		estimate.LowDays = e.policy.FallbackDays / 2
+		estimate.HighDays = e.policy.FallbackDays * 3 / 2
+	} else {
+		sort.Float64s(days)
+		median := math.Round(nearestRank(days, 50)*10) / 10
+		estimate.Basis = EstimateBasisHistory
+		estimate.HistoricalMedianDays = &median
+		estimate.ExpectedDays = int(math.Ceil(median))
+		estimate.LowDays = int(math.Floor(nearestRank(days, 25)))
+		estimate.HighDays = int(math.Ceil(nearestRank(days, 75)))
+	}
+
+	if recentDecisions > 0 {
+		clearDays := int(math.Ceil(float64(estimate.QueueDepth) / (float64(recentDecisions) / estimateThroughputWindowDays)))
+		if clearDays > estimate.ExpectedDays {
+			estimate.ExpectedDays = clearDays
+		}
+	}
+	if estimate.HighDays < estimate.ExpectedDays {
+		estimate.HighDays = estimate.ExpectedDays
+	}
+	if estimate.LowDays > estimate.ExpectedDays {
+		estimate.LowDays = estimate.ExpectedDays
+	}
+
+	fees, err := e.estimateFees(category.ID, planArea, now)
+	if err != nil {
+		return nil, err
+	}
+	estimate.Fees = fees
+
+	return estimate, nil
+}
+
+// EstimateForApplication estimates when a submitted application will be decided. It returns nil
+// for applications already decided or without a tariff.
+func (e *ProcessingEstimator) EstimateForApplication(application *models.Application) (*ProcessingEstimate, error) {
+	open := false
+	for _, status := range openApplicationStatuses {
+		if application.Status == status {
+			open = true
+			break
+		}
+	}
+	if !open || application.TariffID == nil {
+		return nil, nil
+	}
+
+	var tariff models.Tariff
+	if application.Tariff != nil {
+		tariff = *application.Tariff
+	} else if err := e.db.Where("id = ?", *application.TariffID).First(&tariff).Error; err != nil {
+		return nil, err
+	}
+
+	estimate, err := e.Estimate(tariff.DevelopmentCategoryID, application.PlanArea)
+	if err != nil {
+		return nil, err
+	}
+
+	expectedBy := application.SubmissionDate.AddDate(0, 0, estimate.ExpectedDays)
+	remaining := int(math.Ceil(time.Until(expectedBy).Hours() / 24))
+	if remaining < 0 {
+		remaining = 0
+	}
+	estimate.ExpectedDecisionBy = &expectedBy
+	estimate.RemainingDays = &remaining
+	return estimate, nil
+}
+
+func (e *ProcessingEstimator) categoryApplications(developmentCategoryID uuid.UUID) *gorm.DB {
+	return e.db.Model(&models.Application{}).
+		Joins("JOIN tariffs ON tariffs.id = applications.tariff_id").
+		Where("tariffs.development_category_id = ?", developmentCategoryID)
+}
+
+// decisionDays returns the days from submission to decision of applications in the category
+// decided since the given time
+func (e *ProcessingEstimator) decisionDays(developmentCategoryID uuid.UUID, since time.Time) ([]float64, error) {
+	var days []float64
+	err := e.categoryApplications(developmentCategoryID).
+		Where("COALESCE(applications.final_approval_date, applications.rejection_date) >= ?", since).
+		Pluck("EXTRACT(EPOCH FROM (COALESCE(applications.final_approval_date, applications.rejection_date) - applications.submission_date)) / 86400", &days).Error
+	return days, err
+}
+
+func (e *ProcessingEstimator) estimateFees(developmentCategoryID uuid.UUID, planArea *decimal.Decimal, now time.Time) (*FeeEstimate, error) {
+	var tariff models.Tariff
+	err := e.db.Where("development_category_id = ? AND is_active = ? AND valid_from <= ? AND (valid_to IS NULL OR valid_to >= ?)",
+		developmentCategoryID, true, now, now).
+		Order("valid_from DESC").
+		First(&tariff).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil // No tariff yet, the time estimate still stands
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	vatRate := decimal.Zero
+	var vat models.VATRate
+	err = e.db.Where("is_active = ? AND (valid_to IS NULL OR valid_to > ?)", true, now).First(&vat).Error
+	if err == nil {
+		vatRate = vat.Rate
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	area := decimal.Zero
+	if planArea != nil {
+		area = *planArea
+	}
+	costs := CalculateApplicationCosts(tariff, vatRate, area)
+
+	fees := &FeeEstimate{
+		Currency:         tariff.Currency,
+		AreaCost:         costs.AreaCost.Round(2),
+		PermitFee:        costs.PermitFee.Round(2),
+		InspectionFee:    costs.InspectionFee.Round(2),
+		DevelopmentLevy:  costs.DevelopmentLevy.Round(2),
+		VATAmount:        costs.VATAmount.Round(2),
+		TotalCost:        costs.TotalCost.Round(2),
+		IncludesAreaCost: planArea != nil,
+	}
+	if planArea != nil {
+		value := planArea.String()
+		fees.PlanArea = &value
+	}
+	return fees, nil
+}
+
+// nearestRank returns the p-th percentile of sorted values by the nearest-rank method
+func nearestRank(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+const defaultRatesBillingTimeoutSeconds = 10
+
+// RatesBillingConfig points at the council billing system. The clearance check is off unless
+// RATES_BILLING_API_URL is set.
+type RatesBillingConfig struct {
+	BaseURL string
+	APIKey  string
+	Timeout time.Duration
+}
+
+// LoadRatesBillingConfig reads the billing integration settings:
+//
+//	RATES_BILLING_API_URL=https://billing.example/api   enables the check
+//	RATES_BILLING_API_KEY=...                           sent as a bearer token
+//	RATES_BILLING_TIMEOUT_SECONDS=10                    per-request timeout
+func LoadRatesBillingConfig() RatesBillingConfig {
+	cfg := RatesBillingConfig{
+		BaseURL: strings.TrimRight(os.Getenv("RATES_BILLING_API_URL"), "/"),
+		APIKey:  os.Getenv("RATES_BILLING_API_KEY"),
+		Timeout: defaultRatesBillingTimeoutSeconds * time.Second,
+	}
+
+	if raw := os.Getenv("RATES_BILLING_TIMEOUT_SECONDS"); raw != "" {
+		seconds, err := strconv.Atoi(raw)
+		if err != nil || seconds <= 0 {
+			config.Logger.Warn("Invalid RATES_BILLING_TIMEOUT_SECONDS, using default",
+				zap.String("value", raw),
+				zap.Int("default", defaultRatesBillingTimeoutSeconds))
+		} else {
+			cfg.Timeout = time.Duration(seconds) * time.Second
+		}
+	}
+
+	return cfg
+}
+
+// RatesClearanceService asks the council billing system whether a stand's rates account is clear
+type RatesClearanceService struct {
+	config     RatesBillingConfig
+	httpClient *http.Client
+}
+
+func NewRatesClearanceService(cfg RatesBillingConfig) *RatesClearanceService {
+	return &RatesClearanceService{
+		config:     cfg,
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// Enabled reports whether applications should be checked for rates clearance
+func (s *RatesClearanceService) Enabled() bool {
+	return s != nil && s.config.BaseURL != ""
+}
+
+// ratesAccountResponse is the billing system's account balance payload
+type ratesAccountResponse struct {
+	AccountNumber string          `json:"account_number"`
+	Balance       decimal.Decimal `json:"balance"`
+	Currency      string          `json:"currency"`
+	Reference     string          `json:"reference"`
+}
+
+// CheckStand queries billing for the stand's rates account. It always returns a result: when
+// billing cannot answer, the status is UNAVAILABLE and Message says why. The record is not
+// saved and has no application or creator set.
+func (s *RatesClearanceService) CheckStand(ctx context.Context, stand *models.Stand) *models.RatesClearance {
+	clearance := &models.RatesClearance{
+		StandID:       stand.ID,
+		AccountNumber: stand.AccountNumber,
+		CheckedAt:     time.Now(),
+	}
+
+	unavailable := func(message string) *models.RatesClearance {
+		clearance.Status = models.RatesClearanceUnavailable
+		clearance.Message = &message
+		return clearance
+	}
+
+	if stand.AccountNumber == nil || strings.TrimSpace(*stand.AccountNumber) == "" {
+		return unavailable("stand has no rates account number")
+	}
+
+	account, err := s.fetchAccount(ctx, strings.TrimSpace(*stand.AccountNumber))
+	if err != nil {
+		config.Logger.Warn("Rates clearance check failed",
+			zap.String("standID", stand.ID.String()),
+			zap.String("accountNumber", *stand.AccountNumber),
+			zap.Error(err))
+		return unavailable(err.Error())
+	}
+
+	clearance.OutstandingBalance = &account.Balance
+	if account.Currency != "" {
+		clearance.Currency = &account.Currency
+	}
+	if account.Reference != "" {
+		clearance.BillingReference = &account.Reference
+	}
+
+	if account.Balance.GreaterThan(decimal.Zero) {
+		clearance.Status = models.RatesClearanceArrears
+		message := fmt.Sprintf("rates account is in arrears by %s %s", account.Balance.StringFixed(2), account.Currency)
+		clearance.Message = &message
+	} else {
+		clearance.Status = models.RatesClearanceCleared
+	}
+	return clearance
+}
+
+func (s *RatesClearanceService) fetchAccount(ctx context.Context, accountNumber string) (*ratesAccountResponse, error) {
+	endpoint := fmt.Sprintf("%s/accounts/%s/balance", s.config.BaseURL, url.PathEscape(accountNumber))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build billing request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if s.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.config.APIKey)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("billing system unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("rates account %s not found in billing system", accountNumber)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("billing system returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var account ratesAccountResponse
+	if err := json.NewDecoder(resp.Body).Decode(&account); err != nil {
+		return nil, fmt.Errorf("invalid billing response: %w", err)
+	}
+	return &account, nil
+}
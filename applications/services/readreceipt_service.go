@@ -2,6 +2,7 @@
 package services
 
 import (
+	"fmt"
 	"time"
 	"town-planning-backend/db/models"
 	"town-planning-backend/config"
@@ -12,20 +13,34 @@ import (
 )
 
 type ReadReceiptService struct {
-	db *gorm.DB
+	db          *gorm.DB
+	threadState *ThreadStateService
 }
 
 func NewReadReceiptService(db *gorm.DB) *ReadReceiptService {
-	return &ReadReceiptService{db: db}
+	return &ReadReceiptService{db: db, threadState: NewThreadStateService(db)}
+}
+
+// ThreadState exposes the participant thread state service backing read positions
+func (s *ReadReceiptService) ThreadState() *ThreadStateService {
+	return s.threadState
 }
 
 // ProcessReadReceipts is the single source of truth for read receipt logic
-func (s *ReadReceiptService) ProcessReadReceipts(threadID string, userID uuid.UUID, messageIDs []string, isRealtime bool) (int, error) {
+func (s *ReadReceiptService) ProcessReadReceipts(threadID string, userID uuid.UUID, messageIDs []string, isRealtime bool) (int, *models.ParticipantThreadState, error) {
 	processedCount := 0
 	readAt := time.Now()
 
+	threadUUID, err := uuid.Parse(threadID)
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid thread ID: %w", err)
+	}
+
+	var state *models.ParticipantThreadState
+	var readIDs []uuid.UUID
+
 	// Use transaction for atomic operations
-	err := s.db.Transaction(func(tx *gorm.DB) error {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
 		for _, msgID := range messageIDs {
 			messageUUID, err := uuid.Parse(msgID)
 			if err != nil {
@@ -63,19 +78,20 @@ func (s *ReadReceiptService) ProcessReadReceipts(threadID string, userID uuid.UU
 				}
 			}
 
+			readIDs = append(readIDs, messageUUID)
 			processedCount++
 		}
 
-		// Reset participant unread count
-		return tx.Model(&models.ChatParticipant{}).
-			Where("thread_id = ? AND user_id = ?", threadID, userID).
-			Updates(map[string]interface{}{
-				"unread_count": 0,
-				"last_read_at": readAt,
-			}).Error
+		// Advance the read position and recount unread messages
+		var err error
+		state, err = s.threadState.MarkRead(tx, threadUUID, userID, readIDs)
+		return err
 	})
+	if err != nil {
+		return 0, nil, err
+	}
 
-	return processedCount, err
+	return processedCount, state, nil
 }
 
 func (s *ReadReceiptService) GetUserByID(userID string) (*models.User, error) {
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+	"town-planning-backend/db/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// RiskInputs are the facts about an application that feed its risk score
+type RiskInputs struct {
+	PlanArea        *decimal.Decimal
+	Category        *models.DevelopmentCategory
+	ApplicantDebtor bool
+	PriorRejections int
+	Stand           *models.Stand
+}
+
+// RiskFactorScore is the points one factor added to a score
+type RiskFactorScore struct {
+	Factor models.RiskFactor `json:"factor"`
+	Points int               `json:"points"`
+	Detail string            `json:"detail"`
+}
+
+// DefaultRiskScoringProfile is the profile used until an administrator saves one
+func DefaultRiskScoringProfile() *models.RiskScoringProfile {
+	return &models.RiskScoringProfile{
+		IsActive:              true,
+		PlanAreaThreshold:     decimal.NewFromInt(500),
+		PlanAreaPoints:        20,
+		ApplicantDebtorPoints: 25,
+		PriorRejectionPoints:  10,
+		PriorRejectionCap:     30,
+		UnservicedStandPoints: 15,
+		MediumRiskThreshold:   30,
+		HighRiskThreshold:     60,
+		CreatedBy:             "system",
+	}
+}
+
+// ValidateRiskScoringProfile rejects profiles whose points or thresholds cannot produce a
+// sensible ranking
+func ValidateRiskScoringProfile(profile *models.RiskScoringProfile) error {
+	if profile.PlanAreaThreshold.IsNegative() {
+		return fmt.Errorf("plan area threshold cannot be negative")
+	}
+	for name, points := range map[string]int{
+		"plan area points":        profile.PlanAreaPoints,
+		"applicant debtor points": profile.ApplicantDebtorPoints,
+		"prior rejection points":  profile.PriorRejectionPoints,
+		"prior rejection cap":     profile.PriorRejectionCap,
+		"unserviced stand points": profile.UnservicedStandPoints,
+		"medium risk threshold":   profile.MediumRiskThreshold,
+	} {
+		if points < 0 {
+			return fmt.Errorf("%s cannot be negative", name)
+		}
+	}
+	if profile.HighRiskThreshold <= profile.MediumRiskThreshold {
+		return fmt.Errorf("high risk threshold must be above the medium risk threshold")
+	}
+	return nil
+}
+
+// ScoreApplicationRisk adds up the points of every factor that applies to the application
+func ScoreApplicationRisk(profile *models.RiskScoringProfile, inputs RiskInputs) (*models.ApplicationRiskAssessment, []RiskFactorScore, error) {
+	var factors []RiskFactorScore
+	add := func(factor models.RiskFactor, points int, detail string) {
+		if points > 0 {
+			factors = append(factors, RiskFactorScore{Factor: factor, Points: points, Detail: detail})
+		}
+	}
+
+	if inputs.PlanArea != nil && inputs.PlanArea.GreaterThan(profile.PlanAreaThreshold) {
+		add(models.RiskFactorPlanArea, profile.PlanAreaPoints,
+			fmt.Sprintf("plan area %s m² is above %s m²", inputs.PlanArea.StringFixed(2), profile.PlanAreaThreshold.StringFixed(2)))
+	}
+
+	if inputs.Category != nil {
+		add(models.RiskFactorDevelopmentCategory, inputs.Category.RiskPoints,
+			fmt.Sprintf("development category %s", inputs.Category.Name))
+	}
+
+	if inputs.ApplicantDebtor {
+		add(models.RiskFactorApplicantDebtor, profile.ApplicantDebtorPoints, "applicant is a council debtor")
+	}
+
+	if inputs.PriorRejections > 0 {
+		points := inputs.PriorRejections * profile.PriorRejectionPoints
+		if points > profile.PriorRejectionCap {
+			points = profile.PriorRejectionCap
+		}
+		add(models.RiskFactorPriorRejections, points,
+			fmt.Sprintf("applicant has %d earlier rejected application(s)", inputs.PriorRejections))
+	}
+
+	if inputs.Stand != nil && !inputs.Stand.IsServiced {
+		add(models.RiskFactorUnservicedStand, profile.UnservicedStandPoints,
+			fmt.Sprintf("stand %s is not serviced", inputs.Stand.StandNumber))
+	}
+
+	score := 0
+	for _, factor := range factors {
+		score += factor.Points
+	}
+
+	encoded, err := json.Marshal(factors)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode risk factors: %w", err)
+	}
+
+	return &models.ApplicationRiskAssessment{
+		ProfileID:  profile.ID,
+		Score:      score,
+		Level:      profile.LevelFor(score),
+		Factors:    encoded,
+		AssessedAt: time.Now(),
+	}, factors, nil
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PauseApplicationSLA stops the application's SLA clocks while staff wait on the applicant. A
+// pause that is already running is returned as it is; opened reports whether a new one began.
+func PauseApplicationSLA(
+	tx *gorm.DB,
+	applicationID uuid.UUID,
+	issueID uuid.UUID,
+	pausedByID uuid.UUID,
+	now time.Time,
+) (pause *models.ApplicationSLAPause, opened bool, err error) {
+	// Locking the application keeps two flags raised together from opening two pauses
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		Where("id = ?", applicationID).
+		First(&models.Application{}).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, false, errors.New("application not found")
+		}
+		return nil, false, fmt.Errorf("failed to lock application: %w", err)
+	}
+
+	open, err := openSLAPause(tx, applicationID)
+	if err != nil {
+		return nil, false, err
+	}
+	if open != nil {
+		return open, false, nil
+	}
+
+	pause = &models.ApplicationSLAPause{
+		ApplicationID: applicationID,
+		IssueID:       issueID,
+		PausedByID:    pausedByID,
+		PausedAt:      now,
+	}
+	if err := tx.Create(pause).Error; err != nil {
+		return nil, false, fmt.Errorf("failed to pause SLA: %w", err)
+	}
+	return pause, true, nil
+}
+
+// ResumeApplicationSLA restarts the application's SLA clocks. When the applicant resumed them,
+// by replying or sending documents, every issue stops waiting on them; otherwise the clocks stay
+// paused while any open issue is still waiting. The closed pause is returned, or nil when the
+// clocks were not paused or stay paused. resumedByID is nil for the applicant.
+func ResumeApplicationSLA(
+	tx *gorm.DB,
+	applicationID uuid.UUID,
+	reason models.SLAResumeReason,
+	resumedByID *uuid.UUID,
+	now time.Time,
+) (*models.ApplicationSLAPause, error) {
+	if reason.ByApplicant() {
+		if err := tx.Model(&models.ApplicationIssue{}).
+			Where("application_id = ? AND awaiting_applicant = ?", applicationID, true).
+			Updates(map[string]interface{}{
+				"awaiting_applicant":       false,
+				"awaiting_applicant_since": nil,
+				"updated_at":               now,
+			}).Error; err != nil {
+			return nil, fmt.Errorf("failed to clear awaiting applicant flags: %w", err)
+		}
+	} else {
+		var waiting int64
+		if err := tx.Model(&models.ApplicationIssue{}).
+			Where("application_id = ? AND awaiting_applicant = ? AND is_resolved = ?", applicationID, true, false).
+			Count(&waiting).Error; err != nil {
+			return nil, fmt.Errorf("failed to count issues awaiting the applicant: %w", err)
+		}
+		if waiting > 0 {
+			return nil, nil
+		}
+	}
+
+	pause, err := openSLAPause(tx, applicationID)
+	if err != nil || pause == nil {
+		return nil, err
+	}
+	pause.ResumedAt = &now
+	pause.ResumeReason = &reason
+	pause.ResumedByID = resumedByID
+	if err := tx.Model(pause).Updates(map[string]interface{}{
+		"resumed_at":    now,
+		"resume_reason": reason,
+		"resumed_by_id": resumedByID,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to resume SLA: %w", err)
+	}
+	return pause, nil
+}
+
+func openSLAPause(tx *gorm.DB, applicationID uuid.UUID) (*models.ApplicationSLAPause, error) {
+	var pause models.ApplicationSLAPause
+	err := tx.Where("application_id = ? AND resumed_at IS NULL", applicationID).First(&pause).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up SLA pause: %w", err)
+	}
+	return &pause, nil
+}
+
+// PausedDuration is how much of [from, to) the pauses cover. An application's pauses never
+// overlap, so their overlaps with the window add up.
+func PausedDuration(pauses []models.ApplicationSLAPause, from, to time.Time) time.Duration {
+	var paused time.Duration
+	for _, pause := range pauses {
+		start := pause.PausedAt
+		if start.Before(from) {
+			start = from
+		}
+		end := to
+		if pause.ResumedAt != nil && pause.ResumedAt.Before(to) {
+			end = *pause.ResumedAt
+		}
+		if end.After(start) {
+			paused += end.Sub(start)
+		}
+	}
+	return paused
+}
+
+// SLAClockStart shifts when a clock started by the time it spent paused up to now, so that
+// now.Sub(start) counts only the time staff could act. paused reports whether the clock is
+// stopped now.
+func SLAClockStart(started time.Time, pauses []models.ApplicationSLAPause, now time.Time) (start time.Time, paused bool) {
+	return started.Add(PausedDuration(pauses, started, now)), IsSLAPaused(pauses)
+}
+
+// IsSLAPaused reports whether one of the pauses is still open
+func IsSLAPaused(pauses []models.ApplicationSLAPause) bool {
+	for i := range pauses {
+		if pauses[i].IsOpen() {
+			return true
+		}
+	}
+	return false
+}
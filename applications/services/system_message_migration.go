@@ -0,0 +1,165 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	legacyIssueCreated      = regexp.MustCompile(`(?s)^Issue created: (.*)$`)
+	legacyIssueResolved     = regexp.MustCompile(`(?s)^Issue resolved by ([^:\n]+)(?::\n(.*))?$`)
+	legacyIssueReopened     = regexp.MustCompile(`^Issue reopened by (.+)$`)
+	legacyParticipantCount  = regexp.MustCompile(`^(\d+) participants$`)
+	legacyPermissionsSuffix = regexp.MustCompile(`^(.+) to the conversation with (.+) permissions$`)
+)
+
+// legacySystemMessagesMigration is the data_migrations row recording the conversion has run
+const legacySystemMessagesMigration = "legacy_system_messages"
+
+// MigrateLegacySystemMessages converts SYSTEM messages saved as plain English text into
+// structured events so they can be localized. Messages that do not match a known format are
+// left as is. The conversion runs once; later starts find it recorded and skip it.
+func MigrateLegacySystemMessages(db *gorm.DB) (int, error) {
+	var completed int64
+	if err := db.Model(&models.DataMigration{}).
+		Where("name = ?", legacySystemMessagesMigration).
+		Count(&completed).Error; err != nil {
+		return 0, fmt.Errorf("failed to check legacy system message migration: %w", err)
+	}
+	if completed > 0 {
+		return 0, nil
+	}
+
+	var messages []models.ChatMessage
+	migrated := 0
+
+	result := db.
+		Preload("Sender").
+		Where("message_type = ? AND event_type IS NULL", models.MessageTypeSystem).
+		FindInBatches(&messages, 500, func(tx *gorm.DB, batch int) error {
+			for _, message := range messages {
+				eventType, params, ok := parseLegacySystemMessage(message)
+				if !ok {
+					continue
+				}
+
+				raw, err := json.Marshal(params)
+				if err != nil {
+					return fmt.Errorf("failed to encode params for message %s: %w", message.ID, err)
+				}
+
+				if err := db.Model(&models.ChatMessage{}).
+					Where("id = ?", message.ID).
+					Updates(map[string]interface{}{
+						"event_type":   eventType,
+						"event_params": raw,
+					}).Error; err != nil {
+					return fmt.Errorf("failed to migrate message %s: %w", message.ID, err)
+				}
+				migrated++
+			}
+			return nil
+		})
+
+	if result.Error != nil {
+		return migrated, result.Error
+	}
+
+	if err := db.Create(&models.DataMigration{
+		Name:        legacySystemMessagesMigration,
+		CompletedAt: time.Now(),
+	}).Error; err != nil {
+		return migrated, fmt.Errorf("failed to record legacy system message migration: %w", err)
+	}
+
+	if migrated > 0 {
+		config.Logger.Info("Migrated legacy chat system messages", zap.Int("count", migrated))
+	}
+	return migrated, nil
+}
+
+func parseLegacySystemMessage(message models.ChatMessage) (models.SystemEventType, SystemEventParams, bool) {
+	content := message.Content
+
+	if m := legacyIssueCreated.FindStringSubmatch(content); m != nil {
+		return models.SystemEventIssueCreated, SystemEventParams{Description: m[1]}, true
+	}
+	if m := legacyIssueResolved.FindStringSubmatch(content); m != nil {
+		return models.SystemEventIssueResolved, SystemEventParams{ActorName: m[1], Comment: m[2]}, true
+	}
+	if m := legacyIssueReopened.FindStringSubmatch(content); m != nil {
+		return models.SystemEventIssueReopened, SystemEventParams{ActorName: m[1]}, true
+	}
+
+	// Participant messages start with the sender's name, which tells us where the actor ends
+	actor := strings.TrimSpace(message.Sender.FirstName + " " + message.Sender.LastName)
+	if actor == "" || !strings.HasPrefix(content, actor+" ") {
+		return "", SystemEventParams{}, false
+	}
+	rest := strings.TrimPrefix(content, actor+" ")
+
+	switch {
+	case strings.HasPrefix(rest, "added "):
+		return parseLegacyParticipantChange(actor, strings.TrimPrefix(rest, "added "), " to the conversation",
+			models.SystemEventParticipantAdded, models.SystemEventParticipantsAdded)
+	case strings.HasPrefix(rest, "removed "):
+		return parseLegacyParticipantChange(actor, strings.TrimPrefix(rest, "removed "), " from the conversation",
+			models.SystemEventParticipantRemoved, models.SystemEventParticipantsRemoved)
+	}
+	return "", SystemEventParams{}, false
+}
+
+func parseLegacyParticipantChange(
+	actor string,
+	rest string,
+	suffix string,
+	single models.SystemEventType,
+	bulk models.SystemEventType,
+) (models.SystemEventType, SystemEventParams, bool) {
+	params := SystemEventParams{ActorName: actor}
+
+	// "{actor} added {target} to the conversation with invite/remove permissions"
+	if single == models.SystemEventParticipantAdded {
+		if m := legacyPermissionsSuffix.FindStringSubmatch(rest); m != nil {
+			params.TargetNames = []string{m[1]}
+			for _, label := range strings.Split(m[2], "/") {
+				switch label {
+				case "invite":
+					params.Permissions = append(params.Permissions, permissionInvite)
+				case "remove":
+					params.Permissions = append(params.Permissions, permissionRemove)
+				case "manage permissions":
+					params.Permissions = append(params.Permissions, permissionManage)
+				}
+			}
+			return single, params, true
+		}
+	}
+
+	if !strings.HasSuffix(rest, suffix) {
+		return "", SystemEventParams{}, false
+	}
+	targets := strings.TrimSuffix(rest, suffix)
+
+	if m := legacyParticipantCount.FindStringSubmatch(targets); m != nil {
+		count, _ := strconv.Atoi(m[1])
+		params.TargetCount = count
+		return bulk, params, true
+	}
+
+	names := strings.Split(targets, ", ")
+	params.TargetNames = names
+	if len(names) > 1 {
+		return bulk, params, true
+	}
+	return single, params, true
+}
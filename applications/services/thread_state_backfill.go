@@ -0,0 +1,50 @@
+package services
+
+import (
+	"town-planning-backend/config"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// BackfillParticipantThreadStates creates thread state rows for participants that predate them.
+// The read position is the newest message the user has a read receipt for, and the unread
+// count is what arrived from others after it. Only participants without a state row are
+// looked at, so once the backfill has run a start costs one anti-join.
+func BackfillParticipantThreadStates(db *gorm.DB) (int, error) {
+	result := db.Exec(`
+		INSERT INTO participant_thread_states
+			(id, thread_id, user_id, last_read_message_id, last_read_at, unread_count, is_muted, created_at, updated_at)
+		SELECT gen_random_uuid(), p.thread_id, p.user_id, last_read.message_id,
+			COALESCE(last_read.read_at, p.last_read_at),
+			(SELECT COUNT(*) FROM chat_messages m
+				WHERE m.thread_id = p.thread_id AND m.sender_id != p.user_id AND m.is_deleted = false
+				AND (last_read.created_at IS NULL OR m.created_at > last_read.created_at)),
+			p.mute_notifications, NOW(), NOW()
+		FROM (
+			SELECT DISTINCT ON (thread_id, user_id) thread_id, user_id, last_read_at, mute_notifications
+			FROM chat_participants cp
+			WHERE NOT EXISTS (
+				SELECT 1 FROM participant_thread_states s
+				WHERE s.thread_id = cp.thread_id AND s.user_id = cp.user_id)
+			ORDER BY thread_id, user_id, is_active DESC, updated_at DESC
+		) p
+		LEFT JOIN LATERAL (
+			SELECT r.message_id, r.read_at, m.created_at
+			FROM read_receipts r
+			JOIN chat_messages m ON m.id = r.message_id
+			WHERE r.user_id = p.user_id AND m.thread_id = p.thread_id
+			ORDER BY m.created_at DESC
+			LIMIT 1
+		) last_read ON true
+		ON CONFLICT (thread_id, user_id) DO NOTHING`)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	created := int(result.RowsAffected)
+	if created > 0 {
+		config.Logger.Info("Backfilled participant thread states from read receipts", zap.Int("count", created))
+	}
+	return created, nil
+}
@@ -0,0 +1,295 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TypingWindow is how long a typing signal keeps a participant shown as typing
+const TypingWindow = 6 * time.Second
+
+// LastSeenResolution is how stale a participant's last seen time may get before a WebSocket ping
+// rewrites it; pings arrive every 30 seconds per open connection
+const LastSeenResolution = time.Minute
+
+// ThreadStateService owns the per-participant thread state. Read positions, unread counts,
+// typing and mute all go through here so the HTTP endpoints and WebSocket events agree.
+type ThreadStateService struct {
+	db *gorm.DB
+}
+
+func NewThreadStateService(db *gorm.DB) *ThreadStateService {
+	return &ThreadStateService{db: db}
+}
+
+// ThreadStateView is a participant's state as returned to clients
+type ThreadStateView struct {
+	ThreadID          uuid.UUID  `json:"threadId"`
+	UserID            uuid.UUID  `json:"userId"`
+	LastReadMessageID *uuid.UUID `json:"lastReadMessageId"`
+	LastReadAt        *time.Time `json:"lastReadAt"`
+	UnreadCount       int        `json:"unreadCount"`
+	IsTyping          bool       `json:"isTyping"`
+	LastTypingAt      *time.Time `json:"lastTypingAt"`
+	IsMuted           bool       `json:"isMuted"`
+	MutedUntil        *time.Time `json:"mutedUntil"`
+	LastSeenAt        *time.Time `json:"lastSeenAt"`
+}
+
+// NewThreadStateView converts a state row into its client representation
+func NewThreadStateView(state *models.ParticipantThreadState) ThreadStateView {
+	return ThreadStateView{
+		ThreadID:          state.ThreadID,
+		UserID:            state.UserID,
+		LastReadMessageID: state.LastReadMessageID,
+		LastReadAt:        state.LastReadAt,
+		UnreadCount:       state.UnreadCount,
+		IsTyping:          state.IsTyping(TypingWindow),
+		LastTypingAt:      state.LastTypingAt,
+		IsMuted:           state.IsMutedNow(),
+		MutedUntil:        state.MutedUntil,
+		LastSeenAt:        state.LastSeenAt,
+	}
+}
+
+// ensureState returns the user's state row for the thread, creating it on first use.
+// Only participants of the thread have state.
+func (s *ThreadStateService) ensureState(tx *gorm.DB, threadID, userID uuid.UUID) (*models.ParticipantThreadState, error) {
+	var participant models.ChatParticipant
+	if err := tx.Where("thread_id = ? AND user_id = ? AND is_active = ?", threadID, userID, true).
+		First(&participant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user is not a participant in this thread")
+		}
+		return nil, fmt.Errorf("failed to check thread participant: %w", err)
+	}
+
+	state := models.ParticipantThreadState{
+		ThreadID: threadID,
+		UserID:   userID,
+		IsMuted:  participant.MuteNotifications,
+	}
+	if err := tx.Where("thread_id = ? AND user_id = ?", threadID, userID).
+		FirstOrCreate(&state).Error; err != nil {
+		return nil, fmt.Errorf("failed to load thread state: %w", err)
+	}
+	return &state, nil
+}
+
+// GetState returns the user's state in a thread
+func (s *ThreadStateService) GetState(threadID, userID uuid.UUID) (*models.ParticipantThreadState, error) {
+	return s.ensureState(s.db, threadID, userID)
+}
+
+// GetThreadStates returns the state of every participant who has one in the thread
+func (s *ThreadStateService) GetThreadStates(threadID uuid.UUID) ([]models.ParticipantThreadState, error) {
+	var states []models.ParticipantThreadState
+	if err := s.db.Where("thread_id = ?", threadID).Find(&states).Error; err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+// MarkRead moves the user's read position to the newest of the given messages and recounts
+// what is still unread after it. The position never moves backwards.
+func (s *ThreadStateService) MarkRead(tx *gorm.DB, threadID, userID uuid.UUID, messageIDs []uuid.UUID) (*models.ParticipantThreadState, error) {
+	state, err := s.ensureState(tx, threadID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	if len(messageIDs) > 0 {
+		var newest models.ChatMessage
+		err := tx.Select("id", "created_at").
+			Where("thread_id = ? AND id IN ?", threadID, messageIDs).
+			Order("created_at DESC").
+			First(&newest).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to find read messages: %w", err)
+		}
+
+		if err == nil {
+			advance := state.LastReadMessageID == nil
+			if !advance {
+				var current models.ChatMessage
+				if err := tx.Select("id", "created_at").Where("id = ?", *state.LastReadMessageID).
+					First(&current).Error; err != nil {
+					advance = true
+				} else {
+					advance = newest.CreatedAt.After(current.CreatedAt)
+				}
+			}
+			if advance {
+				state.LastReadMessageID = &newest.ID
+			}
+		}
+	}
+
+	unread, err := s.countUnread(tx, threadID, userID, state.LastReadMessageID)
+	if err != nil {
+		return nil, err
+	}
+
+	state.LastReadAt = &now
+	state.UnreadCount = unread
+	state.LastSeenAt = &now
+
+	if err := tx.Model(state).Updates(map[string]interface{}{
+		"last_read_message_id": state.LastReadMessageID,
+		"last_read_at":         now,
+		"unread_count":         unread,
+		"last_seen_at":         now,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update thread state: %w", err)
+	}
+
+	// Keep the participant row in step for clients still reading it from the thread payload
+	if err := tx.Model(&models.ChatParticipant{}).
+		Where("thread_id = ? AND user_id = ?", threadID, userID).
+		Updates(map[string]interface{}{
+			"unread_count": unread,
+			"last_read_at": now,
+			"last_seen_at": now,
+		}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update participant read state: %w", err)
+	}
+
+	return state, nil
+}
+
+// countUnread counts messages from others that arrived after the read position
+func (s *ThreadStateService) countUnread(tx *gorm.DB, threadID, userID uuid.UUID, lastReadMessageID *uuid.UUID) (int, error) {
+	var count int64
+	query := tx.Model(&models.ChatMessage{}).
+		Where("thread_id = ? AND sender_id != ? AND is_deleted = ?", threadID, userID, false)
+	if lastReadMessageID != nil {
+		query = query.Where("created_at > (?)",
+			tx.Model(&models.ChatMessage{}).Select("created_at").Where("id = ?", *lastReadMessageID))
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count unread messages: %w", err)
+	}
+	return int(count), nil
+}
+
+// IncrementUnread bumps the unread count of every active participant except the sender.
+// Participants without a state row yet get one.
+func (s *ThreadStateService) IncrementUnread(tx *gorm.DB, threadID, senderID uuid.UUID) error {
+	if err := tx.Exec(`
+		INSERT INTO participant_thread_states (id, thread_id, user_id, unread_count, is_muted, created_at, updated_at)
+		SELECT gen_random_uuid(), p.thread_id, p.user_id, 1, bool_or(p.mute_notifications), NOW(), NOW()
+		FROM chat_participants p
+		WHERE p.thread_id = ? AND p.user_id != ? AND p.is_active = true
+		GROUP BY p.thread_id, p.user_id
+		ON CONFLICT (thread_id, user_id)
+		DO UPDATE SET unread_count = participant_thread_states.unread_count + 1, updated_at = NOW()`,
+		threadID, senderID).Error; err != nil {
+		return fmt.Errorf("failed to increment unread counts: %w", err)
+	}
+
+	if err := tx.Model(&models.ChatParticipant{}).
+		Where("thread_id = ? AND user_id != ? AND is_active = ?", threadID, senderID, true).
+		UpdateColumn("unread_count", gorm.Expr("unread_count + ?", 1)).Error; err != nil {
+		return fmt.Errorf("failed to increment participant unread counts: %w", err)
+	}
+
+	return nil
+}
+
+// RecordSeen moves the user's last seen time in the thread to at. Unless force is set, a last
+// seen time less than LastSeenResolution old is left alone, so keep-alive pings do not write on
+// every beat. Participants without a state row yet get one; non-participants are ignored.
+func (s *ThreadStateService) RecordSeen(threadID, userID uuid.UUID, at time.Time, force bool) error {
+	staleBefore := at.Add(-LastSeenResolution)
+	if force {
+		staleBefore = at
+	}
+
+	result := s.db.Exec(`
+		INSERT INTO participant_thread_states (id, thread_id, user_id, last_seen_at, is_muted, created_at, updated_at)
+		SELECT gen_random_uuid(), p.thread_id, p.user_id, ?, bool_or(p.mute_notifications), NOW(), NOW()
+		FROM chat_participants p
+		WHERE p.thread_id = ? AND p.user_id = ? AND p.is_active = true
+		GROUP BY p.thread_id, p.user_id
+		ON CONFLICT (thread_id, user_id)
+		DO UPDATE SET last_seen_at = EXCLUDED.last_seen_at, updated_at = NOW()
+		WHERE participant_thread_states.last_seen_at IS NULL OR participant_thread_states.last_seen_at < ?`,
+		at, threadID, userID, staleBefore)
+	if result.Error != nil {
+		return fmt.Errorf("failed to record last seen: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+
+	// Keep the participant row in step for clients still reading it from the thread payload
+	if err := s.db.Model(&models.ChatParticipant{}).
+		Where("thread_id = ? AND user_id = ?", threadID, userID).
+		Update("last_seen_at", at).Error; err != nil {
+		return fmt.Errorf("failed to update participant last seen: %w", err)
+	}
+	return nil
+}
+
+// RecordTyping stores when the user last typed. Stopping clears it.
+func (s *ThreadStateService) RecordTyping(threadID, userID uuid.UUID, isTyping bool) (*models.ParticipantThreadState, error) {
+	state, err := s.ensureState(s.db, threadID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var typingAt *time.Time
+	if isTyping {
+		now := time.Now()
+		typingAt = &now
+	}
+	state.LastTypingAt = typingAt
+
+	if err := s.db.Model(state).Update("last_typing_at", typingAt).Error; err != nil {
+		return nil, fmt.Errorf("failed to record typing: %w", err)
+	}
+	return state, nil
+}
+
+// SetMute mutes or unmutes notifications for the user in the thread. A nil until mutes indefinitely.
+func (s *ThreadStateService) SetMute(threadID, userID uuid.UUID, muted bool, until *time.Time) (*models.ParticipantThreadState, error) {
+	if muted && until != nil && !until.After(time.Now()) {
+		return nil, errors.New("mute end must be in the future")
+	}
+	if !muted {
+		until = nil
+	}
+
+	var state *models.ParticipantThreadState
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		state, err = s.ensureState(tx, threadID, userID)
+		if err != nil {
+			return err
+		}
+
+		state.IsMuted = muted
+		state.MutedUntil = until
+		if err := tx.Model(state).Updates(map[string]interface{}{
+			"is_muted":    muted,
+			"muted_until": until,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update mute: %w", err)
+		}
+
+		return tx.Model(&models.ChatParticipant{}).
+			Where("thread_id = ? AND user_id = ?", threadID, userID).
+			Update("mute_notifications", muted).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"strconv"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/utils"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultVoiceNoteMaxSeconds = 300
+	defaultVoiceNoteMaxMB      = 10
+)
+
+// VoiceNoteLimits is the council's policy for chat voice notes
+type VoiceNoteLimits struct {
+	MaxDuration time.Duration
+	MaxBytes    int64
+}
+
+// LoadVoiceNoteLimits reads the voice note policy. Both variables are optional:
+//
+//	CHAT_VOICE_NOTE_MAX_SECONDS=300   longest voice note accepted
+//	CHAT_VOICE_NOTE_MAX_MB=10         largest voice note file accepted
+func LoadVoiceNoteLimits() VoiceNoteLimits {
+	return VoiceNoteLimits{
+		MaxDuration: time.Duration(positiveEnvInt("CHAT_VOICE_NOTE_MAX_SECONDS", defaultVoiceNoteMaxSeconds)) * time.Second,
+		MaxBytes:    int64(positiveEnvInt("CHAT_VOICE_NOTE_MAX_MB", defaultVoiceNoteMaxMB)) * 1024 * 1024,
+	}
+}
+
+func positiveEnvInt(name string, fallback int) int {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return fallback
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value <= 0 {
+		config.Logger.Warn("Invalid setting, using default",
+			zap.String("variable", name),
+			zap.String("value", raw),
+			zap.Int("default", fallback))
+		return fallback
+	}
+	return value
+}
+
+// IsVoiceNote reports whether an uploaded chat attachment is audio
+func IsVoiceNote(file *multipart.FileHeader) bool {
+	return utils.IsAudioMimeType(file.Header.Get("Content-Type"))
+}
+
+// InspectVoiceNote checks an audio attachment against the limits and returns its duration
+func (l VoiceNoteLimits) InspectVoiceNote(file *multipart.FileHeader) (time.Duration, error) {
+	if file.Size > l.MaxBytes {
+		return 0, fmt.Errorf("voice note %s is larger than the %d MB limit", file.Filename, l.MaxBytes/(1024*1024))
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return 0, fmt.Errorf("failed to open voice note %s: %w", file.Filename, err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, l.MaxBytes+1))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read voice note %s: %w", file.Filename, err)
+	}
+
+	duration, err := utils.AudioDuration(data, file.Header.Get("Content-Type"))
+	if err != nil {
+		return 0, fmt.Errorf("could not read the length of voice note %s: %w", file.Filename, err)
+	}
+	if duration > l.MaxDuration {
+		return 0, fmt.Errorf("voice note %s is longer than the %s limit", file.Filename, l.MaxDuration)
+	}
+	return duration, nil
+}
+
+// ValidateVoiceNotes checks every audio attachment in an upload, so a message is rejected
+// before anything is stored
+func (l VoiceNoteLimits) ValidateVoiceNotes(files []*multipart.FileHeader) error {
+	for _, file := range files {
+		if !IsVoiceNote(file) {
+			continue
+		}
+		if _, err := l.InspectVoiceNote(file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
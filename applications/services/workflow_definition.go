@@ -0,0 +1,351 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+)
+
+// WorkflowDefinitionFormatVersion is bumped whenever the shape of the export changes, so saved
+// exports are only diffed against exports of the same shape
+const WorkflowDefinitionFormatVersion = 2
+
+// Node types of the process graph, named after their BPMN counterparts
+const (
+	WorkflowNodeStart           = "startEvent"
+	WorkflowNodeEnd             = "endEvent"
+	WorkflowNodeUserTask        = "userTask"
+	WorkflowNodeParallelGateway = "parallelGateway"
+	WorkflowNodeDecisionGateway = "exclusiveGateway"
+)
+
+// WorkflowDefinition is the approval workflow as configured, in a form the frontend can draw
+// and that can be diffed between councils. Groups, members and checklist items are sorted so
+// that two exports of the same configuration are identical apart from GeneratedAt.
+type WorkflowDefinition struct {
+	FormatVersion  int                       `json:"format_version"`
+	GeneratedAt    time.Time                 `json:"generated_at"`
+	Checksum       string                    `json:"checksum"`
+	SLA            WorkflowSLA               `json:"sla"`
+	AutoRejections []WorkflowRule            `json:"auto_rejection_rules"`
+	Groups         []WorkflowGroupDefinition `json:"groups"`
+}
+
+// WorkflowSLA is how long a member decision may stay pending before the approver is reminded
+// and then escalated to their department head
+type WorkflowSLA struct {
+	RemindAfterDays    int `json:"remind_after_days"`
+	RemindIntervalDays int `json:"remind_interval_days"`
+	EscalateAfterDays  int `json:"escalate_after_days"`
+}
+
+// WorkflowRule describes a rule that is built into the workflow rather than configured
+type WorkflowRule struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+}
+
+// WorkflowGroupDefinition is one approval group with its decision policy and process graph
+type WorkflowGroupDefinition struct {
+	ID          uuid.UUID                `json:"id"`
+	Name        string                   `json:"name"`
+	Description *string                  `json:"description,omitempty"`
+	Type        models.ApprovalGroupType `json:"type"`
+	IsActive    bool                     `json:"is_active"`
+	Policy      WorkflowGroupPolicy      `json:"policy"`
+	Members     []WorkflowMember         `json:"members"`
+	Checklist   []WorkflowChecklistItem  `json:"checklist"`
+	Process     WorkflowProcess          `json:"process"`
+}
+
+// WorkflowGroupPolicy is what it takes for a group to pass an application to its final approver
+type WorkflowGroupPolicy struct {
+	RequiresAllApprovals  bool `json:"requires_all_approvals"`
+	MinimumApprovalWeight int  `json:"minimum_approval_weight"`
+	TotalDecisionWeight   int  `json:"total_decision_weight"`
+	AutoAssignBackups     bool `json:"auto_assign_backups"`
+	HasFinalApprover      bool `json:"has_final_approver"`
+
+	// Which decisions must be explained: approvals with a comment, rejections with a reason
+	CommentPolicy           models.DecisionCommentPolicy `json:"comment_policy"`
+	ApprovalCommentRequired bool                         `json:"approval_comment_required"`
+	RejectionReasonRequired bool                         `json:"rejection_reason_required"`
+}
+
+// WorkflowMember is a group member as it takes part in the workflow. Members are identified by
+// email, which stays readable in a diff.
+type WorkflowMember struct {
+	Email           string                    `json:"email"`
+	Name            string                    `json:"name"`
+	Department      string                    `json:"department,omitempty"`
+	Role            models.MemberRole         `json:"role"`
+	IsFinalApprover bool                      `json:"is_final_approver"`
+	ReviewOrder     int                       `json:"review_order"`
+	BackupPriority  int                       `json:"backup_priority"`
+	DecisionWeight  int                       `json:"decision_weight"`
+	CanApprove      bool                      `json:"can_approve"`
+	CanReject       bool                      `json:"can_reject"`
+	CanRaiseIssues  bool                      `json:"can_raise_issues"`
+	Availability    models.AvailabilityStatus `json:"availability_status"`
+	AutoReassign    bool                      `json:"auto_reassign"`
+}
+
+// WorkflowChecklistItem is a check the group's members sign off before approving
+type WorkflowChecklistItem struct {
+	Label       string `json:"label"`
+	IsMandatory bool   `json:"is_mandatory"`
+	SortOrder   int    `json:"sort_order"`
+}
+
+// WorkflowProcess is the group's workflow as a BPMN-like graph of nodes and sequence flows
+type WorkflowProcess struct {
+	Nodes []WorkflowNode `json:"nodes"`
+	Flows []WorkflowFlow `json:"flows"`
+}
+
+// WorkflowNode is an event, task or gateway. Tasks name the member who performs them.
+type WorkflowNode struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Assignee string `json:"assignee,omitempty"`
+}
+
+// WorkflowFlow is a sequence flow between two nodes, with the condition taken when it leaves a
+// decision gateway
+type WorkflowFlow struct {
+	ID        string `json:"id"`
+	Source    string `json:"source"`
+	Target    string `json:"target"`
+	Condition string `json:"condition,omitempty"`
+}
+
+// builtInAutoRejectionRules are applied by the decision processing to every group
+var builtInAutoRejectionRules = []WorkflowRule{
+	{
+		Code:        "ANY_MEMBER_REJECTION",
+		Description: "Once every regular member has decided, a single rejection rejects the application without going to the final approver",
+	},
+	{
+		Code:        "FINAL_APPROVER_REJECTION",
+		Description: "A rejection by the final approver rejects the application",
+	},
+}
+
+// BuildWorkflowDefinition assembles the export from the approval groups, with their members and
+// users loaded, the groups' active checklist items and the reminder policy
+func BuildWorkflowDefinition(
+	groups []models.ApprovalGroup,
+	checklists map[uuid.UUID][]models.ReviewChecklistItem,
+	reminders DecisionReminderPolicy,
+	now time.Time,
+) (*WorkflowDefinition, error) {
+	day := 24 * time.Hour
+	definition := &WorkflowDefinition{
+		FormatVersion: WorkflowDefinitionFormatVersion,
+		GeneratedAt:   now,
+		SLA: WorkflowSLA{
+			RemindAfterDays:    int(reminders.RemindAfter / day),
+			RemindIntervalDays: int(reminders.RemindInterval / day),
+			EscalateAfterDays:  int(reminders.EscalateAfter / day),
+		},
+		AutoRejections: builtInAutoRejectionRules,
+		Groups:         make([]WorkflowGroupDefinition, 0, len(groups)),
+	}
+
+	for _, group := range groups {
+		definition.Groups = append(definition.Groups, buildWorkflowGroup(group, checklists[group.ID]))
+	}
+	sort.SliceStable(definition.Groups, func(i, j int) bool {
+		return definition.Groups[i].Name < definition.Groups[j].Name
+	})
+
+	checksum, err := definition.checksum()
+	if err != nil {
+		return nil, err
+	}
+	definition.Checksum = checksum
+	return definition, nil
+}
+
+// checksum hashes everything but the generation time, so the checksum only changes when the
+// configuration does
+func (d *WorkflowDefinition) checksum() (string, error) {
+	content, err := json.Marshal(struct {
+		FormatVersion  int                       `json:"format_version"`
+		SLA            WorkflowSLA               `json:"sla"`
+		AutoRejections []WorkflowRule            `json:"auto_rejection_rules"`
+		Groups         []WorkflowGroupDefinition `json:"groups"`
+	}{d.FormatVersion, d.SLA, d.AutoRejections, d.Groups})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode workflow definition: %w", err)
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func buildWorkflowGroup(group models.ApprovalGroup, checklist []models.ReviewChecklistItem) WorkflowGroupDefinition {
+	members := make([]WorkflowMember, 0, len(group.Members))
+	totalWeight := 0
+	var finalApprover *WorkflowMember
+	for _, member := range group.Members {
+		if !member.IsActive || member.Role == models.MemberRoleRetired {
+			continue
+		}
+		workflowMember := WorkflowMember{
+			Email:           member.User.Email,
+			Name:            strings.TrimSpace(member.User.FirstName + " " + member.User.LastName),
+			Role:            member.Role,
+			IsFinalApprover: member.IsFinalApprover,
+			ReviewOrder:     member.ReviewOrder,
+			BackupPriority:  member.BackupPriority,
+			DecisionWeight:  member.Weight(),
+			CanApprove:      member.CanApprove,
+			CanReject:       member.CanReject,
+			CanRaiseIssues:  member.CanRaiseIssues,
+			Availability:    member.AvailabilityStatus,
+			AutoReassign:    member.AutoReassign,
+		}
+		if member.User.Department != nil {
+			workflowMember.Department = member.User.Department.Name
+		}
+		members = append(members, workflowMember)
+	}
+	sort.SliceStable(members, func(i, j int) bool {
+		if members[i].IsFinalApprover != members[j].IsFinalApprover {
+			return !members[i].IsFinalApprover
+		}
+		if members[i].Role != members[j].Role {
+			return members[i].Role == models.MemberRolePrimary
+		}
+		if members[i].ReviewOrder != members[j].ReviewOrder {
+			return members[i].ReviewOrder < members[j].ReviewOrder
+		}
+		if members[i].BackupPriority != members[j].BackupPriority {
+			return members[i].BackupPriority < members[j].BackupPriority
+		}
+		return members[i].Email < members[j].Email
+	})
+	for i := range members {
+		switch {
+		case members[i].IsFinalApprover:
+			finalApprover = &members[i]
+		case members[i].Role == models.MemberRolePrimary && members[i].CanApprove:
+			totalWeight += members[i].DecisionWeight
+		}
+	}
+
+	items := make([]WorkflowChecklistItem, 0, len(checklist))
+	for _, item := range checklist {
+		items = append(items, WorkflowChecklistItem{
+			Label:       item.Label,
+			IsMandatory: item.IsMandatory,
+			SortOrder:   item.SortOrder,
+		})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].SortOrder != items[j].SortOrder {
+			return items[i].SortOrder < items[j].SortOrder
+		}
+		return items[i].Label < items[j].Label
+	})
+
+	minimumWeight := totalWeight
+	if !group.RequiresAllApprovals {
+		minimumWeight = group.MinimumApprovals
+	}
+
+	return WorkflowGroupDefinition{
+		ID:          group.ID,
+		Name:        group.Name,
+		Description: group.Description,
+		Type:        group.Type,
+		IsActive:    group.IsActive,
+		Policy: WorkflowGroupPolicy{
+			RequiresAllApprovals:  group.RequiresAllApprovals,
+			MinimumApprovalWeight: minimumWeight,
+			TotalDecisionWeight:   totalWeight,
+			AutoAssignBackups:     group.AutoAssignBackups,
+			HasFinalApprover:      finalApprover != nil,
+
+			CommentPolicy:           group.CommentPolicy,
+			ApprovalCommentRequired: group.CommentPolicy.RequiresApprovalComment(),
+			RejectionReasonRequired: group.CommentPolicy.RequiresRejectionReason(),
+		},
+		Members:   members,
+		Checklist: items,
+		Process:   buildWorkflowProcess(group, members, finalApprover),
+	}
+}
+
+// buildWorkflowProcess draws the group's workflow: the primary members review in parallel, the
+// quorum gateway either rejects or hands over to the final approver, whose decision ends it.
+// Backups only step in for unavailable or recused members, so they are not drawn as tasks.
+func buildWorkflowProcess(group models.ApprovalGroup, members []WorkflowMember, finalApprover *WorkflowMember) WorkflowProcess {
+	process := WorkflowProcess{
+		Nodes: []WorkflowNode{
+			{ID: "start", Type: WorkflowNodeStart, Name: "Application assigned to " + group.Name},
+			{ID: "review_split", Type: WorkflowNodeParallelGateway, Name: "Members review"},
+			{ID: "review_join", Type: WorkflowNodeParallelGateway, Name: "All members decided"},
+			{ID: "quorum", Type: WorkflowNodeDecisionGateway, Name: quorumLabel(group)},
+			{ID: "rejected", Type: WorkflowNodeEnd, Name: "Application rejected"},
+		},
+		Flows: []WorkflowFlow{{ID: "flow_start", Source: "start", Target: "review_split"}},
+	}
+	addFlow := func(source, target, condition string) {
+		process.Flows = append(process.Flows, WorkflowFlow{
+			ID:        fmt.Sprintf("flow_%s_%s", source, target),
+			Source:    source,
+			Target:    target,
+			Condition: condition,
+		})
+	}
+
+	for i, member := range members {
+		if member.IsFinalApprover || member.Role != models.MemberRolePrimary {
+			continue
+		}
+		taskID := fmt.Sprintf("review_%d", i+1)
+		process.Nodes = append(process.Nodes, WorkflowNode{
+			ID:       taskID,
+			Type:     WorkflowNodeUserTask,
+			Name:     fmt.Sprintf("Review (weight %d)", member.DecisionWeight),
+			Assignee: member.Email,
+		})
+		addFlow("review_split", taskID, "")
+		addFlow(taskID, "review_join", "")
+	}
+	addFlow("review_join", "quorum", "")
+	addFlow("quorum", "rejected", "any member rejected")
+
+	if finalApprover == nil {
+		process.Nodes = append(process.Nodes, WorkflowNode{ID: "approved", Type: WorkflowNodeEnd, Name: "Ready for final approval"})
+		addFlow("quorum", "approved", "quorum reached")
+		return process
+	}
+
+	process.Nodes = append(process.Nodes,
+		WorkflowNode{ID: "final_approval", Type: WorkflowNodeUserTask, Name: "Final approval", Assignee: finalApprover.Email},
+		WorkflowNode{ID: "final_decision", Type: WorkflowNodeDecisionGateway, Name: "Final decision"},
+		WorkflowNode{ID: "approved", Type: WorkflowNodeEnd, Name: "Application approved"},
+	)
+	addFlow("quorum", "final_approval", "quorum reached and all issues resolved")
+	addFlow("final_approval", "final_decision", "")
+	addFlow("final_decision", "approved", "approved")
+	addFlow("final_decision", "rejected", "rejected")
+	return process
+}
+
+func quorumLabel(group models.ApprovalGroup) string {
+	if group.RequiresAllApprovals {
+		return "All members approved?"
+	}
+	return fmt.Sprintf("Approval weight of %d reached?", group.MinimumApprovals)
+}
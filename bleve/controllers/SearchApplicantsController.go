@@ -13,8 +13,9 @@ func (c *SearchController) SearchApplicantsController(ctx *fiber.Ctx) error {
 	}
 
 	status := ctx.Query("status")
+	archived := ctx.Query("archived") // "true" for archived only, "include" for both
 
-	results, err := c.repo.SearchApplicants(query, status)
+	results, err := c.repo.SearchApplicants(query, status, archived)
 	if err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Search failed",
@@ -11,6 +11,7 @@ func (c *SearchController) SearchStandsController(ctx *fiber.Ctx) error {
 	status := ctx.Query("status")
 	standType := ctx.Query("stand_type")
 	standCurrency := ctx.Query("stand_currency")
+	archived := ctx.Query("archived") // "true" for archived only, "include" for both
 
 	// Optional boolean filter
 	activeStr := ctx.Query("active")
@@ -27,7 +28,7 @@ func (c *SearchController) SearchStandsController(ctx *fiber.Ctx) error {
 	}
 
 	// Perform the search
-	results, err := c.repo.SearchStands(query, status, standType, active, standCurrency)
+	results, err := c.repo.SearchStands(query, status, standType, active, standCurrency, archived)
 	if err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Search failed",
@@ -21,6 +21,7 @@ func (r *BleveRepository) IndexSingleApplicant(applicant models.Applicant) error
 		Debtor        bool                   `json:"debtor"`
 		Status        models.ApplicantStatus `json:"status"`
 		ApplicantType models.ApplicantType   `json:"applicant_type"`
+		Archived      bool                   `json:"archived"`
 	}{
 		ID:            applicant.ID.String(),
 		FirstName:     derefString(applicant.FirstName),
@@ -32,6 +33,7 @@ func (r *BleveRepository) IndexSingleApplicant(applicant models.Applicant) error
 		Debtor:        applicant.Debtor,
 		Status:        applicant.Status,
 		ApplicantType: applicant.ApplicantType,
+		Archived:      applicationsArchived(applicant.Applications),
 	}
 
 	err := r.indexer.IndexDocument("applicants", applicant.ID.String(), bleveApplicantDoc)
@@ -59,6 +61,7 @@ func (r *BleveRepository) IndexExistingApplicants(applicants []models.Applicant)
 			Debtor        bool                   `json:"debtor"`
 			Status        models.ApplicantStatus `json:"status"`
 			ApplicantType models.ApplicantType   `json:"applicant_type"`
+			Archived      bool                   `json:"archived"`
 		}{
 			ID:            applicant.ID.String(),
 			FirstName:     derefString(applicant.FirstName),
@@ -70,6 +73,7 @@ func (r *BleveRepository) IndexExistingApplicants(applicants []models.Applicant)
 			Debtor:        applicant.Debtor,
 			Status:        applicant.Status,
 			ApplicantType: applicant.ApplicantType,
+			Archived:      applicationsArchived(applicant.Applications),
 		}
 
 		docsToBleveIndex[applicant.ID.String()] = bleveApplicantDoc
@@ -93,6 +97,7 @@ func (r *BleveRepository) IndexExistingApplicants(applicants []models.Applicant)
 func (r *BleveRepository) SearchApplicants(
 	queryString string,
 	status string,
+	archived string,
 ) (*bleve.SearchResult, error) {
 	booleanQuery := bleve.NewBooleanQuery()
 
@@ -164,6 +169,8 @@ func (r *BleveRepository) SearchApplicants(
 		finalQuery.AddMust(statusQuery)
 	}
 
+	filterArchived(finalQuery, archived)
+
 	// Configure search request with final query
 	searchRequest := bleve.NewSearchRequest(finalQuery)
 	searchRequest.Size = 20
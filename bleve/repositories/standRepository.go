@@ -6,6 +6,7 @@ import (
 	"town-planning-backend/db/models"
 
 	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search/query"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
@@ -30,6 +31,7 @@ func (r *BleveRepository) IndexSingleStand(stand models.Stand) error {
 		StandType      string               `json:"stand_type,omitempty"`
 		StandCurrency  models.StandCurrency `json:"stand_currency"`
 		IsActive       bool                 `json:"is_active"`
+		Archived       bool                 `json:"archived"`
 	}{
 		ID:             stand.ID.String(),
 		StandNumber:    stand.StandNumber,
@@ -40,6 +42,7 @@ func (r *BleveRepository) IndexSingleStand(stand models.Stand) error {
 		StandType:      getStandTypeName(stand.StandType),
 		StandCurrency:  stand.StandCurrency,
 		IsActive:       stand.IsActive,
+		Archived:       applicationsArchived(stand.Applications),
 	}
 
 	// Index the document into Bleve
@@ -78,6 +81,7 @@ func (r *BleveRepository) IndexExistingStands(stands []models.Stand) error {
 			StandType      string               `json:"stand_type,omitempty"`
 			StandCurrency  models.StandCurrency `json:"stand_currency"`
 			IsActive       bool                 `json:"is_active"`
+			Archived       bool                 `json:"archived"`
 		}{
 			ID:             stand.ID.String(),
 			StandNumber:    stand.StandNumber,
@@ -88,6 +92,7 @@ func (r *BleveRepository) IndexExistingStands(stands []models.Stand) error {
 			StandType:      getStandTypeName(stand.StandType),
 			StandCurrency:  stand.StandCurrency,
 			IsActive:       stand.IsActive,
+			Archived:       applicationsArchived(stand.Applications),
 		}
 
 		docsToBleveIndex[stand.ID.String()] = bleveStandDoc
@@ -116,6 +121,7 @@ func (r *BleveRepository) SearchStands(
 	standType string,
 	active *bool,
 	standCurrency string,
+	archived string,
 ) (*bleve.SearchResult, error) {
 	booleanQuery := bleve.NewBooleanQuery()
 	queryString = strings.TrimSpace(queryString)
@@ -204,6 +210,8 @@ func (r *BleveRepository) SearchStands(
 		finalQuery.AddMust(activeQuery)
 	}
 
+	filterArchived(finalQuery, archived)
+
 	return r.indexer.SearchIndex("stands", finalQuery, 20)
 }
 
@@ -248,6 +256,36 @@ func derefUUID(u *uuid.UUID) string {
 	return ""
 }
 
+// applicationsArchived reports whether every application on a stand or applicant is in cold
+// storage. Those with no applications are never archived.
+func applicationsArchived(applications []models.Application) bool {
+	if len(applications) == 0 {
+		return false
+	}
+	for _, application := range applications {
+		if application.ArchivedAt == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// filterArchived applies the archived search parameter the way the application lists do:
+// "true" for archived only, "include" for both, and otherwise archived records are left out.
+// Documents indexed before the flag existed have no archived field and count as not archived.
+func filterArchived(finalQuery *query.BooleanQuery, archived string) {
+	archivedQuery := bleve.NewBoolFieldQuery(true)
+	archivedQuery.SetField("archived")
+
+	switch archived {
+	case "true":
+		finalQuery.AddMust(archivedQuery)
+	case "include":
+	default:
+		finalQuery.AddMustNot(archivedQuery)
+	}
+}
+
 func getStandTypeName(standType *models.StandType) string {
 	if standType != nil && standType.Name != "" {
 		return standType.Name
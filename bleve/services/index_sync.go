@@ -0,0 +1,349 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	TypeSyncSearchIndex = "search_index:sync"
+
+	indexSyncQueue   = "search_index"
+	indexSyncRetries = 3
+
+	// indexSyncDelay gives the transaction a write was made in time to commit before the task
+	// reloads the records
+	indexSyncDelay = 5 * time.Second
+
+	// indexSyncIDsKey carries the IDs an update or delete will touch from the callback before
+	// the statement to the one after it
+	indexSyncIDsKey = "search_index:ids"
+)
+
+// indexedTables are the tables whose writes re-sync the search indexes. Applications are not
+// indexed themselves; their writes re-sync the stand and applicant they belong to.
+var indexedTables = map[string]bool{
+	"users":        true,
+	"applicants":   true,
+	"stands":       true,
+	"applications": true,
+}
+
+// SearchIndexWriter is the part of the Bleve repository the index sync keeps up to date
+type SearchIndexWriter interface {
+	UpdateUser(user models.User) error
+	DeleteUser(userID string) error
+	UpdateApplicant(applicant models.Applicant) error
+	DeleteApplicant(applicantID string) error
+	UpdateStand(stand models.Stand) error
+	DeleteStand(standID string) error
+}
+
+type indexSyncPayload struct {
+	Table string      `json:"table"`
+	IDs   []uuid.UUID `json:"ids"`
+}
+
+// IndexSyncService keeps the Bleve indexes in step with the database whichever repository made
+// the write. GORM callbacks queue a task for every create, update and delete on an indexed
+// table, and the task reloads the records and re-indexes them, or removes them once deleted.
+// Writes made with raw SQL bypass the callbacks and still need an explicit re-index.
+type IndexSyncService struct {
+	db      *gorm.DB
+	indexes SearchIndexWriter
+	queue   *asynq.Client
+}
+
+func NewIndexSyncService(db *gorm.DB, indexes SearchIndexWriter, queue *asynq.Client) *IndexSyncService {
+	return &IndexSyncService{db: db, indexes: indexes, queue: queue}
+}
+
+// RegisterCallbacks hooks the sync into every create, update and delete made through db
+func (s *IndexSyncService) RegisterCallbacks(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Update().Before("gorm:update").Register("search_index:collect_update", s.collectAffectedIDs); err != nil {
+		return fmt.Errorf("failed to register search index update callback: %w", err)
+	}
+	if err := callbacks.Delete().Before("gorm:delete").Register("search_index:collect_delete", s.collectAffectedIDs); err != nil {
+		return fmt.Errorf("failed to register search index delete callback: %w", err)
+	}
+	if err := callbacks.Create().After("gorm:create").Register("search_index:sync_create", s.queueSync); err != nil {
+		return fmt.Errorf("failed to register search index create callback: %w", err)
+	}
+	if err := callbacks.Update().After("gorm:update").Register("search_index:sync_update", s.queueSync); err != nil {
+		return fmt.Errorf("failed to register search index update callback: %w", err)
+	}
+	if err := callbacks.Delete().After("gorm:delete").Register("search_index:sync_delete", s.queueSync); err != nil {
+		return fmt.Errorf("failed to register search index delete callback: %w", err)
+	}
+	return nil
+}
+
+// collectAffectedIDs records which rows an update or delete will touch. Writes on a model
+// without its ID set, e.g. Model(&models.Stand{}).Where(...).Updates(...), are resolved by
+// selecting the IDs matching the statement's conditions before it runs.
+func (s *IndexSyncService) collectAffectedIDs(tx *gorm.DB) {
+	if !isIndexed(tx) {
+		return
+	}
+
+	ids := modelIDs(tx)
+	if len(ids) == 0 {
+		where, ok := tx.Statement.Clauses["WHERE"]
+		if !ok || where.Expression == nil {
+			return
+		}
+		if err := tx.Session(&gorm.Session{NewDB: true}).
+			Table(tx.Statement.Table).
+			Clauses(where.Expression).
+			Pluck(tx.Statement.Schema.PrioritizedPrimaryField.DBName, &ids).Error; err != nil {
+			config.Logger.Warn("Failed to resolve rows for search index sync",
+				zap.String("table", tx.Statement.Table),
+				zap.Error(err))
+			return
+		}
+	}
+	tx.InstanceSet(indexSyncIDsKey, ids)
+}
+
+// queueSync queues a search index sync for the rows a successful write touched
+func (s *IndexSyncService) queueSync(tx *gorm.DB) {
+	if tx.Error != nil || tx.DryRun || !isIndexed(tx) {
+		return
+	}
+
+	ids := modelIDs(tx)
+	if collected, ok := tx.InstanceGet(indexSyncIDsKey); ok {
+		ids = append(ids, collected.([]uuid.UUID)...)
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	s.Enqueue(tx.Statement.Context, tx.Statement.Table, ids)
+}
+
+// Enqueue queues a sync of the rows of an indexed table. Without a queue, or when queueing
+// fails, the rows are synced in the background after indexSyncDelay instead.
+func (s *IndexSyncService) Enqueue(ctx context.Context, table string, ids []uuid.UUID) {
+	syncLater := func() {
+		time.AfterFunc(indexSyncDelay, func() {
+			if err := s.Sync(context.Background(), table, ids); err != nil {
+				config.Logger.Error("Failed to sync search index",
+					zap.String("table", table),
+					zap.Error(err))
+			}
+		})
+	}
+
+	if s.queue == nil {
+		syncLater()
+		return
+	}
+
+	payload, err := json.Marshal(indexSyncPayload{Table: table, IDs: ids})
+	if err != nil {
+		config.Logger.Error("Failed to encode search index sync task", zap.Error(err))
+		return
+	}
+	task := asynq.NewTask(TypeSyncSearchIndex, payload,
+		asynq.Queue(indexSyncQueue),
+		asynq.MaxRetry(indexSyncRetries),
+		asynq.ProcessIn(indexSyncDelay),
+		asynq.Timeout(time.Minute))
+	if _, err := s.queue.EnqueueContext(ctx, task); err != nil {
+		config.Logger.Warn("Failed to queue search index sync, syncing in the background",
+			zap.String("table", table),
+			zap.Int("count", len(ids)),
+			zap.Error(err))
+		syncLater()
+	}
+}
+
+// HandleSyncTask is the queue worker for TypeSyncSearchIndex
+func (s *IndexSyncService) HandleSyncTask(ctx context.Context, task *asynq.Task) error {
+	var payload indexSyncPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("invalid search index task payload: %v: %w", err, asynq.SkipRetry)
+	}
+	return s.Sync(ctx, payload.Table, payload.IDs)
+}
+
+// Sync re-indexes the rows of an indexed table as they are now, removing those that no longer
+// exist. Every row is synced even if an earlier one failed; the failures are returned together.
+func (s *IndexSyncService) Sync(ctx context.Context, table string, ids []uuid.UUID) error {
+	var failures []error
+	for _, id := range ids {
+		var err error
+		switch table {
+		case "users":
+			err = s.syncUser(ctx, id)
+		case "applicants":
+			err = s.syncApplicant(ctx, id)
+		case "stands":
+			err = s.syncStand(ctx, id)
+		case "applications":
+			err = s.syncApplication(ctx, id)
+		default:
+			return fmt.Errorf("table %s is not indexed: %w", table, asynq.SkipRetry)
+		}
+		if err != nil {
+			failures = append(failures, fmt.Errorf("%s %s: %w", table, id, err))
+		}
+	}
+	return errors.Join(failures...)
+}
+
+func (s *IndexSyncService) syncUser(ctx context.Context, id uuid.UUID) error {
+	var user models.User
+	err := s.db.WithContext(ctx).Where("id = ?", id).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return s.indexes.DeleteUser(id.String())
+	}
+	if err != nil {
+		return err
+	}
+	return s.indexes.UpdateUser(user)
+}
+
+// syncApplicant also re-syncs the stands the applicant owns, which are indexed under the
+// owner's name
+func (s *IndexSyncService) syncApplicant(ctx context.Context, id uuid.UUID) error {
+	var applicant models.Applicant
+	err := s.db.WithContext(ctx).
+		Preload("Applications", selectArchivedAt("applicant_id")).
+		Where("id = ?", id).
+		First(&applicant).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		err = s.indexes.DeleteApplicant(id.String())
+	case err == nil:
+		err = s.indexes.UpdateApplicant(applicant)
+	}
+	if err != nil {
+		return err
+	}
+
+	var standIDs []uuid.UUID
+	if err := s.db.WithContext(ctx).Model(&models.Stand{}).
+		Where("current_owner_id = ?", id).
+		Pluck("id", &standIDs).Error; err != nil {
+		return err
+	}
+	for _, standID := range standIDs {
+		if err := s.syncStand(ctx, standID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *IndexSyncService) syncStand(ctx context.Context, id uuid.UUID) error {
+	var stand models.Stand
+	err := s.db.WithContext(ctx).
+		Preload("CurrentOwner").
+		Preload("StandType").
+		Preload("Applications", selectArchivedAt("stand_id")).
+		Where("id = ?", id).
+		First(&stand).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return s.indexes.DeleteStand(id.String())
+	}
+	if err != nil {
+		return err
+	}
+	return s.indexes.UpdateStand(stand)
+}
+
+// syncApplication re-syncs the stand and applicant of an application, which approval and
+// allocation workflows update alongside it, as does archiving. Deleted applications are included.
+func (s *IndexSyncService) syncApplication(ctx context.Context, id uuid.UUID) error {
+	var application models.Application
+	err := s.db.WithContext(ctx).Unscoped().
+		Select("id", "stand_id", "applicant_id").
+		Where("id = ?", id).
+		First(&application).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if application.StandID != nil {
+		if err := s.syncStand(ctx, *application.StandID); err != nil {
+			return err
+		}
+	}
+	return s.syncApplicant(ctx, application.ApplicantID)
+}
+
+// StartIndexSyncWorker runs the queue worker keeping the search indexes in step with the
+// database. Tasks run one at a time since Bleve serialises index writes anyway.
+func StartIndexSyncWorker(redisOpt asynq.RedisConnOpt, service *IndexSyncService) (*asynq.Server, error) {
+	server := asynq.NewServer(redisOpt, asynq.Config{
+		Concurrency: 1,
+		Queues:      map[string]int{indexSyncQueue: 1},
+	})
+
+	mux := asynq.NewServeMux()
+	mux.HandleFunc(TypeSyncSearchIndex, service.HandleSyncTask)
+	if err := server.Start(mux); err != nil {
+		return nil, fmt.Errorf("failed to start search index worker: %w", err)
+	}
+	return server, nil
+}
+
+// selectArchivedAt loads only what the index needs of a stand's or applicant's applications to
+// tell whether they are all archived
+func selectArchivedAt(foreignKey string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", foreignKey, "archived_at")
+	}
+}
+
+func isIndexed(tx *gorm.DB) bool {
+	return tx.Statement.Schema != nil &&
+		tx.Statement.Schema.PrioritizedPrimaryField != nil &&
+		indexedTables[tx.Statement.Table]
+}
+
+// modelIDs returns the IDs set on the model or models a statement was given
+func modelIDs(tx *gorm.DB) []uuid.UUID {
+	field := tx.Statement.Schema.PrioritizedPrimaryField
+	ctx := tx.Statement.Context
+
+	var ids []uuid.UUID
+	add := func(value reflect.Value) {
+		if value.Kind() != reflect.Struct {
+			return
+		}
+		if raw, zero := field.ValueOf(ctx, value); !zero {
+			if id, ok := raw.(uuid.UUID); ok {
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	value := tx.Statement.ReflectValue
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			add(reflect.Indirect(value.Index(i)))
+		}
+	case reflect.Struct:
+		add(value)
+	}
+	return ids
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/blevesearch/bleve/v2"
 	"github.com/blevesearch/bleve/v2/search/query"
@@ -25,6 +26,7 @@ type IndexingServiceInterface interface {
 }
 
 type IndexingService struct {
+	mu       sync.Mutex // Guards indexes; the search index worker writes alongside requests
 	indexes  map[string]bleve.Index
 	logger   *zap.Logger
 	basePath string
@@ -43,6 +45,9 @@ func (s *IndexingService) GetIndex(indexName string) (bleve.Index, error) {
 }
 
 func (s *IndexingService) getOrCreateIndex(indexName string) (bleve.Index, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if idx, ok := s.indexes[indexName]; ok {
 		return idx, nil
 	}
@@ -188,6 +193,9 @@ func (s *IndexingService) GetDocument(indexName, id string) (interface{}, error)
 }
 
 func (s *IndexingService) DeleteIndex(indexName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	idx, exists := s.indexes[indexName]
 	if !exists {
 		return fmt.Errorf("index %s not found in memory", indexName)
@@ -232,10 +240,12 @@ func (s *IndexingService) IndexExists(indexName string) (bool, error) {
 
 func (s *IndexingService) DeleteAllIndices() error {
 	// Get list of known indices from your service
+	s.mu.Lock()
 	knownIndices := make([]string, 0, len(s.indexes))
 	for indexName := range s.indexes {
 		knownIndices = append(knownIndices, indexName)
 	}
+	s.mu.Unlock()
 
 	var errorsOccurred []error
 	var successCount int
@@ -259,7 +269,10 @@ func (s *IndexingService) DeleteAllIndices() error {
 
 	for _, file := range files {
 		indexName := strings.TrimSuffix(filepath.Base(file), ".bleve")
-		if _, exists := s.indexes[indexName]; !exists {
+		s.mu.Lock()
+		_, exists := s.indexes[indexName]
+		s.mu.Unlock()
+		if !exists {
 			if err := os.RemoveAll(file); err != nil {
 				errorsOccurred = append(errorsOccurred, err)
 				continue
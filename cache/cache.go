@@ -0,0 +1,258 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"town-planning-backend/config"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// Entity identifies a group of cached repository reads. Each entity can be disabled on its own.
+type Entity string
+
+const (
+	EntityUser             Entity = "users"
+	EntityRolePermissions  Entity = "role_permissions"
+	EntityDocumentCategory Entity = "document_categories"
+	EntityApprovalGroup    Entity = "approval_groups"
+	EntitySetting          Entity = "settings"
+)
+
+const (
+	defaultTTL            = 10 * time.Minute
+	keyPrefix             = "repo_cache"
+	redisOperationTimeout = 2 * time.Second
+)
+
+// AllEntities lists every entity the repository cache knows about
+var AllEntities = []Entity{EntityUser, EntityRolePermissions, EntityDocumentCategory, EntityApprovalGroup, EntitySetting}
+
+// Config controls the repository cache
+type Config struct {
+	Enabled  bool
+	TTL      time.Duration
+	Disabled map[Entity]bool
+}
+
+// LoadConfigFromEnv reads the cache configuration. All variables are optional:
+//
+//	REPO_CACHE_ENABLED=false                       turns the whole cache off
+//	REPO_CACHE_TTL=10m                             time to live for cached entries
+//	REPO_CACHE_DISABLED_ENTITIES=users,approval_groups
+func LoadConfigFromEnv() Config {
+	cfg := Config{
+		Enabled:  true,
+		TTL:      defaultTTL,
+		Disabled: map[Entity]bool{},
+	}
+
+	if raw := os.Getenv("REPO_CACHE_ENABLED"); raw != "" {
+		cfg.Enabled = strings.EqualFold(raw, "true") || raw == "1"
+	}
+
+	if raw := os.Getenv("REPO_CACHE_TTL"); raw != "" {
+		ttl, err := time.ParseDuration(raw)
+		if err != nil || ttl <= 0 {
+			config.Logger.Warn("Invalid REPO_CACHE_TTL, using default",
+				zap.String("value", raw),
+				zap.Duration("default", defaultTTL))
+		} else {
+			cfg.TTL = ttl
+		}
+	}
+
+	for _, name := range strings.Split(os.Getenv("REPO_CACHE_DISABLED_ENTITIES"), ",") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			cfg.Disabled[Entity(name)] = true
+		}
+	}
+
+	return cfg
+}
+
+// RepositoryCache stores repository read results in Redis, gob-encoded so that fields hidden
+// from JSON survive the round trip. Never cache values holding credentials such as password
+// hashes or TOTP secrets.
+type RepositoryCache struct {
+	client  *redis.Client
+	config  Config
+	metrics *metrics
+}
+
+func NewRepositoryCache(client *redis.Client, cfg Config) *RepositoryCache {
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultTTL
+	}
+	if cfg.Disabled == nil {
+		cfg.Disabled = map[Entity]bool{}
+	}
+	return &RepositoryCache{
+		client:  client,
+		config:  cfg,
+		metrics: newMetrics(),
+	}
+}
+
+// Enabled reports whether reads for the entity should go through the cache
+func (rc *RepositoryCache) Enabled(entity Entity) bool {
+	return rc != nil && rc.client != nil && rc.config.Enabled && !rc.config.Disabled[entity]
+}
+
+func key(entity Entity, id string) string {
+	return fmt.Sprintf("%s:%s:%s", keyPrefix, entity, id)
+}
+
+// Get loads a cached value into dest and reports whether it was found
+func (rc *RepositoryCache) Get(entity Entity, id string, dest interface{}) bool {
+	if !rc.Enabled(entity) {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisOperationTimeout)
+	defer cancel()
+
+	data, err := rc.client.Get(ctx, key(entity, id)).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			rc.metrics.recordError(entity)
+			config.Logger.Warn("Repository cache read failed",
+				zap.String("entity", string(entity)),
+				zap.String("id", id),
+				zap.Error(err))
+		}
+		rc.metrics.recordMiss(entity)
+		return false
+	}
+
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(dest); err != nil {
+		// A stale or incompatible entry is treated as a miss and dropped
+		rc.metrics.recordError(entity)
+		rc.metrics.recordMiss(entity)
+		rc.Invalidate(entity, id)
+		return false
+	}
+
+	rc.metrics.recordHit(entity)
+	return true
+}
+
+// Set stores a value for the entity. Failures are logged and otherwise ignored.
+func (rc *RepositoryCache) Set(entity Entity, id string, value interface{}) {
+	if !rc.Enabled(entity) {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(value); err != nil {
+		rc.metrics.recordError(entity)
+		config.Logger.Warn("Repository cache encode failed",
+			zap.String("entity", string(entity)),
+			zap.String("id", id),
+			zap.Error(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisOperationTimeout)
+	defer cancel()
+
+	if err := rc.client.Set(ctx, key(entity, id), buf.Bytes(), rc.config.TTL).Err(); err != nil {
+		rc.metrics.recordError(entity)
+		config.Logger.Warn("Repository cache write failed",
+			zap.String("entity", string(entity)),
+			zap.String("id", id),
+			zap.Error(err))
+	}
+}
+
+// Invalidate removes cached entries for the given ids. It runs even when the entity is
+// disabled so that re-enabling the cache never serves entries written before it was turned off.
+func (rc *RepositoryCache) Invalidate(entity Entity, ids ...string) {
+	if rc == nil || rc.client == nil || len(ids) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, key(entity, id))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisOperationTimeout)
+	defer cancel()
+
+	if err := rc.client.Del(ctx, keys...).Err(); err != nil {
+		rc.metrics.recordError(entity)
+		config.Logger.Error("Repository cache invalidation failed",
+			zap.String("entity", string(entity)),
+			zap.Strings("ids", ids),
+			zap.Error(err))
+		return
+	}
+	rc.metrics.recordInvalidation(entity, len(ids))
+}
+
+// InvalidateAll removes every cached entry of an entity
+func (rc *RepositoryCache) InvalidateAll(entity Entity) {
+	if rc == nil || rc.client == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*redisOperationTimeout)
+	defer cancel()
+
+	var removed int
+	iter := rc.client.Scan(ctx, 0, key(entity, "*"), 100).Iterator()
+	for iter.Next(ctx) {
+		if err := rc.client.Del(ctx, iter.Val()).Err(); err != nil {
+			rc.metrics.recordError(entity)
+			config.Logger.Error("Repository cache invalidation failed",
+				zap.String("entity", string(entity)),
+				zap.String("key", iter.Val()),
+				zap.Error(err))
+			continue
+		}
+		removed++
+	}
+	if err := iter.Err(); err != nil {
+		rc.metrics.recordError(entity)
+		config.Logger.Error("Repository cache scan failed",
+			zap.String("entity", string(entity)),
+			zap.Error(err))
+	}
+	rc.metrics.recordInvalidation(entity, removed)
+}
+
+// ===========================================================================
+// Process-wide instance used by write paths that bypass the cached repositories
+// (transaction-bound repositories, raw GORM updates).
+// ===========================================================================
+
+var shared *RepositoryCache
+
+// Init sets the process-wide cache. Until it is called the helpers below do nothing.
+func Init(rc *RepositoryCache) {
+	shared = rc
+}
+
+// Shared returns the process-wide cache, or nil before Init
+func Shared() *RepositoryCache {
+	return shared
+}
+
+// Invalidate removes entries from the process-wide cache
+func Invalidate(entity Entity, ids ...string) {
+	shared.Invalidate(entity, ids...)
+}
+
+// InvalidateAll removes every entry of an entity from the process-wide cache
+func InvalidateAll(entity Entity) {
+	shared.InvalidateAll(entity)
+}
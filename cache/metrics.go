@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type entityCounters struct {
+	hits          atomic.Int64
+	misses        atomic.Int64
+	errors        atomic.Int64
+	invalidations atomic.Int64
+}
+
+type metrics struct {
+	mu       sync.RWMutex
+	counters map[Entity]*entityCounters
+}
+
+func newMetrics() *metrics {
+	m := &metrics{counters: make(map[Entity]*entityCounters, len(AllEntities))}
+	for _, entity := range AllEntities {
+		m.counters[entity] = &entityCounters{}
+	}
+	return m
+}
+
+func (m *metrics) get(entity Entity) *entityCounters {
+	m.mu.RLock()
+	counters, ok := m.counters[entity]
+	m.mu.RUnlock()
+	if ok {
+		return counters
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if counters, ok = m.counters[entity]; !ok {
+		counters = &entityCounters{}
+		m.counters[entity] = counters
+	}
+	return counters
+}
+
+func (m *metrics) recordHit(entity Entity) {
+	m.get(entity).hits.Add(1)
+}
+
+func (m *metrics) recordMiss(entity Entity) {
+	m.get(entity).misses.Add(1)
+}
+
+func (m *metrics) recordError(entity Entity) {
+	m.get(entity).errors.Add(1)
+}
+
+func (m *metrics) recordInvalidation(entity Entity, count int) {
+	m.get(entity).invalidations.Add(int64(count))
+}
+
+// EntityStats is a snapshot of the counters for one entity since process start
+type EntityStats struct {
+	Entity        Entity  `json:"entity"`
+	Enabled       bool    `json:"enabled"`
+	Hits          int64   `json:"hits"`
+	Misses        int64   `json:"misses"`
+	Errors        int64   `json:"errors"`
+	Invalidations int64   `json:"invalidations"`
+	HitRate       float64 `json:"hit_rate"`
+}
+
+// Stats returns hit-rate counters for every known entity
+func (rc *RepositoryCache) Stats() []EntityStats {
+	if rc == nil {
+		return []EntityStats{}
+	}
+
+	stats := make([]EntityStats, 0, len(AllEntities))
+	for _, entity := range AllEntities {
+		counters := rc.metrics.get(entity)
+		s := EntityStats{
+			Entity:        entity,
+			Enabled:       rc.Enabled(entity),
+			Hits:          counters.hits.Load(),
+			Misses:        counters.misses.Load(),
+			Errors:        counters.errors.Load(),
+			Invalidations: counters.invalidations.Load(),
+		}
+		if total := s.Hits + s.Misses; total > 0 {
+			s.HitRate = float64(s.Hits) / float64(total)
+		}
+		stats = append(stats, s)
+	}
+	return stats
+}
+
+// StatsHandler serves the cache counters for monitoring
+func (rc *RepositoryCache) StatsHandler(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Repository cache statistics retrieved successfully",
+		"data":    rc.Stats(),
+	})
+}
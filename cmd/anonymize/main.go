@@ -0,0 +1,95 @@
+// Command anonymize fills a developer database with an anonymized copy of another database, so
+// workflow bugs can be reproduced against production-shaped data without applicant data. The
+// target is the database configured in .env, which must not be production; its data is
+// replaced. Names, emails, phone numbers, ID numbers and addresses are pseudonymized, messages
+// redacted, secrets cleared and documents replaced with a placeholder before anything is
+// committed. --demo then seeds the demo accounts, since cloned staff cannot sign in.
+//
+//	go run ./cmd/anonymize --source "host=replica user=readonly dbname=town_planning sslmode=require"
+//	go run ./cmd/anonymize --in-place --demo
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	config "town-planning-backend/config"
+	"town-planning-backend/seeds"
+	"town-planning-backend/staging/services"
+	"town-planning-backend/utils"
+
+	"github.com/joho/godotenv"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func main() {
+	var source string
+	var inPlace, demo bool
+	flag.StringVar(&source, "source", os.Getenv("ANONYMIZE_SOURCE_DSN"), "DSN of the database to copy, ideally a read replica (default $ANONYMIZE_SOURCE_DSN)")
+	flag.BoolVar(&inPlace, "in-place", false, "anonymize the configured database itself, e.g. after restoring a backup into it")
+	flag.BoolVar(&demo, "demo", false, "seed roles and demo accounts afterwards")
+	flag.Parse()
+
+	config.InitLogger()
+
+	if err := godotenv.Load(".env"); err != nil {
+		config.Logger.Warn("No .env file loaded, using process environment", zap.Error(err))
+	}
+	if source == "" {
+		source = os.Getenv("ANONYMIZE_SOURCE_DSN")
+	}
+	if (source == "") == !inPlace {
+		fmt.Fprintln(os.Stderr, "anonymize: give either --source or --in-place")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	db := config.ConfigureDatabase()
+	service := services.NewAnonymizeService(db, nil, utils.NewLocalFileStorage("./uploads"), nil, nil, nil, nil)
+	if !service.Enabled() {
+		fmt.Fprintf(os.Stderr, "anonymize: %v\n", services.ErrAnonymizeInProduction)
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+	var result *services.AnonymizeResult
+	var err error
+	if inPlace {
+		result, err = service.AnonymizeInPlace(ctx, "cli")
+	} else {
+		sourceDB, openErr := gorm.Open(postgres.Open(source), &gorm.Config{})
+		if openErr != nil {
+			fmt.Fprintf(os.Stderr, "anonymize: failed to connect to source: %v\n", openErr)
+			os.Exit(1)
+		}
+		result, err = service.CloneFrom(ctx, sourceDB, "cli")
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "anonymize: %v\n", err)
+		os.Exit(1)
+	}
+
+	if demo {
+		opts := seeds.NewOptions()
+		opts.Roles = true
+		opts.Demo = true
+		report := &seeds.Report{}
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			return seeds.SeedTownPlanningAll(tx, opts, report)
+		}); err != nil {
+			fmt.Fprintf(os.Stderr, "anonymize: data anonymized but demo seeding failed: %v\n", err)
+			os.Exit(1)
+		}
+		seeds.InvalidateSeededCaches()
+		report.Write(os.Stdout)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(result)
+}
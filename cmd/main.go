@@ -2,29 +2,39 @@ package main
 
 import (
 	"context"
-	"log"
 
+	"town-planning-backend/cache"
 	config "town-planning-backend/config"
+	"town-planning-backend/settings"
 	"town-planning-backend/token"
 	"town-planning-backend/utils"
 
-	// "town-planning-backend/seeds"
-
 	"town-planning-backend/middleware"
 
 	// Repositories
 
+	address_repositories "town-planning-backend/addresses/repositories"
 	applicants_repositories "town-planning-backend/applicants/repositories"
 	applications_repositories "town-planning-backend/applications/repositories"
 	applications_services "town-planning-backend/applications/services"
+	address_services "town-planning-backend/addresses/services"
 	document_repositories "town-planning-backend/documents/repositories"
+	inspections_repositories "town-planning-backend/inspections/repositories"
+	planningscheme_repositories "town-planning-backend/planningschemes/repositories"
+	reports_repositories "town-planning-backend/reports/repositories"
+	settings_repositories "town-planning-backend/settings/repositories"
 	stands_repositories "town-planning-backend/stands/repositories"
 	users_repositories "town-planning-backend/users/repositories"
 
 	// Routes
 
+	address_routes "town-planning-backend/addresses/routes"
 	applicant_routes "town-planning-backend/applicants/routes"
 	application_routes "town-planning-backend/applications/routes"
+	inspection_routes "town-planning-backend/inspections/routes"
+	planningscheme_routes "town-planning-backend/planningschemes/routes"
+	report_routes "town-planning-backend/reports/routes"
+	settings_routes "town-planning-backend/settings/routes"
 	stand_routes "town-planning-backend/stands/routes"
 	user_routes "town-planning-backend/users/routes"
 
@@ -35,10 +45,22 @@ import (
 	bleveServices "town-planning-backend/bleve/services"
 
 	// documents
+	document_routes "town-planning-backend/documents/routes"
 	document_services "town-planning-backend/documents/services"
-	// services
 
-	// seeds "town-planning-backend/seeds"
+	// emails
+	email_routes "town-planning-backend/emails/routes"
+	email_services "town-planning-backend/emails/services"
+
+	// sms
+	sms_routes "town-planning-backend/sms/routes"
+	sms_services "town-planning-backend/sms/services"
+
+	// staging
+	staging_routes "town-planning-backend/staging/routes"
+	staging_services "town-planning-backend/staging/services"
+
+	// services
 
 	// WebSocket
 	"town-planning-backend/websocket"
@@ -47,6 +69,10 @@ import (
 
 	// Other imports
 	"encoding/gob"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/hibiken/asynq"
@@ -68,13 +94,30 @@ func main() {
 	}
 	gob.Register(uuid.UUID{})
 
-	app := fiber.New()
+	// Request timeouts and body limits. Fiber refuses bodies over BodyLimit before any
+	// middleware runs, so it is the upload limit; other requests are held to less.
+	requestLimits := middleware.LoadRequestLimits()
+	app := fiber.New(fiber.Config{
+		BodyLimit:   requestLimits.UploadBodyLimit,
+		ReadTimeout: requestLimits.ReadTimeout,
+	})
 
 	// Apply CORS middleware from middleware package
 	middleware.InitCors(app)
+	middleware.InitRequestLimits(app, requestLimits)
 
 	// Initialize database and configs
 	db := config.ConfigureDatabase()
+
+	// Convert plain-text chat system messages from before localization into structured events
+	if _, err := applications_services.MigrateLegacySystemMessages(db); err != nil {
+		config.Logger.Error("Failed to migrate legacy chat system messages", zap.Error(err))
+	}
+
+	// Seed per-participant thread state from existing read receipts
+	if _, err := applications_services.BackfillParticipantThreadStates(db); err != nil {
+		config.Logger.Error("Failed to backfill participant thread states", zap.Error(err))
+	}
 	port := config.GetEnv("PORT")
 	ctx := context.Background()
 
@@ -88,6 +131,14 @@ func main() {
 	redisClient := config.InitRedisServer(ctx) // Assuming this gives you a *redis.Client or similar
 	// Note: asynq.RedisClientOpt uses its own Redis client internally.
 
+	// Redis cache for hot repository reads (users, role permissions, document categories, approval groups, settings)
+	repoCache := cache.NewRepositoryCache(redisClient, cache.LoadConfigFromEnv())
+	cache.Init(repoCache)
+
+	// Council-wide settings changed by administrators, read through the repository cache
+	settingsRepo := settings_repositories.NewSettingsRepository(db, repoCache)
+	settings.Init(settingsRepo)
+
 	asynqRedisOpt := asynq.RedisClientOpt{
 		Addr:     redisAddr,
 		Password: "", // Or config.GetEnv("REDIS_PASSWORD") if needed
@@ -132,14 +183,37 @@ func main() {
 	// 	log.Fatal("Failed to create Cloudflare service:", err)
 	// }
 
-	// Initialize the mailer
-	utils.InitializeMailer()
+	// Initialize the mailer: emails are logged, queued on Asynq and sent by the email worker
+	emailProvider, err := email_services.NewProviderFromEnv()
+	if err != nil {
+		config.Logger.Fatal("Mailer not initialized", zap.Error(err))
+	}
+	emailService := email_services.NewEmailService(db, emailProvider, asynqClient, baseURL, tokenKey)
+	email_services.Init(emailService)
 
-	// Now you can use the mailer globally
-	mailer := utils.GetMailer()
-	if mailer == nil {
-		config.Logger.Fatal("Mailer not initialized")
-		log.Fatalf("Mailer not initialized")
+	emailWorker, err := email_services.StartEmailWorker(asynqRedisOpt, emailService)
+	if err != nil {
+		config.Logger.Fatal("Failed to start email worker", zap.Error(err))
+	}
+	defer emailWorker.Shutdown()
+	config.Logger.Info("Mailer initialized successfully", zap.String("provider", emailProvider.Name()))
+
+	// Text messages go through the SMS_PROVIDERS in order, falling back to the next on failure
+	smsProviders, err := sms_services.NewProvidersFromEnv()
+	if err != nil {
+		config.Logger.Fatal("SMS not initialized", zap.Error(err))
+	}
+	smsService := sms_services.NewSMSService(db, smsProviders, asynqClient, baseURL, config.GetEnv("SMS_WEBHOOK_TOKEN"))
+	sms_services.Init(smsService)
+	if smsService.Enabled() {
+		smsWorker, err := sms_services.StartSMSWorker(asynqRedisOpt, smsService)
+		if err != nil {
+			config.Logger.Fatal("Failed to start SMS worker", zap.Error(err))
+		}
+		defer smsWorker.Shutdown()
+		config.Logger.Info("SMS initialized successfully", zap.Strings("providers", smsService.ProviderNames()))
+	} else {
+		config.Logger.Warn("SMS_PROVIDERS not set, applicants will not be sent text messages")
 	}
 
 	// ------ WebSocket Hub Initialization for Real-time Chat ------
@@ -154,23 +228,66 @@ func main() {
 	// Repositories
 	bleveIndexingService := bleveServices.NewIndexingService(config.Logger, indexPath)
 	standRepo := stands_repositories.NewStandRepository(db)
-	userRepo := users_repositories.NewUserRepository(db)
+	userRepo := users_repositories.NewCachedUserRepository(users_repositories.NewUserRepository(db), repoCache)
 	applicantRepo := applicants_repositories.NewApplicantRepository(db)
 	bleveServiceRepo, bleveInterfaceRepo := bleveRepositories.NewBleveRepository(bleveIndexingService)
-	documentRepo := document_repositories.NewDocumentRepository(db, standRepo)
+
+	// Re-index users, applicants and stands whichever repository writes them
+	indexSyncService := bleveServices.NewIndexSyncService(db, bleveInterfaceRepo, asynqClient)
+	if err := indexSyncService.RegisterCallbacks(db); err != nil {
+		config.Logger.Fatal("Failed to register search index callbacks", zap.Error(err))
+	}
+	indexSyncWorker, err := bleveServices.StartIndexSyncWorker(asynqRedisOpt, indexSyncService)
+	if err != nil {
+		config.Logger.Fatal("Failed to start search index worker", zap.Error(err))
+	}
+	defer indexSyncWorker.Shutdown()
+
+	documentRepo := document_repositories.NewCachedDocumentRepository(document_repositories.NewDocumentRepository(db, standRepo), repoCache)
 	readReceiptService := applications_services.NewReadReceiptService(db)
+	inspectionRepo := inspections_repositories.NewInspectionRepository(db)
+	planningSchemeRepo := planningscheme_repositories.NewPlanningSchemeRepository(db)
+	nationalReportRepo := reports_repositories.NewNationalReportRepository(db)
+	funnelReportRepo := reports_repositories.NewFunnelReportRepository(db)
+	integrityReportRepo := reports_repositories.NewIntegrityReportRepository(db)
 
 	// Services
 	fileStorage := utils.NewLocalFileStorage("./uploads")
 	documentService := document_services.NewDocumentService(documentRepo, fileStorage)
-
-	applicationRepo := applications_repositories.NewApplicationRepository(db, documentService)
+	reportStorage := utils.NewLocalFileStorage("./generated-reports") // Not served statically, see ReportJobRepository
+	reportJobRepo := reports_repositories.NewReportJobRepository(db, funnelReportRepo, nationalReportRepo, reportStorage, tokenKey, baseURL)
+	activityReportRepo := reports_repositories.NewActivityReportRepository(db)
+	decisionAuditRepo := reports_repositories.NewDecisionAuditRepository(db)
+	workloadForecastRepo := reports_repositories.NewWorkloadForecastRepository(db)
+	levyBenchmarkRepo := reports_repositories.NewLevyBenchmarkRepository(db)
+	suburbRepo := address_repositories.NewSuburbRepository(db)
+	addressService := address_services.NewAddressService(suburbRepo)
+	stagingResetService := staging_services.NewResetService(db, redisClient, userRepo, applicantRepo, standRepo, bleveInterfaceRepo)
+	anonymizeService := staging_services.NewAnonymizeService(db, redisClient, fileStorage, userRepo, applicantRepo, standRepo, bleveInterfaceRepo)
+
+	applicationRepo := applications_repositories.NewCachedApplicationRepository(
+		applications_repositories.NewApplicationRepository(db, documentService),
+		repoCache,
+	)
 
 	// Routes
 	user_routes.InitRoutes(app, userRepo, ctx, redisClient, tokenMaker, bleveInterfaceRepo, db, baseURL, baseFrontendURL)
-	applicant_routes.ApplicantInitRoutes(app, applicantRepo, bleveInterfaceRepo, db)
-	application_routes.ApplicationRouterInit(app, db, applicationRepo, bleveInterfaceRepo, userRepo, documentService, applicantRepo, wsHub) // Added wsHub
-	stand_routes.StandRouterInit(app, db, standRepo, bleveInterfaceRepo)
+	applicant_routes.ApplicantInitRoutes(app, applicantRepo, bleveInterfaceRepo, db, addressService)
+	applicant_routes.PortalInitRoutes(app, db, applicantRepo, documentService, tokenMaker, redisClient, ctx, baseFrontendURL)
+	application_routes.ApplicationRouterInit(app, db, applicationRepo, bleveInterfaceRepo, userRepo, documentService, applicantRepo, wsHub, redisClient) // Added wsHub
+	stand_routes.StandRouterInit(app, db, standRepo, bleveInterfaceRepo, fileStorage, addressService)
+	inspection_routes.InspectionRouterInit(app, db, inspectionRepo, fileStorage, userRepo)
+	planningscheme_routes.PlanningSchemeRouterInit(app, db, planningSchemeRepo, documentService, userRepo)
+	settings_routes.SettingsRouterInit(app, db, settingsRepo, userRepo)
+	address_routes.AddressRouterInit(app, db, suburbRepo, addressService, userRepo)
+	report_routes.ReportRouterInit(app, db, nationalReportRepo, funnelReportRepo, integrityReportRepo, reportJobRepo, activityReportRepo, decisionAuditRepo, workloadForecastRepo, levyBenchmarkRepo, userRepo)
+	document_routes.DocumentRouterInit(app, db, standRepo, applicantRepo, documentRepo, nil, documentService, userRepo)
+	email_routes.EmailRouterInit(app, emailService, config.GetEnv("EMAIL_WEBHOOK_TOKEN"))
+	sms_routes.SMSRouterInit(app, smsService, config.GetEnv("SMS_WEBHOOK_TOKEN"))
+	staging_routes.StagingRouterInit(app, stagingResetService, anonymizeService, userRepo)
+
+	// Repository cache hit rates, for administrators
+	app.Get("/api/v1/cache/stats", middleware.RequirePermission(userRepo, "settings.manage"), repoCache.StatsHandler)
 
 	// Create WebSocket handler with token validation
 	wsHandler := websocket.NewWsHandler(wsHub, tokenMaker, *readReceiptService)
@@ -179,6 +296,10 @@ func main() {
 	app.Get("/ws", wsHandler.HandleWebSocket)
 	config.Logger.Info("WebSocket endpoint registered at /ws")
 
+	// Fallbacks for networks that block WebSocket upgrades
+	app.Get("/ws/poll", middleware.LongRunning(), wsHandler.HandleLongPoll)
+	app.Get("/ws/events", wsHandler.HandleEventStream)
+
 	// Bleve Routes
 	bleveController := bleveControllers.NewSearchController(bleveServiceRepo)
 	bleveRoutes.InitBleveRoutes(app, bleveController, db)
@@ -188,31 +309,67 @@ func main() {
 		config.Logger.Fatal("Failed to initialize date location", zap.Error(err))
 	}
 
-	// Background cleanup tasks
-	go utils.RunScheduledCleanup(redisClient)
+	// Background cleanup tasks, stopped on shutdown
+	schedulerCtx, stopSchedulers := context.WithCancel(ctx)
+	var schedulers sync.WaitGroup
+	for _, run := range []func(context.Context){
+		func(ctx context.Context) { utils.RunScheduledCleanup(ctx, redisClient) },
+		func(ctx context.Context) { reports_repositories.RunScheduledIntegrityChecks(ctx, integrityReportRepo) },
+		func(ctx context.Context) { reports_repositories.RunScheduledReportCleanup(ctx, reportJobRepo) },
+		func(ctx context.Context) { reports_repositories.RunScheduledActivityDigest(ctx, activityReportRepo) },
+	} {
+		schedulers.Add(1)
+		go func(run func(context.Context)) {
+			defer schedulers.Done()
+			run(schedulerCtx)
+		}(run)
+	}
 
 	// // Re-Index all data
 	// bootstrap.IndexBleveData(ctx, userRepo, applicantRepo, standRepo, bleveInterfaceRepo)
 
-	//------ Run seeders for initial data with proper error handling and logging ------ //
-	// config.Logger.Info("Starting database seeding...")
+	// Seed data with the bootstrap command instead: go run ./cmd/seed --help
 
-	// // Run comprehensive database seeding
-	// config.Logger.Info("Starting comprehensive database seeding process...")
-
-	// if err := seeds.SeedTownPlanningAll(db); err != nil {
-	// 	config.Logger.Error("Database seeding failed", zap.Error(err))
-	// } else {
-	// 	config.Logger.Info("All database seeding completed successfully")
-	// }
+	// Start the application
+	config.Logger.Info("Server starting with WebSocket support", zap.String("port", port))
+	go func() {
+		if err := app.Listen(":" + port); err != nil {
+			config.Logger.Fatal("Server failed", zap.String("port", port), zap.Error(err))
+		}
+	}()
+
+	// Shut down gracefully on SIGTERM: stop accepting requests and let in-flight ones, approval
+	// transactions included, finish before closing WebSocket connections
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGTERM, os.Interrupt)
+	sig := <-quit
+	config.Logger.Info("Shutting down", zap.String("signal", sig.String()), zap.Duration("timeout", requestLimits.ShutdownTimeout))
+
+	// No scheduled job starts from here on; one already running finishes below
+	stopSchedulers()
+
+	if err := app.ShutdownWithTimeout(requestLimits.ShutdownTimeout); err != nil {
+		config.Logger.Error("In-flight requests did not finish before shutdown", zap.Error(err))
+	}
 
-	// config.Logger.Info("Database seeding process finished")
+	shutdownCtx, cancel := context.WithTimeout(ctx, requestLimits.ShutdownTimeout)
+	defer cancel()
+	if err := wsHub.Shutdown(shutdownCtx); err != nil {
+		config.Logger.Warn("WebSocket connections did not close before shutdown", zap.Error(err))
+	}
 
-	//------ Run seeders for initial data with proper error handling and logging ------ //
+	schedulersDone := make(chan struct{})
+	go func() {
+		schedulers.Wait()
+		close(schedulersDone)
+	}()
+	select {
+	case <-schedulersDone:
+	case <-shutdownCtx.Done():
+		config.Logger.Warn("Scheduled jobs did not finish before shutdown")
+	}
 
-	// Start the application
-	config.Logger.Info("Server starting with WebSocket support", zap.String("port", port))
-	config.Logger.Fatal("Server failed", zap.String("port", port), zap.Error(app.Listen(":"+port)))
+	config.Logger.Info("Server stopped")
 }
 
 // // Initialize and start the PaymentCalculationService
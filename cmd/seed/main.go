@@ -0,0 +1,71 @@
+// Command seed bootstraps reference data and accounts. It is safe to run repeatedly: records
+// that already exist are left alone unless --overwrite is given.
+//
+//	go run ./cmd/seed --roles --users
+//	go run ./cmd/seed --roles --demo --tenant=harare.gov.zw
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"town-planning-backend/cache"
+	config "town-planning-backend/config"
+	"town-planning-backend/seeds"
+
+	"github.com/joho/godotenv"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func main() {
+	opts := seeds.NewOptions()
+
+	flag.BoolVar(&opts.Roles, "roles", false, "seed departments, roles, permissions and document categories")
+	flag.BoolVar(&opts.Users, "users", false, "seed staff accounts (magic link sign-in)")
+	flag.BoolVar(&opts.Demo, "demo", false, "seed demo accounts with a shared password (refused in production)")
+	flag.StringVar(&opts.Tenant, "tenant", opts.Tenant, "council email domain for seeded accounts and departments")
+	flag.BoolVar(&opts.Overwrite, "overwrite", false, "update existing records to match the seed data (refused in production)")
+	flag.Parse()
+
+	config.InitLogger()
+
+	if err := godotenv.Load(".env"); err != nil {
+		config.Logger.Warn("No .env file loaded, using process environment", zap.Error(err))
+	}
+	// APP_ENV may come from .env, which is only loaded now
+	if env := os.Getenv("APP_ENV"); env != "" {
+		opts.Environment = env
+	}
+
+	if err := opts.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "seed: %v\n", err)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	db := config.ConfigureDatabase()
+
+	// Role permission changes must reach the API's cache
+	if os.Getenv("REDIS_ADDRESS") != "" {
+		redisClient := config.InitRedisServer(context.Background())
+		cache.Init(cache.NewRepositoryCache(redisClient, cache.LoadConfigFromEnv()))
+	}
+
+	// One transaction so a failed run leaves nothing half seeded
+	report := &seeds.Report{}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return seeds.SeedTownPlanningAll(tx, opts, report)
+	})
+	if err != nil {
+		config.Logger.Error("Database seeding failed", zap.Error(err))
+		fmt.Fprintf(os.Stderr, "seed: %v\n", err)
+		os.Exit(1)
+	}
+	seeds.InvalidateSeededCaches()
+
+	fmt.Printf("Seeded %s (%s)\n", opts.Tenant, opts.Environment)
+	report.Write(os.Stdout)
+}
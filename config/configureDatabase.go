@@ -48,10 +48,55 @@ var allModels = []interface{}{
 
 	// 6a. Payment tracking
 	&models.Payment{},
+	&models.DuplicatePaymentAlert{}, // References Payment and Application
+
+	// 6b. Levy installment plans (references Application and Payment)
+	&models.InstallmentPlan{},
+	&models.Installment{},
+	&models.InstallmentAllocation{},
 
 	// 7. Document models (now all referenced tables exist)
 	&models.Document{},
 	&models.DocumentAuditLog{},
+	&models.DocumentClassificationSuggestion{},
+	&models.FileNamingPolicy{},
+
+	// 7a. Application ownership transfers (references Application, Applicant and Document)
+	&models.ApplicationTransfer{},
+
+	// 7b. Permit collection appointments (references Application and Department)
+	&models.CollectionCalendar{},
+	&models.CollectionAppointment{},
+
+	// 7c. Hard-copy application files (references Application, User and Department)
+	&models.ApplicationPhysicalFile{},
+	&models.PhysicalFileMovement{},
+
+	// 7d. Engineering certificate countersignatures (references Application, Document and User)
+	&models.CertificateCountersignature{},
+
+	// 7e. Stand rates clearance checks (references Application, Stand and User)
+	&models.RatesClearance{},
+
+	// 7e2. Stand ownership checks against the deeds registry (references Application, Stand and Applicant)
+	&models.DeedsVerification{},
+
+	// 7f. Joint owners of an application (references Application, Applicant and Document)
+	&models.ApplicationCoApplicant{},
+
+	// 7g. Council boundary layers and stand geo-validation (references Application, Stand and User)
+	&models.BoundaryLayer{},
+	&models.BoundaryCheck{},
+
+	// 7h. Permit suspensions and revocations (references Permit, User and Document)
+	&models.PermitStatusChange{},
+
+	// 7i. Applicant portal conversations with staff (references Application and Applicant)
+	&models.ApplicantMessage{},
+
+	// 7j. Planning scheme documents and their versions (references Document)
+	&models.PlanningScheme{},
+	&models.PlanningSchemeVersion{},
 
 	// 8. Approval Workflow Models
 	&models.ApprovalGroup{},
@@ -62,13 +107,43 @@ var allModels = []interface{}{
 	&models.FinalApproval{},
 	&models.Comment{},
 	&models.DecisionRevocation{},
+	&models.ConflictOfInterestDeclaration{},
+	&models.ReviewChecklistItem{},   // References ApprovalGroup
+	&models.DecisionChecklistItem{}, // References MemberApprovalDecision and ReviewChecklistItem
+
+	// 8a. Application risk scoring (references Application and ApprovalGroup)
+	&models.RiskScoringProfile{},
+	&models.ApplicationRiskAssessment{},
+
+	// 8b. Post-approval amendments (references Application, FinalApproval, Document and Payment)
+	&models.ApplicationAmendment{},
+
+	// 8c. Committee meeting packs (references Application and User)
+	&models.CommitteePack{},
+
+	// 8d. Cold storage archives (references Application, Document and User)
+	&models.ApplicationArchive{},
+	&models.ApplicationArchiveDocument{},
+
+	// 8e. Application drafts (references User and Application)
+	&models.ApplicationDraft{},
+
+	// 8f. Second opinions from outside the approval group (references Application, ApprovalGroup and User)
+	&models.SecondOpinionRequest{},
+
+	// 8g. Appeals against rejections (references Application, FinalApproval, ApprovalGroup and Payment)
+	&models.ApplicationAppeal{},
+	&models.AppealHearing{},
 
 	// 9. NEW: Chat System Models (MUST come after ApplicationIssue)
-	&models.ChatThread{},      // References ApplicationIssue
-	&models.ChatParticipant{}, // References ChatThread
-	&models.ChatMessage{},     // References ChatThread
-	&models.ReadReceipt{},     // References ChatMessage
-	&models.ChatAttachment{},  // References ChatMessage and Document
+	&models.ChatThread{},             // References ApplicationIssue
+	&models.ChatParticipant{},        // References ChatThread
+	&models.ChatMessage{},            // References ChatThread
+	&models.ReadReceipt{},            // References ChatMessage
+	&models.ChatMessageMention{},     // References ChatMessage and User
+	&models.ParticipantThreadState{}, // References ChatThread and User
+	&models.ChatAttachment{},         // References ChatMessage and Document
+	&models.ScheduledChatMessage{},   // References ChatThread and User
 	&models.MessageStar{},
 	&models.MessageReaction{},
 	&models.TypingIndicator{},
@@ -89,11 +164,71 @@ var allModels = []interface{}{
 	&models.AllStandOwners{},
 	&models.Reservation{},
 	&models.EmailLog{},
+	&models.SMSLog{},
 
 	// 12. Bulk Upload Error Models
 	&models.BulkUploadErrorProjects{},
 	&models.BulkUploadErrorStands{},
 	&models.BulkStandUploadError{},
+
+	// 13. Inspection and Offline Sync Models
+	&models.Inspection{},
+	&models.InspectionChecklistItem{},
+	&models.InspectionPhoto{},
+	&models.SyncMutation{},
+	&models.StandPhoto{}, // References Stand and InspectionPhoto
+
+	// 14. National reporting
+	&models.NationalReportSubmission{},
+
+	// 15. Background report generation (references User)
+	&models.ReportJob{},
+
+	// 16. HR export import runs
+	&models.UserImportRun{},
+
+	// 17. Approver queue filters saved per user
+	&models.SavedQueueFilter{},
+
+	// 18. Decision reminders and escalations (references MemberApprovalDecision and ApplicationIssue)
+	&models.DecisionReminder{},
+
+	// 19. Council-wide settings changed by administrators (references User)
+	&models.Setting{},
+	&models.SettingChange{},
+
+	// 20. Decision audit exports handed to oversight bodies (references User)
+	&models.DecisionAuditExport{},
+
+	// 21. Suburb reference table for address standardization
+	&models.Suburb{},
+
+	// 22. SLA pauses while staff wait on the applicant (references Application and ApplicationIssue)
+	&models.ApplicationSLAPause{},
+
+	// 23. Open Graph previews of links in chat messages (references ChatMessage)
+	&models.LinkPreview{},
+
+	// 24. Per-visit inspection invoices and compliance certificates (references Inspection, Application and Payment)
+	&models.InspectionInvoice{},
+	&models.ComplianceCertificate{},
+
+	// 25. Requests to applicants for outstanding checklist documents (references Application, Applicant and User)
+	&models.DocumentRequest{},
+
+	// 26. Levy and stand market value benchmarks per ward and development category (references DevelopmentCategory)
+	&models.LevyBenchmark{},
+
+	// 27. Pre-screening triage of new submissions (references Application, ApprovalGroup and User)
+	&models.ApplicationPreScreening{},
+
+	// 28. One-off data migrations that have completed
+	&models.DataMigration{},
+}
+
+// MigratedModels returns the models ConfigureDatabase migrates, in migration order
+func MigratedModels() []interface{} {
+	return append([]interface{}(nil), allModels...)
 }
 
 func ConfigureDatabase() *gorm.DB {
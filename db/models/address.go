@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Suburb is an entry of the council's suburb reference table. Applicant and project addresses
+// are checked against it, and the ward of a matched suburb is suggested for the address.
+type Suburb struct {
+	ID             uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	Name           string    `gorm:"type:varchar(100);not null" json:"name"`
+	NormalizedName string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_suburb_city" json:"-"` // Lower case, punctuation removed, for matching
+	City           string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_suburb_city;index" json:"city"`
+	Ward           *string   `gorm:"type:varchar(50);index" json:"ward"`
+	PostalCode     *string   `gorm:"type:varchar(20)" json:"postal_code"`
+	IsActive       bool      `gorm:"default:true;index" json:"is_active"`
+
+	// Audit fields
+	CreatedBy string    `gorm:"not null" json:"created_by"`
+	UpdatedBy *string   `json:"updated_by"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (s *Suburb) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
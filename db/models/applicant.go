@@ -56,6 +56,9 @@ type Applicant struct {
 	Status         ApplicantStatus `json:"status"`
 	Debtor         bool            `gorm:"default:false" json:"debtor"`
 
+	// Language letters and emails are sent in: en, sn or nd
+	PreferredLanguage string `gorm:"type:varchar(10);default:'en'" json:"preferred_language"`
+
 	// Metadata
 	CreatedBy string    `json:"created_by"`
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
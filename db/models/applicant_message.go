@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ApplicantMessageSender says which side of the portal conversation wrote a message
+type ApplicantMessageSender string
+
+const (
+	ApplicantMessageFromApplicant ApplicantMessageSender = "APPLICANT"
+	ApplicantMessageFromStaff     ApplicantMessageSender = "STAFF"
+)
+
+// ApplicantMessage is one message in the conversation between an applicant, through the portal,
+// and council staff about an application. It is kept apart from the staff issue chat so
+// applicants never see internal discussion. SenderName is stored so the applicant sees who
+// replied without staff user records being exposed.
+type ApplicantMessage struct {
+	ID            uuid.UUID              `gorm:"type:uuid;primary_key;" json:"id"`
+	ApplicationID uuid.UUID              `gorm:"type:uuid;not null;index" json:"application_id"`
+	ApplicantID   uuid.UUID              `gorm:"type:uuid;not null;index" json:"applicant_id"`
+	SenderType    ApplicantMessageSender `gorm:"type:varchar(20);not null" json:"sender_type"`
+	StaffUserID   *uuid.UUID             `gorm:"type:uuid;index" json:"-"`
+	SenderName    string                 `gorm:"type:varchar(200);not null" json:"sender_name"`
+	Content       string                 `gorm:"type:text;not null" json:"content"`
+
+	// Set when the other side has read the message
+	ReadAt *time.Time `json:"read_at"`
+
+	// Relationships
+	Application *Application `gorm:"foreignKey:ApplicationID" json:"-"`
+	Applicant   *Applicant   `gorm:"foreignKey:ApplicantID" json:"-"`
+
+	// Audit fields
+	CreatedAt time.Time `gorm:"autoCreateTime;index" json:"created_at"`
+}
+
+func (am *ApplicantMessage) BeforeCreate(tx *gorm.DB) error {
+	if am.ID == uuid.Nil {
+		am.ID = uuid.New()
+	}
+	return nil
+}
@@ -31,6 +31,10 @@ const (
 	DepartmentReviewApplication   ApplicationStatus = "DEPARTMENT_REVIEW"
 	FinalReviewApplication        ApplicationStatus = "FINAL_REVIEW"
 	ReadyForCollectionApplication ApplicationStatus = "READY_FOR_COLLECTION"
+
+	// Pre-screening triage before an approval group is assigned
+	PreScreeningApplication        ApplicationStatus = "PRE_SCREENING"
+	ReturnedToApplicantApplication ApplicationStatus = "RETURNED_TO_APPLICANT"
 )
 
 // DevelopmentCategory model for dynamic development categories
@@ -41,6 +45,9 @@ type DevelopmentCategory struct {
 	IsSystem    bool      `gorm:"default:false" json:"is_system"` // System types cannot be modified
 	IsActive    bool      `gorm:"default:true" json:"is_active"`
 
+	// Points this category adds to an application's risk score
+	RiskPoints int `gorm:"not null;default:0" json:"risk_points"`
+
 	// Audit fields
 	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
@@ -62,9 +69,15 @@ type Permit struct {
 	// Development category this permit was issued for
 	DevelopmentCategoryID uuid.UUID `gorm:"type:uuid;not null;index" json:"development_category_id"`
 
+	// Latest suspension, reinstatement or revocation
+	StatusReason    *string    `gorm:"type:text" json:"status_reason"`
+	StatusChangedAt *time.Time `json:"status_changed_at"`
+	StatusChangedBy *uuid.UUID `gorm:"type:uuid" json:"status_changed_by"`
+
 	// Relationships
-	Application         Application         `gorm:"foreignKey:ApplicationID" json:"application"`
-	DevelopmentCategory DevelopmentCategory `gorm:"foreignKey:DevelopmentCategoryID" json:"development_category"`
+	Application         Application          `gorm:"foreignKey:ApplicationID" json:"application"`
+	DevelopmentCategory DevelopmentCategory  `gorm:"foreignKey:DevelopmentCategoryID" json:"development_category"`
+	StatusChanges       []PermitStatusChange `gorm:"foreignKey:PermitID" json:"status_changes,omitempty"`
 
 	// Audit fields
 	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
@@ -81,7 +94,8 @@ type Tariff struct {
 	PricePerSquareMeter    decimal.Decimal `gorm:"type:decimal(15,2);not null" json:"price_per_square_meter"`
 	PermitFee              decimal.Decimal `gorm:"type:decimal(15,2);not null" json:"permit_fee"`
 	InspectionFee          decimal.Decimal `gorm:"type:decimal(15,2);not null" json:"inspection_fee"`
-	DevelopmentLevyPercent decimal.Decimal `gorm:"type:decimal(15,2);not null" json:"development_levy_percent"` // e.g., 15.00 = 15%
+	DevelopmentLevyPercent decimal.Decimal `gorm:"type:decimal(15,2);not null" json:"development_levy_percent"`        // e.g., 15.00 = 15%
+	AmendmentFeePercent    decimal.Decimal `gorm:"type:decimal(5,2);not null;default:25" json:"amendment_fee_percent"` // Share of the permit and inspection fees charged for a minor amendment
 	ValidFrom              time.Time       `gorm:"not null;index" json:"valid_from"`
 	ValidTo                *time.Time      `gorm:"index" json:"valid_to"` // NULL means currently active
 	IsActive               bool            `gorm:"default:true" json:"is_active"`
@@ -136,6 +150,15 @@ type Application struct {
 	PaymentStatus        PaymentStatus `gorm:"type:varchar(20);default:'PENDING'" json:"payment_status"`
 	AllDocumentsProvided bool          `gorm:"default:false;index" json:"all_documents_provided"`
 	ReadyForReview       bool          `gorm:"default:false;index" json:"ready_for_review"` // Payment complete + docs provided
+	OnInstallmentPlan    bool          `gorm:"default:false" json:"on_installment_plan"`    // Levy is being paid under an active installment plan
+
+	// Set when the stand's coordinates fall outside the council or ward boundaries
+	RequiresBoundaryReview bool `gorm:"default:false;index" json:"requires_boundary_review"`
+
+	// Latest risk assessment, used to prioritise approver queues
+	RiskScore      *int       `gorm:"index" json:"risk_score"`
+	RiskLevel      *RiskLevel `gorm:"type:varchar(10);index" json:"risk_level"`
+	RiskAssessedAt *time.Time `json:"risk_assessed_at"`
 
 	// Application workflow status
 	Status         ApplicationStatus `gorm:"type:varchar(40);default:'SUBMITTED';index" json:"status"`
@@ -154,6 +177,10 @@ type Application struct {
 	IsCollected bool    `gorm:"default:false" json:"is_collected"`
 	CollectedBy *string `json:"collected_by"`
 
+	// Set while the application is in cold storage, see ApplicationArchive. Archived applications
+	// are left out of the default lists until rehydrated.
+	ArchivedAt *time.Time `gorm:"index" json:"archived_at"`
+
 	// Document verification flags
 	ProcessedReceiptProvided                 bool `gorm:"default:false" json:"processed_receipt_provided"`
 	InitialPlanProvided                      bool `gorm:"default:false" json:"initial_plan_provided"`
@@ -161,6 +188,7 @@ type Application struct {
 	ProcessedQuotationProvided               bool `gorm:"default:false" json:"processed_quotation_provided"`
 	StructuralEngineeringCertificateProvided bool `gorm:"default:false" json:"structural_engineering_certificate_provided"`
 	RingBeamCertificateProvided              bool `gorm:"default:false" json:"ring_beam_certificate_provided"`
+	EngineeringCertificateCountersigned      bool `gorm:"default:false" json:"engineering_certificate_countersigned"` // A council engineer countersigned the current certificate
 
 	// Property details
 	PropertyTypeID *uuid.UUID `gorm:"type:uuid;index" json:"property_type_id"`
@@ -187,11 +215,23 @@ type Application struct {
 	Payment              Payment               `gorm:"foreignKey:ApplicationID" json:"payment,omitempty"`
 
 	// New approval group relationships
-	GroupAssignments []ApplicationGroupAssignment `gorm:"foreignKey:ApplicationID" json:"group_assignments,omitempty"`
-	Issues           []ApplicationIssue           `gorm:"foreignKey:ApplicationID" json:"issues,omitempty"`
-	Comments         []Comment                    `gorm:"foreignKey:ApplicationID" json:"comments,omitempty"`
-	FinalApproval    *FinalApproval               `gorm:"foreignKey:ApplicationID" json:"final_approval,omitempty"`
-	FinalApprover    *User                        `gorm:"foreignKey:FinalApproverID" json:"final_approver,omitempty"`
+	GroupAssignments   []ApplicationGroupAssignment  `gorm:"foreignKey:ApplicationID" json:"group_assignments,omitempty"`
+	Issues             []ApplicationIssue            `gorm:"foreignKey:ApplicationID" json:"issues,omitempty"`
+	Comments           []Comment                     `gorm:"foreignKey:ApplicationID" json:"comments,omitempty"`
+	FinalApproval      *FinalApproval                `gorm:"foreignKey:ApplicationID" json:"final_approval,omitempty"`
+	FinalApprover      *User                         `gorm:"foreignKey:FinalApproverID" json:"final_approver,omitempty"`
+	Transfers          []ApplicationTransfer         `gorm:"foreignKey:ApplicationID" json:"transfers,omitempty"`
+	PhysicalFile       *ApplicationPhysicalFile      `gorm:"foreignKey:ApplicationID" json:"physical_file,omitempty"`
+	InstallmentPlans   []InstallmentPlan             `gorm:"foreignKey:ApplicationID" json:"installment_plans,omitempty"`
+	Countersignatures  []CertificateCountersignature `gorm:"foreignKey:ApplicationID" json:"countersignatures,omitempty"`
+	RatesClearances    []RatesClearance              `gorm:"foreignKey:ApplicationID" json:"rates_clearances,omitempty"`
+	DeedsVerifications []DeedsVerification           `gorm:"foreignKey:ApplicationID" json:"deeds_verifications,omitempty"`
+	CoApplicants       []ApplicationCoApplicant      `gorm:"foreignKey:ApplicationID" json:"co_applicants,omitempty"`
+	BoundaryChecks     []BoundaryCheck               `gorm:"foreignKey:ApplicationID" json:"boundary_checks,omitempty"`
+	RiskAssessments    []ApplicationRiskAssessment   `gorm:"foreignKey:ApplicationID" json:"risk_assessments,omitempty"`
+	Amendments         []ApplicationAmendment        `gorm:"foreignKey:ApplicationID" json:"amendments,omitempty"`
+	Appeals            []ApplicationAppeal           `gorm:"foreignKey:ApplicationID" json:"appeals,omitempty"`
+	SecondOpinions     []SecondOpinionRequest        `gorm:"foreignKey:ApplicationID" json:"second_opinions,omitempty"`
 
 	// Audit fields
 	CreatedBy string         `gorm:"not null" json:"created_by"`
@@ -209,6 +249,12 @@ func (a *Application) BeforeCreate(tx *gorm.DB) (err error) {
 	return
 }
 
+// PaymentPrerequisiteMet reports whether payment no longer holds up review, either because the
+// levy is paid or because an installment plan has been agreed
+func (a *Application) PaymentPrerequisiteMet() bool {
+	return a.PaymentStatus == PaidPayment || a.OnInstallmentPlan
+}
+
 // DevelopmentCategory
 func (pt *DevelopmentCategory) BeforeCreate(tx *gorm.DB) (err error) {
 	if pt.ID == uuid.Nil {
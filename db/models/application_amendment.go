@@ -0,0 +1,79 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type ApplicationAmendmentStatus string
+
+const (
+	AmendmentStatusPendingPayment  ApplicationAmendmentStatus = "PENDING_PAYMENT"
+	AmendmentStatusPendingApproval ApplicationAmendmentStatus = "PENDING_APPROVAL"
+	AmendmentStatusApproved        ApplicationAmendmentStatus = "APPROVED"
+	AmendmentStatusRejected        ApplicationAmendmentStatus = "REJECTED"
+)
+
+// ApplicationAmendment is a minor variation to the plans of an approved application, such as
+// moved windows. It carries a revised plan, costs the reduced amendment fee of the
+// application's tariff and is signed off by a single approver instead of the approval groups.
+// Once approved, an amended permit noting the variation replaces the permit document.
+type ApplicationAmendment struct {
+	ID            uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	ApplicationID uuid.UUID `gorm:"type:uuid;not null;index" json:"application_id"`
+
+	// The approval being varied
+	ParentApprovalID uuid.UUID `gorm:"type:uuid;not null;index" json:"parent_approval_id"`
+
+	// Numbered on approval, 1 for the application's first approved amendment, and printed on
+	// the amended permit
+	AmendmentNumber int `gorm:"default:0" json:"amendment_number"`
+
+	Description           string    `gorm:"type:text;not null" json:"description"`
+	RevisedPlanDocumentID uuid.UUID `gorm:"type:uuid;not null" json:"revised_plan_document_id"`
+
+	// Fee, worked out from the tariff when the amendment is requested
+	TariffID  *uuid.UUID      `gorm:"type:uuid" json:"tariff_id"`
+	Currency  string          `gorm:"type:varchar(10)" json:"currency"`
+	Fee       decimal.Decimal `gorm:"type:decimal(15,2);not null" json:"fee"`
+	VATAmount decimal.Decimal `gorm:"type:decimal(15,2);not null" json:"vat_amount"`
+	TotalFee  decimal.Decimal `gorm:"type:decimal(15,2);not null" json:"total_fee"`
+	PaymentID *uuid.UUID      `gorm:"type:uuid" json:"payment_id"`
+	PaidAt    *time.Time      `json:"paid_at"`
+
+	Status ApplicationAmendmentStatus `gorm:"type:varchar(20);default:'PENDING_PAYMENT';index" json:"status"`
+
+	// Review
+	RequestedByID uuid.UUID  `gorm:"type:uuid;not null;index" json:"requested_by_id"`
+	ReviewedByID  *uuid.UUID `gorm:"type:uuid;index" json:"reviewed_by_id"`
+	ReviewedAt    *time.Time `json:"reviewed_at"`
+	ReviewComment *string    `gorm:"type:text" json:"review_comment"`
+
+	// The amended permit issued on approval
+	PermitDocumentID *uuid.UUID `gorm:"type:uuid" json:"permit_document_id"`
+
+	// Relationships
+	Application         *Application   `gorm:"foreignKey:ApplicationID" json:"application,omitempty"`
+	ParentApproval      *FinalApproval `gorm:"foreignKey:ParentApprovalID" json:"parent_approval,omitempty"`
+	RevisedPlanDocument *Document      `gorm:"foreignKey:RevisedPlanDocumentID" json:"revised_plan_document,omitempty"`
+	Payment             *Payment       `gorm:"foreignKey:PaymentID" json:"payment,omitempty"`
+	PermitDocument      *Document      `gorm:"foreignKey:PermitDocumentID" json:"permit_document,omitempty"`
+	RequestedBy         *User          `gorm:"foreignKey:RequestedByID" json:"requested_by,omitempty"`
+	ReviewedBy          *User          `gorm:"foreignKey:ReviewedByID" json:"reviewed_by,omitempty"`
+
+	// Audit fields
+	CreatedBy string         `gorm:"not null" json:"created_by"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (aa *ApplicationAmendment) BeforeCreate(tx *gorm.DB) error {
+	if aa.ID == uuid.Nil {
+		aa.ID = uuid.New()
+	}
+	return nil
+}
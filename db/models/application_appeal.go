@@ -0,0 +1,102 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type ApplicationAppealStatus string
+
+const (
+	AppealStatusLodged           ApplicationAppealStatus = "LODGED"
+	AppealStatusHearingScheduled ApplicationAppealStatus = "HEARING_SCHEDULED"
+	AppealStatusUpheld           ApplicationAppealStatus = "UPHELD"
+	AppealStatusDismissed        ApplicationAppealStatus = "DISMISSED"
+)
+
+type AppealHearingStatus string
+
+const (
+	AppealHearingScheduled AppealHearingStatus = "SCHEDULED"
+	AppealHearingPostponed AppealHearingStatus = "POSTPONED"
+	AppealHearingHeld      AppealHearingStatus = "HELD"
+)
+
+// ApplicationAppeal is an applicant's appeal against the rejection of their application. It is
+// heard by an APPEALS approval group, separate from the group that reviewed the application.
+// An upheld appeal reopens the application for review under a new group assignment.
+type ApplicationAppeal struct {
+	ID            uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	ApplicationID uuid.UUID `gorm:"type:uuid;not null;index" json:"application_id"`
+
+	// The rejection being appealed
+	RejectionApprovalID uuid.UUID `gorm:"type:uuid;not null;index" json:"rejection_approval_id"`
+
+	Grounds string `gorm:"type:text;not null" json:"grounds"`
+
+	// Appeal fee, paid when the appeal is lodged
+	Currency  string          `gorm:"type:varchar(10)" json:"currency"`
+	Fee       decimal.Decimal `gorm:"type:decimal(15,2);not null" json:"fee"`
+	PaymentID *uuid.UUID      `gorm:"type:uuid" json:"payment_id"`
+
+	AppealsGroupID uuid.UUID               `gorm:"type:uuid;not null;index" json:"appeals_group_id"`
+	Status         ApplicationAppealStatus `gorm:"type:varchar(20);default:'LODGED';index" json:"status"`
+
+	// Outcome
+	DecidedByID   *uuid.UUID `gorm:"type:uuid;index" json:"decided_by_id"`
+	DecidedAt     *time.Time `json:"decided_at"`
+	OutcomeReason *string    `gorm:"type:text" json:"outcome_reason"`
+
+	// The review assignment opened when the appeal was upheld
+	ReopenedAssignmentID *uuid.UUID `gorm:"type:uuid" json:"reopened_assignment_id"`
+
+	// Relationships
+	Application        *Application                `gorm:"foreignKey:ApplicationID" json:"application,omitempty"`
+	RejectionApproval  *FinalApproval              `gorm:"foreignKey:RejectionApprovalID" json:"rejection_approval,omitempty"`
+	Payment            *Payment                    `gorm:"foreignKey:PaymentID" json:"payment,omitempty"`
+	AppealsGroup       *ApprovalGroup              `gorm:"foreignKey:AppealsGroupID" json:"appeals_group,omitempty"`
+	LodgedBy           *User                       `gorm:"foreignKey:LodgedByID" json:"lodged_by,omitempty"`
+	DecidedBy          *User                       `gorm:"foreignKey:DecidedByID" json:"decided_by,omitempty"`
+	ReopenedAssignment *ApplicationGroupAssignment `gorm:"foreignKey:ReopenedAssignmentID" json:"reopened_assignment,omitempty"`
+	Hearings           []AppealHearing             `gorm:"foreignKey:AppealID" json:"hearings,omitempty"`
+
+	// Audit fields
+	LodgedByID uuid.UUID      `gorm:"type:uuid;not null;index" json:"lodged_by_id"`
+	CreatedBy  string         `gorm:"not null" json:"created_by"`
+	CreatedAt  time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (aa *ApplicationAppeal) BeforeCreate(tx *gorm.DB) error {
+	if aa.ID == uuid.Nil {
+		aa.ID = uuid.New()
+	}
+	return nil
+}
+
+// AppealHearing is a hearing date set for an appeal. Rescheduling postpones the earlier hearing
+// instead of overwriting it, so every date the appeal was set down for is kept.
+type AppealHearing struct {
+	ID          uuid.UUID           `gorm:"type:uuid;primary_key;" json:"id"`
+	AppealID    uuid.UUID           `gorm:"type:uuid;not null;index" json:"appeal_id"`
+	ScheduledAt time.Time           `gorm:"not null;index" json:"scheduled_at"`
+	Venue       *string             `gorm:"type:varchar(200)" json:"venue"`
+	Notes       *string             `gorm:"type:text" json:"notes"`
+	Status      AppealHearingStatus `gorm:"type:varchar(20);default:'SCHEDULED'" json:"status"`
+
+	// Audit fields
+	CreatedBy string    `gorm:"not null" json:"created_by"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (ah *AppealHearing) BeforeCreate(tx *gorm.DB) error {
+	if ah.ID == uuid.Nil {
+		ah.ID = uuid.New()
+	}
+	return nil
+}
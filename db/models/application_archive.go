@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ApplicationArchive records one move of a terminal application into cold storage: its
+// documents are compressed and the application drops out of the default lists. Rehydrating
+// the application restores the documents and closes the record.
+type ApplicationArchive struct {
+	ID            uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	ApplicationID uuid.UUID `gorm:"type:uuid;not null;index" json:"application_id"`
+	ArchivedAt    time.Time `gorm:"not null" json:"archived_at"`
+	ArchivedBy    string    `gorm:"not null" json:"archived_by"` // "system" for the scheduled archival
+
+	// Storage saved by compressing the documents
+	DocumentCount int   `gorm:"default:0" json:"document_count"`
+	OriginalBytes int64 `gorm:"default:0" json:"original_bytes"`
+	ArchivedBytes int64 `gorm:"default:0" json:"archived_bytes"`
+
+	// Set when the application is restored, e.g. for a legal query
+	RehydratedAt      *time.Time `gorm:"index" json:"rehydrated_at"`
+	RehydratedByID    *uuid.UUID `gorm:"type:uuid" json:"rehydrated_by_id"`
+	RehydrationReason *string    `gorm:"type:text" json:"rehydration_reason"`
+
+	// Relationships
+	Application  *Application                 `gorm:"foreignKey:ApplicationID" json:"application,omitempty"`
+	RehydratedBy *User                        `gorm:"foreignKey:RehydratedByID" json:"rehydrated_by,omitempty"`
+	Documents    []ApplicationArchiveDocument `gorm:"foreignKey:ArchiveID" json:"documents,omitempty"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// ApplicationArchiveDocument is a document compressed by an archive, with the path it is
+// restored to
+type ApplicationArchiveDocument struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	ArchiveID    uuid.UUID `gorm:"type:uuid;not null;index" json:"archive_id"`
+	DocumentID   uuid.UUID `gorm:"type:uuid;not null;index" json:"document_id"`
+	OriginalPath string    `gorm:"type:varchar(500);not null" json:"original_path"`
+	ArchivePath  string    `gorm:"type:varchar(500);not null" json:"archive_path"`
+	OriginalSize int64     `json:"original_size"`
+	ArchivedSize int64     `json:"archived_size"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	Document *Document `gorm:"foreignKey:DocumentID" json:"document,omitempty"`
+}
+
+func (aa *ApplicationArchive) BeforeCreate(tx *gorm.DB) error {
+	if aa.ID == uuid.Nil {
+		aa.ID = uuid.New()
+	}
+	if aa.ArchivedAt.IsZero() {
+		aa.ArchivedAt = time.Now()
+	}
+	return nil
+}
+
+func (ad *ApplicationArchiveDocument) BeforeCreate(tx *gorm.DB) error {
+	if ad.ID == uuid.Nil {
+		ad.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CoApplicantRole is an applicant's part in a jointly owned application
+type CoApplicantRole string
+
+const (
+	CoApplicantPrimary CoApplicantRole = "PRIMARY"
+	CoApplicantCoOwner CoApplicantRole = "CO_OWNER"
+)
+
+// CoApplicantIDCategoryCode is the document category every co-owner must provide
+const CoApplicantIDCategoryCode = "NATIONAL_ID"
+
+// ApplicationCoApplicant links an application to each of its owners. The PRIMARY row mirrors
+// Application.ApplicantID; every CO_OWNER must have an ID document on file.
+type ApplicationCoApplicant struct {
+	ApplicationID uuid.UUID       `gorm:"type:uuid;primaryKey" json:"application_id"`
+	ApplicantID   uuid.UUID       `gorm:"type:uuid;primaryKey;index" json:"applicant_id"`
+	Role          CoApplicantRole `gorm:"type:varchar(20);not null" json:"role"`
+	IDDocumentID  *uuid.UUID      `gorm:"type:uuid;index" json:"id_document_id"`
+
+	// Relationships
+	Application *Application `gorm:"foreignKey:ApplicationID" json:"-"`
+	Applicant   *Applicant   `gorm:"foreignKey:ApplicantID" json:"applicant,omitempty"`
+	IDDocument  *Document    `gorm:"foreignKey:IDDocumentID" json:"id_document,omitempty"`
+
+	// Audit fields
+	CreatedBy string    `gorm:"not null" json:"created_by"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName overrides default table name for join table
+func (ApplicationCoApplicant) TableName() string {
+	return "application_co_applicants"
+}
+
+// ApplicantNames lists everyone the application is made in the name of, primary applicant
+// first and co-owners in the order they were added. CoApplicants.Applicant must be preloaded
+// for co-owners to be included.
+func (a *Application) ApplicantNames() []string {
+	names := []string{}
+	if a.Applicant.FullName != "" {
+		names = append(names, a.Applicant.FullName)
+	}
+
+	coOwners := make([]ApplicationCoApplicant, 0, len(a.CoApplicants))
+	for _, coApplicant := range a.CoApplicants {
+		if coApplicant.Role == CoApplicantCoOwner && coApplicant.ApplicantID != a.ApplicantID && coApplicant.Applicant != nil {
+			coOwners = append(coOwners, coApplicant)
+		}
+	}
+	sort.SliceStable(coOwners, func(i, j int) bool {
+		return coOwners[i].CreatedAt.Before(coOwners[j].CreatedAt)
+	})
+	for _, coOwner := range coOwners {
+		if coOwner.Applicant.FullName != "" {
+			names = append(names, coOwner.Applicant.FullName)
+		}
+	}
+	return names
+}
+
+// ApplicantDisplayName joins all applicant names for permits and letters, e.g. "A & B"
+func (a *Application) ApplicantDisplayName() string {
+	return strings.Join(a.ApplicantNames(), " & ")
+}
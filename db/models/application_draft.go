@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ApplicationDraftStatus tracks a draft from the first autosave until it is submitted or goes stale
+type ApplicationDraftStatus string
+
+const (
+	DraftApplicationDraft    ApplicationDraftStatus = "DRAFT"    // Still being filled in
+	PromotedApplicationDraft ApplicationDraftStatus = "PROMOTED" // Submitted as an application
+	ExpiredApplicationDraft  ApplicationDraftStatus = "EXPIRED"  // Not saved again before it expired
+)
+
+// ApplicationDraft holds a partly entered application, e.g. a walk-in a technician was
+// interrupted on. The form data is kept as entered and only validated when the draft is
+// submitted. Each save pushes the expiry back.
+type ApplicationDraft struct {
+	ID      uuid.UUID              `gorm:"type:uuid;primary_key;" json:"id"`
+	OwnerID uuid.UUID              `gorm:"type:uuid;not null;index" json:"owner_id"`
+	Label   *string                `gorm:"type:varchar(200)" json:"label"` // e.g. the applicant's name, to tell drafts apart
+	Status  ApplicationDraftStatus `gorm:"type:varchar(20);not null;default:'DRAFT';index" json:"status"`
+
+	// The create-application form fields entered so far
+	Data datatypes.JSON `gorm:"type:jsonb;not null" json:"data"`
+
+	// Incremented on every save so a stale autosave from another tab cannot overwrite newer data
+	Version int `gorm:"not null;default:1" json:"version"`
+
+	LastSavedAt time.Time `gorm:"not null" json:"last_saved_at"`
+	ExpiresAt   time.Time `gorm:"not null;index" json:"expires_at"`
+
+	// Set when the draft is submitted
+	PromotedAt            *time.Time `json:"promoted_at"`
+	PromotedApplicationID *uuid.UUID `gorm:"type:uuid;index" json:"promoted_application_id"`
+
+	// Relationships
+	Owner               *User        `gorm:"foreignKey:OwnerID" json:"owner,omitempty"`
+	PromotedApplication *Application `gorm:"foreignKey:PromotedApplicationID" json:"promoted_application,omitempty"`
+
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (ad *ApplicationDraft) BeforeCreate(tx *gorm.DB) error {
+	if ad.ID == uuid.Nil {
+		ad.ID = uuid.New()
+	}
+	if ad.LastSavedAt.IsZero() {
+		ad.LastSavedAt = time.Now()
+	}
+	return nil
+}
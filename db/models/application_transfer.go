@@ -0,0 +1,79 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type ApplicationTransferStatus string
+
+const (
+	TransferStatusPending  ApplicationTransferStatus = "PENDING"
+	TransferStatusApproved ApplicationTransferStatus = "APPROVED"
+	TransferStatusRejected ApplicationTransferStatus = "REJECTED"
+)
+
+type TransferSupportingDocumentType string
+
+const (
+	TransferDocumentSaleAgreement TransferSupportingDocumentType = "SALE_AGREEMENT"
+	TransferDocumentTitleDeed     TransferSupportingDocumentType = "TITLE_DEED"
+)
+
+// ApplicationTransfer records the re-assignment of an application to a new applicant after a
+// property sale. Rows are never deleted, so together they form the ownership history of the
+// application; FromApplicantID keeps the original applicant after the transfer is approved.
+type ApplicationTransfer struct {
+	ID              uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	ApplicationID   uuid.UUID `gorm:"type:uuid;not null;index" json:"application_id"`
+	FromApplicantID uuid.UUID `gorm:"type:uuid;not null;index" json:"from_applicant_id"`
+	ToApplicantID   uuid.UUID `gorm:"type:uuid;not null;index" json:"to_applicant_id"`
+
+	// Proof of sale
+	SupportingDocumentType TransferSupportingDocumentType `gorm:"type:varchar(30);not null" json:"supporting_document_type"`
+	SupportingDocumentID   uuid.UUID                      `gorm:"type:uuid;not null" json:"supporting_document_id"`
+	Reason                 *string                        `gorm:"type:text" json:"reason"`
+
+	// Development category of the new applicant, used to re-price the application when it differs
+	NewDevelopmentCategoryID *uuid.UUID `gorm:"type:uuid" json:"new_development_category_id"`
+
+	Status ApplicationTransferStatus `gorm:"type:varchar(20);default:'PENDING';index" json:"status"`
+
+	// Review
+	RequestedByID uuid.UUID  `gorm:"type:uuid;not null;index" json:"requested_by_id"`
+	ReviewedByID  *uuid.UUID `gorm:"type:uuid;index" json:"reviewed_by_id"`
+	ReviewedAt    *time.Time `json:"reviewed_at"`
+	ReviewComment *string    `gorm:"type:text" json:"review_comment"`
+
+	// Re-pricing outcome
+	PreviousTariffID     *uuid.UUID       `gorm:"type:uuid" json:"previous_tariff_id"`
+	NewTariffID          *uuid.UUID       `gorm:"type:uuid" json:"new_tariff_id"`
+	PreviousTotalCost    *decimal.Decimal `gorm:"type:decimal(15,2)" json:"previous_total_cost"`
+	NewTotalCost         *decimal.Decimal `gorm:"type:decimal(15,2)" json:"new_total_cost"`
+	QuotationRegenerated bool             `gorm:"default:false" json:"quotation_regenerated"`
+	QuotationDocumentID  *uuid.UUID       `gorm:"type:uuid" json:"quotation_document_id"`
+
+	// Relationships
+	Application        *Application `gorm:"foreignKey:ApplicationID" json:"application,omitempty"`
+	FromApplicant      *Applicant   `gorm:"foreignKey:FromApplicantID" json:"from_applicant,omitempty"`
+	ToApplicant        *Applicant   `gorm:"foreignKey:ToApplicantID" json:"to_applicant,omitempty"`
+	SupportingDocument *Document    `gorm:"foreignKey:SupportingDocumentID" json:"supporting_document,omitempty"`
+	RequestedBy        *User        `gorm:"foreignKey:RequestedByID" json:"requested_by,omitempty"`
+	ReviewedBy         *User        `gorm:"foreignKey:ReviewedByID" json:"reviewed_by,omitempty"`
+
+	// Audit fields
+	CreatedBy string         `gorm:"not null" json:"created_by"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (at *ApplicationTransfer) BeforeCreate(tx *gorm.DB) error {
+	if at.ID == uuid.Nil {
+		at.ID = uuid.New()
+	}
+	return nil
+}
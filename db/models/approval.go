@@ -2,6 +2,7 @@ package models
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,8 +15,38 @@ type ApprovalGroupType string
 const (
 	ApprovalGroupGlobal      ApprovalGroupType = "GLOBAL"
 	ApprovalGroupApplication ApprovalGroupType = "APPLICATION_SPECIFIC"
+	ApprovalGroupAppeals     ApprovalGroupType = "APPEALS" // Hears appeals against rejections, never reviews applications
 )
 
+// DecisionCommentPolicy sets which member decisions must be explained in a comment
+type DecisionCommentPolicy string
+
+const (
+	CommentsOptional           DecisionCommentPolicy = "NONE"
+	CommentsRequiredApprovals  DecisionCommentPolicy = "APPROVALS"
+	CommentsRequiredRejections DecisionCommentPolicy = "REJECTIONS"
+	CommentsRequiredBoth       DecisionCommentPolicy = "BOTH"
+)
+
+// Valid reports whether the policy is one of the known values
+func (p DecisionCommentPolicy) Valid() bool {
+	switch p {
+	case CommentsOptional, CommentsRequiredApprovals, CommentsRequiredRejections, CommentsRequiredBoth:
+		return true
+	}
+	return false
+}
+
+// RequiresApprovalComment reports whether approvals need a comment
+func (p DecisionCommentPolicy) RequiresApprovalComment() bool {
+	return p == CommentsRequiredApprovals || p == CommentsRequiredBoth
+}
+
+// RequiresRejectionReason reports whether rejections need a reason
+func (p DecisionCommentPolicy) RequiresRejectionReason() bool {
+	return p == CommentsRequiredRejections || p == CommentsRequiredBoth
+}
+
 type CommentType string
 
 const (
@@ -24,6 +55,9 @@ const (
 	CommentTypeRejection  CommentType = "REJECTION"
 	CommentTypeIssue      CommentType = "ISSUE"
 	CommentTypeResolution CommentType = "RESOLUTION"
+
+	// Advice from a reviewer outside the approval group; it does not count as a decision
+	CommentTypeSecondOpinion CommentType = "SECOND_OPINION"
 )
 
 // ========================================
@@ -80,6 +114,22 @@ const (
 	AvailabilityLimited     AvailabilityStatus = "LIMITED" // Can handle only critical items
 )
 
+// ConflictDeclarationStatus is the outcome of a reviewer's conflict-of-interest declaration
+type ConflictDeclarationStatus string
+
+const (
+	ConflictDeclared   ConflictDeclarationStatus = "DECLARED"    // Reviewer is related to the applicant and is recused
+	ConflictNoConflict ConflictDeclarationStatus = "NO_CONFLICT" // Reviewer confirmed there is no conflict despite a heuristic match
+)
+
+// ConflictIndicator names the heuristic that suggested a possible conflict
+type ConflictIndicator string
+
+const (
+	ConflictIndicatorSurname ConflictIndicator = "SURNAME_MATCH"
+	ConflictIndicatorAddress ConflictIndicator = "ADDRESS_MATCH"
+)
+
 // ApprovalGroup represents a group of users who review applications
 type ApprovalGroup struct {
 	ID          uuid.UUID         `gorm:"type:uuid;primary_key;" json:"id"`
@@ -88,10 +138,14 @@ type ApprovalGroup struct {
 	Type        ApprovalGroupType `gorm:"type:varchar(30);not null" json:"type"`
 	IsActive    bool              `gorm:"default:true;index" json:"is_active"`
 
-	// Workflow configuration
+	// Workflow configuration. When not every member has to approve, MinimumApprovals is the
+	// approval weight needed, summing each approving member's DecisionWeight.
 	RequiresAllApprovals bool `gorm:"default:true" json:"requires_all_approvals"`
 	MinimumApprovals     int  `gorm:"default:1" json:"minimum_approvals"`
 
+	// Which member decisions must come with a comment
+	CommentPolicy DecisionCommentPolicy `gorm:"type:varchar(20);default:'REJECTIONS'" json:"comment_policy"`
+
 	// Auto-assignment configuration
 	AutoAssignBackups bool `gorm:"default:false" json:"auto_assign_backups"`
 
@@ -121,6 +175,9 @@ type ApprovalGroupMember struct {
 	CanReject      bool       `gorm:"default:true" json:"can_reject"`
 	ReviewOrder    int        `gorm:"default:0" json:"review_order"`
 
+	// How much this member's decision counts towards MinimumApprovals (e.g. 2 for a chief engineer)
+	DecisionWeight int `gorm:"not null;default:1" json:"decision_weight"`
+
 	// NEW: Final approver flag
 	IsFinalApprover bool `gorm:"default:false;index" json:"is_final_approver"`
 
@@ -176,11 +233,28 @@ type ApplicationGroupAssignment struct {
 	// Backup assignment tracking
 	UsedBackupMembers bool `gorm:"default:false" json:"used_backup_members"`
 
+	// Members recused because of a declared conflict of interest
+	RecusedCount int `gorm:"default:0" json:"recused_count"`
+
+	// Weighted progress of regular members, summing DecisionWeight. Recused members are left out.
+	TotalWeight    int `gorm:"default:0" json:"total_weight"`
+	ApprovedWeight int `gorm:"default:0" json:"approved_weight"`
+	RejectedWeight int `gorm:"default:0" json:"rejected_weight"`
+	PendingWeight  int `gorm:"default:0" json:"pending_weight"`
+
+	// Bumped whenever member decisions change, so a decision worked out from an older read is
+	// refused instead of overwriting a concurrent one
+	DecisionVersion int `gorm:"default:0;not null" json:"decision_version"`
+
+	// The group's quorum when it was assigned without enough available approvers. Not stored.
+	Quorum *QuorumCheck `gorm:"-" json:"quorum,omitempty"`
+
 	// Relationships
-	Application   Application              `gorm:"foreignKey:ApplicationID" json:"application"`
-	Group         ApprovalGroup            `gorm:"foreignKey:ApprovalGroupID" json:"group"`
-	Decisions     []MemberApprovalDecision `gorm:"foreignKey:AssignmentID" json:"decisions,omitempty"`
-	FinalDecision *FinalApproval           `gorm:"foreignKey:FinalDecisionID" json:"final_decision,omitempty"` // ← FIX THIS
+	Application          Application                     `gorm:"foreignKey:ApplicationID" json:"application"`
+	Group                ApprovalGroup                   `gorm:"foreignKey:ApprovalGroupID" json:"group"`
+	Decisions            []MemberApprovalDecision        `gorm:"foreignKey:AssignmentID" json:"decisions,omitempty"`
+	FinalDecision        *FinalApproval                  `gorm:"foreignKey:FinalDecisionID" json:"final_decision,omitempty"` // ← FIX THIS
+	ConflictDeclarations []ConflictOfInterestDeclaration `gorm:"foreignKey:AssignmentID" json:"conflict_declarations,omitempty"`
 
 	// Audit fields
 	AssignedBy string         `gorm:"not null" json:"assigned_by"`
@@ -216,6 +290,9 @@ type MemberApprovalDecision struct {
 	RevokedAt     *time.Time `json:"revoked_at"`
 	RevokedReason *string    `gorm:"type:text" json:"revoked_reason"`
 
+	// Idempotency-Key the decision request was sent with, kept for the audit log
+	IdempotencyKey *string `gorm:"type:varchar(100)" json:"idempotency_key,omitempty"`
+
 	// Backup assignment info (if this was assigned to a backup)
 	OriginalMemberID *uuid.UUID `gorm:"type:uuid;index" json:"original_member_id"` // If backup replacing someone
 	BackupAssignment bool       `gorm:"default:false" json:"backup_assignment"`
@@ -226,6 +303,43 @@ type MemberApprovalDecision struct {
 	User           User                       `gorm:"foreignKey:UserID" json:"user"`
 	OriginalMember *ApprovalGroupMember       `gorm:"foreignKey:OriginalMemberID" json:"original_member,omitempty"`
 	Comments       []Comment                  `gorm:"foreignKey:DecisionID" json:"comments,omitempty"`
+	ChecklistItems []DecisionChecklistItem    `gorm:"foreignKey:DecisionID" json:"checklist_items,omitempty"` // Ticked when approving
+
+	// Audit fields
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// ConflictOfInterestDeclaration records a reviewer's declaration about their relationship to the
+// applicant. A declared conflict recuses the member: their decision is SKIPPED and a backup member
+// takes their place. Heuristic matches that prompted the declaration are kept for audit.
+type ConflictOfInterestDeclaration struct {
+	ID            uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	ApplicationID uuid.UUID `gorm:"type:uuid;not null;index" json:"application_id"`
+	AssignmentID  uuid.UUID `gorm:"type:uuid;not null;index" json:"assignment_id"`
+	MemberID      uuid.UUID `gorm:"type:uuid;not null;index" json:"member_id"`
+	UserID        uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+
+	Status       ConflictDeclarationStatus `gorm:"type:varchar(20);not null" json:"status"`
+	Relationship *string                   `gorm:"type:varchar(100)" json:"relationship"` // e.g. SPOUSE, SIBLING, BUSINESS_PARTNER
+	Details      *string                   `gorm:"type:text" json:"details"`
+
+	// Heuristic matches found when the declaration was made, comma separated
+	Indicators *string `gorm:"type:varchar(100)" json:"indicators"`
+
+	// Recusal outcome
+	SkippedDecisionID *uuid.UUID `gorm:"type:uuid" json:"skipped_decision_id"`
+	BackupMemberID    *uuid.UUID `gorm:"type:uuid" json:"backup_member_id"`
+	BackupDecisionID  *uuid.UUID `gorm:"type:uuid" json:"backup_decision_id"`
+
+	DeclaredAt time.Time `gorm:"not null" json:"declared_at"`
+
+	// Relationships
+	Assignment   ApplicationGroupAssignment `gorm:"foreignKey:AssignmentID" json:"-"`
+	Member       ApprovalGroupMember        `gorm:"foreignKey:MemberID" json:"-"`
+	User         User                       `gorm:"foreignKey:UserID" json:"user"`
+	BackupMember *ApprovalGroupMember       `gorm:"foreignKey:BackupMemberID" json:"backup_member,omitempty"`
 
 	// Audit fields
 	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
@@ -266,6 +380,9 @@ type ApplicationIssue struct {
 	// Chat thread reference
 	ChatThreadID *uuid.UUID `gorm:"type:uuid;index" json:"chat_thread_id"`
 
+	// Chat message the issue was raised from, when it was converted from a message
+	SourceMessageID *uuid.UUID `gorm:"type:uuid;index" json:"source_message_id"`
+
 	// ========================================
 	// ISSUE DETAILS
 	// ========================================
@@ -274,6 +391,11 @@ type ApplicationIssue struct {
 	Priority    string  `gorm:"type:varchar(20);default:'MEDIUM'" json:"priority"` // LOW, MEDIUM, HIGH, CRITICAL
 	Category    *string `gorm:"type:varchar(50)" json:"category"`                  // Optional: LOGISTICS, TECHNICAL, ADMINISTRATIVE, etc.
 
+	// Set while staff wait on the applicant to answer the issue; pauses the application's SLA
+	// clocks, see ApplicationSLAPause
+	AwaitingApplicant      bool       `gorm:"default:false;index" json:"awaiting_applicant"`
+	AwaitingApplicantSince *time.Time `json:"awaiting_applicant_since"`
+
 	// ========================================
 	// RESOLUTION TRACKING
 	// ========================================
@@ -335,6 +457,10 @@ type FinalApproval struct {
 	Approver             User `gorm:"foreignKey:ApproverID" json:"approver"`
 	IsSystemAutoDecision bool `gorm:"default:false" json:"is_system_auto_decision"`
 
+	// Planning scheme versions in force when the application was submitted, which the decision
+	// was made under
+	PlanningSchemeVersions []PlanningSchemeVersion `gorm:"many2many:final_approval_scheme_versions" json:"planning_scheme_versions,omitempty"`
+
 	// Audit fields
 	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
@@ -383,6 +509,7 @@ type DecisionRevocation struct {
 	Reason         string               `gorm:"type:text;not null" json:"reason"`
 	RevokedAt      time.Time            `gorm:"not null" json:"revoked_at"`
 	PreviousStatus MemberDecisionStatus `gorm:"type:varchar(20)" json:"previous_status"`
+	IdempotencyKey *string              `gorm:"type:varchar(100)" json:"idempotency_key,omitempty"` // Sent with the revocation request
 
 	// Relationships
 	Decision MemberApprovalDecision `gorm:"foreignKey:DecisionID" json:"decision"`
@@ -435,6 +562,13 @@ func (mad *MemberApprovalDecision) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+func (coi *ConflictOfInterestDeclaration) BeforeCreate(tx *gorm.DB) error {
+	if coi.ID == uuid.Nil {
+		coi.ID = uuid.New()
+	}
+	return nil
+}
+
 func (ai *ApplicationIssue) BeforeCreate(tx *gorm.DB) error {
 	if ai.ID == uuid.Nil {
 		ai.ID = uuid.New()
@@ -456,20 +590,130 @@ func (c *Comment) BeforeCreate(tx *gorm.DB) (err error) {
 	return
 }
 
-// Helper method to check if all regular members have approved
+// Helper method to check if the regular members' weighted approvals meet the group's rule
 func (aga *ApplicationGroupAssignment) AllRegularMembersApproved() bool {
-	// Count only non-final-approver members
-	regularMembersCount := 0
-	for _, member := range aga.Group.Members {
-		if !member.IsFinalApprover {
-			regularMembersCount++
+	return aga.Group.QuorumReached(aga.ApprovedWeight, aga.RejectedWeight, aga.PendingWeight)
+}
+
+// QuorumReached reports whether weighted member decisions are enough to pass the application to the
+// final approver. Any rejection blocks it. With RequiresAllApprovals nobody may still be pending;
+// otherwise approvals must weigh at least MinimumApprovals, capped at the weight that can still vote.
+func (ag *ApprovalGroup) QuorumReached(approvedWeight, rejectedWeight, pendingWeight int) bool {
+	if rejectedWeight > 0 {
+		return false
+	}
+	if ag.RequiresAllApprovals {
+		return pendingWeight == 0
+	}
+
+	required := ag.MinimumApprovals
+	if eligible := approvedWeight + pendingWeight; required > eligible {
+		required = eligible
+	}
+	return approvedWeight >= required
+}
+
+// Weight returns the member's decision weight, treating unset weights as 1
+func (agm *ApprovalGroupMember) Weight() int {
+	if agm.DecisionWeight < 1 {
+		return 1
+	}
+	return agm.DecisionWeight
+}
+
+// QuorumCheck is whether an approval group can decide an application now: enough approval
+// weight among its available approving members, and an active final approver
+type QuorumCheck struct {
+	GroupID                uuid.UUID `json:"group_id"`
+	GroupName              string    `json:"group_name"`
+	AvailableApprovers     int       `json:"available_approvers"`
+	AvailableWeight        int       `json:"available_weight"`
+	RequiredWeight         int       `json:"required_weight"`
+	HasFinalApprover       bool      `json:"has_final_approver"`
+	FinalApproverAvailable bool      `json:"final_approver_available"`
+	Problems               []string  `json:"problems"`
+}
+
+// Met reports whether the group has its quorum
+func (qc QuorumCheck) Met() bool {
+	return len(qc.Problems) == 0
+}
+
+// AvailableAt reports whether the member is not marked away at the given time. A member away
+// without a return date stays away until marked available again.
+func (agm *ApprovalGroupMember) AvailableAt(at time.Time) bool {
+	if agm.AvailabilityStatus == AvailabilityAvailable || agm.AvailabilityStatus == "" {
+		return true
+	}
+	return agm.UnavailableUntil != nil && !agm.UnavailableUntil.After(at)
+}
+
+// CheckQuorum works out the group's quorum at the given time from its loaded Members. Regular
+// members count when active, allowed to approve and available; when the group assigns backups
+// automatically, available backups count in place of absent primary members who allow it. With
+// RequiresAllApprovals every primary member's weight is needed, otherwise MinimumApprovals.
+func (ag *ApprovalGroup) CheckQuorum(at time.Time) QuorumCheck {
+	check := QuorumCheck{
+		GroupID:   ag.ID,
+		GroupName: ag.Name,
+		Problems:  []string{},
+	}
+
+	var absentPrimaries int
+	var backups []ApprovalGroupMember
+	for i := range ag.Members {
+		member := ag.Members[i]
+		if !member.IsActive || member.Role == MemberRoleRetired {
+			continue
+		}
+		if member.IsFinalApprover {
+			check.HasFinalApprover = true
+			check.FinalApproverAvailable = check.FinalApproverAvailable || member.AvailableAt(at)
+			continue
+		}
+		if !member.CanApprove {
+			continue
+		}
+		if member.Role == MemberRoleBackup {
+			if member.AvailableAt(at) {
+				backups = append(backups, member)
+			}
+			continue
+		}
+
+		check.RequiredWeight += member.Weight()
+		if member.AvailableAt(at) {
+			check.AvailableApprovers++
+			check.AvailableWeight += member.Weight()
+		} else if ag.AutoAssignBackups && member.AutoReassign {
+			absentPrimaries++
 		}
 	}
+	sort.SliceStable(backups, func(i, j int) bool {
+		return backups[i].BackupPriority < backups[j].BackupPriority
+	})
+	for i := 0; i < absentPrimaries && i < len(backups); i++ {
+		check.AvailableApprovers++
+		check.AvailableWeight += backups[i].Weight()
+	}
 
-	if aga.Group.RequiresAllApprovals {
-		return aga.ApprovedCount >= regularMembersCount
+	if !ag.RequiresAllApprovals {
+		check.RequiredWeight = ag.MinimumApprovals
+	}
+	if check.RequiredWeight < 1 {
+		check.RequiredWeight = 1
+	}
+
+	if check.AvailableWeight < check.RequiredWeight {
+		check.Problems = append(check.Problems, fmt.Sprintf(
+			"available approvers weigh %d of the %d needed", check.AvailableWeight, check.RequiredWeight))
+	}
+	if !check.HasFinalApprover {
+		check.Problems = append(check.Problems, "group has no active final approver")
+	} else if !check.FinalApproverAvailable {
+		check.Problems = append(check.Problems, "final approver is unavailable")
 	}
-	return aga.ApprovedCount >= aga.Group.MinimumApprovals
+	return check
 }
 
 // Helper method to check if application is ready for final approval
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// BoundaryLayerKind is what a boundary layer's polygons outline
+type BoundaryLayerKind string
+
+const (
+	BoundaryLayerJurisdiction BoundaryLayerKind = "JURISDICTION" // The council's area of jurisdiction
+	BoundaryLayerWard         BoundaryLayerKind = "WARD"         // One named polygon per ward
+)
+
+// BoundaryLayer is an uploaded GeoJSON layer of council boundaries. Only one layer of each kind
+// is active at a time; uploading a new one deactivates the previous so older layers stay on record.
+type BoundaryLayer struct {
+	ID           uuid.UUID         `gorm:"type:uuid;primary_key;" json:"id"`
+	Name         string            `gorm:"type:varchar(255);not null" json:"name"`
+	Kind         BoundaryLayerKind `gorm:"type:varchar(20);not null;index" json:"kind"`
+	GeoJSON      datatypes.JSON    `gorm:"type:jsonb;not null" json:"geojson,omitempty"`
+	NameProperty string            `gorm:"type:varchar(50);default:'name'" json:"name_property"` // Feature property holding the ward name
+	FeatureCount int               `gorm:"default:0" json:"feature_count"`
+	IsActive     bool              `gorm:"default:true;index" json:"is_active"`
+
+	// Audit fields
+	CreatedBy string         `gorm:"not null" json:"created_by"`
+	UpdatedBy *string        `json:"updated_by"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (bl *BoundaryLayer) BeforeCreate(tx *gorm.DB) error {
+	if bl.ID == uuid.Nil {
+		bl.ID = uuid.New()
+	}
+	return nil
+}
+
+// BoundaryCheckStatus is the outcome of checking a stand's coordinates against the boundary layers
+type BoundaryCheckStatus string
+
+const (
+	BoundaryWithin              BoundaryCheckStatus = "WITHIN"
+	BoundaryOutsideJurisdiction BoundaryCheckStatus = "OUTSIDE_JURISDICTION"
+	BoundaryOutsideWards        BoundaryCheckStatus = "OUTSIDE_WARDS"  // Inside the jurisdiction but in no ward polygon
+	BoundaryWardMismatch        BoundaryCheckStatus = "WARD_MISMATCH"  // The stand's recorded ward differs from the ward it lies in
+	BoundaryNoCoordinates       BoundaryCheckStatus = "NO_COORDINATES" // The stand has no coordinates to check
+)
+
+// BoundaryCheck records one geo-validation of an application's stand. Checks are never updated
+// except to record their review; a re-check adds a new record and the latest is current.
+type BoundaryCheck struct {
+	ID            uuid.UUID           `gorm:"type:uuid;primary_key;" json:"id"`
+	ApplicationID uuid.UUID           `gorm:"type:uuid;not null;index" json:"application_id"`
+	StandID       uuid.UUID           `gorm:"type:uuid;not null;index" json:"stand_id"`
+	Status        BoundaryCheckStatus `gorm:"type:varchar(30);not null;index" json:"status"`
+
+	Latitude     *decimal.Decimal `gorm:"type:decimal(10,8)" json:"latitude"`
+	Longitude    *decimal.Decimal `gorm:"type:decimal(11,8)" json:"longitude"`
+	RecordedWard *string          `gorm:"type:varchar(50)" json:"recorded_ward"` // Ward on the stand record
+	DetectedWard *string          `gorm:"type:varchar(50)" json:"detected_ward"` // Ward polygon containing the coordinates
+	Message      *string          `gorm:"type:text" json:"message"`
+	CheckedAt    time.Time        `gorm:"not null;index" json:"checked_at"`
+
+	// Layers the check was made against
+	JurisdictionLayerID *uuid.UUID `gorm:"type:uuid" json:"jurisdiction_layer_id"`
+	WardLayerID         *uuid.UUID `gorm:"type:uuid" json:"ward_layer_id"`
+
+	// Special review of a flagged check
+	ReviewedByID *uuid.UUID `gorm:"type:uuid;index" json:"reviewed_by_id"`
+	ReviewedAt   *time.Time `json:"reviewed_at"`
+	ReviewNote   *string    `gorm:"type:text" json:"review_note"`
+
+	// Relationships
+	Application *Application `gorm:"foreignKey:ApplicationID" json:"-"`
+	Stand       *Stand       `gorm:"foreignKey:StandID" json:"-"`
+	ReviewedBy  *User        `gorm:"foreignKey:ReviewedByID" json:"reviewed_by,omitempty"`
+
+	// Audit fields
+	CreatedBy string         `gorm:"not null" json:"created_by"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (bc *BoundaryCheck) BeforeCreate(tx *gorm.DB) error {
+	if bc.ID == uuid.Nil {
+		bc.ID = uuid.New()
+	}
+	return nil
+}
+
+// RequiresReview reports whether the check flags the application for special boundary review
+func (bc *BoundaryCheck) RequiresReview() bool {
+	return bc.Status != BoundaryWithin
+}
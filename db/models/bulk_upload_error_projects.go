@@ -10,11 +10,12 @@ import (
 type BulkUploadErrorType string
 
 const (
-	DuplicateErrorType   BulkUploadErrorType = "Duplicate"
-	MissingDataErrorType BulkUploadErrorType = "Missing Data"
-	CalculationErrorType BulkUploadErrorType = "CALCULATION_ERROR"
-	NotFoundErrorType    BulkUploadErrorType = "Stand Not Found"
-	UpdateErrorType      BulkUploadErrorType = "Failed to update stand"
+	DuplicateErrorType      BulkUploadErrorType = "Duplicate"
+	MissingDataErrorType    BulkUploadErrorType = "Missing Data"
+	CalculationErrorType    BulkUploadErrorType = "CALCULATION_ERROR"
+	NotFoundErrorType       BulkUploadErrorType = "Stand Not Found"
+	UpdateErrorType         BulkUploadErrorType = "Failed to update stand"
+	InvalidAddressErrorType BulkUploadErrorType = "Invalid Address" // Not in the suburb reference table
 )
 
 type AddedViaType string
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -15,6 +16,31 @@ const (
 	MessageTypeAction ChatMessageType = "ACTION" // Issue resolved, etc.
 )
 
+// SystemEventType identifies what a SYSTEM message describes. The text is rendered from the
+// event type and its params when the message is read, in the reader's language.
+type SystemEventType string
+
+const (
+	SystemEventParticipantAdded     SystemEventType = "PARTICIPANT_ADDED"
+	SystemEventParticipantsAdded    SystemEventType = "PARTICIPANTS_ADDED"
+	SystemEventParticipantRemoved   SystemEventType = "PARTICIPANT_REMOVED"
+	SystemEventParticipantsRemoved  SystemEventType = "PARTICIPANTS_REMOVED"
+	SystemEventIssueCreated         SystemEventType = "ISSUE_CREATED"
+	SystemEventIssueResolved        SystemEventType = "ISSUE_RESOLVED"
+	SystemEventIssueReopened        SystemEventType = "ISSUE_REOPENED"
+	SystemEventIssueFromMessage     SystemEventType = "ISSUE_FROM_MESSAGE"   // Posted where the message was sent
+	SystemEventIssueSourceMessage   SystemEventType = "ISSUE_SOURCE_MESSAGE" // Posted in the new issue's thread
+	SystemEventDecisionEscalated    SystemEventType = "DECISION_ESCALATED"
+	SystemEventIssueAssigned        SystemEventType = "ISSUE_ASSIGNED"
+	SystemEventIssuePriorityChanged SystemEventType = "ISSUE_PRIORITY_CHANGED"
+	SystemEventParticipantInvited   SystemEventType = "PARTICIPANT_INVITED"
+	SystemEventInvitationAccepted   SystemEventType = "INVITATION_ACCEPTED"
+	SystemEventInvitationDeclined   SystemEventType = "INVITATION_DECLINED"
+	SystemEventApplicationApproved  SystemEventType = "APPLICATION_APPROVED" // Closing message posted when a thread is frozen
+	SystemEventApplicationRejected  SystemEventType = "APPLICATION_REJECTED" // Closing message posted when a thread is frozen
+	SystemEventThreadUnfrozen       SystemEventType = "THREAD_UNFROZEN"
+)
+
 type MessageStatus string
 
 const (
@@ -23,6 +49,14 @@ const (
 	MessageStatusRead      MessageStatus = "READ"
 )
 
+// MessageOrigin records where a message was written
+type MessageOrigin string
+
+const (
+	MessageOriginApp   MessageOrigin = "APP"
+	MessageOriginEmail MessageOrigin = "EMAIL" // Posted from a reply to a chat email digest
+)
+
 type ChatThreadType string
 
 const (
@@ -39,6 +73,17 @@ const (
 	ParticipantRoleMember ParticipantRole = "MEMBER"
 )
 
+// ParticipantInvitationStatus tracks the invitation of a participant from another department.
+// Participants added from the same department are ACCEPTED straight away.
+type ParticipantInvitationStatus string
+
+const (
+	InvitationPending  ParticipantInvitationStatus = "PENDING"
+	InvitationAccepted ParticipantInvitationStatus = "ACCEPTED"
+	InvitationDeclined ParticipantInvitationStatus = "DECLINED"
+	InvitationExpired  ParticipantInvitationStatus = "EXPIRED"
+)
+
 // Updated models without soft delete
 
 type ChatThread struct {
@@ -56,6 +101,11 @@ type ChatThread struct {
 	IsActive        bool      `gorm:"default:true;index" json:"is_active"`
 	IsResolved      bool      `gorm:"default:false;index" json:"is_resolved"`
 
+	// Frozen threads are read-only: once the application is finally decided only users with
+	// chat.moderate may post, until one of them unfreezes the thread
+	IsFrozen bool       `gorm:"default:false;index" json:"is_frozen"`
+	FrozenAt *time.Time `json:"frozen_at"`
+
 	// Real-time tracking - ADDED FOR WEBSOCKET FEATURES
 	LastActivityAt time.Time `gorm:"autoUpdateTime;index" json:"last_activity_at"` // Track last message/activity
 	UnreadCount    int       `gorm:"default:0" json:"unread_count"`                // Cache unread count for performance
@@ -88,6 +138,13 @@ type ChatParticipant struct {
 	// Notification preferences
 	MuteNotifications bool `gorm:"default:false" json:"mute_notifications"`
 
+	// Invitation of a participant from another department. Invitees stay inactive, and cannot
+	// read the thread, until they accept; an unanswered invitation expires.
+	InvitationStatus      ParticipantInvitationStatus `gorm:"type:varchar(20);default:'ACCEPTED';index" json:"invitation_status"`
+	InvitedAt             *time.Time                  `json:"invited_at"`
+	InvitationExpiresAt   *time.Time                  `gorm:"index" json:"invitation_expires_at"`
+	InvitationRespondedAt *time.Time                  `json:"invitation_responded_at"`
+
 	// Real-time status - ADDED FOR WEBSOCKET FEATURES
 	IsOnline    bool       `gorm:"default:false" json:"is_online"` // Track online status
 	LastSeenAt  *time.Time `gorm:"index" json:"last_seen_at"`      // Last seen timestamp
@@ -116,8 +173,13 @@ type ChatMessage struct {
 	Content     string          `gorm:"type:text;not null" json:"content"`
 	MessageType ChatMessageType `gorm:"type:varchar(20);default:'TEXT'" json:"message_type"`
 
+	// Structured system events. Content keeps the English rendering as a fallback.
+	EventType   *SystemEventType `gorm:"type:varchar(50);index" json:"event_type,omitempty"`
+	EventParams datatypes.JSON   `gorm:"type:jsonb" json:"event_params,omitempty"`
+
 	// Message status tracking
 	Status MessageStatus `gorm:"type:varchar(20);default:'SENT'" json:"status"`
+	Origin MessageOrigin `gorm:"type:varchar(10);default:'APP'" json:"origin"`
 
 	// Editing and deletion
 	IsEdited  bool       `gorm:"default:false" json:"is_edited"`
@@ -143,6 +205,7 @@ type ChatMessage struct {
 	Parent       *ChatMessage     `gorm:"foreignKey:ParentID" json:"parent,omitempty"`
 	Attachments  []ChatAttachment `gorm:"foreignKey:MessageID" json:"attachments,omitempty"`
 	ReadReceipts []ReadReceipt    `gorm:"foreignKey:MessageID" json:"read_receipts,omitempty"`
+	LinkPreviews []LinkPreview    `gorm:"foreignKey:MessageID" json:"link_previews,omitempty"`
 
 	// Audit fields
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
@@ -166,10 +229,34 @@ type ReadReceipt struct {
 	User    User        `gorm:"foreignKey:UserID" json:"user"`
 }
 
+// ChatMessageMention records a user named in a message with @, so their queue can show threads
+// where they were mentioned and have not read the message yet
+type ChatMessageMention struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	MessageID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_mention_message_user" json:"message_id"`
+	ThreadID  uuid.UUID `gorm:"type:uuid;not null;index" json:"thread_id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_mention_message_user;index" json:"user_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	// Relationships
+	Message ChatMessage `gorm:"foreignKey:MessageID" json:"-"`
+	User    User        `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// ChatAttachmentKind separates voice notes, which clients play inline, from other files
+type ChatAttachmentKind string
+
+const (
+	ChatAttachmentFile      ChatAttachmentKind = "FILE"
+	ChatAttachmentVoiceNote ChatAttachmentKind = "VOICE_NOTE"
+)
+
 type ChatAttachment struct {
-	ID         uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
-	MessageID  uuid.UUID `gorm:"type:uuid;not null;index" json:"message_id"`
-	DocumentID uuid.UUID `gorm:"type:uuid;not null;index" json:"document_id"`
+	ID         uuid.UUID          `gorm:"type:uuid;primary_key;" json:"id"`
+	MessageID  uuid.UUID          `gorm:"type:uuid;not null;index" json:"message_id"`
+	DocumentID uuid.UUID          `gorm:"type:uuid;not null;index" json:"document_id"`
+	Kind       ChatAttachmentKind `gorm:"type:varchar(20);not null;default:'FILE'" json:"kind"`
+	DurationMs *int64             `json:"duration_ms,omitempty"` // Voice notes only
 
 	// Relationships
 	Message  ChatMessage `gorm:"foreignKey:MessageID" json:"message"`
@@ -223,6 +310,37 @@ type TypingIndicator struct {
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
 }
 
+// ParticipantThreadState is the single record of a user's read, typing and mute state in a thread.
+// Unread counts, read positions and typing status are all derived from this row.
+type ParticipantThreadState struct {
+	ID       uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	ThreadID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_thread_state_thread_user" json:"thread_id"`
+	UserID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_thread_state_thread_user;index" json:"user_id"`
+
+	// Read position
+	LastReadMessageID *uuid.UUID `gorm:"type:uuid" json:"last_read_message_id"`
+	LastReadAt        *time.Time `json:"last_read_at"`
+	UnreadCount       int        `gorm:"not null;default:0" json:"unread_count"`
+
+	// Typing
+	LastTypingAt *time.Time `json:"last_typing_at"`
+
+	// Notification mute
+	IsMuted    bool       `gorm:"default:false" json:"is_muted"`
+	MutedUntil *time.Time `json:"muted_until"` // nil while muted means muted indefinitely
+
+	// Presence: when the user last had the thread open, from read receipts and WebSocket pings
+	LastSeenAt *time.Time `json:"last_seen_at"`
+
+	// Email bridge: the secret in the thread's reply-by-email address for this user, and when
+	// unread messages were last emailed to them
+	EmailReplyToken *string    `gorm:"type:varchar(32);uniqueIndex" json:"-"`
+	EmailBridgedAt  *time.Time `json:"email_bridged_at"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
 // BeforeCreate hooks remain the same
 func (ct *ChatThread) BeforeCreate(tx *gorm.DB) error {
 	if ct.ID == uuid.Nil {
@@ -253,6 +371,13 @@ func (rr *ReadReceipt) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+func (cm *ChatMessageMention) BeforeCreate(tx *gorm.DB) error {
+	if cm.ID == uuid.Nil {
+		cm.ID = uuid.New()
+	}
+	return nil
+}
+
 func (ca *ChatAttachment) BeforeCreate(tx *gorm.DB) error {
 	if ca.ID == uuid.Nil {
 		ca.ID = uuid.New()
@@ -281,6 +406,13 @@ func (ti *TypingIndicator) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+func (ps *ParticipantThreadState) BeforeCreate(tx *gorm.DB) error {
+	if ps.ID == uuid.Nil {
+		ps.ID = uuid.New()
+	}
+	return nil
+}
+
 // Helper methods for real-time features
 
 // UpdateLastActivity updates the thread's last activity timestamp
@@ -331,3 +463,19 @@ func (cm *ChatMessage) MarkAsRead() {
 	cm.Status = MessageStatusRead
 	cm.ReadCount++
 }
+
+// IsTyping reports whether the user typed within the given window
+func (ps *ParticipantThreadState) IsTyping(window time.Duration) bool {
+	if ps.LastTypingAt == nil {
+		return false
+	}
+	return time.Since(*ps.LastTypingAt) < window
+}
+
+// IsMutedNow reports whether notifications are muted, honouring an expired mute
+func (ps *ParticipantThreadState) IsMutedNow() bool {
+	if !ps.IsMuted {
+		return false
+	}
+	return ps.MutedUntil == nil || time.Now().Before(*ps.MutedUntil)
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CollectionAppointmentStatus tracks a permit collection booking
+type CollectionAppointmentStatus string
+
+const (
+	CollectionBooked    CollectionAppointmentStatus = "BOOKED"
+	CollectionCollected CollectionAppointmentStatus = "COLLECTED"
+	CollectionNoShow    CollectionAppointmentStatus = "NO_SHOW"
+	CollectionCancelled CollectionAppointmentStatus = "CANCELLED"
+)
+
+// CollectionCalendar defines when a department's front desk hands out permits.
+// Slots run from OpeningTime to ClosingTime on WorkingDays, each serving up to SlotCapacity applicants.
+type CollectionCalendar struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	DepartmentID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"department_id"`
+
+	OpeningTime         string `gorm:"type:varchar(5);not null;default:'08:00'" json:"opening_time"`      // HH:MM, local time
+	ClosingTime         string `gorm:"type:varchar(5);not null;default:'16:00'" json:"closing_time"`      // HH:MM, local time
+	WorkingDays         string `gorm:"type:varchar(20);not null;default:'1,2,3,4,5'" json:"working_days"` // comma separated, 0 = Sunday
+	SlotDurationMinutes int    `gorm:"not null;default:30" json:"slot_duration_minutes"`
+	SlotCapacity        int    `gorm:"not null;default:1" json:"slot_capacity"`
+	BookingHorizonDays  int    `gorm:"not null;default:14" json:"booking_horizon_days"`
+	IsActive            bool   `gorm:"default:true" json:"is_active"`
+
+	// Relationships
+	Department *Department `gorm:"foreignKey:DepartmentID" json:"department,omitempty"`
+
+	// Audit fields
+	CreatedBy string         `gorm:"not null" json:"created_by"`
+	UpdatedBy *string        `json:"updated_by"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// CollectionAppointment is an applicant's booked slot for collecting a ready permit
+type CollectionAppointment struct {
+	ID            uuid.UUID                   `gorm:"type:uuid;primary_key;" json:"id"`
+	ApplicationID uuid.UUID                   `gorm:"type:uuid;not null;index" json:"application_id"`
+	DepartmentID  uuid.UUID                   `gorm:"type:uuid;not null;index:idx_collection_slot" json:"department_id"`
+	SlotStart     time.Time                   `gorm:"not null;index:idx_collection_slot" json:"slot_start"`
+	SlotEnd       time.Time                   `gorm:"not null" json:"slot_end"`
+	Status        CollectionAppointmentStatus `gorm:"type:varchar(20);not null;default:'BOOKED';index" json:"status"`
+	Notes         *string                     `gorm:"type:text" json:"notes"`
+
+	// Confirmation sent to the applicant
+	ConfirmationSentAt *time.Time `json:"confirmation_sent_at"`
+
+	// Outcome
+	CollectedAt        *time.Time `json:"collected_at"`
+	NoShowMarkedAt     *time.Time `json:"no_show_marked_at"`
+	NoShowMarkedBy     *string    `json:"no_show_marked_by"`
+	FollowUpCompleted  bool       `gorm:"default:false" json:"follow_up_completed"`
+	CancelledAt        *time.Time `json:"cancelled_at"`
+	CancellationReason *string    `gorm:"type:text" json:"cancellation_reason"`
+
+	// Relationships
+	Application *Application `gorm:"foreignKey:ApplicationID" json:"application,omitempty"`
+	Department  *Department  `gorm:"foreignKey:DepartmentID" json:"department,omitempty"`
+
+	// Audit fields
+	CreatedBy string         `gorm:"not null" json:"created_by"`
+	UpdatedBy *string        `json:"updated_by"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (cc *CollectionCalendar) BeforeCreate(tx *gorm.DB) error {
+	if cc.ID == uuid.Nil {
+		cc.ID = uuid.New()
+	}
+	return nil
+}
+
+func (ca *CollectionAppointment) BeforeCreate(tx *gorm.DB) error {
+	if ca.ID == uuid.Nil {
+		ca.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CommitteePackStatus tracks a committee pack from request to download
+type CommitteePackStatus string
+
+const (
+	CommitteePackPending    CommitteePackStatus = "PENDING"
+	CommitteePackGenerating CommitteePackStatus = "GENERATING"
+	CommitteePackCompleted  CommitteePackStatus = "COMPLETED"
+	CommitteePackFailed     CommitteePackStatus = "FAILED"
+)
+
+// CommitteePack is the consolidated PDF printed for a committee meeting: the application's
+// summary sheet, its plans and receipts, decision history and issues. It is generated in the
+// background and kept so the same pack can be downloaded again.
+type CommitteePack struct {
+	ID            uuid.UUID           `gorm:"type:uuid;primary_key;" json:"id"`
+	ApplicationID uuid.UUID           `gorm:"type:uuid;not null;index" json:"application_id"`
+	Status        CommitteePackStatus `gorm:"type:varchar(20);not null;default:'PENDING';index" json:"status"`
+
+	// Generated file, set once the pack completes
+	FileName  *string `gorm:"type:varchar(255)" json:"file_name"`
+	FilePath  *string `gorm:"type:varchar(500)" json:"-"` // Relative to the document storage
+	FileSize  int64   `gorm:"default:0" json:"file_size"`
+	PageNotes *string `gorm:"type:text" json:"page_notes"` // Key documents listed but not reproduced
+	Error     *string `gorm:"type:text" json:"error"`
+
+	RequestedByID uuid.UUID  `gorm:"type:uuid;not null;index" json:"requested_by_id"`
+	StartedAt     *time.Time `json:"started_at"`
+	CompletedAt   *time.Time `json:"completed_at"`
+
+	// Relationships
+	Application *Application `gorm:"foreignKey:ApplicationID" json:"application,omitempty"`
+	RequestedBy *User        `gorm:"foreignKey:RequestedByID" json:"requested_by,omitempty"`
+
+	// Audit fields
+	CreatedBy string         `gorm:"not null" json:"created_by"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (cp *CommitteePack) BeforeCreate(tx *gorm.DB) error {
+	if cp.ID == uuid.Nil {
+		cp.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CountersignatureStatus tracks a certificate routed to a council engineer
+type CountersignatureStatus string
+
+const (
+	CountersignaturePending  CountersignatureStatus = "PENDING"
+	CountersignatureSigned   CountersignatureStatus = "COUNTERSIGNED"
+	CountersignatureDeclined CountersignatureStatus = "DECLINED"
+)
+
+// CertificateCountersignature routes an uploaded engineering certificate to a council engineer
+// for review. Once signed it records the certificate fingerprint that was signed and points at
+// the generated countersignature PDF.
+type CertificateCountersignature struct {
+	ID            uuid.UUID              `gorm:"type:uuid;primary_key;" json:"id"`
+	ApplicationID uuid.UUID              `gorm:"type:uuid;not null;index" json:"application_id"`
+	DocumentID    uuid.UUID              `gorm:"type:uuid;not null;index" json:"document_id"` // The certificate being countersigned
+	EngineerID    uuid.UUID              `gorm:"type:uuid;not null;index" json:"engineer_id"`
+	Status        CountersignatureStatus `gorm:"type:varchar(20);not null;default:'PENDING';index" json:"status"`
+
+	// Routing
+	RoutedByID   uuid.UUID `gorm:"type:uuid;not null" json:"routed_by_id"`
+	RoutedAt     time.Time `gorm:"not null" json:"routed_at"`
+	RoutingNotes *string   `gorm:"type:text" json:"routing_notes"`
+
+	// Engineer's review
+	ReviewComment *string    `gorm:"type:text" json:"review_comment"`
+	SignedAt      *time.Time `json:"signed_at"`
+	DeclinedAt    *time.Time `json:"declined_at"`
+
+	// Signature record. DocumentHash is the certificate's file hash when it was signed, so a later
+	// edit to the certificate no longer matches.
+	DocumentHash  string `gorm:"type:varchar(128)" json:"document_hash"`
+	SignatureHash string `gorm:"type:varchar(64)" json:"signature_hash"`
+
+	// Generated countersignature PDF
+	AnnotatedDocumentID *uuid.UUID `gorm:"type:uuid" json:"annotated_document_id"`
+
+	// Relationships
+	Application       *Application `gorm:"foreignKey:ApplicationID" json:"application,omitempty"`
+	Document          *Document    `gorm:"foreignKey:DocumentID" json:"document,omitempty"`
+	Engineer          *User        `gorm:"foreignKey:EngineerID" json:"engineer,omitempty"`
+	RoutedBy          *User        `gorm:"foreignKey:RoutedByID" json:"routed_by,omitempty"`
+	AnnotatedDocument *Document    `gorm:"foreignKey:AnnotatedDocumentID" json:"annotated_document,omitempty"`
+
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (cs *CertificateCountersignature) BeforeCreate(tx *gorm.DB) error {
+	if cs.ID == uuid.Nil {
+		cs.ID = uuid.New()
+	}
+	return nil
+}
+
+// ComputeSignatureHash binds the signing engineer and time to the certificate fingerprint
+func (cs *CertificateCountersignature) ComputeSignatureHash() string {
+	signedAt := ""
+	if cs.SignedAt != nil {
+		signedAt = cs.SignedAt.UTC().Format(time.RFC3339Nano)
+	}
+	sum := sha256.Sum256([]byte(cs.ID.String() + "|" + cs.DocumentHash + "|" + cs.EngineerID.String() + "|" + signedAt))
+	return hex.EncodeToString(sum[:])
+}
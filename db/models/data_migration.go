@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// DataMigration records that a one-off data migration run at startup has completed, so later
+// starts skip it
+type DataMigration struct {
+	Name        string    `gorm:"type:varchar(100);primary_key" json:"name"`
+	CompletedAt time.Time `gorm:"not null" json:"completed_at"`
+}
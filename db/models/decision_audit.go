@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DecisionAuditExport records a decision audit log handed over for review. The head hash is the
+// last link of the exported hash chain; recomputing the chain from a copy and comparing it with
+// the recorded head shows whether the copy was altered after it left the council.
+type DecisionAuditExport struct {
+	ID uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+
+	PeriodFrom time.Time `gorm:"not null;index" json:"period_from"`
+	PeriodTo   time.Time `gorm:"not null" json:"period_to"` // Exclusive
+	Format     string    `gorm:"type:varchar(10);not null" json:"format"`
+
+	EntryCount  int    `gorm:"not null" json:"entry_count"`
+	GenesisHash string `gorm:"type:varchar(64);not null" json:"genesis_hash"`
+	HeadHash    string `gorm:"type:varchar(64);not null;index" json:"head_hash"`
+
+	ExportedByID uuid.UUID `gorm:"type:uuid;not null;index" json:"exported_by_id"`
+	ExportedAt   time.Time `gorm:"not null" json:"exported_at"`
+
+	// Relationships
+	ExportedBy *User `gorm:"foreignKey:ExportedByID" json:"exported_by,omitempty"`
+
+	// Audit fields
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (dae *DecisionAuditExport) BeforeCreate(tx *gorm.DB) error {
+	if dae.ID == uuid.Nil {
+		dae.ID = uuid.New()
+	}
+	return nil
+}
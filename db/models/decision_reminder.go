@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// IssueCategoryDecisionEscalation marks the COLLABORATIVE issue opened when an approver's
+// decision stays pending past the escalation threshold. One open escalation issue per
+// assignment is reused for every overdue approver on it.
+const IssueCategoryDecisionEscalation = "DECISION_ESCALATION"
+
+// DecisionReminder tracks the reminders sent for one pending member decision and, once the
+// approver has ignored them past the escalation threshold, the issue it was escalated in.
+type DecisionReminder struct {
+	ID            uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	DecisionID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"decision_id"`
+	AssignmentID  uuid.UUID `gorm:"type:uuid;not null;index" json:"assignment_id"`
+	ApplicationID uuid.UUID `gorm:"type:uuid;not null;index" json:"application_id"`
+	UserID        uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+
+	ReminderCount  int        `gorm:"not null;default:0" json:"reminder_count"`
+	LastRemindedAt *time.Time `json:"last_reminded_at"`
+
+	// Escalation to the approver's department head
+	EscalatedAt       *time.Time `gorm:"index" json:"escalated_at"`
+	EscalatedToUserID *uuid.UUID `gorm:"type:uuid" json:"escalated_to_user_id"`
+	EscalationIssueID *uuid.UUID `gorm:"type:uuid;index" json:"escalation_issue_id"`
+
+	// Relationships
+	Decision        *MemberApprovalDecision `gorm:"foreignKey:DecisionID" json:"-"`
+	EscalationIssue *ApplicationIssue       `gorm:"foreignKey:EscalationIssueID" json:"escalation_issue,omitempty"`
+
+	// Audit fields
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (dr *DecisionReminder) BeforeCreate(tx *gorm.DB) error {
+	if dr.ID == uuid.Nil {
+		dr.ID = uuid.New()
+	}
+	return nil
+}
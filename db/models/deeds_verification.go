@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DeedsVerificationStatus is the outcome of checking with the deeds registry that the applicant
+// owns the application's stand
+type DeedsVerificationStatus string
+
+const (
+	DeedsVerified    DeedsVerificationStatus = "VERIFIED"
+	DeedsMismatch    DeedsVerificationStatus = "MISMATCH"    // The stand is registered to someone else
+	DeedsNotFound    DeedsVerificationStatus = "NOT_FOUND"   // The registry has no title for the stand
+	DeedsUnavailable DeedsVerificationStatus = "UNAVAILABLE" // The registry could not confirm ownership either way
+)
+
+// DeedsVerification records one ownership check of an application's stand against the national
+// deeds registry. Checks are never updated; a re-check adds a new record, and the latest one is
+// the application's current verification.
+type DeedsVerification struct {
+	ID            uuid.UUID               `gorm:"type:uuid;primary_key;" json:"id"`
+	ApplicationID uuid.UUID               `gorm:"type:uuid;not null;index" json:"application_id"`
+	StandID       uuid.UUID               `gorm:"type:uuid;not null;index" json:"stand_id"`
+	ApplicantID   uuid.UUID               `gorm:"type:uuid;not null;index" json:"applicant_id"`
+	StandNumber   string                  `gorm:"type:varchar(100);not null" json:"stand_number"`
+	Status        DeedsVerificationStatus `gorm:"type:varchar(20);not null;index" json:"status"`
+
+	// As held by the registry
+	CertificateNumber *string   `gorm:"type:varchar(100);index" json:"certificate_number"`
+	RegisteredOwners  *string   `gorm:"type:text" json:"registered_owners"` // Comma separated names
+	Message           *string   `gorm:"type:text" json:"message"`
+	CheckedAt         time.Time `gorm:"not null;index" json:"checked_at"`
+
+	// Relationships
+	Application *Application `gorm:"foreignKey:ApplicationID" json:"-"`
+	Stand       *Stand       `gorm:"foreignKey:StandID" json:"-"`
+	Applicant   *Applicant   `gorm:"foreignKey:ApplicantID" json:"-"`
+
+	// Audit fields
+	CreatedBy string         `gorm:"not null" json:"created_by"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (dv *DeedsVerification) BeforeCreate(tx *gorm.DB) error {
+	if dv.ID == uuid.Nil {
+		dv.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsStale reports whether the check is older than maxAge, so ownership may have changed since
+func (dv *DeedsVerification) IsStale(maxAge time.Duration, now time.Time) bool {
+	return now.Sub(dv.CheckedAt) > maxAge
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// ClassificationSuggestionStatus tracks what the uploader did with a suggested category
+type ClassificationSuggestionStatus string
+
+const (
+	SuggestionPending  ClassificationSuggestionStatus = "PENDING"
+	SuggestionAccepted ClassificationSuggestionStatus = "ACCEPTED"
+	SuggestionRejected ClassificationSuggestionStatus = "REJECTED"
+)
+
+// DocumentClassificationSuggestion is a category the classifier proposed for a document uploaded
+// without a category (or as OTHER). Each suggestion records the rule that produced it so the
+// acceptance rate of every rule can be measured and poor rules tuned or removed.
+type DocumentClassificationSuggestion struct {
+	ID                  uuid.UUID                      `gorm:"type:uuid;primary_key;" json:"id"`
+	DocumentID          uuid.UUID                      `gorm:"type:uuid;not null;index" json:"document_id"`
+	SuggestedCategoryID uuid.UUID                      `gorm:"type:uuid;not null" json:"suggested_category_id"`
+	Rule                string                         `gorm:"type:varchar(100);not null;index" json:"rule"`
+	Confidence          decimal.Decimal                `gorm:"type:decimal(4,2);not null" json:"confidence"`
+	Status              ClassificationSuggestionStatus `gorm:"type:varchar(20);not null;default:'PENDING';index" json:"status"`
+	ResolvedBy          *string                        `json:"resolved_by"`
+	ResolvedAt          *time.Time                     `json:"resolved_at"`
+
+	// Relationships
+	Document          *Document         `gorm:"foreignKey:DocumentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	SuggestedCategory *DocumentCategory `gorm:"foreignKey:SuggestedCategoryID" json:"suggested_category,omitempty"`
+
+	// Audit fields
+	CreatedBy string         `gorm:"not null" json:"created_by"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (s *DocumentClassificationSuggestion) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
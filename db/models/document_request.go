@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// DocumentRequestChannel is how a request for outstanding documents reached the applicant
+type DocumentRequestChannel string
+
+const (
+	DocumentRequestByEmail    DocumentRequestChannel = "EMAIL"
+	DocumentRequestByWhatsApp DocumentRequestChannel = "WHATSAPP" // Composed for staff to send from WhatsApp
+)
+
+// DocumentRequest records staff asking an applicant for the checklist documents missing from an
+// application. Items holds the checklist items requested, as they were when the request was sent.
+type DocumentRequest struct {
+	ID            uuid.UUID              `gorm:"type:uuid;primary_key;" json:"id"`
+	ApplicationID uuid.UUID              `gorm:"type:uuid;not null;index" json:"application_id"`
+	ApplicantID   uuid.UUID              `gorm:"type:uuid;not null;index" json:"applicant_id"`
+	RequestedByID uuid.UUID              `gorm:"type:uuid;not null" json:"requested_by_id"`
+	Channel       DocumentRequestChannel `gorm:"type:varchar(20);not null" json:"channel"`
+	Recipient     string                 `gorm:"type:varchar(255);not null" json:"recipient"` // Email address or phone number
+	Items         datatypes.JSON         `gorm:"type:jsonb;not null" json:"items"`
+	Note          *string                `gorm:"type:text" json:"note"`
+	Message       string                 `gorm:"type:text;not null" json:"message"` // As sent to the applicant
+
+	// Relationships
+	RequestedBy *User `gorm:"foreignKey:RequestedByID" json:"requested_by,omitempty"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime;index" json:"created_at"`
+}
+
+func (dr *DocumentRequest) BeforeCreate(tx *gorm.DB) error {
+	if dr.ID == uuid.Nil {
+		dr.ID = uuid.New()
+	}
+	return nil
+}
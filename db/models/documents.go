@@ -23,6 +23,8 @@ const (
 	ActionReplace Action = "REPLACE"
 	ActionPending Action = "PENDING"
 	ActionRevise  Action = "REVERSE"
+
+	ActionDownload Action = "DOWNLOAD" // A copy was downloaded, recorded for controlled distribution
 )
 
 // DocumentType with housing-specific types
@@ -40,6 +42,17 @@ const (
 	EngineeringCertificate DocumentType = "ENGINEERING_CERTIFICATE"
 	BuildingPlanType       DocumentType = "BUILDING_PLAN"
 	SitePlanType           DocumentType = "SITE_PLAN"
+	AudioType              DocumentType = "AUDIO"
+)
+
+// WatermarkPolicy says when downloads of a category's documents must carry a watermark naming
+// who downloaded them
+type WatermarkPolicy string
+
+const (
+	WatermarkOptional        WatermarkPolicy = "OPTIONAL"         // Only when the downloader asks for one
+	WatermarkExternalSharing WatermarkPolicy = "EXTERNAL_SHARING" // Always when downloaded for sharing outside the council
+	WatermarkAlways          WatermarkPolicy = "ALWAYS"           // On every download
 )
 
 // DocumentCategory represents document categories
@@ -51,6 +64,9 @@ type DocumentCategory struct {
 	IsSystem    bool      `gorm:"default:false" json:"is_system"`
 	IsActive    bool      `gorm:"default:true" json:"is_active"`
 
+	// When downloads of the category's documents are watermarked
+	WatermarkPolicy WatermarkPolicy `gorm:"type:varchar(20);not null;default:'OPTIONAL'" json:"watermark_policy"`
+
 	// Audit fields
 	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
@@ -58,6 +74,18 @@ type DocumentCategory struct {
 	CreatedBy string         `gorm:"not null" json:"created_by"`
 }
 
+// WatermarkRequired reports whether a download must be watermarked under the policy
+func (p WatermarkPolicy) WatermarkRequired(externalSharing bool) bool {
+	switch p {
+	case WatermarkAlways:
+		return true
+	case WatermarkExternalSharing:
+		return externalSharing
+	default:
+		return false
+	}
+}
+
 // Document model - CLEANED UP with only core fields and versioning
 type Document struct {
 	ID           uuid.UUID       `gorm:"type:uuid;primary_key;" json:"id"`
@@ -72,6 +100,10 @@ type Document struct {
 	FileHash string `gorm:"index" json:"file_hash"`
 	MimeType string `json:"mime_type"`
 
+	// Set when an image was re-encoded without its EXIF and other metadata. Nil for other files
+	// and for image formats that cannot be re-encoded.
+	MetadataScrubbedAt *time.Time `json:"metadata_scrubbed_at"`
+
 	// Document metadata
 	Description *string `gorm:"type:text" json:"description"`
 	IsPublic    bool    `gorm:"default:false" json:"is_public"`
@@ -7,11 +7,24 @@ import (
 	"gorm.io/gorm"
 )
 
+// Email statuses. QUEUED emails are waiting for the send worker; DELIVERED, BOUNCED and
+// COMPLAINED come from the provider's webhook.
+const (
+	EmailStatusQueued     = "QUEUED"
+	EmailStatusSent       = "SENT"
+	EmailStatusFailed     = "FAILED"
+	EmailStatusDelivered  = "DELIVERED"
+	EmailStatusBounced    = "BOUNCED"
+	EmailStatusComplained = "COMPLAINED"
+)
+
 type EmailLog struct {
 	ID             uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
 	Recipient      string    `gorm:"not null" json:"recipient"`
 	Subject        string    `gorm:"not null" json:"subject"`
 	Message        string    `gorm:"type:text;not null" json:"message"`
+	HTMLMessage    *string   `gorm:"type:text" json:"html_message,omitempty"`
+	ReplyTo        *string   `gorm:"type:varchar(255)" json:"reply_to,omitempty"` // Set when replies are routed back into the app
 	SentAt         time.Time `gorm:"not null" json:"sent_at"`
 	Active         *bool     `gorm:"default:true" json:"active"`
 	AttachmentPath string    `json:"attachment_path"` // Legacy field for backward compatibility
@@ -31,10 +44,29 @@ type EmailLog struct {
 
 	// Additional email metadata
 	EmailType    string  `gorm:"type:varchar(50)" json:"email_type"` // e.g., "APPLICATION_SUBMITTED", "PAYMENT_RECEIPT"
-	Status       string  `gorm:"type:varchar(20);default:'SENT'" json:"status"` // SENT, FAILED, DELIVERED
+	Status       string  `gorm:"type:varchar(20);default:'SENT'" json:"status"` // QUEUED, SENT, FAILED, DELIVERED, BOUNCED, COMPLAINED
 	Error        *string `gorm:"type:text" json:"error,omitempty"`
 	TemplateName *string `gorm:"type:varchar(100)" json:"template_name,omitempty"`
 
+	// Delivery through the email provider
+	Provider          string  `gorm:"type:varchar(20)" json:"provider"` // smtp, sendgrid, ses
+	ProviderMessageID *string `gorm:"type:varchar(255);index" json:"provider_message_id,omitempty"`
+	Attempts          int     `gorm:"default:0" json:"attempts"`
+
+	// Bounce and complaint reports
+	DeliveredAt  *time.Time `json:"delivered_at,omitempty"`
+	BouncedAt    *time.Time `json:"bounced_at,omitempty"`
+	BounceType   *string    `gorm:"type:varchar(50)" json:"bounce_type,omitempty"`
+	ComplainedAt *time.Time `json:"complained_at,omitempty"`
+
+	// Open and click tracking, only for applicant-facing notifications
+	TrackOpens    bool       `gorm:"default:false" json:"track_opens"`
+	TrackClicks   bool       `gorm:"default:false" json:"track_clicks"`
+	OpenCount     int        `gorm:"default:0" json:"open_count"`
+	FirstOpenedAt *time.Time `json:"first_opened_at,omitempty"`
+	ClickCount    int        `gorm:"default:0" json:"click_count"`
+	LastClickedAt *time.Time `json:"last_clicked_at,omitempty"`
+
 	// Audit fields
 	CreatedBy string    `gorm:"not null" json:"created_by"`
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// FileNamingPolicy is the pattern stored document files are named with, built from tokens such
+// as {category} and {plan_number}. The policy without a category is the council default; a
+// category policy overrides it for documents in that category.
+type FileNamingPolicy struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key;" json:"id"`
+	CategoryID *uuid.UUID `gorm:"type:uuid;uniqueIndex" json:"category_id"`
+	Pattern    string     `gorm:"type:varchar(255);not null" json:"pattern"`
+
+	// Relationships
+	Category *DocumentCategory `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+
+	// Audit fields
+	CreatedBy string    `gorm:"not null" json:"created_by"`
+	UpdatedBy *string   `json:"updated_by"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (p *FileNamingPolicy) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
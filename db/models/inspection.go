@@ -0,0 +1,216 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ========================================
+// ENUM DEFINITIONS
+// ========================================
+
+type InspectionStatus string
+
+const (
+	InspectionStatusScheduled  InspectionStatus = "SCHEDULED"
+	InspectionStatusInProgress InspectionStatus = "IN_PROGRESS"
+	InspectionStatusCompleted  InspectionStatus = "COMPLETED"
+	InspectionStatusCancelled  InspectionStatus = "CANCELLED"
+)
+
+type InspectionOutcome string
+
+const (
+	InspectionOutcomePassed      InspectionOutcome = "PASSED"
+	InspectionOutcomeFailed      InspectionOutcome = "FAILED"
+	InspectionOutcomeConditional InspectionOutcome = "CONDITIONAL"
+)
+
+type ChecklistItemResult string
+
+const (
+	ChecklistResultPending       ChecklistItemResult = "PENDING"
+	ChecklistResultPass          ChecklistItemResult = "PASS"
+	ChecklistResultFail          ChecklistItemResult = "FAIL"
+	ChecklistResultNotApplicable ChecklistItemResult = "NOT_APPLICABLE"
+)
+
+type SyncMutationStatus string
+
+const (
+	SyncMutationApplied  SyncMutationStatus = "APPLIED"
+	SyncMutationConflict SyncMutationStatus = "CONFLICT"
+	SyncMutationRejected SyncMutationStatus = "REJECTED"
+)
+
+// PhotoVerificationStatus is the verdict on whether a photo's embedded capture time and GPS
+// position match the inspection. FLAGGED photos wait for a supervisor, who accepts or rejects them.
+type PhotoVerificationStatus string
+
+const (
+	PhotoVerificationPending      PhotoVerificationStatus = "PENDING"
+	PhotoVerificationVerified     PhotoVerificationStatus = "VERIFIED"
+	PhotoVerificationUnverifiable PhotoVerificationStatus = "UNVERIFIABLE"
+	PhotoVerificationFlagged      PhotoVerificationStatus = "FLAGGED"
+	PhotoVerificationAccepted     PhotoVerificationStatus = "ACCEPTED"
+	PhotoVerificationRejected     PhotoVerificationStatus = "REJECTED"
+)
+
+// ========================================
+// INSPECTION MODELS
+// ========================================
+
+// Inspection is a site visit carried out by a building inspector against an application.
+// Version is bumped on every server-side write so offline clients can detect conflicts.
+type Inspection struct {
+	ID            uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	ApplicationID uuid.UUID `gorm:"type:uuid;not null;index" json:"application_id"`
+	InspectorID   uuid.UUID `gorm:"type:uuid;not null;index" json:"inspector_id"`
+
+	InspectionType string             `gorm:"type:varchar(100);not null" json:"inspection_type"`
+	Status         InspectionStatus   `gorm:"type:varchar(20);default:'SCHEDULED';index" json:"status"`
+	Outcome        *InspectionOutcome `gorm:"type:varchar(20)" json:"outcome"`
+	ScheduledDate  *time.Time         `json:"scheduled_date"`
+	StartedAt      *time.Time         `json:"started_at"`
+	CompletedAt    *time.Time         `json:"completed_at"`
+	Notes          *string            `gorm:"type:text" json:"notes"`
+
+	Latitude  *decimal.Decimal `gorm:"type:decimal(10,8)" json:"latitude"`
+	Longitude *decimal.Decimal `gorm:"type:decimal(11,8)" json:"longitude"`
+
+	// Worst verdict across the inspection's photos, and how many still wait for a supervisor
+	PhotoVerification  PhotoVerificationStatus `gorm:"type:varchar(20);default:'PENDING';index" json:"photo_verification"`
+	FlaggedPhotosCount int                     `gorm:"default:0" json:"flagged_photos_count"`
+
+	// Offline sync bookkeeping
+	Version         int        `gorm:"default:1;not null" json:"version"`
+	ClientUpdatedAt *time.Time `json:"client_updated_at"`
+
+	// Relationships
+	Application    *Application              `gorm:"foreignKey:ApplicationID" json:"application,omitempty"`
+	Inspector      *User                     `gorm:"foreignKey:InspectorID" json:"inspector,omitempty"`
+	ChecklistItems []InspectionChecklistItem `gorm:"foreignKey:InspectionID" json:"checklist_items,omitempty"`
+	Photos         []InspectionPhoto         `gorm:"foreignKey:InspectionID" json:"photos,omitempty"`
+
+	// Audit fields
+	CreatedBy string         `gorm:"not null" json:"created_by"`
+	UpdatedBy *string        `json:"updated_by"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime;index" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+// InspectionChecklistItem is a single line on the inspector's checklist
+type InspectionChecklistItem struct {
+	ID           uuid.UUID           `gorm:"type:uuid;primary_key;" json:"id"`
+	InspectionID uuid.UUID           `gorm:"type:uuid;not null;index" json:"inspection_id"`
+	Code         string              `gorm:"type:varchar(50);not null" json:"code"`
+	Label        string              `gorm:"type:varchar(255);not null" json:"label"`
+	Result       ChecklistItemResult `gorm:"type:varchar(20);default:'PENDING'" json:"result"`
+	Notes        *string             `gorm:"type:text" json:"notes"`
+	SortOrder    int                 `gorm:"default:0" json:"sort_order"`
+
+	Version         int        `gorm:"default:1;not null" json:"version"`
+	ClientUpdatedAt *time.Time `json:"client_updated_at"`
+
+	CreatedBy string         `gorm:"not null" json:"created_by"`
+	UpdatedBy *string        `json:"updated_by"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime;index" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+// InspectionPhoto holds the metadata of a photo captured on site. The record can be
+// created offline before the binary is uploaded, in which case FilePath is empty.
+type InspectionPhoto struct {
+	ID           uuid.UUID        `gorm:"type:uuid;primary_key;" json:"id"`
+	InspectionID uuid.UUID        `gorm:"type:uuid;not null;index" json:"inspection_id"`
+	FilePath     *string          `json:"file_path"`
+	MimeType     *string          `gorm:"type:varchar(100)" json:"mime_type"`
+	FileSize     *int64           `json:"file_size"`
+	Caption      *string          `gorm:"type:varchar(500)" json:"caption"`
+	Latitude     *decimal.Decimal `gorm:"type:decimal(10,8)" json:"latitude"`
+	Longitude    *decimal.Decimal `gorm:"type:decimal(11,8)" json:"longitude"`
+	TakenAt      *time.Time       `json:"taken_at"`
+	UploadedAt   *time.Time       `json:"uploaded_at"`
+
+	// Set when the uploaded file was re-encoded without its EXIF metadata. The capture time is
+	// kept in TakenAt and the position in Latitude and Longitude.
+	MetadataScrubbedAt *time.Time `json:"metadata_scrubbed_at"`
+
+	// Also show the photo in the gallery of the application's stand
+	AddToStandGallery bool `gorm:"default:false" json:"add_to_stand_gallery"`
+
+	// Checked against the EXIF capture time and GPS position read before scrubbing, which the
+	// app cannot edit the way it can TakenAt, Latitude and Longitude
+	VerificationStatus     PhotoVerificationStatus `gorm:"type:varchar(20);default:'PENDING';index" json:"verification_status"`
+	VerificationReasons    datatypes.JSON          `gorm:"type:json" json:"verification_reasons"`
+	ExifCapturedAt         *time.Time              `json:"exif_captured_at"`
+	ExifLatitude           *decimal.Decimal        `gorm:"type:decimal(10,8)" json:"exif_latitude"`
+	ExifLongitude          *decimal.Decimal        `gorm:"type:decimal(11,8)" json:"exif_longitude"`
+	DistanceFromSiteMeters *decimal.Decimal        `gorm:"type:decimal(12,2)" json:"distance_from_site_meters"`
+	VerifiedAt             *time.Time              `json:"verified_at"`
+
+	// Supervisor review of a flagged photo
+	ReviewedBy  *uuid.UUID `gorm:"type:uuid" json:"reviewed_by"`
+	ReviewedAt  *time.Time `json:"reviewed_at"`
+	ReviewNotes *string    `gorm:"type:text" json:"review_notes"`
+
+	Version         int        `gorm:"default:1;not null" json:"version"`
+	ClientUpdatedAt *time.Time `json:"client_updated_at"`
+
+	CreatedBy string         `gorm:"not null" json:"created_by"`
+	UpdatedBy *string        `json:"updated_by"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime;index" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+// SyncMutation records every mutation pushed by a mobile client, keyed by the
+// client generated idempotency key, so that retried pushes return the original result.
+type SyncMutation struct {
+	ID             uuid.UUID          `gorm:"type:uuid;primary_key;" json:"id"`
+	IdempotencyKey string             `gorm:"type:varchar(100);uniqueIndex;not null" json:"idempotency_key"`
+	UserID         uuid.UUID          `gorm:"type:uuid;not null;index" json:"user_id"`
+	DeviceID       string             `gorm:"type:varchar(100);index" json:"device_id"`
+	EntityType     string             `gorm:"type:varchar(50);not null" json:"entity_type"`
+	EntityID       uuid.UUID          `gorm:"type:uuid;not null;index" json:"entity_id"`
+	Operation      string             `gorm:"type:varchar(20);not null" json:"operation"`
+	Status         SyncMutationStatus `gorm:"type:varchar(20);not null" json:"status"`
+	Message        *string            `gorm:"type:text" json:"message"`
+	ServerVersion  int                `json:"server_version"`
+	Payload        datatypes.JSON     `gorm:"type:json" json:"payload"`
+	CreatedAt      time.Time          `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (i *Inspection) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+func (ci *InspectionChecklistItem) BeforeCreate(tx *gorm.DB) error {
+	if ci.ID == uuid.Nil {
+		ci.ID = uuid.New()
+	}
+	return nil
+}
+
+func (ip *InspectionPhoto) BeforeCreate(tx *gorm.DB) error {
+	if ip.ID == uuid.Nil {
+		ip.ID = uuid.New()
+	}
+	return nil
+}
+
+func (sm *SyncMutation) BeforeCreate(tx *gorm.DB) error {
+	if sm.ID == uuid.Nil {
+		sm.ID = uuid.New()
+	}
+	return nil
+}
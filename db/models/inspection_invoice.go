@@ -0,0 +1,113 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// InspectionInvoiceStatus tracks payment of an inspection's fee
+type InspectionInvoiceStatus string
+
+const (
+	InspectionInvoiceUnpaid    InspectionInvoiceStatus = "UNPAID"
+	InspectionInvoicePaid      InspectionInvoiceStatus = "PAID"
+	InspectionInvoiceWaived    InspectionInvoiceStatus = "WAIVED"    // Written off by a finance officer
+	InspectionInvoiceCancelled InspectionInvoiceStatus = "CANCELLED" // The inspection was cancelled before it was paid
+)
+
+// InspectionInvoiceTrigger is the point in an inspection's life at which it was invoiced
+type InspectionInvoiceTrigger string
+
+const (
+	InspectionInvoiceOnSchedule   InspectionInvoiceTrigger = "SCHEDULED"
+	InspectionInvoiceOnCompletion InspectionInvoiceTrigger = "COMPLETED"
+)
+
+// InspectionInvoice bills a single inspection visit at the application tariff's inspection fee,
+// for councils that charge per visit instead of through the tariff. An inspection is invoiced
+// at most once.
+type InspectionInvoice struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key;" json:"id"`
+	InvoiceNumber string     `gorm:"uniqueIndex;not null" json:"invoice_number"`
+	InspectionID  uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"inspection_id"`
+	ApplicationID uuid.UUID  `gorm:"type:uuid;not null;index" json:"application_id"`
+	TariffID      *uuid.UUID `gorm:"type:uuid;index" json:"tariff_id"`
+
+	Amount   decimal.Decimal          `gorm:"type:decimal(15,2);not null" json:"amount"`
+	Currency string                   `gorm:"type:varchar(10);not null" json:"currency"`
+	Trigger  InspectionInvoiceTrigger `gorm:"type:varchar(20);not null" json:"trigger"`
+	Status   InspectionInvoiceStatus  `gorm:"type:varchar(20);not null;default:'UNPAID';index" json:"status"`
+	IssuedAt time.Time                `gorm:"not null" json:"issued_at"`
+
+	// Settlement
+	PaymentID    *uuid.UUID `gorm:"type:uuid;index" json:"payment_id"`
+	PaidAt       *time.Time `json:"paid_at"`
+	WaivedByID   *uuid.UUID `gorm:"type:uuid" json:"waived_by_id"`
+	WaivedAt     *time.Time `json:"waived_at"`
+	WaiverReason *string    `gorm:"type:text" json:"waiver_reason"`
+
+	// Relationships
+	Inspection *Inspection `gorm:"foreignKey:InspectionID" json:"inspection,omitempty"`
+	Payment    *Payment    `gorm:"foreignKey:PaymentID" json:"payment,omitempty"`
+
+	// Audit fields
+	CreatedBy string    `gorm:"not null" json:"created_by"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// IsOutstanding reports whether the invoice still has to be paid
+func (ii *InspectionInvoice) IsOutstanding() bool {
+	return ii.Status == InspectionInvoiceUnpaid
+}
+
+func (ii *InspectionInvoice) BeforeCreate(tx *gorm.DB) error {
+	if ii.ID == uuid.Nil {
+		ii.ID = uuid.New()
+	}
+	if ii.InvoiceNumber == "" {
+		ii.InvoiceNumber = fmt.Sprintf("INSP-INV-%s", strings.ToUpper(uuid.NewString()[0:8]))
+	}
+	if ii.IssuedAt.IsZero() {
+		ii.IssuedAt = time.Now()
+	}
+	return nil
+}
+
+// ComplianceCertificate records that the council certified a completed development as
+// complying with its permit. It is issued once per application, after an inspection passed and
+// every inspection invoice was settled.
+type ComplianceCertificate struct {
+	ID                uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	CertificateNumber string    `gorm:"uniqueIndex;not null" json:"certificate_number"`
+	ApplicationID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"application_id"`
+	InspectionID      uuid.UUID `gorm:"type:uuid;not null;index" json:"inspection_id"` // Passed inspection certified
+	IssuedByID        uuid.UUID `gorm:"type:uuid;not null" json:"issued_by_id"`
+	IssuedAt          time.Time `gorm:"not null" json:"issued_at"`
+	Notes             *string   `gorm:"type:text" json:"notes"`
+
+	// Relationships
+	Inspection *Inspection `gorm:"foreignKey:InspectionID" json:"inspection,omitempty"`
+
+	// Audit fields
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (cc *ComplianceCertificate) BeforeCreate(tx *gorm.DB) error {
+	if cc.ID == uuid.Nil {
+		cc.ID = uuid.New()
+	}
+	if cc.CertificateNumber == "" {
+		cc.CertificateNumber = fmt.Sprintf("COC-%s", strings.ToUpper(uuid.NewString()[0:8]))
+	}
+	if cc.IssuedAt.IsZero() {
+		cc.IssuedAt = time.Now()
+	}
+	return nil
+}
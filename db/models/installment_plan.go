@@ -0,0 +1,143 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// InstallmentPlanStatus tracks whether a levy installment plan still has money owing
+type InstallmentPlanStatus string
+
+const (
+	InstallmentPlanActive    InstallmentPlanStatus = "ACTIVE"
+	InstallmentPlanSettled   InstallmentPlanStatus = "SETTLED"
+	InstallmentPlanCancelled InstallmentPlanStatus = "CANCELLED"
+)
+
+// InstallmentStatus tracks payment of a single scheduled installment
+type InstallmentStatus string
+
+const (
+	InstallmentPending InstallmentStatus = "PENDING"
+	InstallmentPartial InstallmentStatus = "PARTIAL"
+	InstallmentPaid    InstallmentStatus = "PAID"
+)
+
+// InstallmentPlan spreads an application's TotalCost over scheduled installments so large
+// developments can start review before the levy is fully paid. The permit is only issued
+// once the plan is settled.
+type InstallmentPlan struct {
+	ID            uuid.UUID             `gorm:"type:uuid;primary_key;" json:"id"`
+	ApplicationID uuid.UUID             `gorm:"type:uuid;not null;index" json:"application_id"`
+	Status        InstallmentPlanStatus `gorm:"type:varchar(20);not null;default:'ACTIVE';index" json:"status"`
+
+	// TotalAmount is the application's TotalCost when the plan was agreed
+	TotalAmount    decimal.Decimal `gorm:"type:decimal(15,2);not null" json:"total_amount"`
+	PaidAmount     decimal.Decimal `gorm:"type:decimal(15,2);not null;default:0" json:"paid_amount"`
+	IntervalMonths int             `gorm:"not null;default:1" json:"interval_months"`
+
+	// Council policy: when set the final approver may approve before the plan is settled.
+	// The permit itself is still withheld until settlement.
+	AllowConditionalApproval bool `gorm:"default:false" json:"allow_conditional_approval"`
+
+	SettledAt   *time.Time `json:"settled_at"`
+	CancelledAt *time.Time `json:"cancelled_at"`
+
+	// Relationships
+	Application  *Application  `gorm:"foreignKey:ApplicationID" json:"application,omitempty"`
+	Installments []Installment `gorm:"foreignKey:PlanID" json:"installments,omitempty"`
+
+	// Audit fields
+	CreatedBy string         `gorm:"not null" json:"created_by"`
+	UpdatedBy *string        `json:"updated_by"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// Installment is one scheduled amount of a plan
+type Installment struct {
+	ID         uuid.UUID         `gorm:"type:uuid;primary_key;" json:"id"`
+	PlanID     uuid.UUID         `gorm:"type:uuid;not null;uniqueIndex:idx_installment_plan_sequence" json:"plan_id"`
+	Sequence   int               `gorm:"not null;uniqueIndex:idx_installment_plan_sequence" json:"sequence"`
+	DueDate    time.Time         `gorm:"not null;index" json:"due_date"`
+	AmountDue  decimal.Decimal   `gorm:"type:decimal(15,2);not null" json:"amount_due"`
+	AmountPaid decimal.Decimal   `gorm:"type:decimal(15,2);not null;default:0" json:"amount_paid"`
+	Status     InstallmentStatus `gorm:"type:varchar(20);not null;default:'PENDING';index" json:"status"`
+	PaidAt     *time.Time        `json:"paid_at"` // When the installment was paid in full
+
+	// Relationships
+	Allocations []InstallmentAllocation `gorm:"foreignKey:InstallmentID" json:"allocations,omitempty"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// InstallmentAllocation records how much of a payment went toward an installment. A payment
+// larger than the next installment is spread over the following ones.
+type InstallmentAllocation struct {
+	ID            uuid.UUID       `gorm:"type:uuid;primary_key;" json:"id"`
+	InstallmentID uuid.UUID       `gorm:"type:uuid;not null;index" json:"installment_id"`
+	PaymentID     uuid.UUID       `gorm:"type:uuid;not null;index" json:"payment_id"`
+	Amount        decimal.Decimal `gorm:"type:decimal(15,2);not null" json:"amount"`
+
+	// Relationships
+	Payment *Payment `gorm:"foreignKey:PaymentID" json:"payment,omitempty"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (ip *InstallmentPlan) BeforeCreate(tx *gorm.DB) error {
+	if ip.ID == uuid.Nil {
+		ip.ID = uuid.New()
+	}
+	return nil
+}
+
+func (i *Installment) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+func (ia *InstallmentAllocation) BeforeCreate(tx *gorm.DB) error {
+	if ia.ID == uuid.Nil {
+		ia.ID = uuid.New()
+	}
+	return nil
+}
+
+// Outstanding returns what is still owed on the plan
+func (ip *InstallmentPlan) Outstanding() decimal.Decimal {
+	return ip.TotalAmount.Sub(ip.PaidAmount)
+}
+
+// IsSettled reports whether the plan has been paid in full
+func (ip *InstallmentPlan) IsSettled() bool {
+	return ip.Status == InstallmentPlanSettled
+}
+
+// Outstanding returns what is still owed on the installment
+func (i *Installment) Outstanding() decimal.Decimal {
+	return i.AmountDue.Sub(i.AmountPaid)
+}
+
+// IsOverdue reports whether the installment is past due and not fully paid
+func (i *Installment) IsOverdue(asOf time.Time) bool {
+	return i.Status != InstallmentPaid && i.DueDate.Before(asOf)
+}
+
+// Arrears sums what is owed on installments that are past due. Installments must be loaded.
+func (ip *InstallmentPlan) Arrears(asOf time.Time) decimal.Decimal {
+	arrears := decimal.Zero
+	for i := range ip.Installments {
+		if ip.Installments[i].IsOverdue(asOf) {
+			arrears = arrears.Add(ip.Installments[i].Outstanding())
+		}
+	}
+	return arrears
+}
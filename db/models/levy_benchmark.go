@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// LevyBenchmark is the levy and stand market value the council expects per square metre for a
+// development category, in one ward or, when Ward is nil, across the council. Applications that
+// stray further than TolerancePercent from it are flagged on the levy benchmarking report, most
+// often because their plan area was entered wrongly.
+type LevyBenchmark struct {
+	ID                        uuid.UUID        `gorm:"type:uuid;primary_key;" json:"id"`
+	DevelopmentCategoryID     uuid.UUID        `gorm:"type:uuid;not null;index" json:"development_category_id"`
+	Ward                      *string          `gorm:"type:varchar(50);index" json:"ward"` // Nil applies to every ward without its own benchmark
+	LevyPerSquareMeter        decimal.Decimal  `gorm:"type:decimal(15,2);not null" json:"levy_per_square_meter"`
+	MarketValuePerSquareMeter *decimal.Decimal `gorm:"type:decimal(15,2)" json:"market_value_per_square_meter"` // Stand value per square metre of stand area
+	TolerancePercent          decimal.Decimal  `gorm:"type:decimal(5,2);not null;default:25" json:"tolerance_percent"`
+	Notes                     *string          `gorm:"type:text" json:"notes"`
+
+	// Relationships
+	DevelopmentCategory *DevelopmentCategory `gorm:"foreignKey:DevelopmentCategoryID" json:"development_category,omitempty"`
+
+	// Audit fields
+	CreatedBy string         `gorm:"not null" json:"created_by"`
+	UpdatedBy *string        `json:"updated_by"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (lb *LevyBenchmark) BeforeCreate(tx *gorm.DB) error {
+	if lb.ID == uuid.Nil {
+		lb.ID = uuid.New()
+	}
+	return nil
+}
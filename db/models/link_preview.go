@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LinkPreviewStatus tracks fetching a link's Open Graph metadata
+type LinkPreviewStatus string
+
+const (
+	LinkPreviewReady  LinkPreviewStatus = "READY"  // Metadata fetched
+	LinkPreviewFailed LinkPreviewStatus = "FAILED" // Page unreachable, not HTML or blocked; no card is shown
+)
+
+// LinkPreview is the Open Graph card for a URL found in a chat message. Previews are fetched in
+// the background after the message is sent, so a message may have none for a moment after it
+// is delivered.
+type LinkPreview struct {
+	ID        uuid.UUID         `gorm:"type:uuid;primary_key;" json:"id"`
+	MessageID uuid.UUID         `gorm:"type:uuid;not null;index" json:"message_id"`
+	URL       string            `gorm:"type:text;not null;index" json:"url"` // As written in the message
+	Position  int               `gorm:"not null;default:0" json:"position"`  // Order of the URL in the message
+	Status    LinkPreviewStatus `gorm:"type:varchar(20);not null;index" json:"status"`
+
+	// Open Graph metadata, falling back to the page's title and description
+	Title       *string `gorm:"type:varchar(300)" json:"title,omitempty"`
+	Description *string `gorm:"type:text" json:"description,omitempty"`
+	ImageURL    *string `gorm:"type:text" json:"image_url,omitempty"`
+	SiteName    *string `gorm:"type:varchar(200)" json:"site_name,omitempty"`
+	FinalURL    *string `gorm:"type:text" json:"final_url,omitempty"` // After redirects
+
+	FetchedAt time.Time `gorm:"not null" json:"fetched_at"`
+
+	// Audit fields
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (lp *LinkPreview) BeforeCreate(tx *gorm.DB) error {
+	if lp.ID == uuid.Nil {
+		lp.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// NationalReportSubmission freezes the quarterly statistics submitted to the national housing
+// ministry. Once a quarter is locked its figures are served from Figures, never recomputed,
+// and any later change in the live data is surfaced as drift instead of altering the report.
+type NationalReportSubmission struct {
+	ID      uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	Year    int       `gorm:"not null;uniqueIndex:idx_national_report_period" json:"year"`
+	Quarter int       `gorm:"not null;uniqueIndex:idx_national_report_period" json:"quarter"`
+
+	// Snapshot of the report exactly as submitted
+	SchemaVersion   string         `gorm:"type:varchar(20);not null" json:"schema_version"`
+	Figures         datatypes.JSON `gorm:"type:jsonb;not null" json:"figures"`
+	FiguresChecksum string         `gorm:"type:varchar(64);not null" json:"figures_checksum"` // SHA-256 of Figures
+
+	SubmissionReference *string `gorm:"type:varchar(100)" json:"submission_reference"`
+	Notes               *string `gorm:"type:text" json:"notes"`
+
+	LockedByID uuid.UUID `gorm:"type:uuid;not null" json:"locked_by_id"`
+	LockedAt   time.Time `gorm:"not null" json:"locked_at"`
+
+	// Relationships
+	LockedBy *User `gorm:"foreignKey:LockedByID" json:"locked_by,omitempty"`
+
+	// Audit fields
+	CreatedBy string         `gorm:"not null" json:"created_by"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (nrs *NationalReportSubmission) BeforeCreate(tx *gorm.DB) error {
+	if nrs.ID == uuid.Nil {
+		nrs.ID = uuid.New()
+	}
+	return nil
+}
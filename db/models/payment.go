@@ -28,6 +28,8 @@ const (
 	PaymentForInspectionFee   PaymentFor = "INSPECTION_FEE"
 	PaymentForPermitFee       PaymentFor = "PERMIT_FEE"
 	PaymentForDevelopmentLevy PaymentFor = "DEVELOPMENT_LEVY"
+	PaymentForAmendmentFee    PaymentFor = "AMENDMENT_FEE"
+	PaymentForAppealFee       PaymentFor = "APPEAL_FEE"
 )
 
 type TransactionType string
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// DuplicatePaymentReason is why a capture was taken for a payment already on record
+type DuplicatePaymentReason string
+
+const (
+	// DuplicateReceiptNumber means the receipt number was already captured
+	DuplicateReceiptNumber DuplicatePaymentReason = "RECEIPT_NUMBER"
+	// DuplicateAmountForApplicant means the same amount was captured for the same applicant a few
+	// minutes earlier
+	DuplicateAmountForApplicant DuplicatePaymentReason = "AMOUNT_AND_APPLICANT"
+)
+
+// DuplicatePaymentAlertStatus tracks the finance officer's resolution of a blocked capture
+type DuplicatePaymentAlertStatus string
+
+const (
+	DuplicateAlertOpen      DuplicatePaymentAlertStatus = "OPEN"
+	DuplicateAlertConfirmed DuplicatePaymentAlertStatus = "CONFIRMED_DUPLICATE"
+	DuplicateAlertDismissed DuplicatePaymentAlertStatus = "NOT_DUPLICATE"
+)
+
+// DuplicatePaymentAlert records a payment capture that was blocked because it looked like a
+// payment already on record. The blocked attempt was never saved as a payment, so its details
+// are kept here next to the existing payment for the finance officer to resolve. Dismissing an
+// amount match lets the same amount be captured again for the application.
+type DuplicatePaymentAlert struct {
+	ID     uuid.UUID                   `gorm:"type:uuid;primary_key;" json:"id"`
+	Reason DuplicatePaymentReason      `gorm:"type:varchar(30);not null" json:"reason"`
+	Status DuplicatePaymentAlertStatus `gorm:"type:varchar(30);not null;default:'OPEN';index" json:"status"`
+
+	// The payment already on record
+	ExistingPaymentID uuid.UUID `gorm:"type:uuid;not null;index" json:"existing_payment_id"`
+
+	// The blocked attempt
+	ApplicationID              *uuid.UUID      `gorm:"type:uuid;index" json:"application_id"`
+	ApplicantID                *uuid.UUID      `gorm:"type:uuid;index" json:"applicant_id"`
+	AttemptedPaymentFor        PaymentFor      `gorm:"type:varchar(50)" json:"attempted_payment_for"`
+	AttemptedAmount            decimal.Decimal `gorm:"type:decimal(18,8)" json:"attempted_amount"`
+	AttemptedReceiptNumber     string          `gorm:"index" json:"attempted_receipt_number"`
+	AttemptedPaymentMethod     PaymentMethod   `gorm:"type:varchar(30)" json:"attempted_payment_method"`
+	AttemptedExternalReference *string         `json:"attempted_external_reference,omitempty"`
+	AttemptedPaymentDate       time.Time       `json:"attempted_payment_date"`
+	AttemptedBy                string          `gorm:"not null" json:"attempted_by"`
+	AttemptedAt                time.Time       `gorm:"not null" json:"attempted_at"`
+
+	// Notification and resolution
+	FinanceOfficersNotified int        `gorm:"default:0" json:"finance_officers_notified"`
+	ResolvedByID            *uuid.UUID `gorm:"type:uuid" json:"resolved_by_id"`
+	ResolvedAt              *time.Time `json:"resolved_at"`
+	ResolutionNotes         *string    `gorm:"type:text" json:"resolution_notes"`
+
+	// Relationships
+	ExistingPayment *Payment     `gorm:"foreignKey:ExistingPaymentID" json:"existing_payment,omitempty"`
+	Application     *Application `gorm:"foreignKey:ApplicationID" json:"application,omitempty"`
+	ResolvedBy      *User        `gorm:"foreignKey:ResolvedByID" json:"resolved_by,omitempty"`
+
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (a *DuplicatePaymentAlert) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PermitAction is a change made to a permit after it was issued
+type PermitAction string
+
+const (
+	PermitActionSuspend   PermitAction = "SUSPEND"
+	PermitActionReinstate PermitAction = "REINSTATE"
+	PermitActionRevoke    PermitAction = "REVOKE"
+)
+
+// EffectiveStatus is the permit's status on the given day. Active permits past their validity
+// are reported as expired even before a job updates them.
+func (p *Permit) EffectiveStatus(now time.Time) PermitStatus {
+	if p.Status == PermitActive && p.ValidUntil != nil && now.After(*p.ValidUntil) {
+		return PermitExpired
+	}
+	return p.Status
+}
+
+// PermitStatusChange records one suspension, reinstatement or revocation of an issued permit,
+// with the reason and the documents (court orders, investigation reports) that support it.
+// Rows are never deleted, so together they form the permit's history.
+type PermitStatusChange struct {
+	ID         uuid.UUID    `gorm:"type:uuid;primary_key;" json:"id"`
+	PermitID   uuid.UUID    `gorm:"type:uuid;not null;index" json:"permit_id"`
+	Action     PermitAction `gorm:"type:varchar(20);not null" json:"action"`
+	FromStatus PermitStatus `gorm:"type:varchar(20);not null" json:"from_status"`
+	ToStatus   PermitStatus `gorm:"type:varchar(20);not null" json:"to_status"`
+	Reason     string       `gorm:"type:text;not null" json:"reason"`
+
+	ChangedByID uuid.UUID `gorm:"type:uuid;not null;index" json:"changed_by_id"`
+	ChangedAt   time.Time `gorm:"not null" json:"changed_at"`
+
+	// Who was told about the change
+	ApplicantNotified  bool `gorm:"default:false" json:"applicant_notified"`
+	InspectorsNotified int  `gorm:"default:0" json:"inspectors_notified"`
+
+	// Relationships
+	Permit    *Permit    `gorm:"foreignKey:PermitID" json:"-"`
+	ChangedBy *User      `gorm:"foreignKey:ChangedByID" json:"changed_by,omitempty"`
+	Documents []Document `gorm:"many2many:permit_status_change_documents" json:"documents,omitempty"`
+
+	// Audit fields
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (c *PermitStatusChange) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
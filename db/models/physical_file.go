@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PhysicalFileStatus tracks where an application's paper file is
+type PhysicalFileStatus string
+
+const (
+	PhysicalFileWithHolder PhysicalFileStatus = "WITH_HOLDER"
+	PhysicalFileInTransit  PhysicalFileStatus = "IN_TRANSIT"
+	PhysicalFileMissing    PhysicalFileStatus = "MISSING" // In transit longer than the missing threshold
+)
+
+// ApplicationPhysicalFile is the hard-copy file kept for an application. The barcode printed on
+// the cover identifies it when it is scanned at each office.
+type ApplicationPhysicalFile struct {
+	ID            uuid.UUID          `gorm:"type:uuid;primary_key;" json:"id"`
+	ApplicationID uuid.UUID          `gorm:"type:uuid;not null;uniqueIndex" json:"application_id"`
+	Barcode       string             `gorm:"type:varchar(40);not null;uniqueIndex" json:"barcode"`
+	Status        PhysicalFileStatus `gorm:"type:varchar(20);not null;default:'WITH_HOLDER';index" json:"status"`
+
+	// Current custody. While in transit this is still the sender until the recipient confirms receipt.
+	CurrentHolderID     *uuid.UUID `gorm:"type:uuid;index" json:"current_holder_id"`
+	CurrentDepartmentID *uuid.UUID `gorm:"type:uuid;index" json:"current_department_id"`
+	CurrentLocation     *string    `gorm:"type:varchar(200)" json:"current_location"` // Office or cabinet
+	HeldSince           time.Time  `gorm:"not null" json:"held_since"`
+
+	MissingFlaggedAt *time.Time `json:"missing_flagged_at"`
+
+	// Relationships
+	Application       *Application           `gorm:"foreignKey:ApplicationID" json:"application,omitempty"`
+	CurrentHolder     *User                  `gorm:"foreignKey:CurrentHolderID" json:"current_holder,omitempty"`
+	CurrentDepartment *Department            `gorm:"foreignKey:CurrentDepartmentID" json:"current_department,omitempty"`
+	Movements         []PhysicalFileMovement `gorm:"foreignKey:FileID" json:"movements,omitempty"`
+
+	// Audit fields
+	CreatedBy string         `gorm:"not null" json:"created_by"`
+	UpdatedBy *string        `json:"updated_by"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// PhysicalFileMovement is one custody transfer of a paper file. A movement is open from dispatch
+// until the recipient confirms receipt; rows are never deleted so they form the file's trail.
+type PhysicalFileMovement struct {
+	ID     uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	FileID uuid.UUID `gorm:"type:uuid;not null;index" json:"file_id"`
+
+	FromHolderID     *uuid.UUID `gorm:"type:uuid;index" json:"from_holder_id"`
+	FromDepartmentID *uuid.UUID `gorm:"type:uuid" json:"from_department_id"`
+	ToHolderID       uuid.UUID  `gorm:"type:uuid;not null;index" json:"to_holder_id"`
+	ToDepartmentID   *uuid.UUID `gorm:"type:uuid" json:"to_department_id"`
+	ToLocation       *string    `gorm:"type:varchar(200)" json:"to_location"`
+	Notes            *string    `gorm:"type:text" json:"notes"`
+
+	DispatchedByID uuid.UUID  `gorm:"type:uuid;not null" json:"dispatched_by_id"`
+	DispatchedAt   time.Time  `gorm:"not null;index" json:"dispatched_at"`
+	ReceivedAt     *time.Time `json:"received_at"`
+
+	// Relationships
+	FromHolder     *User       `gorm:"foreignKey:FromHolderID" json:"from_holder,omitempty"`
+	FromDepartment *Department `gorm:"foreignKey:FromDepartmentID" json:"from_department,omitempty"`
+	ToHolder       *User       `gorm:"foreignKey:ToHolderID" json:"to_holder,omitempty"`
+	ToDepartment   *Department `gorm:"foreignKey:ToDepartmentID" json:"to_department,omitempty"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (pf *ApplicationPhysicalFile) BeforeCreate(tx *gorm.DB) error {
+	if pf.ID == uuid.Nil {
+		pf.ID = uuid.New()
+	}
+	return nil
+}
+
+func (pm *PhysicalFileMovement) BeforeCreate(tx *gorm.DB) error {
+	if pm.ID == uuid.Nil {
+		pm.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PlanningSchemeType is the kind of planning instrument a scheme is
+type PlanningSchemeType string
+
+const (
+	ZoningMapScheme   PlanningSchemeType = "ZONING_MAP"
+	LocalPlanScheme   PlanningSchemeType = "LOCAL_PLAN"
+	MasterPlanScheme  PlanningSchemeType = "MASTER_PLAN"
+	RegulationsScheme PlanningSchemeType = "REGULATIONS"
+)
+
+// PlanningSchemeDocumentCategoryCode is the document category scheme versions are filed under
+const PlanningSchemeDocumentCategoryCode = "PLANNING_SCHEME"
+
+// PlanningScheme is one of the council's planning instruments, such as a zoning map or a local
+// plan. Its content lives in versions, each in force for a period.
+type PlanningScheme struct {
+	ID          uuid.UUID          `gorm:"type:uuid;primary_key;" json:"id"`
+	Name        string             `gorm:"not null" json:"name"`
+	Code        string             `gorm:"type:varchar(50);uniqueIndex;not null" json:"code"`
+	SchemeType  PlanningSchemeType `gorm:"type:varchar(20);not null;index" json:"scheme_type"`
+	Description *string            `gorm:"type:text" json:"description"`
+	IsActive    bool               `gorm:"default:true;index" json:"is_active"`
+
+	// Relationships
+	Versions []PlanningSchemeVersion `gorm:"foreignKey:SchemeID" json:"versions,omitempty"`
+
+	// Audit fields
+	CreatedBy string         `gorm:"not null" json:"created_by"`
+	UpdatedBy *string        `json:"updated_by"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (s *PlanningScheme) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// PlanningSchemeVersion is the text of a scheme in force from EffectiveFrom until the next
+// version takes over. EffectiveTo is nil for the version currently in force. Versions are never
+// edited once published; a correction is a new version.
+type PlanningSchemeVersion struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key;" json:"id"`
+	SchemeID      uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_scheme_version" json:"scheme_id"`
+	VersionNumber int        `gorm:"not null;uniqueIndex:idx_scheme_version" json:"version_number"`
+	Title         string     `gorm:"not null" json:"title"`
+	Summary       *string    `gorm:"type:text" json:"summary"` // What changed from the previous version
+	GazetteNotice *string    `json:"gazette_notice"`           // Statutory instrument or notice that brought it into force
+	EffectiveFrom time.Time  `gorm:"not null;index" json:"effective_from"`
+	EffectiveTo   *time.Time `gorm:"index" json:"effective_to"`
+	DocumentID    *uuid.UUID `gorm:"type:uuid;index" json:"document_id"`
+
+	// Relationships
+	Scheme   *PlanningScheme `gorm:"foreignKey:SchemeID" json:"scheme,omitempty"`
+	Document *Document       `gorm:"foreignKey:DocumentID" json:"document,omitempty"`
+
+	// Audit fields
+	CreatedBy string    `gorm:"not null" json:"created_by"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (v *PlanningSchemeVersion) BeforeCreate(tx *gorm.DB) error {
+	if v.ID == uuid.Nil {
+		v.ID = uuid.New()
+	}
+	return nil
+}
+
+// InEffectAt reports whether the version was in force at the given time
+func (v *PlanningSchemeVersion) InEffectAt(at time.Time) bool {
+	return !at.Before(v.EffectiveFrom) && (v.EffectiveTo == nil || at.Before(*v.EffectiveTo))
+}
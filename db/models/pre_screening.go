@@ -0,0 +1,87 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// PreScreeningOutcome is where a pre-screening triage stands
+type PreScreeningOutcome string
+
+const (
+	PreScreeningPending PreScreeningOutcome = "PENDING" // Waiting in the triage queue
+	PreScreeningPassed  PreScreeningOutcome = "PASSED"  // Sent on to the approval group
+	PreScreeningBounced PreScreeningOutcome = "BOUNCED" // Returned to the applicant
+)
+
+// PreScreeningCheck is one item of the technicians' quick triage checklist
+type PreScreeningCheck string
+
+const (
+	PreScreeningLegiblePlans PreScreeningCheck = "LEGIBLE_PLANS"
+	PreScreeningCorrectStand PreScreeningCheck = "CORRECT_STAND"
+	PreScreeningFeesPaid     PreScreeningCheck = "FEES_PAID"
+)
+
+// PreScreeningChecklist is every check an application must pass before formal review
+var PreScreeningChecklist = []PreScreeningCheck{
+	PreScreeningLegiblePlans,
+	PreScreeningCorrectStand,
+	PreScreeningFeesPaid,
+}
+
+// PreScreeningBounceReason is a standard reason for returning an application to the applicant
+type PreScreeningBounceReason string
+
+const (
+	BounceIllegiblePlans    PreScreeningBounceReason = "ILLEGIBLE_PLANS"
+	BounceWrongStand        PreScreeningBounceReason = "WRONG_STAND"
+	BounceFeesUnpaid        PreScreeningBounceReason = "FEES_UNPAID"
+	BounceMissingDocuments  PreScreeningBounceReason = "MISSING_DOCUMENTS"
+	BounceIncompleteDetails PreScreeningBounceReason = "INCOMPLETE_DETAILS"
+	BounceOther             PreScreeningBounceReason = "OTHER" // Explained in the note
+)
+
+// PreScreeningBounceReasons describes each standard reason as the applicant is told it
+var PreScreeningBounceReasons = map[PreScreeningBounceReason]string{
+	BounceIllegiblePlans:    "The plans submitted cannot be read clearly",
+	BounceWrongStand:        "The plans do not match the stand the application was made for",
+	BounceFeesUnpaid:        "The application fees have not been paid",
+	BounceMissingDocuments:  "Required documents are missing",
+	BounceIncompleteDetails: "The application form is incomplete",
+	BounceOther:             "See the note from the planning office",
+}
+
+// ApplicationPreScreening is one pass of an application through the pre-screening triage queue.
+// ApprovalGroupID is the group the application goes to once it passes; a bounced application
+// gets a new pending screening when it is resubmitted.
+type ApplicationPreScreening struct {
+	ID              uuid.UUID           `gorm:"type:uuid;primary_key;" json:"id"`
+	ApplicationID   uuid.UUID           `gorm:"type:uuid;not null;index" json:"application_id"`
+	ApprovalGroupID uuid.UUID           `gorm:"type:uuid;not null" json:"approval_group_id"`
+	Outcome         PreScreeningOutcome `gorm:"type:varchar(20);not null;default:'PENDING';index" json:"outcome"`
+	Checks          datatypes.JSON      `gorm:"type:jsonb" json:"checks"`         // Check code to whether it passed
+	BounceReasons   datatypes.JSON      `gorm:"type:jsonb" json:"bounce_reasons"` // Standard reason codes
+	Note            *string             `gorm:"type:text" json:"note"`
+	QueuedAt        time.Time           `gorm:"not null;index" json:"queued_at"`
+	ScreenedByID    *uuid.UUID          `gorm:"type:uuid" json:"screened_by_id"`
+	ScreenedAt      *time.Time          `json:"screened_at"`
+
+	// Relationships
+	Application   *Application   `gorm:"foreignKey:ApplicationID" json:"application,omitempty"`
+	ApprovalGroup *ApprovalGroup `gorm:"foreignKey:ApprovalGroupID" json:"approval_group,omitempty"`
+	ScreenedBy    *User          `gorm:"foreignKey:ScreenedByID" json:"screened_by,omitempty"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (ps *ApplicationPreScreening) BeforeCreate(tx *gorm.DB) error {
+	if ps.ID == uuid.Nil {
+		ps.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ApproverQueue names one of the lists on an approver's "my queue" page
+type ApproverQueue string
+
+const (
+	QueuePendingDecisions ApproverQueue = "DECISIONS" // Applications awaiting the user's decision
+	QueueAssignedIssues   ApproverQueue = "ISSUES"    // Unresolved issues assigned to the user
+	QueueUnreadMentions   ApproverQueue = "MENTIONS"  // Threads where the user is mentioned in unread messages
+)
+
+// SavedQueueFilter is a named set of filters and sort order a user saved for one of their queues.
+// It is stored server-side so the same views are available on every device.
+type SavedQueueFilter struct {
+	ID     uuid.UUID     `gorm:"type:uuid;primary_key;" json:"id"`
+	UserID uuid.UUID     `gorm:"type:uuid;not null;uniqueIndex:idx_saved_queue_filter_name" json:"user_id"`
+	Queue  ApproverQueue `gorm:"type:varchar(20);not null;uniqueIndex:idx_saved_queue_filter_name" json:"queue"`
+	Name   string        `gorm:"type:varchar(100);not null;uniqueIndex:idx_saved_queue_filter_name" json:"name"`
+
+	// Query parameters as sent to the queue endpoint, e.g. {"risk_level": "HIGH", "sort": "risk"}
+	Filters datatypes.JSON `gorm:"type:jsonb;not null" json:"filters"`
+
+	// The filter applied when the user opens the queue without choosing one
+	IsDefault bool `gorm:"default:false" json:"is_default"`
+
+	// Relationships
+	User User `gorm:"foreignKey:UserID" json:"-"`
+
+	// Audit fields
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (f *SavedQueueFilter) BeforeCreate(tx *gorm.DB) error {
+	if f.ID == uuid.Nil {
+		f.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// RatesClearanceStatus is the outcome of checking a stand's rates account with council billing
+type RatesClearanceStatus string
+
+const (
+	RatesClearanceCleared     RatesClearanceStatus = "CLEARED"
+	RatesClearanceArrears     RatesClearanceStatus = "ARREARS"
+	RatesClearanceUnavailable RatesClearanceStatus = "UNAVAILABLE" // Billing could not confirm the account either way
+	RatesClearanceOverridden  RatesClearanceStatus = "OVERRIDDEN"  // An authorised officer accepted the application regardless
+)
+
+// RatesClearance records one rates clearance check for an application's stand. Checks are never
+// updated; a re-check or an override adds a new record, and the latest one is the application's
+// current clearance.
+type RatesClearance struct {
+	ID            uuid.UUID            `gorm:"type:uuid;primary_key;" json:"id"`
+	ApplicationID uuid.UUID            `gorm:"type:uuid;not null;index" json:"application_id"`
+	StandID       uuid.UUID            `gorm:"type:uuid;not null;index" json:"stand_id"`
+	AccountNumber *string              `gorm:"type:varchar(50)" json:"account_number"`
+	Status        RatesClearanceStatus `gorm:"type:varchar(20);not null;index" json:"status"`
+
+	// Balance as reported by the billing system; positive means money is owed
+	OutstandingBalance *decimal.Decimal `gorm:"type:decimal(15,2)" json:"outstanding_balance"`
+	Currency           *string          `gorm:"type:varchar(10)" json:"currency"`
+	BillingReference   *string          `gorm:"type:varchar(100)" json:"billing_reference"`
+	Message            *string          `gorm:"type:text" json:"message"`
+	CheckedAt          time.Time        `gorm:"not null;index" json:"checked_at"`
+
+	// Override details, set only when Status is OVERRIDDEN
+	OverrideReason *string    `gorm:"type:text" json:"override_reason"`
+	OverriddenByID *uuid.UUID `gorm:"type:uuid;index" json:"overridden_by_id"`
+
+	// Relationships
+	Application  *Application `gorm:"foreignKey:ApplicationID" json:"-"`
+	Stand        *Stand       `gorm:"foreignKey:StandID" json:"-"`
+	OverriddenBy *User        `gorm:"foreignKey:OverriddenByID" json:"overridden_by,omitempty"`
+
+	// Audit fields
+	CreatedBy string         `gorm:"not null" json:"created_by"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (rc *RatesClearance) BeforeCreate(tx *gorm.DB) error {
+	if rc.ID == uuid.Nil {
+		rc.ID = uuid.New()
+	}
+	return nil
+}
+
+// AllowsAcceptance reports whether an application may proceed on this clearance
+func (rc *RatesClearance) AllowsAcceptance() bool {
+	return rc.Status == RatesClearanceCleared || rc.Status == RatesClearanceOverridden
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ReportJobType is a report that can be generated in the background
+type ReportJobType string
+
+const (
+	ReportJobFunnel            ReportJobType = "FUNNEL"
+	ReportJobNationalQuarterly ReportJobType = "NATIONAL_QUARTERLY"
+)
+
+// ReportJobStatus tracks a report job from request to download
+type ReportJobStatus string
+
+const (
+	ReportJobPending   ReportJobStatus = "PENDING"
+	ReportJobRunning   ReportJobStatus = "RUNNING"
+	ReportJobCompleted ReportJobStatus = "COMPLETED"
+	ReportJobFailed    ReportJobStatus = "FAILED"
+	ReportJobExpired   ReportJobStatus = "EXPIRED" // Retention period over, file deleted
+)
+
+// ReportJob is a report generated outside the request that asked for it, for periods too large
+// to compute within an HTTP timeout. The finished file is kept in storage until ExpiresAt.
+type ReportJob struct {
+	ID         uuid.UUID       `gorm:"type:uuid;primary_key;" json:"id"`
+	ReportType ReportJobType   `gorm:"type:varchar(30);not null" json:"report_type"`
+	Parameters datatypes.JSON  `gorm:"type:jsonb;not null" json:"parameters"`
+	Status     ReportJobStatus `gorm:"type:varchar(20);not null;default:'PENDING';index" json:"status"`
+
+	// Generated file, set once the job completes
+	FileName    *string `gorm:"type:varchar(255)" json:"file_name"`
+	FilePath    *string `gorm:"type:varchar(500)" json:"-"` // Relative to the report storage
+	FileSize    int64   `gorm:"default:0" json:"file_size"`
+	ContentType *string `gorm:"type:varchar(100)" json:"content_type"`
+	Error       *string `gorm:"type:text" json:"error"`
+
+	RequestedByID uuid.UUID  `gorm:"type:uuid;not null;index" json:"requested_by_id"`
+	StartedAt     *time.Time `json:"started_at"`
+	CompletedAt   *time.Time `json:"completed_at"`
+	ExpiresAt     *time.Time `gorm:"index" json:"expires_at"`
+
+	// Relationships
+	RequestedBy *User `gorm:"foreignKey:RequestedByID" json:"requested_by,omitempty"`
+
+	// Audit fields
+	CreatedBy string         `gorm:"not null" json:"created_by"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (rj *ReportJob) BeforeCreate(tx *gorm.DB) error {
+	if rj.ID == uuid.Nil {
+		rj.ID = uuid.New()
+	}
+	return nil
+}
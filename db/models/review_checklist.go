@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReviewChecklistItem is a check an approval group's members sign off before approving, such as
+// "Setbacks verified". Mandatory items must be ticked for an approval to be accepted. Items are
+// deactivated rather than deleted so past decisions keep what was ticked.
+type ReviewChecklistItem struct {
+	ID              uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	ApprovalGroupID uuid.UUID `gorm:"type:uuid;not null;index" json:"approval_group_id"`
+	Label           string    `gorm:"type:varchar(200);not null" json:"label"`
+	Description     *string   `gorm:"type:text" json:"description"`
+	IsMandatory     bool      `gorm:"default:true" json:"is_mandatory"`
+	SortOrder       int       `gorm:"default:0" json:"sort_order"`
+	IsActive        bool      `gorm:"default:true;index" json:"is_active"`
+
+	// Relationships
+	ApprovalGroup *ApprovalGroup `gorm:"foreignKey:ApprovalGroupID" json:"approval_group,omitempty"`
+
+	// Audit fields
+	CreatedBy string         `gorm:"not null" json:"created_by"`
+	UpdatedBy *string        `json:"updated_by"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (i *ReviewChecklistItem) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+// DecisionChecklistItem is a checklist item a member ticked when approving. The label is kept as
+// it read at the time, since the group's checklist may be reworded later.
+type DecisionChecklistItem struct {
+	ID              uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	DecisionID      uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_decision_checklist_item" json:"decision_id"`
+	ChecklistItemID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_decision_checklist_item" json:"checklist_item_id"`
+	Label           string    `gorm:"type:varchar(200);not null" json:"label"`
+	IsMandatory     bool      `json:"is_mandatory"`
+	CheckedAt       time.Time `gorm:"not null" json:"checked_at"`
+
+	// Relationships
+	ChecklistItem *ReviewChecklistItem `gorm:"foreignKey:ChecklistItemID" json:"checklist_item,omitempty"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (i *DecisionChecklistItem) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
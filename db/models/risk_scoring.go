@@ -0,0 +1,117 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// RiskLevel buckets an application's risk score
+type RiskLevel string
+
+const (
+	RiskLevelLow    RiskLevel = "LOW"
+	RiskLevelMedium RiskLevel = "MEDIUM"
+	RiskLevelHigh   RiskLevel = "HIGH"
+)
+
+// RiskFactor names one input to the risk score
+type RiskFactor string
+
+const (
+	RiskFactorPlanArea            RiskFactor = "PLAN_AREA"
+	RiskFactorDevelopmentCategory RiskFactor = "DEVELOPMENT_CATEGORY"
+	RiskFactorApplicantDebtor     RiskFactor = "APPLICANT_DEBTOR"
+	RiskFactorPriorRejections     RiskFactor = "PRIOR_REJECTIONS"
+	RiskFactorUnservicedStand     RiskFactor = "UNSERVICED_STAND"
+)
+
+// RiskScoringProfile holds the points each risk factor adds and the score thresholds. Only one
+// profile is active; saving a new profile replaces it and keeps the old one for the history.
+// Development categories carry their own points in DevelopmentCategory.RiskPoints. Points have
+// no column defaults so that 0 can switch a factor off.
+type RiskScoringProfile struct {
+	ID       uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	IsActive bool      `gorm:"default:true;index" json:"is_active"`
+
+	// Plans larger than PlanAreaThreshold (square metres) add PlanAreaPoints
+	PlanAreaThreshold decimal.Decimal `gorm:"type:decimal(15,2);not null" json:"plan_area_threshold"`
+	PlanAreaPoints    int             `gorm:"not null" json:"plan_area_points"`
+
+	ApplicantDebtorPoints int `gorm:"not null" json:"applicant_debtor_points"`
+
+	// Each earlier rejection of the applicant adds PriorRejectionPoints, up to PriorRejectionCap
+	PriorRejectionPoints int `gorm:"not null" json:"prior_rejection_points"`
+	PriorRejectionCap    int `gorm:"not null" json:"prior_rejection_cap"`
+
+	UnservicedStandPoints int `gorm:"not null" json:"unserviced_stand_points"`
+
+	// Scores at or above a threshold take that level
+	MediumRiskThreshold int `gorm:"not null" json:"medium_risk_threshold"`
+	HighRiskThreshold   int `gorm:"not null" json:"high_risk_threshold"`
+
+	// High-risk applications are assigned to this group at submission instead of the group chosen
+	// by the clerk. Routing is off when it is not set.
+	SeniorApprovalGroupID *uuid.UUID     `gorm:"type:uuid;index" json:"senior_approval_group_id"`
+	SeniorApprovalGroup   *ApprovalGroup `gorm:"foreignKey:SeniorApprovalGroupID" json:"senior_approval_group,omitempty"`
+
+	// Audit fields
+	CreatedBy string         `gorm:"not null" json:"created_by"`
+	UpdatedBy *string        `json:"updated_by"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// LevelFor buckets a score using the profile thresholds
+func (p *RiskScoringProfile) LevelFor(score int) RiskLevel {
+	switch {
+	case score >= p.HighRiskThreshold:
+		return RiskLevelHigh
+	case score >= p.MediumRiskThreshold:
+		return RiskLevelMedium
+	default:
+		return RiskLevelLow
+	}
+}
+
+// ApplicationRiskAssessment is one scoring of an application. Factors holds the points each
+// factor contributed, so reviewers can see why an application scored as it did.
+type ApplicationRiskAssessment struct {
+	ID            uuid.UUID      `gorm:"type:uuid;primary_key;" json:"id"`
+	ApplicationID uuid.UUID      `gorm:"type:uuid;not null;index" json:"application_id"`
+	ProfileID     uuid.UUID      `gorm:"type:uuid;not null;index" json:"profile_id"`
+	Score         int            `gorm:"not null" json:"score"`
+	Level         RiskLevel      `gorm:"type:varchar(10);not null" json:"level"`
+	Factors       datatypes.JSON `gorm:"type:jsonb" json:"factors"`
+	AssessedAt    time.Time      `gorm:"not null;index" json:"assessed_at"`
+
+	// Set when the assessment sent the application to the senior approval group
+	RoutedToGroupID *uuid.UUID `gorm:"type:uuid" json:"routed_to_group_id"`
+
+	// Relationships
+	Application *Application        `gorm:"foreignKey:ApplicationID" json:"-"`
+	Profile     *RiskScoringProfile `gorm:"foreignKey:ProfileID" json:"-"`
+
+	// Audit fields
+	CreatedBy string         `gorm:"not null" json:"created_by"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (p *RiskScoringProfile) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+func (a *ApplicationRiskAssessment) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
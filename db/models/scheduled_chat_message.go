@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ScheduledMessageStatus tracks a scheduled chat message until it is sent
+type ScheduledMessageStatus string
+
+const (
+	ScheduledMessagePending   ScheduledMessageStatus = "SCHEDULED"
+	ScheduledMessageSent      ScheduledMessageStatus = "SENT"
+	ScheduledMessageCancelled ScheduledMessageStatus = "CANCELLED"
+	ScheduledMessageFailed    ScheduledMessageStatus = "FAILED" // e.g. the author left the thread before it was due
+)
+
+// ScheduledChatMessage is a text message composed now and posted to the thread at ScheduledFor,
+// as its author. Once sent, SentMessageID points at the chat message it became.
+type ScheduledChatMessage struct {
+	ID           uuid.UUID              `gorm:"type:uuid;primary_key;" json:"id"`
+	ThreadID     uuid.UUID              `gorm:"type:uuid;not null;index" json:"thread_id"`
+	SenderID     uuid.UUID              `gorm:"type:uuid;not null;index" json:"sender_id"`
+	Content      string                 `gorm:"type:text;not null" json:"content"`
+	ScheduledFor time.Time              `gorm:"not null;index" json:"scheduled_for"`
+	Status       ScheduledMessageStatus `gorm:"type:varchar(20);not null;default:'SCHEDULED';index" json:"status"`
+
+	SentMessageID *uuid.UUID `gorm:"type:uuid" json:"sent_message_id"`
+	SentAt        *time.Time `json:"sent_at"`
+	CancelledAt   *time.Time `json:"cancelled_at"`
+	Error         *string    `gorm:"type:text" json:"error"`
+
+	// Relationships
+	Thread *ChatThread `gorm:"foreignKey:ThreadID" json:"thread,omitempty"`
+	Sender *User       `gorm:"foreignKey:SenderID" json:"-"`
+
+	// Audit fields
+	CreatedBy string         `gorm:"not null" json:"created_by"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (sm *ScheduledChatMessage) BeforeCreate(tx *gorm.DB) error {
+	if sm.ID == uuid.Nil {
+		sm.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SecondOpinionStatus tracks a second opinion asked of someone outside the approval group
+type SecondOpinionStatus string
+
+const (
+	SecondOpinionPending   SecondOpinionStatus = "PENDING"
+	SecondOpinionSubmitted SecondOpinionStatus = "SUBMITTED"
+	SecondOpinionWithdrawn SecondOpinionStatus = "WITHDRAWN"
+)
+
+// SecondOpinionRequest asks a council colleague outside the application's approval group for
+// advice, e.g. a heritage officer on a building in a conservation area. The opinion is advisory:
+// it is shown to the group as a comment and never counts towards the group's decision.
+type SecondOpinionRequest struct {
+	ID              uuid.UUID           `gorm:"type:uuid;primary_key;" json:"id"`
+	ApplicationID   uuid.UUID           `gorm:"type:uuid;not null;index" json:"application_id"`
+	ApprovalGroupID uuid.UUID           `gorm:"type:uuid;not null;index" json:"approval_group_id"` // The group asking
+	ReviewerID      uuid.UUID           `gorm:"type:uuid;not null;index" json:"reviewer_id"`
+	Status          SecondOpinionStatus `gorm:"type:varchar(20);not null;default:'PENDING';index" json:"status"`
+
+	// Request
+	RequestedByID uuid.UUID `gorm:"type:uuid;not null" json:"requested_by_id"`
+	RequestedAt   time.Time `gorm:"not null" json:"requested_at"`
+	Question      string    `gorm:"type:text;not null" json:"question"`
+	DueDate       time.Time `gorm:"not null;index" json:"due_date"`
+
+	// Reviewer's advice, also posted to the application's comments
+	Opinion     *string    `gorm:"type:text" json:"opinion"`
+	CommentID   *uuid.UUID `gorm:"type:uuid" json:"comment_id"`
+	SubmittedAt *time.Time `json:"submitted_at"`
+
+	// Set when the requester no longer needs the opinion
+	WithdrawnAt *time.Time `json:"withdrawn_at"`
+
+	// Relationships
+	Application   *Application   `gorm:"foreignKey:ApplicationID" json:"application,omitempty"`
+	ApprovalGroup *ApprovalGroup `gorm:"foreignKey:ApprovalGroupID" json:"approval_group,omitempty"`
+	Reviewer      *User          `gorm:"foreignKey:ReviewerID" json:"reviewer,omitempty"`
+	RequestedBy   *User          `gorm:"foreignKey:RequestedByID" json:"requested_by,omitempty"`
+
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (so *SecondOpinionRequest) BeforeCreate(tx *gorm.DB) error {
+	if so.ID == uuid.Nil {
+		so.ID = uuid.New()
+	}
+	if so.RequestedAt.IsZero() {
+		so.RequestedAt = time.Now()
+	}
+	return nil
+}
+
+// IsOverdue reports whether the opinion is still outstanding after its due date
+func (so *SecondOpinionRequest) IsOverdue(now time.Time) bool {
+	return so.Status == SecondOpinionPending && now.After(so.DueDate)
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Setting is a council-wide setting changed by an administrator. Settings that were never
+// changed have no row and use their default.
+type Setting struct {
+	Key       string    `gorm:"type:varchar(100);primary_key" json:"key"`
+	Value     string    `gorm:"type:text;not null" json:"value"`
+	UpdatedBy string    `gorm:"not null" json:"updated_by"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// SettingChange records who changed a setting, from what and to what
+type SettingChange struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	Key         string    `gorm:"type:varchar(100);not null;index" json:"key"`
+	OldValue    *string   `gorm:"type:text" json:"old_value"` // Nil when the setting was at its default
+	NewValue    *string   `gorm:"type:text" json:"new_value"` // Nil when the setting was reset to its default
+	Reason      *string   `gorm:"type:text" json:"reason"`
+	ChangedByID uuid.UUID `gorm:"type:uuid;not null;index" json:"changed_by_id"`
+	ChangedBy   *User     `gorm:"foreignKey:ChangedByID" json:"changed_by,omitempty"`
+	ChangedAt   time.Time `gorm:"not null;index" json:"changed_at"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SLAResumeReason says why a paused SLA clock started running again
+type SLAResumeReason string
+
+const (
+	SLAResumeApplicantResponded SLAResumeReason = "APPLICANT_RESPONDED" // Applicant replied on the portal
+	SLAResumeDocumentsReceived  SLAResumeReason = "DOCUMENTS_RECEIVED"  // Applicant uploaded documents
+	SLAResumeIssueResolved      SLAResumeReason = "ISSUE_RESOLVED"      // Last issue waiting on the applicant was resolved
+	SLAResumeFlagCleared        SLAResumeReason = "FLAG_CLEARED"        // Staff stopped waiting on the applicant
+)
+
+// ByApplicant reports whether the applicant's own action resumed the clock
+func (r SLAResumeReason) ByApplicant() bool {
+	return r == SLAResumeApplicantResponded || r == SLAResumeDocumentsReceived
+}
+
+// ApplicationSLAPause is a period in which an application's SLA clocks stood still because staff
+// were waiting on the applicant. An application has at most one open pause: it opens when the
+// first issue is flagged awaiting_applicant and closes when the applicant responds or no issue
+// is waiting on them any more. Pending decisions and turnaround reports leave these periods out.
+type ApplicationSLAPause struct {
+	ID            uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	ApplicationID uuid.UUID `gorm:"type:uuid;not null;index" json:"application_id"`
+	IssueID       uuid.UUID `gorm:"type:uuid;not null;index" json:"issue_id"` // Issue whose flag opened the pause
+
+	PausedByID uuid.UUID `gorm:"type:uuid;not null" json:"paused_by_id"`
+	PausedAt   time.Time `gorm:"not null;index" json:"paused_at"`
+
+	ResumedAt    *time.Time       `gorm:"index" json:"resumed_at"`
+	ResumeReason *SLAResumeReason `gorm:"type:varchar(30)" json:"resume_reason"`
+	ResumedByID  *uuid.UUID       `gorm:"type:uuid" json:"resumed_by_id"` // Nil when the applicant resumed it
+
+	// Relationships
+	Issue *ApplicationIssue `gorm:"foreignKey:IssueID" json:"issue,omitempty"`
+
+	// Audit fields
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// IsOpen reports whether the clock is still paused
+func (p *ApplicationSLAPause) IsOpen() bool {
+	return p.ResumedAt == nil
+}
+
+func (p *ApplicationSLAPause) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SMS statuses. QUEUED messages are waiting for the send worker; DELIVERED and UNDELIVERED come
+// from the provider's status callback.
+const (
+	SMSStatusQueued      = "QUEUED"
+	SMSStatusSent        = "SENT"
+	SMSStatusFailed      = "FAILED"
+	SMSStatusDelivered   = "DELIVERED"
+	SMSStatusUndelivered = "UNDELIVERED"
+)
+
+// SMSLog is one text message in the communication log, kept next to the email log
+type SMSLog struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	Recipient string    `gorm:"type:varchar(20);not null" json:"recipient"` // E.164, e.g. +263771234567
+	Message   string    `gorm:"type:text;not null" json:"message"`
+	Language  string    `gorm:"type:varchar(10)" json:"language"`
+
+	ApplicationID *uuid.UUID `gorm:"type:uuid;index" json:"application_id,omitempty"`
+	ApplicantID   *uuid.UUID `gorm:"type:uuid;index" json:"applicant_id,omitempty"`
+
+	MessageType  string  `gorm:"type:varchar(50)" json:"message_type"` // e.g. "PERMIT_STATUS_CHANGE"
+	TemplateName *string `gorm:"type:varchar(100)" json:"template_name,omitempty"`
+	Status       string  `gorm:"type:varchar(20);default:'QUEUED'" json:"status"` // QUEUED, SENT, FAILED, DELIVERED, UNDELIVERED
+	Error        *string `gorm:"type:text" json:"error,omitempty"`
+
+	// Delivery through the SMS providers, tried in the configured order. Provider is the one
+	// that accepted the message.
+	Provider          *string    `gorm:"type:varchar(20)" json:"provider,omitempty"` // twilio, aggregator
+	ProviderMessageID *string    `gorm:"type:varchar(255);index" json:"provider_message_id,omitempty"`
+	ProvidersTried    string     `gorm:"type:varchar(100)" json:"providers_tried"` // Comma-separated, in order
+	Attempts          int        `gorm:"default:0" json:"attempts"`
+	SentAt            *time.Time `json:"sent_at,omitempty"`
+
+	// Status callbacks
+	DeliveredAt       *time.Time `json:"delivered_at,omitempty"`
+	FailedAt          *time.Time `json:"failed_at,omitempty"`
+	ProviderStatus    *string    `gorm:"type:varchar(30)" json:"provider_status,omitempty"` // Last status reported, as the provider words it
+	ProviderErrorCode *string    `gorm:"type:varchar(30)" json:"provider_error_code,omitempty"`
+
+	// Relationships
+	Application *Application `gorm:"foreignKey:ApplicationID" json:"application,omitempty"`
+	Applicant   *Applicant   `gorm:"foreignKey:ApplicantID" json:"applicant,omitempty"`
+
+	CreatedBy string    `gorm:"not null" json:"created_by"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (s *SMSLog) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
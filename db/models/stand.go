@@ -92,6 +92,7 @@ type Stand struct {
 	Longitude       *decimal.Decimal `gorm:"type:decimal(11,8)" json:"longitude"`
 	AreaSquareMeter *decimal.Decimal `gorm:"type:decimal(15,2)" json:"area_square_meter"`
 	AreaHectare     *decimal.Decimal `gorm:"type:decimal(15,4)" json:"area_hectare"`
+	Ward            *string          `gorm:"type:varchar(50);index" json:"ward"`
 
 	// Stand Classification
 	StandTypeID    *uuid.UUID `gorm:"type:uuid;index" json:"stand_type_id"`
@@ -137,6 +138,7 @@ type Stand struct {
 	AllStandOwners *[]AllStandOwners `gorm:"foreignKey:StandID;references:ID" json:"all_stand_owners"`
 	Applications   []Application     `gorm:"foreignKey:StandID" json:"applications,omitempty"`
 	StandDocuments []StandDocument   `gorm:"foreignKey:StandID" json:"stand_documents,omitempty"`
+	Photos         []StandPhoto      `gorm:"foreignKey:StandID" json:"photos,omitempty"`
 
 	// Audit fields
 	CreatedBy  string         `gorm:"not null" json:"created_by"`
@@ -149,6 +151,45 @@ type Stand struct {
 	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
+type StandPhotoSource string
+
+const (
+	StandPhotoSourceUpload     StandPhotoSource = "UPLOAD"
+	StandPhotoSourceInspection StandPhotoSource = "INSPECTION"
+)
+
+// StandPhoto is a geo-tagged site photo in a stand's gallery. Unlike stand documents these
+// are context pictures of the site; photos taken during an inspection can be linked here too.
+type StandPhoto struct {
+	ID        uuid.UUID        `gorm:"type:uuid;primary_key;" json:"id"`
+	StandID   uuid.UUID        `gorm:"type:uuid;not null;index" json:"stand_id"`
+	FilePath  string           `gorm:"not null" json:"file_path"`
+	MimeType  string           `gorm:"type:varchar(100)" json:"mime_type"`
+	FileSize  int64            `json:"file_size"`
+	Caption   *string          `gorm:"type:varchar(500)" json:"caption"`
+	Latitude  *decimal.Decimal `gorm:"type:decimal(10,8)" json:"latitude"`
+	Longitude *decimal.Decimal `gorm:"type:decimal(11,8)" json:"longitude"`
+	TakenAt   *time.Time       `json:"taken_at"`
+	IsPrimary bool             `gorm:"default:false" json:"is_primary"`
+
+	// Set when the file was re-encoded without its EXIF metadata
+	MetadataScrubbedAt *time.Time `json:"metadata_scrubbed_at"`
+
+	Source            StandPhotoSource `gorm:"type:varchar(20);default:'UPLOAD'" json:"source"`
+	InspectionPhotoID *uuid.UUID       `gorm:"type:uuid;uniqueIndex" json:"inspection_photo_id"`
+
+	// Relationships
+	Stand           *Stand           `gorm:"foreignKey:StandID;constraint:OnDelete:CASCADE" json:"-"`
+	InspectionPhoto *InspectionPhoto `gorm:"foreignKey:InspectionPhotoID" json:"-"`
+
+	// Audit fields
+	CreatedBy string         `gorm:"not null" json:"created_by"`
+	UpdatedBy *string        `json:"updated_by"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
 type AllStandOwners struct {
 	ID            uuid.UUID  `gorm:"type:uuid;primary_key;" json:"id"`
 	StandID       uuid.UUID  `json:"stand_id"`
@@ -185,3 +226,10 @@ func (s *Stand) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+func (sp *StandPhoto) BeforeCreate(tx *gorm.DB) error {
+	if sp.ID == uuid.Nil {
+		sp.ID = uuid.New()
+	}
+	return nil
+}
@@ -100,6 +100,10 @@ type Department struct {
 	PhoneNumber    *string `gorm:"type:varchar(20)" json:"phone_number" validate:"omitempty,e164"`
 	OfficeLocation *string `gorm:"type:varchar(200)" json:"office_location" validate:"omitempty,max=200"`
 
+	// Head of department, who overdue approval decisions are escalated to. No relationship is
+	// declared so departments can still be migrated before users.
+	HeadUserID *uuid.UUID `gorm:"type:uuid;index" json:"head_user_id"`
+
 	// Relationships
 	Users []User `gorm:"foreignKey:DepartmentID;constraint:OnDelete:SET NULL" json:"users,omitempty"`
 
@@ -146,6 +150,14 @@ type User struct {
 	// Profile information
 	ProfilePictureURL *string `gorm:"type:varchar(500)" json:"profile_picture_url" validate:"omitempty,url"`
 	SignatureFilePath *string `gorm:"type:varchar(500)" json:"signature_file_path"`
+	PreferredLanguage string  `gorm:"type:varchar(10);default:'en'" json:"preferred_language"`
+
+	// Used to flag possible conflicts of interest with applicants at the same address
+	ResidentialAddress *string `gorm:"type:text" json:"residential_address"`
+
+	// Set for staff provisioned from the HR export. Only these users are deactivated when they
+	// drop out of a later import.
+	EmployeeNumber *string `gorm:"type:varchar(50);uniqueIndex" json:"employee_number,omitempty"`
 
 	// Audit fields (using custom names for User model)
 	CreatedBy     string         `gorm:"type:varchar(255);not null" json:"created_by" validate:"required"`
@@ -165,6 +177,16 @@ func (User) TableName() string {
 	return "users"
 }
 
+// ClearCredentials blanks the password hash, TOTP secret and login lockout state, for copies of
+// the user that are cached or otherwise kept outside the database
+func (u *User) ClearCredentials() {
+	u.Password = ""
+	u.TOTPSecret = ""
+	u.FailedLoginAttempts = 0
+	u.LockedUntil = nil
+	u.PasswordChangedAt = nil
+}
+
 // IsLocked checks if the user account is currently locked
 func (u *User) IsLocked() bool {
 	return u.LockedUntil != nil && u.LockedUntil.After(time.Now())
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// UserImportRun records one HR export import, with the reconciliation report it produced.
+// Dry runs are recorded too so an import can be previewed and compared before it is applied.
+type UserImportRun struct {
+	ID       uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	FileName string    `gorm:"type:varchar(255);not null" json:"file_name"`
+	DryRun   bool      `gorm:"not null;default:false;index" json:"dry_run"`
+
+	TotalRows          int `gorm:"not null;default:0" json:"total_rows"`
+	CreatedCount       int `gorm:"not null;default:0" json:"created_count"`
+	UpdatedCount       int `gorm:"not null;default:0" json:"updated_count"`
+	UnchangedCount     int `gorm:"not null;default:0" json:"unchanged_count"`
+	DeactivatedCount   int `gorm:"not null;default:0" json:"deactivated_count"`
+	ErrorCount         int `gorm:"not null;default:0" json:"error_count"`
+	DepartmentsCreated int `gorm:"not null;default:0" json:"departments_created"`
+	InvitationsSent    int `gorm:"not null;default:0" json:"invitations_sent"`
+
+	// Report holds the per-row reconciliation outcome
+	Report datatypes.JSON `gorm:"type:jsonb" json:"report"`
+
+	// Audit fields
+	CreatedBy string         `gorm:"type:varchar(255);not null" json:"created_by"`
+	CreatedAt time.Time      `gorm:"autoCreateTime;index" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (r *UserImportRun) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
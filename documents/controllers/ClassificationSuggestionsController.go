@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"town-planning-backend/config"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type ResolveClassificationSuggestionRequest struct {
+	Accept bool `json:"accept"`
+}
+
+// GetClassificationSuggestions lists the category suggestions made for a document
+func (dc *DocumentController) GetClassificationSuggestions(c *fiber.Ctx) error {
+	documentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid document ID format"})
+	}
+
+	suggestions, err := dc.DocumentRepo.GetClassificationSuggestions(documentID)
+	if err != nil {
+		config.Logger.Error("Failed to fetch classification suggestions",
+			zap.String("document_id", documentID.String()),
+			zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to fetch suggestions",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Suggestions retrieved successfully",
+		"data":    suggestions,
+	})
+}
+
+// ResolveClassificationSuggestion accepts or rejects a suggested category. Accepting moves the
+// document into that category.
+func (dc *DocumentController) ResolveClassificationSuggestion(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "User not authenticated",
+		})
+	}
+
+	documentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid document ID format"})
+	}
+	suggestionID, err := uuid.Parse(c.Params("suggestionId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid suggestion ID format"})
+	}
+
+	var request ResolveClassificationSuggestionRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+
+	tx := dc.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to start transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	suggestion, err := dc.DocumentRepo.ResolveClassificationSuggestion(tx, documentID, suggestionID, request.Accept, payload.UserID.String())
+	if err != nil {
+		tx.Rollback()
+		statusCode := fiber.StatusInternalServerError
+		switch err.Error() {
+		case "suggestion not found", "document not found":
+			statusCode = fiber.StatusNotFound
+		case "suggestion already resolved":
+			statusCode = fiber.StatusConflict
+		default:
+			config.Logger.Error("Failed to resolve classification suggestion",
+				zap.String("document_id", documentID.String()),
+				zap.String("suggestion_id", suggestionID.String()),
+				zap.Error(err))
+		}
+		return c.Status(statusCode).JSON(fiber.Map{
+			"message": "Failed to resolve suggestion",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Suggestion resolved successfully",
+		"data":    suggestion,
+	})
+}
+
+// GetClassificationRuleStats reports the acceptance rate of each classifier rule
+func (dc *DocumentController) GetClassificationRuleStats(c *fiber.Ctx) error {
+	stats, err := dc.DocumentRepo.GetClassificationRuleStats()
+	if err != nil {
+		config.Logger.Error("Failed to fetch classification rule stats", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to fetch classification statistics",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Classification statistics retrieved successfully",
+		"data":    stats,
+	})
+}
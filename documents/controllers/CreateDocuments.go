@@ -1,19 +1,69 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
+	"strings"
 	"town-planning-backend/config"
+	documents_requests "town-planning-backend/documents/requests"
+	"town-planning-backend/documents/services"
+	"town-planning-backend/token"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
+// CreateDocument uploads a single document.
+// Multipart fields: file (required), category_code (optional, classified when empty or OTHER),
+// and at least one of applicant_id, application_id, stand_id.
 func (dc *DocumentController) CreateDocument(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "User not authenticated",
+		})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "File is required",
+			"error":   err.Error(),
+		})
+	}
+
+	request := &documents_requests.CreateDocumentRequest{
+		FileName:     fileHeader.Filename,
+		CategoryCode: strings.ToUpper(strings.TrimSpace(c.FormValue("category_code"))),
+		CreatedBy:    payload.UserID.String(),
+		FileType:     fileHeader.Header.Get("Content-Type"),
+	}
+
+	for field, target := range map[string]**uuid.UUID{
+		"applicant_id":   &request.ApplicantID,
+		"application_id": &request.ApplicationID,
+		"stand_id":       &request.StandID,
+	} {
+		raw := strings.TrimSpace(c.FormValue(field))
+		if raw == "" {
+			continue
+		}
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "Invalid " + field,
+				"error":   err.Error(),
+			})
+		}
+		*target = &id
+	}
+
 	config.Logger.Info("Starting transaction for document creation")
 
 	// Start transaction
-	tx := dc.DB.Session(&gorm.Session{}).WithContext(c.Context()).Begin()
+	tx := dc.DB.Session(&gorm.Session{}).WithContext(c.UserContext()).Begin()
 	if tx.Error != nil {
 		config.Logger.Error("Failed to start transaction", zap.Error(tx.Error))
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -31,10 +81,15 @@ func (dc *DocumentController) CreateDocument(c *fiber.Ctx) error {
 	}()
 
 	// Call service and pass the transaction
-	response, err := dc.DocumentService.UnifiedCreateDocument(tx, c, nil, nil, nil)
+	response, err := dc.DocumentService.UnifiedCreateDocument(tx, c, request, nil, fileHeader)
 	if err != nil {
 		config.Logger.Error("Document creation failed", zap.Error(err))
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		status := fiber.StatusBadRequest
+		var quotaErr *services.StorageQuotaError
+		if errors.As(err, &quotaErr) {
+			status = fiber.StatusRequestEntityTooLarge
+		}
+		return c.Status(status).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
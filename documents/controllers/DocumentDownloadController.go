@@ -0,0 +1,235 @@
+package controllers
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/documents/services"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type SetWatermarkPolicyRequest struct {
+	WatermarkPolicy models.WatermarkPolicy `json:"watermark_policy"`
+}
+
+var watermarkPolicies = map[models.WatermarkPolicy]bool{
+	models.WatermarkOptional:        true,
+	models.WatermarkExternalSharing: true,
+	models.WatermarkAlways:          true,
+}
+
+// DownloadDocumentController streams a document. With watermark=true, or when the category's
+// policy demands it, the copy is stamped with the downloader's name, the date and the
+// application number. external_sharing=true marks a copy meant to leave the council. Every
+// download is recorded in the document's audit log.
+func (dc *DocumentController) DownloadDocumentController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	documentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid document ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	document, planNumber, err := dc.DocumentRepo.GetDocumentForDownload(documentID)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if err.Error() == "document not found" {
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to download document",
+			"error":   err.Error(),
+		})
+	}
+
+	externalSharing := c.QueryBool("external_sharing", false)
+	watermark := c.QueryBool("watermark", false)
+	if document.Category != nil && document.Category.WatermarkPolicy.WatermarkRequired(externalSharing) {
+		watermark = true
+	}
+
+	file, err := dc.openDocumentFile(document)
+	if err != nil {
+		config.Logger.Error("Failed to open document file", zap.Error(err), zap.String("documentID", documentID.String()))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to download document",
+			"error":   err.Error(),
+		})
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to read document",
+			"error":   err.Error(),
+		})
+	}
+
+	var user models.User
+	if err := dc.DB.Select("id", "first_name", "last_name", "email").Where("id = ?", payload.UserID).First(&user).Error; err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Please log out and log in again",
+		})
+	}
+	userName := strings.TrimSpace(user.FirstName + " " + user.LastName)
+
+	if watermark {
+		data, err = dc.DocumentService.Watermarker.Apply(c.UserContext(), data, document.FileName, document.MimeType, services.Watermark{
+			DownloadedBy:      userName,
+			DownloadedAt:      time.Now(),
+			ApplicationNumber: planNumber,
+		})
+		if err != nil {
+			status := fiber.StatusBadGateway
+			switch {
+			case errors.Is(err, services.ErrWatermarkUnsupported):
+				status = fiber.StatusUnprocessableEntity
+			case errors.Is(err, services.ErrWatermarkUnavailable):
+				status = fiber.StatusServiceUnavailable
+			}
+			config.Logger.Warn("Failed to watermark document",
+				zap.Error(err),
+				zap.String("documentID", documentID.String()))
+			return c.Status(status).JSON(fiber.Map{
+				"success": false,
+				"message": "The document could not be watermarked",
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	details := "downloaded"
+	if watermark {
+		details = "downloaded with watermark"
+	}
+	if externalSharing {
+		details += " for external sharing"
+	}
+	ipAddress := c.IP()
+	userAgent := c.Get(fiber.HeaderUserAgent)
+	auditLog := models.DocumentAuditLog{
+		ID:         uuid.New(),
+		DocumentID: document.ID,
+		Action:     models.ActionDownload,
+		UserID:     payload.UserID.String(),
+		UserName:   &userName,
+		Details:    &details,
+		IPAddress:  &ipAddress,
+		UserAgent:  &userAgent,
+	}
+	if err := dc.DB.Create(&auditLog).Error; err != nil {
+		config.Logger.Warn("Failed to record document download",
+			zap.Error(err),
+			zap.String("documentID", documentID.String()))
+	}
+
+	c.Set(fiber.HeaderContentType, document.MimeType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, document.FileName))
+	return c.Status(fiber.StatusOK).Send(data)
+}
+
+// openDocumentFile opens a document for download. Documents of an application in cold storage
+// are read through their gzip copy, so the original bytes are served either way.
+func (dc *DocumentController) openDocumentFile(document *models.Document) (io.ReadCloser, error) {
+	archivePath, err := dc.DocumentRepo.GetDocumentArchivePath(document.ID)
+	if err != nil {
+		return nil, err
+	}
+	if archivePath == "" {
+		return dc.DocumentService.FileStorage.DownloadFile(document.FilePath)
+	}
+
+	archive, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := gzip.NewReader(archive)
+	if err != nil {
+		archive.Close()
+		return nil, fmt.Errorf("failed to read archive file: %w", err)
+	}
+	return archivedFile{Reader: zr, archive: archive}, nil
+}
+
+// archivedFile closes the archive file along with its gzip reader
+type archivedFile struct {
+	*gzip.Reader
+	archive *os.File
+}
+
+func (f archivedFile) Close() error {
+	f.Reader.Close()
+	return f.archive.Close()
+}
+
+// SetWatermarkPolicyController sets when downloads of a category's documents are watermarked
+func (dc *DocumentController) SetWatermarkPolicyController(c *fiber.Ctx) error {
+	categoryID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid category ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var request SetWatermarkPolicyRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+	if !watermarkPolicies[request.WatermarkPolicy] {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid watermark policy",
+			"error":   "watermark_policy must be OPTIONAL, EXTERNAL_SHARING or ALWAYS",
+		})
+	}
+
+	category, err := dc.DocumentRepo.SetCategoryWatermarkPolicy(dc.DB, categoryID, request.WatermarkPolicy)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if err.Error() == "document category not found" {
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to save watermark policy",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Watermark policy saved",
+		"data":    category,
+	})
+}
@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"strings"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/documents/services"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// SaveFileNamingPolicyRequest sets a naming pattern. Without a category it sets the council default.
+type SaveFileNamingPolicyRequest struct {
+	CategoryID *uuid.UUID `json:"category_id"`
+	Pattern    string     `json:"pattern"`
+}
+
+// sampleFileName shows what a pattern produces for a typical application document
+func sampleFileName(pattern string) string {
+	return services.RenderFileName(pattern, services.FileNameValues{
+		Category:   "SITE_PLAN",
+		Applicant:  "Tendai Moyo",
+		PlanNumber: "TP/2024/0153",
+		StoredAt:   time.Date(2024, time.March, 4, 9, 30, 0, 0, time.Local),
+		Version:    2,
+		UID:        "3f9c2a1b",
+	}, ".pdf")
+}
+
+// GetFileNamingPoliciesController lists the configured naming policies with the tokens they may use
+func (dc *DocumentController) GetFileNamingPoliciesController(c *fiber.Ctx) error {
+	policies, err := dc.DocumentRepo.GetFileNamingPolicies()
+	if err != nil {
+		config.Logger.Error("Failed to fetch file naming policies", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch file naming policies",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "File naming policies retrieved successfully",
+		"data": fiber.Map{
+			"default_pattern": services.DefaultFileNamingPattern,
+			"default_sample":  sampleFileName(services.DefaultFileNamingPattern),
+			"tokens":          services.FileNamingTokens,
+			"policies":        policies,
+		},
+	})
+}
+
+// SaveFileNamingPolicyController sets the naming pattern for a category or the council default.
+// Files already stored keep their names.
+func (dc *DocumentController) SaveFileNamingPolicyController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	var request SaveFileNamingPolicyRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+	request.Pattern = strings.TrimSpace(request.Pattern)
+	if err := services.ValidateFileNamingPattern(request.Pattern); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid file naming pattern",
+			"error":   err.Error(),
+		})
+	}
+
+	policy, err := dc.DocumentRepo.SaveFileNamingPolicy(dc.DB, request.CategoryID, request.Pattern, payload.UserID.String())
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if err.Error() == "document category not found" {
+			status = fiber.StatusNotFound
+		}
+		config.Logger.Error("Failed to save file naming policy", zap.Error(err))
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to save file naming policy",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "File naming policy saved successfully",
+		"data": fiber.Map{
+			"policy": policy,
+			"sample": sampleFileName(policy.Pattern),
+		},
+	})
+}
+
+// DeleteFileNamingPolicyController removes a category policy, or the council default when no
+// category_id is given, so that files fall back to the next policy
+func (dc *DocumentController) DeleteFileNamingPolicyController(c *fiber.Ctx) error {
+	var categoryID *uuid.UUID
+	if value := c.Query("category_id"); value != "" {
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": "Invalid category ID",
+				"error":   "invalid_uuid",
+			})
+		}
+		categoryID = &parsed
+	}
+
+	if err := dc.DocumentRepo.DeleteFileNamingPolicy(dc.DB, categoryID); err != nil {
+		status := fiber.StatusInternalServerError
+		if err.Error() == "file naming policy not found" {
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to delete file naming policy",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "File naming policy deleted successfully",
+	})
+}
@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"town-planning-backend/utils/pagination"
+
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -9,8 +11,32 @@ func (dc *DocumentController) GetDocumentsByPlanID(c *fiber.Ctx) error {
 	// Get the plan UUID from the URL parameter
 	planUUID := c.Params("id") // The plan UUID is passed as a URL parameter
 
+	// Clients written before pagination expect every document, so only page when asked to
+	if !pagination.Requested(c) {
+		documents, _, err := dc.DocumentRepo.GetDocumentsByPlanID(c.UserContext(), planUUID, nil)
+		if err != nil {
+			return c.Status(404).JSON(fiber.Map{
+				"message": "Documents not found",
+				"error":   err.Error(),
+			})
+		}
+		return c.JSON(fiber.Map{
+			"message": "Documents retrieved successfully",
+			"data":    documents,
+			"error":   nil,
+		})
+	}
+
+	page, err := pagination.ParseRequest(c, 20)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"message": "Invalid pagination parameters",
+			"error":   err.Error(),
+		})
+	}
+
 	// Fetch the plan from the repository using the UUID
-	documents, err := dc.DocumentRepo.GetDocumentsByPlanID(planUUID)
+	documents, total, err := dc.DocumentRepo.GetDocumentsByPlanID(c.UserContext(), planUUID, &page)
 	if err != nil {
 		// If the plan is not found or an error occurs, return an error response
 		return c.Status(404).JSON(fiber.Map{
@@ -19,10 +45,15 @@ func (dc *DocumentController) GetDocumentsByPlanID(c *fiber.Ctx) error {
 		})
 	}
 
+	var next *pagination.Cursor
+	if len(documents) > 0 {
+		last := documents[len(documents)-1]
+		next = page.NextCursor(len(documents), last.CreatedAt, last.ID)
+	}
+
 	// Return the plan data in the response
-	return c.JSON(fiber.Map{
-		"message": "Documents retrieved successfully",
-		"data":    documents,
-		"error":   nil,
-	})
+	response := pagination.NewEnvelope(c, page, documents, total, next).Map()
+	response["message"] = "Documents retrieved successfully"
+	response["error"] = nil
+	return c.JSON(response)
 }
@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/documents/repositories"
+	"town-planning-backend/documents/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// storageReportWindowDays is how far back the department report totals recent uploads
+const storageReportWindowDays = 30
+
+// StorageUsageView is an application's or applicant's usage against its quota
+type StorageUsageView struct {
+	repositories.StorageUsage
+	QuotaBytes     int64   `json:"quota_bytes"`
+	RemainingBytes int64   `json:"remaining_bytes"`
+	PercentUsed    float64 `json:"percent_used"`
+	Used           string  `json:"used"`
+	Quota          string  `json:"quota"`
+}
+
+func newStorageUsageView(usage *repositories.StorageUsage, quota int64) StorageUsageView {
+	view := StorageUsageView{
+		StorageUsage: *usage,
+		QuotaBytes:   quota,
+		Used:         services.FormatStorageSize(usage.UsedBytes),
+		Quota:        services.FormatStorageSize(quota),
+	}
+	if quota > 0 {
+		view.RemainingBytes = max(quota-usage.UsedBytes, 0)
+		view.PercentUsed = float64(usage.UsedBytes) * 100 / float64(quota)
+	}
+	return view
+}
+
+// GetApplicationStorageUsageController shows how much of its quota an application's files use
+func (dc *DocumentController) GetApplicationStorageUsageController(c *fiber.Ctx) error {
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   err.Error(),
+		})
+	}
+
+	usage, err := dc.DocumentRepo.GetApplicationStorageUsage(dc.DB, applicationID)
+	if err != nil {
+		config.Logger.Error("Failed to calculate application storage usage",
+			zap.Error(err),
+			zap.String("applicationID", applicationID.String()))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to calculate storage usage",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Application storage usage retrieved successfully",
+		"data":    newStorageUsageView(usage, services.LoadStorageQuotas().ApplicationBytes),
+	})
+}
+
+// GetApplicantStorageUsageController shows how much of its quota an applicant's files use
+func (dc *DocumentController) GetApplicantStorageUsageController(c *fiber.Ctx) error {
+	applicantID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid applicant ID",
+			"error":   err.Error(),
+		})
+	}
+
+	usage, err := dc.DocumentRepo.GetApplicantStorageUsage(dc.DB, applicantID)
+	if err != nil {
+		config.Logger.Error("Failed to calculate applicant storage usage",
+			zap.Error(err),
+			zap.String("applicantID", applicantID.String()))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to calculate storage usage",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Applicant storage usage retrieved successfully",
+		"data":    newStorageUsageView(usage, services.LoadStorageQuotas().ApplicantBytes),
+	})
+}
+
+// GetDepartmentStorageReportController reports the uploads volume usage by department, with the
+// last 30 days' uploads for each, for capacity planning
+func (dc *DocumentController) GetDepartmentStorageReportController(c *fiber.Ctx) error {
+	since := time.Now().AddDate(0, 0, -storageReportWindowDays)
+
+	departments, err := dc.DocumentRepo.GetDepartmentStorageUsage(since)
+	if err != nil {
+		config.Logger.Error("Failed to build department storage report", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to build storage report",
+			"error":   err.Error(),
+		})
+	}
+
+	var totalBytes, addedBytes, documentCount int64
+	for _, department := range departments {
+		totalBytes += department.UsedBytes
+		addedBytes += department.AddedBytes
+		documentCount += department.DocumentCount
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Storage report generated successfully",
+		"data": fiber.Map{
+			"departments":    departments,
+			"document_count": documentCount,
+			"used_bytes":     totalBytes,
+			"used":           services.FormatStorageSize(totalBytes),
+			"added_bytes":    addedBytes,
+			"added":          services.FormatStorageSize(addedBytes),
+			"added_since":    since,
+			"quotas": fiber.Map{
+				"application_bytes": services.LoadStorageQuotas().ApplicationBytes,
+				"applicant_bytes":   services.LoadStorageQuotas().ApplicantBytes,
+			},
+		},
+	})
+}
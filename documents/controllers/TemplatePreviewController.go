@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"strings"
+	"town-planning-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// templateSummary describes a letter, email or text message and the languages it has been
+// translated into
+type templateSummary struct {
+	Name      string   `json:"name"`
+	Kind      string   `json:"kind"` // LETTER, EMAIL or SMS
+	Languages []string `json:"languages"`
+}
+
+// ListTemplatesController lists the applicant letters, emails and text messages with their
+// available languages.
+// Languages without a translation fall back to English.
+func (dc *DocumentController) ListTemplatesController(c *fiber.Ctx) error {
+	templates := []templateSummary{}
+	for _, letter := range utils.LetterTemplates {
+		templates = append(templates, templateSummary{
+			Name:      letter.Name,
+			Kind:      "LETTER",
+			Languages: utils.LetterTemplateLanguages(letter),
+		})
+	}
+	for _, name := range utils.EmailTemplateNames() {
+		templates = append(templates, templateSummary{
+			Name:      name,
+			Kind:      "EMAIL",
+			Languages: utils.EmailTemplateLanguages(name),
+		})
+	}
+	// Text messages share the emails' names
+	for _, name := range utils.SMSTemplateNames() {
+		templates = append(templates, templateSummary{
+			Name:      name,
+			Kind:      "SMS",
+			Languages: utils.SMSTemplateLanguages(name),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Templates retrieved successfully",
+		"data": fiber.Map{
+			"supported_languages": utils.TemplateLanguages,
+			"default_language":    utils.DefaultTemplateLanguage,
+			"templates":           templates,
+		},
+	})
+}
+
+// PreviewTemplateController renders a letter or email with sample data in the requested language.
+// Query: language (en, sn or nd; defaults to English), kind=sms for the text message version of
+// an email. The response reports the language actually
+// used, which differs from the requested one when the template falls back to English.
+func (dc *DocumentController) PreviewTemplateController(c *fiber.Ctx) error {
+	name := c.Params("name")
+	requested := utils.NormalizeTemplateLanguage(c.Query("language"))
+
+	if _, err := utils.FindLetterTemplate(name); err == nil {
+		html, language, err := utils.PreviewLetterTemplate(name, requested)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"message": "Failed to render template preview",
+				"error":   err.Error(),
+			})
+		}
+		return c.JSON(fiber.Map{
+			"success": true,
+			"message": "Template preview rendered successfully",
+			"data": fiber.Map{
+				"name":               name,
+				"kind":               "LETTER",
+				"requested_language": requested,
+				"language":           language,
+				"fallback":           language != requested,
+				"html":               html,
+			},
+		})
+	}
+
+	if strings.EqualFold(c.Query("kind"), "sms") {
+		body, language, err := utils.PreviewSMSTemplate(name, requested)
+		if err != nil {
+			status := fiber.StatusInternalServerError
+			if err.Error() == "template not found" {
+				status = fiber.StatusNotFound
+			}
+			return c.Status(status).JSON(fiber.Map{
+				"success": false,
+				"message": "Failed to render template preview",
+				"error":   err.Error(),
+			})
+		}
+		return c.JSON(fiber.Map{
+			"success": true,
+			"message": "Template preview rendered successfully",
+			"data": fiber.Map{
+				"name":               name,
+				"kind":               "SMS",
+				"requested_language": requested,
+				"language":           language,
+				"fallback":           language != requested,
+				"body":               body,
+				"characters":         len([]rune(body)),
+			},
+		})
+	}
+
+	subject, body, language, err := utils.PreviewEmailTemplate(name, requested)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if err.Error() == "template not found" {
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to render template preview",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Template preview rendered successfully",
+		"data": fiber.Map{
+			"name":               name,
+			"kind":               "EMAIL",
+			"requested_language": requested,
+			"language":           language,
+			"fallback":           language != requested,
+			"subject":            subject,
+			"body":               body,
+		},
+	})
+}
@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"town-planning-backend/cache"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// cachedDocumentRepository serves document category lookups from Redis. Every document
+// upload resolves its category by code, which makes it one of the hottest reads.
+type cachedDocumentRepository struct {
+	DocumentRepository
+	cache *cache.RepositoryCache
+}
+
+// NewCachedDocumentRepository wraps a document repository with the repository cache
+func NewCachedDocumentRepository(repo DocumentRepository, repoCache *cache.RepositoryCache) DocumentRepository {
+	return &cachedDocumentRepository{DocumentRepository: repo, cache: repoCache}
+}
+
+func (r *cachedDocumentRepository) GetCategoryByCode(tx *gorm.DB, code string) (*models.DocumentCategory, error) {
+	var category models.DocumentCategory
+	if r.cache.Get(cache.EntityDocumentCategory, code, &category) {
+		return &category, nil
+	}
+
+	found, err := r.DocumentRepository.GetCategoryByCode(tx, code)
+	if err != nil {
+		return nil, err
+	}
+	r.cache.Set(cache.EntityDocumentCategory, code, found)
+	return found, nil
+}
+
+func (r *cachedDocumentRepository) CreateCategory(tx *gorm.DB, category *models.DocumentCategory) (*models.DocumentCategory, error) {
+	created, err := r.DocumentRepository.CreateCategory(tx, category)
+	if err != nil {
+		return nil, err
+	}
+	r.cache.Invalidate(cache.EntityDocumentCategory, created.Code)
+	return created, nil
+}
+
+func (r *cachedDocumentRepository) SetCategoryWatermarkPolicy(tx *gorm.DB, categoryID uuid.UUID, policy models.WatermarkPolicy) (*models.DocumentCategory, error) {
+	category, err := r.DocumentRepository.SetCategoryWatermarkPolicy(tx, categoryID, policy)
+	if err != nil {
+		return nil, err
+	}
+	r.cache.Invalidate(cache.EntityDocumentCategory, category.Code)
+	return category, nil
+}
@@ -0,0 +1,151 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ClassificationRuleStats summarises how uploaders responded to one classifier rule
+type ClassificationRuleStats struct {
+	Rule           string  `json:"rule"`
+	Total          int64   `json:"total"`
+	Accepted       int64   `json:"accepted"`
+	Rejected       int64   `json:"rejected"`
+	Pending        int64   `json:"pending"`
+	AcceptanceRate float64 `json:"acceptance_rate"` // accepted / resolved, 0 when nothing is resolved
+}
+
+func (r *documentRepository) CreateClassificationSuggestions(tx *gorm.DB, suggestions []models.DocumentClassificationSuggestion) error {
+	if len(suggestions) == 0 {
+		return nil
+	}
+	if err := tx.Create(&suggestions).Error; err != nil {
+		return fmt.Errorf("failed to save classification suggestions: %w", err)
+	}
+	return nil
+}
+
+func (r *documentRepository) GetClassificationSuggestions(documentID uuid.UUID) ([]models.DocumentClassificationSuggestion, error) {
+	var suggestions []models.DocumentClassificationSuggestion
+	err := r.db.Preload("SuggestedCategory").
+		Where("document_id = ?", documentID).
+		Order("confidence DESC, created_at ASC").
+		Find(&suggestions).Error
+	return suggestions, err
+}
+
+// ResolveClassificationSuggestion records the uploader's response to a suggestion. Accepting one
+// moves the document into the suggested category and rejects the document's other pending suggestions.
+func (r *documentRepository) ResolveClassificationSuggestion(
+	tx *gorm.DB,
+	documentID uuid.UUID,
+	suggestionID uuid.UUID,
+	accept bool,
+	resolvedBy string,
+) (*models.DocumentClassificationSuggestion, error) {
+	var suggestion models.DocumentClassificationSuggestion
+	err := tx.Preload("SuggestedCategory").
+		Where("id = ? AND document_id = ?", suggestionID, documentID).
+		First(&suggestion).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("suggestion not found")
+		}
+		return nil, err
+	}
+	if suggestion.Status != models.SuggestionPending {
+		return nil, errors.New("suggestion already resolved")
+	}
+
+	now := time.Now()
+	suggestion.Status = models.SuggestionRejected
+	if accept {
+		suggestion.Status = models.SuggestionAccepted
+
+		var document models.Document
+		if err := tx.Where("id = ?", documentID).First(&document).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("document not found")
+			}
+			return nil, err
+		}
+
+		if err := tx.Model(&models.Document{}).
+			Where("id = ?", documentID).
+			Updates(map[string]interface{}{
+				"category_id": suggestion.SuggestedCategoryID,
+				"updated_by":  resolvedBy,
+				"last_action": models.ActionUpdate,
+			}).Error; err != nil {
+			return nil, fmt.Errorf("failed to update document category: %w", err)
+		}
+
+		reason := fmt.Sprintf("Accepted classification suggestion (%s)", suggestion.Rule)
+		auditLog := &models.DocumentAuditLog{
+			ID:            uuid.New(),
+			DocumentID:    documentID,
+			Action:        models.ActionUpdate,
+			UserID:        resolvedBy,
+			Reason:        &reason,
+			OldCategoryID: document.CategoryID,
+			NewCategoryID: &suggestion.SuggestedCategoryID,
+			CreatedAt:     now,
+		}
+		if err := tx.Create(auditLog).Error; err != nil {
+			return nil, fmt.Errorf("failed to create audit log: %w", err)
+		}
+
+		// Only one category can win; the alternatives count as rejected for their rules
+		if err := tx.Model(&models.DocumentClassificationSuggestion{}).
+			Where("document_id = ? AND id <> ? AND status = ?", documentID, suggestion.ID, models.SuggestionPending).
+			Updates(map[string]interface{}{
+				"status":      models.SuggestionRejected,
+				"resolved_by": resolvedBy,
+				"resolved_at": now,
+			}).Error; err != nil {
+			return nil, fmt.Errorf("failed to reject alternative suggestions: %w", err)
+		}
+	}
+
+	suggestion.ResolvedBy = &resolvedBy
+	suggestion.ResolvedAt = &now
+	if err := tx.Model(&suggestion).Updates(map[string]interface{}{
+		"status":      suggestion.Status,
+		"resolved_by": resolvedBy,
+		"resolved_at": now,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to resolve suggestion: %w", err)
+	}
+
+	return &suggestion, nil
+}
+
+// GetClassificationRuleStats returns the acceptance rate of every rule that has made a suggestion
+func (r *documentRepository) GetClassificationRuleStats() ([]ClassificationRuleStats, error) {
+	var stats []ClassificationRuleStats
+	err := r.db.Model(&models.DocumentClassificationSuggestion{}).
+		Select(`rule,
+			COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE status = ?) AS accepted,
+			COUNT(*) FILTER (WHERE status = ?) AS rejected,
+			COUNT(*) FILTER (WHERE status = ?) AS pending`,
+			models.SuggestionAccepted, models.SuggestionRejected, models.SuggestionPending).
+		Group("rule").
+		Order("rule ASC").
+		Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range stats {
+		if resolved := stats[i].Accepted + stats[i].Rejected; resolved > 0 {
+			stats[i].AcceptanceRate = float64(stats[i].Accepted) / float64(resolved)
+		}
+	}
+	return stats, nil
+}
@@ -1,12 +1,14 @@
 package repositories
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
 	"town-planning-backend/config"
 	"town-planning-backend/db/models"
 	stand_repositories "town-planning-backend/stands/repositories"
+	"town-planning-backend/utils/pagination"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -14,7 +16,7 @@ import (
 )
 
 type DocumentRepository interface {
-	GetDocumentsByPlanID(planUUID string) ([]models.Document, error)
+	GetDocumentsByPlanID(ctx context.Context, planUUID string, page *pagination.Request) ([]models.Document, int64, error)
 	CreateDocument(tx *gorm.DB, document *models.Document) (*models.Document, error)
 	CreateDocumentWithAudit(tx *gorm.DB, document *models.Document, userID, userName, userRole, ipAddress, userAgent string) (*models.Document, error)
 	DeleteDocument(id uuid.UUID) error
@@ -29,6 +31,29 @@ type DocumentRepository interface {
 	CreateEntityDocumentRelationship(tx *gorm.DB, relationship interface{}) error
 	DeleteEntityDocumentRelationships(tx *gorm.DB, documentID uuid.UUID) error
 	GetDocumentWithRelationships(tx *gorm.DB, documentID uuid.UUID) (*models.Document, error)
+
+	// Classification suggestions
+	CreateClassificationSuggestions(tx *gorm.DB, suggestions []models.DocumentClassificationSuggestion) error
+	GetClassificationSuggestions(documentID uuid.UUID) ([]models.DocumentClassificationSuggestion, error)
+	ResolveClassificationSuggestion(tx *gorm.DB, documentID uuid.UUID, suggestionID uuid.UUID, accept bool, resolvedBy string) (*models.DocumentClassificationSuggestion, error)
+	GetClassificationRuleStats() ([]ClassificationRuleStats, error)
+
+	// File naming policies
+	GetFileNamingPolicies() ([]models.FileNamingPolicy, error)
+	GetFileNamingPattern(tx *gorm.DB, categoryID uuid.UUID) (string, error)
+	SaveFileNamingPolicy(tx *gorm.DB, categoryID *uuid.UUID, pattern string, savedBy string) (*models.FileNamingPolicy, error)
+	DeleteFileNamingPolicy(tx *gorm.DB, categoryID *uuid.UUID) error
+	GetApplicationPlanNumber(tx *gorm.DB, applicationID uuid.UUID) (string, error)
+
+	// Watermarked downloads
+	SetCategoryWatermarkPolicy(tx *gorm.DB, categoryID uuid.UUID, policy models.WatermarkPolicy) (*models.DocumentCategory, error)
+	GetDocumentForDownload(documentID uuid.UUID) (*models.Document, string, error)
+	GetDocumentArchivePath(documentID uuid.UUID) (string, error)
+
+	// Storage usage
+	GetApplicationStorageUsage(tx *gorm.DB, applicationID uuid.UUID) (*StorageUsage, error)
+	GetApplicantStorageUsage(tx *gorm.DB, applicantID uuid.UUID) (*StorageUsage, error)
+	GetDepartmentStorageUsage(addedSince time.Time) ([]DepartmentStorageUsage, error)
 }
 
 type documentRepository struct {
@@ -238,16 +263,29 @@ func (r *documentRepository) UpdateDocument(tx *gorm.DB, documentID uuid.UUID, u
 	return []models.Document{document}, nil
 }
 
-// GetDocumentsByPlanID - needs to be updated based on your plan structure
-func (r *documentRepository) GetDocumentsByPlanID(planUUID string) ([]models.Document, error) {
+// GetDocumentsByPlanID - needs to be updated based on your plan structure. Returns every
+// document unless page is set.
+func (r *documentRepository) GetDocumentsByPlanID(ctx context.Context, planUUID string, page *pagination.Request) ([]models.Document, int64, error) {
 	var documents []models.Document
+	var total int64
+	db := r.db.WithContext(ctx)
 
 	// This depends on how payment plan documents are stored in your system
 	// You might need to create a PaymentPlanDocument join table or use existing relationships
-	if err := r.db.Find(&documents).Error; err != nil {
-		return nil, err
+	if page == nil {
+		if err := db.Find(&documents).Error; err != nil {
+			return nil, 0, err
+		}
+		return documents, int64(len(documents)), nil
 	}
-	return documents, nil
+
+	if err := db.Model(&models.Document{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := page.Window(db, "documents", "created_at DESC").Find(&documents).Error; err != nil {
+		return nil, 0, err
+	}
+	return documents, total, nil
 }
 
 func (r *documentRepository) DeleteDocument(id uuid.UUID) error {
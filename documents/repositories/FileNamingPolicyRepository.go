@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GetFileNamingPolicies lists the council default policy first, then the category policies
+func (r *documentRepository) GetFileNamingPolicies() ([]models.FileNamingPolicy, error) {
+	var policies []models.FileNamingPolicy
+	err := r.db.Preload("Category").
+		Order("category_id IS NOT NULL, created_at ASC").
+		Find(&policies).Error
+	return policies, err
+}
+
+// GetFileNamingPattern returns the pattern for a category: its own policy, else the council
+// default, else an empty string when neither is configured
+func (r *documentRepository) GetFileNamingPattern(tx *gorm.DB, categoryID uuid.UUID) (string, error) {
+	var policy models.FileNamingPolicy
+	err := tx.Where("category_id = ? OR category_id IS NULL", categoryID).
+		Order("category_id IS NULL").
+		First(&policy).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to load file naming policy: %w", err)
+	}
+	return policy.Pattern, nil
+}
+
+// SaveFileNamingPolicy sets the pattern for a category, or the council default when categoryID
+// is nil
+func (r *documentRepository) SaveFileNamingPolicy(tx *gorm.DB, categoryID *uuid.UUID, pattern string, savedBy string) (*models.FileNamingPolicy, error) {
+	if categoryID != nil {
+		var category models.DocumentCategory
+		if err := tx.Where("id = ?", *categoryID).First(&category).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("document category not found")
+			}
+			return nil, fmt.Errorf("failed to load document category: %w", err)
+		}
+	}
+
+	var policy models.FileNamingPolicy
+	err := fileNamingPolicyScope(tx, categoryID).First(&policy).Error
+	switch {
+	case err == nil:
+		if err := tx.Model(&policy).Updates(map[string]interface{}{
+			"pattern":    pattern,
+			"updated_by": savedBy,
+		}).Error; err != nil {
+			return nil, fmt.Errorf("failed to update file naming policy: %w", err)
+		}
+		policy.Pattern = pattern
+		policy.UpdatedBy = &savedBy
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		policy = models.FileNamingPolicy{
+			CategoryID: categoryID,
+			Pattern:    pattern,
+			CreatedBy:  savedBy,
+		}
+		if err := tx.Create(&policy).Error; err != nil {
+			return nil, fmt.Errorf("failed to create file naming policy: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("failed to load file naming policy: %w", err)
+	}
+	return &policy, nil
+}
+
+// DeleteFileNamingPolicy removes a category policy, or the council default when categoryID is
+// nil, so that files fall back to the next policy
+func (r *documentRepository) DeleteFileNamingPolicy(tx *gorm.DB, categoryID *uuid.UUID) error {
+	result := fileNamingPolicyScope(tx, categoryID).Delete(&models.FileNamingPolicy{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete file naming policy: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("file naming policy not found")
+	}
+	return nil
+}
+
+// GetApplicationPlanNumber returns the plan number used to name an application's documents
+func (r *documentRepository) GetApplicationPlanNumber(tx *gorm.DB, applicationID uuid.UUID) (string, error) {
+	var application models.Application
+	if err := tx.Select("id", "plan_number").Where("id = ?", applicationID).First(&application).Error; err != nil {
+		return "", err
+	}
+	return application.PlanNumber, nil
+}
+
+func fileNamingPolicyScope(tx *gorm.DB, categoryID *uuid.UUID) *gorm.DB {
+	if categoryID == nil {
+		return tx.Where("category_id IS NULL")
+	}
+	return tx.Where("category_id = ?", *categoryID)
+}
@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// StorageUsage is the space taken by the files of one application or applicant. Archived
+// versions count, since their files stay on the uploads volume.
+type StorageUsage struct {
+	UsedBytes     int64 `json:"used_bytes"`
+	DocumentCount int64 `json:"document_count"`
+}
+
+// DepartmentStorageUsage is the space taken by files uploaded by a department's staff.
+// DepartmentID is nil for files from applicants through the portal and from accounts without a
+// department.
+type DepartmentStorageUsage struct {
+	DepartmentID   *uuid.UUID `json:"department_id"`
+	DepartmentName string     `json:"department_name"`
+	DocumentCount  int64      `json:"document_count"`
+	UsedBytes      int64      `json:"used_bytes"`
+	AddedBytes     int64      `json:"added_bytes"` // Uploaded within the report window
+}
+
+// GetApplicationStorageUsage sums the files linked to an application
+func (r *documentRepository) GetApplicationStorageUsage(tx *gorm.DB, applicationID uuid.UUID) (*StorageUsage, error) {
+	var usage StorageUsage
+	err := tx.Model(&models.Document{}).
+		Select("COUNT(*) AS document_count, COALESCE(SUM(documents.file_size), 0)::bigint AS used_bytes").
+		Where("documents.id IN (?)", tx.Model(&models.ApplicationDocument{}).
+			Select("document_id").
+			Where("application_id = ?", applicationID)).
+		Scan(&usage).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate application storage usage: %w", err)
+	}
+	return &usage, nil
+}
+
+// GetApplicantStorageUsage sums the files linked to an applicant, either directly or through
+// any of their applications. A file linked both ways is counted once.
+func (r *documentRepository) GetApplicantStorageUsage(tx *gorm.DB, applicantID uuid.UUID) (*StorageUsage, error) {
+	var usage StorageUsage
+	err := tx.Model(&models.Document{}).
+		Select("COUNT(*) AS document_count, COALESCE(SUM(documents.file_size), 0)::bigint AS used_bytes").
+		Where("documents.id IN (?) OR documents.id IN (?)",
+			tx.Model(&models.ApplicantDocument{}).
+				Select("document_id").
+				Where("applicant_id = ?", applicantID),
+			tx.Model(&models.ApplicationDocument{}).
+				Select("application_documents.document_id").
+				Joins("JOIN applications ON applications.id = application_documents.application_id").
+				Where("applications.applicant_id = ?", applicantID)).
+		Scan(&usage).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate applicant storage usage: %w", err)
+	}
+	return &usage, nil
+}
+
+// GetDepartmentStorageUsage groups every stored file by the department of the staff member who
+// uploaded it, largest first. Files uploaded since addedSince are also totalled separately to
+// show how fast each department is filling the volume.
+func (r *documentRepository) GetDepartmentStorageUsage(addedSince time.Time) ([]DepartmentStorageUsage, error) {
+	var usage []DepartmentStorageUsage
+	err := r.db.Model(&models.Document{}).
+		Select(`departments.id AS department_id,
+			COALESCE(departments.name, 'Applicants and unassigned') AS department_name,
+			COUNT(documents.id) AS document_count,
+			COALESCE(SUM(documents.file_size), 0)::bigint AS used_bytes,
+			COALESCE(SUM(documents.file_size) FILTER (WHERE documents.created_at >= ?), 0)::bigint AS added_bytes`, addedSince).
+		// Uploads record the uploader by user ID, older ones by email
+		Joins("LEFT JOIN users ON users.id::text = documents.created_by OR users.email = documents.created_by").
+		Joins("LEFT JOIN departments ON departments.id = users.department_id").
+		Group("departments.id, departments.name").
+		Order("used_bytes DESC").
+		Scan(&usage).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate department storage usage: %w", err)
+	}
+	return usage, nil
+}
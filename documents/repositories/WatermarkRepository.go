@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SetCategoryWatermarkPolicy changes when downloads of a category's documents are watermarked
+func (r *documentRepository) SetCategoryWatermarkPolicy(tx *gorm.DB, categoryID uuid.UUID, policy models.WatermarkPolicy) (*models.DocumentCategory, error) {
+	var category models.DocumentCategory
+	if err := tx.Where("id = ?", categoryID).First(&category).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("document category not found")
+		}
+		return nil, fmt.Errorf("failed to load document category: %w", err)
+	}
+
+	if err := tx.Model(&category).Update("watermark_policy", policy).Error; err != nil {
+		return nil, fmt.Errorf("failed to update watermark policy: %w", err)
+	}
+	category.WatermarkPolicy = policy
+	return &category, nil
+}
+
+// GetDocumentForDownload returns a current document with its category, and the plan number of
+// the application it is filed under, or an empty string when it is not an application document
+func (r *documentRepository) GetDocumentForDownload(documentID uuid.UUID) (*models.Document, string, error) {
+	var document models.Document
+	if err := r.db.Preload("Category").Where("id = ? AND is_active = ?", documentID, true).First(&document).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", errors.New("document not found")
+		}
+		return nil, "", fmt.Errorf("failed to load document: %w", err)
+	}
+
+	var planNumber string
+	err := r.db.Model(&models.ApplicationDocument{}).
+		Joins("JOIN applications ON applications.id = application_documents.application_id").
+		Where("application_documents.document_id = ?", documentID).
+		Order("application_documents.created_at ASC").
+		Limit(1).
+		Pluck("applications.plan_number", &planNumber).Error
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load document application: %w", err)
+	}
+	return &document, planNumber, nil
+}
+
+// GetDocumentArchivePath returns the path of the gzip copy of a document whose application is
+// in cold storage, or an empty string when the document is not archived
+func (r *documentRepository) GetDocumentArchivePath(documentID uuid.UUID) (string, error) {
+	var archivePath string
+	err := r.db.Model(&models.ApplicationArchiveDocument{}).
+		Joins("JOIN application_archives ON application_archives.id = application_archive_documents.archive_id").
+		Where("application_archive_documents.document_id = ? AND application_archives.rehydrated_at IS NULL", documentID).
+		Order("application_archives.archived_at DESC").
+		Limit(1).
+		Pluck("application_archive_documents.archive_path", &archivePath).Error
+	if err != nil {
+		return "", fmt.Errorf("failed to load document archive: %w", err)
+	}
+	return archivePath, nil
+}
@@ -6,7 +6,9 @@ import (
 	document_repositories "town-planning-backend/documents/repositories"
 	"town-planning-backend/documents/services"
 	internal_services "town-planning-backend/internal/services"
+	"town-planning-backend/middleware"
 	stand_repositories "town-planning-backend/stands/repositories"
+	user_repositories "town-planning-backend/users/repositories"
 
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
@@ -19,6 +21,7 @@ func DocumentRouterInit(app *fiber.App,
 	documentRepository document_repositories.DocumentRepository,
 	geminiService *internal_services.GeminiService,
 	documentService *services.DocumentService,
+	userRepository user_repositories.UserRepository,
 ) {
 	documentController := &document_controllers.DocumentController{
 		DB:              db,
@@ -30,8 +33,31 @@ func DocumentRouterInit(app *fiber.App,
 	}
 
 	// app.Post("/api/v1/documents/categories", documentController.CreateDocumentCategory)
-	app.Post("/api/v1/documents", documentController.CreateDocument)
+	app.Post("/api/v1/documents", middleware.Upload(), documentController.CreateDocument)
 	// app.Get("/api/v1/filtered/document-categories", documentController.FilteredDocumentCategories)
 	app.Get("/api/v1/documents-payment-plans/:id", documentController.GetDocumentsByPlanID)
 	app.Delete("/api/v1/documents/:id", documentController.DeleteDocument)
+
+	// Downloads, watermarked when asked or when the category requires it
+	app.Get("/api/v1/documents/:id/download", documentController.DownloadDocumentController)
+	app.Put("/api/v1/admin/document-categories/:id/watermark-policy", middleware.RequirePermission(userRepository, "user.manage"), documentController.SetWatermarkPolicyController)
+
+	// Classification suggestions for uploads made without a category
+	app.Get("/api/v1/documents/classification/stats", documentController.GetClassificationRuleStats)
+	app.Get("/api/v1/documents/:id/suggestions", documentController.GetClassificationSuggestions)
+	app.Post("/api/v1/documents/:id/suggestions/:suggestionId/resolve", documentController.ResolveClassificationSuggestion)
+
+	// Storage used against the upload quotas, and by department for capacity planning
+	app.Get("/api/v1/documents/storage/applications/:id", documentController.GetApplicationStorageUsageController)
+	app.Get("/api/v1/documents/storage/applicants/:id", documentController.GetApplicantStorageUsageController)
+	app.Get("/api/v1/documents/storage/departments", middleware.RequirePermission(userRepository, "report.generate"), documentController.GetDepartmentStorageReportController)
+
+	// Letter and email templates in each applicant language
+	app.Get("/api/v1/admin/templates", middleware.RequirePermission(userRepository, "user.manage"), documentController.ListTemplatesController)
+	app.Get("/api/v1/admin/templates/:name/preview", middleware.RequirePermission(userRepository, "user.manage"), documentController.PreviewTemplateController)
+
+	// How stored document files are named, council-wide and per category
+	app.Get("/api/v1/admin/file-naming-policies", middleware.RequirePermission(userRepository, "user.manage"), documentController.GetFileNamingPoliciesController)
+	app.Put("/api/v1/admin/file-naming-policies", middleware.RequirePermission(userRepository, "user.manage"), documentController.SaveFileNamingPolicyController)
+	app.Delete("/api/v1/admin/file-naming-policies", middleware.RequirePermission(userRepository, "user.manage"), documentController.DeleteFileNamingPolicyController)
 }
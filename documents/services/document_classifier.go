@@ -0,0 +1,142 @@
+package services
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// OtherCategoryCode is the catch-all category uploads fall back to when no category is given
+const OtherCategoryCode = "OTHER"
+
+// classifierSampleSize caps how much of a file is scanned for keywords
+const classifierSampleSize = 64 * 1024
+
+// maxClassificationSuggestions caps how many categories are suggested for one upload
+const maxClassificationSuggestions = 3
+
+// classificationRule maps a filename pattern or a set of content keywords to a category.
+// Rule IDs are stored with every suggestion, so keep them stable: rename a rule and its
+// acceptance history starts again from zero.
+type classificationRule struct {
+	ID           string
+	CategoryCode string
+	Filename     *regexp.Regexp
+	Keywords     []string
+	Confidence   float64
+}
+
+// Filename rules are more reliable than keyword rules, as uploaders usually name files after
+// what they are. Keyword rules need every keyword in the set to appear in the text.
+var classificationRules = []classificationRule{
+	{ID: "filename:title_deed", CategoryCode: "TITLE_DEED", Filename: regexp.MustCompile(`title[\s_-]*deed|deed[\s_-]*of[\s_-]*transfer`), Confidence: 0.85},
+	{ID: "filename:sale_agreement", CategoryCode: "SALE_AGREEMENT", Filename: regexp.MustCompile(`(sale|purchase)[\s_-]*agreement|agreement[\s_-]*of[\s_-]*sale`), Confidence: 0.85},
+	{ID: "filename:lease_agreement", CategoryCode: "LEASE_AGREEMENT", Filename: regexp.MustCompile(`lease`), Confidence: 0.75},
+	{ID: "filename:survey_diagram", CategoryCode: "SURVEY_DIAGRAM", Filename: regexp.MustCompile(`survey|diagram|\bsg[\s_-]*\d+`), Confidence: 0.7},
+	{ID: "filename:national_id", CategoryCode: "NATIONAL_ID", Filename: regexp.MustCompile(`national[\s_-]*id|\bid[\s_-]*(card|copy|doc)|passport`), Confidence: 0.8},
+	{ID: "filename:proof_of_residence", CategoryCode: "PROOF_OF_RESIDENCE", Filename: regexp.MustCompile(`proof[\s_-]*of[\s_-]*(residence|address)|utility[\s_-]*bill`), Confidence: 0.8},
+	{ID: "filename:site_plan", CategoryCode: "SITE_PLAN", Filename: regexp.MustCompile(`site[\s_-]*(plan|layout)`), Confidence: 0.8},
+	{ID: "filename:building_plan", CategoryCode: "BUILDING_PLAN", Filename: regexp.MustCompile(`building[\s_-]*plan|floor[\s_-]*plan|elevation|architectural`), Confidence: 0.75},
+	{ID: "filename:engineering_certificate", CategoryCode: "ENGINEERING_CERTIFICATE", Filename: regexp.MustCompile(`(structural|engineer(ing)?)[\s_-]*cert`), Confidence: 0.8},
+	{ID: "filename:ring_beam_certificate", CategoryCode: "RING_BEAM_CERTIFICATE", Filename: regexp.MustCompile(`ring[\s_-]*beam`), Confidence: 0.85},
+	{ID: "filename:geotechnical_report", CategoryCode: "GEOTECHNICAL_REPORT", Filename: regexp.MustCompile(`geo[\s_-]*tech|soil[\s_-]*(report|test)`), Confidence: 0.8},
+	{ID: "filename:eia_report", CategoryCode: "EIA_REPORT", Filename: regexp.MustCompile(`\beia\b|environmental[\s_-]*impact`), Confidence: 0.8},
+	{ID: "filename:inspection_report", CategoryCode: "INSPECTION_REPORT", Filename: regexp.MustCompile(`inspection`), Confidence: 0.7},
+	{ID: "filename:occupation_certificate", CategoryCode: "OCCUPATION_CERTIFICATE", Filename: regexp.MustCompile(`occupation|occupancy`), Confidence: 0.75},
+	{ID: "filename:compliance_certificate", CategoryCode: "COMPLIANCE_CERTIFICATE", Filename: regexp.MustCompile(`compliance`), Confidence: 0.7},
+	{ID: "filename:receipt", CategoryCode: "PROCESSED_RECEIPT", Filename: regexp.MustCompile(`receipt`), Confidence: 0.7},
+	{ID: "filename:quotation", CategoryCode: "PROCESSED_QUOTATION", Filename: regexp.MustCompile(`quot(e|ation)`), Confidence: 0.65},
+	{ID: "filename:tpd1_form", CategoryCode: "TPD1_FORM", Filename: regexp.MustCompile(`tpd[\s_-]*1`), Confidence: 0.85},
+	{ID: "filename:approval_letter", CategoryCode: "APPROVAL_LETTER", Filename: regexp.MustCompile(`approval[\s_-]*letter`), Confidence: 0.75},
+
+	{ID: "keywords:title_deed", CategoryCode: "TITLE_DEED", Keywords: []string{"deed of transfer", "registrar of deeds"}, Confidence: 0.6},
+	{ID: "keywords:sale_agreement", CategoryCode: "SALE_AGREEMENT", Keywords: []string{"agreement of sale", "purchaser", "seller"}, Confidence: 0.55},
+	{ID: "keywords:lease_agreement", CategoryCode: "LEASE_AGREEMENT", Keywords: []string{"lease", "lessor", "lessee"}, Confidence: 0.55},
+	{ID: "keywords:survey_diagram", CategoryCode: "SURVEY_DIAGRAM", Keywords: []string{"surveyor", "diagram", "beacon"}, Confidence: 0.5},
+	{ID: "keywords:proof_of_residence", CategoryCode: "PROOF_OF_RESIDENCE", Keywords: []string{"proof of residence"}, Confidence: 0.55},
+	{ID: "keywords:engineering_certificate", CategoryCode: "ENGINEERING_CERTIFICATE", Keywords: []string{"structural", "engineer", "certify"}, Confidence: 0.5},
+	{ID: "keywords:geotechnical_report", CategoryCode: "GEOTECHNICAL_REPORT", Keywords: []string{"geotechnical", "soil"}, Confidence: 0.55},
+	{ID: "keywords:eia_report", CategoryCode: "EIA_REPORT", Keywords: []string{"environmental impact assessment"}, Confidence: 0.6},
+	{ID: "keywords:inspection_report", CategoryCode: "INSPECTION_REPORT", Keywords: []string{"inspection", "inspector", "findings"}, Confidence: 0.5},
+}
+
+// ClassificationSuggestion is a category the classifier proposes for an uploaded file
+type ClassificationSuggestion struct {
+	CategoryCode string  `json:"category_code"`
+	Rule         string  `json:"rule"`
+	Confidence   float64 `json:"confidence"`
+}
+
+// ClassifyDocument suggests categories for a file from its name and, where the file holds
+// readable text, keywords in its first few kilobytes. Only the best rule per category is
+// kept, and suggestions are returned most confident first.
+func ClassifyDocument(fileName string, content []byte) []ClassificationSuggestion {
+	name := strings.ToLower(strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName)))
+	text := extractSampleText(content)
+
+	best := map[string]ClassificationSuggestion{}
+	for _, rule := range classificationRules {
+		matched := false
+		if rule.Filename != nil {
+			matched = rule.Filename.MatchString(name)
+		} else if text != "" && len(rule.Keywords) > 0 {
+			matched = true
+			for _, keyword := range rule.Keywords {
+				if !strings.Contains(text, keyword) {
+					matched = false
+					break
+				}
+			}
+		}
+		if !matched {
+			continue
+		}
+		if existing, ok := best[rule.CategoryCode]; !ok || rule.Confidence > existing.Confidence {
+			best[rule.CategoryCode] = ClassificationSuggestion{
+				CategoryCode: rule.CategoryCode,
+				Rule:         rule.ID,
+				Confidence:   rule.Confidence,
+			}
+		}
+	}
+
+	suggestions := make([]ClassificationSuggestion, 0, len(best))
+	for _, suggestion := range best {
+		suggestions = append(suggestions, suggestion)
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Confidence != suggestions[j].Confidence {
+			return suggestions[i].Confidence > suggestions[j].Confidence
+		}
+		return suggestions[i].CategoryCode < suggestions[j].CategoryCode
+	})
+	if len(suggestions) > maxClassificationSuggestions {
+		suggestions = suggestions[:maxClassificationSuggestions]
+	}
+	return suggestions
+}
+
+// extractSampleText pulls readable words out of the start of a file. This is deliberately crude:
+// it catches plain text and uncompressed PDF text without needing a parser for every format.
+func extractSampleText(content []byte) string {
+	if len(content) > classifierSampleSize {
+		content = content[:classifierSampleSize]
+	}
+
+	var b strings.Builder
+	run := 0
+	for _, c := range content {
+		printable := c >= 0x20 && c < 0x7f
+		if printable {
+			b.WriteByte(c)
+			run++
+			continue
+		}
+		if run > 0 {
+			b.WriteByte(' ')
+		}
+		run = 0
+	}
+	return strings.Join(strings.Fields(strings.ToLower(b.String())), " ")
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -35,11 +36,13 @@ type DocumentService struct {
 	Validator    *validators.DocumentValidator
 	DocumentRepo repositories.DocumentRepository
 	FileStorage  utils.FileStorage
+	Watermarker  *WatermarkService
 }
 
 type CreateDocumentResponse struct {
-	ID       uuid.UUID        `json:"id"`
-	Document *models.Document `json:"document"`
+	ID          uuid.UUID                                 `json:"id"`
+	Document    *models.Document                          `json:"document"`
+	Suggestions []models.DocumentClassificationSuggestion `json:"suggestions,omitempty"`
 }
 
 func NewDocumentService(repo repositories.DocumentRepository, fileStorage utils.FileStorage) *DocumentService {
@@ -47,6 +50,7 @@ func NewDocumentService(repo repositories.DocumentRepository, fileStorage utils.
 		Validator:    validators.NewDocumentValidator(),
 		DocumentRepo: repo,
 		FileStorage:  fileStorage,
+		Watermarker:  NewWatermarkService(LoadWatermarkConfig()),
 	}
 }
 
@@ -62,6 +66,12 @@ func (s *DocumentService) UnifiedCreateDocument(
 		zap.String("category_code", request.CategoryCode),
 		zap.Any("applicant_id", request.ApplicantID))
 
+	// Uploads without a usable category are filed as OTHER and classified after saving
+	classify := strings.TrimSpace(request.CategoryCode) == "" || strings.EqualFold(request.CategoryCode, OtherCategoryCode)
+	if classify {
+		request.CategoryCode = OtherCategoryCode
+	}
+
 	// Validate request
 	if err := s.Validator.ValidateCreateDocumentRequest(request); err != nil {
 		return nil, fmt.Errorf("validation error: %w", err)
@@ -82,20 +92,47 @@ func (s *DocumentService) UnifiedCreateDocument(
 		}
 	}
 
+	if fileHeader == nil && fileContent == nil {
+		return nil, fmt.Errorf("no file content provided")
+	}
+
+	// Work out the version first, since it is part of the file name
+	versionInfo, previousVersion, err := s.prepareVersioning(tx, request, category.ID)
+	if err != nil {
+		return nil, fmt.Errorf("versioning preparation failed: %w", err)
+	}
+
+	naming, err := s.fileNaming(tx, request, category, applicant, versionInfo.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	// Images lose their EXIF and other metadata before they are stored
+	scrubbed, err := s.scrubImageMetadata(request, fileContent, fileHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	// Uploads must fit within the application's and applicant's storage quotas
+	if err := s.checkStorageQuota(tx, request, fileHeader, scrubbed); err != nil {
+		return nil, err
+	}
+
 	// Handle file upload
 	var filePath, fileName string
 	var fileSize int64
 
-	if fileHeader != nil {
-		filePath, fileName, fileSize, err = s.saveMultipartFile(fileHeader, request, applicant)
-	} else if fileContent != nil {
+	if scrubbed != nil {
+		fileSize = int64(len(scrubbed.Data))
+		filePath, fileName, _, err = s.saveFileStream(bytes.NewReader(scrubbed.Data), uploadedFileName(request, fileHeader), fileSize, request, applicant, naming)
+	} else if fileHeader != nil {
+		filePath, fileName, fileSize, err = s.saveMultipartFile(fileHeader, request, applicant, naming)
+	} else {
 		if len(fileContent) == 0 {
 			config.Logger.Warn("File content is empty but proceeding", zap.String("filename", request.FileName))
 			// Don't return error for empty files, just log warning
 		}
-		filePath, fileName, fileSize, err = s.saveByteFile(fileContent, request, applicant)
-	} else {
-		return nil, fmt.Errorf("no file content provided")
+		filePath, fileName, fileSize, err = s.saveByteFile(fileContent, request, applicant, naming)
 	}
 
 	if err != nil {
@@ -119,11 +156,15 @@ func (s *DocumentService) UnifiedCreateDocument(
 		zap.String("name", fileName),
 		zap.Int64("size_bytes", fileSize))
 
-	// Handle versioning
-	versionInfo, err := s.prepareVersioning(tx, request, category.ID)
-	if err != nil {
-		s.cleanupFile(filePath)
-		return nil, fmt.Errorf("versioning preparation failed: %w", err)
+	// Archive the version this file replaces
+	if previousVersion != nil {
+		if err := s.archiveDocumentVersion(tx, previousVersion); err != nil {
+			config.Logger.Error("Failed to archive previous version",
+				zap.Error(err),
+				zap.String("documentID", previousVersion.ID.String()))
+			s.cleanupFile(filePath)
+			return nil, fmt.Errorf("failed to archive previous version: %w", err)
+		}
 	}
 
 	// Create document record with computed file size
@@ -133,6 +174,12 @@ func (s *DocumentService) UnifiedCreateDocument(
 		return nil, err
 	}
 
+	if scrubbed != nil {
+		scrubbedAt := time.Now()
+		document.MimeType = scrubbed.MimeType
+		document.MetadataScrubbedAt = &scrubbedAt
+	}
+
 	// Verify document has valid file size before saving (allow zero but not negative)
 	if document.FileSize.LessThan(decimal.NewFromInt(0)) {
 		s.cleanupFile(filePath)
@@ -156,17 +203,84 @@ func (s *DocumentService) UnifiedCreateDocument(
 		// Don't return error here as the document was created successfully
 	}
 
+	var suggestions []models.DocumentClassificationSuggestion
+	if classify {
+		suggestions, err = s.suggestCategories(tx, createdDocument, request, fileContent, fileHeader)
+		if err != nil {
+			s.cleanupFile(filePath)
+			return nil, err
+		}
+	}
+
 	config.Logger.Info("Document created successfully",
 		zap.String("document_id", createdDocument.ID.String()),
 		zap.String("file_path", filePath),
 		zap.Int64("file_size", fileSize))
 
 	return &CreateDocumentResponse{
-		ID:       createdDocument.ID,
-		Document: createdDocument,
+		ID:          createdDocument.ID,
+		Document:    createdDocument,
+		Suggestions: suggestions,
 	}, nil
 }
 
+// suggestCategories runs the classifier over an uncategorised upload and stores its suggestions
+// so that acceptance can be tracked per rule. Suggested categories that do not exist are skipped.
+func (s *DocumentService) suggestCategories(
+	tx *gorm.DB,
+	document *models.Document,
+	request *documents_requests.CreateDocumentRequest,
+	fileContent []byte,
+	fileHeader *multipart.FileHeader,
+) ([]models.DocumentClassificationSuggestion, error) {
+	originalName := request.FileName
+	sample := fileContent
+	if fileHeader != nil {
+		originalName = fileHeader.Filename
+		if src, err := fileHeader.Open(); err == nil {
+			sample, _ = io.ReadAll(io.LimitReader(src, classifierSampleSize))
+			src.Close()
+		}
+	}
+
+	var suggestions []models.DocumentClassificationSuggestion
+	var categories []*models.DocumentCategory
+	for _, match := range ClassifyDocument(originalName, sample) {
+		category, err := s.DocumentRepo.GetCategoryByCode(tx, match.CategoryCode)
+		if err != nil {
+			config.Logger.Warn("Suggested document category not found, skipping",
+				zap.String("category_code", match.CategoryCode),
+				zap.String("rule", match.Rule))
+			continue
+		}
+		suggestions = append(suggestions, models.DocumentClassificationSuggestion{
+			ID:                  uuid.New(),
+			DocumentID:          document.ID,
+			SuggestedCategoryID: category.ID,
+			Rule:                match.Rule,
+			Confidence:          decimal.NewFromFloat(match.Confidence),
+			Status:              models.SuggestionPending,
+			CreatedBy:           request.CreatedBy,
+		})
+		categories = append(categories, category)
+	}
+
+	if err := s.DocumentRepo.CreateClassificationSuggestions(tx, suggestions); err != nil {
+		return nil, err
+	}
+
+	// Attach categories after saving so GORM does not try to upsert them
+	for i := range suggestions {
+		suggestions[i].SuggestedCategory = categories[i]
+	}
+
+	config.Logger.Info("Document classification suggestions recorded",
+		zap.String("document_id", document.ID.String()),
+		zap.Int("count", len(suggestions)))
+
+	return suggestions, nil
+}
+
 // Create entity-document relationships based on request
 func (s *DocumentService) createEntityDocumentRelationships(
 	tx *gorm.DB,
@@ -309,11 +423,59 @@ func (s *DocumentService) CreateCouncilApplicantDocuments(
 	return createdDocuments, nil
 }
 
+// scrubImageMetadata re-encodes JPEG, PNG and GIF uploads without their metadata so that photos
+// taken on staff phones do not give away where they were taken. It returns nil for other files,
+// including image formats that cannot be re-encoded, which are stored as uploaded.
+func (s *DocumentService) scrubImageMetadata(
+	request *documents_requests.CreateDocumentRequest,
+	fileContent []byte,
+	fileHeader *multipart.FileHeader,
+) (*utils.ScrubbedImage, error) {
+	originalName := uploadedFileName(request, fileHeader)
+	fileExt := strings.ToLower(filepath.Ext(originalName))
+	documentType, err := s.Validator.GetDocumentType(fileExt)
+	if err != nil || documentType != models.ImageType {
+		return nil, nil
+	}
+	if !utils.CanScrubImage(fileExt) {
+		config.Logger.Warn("Image stored with its metadata; format cannot be re-encoded",
+			zap.String("filename", originalName))
+		return nil, nil
+	}
+
+	data := fileContent
+	if fileHeader != nil {
+		src, err := fileHeader.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open file: %w", err)
+		}
+		defer src.Close()
+		if data, err = io.ReadAll(src); err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+	}
+
+	scrubbed, err := utils.ScrubImageMetadata(data, fileExt)
+	if err != nil {
+		return nil, fmt.Errorf("image could not be processed: %w", err)
+	}
+	return scrubbed, nil
+}
+
+// uploadedFileName is the name the file was uploaded with
+func uploadedFileName(request *documents_requests.CreateDocumentRequest, fileHeader *multipart.FileHeader) string {
+	if fileHeader != nil {
+		return fileHeader.Filename
+	}
+	return request.FileName
+}
+
 // File handling methods
 func (s *DocumentService) saveMultipartFile(
 	fileHeader *multipart.FileHeader,
 	request *documents_requests.CreateDocumentRequest,
 	applicant *models.Applicant,
+	naming *fileNaming,
 ) (string, string, int64, error) {
 
 	src, err := fileHeader.Open()
@@ -322,13 +484,14 @@ func (s *DocumentService) saveMultipartFile(
 	}
 	defer src.Close()
 
-	return s.saveFileStream(src, fileHeader.Filename, fileHeader.Size, request, applicant)
+	return s.saveFileStream(src, fileHeader.Filename, fileHeader.Size, request, applicant, naming)
 }
 
 func (s *DocumentService) saveByteFile(
 	fileContent []byte,
 	request *documents_requests.CreateDocumentRequest,
 	applicant *models.Applicant,
+	naming *fileNaming,
 ) (string, string, int64, error) {
 
 	fileSize := int64(len(fileContent))
@@ -339,7 +502,7 @@ func (s *DocumentService) saveByteFile(
 
 	reader := bytes.NewReader(fileContent)
 
-	filePath, fileName, _, err := s.saveFileStream(reader, request.FileName, fileSize, request, applicant)
+	filePath, fileName, _, err := s.saveFileStream(reader, request.FileName, fileSize, request, applicant, naming)
 	if err != nil {
 		return "", "", 0, err
 	}
@@ -353,15 +516,21 @@ func (s *DocumentService) saveFileStream(
 	fileSize int64,
 	request *documents_requests.CreateDocumentRequest,
 	applicant *models.Applicant,
+	naming *fileNaming,
 ) (string, string, int64, error) {
 
-	folderPath, fileName := s.generateOrganizedFileStructure(request, applicant, originalName)
-	fullPath := filepath.Join(folderPath, fileName)
+	folderPath, fileName := s.generateOrganizedFileStructure(request, applicant, originalName, naming)
 
 	if err := s.ensureDirectoryExists(folderPath); err != nil {
 		return "", "", 0, fmt.Errorf("failed to create directory: %w", err)
 	}
 
+	fileName, err := s.availableFileName(folderPath, fileName)
+	if err != nil {
+		return "", "", 0, err
+	}
+	fullPath := filepath.Join(folderPath, fileName)
+
 	filePath, err := s.FileStorage.UploadFileFromReader(src, fullPath)
 	if err != nil {
 		return "", "", 0, fmt.Errorf("file storage failed: %w", err)
@@ -388,6 +557,7 @@ func (s *DocumentService) generateOrganizedFileStructure(
 	request *documents_requests.CreateDocumentRequest,
 	applicant *models.Applicant,
 	originalName string,
+	naming *fileNaming,
 ) (string, string) {
 
 	fileExt := strings.ToLower(filepath.Ext(originalName))
@@ -396,7 +566,7 @@ func (s *DocumentService) generateOrganizedFileStructure(
 	}
 
 	folderPath := s.generateFolderPath(applicant, request.CategoryCode)
-	fileName := s.generateDescriptiveFilename(request, applicant, fileExt)
+	fileName := s.generateDescriptiveFilename(naming, fileExt)
 
 	return folderPath, fileName
 }
@@ -408,38 +578,76 @@ func (s *DocumentService) generateFolderPath(applicant *models.Applicant, catego
 	return filepath.Join("general", categoryCode)
 }
 
-func (s *DocumentService) generateDescriptiveFilename(
+// fileNaming is the pattern and token values a stored file is named from
+type fileNaming struct {
+	Pattern string
+	Values  FileNameValues
+}
+
+// fileNaming resolves the naming policy for the document's category and gathers the values
+// its tokens expand to
+func (s *DocumentService) fileNaming(
+	tx *gorm.DB,
 	request *documents_requests.CreateDocumentRequest,
+	category *models.DocumentCategory,
 	applicant *models.Applicant,
-	fileExt string,
-) string {
+	version int,
+) (*fileNaming, error) {
 
-	timestamp := time.Now().Format("20060102_150405")
-	shortUUID := uuid.New().String()[:8]
+	pattern, err := s.DocumentRepo.GetFileNamingPattern(tx, category.ID)
+	if err != nil {
+		return nil, err
+	}
+	if pattern == "" {
+		pattern = DefaultFileNamingPattern
+	}
 
-	var applicantName string
+	values := FileNameValues{
+		Category: category.Code,
+		StoredAt: time.Now(),
+		Version:  version,
+		UID:      uuid.New().String()[:8],
+	}
 	if applicant != nil {
-		applicantName = s.sanitizeForFilename(applicant.GetFullName())
-	} else {
-		applicantName = "unknown"
+		values.Applicant = applicant.GetFullName()
 	}
+	if request.ApplicationID != nil && strings.Contains(pattern, "{plan_number}") {
+		planNumber, err := s.DocumentRepo.GetApplicationPlanNumber(tx, *request.ApplicationID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to load plan number for file name: %w", err)
+		}
+		values.PlanNumber = planNumber
+	}
+
+	return &fileNaming{Pattern: pattern, Values: values}, nil
+}
 
-	filename := fmt.Sprintf("%s_%s_%s_v1_%s%s",
-		request.CategoryCode,
-		applicantName,
-		timestamp,
-		shortUUID,
-		fileExt,
-	)
+func (s *DocumentService) generateDescriptiveFilename(naming *fileNaming, fileExt string) string {
+	return RenderFileName(naming.Pattern, naming.Values, fileExt)
+}
 
-	return filename
+// availableFileName numbers the file name when the folder already holds a file by that name, as
+// happens when a pattern leaves out {uid} or {time}
+func (s *DocumentService) availableFileName(folderPath, fileName string) (string, error) {
+	candidate := fileName
+	for attempt := 2; attempt <= 1000; attempt++ {
+		exists, err := s.FileStorage.FileExists(filepath.Join(folderPath, candidate))
+		if err != nil {
+			return "", fmt.Errorf("failed to check file name: %w", err)
+		}
+		if !exists {
+			return candidate, nil
+		}
+		candidate = CollisionFileName(fileName, attempt)
+	}
+	return "", fmt.Errorf("no free file name for %s", fileName)
 }
 
 func (s *DocumentService) prepareVersioning(
 	tx *gorm.DB,
 	request *documents_requests.CreateDocumentRequest,
 	categoryID uuid.UUID,
-) (*VersionInfo, error) {
+) (*VersionInfo, *models.Document, error) {
 
 	entityType, entityID := s.determineEntityType(request)
 
@@ -458,7 +666,7 @@ func (s *DocumentService) prepareVersioning(
 			zap.Error(err),
 			zap.String("entityType", entityType),
 			zap.Any("entityID", entityID))
-		return nil, fmt.Errorf("versioning check failed: %w", err)
+		return nil, nil, fmt.Errorf("versioning check failed: %w", err)
 	}
 
 	// No existing document found - this is the normal case for new entities
@@ -473,7 +681,7 @@ func (s *DocumentService) prepareVersioning(
 			IsCurrent:  true,
 			PreviousID: nil,
 			OriginalID: nil,
-		}, nil
+		}, nil, nil
 	}
 
 	// Existing document found - create new version
@@ -483,14 +691,6 @@ func (s *DocumentService) prepareVersioning(
 		zap.String("entityType", entityType),
 		zap.Any("entityID", entityID))
 
-	// Archive the existing version
-	if err := s.archiveDocumentVersion(tx, existingDoc); err != nil {
-		config.Logger.Error("Failed to archive previous version",
-			zap.Error(err),
-			zap.String("documentID", existingDoc.ID.String()))
-		return nil, fmt.Errorf("failed to archive previous version: %w", err)
-	}
-
 	// Determine original ID for the version chain
 	originalID := existingDoc.OriginalID
 	if originalID == nil {
@@ -508,7 +708,7 @@ func (s *DocumentService) prepareVersioning(
 		IsCurrent:  true,
 		PreviousID: &existingDoc.ID,
 		OriginalID: originalID,
-	}, nil
+	}, existingDoc, nil
 }
 
 func (s *DocumentService) determineEntityType(request *documents_requests.CreateDocumentRequest) (string, *uuid.UUID) {
@@ -575,6 +775,11 @@ func (s *DocumentService) createDocumentRecord(
 		PreviousID:       versionInfo.PreviousID,
 	}
 
+	// Audio keeps the uploaded type so players know the codec
+	if documentType == models.AudioType && utils.IsAudioMimeType(request.FileType) {
+		document.MimeType = utils.NormalizeAudioMimeType(request.FileType)
+	}
+
 	if versionInfo.OriginalID != nil {
 		document.OriginalID = versionInfo.OriginalID
 	} else {
@@ -606,21 +811,6 @@ func (s *DocumentService) cleanupFile(filePath string) {
 	}
 }
 
-func (s *DocumentService) sanitizeForFilename(name string) string {
-	sanitized := strings.ReplaceAll(name, " ", "_")
-	sanitized = strings.ReplaceAll(sanitized, "/", "-")
-	sanitized = strings.ReplaceAll(sanitized, "\\", "-")
-	sanitized = strings.ReplaceAll(sanitized, "..", "")
-	sanitized = strings.ReplaceAll(sanitized, ":", "-")
-	sanitized = strings.ReplaceAll(sanitized, "*", "-")
-	sanitized = strings.ReplaceAll(sanitized, "?", "-")
-	sanitized = strings.ReplaceAll(sanitized, "\"", "-")
-	sanitized = strings.ReplaceAll(sanitized, "<", "-")
-	sanitized = strings.ReplaceAll(sanitized, ">", "-")
-	sanitized = strings.ReplaceAll(sanitized, "|", "-")
-	return sanitized
-}
-
 func (s *DocumentService) calculateFileHash(fileName string, fileSize int64) string {
 	return fmt.Sprintf("%s-%d", fileName, fileSize)
 }
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultFileNamingPattern names files the way they were named before policies were configurable
+const DefaultFileNamingPattern = "{category}_{applicant}_{date}_{time}_v{version}_{uid}"
+
+// maxFileNameBaseLength keeps generated names well inside filesystem limits
+const maxFileNameBaseLength = 180
+
+// FileNamingTokens lists the tokens a naming pattern may use, with what each expands to
+var FileNamingTokens = map[string]string{
+	"category":    "document category code",
+	"applicant":   "applicant full name, or unknown",
+	"plan_number": "application plan number, or unknown",
+	"date":        "date stored, YYYYMMDD",
+	"time":        "time stored, HHMMSS",
+	"version":     "document version number",
+	"uid":         "eight random characters",
+}
+
+var (
+	fileNamingTokenPattern     = regexp.MustCompile(`\{([a-z_]*)\}`)
+	fileNamingLiteralPattern   = regexp.MustCompile(`^[A-Za-z0-9_.\-]*$`)
+	fileNameUnsafeCharacters   = regexp.MustCompile(`[^A-Za-z0-9_.\-]+`)
+	fileNameRepeatedSeparators = regexp.MustCompile(`([_\-.])[_\-.]+`)
+)
+
+// FileNameValues are the values a naming pattern's tokens expand to
+type FileNameValues struct {
+	Category   string
+	Applicant  string
+	PlanNumber string
+	StoredAt   time.Time
+	Version    int
+	UID        string
+}
+
+// ValidateFileNamingPattern rejects patterns with unknown tokens, unbalanced braces or literal
+// text that is not safe in a file name
+func ValidateFileNamingPattern(pattern string) error {
+	if strings.TrimSpace(pattern) == "" {
+		return fmt.Errorf("pattern is required")
+	}
+	if len(pattern) > 255 {
+		return fmt.Errorf("pattern cannot be longer than 255 characters")
+	}
+
+	tokens := fileNamingTokenPattern.FindAllStringSubmatch(pattern, -1)
+	if len(tokens) == 0 {
+		return fmt.Errorf("pattern must use at least one token")
+	}
+	for _, token := range tokens {
+		if _, ok := FileNamingTokens[token[1]]; !ok {
+			return fmt.Errorf("unknown token {%s}", token[1])
+		}
+	}
+
+	literal := fileNamingTokenPattern.ReplaceAllString(pattern, "")
+	if strings.ContainsAny(literal, "{}") {
+		return fmt.Errorf("pattern has unbalanced braces")
+	}
+	if !fileNamingLiteralPattern.MatchString(literal) {
+		return fmt.Errorf("pattern text may only contain letters, digits, '_', '-' and '.'")
+	}
+	return nil
+}
+
+// RenderFileName expands the pattern and appends the extension. Values are made safe for file
+// names, so applicant names and plan numbers with slashes or spaces cannot escape the folder.
+func RenderFileName(pattern string, values FileNameValues, fileExt string) string {
+	expanded := fileNamingTokenPattern.ReplaceAllStringFunc(pattern, func(token string) string {
+		switch strings.Trim(token, "{}") {
+		case "category":
+			return sanitizeFileNameValue(values.Category)
+		case "applicant":
+			return sanitizeFileNameValue(values.Applicant)
+		case "plan_number":
+			return sanitizeFileNameValue(values.PlanNumber)
+		case "date":
+			return values.StoredAt.Format("20060102")
+		case "time":
+			return values.StoredAt.Format("150405")
+		case "version":
+			return fmt.Sprintf("%d", values.Version)
+		case "uid":
+			return values.UID
+		}
+		return ""
+	})
+
+	expanded = fileNameRepeatedSeparators.ReplaceAllString(expanded, "$1")
+	expanded = strings.Trim(expanded, "_-.")
+	if len(expanded) > maxFileNameBaseLength {
+		expanded = strings.TrimRight(expanded[:maxFileNameBaseLength], "_-.")
+	}
+	if expanded == "" {
+		expanded = "document"
+	}
+	return expanded + fileExt
+}
+
+// CollisionFileName numbers a file name that is already taken: name_2.pdf, name_3.pdf, ...
+func CollisionFileName(fileName string, attempt int) string {
+	dot := strings.LastIndex(fileName, ".")
+	if dot <= 0 {
+		return fmt.Sprintf("%s_%d", fileName, attempt)
+	}
+	return fmt.Sprintf("%s_%d%s", fileName[:dot], attempt, fileName[dot:])
+}
+
+func sanitizeFileNameValue(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "unknown"
+	}
+	value = fileNameUnsafeCharacters.ReplaceAllString(value, "_")
+	value = strings.Trim(strings.ReplaceAll(value, "..", "_"), "_")
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
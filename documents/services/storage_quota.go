@@ -0,0 +1,98 @@
+package services
+
+import (
+	"fmt"
+	"mime/multipart"
+	documents_requests "town-planning-backend/documents/requests"
+	"town-planning-backend/settings"
+	"town-planning-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+// StorageQuotas caps the space uploads may take on the uploads volume
+type StorageQuotas struct {
+	ApplicationBytes int64
+	ApplicantBytes   int64
+}
+
+// LoadStorageQuotas reads the storage quotas from the uploads settings, which administrators
+// can change while the server runs
+func LoadStorageQuotas() StorageQuotas {
+	return StorageQuotas{
+		ApplicationBytes: int64(settings.Int(settings.ApplicationStorageQuotaMB)) * 1024 * 1024,
+		ApplicantBytes:   int64(settings.Int(settings.ApplicantStorageQuotaMB)) * 1024 * 1024,
+	}
+}
+
+// StorageQuotaError is returned when an upload would take an application or applicant over
+// its quota. Its message is meant to be shown to the uploader as is.
+type StorageQuotaError struct {
+	Scope         string // "application" or "applicant"
+	UsedBytes     int64
+	IncomingBytes int64
+	QuotaBytes    int64
+}
+
+func (e *StorageQuotaError) Error() string {
+	return fmt.Sprintf("This file (%s) would take the %s's documents to %s, over its %s storage limit. "+
+		"Please compress the file before uploading it again, for example by saving scanned PDFs at a "+
+		"lower resolution or in black and white, or by reducing the size of photos.",
+		FormatStorageSize(e.IncomingBytes), e.Scope,
+		FormatStorageSize(e.UsedBytes+e.IncomingBytes), FormatStorageSize(e.QuotaBytes))
+}
+
+// FormatStorageSize renders a byte count as "12.3 MB"
+func FormatStorageSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+// checkStorageQuota refuses an upload that would take its application or applicant over quota.
+// Files the council generates itself, such as permits and quotations, arrive as bytes rather
+// than uploads and are never refused; they still count towards usage.
+func (s *DocumentService) checkStorageQuota(
+	tx *gorm.DB,
+	request *documents_requests.CreateDocumentRequest,
+	fileHeader *multipart.FileHeader,
+	scrubbed *utils.ScrubbedImage,
+) error {
+	if fileHeader == nil {
+		return nil
+	}
+	incoming := fileHeader.Size
+	if scrubbed != nil {
+		incoming = int64(len(scrubbed.Data))
+	}
+
+	quotas := LoadStorageQuotas()
+	if request.ApplicationID != nil && quotas.ApplicationBytes > 0 {
+		usage, err := s.DocumentRepo.GetApplicationStorageUsage(tx, *request.ApplicationID)
+		if err != nil {
+			return err
+		}
+		if usage.UsedBytes+incoming > quotas.ApplicationBytes {
+			return &StorageQuotaError{Scope: "application", UsedBytes: usage.UsedBytes, IncomingBytes: incoming, QuotaBytes: quotas.ApplicationBytes}
+		}
+	}
+
+	if request.ApplicantID != nil && quotas.ApplicantBytes > 0 {
+		usage, err := s.DocumentRepo.GetApplicantStorageUsage(tx, *request.ApplicantID)
+		if err != nil {
+			return err
+		}
+		if usage.UsedBytes+incoming > quotas.ApplicantBytes {
+			return &StorageQuotaError{Scope: "applicant", UsedBytes: usage.UsedBytes, IncomingBytes: incoming, QuotaBytes: quotas.ApplicantBytes}
+		}
+	}
+
+	return nil
+}
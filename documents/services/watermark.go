@@ -0,0 +1,166 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"town-planning-backend/config"
+
+	"go.uber.org/zap"
+)
+
+const defaultWatermarkTimeoutSeconds = 30
+
+// ErrWatermarkUnavailable is returned when a watermark is needed but no watermarking service is
+// configured
+var ErrWatermarkUnavailable = errors.New("watermarking service is not configured")
+
+// ErrWatermarkUnsupported is returned for file types the watermarking service cannot mark
+var ErrWatermarkUnsupported = errors.New("only PDF and image files can be watermarked")
+
+// watermarkableTypes are the MIME types the watermarking service accepts
+var watermarkableTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+}
+
+// WatermarkConfig locates the watermarking service
+type WatermarkConfig struct {
+	BaseURL string
+	APIKey  string
+	Timeout time.Duration
+}
+
+// LoadWatermarkConfig reads the watermarking service settings. Without WATERMARK_SERVICE_URL
+// downloads that must be watermarked are refused.
+//
+//	WATERMARK_SERVICE_URL=https://watermark.example   service base URL
+//	WATERMARK_SERVICE_API_KEY=...                      bearer token, when the service needs one
+//	WATERMARK_SERVICE_TIMEOUT_SECONDS=30
+func LoadWatermarkConfig() WatermarkConfig {
+	cfg := WatermarkConfig{
+		BaseURL: strings.TrimRight(os.Getenv("WATERMARK_SERVICE_URL"), "/"),
+		APIKey:  os.Getenv("WATERMARK_SERVICE_API_KEY"),
+		Timeout: defaultWatermarkTimeoutSeconds * time.Second,
+	}
+
+	if raw := os.Getenv("WATERMARK_SERVICE_TIMEOUT_SECONDS"); raw != "" {
+		seconds, err := strconv.Atoi(raw)
+		if err != nil || seconds <= 0 {
+			config.Logger.Warn("Invalid WATERMARK_SERVICE_TIMEOUT_SECONDS, using default",
+				zap.String("value", raw),
+				zap.Int("default", defaultWatermarkTimeoutSeconds))
+		} else {
+			cfg.Timeout = time.Duration(seconds) * time.Second
+		}
+	}
+
+	return cfg
+}
+
+// Watermark is the text stamped on a distributed copy
+type Watermark struct {
+	DownloadedBy      string
+	DownloadedAt      time.Time
+	ApplicationNumber string // Plan number of the application the document belongs to, if any
+}
+
+// Text is the watermark as the service prints it, one item per line
+func (w Watermark) Text() string {
+	lines := []string{
+		"Downloaded by " + w.DownloadedBy,
+		w.DownloadedAt.Format("02 Jan 2006 15:04"),
+	}
+	if w.ApplicationNumber != "" {
+		lines = append(lines, "Application "+w.ApplicationNumber)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// WatermarkService stamps documents through the external watermarking service
+type WatermarkService struct {
+	config     WatermarkConfig
+	httpClient *http.Client
+}
+
+func NewWatermarkService(cfg WatermarkConfig) *WatermarkService {
+	return &WatermarkService{
+		config:     cfg,
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// Enabled reports whether a watermarking service is configured
+func (s *WatermarkService) Enabled() bool {
+	return s != nil && s.config.BaseURL != ""
+}
+
+// CanWatermark reports whether files of this MIME type can be watermarked
+func CanWatermark(mimeType string) bool {
+	return watermarkableTypes[strings.ToLower(strings.TrimSpace(mimeType))]
+}
+
+// Apply sends the file to the watermarking service and returns the marked copy, in the same
+// format as the original
+func (s *WatermarkService) Apply(ctx context.Context, data []byte, fileName string, mimeType string, watermark Watermark) ([]byte, error) {
+	if !CanWatermark(mimeType) {
+		return nil, ErrWatermarkUnsupported
+	}
+	if !s.Enabled() {
+		return nil, ErrWatermarkUnavailable
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.WriteField("text", watermark.Text()); err != nil {
+		return nil, fmt.Errorf("failed to build watermark request: %w", err)
+	}
+	part, err := writer.CreateFormFile("file", fileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build watermark request: %w", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to build watermark request: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to build watermark request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.BaseURL+"/watermark", &body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build watermark request: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Accept", mimeType)
+	if s.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.config.APIKey)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("watermarking service unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("watermarking service returned status %d", resp.StatusCode)
+	}
+
+	marked, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read watermarked file: %w", err)
+	}
+	if len(marked) == 0 {
+		return nil, errors.New("watermarking service returned an empty file")
+	}
+	return marked, nil
+}
@@ -8,6 +8,7 @@ import (
 	"town-planning-backend/db/models"
 
 	documents_requests "town-planning-backend/documents/requests"
+	"town-planning-backend/utils"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
@@ -108,6 +109,10 @@ func (v *DocumentValidator) validateFileType(fileType string) error {
 	}
 
 	cleanFileType := strings.TrimSpace(strings.ToLower(fileType))
+	// Voice notes arrive with codec parameters, e.g. audio/webm;codecs=opus
+	if utils.IsAudioMimeType(cleanFileType) {
+		return nil
+	}
 	if !allowedMimeTypes[cleanFileType] {
 		return fmt.Errorf("invalid file type: %s", fileType)
 	}
@@ -327,6 +332,8 @@ func (v *DocumentValidator) GetDocumentType(ext string) (models.DocumentType, er
 	case ".zip", ".rar", ".7z":
 		// For survey plans and other compressed documents
 		return models.SurveyPlanType, nil
+	case ".webm", ".ogg", ".opus", ".mp3", ".m4a", ".wav":
+		return models.AudioType, nil
 	default:
 		return "", fmt.Errorf("unrecognized file extension: %s", ext)
 	}
@@ -346,6 +353,7 @@ func (v *DocumentValidator) ValidateDocumentType(docType string) error {
 		string(models.EngineeringCertificate): true,
 		string(models.BuildingPlanType):       true,
 		string(models.SitePlanType):           true,
+		string(models.AudioType):              true,
 	}
 
 	if !validTypes[docType] {
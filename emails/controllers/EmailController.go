@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"town-planning-backend/config"
+	"town-planning-backend/emails/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type EmailController struct {
+	EmailSvc *services.EmailService
+
+	// Shared secret the provider webhooks are configured with as ?token=. Webhooks are refused
+	// while it is empty.
+	WebhookToken string
+}
+
+// TrackOpenController serves the open tracking pixel. The pixel is served even when the open
+// can't be recorded so the reader never sees a broken image.
+func (ec *EmailController) TrackOpenController(c *fiber.Ctx) error {
+	if emailLogID, err := uuid.Parse(c.Params("id")); err == nil {
+		if err := ec.EmailSvc.RecordOpen(emailLogID); err != nil {
+			config.Logger.Warn("Failed to record email open",
+				zap.String("emailLogID", emailLogID.String()),
+				zap.Error(err))
+		}
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store, no-cache, must-revalidate")
+	c.Set(fiber.HeaderContentType, "image/gif")
+	return c.Send(services.TrackingPixel)
+}
+
+// TrackClickController records a click on a tracked link and redirects to the link
+func (ec *EmailController) TrackClickController(c *fiber.Ctx) error {
+	emailLogID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid email ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	target, err := ec.EmailSvc.RecordClick(emailLogID, c.Query("url"), c.Query("sig"))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidTrackingLink) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"success": false,
+				"message": err.Error(),
+			})
+		}
+		// The reader still gets where they were going
+		config.Logger.Warn("Failed to record email click",
+			zap.String("emailLogID", emailLogID.String()),
+			zap.Error(err))
+	}
+
+	return c.Redirect(target, fiber.StatusFound)
+}
+
+// SendGridWebhookController receives SendGrid's event webhook
+func (ec *EmailController) SendGridWebhookController(c *fiber.Ctx) error {
+	if !ec.webhookAuthorized(c) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid webhook token",
+		})
+	}
+
+	recorded, err := ec.EmailSvc.HandleSendGridEvents(c.Body())
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid webhook payload",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Events recorded",
+		"data":    fiber.Map{"recorded": recorded},
+	})
+}
+
+// SESWebhookController receives SES notifications delivered by an SNS topic subscription
+func (ec *EmailController) SESWebhookController(c *fiber.Ctx) error {
+	if !ec.webhookAuthorized(c) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid webhook token",
+		})
+	}
+
+	if err := ec.EmailSvc.HandleSESNotification(c.UserContext(), c.Body()); err != nil {
+		config.Logger.Warn("Failed to handle SES notification", zap.Error(err))
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to handle notification",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Notification recorded",
+	})
+}
+
+func (ec *EmailController) webhookAuthorized(c *fiber.Ctx) bool {
+	if ec.WebhookToken == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(c.Query("token")), []byte(ec.WebhookToken)) == 1
+}
+
+// GetApplicationEmailsController lists the emails sent about an application and whether they
+// were delivered, bounced or opened
+func (ec *EmailController) GetApplicationEmailsController(c *fiber.Ctx) error {
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	emails, err := ec.EmailSvc.GetApplicationEmails(applicationID)
+	if err != nil {
+		config.Logger.Error("Failed to fetch application emails",
+			zap.Error(err),
+			zap.String("applicationID", applicationID.String()))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch emails",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Emails retrieved successfully",
+		"data":    emails,
+	})
+}
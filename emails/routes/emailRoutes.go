@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"town-planning-backend/emails/controllers"
+	"town-planning-backend/emails/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// EmailRouterInit registers the email endpoints. EMAIL_WEBHOOK_TOKEN is the secret the
+// SendGrid and SES webhooks are configured with, e.g. /emails/webhooks/ses?token=...
+func EmailRouterInit(app *fiber.App, emailService *services.EmailService, webhookToken string) {
+	emailController := &controllers.EmailController{
+		EmailSvc:     emailService,
+		WebhookToken: webhookToken,
+	}
+
+	// Opened from applicants' mailboxes and called by providers, so outside the staff routes
+	app.Get("/emails/track/:id/open.gif", emailController.TrackOpenController)
+	app.Get("/emails/track/:id/click", emailController.TrackClickController)
+	app.Post("/emails/webhooks/sendgrid", emailController.SendGridWebhookController)
+	app.Post("/emails/webhooks/ses", emailController.SESWebhookController)
+
+	// Delivery status of an application's emails
+	app.Get("/api/v1/applications/:id/emails", emailController.GetApplicationEmailsController)
+}
@@ -0,0 +1,309 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// TypeSendEmail is the task that delivers one queued email
+	TypeSendEmail = "email:send"
+
+	emailQueue              = "email"
+	emailSendRetries        = 4
+	defaultEmailConcurrency = 5
+)
+
+// Email is a notification to send. Applicant-facing notifications turn on open tracking and,
+// unless the links are sign-in links, click tracking.
+type Email struct {
+	To             string
+	Subject        string
+	Text           string
+	HTML           string // Optional; built from Text when tracking needs one
+	AttachmentPath string // Optional local file
+	ReplyTo        string // Optional, e.g. a chat thread's reply-by-email address
+	EmailType      string // e.g. "PERMIT_STATUS_CHANGE"
+	TemplateName   *string
+
+	ApplicationID *uuid.UUID
+	ApplicantID   *uuid.UUID
+	PaymentID     *uuid.UUID
+
+	TrackOpens  bool
+	TrackClicks bool
+
+	CreatedBy string // Defaults to "system"
+}
+
+// EmailService records every email in the email log and hands it to the provider through the
+// task queue, so requests never wait on the mail server
+type EmailService struct {
+	db          *gorm.DB
+	provider    Provider
+	queue       *asynq.Client // Nil sends straight away
+	from        string
+	baseURL     string // Public backend URL for tracking links
+	trackingKey []byte
+}
+
+// NewEmailService sends as EMAIL_FROM, or SMTP_FROM when it is not set
+func NewEmailService(db *gorm.DB, provider Provider, queue *asynq.Client, baseURL string, trackingKey string) *EmailService {
+	from := os.Getenv("EMAIL_FROM")
+	if from == "" {
+		from = os.Getenv("SMTP_FROM")
+	}
+	return &EmailService{
+		db:          db,
+		provider:    provider,
+		queue:       queue,
+		from:        from,
+		baseURL:     baseURL,
+		trackingKey: []byte(trackingKey),
+	}
+}
+
+var defaultService *EmailService
+
+// Init sets the service behind utils.SendEmail
+func Init(service *EmailService) {
+	defaultService = service
+}
+
+// Default returns the service set by Init, or nil before then
+func Default() *EmailService {
+	return defaultService
+}
+
+type sendEmailPayload struct {
+	EmailLogID uuid.UUID `json:"email_log_id"`
+}
+
+// Send logs the email as queued and enqueues its delivery. An error means the email will not
+// go out; failures after queueing are retried and end up on the log as FAILED.
+func (s *EmailService) Send(ctx context.Context, email Email) (*models.EmailLog, error) {
+	if email.To == "" {
+		return nil, errors.New("email has no recipient")
+	}
+	if s.from == "" {
+		return nil, errors.New("EMAIL_FROM environment variable not set")
+	}
+
+	if email.AttachmentPath != "" {
+		if _, err := os.Stat(email.AttachmentPath); err != nil {
+			// Don't fail the email just because an optional attachment isn't found
+			config.Logger.Warn("Attachment file not found for email",
+				zap.String("filepath", email.AttachmentPath),
+				zap.String("to_email", email.To),
+				zap.Error(err))
+			email.AttachmentPath = ""
+		}
+	}
+
+	createdBy := email.CreatedBy
+	if createdBy == "" {
+		createdBy = "system"
+	}
+	active := true
+	log := &models.EmailLog{
+		ID:             uuid.New(),
+		Recipient:      email.To,
+		Subject:        email.Subject,
+		Message:        email.Text,
+		Active:         &active,
+		AttachmentPath: email.AttachmentPath,
+		ApplicationID:  email.ApplicationID,
+		ApplicantID:    email.ApplicantID,
+		PaymentID:      email.PaymentID,
+		EmailType:      email.EmailType,
+		Status:         models.EmailStatusQueued,
+		TemplateName:   email.TemplateName,
+		Provider:       s.provider.Name(),
+		TrackOpens:     email.TrackOpens,
+		TrackClicks:    email.TrackClicks,
+		CreatedBy:      createdBy,
+	}
+	if email.ReplyTo != "" {
+		log.ReplyTo = &email.ReplyTo
+	}
+
+	html := email.HTML
+	if html == "" && (email.TrackOpens || email.TrackClicks) {
+		html = textToHTML(email.Subject, email.Text)
+	}
+	if html != "" {
+		html = s.addTracking(html, log.ID, email.TrackOpens, email.TrackClicks)
+		log.HTMLMessage = &html
+	}
+
+	if err := s.db.Create(log).Error; err != nil {
+		return nil, fmt.Errorf("failed to log email: %w", err)
+	}
+
+	if s.queue == nil {
+		return log, s.deliver(ctx, log.ID, true)
+	}
+
+	payload, err := json.Marshal(sendEmailPayload{EmailLogID: log.ID})
+	if err != nil {
+		return nil, err
+	}
+	task := asynq.NewTask(TypeSendEmail, payload,
+		asynq.Queue(emailQueue),
+		asynq.MaxRetry(emailSendRetries),
+		asynq.Timeout(2*time.Minute))
+	if _, err := s.queue.EnqueueContext(ctx, task); err != nil {
+		config.Logger.Warn("Failed to queue email, sending it straight away",
+			zap.String("emailLogID", log.ID.String()),
+			zap.String("to_email", email.To),
+			zap.Error(err))
+		return log, s.deliver(ctx, log.ID, true)
+	}
+
+	return log, nil
+}
+
+// HandleSendEmailTask is the queue worker for TypeSendEmail
+func (s *EmailService) HandleSendEmailTask(ctx context.Context, task *asynq.Task) error {
+	var payload sendEmailPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("invalid email task payload: %v: %w", err, asynq.SkipRetry)
+	}
+
+	retried, _ := asynq.GetRetryCount(ctx)
+	maxRetry, _ := asynq.GetMaxRetry(ctx)
+	return s.deliver(ctx, payload.EmailLogID, retried >= maxRetry)
+}
+
+// deliver sends a queued email. Emails already sent are skipped, so a task that runs twice
+// sends once. The email is marked FAILED only on the last attempt.
+func (s *EmailService) deliver(ctx context.Context, emailLogID uuid.UUID, lastAttempt bool) error {
+	var log models.EmailLog
+	if err := s.db.Where("id = ?", emailLogID).First(&log).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("email log %s not found: %w", emailLogID, asynq.SkipRetry)
+		}
+		return fmt.Errorf("failed to load email log: %w", err)
+	}
+	if log.Status != models.EmailStatusQueued {
+		return nil
+	}
+
+	message := &Message{
+		ID:      log.ID,
+		From:    s.from,
+		To:      log.Recipient,
+		Subject: log.Subject,
+		Text:    log.Message,
+	}
+	if log.HTMLMessage != nil {
+		message.HTML = *log.HTMLMessage
+	}
+	if log.AttachmentPath != "" {
+		message.Attachments = []string{log.AttachmentPath}
+	}
+	if log.ReplyTo != nil {
+		message.ReplyTo = *log.ReplyTo
+	}
+
+	providerMessageID, sendErr := s.provider.Send(ctx, message)
+
+	updates := map[string]interface{}{
+		"attempts":   gorm.Expr("attempts + 1"),
+		"updated_at": time.Now(),
+	}
+	if sendErr != nil {
+		errMessage := sendErr.Error()
+		updates["error"] = &errMessage
+		if lastAttempt {
+			updates["status"] = models.EmailStatusFailed
+		}
+	} else {
+		updates["status"] = models.EmailStatusSent
+		updates["sent_at"] = time.Now()
+		updates["error"] = nil
+		if providerMessageID != "" {
+			updates["provider_message_id"] = &providerMessageID
+		}
+	}
+	if err := s.db.Model(&models.EmailLog{}).Where("id = ?", log.ID).Updates(updates).Error; err != nil {
+		config.Logger.Error("Failed to update email log",
+			zap.String("emailLogID", log.ID.String()),
+			zap.Error(err))
+	}
+
+	if sendErr != nil {
+		config.Logger.Error("Failed to send email",
+			zap.String("emailLogID", log.ID.String()),
+			zap.String("provider", s.provider.Name()),
+			zap.String("to_email", log.Recipient),
+			zap.String("subject", log.Subject),
+			zap.Bool("last_attempt", lastAttempt),
+			zap.Error(sendErr))
+		return sendErr
+	}
+
+	config.Logger.Info("Email sent successfully",
+		zap.String("emailLogID", log.ID.String()),
+		zap.String("provider", s.provider.Name()),
+		zap.String("to_email", log.Recipient),
+		zap.String("subject", log.Subject))
+	return nil
+}
+
+// StartEmailWorker runs the queue worker delivering emails. EMAIL_WORKER_CONCURRENCY sets how
+// many are sent at once (default 5).
+func StartEmailWorker(redisOpt asynq.RedisConnOpt, service *EmailService) (*asynq.Server, error) {
+	server := asynq.NewServer(redisOpt, asynq.Config{
+		Concurrency: emailWorkerConcurrency(),
+		Queues:      map[string]int{emailQueue: 1},
+	})
+
+	mux := asynq.NewServeMux()
+	mux.HandleFunc(TypeSendEmail, service.HandleSendEmailTask)
+	if err := server.Start(mux); err != nil {
+		return nil, fmt.Errorf("failed to start email worker: %w", err)
+	}
+	return server, nil
+}
+
+func emailWorkerConcurrency() int {
+	raw := os.Getenv("EMAIL_WORKER_CONCURRENCY")
+	if raw == "" {
+		return defaultEmailConcurrency
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value <= 0 {
+		config.Logger.Warn("Invalid setting, using default",
+			zap.String("variable", "EMAIL_WORKER_CONCURRENCY"),
+			zap.String("value", raw),
+			zap.Int("default", defaultEmailConcurrency))
+		return defaultEmailConcurrency
+	}
+	return value
+}
+
+// GetApplicationEmails lists the emails sent about an application, newest first, with their
+// delivery status
+func (s *EmailService) GetApplicationEmails(applicationID uuid.UUID) ([]models.EmailLog, error) {
+	var logs []models.EmailLog
+	if err := s.db.
+		Where("application_id = ?", applicationID).
+		Order("created_at DESC").
+		Find(&logs).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch application emails: %w", err)
+	}
+	return logs, nil
+}
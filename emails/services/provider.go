@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/gomail.v2"
+)
+
+// Message is one email ready to hand to a provider
+type Message struct {
+	ID          uuid.UUID // EmailLog ID, passed to the provider so webhook events can be matched back
+	From        string
+	To          string
+	ReplyTo     string // Empty to reply to From
+	Subject     string
+	Text        string
+	HTML        string   // Empty for plain-text only emails
+	Attachments []string // Paths of local files
+}
+
+// Provider delivers emails. Send returns the provider's ID for the message, which bounce and
+// complaint webhooks refer to.
+type Provider interface {
+	Name() string
+	Send(ctx context.Context, message *Message) (string, error)
+}
+
+// NewProviderFromEnv builds the provider chosen by EMAIL_PROVIDER:
+//
+//	EMAIL_PROVIDER=smtp       SMTP_HOST, SMTP_PORT, SMTP_USER, SMTP_PASSWORD (the default)
+//	EMAIL_PROVIDER=sendgrid   SENDGRID_API_KEY
+//	EMAIL_PROVIDER=ses        AWS_REGION, AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, optionally
+//	                          AWS_SESSION_TOKEN and SES_CONFIGURATION_SET
+func NewProviderFromEnv() (Provider, error) {
+	switch strings.ToLower(os.Getenv("EMAIL_PROVIDER")) {
+	case "", "smtp":
+		return NewSMTPProviderFromEnv(), nil
+	case "sendgrid":
+		return NewSendGridProvider(os.Getenv("SENDGRID_API_KEY"))
+	case "ses":
+		return NewSESProvider(
+			os.Getenv("AWS_REGION"),
+			os.Getenv("AWS_ACCESS_KEY_ID"),
+			os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			os.Getenv("AWS_SESSION_TOKEN"),
+			os.Getenv("SES_CONFIGURATION_SET"),
+		)
+	default:
+		return nil, fmt.Errorf("unknown EMAIL_PROVIDER %q, expected smtp, sendgrid or ses", os.Getenv("EMAIL_PROVIDER"))
+	}
+}
+
+// mimeMessage builds the message as MIME, for SMTP and for providers that accept raw emails
+func mimeMessage(message *Message) *gomail.Message {
+	m := gomail.NewMessage()
+	m.SetHeader("From", message.From)
+	m.SetHeader("To", message.To)
+	if message.ReplyTo != "" {
+		m.SetHeader("Reply-To", message.ReplyTo)
+	}
+	m.SetHeader("Subject", message.Subject)
+	m.SetHeader("Message-ID", messageIDHeader(message))
+	m.SetDateHeader("Date", time.Now())
+
+	m.SetBody("text/plain", message.Text)
+	if message.HTML != "" {
+		m.AddAlternative("text/html", message.HTML)
+	}
+	for _, path := range message.Attachments {
+		m.Attach(path)
+	}
+	return m
+}
+
+// messageIDHeader derives the Message-ID from the email log, on the sender's domain
+func messageIDHeader(message *Message) string {
+	domain := "localhost"
+	if address, err := mail.ParseAddress(message.From); err == nil {
+		if at := strings.LastIndex(address.Address, "@"); at >= 0 {
+			domain = address.Address[at+1:]
+		}
+	}
+	return fmt.Sprintf("<%s@%s>", message.ID, domain)
+}
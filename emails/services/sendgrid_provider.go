@@ -0,0 +1,136 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const sendGridSendURL = "https://api.sendgrid.com/v3/mail/send"
+
+// SendGridProvider sends through the SendGrid v3 API
+type SendGridProvider struct {
+	apiKey string
+	client *http.Client
+}
+
+func NewSendGridProvider(apiKey string) (*SendGridProvider, error) {
+	if apiKey == "" {
+		return nil, errors.New("SENDGRID_API_KEY is not set")
+	}
+	return &SendGridProvider{apiKey: apiKey, client: &http.Client{Timeout: 30 * time.Second}}, nil
+}
+
+func (p *SendGridProvider) Name() string {
+	return "sendgrid"
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridAttachment struct {
+	Content  string `json:"content"`
+	Filename string `json:"filename"`
+	Type     string `json:"type,omitempty"`
+}
+
+type sendGridToggle struct {
+	Enable bool `json:"enable"`
+}
+
+type sendGridPersonalization struct {
+	To         []sendGridAddress `json:"to"`
+	CustomArgs map[string]string `json:"custom_args"`
+}
+
+type sendGridRequest struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	ReplyTo          *sendGridAddress          `json:"reply_to,omitempty"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+	Attachments      []sendGridAttachment      `json:"attachments,omitempty"`
+	TrackingSettings struct {
+		ClickTracking sendGridToggle `json:"click_tracking"`
+		OpenTracking  sendGridToggle `json:"open_tracking"`
+	} `json:"tracking_settings"`
+}
+
+func (p *SendGridProvider) Send(ctx context.Context, message *Message) (string, error) {
+	from, err := mail.ParseAddress(message.From)
+	if err != nil {
+		return "", fmt.Errorf("invalid sender address %q: %w", message.From, err)
+	}
+
+	var request sendGridRequest
+	request.Personalizations = []sendGridPersonalization{{
+		To: []sendGridAddress{{Email: message.To}},
+		// Echoed back on every webhook event for this message
+		CustomArgs: map[string]string{"email_log_id": message.ID.String()},
+	}}
+	request.From = sendGridAddress{Email: from.Address, Name: from.Name}
+	if message.ReplyTo != "" {
+		request.ReplyTo = &sendGridAddress{Email: message.ReplyTo}
+	}
+	request.Subject = message.Subject
+	request.Content = []sendGridContent{{Type: "text/plain", Value: message.Text}}
+	if message.HTML != "" {
+		request.Content = append(request.Content, sendGridContent{Type: "text/html", Value: message.HTML})
+	}
+	// Opens and clicks are tracked by us, only on applicant-facing notifications
+	request.TrackingSettings.ClickTracking.Enable = false
+	request.TrackingSettings.OpenTracking.Enable = false
+
+	for _, path := range message.Attachments {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read attachment %s: %w", path, err)
+		}
+		request.Attachments = append(request.Attachments, sendGridAttachment{
+			Content:  base64.StdEncoding.EncodeToString(data),
+			Filename: filepath.Base(path),
+			Type:     mime.TypeByExtension(filepath.Ext(path)),
+		})
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode SendGrid request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sendGridSendURL, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to reach SendGrid: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return "", fmt.Errorf("SendGrid refused the email (%d): %s", resp.StatusCode, string(detail))
+	}
+	return resp.Header.Get("X-Message-Id"), nil
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SESProvider sends through the Amazon SES v2 API. Requests are signed with AWS Signature
+// Version 4 so the service needs no AWS SDK.
+type SESProvider struct {
+	region           string
+	accessKeyID      string
+	secretAccessKey  string
+	sessionToken     string
+	configurationSet string
+	client           *http.Client
+}
+
+func NewSESProvider(region, accessKeyID, secretAccessKey, sessionToken, configurationSet string) (*SESProvider, error) {
+	if region == "" || accessKeyID == "" || secretAccessKey == "" {
+		return nil, errors.New("AWS_REGION, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set for SES")
+	}
+	return &SESProvider{
+		region:           region,
+		accessKeyID:      accessKeyID,
+		secretAccessKey:  secretAccessKey,
+		sessionToken:     sessionToken,
+		configurationSet: configurationSet,
+		client:           &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (p *SESProvider) Name() string {
+	return "ses"
+}
+
+type sesSendRequest struct {
+	FromEmailAddress string `json:"FromEmailAddress"`
+	Destination      struct {
+		ToAddresses []string `json:"ToAddresses"`
+	} `json:"Destination"`
+	Content struct {
+		Raw struct {
+			Data string `json:"Data"`
+		} `json:"Raw"`
+	} `json:"Content"`
+	ConfigurationSetName string `json:"ConfigurationSetName,omitempty"`
+}
+
+func (p *SESProvider) Send(ctx context.Context, message *Message) (string, error) {
+	// Raw content carries the attachments and our Message-ID
+	var raw bytes.Buffer
+	if _, err := mimeMessage(message).WriteTo(&raw); err != nil {
+		return "", fmt.Errorf("failed to build email: %w", err)
+	}
+
+	var request sesSendRequest
+	request.FromEmailAddress = message.From
+	request.Destination.ToAddresses = []string{message.To}
+	request.Content.Raw.Data = base64.StdEncoding.EncodeToString(raw.Bytes())
+	request.ConfigurationSetName = p.configurationSet
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode SES request: %w", err)
+	}
+
+	host := fmt.Sprintf("email.%s.amazonaws.com", p.region)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://"+host+"/v2/email/outbound-emails", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	p.sign(req, host, body, time.Now().UTC())
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to reach SES: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("SES refused the email (%d): %s", resp.StatusCode, string(respBody))
+	}
+
+	var result struct {
+		MessageId string `json:"MessageId"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("failed to read SES response: %w", err)
+	}
+	return result.MessageId, nil
+}
+
+// sign adds the AWS Signature Version 4 headers for the SES service
+func (p *SESProvider) sign(req *http.Request, host string, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headerNames := []string{"content-type", "host", "x-amz-content-sha256", "x-amz-date"}
+	headerValues := map[string]string{
+		"content-type":         req.Header.Get("Content-Type"),
+		"host":                 host,
+		"x-amz-content-sha256": payloadHash,
+		"x-amz-date":           amzDate,
+	}
+	if p.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", p.sessionToken)
+		headerNames = append(headerNames, "x-amz-security-token")
+		headerValues["x-amz-security-token"] = p.sessionToken
+	}
+
+	var canonicalHeaders strings.Builder
+	for _, name := range headerNames {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headerValues[name]) + "\n")
+	}
+	signedHeaders := strings.Join(headerNames, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + p.region + "/ses/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+p.secretAccessKey), date)
+	key = hmacSHA256(key, p.region)
+	key = hmacSHA256(key, "ses")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		p.accessKeyID, scope, signedHeaders, signature))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"town-planning-backend/config"
+
+	"go.uber.org/zap"
+	"gopkg.in/gomail.v2"
+)
+
+// SMTPProvider sends through the council's mail server. SMTP reports no bounces, so emails sent
+// this way stay SENT.
+type SMTPProvider struct {
+	dialer *gomail.Dialer
+}
+
+// NewSMTPProviderFromEnv reads SMTP_HOST, SMTP_PORT, SMTP_USER and SMTP_PASSWORD
+func NewSMTPProviderFromEnv() *SMTPProvider {
+	mailPort := os.Getenv("SMTP_PORT")
+	port, err := strconv.Atoi(mailPort)
+	if err != nil {
+		config.Logger.Error("Invalid SMTP_PORT value, defaulting to port 25",
+			zap.String("provided_port", mailPort),
+			zap.Error(err),
+		)
+		port = 25 // Fallback to a default port if conversion fails
+	}
+
+	return &SMTPProvider{
+		dialer: gomail.NewDialer(os.Getenv("SMTP_HOST"), port, os.Getenv("SMTP_USER"), os.Getenv("SMTP_PASSWORD")),
+	}
+}
+
+func (p *SMTPProvider) Name() string {
+	return "smtp"
+}
+
+func (p *SMTPProvider) Send(ctx context.Context, message *Message) (string, error) {
+	if err := p.dialer.DialAndSend(mimeMessage(message)); err != nil {
+		return "", fmt.Errorf("failed to send email via SMTP: %w", err)
+	}
+	return strings.Trim(messageIDHeader(message), "<>"), nil
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"crypto/hmac"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	hrefPattern = regexp.MustCompile(`href="(https?://[^"]+)"`)
+	urlPattern  = regexp.MustCompile(`https?://[^\s<]+`)
+
+	// ErrInvalidTrackingLink is returned for click links whose signature does not match, so the
+	// redirect cannot be used to send people to other sites
+	ErrInvalidTrackingLink = errors.New("invalid tracking link")
+)
+
+// TrackingPixel is a transparent 1x1 GIF
+var TrackingPixel, _ = base64.StdEncoding.DecodeString("R0lGODlhAQABAIAAAAAAAP///yH5BAEAAAAALAAAAAABAAEAAAIBRAA7")
+
+// textToHTML renders a plain-text email as HTML, keeping line breaks and making links clickable
+func textToHTML(subject string, text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		escaped := html.EscapeString(line)
+		lines[i] = urlPattern.ReplaceAllStringFunc(escaped, func(link string) string {
+			// Punctuation ending a sentence is not part of the link
+			trimmed := strings.TrimRight(link, ".,;:!?)")
+			return fmt.Sprintf(`<a href="%s">%s</a>%s`, trimmed, trimmed, link[len(trimmed):])
+		})
+	}
+	return fmt.Sprintf(`<html>
+	<head>
+		<meta charset="utf-8">
+		<title>%s</title>
+	</head>
+	<body>
+		<p>%s</p>
+	</body>
+</html>`, html.EscapeString(subject), strings.Join(lines, "<br>\n"))
+}
+
+// addTracking points the email's links at the click tracker and adds the open pixel
+func (s *EmailService) addTracking(body string, emailLogID uuid.UUID, trackOpens bool, trackClicks bool) string {
+	if trackClicks {
+		body = hrefPattern.ReplaceAllStringFunc(body, func(match string) string {
+			target := html.UnescapeString(hrefPattern.FindStringSubmatch(match)[1])
+			return fmt.Sprintf(`href="%s"`, html.EscapeString(s.clickURL(emailLogID, target)))
+		})
+	}
+
+	if trackOpens {
+		pixel := fmt.Sprintf(`<img src="%s/emails/track/%s/open.gif" width="1" height="1" alt="" style="display:none">`,
+			s.baseURL, emailLogID)
+		if i := strings.LastIndex(strings.ToLower(body), "</body>"); i >= 0 {
+			body = body[:i] + pixel + body[i:]
+		} else {
+			body += pixel
+		}
+	}
+	return body
+}
+
+func (s *EmailService) clickURL(emailLogID uuid.UUID, target string) string {
+	return fmt.Sprintf("%s/emails/track/%s/click?url=%s&sig=%s",
+		s.baseURL, emailLogID, url.QueryEscape(target), s.linkSignature(emailLogID, target))
+}
+
+func (s *EmailService) linkSignature(emailLogID uuid.UUID, target string) string {
+	return base64.RawURLEncoding.EncodeToString(hmacSHA256(s.trackingKey, emailLogID.String()+"|"+target))
+}
+
+// RecordOpen counts an open of a tracked email. Unknown or untracked emails are ignored, since
+// the pixel must load either way.
+func (s *EmailService) RecordOpen(emailLogID uuid.UUID) error {
+	now := time.Now()
+	return s.db.Model(&models.EmailLog{}).
+		Where("id = ? AND track_opens = ?", emailLogID, true).
+		Updates(map[string]interface{}{
+			"open_count":      gorm.Expr("open_count + 1"),
+			"first_opened_at": gorm.Expr("COALESCE(first_opened_at, ?)", now),
+		}).Error
+}
+
+// RecordClick checks a click link's signature, counts the click and returns where to send the
+// reader. A click also counts as the first open when the reader's client blocked the pixel.
+func (s *EmailService) RecordClick(emailLogID uuid.UUID, target string, signature string) (string, error) {
+	expected := s.linkSignature(emailLogID, target)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return "", ErrInvalidTrackingLink
+	}
+
+	now := time.Now()
+	err := s.db.Model(&models.EmailLog{}).
+		Where("id = ? AND track_clicks = ?", emailLogID, true).
+		Updates(map[string]interface{}{
+			"click_count":     gorm.Expr("click_count + 1"),
+			"last_clicked_at": now,
+			"first_opened_at": gorm.Expr("COALESCE(first_opened_at, ?)", now),
+		}).Error
+	return target, err
+}
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Delivery outcomes reported by a provider's webhook
+const (
+	DeliveryEventDelivered  = "delivered"
+	DeliveryEventBounced    = "bounced"
+	DeliveryEventComplained = "complained"
+)
+
+// DeliveryEvent is one delivery outcome for one email. Transient bounces are recorded but
+// leave the email's status alone, since the provider keeps retrying.
+type DeliveryEvent struct {
+	Kind       string
+	BounceType string // e.g. "Permanent", "Transient", "blocked"
+	Permanent  bool
+	Detail     string
+	At         time.Time
+}
+
+// RecordDeliveryEvent applies a webhook event to the email's log. The email is found by our
+// log ID when the provider echoes it back, otherwise by the provider's message ID. Bounces and
+// complaints are final: a later delivered event does not overwrite them.
+func (s *EmailService) RecordDeliveryEvent(emailLogID *uuid.UUID, providerMessageID string, event DeliveryEvent) error {
+	query := s.db.Model(&models.EmailLog{})
+	switch {
+	case emailLogID != nil:
+		query = query.Where("id = ?", *emailLogID)
+	case providerMessageID != "":
+		query = query.Where("provider_message_id = ?", providerMessageID)
+	default:
+		return errors.New("event does not identify an email")
+	}
+
+	if event.At.IsZero() {
+		event.At = time.Now()
+	}
+	updates := map[string]interface{}{"updated_at": time.Now()}
+
+	switch event.Kind {
+	case DeliveryEventDelivered:
+		query = query.Where("status IN ?", []string{models.EmailStatusQueued, models.EmailStatusSent})
+		updates["status"] = models.EmailStatusDelivered
+		updates["delivered_at"] = event.At
+	case DeliveryEventBounced:
+		bounceType := event.BounceType
+		updates["bounced_at"] = event.At
+		updates["bounce_type"] = &bounceType
+		if event.Detail != "" {
+			updates["error"] = &event.Detail
+		}
+		if event.Permanent {
+			query = query.Where("status <> ?", models.EmailStatusComplained)
+			updates["status"] = models.EmailStatusBounced
+		}
+	case DeliveryEventComplained:
+		updates["status"] = models.EmailStatusComplained
+		updates["complained_at"] = event.At
+	default:
+		return fmt.Errorf("unknown delivery event %q", event.Kind)
+	}
+
+	return query.Updates(updates).Error
+}
+
+// sendGridEvent is one entry of a SendGrid event webhook post
+type sendGridEvent struct {
+	Event       string `json:"event"`
+	Type        string `json:"type"` // "bounce" or "blocked" on bounce events
+	Reason      string `json:"reason"`
+	Timestamp   int64  `json:"timestamp"`
+	SGMessageID string `json:"sg_message_id"`
+	EmailLogID  string `json:"email_log_id"` // Our custom argument
+}
+
+// HandleSendGridEvents applies a SendGrid event webhook post and returns how many events were
+// recorded. Open and click events are ignored as SendGrid's tracking is turned off.
+func (s *EmailService) HandleSendGridEvents(body []byte) (int, error) {
+	var events []sendGridEvent
+	if err := json.Unmarshal(body, &events); err != nil {
+		return 0, fmt.Errorf("invalid SendGrid event payload: %w", err)
+	}
+
+	recorded := 0
+	for _, e := range events {
+		event := DeliveryEvent{At: time.Unix(e.Timestamp, 0), Detail: e.Reason}
+		switch e.Event {
+		case "delivered":
+			event.Kind = DeliveryEventDelivered
+		case "bounce":
+			event.Kind = DeliveryEventBounced
+			event.BounceType = e.Type
+			// Blocked messages may go through on a later attempt
+			event.Permanent = e.Type != "blocked"
+		case "dropped":
+			event.Kind = DeliveryEventBounced
+			event.BounceType = "dropped"
+			event.Permanent = true
+		case "spamreport":
+			event.Kind = DeliveryEventComplained
+		default:
+			continue
+		}
+
+		var emailLogID *uuid.UUID
+		if id, err := uuid.Parse(e.EmailLogID); err == nil {
+			emailLogID = &id
+		}
+		// sg_message_id is the X-Message-Id we stored followed by a delivery suffix
+		providerMessageID, _, _ := strings.Cut(e.SGMessageID, ".")
+
+		if err := s.RecordDeliveryEvent(emailLogID, providerMessageID, event); err != nil {
+			config.Logger.Warn("Failed to record SendGrid event",
+				zap.String("event", e.Event),
+				zap.String("sg_message_id", e.SGMessageID),
+				zap.Error(err))
+			continue
+		}
+		recorded++
+	}
+	return recorded, nil
+}
+
+// snsEnvelope is an Amazon SNS HTTP delivery
+type snsEnvelope struct {
+	Type         string `json:"Type"`
+	Message      string `json:"Message"`
+	SubscribeURL string `json:"SubscribeURL"`
+	TopicArn     string `json:"TopicArn"`
+}
+
+// sesNotification covers both SES feedback notifications (notificationType) and configuration
+// set event publishing (eventType)
+type sesNotification struct {
+	NotificationType string `json:"notificationType"`
+	EventType        string `json:"eventType"`
+	Mail             struct {
+		MessageID string `json:"messageId"`
+	} `json:"mail"`
+	Bounce struct {
+		BounceType        string `json:"bounceType"`
+		BounceSubType     string `json:"bounceSubType"`
+		Timestamp         string `json:"timestamp"`
+		BouncedRecipients []struct {
+			DiagnosticCode string `json:"diagnosticCode"`
+		} `json:"bouncedRecipients"`
+	} `json:"bounce"`
+	Complaint struct {
+		ComplaintFeedbackType string `json:"complaintFeedbackType"`
+		Timestamp             string `json:"timestamp"`
+	} `json:"complaint"`
+	Delivery struct {
+		Timestamp string `json:"timestamp"`
+	} `json:"delivery"`
+}
+
+// HandleSESNotification applies an SNS delivery of SES bounce, complaint and delivery
+// notifications. The topic's subscription confirmation is followed automatically.
+func (s *EmailService) HandleSESNotification(ctx context.Context, body []byte) error {
+	var envelope snsEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return fmt.Errorf("invalid SNS payload: %w", err)
+	}
+
+	switch envelope.Type {
+	case "SubscriptionConfirmation":
+		return confirmSNSSubscription(ctx, envelope)
+	case "Notification":
+	default:
+		return nil
+	}
+
+	var notification sesNotification
+	if err := json.Unmarshal([]byte(envelope.Message), &notification); err != nil {
+		return fmt.Errorf("invalid SES notification: %w", err)
+	}
+
+	kind := notification.NotificationType
+	if kind == "" {
+		kind = notification.EventType
+	}
+
+	var event DeliveryEvent
+	switch kind {
+	case "Delivery":
+		event.Kind = DeliveryEventDelivered
+		event.At = parseSESTime(notification.Delivery.Timestamp)
+	case "Bounce":
+		event.Kind = DeliveryEventBounced
+		event.BounceType = notification.Bounce.BounceType
+		event.Permanent = notification.Bounce.BounceType == "Permanent"
+		event.At = parseSESTime(notification.Bounce.Timestamp)
+		event.Detail = notification.Bounce.BounceSubType
+		if len(notification.Bounce.BouncedRecipients) > 0 && notification.Bounce.BouncedRecipients[0].DiagnosticCode != "" {
+			event.Detail = notification.Bounce.BouncedRecipients[0].DiagnosticCode
+		}
+	case "Complaint":
+		event.Kind = DeliveryEventComplained
+		event.At = parseSESTime(notification.Complaint.Timestamp)
+		event.Detail = notification.Complaint.ComplaintFeedbackType
+	default:
+		return nil
+	}
+
+	return s.RecordDeliveryEvent(nil, notification.Mail.MessageID, event)
+}
+
+// confirmSNSSubscription visits the subscription URL, which must be on Amazon's SNS endpoints
+func confirmSNSSubscription(ctx context.Context, envelope snsEnvelope) error {
+	subscribeURL, err := url.Parse(envelope.SubscribeURL)
+	if err != nil || subscribeURL.Scheme != "https" ||
+		!strings.HasPrefix(subscribeURL.Hostname(), "sns.") || !strings.HasSuffix(subscribeURL.Hostname(), ".amazonaws.com") {
+		return fmt.Errorf("refusing SNS subscription URL %q", envelope.SubscribeURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, subscribeURL.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := (&http.Client{Timeout: 15 * time.Second}).Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to confirm SNS subscription: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("SNS subscription confirmation failed with status %d", resp.StatusCode)
+	}
+	config.Logger.Info("Confirmed SNS subscription for SES notifications", zap.String("topic", envelope.TopicArn))
+	return nil
+}
+
+func parseSESTime(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Now()
+	}
+	return t
+}
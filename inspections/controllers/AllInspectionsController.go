@@ -0,0 +1,16 @@
+package controllers
+
+import (
+	"town-planning-backend/inspections/repositories"
+	"town-planning-backend/inspections/services"
+	"town-planning-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+type InspectionController struct {
+	InspectionRepo repositories.InspectionRepository
+	DB             *gorm.DB
+	FileStorage    utils.FileStorage
+	PhotoPolicy    services.PhotoVerificationPolicy
+}
@@ -0,0 +1,431 @@
+package controllers
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/inspections/repositories"
+	"town-planning-backend/inspections/requests"
+	"town-planning-backend/token"
+	"town-planning-backend/utils/pagination"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// inspectionInvoicingErrorStatus maps scheduling, invoicing and certificate errors to a status
+func inspectionInvoicingErrorStatus(err error) int {
+	var outstanding *repositories.OutstandingInspectionInvoicesError
+	switch {
+	case errors.As(err, &outstanding):
+		return fiber.StatusConflict
+	case strings.Contains(err.Error(), "not found"):
+		return fiber.StatusNotFound
+	case strings.HasPrefix(err.Error(), "duplicate payment"),
+		err.Error() == "inspection invoice is not awaiting payment",
+		err.Error() == "compliance certificate has already been issued",
+		err.Error() == "no passed inspection to certify":
+		return fiber.StatusConflict
+	case err.Error() == "payment amount must equal the invoice amount",
+		err.Error() == "inspector is not active":
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// ScheduleInspectionController books a site visit for an inspector. When inspections are billed
+// per visit on scheduling, the visit's invoice is raised with it.
+func (ic *InspectionController) ScheduleInspectionController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	var request requests.ScheduleInspectionRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+	request.InspectionType = strings.TrimSpace(request.InspectionType)
+	if request.ApplicationID == uuid.Nil || request.InspectorID == uuid.Nil || request.InspectionType == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "application_id, inspector_id and inspection_type are required",
+		})
+	}
+
+	inspection := &models.Inspection{
+		ApplicationID:  request.ApplicationID,
+		InspectorID:    request.InspectorID,
+		InspectionType: request.InspectionType,
+		ScheduledDate:  request.ScheduledDate,
+		Notes:          request.Notes,
+		CreatedBy:      payload.UserID.String(),
+	}
+
+	tx := ic.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	inspection, invoice, err := ic.InspectionRepo.ScheduleInspection(tx, inspection)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(inspectionInvoicingErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to schedule inspection",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Inspection scheduled successfully",
+		"data": fiber.Map{
+			"inspection": inspection,
+			"invoice":    invoice,
+		},
+	})
+}
+
+// GetInspectionInvoicesController lists inspection invoices, filtered by application_id and
+// status when given
+func (ic *InspectionController) GetInspectionInvoicesController(c *fiber.Ctx) error {
+	var applicationID *uuid.UUID
+	if raw := c.Query("application_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": "Invalid application ID",
+				"error":   "invalid_uuid",
+			})
+		}
+		applicationID = &id
+	}
+
+	status := models.InspectionInvoiceStatus(strings.ToUpper(c.Query("status")))
+	switch status {
+	case "", models.InspectionInvoiceUnpaid, models.InspectionInvoicePaid, models.InspectionInvoiceWaived, models.InspectionInvoiceCancelled:
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "status must be UNPAID, PAID, WAIVED or CANCELLED",
+		})
+	}
+
+	page, err := pagination.ParseRequest(c, 20)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid pagination parameters",
+			"error":   err.Error(),
+		})
+	}
+
+	invoices, total, err := ic.InspectionRepo.GetInspectionInvoices(applicationID, status, page)
+	if err != nil {
+		config.Logger.Error("Failed to fetch inspection invoices", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch inspection invoices",
+			"error":   err.Error(),
+		})
+	}
+
+	var next *pagination.Cursor
+	if len(invoices) > 0 {
+		last := invoices[len(invoices)-1]
+		next = page.NextCursor(len(invoices), last.CreatedAt, last.ID)
+	}
+	return c.JSON(pagination.NewEnvelope(c, page, invoices, total, next))
+}
+
+// RecordInspectionInvoicePaymentController records payment of an inspection invoice
+func (ic *InspectionController) RecordInspectionInvoicePaymentController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	invoiceID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid invoice ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var request requests.RecordInspectionInvoicePaymentRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+	if request.ReceiptNumber == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Receipt number is required",
+			"error":   "missing_receipt_number",
+		})
+	}
+	if request.PaymentDate != nil && request.PaymentDate.After(time.Now()) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Payment date cannot be in the future",
+			"error":   "invalid_payment_date",
+		})
+	}
+
+	payment := models.Payment{
+		Amount:            request.Amount,
+		PaymentMethod:     request.PaymentMethod,
+		ReceiptNumber:     request.ReceiptNumber,
+		ExternalReference: request.ExternalReference,
+		BankAccountID:     request.BankAccountID,
+		Notes:             request.Notes,
+		CreatedBy:         payload.UserID.String(),
+	}
+	if payment.PaymentMethod == "" {
+		payment.PaymentMethod = models.CashPaymentMethod
+	}
+	if request.PaymentDate != nil {
+		payment.PaymentDate = *request.PaymentDate
+	}
+
+	tx := ic.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	invoice, err := ic.InspectionRepo.RecordInspectionInvoicePayment(tx, invoiceID, &payment)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(inspectionInvoicingErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to record inspection invoice payment",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Inspection invoice paid",
+		"data": fiber.Map{
+			"invoice": invoice,
+			"payment": payment,
+		},
+	})
+}
+
+// WaiveInspectionInvoiceController writes off an unpaid inspection invoice
+func (ic *InspectionController) WaiveInspectionInvoiceController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	invoiceID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid invoice ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var request requests.WaiveInspectionInvoiceRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+	request.Reason = strings.TrimSpace(request.Reason)
+	if request.Reason == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "A reason is required to waive an invoice",
+		})
+	}
+
+	tx := ic.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	invoice, err := ic.InspectionRepo.WaiveInspectionInvoice(tx, invoiceID, payload.UserID, request.Reason)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(inspectionInvoicingErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to waive inspection invoice",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("Inspection invoice waived",
+		zap.String("invoiceID", invoice.ID.String()),
+		zap.String("userID", payload.UserID.String()))
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Inspection invoice waived",
+		"data":    invoice,
+	})
+}
+
+// IssueComplianceCertificateController issues an application's certificate of compliance. It is
+// refused while any inspection invoice on the application is unpaid; the response then lists
+// the invoices to settle.
+func (ic *InspectionController) IssueComplianceCertificateController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var request requests.IssueComplianceCertificateRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": "Invalid request payload",
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	tx := ic.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	certificate, err := ic.InspectionRepo.IssueComplianceCertificate(tx, applicationID, payload.UserID, request.Notes)
+	if err != nil {
+		tx.Rollback()
+		response := fiber.Map{
+			"success": false,
+			"message": "Failed to issue compliance certificate",
+			"error":   err.Error(),
+		}
+		var outstanding *repositories.OutstandingInspectionInvoicesError
+		if errors.As(err, &outstanding) {
+			response["outstanding_invoices"] = outstanding.Invoices
+		}
+		return c.Status(inspectionInvoicingErrorStatus(err)).JSON(response)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Compliance certificate issued",
+		"data":    certificate,
+	})
+}
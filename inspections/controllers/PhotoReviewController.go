@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/inspections/requests"
+	"town-planning-backend/token"
+	"town-planning-backend/utils/pagination"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// GetPhotosForReviewController lists inspection photos waiting for a supervisor. Flagged photos
+// are listed by default; pass status=UNVERIFIABLE for photos that could not be checked.
+func (ic *InspectionController) GetPhotosForReviewController(c *fiber.Ctx) error {
+	status := models.PhotoVerificationStatus(c.Query("status", string(models.PhotoVerificationFlagged)))
+	if status != models.PhotoVerificationFlagged && status != models.PhotoVerificationUnverifiable {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "status must be FLAGGED or UNVERIFIABLE",
+		})
+	}
+
+	page, err := pagination.ParseRequest(c, 20)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid pagination parameters",
+			"error":   err.Error(),
+		})
+	}
+
+	photos, total, err := ic.InspectionRepo.GetPhotosForReview(status, page)
+	if err != nil {
+		config.Logger.Error("Failed to fetch photos for review", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch photos",
+			"error":   err.Error(),
+		})
+	}
+
+	var next *pagination.Cursor
+	if len(photos) > 0 {
+		last := photos[len(photos)-1]
+		next = page.NextCursor(len(photos), last.CreatedAt, last.ID)
+	}
+	return c.JSON(pagination.NewEnvelope(c, page, photos, total, next))
+}
+
+// ReviewInspectionPhotoController accepts or rejects a photo flagged by verification. The
+// verdict is kept on the photo and rolled up onto its inspection.
+func (ic *InspectionController) ReviewInspectionPhotoController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	photoID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid photo ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var request requests.PhotoReviewRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+	if request.Accept == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "accept is required",
+		})
+	}
+	if !*request.Accept && (request.Notes == nil || *request.Notes == "") {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "notes are required when rejecting a photo",
+		})
+	}
+
+	tx := ic.DB.WithContext(c.UserContext()).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	photo, err := ic.InspectionRepo.ReviewInspectionPhoto(tx, photoID, *request.Accept, request.Notes, payload.UserID)
+	if err != nil {
+		tx.Rollback()
+		statusCode := fiber.StatusInternalServerError
+		if err.Error() == "photo not found" {
+			statusCode = fiber.StatusNotFound
+		} else if err.Error() == "photo is not awaiting review" {
+			statusCode = fiber.StatusConflict
+		}
+		return c.Status(statusCode).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to review photo",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Photo reviewed successfully",
+		"data":    photo,
+	})
+}
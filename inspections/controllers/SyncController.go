@@ -0,0 +1,249 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/inspections/repositories"
+	"town-planning-backend/inspections/requests"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultSyncPullLimit = 200
+	maxSyncPullLimit     = 1000
+	maxSyncPushBatchSize = 500
+)
+
+// SyncPullController returns everything that changed for the authenticated inspector since the cursor.
+// The cursor is the `next_cursor` value from the previous pull, an RFC3339 time optionally
+// followed by "/" and a row ID; omit it for a full sync.
+func (ic *InspectionController) SyncPullController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	since := repositories.SyncCursorAfter(time.Time{})
+	if cursor := strings.TrimSpace(c.Query("cursor")); cursor != "" && cursor != "null" {
+		parsed, err := repositories.ParseSyncCursor(cursor)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": "Invalid cursor",
+				"error":   err.Error(),
+			})
+		}
+		since = parsed
+	}
+
+	limit := c.QueryInt("limit", defaultSyncPullLimit)
+	if limit <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid limit parameter",
+		})
+	}
+	if limit > maxSyncPullLimit {
+		limit = maxSyncPullLimit
+	}
+
+	changeSet, err := ic.InspectionRepo.GetChangesSince(payload.UserID, since, limit)
+	if err != nil {
+		config.Logger.Error("Failed to pull sync changes",
+			zap.Error(err),
+			zap.String("userID", payload.UserID.String()),
+			zap.Stringer("since", since))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to pull changes",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Changes retrieved successfully",
+		"data": fiber.Map{
+			"inspections":     changeSet.Inspections,
+			"checklist_items": changeSet.ChecklistItems,
+			"photos":          changeSet.Photos,
+			"applications":    changeSet.Applications,
+			"next_cursor":     changeSet.NextCursor.String(),
+			"has_more":        changeSet.HasMore,
+			"server_time":     time.Now().Format(time.RFC3339Nano),
+		},
+	})
+}
+
+// SyncPushController applies a batch of offline mutations. Each mutation is committed on its own
+// so a single bad entry does not discard the rest of the batch, and mutations already seen under
+// the same idempotency key are replayed from the stored result instead of being applied again.
+func (ic *InspectionController) SyncPushController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	var request requests.SyncPushRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+
+	if len(request.Mutations) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "At least one mutation is required",
+		})
+	}
+
+	if len(request.Mutations) > maxSyncPushBatchSize {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+			"success": false,
+			"message": "Too many mutations in a single push",
+			"error":   "batch_too_large",
+		})
+	}
+
+	results := make([]requests.SyncMutationResult, 0, len(request.Mutations))
+	summary := map[models.SyncMutationStatus]int{}
+
+	for _, mutation := range request.Mutations {
+		result, err := ic.processSyncMutation(c.UserContext(), payload, request.DeviceID, mutation)
+		if err != nil {
+			config.Logger.Error("Failed to apply sync mutation",
+				zap.Error(err),
+				zap.String("userID", payload.UserID.String()),
+				zap.String("idempotencyKey", mutation.IdempotencyKey))
+			// Not recorded - the client keeps the mutation queued and retries with the same key
+			message := "server error, retry later"
+			result = &requests.SyncMutationResult{
+				IdempotencyKey: mutation.IdempotencyKey,
+				EntityType:     mutation.EntityType,
+				EntityID:       mutation.EntityID,
+				Status:         "ERROR",
+				Message:        &message,
+			}
+		} else {
+			summary[models.SyncMutationStatus(result.Status)]++
+		}
+		results = append(results, *result)
+	}
+
+	config.Logger.Info("Sync push processed",
+		zap.String("userID", payload.UserID.String()),
+		zap.String("deviceID", request.DeviceID),
+		zap.Int("mutations", len(request.Mutations)),
+		zap.Int("applied", summary[models.SyncMutationApplied]),
+		zap.Int("conflicts", summary[models.SyncMutationConflict]),
+		zap.Int("rejected", summary[models.SyncMutationRejected]))
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Sync push processed",
+		"data": fiber.Map{
+			"results":     results,
+			"server_time": time.Now().Format(time.RFC3339Nano),
+		},
+	})
+}
+
+func (ic *InspectionController) processSyncMutation(
+	ctx context.Context,
+	payload *token.Payload,
+	deviceID string,
+	mutation requests.SyncMutationRequest,
+) (*requests.SyncMutationResult, error) {
+	if strings.TrimSpace(mutation.IdempotencyKey) == "" {
+		message := "idempotency_key is required"
+		return &requests.SyncMutationResult{
+			EntityType: mutation.EntityType,
+			EntityID:   mutation.EntityID,
+			Status:     string(models.SyncMutationRejected),
+			Message:    &message,
+		}, nil
+	}
+
+	// Replay a mutation we have already seen
+	existing, err := ic.InspectionRepo.GetSyncMutationByKey(mutation.IdempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return replayedSyncResult(payload, existing), nil
+	}
+
+	tx := ic.DB.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	record, err := ic.InspectionRepo.ApplySyncMutation(tx, payload.UserID, deviceID, mutation)
+	if err != nil {
+		tx.Rollback()
+
+		// A concurrent push may have recorded the same key first
+		if existing, lookupErr := ic.InspectionRepo.GetSyncMutationByKey(mutation.IdempotencyKey); lookupErr == nil && existing != nil {
+			return replayedSyncResult(payload, existing), nil
+		}
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return &requests.SyncMutationResult{
+		IdempotencyKey: record.IdempotencyKey,
+		EntityType:     record.EntityType,
+		EntityID:       record.EntityID,
+		Status:         string(record.Status),
+		ServerVersion:  record.ServerVersion,
+		Message:        record.Message,
+	}, nil
+}
+
+func replayedSyncResult(payload *token.Payload, existing *models.SyncMutation) *requests.SyncMutationResult {
+	// Idempotency keys are only meaningful for the user who created them
+	if existing.UserID != payload.UserID {
+		message := "idempotency_key already used"
+		return &requests.SyncMutationResult{
+			IdempotencyKey: existing.IdempotencyKey,
+			EntityType:     existing.EntityType,
+			Status:         string(models.SyncMutationRejected),
+			Message:        &message,
+		}
+	}
+
+	return &requests.SyncMutationResult{
+		IdempotencyKey: existing.IdempotencyKey,
+		EntityType:     existing.EntityType,
+		EntityID:       existing.EntityID,
+		Status:         string(existing.Status),
+		ServerVersion:  existing.ServerVersion,
+		Message:        existing.Message,
+		Replayed:       true,
+	}
+}
@@ -0,0 +1,210 @@
+package controllers
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/inspections/services"
+	"town-planning-backend/token"
+	"town-planning-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var allowedInspectionPhotoExtensions = map[string]string{
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".heic": "image/heic",
+	".webp": "image/webp",
+}
+
+// UploadInspectionPhotoController uploads the binary for a photo whose metadata was pushed through sync.
+// Uploading again for the same photo replaces the stored file, so retries are safe.
+// Set add_to_stand_gallery=true to also show the photo in the stand's gallery.
+// The capture time and position embedded in the file are checked against the inspection before
+// they are scrubbed, and photos that don't match are flagged for a supervisor.
+func (ic *InspectionController) UploadInspectionPhotoController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	photoID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid photo ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Photo file is required",
+			"error":   err.Error(),
+		})
+	}
+
+	fileExt := strings.ToLower(filepath.Ext(fileHeader.Filename))
+	mimeType, allowed := allowedInspectionPhotoExtensions[fileExt]
+	if !allowed {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Unsupported photo format",
+			"error":   fmt.Sprintf("file type %s is not allowed", fileExt),
+		})
+	}
+
+	photo, err := ic.InspectionRepo.GetInspectionPhotoForInspector(photoID, payload.UserID)
+	if err != nil {
+		statusCode := fiber.StatusInternalServerError
+		if err.Error() == "photo not found" {
+			statusCode = fiber.StatusNotFound
+		} else if err.Error() == "photo not accessible" {
+			statusCode = fiber.StatusForbidden
+		}
+		return c.Status(statusCode).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to load photo",
+			"error":   err.Error(),
+		})
+	}
+
+	// Optional override of the flag pushed through sync
+	if raw := c.FormValue("add_to_stand_gallery"); raw != "" {
+		addToGallery, err := strconv.ParseBool(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": "Invalid add_to_stand_gallery value",
+				"error":   err.Error(),
+			})
+		}
+		photo.AddToStandGallery = addToGallery
+	}
+
+	folderPath := filepath.Join("inspections", photo.InspectionID.String())
+	if err := os.MkdirAll(filepath.Join("uploads", folderPath), 0755); err != nil {
+		config.Logger.Error("Failed to create inspection photo directory", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to store photo",
+			"error":   err.Error(),
+		})
+	}
+
+	// The phone's EXIF block is dropped; the capture time and position are kept when the app
+	// did not send them
+	data, scrubbed, err := utils.ReadUploadedImage(fileHeader)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to read photo file",
+			"error":   err.Error(),
+		})
+	}
+
+	site, err := ic.InspectionRepo.GetPhotoVerificationSite(photo.InspectionID)
+	if err != nil {
+		config.Logger.Error("Failed to load inspection for photo verification",
+			zap.Error(err),
+			zap.String("photoID", photo.ID.String()))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to verify photo",
+			"error":   err.Error(),
+		})
+	}
+	evidence := services.PhotoEvidence{UploadedAt: time.Now()}
+	if scrubbed != nil {
+		evidence.CapturedAt = scrubbed.CapturedAt
+		evidence.Latitude = scrubbed.Latitude
+		evidence.Longitude = scrubbed.Longitude
+	}
+	verdict := ic.PhotoPolicy.Verify(photo, evidence, *site)
+	if err := verdict.Apply(photo, evidence); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to verify photo",
+			"error":   err.Error(),
+		})
+	}
+	if verdict.Status == models.PhotoVerificationFlagged {
+		config.Logger.Warn("Inspection photo flagged for review",
+			zap.String("photoID", photo.ID.String()),
+			zap.String("inspectionID", photo.InspectionID.String()),
+			zap.Strings("reasons", verdict.Reasons))
+	}
+
+	if scrubbed != nil {
+		scrubbedAt := time.Now()
+		mimeType = scrubbed.MimeType
+		photo.MetadataScrubbedAt = &scrubbedAt
+		if photo.TakenAt == nil {
+			photo.TakenAt = scrubbed.CapturedAt
+		}
+		if photo.Latitude == nil && photo.Longitude == nil {
+			photo.Latitude = photo.ExifLatitude
+			photo.Longitude = photo.ExifLongitude
+		}
+	} else {
+		photo.MetadataScrubbedAt = nil
+	}
+
+	filePath, err := ic.FileStorage.UploadFileFromReader(bytes.NewReader(data), filepath.Join(folderPath, photo.ID.String()+fileExt))
+	if err != nil {
+		config.Logger.Error("Failed to store inspection photo",
+			zap.Error(err),
+			zap.String("photoID", photo.ID.String()))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to store photo",
+			"error":   err.Error(),
+		})
+	}
+
+	tx := ic.DB.WithContext(c.UserContext()).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	updatedPhoto, err := ic.InspectionRepo.AttachInspectionPhotoFile(tx, photo, filePath, mimeType, int64(len(data)), payload.UserID.String())
+	if err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to save photo",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Photo uploaded successfully",
+		"data":    updatedPhoto,
+	})
+}
@@ -0,0 +1,297 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/inspections/services"
+	"town-planning-backend/utils/pagination"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OutstandingInspectionInvoicesError is returned when a compliance certificate is requested while
+// inspection invoices on the application are unpaid. It carries the invoices to settle.
+type OutstandingInspectionInvoicesError struct {
+	Invoices []models.InspectionInvoice
+}
+
+func (e *OutstandingInspectionInvoicesError) Error() string {
+	return fmt.Sprintf("%d inspection invoice(s) must be paid before a compliance certificate is issued", len(e.Invoices))
+}
+
+// ScheduleInspection books a site visit for an inspector and, when inspections are invoiced on
+// scheduling, raises its invoice. The invoice is nil when none was raised.
+func (r *inspectionRepository) ScheduleInspection(tx *gorm.DB, inspection *models.Inspection) (*models.Inspection, *models.InspectionInvoice, error) {
+	var application models.Application
+	if err := tx.Select("id").Where("id = ?", inspection.ApplicationID).First(&application).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New("application not found")
+		}
+		return nil, nil, fmt.Errorf("failed to load application: %w", err)
+	}
+
+	var inspector models.User
+	if err := tx.Select("id", "active").Where("id = ?", inspection.InspectorID).First(&inspector).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New("inspector not found")
+		}
+		return nil, nil, fmt.Errorf("failed to load inspector: %w", err)
+	}
+	if !inspector.Active {
+		return nil, nil, errors.New("inspector is not active")
+	}
+
+	inspection.Status = models.InspectionStatusScheduled
+	if err := tx.Create(inspection).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to schedule inspection: %w", err)
+	}
+
+	var invoice *models.InspectionInvoice
+	if services.ShouldInvoiceInspection(models.InspectionInvoiceOnSchedule) {
+		var err error
+		if invoice, err = r.raiseInspectionInvoice(tx, inspection, models.InspectionInvoiceOnSchedule, inspection.CreatedBy); err != nil {
+			return nil, nil, err
+		}
+	}
+	return inspection, invoice, nil
+}
+
+// syncInspectionInvoice keeps an inspection's invoice in step with its status: completing it
+// raises the invoice when inspections are invoiced on completion, and cancelling it cancels an
+// unpaid invoice
+func (r *inspectionRepository) syncInspectionInvoice(tx *gorm.DB, inspection *models.Inspection, previous models.InspectionStatus, updatedBy string) error {
+	if inspection.Status == previous {
+		return nil
+	}
+
+	switch inspection.Status {
+	case models.InspectionStatusCompleted:
+		if services.ShouldInvoiceInspection(models.InspectionInvoiceOnCompletion) {
+			_, err := r.raiseInspectionInvoice(tx, inspection, models.InspectionInvoiceOnCompletion, updatedBy)
+			return err
+		}
+	case models.InspectionStatusCancelled:
+		if err := tx.Model(&models.InspectionInvoice{}).
+			Where("inspection_id = ? AND status = ?", inspection.ID, models.InspectionInvoiceUnpaid).
+			Update("status", models.InspectionInvoiceCancelled).Error; err != nil {
+			return fmt.Errorf("failed to cancel inspection invoice: %w", err)
+		}
+	}
+	return nil
+}
+
+// raiseInspectionInvoice invoices an inspection at the inspection fee of the application's
+// tariff. An inspection already invoiced keeps its invoice, and applications without a tariff
+// or with no inspection fee are not invoiced; both return nil.
+func (r *inspectionRepository) raiseInspectionInvoice(
+	tx *gorm.DB,
+	inspection *models.Inspection,
+	trigger models.InspectionInvoiceTrigger,
+	createdBy string,
+) (*models.InspectionInvoice, error) {
+	var existing int64
+	if err := tx.Model(&models.InspectionInvoice{}).Where("inspection_id = ?", inspection.ID).Count(&existing).Error; err != nil {
+		return nil, fmt.Errorf("failed to check inspection invoice: %w", err)
+	}
+	if existing > 0 {
+		return nil, nil
+	}
+
+	var application models.Application
+	if err := tx.Preload("Tariff").Select("id", "tariff_id").Where("id = ?", inspection.ApplicationID).First(&application).Error; err != nil {
+		return nil, fmt.Errorf("failed to load application tariff: %w", err)
+	}
+	if application.Tariff == nil || !application.Tariff.InspectionFee.IsPositive() {
+		config.Logger.Warn("Inspection not invoiced, application has no inspection fee",
+			zap.String("inspectionID", inspection.ID.String()),
+			zap.String("applicationID", inspection.ApplicationID.String()))
+		return nil, nil
+	}
+
+	invoice := &models.InspectionInvoice{
+		InspectionID:  inspection.ID,
+		ApplicationID: inspection.ApplicationID,
+		TariffID:      application.TariffID,
+		Amount:        application.Tariff.InspectionFee,
+		Currency:      application.Tariff.Currency,
+		Trigger:       trigger,
+		Status:        models.InspectionInvoiceUnpaid,
+		CreatedBy:     createdBy,
+	}
+	if err := tx.Create(invoice).Error; err != nil {
+		return nil, fmt.Errorf("failed to raise inspection invoice: %w", err)
+	}
+	return invoice, nil
+}
+
+// GetInspectionInvoices lists inspection invoices, newest first, optionally for one application
+// and in one status
+func (r *inspectionRepository) GetInspectionInvoices(
+	applicationID *uuid.UUID,
+	status models.InspectionInvoiceStatus,
+	page pagination.Request,
+) ([]models.InspectionInvoice, int64, error) {
+	query := r.db.Model(&models.InspectionInvoice{})
+	if applicationID != nil {
+		query = query.Where("application_id = ?", *applicationID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count inspection invoices: %w", err)
+	}
+
+	var invoices []models.InspectionInvoice
+	if err := page.Window(query.Preload("Inspection"), "inspection_invoices", "inspection_invoices.created_at DESC").
+		Find(&invoices).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch inspection invoices: %w", err)
+	}
+	return invoices, total, nil
+}
+
+func (r *inspectionRepository) getInspectionInvoiceForUpdate(tx *gorm.DB, invoiceID uuid.UUID) (*models.InspectionInvoice, error) {
+	var invoice models.InspectionInvoice
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", invoiceID).First(&invoice).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("inspection invoice not found")
+		}
+		return nil, fmt.Errorf("failed to load inspection invoice: %w", err)
+	}
+	return &invoice, nil
+}
+
+// RecordInspectionInvoicePayment records payment of an inspection invoice, which must be paid
+// in full
+func (r *inspectionRepository) RecordInspectionInvoicePayment(tx *gorm.DB, invoiceID uuid.UUID, payment *models.Payment) (*models.InspectionInvoice, error) {
+	invoice, err := r.getInspectionInvoiceForUpdate(tx, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if !invoice.IsOutstanding() {
+		return nil, errors.New("inspection invoice is not awaiting payment")
+	}
+	if !payment.Amount.Equal(invoice.Amount) {
+		return nil, errors.New("payment amount must equal the invoice amount")
+	}
+
+	var duplicates int64
+	if err := tx.Model(&models.Payment{}).Where("receipt_number = ?", payment.ReceiptNumber).Count(&duplicates).Error; err != nil {
+		return nil, fmt.Errorf("failed to check receipt number: %w", err)
+	}
+	if duplicates > 0 {
+		return nil, errors.New("duplicate payment: receipt number has already been captured")
+	}
+
+	payment.ApplicationID = &invoice.ApplicationID
+	payment.TariffID = invoice.TariffID
+	payment.PaymentFor = models.PaymentForInspectionFee
+	payment.PaymentStatus = models.PaidPayment
+	payment.TransactionType = models.OrdinaryTransactionType
+	if payment.ReceivedCurrency == nil {
+		payment.ReceivedCurrency = &invoice.Currency
+	}
+	if err := tx.Create(payment).Error; err != nil {
+		return nil, fmt.Errorf("failed to record payment: %w", err)
+	}
+
+	now := time.Now()
+	invoice.Status = models.InspectionInvoicePaid
+	invoice.PaymentID = &payment.ID
+	invoice.PaidAt = &now
+	if err := tx.Save(invoice).Error; err != nil {
+		return nil, fmt.Errorf("failed to update inspection invoice: %w", err)
+	}
+	return invoice, nil
+}
+
+// WaiveInspectionInvoice writes off an unpaid inspection invoice
+func (r *inspectionRepository) WaiveInspectionInvoice(tx *gorm.DB, invoiceID uuid.UUID, userID uuid.UUID, reason string) (*models.InspectionInvoice, error) {
+	invoice, err := r.getInspectionInvoiceForUpdate(tx, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if !invoice.IsOutstanding() {
+		return nil, errors.New("inspection invoice is not awaiting payment")
+	}
+
+	now := time.Now()
+	invoice.Status = models.InspectionInvoiceWaived
+	invoice.WaivedByID = &userID
+	invoice.WaivedAt = &now
+	invoice.WaiverReason = &reason
+	if err := tx.Save(invoice).Error; err != nil {
+		return nil, fmt.Errorf("failed to update inspection invoice: %w", err)
+	}
+	return invoice, nil
+}
+
+// IssueComplianceCertificate certifies an application's completed development against its most
+// recent passed inspection. It is refused with an OutstandingInspectionInvoicesError while any
+// inspection invoice on the application is unpaid.
+func (r *inspectionRepository) IssueComplianceCertificate(
+	tx *gorm.DB,
+	applicationID uuid.UUID,
+	issuedByID uuid.UUID,
+	notes *string,
+) (*models.ComplianceCertificate, error) {
+	var application models.Application
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		Where("id = ?", applicationID).
+		First(&application).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("application not found")
+		}
+		return nil, fmt.Errorf("failed to load application: %w", err)
+	}
+
+	var issued int64
+	if err := tx.Model(&models.ComplianceCertificate{}).Where("application_id = ?", applicationID).Count(&issued).Error; err != nil {
+		return nil, fmt.Errorf("failed to check compliance certificate: %w", err)
+	}
+	if issued > 0 {
+		return nil, errors.New("compliance certificate has already been issued")
+	}
+
+	var outstanding []models.InspectionInvoice
+	if err := tx.Where("application_id = ? AND status = ?", applicationID, models.InspectionInvoiceUnpaid).
+		Order("issued_at ASC").
+		Find(&outstanding).Error; err != nil {
+		return nil, fmt.Errorf("failed to check inspection invoices: %w", err)
+	}
+	if len(outstanding) > 0 {
+		return nil, &OutstandingInspectionInvoicesError{Invoices: outstanding}
+	}
+
+	var inspection models.Inspection
+	if err := tx.Where("application_id = ? AND status = ? AND outcome = ?",
+		applicationID, models.InspectionStatusCompleted, models.InspectionOutcomePassed).
+		Order("completed_at DESC NULLS LAST").
+		First(&inspection).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("no passed inspection to certify")
+		}
+		return nil, fmt.Errorf("failed to load inspections: %w", err)
+	}
+
+	certificate := &models.ComplianceCertificate{
+		ApplicationID: applicationID,
+		InspectionID:  inspection.ID,
+		IssuedByID:    issuedByID,
+		Notes:         notes,
+	}
+	if err := tx.Create(certificate).Error; err != nil {
+		return nil, fmt.Errorf("failed to issue compliance certificate: %w", err)
+	}
+	certificate.Inspection = &inspection
+	return certificate, nil
+}
@@ -0,0 +1,740 @@
+package repositories
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"town-planning-backend/db/models"
+	"town-planning-backend/inspections/requests"
+	"town-planning-backend/inspections/services"
+	"town-planning-backend/utils/pagination"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+type InspectionRepository interface {
+	// Offline sync
+	GetChangesSince(inspectorID uuid.UUID, since SyncCursor, limit int) (*SyncChangeSet, error)
+	GetSyncMutationByKey(idempotencyKey string) (*models.SyncMutation, error)
+	ApplySyncMutation(tx *gorm.DB, userID uuid.UUID, deviceID string, mutation requests.SyncMutationRequest) (*models.SyncMutation, error)
+
+	// Photos
+	GetInspectionPhotoForInspector(photoID uuid.UUID, inspectorID uuid.UUID) (*models.InspectionPhoto, error)
+	AttachInspectionPhotoFile(tx *gorm.DB, photo *models.InspectionPhoto, filePath string, mimeType string, fileSize int64, updatedBy string) (*models.InspectionPhoto, error)
+
+	// Photo verification
+	GetPhotoVerificationSite(inspectionID uuid.UUID) (*services.PhotoSite, error)
+	GetPhotosForReview(status models.PhotoVerificationStatus, page pagination.Request) ([]models.InspectionPhoto, int64, error)
+	ReviewInspectionPhoto(tx *gorm.DB, photoID uuid.UUID, accept bool, notes *string, reviewerID uuid.UUID) (*models.InspectionPhoto, error)
+
+	// Scheduling and per-visit invoicing
+	ScheduleInspection(tx *gorm.DB, inspection *models.Inspection) (*models.Inspection, *models.InspectionInvoice, error)
+	GetInspectionInvoices(applicationID *uuid.UUID, status models.InspectionInvoiceStatus, page pagination.Request) ([]models.InspectionInvoice, int64, error)
+	RecordInspectionInvoicePayment(tx *gorm.DB, invoiceID uuid.UUID, payment *models.Payment) (*models.InspectionInvoice, error)
+	WaiveInspectionInvoice(tx *gorm.DB, invoiceID uuid.UUID, userID uuid.UUID, reason string) (*models.InspectionInvoice, error)
+	IssueComplianceCertificate(tx *gorm.DB, applicationID uuid.UUID, issuedByID uuid.UUID, notes *string) (*models.ComplianceCertificate, error)
+}
+
+type inspectionRepository struct {
+	db *gorm.DB
+}
+
+func NewInspectionRepository(db *gorm.DB) InspectionRepository {
+	return &inspectionRepository{
+		db: db,
+	}
+}
+
+// ApplicationSyncSummary is the trimmed down application view shipped to the mobile app
+type ApplicationSyncSummary struct {
+	ID            uuid.UUID                `json:"id"`
+	PlanNumber    string                   `json:"plan_number"`
+	PermitNumber  string                   `json:"permit_number"`
+	Status        models.ApplicationStatus `json:"status"`
+	ApplicantName string                   `json:"applicant_name"`
+	StandNumber   *string                  `json:"stand_number"`
+	PlanArea      *decimal.Decimal         `json:"plan_area"`
+	UpdatedAt     time.Time                `json:"updated_at"`
+}
+
+// SyncChangeSet is everything that changed for an inspector since a cursor.
+// Soft-deleted rows are included so clients can drop them locally.
+type SyncChangeSet struct {
+	Inspections    []models.Inspection              `json:"inspections"`
+	ChecklistItems []models.InspectionChecklistItem `json:"checklist_items"`
+	Photos         []models.InspectionPhoto         `json:"photos"`
+	Applications   []ApplicationSyncSummary         `json:"applications"`
+	NextCursor     SyncCursor                       `json:"next_cursor"`
+	HasMore        bool                             `json:"has_more"`
+}
+
+// SyncCursor is where a pull resumes: after the rows changed before UpdatedAt, and after those
+// changed at UpdatedAt up to ID. Rows changed in the same instant, e.g. checklist items created
+// together, are paged by ID so none are skipped.
+type SyncCursor struct {
+	UpdatedAt time.Time
+	ID        uuid.UUID
+}
+
+// SyncCursorAfter resumes after every row changed at or before the given time
+func SyncCursorAfter(at time.Time) SyncCursor {
+	return SyncCursor{UpdatedAt: at, ID: uuid.Max}
+}
+
+// ParseSyncCursor reads a cursor written by String. A bare RFC3339 time, as issued before
+// cursors carried an ID, resumes after everything changed at that time.
+func ParseSyncCursor(raw string) (SyncCursor, error) {
+	at, id, withID := strings.Cut(raw, "/")
+	updatedAt, err := time.Parse(time.RFC3339Nano, at)
+	if err != nil {
+		return SyncCursor{}, err
+	}
+	if !withID {
+		return SyncCursorAfter(updatedAt), nil
+	}
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return SyncCursor{}, err
+	}
+	return SyncCursor{UpdatedAt: updatedAt, ID: parsedID}, nil
+}
+
+// String encodes the cursor as its RFC3339 time, followed by "/" and the ID when it stops
+// within an instant
+func (c SyncCursor) String() string {
+	at := c.UpdatedAt.Format(time.RFC3339Nano)
+	if c.ID == uuid.Max {
+		return at
+	}
+	return at + "/" + c.ID.String()
+}
+
+// MarshalText writes the cursor as String does
+func (c SyncCursor) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// before orders cursors the way Postgres orders (updated_at, id)
+func (c SyncCursor) before(other SyncCursor) bool {
+	if !c.UpdatedAt.Equal(other.UpdatedAt) {
+		return c.UpdatedAt.Before(other.UpdatedAt)
+	}
+	return bytes.Compare(c.ID[:], other.ID[:]) < 0
+}
+
+// GetChangesSince returns inspections, checklist items, photos and application summaries
+// changed after `since` for the given inspector. Each entity list is capped at `limit`;
+// when any list is capped, NextCursor is moved back to the oldest capped boundary so the
+// client keeps pulling until HasMore is false.
+func (r *inspectionRepository) GetChangesSince(inspectorID uuid.UUID, since SyncCursor, limit int) (*SyncChangeSet, error) {
+	upperBound := time.Now()
+	changeSet := &SyncChangeSet{
+		Inspections:    []models.Inspection{},
+		ChecklistItems: []models.InspectionChecklistItem{},
+		Photos:         []models.InspectionPhoto{},
+		Applications:   []ApplicationSyncSummary{},
+		NextCursor:     SyncCursorAfter(upperBound),
+	}
+
+	inspectorInspections := r.db.Unscoped().Model(&models.Inspection{}).
+		Select("id").
+		Where("inspector_id = ?", inspectorID)
+
+	// Inspections
+	if err := r.db.Unscoped().
+		Where("inspector_id = ? AND (updated_at, id) > (?, ?) AND updated_at <= ?", inspectorID, since.UpdatedAt, since.ID, upperBound).
+		Order("updated_at ASC, id ASC").
+		Limit(limit).
+		Find(&changeSet.Inspections).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch inspection changes: %w", err)
+	}
+	if len(changeSet.Inspections) == limit {
+		changeSet.HasMore = true
+		last := changeSet.Inspections[limit-1]
+		r.moveCursorBack(changeSet, SyncCursor{UpdatedAt: last.UpdatedAt, ID: last.ID})
+	}
+
+	// Checklist items
+	if err := r.db.Unscoped().
+		Where("inspection_id IN (?) AND (updated_at, id) > (?, ?) AND updated_at <= ?", inspectorInspections, since.UpdatedAt, since.ID, upperBound).
+		Order("updated_at ASC, id ASC").
+		Limit(limit).
+		Find(&changeSet.ChecklistItems).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch checklist changes: %w", err)
+	}
+	if len(changeSet.ChecklistItems) == limit {
+		changeSet.HasMore = true
+		last := changeSet.ChecklistItems[limit-1]
+		r.moveCursorBack(changeSet, SyncCursor{UpdatedAt: last.UpdatedAt, ID: last.ID})
+	}
+
+	// Photos
+	if err := r.db.Unscoped().
+		Where("inspection_id IN (?) AND (updated_at, id) > (?, ?) AND updated_at <= ?", inspectorInspections, since.UpdatedAt, since.ID, upperBound).
+		Order("updated_at ASC, id ASC").
+		Limit(limit).
+		Find(&changeSet.Photos).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch photo changes: %w", err)
+	}
+	if len(changeSet.Photos) == limit {
+		changeSet.HasMore = true
+		last := changeSet.Photos[limit-1]
+		r.moveCursorBack(changeSet, SyncCursor{UpdatedAt: last.UpdatedAt, ID: last.ID})
+	}
+
+	// Application summaries: applications that changed, plus applications behind newly synced inspections
+	changedInspectionApps := make([]uuid.UUID, 0, len(changeSet.Inspections))
+	for _, inspection := range changeSet.Inspections {
+		changedInspectionApps = append(changedInspectionApps, inspection.ApplicationID)
+	}
+
+	appQuery := r.db.Table("applications").
+		Select(`applications.id, applications.plan_number, applications.permit_number, applications.status,
+			applicants.full_name AS applicant_name, stands.stand_number, applications.plan_area, applications.updated_at`).
+		Joins("LEFT JOIN applicants ON applicants.id = applications.applicant_id").
+		Joins("LEFT JOIN stands ON stands.id = applications.stand_id").
+		Where("applications.deleted_at IS NULL").
+		Where("applications.id IN (?)", r.db.Unscoped().Model(&models.Inspection{}).Select("application_id").Where("inspector_id = ?", inspectorID))
+
+	if len(changedInspectionApps) > 0 {
+		appQuery = appQuery.Where("(applications.updated_at > ? AND applications.updated_at <= ?) OR applications.id IN ?", since.UpdatedAt, upperBound, changedInspectionApps)
+	} else {
+		appQuery = appQuery.Where("applications.updated_at > ? AND applications.updated_at <= ?", since.UpdatedAt, upperBound)
+	}
+
+	if err := appQuery.Order("applications.updated_at ASC").Scan(&changeSet.Applications).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch application summaries: %w", err)
+	}
+
+	return changeSet, nil
+}
+
+func (r *inspectionRepository) moveCursorBack(changeSet *SyncChangeSet, boundary SyncCursor) {
+	if boundary.before(changeSet.NextCursor) {
+		changeSet.NextCursor = boundary
+	}
+}
+
+// GetSyncMutationByKey returns the recorded result of a previously pushed mutation, or nil
+func (r *inspectionRepository) GetSyncMutationByKey(idempotencyKey string) (*models.SyncMutation, error) {
+	var mutation models.SyncMutation
+	err := r.db.Where("idempotency_key = ?", idempotencyKey).First(&mutation).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &mutation, nil
+}
+
+// ApplySyncMutation applies a single offline mutation and records its outcome under the
+// idempotency key. Validation problems and version conflicts are recorded (not returned as
+// errors) so that a retry of the same key returns the same answer.
+func (r *inspectionRepository) ApplySyncMutation(
+	tx *gorm.DB,
+	userID uuid.UUID,
+	deviceID string,
+	mutation requests.SyncMutationRequest,
+) (*models.SyncMutation, error) {
+	record := &models.SyncMutation{
+		IdempotencyKey: mutation.IdempotencyKey,
+		UserID:         userID,
+		DeviceID:       deviceID,
+		EntityType:     mutation.EntityType,
+		EntityID:       mutation.EntityID,
+		Operation:      mutation.Operation,
+		Payload:        datatypes.JSON(mutation.Data),
+	}
+	if len(mutation.Data) == 0 {
+		record.Payload = datatypes.JSON("null")
+	}
+
+	var (
+		version int
+		err     error
+	)
+
+	switch mutation.EntityType {
+	case requests.SyncEntityInspection:
+		version, err = r.applyInspectionMutation(tx, userID, mutation)
+	case requests.SyncEntityChecklistItem:
+		version, err = r.applyChecklistItemMutation(tx, userID, mutation)
+	case requests.SyncEntityPhoto:
+		version, err = r.applyPhotoMutation(tx, userID, mutation)
+	default:
+		err = newSyncRejection(fmt.Sprintf("unsupported entity type: %s", mutation.EntityType))
+	}
+
+	record.ServerVersion = version
+	record.Status = models.SyncMutationApplied
+
+	var syncErr *syncMutationError
+	if err != nil {
+		if !errors.As(err, &syncErr) {
+			return nil, err
+		}
+		record.Status = syncErr.status
+		message := syncErr.message
+		record.Message = &message
+	}
+
+	if err := tx.Create(record).Error; err != nil {
+		return nil, fmt.Errorf("failed to record sync mutation: %w", err)
+	}
+
+	return record, nil
+}
+
+// Statuses and results an offline client may set
+var (
+	syncInspectionStatuses = map[models.InspectionStatus]bool{
+		models.InspectionStatusScheduled:  true,
+		models.InspectionStatusInProgress: true,
+		models.InspectionStatusCompleted:  true,
+		models.InspectionStatusCancelled:  true,
+	}
+	syncInspectionOutcomes = map[models.InspectionOutcome]bool{
+		models.InspectionOutcomePassed:      true,
+		models.InspectionOutcomeFailed:      true,
+		models.InspectionOutcomeConditional: true,
+	}
+	syncChecklistResults = map[models.ChecklistItemResult]bool{
+		models.ChecklistResultPending:       true,
+		models.ChecklistResultPass:          true,
+		models.ChecklistResultFail:          true,
+		models.ChecklistResultNotApplicable: true,
+	}
+)
+
+// syncMutationError marks an outcome that is recorded against the idempotency key
+// instead of aborting the push
+type syncMutationError struct {
+	status  models.SyncMutationStatus
+	message string
+}
+
+func (e *syncMutationError) Error() string {
+	return e.message
+}
+
+func newSyncRejection(message string) error {
+	return &syncMutationError{status: models.SyncMutationRejected, message: message}
+}
+
+func newSyncConflict(serverVersion int) error {
+	return &syncMutationError{
+		status:  models.SyncMutationConflict,
+		message: fmt.Sprintf("record was changed on the server (server version %d)", serverVersion),
+	}
+}
+
+func (r *inspectionRepository) getInspectionForInspector(tx *gorm.DB, inspectionID uuid.UUID, inspectorID uuid.UUID) (*models.Inspection, error) {
+	var inspection models.Inspection
+	if err := tx.Where("id = ?", inspectionID).First(&inspection).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, newSyncRejection("inspection not found")
+		}
+		return nil, err
+	}
+	if inspection.InspectorID != inspectorID {
+		return nil, newSyncRejection("inspection is not assigned to this inspector")
+	}
+	return &inspection, nil
+}
+
+func (r *inspectionRepository) applyInspectionMutation(tx *gorm.DB, userID uuid.UUID, mutation requests.SyncMutationRequest) (int, error) {
+	if mutation.Operation != requests.SyncOperationUpsert {
+		return 0, newSyncRejection("inspections can only be updated from the mobile app")
+	}
+
+	inspection, err := r.getInspectionForInspector(tx, mutation.EntityID, userID)
+	if err != nil {
+		return 0, err
+	}
+	if mutation.BaseVersion != nil && *mutation.BaseVersion != inspection.Version {
+		return inspection.Version, newSyncConflict(inspection.Version)
+	}
+
+	var data requests.InspectionSyncData
+	if err := json.Unmarshal(mutation.Data, &data); err != nil {
+		return inspection.Version, newSyncRejection("invalid inspection data")
+	}
+
+	previousStatus := inspection.Status
+
+	if data.Status != nil {
+		status := models.InspectionStatus(*data.Status)
+		if !syncInspectionStatuses[status] {
+			return inspection.Version, newSyncRejection(fmt.Sprintf("invalid inspection status: %s", *data.Status))
+		}
+		inspection.Status = status
+	}
+	if data.Outcome != nil {
+		outcome := models.InspectionOutcome(*data.Outcome)
+		if !syncInspectionOutcomes[outcome] {
+			return inspection.Version, newSyncRejection(fmt.Sprintf("invalid inspection outcome: %s", *data.Outcome))
+		}
+		inspection.Outcome = &outcome
+	}
+	if data.StartedAt != nil {
+		inspection.StartedAt = data.StartedAt
+	}
+	if data.CompletedAt != nil {
+		inspection.CompletedAt = data.CompletedAt
+	}
+	if data.Notes != nil {
+		inspection.Notes = data.Notes
+	}
+	if inspection.Latitude, err = parseCoordinate(data.Latitude, inspection.Latitude); err != nil {
+		return inspection.Version, newSyncRejection("invalid latitude")
+	}
+	if inspection.Longitude, err = parseCoordinate(data.Longitude, inspection.Longitude); err != nil {
+		return inspection.Version, newSyncRejection("invalid longitude")
+	}
+
+	updatedBy := userID.String()
+	inspection.Version++
+	inspection.ClientUpdatedAt = mutation.ClientUpdatedAt
+	inspection.UpdatedBy = &updatedBy
+
+	if err := tx.Save(inspection).Error; err != nil {
+		return 0, fmt.Errorf("failed to update inspection: %w", err)
+	}
+	if err := r.syncInspectionInvoice(tx, inspection, previousStatus, updatedBy); err != nil {
+		return 0, err
+	}
+	return inspection.Version, nil
+}
+
+func (r *inspectionRepository) applyChecklistItemMutation(tx *gorm.DB, userID uuid.UUID, mutation requests.SyncMutationRequest) (int, error) {
+	var item models.InspectionChecklistItem
+	err := tx.Unscoped().Where("id = ?", mutation.EntityID).First(&item).Error
+	exists := err == nil
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+	// A deleted item keeps its ID, so it can be neither recreated nor deleted again
+	if exists && item.DeletedAt.Valid {
+		if mutation.Operation == requests.SyncOperationDelete {
+			return item.Version, nil
+		}
+		return item.Version, newSyncRejection("checklist item was deleted")
+	}
+
+	updatedBy := userID.String()
+
+	if mutation.Operation == requests.SyncOperationDelete {
+		if !exists {
+			// Already gone - deleting twice is not an error for an offline client
+			return 0, nil
+		}
+		if _, err := r.getInspectionForInspector(tx, item.InspectionID, userID); err != nil {
+			return item.Version, err
+		}
+		if mutation.BaseVersion != nil && *mutation.BaseVersion != item.Version {
+			return item.Version, newSyncConflict(item.Version)
+		}
+		item.Version++
+		item.UpdatedBy = &updatedBy
+		if err := tx.Save(&item).Error; err != nil {
+			return 0, fmt.Errorf("failed to update checklist item: %w", err)
+		}
+		if err := tx.Delete(&item).Error; err != nil {
+			return 0, fmt.Errorf("failed to delete checklist item: %w", err)
+		}
+		return item.Version, nil
+	}
+
+	if mutation.Operation != requests.SyncOperationUpsert {
+		return 0, newSyncRejection(fmt.Sprintf("unsupported operation: %s", mutation.Operation))
+	}
+
+	var data requests.ChecklistItemSyncData
+	if err := json.Unmarshal(mutation.Data, &data); err != nil {
+		return 0, newSyncRejection("invalid checklist item data")
+	}
+
+	if !exists {
+		if data.InspectionID == nil || data.Code == nil || data.Label == nil {
+			return 0, newSyncRejection("inspection_id, code and label are required for new checklist items")
+		}
+		if _, err := r.getInspectionForInspector(tx, *data.InspectionID, userID); err != nil {
+			return 0, err
+		}
+		item = models.InspectionChecklistItem{
+			ID:           mutation.EntityID,
+			InspectionID: *data.InspectionID,
+			Code:         *data.Code,
+			Label:        *data.Label,
+			Result:       models.ChecklistResultPending,
+			Version:      1,
+			CreatedBy:    updatedBy,
+		}
+	} else {
+		if _, err := r.getInspectionForInspector(tx, item.InspectionID, userID); err != nil {
+			return item.Version, err
+		}
+		if mutation.BaseVersion != nil && *mutation.BaseVersion != item.Version {
+			return item.Version, newSyncConflict(item.Version)
+		}
+		item.Version++
+		item.UpdatedBy = &updatedBy
+		if data.Code != nil {
+			item.Code = *data.Code
+		}
+		if data.Label != nil {
+			item.Label = *data.Label
+		}
+	}
+
+	if data.Result != nil {
+		result := models.ChecklistItemResult(*data.Result)
+		if !syncChecklistResults[result] {
+			return item.Version, newSyncRejection(fmt.Sprintf("invalid checklist result: %s", *data.Result))
+		}
+		item.Result = result
+	}
+	if data.Notes != nil {
+		item.Notes = data.Notes
+	}
+	if data.SortOrder != nil {
+		item.SortOrder = *data.SortOrder
+	}
+	item.ClientUpdatedAt = mutation.ClientUpdatedAt
+
+	if err := tx.Save(&item).Error; err != nil {
+		return 0, fmt.Errorf("failed to save checklist item: %w", err)
+	}
+	return item.Version, nil
+}
+
+func (r *inspectionRepository) applyPhotoMutation(tx *gorm.DB, userID uuid.UUID, mutation requests.SyncMutationRequest) (int, error) {
+	var photo models.InspectionPhoto
+	err := tx.Unscoped().Where("id = ?", mutation.EntityID).First(&photo).Error
+	exists := err == nil
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+	// A deleted photo keeps its ID, so it can be neither recreated nor deleted again
+	if exists && photo.DeletedAt.Valid {
+		if mutation.Operation == requests.SyncOperationDelete {
+			return photo.Version, nil
+		}
+		return photo.Version, newSyncRejection("photo was deleted")
+	}
+
+	updatedBy := userID.String()
+
+	if mutation.Operation == requests.SyncOperationDelete {
+		if !exists {
+			return 0, nil
+		}
+		if _, err := r.getInspectionForInspector(tx, photo.InspectionID, userID); err != nil {
+			return photo.Version, err
+		}
+		photo.Version++
+		photo.UpdatedBy = &updatedBy
+		if err := tx.Save(&photo).Error; err != nil {
+			return 0, fmt.Errorf("failed to update photo: %w", err)
+		}
+		if err := tx.Delete(&photo).Error; err != nil {
+			return 0, fmt.Errorf("failed to delete photo: %w", err)
+		}
+		if err := tx.Where("inspection_photo_id = ?", photo.ID).Delete(&models.StandPhoto{}).Error; err != nil {
+			return 0, fmt.Errorf("failed to remove photo from stand gallery: %w", err)
+		}
+		if err := r.refreshInspectionPhotoVerification(tx, photo.InspectionID); err != nil {
+			return 0, err
+		}
+		return photo.Version, nil
+	}
+
+	if mutation.Operation != requests.SyncOperationUpsert {
+		return 0, newSyncRejection(fmt.Sprintf("unsupported operation: %s", mutation.Operation))
+	}
+
+	var data requests.PhotoSyncData
+	if err := json.Unmarshal(mutation.Data, &data); err != nil {
+		return 0, newSyncRejection("invalid photo data")
+	}
+
+	if !exists {
+		if data.InspectionID == nil {
+			return 0, newSyncRejection("inspection_id is required for new photos")
+		}
+		if _, err := r.getInspectionForInspector(tx, *data.InspectionID, userID); err != nil {
+			return 0, err
+		}
+		photo = models.InspectionPhoto{
+			ID:           mutation.EntityID,
+			InspectionID: *data.InspectionID,
+			Version:      1,
+			CreatedBy:    updatedBy,
+		}
+	} else {
+		if _, err := r.getInspectionForInspector(tx, photo.InspectionID, userID); err != nil {
+			return photo.Version, err
+		}
+		if mutation.BaseVersion != nil && *mutation.BaseVersion != photo.Version {
+			return photo.Version, newSyncConflict(photo.Version)
+		}
+		photo.Version++
+		photo.UpdatedBy = &updatedBy
+	}
+
+	if data.Caption != nil {
+		photo.Caption = data.Caption
+	}
+	if data.TakenAt != nil {
+		photo.TakenAt = data.TakenAt
+	}
+	if photo.Latitude, err = parseCoordinate(data.Latitude, photo.Latitude); err != nil {
+		return photo.Version, newSyncRejection("invalid latitude")
+	}
+	if photo.Longitude, err = parseCoordinate(data.Longitude, photo.Longitude); err != nil {
+		return photo.Version, newSyncRejection("invalid longitude")
+	}
+	if data.AddToStandGallery != nil {
+		photo.AddToStandGallery = *data.AddToStandGallery
+	}
+	photo.ClientUpdatedAt = mutation.ClientUpdatedAt
+
+	if err := tx.Save(&photo).Error; err != nil {
+		return 0, fmt.Errorf("failed to save photo: %w", err)
+	}
+	if err := r.syncStandGalleryPhoto(tx, &photo); err != nil {
+		return 0, err
+	}
+	return photo.Version, nil
+}
+
+// parseCoordinate keeps the current value when the client did not send one
+func parseCoordinate(value *string, current *decimal.Decimal) (*decimal.Decimal, error) {
+	if value == nil {
+		return current, nil
+	}
+	parsed, err := decimal.NewFromString(*value)
+	if err != nil {
+		return current, err
+	}
+	return &parsed, nil
+}
+
+// GetInspectionPhotoForInspector loads a photo and checks it belongs to one of the inspector's inspections
+func (r *inspectionRepository) GetInspectionPhotoForInspector(photoID uuid.UUID, inspectorID uuid.UUID) (*models.InspectionPhoto, error) {
+	var photo models.InspectionPhoto
+	if err := r.db.Where("id = ?", photoID).First(&photo).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("photo not found")
+		}
+		return nil, err
+	}
+
+	if _, err := r.getInspectionForInspector(r.db, photo.InspectionID, inspectorID); err != nil {
+		var syncErr *syncMutationError
+		if errors.As(err, &syncErr) {
+			return nil, errors.New("photo not accessible")
+		}
+		return nil, err
+	}
+
+	return &photo, nil
+}
+
+// AttachInspectionPhotoFile stores the uploaded file location against a photo record, along
+// with the verification verdict the caller set on it
+func (r *inspectionRepository) AttachInspectionPhotoFile(
+	tx *gorm.DB,
+	photo *models.InspectionPhoto,
+	filePath string,
+	mimeType string,
+	fileSize int64,
+	updatedBy string,
+) (*models.InspectionPhoto, error) {
+	now := time.Now()
+	photo.FilePath = &filePath
+	photo.MimeType = &mimeType
+	photo.FileSize = &fileSize
+	photo.UploadedAt = &now
+	photo.UpdatedBy = &updatedBy
+	photo.Version++
+
+	if err := tx.Save(photo).Error; err != nil {
+		return nil, fmt.Errorf("failed to attach photo file: %w", err)
+	}
+	if err := r.syncStandGalleryPhoto(tx, photo); err != nil {
+		return nil, err
+	}
+	if err := r.refreshInspectionPhotoVerification(tx, photo.InspectionID); err != nil {
+		return nil, err
+	}
+	return photo, nil
+}
+
+// syncStandGalleryPhoto keeps the stand gallery entry of an inspection photo in step with the
+// photo. An entry exists only while the inspector wants it shared, the file has been uploaded
+// and a supervisor has not rejected it.
+func (r *inspectionRepository) syncStandGalleryPhoto(tx *gorm.DB, photo *models.InspectionPhoto) error {
+	if !photo.AddToStandGallery || photo.FilePath == nil || photo.VerificationStatus == models.PhotoVerificationRejected {
+		if err := tx.Where("inspection_photo_id = ?", photo.ID).Delete(&models.StandPhoto{}).Error; err != nil {
+			return fmt.Errorf("failed to remove photo from stand gallery: %w", err)
+		}
+		return nil
+	}
+
+	var application models.Application
+	err := tx.Select("applications.id", "applications.stand_id").
+		Joins("JOIN inspections ON inspections.application_id = applications.id").
+		Where("inspections.id = ?", photo.InspectionID).
+		First(&application).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to load inspection stand: %w", err)
+	}
+	if application.StandID == nil {
+		// Applications without a stand have no gallery to add to
+		return nil
+	}
+
+	var galleryPhoto models.StandPhoto
+	err = tx.Unscoped().Where("inspection_photo_id = ?", photo.ID).First(&galleryPhoto).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to load stand gallery photo: %w", err)
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		galleryPhoto = models.StandPhoto{
+			Source:            models.StandPhotoSourceInspection,
+			InspectionPhotoID: &photo.ID,
+			CreatedBy:         photo.CreatedBy,
+		}
+	} else {
+		galleryPhoto.DeletedAt = gorm.DeletedAt{}
+		galleryPhoto.UpdatedBy = photo.UpdatedBy
+	}
+
+	galleryPhoto.StandID = *application.StandID
+	galleryPhoto.FilePath = *photo.FilePath
+	if photo.MimeType != nil {
+		galleryPhoto.MimeType = *photo.MimeType
+	}
+	if photo.FileSize != nil {
+		galleryPhoto.FileSize = *photo.FileSize
+	}
+	galleryPhoto.Caption = photo.Caption
+	galleryPhoto.Latitude = photo.Latitude
+	galleryPhoto.Longitude = photo.Longitude
+	galleryPhoto.TakenAt = photo.TakenAt
+	galleryPhoto.MetadataScrubbedAt = photo.MetadataScrubbedAt
+
+	if err := tx.Unscoped().Save(&galleryPhoto).Error; err != nil {
+		return fmt.Errorf("failed to save stand gallery photo: %w", err)
+	}
+	return nil
+}
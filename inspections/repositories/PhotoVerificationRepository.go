@@ -0,0 +1,144 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"town-planning-backend/db/models"
+	"town-planning-backend/inspections/services"
+	"town-planning-backend/utils/pagination"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// photoVerificationRank orders verdicts from least to most in need of attention, for rolling
+// them up onto the inspection
+var photoVerificationRank = map[models.PhotoVerificationStatus]int{
+	models.PhotoVerificationPending:      0,
+	models.PhotoVerificationVerified:     1,
+	models.PhotoVerificationAccepted:     2,
+	models.PhotoVerificationUnverifiable: 3,
+	models.PhotoVerificationFlagged:      4,
+	models.PhotoVerificationRejected:     5,
+}
+
+// GetPhotoVerificationSite loads the inspection a photo is checked against, with the position
+// of the application's stand or, failing that, of the inspection
+func (r *inspectionRepository) GetPhotoVerificationSite(inspectionID uuid.UUID) (*services.PhotoSite, error) {
+	var inspection models.Inspection
+	if err := r.db.Preload("Application.Stand").Where("id = ?", inspectionID).First(&inspection).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("inspection not found")
+		}
+		return nil, fmt.Errorf("failed to load inspection: %w", err)
+	}
+
+	site := &services.PhotoSite{
+		Inspection: &inspection,
+		Latitude:   inspection.Latitude,
+		Longitude:  inspection.Longitude,
+	}
+	if inspection.Application != nil && inspection.Application.Stand != nil {
+		stand := inspection.Application.Stand
+		if stand.Latitude != nil && stand.Longitude != nil {
+			site.Latitude = stand.Latitude
+			site.Longitude = stand.Longitude
+		}
+	}
+	return site, nil
+}
+
+// refreshInspectionPhotoVerification rolls the photos' verdicts up onto the inspection. The
+// verdict is not editable by the app, so it is written without bumping the sync version.
+func (r *inspectionRepository) refreshInspectionPhotoVerification(tx *gorm.DB, inspectionID uuid.UUID) error {
+	var statuses []models.PhotoVerificationStatus
+	if err := tx.Model(&models.InspectionPhoto{}).
+		Where("inspection_id = ? AND file_path IS NOT NULL", inspectionID).
+		Pluck("verification_status", &statuses).Error; err != nil {
+		return fmt.Errorf("failed to load photo verdicts: %w", err)
+	}
+
+	verdict := models.PhotoVerificationPending
+	flagged := 0
+	for _, status := range statuses {
+		if photoVerificationRank[status] > photoVerificationRank[verdict] {
+			verdict = status
+		}
+		if status == models.PhotoVerificationFlagged {
+			flagged++
+		}
+	}
+
+	if err := tx.Model(&models.Inspection{}).Where("id = ?", inspectionID).UpdateColumns(map[string]interface{}{
+		"photo_verification":   verdict,
+		"flagged_photos_count": flagged,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update inspection photo verification: %w", err)
+	}
+	return nil
+}
+
+// GetPhotosForReview lists uploaded photos with the given verdict, oldest upload first so the
+// longest waiting are reviewed first
+func (r *inspectionRepository) GetPhotosForReview(status models.PhotoVerificationStatus, page pagination.Request) ([]models.InspectionPhoto, int64, error) {
+	query := r.db.Model(&models.InspectionPhoto{}).
+		Where("verification_status = ? AND file_path IS NOT NULL", status)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count photos: %w", err)
+	}
+
+	var photos []models.InspectionPhoto
+	if err := page.Window(query, "inspection_photos", "inspection_photos.uploaded_at ASC").
+		Find(&photos).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch photos: %w", err)
+	}
+	return photos, total, nil
+}
+
+// ReviewInspectionPhoto records a supervisor's decision on a flagged photo. Rejected photos are
+// taken out of the stand gallery.
+func (r *inspectionRepository) ReviewInspectionPhoto(
+	tx *gorm.DB,
+	photoID uuid.UUID,
+	accept bool,
+	notes *string,
+	reviewerID uuid.UUID,
+) (*models.InspectionPhoto, error) {
+	var photo models.InspectionPhoto
+	if err := tx.Where("id = ?", photoID).First(&photo).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("photo not found")
+		}
+		return nil, err
+	}
+	if photo.VerificationStatus != models.PhotoVerificationFlagged &&
+		photo.VerificationStatus != models.PhotoVerificationUnverifiable {
+		return nil, errors.New("photo is not awaiting review")
+	}
+
+	now := time.Now()
+	updatedBy := reviewerID.String()
+	photo.VerificationStatus = models.PhotoVerificationRejected
+	if accept {
+		photo.VerificationStatus = models.PhotoVerificationAccepted
+	}
+	photo.ReviewedBy = &reviewerID
+	photo.ReviewedAt = &now
+	photo.ReviewNotes = notes
+	photo.UpdatedBy = &updatedBy
+	photo.Version++
+
+	if err := tx.Save(&photo).Error; err != nil {
+		return nil, fmt.Errorf("failed to save photo review: %w", err)
+	}
+	if err := r.syncStandGalleryPhoto(tx, &photo); err != nil {
+		return nil, err
+	}
+	if err := r.refreshInspectionPhotoVerification(tx, photo.InspectionID); err != nil {
+		return nil, err
+	}
+	return &photo, nil
+}
@@ -0,0 +1,40 @@
+package requests
+
+import (
+	"time"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// ScheduleInspectionRequest books a site visit for an inspector
+type ScheduleInspectionRequest struct {
+	ApplicationID  uuid.UUID  `json:"application_id"`
+	InspectorID    uuid.UUID  `json:"inspector_id"`
+	InspectionType string     `json:"inspection_type"`
+	ScheduledDate  *time.Time `json:"scheduled_date"`
+	Notes          *string    `json:"notes"`
+}
+
+// RecordInspectionInvoicePaymentRequest records payment of an inspection invoice, which is paid
+// in full
+type RecordInspectionInvoicePaymentRequest struct {
+	Amount            decimal.Decimal      `json:"amount"`
+	PaymentMethod     models.PaymentMethod `json:"payment_method"`
+	ReceiptNumber     string               `json:"receipt_number"`
+	PaymentDate       *time.Time           `json:"payment_date"`
+	ExternalReference *string              `json:"external_reference"`
+	BankAccountID     *uuid.UUID           `json:"bank_account_id"`
+	Notes             string               `json:"notes"`
+}
+
+// WaiveInspectionInvoiceRequest writes off an unpaid inspection invoice
+type WaiveInspectionInvoiceRequest struct {
+	Reason string `json:"reason"`
+}
+
+// IssueComplianceCertificateRequest certifies an application's completed development
+type IssueComplianceCertificateRequest struct {
+	Notes *string `json:"notes"`
+}
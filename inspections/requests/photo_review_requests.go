@@ -0,0 +1,7 @@
+package requests
+
+// PhotoReviewRequest is a supervisor's decision on a photo flagged by verification
+type PhotoReviewRequest struct {
+	Accept *bool   `json:"accept"`
+	Notes  *string `json:"notes"`
+}
@@ -0,0 +1,82 @@
+package requests
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Entity types understood by the sync push endpoint
+const (
+	SyncEntityInspection    = "inspection"
+	SyncEntityChecklistItem = "checklist_item"
+	SyncEntityPhoto         = "photo"
+)
+
+// Operations understood by the sync push endpoint
+const (
+	SyncOperationUpsert = "upsert"
+	SyncOperationDelete = "delete"
+)
+
+// SyncPushRequest is a batch of mutations queued by the mobile app while offline
+type SyncPushRequest struct {
+	DeviceID  string                `json:"device_id"`
+	Mutations []SyncMutationRequest `json:"mutations"`
+}
+
+// SyncMutationRequest describes a single offline change.
+// BaseVersion is the server version the client last saw; a mismatch is reported as a conflict.
+type SyncMutationRequest struct {
+	IdempotencyKey  string          `json:"idempotency_key"`
+	EntityType      string          `json:"entity_type"`
+	Operation       string          `json:"operation"`
+	EntityID        uuid.UUID       `json:"entity_id"`
+	BaseVersion     *int            `json:"base_version,omitempty"`
+	ClientUpdatedAt *time.Time      `json:"client_updated_at,omitempty"`
+	Data            json.RawMessage `json:"data,omitempty"`
+}
+
+// InspectionSyncData holds the mutable inspection fields an inspector may change offline
+type InspectionSyncData struct {
+	Status      *string    `json:"status,omitempty"`
+	Outcome     *string    `json:"outcome,omitempty"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	Notes       *string    `json:"notes,omitempty"`
+	Latitude    *string    `json:"latitude,omitempty"`
+	Longitude   *string    `json:"longitude,omitempty"`
+}
+
+// ChecklistItemSyncData holds the checklist fields an inspector may create or change offline
+type ChecklistItemSyncData struct {
+	InspectionID *uuid.UUID `json:"inspection_id,omitempty"`
+	Code         *string    `json:"code,omitempty"`
+	Label        *string    `json:"label,omitempty"`
+	Result       *string    `json:"result,omitempty"`
+	Notes        *string    `json:"notes,omitempty"`
+	SortOrder    *int       `json:"sort_order,omitempty"`
+}
+
+// PhotoSyncData holds photo metadata captured offline; the binary is uploaded separately
+type PhotoSyncData struct {
+	InspectionID *uuid.UUID `json:"inspection_id,omitempty"`
+	Caption      *string    `json:"caption,omitempty"`
+	Latitude     *string    `json:"latitude,omitempty"`
+	Longitude    *string    `json:"longitude,omitempty"`
+	TakenAt      *time.Time `json:"taken_at,omitempty"`
+
+	AddToStandGallery *bool `json:"add_to_stand_gallery,omitempty"`
+}
+
+// SyncMutationResult is returned for every mutation in a push, in request order
+type SyncMutationResult struct {
+	IdempotencyKey string    `json:"idempotency_key"`
+	EntityType     string    `json:"entity_type"`
+	EntityID       uuid.UUID `json:"entity_id"`
+	Status         string    `json:"status"`
+	ServerVersion  int       `json:"server_version"`
+	Message        *string   `json:"message,omitempty"`
+	Replayed       bool      `json:"replayed"`
+}
@@ -0,0 +1,47 @@
+package routes
+
+import (
+	"town-planning-backend/inspections/controllers"
+	"town-planning-backend/inspections/repositories"
+	"town-planning-backend/inspections/services"
+	"town-planning-backend/middleware"
+	user_repository "town-planning-backend/users/repositories"
+	"town-planning-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+func InspectionRouterInit(
+	app *fiber.App,
+	db *gorm.DB,
+	inspectionRepository repositories.InspectionRepository,
+	fileStorage utils.FileStorage,
+	userRepo user_repository.UserRepository,
+) {
+	inspectionController := &controllers.InspectionController{
+		InspectionRepo: inspectionRepository,
+		DB:             db,
+		FileStorage:    fileStorage,
+		PhotoPolicy:    services.PhotoVerificationPolicyFromEnv(),
+	}
+
+	// Offline sync for the inspector mobile app
+	syncRoutes := app.Group("/api/v1/sync")
+	syncRoutes.Get("/pull", inspectionController.SyncPullController)
+	syncRoutes.Post("/push", inspectionController.SyncPushController)
+	syncRoutes.Post("/photos/:id/file", middleware.Upload(), inspectionController.UploadInspectionPhotoController)
+
+	// Supervisor review of photos flagged by verification
+	photoRoutes := app.Group("/api/v1/inspections/photos", middleware.RequirePermission(userRepo, "inspection.review_photos"))
+	photoRoutes.Get("/review", inspectionController.GetPhotosForReviewController)
+	photoRoutes.Post("/:id/review", inspectionController.ReviewInspectionPhotoController)
+
+	// Scheduling, per-visit inspection invoices and compliance certificates
+	inspectionRoutes := app.Group("/api/v1/inspections")
+	inspectionRoutes.Post("/", middleware.RequirePermission(userRepo, "inspection.schedule"), inspectionController.ScheduleInspectionController)
+	inspectionRoutes.Get("/invoices", middleware.RequirePermission(userRepo, "payment.verify"), inspectionController.GetInspectionInvoicesController)
+	inspectionRoutes.Post("/invoices/:id/payments", middleware.RequirePermission(userRepo, "payment.process"), inspectionController.RecordInspectionInvoicePaymentController)
+	inspectionRoutes.Post("/invoices/:id/waive", middleware.RequirePermission(userRepo, "payment.reconcile"), inspectionController.WaiveInspectionInvoiceController)
+	inspectionRoutes.Post("/applications/:id/compliance-certificate", middleware.RequirePermission(userRepo, "permit.manage"), inspectionController.IssueComplianceCertificateController)
+}
@@ -0,0 +1,27 @@
+package services
+
+import (
+	"town-planning-backend/db/models"
+	"town-planning-backend/settings"
+)
+
+// InspectionInvoiceTrigger returns the point at which inspections are invoiced per visit, or
+// nil when inspection fees are billed through the tariff only
+func InspectionInvoiceTrigger() *models.InspectionInvoiceTrigger {
+	var trigger models.InspectionInvoiceTrigger
+	switch settings.String(settings.InspectionInvoicing) {
+	case settings.InspectionInvoicingOnSchedule:
+		trigger = models.InspectionInvoiceOnSchedule
+	case settings.InspectionInvoicingOnCompletion:
+		trigger = models.InspectionInvoiceOnCompletion
+	default:
+		return nil
+	}
+	return &trigger
+}
+
+// ShouldInvoiceInspection reports whether an inspection reaching the given point is invoiced
+func ShouldInvoiceInspection(at models.InspectionInvoiceTrigger) bool {
+	trigger := InspectionInvoiceTrigger()
+	return trigger != nil && *trigger == at
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+)
+
+const (
+	defaultPhotoMaxDistanceMeters = 250
+	defaultPhotoTimeToleranceHrs  = 12
+
+	earthRadiusMeters = 6371000
+)
+
+// PhotoVerificationPolicy sets how far from the site and how far outside the inspection a photo
+// may have been taken before it is flagged for a supervisor
+type PhotoVerificationPolicy struct {
+	MaxDistanceMeters float64
+	TimeTolerance     time.Duration
+}
+
+// PhotoVerificationPolicyFromEnv reads INSPECTION_PHOTO_MAX_DISTANCE_M (default 250) and
+// INSPECTION_PHOTO_TIME_TOLERANCE_HOURS (default 12)
+func PhotoVerificationPolicyFromEnv() PhotoVerificationPolicy {
+	return PhotoVerificationPolicy{
+		MaxDistanceMeters: float64(positiveIntEnv("INSPECTION_PHOTO_MAX_DISTANCE_M", defaultPhotoMaxDistanceMeters)),
+		TimeTolerance:     time.Duration(positiveIntEnv("INSPECTION_PHOTO_TIME_TOLERANCE_HOURS", defaultPhotoTimeToleranceHrs)) * time.Hour,
+	}
+}
+
+func positiveIntEnv(variable string, fallback int) int {
+	raw := os.Getenv(variable)
+	if raw == "" {
+		return fallback
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value <= 0 {
+		config.Logger.Warn("Invalid setting, using default",
+			zap.String("variable", variable),
+			zap.String("value", raw),
+			zap.Int("default", fallback))
+		return fallback
+	}
+	return value
+}
+
+// PhotoEvidence is what the uploaded file itself says about where and when it was taken
+type PhotoEvidence struct {
+	CapturedAt *time.Time
+	Latitude   *float64
+	Longitude  *float64
+	UploadedAt time.Time
+}
+
+// PhotoSite is where and when the photo is expected to have been taken. The position is the
+// stand's when it has one, otherwise the one recorded on the inspection.
+type PhotoSite struct {
+	Inspection *models.Inspection
+	Latitude   *decimal.Decimal
+	Longitude  *decimal.Decimal
+}
+
+// PhotoVerdict is the outcome of checking a photo against its inspection
+type PhotoVerdict struct {
+	Status         models.PhotoVerificationStatus
+	Reasons        []string
+	DistanceMeters *float64
+}
+
+// Verify checks the embedded capture time against the inspection's window and the embedded
+// position against the site. The photo is FLAGGED when any check fails, UNVERIFIABLE when a
+// check could not be made, and VERIFIED otherwise. The values the app reported for the photo
+// are also compared with the embedded ones, since a mismatch means the app data was edited.
+func (p PhotoVerificationPolicy) Verify(photo *models.InspectionPhoto, evidence PhotoEvidence, site PhotoSite) PhotoVerdict {
+	verdict := PhotoVerdict{Reasons: []string{}}
+	flagged, incomplete := false, false
+
+	if evidence.CapturedAt == nil {
+		incomplete = true
+		verdict.Reasons = append(verdict.Reasons, "photo has no embedded capture time")
+	} else {
+		start, end := p.captureWindow(site.Inspection, evidence.UploadedAt)
+		captured := *evidence.CapturedAt
+		switch {
+		case captured.Before(start):
+			flagged = true
+			verdict.Reasons = append(verdict.Reasons, fmt.Sprintf("captured %s before the inspection window", roundDuration(start.Sub(captured))))
+		case captured.After(end):
+			flagged = true
+			verdict.Reasons = append(verdict.Reasons, fmt.Sprintf("captured %s after the inspection window", roundDuration(captured.Sub(end))))
+		}
+
+		if photo.TakenAt != nil && absDuration(photo.TakenAt.Sub(captured)) > p.TimeTolerance {
+			flagged = true
+			verdict.Reasons = append(verdict.Reasons, "reported capture time does not match the embedded one")
+		}
+	}
+
+	if evidence.Latitude == nil || evidence.Longitude == nil {
+		incomplete = true
+		verdict.Reasons = append(verdict.Reasons, "photo has no embedded GPS position")
+	} else {
+		if site.Latitude == nil || site.Longitude == nil {
+			incomplete = true
+			verdict.Reasons = append(verdict.Reasons, "site has no recorded position to compare with")
+		} else {
+			distance := distanceMeters(*evidence.Latitude, *evidence.Longitude, site.Latitude.InexactFloat64(), site.Longitude.InexactFloat64())
+			verdict.DistanceMeters = &distance
+			if distance > p.MaxDistanceMeters {
+				flagged = true
+				verdict.Reasons = append(verdict.Reasons, fmt.Sprintf("captured %.0f m from the site, more than the %.0f m allowed", distance, p.MaxDistanceMeters))
+			}
+		}
+
+		if photo.Latitude != nil && photo.Longitude != nil {
+			reported := distanceMeters(*evidence.Latitude, *evidence.Longitude, photo.Latitude.InexactFloat64(), photo.Longitude.InexactFloat64())
+			if reported > p.MaxDistanceMeters {
+				flagged = true
+				verdict.Reasons = append(verdict.Reasons, "reported position does not match the embedded one")
+			}
+		}
+	}
+
+	switch {
+	case flagged:
+		verdict.Status = models.PhotoVerificationFlagged
+	case incomplete:
+		verdict.Status = models.PhotoVerificationUnverifiable
+	default:
+		verdict.Status = models.PhotoVerificationVerified
+	}
+	return verdict
+}
+
+// captureWindow runs from the start of the inspection (or of its scheduled day) to its
+// completion, or to the upload while it is still open, widened by the tolerance on both sides
+// for clock drift and photos taken just before or after the visit
+func (p PhotoVerificationPolicy) captureWindow(inspection *models.Inspection, uploadedAt time.Time) (time.Time, time.Time) {
+	start := inspection.CreatedAt
+	switch {
+	case inspection.StartedAt != nil:
+		start = *inspection.StartedAt
+	case inspection.ScheduledDate != nil:
+		scheduled := *inspection.ScheduledDate
+		start = time.Date(scheduled.Year(), scheduled.Month(), scheduled.Day(), 0, 0, 0, 0, scheduled.Location())
+	}
+
+	end := uploadedAt
+	if inspection.CompletedAt != nil && inspection.CompletedAt.Before(end) {
+		end = *inspection.CompletedAt
+	}
+	return start.Add(-p.TimeTolerance), end.Add(p.TimeTolerance)
+}
+
+// distanceMeters is the great-circle distance between two points
+func distanceMeters(lat1, lon1, lat2, lon2 float64) float64 {
+	toRadians := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+	dLat := toRadians(lat2 - lat1)
+	dLon := toRadians(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+func roundDuration(d time.Duration) time.Duration {
+	return d.Round(time.Minute)
+}
+
+// Apply records the verdict and the embedded values it was based on against the photo. Any
+// earlier supervisor review is cleared, since it was about a different file.
+func (v PhotoVerdict) Apply(photo *models.InspectionPhoto, evidence PhotoEvidence) error {
+	reasons, err := json.Marshal(v.Reasons)
+	if err != nil {
+		return err
+	}
+
+	photo.VerificationStatus = v.Status
+	photo.VerificationReasons = datatypes.JSON(reasons)
+	photo.ExifCapturedAt = evidence.CapturedAt
+	photo.ExifLatitude = optionalDecimal(evidence.Latitude)
+	photo.ExifLongitude = optionalDecimal(evidence.Longitude)
+	photo.DistanceFromSiteMeters = optionalDecimal(v.DistanceMeters)
+	photo.VerifiedAt = &evidence.UploadedAt
+	photo.ReviewedBy = nil
+	photo.ReviewedAt = nil
+	photo.ReviewNotes = nil
+	return nil
+}
+
+func optionalDecimal(value *float64) *decimal.Decimal {
+	if value == nil {
+		return nil
+	}
+	d := decimal.NewFromFloat(*value)
+	return &d
+}
@@ -2,6 +2,8 @@ package bootstrap
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	applicants_repositories "town-planning-backend/applicants/repositories"
 	bleveRepositories "town-planning-backend/bleve/repositories"
@@ -12,6 +14,7 @@ import (
 	"go.uber.org/zap"
 )
 
+// IndexBleveData rebuilds every Bleve index at startup, stopping the process if it cannot
 func IndexBleveData(
 	ctx context.Context,
 	userRepo users_repositories.UserRepository,
@@ -19,38 +22,63 @@ func IndexBleveData(
 	standRepo stands_repositories.StandRepository,
 	bleveRepo bleveRepositories.BleveRepositoryInterface,
 ) {
+	if err := ReindexBleveData(ctx, userRepo, applicantRepo, standRepo, bleveRepo); err != nil {
+		log.Fatalf("Error re-indexing Bleve data: %v", err)
+	}
+}
 
+// ReindexBleveData deletes every Bleve index and indexes users, applicants, projects and stands
+// again from the database. Each entity is indexed even if an earlier one failed; the failures
+// are returned together.
+func ReindexBleveData(
+	ctx context.Context,
+	userRepo users_repositories.UserRepository,
+	applicantRepo applicants_repositories.ApplicantRepository,
+	standRepo stands_repositories.StandRepository,
+	bleveRepo bleveRepositories.BleveRepositoryInterface,
+) error {
 	// Delete All Indexes first
-	err := bleveRepo.DeleteAllIndices(context.Background())
-	if err != nil {
-		log.Fatalf("Error deleting all indices: %v", err)
+	if err := bleveRepo.DeleteAllIndices(ctx); err != nil {
+		return fmt.Errorf("failed to delete indices: %w", err)
 	}
 
+	var failures []error
+
 	// Index Users
 	if users, err := userRepo.GetAllUsers(); err != nil {
 		config.Logger.Error("Error fetching users for Bleve indexing", zap.Error(err))
+		failures = append(failures, fmt.Errorf("users: %w", err))
 	} else if err := bleveRepo.IndexExistingUsers(users); err != nil {
 		config.Logger.Error("Failed to index users into Bleve", zap.Error(err))
+		failures = append(failures, fmt.Errorf("users: %w", err))
 	}
 
 	// Index Applicants
 	if applicants, err := applicantRepo.GetAllApplicants(); err != nil {
 		config.Logger.Error("Error fetching applicants for Bleve indexing", zap.Error(err))
+		failures = append(failures, fmt.Errorf("applicants: %w", err))
 	} else if err := bleveRepo.IndexExistingApplicants(applicants); err != nil {
 		config.Logger.Error("Failed to index applicants into Bleve", zap.Error(err))
+		failures = append(failures, fmt.Errorf("applicants: %w", err))
 	}
 
 	// Index Projects
 	if projects, err := standRepo.GetAllProjects(); err != nil {
 		config.Logger.Error("Error fetching projects for Bleve indexing", zap.Error(err))
+		failures = append(failures, fmt.Errorf("projects: %w", err))
 	} else if err := bleveRepo.IndexExistingProjects(projects); err != nil {
 		config.Logger.Error("Failed to index projects into Bleve", zap.Error(err))
+		failures = append(failures, fmt.Errorf("projects: %w", err))
 	}
 
 	// index stands
 	if stands, err := standRepo.GetAllStands(); err != nil {
 		config.Logger.Error("Error fetching stands for Bleve indexing", zap.Error(err))
+		failures = append(failures, fmt.Errorf("stands: %w", err))
 	} else if err := bleveRepo.IndexExistingStands(stands); err != nil {
 		config.Logger.Error("Failed to index stands into Bleve", zap.Error(err))
+		failures = append(failures, fmt.Errorf("stands: %w", err))
 	}
+
+	return errors.Join(failures...)
 }
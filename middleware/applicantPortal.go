@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"strings"
+	"town-planning-backend/config"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// PortalTokenCookie holds the applicant portal token. Staff cookies are never read by the
+// portal and portal tokens are refused by ProtectedRoute.
+const PortalTokenCookie = "portal_token"
+
+// RevokedPortalTokenKey is the Redis key marking a signed-out portal token
+func RevokedPortalTokenKey(tokenID string) string {
+	return "portal_token_revoked:" + tokenID
+}
+
+// ApplicantPortalRoute authenticates applicants on portal routes. The token comes from the
+// portal cookie or an "Authorization: Bearer" header and must have been issued to an applicant.
+// The payload is stored in c.Locals("applicant").
+func ApplicantPortalRoute(ctx *AppContext) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		portalToken := c.Cookies(PortalTokenCookie)
+		if portalToken == "" {
+			if header := c.Get(fiber.HeaderAuthorization); strings.HasPrefix(header, "Bearer ") {
+				portalToken = strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+			}
+		}
+		if portalToken == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"success": false,
+				"message": "Unauthorized",
+				"error":   "Authentication required",
+			})
+		}
+
+		payload, err := ctx.PasetoMaker.VerifyToken(portalToken)
+		if err != nil || !payload.IsApplicant() {
+			config.Logger.Debug("Invalid portal token encountered", zap.Error(err))
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"success": false,
+				"message": "Unauthorized",
+				"error":   "Session expired or invalid. Please sign in again.",
+			})
+		}
+
+		revoked, err := ctx.RedisClient.Exists(ctx.Ctx, RevokedPortalTokenKey(payload.ID.String())).Result()
+		if err != nil && err != redis.Nil {
+			config.Logger.Error("Error checking portal token revocation",
+				zap.String("applicant_id", payload.ApplicantID.String()),
+				zap.Error(err))
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"message": "Something went wrong",
+				"error":   "An internal server error occurred.",
+			})
+		}
+		if revoked > 0 {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"success": false,
+				"message": "Unauthorized",
+				"error":   "Session expired or invalid. Please sign in again.",
+			})
+		}
+
+		c.Locals("applicant", payload)
+		return c.Next()
+	}
+}
+
+// RequireScope only lets the request through when the applicant's token was granted the scope.
+// It must run after ApplicantPortalRoute.
+func RequireScope(scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		payload, ok := c.Locals("applicant").(*token.Payload)
+		if !ok || payload == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"success": false,
+				"message": "Applicant not authenticated",
+			})
+		}
+
+		if !payload.HasScope(scope) {
+			config.Logger.Warn("Portal scope denied",
+				zap.String("applicantID", payload.ApplicantID.String()),
+				zap.String("scope", scope),
+				zap.String("path", c.Path()))
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"success": false,
+				"message": "Your session does not allow this action",
+				"error":   "missing_scope:" + scope,
+			})
+		}
+
+		return c.Next()
+	}
+}
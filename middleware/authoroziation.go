@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"time"
 
 	"town-planning-backend/config" // Import your config package to access config.Logger
@@ -20,6 +21,13 @@ func ProtectedRoute(ctx *AppContext) fiber.Handler {
 		// If access token exists, verify it
 		if accessToken != "" {
 			payload, err := ctx.PasetoMaker.VerifyToken(accessToken)
+			if err == nil && !payload.IsStaff() {
+				// Applicant portal tokens never open staff routes
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"message": "Unauthorized",
+					"error":   "Authentication required",
+				})
+			}
 			if err == nil {
 				// Valid access token, proceed
 				c.Locals("user", payload)
@@ -41,6 +49,9 @@ func ProtectedRoute(ctx *AppContext) fiber.Handler {
 
 		// Verify the refresh token
 		refreshPayload, err := ctx.PasetoMaker.VerifyToken(refreshToken)
+		if err == nil && !refreshPayload.IsStaff() {
+			err = errors.New("refresh token was not issued to staff")
+		}
 		if err != nil {
 			config.Logger.Error("Invalid refresh token verification failed", zap.Error(err))
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
@@ -0,0 +1,223 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"town-planning-backend/config"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultRequestTimeoutSeconds     = 30
+	defaultLongRequestTimeoutSeconds = 180
+	defaultBodyLimitKB               = 1024
+	defaultUploadBodyLimitMB         = 50
+	defaultReadTimeoutSeconds        = 120
+	defaultShutdownTimeoutSeconds    = 30
+)
+
+// RequestLimits bounds how long a request may run and how large its body may be
+type RequestLimits struct {
+	Timeout         time.Duration // Default deadline for a handler's database work
+	LongTimeout     time.Duration // Deadline for routes marked LongRunning
+	BodyLimit       int           // Largest non-upload body, in bytes
+	UploadBodyLimit int           // Largest multipart upload to an Upload route, in bytes; also the server-wide cap
+	ReadTimeout     time.Duration // Time allowed to read a whole request, uploads included
+	ShutdownTimeout time.Duration // Time in-flight requests get to finish on shutdown
+}
+
+// longRequestTimeout is the deadline LongRunning applies, set by InitRequestLimits
+var longRequestTimeout = time.Duration(defaultLongRequestTimeoutSeconds) * time.Second
+
+// LoadRequestLimits reads the request limits. All variables are optional:
+//
+//	REQUEST_TIMEOUT_SECONDS=30         default handler deadline
+//	LONG_REQUEST_TIMEOUT_SECONDS=180   deadline for PDF generation, bulk imports and reports
+//	REQUEST_BODY_LIMIT_KB=1024         largest JSON or form body
+//	UPLOAD_BODY_LIMIT_MB=50            largest multipart upload
+//	REQUEST_READ_TIMEOUT_SECONDS=120   time allowed to receive a request
+//	SHUTDOWN_TIMEOUT_SECONDS=30        time in-flight requests get to finish on SIGTERM
+func LoadRequestLimits() RequestLimits {
+	return RequestLimits{
+		Timeout:         time.Duration(positiveEnvInt("REQUEST_TIMEOUT_SECONDS", defaultRequestTimeoutSeconds)) * time.Second,
+		LongTimeout:     time.Duration(positiveEnvInt("LONG_REQUEST_TIMEOUT_SECONDS", defaultLongRequestTimeoutSeconds)) * time.Second,
+		BodyLimit:       positiveEnvInt("REQUEST_BODY_LIMIT_KB", defaultBodyLimitKB) * 1024,
+		UploadBodyLimit: positiveEnvInt("UPLOAD_BODY_LIMIT_MB", defaultUploadBodyLimitMB) * 1024 * 1024,
+		ReadTimeout:     time.Duration(positiveEnvInt("REQUEST_READ_TIMEOUT_SECONDS", defaultReadTimeoutSeconds)) * time.Second,
+		ShutdownTimeout: time.Duration(positiveEnvInt("SHUTDOWN_TIMEOUT_SECONDS", defaultShutdownTimeoutSeconds)) * time.Second,
+	}
+}
+
+// InitRequestLimits applies the default timeout and body limits to every route. The server's
+// own BodyLimit must be set to UploadBodyLimit when the app is created, since Fiber rejects
+// anything larger before middleware runs. It must be called before any routes are registered
+// so that the routes marked with Upload are seen.
+func InitRequestLimits(app *fiber.App, limits RequestLimits) {
+	longRequestTimeout = limits.LongTimeout
+
+	uploads := &uploadRoutes{}
+	app.Hooks().OnRoute(uploads.register)
+	app.Use(limitBody(limits, uploads))
+	app.Use(Timeout(limits.Timeout))
+}
+
+// Upload marks a route as taking multipart uploads, so its multipart bodies may be as large as
+// UploadBodyLimit instead of the default limit
+func Upload() fiber.Handler {
+	return uploadMarker
+}
+
+func uploadMarker(c *fiber.Ctx) error {
+	return c.Next()
+}
+
+// LongRunning gives a route the long request timeout instead of the default
+func LongRunning() fiber.Handler {
+	return Timeout(longRequestTimeout)
+}
+
+// Timeout sets a deadline on the request's user context. Handlers pass c.UserContext() to
+// their queries so that work past the deadline is cancelled, and a request that fails once the
+// deadline has passed is answered with a 408. A route-level Timeout replaces the default rather
+// than nesting inside it. The context is not tied to the server, so a shutdown lets in-flight
+// requests finish instead of cancelling them.
+func Timeout(timeout time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+
+		// A later Timeout took over the request, so its deadline applies
+		if c.UserContext() != ctx {
+			return err
+		}
+		// Only a request that failed is answered with a timeout. A response the handler already
+		// wrote, e.g. a committed decision or payment, is kept so clients do not retry it.
+		failed := errors.Is(err, context.DeadlineExceeded) ||
+			(err == nil && c.Response().StatusCode() >= fiber.StatusInternalServerError)
+		if failed && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			config.Logger.Warn("Request timed out",
+				zap.String("method", c.Method()),
+				zap.String("path", c.Path()),
+				zap.Duration("timeout", timeout))
+			return c.Status(fiber.StatusRequestTimeout).JSON(fiber.Map{
+				"success": false,
+				"message": "The request took too long to complete, please try again",
+				"error":   "request_timeout",
+			})
+		}
+		return err
+	}
+}
+
+// limitBody rejects bodies over the limit for their route. Only multipart requests to routes
+// marked with Upload get the large limit.
+func limitBody(limits RequestLimits, uploads *uploadRoutes) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		size := c.Request().Header.ContentLength()
+		if bodySize := len(c.Request().Body()); bodySize > size {
+			size = bodySize
+		}
+		if size <= limits.BodyLimit {
+			return c.Next()
+		}
+
+		limit := limits.BodyLimit
+		if strings.HasPrefix(strings.ToLower(c.Get(fiber.HeaderContentType)), fiber.MIMEMultipartForm) &&
+			uploads.match(c.Method(), c.Path()) {
+			limit = limits.UploadBodyLimit
+		}
+		if size > limit {
+			return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+				"success": false,
+				"message": "Request body is too large",
+				"error":   "body_too_large",
+			})
+		}
+		return c.Next()
+	}
+}
+
+// uploadRoutes are the paths of the routes registered with Upload, split into segments
+type uploadRoutes struct {
+	mu     sync.RWMutex
+	routes map[string][][]string // By method
+}
+
+// register records a route if Upload is among its handlers
+func (u *uploadRoutes) register(route fiber.Route) error {
+	marker := reflect.ValueOf(uploadMarker).Pointer()
+	for _, handler := range route.Handlers {
+		if reflect.ValueOf(handler).Pointer() != marker {
+			continue
+		}
+		u.mu.Lock()
+		if u.routes == nil {
+			u.routes = make(map[string][][]string)
+		}
+		u.routes[route.Method] = append(u.routes[route.Method], pathSegments(route.Path))
+		u.mu.Unlock()
+		return nil
+	}
+	return nil
+}
+
+// match reports whether the request path falls under an Upload route. Parameters match any one
+// segment and wildcards the rest of the path, as Fiber matches them.
+func (u *uploadRoutes) match(method, path string) bool {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	segments := pathSegments(path)
+	for _, pattern := range u.routes[method] {
+		if matchSegments(pattern, segments) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(pattern, segments []string) bool {
+	for i, part := range pattern {
+		if part == "*" || part == "+" {
+			return true
+		}
+		if i >= len(segments) {
+			return false
+		}
+		if !strings.HasPrefix(part, ":") && !strings.EqualFold(part, segments[i]) {
+			return false
+		}
+	}
+	return len(pattern) == len(segments)
+}
+
+func pathSegments(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+func positiveEnvInt(name string, fallback int) int {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return fallback
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value <= 0 {
+		config.Logger.Warn("Invalid setting, using default",
+			zap.String("variable", name),
+			zap.String("value", raw),
+			zap.Int("default", fallback))
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"town-planning-backend/config"
+	"town-planning-backend/token"
+	"town-planning-backend/users/repositories"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// RequirePermission only lets the request through when the authenticated user's role
+// grants the named permission. It must run after ProtectedRoute.
+func RequirePermission(userRepo repositories.UserRepository, permissionName string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		payload, ok := c.Locals("user").(*token.Payload)
+		if !ok || payload == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"success": false,
+				"message": "User not authenticated",
+			})
+		}
+
+		allowed, err := userRepo.UserHasPermission(payload.UserID.String(), permissionName)
+		if err != nil {
+			config.Logger.Error("Failed to check user permission",
+				zap.Error(err),
+				zap.String("userID", payload.UserID.String()),
+				zap.String("permission", permissionName))
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"message": "Failed to verify permissions",
+			})
+		}
+
+		if !allowed {
+			config.Logger.Warn("Permission denied",
+				zap.String("userID", payload.UserID.String()),
+				zap.String("permission", permissionName),
+				zap.String("path", c.Path()))
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"success": false,
+				"message": "You do not have permission to perform this action",
+				"error":   "missing_permission:" + permissionName,
+			})
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,397 @@
+package controllers
+
+import (
+	"strings"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	documents_requests "town-planning-backend/documents/requests"
+	documents_services "town-planning-backend/documents/services"
+	"town-planning-backend/planningschemes/repositories"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type PlanningSchemeController struct {
+	SchemeRepo  repositories.PlanningSchemeRepository
+	DB          *gorm.DB
+	DocumentSvc *documents_services.DocumentService
+}
+
+type CreatePlanningSchemeRequest struct {
+	Name        string                    `json:"name"`
+	Code        string                    `json:"code"`
+	SchemeType  models.PlanningSchemeType `json:"scheme_type"`
+	Description *string                   `json:"description"`
+}
+
+type SetPlanningSchemeActiveRequest struct {
+	IsActive bool `json:"is_active"`
+}
+
+var planningSchemeTypes = map[models.PlanningSchemeType]bool{
+	models.ZoningMapScheme:   true,
+	models.LocalPlanScheme:   true,
+	models.MasterPlanScheme:  true,
+	models.RegulationsScheme: true,
+}
+
+// CreatePlanningSchemeController registers a planning scheme. Its text is added as versions.
+func (pc *PlanningSchemeController) CreatePlanningSchemeController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	var request CreatePlanningSchemeRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+
+	request.Name = strings.TrimSpace(request.Name)
+	request.Code = strings.ToUpper(strings.TrimSpace(request.Code))
+	if request.Name == "" || request.Code == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Name and code are required",
+		})
+	}
+	if !planningSchemeTypes[request.SchemeType] {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid scheme type",
+			"error":   "scheme_type must be ZONING_MAP, LOCAL_PLAN, MASTER_PLAN or REGULATIONS",
+		})
+	}
+
+	scheme, err := pc.SchemeRepo.CreateScheme(&models.PlanningScheme{
+		Name:        request.Name,
+		Code:        request.Code,
+		SchemeType:  request.SchemeType,
+		Description: request.Description,
+		IsActive:    true,
+		CreatedBy:   payload.UserID.String(),
+	})
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if err.Error() == "a planning scheme with this code already exists" {
+			status = fiber.StatusConflict
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to create planning scheme",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Planning scheme created",
+		"data":    scheme,
+	})
+}
+
+// GetPlanningSchemesController lists the planning schemes with their versions, newest first
+func (pc *PlanningSchemeController) GetPlanningSchemesController(c *fiber.Ctx) error {
+	schemes, err := pc.SchemeRepo.GetSchemes(c.QueryBool("include_inactive", false))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch planning schemes",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Planning schemes retrieved",
+		"data":    schemes,
+	})
+}
+
+// GetPlanningSchemeController returns a planning scheme with its version history
+func (pc *PlanningSchemeController) GetPlanningSchemeController(c *fiber.Ctx) error {
+	schemeID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid planning scheme ID",
+			"error":   err.Error(),
+		})
+	}
+
+	scheme, err := pc.SchemeRepo.GetScheme(schemeID)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if err.Error() == "planning scheme not found" {
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch planning scheme",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Planning scheme retrieved",
+		"data":    scheme,
+	})
+}
+
+// SetPlanningSchemeActiveController retires or restores a scheme. Retired schemes are no longer
+// recorded against new decisions; past decisions keep the versions they recorded.
+func (pc *PlanningSchemeController) SetPlanningSchemeActiveController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	schemeID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid planning scheme ID",
+			"error":   err.Error(),
+		})
+	}
+
+	var request SetPlanningSchemeActiveRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+
+	scheme, err := pc.SchemeRepo.SetSchemeActive(schemeID, request.IsActive, payload.UserID.String())
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if err.Error() == "planning scheme not found" {
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to update planning scheme",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Planning scheme updated",
+		"data":    scheme,
+	})
+}
+
+// PublishSchemeVersionController uploads the document for a new version of a scheme. Form
+// fields: title, effective_from (YYYY-MM-DD), summary, gazette_notice and the document file.
+func (pc *PlanningSchemeController) PublishSchemeVersionController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	schemeID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid planning scheme ID",
+			"error":   err.Error(),
+		})
+	}
+
+	title := strings.TrimSpace(c.FormValue("title"))
+	if title == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Version title is required",
+		})
+	}
+
+	effectiveFrom, err := time.ParseInLocation("2006-01-02", c.FormValue("effective_from"), time.Local)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "effective_from must be a date in YYYY-MM-DD format",
+			"error":   err.Error(),
+		})
+	}
+
+	fileHeader, err := c.FormFile("document")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "The scheme document is required",
+			"error":   err.Error(),
+		})
+	}
+
+	version := &models.PlanningSchemeVersion{
+		Title:         title,
+		EffectiveFrom: effectiveFrom,
+		CreatedBy:     payload.UserID.String(),
+	}
+	if summary := strings.TrimSpace(c.FormValue("summary")); summary != "" {
+		version.Summary = &summary
+	}
+	if notice := strings.TrimSpace(c.FormValue("gazette_notice")); notice != "" {
+		version.GazetteNotice = &notice
+	}
+
+	tx := pc.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	document, err := pc.DocumentSvc.UnifiedCreateDocument(tx, c, &documents_requests.CreateDocumentRequest{
+		CategoryCode: models.PlanningSchemeDocumentCategoryCode,
+		FileName:     fileHeader.Filename,
+		CreatedBy:    payload.UserID.String(),
+		FileType:     fileHeader.Header.Get("Content-Type"),
+	}, nil, fileHeader)
+	if err != nil {
+		tx.Rollback()
+		config.Logger.Error("Failed to store planning scheme document",
+			zap.Error(err),
+			zap.String("schemeID", schemeID.String()))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to store scheme document",
+			"error":   err.Error(),
+		})
+	}
+	version.DocumentID = &document.ID
+
+	published, err := pc.SchemeRepo.PublishSchemeVersion(tx, schemeID, version)
+	if err != nil {
+		tx.Rollback()
+		status := fiber.StatusInternalServerError
+		switch err.Error() {
+		case "planning scheme not found":
+			status = fiber.StatusNotFound
+		case "new version must take effect after the current version":
+			status = fiber.StatusBadRequest
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to publish scheme version",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not commit database transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("Planning scheme version published",
+		zap.String("schemeID", schemeID.String()),
+		zap.Int("versionNumber", published.VersionNumber),
+		zap.Time("effectiveFrom", published.EffectiveFrom),
+		zap.String("publishedBy", payload.UserID.String()))
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Scheme version published",
+		"data":    published,
+	})
+}
+
+// GetSchemesInEffectController returns the version of every active scheme in force on a date,
+// today when no date is given
+func (pc *PlanningSchemeController) GetSchemesInEffectController(c *fiber.Ctx) error {
+	at := time.Now()
+	if date := c.Query("date"); date != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", date, time.Local)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": "date must be in YYYY-MM-DD format",
+				"error":   err.Error(),
+			})
+		}
+		// Anything in force at some point that day counts
+		at = parsed.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+
+	versions, err := pc.SchemeRepo.GetVersionsInEffect(at)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch planning schemes in effect",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Planning schemes in effect retrieved",
+		"data":    versions,
+	})
+}
+
+// GetApplicationSchemesController tells which scheme versions applied when the application was
+// submitted, and which versions its final decision recorded
+func (pc *PlanningSchemeController) GetApplicationSchemesController(c *fiber.Ctx) error {
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   err.Error(),
+		})
+	}
+
+	schemes, err := pc.SchemeRepo.GetApplicationSchemes(applicationID)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if err.Error() == "application not found" {
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch application planning schemes",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Application planning schemes retrieved",
+		"data":    schemes,
+	})
+}
@@ -0,0 +1,220 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PlanningSchemeRepository interface {
+	CreateScheme(scheme *models.PlanningScheme) (*models.PlanningScheme, error)
+	GetSchemes(includeInactive bool) ([]models.PlanningScheme, error)
+	GetScheme(schemeID uuid.UUID) (*models.PlanningScheme, error)
+	SetSchemeActive(schemeID uuid.UUID, active bool, updatedBy string) (*models.PlanningScheme, error)
+	PublishSchemeVersion(tx *gorm.DB, schemeID uuid.UUID, version *models.PlanningSchemeVersion) (*models.PlanningSchemeVersion, error)
+	GetVersionsInEffect(at time.Time) ([]models.PlanningSchemeVersion, error)
+	GetApplicationSchemes(applicationID uuid.UUID) (*ApplicationSchemes, error)
+}
+
+type planningSchemeRepository struct {
+	db *gorm.DB
+}
+
+func NewPlanningSchemeRepository(db *gorm.DB) PlanningSchemeRepository {
+	return &planningSchemeRepository{
+		db: db,
+	}
+}
+
+// ApplicationSchemes lists the scheme versions that applied to an application. When a final
+// decision has been made, DecisionSchemeVersions are the versions it recorded; they only differ
+// from InEffect when a version published after the decision took effect retroactively.
+type ApplicationSchemes struct {
+	ApplicationID          uuid.UUID                      `json:"application_id"`
+	SubmissionDate         time.Time                      `json:"submission_date"`
+	InEffect               []models.PlanningSchemeVersion `json:"in_effect"`
+	FinalApprovalID        *uuid.UUID                     `json:"final_approval_id"`
+	DecisionSchemeVersions []models.PlanningSchemeVersion `json:"decision_scheme_versions"`
+}
+
+// VersionsInEffect returns the version of every active scheme that was in force at the given
+// time. Decisions and letters use it to record which scheme text they were made under.
+func VersionsInEffect(db *gorm.DB, at time.Time) ([]models.PlanningSchemeVersion, error) {
+	var versions []models.PlanningSchemeVersion
+	err := db.
+		Joins("JOIN planning_schemes ON planning_schemes.id = planning_scheme_versions.scheme_id").
+		Where("planning_schemes.is_active = ? AND planning_schemes.deleted_at IS NULL", true).
+		Where("planning_scheme_versions.effective_from <= ?", at).
+		Where("planning_scheme_versions.effective_to IS NULL OR planning_scheme_versions.effective_to > ?", at).
+		Preload("Scheme").
+		Order("planning_schemes.name ASC").
+		Find(&versions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch planning scheme versions: %w", err)
+	}
+	return versions, nil
+}
+
+func (r *planningSchemeRepository) CreateScheme(scheme *models.PlanningScheme) (*models.PlanningScheme, error) {
+	var existing int64
+	if err := r.db.Model(&models.PlanningScheme{}).Where("code = ?", scheme.Code).Count(&existing).Error; err != nil {
+		return nil, err
+	}
+	if existing > 0 {
+		return nil, errors.New("a planning scheme with this code already exists")
+	}
+
+	if err := r.db.Create(scheme).Error; err != nil {
+		return nil, fmt.Errorf("failed to create planning scheme: %w", err)
+	}
+	return scheme, nil
+}
+
+func (r *planningSchemeRepository) GetSchemes(includeInactive bool) ([]models.PlanningScheme, error) {
+	query := r.db.Model(&models.PlanningScheme{})
+	if !includeInactive {
+		query = query.Where("is_active = ?", true)
+	}
+
+	var schemes []models.PlanningScheme
+	if err := query.
+		Preload("Versions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("version_number DESC")
+		}).
+		Order("name ASC").
+		Find(&schemes).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch planning schemes: %w", err)
+	}
+	return schemes, nil
+}
+
+func (r *planningSchemeRepository) GetScheme(schemeID uuid.UUID) (*models.PlanningScheme, error) {
+	var scheme models.PlanningScheme
+	err := r.db.
+		Preload("Versions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("version_number DESC")
+		}).
+		Preload("Versions.Document").
+		Where("id = ?", schemeID).
+		First(&scheme).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("planning scheme not found")
+		}
+		return nil, err
+	}
+	return &scheme, nil
+}
+
+func (r *planningSchemeRepository) SetSchemeActive(schemeID uuid.UUID, active bool, updatedBy string) (*models.PlanningScheme, error) {
+	result := r.db.Model(&models.PlanningScheme{}).
+		Where("id = ?", schemeID).
+		Updates(map[string]interface{}{
+			"is_active":  active,
+			"updated_by": updatedBy,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("planning scheme not found")
+	}
+	return r.GetScheme(schemeID)
+}
+
+// PublishSchemeVersion adds the next version of a scheme. The version in force until now is
+// closed the moment the new one takes effect, so at most one version is in force at any time.
+// A version cannot take effect before the latest one, as that would rewrite which text past
+// decisions were made under.
+func (r *planningSchemeRepository) PublishSchemeVersion(tx *gorm.DB, schemeID uuid.UUID, version *models.PlanningSchemeVersion) (*models.PlanningSchemeVersion, error) {
+	// Lock the scheme so two publications cannot take the same version number
+	var scheme models.PlanningScheme
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", schemeID).
+		First(&scheme).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("planning scheme not found")
+		}
+		return nil, err
+	}
+
+	var latest models.PlanningSchemeVersion
+	err := tx.Where("scheme_id = ?", schemeID).
+		Order("version_number DESC").
+		First(&latest).Error
+	switch {
+	case err == nil:
+		if !version.EffectiveFrom.After(latest.EffectiveFrom) {
+			return nil, errors.New("new version must take effect after the current version")
+		}
+		if err := tx.Model(&models.PlanningSchemeVersion{}).
+			Where("id = ?", latest.ID).
+			Update("effective_to", version.EffectiveFrom).Error; err != nil {
+			return nil, fmt.Errorf("failed to close previous scheme version: %w", err)
+		}
+		version.VersionNumber = latest.VersionNumber + 1
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		version.VersionNumber = 1
+	default:
+		return nil, err
+	}
+
+	version.SchemeID = schemeID
+	version.EffectiveTo = nil
+	if err := tx.Create(version).Error; err != nil {
+		return nil, fmt.Errorf("failed to create scheme version: %w", err)
+	}
+
+	version.Scheme = &scheme
+	return version, nil
+}
+
+func (r *planningSchemeRepository) GetVersionsInEffect(at time.Time) ([]models.PlanningSchemeVersion, error) {
+	return VersionsInEffect(r.db, at)
+}
+
+// GetApplicationSchemes returns the scheme versions in force when the application was submitted,
+// and the versions its final decision recorded
+func (r *planningSchemeRepository) GetApplicationSchemes(applicationID uuid.UUID) (*ApplicationSchemes, error) {
+	var application models.Application
+	if err := r.db.Select("id", "submission_date").
+		Where("id = ?", applicationID).
+		First(&application).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("application not found")
+		}
+		return nil, err
+	}
+
+	inEffect, err := VersionsInEffect(r.db, application.SubmissionDate)
+	if err != nil {
+		return nil, err
+	}
+
+	schemes := &ApplicationSchemes{
+		ApplicationID:          application.ID,
+		SubmissionDate:         application.SubmissionDate,
+		InEffect:               inEffect,
+		DecisionSchemeVersions: []models.PlanningSchemeVersion{},
+	}
+
+	var finalApproval models.FinalApproval
+	err = r.db.
+		Preload("PlanningSchemeVersions.Scheme").
+		Where("application_id = ?", applicationID).
+		Order("decision_at DESC").
+		First(&finalApproval).Error
+	if err == nil {
+		schemes.FinalApprovalID = &finalApproval.ID
+		schemes.DecisionSchemeVersions = finalApproval.PlanningSchemeVersions
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	return schemes, nil
+}
@@ -0,0 +1,37 @@
+package routes
+
+import (
+	documents_services "town-planning-backend/documents/services"
+	"town-planning-backend/middleware"
+	"town-planning-backend/planningschemes/controllers"
+	"town-planning-backend/planningschemes/repositories"
+	user_repository "town-planning-backend/users/repositories"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+func PlanningSchemeRouterInit(
+	app *fiber.App,
+	db *gorm.DB,
+	planningSchemeRepository repositories.PlanningSchemeRepository,
+	documentService *documents_services.DocumentService,
+	userRepo user_repository.UserRepository,
+) {
+	planningSchemeController := &controllers.PlanningSchemeController{
+		SchemeRepo:  planningSchemeRepository,
+		DB:          db,
+		DocumentSvc: documentService,
+	}
+
+	schemeRoutes := app.Group("/api/v1/planning-schemes")
+	schemeRoutes.Get("/", planningSchemeController.GetPlanningSchemesController)
+	schemeRoutes.Get("/in-effect", planningSchemeController.GetSchemesInEffectController)
+	schemeRoutes.Get("/:id", planningSchemeController.GetPlanningSchemeController)
+	schemeRoutes.Post("/", middleware.RequirePermission(userRepo, "planning_scheme.manage"), planningSchemeController.CreatePlanningSchemeController)
+	schemeRoutes.Patch("/:id/active", middleware.RequirePermission(userRepo, "planning_scheme.manage"), planningSchemeController.SetPlanningSchemeActiveController)
+	schemeRoutes.Post("/:id/versions", middleware.Upload(), middleware.RequirePermission(userRepo, "planning_scheme.manage"), planningSchemeController.PublishSchemeVersionController)
+
+	// Which scheme versions applied to an application
+	app.Get("/api/v1/applications/:id/planning-schemes", planningSchemeController.GetApplicationSchemesController)
+}
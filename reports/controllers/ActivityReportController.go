@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/reports/repositories"
+	"town-planning-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// GetWeeklyActivityController reports each reviewer's decisions, issue response times, messages
+// and resolved threads for a week. Query: week (YYYY-MM-DD, any day of the week; defaults to last
+// week) and user_id to report a single user.
+func (rc *ReportController) GetWeeklyActivityController(c *fiber.Ctx) error {
+	location := utils.DateLocation
+	if location == nil {
+		location = time.Local
+	}
+
+	weekStart := repositories.ActivityWeekStart(time.Now()).AddDate(0, 0, -7)
+	if week := c.Query("week"); week != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", week, location)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": "invalid week, expected YYYY-MM-DD",
+			})
+		}
+		weekStart = parsed
+	}
+
+	var userID *uuid.UUID
+	if value := c.Query("user_id"); value != "" {
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": "Invalid user ID",
+				"error":   "invalid_uuid",
+			})
+		}
+		userID = &parsed
+	}
+
+	report, err := rc.ActivityReportRepo.GetWeeklyActivity(weekStart, userID)
+	if err != nil {
+		config.Logger.Error("Failed to compute weekly activity",
+			zap.Error(err),
+			zap.Time("weekStart", weekStart))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to compute weekly activity",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Weekly activity generated successfully",
+		"data":    report,
+	})
+}
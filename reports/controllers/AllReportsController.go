@@ -0,0 +1,19 @@
+package controllers
+
+import (
+	"town-planning-backend/reports/repositories"
+
+	"gorm.io/gorm"
+)
+
+type ReportController struct {
+	NationalReportRepo   repositories.NationalReportRepository
+	FunnelReportRepo     repositories.FunnelReportRepository
+	IntegrityReportRepo  repositories.IntegrityReportRepository
+	ReportJobRepo        repositories.ReportJobRepository
+	ActivityReportRepo   repositories.ActivityReportRepository
+	DecisionAuditRepo    repositories.DecisionAuditRepository
+	WorkloadForecastRepo repositories.WorkloadForecastRepository
+	LevyBenchmarkRepo    repositories.LevyBenchmarkRepository
+	DB                   *gorm.DB
+}
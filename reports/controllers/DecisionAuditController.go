@@ -0,0 +1,213 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/reports/repositories"
+	"town-planning-backend/token"
+	"town-planning-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// maxDecisionAuditDays bounds one export so it can be produced within a request
+const maxDecisionAuditDays = 731
+
+var decisionAuditCSVHeader = []string{
+	"sequence",
+	"event",
+	"record_id",
+	"application_id",
+	"plan_number",
+	"actor_id",
+	"actor_name",
+	"actor_email",
+	"decision",
+	"reason",
+	"occurred_at",
+	"previous_hash",
+	"hash",
+}
+
+// ExportDecisionAuditController exports the hash-chained log of decisions, revocations, overrides
+// and final decisions for oversight reviews. Query: from and to (YYYY-MM-DD, both inclusive) and
+// format=json|csv. Every export is recorded with its head hash.
+func (rc *ReportController) ExportDecisionAuditController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	format := strings.ToLower(c.Query("format", "json"))
+	if format != "json" && format != "csv" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid format parameter, expected json or csv",
+		})
+	}
+
+	location := utils.DateLocation
+	if location == nil {
+		location = time.Local
+	}
+	from, err := time.ParseInLocation("2006-01-02", c.Query("from"), location)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "invalid from, expected YYYY-MM-DD",
+		})
+	}
+	lastDay, err := time.ParseInLocation("2006-01-02", c.Query("to"), location)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "invalid to, expected YYYY-MM-DD",
+		})
+	}
+	to := lastDay.AddDate(0, 0, 1)
+	if !to.After(from) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "to must not be before from",
+		})
+	}
+	if to.Sub(from) > maxDecisionAuditDays*24*time.Hour {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": fmt.Sprintf("the period cannot be longer than %d days", maxDecisionAuditDays),
+		})
+	}
+
+	log, err := rc.DecisionAuditRepo.GetDecisionAuditLog(c.UserContext(), from, to)
+	if err != nil {
+		config.Logger.Error("Failed to build decision audit log",
+			zap.Error(err),
+			zap.Time("from", from),
+			zap.Time("to", to))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to build decision audit log",
+			"error":   err.Error(),
+		})
+	}
+
+	var data []byte
+	if format == "csv" {
+		if data, err = decisionAuditCSV(log); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"message": "Failed to generate CSV",
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	// An export nobody can later check against is not tamper-evident, so it is not served
+	export, err := rc.DecisionAuditRepo.RecordDecisionAuditExport(log, format, payload.UserID)
+	if err != nil {
+		config.Logger.Error("Failed to record decision audit export", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to record decision audit export",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("Decision audit log exported",
+		zap.String("exportID", export.ID.String()),
+		zap.String("exportedBy", payload.UserID.String()),
+		zap.Int("entries", export.EntryCount),
+		zap.String("headHash", export.HeadHash))
+
+	c.Set("X-Audit-Export-ID", export.ID.String())
+	c.Set("X-Audit-Genesis-Hash", log.GenesisHash)
+	c.Set("X-Audit-Head-Hash", log.HeadHash)
+
+	if format == "csv" {
+		c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+		c.Set(fiber.HeaderContentDisposition,
+			fmt.Sprintf(`attachment; filename="decision-audit-%s-to-%s.csv"`, from.Format("2006-01-02"), lastDay.Format("2006-01-02")))
+		return c.Status(fiber.StatusOK).Send(data)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Decision audit log exported successfully",
+		"data": fiber.Map{
+			"export": export,
+			"log":    log,
+		},
+	})
+}
+
+// GetDecisionAuditExportsController lists recent exports with their head hashes, for checking a
+// copy that was handed over
+func (rc *ReportController) GetDecisionAuditExportsController(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 50)
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	exports, err := rc.DecisionAuditRepo.GetDecisionAuditExports(limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to load decision audit exports",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Decision audit exports retrieved successfully",
+		"data":    exports,
+	})
+}
+
+func decisionAuditCSV(log *repositories.DecisionAuditLog) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	if err := writer.Write(decisionAuditCSVHeader); err != nil {
+		return nil, err
+	}
+	for _, entry := range log.Entries {
+		actorID := ""
+		if entry.ActorID != nil {
+			actorID = entry.ActorID.String()
+		}
+		row := []string{
+			strconv.Itoa(entry.Sequence),
+			entry.Event,
+			entry.RecordID.String(),
+			entry.ApplicationID.String(),
+			entry.PlanNumber,
+			actorID,
+			entry.ActorName,
+			entry.ActorEmail,
+			entry.Decision,
+			entry.Reason,
+			entry.OccurredAt.UTC().Format(time.RFC3339Nano),
+			entry.PreviousHash,
+			entry.Hash,
+		}
+		if err := writer.Write(row); err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"errors"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+const defaultStallAfterDays = 30
+
+// GetApplicationFunnelController reports where applications stall between submission and decision.
+// Query: from, to (YYYY-MM, inclusive; defaults to the last twelve months) and stall_after_days,
+// the wait in a stage after which an application counts as dropped off (default 30).
+func (rc *ReportController) GetApplicationFunnelController(c *fiber.Ctx) error {
+	from, to, err := parseFunnelPeriod(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	stallAfterDays := c.QueryInt("stall_after_days", defaultStallAfterDays)
+	if stallAfterDays < 1 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "stall_after_days must be at least 1",
+		})
+	}
+
+	report, err := rc.FunnelReportRepo.GetApplicationFunnel(c.UserContext(), from, to, stallAfterDays)
+	if err != nil {
+		config.Logger.Error("Failed to compute application funnel",
+			zap.Error(err),
+			zap.Time("from", from),
+			zap.Time("to", to))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to compute application funnel",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Application funnel generated successfully",
+		"data":    report,
+	})
+}
+
+// parseFunnelPeriod returns the start of the from month and the start of the month after to
+func parseFunnelPeriod(c *fiber.Ctx) (time.Time, time.Time, error) {
+	return parseFunnelMonths(c.Query("from"), c.Query("to"))
+}
+
+// parseFunnelMonths parses YYYY-MM from and to months, defaulting to the last twelve months
+func parseFunnelMonths(fromValue, toValue string) (time.Time, time.Time, error) {
+	location := utils.DateLocation
+	if location == nil {
+		location = time.Local
+	}
+
+	now := time.Now().In(location)
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, location)
+	from := thisMonth.AddDate(0, -11, 0)
+	to := thisMonth.AddDate(0, 1, 0)
+
+	if fromValue != "" {
+		parsed, err := time.ParseInLocation("2006-01", fromValue, location)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid from month, expected YYYY-MM")
+		}
+		from = parsed
+	}
+	if toValue != "" {
+		parsed, err := time.ParseInLocation("2006-01", toValue, location)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid to month, expected YYYY-MM")
+		}
+		to = parsed.AddDate(0, 1, 0)
+	}
+
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, errors.New("from month must not be after to month")
+	}
+	if to.Sub(from) > 5*366*24*time.Hour {
+		return time.Time{}, time.Time{}, errors.New("period cannot be longer than five years")
+	}
+	return from, to, nil
+}
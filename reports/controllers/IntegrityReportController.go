@@ -0,0 +1,32 @@
+package controllers
+
+import (
+	"town-planning-backend/config"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// GetIntegrityReportController returns the latest approval integrity report so violations can be
+// repaired by hand. Query: refresh=true runs the checks now instead of returning the nightly run.
+func (rc *ReportController) GetIntegrityReportController(c *fiber.Ctx) error {
+	report := rc.IntegrityReportRepo.GetLatestIntegrityReport()
+	if report == nil || c.QueryBool("refresh") {
+		var err error
+		report, err = rc.IntegrityReportRepo.RunIntegrityChecks(c.UserContext())
+		if err != nil {
+			config.Logger.Error("Failed to run approval integrity checks", zap.Error(err))
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"message": "Failed to run integrity checks",
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Integrity report retrieved successfully",
+		"data":    report,
+	})
+}
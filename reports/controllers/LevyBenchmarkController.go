@@ -0,0 +1,430 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/reports/repositories"
+	"town-planning-backend/reports/requests"
+	"town-planning-backend/token"
+	"town-planning-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// maxLevyBenchmarkDays bounds one report so it can be produced within a request
+const maxLevyBenchmarkDays = 731
+
+var defaultLevyBenchmarkTolerance = decimal.NewFromInt(25)
+
+var levyBenchmarkCSVHeader = []string{
+	"plan_number",
+	"status",
+	"submission_date",
+	"stand_number",
+	"ward",
+	"development_category",
+	"plan_area",
+	"development_levy",
+	"levy_per_square_meter",
+	"benchmark_levy_per_square_meter",
+	"levy_deviation_percent",
+	"stand_area",
+	"stand_value",
+	"market_value_per_square_meter",
+	"benchmark_market_value_per_square_meter",
+	"market_value_deviation_percent",
+	"flags",
+}
+
+// GetLevyBenchmarkReportController compares the development levies charged per ward and
+// development category with the configured benchmarks, counting the outliers in each. Query:
+// from and to (YYYY-MM-DD, both inclusive), optionally ward and category_id.
+func (rc *ReportController) GetLevyBenchmarkReportController(c *fiber.Ctx) error {
+	filter, err := parseLevyBenchmarkFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	report, err := rc.LevyBenchmarkRepo.GetLevyBenchmarkReport(c.UserContext(), filter)
+	if err != nil {
+		config.Logger.Error("Failed to build levy benchmark report",
+			zap.Error(err),
+			zap.Time("from", filter.From),
+			zap.Time("to", filter.To))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to build levy benchmark report",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Levy benchmark report generated successfully",
+		"data":    report,
+	})
+}
+
+// GetLevyBenchmarkApplicationsController drills down to the applications behind the report, each
+// with the reasons it was flagged. Query: from, to, ward and category_id as for the report ("none"
+// selects applications without a ward or category), outliers_only, and format=json|csv; the CSV
+// is the export handed to the valuation department.
+func (rc *ReportController) GetLevyBenchmarkApplicationsController(c *fiber.Ctx) error {
+	format := strings.ToLower(c.Query("format", "json"))
+	if format != "json" && format != "csv" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid format parameter, expected json or csv",
+		})
+	}
+
+	filter, err := parseLevyBenchmarkFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+	filter.OutliersOnly = c.QueryBool("outliers_only", false)
+
+	applications, err := rc.LevyBenchmarkRepo.GetLevyBenchmarkApplications(c.UserContext(), filter)
+	if err != nil {
+		config.Logger.Error("Failed to load levy benchmark applications",
+			zap.Error(err),
+			zap.Time("from", filter.From),
+			zap.Time("to", filter.To))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to load levy benchmark applications",
+			"error":   err.Error(),
+		})
+	}
+
+	if format == "csv" {
+		data, err := levyBenchmarkCSV(applications)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"message": "Failed to generate CSV",
+				"error":   err.Error(),
+			})
+		}
+		c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+		c.Set(fiber.HeaderContentDisposition,
+			fmt.Sprintf(`attachment; filename="levy-benchmarks-%s-to-%s.csv"`,
+				filter.From.Format("2006-01-02"), filter.To.AddDate(0, 0, -1).Format("2006-01-02")))
+		return c.Status(fiber.StatusOK).Send(data)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Levy benchmark applications retrieved successfully",
+		"data":    applications,
+	})
+}
+
+// GetLevyBenchmarksController lists the configured benchmarks
+func (rc *ReportController) GetLevyBenchmarksController(c *fiber.Ctx) error {
+	benchmarks, err := rc.LevyBenchmarkRepo.GetLevyBenchmarks()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to load levy benchmarks",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Levy benchmarks retrieved successfully",
+		"data":    benchmarks,
+	})
+}
+
+// CreateLevyBenchmarkController configures the levy expected for a ward and development category
+func (rc *ReportController) CreateLevyBenchmarkController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	var request requests.CreateLevyBenchmarkRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+
+	categoryID, err := uuid.Parse(request.DevelopmentCategoryID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid development category ID",
+		})
+	}
+	tolerance := defaultLevyBenchmarkTolerance
+	if request.TolerancePercent != nil {
+		tolerance = *request.TolerancePercent
+	}
+	if err := validateLevyBenchmarkFigures(&request.LevyPerSquareMeter, request.MarketValuePerSquareMeter, &tolerance); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	benchmark, err := rc.LevyBenchmarkRepo.CreateLevyBenchmark(&models.LevyBenchmark{
+		DevelopmentCategoryID:     categoryID,
+		Ward:                      normalizeBenchmarkWard(request.Ward),
+		LevyPerSquareMeter:        request.LevyPerSquareMeter,
+		MarketValuePerSquareMeter: request.MarketValuePerSquareMeter,
+		TolerancePercent:          tolerance,
+		Notes:                     request.Notes,
+		CreatedBy:                 payload.UserID.String(),
+	})
+	if err != nil {
+		return c.Status(levyBenchmarkErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to create levy benchmark",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Levy benchmark created successfully",
+		"data":    benchmark,
+	})
+}
+
+// UpdateLevyBenchmarkController changes a benchmark's figures, ward or notes
+func (rc *ReportController) UpdateLevyBenchmarkController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid levy benchmark ID",
+		})
+	}
+
+	var request requests.UpdateLevyBenchmarkRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+	if err := validateLevyBenchmarkFigures(request.LevyPerSquareMeter, request.MarketValuePerSquareMeter, request.TolerancePercent); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	updatedBy := payload.UserID.String()
+	updates := map[string]interface{}{"updated_by": updatedBy}
+	if request.Ward != nil {
+		updates["ward"] = normalizeBenchmarkWard(request.Ward)
+	}
+	if request.LevyPerSquareMeter != nil {
+		updates["levy_per_square_meter"] = *request.LevyPerSquareMeter
+	}
+	if request.MarketValuePerSquareMeter != nil {
+		updates["market_value_per_square_meter"] = *request.MarketValuePerSquareMeter
+	}
+	if request.TolerancePercent != nil {
+		updates["tolerance_percent"] = *request.TolerancePercent
+	}
+	if request.Notes != nil {
+		updates["notes"] = *request.Notes
+	}
+
+	benchmark, err := rc.LevyBenchmarkRepo.UpdateLevyBenchmark(id, updates)
+	if err != nil {
+		return c.Status(levyBenchmarkErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to update levy benchmark",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Levy benchmark updated successfully",
+		"data":    benchmark,
+	})
+}
+
+// DeleteLevyBenchmarkController removes a benchmark
+func (rc *ReportController) DeleteLevyBenchmarkController(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid levy benchmark ID",
+		})
+	}
+
+	if err := rc.LevyBenchmarkRepo.DeleteLevyBenchmark(id); err != nil {
+		return c.Status(levyBenchmarkErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to delete levy benchmark",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Levy benchmark deleted successfully",
+	})
+}
+
+func parseLevyBenchmarkFilter(c *fiber.Ctx) (repositories.LevyBenchmarkFilter, error) {
+	location := utils.DateLocation
+	if location == nil {
+		location = time.Local
+	}
+
+	from, err := time.ParseInLocation("2006-01-02", c.Query("from"), location)
+	if err != nil {
+		return repositories.LevyBenchmarkFilter{}, errors.New("invalid from, expected YYYY-MM-DD")
+	}
+	lastDay, err := time.ParseInLocation("2006-01-02", c.Query("to"), location)
+	if err != nil {
+		return repositories.LevyBenchmarkFilter{}, errors.New("invalid to, expected YYYY-MM-DD")
+	}
+	to := lastDay.AddDate(0, 0, 1)
+	if !to.After(from) {
+		return repositories.LevyBenchmarkFilter{}, errors.New("to must not be before from")
+	}
+	if to.Sub(from) > maxLevyBenchmarkDays*24*time.Hour {
+		return repositories.LevyBenchmarkFilter{}, fmt.Errorf("the period cannot be longer than %d days", maxLevyBenchmarkDays)
+	}
+
+	categoryID := strings.TrimSpace(c.Query("category_id"))
+	if categoryID != "" && categoryID != repositories.LevyBenchmarkNone {
+		if _, err := uuid.Parse(categoryID); err != nil {
+			return repositories.LevyBenchmarkFilter{}, errors.New("invalid category_id")
+		}
+	}
+
+	return repositories.LevyBenchmarkFilter{
+		From:       from,
+		To:         to,
+		Ward:       strings.TrimSpace(c.Query("ward")),
+		CategoryID: categoryID,
+	}, nil
+}
+
+func validateLevyBenchmarkFigures(levy *decimal.Decimal, marketValue *decimal.Decimal, tolerance *decimal.Decimal) error {
+	if levy != nil && !levy.IsPositive() {
+		return errors.New("levy_per_square_meter must be greater than zero")
+	}
+	if marketValue != nil && !marketValue.IsPositive() {
+		return errors.New("market_value_per_square_meter must be greater than zero")
+	}
+	if tolerance != nil && (!tolerance.IsPositive() || tolerance.GreaterThan(decimal.NewFromInt(1000))) {
+		return errors.New("tolerance_percent must be greater than 0 and at most 1000")
+	}
+	return nil
+}
+
+// normalizeBenchmarkWard treats a blank ward as council-wide
+func normalizeBenchmarkWard(ward *string) *string {
+	if ward == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*ward)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+func levyBenchmarkErrorStatus(err error) int {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		return fiber.StatusNotFound
+	case strings.Contains(err.Error(), "already exists"):
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+func levyBenchmarkCSV(applications []repositories.LevyBenchmarkApplication) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	if err := writer.Write(levyBenchmarkCSVHeader); err != nil {
+		return nil, err
+	}
+	optional := func(value *decimal.Decimal) string {
+		if value == nil {
+			return ""
+		}
+		return value.StringFixed(2)
+	}
+	for _, application := range applications {
+		standNumber, ward := "", ""
+		if application.StandNumber != nil {
+			standNumber = *application.StandNumber
+		}
+		if application.Ward != nil {
+			ward = *application.Ward
+		}
+		row := []string{
+			application.PlanNumber,
+			application.Status,
+			application.SubmissionDate.Format("2006-01-02"),
+			standNumber,
+			ward,
+			application.Category,
+			optional(application.PlanArea),
+			application.DevelopmentLevy.StringFixed(2),
+			optional(application.LevyPerSquareMeter),
+			optional(application.BenchmarkLevy),
+			optional(application.LevyDeviationPercent),
+			optional(application.StandArea),
+			optional(application.StandValue),
+			optional(application.MarketValuePerSquareMeter),
+			optional(application.BenchmarkMarketValue),
+			optional(application.MarketValueDeviation),
+			strings.Join(application.Flags, ";"),
+		}
+		if err := writer.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
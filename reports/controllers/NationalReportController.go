@@ -0,0 +1,297 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/reports/repositories"
+	"town-planning-backend/reports/requests"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+var nationalReportCSVHeader = []string{
+	"year",
+	"quarter",
+	"development_category",
+	"applications_received",
+	"applications_approved",
+	"applications_rejected",
+	"average_processing_days",
+	"levy_revenue",
+}
+
+// GetQuarterlyNationalReportController returns the quarterly statistics in the ministry's schema.
+// Query: year, quarter (1-4), format=json|csv. Locked quarters are served from the stored
+// snapshot, with live_figures_differ set when the live data has since changed.
+func (rc *ReportController) GetQuarterlyNationalReportController(c *fiber.Ctx) error {
+	year, quarter, err := parseReportPeriod(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	format := strings.ToLower(c.Query("format", "json"))
+	if format != "json" && format != "csv" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid format parameter, expected json or csv",
+		})
+	}
+
+	live, err := rc.NationalReportRepo.GetQuarterlyStatistics(c.UserContext(), year, quarter)
+	if err != nil {
+		config.Logger.Error("Failed to compute national report statistics",
+			zap.Error(err),
+			zap.Int("year", year),
+			zap.Int("quarter", quarter))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to compute report statistics",
+			"error":   err.Error(),
+		})
+	}
+
+	submission, err := rc.NationalReportRepo.GetReportSubmission(c.UserContext(), year, quarter)
+	if err != nil {
+		config.Logger.Error("Failed to fetch national report submission",
+			zap.Error(err),
+			zap.Int("year", year),
+			zap.Int("quarter", quarter))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch report submission",
+			"error":   err.Error(),
+		})
+	}
+
+	report := live
+	locked := submission != nil
+	liveFiguresDiffer := false
+	if locked {
+		var snapshot repositories.QuarterlyStatistics
+		if err := json.Unmarshal(submission.Figures, &snapshot); err != nil {
+			config.Logger.Error("Failed to decode locked national report figures",
+				zap.Error(err),
+				zap.String("submissionID", submission.ID.String()))
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"message": "Failed to read locked report figures",
+				"error":   err.Error(),
+			})
+		}
+		report = &snapshot
+
+		_, liveChecksum, err := repositories.EncodeQuarterlyStatistics(live)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"message": "Failed to compare report figures",
+				"error":   err.Error(),
+			})
+		}
+		liveFiguresDiffer = liveChecksum != submission.FiguresChecksum
+	}
+
+	if format == "csv" {
+		data, err := nationalReportCSV(report)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"message": "Failed to generate CSV",
+				"error":   err.Error(),
+			})
+		}
+		c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+		c.Set(fiber.HeaderContentDisposition,
+			fmt.Sprintf(`attachment; filename="national-planning-statistics-%d-Q%d.csv"`, year, quarter))
+		c.Set("X-Report-Locked", strconv.FormatBool(locked))
+		c.Set("X-Report-Live-Figures-Differ", strconv.FormatBool(liveFiguresDiffer))
+		return c.Status(fiber.StatusOK).Send(data)
+	}
+
+	response := fiber.Map{
+		"report":              report,
+		"locked":              locked,
+		"live_figures_differ": liveFiguresDiffer,
+		"submission":          submission,
+	}
+	if liveFiguresDiffer {
+		response["live_figures"] = live
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Report generated successfully",
+		"data":    response,
+	})
+}
+
+// LockQuarterlyNationalReportController freezes a completed quarter's figures once they have been
+// submitted to the ministry. Later changes to the underlying data do not alter a locked quarter.
+func (rc *ReportController) LockQuarterlyNationalReportController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	var request requests.LockNationalReportRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+
+	tx := rc.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	submission, err := rc.NationalReportRepo.LockQuarter(
+		tx,
+		request.Year,
+		request.Quarter,
+		payload.UserID,
+		request.SubmissionReference,
+		request.Notes,
+	)
+	if err != nil {
+		tx.Rollback()
+
+		status := fiber.StatusInternalServerError
+		switch err.Error() {
+		case "quarter must be between 1 and 4", "invalid year", "quarter has not ended yet":
+			status = fiber.StatusBadRequest
+		case "quarter is already locked":
+			status = fiber.StatusConflict
+		default:
+			config.Logger.Error("Failed to lock national report quarter",
+				zap.Error(err),
+				zap.Int("year", request.Year),
+				zap.Int("quarter", request.Quarter))
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to lock quarter",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Info("National report quarter locked",
+		zap.Int("year", submission.Year),
+		zap.Int("quarter", submission.Quarter),
+		zap.String("checksum", submission.FiguresChecksum),
+		zap.String("lockedBy", payload.UserID.String()))
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Quarter locked successfully",
+		"data":    submission,
+	})
+}
+
+// GetNationalReportSubmissionsController lists the locked quarters
+func (rc *ReportController) GetNationalReportSubmissionsController(c *fiber.Ctx) error {
+	submissions, err := rc.NationalReportRepo.GetReportSubmissions()
+	if err != nil {
+		config.Logger.Error("Failed to fetch national report submissions", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch report submissions",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Report submissions retrieved successfully",
+		"data":    submissions,
+	})
+}
+
+// parseReportPeriod reads year and quarter, defaulting to the last completed quarter
+func parseReportPeriod(c *fiber.Ctx) (int, int, error) {
+	now := time.Now()
+	defaultYear := now.Year()
+	defaultQuarter := (int(now.Month()) - 1) / 3 // previous quarter, 0 means Q4 of last year
+	if defaultQuarter == 0 {
+		defaultYear--
+		defaultQuarter = 4
+	}
+
+	year := c.QueryInt("year", defaultYear)
+	quarter := c.QueryInt("quarter", defaultQuarter)
+	if _, _, err := repositories.QuarterBounds(year, quarter); err != nil {
+		return 0, 0, err
+	}
+	return year, quarter, nil
+}
+
+func nationalReportCSV(report *repositories.QuarterlyStatistics) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	row := func(stats repositories.QuarterlyCategoryStatistics) []string {
+		return []string{
+			strconv.Itoa(report.Year),
+			strconv.Itoa(report.Quarter),
+			stats.DevelopmentCategory,
+			strconv.FormatInt(stats.ApplicationsReceived, 10),
+			strconv.FormatInt(stats.ApplicationsApproved, 10),
+			strconv.FormatInt(stats.ApplicationsRejected, 10),
+			stats.AverageProcessingDays.StringFixed(1),
+			stats.LevyRevenue.StringFixed(2),
+		}
+	}
+
+	if err := writer.Write(nationalReportCSVHeader); err != nil {
+		return nil, err
+	}
+	for _, category := range report.Categories {
+		if err := writer.Write(row(category)); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Write(row(report.Totals)); err != nil {
+		return nil, err
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
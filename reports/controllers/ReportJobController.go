@@ -0,0 +1,233 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/reports/repositories"
+	"town-planning-backend/reports/requests"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// reportJobResponse adds the signed download link to a completed job
+func (rc *ReportController) reportJobResponse(job *models.ReportJob) fiber.Map {
+	response := fiber.Map{
+		"job": job,
+	}
+	if url := rc.ReportJobRepo.SignedDownloadURL(job); url != "" {
+		response["download_url"] = url
+	}
+	return response
+}
+
+// CreateReportJobController queues a report to be generated in the background and returns the
+// job to poll. The requester is also emailed a download link when it is ready.
+func (rc *ReportController) CreateReportJobController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	var request requests.CreateReportJobRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+
+	reportType := models.ReportJobType(strings.ToUpper(strings.TrimSpace(request.ReportType)))
+	parameters := repositories.ReportJobParameters{}
+	switch reportType {
+	case models.ReportJobFunnel:
+		from, to, err := parseFunnelMonths(request.From, request.To)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": err.Error(),
+			})
+		}
+		parameters.From = &from
+		parameters.To = &to
+		parameters.StallAfterDays = request.StallAfterDays
+		if parameters.StallAfterDays == 0 {
+			parameters.StallAfterDays = defaultStallAfterDays
+		}
+	case models.ReportJobNationalQuarterly:
+		parameters.Year = request.Year
+		parameters.Quarter = request.Quarter
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": fmt.Sprintf("Invalid report_type, expected %s or %s", models.ReportJobFunnel, models.ReportJobNationalQuarterly),
+		})
+	}
+
+	job, err := rc.ReportJobRepo.CreateReportJob(reportType, parameters, payload.UserID, payload.UserID.String())
+	if err != nil {
+		status := fiber.StatusBadRequest
+		if strings.HasPrefix(err.Error(), "failed to") {
+			status = fiber.StatusInternalServerError
+			config.Logger.Error("Failed to create report job",
+				zap.Error(err),
+				zap.String("reportType", string(reportType)),
+				zap.String("userID", payload.UserID.String()))
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to queue report",
+			"error":   err.Error(),
+		})
+	}
+
+	go rc.ReportJobRepo.RunReportJob(job.ID)
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"success": true,
+		"message": "Report queued, you will be emailed a download link when it is ready",
+		"data":    rc.reportJobResponse(job),
+	})
+}
+
+// GetReportJobController returns a report job's status, with a download link once it has completed
+func (rc *ReportController) GetReportJobController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	jobID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid report job ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	job, err := rc.ReportJobRepo.GetReportJob(jobID)
+	if err == nil && job.RequestedByID != payload.UserID {
+		// Other users' jobs are reported as missing rather than forbidden
+		err = fmt.Errorf("report job not found")
+	}
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if err.Error() == "report job not found" {
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch report job",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Report job retrieved successfully",
+		"data":    rc.reportJobResponse(job),
+	})
+}
+
+// GetReportJobsController lists the current user's report jobs, newest first
+func (rc *ReportController) GetReportJobsController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	jobs, err := rc.ReportJobRepo.GetReportJobsForUser(payload.UserID)
+	if err != nil {
+		config.Logger.Error("Failed to fetch report jobs", zap.Error(err), zap.String("userID", payload.UserID.String()))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch report jobs",
+			"error":   err.Error(),
+		})
+	}
+
+	data := make([]fiber.Map, 0, len(jobs))
+	for i := range jobs {
+		data = append(data, rc.reportJobResponse(&jobs[i]))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Report jobs retrieved successfully",
+		"data":    data,
+	})
+}
+
+// DownloadReportController streams a generated report. It is reached through a signed link and
+// needs no login, so the signature is the only access check.
+func (rc *ReportController) DownloadReportController(c *fiber.Ctx) error {
+	jobID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid report job ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	if err := rc.ReportJobRepo.VerifyDownloadSignature(jobID, c.Query("expires"), c.Query("signature")); err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	job, err := rc.ReportJobRepo.GetReportJob(jobID)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if err.Error() == "report job not found" {
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch report",
+			"error":   err.Error(),
+		})
+	}
+
+	file, err := rc.ReportJobRepo.OpenReportFile(job)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		switch err.Error() {
+		case "report has expired":
+			status = fiber.StatusGone
+		case "report is not ready":
+			status = fiber.StatusConflict
+		default:
+			config.Logger.Error("Failed to open generated report", zap.Error(err), zap.String("jobID", jobID.String()))
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to download report",
+			"error":   err.Error(),
+		})
+	}
+
+	if job.ContentType != nil {
+		c.Set(fiber.HeaderContentType, *job.ContentType)
+	}
+	if job.FileName != nil {
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, *job.FileName))
+	}
+	return c.Status(fiber.StatusOK).SendStream(file, int(job.FileSize))
+}
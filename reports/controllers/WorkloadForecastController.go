@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"time"
+	"town-planning-backend/config"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultForecastHistoryMonths = 36
+	maxForecastHistoryMonths     = 120
+)
+
+// GetWorkloadForecastController projects next quarter's application volumes per development
+// category from past submissions, for staffing plans. Query: history_months (default 36, at most
+// 120) sets how much history the projection is fitted to.
+func (rc *ReportController) GetWorkloadForecastController(c *fiber.Ctx) error {
+	historyMonths := c.QueryInt("history_months", defaultForecastHistoryMonths)
+	if historyMonths < 1 || historyMonths > maxForecastHistoryMonths {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "history_months must be between 1 and 120",
+		})
+	}
+
+	forecast, err := rc.WorkloadForecastRepo.GetNextQuarterForecast(c.UserContext(), time.Now(), historyMonths)
+	if err != nil {
+		config.Logger.Error("Failed to forecast workload",
+			zap.Error(err),
+			zap.Int("historyMonths", historyMonths))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to forecast workload",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Workload forecast generated successfully",
+		"data":    forecast,
+	})
+}
@@ -0,0 +1,377 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/utils"
+
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ActivityReportPermission lets a user see reviewer activity and receive the weekly digest
+const ActivityReportPermission = "report.activity"
+
+// activityDigestSchedule emails the previous week's activity on Monday mornings
+const activityDigestSchedule = "0 7 * * 1"
+
+// UserActivity is what one user did during a week. Response time is measured from when an issue
+// was raised to the user's first reply in its chat thread, or its resolution if that came first.
+type UserActivity struct {
+	UserID     uuid.UUID `json:"user_id"`
+	Name       string    `json:"name"`
+	Email      string    `json:"email"`
+	Department string    `json:"department"`
+
+	MemberDecisions int64 `json:"member_decisions"`
+	FinalDecisions  int64 `json:"final_decisions"`
+	DecisionsMade   int64 `json:"decisions_made"`
+
+	IssuesAssigned       int64            `json:"issues_assigned"`
+	IssuesResponded      int64            `json:"issues_responded"`
+	AverageResponseHours *decimal.Decimal `json:"average_response_hours"`
+
+	MessagesSent    int64 `json:"messages_sent"`
+	ThreadsResolved int64 `json:"threads_resolved"`
+}
+
+// ActivityReport is the activity of every reviewer for one week, Monday to Sunday
+type ActivityReport struct {
+	WeekStart   string         `json:"week_start"`
+	WeekEnd     string         `json:"week_end"`
+	GeneratedAt time.Time      `json:"generated_at"`
+	Users       []UserActivity `json:"users"`
+	Totals      UserActivity   `json:"totals"`
+}
+
+type ActivityReportRepository interface {
+	GetWeeklyActivity(weekStart time.Time, userID *uuid.UUID) (*ActivityReport, error)
+	SendWeeklyActivityDigest(weekStart time.Time) (int, error)
+}
+
+type activityReportRepository struct {
+	db *gorm.DB
+}
+
+func NewActivityReportRepository(db *gorm.DB) ActivityReportRepository {
+	return &activityReportRepository{
+		db: db,
+	}
+}
+
+// ActivityWeekStart returns the Monday that starts the week containing t
+func ActivityWeekStart(t time.Time) time.Time {
+	location := utils.DateLocation
+	if location == nil {
+		location = time.Local
+	}
+	t = t.In(location)
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, location)
+}
+
+type activityCountRow struct {
+	UserID uuid.UUID
+	Count  int64
+}
+
+type activityResponseRow struct {
+	UserID        uuid.UUID
+	Responded     int64
+	TotalResponse float64
+}
+
+// GetWeeklyActivity reports the week starting weekStart for every reviewer, or only userID when
+// given. Reviewers are active members of approval groups plus anyone who was active that week.
+func (r *activityReportRepository) GetWeeklyActivity(weekStart time.Time, userID *uuid.UUID) (*ActivityReport, error) {
+	from := ActivityWeekStart(weekStart)
+	to := from.AddDate(0, 0, 7)
+
+	activity := map[uuid.UUID]*UserActivity{}
+	entry := func(id uuid.UUID) *UserActivity {
+		if existing, ok := activity[id]; ok {
+			return existing
+		}
+		created := &UserActivity{UserID: id}
+		activity[id] = created
+		return created
+	}
+
+	var memberIDs []uuid.UUID
+	if err := r.db.Model(&models.ApprovalGroupMember{}).
+		Distinct("user_id").
+		Where("is_active = ?", true).
+		Pluck("user_id", &memberIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to load approval group members: %w", err)
+	}
+	for _, id := range memberIDs {
+		entry(id)
+	}
+
+	counts := []struct {
+		name  string
+		query *gorm.DB
+		apply func(*UserActivity, int64)
+	}{
+		{
+			name: "member decisions",
+			query: r.db.Model(&models.MemberApprovalDecision{}).
+				Select("user_id, COUNT(*) AS count").
+				Where("decided_at >= ? AND decided_at < ?", from, to).
+				Where("status IN ?", []models.MemberDecisionStatus{models.DecisionApproved, models.DecisionRejected}).
+				Group("user_id"),
+			apply: func(a *UserActivity, n int64) { a.MemberDecisions = n },
+		},
+		{
+			name: "final decisions",
+			query: r.db.Model(&models.FinalApproval{}).
+				Select("approver_id AS user_id, COUNT(*) AS count").
+				Where("decision_at >= ? AND decision_at < ?", from, to).
+				Group("approver_id"),
+			apply: func(a *UserActivity, n int64) { a.FinalDecisions = n },
+		},
+		{
+			name: "messages sent",
+			query: r.db.Model(&models.ChatMessage{}).
+				Select("sender_id AS user_id, COUNT(*) AS count").
+				Where("created_at >= ? AND created_at < ?", from, to).
+				Where("message_type = ? AND is_deleted = ?", models.MessageTypeText, false).
+				Group("sender_id"),
+			apply: func(a *UserActivity, n int64) { a.MessagesSent = n },
+		},
+		{
+			name: "threads resolved",
+			query: r.db.Model(&models.ChatThread{}).
+				Select("application_issues.resolved_by AS user_id, COUNT(*) AS count").
+				Joins("JOIN application_issues ON application_issues.id = chat_threads.issue_id").
+				Where("chat_threads.is_resolved = ? AND chat_threads.resolved_at >= ? AND chat_threads.resolved_at < ?", true, from, to).
+				Where("application_issues.resolved_by IS NOT NULL").
+				Group("application_issues.resolved_by"),
+			apply: func(a *UserActivity, n int64) { a.ThreadsResolved = n },
+		},
+		{
+			name: "issues assigned",
+			query: r.db.Table("(?) AS assigned", r.assignedIssues()).
+				Select("assigned.user_id, COUNT(*) AS count").
+				Where("assigned.created_at >= ? AND assigned.created_at < ?", from, to).
+				Group("assigned.user_id"),
+			apply: func(a *UserActivity, n int64) { a.IssuesAssigned = n },
+		},
+	}
+
+	for _, count := range counts {
+		var rows []activityCountRow
+		if err := count.query.Scan(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to count %s: %w", count.name, err)
+		}
+		for _, row := range rows {
+			count.apply(entry(row.UserID), row.Count)
+		}
+	}
+
+	// Issues count towards the week their first response was made in
+	var responses []activityResponseRow
+	if err := r.db.Table("(?) AS responses", r.issueResponses()).
+		Select("responses.user_id, COUNT(*) AS responded, SUM(EXTRACT(EPOCH FROM responses.responded_at - responses.created_at)) AS total_response").
+		Where("responses.responded_at >= ? AND responses.responded_at < ?", from, to).
+		Group("responses.user_id").
+		Scan(&responses).Error; err != nil {
+		return nil, fmt.Errorf("failed to measure issue response times: %w", err)
+	}
+	responseSeconds := map[uuid.UUID]float64{}
+	for _, row := range responses {
+		a := entry(row.UserID)
+		a.IssuesResponded = row.Responded
+		a.AverageResponseHours = averageHours(row.TotalResponse, row.Responded)
+		responseSeconds[row.UserID] = row.TotalResponse
+	}
+
+	if userID != nil {
+		only := entry(*userID)
+		activity = map[uuid.UUID]*UserActivity{*userID: only}
+	}
+
+	if err := r.attachUserDetails(activity); err != nil {
+		return nil, err
+	}
+
+	report := &ActivityReport{
+		WeekStart:   from.Format("2006-01-02"),
+		WeekEnd:     to.AddDate(0, 0, -1).Format("2006-01-02"),
+		GeneratedAt: time.Now(),
+		Users:       make([]UserActivity, 0, len(activity)),
+	}
+
+	var totalResponseSeconds float64
+	for _, a := range activity {
+		a.DecisionsMade = a.MemberDecisions + a.FinalDecisions
+		report.Users = append(report.Users, *a)
+
+		report.Totals.MemberDecisions += a.MemberDecisions
+		report.Totals.FinalDecisions += a.FinalDecisions
+		report.Totals.DecisionsMade += a.DecisionsMade
+		report.Totals.IssuesAssigned += a.IssuesAssigned
+		report.Totals.IssuesResponded += a.IssuesResponded
+		report.Totals.MessagesSent += a.MessagesSent
+		report.Totals.ThreadsResolved += a.ThreadsResolved
+		totalResponseSeconds += responseSeconds[a.UserID]
+	}
+	report.Totals.AverageResponseHours = averageHours(totalResponseSeconds, report.Totals.IssuesResponded)
+	report.Totals.Name = "ALL"
+
+	sort.Slice(report.Users, func(i, j int) bool {
+		if report.Users[i].DecisionsMade != report.Users[j].DecisionsMade {
+			return report.Users[i].DecisionsMade > report.Users[j].DecisionsMade
+		}
+		return report.Users[i].Name < report.Users[j].Name
+	})
+
+	return report, nil
+}
+
+// averageHours converts a total response time in seconds to an average in hours
+func averageHours(totalSeconds float64, count int64) *decimal.Decimal {
+	if count == 0 {
+		return nil
+	}
+	hours := decimal.NewFromFloat(totalSeconds / float64(count) / 3600).Round(1)
+	return &hours
+}
+
+// assignedIssues lists each issue with the user it is assigned to, either directly or through
+// an approval group member. Collaborative issues belong to no one and are left out.
+func (r *activityReportRepository) assignedIssues() *gorm.DB {
+	return r.db.Model(&models.ApplicationIssue{}).
+		Select("application_issues.id, application_issues.chat_thread_id, application_issues.created_at, application_issues.resolved_at, application_issues.resolved_by, " +
+			"COALESCE(application_issues.assigned_to_user_id, approval_group_members.user_id) AS user_id").
+		Joins("LEFT JOIN approval_group_members ON approval_group_members.id = application_issues.assigned_to_group_member_id").
+		Where("COALESCE(application_issues.assigned_to_user_id, approval_group_members.user_id) IS NOT NULL")
+}
+
+// issueResponses gives when the assignee first responded to each assigned issue: their first
+// message in its thread, or their resolution of it if that came first
+func (r *activityReportRepository) issueResponses() *gorm.DB {
+	firstMessage := r.db.Model(&models.ChatMessage{}).
+		Select("MIN(chat_messages.created_at)").
+		Where("chat_messages.thread_id = assigned.chat_thread_id AND chat_messages.sender_id = assigned.user_id").
+		Where("chat_messages.created_at >= assigned.created_at").
+		Where("chat_messages.message_type = ? AND chat_messages.is_deleted = ?", models.MessageTypeText, false)
+
+	return r.db.Table("(?) AS assigned", r.assignedIssues()).
+		Select("assigned.user_id, assigned.created_at, "+
+			"LEAST((?), CASE WHEN assigned.resolved_by = assigned.user_id THEN assigned.resolved_at END) AS responded_at", firstMessage)
+}
+
+// attachUserDetails fills in names, emails and departments
+func (r *activityReportRepository) attachUserDetails(activity map[uuid.UUID]*UserActivity) error {
+	if len(activity) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, 0, len(activity))
+	for id := range activity {
+		ids = append(ids, id)
+	}
+
+	var users []models.User
+	if err := r.db.Unscoped().
+		Preload("Department").
+		Select("id", "first_name", "last_name", "email", "department_id").
+		Where("id IN ?", ids).
+		Find(&users).Error; err != nil {
+		return fmt.Errorf("failed to load users: %w", err)
+	}
+	for _, user := range users {
+		a := activity[user.ID]
+		a.Name = strings.TrimSpace(user.FirstName + " " + user.LastName)
+		a.Email = user.Email
+		if user.Department != nil {
+			a.Department = user.Department.Name
+		}
+	}
+	return nil
+}
+
+// SendWeeklyActivityDigest emails the week's activity report to every active user holding the
+// activity report permission and returns how many were sent
+func (r *activityReportRepository) SendWeeklyActivityDigest(weekStart time.Time) (int, error) {
+	report, err := r.GetWeeklyActivity(weekStart, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var recipients []models.User
+	if err := r.db.Model(&models.User{}).
+		Select("users.id", "users.email").
+		Joins("JOIN role_permissions ON role_permissions.role_id = users.role_id").
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id AND permissions.deleted_at IS NULL").
+		Where("permissions.name = ? AND permissions.is_active = ?", ActivityReportPermission, true).
+		Where("users.active = ? AND users.is_suspended = ?", true, false).
+		Find(&recipients).Error; err != nil {
+		return 0, fmt.Errorf("failed to load activity digest recipients: %w", err)
+	}
+
+	message := formatActivityDigest(report)
+	subject := fmt.Sprintf("Reviewer activity for the week of %s", report.WeekStart)
+	sent := 0
+	for _, recipient := range recipients {
+		if err := utils.SendEmail(recipient.Email, message, subject, "", ""); err != nil {
+			config.Logger.Warn("Failed to email activity digest",
+				zap.Error(err),
+				zap.String("userID", recipient.ID.String()))
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// formatActivityDigest renders the report as a plain-text table, one line per reviewer
+func formatActivityDigest(report *ActivityReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Reviewer activity from %s to %s\n\n", report.WeekStart, report.WeekEnd)
+	fmt.Fprintf(&b, "%-30s %9s %15s %9s %9s\n", "Reviewer", "Decisions", "Avg response (h)", "Messages", "Resolved")
+
+	line := func(a UserActivity) {
+		response := "-"
+		if a.AverageResponseHours != nil {
+			response = a.AverageResponseHours.StringFixed(1)
+		}
+		fmt.Fprintf(&b, "%-30s %9d %15s %9d %9d\n", a.Name, a.DecisionsMade, response, a.MessagesSent, a.ThreadsResolved)
+	}
+	for _, a := range report.Users {
+		line(a)
+	}
+	b.WriteString("\n")
+	line(report.Totals)
+	return b.String()
+}
+
+// RunScheduledActivityDigest emails directors the previous week's reviewer activity every Monday
+// until ctx is cancelled
+func RunScheduledActivityDigest(ctx context.Context, repo ActivityReportRepository) {
+	c := cron.New()
+
+	c.AddFunc(activityDigestSchedule, func() {
+		lastWeek := ActivityWeekStart(time.Now()).AddDate(0, 0, -7)
+		sent, err := repo.SendWeeklyActivityDigest(lastWeek)
+		if err != nil {
+			config.Logger.Error("Scheduled activity digest failed", zap.Error(err))
+			return
+		}
+		config.Logger.Info("Weekly activity digest sent", zap.Int("recipients", sent))
+	})
+
+	c.Start()
+
+	// Keep running until shutdown, then wait for a running job to finish
+	<-ctx.Done()
+	<-c.Stop().Done()
+}
@@ -0,0 +1,276 @@
+package repositories
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DecisionAuditPermission lets a user export the decision audit log
+const DecisionAuditPermission = "audit.export"
+
+// Events recorded in the decision audit log
+const (
+	AuditMemberDecision = "MEMBER_DECISION"
+	AuditRevocation     = "DECISION_REVOKED"
+	AuditFinalDecision  = "FINAL_DECISION"
+	AuditOverride       = "FINAL_OVERRIDE" // Final decision that overrode the group's decision
+)
+
+// DecisionAuditEntry is one link of the audit hash chain. Hash is the SHA-256 of the entry's
+// fields and the previous entry's hash, see DecisionAuditEntry.ComputeHash, so altering,
+// removing or reordering any entry changes every hash after it.
+type DecisionAuditEntry struct {
+	Sequence      int        `json:"sequence"`
+	Event         string     `json:"event"`
+	RecordID      uuid.UUID  `json:"record_id"` // Decision, revocation or final approval ID
+	ApplicationID uuid.UUID  `json:"application_id"`
+	PlanNumber    string     `json:"plan_number"`
+	ActorID       *uuid.UUID `json:"actor_id"` // Nil for decisions the system made
+	ActorName     string     `json:"actor_name"`
+	ActorEmail    string     `json:"actor_email"`
+	Decision      string     `json:"decision"`
+	Reason        string     `json:"reason"`
+	OccurredAt    time.Time  `json:"occurred_at"`
+	PreviousHash  string     `json:"previous_hash"`
+	Hash          string     `json:"hash"`
+
+	// IdempotencyKey is the key the client sent with the decision. It is not hashed, so chains
+	// exported before keys were recorded still verify.
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+}
+
+// DecisionAuditLog is the hash-chained log of a period. The chain starts from GenesisHash, which
+// is derived from the period alone, and ends at HeadHash.
+type DecisionAuditLog struct {
+	PeriodFrom  time.Time            `json:"period_from"`
+	PeriodTo    time.Time            `json:"period_to"` // Exclusive
+	GeneratedAt time.Time            `json:"generated_at"`
+	GenesisHash string               `json:"genesis_hash"`
+	HeadHash    string               `json:"head_hash"`
+	Algorithm   string               `json:"algorithm"`
+	Entries     []DecisionAuditEntry `json:"entries"`
+}
+
+// decisionAuditAlgorithm describes how to recompute the chain, and travels with every export
+const decisionAuditAlgorithm = "hash = sha256(join([sequence, event, record_id, application_id, plan_number, actor_id, actor_name, actor_email, decision, reason, occurred_at (RFC 3339, UTC), previous_hash], newline)), hex encoded; genesis = sha256(\"decision-audit|\" + period_from + \"|\" + period_to)"
+
+// DecisionAuditGenesis is the hash the chain for a period starts from
+func DecisionAuditGenesis(from, to time.Time) string {
+	sum := sha256.Sum256([]byte("decision-audit|" + from.UTC().Format(time.RFC3339) + "|" + to.UTC().Format(time.RFC3339)))
+	return hex.EncodeToString(sum[:])
+}
+
+// ComputeHash returns the entry's hash from its fields and PreviousHash
+func (e DecisionAuditEntry) ComputeHash() string {
+	actorID := ""
+	if e.ActorID != nil {
+		actorID = e.ActorID.String()
+	}
+	fields := []string{
+		strconv.Itoa(e.Sequence),
+		e.Event,
+		e.RecordID.String(),
+		e.ApplicationID.String(),
+		e.PlanNumber,
+		actorID,
+		e.ActorName,
+		e.ActorEmail,
+		e.Decision,
+		e.Reason,
+		e.OccurredAt.UTC().Format(time.RFC3339Nano),
+		e.PreviousHash,
+	}
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+type DecisionAuditRepository interface {
+	GetDecisionAuditLog(ctx context.Context, from, to time.Time) (*DecisionAuditLog, error)
+	RecordDecisionAuditExport(log *DecisionAuditLog, format string, exportedByID uuid.UUID) (*models.DecisionAuditExport, error)
+	GetDecisionAuditExports(limit int) ([]models.DecisionAuditExport, error)
+}
+
+type decisionAuditRepository struct {
+	db *gorm.DB
+}
+
+func NewDecisionAuditRepository(db *gorm.DB) DecisionAuditRepository {
+	return &decisionAuditRepository{
+		db: db,
+	}
+}
+
+type decisionAuditRow struct {
+	RecordID      uuid.UUID
+	ApplicationID uuid.UUID
+	PlanNumber    string
+	ActorID       *uuid.UUID
+	FirstName     *string
+	LastName      *string
+	Email         *string
+	Decision      string
+	Reason        *string
+	OccurredAt    time.Time
+	Overrode      bool
+
+	IdempotencyKey *string
+}
+
+// GetDecisionAuditLog collects every member decision, revocation and final decision made in
+// [from, to) and chains them in the order they happened. Soft-deleted records are included:
+// the log is meant to show everything that was decided, not only what is still current.
+func (r *decisionAuditRepository) GetDecisionAuditLog(ctx context.Context, from, to time.Time) (*DecisionAuditLog, error) {
+	db := r.db.WithContext(ctx)
+	var entries []DecisionAuditEntry
+	add := func(event string, rows []decisionAuditRow) {
+		for _, row := range rows {
+			entry := DecisionAuditEntry{
+				Event:         event,
+				RecordID:      row.RecordID,
+				ApplicationID: row.ApplicationID,
+				PlanNumber:    row.PlanNumber,
+				ActorID:       row.ActorID,
+				Decision:      row.Decision,
+				OccurredAt:    row.OccurredAt.UTC(),
+			}
+			if event == AuditFinalDecision && row.Overrode {
+				entry.Event = AuditOverride
+			}
+			if row.FirstName != nil && row.LastName != nil {
+				entry.ActorName = strings.TrimSpace(*row.FirstName + " " + *row.LastName)
+			}
+			if row.ActorID == nil {
+				entry.ActorName = "system"
+			}
+			if row.Email != nil {
+				entry.ActorEmail = *row.Email
+			}
+			if row.Reason != nil {
+				entry.Reason = *row.Reason
+			}
+			if row.IdempotencyKey != nil {
+				entry.IdempotencyKey = *row.IdempotencyKey
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	// A revoked decision keeps its decided_at; what was decided is the status it was revoked from
+	var decisions []decisionAuditRow
+	if err := db.Table("member_approval_decisions AS d").
+		Select(`d.id AS record_id, a.application_id, apps.plan_number, d.user_id AS actor_id,
+			u.first_name, u.last_name, u.email, d.decided_at AS occurred_at, d.idempotency_key,
+			CASE WHEN d.status = ? THEN COALESCE((
+				SELECT rv.previous_status FROM decision_revocations rv
+				WHERE rv.decision_id = d.id ORDER BY rv.revoked_at LIMIT 1
+			), d.status) ELSE d.status END AS decision`, models.DecisionRevoked).
+		Joins("JOIN application_group_assignments a ON a.id = d.assignment_id").
+		Joins("JOIN applications apps ON apps.id = a.application_id").
+		Joins("LEFT JOIN users u ON u.id = d.user_id").
+		Where("d.decided_at >= ? AND d.decided_at < ?", from, to).
+		Where("d.status IN ?", []models.MemberDecisionStatus{models.DecisionApproved, models.DecisionRejected, models.DecisionRevoked}).
+		Scan(&decisions).Error; err != nil {
+		return nil, fmt.Errorf("failed to load member decisions: %w", err)
+	}
+	add(AuditMemberDecision, decisions)
+
+	var revocations []decisionAuditRow
+	if err := db.Table("decision_revocations AS rv").
+		Select(`rv.id AS record_id, a.application_id, apps.plan_number, rv.revoked_by AS actor_id,
+			u.first_name, u.last_name, u.email, rv.previous_status AS decision, rv.reason, rv.revoked_at AS occurred_at,
+			rv.idempotency_key`).
+		Joins("JOIN member_approval_decisions d ON d.id = rv.decision_id").
+		Joins("JOIN application_group_assignments a ON a.id = d.assignment_id").
+		Joins("JOIN applications apps ON apps.id = a.application_id").
+		Joins("LEFT JOIN users u ON u.id = rv.revoked_by").
+		Where("rv.revoked_at >= ? AND rv.revoked_at < ?", from, to).
+		Scan(&revocations).Error; err != nil {
+		return nil, fmt.Errorf("failed to load decision revocations: %w", err)
+	}
+	add(AuditRevocation, revocations)
+
+	var finals []decisionAuditRow
+	if err := db.Table("final_approvals AS fa").
+		Select(`fa.id AS record_id, fa.application_id, apps.plan_number,
+			CASE WHEN fa.is_system_auto_decision THEN NULL ELSE fa.approver_id END AS actor_id,
+			u.first_name, u.last_name, u.email, fa.decision, fa.decision_at AS occurred_at,
+			fa.overrode_group_decision AS overrode,
+			CASE WHEN fa.overrode_group_decision THEN COALESCE(fa.override_reason, fa.comment) ELSE fa.comment END AS reason`).
+		Joins("JOIN applications apps ON apps.id = fa.application_id").
+		Joins("LEFT JOIN users u ON u.id = fa.approver_id AND NOT fa.is_system_auto_decision").
+		Where("fa.decision_at >= ? AND fa.decision_at < ?", from, to).
+		Scan(&finals).Error; err != nil {
+		return nil, fmt.Errorf("failed to load final decisions: %w", err)
+	}
+	add(AuditFinalDecision, finals)
+
+	// Record IDs break ties so the same period always produces the same chain
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].OccurredAt.Equal(entries[j].OccurredAt) {
+			return entries[i].OccurredAt.Before(entries[j].OccurredAt)
+		}
+		return entries[i].RecordID.String() < entries[j].RecordID.String()
+	})
+
+	genesis := DecisionAuditGenesis(from, to)
+	previous := genesis
+	for i := range entries {
+		entries[i].Sequence = i + 1
+		entries[i].PreviousHash = previous
+		entries[i].Hash = entries[i].ComputeHash()
+		previous = entries[i].Hash
+	}
+	if entries == nil {
+		entries = []DecisionAuditEntry{}
+	}
+
+	return &DecisionAuditLog{
+		PeriodFrom:  from,
+		PeriodTo:    to,
+		GeneratedAt: time.Now(),
+		GenesisHash: genesis,
+		HeadHash:    previous,
+		Algorithm:   decisionAuditAlgorithm,
+		Entries:     entries,
+	}, nil
+}
+
+// RecordDecisionAuditExport keeps the head hash of an export so a copy handed over can later be
+// checked against what the council issued
+func (r *decisionAuditRepository) RecordDecisionAuditExport(log *DecisionAuditLog, format string, exportedByID uuid.UUID) (*models.DecisionAuditExport, error) {
+	export := models.DecisionAuditExport{
+		PeriodFrom:   log.PeriodFrom,
+		PeriodTo:     log.PeriodTo,
+		Format:       format,
+		EntryCount:   len(log.Entries),
+		GenesisHash:  log.GenesisHash,
+		HeadHash:     log.HeadHash,
+		ExportedByID: exportedByID,
+		ExportedAt:   log.GeneratedAt,
+	}
+	if err := r.db.Create(&export).Error; err != nil {
+		return nil, fmt.Errorf("failed to record decision audit export: %w", err)
+	}
+	return &export, nil
+}
+
+// GetDecisionAuditExports returns the most recent exports, newest first
+func (r *decisionAuditRepository) GetDecisionAuditExports(limit int) ([]models.DecisionAuditExport, error) {
+	var exports []models.DecisionAuditExport
+	if err := r.db.Preload("ExportedBy", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "first_name", "last_name", "email")
+	}).Order("exported_at DESC").Limit(limit).Find(&exports).Error; err != nil {
+		return nil, fmt.Errorf("failed to load decision audit exports: %w", err)
+	}
+	return exports, nil
+}
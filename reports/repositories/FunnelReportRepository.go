@@ -0,0 +1,341 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+	"town-planning-backend/utils"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// FunnelStage is a step an application passes through on its way to a decision
+type FunnelStage string
+
+const (
+	FunnelDraft     FunnelStage = "DRAFT"
+	FunnelDocuments FunnelStage = "DOCUMENTS"
+	FunnelPayment   FunnelStage = "PAYMENT"
+	FunnelReview    FunnelStage = "REVIEW"
+	FunnelDecision  FunnelStage = "DECISION"
+)
+
+// FunnelStages lists the stages in the order applications move through them
+var FunnelStages = []FunnelStage{FunnelDraft, FunnelDocuments, FunnelPayment, FunnelReview, FunnelDecision}
+
+// funnelBuckets are the upper bounds in days of the time-in-stage histogram; the last bucket is open
+var funnelBuckets = []struct {
+	Label   string
+	MaxDays float64
+}{
+	{"0-1", 1},
+	{"1-3", 3},
+	{"3-7", 7},
+	{"7-14", 14},
+	{"14-30", 30},
+	{"30+", math.Inf(1)},
+}
+
+type FunnelReportRepository interface {
+	GetApplicationFunnel(ctx context.Context, from, to time.Time, stallAfterDays int) (*FunnelReport, error)
+}
+
+type funnelReportRepository struct {
+	db *gorm.DB
+}
+
+func NewFunnelReportRepository(db *gorm.DB) FunnelReportRepository {
+	return &funnelReportRepository{
+		db: db,
+	}
+}
+
+// DurationBucket counts the applications that spent a given range of days in a stage
+type DurationBucket struct {
+	Label string `json:"label"`
+	Count int64  `json:"count"`
+}
+
+// StageDurationStatistics describes how long applications spent in a stage before moving on
+type StageDurationStatistics struct {
+	Samples    int64            `json:"samples"`
+	MeanDays   decimal.Decimal  `json:"mean_days"`
+	MedianDays decimal.Decimal  `json:"median_days"`
+	P75Days    decimal.Decimal  `json:"p75_days"`
+	P90Days    decimal.Decimal  `json:"p90_days"`
+	Buckets    []DurationBucket `json:"buckets"`
+}
+
+// FunnelStageStatistics is one stage of a funnel. Applications that reached a stage either
+// advanced, are still within the stall window, or have stalled there. Drop-off is the share
+// of applications that reached the stage and stalled in it.
+type FunnelStageStatistics struct {
+	Stage       FunnelStage              `json:"stage"`
+	Reached     int64                    `json:"reached"`
+	Advanced    int64                    `json:"advanced"`
+	InProgress  int64                    `json:"in_progress"`
+	Stalled     int64                    `json:"stalled"`
+	DropOffRate decimal.Decimal          `json:"drop_off_rate"`
+	TimeInStage *StageDurationStatistics `json:"time_in_stage,omitempty"`
+}
+
+// FunnelSegment is the funnel for one development category and submission month. Rollups use
+// "ALL" for the dimension they cover.
+type FunnelSegment struct {
+	DevelopmentCategory string                  `json:"development_category"`
+	Month               string                  `json:"month"`
+	Applications        int64                   `json:"applications"`
+	Stages              []FunnelStageStatistics `json:"stages"`
+}
+
+// FunnelReport shows where applications submitted in a period stall before a decision
+type FunnelReport struct {
+	PeriodStart    string          `json:"period_start"`
+	PeriodEnd      string          `json:"period_end"`
+	StallAfterDays int             `json:"stall_after_days"`
+	GeneratedAt    time.Time       `json:"generated_at"`
+	Segments       []FunnelSegment `json:"segments"`
+	Categories     []FunnelSegment `json:"categories"`
+	Totals         FunnelSegment   `json:"totals"`
+}
+
+type funnelApplicationRow struct {
+	Category             string
+	SubmissionDate       time.Time
+	DocumentsCompletedAt *time.Time
+	PaymentCompletedAt   *time.Time
+	ReviewStartedAt      *time.Time
+	DecidedAt            *time.Time
+	PausedReviewDays     float64 // Time in review spent waiting on the applicant
+}
+
+// stageTimes returns when the application entered each funnel stage, nil where it was skipped
+// or not yet reached
+func (row funnelApplicationRow) stageTimes() []*time.Time {
+	submitted := row.SubmissionDate
+	return []*time.Time{&submitted, row.DocumentsCompletedAt, row.PaymentCompletedAt, row.ReviewStartedAt, row.DecidedAt}
+}
+
+type stageAccumulator struct {
+	reached, advanced, inProgress, stalled int64
+	days                                   []float64
+}
+
+type funnelAccumulator struct {
+	applications int64
+	stages       []stageAccumulator
+}
+
+func newFunnelAccumulator() *funnelAccumulator {
+	return &funnelAccumulator{stages: make([]stageAccumulator, len(FunnelStages))}
+}
+
+// add places an application in the funnel. The furthest stage it has a timestamp for counts as
+// reached, along with every stage before it, so a skipped stage (e.g. payment deferred onto an
+// installment plan) does not look like a drop-off.
+func (acc *funnelAccumulator) add(row funnelApplicationRow, now time.Time, stallAfter time.Duration) {
+	acc.applications++
+	times := row.stageTimes()
+
+	furthest := 0
+	for i, t := range times {
+		if t != nil {
+			furthest = i
+		}
+	}
+
+	last := len(FunnelStages) - 1
+	for i := 0; i <= furthest; i++ {
+		stage := &acc.stages[i]
+		stage.reached++
+		if i == last {
+			continue
+		}
+
+		if i < furthest {
+			stage.advanced++
+			// Time in a stage runs until the next stage the application actually entered
+			if times[i] != nil {
+				for _, next := range times[i+1:] {
+					if next != nil {
+						days := next.Sub(*times[i]).Hours() / 24
+						if FunnelStages[i] == FunnelReview {
+							days -= row.PausedReviewDays
+						}
+						stage.days = append(stage.days, math.Max(days, 0))
+						break
+					}
+				}
+			}
+			continue
+		}
+
+		if now.Sub(*times[i]) > stallAfter {
+			stage.stalled++
+		} else {
+			stage.inProgress++
+		}
+	}
+}
+
+func (acc *funnelAccumulator) segment(category, month string) FunnelSegment {
+	segment := FunnelSegment{
+		DevelopmentCategory: category,
+		Month:               month,
+		Applications:        acc.applications,
+		Stages:              make([]FunnelStageStatistics, len(FunnelStages)),
+	}
+	for i, stage := range acc.stages {
+		stats := FunnelStageStatistics{
+			Stage:       FunnelStages[i],
+			Reached:     stage.reached,
+			Advanced:    stage.advanced,
+			InProgress:  stage.inProgress,
+			Stalled:     stage.stalled,
+			DropOffRate: decimal.Zero,
+		}
+		if stage.reached > 0 {
+			stats.DropOffRate = decimal.NewFromInt(stage.stalled).
+				Div(decimal.NewFromInt(stage.reached)).
+				Mul(decimal.NewFromInt(100)).
+				Round(1)
+		}
+		if i < len(FunnelStages)-1 {
+			stats.TimeInStage = durationStatistics(stage.days)
+		}
+		segment.Stages[i] = stats
+	}
+	return segment
+}
+
+func durationStatistics(days []float64) *StageDurationStatistics {
+	stats := &StageDurationStatistics{
+		Samples:    int64(len(days)),
+		MeanDays:   decimal.Zero,
+		MedianDays: decimal.Zero,
+		P75Days:    decimal.Zero,
+		P90Days:    decimal.Zero,
+		Buckets:    make([]DurationBucket, len(funnelBuckets)),
+	}
+	for i, bucket := range funnelBuckets {
+		stats.Buckets[i].Label = bucket.Label
+	}
+	if len(days) == 0 {
+		return stats
+	}
+
+	sorted := append([]float64(nil), days...)
+	sort.Float64s(sorted)
+
+	sum := 0.0
+	for _, d := range sorted {
+		sum += d
+		for i, bucket := range funnelBuckets {
+			if d < bucket.MaxDays {
+				stats.Buckets[i].Count++
+				break
+			}
+		}
+	}
+
+	stats.MeanDays = decimal.NewFromFloat(sum / float64(len(sorted))).Round(1)
+	stats.MedianDays = decimal.NewFromFloat(percentile(sorted, 50)).Round(1)
+	stats.P75Days = decimal.NewFromFloat(percentile(sorted, 75)).Round(1)
+	stats.P90Days = decimal.NewFromFloat(percentile(sorted, 90)).Round(1)
+	return stats
+}
+
+// percentile uses the nearest-rank method on sorted values
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// GetApplicationFunnel builds the funnel for applications submitted in [from, to), split by
+// development category and submission month. An application waiting longer than
+// stallAfterDays in its current stage counts as having dropped off there.
+func (r *funnelReportRepository) GetApplicationFunnel(ctx context.Context, from, to time.Time, stallAfterDays int) (*FunnelReport, error) {
+	if !from.Before(to) {
+		return nil, errors.New("period start must be before period end")
+	}
+	if stallAfterDays < 1 {
+		return nil, errors.New("stall_after_days must be at least 1")
+	}
+
+	var rows []funnelApplicationRow
+	if err := r.db.WithContext(ctx).Table("applications").
+		Select(`COALESCE(development_categories.name, ?) AS category,
+			applications.submission_date,
+			applications.documents_completed_at,
+			applications.payment_completed_at,
+			applications.review_started_at,
+			COALESCE(applications.final_approval_date, applications.rejection_date) AS decided_at,
+			`+pausedDaysSQL("applications.review_started_at", "COALESCE(applications.final_approval_date, applications.rejection_date)")+` AS paused_review_days`, uncategorisedLabel).
+		Joins(categoryJoins).
+		Where("applications.deleted_at IS NULL").
+		Where("applications.submission_date >= ? AND applications.submission_date < ?", from, to).
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load applications: %w", err)
+	}
+
+	location := utils.DateLocation
+	if location == nil {
+		location = time.Local
+	}
+
+	now := time.Now()
+	stallAfter := time.Duration(stallAfterDays) * 24 * time.Hour
+
+	type segmentKey struct{ category, month string }
+	segments := map[segmentKey]*funnelAccumulator{}
+	categories := map[string]*funnelAccumulator{}
+	totals := newFunnelAccumulator()
+
+	for _, row := range rows {
+		key := segmentKey{row.Category, row.SubmissionDate.In(location).Format("2006-01")}
+		if segments[key] == nil {
+			segments[key] = newFunnelAccumulator()
+		}
+		if categories[row.Category] == nil {
+			categories[row.Category] = newFunnelAccumulator()
+		}
+		segments[key].add(row, now, stallAfter)
+		categories[row.Category].add(row, now, stallAfter)
+		totals.add(row, now, stallAfter)
+	}
+
+	report := &FunnelReport{
+		PeriodStart:    from.Format("2006-01-02"),
+		PeriodEnd:      to.AddDate(0, 0, -1).Format("2006-01-02"),
+		StallAfterDays: stallAfterDays,
+		GeneratedAt:    now,
+		Segments:       make([]FunnelSegment, 0, len(segments)),
+		Categories:     make([]FunnelSegment, 0, len(categories)),
+		Totals:         totals.segment(totalsLabel, totalsLabel),
+	}
+	for key, acc := range segments {
+		report.Segments = append(report.Segments, acc.segment(key.category, key.month))
+	}
+	for category, acc := range categories {
+		report.Categories = append(report.Categories, acc.segment(category, totalsLabel))
+	}
+
+	sort.Slice(report.Segments, func(i, j int) bool {
+		if report.Segments[i].Month != report.Segments[j].Month {
+			return report.Segments[i].Month < report.Segments[j].Month
+		}
+		return report.Segments[i].DevelopmentCategory < report.Segments[j].DevelopmentCategory
+	})
+	sort.Slice(report.Categories, func(i, j int) bool {
+		return report.Categories[i].DevelopmentCategory < report.Categories[j].DevelopmentCategory
+	})
+
+	return report, nil
+}
@@ -0,0 +1,291 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// IntegrityViolationKind names an approval invariant that the data breaks
+type IntegrityViolationKind string
+
+const (
+	ViolationFinalApproverCount       IntegrityViolationKind = "FINAL_APPROVER_COUNT"
+	ViolationAssignmentCounts         IntegrityViolationKind = "ASSIGNMENT_COUNTS"
+	ViolationResolvedIssueWithoutText IntegrityViolationKind = "RESOLVED_ISSUE_WITHOUT_RESOLUTION"
+	ViolationApprovedWithoutFinal     IntegrityViolationKind = "APPROVED_WITHOUT_FINAL_APPROVAL"
+)
+
+// integrityCheckSchedule runs the checks nightly, after the 1 AM cleanup
+const integrityCheckSchedule = "0 2 * * *"
+
+// IntegrityViolation is one record that breaks an invariant, with enough detail to repair it by hand
+type IntegrityViolation struct {
+	Kind          IntegrityViolationKind `json:"kind"`
+	EntityType    string                 `json:"entity_type"`
+	EntityID      uuid.UUID              `json:"entity_id"`
+	ApplicationID *uuid.UUID             `json:"application_id,omitempty"`
+	Description   string                 `json:"description"`
+}
+
+// IntegrityReport is the outcome of one run of the integrity checks
+type IntegrityReport struct {
+	CheckedAt      time.Time                      `json:"checked_at"`
+	DurationMs     int64                          `json:"duration_ms"`
+	ViolationCount int                            `json:"violation_count"`
+	CountsByKind   map[IntegrityViolationKind]int `json:"counts_by_kind"`
+	Violations     []IntegrityViolation           `json:"violations"`
+}
+
+type IntegrityReportRepository interface {
+	RunIntegrityChecks(ctx context.Context) (*IntegrityReport, error)
+	GetLatestIntegrityReport() *IntegrityReport
+}
+
+type integrityReportRepository struct {
+	db *gorm.DB
+
+	mu     sync.RWMutex
+	latest *IntegrityReport
+}
+
+func NewIntegrityReportRepository(db *gorm.DB) IntegrityReportRepository {
+	return &integrityReportRepository{
+		db: db,
+	}
+}
+
+// RunIntegrityChecks validates the approval invariants, logs every violation found and keeps
+// the report as the latest run
+func (r *integrityReportRepository) RunIntegrityChecks(ctx context.Context) (*IntegrityReport, error) {
+	started := time.Now()
+	db := r.db.WithContext(ctx)
+
+	checks := []func(db *gorm.DB) ([]IntegrityViolation, error){
+		r.checkFinalApprovers,
+		r.checkAssignmentCounts,
+		r.checkResolvedIssues,
+		r.checkApprovedApplications,
+	}
+
+	violations := []IntegrityViolation{}
+	for _, check := range checks {
+		found, err := check(db)
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, found...)
+	}
+
+	report := &IntegrityReport{
+		CheckedAt:      started,
+		DurationMs:     time.Since(started).Milliseconds(),
+		ViolationCount: len(violations),
+		CountsByKind:   map[IntegrityViolationKind]int{},
+		Violations:     violations,
+	}
+	for _, violation := range violations {
+		report.CountsByKind[violation.Kind]++
+
+		fields := []zap.Field{
+			zap.String("kind", string(violation.Kind)),
+			zap.String("entityType", violation.EntityType),
+			zap.String("entityID", violation.EntityID.String()),
+			zap.String("description", violation.Description),
+		}
+		if violation.ApplicationID != nil {
+			fields = append(fields, zap.String("applicationID", violation.ApplicationID.String()))
+		}
+		config.Logger.Warn("Approval integrity violation", fields...)
+	}
+
+	config.Logger.Info("Approval integrity checks completed",
+		zap.Int("violations", report.ViolationCount),
+		zap.Int64("durationMs", report.DurationMs))
+
+	r.mu.Lock()
+	r.latest = report
+	r.mu.Unlock()
+
+	return report, nil
+}
+
+// GetLatestIntegrityReport returns the last completed run, or nil if the checks have not run yet
+func (r *integrityReportRepository) GetLatestIntegrityReport() *IntegrityReport {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.latest
+}
+
+// checkFinalApprovers finds active groups without exactly one active final approver
+func (r *integrityReportRepository) checkFinalApprovers(db *gorm.DB) ([]IntegrityViolation, error) {
+	var rows []struct {
+		ID             uuid.UUID
+		Name           string
+		FinalApprovers int64
+	}
+	if err := db.Raw(`
+		SELECT g.id, g.name, COUNT(m.id) AS final_approvers
+		FROM approval_groups g
+		LEFT JOIN approval_group_members m
+			ON m.approval_group_id = g.id
+			AND m.is_final_approver = true
+			AND m.is_active = true
+			AND m.deleted_at IS NULL
+		WHERE g.is_active = true AND g.deleted_at IS NULL
+		GROUP BY g.id, g.name
+		HAVING COUNT(m.id) <> 1`).
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to check final approvers: %w", err)
+	}
+
+	violations := make([]IntegrityViolation, 0, len(rows))
+	for _, row := range rows {
+		violations = append(violations, IntegrityViolation{
+			Kind:        ViolationFinalApproverCount,
+			EntityType:  "approval_group",
+			EntityID:    row.ID,
+			Description: fmt.Sprintf("group %q has %d active final approvers, expected 1", row.Name, row.FinalApprovers),
+		})
+	}
+	return violations, nil
+}
+
+// checkAssignmentCounts finds active assignments whose stored counts differ from their decision
+// rows, counted the same way updateAssignmentStatistics counts them
+func (r *integrityReportRepository) checkAssignmentCounts(db *gorm.DB) ([]IntegrityViolation, error) {
+	var rows []struct {
+		ID             uuid.UUID
+		ApplicationID  uuid.UUID
+		ApprovedCount  int64
+		RejectedCount  int64
+		PendingCount   int64
+		RegularMembers int64
+		Approved       int64
+		Rejected       int64
+		Skipped        int64
+	}
+	if err := db.Raw(`
+		SELECT a.id, a.application_id, a.approved_count, a.rejected_count, a.pending_count,
+			(SELECT COUNT(*) FROM approval_group_members m
+				WHERE m.approval_group_id = a.approval_group_id
+				AND m.is_active = true AND m.is_final_approver = false AND m.deleted_at IS NULL) AS regular_members,
+			(SELECT COUNT(*) FROM member_approval_decisions d
+				JOIN approval_group_members m ON m.id = d.member_id
+				WHERE d.assignment_id = a.id AND d.deleted_at IS NULL
+				AND d.status = ? AND m.is_final_approver = false) AS approved,
+			(SELECT COUNT(*) FROM member_approval_decisions d
+				JOIN approval_group_members m ON m.id = d.member_id
+				WHERE d.assignment_id = a.id AND d.deleted_at IS NULL
+				AND d.status = ? AND m.is_final_approver = false) AS rejected,
+			(SELECT COUNT(*) FROM member_approval_decisions d
+				JOIN approval_group_members m ON m.id = d.member_id
+				WHERE d.assignment_id = a.id AND d.deleted_at IS NULL
+				AND d.status = ? AND m.is_final_approver = false AND m.is_active = true) AS skipped
+		FROM application_group_assignments a
+		WHERE a.is_active = true AND a.deleted_at IS NULL`,
+		models.DecisionApproved, models.DecisionRejected, models.DecisionSkipped).
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to check assignment counts: %w", err)
+	}
+
+	violations := []IntegrityViolation{}
+	for _, row := range rows {
+		pending := row.RegularMembers - row.Approved - row.Rejected - row.Skipped
+		if pending < 0 {
+			pending = 0
+		}
+		if row.ApprovedCount == row.Approved && row.RejectedCount == row.Rejected && row.PendingCount == pending {
+			continue
+		}
+
+		applicationID := row.ApplicationID
+		violations = append(violations, IntegrityViolation{
+			Kind:          ViolationAssignmentCounts,
+			EntityType:    "application_group_assignment",
+			EntityID:      row.ID,
+			ApplicationID: &applicationID,
+			Description: fmt.Sprintf("stored approved/rejected/pending %d/%d/%d, decision rows give %d/%d/%d",
+				row.ApprovedCount, row.RejectedCount, row.PendingCount, row.Approved, row.Rejected, pending),
+		})
+	}
+	return violations, nil
+}
+
+// checkResolvedIssues finds issues marked resolved with no resolution recorded
+func (r *integrityReportRepository) checkResolvedIssues(db *gorm.DB) ([]IntegrityViolation, error) {
+	var issues []models.ApplicationIssue
+	if err := db.
+		Select("id", "application_id", "title").
+		Where("is_resolved = ?", true).
+		Where("resolution IS NULL OR TRIM(resolution) = ''").
+		Find(&issues).Error; err != nil {
+		return nil, fmt.Errorf("failed to check resolved issues: %w", err)
+	}
+
+	violations := make([]IntegrityViolation, 0, len(issues))
+	for _, issue := range issues {
+		applicationID := issue.ApplicationID
+		violations = append(violations, IntegrityViolation{
+			Kind:          ViolationResolvedIssueWithoutText,
+			EntityType:    "application_issue",
+			EntityID:      issue.ID,
+			ApplicationID: &applicationID,
+			Description:   fmt.Sprintf("issue %q is resolved but has no resolution text", issue.Title),
+		})
+	}
+	return violations, nil
+}
+
+// checkApprovedApplications finds approved applications without an approving final decision
+func (r *integrityReportRepository) checkApprovedApplications(db *gorm.DB) ([]IntegrityViolation, error) {
+	var applications []models.Application
+	if err := db.
+		Select("id", "plan_number").
+		Where("status = ?", models.ApprovedApplication).
+		Where("NOT EXISTS (?)", db.Model(&models.FinalApproval{}).
+			Select("1").
+			Where("final_approvals.application_id = applications.id AND final_approvals.decision = ?", models.ApprovedApplication)).
+		Find(&applications).Error; err != nil {
+		return nil, fmt.Errorf("failed to check approved applications: %w", err)
+	}
+
+	violations := make([]IntegrityViolation, 0, len(applications))
+	for _, application := range applications {
+		applicationID := application.ID
+		violations = append(violations, IntegrityViolation{
+			Kind:          ViolationApprovedWithoutFinal,
+			EntityType:    "application",
+			EntityID:      application.ID,
+			ApplicationID: &applicationID,
+			Description:   fmt.Sprintf("application %s is approved but has no final approval", application.PlanNumber),
+		})
+	}
+	return violations, nil
+}
+
+// RunScheduledIntegrityChecks runs the approval integrity checks nightly at 2 AM until ctx is
+// cancelled
+func RunScheduledIntegrityChecks(ctx context.Context, repo IntegrityReportRepository) {
+	c := cron.New()
+
+	c.AddFunc(integrityCheckSchedule, func() {
+		if _, err := repo.RunIntegrityChecks(ctx); err != nil {
+			config.Logger.Error("Scheduled approval integrity checks failed", zap.Error(err))
+		}
+	})
+
+	c.Start()
+
+	// Keep running until shutdown, then wait for a running job to finish
+	<-ctx.Done()
+	<-c.Stop().Done()
+}
@@ -0,0 +1,465 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// LevyBenchmarkNone selects, in a filter, applications whose stand has no ward or whose tariff
+// has no development category
+const LevyBenchmarkNone = "none"
+
+// Reasons an application is flagged on the levy benchmarking report
+const (
+	OutlierMissingPlanArea     = "MISSING_PLAN_AREA"       // No plan area, so the levy cannot be checked
+	OutlierLevyAboveBenchmark  = "LEVY_ABOVE_BENCHMARK"    // Levy per square metre too high, often a plan area entered too small
+	OutlierLevyBelowBenchmark  = "LEVY_BELOW_BENCHMARK"    // Levy per square metre too low, often a plan area entered too large
+	OutlierMarketValueDeviates = "MARKET_VALUE_DEVIATES"   // Stand value per square metre far from the benchmark
+	OutlierNoBenchmark         = "NO_BENCHMARK_CONFIGURED" // Not an outlier; nothing to compare against
+)
+
+// LevyBenchmarkFilter narrows the report to a period and, for drill-downs, to one ward and
+// category. Empty Ward and CategoryID select all of them; LevyBenchmarkNone selects the
+// applications without one.
+type LevyBenchmarkFilter struct {
+	From         time.Time
+	To           time.Time // Exclusive
+	Ward         string
+	CategoryID   string
+	OutliersOnly bool
+}
+
+// LevyBenchmarkApplication is one application's levy and stand value set against its benchmark
+type LevyBenchmarkApplication struct {
+	ApplicationID             uuid.UUID        `json:"application_id"`
+	PlanNumber                string           `json:"plan_number"`
+	Status                    string           `json:"status"`
+	SubmissionDate            time.Time        `json:"submission_date"`
+	StandID                   *uuid.UUID       `json:"stand_id"`
+	StandNumber               *string          `json:"stand_number"`
+	Ward                      *string          `json:"ward"`
+	CategoryID                *uuid.UUID       `json:"category_id"`
+	Category                  string           `json:"category"`
+	PlanArea                  *decimal.Decimal `json:"plan_area"`
+	DevelopmentLevy           decimal.Decimal  `json:"development_levy"`
+	LevyPerSquareMeter        *decimal.Decimal `json:"levy_per_square_meter"`
+	BenchmarkLevy             *decimal.Decimal `json:"benchmark_levy_per_square_meter"`
+	LevyDeviationPercent      *decimal.Decimal `json:"levy_deviation_percent"`
+	StandArea                 *decimal.Decimal `json:"stand_area"`
+	StandValue                *decimal.Decimal `json:"stand_value"`
+	MarketValuePerSquareMeter *decimal.Decimal `json:"market_value_per_square_meter"`
+	BenchmarkMarketValue      *decimal.Decimal `json:"benchmark_market_value_per_square_meter"`
+	MarketValueDeviation      *decimal.Decimal `json:"market_value_deviation_percent"`
+	BenchmarkID               *uuid.UUID       `json:"benchmark_id"`
+	IsOutlier                 bool             `json:"is_outlier"`
+	Flags                     []string         `json:"flags"`
+}
+
+// LevyBenchmarkGroup sums up one ward and development category. AverageLevyPerSquareMeter is
+// the total levy over the total plan area of the applications that have one.
+type LevyBenchmarkGroup struct {
+	Ward                      *string          `json:"ward"`
+	CategoryID                *uuid.UUID       `json:"category_id"`
+	Category                  string           `json:"category"`
+	Applications              int              `json:"applications"`
+	Outliers                  int              `json:"outliers"`
+	TotalLevy                 decimal.Decimal  `json:"total_levy"`
+	TotalPlanArea             decimal.Decimal  `json:"total_plan_area"`
+	AverageLevyPerSquareMeter *decimal.Decimal `json:"average_levy_per_square_meter"`
+	MedianLevyPerSquareMeter  *decimal.Decimal `json:"median_levy_per_square_meter"`
+	BenchmarkLevy             *decimal.Decimal `json:"benchmark_levy_per_square_meter"`
+	LevyDeviationPercent      *decimal.Decimal `json:"levy_deviation_percent"` // Of the average from the benchmark
+	MedianMarketValue         *decimal.Decimal `json:"median_market_value_per_square_meter"`
+	BenchmarkMarketValue      *decimal.Decimal `json:"benchmark_market_value_per_square_meter"`
+	BenchmarkID               *uuid.UUID       `json:"benchmark_id"`
+}
+
+// LevyBenchmarkReport compares the levies charged in a period with the configured benchmarks
+type LevyBenchmarkReport struct {
+	PeriodFrom   time.Time            `json:"period_from"`
+	PeriodTo     time.Time            `json:"period_to"` // Exclusive
+	GeneratedAt  time.Time            `json:"generated_at"`
+	Applications int                  `json:"applications"`
+	Outliers     int                  `json:"outliers"`
+	Groups       []LevyBenchmarkGroup `json:"groups"`
+}
+
+type LevyBenchmarkRepository interface {
+	// Report
+	GetLevyBenchmarkReport(ctx context.Context, filter LevyBenchmarkFilter) (*LevyBenchmarkReport, error)
+	GetLevyBenchmarkApplications(ctx context.Context, filter LevyBenchmarkFilter) ([]LevyBenchmarkApplication, error)
+
+	// Benchmark configuration
+	GetLevyBenchmarks() ([]models.LevyBenchmark, error)
+	CreateLevyBenchmark(benchmark *models.LevyBenchmark) (*models.LevyBenchmark, error)
+	UpdateLevyBenchmark(id uuid.UUID, updates map[string]interface{}) (*models.LevyBenchmark, error)
+	DeleteLevyBenchmark(id uuid.UUID) error
+}
+
+type levyBenchmarkRepository struct {
+	db *gorm.DB
+}
+
+func NewLevyBenchmarkRepository(db *gorm.DB) LevyBenchmarkRepository {
+	return &levyBenchmarkRepository{
+		db: db,
+	}
+}
+
+type levyBenchmarkRow struct {
+	ApplicationID  uuid.UUID
+	PlanNumber     string
+	Status         string
+	SubmissionDate time.Time
+	PlanArea       *decimal.Decimal
+	Levy           decimal.Decimal
+	StandID        *uuid.UUID
+	StandNumber    *string
+	Ward           *string
+	StandArea      *decimal.Decimal
+	StandValue     *decimal.Decimal
+	CategoryID     *uuid.UUID
+	Category       *string
+}
+
+var hundred = decimal.NewFromInt(100)
+
+// GetLevyBenchmarkApplications lists the applications submitted in the period with their levy
+// and stand value per square metre, each checked against the benchmark of its ward and
+// category, or the council-wide benchmark of its category when the ward has none
+func (r *levyBenchmarkRepository) GetLevyBenchmarkApplications(ctx context.Context, filter LevyBenchmarkFilter) ([]LevyBenchmarkApplication, error) {
+	query := r.db.WithContext(ctx).Table("applications").
+		Select(`applications.id AS application_id, applications.plan_number, applications.status,
+			applications.submission_date, applications.plan_area, applications.development_levy AS levy,
+			stands.id AS stand_id, stands.stand_number, stands.ward, stands.area_square_meter AS stand_area,
+			stands.tax_exclusive_stand_price AS stand_value,
+			development_categories.id AS category_id, development_categories.name AS category`).
+		Joins("LEFT JOIN stands ON stands.id = applications.stand_id").
+		Joins(categoryJoins).
+		Where("applications.deleted_at IS NULL").
+		Where("applications.development_levy IS NOT NULL").
+		Where("applications.submission_date >= ? AND applications.submission_date < ?", filter.From, filter.To)
+
+	switch filter.Ward {
+	case "":
+	case LevyBenchmarkNone:
+		query = query.Where("stands.ward IS NULL")
+	default:
+		query = query.Where("stands.ward = ?", filter.Ward)
+	}
+	switch filter.CategoryID {
+	case "":
+	case LevyBenchmarkNone:
+		query = query.Where("development_categories.id IS NULL")
+	default:
+		query = query.Where("development_categories.id = ?", filter.CategoryID)
+	}
+
+	var rows []levyBenchmarkRow
+	if err := query.Order("applications.submission_date, applications.plan_number").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load application levies: %w", err)
+	}
+
+	benchmarks, err := r.GetLevyBenchmarks()
+	if err != nil {
+		return nil, err
+	}
+
+	applications := make([]LevyBenchmarkApplication, 0, len(rows))
+	for _, row := range rows {
+		application := compareWithBenchmark(row, findLevyBenchmark(benchmarks, row.CategoryID, row.Ward))
+		if filter.OutliersOnly && !application.IsOutlier {
+			continue
+		}
+		applications = append(applications, application)
+	}
+	return applications, nil
+}
+
+// GetLevyBenchmarkReport sums up the period's applications per ward and development category
+func (r *levyBenchmarkRepository) GetLevyBenchmarkReport(ctx context.Context, filter LevyBenchmarkFilter) (*LevyBenchmarkReport, error) {
+	// Groups need every application, outlier or not
+	all := filter
+	all.OutliersOnly = false
+	applications, err := r.GetLevyBenchmarkApplications(ctx, all)
+	if err != nil {
+		return nil, err
+	}
+
+	type groupKey struct {
+		ward     string
+		category string
+	}
+	groups := map[groupKey]*LevyBenchmarkGroup{}
+	levyWithArea := map[groupKey]decimal.Decimal{}
+	levyRates := map[groupKey][]decimal.Decimal{}
+	marketValues := map[groupKey][]decimal.Decimal{}
+	report := &LevyBenchmarkReport{
+		PeriodFrom:  filter.From,
+		PeriodTo:    filter.To,
+		GeneratedAt: time.Now(),
+		Groups:      []LevyBenchmarkGroup{},
+	}
+
+	for _, application := range applications {
+		key := groupKey{ward: LevyBenchmarkNone, category: LevyBenchmarkNone}
+		if application.Ward != nil {
+			key.ward = *application.Ward
+		}
+		if application.CategoryID != nil {
+			key.category = application.CategoryID.String()
+		}
+		group, ok := groups[key]
+		if !ok {
+			group = &LevyBenchmarkGroup{
+				Ward:                 application.Ward,
+				CategoryID:           application.CategoryID,
+				Category:             application.Category,
+				BenchmarkLevy:        application.BenchmarkLevy,
+				BenchmarkMarketValue: application.BenchmarkMarketValue,
+				BenchmarkID:          application.BenchmarkID,
+			}
+			groups[key] = group
+		}
+
+		group.Applications++
+		report.Applications++
+		if application.IsOutlier {
+			group.Outliers++
+			report.Outliers++
+		}
+		group.TotalLevy = group.TotalLevy.Add(application.DevelopmentLevy)
+		if application.LevyPerSquareMeter != nil {
+			group.TotalPlanArea = group.TotalPlanArea.Add(*application.PlanArea)
+			levyWithArea[key] = levyWithArea[key].Add(application.DevelopmentLevy)
+			levyRates[key] = append(levyRates[key], *application.LevyPerSquareMeter)
+		}
+		if application.MarketValuePerSquareMeter != nil {
+			marketValues[key] = append(marketValues[key], *application.MarketValuePerSquareMeter)
+		}
+	}
+
+	for key, group := range groups {
+		if group.TotalPlanArea.IsPositive() {
+			average := levyWithArea[key].Div(group.TotalPlanArea).Round(2)
+			group.AverageLevyPerSquareMeter = &average
+			group.LevyDeviationPercent = deviationPercent(average, group.BenchmarkLevy)
+		}
+		group.MedianLevyPerSquareMeter = medianDecimal(levyRates[key])
+		group.MedianMarketValue = medianDecimal(marketValues[key])
+		report.Groups = append(report.Groups, *group)
+	}
+
+	// Wards in order, the council-wide rows last, then categories by name
+	sort.Slice(report.Groups, func(i, j int) bool {
+		a, b := report.Groups[i], report.Groups[j]
+		if (a.Ward == nil) != (b.Ward == nil) {
+			return b.Ward == nil
+		}
+		if a.Ward != nil && *a.Ward != *b.Ward {
+			return *a.Ward < *b.Ward
+		}
+		return a.Category < b.Category
+	})
+	return report, nil
+}
+
+// findLevyBenchmark picks the ward's own benchmark for the category, falling back to the
+// category's council-wide one
+func findLevyBenchmark(benchmarks []models.LevyBenchmark, categoryID *uuid.UUID, ward *string) *models.LevyBenchmark {
+	if categoryID == nil {
+		return nil
+	}
+	var councilWide *models.LevyBenchmark
+	for i := range benchmarks {
+		benchmark := &benchmarks[i]
+		if benchmark.DevelopmentCategoryID != *categoryID {
+			continue
+		}
+		if benchmark.Ward == nil {
+			councilWide = benchmark
+		} else if ward != nil && *benchmark.Ward == *ward {
+			return benchmark
+		}
+	}
+	return councilWide
+}
+
+func compareWithBenchmark(row levyBenchmarkRow, benchmark *models.LevyBenchmark) LevyBenchmarkApplication {
+	application := LevyBenchmarkApplication{
+		ApplicationID:   row.ApplicationID,
+		PlanNumber:      row.PlanNumber,
+		Status:          row.Status,
+		SubmissionDate:  row.SubmissionDate,
+		StandID:         row.StandID,
+		StandNumber:     row.StandNumber,
+		Ward:            row.Ward,
+		CategoryID:      row.CategoryID,
+		Category:        uncategorisedLabel,
+		PlanArea:        row.PlanArea,
+		DevelopmentLevy: row.Levy,
+		StandArea:       row.StandArea,
+		StandValue:      row.StandValue,
+		Flags:           []string{},
+	}
+	if row.Category != nil {
+		application.Category = *row.Category
+	}
+
+	if row.PlanArea != nil && row.PlanArea.IsPositive() {
+		rate := row.Levy.Div(*row.PlanArea).Round(2)
+		application.LevyPerSquareMeter = &rate
+	} else {
+		application.Flags = append(application.Flags, OutlierMissingPlanArea)
+		application.IsOutlier = true
+	}
+	if row.StandArea != nil && row.StandArea.IsPositive() && row.StandValue != nil && row.StandValue.IsPositive() {
+		value := row.StandValue.Div(*row.StandArea).Round(2)
+		application.MarketValuePerSquareMeter = &value
+	}
+
+	if benchmark == nil {
+		application.Flags = append(application.Flags, OutlierNoBenchmark)
+		return application
+	}
+	application.BenchmarkID = &benchmark.ID
+	application.BenchmarkLevy = &benchmark.LevyPerSquareMeter
+	application.BenchmarkMarketValue = benchmark.MarketValuePerSquareMeter
+
+	if application.LevyPerSquareMeter != nil {
+		application.LevyDeviationPercent = deviationPercent(*application.LevyPerSquareMeter, application.BenchmarkLevy)
+		if deviation := application.LevyDeviationPercent; deviation != nil && deviation.Abs().GreaterThan(benchmark.TolerancePercent) {
+			if deviation.IsPositive() {
+				application.Flags = append(application.Flags, OutlierLevyAboveBenchmark)
+			} else {
+				application.Flags = append(application.Flags, OutlierLevyBelowBenchmark)
+			}
+			application.IsOutlier = true
+		}
+	}
+	if application.MarketValuePerSquareMeter != nil {
+		application.MarketValueDeviation = deviationPercent(*application.MarketValuePerSquareMeter, application.BenchmarkMarketValue)
+		if deviation := application.MarketValueDeviation; deviation != nil && deviation.Abs().GreaterThan(benchmark.TolerancePercent) {
+			application.Flags = append(application.Flags, OutlierMarketValueDeviates)
+			application.IsOutlier = true
+		}
+	}
+	return application
+}
+
+// deviationPercent is how far value lies above (positive) or below the benchmark, in percent
+func deviationPercent(value decimal.Decimal, benchmark *decimal.Decimal) *decimal.Decimal {
+	if benchmark == nil || !benchmark.IsPositive() {
+		return nil
+	}
+	deviation := value.Sub(*benchmark).Div(*benchmark).Mul(hundred).Round(2)
+	return &deviation
+}
+
+func medianDecimal(values []decimal.Decimal) *decimal.Decimal {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := append([]decimal.Decimal(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].LessThan(sorted[j]) })
+	middle := len(sorted) / 2
+	median := sorted[middle]
+	if len(sorted)%2 == 0 {
+		median = sorted[middle-1].Add(sorted[middle]).Div(decimal.NewFromInt(2))
+	}
+	median = median.Round(2)
+	return &median
+}
+
+// GetLevyBenchmarks lists the configured benchmarks, the council-wide ones first
+func (r *levyBenchmarkRepository) GetLevyBenchmarks() ([]models.LevyBenchmark, error) {
+	var benchmarks []models.LevyBenchmark
+	if err := r.db.Preload("DevelopmentCategory").
+		Order("ward IS NOT NULL, ward, created_at").
+		Find(&benchmarks).Error; err != nil {
+		return nil, fmt.Errorf("failed to load levy benchmarks: %w", err)
+	}
+	return benchmarks, nil
+}
+
+// CreateLevyBenchmark adds a benchmark. A ward, or the whole council, has at most one per category.
+func (r *levyBenchmarkRepository) CreateLevyBenchmark(benchmark *models.LevyBenchmark) (*models.LevyBenchmark, error) {
+	var category models.DevelopmentCategory
+	if err := r.db.Select("id").Where("id = ?", benchmark.DevelopmentCategoryID).First(&category).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("development category not found")
+		}
+		return nil, fmt.Errorf("failed to load development category: %w", err)
+	}
+	if err := r.checkDuplicateBenchmark(uuid.Nil, benchmark.DevelopmentCategoryID, benchmark.Ward); err != nil {
+		return nil, err
+	}
+
+	if err := r.db.Create(benchmark).Error; err != nil {
+		return nil, fmt.Errorf("failed to create levy benchmark: %w", err)
+	}
+	return benchmark, nil
+}
+
+// UpdateLevyBenchmark changes a benchmark's figures, ward or notes
+func (r *levyBenchmarkRepository) UpdateLevyBenchmark(id uuid.UUID, updates map[string]interface{}) (*models.LevyBenchmark, error) {
+	var benchmark models.LevyBenchmark
+	if err := r.db.Where("id = ?", id).First(&benchmark).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("levy benchmark not found")
+		}
+		return nil, fmt.Errorf("failed to load levy benchmark: %w", err)
+	}
+
+	if ward, ok := updates["ward"]; ok {
+		if err := r.checkDuplicateBenchmark(id, benchmark.DevelopmentCategoryID, ward.(*string)); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.db.Model(&benchmark).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to update levy benchmark: %w", err)
+	}
+	if err := r.db.Where("id = ?", id).First(&benchmark).Error; err != nil {
+		return nil, fmt.Errorf("failed to reload levy benchmark: %w", err)
+	}
+	return &benchmark, nil
+}
+
+// DeleteLevyBenchmark removes a benchmark; its applications fall back to the council-wide one
+func (r *levyBenchmarkRepository) DeleteLevyBenchmark(id uuid.UUID) error {
+	result := r.db.Where("id = ?", id).Delete(&models.LevyBenchmark{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete levy benchmark: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("levy benchmark not found")
+	}
+	return nil
+}
+
+func (r *levyBenchmarkRepository) checkDuplicateBenchmark(id uuid.UUID, categoryID uuid.UUID, ward *string) error {
+	query := r.db.Model(&models.LevyBenchmark{}).
+		Where("development_category_id = ? AND id <> ?", categoryID, id)
+	if ward == nil {
+		query = query.Where("ward IS NULL")
+	} else {
+		query = query.Where("ward = ?", *ward)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check for an existing levy benchmark: %w", err)
+	}
+	if count > 0 {
+		return errors.New("a levy benchmark already exists for this ward and category")
+	}
+	return nil
+}
@@ -0,0 +1,331 @@
+package repositories
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+	"town-planning-backend/db/models"
+	"town-planning-backend/utils"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// NationalReportSchemaVersion is the version of the ministry's quarterly return this API produces.
+// Bump it whenever the JSON or CSV layout changes.
+const NationalReportSchemaVersion = "1.0"
+
+const (
+	uncategorisedLabel = "UNCATEGORISED"
+	totalsLabel        = "ALL"
+	reportCurrency     = "USD"
+)
+
+type NationalReportRepository interface {
+	GetQuarterlyStatistics(ctx context.Context, year int, quarter int) (*QuarterlyStatistics, error)
+	GetReportSubmission(ctx context.Context, year int, quarter int) (*models.NationalReportSubmission, error)
+	GetReportSubmissions() ([]models.NationalReportSubmission, error)
+	LockQuarter(tx *gorm.DB, year int, quarter int, lockedBy uuid.UUID, submissionReference *string, notes *string) (*models.NationalReportSubmission, error)
+}
+
+type nationalReportRepository struct {
+	db *gorm.DB
+}
+
+func NewNationalReportRepository(db *gorm.DB) NationalReportRepository {
+	return &nationalReportRepository{
+		db: db,
+	}
+}
+
+// QuarterlyCategoryStatistics is one row of the ministry return
+type QuarterlyCategoryStatistics struct {
+	DevelopmentCategory   string          `json:"development_category"`
+	ApplicationsReceived  int64           `json:"applications_received"`
+	ApplicationsApproved  int64           `json:"applications_approved"`
+	ApplicationsRejected  int64           `json:"applications_rejected"`
+	AverageProcessingDays decimal.Decimal `json:"average_processing_days"`
+	LevyRevenue           decimal.Decimal `json:"levy_revenue"`
+}
+
+// QuarterlyStatistics is the full quarterly return in the ministry's schema. It contains no
+// generation timestamps so that identical data always produces an identical checksum.
+type QuarterlyStatistics struct {
+	SchemaVersion  string                        `json:"schema_version"`
+	LocalAuthority string                        `json:"local_authority"`
+	Year           int                           `json:"year"`
+	Quarter        int                           `json:"quarter"`
+	PeriodStart    string                        `json:"period_start"`
+	PeriodEnd      string                        `json:"period_end"`
+	Currency       string                        `json:"currency"`
+	Categories     []QuarterlyCategoryStatistics `json:"categories"`
+	Totals         QuarterlyCategoryStatistics   `json:"totals"`
+}
+
+// QuarterBounds returns the first instant of the quarter and the first instant after it
+func QuarterBounds(year int, quarter int) (time.Time, time.Time, error) {
+	if quarter < 1 || quarter > 4 {
+		return time.Time{}, time.Time{}, errors.New("quarter must be between 1 and 4")
+	}
+	if year < 2000 || year > 9999 {
+		return time.Time{}, time.Time{}, errors.New("invalid year")
+	}
+
+	location := utils.DateLocation
+	if location == nil {
+		location = time.Local
+	}
+	start := time.Date(year, time.Month((quarter-1)*3+1), 1, 0, 0, 0, 0, location)
+	return start, start.AddDate(0, 3, 0), nil
+}
+
+type categoryCount struct {
+	Category string
+	Count    int64
+	SumDays  float64
+}
+
+type categoryAmount struct {
+	Category string
+	Amount   decimal.Decimal
+}
+
+// Applications are categorised by the development category of their tariff
+const categoryJoins = `
+	LEFT JOIN tariffs ON tariffs.id = applications.tariff_id
+	LEFT JOIN development_categories ON development_categories.id = tariffs.development_category_id`
+
+// pausedDaysSQL is the time, in days, an application's SLA clocks were paused waiting on the
+// applicant between two of its timestamps. A null end counts pauses up to now.
+func pausedDaysSQL(startExpr, endExpr string) string {
+	return fmt.Sprintf(`COALESCE((
+		SELECT SUM(GREATEST(EXTRACT(EPOCH FROM (LEAST(COALESCE(p.resumed_at, NOW()), %s) - GREATEST(p.paused_at, %s))), 0))
+		FROM application_sla_pauses p WHERE p.application_id = applications.id
+	), 0) / 86400`, endExpr, startExpr)
+}
+
+// Processing days leave out the time spent waiting on the applicant
+func (r *nationalReportRepository) countByCategory(db *gorm.DB, dateColumn string, start, end time.Time, withProcessingDays bool) ([]categoryCount, error) {
+	selectClause := "COALESCE(development_categories.name, ?) AS category, COUNT(*) AS count"
+	if withProcessingDays {
+		selectClause += fmt.Sprintf(", COALESCE(SUM(EXTRACT(EPOCH FROM (applications.%s - applications.submission_date)) / 86400 - %s), 0) AS sum_days",
+			dateColumn, pausedDaysSQL("applications.submission_date", "applications."+dateColumn))
+	}
+
+	var rows []categoryCount
+	err := db.Table("applications").
+		Select(selectClause, uncategorisedLabel).
+		Joins(categoryJoins).
+		Where("applications.deleted_at IS NULL").
+		Where(fmt.Sprintf("applications.%s >= ? AND applications.%s < ?", dateColumn, dateColumn), start, end).
+		Group("1").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// GetQuarterlyStatistics computes the live figures for a quarter
+func (r *nationalReportRepository) GetQuarterlyStatistics(ctx context.Context, year int, quarter int) (*QuarterlyStatistics, error) {
+	start, end, err := QuarterBounds(year, quarter)
+	if err != nil {
+		return nil, err
+	}
+	db := r.db.WithContext(ctx)
+
+	received, err := r.countByCategory(db, "submission_date", start, end, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count received applications: %w", err)
+	}
+	approved, err := r.countByCategory(db, "final_approval_date", start, end, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count approved applications: %w", err)
+	}
+	rejected, err := r.countByCategory(db, "rejection_date", start, end, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count rejected applications: %w", err)
+	}
+
+	// Development levy actually collected in the quarter; reversals reduce revenue
+	var levies []categoryAmount
+	if err := db.Table("payments").
+		Select(`COALESCE(development_categories.name, ?) AS category,
+			COALESCE(SUM(CASE WHEN payments.is_reversal THEN -ABS(payments.amount) ELSE payments.amount END), 0) AS amount`, uncategorisedLabel).
+		Joins("LEFT JOIN applications ON applications.id = payments.application_id").
+		Joins(categoryJoins).
+		Where("payments.payment_for = ? AND payments.payment_status = ?", models.PaymentForDevelopmentLevy, models.PaidPayment).
+		Where("payments.payment_date >= ? AND payments.payment_date < ?", start, end).
+		Group("1").
+		Scan(&levies).Error; err != nil {
+		return nil, fmt.Errorf("failed to sum levy revenue: %w", err)
+	}
+
+	type accumulator struct {
+		row     QuarterlyCategoryStatistics
+		sumDays float64
+		decided int64
+	}
+	byCategory := map[string]*accumulator{}
+	get := func(category string) *accumulator {
+		acc, ok := byCategory[category]
+		if !ok {
+			acc = &accumulator{row: QuarterlyCategoryStatistics{DevelopmentCategory: category}}
+			byCategory[category] = acc
+		}
+		return acc
+	}
+
+	for _, row := range received {
+		get(row.Category).row.ApplicationsReceived = row.Count
+	}
+	for _, row := range approved {
+		acc := get(row.Category)
+		acc.row.ApplicationsApproved = row.Count
+		acc.sumDays += row.SumDays
+		acc.decided += row.Count
+	}
+	for _, row := range rejected {
+		acc := get(row.Category)
+		acc.row.ApplicationsRejected = row.Count
+		acc.sumDays += row.SumDays
+		acc.decided += row.Count
+	}
+	for _, row := range levies {
+		get(row.Category).row.LevyRevenue = row.Amount
+	}
+
+	stats := &QuarterlyStatistics{
+		SchemaVersion:  NationalReportSchemaVersion,
+		LocalAuthority: os.Getenv("LOCAL_AUTHORITY_NAME"),
+		Year:           year,
+		Quarter:        quarter,
+		PeriodStart:    start.Format("2006-01-02"),
+		PeriodEnd:      end.AddDate(0, 0, -1).Format("2006-01-02"),
+		Currency:       reportCurrency,
+		Categories:     make([]QuarterlyCategoryStatistics, 0, len(byCategory)),
+	}
+
+	// Average processing days covers every application decided (approved or rejected) in the quarter
+	totals := accumulator{row: QuarterlyCategoryStatistics{DevelopmentCategory: totalsLabel}}
+	for _, acc := range byCategory {
+		if acc.decided > 0 {
+			acc.row.AverageProcessingDays = decimal.NewFromFloat(acc.sumDays / float64(acc.decided))
+		}
+		acc.row.AverageProcessingDays = acc.row.AverageProcessingDays.Round(1)
+		acc.row.LevyRevenue = acc.row.LevyRevenue.Round(2)
+		stats.Categories = append(stats.Categories, acc.row)
+
+		totals.row.ApplicationsReceived += acc.row.ApplicationsReceived
+		totals.row.ApplicationsApproved += acc.row.ApplicationsApproved
+		totals.row.ApplicationsRejected += acc.row.ApplicationsRejected
+		totals.row.LevyRevenue = totals.row.LevyRevenue.Add(acc.row.LevyRevenue)
+		totals.sumDays += acc.sumDays
+		totals.decided += acc.decided
+	}
+	if totals.decided > 0 {
+		totals.row.AverageProcessingDays = decimal.NewFromFloat(totals.sumDays / float64(totals.decided))
+	}
+	totals.row.AverageProcessingDays = totals.row.AverageProcessingDays.Round(1)
+	stats.Totals = totals.row
+
+	sort.Slice(stats.Categories, func(i, j int) bool {
+		return stats.Categories[i].DevelopmentCategory < stats.Categories[j].DevelopmentCategory
+	})
+
+	return stats, nil
+}
+
+// GetReportSubmission returns the locked submission for a quarter, or nil if it is still open
+func (r *nationalReportRepository) GetReportSubmission(ctx context.Context, year int, quarter int) (*models.NationalReportSubmission, error) {
+	var submission models.NationalReportSubmission
+	err := r.db.WithContext(ctx).Preload("LockedBy").
+		Where("year = ? AND quarter = ?", year, quarter).
+		First(&submission).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &submission, nil
+}
+
+// GetReportSubmissions lists every locked quarter, most recent first
+func (r *nationalReportRepository) GetReportSubmissions() ([]models.NationalReportSubmission, error) {
+	var submissions []models.NationalReportSubmission
+	err := r.db.Preload("LockedBy").
+		Order("year DESC, quarter DESC").
+		Find(&submissions).Error
+	return submissions, err
+}
+
+// LockQuarter freezes the current figures for a completed quarter
+func (r *nationalReportRepository) LockQuarter(
+	tx *gorm.DB,
+	year int,
+	quarter int,
+	lockedBy uuid.UUID,
+	submissionReference *string,
+	notes *string,
+) (*models.NationalReportSubmission, error) {
+	_, end, err := QuarterBounds(year, quarter)
+	if err != nil {
+		return nil, err
+	}
+	if time.Now().Before(end) {
+		return nil, errors.New("quarter has not ended yet")
+	}
+
+	var existing int64
+	if err := tx.Model(&models.NationalReportSubmission{}).
+		Where("year = ? AND quarter = ?", year, quarter).
+		Count(&existing).Error; err != nil {
+		return nil, err
+	}
+	if existing > 0 {
+		return nil, errors.New("quarter is already locked")
+	}
+
+	stats, err := r.GetQuarterlyStatistics(tx.Statement.Context, year, quarter)
+	if err != nil {
+		return nil, err
+	}
+
+	figures, checksum, err := EncodeQuarterlyStatistics(stats)
+	if err != nil {
+		return nil, err
+	}
+
+	submission := &models.NationalReportSubmission{
+		Year:                year,
+		Quarter:             quarter,
+		SchemaVersion:       stats.SchemaVersion,
+		Figures:             datatypes.JSON(figures),
+		FiguresChecksum:     checksum,
+		SubmissionReference: submissionReference,
+		Notes:               notes,
+		LockedByID:          lockedBy,
+		LockedAt:            time.Now(),
+		CreatedBy:           lockedBy.String(),
+	}
+	if err := tx.Create(submission).Error; err != nil {
+		return nil, fmt.Errorf("failed to lock quarter: %w", err)
+	}
+	return submission, nil
+}
+
+// EncodeQuarterlyStatistics serialises the figures and returns them with their SHA-256 checksum
+func EncodeQuarterlyStatistics(stats *QuarterlyStatistics) ([]byte, string, error) {
+	figures, err := json.Marshal(stats)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode figures: %w", err)
+	}
+	sum := sha256.Sum256(figures)
+	return figures, hex.EncodeToString(sum[:]), nil
+}
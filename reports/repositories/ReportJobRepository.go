@@ -0,0 +1,438 @@
+package repositories
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/utils"
+
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ReportRetention is how long generated reports are kept before the file is deleted
+const ReportRetention = 30 * 24 * time.Hour
+
+// reportCleanupSchedule removes expired reports nightly, after the integrity checks
+const reportCleanupSchedule = "0 3 * * *"
+
+// ReportJobParameters are the inputs of a background report. Funnel reports use From, To and
+// StallAfterDays; national quarterly reports use Year and Quarter.
+type ReportJobParameters struct {
+	From           *time.Time `json:"from,omitempty"`
+	To             *time.Time `json:"to,omitempty"`
+	StallAfterDays int        `json:"stall_after_days,omitempty"`
+	Year           int        `json:"year,omitempty"`
+	Quarter        int        `json:"quarter,omitempty"`
+}
+
+type ReportJobRepository interface {
+	CreateReportJob(reportType models.ReportJobType, parameters ReportJobParameters, requestedByID uuid.UUID, createdBy string) (*models.ReportJob, error)
+	GetReportJob(jobID uuid.UUID) (*models.ReportJob, error)
+	GetReportJobsForUser(userID uuid.UUID) ([]models.ReportJob, error)
+	RunReportJob(jobID uuid.UUID)
+	OpenReportFile(job *models.ReportJob) (io.ReadCloser, error)
+	SignedDownloadURL(job *models.ReportJob) string
+	VerifyDownloadSignature(jobID uuid.UUID, expires string, signature string) error
+	ExpireReportJobs() (int, error)
+	FailInterruptedReportJobs() (int64, error)
+}
+
+type reportJobRepository struct {
+	db           *gorm.DB
+	funnelRepo   FunnelReportRepository
+	nationalRepo NationalReportRepository
+	storage      utils.FileStorage
+	signingKey   []byte
+	baseURL      string
+}
+
+// NewReportJobRepository stores generated reports in storage, which must not be publicly served:
+// reports are only downloadable through links signed with signingKey.
+func NewReportJobRepository(
+	db *gorm.DB,
+	funnelRepo FunnelReportRepository,
+	nationalRepo NationalReportRepository,
+	storage utils.FileStorage,
+	signingKey string,
+	baseURL string,
+) ReportJobRepository {
+	return &reportJobRepository{
+		db:           db,
+		funnelRepo:   funnelRepo,
+		nationalRepo: nationalRepo,
+		storage:      storage,
+		signingKey:   []byte(signingKey),
+		baseURL:      baseURL,
+	}
+}
+
+// CreateReportJob validates the parameters and queues the job. The caller starts it with RunReportJob.
+func (r *reportJobRepository) CreateReportJob(
+	reportType models.ReportJobType,
+	parameters ReportJobParameters,
+	requestedByID uuid.UUID,
+	createdBy string,
+) (*models.ReportJob, error) {
+	switch reportType {
+	case models.ReportJobFunnel:
+		if parameters.From == nil || parameters.To == nil {
+			return nil, errors.New("from and to are required")
+		}
+		if !parameters.From.Before(*parameters.To) {
+			return nil, errors.New("period start must be before period end")
+		}
+		if parameters.StallAfterDays < 1 {
+			return nil, errors.New("stall_after_days must be at least 1")
+		}
+	case models.ReportJobNationalQuarterly:
+		if _, _, err := QuarterBounds(parameters.Year, parameters.Quarter); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("unsupported report type")
+	}
+
+	encoded, err := json.Marshal(parameters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode report parameters: %w", err)
+	}
+
+	job := &models.ReportJob{
+		ReportType:    reportType,
+		Parameters:    encoded,
+		Status:        models.ReportJobPending,
+		RequestedByID: requestedByID,
+		CreatedBy:     createdBy,
+	}
+	if err := r.db.Create(job).Error; err != nil {
+		return nil, fmt.Errorf("failed to create report job: %w", err)
+	}
+	return job, nil
+}
+
+func (r *reportJobRepository) GetReportJob(jobID uuid.UUID) (*models.ReportJob, error) {
+	var job models.ReportJob
+	if err := r.db.Where("id = ?", jobID).First(&job).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("report job not found")
+		}
+		return nil, fmt.Errorf("failed to fetch report job: %w", err)
+	}
+	return &job, nil
+}
+
+// GetReportJobsForUser lists the user's report jobs, newest first
+func (r *reportJobRepository) GetReportJobsForUser(userID uuid.UUID) ([]models.ReportJob, error) {
+	var jobs []models.ReportJob
+	if err := r.db.
+		Where("requested_by_id = ?", userID).
+		Order("created_at DESC").
+		Find(&jobs).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch report jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// RunReportJob generates the report, stores the file and emails the requester a download link.
+// It is meant to run in its own goroutine; failures are recorded on the job.
+func (r *reportJobRepository) RunReportJob(jobID uuid.UUID) {
+	job, err := r.GetReportJob(jobID)
+	if err != nil {
+		config.Logger.Error("Failed to load report job", zap.Error(err), zap.String("jobID", jobID.String()))
+		return
+	}
+
+	started := time.Now()
+	if err := r.db.Model(job).Updates(map[string]interface{}{
+		"status":     models.ReportJobRunning,
+		"started_at": started,
+	}).Error; err != nil {
+		config.Logger.Error("Failed to mark report job running", zap.Error(err), zap.String("jobID", jobID.String()))
+		return
+	}
+
+	// The job outlives the request that queued it, so it is not bound to that request's deadline
+	data, fileName, err := r.generateReport(context.Background(), job)
+	if err == nil {
+		err = r.storeReport(job, data, fileName)
+	}
+	if err != nil {
+		config.Logger.Error("Report job failed",
+			zap.Error(err),
+			zap.String("jobID", jobID.String()),
+			zap.String("reportType", string(job.ReportType)))
+		r.finishReportJob(job, models.ReportJobFailed, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	config.Logger.Info("Report job completed",
+		zap.String("jobID", jobID.String()),
+		zap.String("reportType", string(job.ReportType)),
+		zap.Int("fileSize", len(data)),
+		zap.Duration("duration", time.Since(started)))
+
+	completed, err := r.GetReportJob(jobID)
+	if err != nil {
+		config.Logger.Warn("Failed to reload completed report job", zap.Error(err), zap.String("jobID", jobID.String()))
+		return
+	}
+	r.notifyRequester(completed)
+}
+
+// generateReport computes the report and encodes it as a JSON file
+func (r *reportJobRepository) generateReport(ctx context.Context, job *models.ReportJob) ([]byte, string, error) {
+	var parameters ReportJobParameters
+	if err := json.Unmarshal(job.Parameters, &parameters); err != nil {
+		return nil, "", fmt.Errorf("failed to decode report parameters: %w", err)
+	}
+
+	switch job.ReportType {
+	case models.ReportJobFunnel:
+		if parameters.From == nil || parameters.To == nil {
+			return nil, "", errors.New("from and to are required")
+		}
+		report, err := r.funnelRepo.GetApplicationFunnel(ctx, *parameters.From, *parameters.To, parameters.StallAfterDays)
+		if err != nil {
+			return nil, "", err
+		}
+		data, err := json.Marshal(report)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to encode funnel report: %w", err)
+		}
+		fileName := fmt.Sprintf("application-funnel-%s-to-%s.json",
+			parameters.From.Format("2006-01"), parameters.To.AddDate(0, -1, 0).Format("2006-01"))
+		return data, fileName, nil
+
+	case models.ReportJobNationalQuarterly:
+		fileName := fmt.Sprintf("national-planning-statistics-%d-Q%d.json", parameters.Year, parameters.Quarter)
+
+		// Locked quarters are served from the submitted snapshot, as on the live endpoint
+		submission, err := r.nationalRepo.GetReportSubmission(ctx, parameters.Year, parameters.Quarter)
+		if err != nil {
+			return nil, "", err
+		}
+		if submission != nil {
+			return submission.Figures, fileName, nil
+		}
+
+		stats, err := r.nationalRepo.GetQuarterlyStatistics(ctx, parameters.Year, parameters.Quarter)
+		if err != nil {
+			return nil, "", err
+		}
+		data, _, err := EncodeQuarterlyStatistics(stats)
+		if err != nil {
+			return nil, "", err
+		}
+		return data, fileName, nil
+	}
+
+	return nil, "", errors.New("unsupported report type")
+}
+
+// storeReport saves the file and completes the job, starting its retention period
+func (r *reportJobRepository) storeReport(job *models.ReportJob, data []byte, fileName string) error {
+	filePath := filepath.Join(job.ID.String(), fileName)
+	if _, err := r.storage.UploadFileFromReader(bytes.NewReader(data), filePath); err != nil {
+		return fmt.Errorf("failed to store report: %w", err)
+	}
+
+	completed := time.Now()
+	expires := completed.Add(ReportRetention)
+	if err := r.finishReportJob(job, models.ReportJobCompleted, map[string]interface{}{
+		"file_name":    fileName,
+		"file_path":    filePath,
+		"file_size":    int64(len(data)),
+		"content_type": "application/json",
+		"completed_at": completed,
+		"expires_at":   expires,
+	}); err != nil {
+		_ = r.storage.DeleteFile(filePath)
+		return err
+	}
+	return nil
+}
+
+// finishReportJob records the job's final status. Failed jobs expire with the same retention
+// so their error stays visible to the requester for a while.
+func (r *reportJobRepository) finishReportJob(job *models.ReportJob, status models.ReportJobStatus, updates map[string]interface{}) error {
+	updates["status"] = status
+	if status == models.ReportJobFailed {
+		now := time.Now()
+		updates["completed_at"] = now
+		updates["expires_at"] = now.Add(ReportRetention)
+	}
+
+	if err := r.db.Model(job).Updates(updates).Error; err != nil {
+		config.Logger.Error("Failed to update report job",
+			zap.Error(err),
+			zap.String("jobID", job.ID.String()),
+			zap.String("status", string(status)))
+		return fmt.Errorf("failed to update report job: %w", err)
+	}
+	return nil
+}
+
+// notifyRequester emails the requester that the report is ready, with its download link
+func (r *reportJobRepository) notifyRequester(job *models.ReportJob) {
+	var user models.User
+	if err := r.db.Select("id", "email").Where("id = ?", job.RequestedByID).First(&user).Error; err != nil {
+		config.Logger.Warn("Failed to load report requester for notification",
+			zap.Error(err),
+			zap.String("jobID", job.ID.String()))
+		return
+	}
+
+	message := fmt.Sprintf(
+		"Your %s report is ready. Download it from the link below before %s, when it will be deleted.\n%s",
+		reportJobLabel(job.ReportType),
+		job.ExpiresAt.Format("2 January 2006"),
+		r.SignedDownloadURL(job))
+	if err := utils.SendEmail(user.Email, message, "Your report is ready", "", ""); err != nil {
+		config.Logger.Warn("Failed to email report download link",
+			zap.Error(err),
+			zap.String("jobID", job.ID.String()))
+	}
+}
+
+func reportJobLabel(reportType models.ReportJobType) string {
+	switch reportType {
+	case models.ReportJobFunnel:
+		return "application funnel"
+	case models.ReportJobNationalQuarterly:
+		return "national quarterly statistics"
+	}
+	return string(reportType)
+}
+
+// OpenReportFile opens a completed job's file for download
+func (r *reportJobRepository) OpenReportFile(job *models.ReportJob) (io.ReadCloser, error) {
+	if job.Status == models.ReportJobExpired {
+		return nil, errors.New("report has expired")
+	}
+	if job.Status != models.ReportJobCompleted || job.FilePath == nil {
+		return nil, errors.New("report is not ready")
+	}
+	return r.storage.DownloadFile(*job.FilePath)
+}
+
+// SignedDownloadURL returns a link to the job's file that works without logging in until the
+// report expires
+func (r *reportJobRepository) SignedDownloadURL(job *models.ReportJob) string {
+	if job.Status != models.ReportJobCompleted || job.ExpiresAt == nil {
+		return ""
+	}
+	expires := strconv.FormatInt(job.ExpiresAt.Unix(), 10)
+	return fmt.Sprintf("%s/reports/downloads/%s?expires=%s&signature=%s",
+		r.baseURL, job.ID, expires, r.sign(job.ID, expires))
+}
+
+// VerifyDownloadSignature checks that a download link was signed by this server and has not expired
+func (r *reportJobRepository) VerifyDownloadSignature(jobID uuid.UUID, expires string, signature string) error {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return errors.New("invalid download link")
+	}
+	if !hmac.Equal([]byte(signature), []byte(r.sign(jobID, expires))) {
+		return errors.New("invalid download link")
+	}
+	if time.Now().Unix() > expiresAt {
+		return errors.New("download link has expired")
+	}
+	return nil
+}
+
+func (r *reportJobRepository) sign(jobID uuid.UUID, expires string) string {
+	mac := hmac.New(sha256.New, r.signingKey)
+	mac.Write([]byte(jobID.String() + ":" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ExpireReportJobs deletes the files of reports past their retention period
+func (r *reportJobRepository) ExpireReportJobs() (int, error) {
+	var jobs []models.ReportJob
+	if err := r.db.
+		Where("status IN ? AND expires_at < ?", []models.ReportJobStatus{models.ReportJobCompleted, models.ReportJobFailed}, time.Now()).
+		Find(&jobs).Error; err != nil {
+		return 0, fmt.Errorf("failed to fetch expired report jobs: %w", err)
+	}
+
+	expired := 0
+	for i := range jobs {
+		job := &jobs[i]
+		if job.FilePath != nil {
+			if err := r.storage.DeleteFile(*job.FilePath); err != nil {
+				config.Logger.Warn("Failed to delete expired report file",
+					zap.Error(err),
+					zap.String("jobID", job.ID.String()))
+				continue
+			}
+		}
+		if err := r.db.Model(job).Updates(map[string]interface{}{
+			"status":    models.ReportJobExpired,
+			"file_path": nil,
+		}).Error; err != nil {
+			return expired, fmt.Errorf("failed to expire report job: %w", err)
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+// FailInterruptedReportJobs fails jobs left pending or running by a server restart, since
+// nothing will pick them up again
+func (r *reportJobRepository) FailInterruptedReportJobs() (int64, error) {
+	now := time.Now()
+	result := r.db.Model(&models.ReportJob{}).
+		Where("status IN ?", []models.ReportJobStatus{models.ReportJobPending, models.ReportJobRunning}).
+		Updates(map[string]interface{}{
+			"status":       models.ReportJobFailed,
+			"error":        "report generation was interrupted by a server restart, please request it again",
+			"completed_at": now,
+			"expires_at":   now.Add(ReportRetention),
+		})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to fail interrupted report jobs: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// RunScheduledReportCleanup fails jobs interrupted by the last shutdown, then deletes expired
+// reports nightly at 3 AM until ctx is cancelled
+func RunScheduledReportCleanup(ctx context.Context, repo ReportJobRepository) {
+	if failed, err := repo.FailInterruptedReportJobs(); err != nil {
+		config.Logger.Error("Failed to fail interrupted report jobs", zap.Error(err))
+	} else if failed > 0 {
+		config.Logger.Warn("Failed report jobs interrupted by restart", zap.Int64("count", failed))
+	}
+
+	c := cron.New()
+
+	c.AddFunc(reportCleanupSchedule, func() {
+		expired, err := repo.ExpireReportJobs()
+		if err != nil {
+			config.Logger.Error("Scheduled report cleanup failed", zap.Error(err))
+			return
+		}
+		config.Logger.Info("Expired generated reports removed", zap.Int("count", expired))
+	})
+
+	c.Start()
+
+	// Keep running until shutdown, then wait for a running job to finish
+	<-ctx.Done()
+	<-c.Stop().Done()
+}
@@ -0,0 +1,276 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+	"town-planning-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+// Forecast methods, from most to least informed
+const (
+	ForecastSeasonalRegression = "seasonal_regression" // Linear trend scaled by calendar-month seasonality
+	ForecastLinearTrend        = "linear_trend"        // Under two years of history, too little to measure seasonality
+	ForecastAverage            = "average"             // Under six months of history
+)
+
+// forecastZ is the z-score of the 95% confidence ranges
+const forecastZ = 1.96
+
+// MonthlyCount is the number of applications submitted in a month (YYYY-MM)
+type MonthlyCount struct {
+	Month string `json:"month"`
+	Count int64  `json:"count"`
+}
+
+// MonthForecast is the projected volume of one month, with its confidence range
+type MonthForecast struct {
+	Month     string  `json:"month"`
+	Projected float64 `json:"projected"`
+	Low       float64 `json:"low"`
+	High      float64 `json:"high"`
+}
+
+// CategoryForecast projects one development category's volumes. Series is the history the
+// projection was fitted to.
+type CategoryForecast struct {
+	Category  string          `json:"category"`
+	Method    string          `json:"method"`
+	Projected float64         `json:"projected"`
+	Low       float64         `json:"low"`
+	High      float64         `json:"high"`
+	Months    []MonthForecast `json:"months"`
+	Series    []MonthlyCount  `json:"series"`
+}
+
+// WorkloadForecast projects next quarter's application volumes per category
+type WorkloadForecast struct {
+	Year            int                `json:"year"`
+	Quarter         int                `json:"quarter"`
+	PeriodStart     string             `json:"period_start"`
+	PeriodEnd       string             `json:"period_end"`
+	HistoryStart    string             `json:"history_start"`
+	HistoryEnd      string             `json:"history_end"`
+	ConfidenceLevel float64            `json:"confidence_level"`
+	GeneratedAt     time.Time          `json:"generated_at"`
+	Categories      []CategoryForecast `json:"categories"`
+	Totals          CategoryForecast   `json:"totals"`
+}
+
+type WorkloadForecastRepository interface {
+	GetNextQuarterForecast(ctx context.Context, now time.Time, historyMonths int) (*WorkloadForecast, error)
+}
+
+type workloadForecastRepository struct {
+	db *gorm.DB
+}
+
+func NewWorkloadForecastRepository(db *gorm.DB) WorkloadForecastRepository {
+	return &workloadForecastRepository{
+		db: db,
+	}
+}
+
+type monthlyCategoryCount struct {
+	Category string
+	Month    string
+	Count    int64
+}
+
+// GetNextQuarterForecast fits the last historyMonths complete months of submissions and projects
+// the quarter after the one containing now. Months are bucketed in the database session's time
+// zone (DB_TIMEZONE).
+func (r *workloadForecastRepository) GetNextQuarterForecast(ctx context.Context, now time.Time, historyMonths int) (*WorkloadForecast, error) {
+	location := utils.DateLocation
+	if location == nil {
+		location = time.Local
+	}
+	now = now.In(location)
+
+	currentQuarter := (int(now.Month())-1)/3 + 1
+	currentStart, _, err := QuarterBounds(now.Year(), currentQuarter)
+	if err != nil {
+		return nil, err
+	}
+	periodStart := currentStart.AddDate(0, 3, 0)
+	periodEnd := periodStart.AddDate(0, 3, 0)
+
+	historyEnd := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, location)
+	historyStart := historyEnd.AddDate(0, -historyMonths, 0)
+
+	var rows []monthlyCategoryCount
+	if err := r.db.WithContext(ctx).Table("applications").
+		Select("COALESCE(development_categories.name, ?) AS category, to_char(date_trunc('month', applications.submission_date), 'YYYY-MM') AS month, COUNT(*) AS count", uncategorisedLabel).
+		Joins(categoryJoins).
+		Where("applications.deleted_at IS NULL").
+		Where("applications.submission_date >= ? AND applications.submission_date < ?", historyStart, historyEnd).
+		Group("1, 2").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count monthly submissions: %w", err)
+	}
+
+	// Every category gets a full series, months without submissions counting as zero
+	months := make([]time.Time, 0, historyMonths)
+	for month := historyStart; month.Before(historyEnd); month = month.AddDate(0, 1, 0) {
+		months = append(months, month)
+	}
+	counts := map[string]map[string]int64{}
+	totals := map[string]int64{}
+	for _, row := range rows {
+		if counts[row.Category] == nil {
+			counts[row.Category] = map[string]int64{}
+		}
+		counts[row.Category][row.Month] += row.Count
+		totals[row.Month] += row.Count
+	}
+	series := func(byMonth map[string]int64) []MonthlyCount {
+		result := make([]MonthlyCount, len(months))
+		for i, month := range months {
+			key := month.Format("2006-01")
+			result[i] = MonthlyCount{Month: key, Count: byMonth[key]}
+		}
+		return result
+	}
+
+	targets := []time.Time{periodStart, periodStart.AddDate(0, 1, 0), periodStart.AddDate(0, 2, 0)}
+	forecast := &WorkloadForecast{
+		Year:            periodStart.Year(),
+		Quarter:         (int(periodStart.Month())-1)/3 + 1,
+		PeriodStart:     periodStart.Format("2006-01-02"),
+		PeriodEnd:       periodEnd.AddDate(0, 0, -1).Format("2006-01-02"),
+		HistoryStart:    historyStart.Format("2006-01-02"),
+		HistoryEnd:      historyEnd.AddDate(0, 0, -1).Format("2006-01-02"),
+		ConfidenceLevel: 0.95,
+		GeneratedAt:     time.Now(),
+		Categories:      make([]CategoryForecast, 0, len(counts)),
+	}
+	for category, byMonth := range counts {
+		forecast.Categories = append(forecast.Categories, projectSeries(category, series(byMonth), historyStart, targets))
+	}
+	sort.Slice(forecast.Categories, func(i, j int) bool {
+		return forecast.Categories[i].Category < forecast.Categories[j].Category
+	})
+	forecast.Totals = projectSeries("Total", series(totals), historyStart, targets)
+
+	return forecast, nil
+}
+
+// projectSeries fits y = a + b*t to the monthly counts, scales the trend by each calendar month's
+// average ratio of actual to trend, and takes the ranges from the spread of the fitted residuals.
+// A quarter's range adds the months' variances, treating the months as independent.
+func projectSeries(category string, series []MonthlyCount, historyStart time.Time, targets []time.Time) CategoryForecast {
+	n := len(series)
+	result := CategoryForecast{Category: category, Series: series}
+
+	values := make([]float64, n)
+	for i, point := range series {
+		values[i] = float64(point.Count)
+	}
+
+	method := ForecastSeasonalRegression
+	switch {
+	case n < 6:
+		method = ForecastAverage
+	case n < 24:
+		method = ForecastLinearTrend
+	}
+	result.Method = method
+
+	// Trend, or a flat line at the mean when there is too little history for one
+	var intercept, slope float64
+	if n > 0 {
+		intercept = mean(values)
+	}
+	if method != ForecastAverage {
+		xMean := float64(n-1) / 2
+		yMean := intercept
+		var sxy, sxx float64
+		for i, y := range values {
+			sxy += (float64(i) - xMean) * (y - yMean)
+			sxx += (float64(i) - xMean) * (float64(i) - xMean)
+		}
+		if sxx > 0 {
+			slope = sxy / sxx
+		}
+		intercept = yMean - slope*xMean
+	}
+	trend := func(t float64) float64 {
+		return math.Max(0, intercept+slope*t)
+	}
+
+	seasonal := map[time.Month]float64{}
+	if method == ForecastSeasonalRegression {
+		sums := map[time.Month]float64{}
+		samples := map[time.Month]int{}
+		for i, y := range values {
+			if fitted := trend(float64(i)); fitted > 0 {
+				month := historyStart.AddDate(0, i, 0).Month()
+				sums[month] += y / fitted
+				samples[month]++
+			}
+		}
+		for month, sum := range sums {
+			seasonal[month] = sum / float64(samples[month])
+		}
+	}
+	factor := func(month time.Month) float64 {
+		if value, ok := seasonal[month]; ok {
+			return value
+		}
+		return 1
+	}
+
+	var squared float64
+	for i, y := range values {
+		fitted := trend(float64(i)) * factor(historyStart.AddDate(0, i, 0).Month())
+		squared += (y - fitted) * (y - fitted)
+	}
+	var sigma float64
+	if n > 2 {
+		sigma = math.Sqrt(squared / float64(n-2))
+	}
+
+	var variance float64
+	result.Months = make([]MonthForecast, 0, len(targets))
+	for _, target := range targets {
+		offset := monthsBetween(historyStart, target)
+		projected := trend(float64(offset)) * factor(target.Month())
+		result.Months = append(result.Months, MonthForecast{
+			Month:     target.Format("2006-01"),
+			Projected: round1(projected),
+			Low:       round1(math.Max(0, projected-forecastZ*sigma)),
+			High:      round1(projected + forecastZ*sigma),
+		})
+		result.Projected += projected
+		variance += sigma * sigma
+	}
+	spread := forecastZ * math.Sqrt(variance)
+	result.Low = round1(math.Max(0, result.Projected-spread))
+	result.High = round1(result.Projected + spread)
+	result.Projected = round1(result.Projected)
+
+	return result
+}
+
+func monthsBetween(from, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+func round1(value float64) float64 {
+	return math.Round(value*10) / 10
+}
@@ -0,0 +1,24 @@
+package requests
+
+import "github.com/shopspring/decimal"
+
+// CreateLevyBenchmarkRequest sets the levy, and optionally the stand market value, expected per
+// square metre for a development category. Leaving out ward makes it council-wide.
+type CreateLevyBenchmarkRequest struct {
+	DevelopmentCategoryID     string           `json:"development_category_id"`
+	Ward                      *string          `json:"ward"`
+	LevyPerSquareMeter        decimal.Decimal  `json:"levy_per_square_meter"`
+	MarketValuePerSquareMeter *decimal.Decimal `json:"market_value_per_square_meter"`
+	TolerancePercent          *decimal.Decimal `json:"tolerance_percent"` // Defaults to 25
+	Notes                     *string          `json:"notes"`
+}
+
+// UpdateLevyBenchmarkRequest changes the fields sent. An empty ward makes the benchmark
+// council-wide.
+type UpdateLevyBenchmarkRequest struct {
+	Ward                      *string          `json:"ward"`
+	LevyPerSquareMeter        *decimal.Decimal `json:"levy_per_square_meter"`
+	MarketValuePerSquareMeter *decimal.Decimal `json:"market_value_per_square_meter"`
+	TolerancePercent          *decimal.Decimal `json:"tolerance_percent"`
+	Notes                     *string          `json:"notes"`
+}
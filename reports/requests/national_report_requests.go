@@ -0,0 +1,9 @@
+package requests
+
+// LockNationalReportRequest freezes the figures for a quarter once they have been submitted
+type LockNationalReportRequest struct {
+	Year                int     `json:"year"`
+	Quarter             int     `json:"quarter"`
+	SubmissionReference *string `json:"submission_reference"`
+	Notes               *string `json:"notes"`
+}
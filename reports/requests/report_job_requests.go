@@ -0,0 +1,12 @@
+package requests
+
+// CreateReportJobRequest asks for a report to be generated in the background. Funnel reports take
+// from and to as YYYY-MM months and stall_after_days; national quarterly reports take year and quarter.
+type CreateReportJobRequest struct {
+	ReportType     string `json:"report_type"`
+	From           string `json:"from"`
+	To             string `json:"to"`
+	StallAfterDays int    `json:"stall_after_days"`
+	Year           int    `json:"year"`
+	Quarter        int    `json:"quarter"`
+}
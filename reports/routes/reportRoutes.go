@@ -0,0 +1,78 @@
+package routes
+
+import (
+	"town-planning-backend/middleware"
+	"town-planning-backend/reports/controllers"
+	"town-planning-backend/reports/repositories"
+	user_repository "town-planning-backend/users/repositories"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+func ReportRouterInit(
+	app *fiber.App,
+	db *gorm.DB,
+	nationalReportRepository repositories.NationalReportRepository,
+	funnelReportRepository repositories.FunnelReportRepository,
+	integrityReportRepository repositories.IntegrityReportRepository,
+	reportJobRepository repositories.ReportJobRepository,
+	activityReportRepository repositories.ActivityReportRepository,
+	decisionAuditRepository repositories.DecisionAuditRepository,
+	workloadForecastRepository repositories.WorkloadForecastRepository,
+	levyBenchmarkRepository repositories.LevyBenchmarkRepository,
+	userRepo user_repository.UserRepository,
+) {
+	reportController := &controllers.ReportController{
+		NationalReportRepo:   nationalReportRepository,
+		FunnelReportRepo:     funnelReportRepository,
+		IntegrityReportRepo:  integrityReportRepository,
+		ReportJobRepo:        reportJobRepository,
+		ActivityReportRepo:   activityReportRepository,
+		DecisionAuditRepo:    decisionAuditRepository,
+		WorkloadForecastRepo: workloadForecastRepository,
+		LevyBenchmarkRepo:    levyBenchmarkRepository,
+		DB:                   db,
+	}
+
+	// Quarterly statistics for the national housing ministry
+	nationalRoutes := app.Group("/api/v1/reports/national")
+	nationalRoutes.Get("/quarterly", middleware.LongRunning(), middleware.RequirePermission(userRepo, "report.generate"), reportController.GetQuarterlyNationalReportController)
+	nationalRoutes.Get("/quarterly/submissions", middleware.RequirePermission(userRepo, "report.generate"), reportController.GetNationalReportSubmissionsController)
+	nationalRoutes.Post("/quarterly/lock", middleware.RequirePermission(userRepo, "report.submit"), reportController.LockQuarterlyNationalReportController)
+
+	// Where applications stall between submission and decision
+	app.Get("/api/v1/reports/funnel", middleware.LongRunning(), middleware.RequirePermission(userRepo, "report.generate"), reportController.GetApplicationFunnelController)
+
+	// Next quarter's expected volumes, for staffing plans
+	app.Get("/api/v1/reports/forecast", middleware.LongRunning(), middleware.RequirePermission(userRepo, "report.generate"), reportController.GetWorkloadForecastController)
+
+	// Levies charged against the configured benchmarks, with outliers for the valuation department
+	levyRoutes := app.Group("/api/v1/reports/levy-benchmarks")
+	levyRoutes.Get("/", middleware.LongRunning(), middleware.RequirePermission(userRepo, "report.generate"), reportController.GetLevyBenchmarkReportController)
+	levyRoutes.Get("/applications", middleware.LongRunning(), middleware.RequirePermission(userRepo, "report.generate"), reportController.GetLevyBenchmarkApplicationsController)
+	levyRoutes.Get("/config", middleware.RequirePermission(userRepo, "report.generate"), reportController.GetLevyBenchmarksController)
+	levyRoutes.Post("/config", middleware.RequirePermission(userRepo, "settings.manage"), reportController.CreateLevyBenchmarkController)
+	levyRoutes.Put("/config/:id", middleware.RequirePermission(userRepo, "settings.manage"), reportController.UpdateLevyBenchmarkController)
+	levyRoutes.Delete("/config/:id", middleware.RequirePermission(userRepo, "settings.manage"), reportController.DeleteLevyBenchmarkController)
+
+	// Weekly reviewer responsiveness for supervisors
+	app.Get("/api/v1/reports/activity", middleware.RequirePermission(userRepo, repositories.ActivityReportPermission), reportController.GetWeeklyActivityController)
+
+	// Hash-chained decision log for anti-corruption and oversight reviews
+	auditRoutes := app.Group("/api/v1/reports/decision-audit")
+	auditRoutes.Get("/", middleware.LongRunning(), middleware.RequirePermission(userRepo, repositories.DecisionAuditPermission), reportController.ExportDecisionAuditController)
+	auditRoutes.Get("/exports", middleware.RequirePermission(userRepo, repositories.DecisionAuditPermission), reportController.GetDecisionAuditExportsController)
+
+	// Reports too large to generate within a request
+	jobRoutes := app.Group("/api/v1/reports/jobs")
+	jobRoutes.Post("/", middleware.RequirePermission(userRepo, "report.generate"), reportController.CreateReportJobController)
+	jobRoutes.Get("/", middleware.RequirePermission(userRepo, "report.generate"), reportController.GetReportJobsController)
+	jobRoutes.Get("/:id", middleware.RequirePermission(userRepo, "report.generate"), reportController.GetReportJobController)
+
+	// Signed download links, usable without logging in
+	app.Get("/reports/downloads/:id", reportController.DownloadReportController)
+
+	// Approval invariants that need manual repair
+	app.Get("/api/v1/admin/integrity-report", middleware.LongRunning(), middleware.RequirePermission(userRepo, "user.manage"), reportController.GetIntegrityReportController)
+}
@@ -0,0 +1,110 @@
+package seeds
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// DemoPassword is shared by every demo account so testers can sign in as any role
+const DemoPassword = "Password123!"
+
+// SeedTownPlanningDemoUsers seeds one password account per role for demos and local testing.
+// Roles must already be seeded.
+func SeedTownPlanningDemoUsers(db *gorm.DB, opts Options, report *Report) error {
+	config.Logger.Info("Starting demo users seeding...")
+
+	var planningDept models.Department
+	if err := db.Where("name = ?", "Town Planning Department").First(&planningDept).Error; err != nil {
+		return fmt.Errorf("town planning department not found: %w", err)
+	}
+
+	demoUsers := []struct {
+		Mailbox   string
+		FirstName string
+		LastName  string
+		Phone     string
+		RoleName  string
+		InDept    bool
+	}{
+		{"demo.director", "Demo", "Director", "+263242222201", "Town Planning Director", true},
+		{"demo.officer", "Demo", "Officer", "+263242222202", "Town Planning Officer", true},
+		{"demo.technician", "Demo", "Technician", "+263242222203", "Planning Technician", true},
+		{"demo.inspector", "Demo", "Inspector", "+263242222204", "Building Inspector", true},
+		{"demo.public", "Demo", "Applicant", "+263242222205", "Public User", false},
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(DemoPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash demo password: %w", err)
+	}
+
+	createdCount := 0
+	for _, demo := range demoUsers {
+		var role models.Role
+		if err := db.Where("name = ?", demo.RoleName).First(&role).Error; err != nil {
+			return fmt.Errorf("%s role not found, seed roles first: %w", demo.RoleName, err)
+		}
+
+		email := opts.email(demo.Mailbox)
+
+		var existingUser models.User
+		result := db.Where("email = ?", email).First(&existingUser)
+		if result.Error != nil && !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("error checking for user %s: %w", email, result.Error)
+		}
+
+		if result.Error == nil {
+			if !opts.Overwrite {
+				report.record("demo user", email, ActionUnchanged)
+				continue
+			}
+			// Reset the password and role so the account matches what testers expect
+			if err := db.Model(&existingUser).Updates(map[string]interface{}{
+				"password":    string(hashedPassword),
+				"auth_method": models.AuthMethodPassword,
+				"role_id":     role.ID,
+				"active":      true,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to update demo user %s: %w", email, err)
+			}
+			report.record("demo user", email, ActionUpdated)
+			continue
+		}
+
+		user := models.User{
+			ID:            uuid.New(),
+			FirstName:     demo.FirstName,
+			LastName:      demo.LastName,
+			Email:         email,
+			Phone:         demo.Phone,
+			Password:      string(hashedPassword),
+			AuthMethod:    models.AuthMethodPassword,
+			RoleID:        role.ID,
+			Active:        true,
+			EmailVerified: true,
+			CreatedBy:     "system",
+			CreatedAt:     time.Now(),
+			LastUpdatedAt: time.Now(),
+		}
+		if demo.InDept {
+			user.DepartmentID = &planningDept.ID
+		}
+
+		if err := db.Create(&user).Error; err != nil {
+			return fmt.Errorf("failed to create demo user %s: %w", email, err)
+		}
+		createdCount++
+		report.record("demo user", email, ActionCreated)
+	}
+
+	config.Logger.Info("Demo users seeding completed", zap.Int("created", createdCount))
+	return nil
+}
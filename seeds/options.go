@@ -0,0 +1,69 @@
+package seeds
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DefaultTenant is the email domain seeded accounts and departments use when none is given
+const DefaultTenant = "citycouncil.gov.zw"
+
+// Options selects what a seeding run touches
+type Options struct {
+	Roles bool // Departments, roles, permissions, role permissions and document categories
+	Users bool // Staff accounts that sign in by magic link
+	Demo  bool // Demo accounts sharing a known password
+
+	// Tenant is the council email domain used for seeded accounts and department contacts
+	Tenant string
+
+	// Overwrite updates existing rows to match the seed definitions. Without it rows that
+	// already exist are left as they are, so edits made through the app survive a re-run.
+	Overwrite bool
+
+	// Environment is the APP_ENV the run targets
+	Environment string
+}
+
+// NewOptions returns options for the current APP_ENV with the default tenant
+func NewOptions() Options {
+	env := os.Getenv("APP_ENV")
+	if env == "" {
+		env = "development"
+	}
+	return Options{
+		Tenant:      DefaultTenant,
+		Environment: env,
+	}
+}
+
+// IsProduction reports whether the run targets production
+func (o Options) IsProduction() bool {
+	return o.Environment == "production"
+}
+
+// Validate rejects empty runs and options that would clobber data in production
+func (o Options) Validate() error {
+	if !o.Roles && !o.Users && !o.Demo {
+		return errors.New("nothing to seed: choose at least one of roles, users or demo")
+	}
+	if strings.TrimSpace(o.Tenant) == "" || strings.Contains(o.Tenant, "@") {
+		return fmt.Errorf("invalid tenant domain %q", o.Tenant)
+	}
+	if o.IsProduction() {
+		if o.Demo {
+			return errors.New("demo accounts cannot be seeded in production")
+		}
+		if o.Overwrite {
+			return errors.New("overwriting existing records is not allowed in production")
+		}
+	}
+	return nil
+}
+
+// email builds a tenant address from a mailbox name
+func (o Options) email(mailbox string) string {
+	return mailbox + "@" + strings.ToLower(strings.TrimSpace(o.Tenant))
+}
@@ -0,0 +1,57 @@
+package seeds
+
+import (
+	"fmt"
+	"io"
+)
+
+// Action is what a seeding run did to a record
+type Action string
+
+const (
+	ActionCreated   Action = "created"
+	ActionUpdated   Action = "updated"
+	ActionUnchanged Action = "unchanged"
+)
+
+// Change is one seeded record and what happened to it
+type Change struct {
+	Entity string `json:"entity"`
+	Key    string `json:"key"` // Natural key the seeder matches on, e.g. a role name or email
+	Action Action `json:"action"`
+}
+
+// Report collects the changes made by a seeding run
+type Report struct {
+	Changes []Change `json:"changes"`
+}
+
+func (r *Report) record(entity, key string, action Action) {
+	r.Changes = append(r.Changes, Change{Entity: entity, Key: key, Action: action})
+}
+
+// Count returns how many records had the given action
+func (r *Report) Count(action Action) int {
+	count := 0
+	for _, change := range r.Changes {
+		if change.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// Write prints created and updated records as a diff followed by totals. Unchanged
+// records only appear in the totals.
+func (r *Report) Write(w io.Writer) {
+	for _, change := range r.Changes {
+		switch change.Action {
+		case ActionCreated:
+			fmt.Fprintf(w, "+ %s %s\n", change.Entity, change.Key)
+		case ActionUpdated:
+			fmt.Fprintf(w, "~ %s %s\n", change.Entity, change.Key)
+		}
+	}
+	fmt.Fprintf(w, "%d created, %d updated, %d unchanged\n",
+		r.Count(ActionCreated), r.Count(ActionUpdated), r.Count(ActionUnchanged))
+}
@@ -4,17 +4,17 @@ import (
 	"errors"
 	"fmt"
 	"time"
+	"town-planning-backend/cache"
 	"town-planning-backend/config"
 	"town-planning-backend/db/models"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
 // SeedTownPlanningDepartments seeds departments specific to town planning
-func SeedTownPlanningDepartments(db *gorm.DB) error {
+func SeedTownPlanningDepartments(db *gorm.DB, opts Options, report *Report) error {
 	config.Logger.Info("Starting town planning departments seeding...")
 
 	departments := []models.Department{
@@ -24,7 +24,7 @@ func SeedTownPlanningDepartments(db *gorm.DB) error {
 			Description:    stringPtr("Central department responsible for development control and urban planning"),
 			IsActive:       true,
 			IsSystem:       true,
-			Email:          stringPtr("townplanning@citycouncil.gov.zw"),
+			Email:          stringPtr(opts.email("townplanning")),
 			PhoneNumber:    stringPtr("+263-242-123456"),
 			OfficeLocation: stringPtr("City Hall, 3rd Floor, Town Planning Wing"),
 			CreatedBy:      "system",
@@ -37,7 +37,7 @@ func SeedTownPlanningDepartments(db *gorm.DB) error {
 			Description:    stringPtr("Section responsible for development applications and approvals"),
 			IsActive:       true,
 			IsSystem:       true,
-			Email:          stringPtr("devcontrol@citycouncil.gov.zw"),
+			Email:          stringPtr(opts.email("devcontrol")),
 			PhoneNumber:    stringPtr("+263-242-123457"),
 			OfficeLocation: stringPtr("City Hall, 3rd Floor, Development Control Section"),
 			CreatedBy:      "system",
@@ -50,7 +50,7 @@ func SeedTownPlanningDepartments(db *gorm.DB) error {
 			Description:    stringPtr("Section responsible for construction monitoring and compliance"),
 			IsActive:       true,
 			IsSystem:       true,
-			Email:          stringPtr("buildinginspections@citycouncil.gov.zw"),
+			Email:          stringPtr(opts.email("buildinginspections")),
 			PhoneNumber:    stringPtr("+263-242-123458"),
 			OfficeLocation: stringPtr("City Hall, 2nd Floor, Building Inspections Unit"),
 			CreatedBy:      "system",
@@ -75,6 +75,7 @@ func SeedTownPlanningDepartments(db *gorm.DB) error {
 					return fmt.Errorf("failed to create department %s: %w", department.Name, err)
 				}
 				createdCount++
+				report.record("department", department.Name, ActionCreated)
 				config.Logger.Info("Created town planning department", zap.String("name", department.Name))
 			} else {
 				config.Logger.Error("Error checking for existing town planning department",
@@ -82,6 +83,8 @@ func SeedTownPlanningDepartments(db *gorm.DB) error {
 					zap.Error(result.Error))
 				return fmt.Errorf("error checking for department %s: %w", department.Name, result.Error)
 			}
+		} else if !opts.Overwrite {
+			report.record("department", department.Name, ActionUnchanged)
 		} else {
 			// Update existing department
 			department.ID = existingDepartment.ID
@@ -92,6 +95,7 @@ func SeedTownPlanningDepartments(db *gorm.DB) error {
 				return fmt.Errorf("failed to update department %s: %w", department.Name, err)
 			}
 			updatedCount++
+			report.record("department", department.Name, ActionUpdated)
 			config.Logger.Info("Updated town planning department", zap.String("name", department.Name))
 		}
 	}
@@ -104,7 +108,7 @@ func SeedTownPlanningDepartments(db *gorm.DB) error {
 }
 
 // SeedTownPlanningRoles seeds the roles specific to town planning department
-func SeedTownPlanningRoles(db *gorm.DB) error {
+func SeedTownPlanningRoles(db *gorm.DB, opts Options, report *Report) error {
 	config.Logger.Info("Starting town planning roles seeding...")
 
 	roles := []models.Role{
@@ -176,6 +180,7 @@ func SeedTownPlanningRoles(db *gorm.DB) error {
 					return fmt.Errorf("failed to create role %s: %w", role.Name, err)
 				}
 				createdCount++
+				report.record("role", role.Name, ActionCreated)
 				config.Logger.Info("Created town planning role", zap.String("name", role.Name))
 			} else {
 				config.Logger.Error("Error checking for existing town planning role",
@@ -183,6 +188,8 @@ func SeedTownPlanningRoles(db *gorm.DB) error {
 					zap.Error(result.Error))
 				return fmt.Errorf("error checking for role %s: %w", role.Name, result.Error)
 			}
+		} else if !opts.Overwrite {
+			report.record("role", role.Name, ActionUnchanged)
 		} else {
 			// Update existing role
 			role.ID = existingRole.ID
@@ -193,6 +200,7 @@ func SeedTownPlanningRoles(db *gorm.DB) error {
 				return fmt.Errorf("failed to update role %s: %w", role.Name, err)
 			}
 			updatedCount++
+			report.record("role", role.Name, ActionUpdated)
 			config.Logger.Info("Updated town planning role", zap.String("name", role.Name))
 		}
 	}
@@ -205,7 +213,7 @@ func SeedTownPlanningRoles(db *gorm.DB) error {
 }
 
 // SeedTownPlanningPermissions seeds permissions specific to town planning operations
-func SeedTownPlanningPermissions(db *gorm.DB) error {
+func SeedTownPlanningPermissions(db *gorm.DB, opts Options, report *Report) error {
 	config.Logger.Info("Starting town planning permissions seeding...")
 
 	permissions := []models.Permission{
@@ -216,27 +224,55 @@ func SeedTownPlanningPermissions(db *gorm.DB) error {
 		{ID: uuid.New(), Name: "application.review", Description: "Review and assess development applications", Resource: "applications", Action: "update", Category: "application_management", IsActive: true, CreatedBy: "system", CreatedAt: time.Now(), UpdatedAt: time.Now()},
 		{ID: uuid.New(), Name: "application.approve", Description: "Approve development applications", Resource: "applications", Action: "update", Category: "application_management", IsActive: true, CreatedBy: "system", CreatedAt: time.Now(), UpdatedAt: time.Now()},
 		{ID: uuid.New(), Name: "application.reject", Description: "Reject development applications", Resource: "applications", Action: "update", Category: "application_management", IsActive: true, CreatedBy: "system", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		{ID: uuid.New(), Name: "application.transfer", Description: "Approve transfer of applications to a new applicant after a property sale", Resource: "applications", Action: "update", Category: "application_management", IsActive: true, CreatedBy: "system", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		{ID: uuid.New(), Name: "application.rates_override", Description: "Accept applications whose stand rates account is not clear", Resource: "applications", Action: "update", Category: "application_management", IsActive: true, CreatedBy: "system", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		{ID: uuid.New(), Name: "application.amend", Description: "Request minor amendments to approved applications", Resource: "applications", Action: "update", Category: "application_management", IsActive: true, CreatedBy: "system", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		{ID: uuid.New(), Name: "application.appeal", Description: "Lodge appeals against rejected applications", Resource: "applications", Action: "create", Category: "application_management", IsActive: true, CreatedBy: "system", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		{ID: uuid.New(), Name: "appeal.decide", Description: "Schedule hearings and decide appeals as an appeals group member", Resource: "applications", Action: "update", Category: "application_management", IsActive: true, CreatedBy: "system", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		{ID: uuid.New(), Name: "issue.bulk_resolve", Description: "Resolve many collaborative issues at once with a shared resolution note", Resource: "application_issues", Action: "update", Category: "application_management", IsActive: true, CreatedBy: "system", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		{ID: uuid.New(), Name: "chat.moderate", Description: "Post in and reopen chat threads frozen after an application was finally decided", Resource: "chat_threads", Action: "update", Category: "application_management", IsActive: true, CreatedBy: "system", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		{ID: uuid.New(), Name: "application.rehydrate", Description: "Restore archived applications from cold storage, e.g. for legal queries", Resource: "applications", Action: "update", Category: "application_management", IsActive: true, CreatedBy: "system", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		{ID: uuid.New(), Name: "application.prescreen", Description: "Triage new submissions before they are assigned to an approval group", Resource: "applications", Action: "update", Category: "application_management", IsActive: true, CreatedBy: "system", CreatedAt: time.Now(), UpdatedAt: time.Now()},
 
 		// Document Management
 		{ID: uuid.New(), Name: "document.upload", Description: "Upload application documents", Resource: "documents", Action: "create", Category: "document_management", IsActive: true, CreatedBy: "system", CreatedAt: time.Now(), UpdatedAt: time.Now()},
 		{ID: uuid.New(), Name: "document.read", Description: "View application documents", Resource: "documents", Action: "read", Category: "document_management", IsActive: true, CreatedBy: "system", CreatedAt: time.Now(), UpdatedAt: time.Now()},
 		{ID: uuid.New(), Name: "document.process", Description: "Process application documents", Resource: "documents", Action: "update", Category: "document_management", IsActive: true, CreatedBy: "system", CreatedAt: time.Now(), UpdatedAt: time.Now()},
 		{ID: uuid.New(), Name: "document.generate.tpd1", Description: "Generate TPD-1 forms", Resource: "documents", Action: "create", Category: "document_management", IsActive: true, CreatedBy: "system", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		{ID: uuid.New(), Name: "document.countersign", Description: "Countersign engineering certificates", Resource: "documents", Action: "update", Category: "document_management", IsActive: true, CreatedBy: "system", CreatedAt: time.Now(), UpdatedAt: time.Now()},
 
 		// Payment Processing
 		{ID: uuid.New(), Name: "payment.process", Description: "Process application payments", Resource: "payments", Action: "create", Category: "financial_management", IsActive: true, CreatedBy: "system", CreatedAt: time.Now(), UpdatedAt: time.Now()},
 		{ID: uuid.New(), Name: "payment.verify", Description: "Verify payment receipts", Resource: "payments", Action: "read", Category: "financial_management", IsActive: true, CreatedBy: "system", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		{ID: uuid.New(), Name: "payment.reconcile", Description: "Receive duplicate payment alerts and resolve them", Resource: "payments", Action: "update", Category: "financial_management", IsActive: true, CreatedBy: "system", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+
+		// Permit Collection
+		{ID: uuid.New(), Name: "collection.manage", Description: "Manage permit collection calendars and appointments", Resource: "collections", Action: "update", Category: "application_management", IsActive: true, CreatedBy: "system", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		{ID: uuid.New(), Name: "permit.manage", Description: "Suspend, reinstate and revoke issued permits", Resource: "permits", Action: "update", Category: "application_management", IsActive: true, CreatedBy: "system", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+
+		// Planning Schemes
+		{ID: uuid.New(), Name: "planning_scheme.manage", Description: "Publish planning scheme documents and new scheme versions", Resource: "planning_schemes", Action: "update", Category: "application_management", IsActive: true, CreatedBy: "system", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+
+		// Review Checklists
+		{ID: uuid.New(), Name: "review_checklist.manage", Description: "Set the checklist items approvers must tick before approving", Resource: "review_checklists", Action: "update", Category: "application_management", IsActive: true, CreatedBy: "system", CreatedAt: time.Now(), UpdatedAt: time.Now()},
 
 		// Inspection Management
 		{ID: uuid.New(), Name: "inspection.schedule", Description: "Schedule site inspections", Resource: "inspections", Action: "create", Category: "inspection_management", IsActive: true, CreatedBy: "system", CreatedAt: time.Now(), UpdatedAt: time.Now()},
 		{ID: uuid.New(), Name: "inspection.conduct", Description: "Conduct site inspections", Resource: "inspections", Action: "create", Category: "inspection_management", IsActive: true, CreatedBy: "system", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		{ID: uuid.New(), Name: "inspection.review_photos", Description: "Accept or reject inspection photos flagged by verification", Resource: "inspection_photos", Action: "update", Category: "inspection_management", IsActive: true, CreatedBy: "system", CreatedAt: time.Now(), UpdatedAt: time.Now()},
 
 		// User Management (for directors/officers)
 		{ID: uuid.New(), Name: "user.manage", Description: "Manage system users", Resource: "users", Action: "create", Category: "user_management", IsActive: true, CreatedBy: "system", CreatedAt: time.Now(), UpdatedAt: time.Now()},
 		{ID: uuid.New(), Name: "user.read", Description: "View user information", Resource: "users", Action: "read", Category: "user_management", IsActive: true, CreatedBy: "system", CreatedAt: time.Now(), UpdatedAt: time.Now()},
 
+		// System administration
+		{ID: uuid.New(), Name: "settings.manage", Description: "View and change council-wide settings", Resource: "settings", Action: "update", Category: "system_administration", IsActive: true, CreatedBy: "system", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+
 		// Reporting
 		{ID: uuid.New(), Name: "report.generate", Description: "Generate system reports", Resource: "reports", Action: "read", Category: "reporting", IsActive: true, CreatedBy: "system", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		{ID: uuid.New(), Name: "report.submit", Description: "Lock quarterly national reports after submission", Resource: "reports", Action: "create", Category: "reporting", IsActive: true, CreatedBy: "system", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		{ID: uuid.New(), Name: "report.activity", Description: "View reviewer activity and receive the weekly activity digest", Resource: "reports", Action: "read", Category: "reporting", IsActive: true, CreatedBy: "system", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		{ID: uuid.New(), Name: "audit.export", Description: "Export the hash-chained decision audit log for oversight reviews", Resource: "audit", Action: "read", Category: "reporting", IsActive: true, CreatedBy: "system", CreatedAt: time.Now(), UpdatedAt: time.Now()},
 	}
 
 	createdCount := 0
@@ -255,6 +291,7 @@ func SeedTownPlanningPermissions(db *gorm.DB) error {
 					return fmt.Errorf("failed to create permission %s: %w", permission.Name, err)
 				}
 				createdCount++
+				report.record("permission", permission.Name, ActionCreated)
 				config.Logger.Info("Created permission", zap.String("name", permission.Name))
 			} else {
 				config.Logger.Error("Error checking for existing permission",
@@ -262,6 +299,8 @@ func SeedTownPlanningPermissions(db *gorm.DB) error {
 					zap.Error(result.Error))
 				return fmt.Errorf("error checking for permission %s: %w", permission.Name, result.Error)
 			}
+		} else if !opts.Overwrite {
+			report.record("permission", permission.Name, ActionUnchanged)
 		} else {
 			// Update existing permission
 			permission.ID = existingPermission.ID
@@ -272,6 +311,7 @@ func SeedTownPlanningPermissions(db *gorm.DB) error {
 				return fmt.Errorf("failed to update permission %s: %w", permission.Name, err)
 			}
 			updatedCount++
+			report.record("permission", permission.Name, ActionUpdated)
 			config.Logger.Info("Updated permission", zap.String("name", permission.Name))
 		}
 	}
@@ -284,7 +324,7 @@ func SeedTownPlanningPermissions(db *gorm.DB) error {
 }
 
 // SeedTownPlanningDocumentCategories seeds document categories for town planning
-func SeedTownPlanningDocumentCategories(db *gorm.DB) error {
+func SeedTownPlanningDocumentCategories(db *gorm.DB, opts Options, report *Report) error {
 	config.Logger.Info("Starting town planning document categories seeding...")
 	createdBy := "system"
 
@@ -301,14 +341,17 @@ func SeedTownPlanningDocumentCategories(db *gorm.DB) error {
 		{ID: uuid.New(), Name: "Initial Building Plan", Code: "INITIAL_PLAN", Description: "Initial architectural building plans", IsSystem: true, CreatedBy: createdBy},
 		{ID: uuid.New(), Name: "Site Plan", Code: "SITE_PLAN", Description: "Property site and layout plans", IsSystem: true, CreatedBy: createdBy},
 		{ID: uuid.New(), Name: "Building Plan", Code: "BUILDING_PLAN", Description: "Detailed architectural building plans", IsSystem: true, CreatedBy: createdBy},
+		{ID: uuid.New(), Name: "Amended Building Plan", Code: "AMENDED_PLAN", Description: "Revised plans submitted with post-approval amendments", IsSystem: true, CreatedBy: createdBy},
 		{ID: uuid.New(), Name: "Plan Comments Sheet", Code: "PLAN_COMMENTS_SHEET", Description: "Comments sheet for development plans", IsSystem: true, CreatedBy: createdBy},
 
 		// Engineering and Structural Documents
 		{ID: uuid.New(), Name: "Structural Engineering Certificate", Code: "ENGINEERING_CERTIFICATE", Description: "Structural engineering certificates", IsSystem: true, CreatedBy: createdBy},
 		{ID: uuid.New(), Name: "Ring Beam Certificate", Code: "RING_BEAM_CERTIFICATE", Description: "Ring beam construction certificates", IsSystem: true, CreatedBy: createdBy},
+		{ID: uuid.New(), Name: "Countersigned Engineering Certificate", Code: "COUNTERSIGNED_CERTIFICATE", Description: "Council engineer countersignatures of engineering certificates", IsSystem: true, CreatedBy: createdBy},
 
 		// Legal and Ownership Documents
 		{ID: uuid.New(), Name: "Title Deed", Code: "TITLE_DEED", Description: "Property title deeds", IsSystem: true, CreatedBy: createdBy},
+		{ID: uuid.New(), Name: "Sale Agreement", Code: "SALE_AGREEMENT", Description: "Property sale agreements", IsSystem: true, CreatedBy: createdBy},
 		{ID: uuid.New(), Name: "Survey Diagram", Code: "SURVEY_DIAGRAM", Description: "Land survey diagrams", IsSystem: true, CreatedBy: createdBy},
 		{ID: uuid.New(), Name: "Lease Agreement", Code: "LEASE_AGREEMENT", Description: "Property lease agreements", IsSystem: true, CreatedBy: createdBy},
 
@@ -324,6 +367,7 @@ func SeedTownPlanningDocumentCategories(db *gorm.DB) error {
 		{ID: uuid.New(), Name: "Development Permit", Code: "DEVELOPMENT_PERMIT", Description: "Development permits and approvals", IsSystem: true, CreatedBy: createdBy},
 		{ID: uuid.New(), Name: "Building Permit", Code: "BUILDING_PERMIT", Description: "Building construction permits", IsSystem: true, CreatedBy: createdBy},
 		{ID: uuid.New(), Name: "Occupation Certificate", Code: "OCCUPATION_CERTIFICATE", Description: "Certificate of occupation", IsSystem: true, CreatedBy: createdBy},
+		{ID: uuid.New(), Name: "Permit Status Evidence", Code: "PERMIT_STATUS_EVIDENCE", Description: "Court orders and investigation reports supporting permit suspension or revocation", IsSystem: true, CreatedBy: createdBy},
 
 		// Inspection Documents
 		{ID: uuid.New(), Name: "Site Inspection Report", Code: "INSPECTION_REPORT", Description: "Site inspection reports", IsSystem: true, CreatedBy: createdBy},
@@ -333,6 +377,9 @@ func SeedTownPlanningDocumentCategories(db *gorm.DB) error {
 		{ID: uuid.New(), Name: "Official Correspondence", Code: "OFFICIAL_CORRESPONDENCE", Description: "Official letters and correspondence", IsSystem: true, CreatedBy: createdBy},
 		{ID: uuid.New(), Name: "Approval Letter", Code: "APPROVAL_LETTER", Description: "Approval and decision letters", IsSystem: true, CreatedBy: createdBy},
 
+		// Planning schemes
+		{ID: uuid.New(), Name: "Planning Scheme", Code: "PLANNING_SCHEME", Description: "Zoning maps, local plans and other planning scheme documents", IsSystem: true, CreatedBy: createdBy},
+
 		{ID: uuid.New(), Name: "Other Documents", Code: "OTHER", Description: "Other uncategorized documents", IsSystem: true, CreatedBy: createdBy},
 	}
 
@@ -359,6 +406,7 @@ func SeedTownPlanningDocumentCategories(db *gorm.DB) error {
 					return fmt.Errorf("failed to create document category %s: %w", category.Code, err)
 				}
 				createdCount++
+				report.record("document category", category.Code, ActionCreated)
 				config.Logger.Info("Created document category",
 					zap.String("name", category.Name),
 					zap.String("code", category.Code))
@@ -368,6 +416,8 @@ func SeedTownPlanningDocumentCategories(db *gorm.DB) error {
 					zap.Error(result.Error))
 				return fmt.Errorf("error checking for document category %s: %w", category.Code, result.Error)
 			}
+		} else if !opts.Overwrite {
+			report.record("document category", category.Code, ActionUnchanged)
 		} else {
 			// Update existing category
 			category.ID = existingCategory.ID
@@ -378,6 +428,7 @@ func SeedTownPlanningDocumentCategories(db *gorm.DB) error {
 				return fmt.Errorf("failed to update document category %s: %w", category.Code, err)
 			}
 			updatedCount++
+			report.record("document category", category.Code, ActionUpdated)
 			config.Logger.Info("Updated document category",
 				zap.String("name", category.Name),
 				zap.String("code", category.Code))
@@ -392,7 +443,7 @@ func SeedTownPlanningDocumentCategories(db *gorm.DB) error {
 }
 
 // SeedTownPlanningUsers seeds initial users for town planning department
-func SeedTownPlanningUsers(db *gorm.DB) error {
+func SeedTownPlanningUsers(db *gorm.DB, opts Options, report *Report) error {
 	config.Logger.Info("Starting town planning users seeding...")
 
 	// Get roles
@@ -422,7 +473,7 @@ func SeedTownPlanningUsers(db *gorm.DB) error {
 			ID:            uuid.New(),
 			FirstName:     "Director",
 			LastName:      "Town Planning",
-			Email:         "director.townplanning@citycouncil.gov.zw",
+			Email:         opts.email("director.townplanning"),
 			Phone:         "+263242111111",
 			AuthMethod:    models.AuthMethodMagicLink,
 			RoleID:        directorRole.ID,
@@ -438,7 +489,7 @@ func SeedTownPlanningUsers(db *gorm.DB) error {
 			ID:            uuid.New(),
 			FirstName:     "Senior",
 			LastName:      "Planning Officer",
-			Email:         "officer.planning@citycouncil.gov.zw",
+			Email:         opts.email("officer.planning"),
 			Phone:         "+263242111112",
 			AuthMethod:    models.AuthMethodMagicLink,
 			RoleID:        officerRole.ID,
@@ -454,7 +505,7 @@ func SeedTownPlanningUsers(db *gorm.DB) error {
 			ID:            uuid.New(),
 			FirstName:     "Planning",
 			LastName:      "Technician",
-			Email:         "technician.planning@citycouncil.gov.zw",
+			Email:         opts.email("technician.planning"),
 			Phone:         "+263242111113",
 			AuthMethod:    models.AuthMethodMagicLink,
 			RoleID:        technicianRole.ID,
@@ -475,13 +526,7 @@ func SeedTownPlanningUsers(db *gorm.DB) error {
 
 		if result.Error != nil {
 			if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-				// Hash password
-				hashedPassword, err := bcrypt.GenerateFromPassword([]byte("Password123!"), bcrypt.DefaultCost)
-				if err != nil {
-					return fmt.Errorf("failed to hash password for %s: %w", user.Email, err)
-				}
-				user.Password = string(hashedPassword)
-
+				// Staff sign in by magic link, so no password is set
 				if err := db.Create(&user).Error; err != nil {
 					config.Logger.Error("Failed to create user",
 						zap.String("email", user.Email),
@@ -489,6 +534,7 @@ func SeedTownPlanningUsers(db *gorm.DB) error {
 					return fmt.Errorf("failed to create user %s: %w", user.Email, err)
 				}
 				createdCount++
+				report.record("user", user.Email, ActionCreated)
 				config.Logger.Info("Created town planning user",
 					zap.String("name", user.FirstName+" "+user.LastName),
 					zap.String("email", user.Email),
@@ -496,6 +542,9 @@ func SeedTownPlanningUsers(db *gorm.DB) error {
 			} else {
 				return fmt.Errorf("error checking for user %s: %w", user.Email, result.Error)
 			}
+		} else {
+			// Accounts are never overwritten; staff may have changed their details
+			report.record("user", user.Email, ActionUnchanged)
 		}
 	}
 
@@ -504,7 +553,7 @@ func SeedTownPlanningUsers(db *gorm.DB) error {
 }
 
 // CreateTownPlanningRolePermissions assigns permissions to town planning roles
-func CreateTownPlanningRolePermissions(db *gorm.DB) error {
+func CreateTownPlanningRolePermissions(db *gorm.DB, opts Options, report *Report) error {
 	config.Logger.Info("Starting town planning role permission assignments...")
 
 	// Get all roles
@@ -524,33 +573,35 @@ func CreateTownPlanningRolePermissions(db *gorm.DB) error {
 	rolePermissions := map[string][]string{
 		"Town Planning Director": {
 			// Full access
-			"application.submit", "application.read", "application.update", "application.review", "application.approve", "application.reject",
-			"document.upload", "document.read", "document.process", "document.generate.tpd1",
-			"payment.process", "payment.verify",
-			"inspection.schedule", "inspection.conduct",
-			"user.manage", "user.read",
-			"report.generate",
+			"application.submit", "application.read", "application.update", "application.review", "application.approve", "application.reject", "application.transfer", "application.rates_override", "application.amend", "application.rehydrate", "application.prescreen", "application.appeal", "appeal.decide", "issue.bulk_resolve", "chat.moderate",
+			"document.upload", "document.read", "document.process", "document.generate.tpd1", "document.countersign",
+			"payment.process", "payment.verify", "payment.reconcile",
+			"inspection.schedule", "inspection.conduct", "inspection.review_photos",
+			"collection.manage", "permit.manage", "planning_scheme.manage", "review_checklist.manage",
+			"user.manage", "user.read", "settings.manage",
+			"report.generate", "report.submit", "report.activity", "audit.export",
 		},
 		"Town Planning Officer": {
 			// Application review and approval
-			"application.read", "application.review", "application.approve", "application.reject",
+			"application.read", "application.review", "application.approve", "application.reject", "application.transfer",
 			"document.read", "document.process",
 			"payment.verify",
-			"inspection.schedule", "inspection.conduct",
+			"inspection.schedule", "inspection.conduct", "inspection.review_photos",
 			"user.read",
 			"report.generate",
 		},
 		"Planning Technician": {
 			// Document processing and basic application handling
-			"application.submit", "application.read", "application.update",
+			"application.submit", "application.read", "application.update", "application.amend", "application.appeal", "application.prescreen",
 			"document.upload", "document.read", "document.process", "document.generate.tpd1",
 			"payment.process", "payment.verify",
+			"collection.manage",
 			"user.read",
 		},
 		"Building Inspector": {
 			// Inspection focused
 			"application.read",
-			"document.read",
+			"document.read", "document.countersign",
 			"inspection.schedule", "inspection.conduct",
 			"user.read",
 		},
@@ -599,6 +650,11 @@ func CreateTownPlanningRolePermissions(db *gorm.DB) error {
 				}
 				roleAssignments++
 				totalAssignments++
+				report.record("role permission", role.Name+" "+permission.Name, ActionCreated)
+			} else if err != nil {
+				return fmt.Errorf("error checking role permission %s for %s: %w", permission.Name, role.Name, err)
+			} else {
+				report.record("role permission", role.Name+" "+permission.Name, ActionUnchanged)
 			}
 		}
 		config.Logger.Info("Role permissions assigned",
@@ -612,36 +668,68 @@ func CreateTownPlanningRolePermissions(db *gorm.DB) error {
 	return nil
 }
 
-// SeedTownPlanningAll runs all town planning seeding functions in correct order
-func SeedTownPlanningAll(db *gorm.DB) error {
-	config.Logger.Info("Starting comprehensive town planning database seeding...")
+// InvalidateSeededCaches drops cached roles, users and the approval groups embedding them so
+// the API sees what was seeded before the entries expire. Call it once the transaction the seeders ran in has committed;
+// dropping them earlier lets a concurrent read cache the old rows again.
+func InvalidateSeededCaches() {
+	cache.InvalidateAll(cache.EntityRolePermissions)
+	cache.InvalidateAll(cache.EntityUser)
+	cache.InvalidateAll(cache.EntityApprovalGroup)
+}
 
-	// Seed in order of dependencies
-	if err := SeedTownPlanningDepartments(db); err != nil {
-		return fmt.Errorf("failed to seed departments: %w", err)
+// SeedTownPlanningAll runs the seeders selected by opts in dependency order and records what
+// each one did in report. Call InvalidateSeededCaches after committing.
+func SeedTownPlanningAll(db *gorm.DB, opts Options, report *Report) error {
+	if err := opts.Validate(); err != nil {
+		return err
 	}
 
-	if err := SeedTownPlanningRoles(db); err != nil {
-		return fmt.Errorf("failed to seed roles: %w", err)
-	}
+	config.Logger.Info("Starting town planning database seeding...",
+		zap.String("environment", opts.Environment),
+		zap.String("tenant", opts.Tenant),
+		zap.Bool("roles", opts.Roles),
+		zap.Bool("users", opts.Users),
+		zap.Bool("demo", opts.Demo),
+		zap.Bool("overwrite", opts.Overwrite))
+
+	if opts.Roles {
+		if err := SeedTownPlanningDepartments(db, opts, report); err != nil {
+			return fmt.Errorf("failed to seed departments: %w", err)
+		}
 
-	if err := SeedTownPlanningPermissions(db); err != nil {
-		return fmt.Errorf("failed to seed permissions: %w", err)
-	}
+		if err := SeedTownPlanningRoles(db, opts, report); err != nil {
+			return fmt.Errorf("failed to seed roles: %w", err)
+		}
 
-	if err := SeedTownPlanningDocumentCategories(db); err != nil {
-		return fmt.Errorf("failed to seed document categories: %w", err)
+		if err := SeedTownPlanningPermissions(db, opts, report); err != nil {
+			return fmt.Errorf("failed to seed permissions: %w", err)
+		}
+
+		if err := SeedTownPlanningDocumentCategories(db, opts, report); err != nil {
+			return fmt.Errorf("failed to seed document categories: %w", err)
+		}
+
+		if err := CreateTownPlanningRolePermissions(db, opts, report); err != nil {
+			return fmt.Errorf("failed to create role permission associations: %w", err)
+		}
 	}
 
-	if err := CreateTownPlanningRolePermissions(db); err != nil {
-		return fmt.Errorf("failed to create role permission associations: %w", err)
+	if opts.Users {
+		if err := SeedTownPlanningUsers(db, opts, report); err != nil {
+			return fmt.Errorf("failed to seed users: %w", err)
+		}
 	}
 
-	if err := SeedTownPlanningUsers(db); err != nil {
-		return fmt.Errorf("failed to seed users: %w", err)
+	if opts.Demo {
+		if err := SeedTownPlanningDemoUsers(db, opts, report); err != nil {
+			return fmt.Errorf("failed to seed demo users: %w", err)
+		}
 	}
 
-	config.Logger.Info("All town planning database seeding completed successfully")
+	config.Logger.Info("Town planning database seeding completed",
+		zap.Int("created", report.Count(ActionCreated)),
+		zap.Int("updated", report.Count(ActionUpdated)),
+		zap.Int("unchanged", report.Count(ActionUnchanged)))
 	return nil
 }
 
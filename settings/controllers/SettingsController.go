@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"strings"
+	"town-planning-backend/config"
+	"town-planning-backend/settings/repositories"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type SettingsController struct {
+	SettingsRepo repositories.SettingsRepository
+	DB           *gorm.DB
+}
+
+// UpdateSettingRequest sets a new value. A null value resets the setting to its default.
+type UpdateSettingRequest struct {
+	Value  *string `json:"value"`
+	Reason *string `json:"reason"`
+}
+
+func settingErrorStatus(err error) int {
+	switch {
+	case err.Error() == "setting not found":
+		return fiber.StatusNotFound
+	case err.Error() == "setting already has this value":
+		return fiber.StatusConflict
+	case strings.HasPrefix(err.Error(), "failed to"):
+		return fiber.StatusInternalServerError
+	default:
+		return fiber.StatusBadRequest // Value rejected by the setting's definition
+	}
+}
+
+// GetSettingsController lists every setting with its current value and where it came from
+func (sc *SettingsController) GetSettingsController(c *fiber.Ctx) error {
+	views, err := sc.SettingsRepo.GetSettings()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch settings",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Settings retrieved",
+		"data":    views,
+	})
+}
+
+// GetSettingController returns one setting
+func (sc *SettingsController) GetSettingController(c *fiber.Ctx) error {
+	view, err := sc.SettingsRepo.GetSetting(c.Params("key"))
+	if err != nil {
+		return c.Status(settingErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch setting",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Setting retrieved",
+		"data":    view,
+	})
+}
+
+// UpdateSettingController changes a setting and records who changed it. The new value applies
+// to every instance once the cached settings are invalidated.
+func (sc *SettingsController) UpdateSettingController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	var request UpdateSettingRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request payload",
+			"error":   err.Error(),
+		})
+	}
+	if request.Reason != nil {
+		reason := strings.TrimSpace(*request.Reason)
+		request.Reason = &reason
+		if reason == "" {
+			request.Reason = nil
+		}
+	}
+
+	return sc.applySettingChange(c, payload, c.Params("key"), request.Value, request.Reason)
+}
+
+// ResetSettingController returns a setting to its environment value or default
+func (sc *SettingsController) ResetSettingController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	var reason *string
+	if value := strings.TrimSpace(c.Query("reason")); value != "" {
+		reason = &value
+	}
+
+	return sc.applySettingChange(c, payload, c.Params("key"), nil, reason)
+}
+
+func (sc *SettingsController) applySettingChange(
+	c *fiber.Ctx,
+	payload *token.Payload,
+	key string,
+	value *string,
+	reason *string,
+) error {
+	tx := sc.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	view, err := sc.SettingsRepo.UpdateSetting(tx, key, value, reason, payload.UserID)
+	if err != nil {
+		tx.Rollback()
+		return c.Status(settingErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to update setting",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not commit database transaction",
+			"error":   err.Error(),
+		})
+	}
+	sc.SettingsRepo.InvalidateCache()
+
+	config.Logger.Info("Setting changed",
+		zap.String("key", key),
+		zap.String("value", view.Value),
+		zap.String("source", view.Source),
+		zap.String("changedBy", payload.UserID.String()))
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Setting updated",
+		"data":    view,
+	})
+}
+
+// GetSettingHistoryController lists the changes made to a setting, newest first
+func (sc *SettingsController) GetSettingHistoryController(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 50)
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	changes, err := sc.SettingsRepo.GetSettingHistory(c.Params("key"), limit)
+	if err != nil {
+		return c.Status(settingErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch setting history",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Setting history retrieved",
+		"data":    changes,
+	})
+}
@@ -0,0 +1,282 @@
+package settings
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Type is the kind of value a setting holds
+type Type string
+
+const (
+	TypeBool Type = "BOOL"
+	TypeInt  Type = "INT"
+	TypeEnum Type = "ENUM"
+)
+
+// Keys of the council-wide settings
+const (
+	AutoRejectOnMemberRejection  = "approvals.auto_reject_on_member_rejection"
+	DecisionSLADays              = "decisions.sla_days"
+	DecisionReminderIntervalDays = "decisions.reminder_interval_days"
+	DecisionEscalateAfterDays    = "decisions.escalate_after_days"
+	ApplicationStorageQuotaMB    = "uploads.application_quota_mb"
+	ApplicantStorageQuotaMB      = "uploads.applicant_quota_mb"
+	ApplicationDraftExpiryDays   = "applications.draft_expiry_days"
+	AddressValidation            = "addresses.validation"
+	InspectionInvoicing          = "inspections.invoicing"
+	ApplicationPreScreening      = "applications.pre_screening"
+	ApprovalQuorumEnforcement    = "approvals.quorum_enforcement"
+	ChatEmailBridge              = "chat.email_bridge"
+	DeedsVerificationMaxAgeDays  = "applications.deeds_verification_max_age_days"
+	ChatEmailBridgeAfterMinutes  = "chat.email_bridge_after_minutes"
+)
+
+// Values of AddressValidation
+const (
+	AddressValidationOff    = "OFF"    // Addresses are normalized but not checked
+	AddressValidationWarn   = "WARN"   // Unrecognised cities and suburbs are reported but accepted
+	AddressValidationStrict = "STRICT" // Unrecognised cities and suburbs are rejected
+)
+
+// Values of InspectionInvoicing
+const (
+	InspectionInvoicingOff          = "OFF"           // Inspection fees are billed through the tariff only
+	InspectionInvoicingOnSchedule   = "ON_SCHEDULE"   // Each inspection is invoiced when it is scheduled
+	InspectionInvoicingOnCompletion = "ON_COMPLETION" // Each inspection is invoiced when it is completed
+)
+
+// Values of ApprovalQuorumEnforcement
+const (
+	ApprovalQuorumOff    = "OFF"    // Groups are assigned whatever their members' availability
+	ApprovalQuorumWarn   = "WARN"   // Assignments to groups without a quorum succeed with a warning
+	ApprovalQuorumReject = "REJECT" // Assignments to groups without a quorum are refused
+)
+
+// Definition describes a setting: its type, the values it accepts and its default. EnvVar names
+// the environment variable that supplied the value before the setting existed; when set it
+// takes the place of Default until an administrator changes the setting.
+type Definition struct {
+	Key         string   `json:"key"`
+	Type        Type     `json:"type"`
+	Category    string   `json:"category"`
+	Description string   `json:"description"`
+	Default     string   `json:"default"`
+	EnvVar      string   `json:"env_var,omitempty"`
+	Min         *int     `json:"min,omitempty"`
+	Max         *int     `json:"max,omitempty"`
+	Options     []string `json:"options,omitempty"` // ENUM settings only
+}
+
+func intRange(min, max int) (*int, *int) {
+	return &min, &max
+}
+
+var definitions = map[string]Definition{}
+
+func define(def Definition) {
+	definitions[def.Key] = def
+}
+
+func init() {
+	define(Definition{
+		Key:         AutoRejectOnMemberRejection,
+		Type:        TypeBool,
+		Category:    "approvals",
+		Description: "Reject an application automatically once every regular member has decided and one rejected. When off, the final approver decides.",
+		Default:     "true",
+	})
+
+	min, max := intRange(1, 90)
+	define(Definition{
+		Key:         DecisionSLADays,
+		Type:        TypeInt,
+		Category:    "decisions",
+		Description: "Days a member decision may stay pending before the approver is reminded",
+		Default:     "3",
+		EnvVar:      "DECISION_REMINDER_AFTER_DAYS",
+		Min:         min,
+		Max:         max,
+	})
+	min, max = intRange(1, 30)
+	define(Definition{
+		Key:         DecisionReminderIntervalDays,
+		Type:        TypeInt,
+		Category:    "decisions",
+		Description: "Days between further reminders of a pending decision",
+		Default:     "2",
+		EnvVar:      "DECISION_REMINDER_INTERVAL_DAYS",
+		Min:         min,
+		Max:         max,
+	})
+	min, max = intRange(1, 180)
+	define(Definition{
+		Key:         DecisionEscalateAfterDays,
+		Type:        TypeInt,
+		Category:    "decisions",
+		Description: "Days a decision may stay pending before it is escalated to the department head",
+		Default:     "7",
+		EnvVar:      "DECISION_ESCALATE_AFTER_DAYS",
+		Min:         min,
+		Max:         max,
+	})
+
+	min, max = intRange(1, 10240)
+	define(Definition{
+		Key:         ApplicationStorageQuotaMB,
+		Type:        TypeInt,
+		Category:    "uploads",
+		Description: "Megabytes of files that can be linked to one application",
+		Default:     "250",
+		EnvVar:      "APPLICATION_STORAGE_QUOTA_MB",
+		Min:         min,
+		Max:         max,
+	})
+	min, max = intRange(1, 102400)
+	define(Definition{
+		Key:         ApplicantStorageQuotaMB,
+		Type:        TypeInt,
+		Category:    "uploads",
+		Description: "Megabytes of files that can be linked to one applicant across all their applications",
+		Default:     "1024",
+		EnvVar:      "APPLICANT_STORAGE_QUOTA_MB",
+		Min:         min,
+		Max:         max,
+	})
+
+	min, max = intRange(1, 365)
+	define(Definition{
+		Key:         ApplicationDraftExpiryDays,
+		Type:        TypeInt,
+		Category:    "applications",
+		Description: "Days an application draft is kept after it was last saved",
+		Default:     "14",
+		EnvVar:      "APPLICATION_DRAFT_EXPIRY_DAYS",
+		Min:         min,
+		Max:         max,
+	})
+
+	min, max = intRange(1, 3650)
+	define(Definition{
+		Key:         DeedsVerificationMaxAgeDays,
+		Type:        TypeInt,
+		Category:    "applications",
+		Description: "Days a deeds registry ownership check stays current. Approvers are warned when the latest check is older.",
+		Default:     "90",
+		EnvVar:      "DEEDS_VERIFICATION_MAX_AGE_DAYS",
+		Min:         min,
+		Max:         max,
+	})
+
+	define(Definition{
+		Key:         ApplicationPreScreening,
+		Type:        TypeBool,
+		Category:    "applications",
+		Description: "Queue new submissions for technicians to pre-screen before they are assigned to an approval group. When off, they are assigned as soon as they are submitted.",
+		Default:     "false",
+	})
+
+	define(Definition{
+		Key:         ApprovalQuorumEnforcement,
+		Type:        TypeEnum,
+		Category:    "approvals",
+		Description: "What happens when an application is assigned to a group whose available approving members do not weigh MinimumApprovals, or which has no active final approver: OFF assigns it anyway, WARN assigns it and reports the shortfall with alternative groups, REJECT refuses the assignment",
+		Default:     ApprovalQuorumWarn,
+		Options:     []string{ApprovalQuorumOff, ApprovalQuorumWarn, ApprovalQuorumReject},
+	})
+
+	define(Definition{
+		Key:         ChatEmailBridge,
+		Type:        TypeBool,
+		Category:    "chat",
+		Description: "Email unread chat messages to participants who have not opened the thread, with an address they can reply to. Replies are posted into the thread as their messages.",
+		Default:     "false",
+	})
+	min, max = intRange(5, 1440)
+	define(Definition{
+		Key:         ChatEmailBridgeAfterMinutes,
+		Type:        TypeInt,
+		Category:    "chat",
+		Description: "Minutes a message may stay unread, with the participant away from the thread, before it is emailed to them",
+		Default:     "60",
+		EnvVar:      "CHAT_EMAIL_BRIDGE_AFTER_MINUTES",
+		Min:         min,
+		Max:         max,
+	})
+
+	define(Definition{
+		Key:         AddressValidation,
+		Type:        TypeEnum,
+		Category:    "addresses",
+		Description: "How applicant and project addresses are checked against the suburb reference table: OFF only normalizes them, WARN reports unrecognised cities and suburbs, STRICT rejects them",
+		Default:     AddressValidationWarn,
+		Options:     []string{AddressValidationOff, AddressValidationWarn, AddressValidationStrict},
+	})
+
+	define(Definition{
+		Key:         InspectionInvoicing,
+		Type:        TypeEnum,
+		Category:    "inspections",
+		Description: "When inspections are invoiced separately at the tariff's inspection fee: OFF bills them through the tariff only, ON_SCHEDULE invoices each inspection when it is scheduled, ON_COMPLETION when it is completed. Compliance certificates are withheld while an inspection invoice is unpaid.",
+		Default:     InspectionInvoicingOff,
+		Options:     []string{InspectionInvoicingOff, InspectionInvoicingOnSchedule, InspectionInvoicingOnCompletion},
+	})
+}
+
+// Lookup returns the definition of a setting
+func Lookup(key string) (Definition, bool) {
+	def, ok := definitions[key]
+	return def, ok
+}
+
+// Definitions returns every setting, ordered by category and key
+func Definitions() []Definition {
+	all := make([]Definition, 0, len(definitions))
+	for _, def := range definitions {
+		all = append(all, def)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Category != all[j].Category {
+			return all[i].Category < all[j].Category
+		}
+		return all[i].Key < all[j].Key
+	})
+	return all
+}
+
+// Normalize checks a value against the setting's definition and returns it in the form it is
+// stored in
+func (d Definition) Normalize(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	switch d.Type {
+	case TypeBool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return "", fmt.Errorf("%s must be true or false", d.Key)
+		}
+		return strconv.FormatBool(value), nil
+	case TypeInt:
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			return "", fmt.Errorf("%s must be a whole number", d.Key)
+		}
+		if d.Min != nil && value < *d.Min {
+			return "", fmt.Errorf("%s must be at least %d", d.Key, *d.Min)
+		}
+		if d.Max != nil && value > *d.Max {
+			return "", fmt.Errorf("%s must be at most %d", d.Key, *d.Max)
+		}
+		return strconv.Itoa(value), nil
+	case TypeEnum:
+		for _, option := range d.Options {
+			if strings.EqualFold(option, raw) {
+				return option, nil
+			}
+		}
+		return "", fmt.Errorf("%s must be one of %s", d.Key, strings.Join(d.Options, ", "))
+	default:
+		return "", fmt.Errorf("%s has unknown type %s", d.Key, d.Type)
+	}
+}
@@ -0,0 +1,215 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"town-planning-backend/cache"
+	"town-planning-backend/db/models"
+	"town-planning-backend/settings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// overridesCacheID is the cache id of the map of every changed setting, read as one entry
+const overridesCacheID = "overrides"
+
+type SettingsRepository interface {
+	settings.Source
+	GetSettings() ([]SettingView, error)
+	GetSetting(key string) (*SettingView, error)
+	UpdateSetting(tx *gorm.DB, key string, value *string, reason *string, changedByID uuid.UUID) (*SettingView, error)
+	GetSettingHistory(key string, limit int) ([]models.SettingChange, error)
+	InvalidateCache()
+}
+
+type settingsRepository struct {
+	db    *gorm.DB
+	cache *cache.RepositoryCache
+}
+
+func NewSettingsRepository(db *gorm.DB, repoCache *cache.RepositoryCache) SettingsRepository {
+	return &settingsRepository{
+		db:    db,
+		cache: repoCache,
+	}
+}
+
+// SettingView is a setting's definition with its current value and where that value came from
+type SettingView struct {
+	settings.Definition
+	Value     string     `json:"value"`
+	Source    string     `json:"source"` // admin, env or default
+	UpdatedBy *string    `json:"updated_by,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// Overrides returns the values administrators have set, from the cache when it holds them
+func (r *settingsRepository) Overrides() (map[string]string, error) {
+	overrides := map[string]string{}
+	if r.cache.Get(cache.EntitySetting, overridesCacheID, &overrides) {
+		return overrides, nil
+	}
+
+	stored, err := r.storedSettings()
+	if err != nil {
+		return nil, err
+	}
+	for key, setting := range stored {
+		overrides[key] = setting.Value
+	}
+	r.cache.Set(cache.EntitySetting, overridesCacheID, overrides)
+	return overrides, nil
+}
+
+func (r *settingsRepository) storedSettings() (map[string]models.Setting, error) {
+	var rows []models.Setting
+	if err := r.db.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load settings: %w", err)
+	}
+	stored := make(map[string]models.Setting, len(rows))
+	for _, row := range rows {
+		stored[row.Key] = row
+	}
+	return stored, nil
+}
+
+func newSettingView(def settings.Definition, stored map[string]models.Setting) SettingView {
+	overrides := map[string]string{}
+	if setting, ok := stored[def.Key]; ok {
+		overrides[def.Key] = setting.Value
+	}
+	value, source := settings.Resolve(def, overrides)
+
+	view := SettingView{Definition: def, Value: value, Source: source}
+	if setting, ok := stored[def.Key]; ok && source == settings.FromAdmin {
+		view.UpdatedBy = &setting.UpdatedBy
+		view.UpdatedAt = &setting.UpdatedAt
+	}
+	return view
+}
+
+// GetSettings returns every setting with its current value. It reads the database directly so
+// the console always shows what is stored.
+func (r *settingsRepository) GetSettings() ([]SettingView, error) {
+	stored, err := r.storedSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	definitions := settings.Definitions()
+	views := make([]SettingView, 0, len(definitions))
+	for _, def := range definitions {
+		views = append(views, newSettingView(def, stored))
+	}
+	return views, nil
+}
+
+func (r *settingsRepository) GetSetting(key string) (*SettingView, error) {
+	def, ok := settings.Lookup(key)
+	if !ok {
+		return nil, errors.New("setting not found")
+	}
+	stored, err := r.storedSettings()
+	if err != nil {
+		return nil, err
+	}
+	view := newSettingView(def, stored)
+	return &view, nil
+}
+
+// UpdateSetting validates and stores a new value, or with a nil value resets the setting to its
+// default, and records the change. The cache is invalidated by the caller once the transaction
+// commits, with InvalidateCache.
+func (r *settingsRepository) UpdateSetting(
+	tx *gorm.DB,
+	key string,
+	value *string,
+	reason *string,
+	changedByID uuid.UUID,
+) (*SettingView, error) {
+	def, ok := settings.Lookup(key)
+	if !ok {
+		return nil, errors.New("setting not found")
+	}
+
+	var newValue *string
+	if value != nil {
+		normalized, err := def.Normalize(*value)
+		if err != nil {
+			return nil, err
+		}
+		newValue = &normalized
+	}
+
+	var existing models.Setting
+	var oldValue *string
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("key = ?", key).First(&existing).Error
+	switch {
+	case err == nil:
+		oldValue = &existing.Value
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, fmt.Errorf("failed to load setting: %w", err)
+	}
+
+	if (oldValue == nil && newValue == nil) || (oldValue != nil && newValue != nil && *oldValue == *newValue) {
+		return nil, errors.New("setting already has this value")
+	}
+
+	if newValue == nil {
+		if err := tx.Delete(&models.Setting{}, "key = ?", key).Error; err != nil {
+			return nil, fmt.Errorf("failed to reset setting: %w", err)
+		}
+	} else {
+		setting := models.Setting{Key: key, Value: *newValue, UpdatedBy: changedByID.String()}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "key"}},
+			DoUpdates: clause.AssignmentColumns([]string{"value", "updated_by", "updated_at"}),
+		}).Create(&setting).Error; err != nil {
+			return nil, fmt.Errorf("failed to save setting: %w", err)
+		}
+	}
+
+	change := models.SettingChange{
+		ID:          uuid.New(),
+		Key:         key,
+		OldValue:    oldValue,
+		NewValue:    newValue,
+		Reason:      reason,
+		ChangedByID: changedByID,
+		ChangedAt:   time.Now(),
+	}
+	if err := tx.Create(&change).Error; err != nil {
+		return nil, fmt.Errorf("failed to record setting change: %w", err)
+	}
+
+	stored := map[string]models.Setting{}
+	if newValue != nil {
+		stored[key] = models.Setting{Key: key, Value: *newValue, UpdatedBy: changedByID.String(), UpdatedAt: change.ChangedAt}
+	}
+	view := newSettingView(def, stored)
+	return &view, nil
+}
+
+// GetSettingHistory returns the changes made to a setting, newest first
+func (r *settingsRepository) GetSettingHistory(key string, limit int) ([]models.SettingChange, error) {
+	if _, ok := settings.Lookup(key); !ok {
+		return nil, errors.New("setting not found")
+	}
+
+	var changes []models.SettingChange
+	err := r.db.
+		Preload("ChangedBy").
+		Where("key = ?", key).
+		Order("changed_at DESC").
+		Limit(limit).
+		Find(&changes).Error
+	return changes, err
+}
+
+// InvalidateCache drops the cached settings so the next read sees the database
+func (r *settingsRepository) InvalidateCache() {
+	r.cache.Invalidate(cache.EntitySetting, overridesCacheID)
+}
@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"town-planning-backend/middleware"
+	"town-planning-backend/settings/controllers"
+	"town-planning-backend/settings/repositories"
+	user_repository "town-planning-backend/users/repositories"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+func SettingsRouterInit(
+	app *fiber.App,
+	db *gorm.DB,
+	settingsRepository repositories.SettingsRepository,
+	userRepo user_repository.UserRepository,
+) {
+	settingsController := &controllers.SettingsController{
+		SettingsRepo: settingsRepository,
+		DB:           db,
+	}
+
+	settingsRoutes := app.Group("/api/v1/settings", middleware.RequirePermission(userRepo, "settings.manage"))
+	settingsRoutes.Get("/", settingsController.GetSettingsController)
+	settingsRoutes.Get("/:key", settingsController.GetSettingController)
+	settingsRoutes.Get("/:key/history", settingsController.GetSettingHistoryController)
+	settingsRoutes.Put("/:key", settingsController.UpdateSettingController)
+	settingsRoutes.Delete("/:key", settingsController.ResetSettingController)
+}
@@ -0,0 +1,95 @@
+package settings
+
+import (
+	"os"
+	"strconv"
+	"town-planning-backend/config"
+
+	"go.uber.org/zap"
+)
+
+// Source supplies the values administrators have set, keyed by setting key
+type Source interface {
+	Overrides() (map[string]string, error)
+}
+
+// Value source names reported to administrators
+const (
+	FromAdmin   = "admin"
+	FromEnv     = "env"
+	FromDefault = "default"
+)
+
+// ===========================================================================
+// Process-wide source used by the features that read settings
+// ===========================================================================
+
+var shared Source
+
+// Init sets the process-wide source. Until it is called settings come from the environment
+// and their defaults.
+func Init(source Source) {
+	shared = source
+}
+
+// Resolve returns the value of a setting from the given overrides, falling back to its
+// environment variable and then its default, and says where the value came from
+func Resolve(def Definition, overrides map[string]string) (string, string) {
+	if value, ok := overrides[def.Key]; ok {
+		if normalized, err := def.Normalize(value); err == nil {
+			return normalized, FromAdmin
+		}
+		config.Logger.Warn("Stored setting is no longer valid, ignoring it",
+			zap.String("key", def.Key),
+			zap.String("value", value))
+	}
+	if def.EnvVar != "" {
+		if raw := os.Getenv(def.EnvVar); raw != "" {
+			if normalized, err := def.Normalize(raw); err == nil {
+				return normalized, FromEnv
+			}
+			config.Logger.Warn("Invalid setting, using default",
+				zap.String("variable", def.EnvVar),
+				zap.String("value", raw),
+				zap.String("default", def.Default))
+		}
+	}
+	return def.Default, FromDefault
+}
+
+func value(key string) string {
+	def, ok := Lookup(key)
+	if !ok {
+		config.Logger.Error("Unknown setting requested", zap.String("key", key))
+		return ""
+	}
+
+	var overrides map[string]string
+	if shared != nil {
+		var err error
+		if overrides, err = shared.Overrides(); err != nil {
+			config.Logger.Warn("Failed to load settings, using defaults",
+				zap.String("key", key),
+				zap.Error(err))
+		}
+	}
+	resolved, _ := Resolve(def, overrides)
+	return resolved
+}
+
+// Int returns the value of an INT setting
+func Int(key string) int {
+	parsed, _ := strconv.Atoi(value(key))
+	return parsed
+}
+
+// Bool returns the value of a BOOL setting
+func Bool(key string) bool {
+	parsed, _ := strconv.ParseBool(value(key))
+	return parsed
+}
+
+// String returns the value of an ENUM setting
+func String(key string) string {
+	return value(key)
+}
@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"net/url"
+	"town-planning-backend/config"
+	"town-planning-backend/sms/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type SMSController struct {
+	SMSSvc *services.SMSService
+
+	// SMS_WEBHOOK_TOKEN, the secret the status callbacks carry as ?token=. Callbacks are
+	// refused while it is empty.
+	WebhookToken string
+}
+
+// TwilioStatusController receives Twilio's message status callbacks, which must carry both the
+// webhook token and a valid X-Twilio-Signature
+func (sc *SMSController) TwilioStatusController(c *fiber.Ctx) error {
+	if !sc.webhookAuthorized(c) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid webhook token",
+		})
+	}
+
+	form := url.Values{}
+	c.Request().PostArgs().VisitAll(func(key, value []byte) {
+		form.Add(string(key), string(value))
+	})
+	if !sc.SMSSvc.VerifyTwilioSignature(c.OriginalURL(), form, c.Get("X-Twilio-Signature")) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid webhook signature",
+		})
+	}
+
+	if err := sc.SMSSvc.HandleTwilioStatus(c.FormValue("MessageSid"), c.FormValue("MessageStatus"), c.FormValue("ErrorCode")); err != nil {
+		config.Logger.Warn("Failed to record Twilio status callback",
+			zap.String("messageSid", c.FormValue("MessageSid")),
+			zap.String("status", c.FormValue("MessageStatus")),
+			zap.Error(err))
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to record status",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Status recorded",
+	})
+}
+
+// AggregatorStatusController receives the local aggregator's delivery reports
+func (sc *SMSController) AggregatorStatusController(c *fiber.Ctx) error {
+	if !sc.webhookAuthorized(c) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid webhook token",
+		})
+	}
+
+	if err := sc.SMSSvc.HandleAggregatorStatus(c.Body()); err != nil {
+		config.Logger.Warn("Failed to record aggregator delivery report", zap.Error(err))
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to record status",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Status recorded",
+	})
+}
+
+func (sc *SMSController) webhookAuthorized(c *fiber.Ctx) bool {
+	if sc.WebhookToken == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(c.Query("token")), []byte(sc.WebhookToken)) == 1
+}
+
+// GetApplicationMessagesController lists the text messages sent about an application, which
+// provider carried them and whether they were delivered
+func (sc *SMSController) GetApplicationMessagesController(c *fiber.Ctx) error {
+	applicationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid application ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	messages, err := sc.SMSSvc.GetApplicationMessages(applicationID)
+	if err != nil {
+		config.Logger.Error("Failed to fetch application text messages",
+			zap.Error(err),
+			zap.String("applicationID", applicationID.String()))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch text messages",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Text messages retrieved successfully",
+		"data":    messages,
+	})
+}
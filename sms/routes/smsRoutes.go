@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"town-planning-backend/sms/controllers"
+	"town-planning-backend/sms/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SMSRouterInit registers the SMS endpoints. The providers' status callbacks carry the
+// SMS_WEBHOOK_TOKEN secret, e.g. /sms/webhooks/twilio?token=..., and Twilio's are also signed.
+func SMSRouterInit(app *fiber.App, smsService *services.SMSService, webhookToken string) {
+	smsController := &controllers.SMSController{
+		SMSSvc:       smsService,
+		WebhookToken: webhookToken,
+	}
+
+	// Called by the providers, so outside the staff routes
+	app.Post("/sms/webhooks/twilio", smsController.TwilioStatusController)
+	app.Post("/sms/webhooks/aggregator", smsController.AggregatorStatusController)
+
+	// Delivery status of an application's text messages
+	app.Get("/api/v1/applications/:id/sms", smsController.GetApplicationMessagesController)
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Message is one text message ready to hand to a provider
+type Message struct {
+	ID          uuid.UUID // SMSLog ID, passed to providers that echo a reference back
+	To          string    // E.164
+	Body        string
+	CallbackURL string // Where the provider reports delivery status; empty turns callbacks off
+}
+
+// Provider delivers text messages. Send returns the provider's ID for the message, which its
+// status callbacks refer to.
+type Provider interface {
+	Name() string
+	Send(ctx context.Context, message *Message) (string, error)
+}
+
+// NewProvidersFromEnv builds the providers listed in SMS_PROVIDERS, in the order they are tried.
+// Each council orders them for its own network, e.g. the local aggregator first and Twilio as
+// the fallback. An empty list turns SMS off.
+//
+//	twilio       TWILIO_ACCOUNT_SID, TWILIO_AUTH_TOKEN and TWILIO_FROM (a number or messaging
+//	             service SID)
+//	aggregator   SMS_AGGREGATOR_URL, SMS_AGGREGATOR_API_KEY, SMS_AGGREGATOR_SENDER_ID
+func NewProvidersFromEnv() ([]Provider, error) {
+	providers := []Provider{}
+	seen := map[string]bool{}
+	for _, name := range strings.Split(os.Getenv("SMS_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		var provider Provider
+		var err error
+		switch name {
+		case "twilio":
+			provider, err = NewTwilioProvider(
+				os.Getenv("TWILIO_ACCOUNT_SID"),
+				os.Getenv("TWILIO_AUTH_TOKEN"),
+				os.Getenv("TWILIO_FROM"),
+			)
+		case "aggregator":
+			provider, err = NewAggregatorProvider(
+				os.Getenv("SMS_AGGREGATOR_URL"),
+				os.Getenv("SMS_AGGREGATOR_API_KEY"),
+				os.Getenv("SMS_AGGREGATOR_SENDER_ID"),
+			)
+		default:
+			return nil, fmt.Errorf("unknown SMS provider %q in SMS_PROVIDERS, expected twilio or aggregator", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, provider)
+	}
+	return providers, nil
+}
+
+const defaultCountryCode = "263"
+
+var (
+	e164Pattern     = regexp.MustCompile(`^\+[1-9]\d{7,14}$`)
+	phoneSeparators = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "")
+)
+
+// NormalizePhoneNumber turns a number as applicants type it into E.164. Local numbers with a
+// leading 0 are taken to be in SMS_COUNTRY_CODE (263 when not set).
+func NormalizePhoneNumber(phone string) (string, error) {
+	number := phoneSeparators.Replace(strings.TrimSpace(phone))
+	switch {
+	case strings.HasPrefix(number, "00"):
+		number = "+" + number[2:]
+	case strings.HasPrefix(number, "0"):
+		countryCode := strings.TrimPrefix(os.Getenv("SMS_COUNTRY_CODE"), "+")
+		if countryCode == "" {
+			countryCode = defaultCountryCode
+		}
+		number = "+" + countryCode + number[1:]
+	case !strings.HasPrefix(number, "+"):
+		number = "+" + number
+	}
+
+	if !e164Pattern.MatchString(number) {
+		return "", fmt.Errorf("phone number %q is not a valid mobile number", phone)
+	}
+	return number, nil
+}
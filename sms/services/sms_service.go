@@ -0,0 +1,310 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// TypeSendSMS is the task that delivers one queued text message
+	TypeSendSMS = "sms:send"
+
+	smsQueue              = "sms"
+	smsSendRetries        = 4
+	defaultSMSConcurrency = 5
+)
+
+// SMS is a text message to send
+type SMS struct {
+	To           string // Normalized to E.164 before sending
+	Body         string
+	Language     string
+	MessageType  string // e.g. "PERMIT_STATUS_CHANGE"
+	TemplateName *string
+
+	ApplicationID *uuid.UUID
+	ApplicantID   *uuid.UUID
+
+	CreatedBy string // Defaults to "system"
+}
+
+// SMSService records every text message in the communication log and hands it to the providers
+// through the task queue. Each delivery tries the providers in order until one accepts it.
+type SMSService struct {
+	db           *gorm.DB
+	providers    []Provider
+	queue        *asynq.Client // Nil sends straight away
+	baseURL      string        // Public backend URL for status callbacks
+	webhookToken string        // Status callbacks are only requested when set
+}
+
+func NewSMSService(db *gorm.DB, providers []Provider, queue *asynq.Client, baseURL string, webhookToken string) *SMSService {
+	return &SMSService{
+		db:           db,
+		providers:    providers,
+		queue:        queue,
+		baseURL:      strings.TrimRight(baseURL, "/"),
+		webhookToken: webhookToken,
+	}
+}
+
+var defaultService *SMSService
+
+// Init sets the service behind utils.SendApplicantSMS
+func Init(service *SMSService) {
+	defaultService = service
+}
+
+// Default returns the service set by Init, or nil before then
+func Default() *SMSService {
+	return defaultService
+}
+
+// Enabled reports whether any provider is configured
+func (s *SMSService) Enabled() bool {
+	return s != nil && len(s.providers) > 0
+}
+
+// ProviderNames lists the providers in the order they are tried
+func (s *SMSService) ProviderNames() []string {
+	names := make([]string, 0, len(s.providers))
+	for _, provider := range s.providers {
+		names = append(names, provider.Name())
+	}
+	return names
+}
+
+type sendSMSPayload struct {
+	SMSLogID uuid.UUID `json:"sms_log_id"`
+}
+
+// Send logs the message as queued and enqueues its delivery. An error means the message will
+// not go out; failures after queueing are retried and end up on the log as FAILED.
+func (s *SMSService) Send(ctx context.Context, sms SMS) (*models.SMSLog, error) {
+	if !s.Enabled() {
+		return nil, errors.New("no SMS provider is configured")
+	}
+	to, err := NormalizePhoneNumber(sms.To)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(sms.Body) == "" {
+		return nil, errors.New("SMS has no message")
+	}
+
+	createdBy := sms.CreatedBy
+	if createdBy == "" {
+		createdBy = "system"
+	}
+	log := &models.SMSLog{
+		ID:            uuid.New(),
+		Recipient:     to,
+		Message:       sms.Body,
+		Language:      sms.Language,
+		ApplicationID: sms.ApplicationID,
+		ApplicantID:   sms.ApplicantID,
+		MessageType:   sms.MessageType,
+		TemplateName:  sms.TemplateName,
+		Status:        models.SMSStatusQueued,
+		CreatedBy:     createdBy,
+	}
+	if err := s.db.Create(log).Error; err != nil {
+		return nil, fmt.Errorf("failed to log SMS: %w", err)
+	}
+
+	if s.queue == nil {
+		return log, s.deliver(ctx, log.ID, true)
+	}
+
+	payload, err := json.Marshal(sendSMSPayload{SMSLogID: log.ID})
+	if err != nil {
+		return nil, err
+	}
+	task := asynq.NewTask(TypeSendSMS, payload,
+		asynq.Queue(smsQueue),
+		asynq.MaxRetry(smsSendRetries),
+		asynq.Timeout(2*time.Minute))
+	if _, err := s.queue.EnqueueContext(ctx, task); err != nil {
+		config.Logger.Warn("Failed to queue SMS, sending it straight away",
+			zap.String("smsLogID", log.ID.String()),
+			zap.String("to", to),
+			zap.Error(err))
+		return log, s.deliver(ctx, log.ID, true)
+	}
+
+	return log, nil
+}
+
+// HandleSendSMSTask is the queue worker for TypeSendSMS
+func (s *SMSService) HandleSendSMSTask(ctx context.Context, task *asynq.Task) error {
+	var payload sendSMSPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("invalid SMS task payload: %v: %w", err, asynq.SkipRetry)
+	}
+
+	retried, _ := asynq.GetRetryCount(ctx)
+	maxRetry, _ := asynq.GetMaxRetry(ctx)
+	return s.deliver(ctx, payload.SMSLogID, retried >= maxRetry)
+}
+
+// deliver offers a queued message to each provider in turn and stops at the first that accepts
+// it. Messages already sent are skipped, so a task that runs twice sends once. The message is
+// marked FAILED only when every provider refused it on the last attempt.
+func (s *SMSService) deliver(ctx context.Context, smsLogID uuid.UUID, lastAttempt bool) error {
+	var log models.SMSLog
+	if err := s.db.Where("id = ?", smsLogID).First(&log).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("SMS log %s not found: %w", smsLogID, asynq.SkipRetry)
+		}
+		return fmt.Errorf("failed to load SMS log: %w", err)
+	}
+	if log.Status != models.SMSStatusQueued {
+		return nil
+	}
+
+	tried := []string{}
+	failures := []string{}
+	var accepted Provider
+	var providerMessageID string
+	for _, provider := range s.providers {
+		tried = append(tried, provider.Name())
+		id, err := provider.Send(ctx, &Message{
+			ID:          log.ID,
+			To:          log.Recipient,
+			Body:        log.Message,
+			CallbackURL: s.callbackURL(provider.Name()),
+		})
+		if err == nil {
+			accepted, providerMessageID = provider, id
+			break
+		}
+		failures = append(failures, fmt.Sprintf("%s: %v", provider.Name(), err))
+		config.Logger.Warn("SMS provider failed, trying the next one",
+			zap.String("smsLogID", log.ID.String()),
+			zap.String("provider", provider.Name()),
+			zap.Error(err))
+	}
+
+	updates := map[string]interface{}{
+		"attempts":        gorm.Expr("attempts + 1"),
+		"providers_tried": strings.Join(tried, ","),
+		"updated_at":      time.Now(),
+	}
+	var sendErr error
+	if accepted == nil {
+		sendErr = errors.New(strings.Join(failures, "; "))
+		errMessage := sendErr.Error()
+		updates["error"] = &errMessage
+		if lastAttempt {
+			updates["status"] = models.SMSStatusFailed
+			updates["failed_at"] = time.Now()
+		}
+	} else {
+		name := accepted.Name()
+		updates["status"] = models.SMSStatusSent
+		updates["sent_at"] = time.Now()
+		updates["provider"] = &name
+		updates["provider_message_id"] = &providerMessageID
+		updates["error"] = nil
+	}
+	if err := s.db.Model(&models.SMSLog{}).Where("id = ?", log.ID).Updates(updates).Error; err != nil {
+		config.Logger.Error("Failed to update SMS log",
+			zap.String("smsLogID", log.ID.String()),
+			zap.Error(err))
+	}
+
+	if sendErr != nil {
+		config.Logger.Error("Failed to send SMS",
+			zap.String("smsLogID", log.ID.String()),
+			zap.Strings("providers", tried),
+			zap.String("to", log.Recipient),
+			zap.Bool("last_attempt", lastAttempt),
+			zap.Error(sendErr))
+		return sendErr
+	}
+
+	config.Logger.Info("SMS sent successfully",
+		zap.String("smsLogID", log.ID.String()),
+		zap.String("provider", accepted.Name()),
+		zap.String("to", log.Recipient))
+	return nil
+}
+
+// callbackURL is the status callback for a provider, e.g. /sms/webhooks/twilio?token=...
+func (s *SMSService) callbackURL(provider string) string {
+	if s.webhookToken == "" || s.baseURL == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/sms/webhooks/%s?token=%s", s.baseURL, provider, url.QueryEscape(s.webhookToken))
+}
+
+// VerifyTwilioSignature checks a Twilio status callback's X-Twilio-Signature. Twilio signs the
+// callback URL it was given, so the URL is rebuilt from the public backend URL and the request
+// URI. Callbacks are refused while Twilio is not one of the providers.
+func (s *SMSService) VerifyTwilioSignature(requestURI string, form url.Values, signature string) bool {
+	for _, provider := range s.providers {
+		if twilio, ok := provider.(*TwilioProvider); ok {
+			return twilio.ValidSignature(s.baseURL+requestURI, form, signature)
+		}
+	}
+	return false
+}
+
+// StartSMSWorker runs the queue worker delivering text messages. SMS_WORKER_CONCURRENCY sets
+// how many are sent at once (default 5).
+func StartSMSWorker(redisOpt asynq.RedisConnOpt, service *SMSService) (*asynq.Server, error) {
+	server := asynq.NewServer(redisOpt, asynq.Config{
+		Concurrency: smsWorkerConcurrency(),
+		Queues:      map[string]int{smsQueue: 1},
+	})
+
+	mux := asynq.NewServeMux()
+	mux.HandleFunc(TypeSendSMS, service.HandleSendSMSTask)
+	if err := server.Start(mux); err != nil {
+		return nil, fmt.Errorf("failed to start SMS worker: %w", err)
+	}
+	return server, nil
+}
+
+func smsWorkerConcurrency() int {
+	raw := os.Getenv("SMS_WORKER_CONCURRENCY")
+	if raw == "" {
+		return defaultSMSConcurrency
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value <= 0 {
+		config.Logger.Warn("Invalid setting, using default",
+			zap.String("variable", "SMS_WORKER_CONCURRENCY"),
+			zap.String("value", raw),
+			zap.Int("default", defaultSMSConcurrency))
+		return defaultSMSConcurrency
+	}
+	return value
+}
+
+// GetApplicationMessages lists the text messages sent about an application, newest first, with
+// their delivery status
+func (s *SMSService) GetApplicationMessages(applicationID uuid.UUID) ([]models.SMSLog, error) {
+	var logs []models.SMSLog
+	if err := s.db.
+		Where("application_id = ?", applicationID).
+		Order("created_at DESC").
+		Find(&logs).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch application text messages: %w", err)
+	}
+	return logs, nil
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const twilioAPIURL = "https://api.twilio.com/2010-04-01/Accounts/%s/Messages.json"
+
+// TwilioProvider sends through Twilio's Programmable Messaging API
+type TwilioProvider struct {
+	accountSID string
+	authToken  string
+	from       string
+	client     *http.Client
+}
+
+func NewTwilioProvider(accountSID string, authToken string, from string) (*TwilioProvider, error) {
+	if accountSID == "" || authToken == "" {
+		return nil, errors.New("TWILIO_ACCOUNT_SID and TWILIO_AUTH_TOKEN must be set")
+	}
+	if from == "" {
+		return nil, errors.New("TWILIO_FROM is not set")
+	}
+	return &TwilioProvider{
+		accountSID: accountSID,
+		authToken:  authToken,
+		from:       from,
+		client:     &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (p *TwilioProvider) Name() string {
+	return "twilio"
+}
+
+type twilioResponse struct {
+	SID     string `json:"sid"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (p *TwilioProvider) Send(ctx context.Context, message *Message) (string, error) {
+	form := url.Values{}
+	form.Set("To", message.To)
+	form.Set("Body", message.Body)
+	// A messaging service lets Twilio pick the sender for the destination network
+	if strings.HasPrefix(p.from, "MG") {
+		form.Set("MessagingServiceSid", p.from)
+	} else {
+		form.Set("From", p.from)
+	}
+	if message.CallbackURL != "" {
+		form.Set("StatusCallback", message.CallbackURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf(twilioAPIURL, p.accountSID), strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(p.accountSID, p.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to reach Twilio: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	var result twilioResponse
+	_ = json.Unmarshal(body, &result)
+
+	if resp.StatusCode >= 300 {
+		if result.Message != "" {
+			return "", fmt.Errorf("Twilio refused the message (%d, code %d): %s", resp.StatusCode, result.Code, result.Message)
+		}
+		return "", fmt.Errorf("Twilio refused the message (%d): %s", resp.StatusCode, string(body))
+	}
+	if result.SID == "" {
+		return "", errors.New("Twilio accepted the message without returning its SID")
+	}
+	return result.SID, nil
+}
+
+// ValidSignature checks the X-Twilio-Signature of a callback Twilio made to callbackURL: an
+// HMAC-SHA1, keyed with the auth token, of the URL followed by every form field name and value
+// in name order
+func (p *TwilioProvider) ValidSignature(callbackURL string, form url.Values, signature string) bool {
+	if signature == "" {
+		return false
+	}
+
+	names := make([]string, 0, len(form))
+	for name := range form {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var signed strings.Builder
+	signed.WriteString(callbackURL)
+	for _, name := range names {
+		values := append([]string(nil), form[name]...)
+		sort.Strings(values)
+		for _, value := range values {
+			signed.WriteString(name)
+			signed.WriteString(value)
+		}
+	}
+
+	mac := hmac.New(sha1.New, []byte(p.authToken))
+	mac.Write([]byte(signed.String()))
+	expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
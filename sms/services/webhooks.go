@@ -0,0 +1,129 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+)
+
+// Delivery outcomes reported by a provider's status callback
+const (
+	StatusEventSent        = "sent"
+	StatusEventDelivered   = "delivered"
+	StatusEventUndelivered = "undelivered"
+)
+
+// StatusEvent is one delivery report for one message. ProviderStatus is kept as the provider
+// words it, for the communication log.
+type StatusEvent struct {
+	Kind           string
+	ProviderStatus string
+	ErrorCode      string
+	At             time.Time
+}
+
+// RecordStatusEvent applies a status callback to the message's log. The message is found by our
+// log ID when the provider echoes it back, otherwise by the provider's message ID. Delivery
+// reports are final: a late "sent" does not undo them.
+func (s *SMSService) RecordStatusEvent(provider string, smsLogID *uuid.UUID, providerMessageID string, event StatusEvent) error {
+	query := s.db.Model(&models.SMSLog{}).Where("provider = ?", provider)
+	switch {
+	case smsLogID != nil:
+		query = query.Where("id = ?", *smsLogID)
+	case providerMessageID != "":
+		query = query.Where("provider_message_id = ?", providerMessageID)
+	default:
+		return errors.New("status callback does not identify a message")
+	}
+
+	if event.At.IsZero() {
+		event.At = time.Now()
+	}
+	updates := map[string]interface{}{"updated_at": time.Now()}
+	if event.ProviderStatus != "" {
+		updates["provider_status"] = &event.ProviderStatus
+	}
+	if event.ErrorCode != "" {
+		updates["provider_error_code"] = &event.ErrorCode
+	}
+
+	switch event.Kind {
+	case StatusEventSent:
+		query = query.Where("status IN ?", []string{models.SMSStatusQueued, models.SMSStatusSent})
+	case StatusEventDelivered:
+		query = query.Where("status IN ?", []string{models.SMSStatusQueued, models.SMSStatusSent})
+		updates["status"] = models.SMSStatusDelivered
+		updates["delivered_at"] = event.At
+	case StatusEventUndelivered:
+		query = query.Where("status <> ?", models.SMSStatusDelivered)
+		updates["status"] = models.SMSStatusUndelivered
+		updates["failed_at"] = event.At
+	default:
+		return fmt.Errorf("unknown SMS status event %q", event.Kind)
+	}
+
+	return query.Updates(updates).Error
+}
+
+// HandleTwilioStatus applies a Twilio status callback, which is posted as a form. Statuses
+// before "sent" are ignored.
+func (s *SMSService) HandleTwilioStatus(messageSID string, messageStatus string, errorCode string) error {
+	event := StatusEvent{ProviderStatus: messageStatus, ErrorCode: errorCode}
+	switch messageStatus {
+	case "sent":
+		event.Kind = StatusEventSent
+	case "delivered", "read":
+		event.Kind = StatusEventDelivered
+	case "undelivered", "failed":
+		event.Kind = StatusEventUndelivered
+	default:
+		return nil
+	}
+	if messageSID == "" {
+		return errors.New("status callback has no MessageSid")
+	}
+	return s.RecordStatusEvent("twilio", nil, messageSID, event)
+}
+
+// aggregatorStatus is the local aggregator's delivery report
+type aggregatorStatus struct {
+	MessageID string `json:"message_id"`
+	Reference string `json:"reference"` // Our SMS log ID
+	Status    string `json:"status"`    // e.g. DELIVERED, UNDELIVERED, FAILED, EXPIRED, REJECTED
+	ErrorCode string `json:"error_code"`
+	Timestamp string `json:"timestamp"` // RFC3339
+}
+
+// HandleAggregatorStatus applies a delivery report posted by the local aggregator
+func (s *SMSService) HandleAggregatorStatus(body []byte) error {
+	var report aggregatorStatus
+	if err := json.Unmarshal(body, &report); err != nil {
+		return fmt.Errorf("invalid aggregator status payload: %w", err)
+	}
+
+	event := StatusEvent{ProviderStatus: report.Status, ErrorCode: report.ErrorCode}
+	switch strings.ToUpper(report.Status) {
+	case "SENT", "SUBMITTED":
+		event.Kind = StatusEventSent
+	case "DELIVERED", "DELIVRD":
+		event.Kind = StatusEventDelivered
+	case "UNDELIVERED", "UNDELIV", "FAILED", "EXPIRED", "REJECTED":
+		event.Kind = StatusEventUndelivered
+	default:
+		return nil
+	}
+	if at, err := time.Parse(time.RFC3339, report.Timestamp); err == nil {
+		event.At = at
+	}
+
+	var smsLogID *uuid.UUID
+	if id, err := uuid.Parse(report.Reference); err == nil {
+		smsLogID = &id
+	}
+	return s.RecordStatusEvent("aggregator", smsLogID, report.MessageID, event)
+}
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"errors"
+	"town-planning-backend/config"
+	"town-planning-backend/staging/services"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// AnonymizeConfirmation must be sent as confirm so a stray request cannot rewrite the data
+const AnonymizeConfirmation = "ANONYMIZE"
+
+type AnonymizeController struct {
+	AnonymizeService *services.AnonymizeService
+}
+
+// AnonymizeRequest confirms an anonymization
+type AnonymizeRequest struct {
+	Confirm string `json:"confirm"`
+}
+
+// AnonymizeDataController replaces the personal data in this environment's database with
+// pseudonyms and placeholder documents, e.g. after a production backup was restored into it
+func (ac *AnonymizeController) AnonymizeDataController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	var request AnonymizeRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+	if request.Confirm != AnonymizeConfirmation {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Anonymization not confirmed",
+			"error":   `send {"confirm": "` + AnonymizeConfirmation + `"} to anonymize ` + ac.AnonymizeService.Environment(),
+		})
+	}
+
+	result, err := ac.AnonymizeService.AnonymizeInPlace(c.UserContext(), payload.UserID.String())
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		switch {
+		case errors.Is(err, services.ErrAnonymizeInProduction):
+			status = fiber.StatusForbidden
+		case errors.Is(err, services.ErrAnonymizeInProgress):
+			status = fiber.StatusConflict
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to anonymize data",
+			"error":   err.Error(),
+		})
+	}
+
+	config.Logger.Warn("Data anonymized by user",
+		zap.String("userID", payload.UserID.String()),
+		zap.Int("tables", len(result.Tables)))
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Data anonymized",
+		"data":    result,
+	})
+}
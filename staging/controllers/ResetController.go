@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"errors"
+	"town-planning-backend/config"
+	"town-planning-backend/staging/services"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// ResetConfirmation must be sent as confirm so a stray request cannot wipe the environment
+const ResetConfirmation = "RESET"
+
+type ResetController struct {
+	ResetService *services.ResetService
+}
+
+// ResetRequest confirms a staging reset
+type ResetRequest struct {
+	Confirm string `json:"confirm"`
+}
+
+// ResetStagingDataController empties the transactional tables, reruns the seeds, clears Redis
+// and rebuilds the search indexes so QA can rerun end-to-end scenarios from a known dataset
+func (rc *ResetController) ResetStagingDataController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	var request ResetRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+	if request.Confirm != ResetConfirmation {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Reset not confirmed",
+			"error":   `send {"confirm": "` + ResetConfirmation + `"} to reset ` + rc.ResetService.Environment(),
+		})
+	}
+
+	result, err := rc.ResetService.Reset(c.UserContext(), payload.UserID.String())
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		switch {
+		case errors.Is(err, services.ErrResetInProduction):
+			status = fiber.StatusForbidden
+		case errors.Is(err, services.ErrResetInProgress):
+			status = fiber.StatusConflict
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to reset staging data",
+			"error":   err.Error(),
+			"data":    result,
+		})
+	}
+
+	config.Logger.Warn("Staging data reset by user",
+		zap.String("userID", payload.UserID.String()),
+		zap.Int("tables", len(result.TruncatedTables)))
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Staging data reset",
+		"data":    result,
+	})
+}
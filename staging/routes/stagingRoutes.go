@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"town-planning-backend/config"
+	"town-planning-backend/middleware"
+	"town-planning-backend/staging/controllers"
+	"town-planning-backend/staging/services"
+	user_repository "town-planning-backend/users/repositories"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// StagingRouterInit registers the QA and developer tooling. Nothing is registered unless APP_ENV
+// names a staging environment and STAGING_RESET_ENABLED is true.
+func StagingRouterInit(
+	app *fiber.App,
+	resetService *services.ResetService,
+	anonymizeService *services.AnonymizeService,
+	userRepo user_repository.UserRepository,
+) {
+	if !resetService.Enabled() {
+		config.Logger.Info("Staging endpoints disabled", zap.String("environment", resetService.Environment()))
+		return
+	}
+
+	resetController := &controllers.ResetController{
+		ResetService: resetService,
+	}
+
+	anonymizeController := &controllers.AnonymizeController{
+		AnonymizeService: anonymizeService,
+	}
+
+	stagingRoutes := app.Group("/api/v1/staging", middleware.RequirePermission(userRepo, "settings.manage"))
+	stagingRoutes.Post("/reset", resetController.ResetStagingDataController)
+	stagingRoutes.Post("/anonymize", anonymizeController.AnonymizeDataController)
+}
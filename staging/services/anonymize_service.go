@@ -0,0 +1,513 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	applicants_repositories "town-planning-backend/applicants/repositories"
+	bleveRepositories "town-planning-backend/bleve/repositories"
+	"town-planning-backend/config"
+	"town-planning-backend/internal/bootstrap"
+	stands_repositories "town-planning-backend/stands/repositories"
+	users_repositories "town-planning-backend/users/repositories"
+	"town-planning-backend/utils"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+var (
+	// ErrAnonymizeInProduction is returned when anonymization would overwrite data outside a
+	// staging environment
+	ErrAnonymizeInProduction = errors.New("anonymization is disabled in this environment")
+	// ErrAnonymizeInProgress is returned while another anonymization is still running
+	ErrAnonymizeInProgress = errors.New("an anonymization is already in progress")
+)
+
+// PIIKind says how a column's values are anonymized
+type PIIKind string
+
+const (
+	PIIName     PIIKind = "NAME"
+	PIIEmail    PIIKind = "EMAIL"
+	PIIPhone    PIIKind = "PHONE"
+	PIIContact  PIIKind = "CONTACT" // An email address or a phone number, e.g. a notification recipient
+	PIIIDNumber PIIKind = "ID_NUMBER"
+	PIIAddress  PIIKind = "ADDRESS"
+	PIIActor    PIIKind = "ACTOR"     // Audit columns holding the acting user's email, or "system"
+	PIIFileName PIIKind = "FILE_NAME" // Uploaded file names often carry the applicant's name
+	PIIFilePath PIIKind = "FILE_PATH" // Pointed at the placeholder document
+	PIIText     PIIKind = "TEXT"      // Messages written by or sent to applicants
+	PIIClear    PIIKind = "CLEAR"     // Secrets, device details and personal images
+)
+
+// piiColumns classifies columns by name, in every migrated table, so that columns added by new
+// models are anonymized without being listed here table by table. Only string columns are
+// rewritten.
+var piiColumns = map[string]PIIKind{
+	"first_name":                PIIName,
+	"middle_name":               PIIName,
+	"last_name":                 PIIName,
+	"full_name":                 PIIName,
+	"organisation_name":         PIIName,
+	"architect_full_name":       PIIName,
+	"sender_name":               PIIName,
+	"user_name":                 PIIName,
+	"surveyor_name":             PIIName,
+	"email":                     PIIEmail,
+	"architect_email":           PIIEmail,
+	"phone":                     PIIPhone,
+	"phone_number":              PIIPhone,
+	"whatsapp_number":           PIIPhone,
+	"architect_phone_number":    PIIPhone,
+	"recipient":                 PIIContact,
+	"id_number":                 PIIIDNumber,
+	"tax_identification_number": PIIIDNumber,
+	"employee_number":           PIIIDNumber,
+	"postal_address":            PIIAddress,
+	"residential_address":       PIIAddress,
+	"created_by":                PIIActor,
+	"updated_by":                PIIActor,
+	"added_by":                  PIIActor,
+	"assigned_by":               PIIActor,
+	"archived_by":               PIIActor,
+	"attempted_by":              PIIActor,
+	"changed_by":                PIIActor,
+	"collected_by":              PIIActor,
+	"no_show_marked_by":         PIIActor,
+	"removed_by":                PIIActor,
+	"resolved_by":               PIIActor,
+	"revoked_by":                PIIActor,
+	"file_name":                 PIIFileName,
+	"old_file_name":             PIIFileName,
+	"new_file_name":             PIIFileName,
+	"file_path":                 PIIFilePath,
+	"original_path":             PIIFilePath,
+	"archive_path":              PIIFilePath,
+	"attachment_path":           PIIFilePath,
+	"content":                   PIIText,
+	"message":                   PIIText,
+	"html_message":              PIIText,
+	"password":                  PIIClear,
+	"totp_secret":               PIIClear,
+	"ip_address":                PIIClear,
+	"user_agent":                PIIClear,
+	"device_id":                 PIIClear,
+	"profile_picture_url":       PIIClear,
+	"signature_file_path":       PIIClear,
+}
+
+// structuredJSONColumns are the JSON columns known to hold only codes, figures and settings,
+// which are copied as they are. Every other JSON column, e.g. draft form data, audit snapshots,
+// import reports and chat event parameters, may carry personal data and is cleared.
+var structuredJSONColumns = map[string]bool{
+	"geojson":              true,
+	"figures":              true,
+	"checks":               true,
+	"bounce_reasons":       true,
+	"factors":              true,
+	"filters":              true,
+	"parameters":           true,
+	"items":                true,
+	"changed_fields":       true,
+	"verification_reasons": true,
+}
+
+// placeholderFileName is the document every anonymized file path points at
+const placeholderFileName = "anonymized-placeholder.pdf"
+
+// placeholderPDF is a one page PDF reading "Document removed by anonymization"
+var placeholderPDF = []byte(`%PDF-1.4
+1 0 obj << /Type /Catalog /Pages 2 0 R >> endobj
+2 0 obj << /Type /Pages /Kids [3 0 R] /Count 1 >> endobj
+3 0 obj << /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >> endobj
+4 0 obj << /Length 68 >> stream
+BT /F1 18 Tf 72 770 Td (Document removed by anonymization) Tj ET
+endstream endobj
+5 0 obj << /Type /Font /Subtype /Type1 /BaseFont /Helvetica >> endobj
+trailer << /Root 1 0 R >>
+%%EOF
+`)
+
+// copyBatchSize is how many rows a clone inserts at a time
+const copyBatchSize = 500
+
+// AnonymizedTable lists the columns anonymized in a table
+type AnonymizedTable struct {
+	Table   string             `json:"table"`
+	Columns map[string]PIIKind `json:"columns"`
+	Rows    int64              `json:"rows"`
+}
+
+// AnonymizeResult describes what an anonymization did. JSONColumns lists the JSON columns copied
+// as they are; the others are cleared and reported with their table.
+type AnonymizeResult struct {
+	Tables           []AnonymizedTable `json:"tables"`
+	RowsCopied       map[string]int64  `json:"rows_copied,omitempty"`
+	PlaceholderPath  string            `json:"placeholder_path"`
+	JSONColumns      []string          `json:"json_columns"`
+	RedisKeysCleared int               `json:"redis_keys_cleared"`
+	IndexError       *string           `json:"index_error,omitempty"`
+	DurationMS       int64             `json:"duration_ms"`
+}
+
+// AnonymizeService gives developers production-shaped data without applicant data: names,
+// emails, phone numbers, ID numbers and addresses are replaced with pseudonyms, messages are
+// redacted, secrets cleared and documents replaced with a placeholder. Pseudonyms are derived
+// from the original value with a key drawn for each run, so the same person gets the same
+// pseudonym in every table but the originals cannot be recovered from a copy.
+type AnonymizeService struct {
+	db            *gorm.DB
+	redisClient   *redis.Client
+	fileStorage   utils.FileStorage
+	userRepo      users_repositories.UserRepository
+	applicantRepo applicants_repositories.ApplicantRepository
+	standRepo     stands_repositories.StandRepository
+	bleveRepo     bleveRepositories.BleveRepositoryInterface
+	environment   string
+
+	mu sync.Mutex
+}
+
+// NewAnonymizeService creates the service. The Redis client and the search repositories may be
+// nil, as in the command line tool, in which case caches are left alone and the search indexes
+// are rebuilt by the API when it next starts.
+func NewAnonymizeService(
+	db *gorm.DB,
+	redisClient *redis.Client,
+	fileStorage utils.FileStorage,
+	userRepo users_repositories.UserRepository,
+	applicantRepo applicants_repositories.ApplicantRepository,
+	standRepo stands_repositories.StandRepository,
+	bleveRepo bleveRepositories.BleveRepositoryInterface,
+) *AnonymizeService {
+	return &AnonymizeService{
+		db:            db,
+		redisClient:   redisClient,
+		fileStorage:   fileStorage,
+		userRepo:      userRepo,
+		applicantRepo: applicantRepo,
+		standRepo:     standRepo,
+		bleveRepo:     bleveRepo,
+		environment:   os.Getenv("APP_ENV"),
+	}
+}
+
+// Enabled reports whether anonymization may write to this environment's database, which only
+// staging environments allow
+func (s *AnonymizeService) Enabled() bool {
+	return stagingEnvironments[s.environment]
+}
+
+// Environment is the APP_ENV the service was started in
+func (s *AnonymizeService) Environment() string {
+	return s.environment
+}
+
+// CloneFrom replaces this environment's data with a copy of the source database, anonymized
+// before it is committed, so no personal data is ever visible here. The source is read in one
+// read-only snapshot. Foreign keys are not checked while tables are loaded, which needs a
+// database owner or superuser on this side.
+func (s *AnonymizeService) CloneFrom(ctx context.Context, source *gorm.DB, requestedBy string) (*AnonymizeResult, error) {
+	if !s.Enabled() {
+		return nil, ErrAnonymizeInProduction
+	}
+	if !s.mu.TryLock() {
+		return nil, ErrAnonymizeInProgress
+	}
+	defer s.mu.Unlock()
+
+	started := time.Now()
+	config.Logger.Warn("Cloning anonymized data",
+		zap.String("environment", s.environment),
+		zap.String("requestedBy", requestedBy))
+
+	tables, err := migratedTables(s.db, nil)
+	if err != nil {
+		return nil, err
+	}
+	placeholder, err := s.uploadPlaceholder()
+	if err != nil {
+		return nil, err
+	}
+
+	var result *AnonymizeResult
+	err = source.WithContext(ctx).Transaction(func(snapshot *gorm.DB) error {
+		if err := snapshot.Exec("SET TRANSACTION ISOLATION LEVEL REPEATABLE READ READ ONLY").Error; err != nil {
+			return fmt.Errorf("failed to open source snapshot: %w", err)
+		}
+
+		return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec("SET LOCAL session_replication_role = replica").Error; err != nil {
+				return fmt.Errorf("failed to defer foreign key checks: %w", err)
+			}
+
+			quoted := make([]string, len(tables))
+			for i, table := range tables {
+				quoted[i] = tx.Statement.Quote(table)
+			}
+			if err := tx.Exec("TRUNCATE TABLE " + strings.Join(quoted, ", ") + " RESTART IDENTITY").Error; err != nil {
+				return fmt.Errorf("failed to truncate tables: %w", err)
+			}
+
+			copied := make(map[string]int64, len(tables))
+			for _, table := range tables {
+				if !snapshot.Migrator().HasTable(table) {
+					continue
+				}
+				rows, err := copyTable(snapshot, tx, table)
+				if err != nil {
+					return err
+				}
+				copied[table] = rows
+			}
+
+			result, err = anonymize(tx, placeholder)
+			if err != nil {
+				return err
+			}
+			result.RowsCopied = copied
+			return nil
+		})
+	})
+	if err != nil {
+		config.Logger.Error("Anonymized clone failed", zap.Error(err))
+		return nil, err
+	}
+
+	s.refresh(ctx, result)
+	result.DurationMS = time.Since(started).Milliseconds()
+	config.Logger.Warn("Anonymized data cloned",
+		zap.Int("tables", len(result.RowsCopied)),
+		zap.Int64("durationMS", result.DurationMS))
+	return result, nil
+}
+
+// AnonymizeInPlace anonymizes this environment's own data, e.g. after a production backup was
+// restored into it
+func (s *AnonymizeService) AnonymizeInPlace(ctx context.Context, requestedBy string) (*AnonymizeResult, error) {
+	if !s.Enabled() {
+		return nil, ErrAnonymizeInProduction
+	}
+	if !s.mu.TryLock() {
+		return nil, ErrAnonymizeInProgress
+	}
+	defer s.mu.Unlock()
+
+	started := time.Now()
+	config.Logger.Warn("Anonymizing data in place",
+		zap.String("environment", s.environment),
+		zap.String("requestedBy", requestedBy))
+
+	placeholder, err := s.uploadPlaceholder()
+	if err != nil {
+		return nil, err
+	}
+
+	var result *AnonymizeResult
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result, err = anonymize(tx, placeholder)
+		return err
+	})
+	if err != nil {
+		config.Logger.Error("Anonymization failed", zap.Error(err))
+		return nil, err
+	}
+
+	s.refresh(ctx, result)
+	result.DurationMS = time.Since(started).Milliseconds()
+	config.Logger.Warn("Data anonymized",
+		zap.Int("tables", len(result.Tables)),
+		zap.Int64("durationMS", result.DurationMS))
+	return result, nil
+}
+
+// refresh drops cached copies of the original data and rebuilds the search indexes. Failures
+// are reported in the result since the database is already anonymized by then.
+func (s *AnonymizeService) refresh(ctx context.Context, result *AnonymizeResult) {
+	cleared, err := clearRedis(ctx, s.redisClient)
+	result.RedisKeysCleared = cleared
+	if err != nil {
+		config.Logger.Warn("Failed to clear Redis after anonymization", zap.Error(err))
+	}
+
+	if s.bleveRepo == nil {
+		return
+	}
+	if err := bootstrap.ReindexBleveData(ctx, s.userRepo, s.applicantRepo, s.standRepo, s.bleveRepo); err != nil {
+		message := err.Error()
+		result.IndexError = &message
+	}
+}
+
+func (s *AnonymizeService) uploadPlaceholder() (string, error) {
+	path, err := s.fileStorage.UploadFileFromReader(bytes.NewReader(placeholderPDF), placeholderFileName)
+	if err != nil {
+		return "", fmt.Errorf("failed to store placeholder document: %w", err)
+	}
+	return path, nil
+}
+
+// copyTable copies every row of a table from the source snapshot
+func copyTable(source *gorm.DB, tx *gorm.DB, table string) (int64, error) {
+	rows, err := source.Table(table).Rows()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var copied int64
+	batch := make([]map[string]interface{}, 0, copyBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := tx.Table(table).Create(&batch).Error; err != nil {
+			return fmt.Errorf("failed to copy %s: %w", table, err)
+		}
+		copied += int64(len(batch))
+		batch = make([]map[string]interface{}, 0, copyBatchSize)
+		return nil
+	}
+
+	for rows.Next() {
+		row := map[string]interface{}{}
+		if err := source.ScanRows(rows, &row); err != nil {
+			return copied, fmt.Errorf("failed to read %s: %w", table, err)
+		}
+		batch = append(batch, row)
+		if len(batch) == copyBatchSize {
+			if err := flush(); err != nil {
+				return copied, err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return copied, fmt.Errorf("failed to read %s: %w", table, err)
+	}
+	return copied, flush()
+}
+
+// anonymize rewrites the personal columns of every migrated table in one update per table
+func anonymize(tx *gorm.DB, placeholder string) (*AnonymizeResult, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate pseudonym key: %w", err)
+	}
+	args := map[string]interface{}{
+		"key":         hex.EncodeToString(key),
+		"placeholder": placeholder,
+	}
+
+	result := &AnonymizeResult{
+		Tables:          []AnonymizedTable{},
+		PlaceholderPath: placeholder,
+		JSONColumns:     []string{},
+	}
+	seen := map[string]bool{}
+	for _, model := range config.MigratedModels() {
+		stmt := &gorm.Statement{DB: tx}
+		if err := stmt.Parse(model); err != nil {
+			return nil, fmt.Errorf("failed to read model schema: %w", err)
+		}
+		table := stmt.Schema.Table
+		if seen[table] {
+			continue
+		}
+		seen[table] = true
+
+		anonymized := AnonymizedTable{Table: table, Columns: map[string]PIIKind{}}
+		var assignments []string
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" {
+				continue
+			}
+			if field.DataType == "json" || field.DataType == "jsonb" {
+				if structuredJSONColumns[field.DBName] {
+					result.JSONColumns = append(result.JSONColumns, table+"."+field.DBName)
+					continue
+				}
+				cleared := "'{}'"
+				if !field.NotNull {
+					cleared = "NULL"
+				}
+				assignments = append(assignments, tx.Statement.Quote(field.DBName)+" = "+cleared)
+				anonymized.Columns[field.DBName] = PIIClear
+				continue
+			}
+			kind, ok := piiColumns[field.DBName]
+			if !ok || field.DataType != schema.String {
+				continue
+			}
+			column := tx.Statement.Quote(field.DBName)
+			assignments = append(assignments, column+" = "+pseudonymExpression(kind, column, field.FieldType.Kind() == reflect.Ptr))
+			anonymized.Columns[field.DBName] = kind
+		}
+		if len(assignments) == 0 {
+			continue
+		}
+
+		update := tx.Exec("UPDATE "+tx.Statement.Quote(table)+" SET "+strings.Join(assignments, ", "), args)
+		if update.Error != nil {
+			return nil, fmt.Errorf("failed to anonymize %s: %w", table, update.Error)
+		}
+		anonymized.Rows = update.RowsAffected
+		result.Tables = append(result.Tables, anonymized)
+	}
+	sort.Strings(result.JSONColumns)
+	return result, nil
+}
+
+// pseudonymExpression is the SQL replacing a column's value. Empty values are kept so that
+// optional fields stay optional.
+func pseudonymExpression(kind PIIKind, column string, nullable bool) string {
+	hash := func(salt, value string) string {
+		return "md5(@key || ':" + salt + ":' || " + value + ")"
+	}
+	normalized := "lower(btrim(" + column + "))"
+	email := "'user-' || left(" + hash("email", normalized) + ", 12) || '@example.invalid'"
+	phone := "'+2637' || translate(left(" + hash("phone", "regexp_replace("+column+", '\\D', '', 'g')") + ", 8), 'abcdef', '012345')"
+
+	var expression string
+	switch kind {
+	case PIIClear:
+		if nullable {
+			return "NULL"
+		}
+		return "''"
+	case PIIFilePath:
+		expression = "@placeholder"
+	case PIIText:
+		expression = "'[redacted]'"
+	case PIIName:
+		expression = "'Anon ' || upper(left(" + hash("name", normalized) + ", 8))"
+	case PIIEmail:
+		expression = email
+	case PIIPhone:
+		expression = phone
+	case PIIContact:
+		expression = "CASE WHEN " + column + " LIKE '%@%' THEN " + email + " ELSE " + phone + " END"
+	case PIIActor:
+		expression = "CASE WHEN " + column + " LIKE '%@%' THEN " + email + " ELSE " + column + " END"
+	case PIIIDNumber:
+		expression = "'00-' || translate(left(" + hash("id", normalized) + ", 7), 'abcdef', '012345') || 'X00'"
+	case PIIAddress:
+		expression = "translate(left(" + hash("address", normalized) + ", 3), 'abcdef', '012345') || ' Placeholder Road'"
+	case PIIFileName:
+		expression = "'document-' || left(" + hash("file", normalized) + ", 10) || coalesce(substring(" + column + " from '\\.[A-Za-z0-9]{1,8}$'), '')"
+	default:
+		return column
+	}
+	return "CASE WHEN " + column + " IS NULL OR " + column + " = '' THEN " + column + " ELSE " + expression + " END"
+}
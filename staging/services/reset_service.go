@@ -0,0 +1,267 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	applicants_repositories "town-planning-backend/applicants/repositories"
+	bleveRepositories "town-planning-backend/bleve/repositories"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/internal/bootstrap"
+	"town-planning-backend/seeds"
+	stands_repositories "town-planning-backend/stands/repositories"
+	users_repositories "town-planning-backend/users/repositories"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrResetInProduction is returned when a reset is attempted outside an enabled staging
+	// environment
+	ErrResetInProduction = errors.New("staging reset is disabled in this environment")
+	// ErrResetInProgress is returned while another reset is still running
+	ErrResetInProgress = errors.New("a staging reset is already in progress")
+)
+
+// stagingEnvironments are the APP_ENVs the staging tooling may run in. Any other value, including
+// an unset or mistyped APP_ENV, is treated as production.
+var stagingEnvironments = map[string]bool{
+	"staging":     true,
+	"development": true,
+	"test":        true,
+}
+
+// asynqKeyPrefix marks the job queue's keys, which are left alone so the workers keep running
+const asynqKeyPrefix = "asynq:"
+
+// preservedModels are kept by a reset: staff accounts, access control and the configuration an
+// administrator sets up once per environment. Every other migrated table holds data created
+// while using the system and is emptied.
+var preservedModels = []interface{}{
+	&models.Permission{},
+	&models.Role{},
+	&models.RolePermission{},
+	&models.Department{},
+	&models.User{},
+	&models.DocumentCategory{},
+	&models.DevelopmentCategory{},
+	&models.StandType{},
+	&models.Tariff{},
+	&models.VATRate{},
+	&models.Bank{},
+	&models.BankAccount{},
+	&models.ExchangeRate{},
+	&models.FileNamingPolicy{},
+	&models.CollectionCalendar{},
+	&models.BoundaryLayer{},
+	&models.ApprovalGroup{},
+	&models.ApprovalGroupMember{},
+	&models.ReviewChecklistItem{},
+	&models.RiskScoringProfile{},
+	&models.Setting{},
+	&models.Suburb{},
+}
+
+// ResetResult describes what a staging reset did
+type ResetResult struct {
+	TruncatedTables  []string `json:"truncated_tables"`
+	SeedsCreated     int      `json:"seeds_created"`
+	SeedsUpdated     int      `json:"seeds_updated"`
+	SeedsUnchanged   int      `json:"seeds_unchanged"`
+	RedisKeysCleared int      `json:"redis_keys_cleared"`
+	IndexError       *string  `json:"index_error,omitempty"`
+	DurationMS       int64    `json:"duration_ms"`
+}
+
+// ResetService puts a staging environment back to a known dataset so end-to-end scenarios can
+// be rerun from the same starting point
+type ResetService struct {
+	db            *gorm.DB
+	redisClient   *redis.Client
+	userRepo      users_repositories.UserRepository
+	applicantRepo applicants_repositories.ApplicantRepository
+	standRepo     stands_repositories.StandRepository
+	bleveRepo     bleveRepositories.BleveRepositoryInterface
+	environment   string
+
+	mu sync.Mutex
+}
+
+func NewResetService(
+	db *gorm.DB,
+	redisClient *redis.Client,
+	userRepo users_repositories.UserRepository,
+	applicantRepo applicants_repositories.ApplicantRepository,
+	standRepo stands_repositories.StandRepository,
+	bleveRepo bleveRepositories.BleveRepositoryInterface,
+) *ResetService {
+	return &ResetService{
+		db:            db,
+		redisClient:   redisClient,
+		userRepo:      userRepo,
+		applicantRepo: applicantRepo,
+		standRepo:     standRepo,
+		bleveRepo:     bleveRepo,
+		environment:   os.Getenv("APP_ENV"),
+	}
+}
+
+// Enabled reports whether resets are allowed in this environment: APP_ENV must name a staging
+// environment and STAGING_RESET_ENABLED must be true
+func (s *ResetService) Enabled() bool {
+	return stagingEnvironments[s.environment] && os.Getenv("STAGING_RESET_ENABLED") == "true"
+}
+
+// Environment is the APP_ENV the service was started in
+func (s *ResetService) Environment() string {
+	return s.environment
+}
+
+// Reset empties the transactional tables and reruns the role, user and demo seeds, overwriting
+// seeded records, in one transaction. Once committed it clears Redis apart from the job queue
+// and rebuilds the Bleve indexes. A failed reindex is reported in the result rather than failing
+// the reset, since the database is already reset by then.
+func (s *ResetService) Reset(ctx context.Context, requestedBy string) (*ResetResult, error) {
+	if !s.Enabled() {
+		return nil, ErrResetInProduction
+	}
+	if !s.mu.TryLock() {
+		return nil, ErrResetInProgress
+	}
+	defer s.mu.Unlock()
+
+	started := time.Now()
+	config.Logger.Warn("Resetting staging data",
+		zap.String("environment", s.environment),
+		zap.String("requestedBy", requestedBy))
+
+	tables, err := s.transactionalTables()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := seeds.NewOptions()
+	opts.Roles = true
+	opts.Users = true
+	opts.Demo = true
+	opts.Overwrite = true
+	report := &seeds.Report{}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		quoted := make([]string, len(tables))
+		for i, table := range tables {
+			quoted[i] = tx.Statement.Quote(table)
+		}
+		// No CASCADE: a preserved table pointing at an emptied one fails the reset instead of
+		// being emptied along with it
+		if err := tx.Exec("TRUNCATE TABLE " + strings.Join(quoted, ", ") + " RESTART IDENTITY").Error; err != nil {
+			return fmt.Errorf("failed to truncate tables: %w", err)
+		}
+		return seeds.SeedTownPlanningAll(tx, opts, report)
+	})
+	if err != nil {
+		config.Logger.Error("Staging reset failed", zap.Error(err))
+		return nil, err
+	}
+
+	result := &ResetResult{
+		TruncatedTables: tables,
+		SeedsCreated:    report.Count(seeds.ActionCreated),
+		SeedsUpdated:    report.Count(seeds.ActionUpdated),
+		SeedsUnchanged:  report.Count(seeds.ActionUnchanged),
+	}
+
+	cleared, err := clearRedis(ctx, s.redisClient)
+	result.RedisKeysCleared = cleared
+	if err != nil {
+		return result, err
+	}
+
+	if err := bootstrap.ReindexBleveData(ctx, s.userRepo, s.applicantRepo, s.standRepo, s.bleveRepo); err != nil {
+		message := err.Error()
+		result.IndexError = &message
+	}
+
+	result.DurationMS = time.Since(started).Milliseconds()
+	config.Logger.Warn("Staging data reset",
+		zap.Int("tables", len(tables)),
+		zap.Int("redisKeys", cleared),
+		zap.Bool("reindexed", result.IndexError == nil),
+		zap.Int64("durationMS", result.DurationMS))
+	return result, nil
+}
+
+// transactionalTables lists every migrated table that is not preserved, with the join tables of
+// their many-to-many relations
+func (s *ResetService) transactionalTables() ([]string, error) {
+	preserved := make(map[string]bool, len(preservedModels))
+	for _, model := range preservedModels {
+		stmt := &gorm.Statement{DB: s.db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, fmt.Errorf("failed to read model schema: %w", err)
+		}
+		preserved[stmt.Schema.Table] = true
+	}
+
+	return migratedTables(s.db, preserved)
+}
+
+// migratedTables lists the migrated tables, with the join tables of their many-to-many
+// relations, in migration order, leaving out the skipped ones
+func migratedTables(db *gorm.DB, skip map[string]bool) ([]string, error) {
+	seen := map[string]bool{}
+	var tables []string
+	add := func(table string) {
+		if !skip[table] && !seen[table] {
+			seen[table] = true
+			tables = append(tables, table)
+		}
+	}
+	for _, model := range config.MigratedModels() {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, fmt.Errorf("failed to read model schema: %w", err)
+		}
+		if skip[stmt.Schema.Table] {
+			continue
+		}
+		add(stmt.Schema.Table)
+		for _, relationship := range stmt.Schema.Relationships.Relations {
+			if relationship.JoinTable != nil {
+				add(relationship.JoinTable.Table)
+			}
+		}
+	}
+	return tables, nil
+}
+
+// clearRedis deletes every key outside the job queue: cached repository reads, sign-in tokens,
+// rate limits and sessions
+func clearRedis(ctx context.Context, redisClient *redis.Client) (int, error) {
+	if redisClient == nil {
+		return 0, nil
+	}
+
+	var cleared int
+	iter := redisClient.Scan(ctx, 0, "*", 500).Iterator()
+	for iter.Next(ctx) {
+		if strings.HasPrefix(iter.Val(), asynqKeyPrefix) {
+			continue
+		}
+		if err := redisClient.Del(ctx, iter.Val()).Err(); err != nil {
+			return cleared, fmt.Errorf("failed to clear redis key %s: %w", iter.Val(), err)
+		}
+		cleared++
+	}
+	if err := iter.Err(); err != nil {
+		return cleared, fmt.Errorf("failed to scan redis keys: %w", err)
+	}
+	return cleared, nil
+}
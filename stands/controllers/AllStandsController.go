@@ -1,14 +1,18 @@
 package controllers
 
 import (
+	address_services "town-planning-backend/addresses/services"
 	indexing_repository "town-planning-backend/bleve/repositories"
 	"town-planning-backend/stands/repositories"
+	"town-planning-backend/utils"
 
 	"gorm.io/gorm"
 )
 
 type StandController struct {
-	StandRepo repositories.StandRepository
-	DB        *gorm.DB
-	BleveRepo indexing_repository.BleveRepositoryInterface
+	StandRepo      repositories.StandRepository
+	DB             *gorm.DB
+	BleveRepo      indexing_repository.BleveRepositoryInterface
+	FileStorage    utils.FileStorage
+	AddressService *address_services.AddressService
 }
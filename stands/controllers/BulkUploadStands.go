@@ -331,31 +331,17 @@ func (sc *StandController) BulkUploadStands(c *fiber.Ctx) error {
 			downloadLink = utils.GenerateDownloadLink(filePath)
 			message := "Please find the attached file with error records (missing fields and duplicates)."
 			subject := "Stand Upload Errors - " + time.Now().Format("2006-01-02 15:04:05")
+			// The email service records it in the email log
 			err = utils.SendEmail(userEmail, message, subject, "", downloadLink)
 			if err != nil {
 				log.Printf("Warning: Failed to send email with error report: %v", err) // Log but don't fail upload
-			} else {
-				active := true
-				emailLog := models.EmailLog{
-					ID:             uuid.New(),
-					Recipient:      userEmail,
-					Subject:        subject,
-					Message:        message,
-					SentAt:         utils.Today(),
-					Active:         &active,
-					AttachmentPath: downloadLink,
-				}
-				err = sc.StandRepo.LogEmailSent(&emailLog)
-				if err != nil {
-					fmt.Println("Warning: Failed to log email:", err) // Log but don't fail upload
-				}
 			}
 		}
 	}
 
 	// --- Start Database Transaction for valid stands ---
 	if len(validStands) > 0 {
-		tx := sc.DB.Begin()
+		tx := sc.DB.WithContext(c.UserContext()).Begin()
 		if tx.Error != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Failed to begin database transaction", "error": tx.Error.Error()})
 		}
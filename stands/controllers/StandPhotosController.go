@@ -0,0 +1,340 @@
+package controllers
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/token"
+	"town-planning-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+var allowedStandPhotoExtensions = map[string]string{
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".heic": "image/heic",
+	".webp": "image/webp",
+}
+
+// UploadStandPhotoController adds a site photo to a stand's gallery.
+// Multipart fields: file (required), caption, latitude, longitude, taken_at (RFC3339), is_primary.
+func (sc *StandController) UploadStandPhotoController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	standID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid stand ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	if _, err := sc.StandRepo.GetStandByID(standID); err != nil {
+		statusCode := fiber.StatusInternalServerError
+		if err.Error() == "stand not found" {
+			statusCode = fiber.StatusNotFound
+		}
+		return c.Status(statusCode).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to load stand",
+			"error":   err.Error(),
+		})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Photo file is required",
+			"error":   err.Error(),
+		})
+	}
+
+	fileExt := strings.ToLower(filepath.Ext(fileHeader.Filename))
+	mimeType, allowed := allowedStandPhotoExtensions[fileExt]
+	if !allowed {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Unsupported photo format",
+			"error":   fmt.Sprintf("file type %s is not allowed", fileExt),
+		})
+	}
+
+	photo := models.StandPhoto{
+		ID:        uuid.New(),
+		StandID:   standID,
+		MimeType:  mimeType,
+		FileSize:  fileHeader.Size,
+		Source:    models.StandPhotoSourceUpload,
+		CreatedBy: payload.UserID.String(),
+	}
+
+	if caption := strings.TrimSpace(c.FormValue("caption")); caption != "" {
+		photo.Caption = &caption
+	}
+
+	for field, target := range map[string]**decimal.Decimal{
+		"latitude":  &photo.Latitude,
+		"longitude": &photo.Longitude,
+	} {
+		raw := strings.TrimSpace(c.FormValue(field))
+		if raw == "" {
+			continue
+		}
+		value, err := decimal.NewFromString(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": fmt.Sprintf("Invalid %s", field),
+				"error":   err.Error(),
+			})
+		}
+		*target = &value
+	}
+	if photo.Latitude != nil && (photo.Latitude.LessThan(decimal.NewFromInt(-90)) || photo.Latitude.GreaterThan(decimal.NewFromInt(90))) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Latitude must be between -90 and 90",
+		})
+	}
+	if photo.Longitude != nil && (photo.Longitude.LessThan(decimal.NewFromInt(-180)) || photo.Longitude.GreaterThan(decimal.NewFromInt(180))) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Longitude must be between -180 and 180",
+		})
+	}
+
+	if raw := strings.TrimSpace(c.FormValue("taken_at")); raw != "" {
+		takenAt, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": "Invalid taken_at, expected RFC3339",
+				"error":   err.Error(),
+			})
+		}
+		photo.TakenAt = &takenAt
+	}
+
+	if raw := c.FormValue("is_primary"); raw != "" {
+		isPrimary, err := strconv.ParseBool(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": "Invalid is_primary value",
+				"error":   err.Error(),
+			})
+		}
+		photo.IsPrimary = isPrimary
+	}
+
+	// The phone's EXIF block is dropped; the capture time is kept when taken_at was not sent
+	data, scrubbed, err := utils.ReadUploadedImage(fileHeader)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to read photo file",
+			"error":   err.Error(),
+		})
+	}
+	photo.FileSize = int64(len(data))
+	if scrubbed != nil {
+		scrubbedAt := time.Now()
+		photo.MimeType = scrubbed.MimeType
+		photo.MetadataScrubbedAt = &scrubbedAt
+		if photo.TakenAt == nil {
+			photo.TakenAt = scrubbed.CapturedAt
+		}
+	}
+
+	filePath, err := sc.FileStorage.UploadFileFromReader(bytes.NewReader(data), filepath.Join("stands", standID.String(), "photos", photo.ID.String()+fileExt))
+	if err != nil {
+		config.Logger.Error("Failed to store stand photo",
+			zap.Error(err),
+			zap.String("standID", standID.String()))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to store photo",
+			"error":   err.Error(),
+		})
+	}
+	photo.FilePath = filePath
+
+	tx := sc.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		_ = sc.FileStorage.DeleteFile(filePath)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	createdPhoto, err := sc.StandRepo.CreateStandPhoto(tx, &photo)
+	if err != nil {
+		tx.Rollback()
+		_ = sc.FileStorage.DeleteFile(filePath)
+		config.Logger.Error("Failed to save stand photo",
+			zap.Error(err),
+			zap.String("standID", standID.String()))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to save photo",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		_ = sc.FileStorage.DeleteFile(filePath)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Photo uploaded successfully",
+		"data":    createdPhoto,
+	})
+}
+
+// GetStandPhotosController lists a stand's gallery in chronological order
+func (sc *StandController) GetStandPhotosController(c *fiber.Ctx) error {
+	standID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid stand ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	if _, err := sc.StandRepo.GetStandByID(standID); err != nil {
+		statusCode := fiber.StatusInternalServerError
+		if err.Error() == "stand not found" {
+			statusCode = fiber.StatusNotFound
+		}
+		return c.Status(statusCode).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to load stand",
+			"error":   err.Error(),
+		})
+	}
+
+	photos, err := sc.StandRepo.GetStandPhotos(standID)
+	if err != nil {
+		config.Logger.Error("Failed to fetch stand photos",
+			zap.Error(err),
+			zap.String("standID", standID.String()))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch photos",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Photos retrieved successfully",
+		"data":    photos,
+	})
+}
+
+// SetPrimaryStandPhotoController marks a gallery photo as the stand's primary photo
+func (sc *StandController) SetPrimaryStandPhotoController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "User not authenticated",
+		})
+	}
+
+	standID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid stand ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	photoID, err := uuid.Parse(c.Params("photoId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid photo ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	tx := sc.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Internal server error: Could not start database transaction",
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	photo, err := sc.StandRepo.SetPrimaryStandPhoto(tx, standID, photoID, payload.UserID.String())
+	if err != nil {
+		tx.Rollback()
+		statusCode := fiber.StatusInternalServerError
+		if err.Error() == "photo not found" {
+			statusCode = fiber.StatusNotFound
+		}
+		return c.Status(statusCode).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to set primary photo",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to commit transaction",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Primary photo updated successfully",
+		"data":    photo,
+	})
+}
@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"strings"
+	"town-planning-backend/config"
+	"town-planning-backend/stands/repositories"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// GetStandTimelineController returns the full history of a stand in chronological order:
+// ownership, reservations, applications, inspections, disputes and document uploads.
+// Optional query: types (comma-separated event types) to narrow the timeline.
+func (sc *StandController) GetStandTimelineController(c *fiber.Ctx) error {
+	standID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid stand ID",
+			"error":   "invalid_uuid",
+		})
+	}
+
+	stand, err := sc.StandRepo.GetStandByID(standID)
+	if err != nil {
+		statusCode := fiber.StatusInternalServerError
+		if err.Error() == "stand not found" {
+			statusCode = fiber.StatusNotFound
+		}
+		return c.Status(statusCode).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to load stand",
+			"error":   err.Error(),
+		})
+	}
+
+	events, err := sc.StandRepo.GetStandTimeline(standID)
+	if err != nil {
+		config.Logger.Error("Failed to build stand timeline",
+			zap.Error(err),
+			zap.String("standID", standID.String()))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to fetch stand timeline",
+			"error":   err.Error(),
+		})
+	}
+
+	if raw := c.Query("types"); raw != "" {
+		wanted := map[repositories.StandTimelineEventType]bool{}
+		for _, eventType := range strings.Split(raw, ",") {
+			wanted[repositories.StandTimelineEventType(strings.ToUpper(strings.TrimSpace(eventType)))] = true
+		}
+		filtered := make([]repositories.StandTimelineEvent, 0, len(events))
+		for _, event := range events {
+			if wanted[event.Type] {
+				filtered = append(filtered, event)
+			}
+		}
+		events = filtered
+	}
+
+	disputes := 0
+	for _, event := range events {
+		if event.IsDispute {
+			disputes++
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Stand timeline retrieved successfully",
+		"data": fiber.Map{
+			"stand_id":     stand.ID,
+			"stand_number": stand.StandNumber,
+			"events":       events,
+			"total_count":  len(events),
+			"disputes":     disputes,
+		},
+	})
+}
@@ -28,7 +28,7 @@ func (sc *StandController) AddStandTypesController(c *fiber.Ctx) error {
 	}
 
 	// --- Start Database Transaction ---
-	tx := sc.DB.Begin()
+	tx := sc.DB.WithContext(c.UserContext()).Begin()
 	if tx.Error != nil {
 		config.Logger.Error("Failed to begin database transaction", zap.Error(tx.Error))
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -1,10 +1,12 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"time"
 
+	address_services "town-planning-backend/addresses/services"
 	"town-planning-backend/config"
 	"town-planning-backend/db/models"
 	"town-planning-backend/stands/services"
@@ -129,6 +131,29 @@ func (sc *StandController) BulkUploadProjects(c *fiber.Ctx) error {
 			continue // Skip to next row, this is an allowed error
 		}
 
+		// Rows whose address is not recognised are reported like any other invalid row
+		standardized, err := sc.AddressService.Standardize(project.Address, project.City, true)
+		if err != nil {
+			if !errors.Is(err, address_services.ErrAddressNotRecognised) {
+				config.Logger.Error("Failed to standardize project address", zap.Error(err))
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Failed to standardize project addresses"})
+			}
+			invalidRows = append(invalidRows, models.BulkUploadErrorProjects{
+				ID:            uuid.New(),
+				ProjectNumber: project.ProjectNumber,
+				ProjectName:   project.ProjectName,
+				Address:       project.Address,
+				City:          project.City,
+				CreatedBy:     userEmail,
+				Reason:        err.Error(),
+				ErrorType:     models.InvalidAddressErrorType,
+				AddedVia:      models.BulkAddedViaType,
+			})
+			continue
+		}
+		project.Address = standardized.Address
+		project.City = standardized.City
+
 		// Add the valid project to the list
 		projectsToProcess = append(projectsToProcess, project)
 		projectNumbersInFile = append(projectNumbersInFile, project.ProjectNumber)
@@ -202,32 +227,17 @@ func (sc *StandController) BulkUploadProjects(c *fiber.Ctx) error {
 			message := "Please find the attached file with error records (missing fields and duplicates)."
 			subject := "Project Upload Errors - " + time.Now().Format("2006-01-02 15:04:05")
 
-			// Send the email
+			// Send the email; the email service records it in the email log
 			err := utils.SendEmail(userEmail, message, subject, "", *downloadLink)
 			if err != nil {
 				config.Logger.Error("Warning: Failed to send email with project error report", zap.Error(err))
-			} else {
-				active := true
-				// Log the email sent to the database
-				emailLog := models.EmailLog{
-					ID:             uuid.New(),
-					Recipient:      userEmail,
-					Subject:        subject,
-					Message:        message,
-					SentAt:         utils.Today(),
-					Active:         &active,
-					AttachmentPath: *downloadLink,
-				}
-				if err := sc.StandRepo.LogEmailSent(&emailLog); err != nil {
-					config.Logger.Error("Warning: Failed to log email for project errors", zap.Error(err))
-				}
 			}
 		}
 	}
 
 	// --- Start Database Transaction for valid projects ---
 	if len(filteredProjects) > 0 {
-		tx := sc.DB.Begin()
+		tx := sc.DB.WithContext(c.UserContext()).Begin()
 		if tx.Error != nil {
 			config.Logger.Error("Failed to begin database transaction for projects", zap.Error(tx.Error))
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Failed to begin database transaction", "error": tx.Error.Error()})
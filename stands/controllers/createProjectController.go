@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"errors"
+	address_services "town-planning-backend/addresses/services"
 	"town-planning-backend/config"
 	"town-planning-backend/db/models"
 	"town-planning-backend/stands/services"
@@ -26,6 +28,23 @@ func (sc *StandController) CreateProject(c *fiber.Ctx) error {
 		})
 	}
 
+	// Projects are on the ground, so their address must name a suburb
+	standardized, err := sc.AddressService.Standardize(project.Address, project.City, true)
+	if err != nil {
+		if errors.Is(err, address_services.ErrAddressNotRecognised) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"message": "Validation failed",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(500).JSON(fiber.Map{
+			"message": "Failed to standardize address",
+			"error":   err.Error(),
+		})
+	}
+	project.Address = standardized.Address
+	project.City = standardized.City
+
 	// Check for duplicate project number
 	existingProject, _ := sc.StandRepo.GetProjectByProjectNumber(project.ProjectNumber)
 
@@ -60,7 +79,8 @@ func (sc *StandController) CreateProject(c *fiber.Ctx) error {
 	}
 
 	return c.Status(201).JSON(fiber.Map{
-		"message": "Project created successfully",
-		"data":    createdProject,
+		"message":  "Project created successfully",
+		"data":     createdProject,
+		"warnings": standardized.Warnings,
 	})
 }
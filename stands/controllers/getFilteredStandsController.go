@@ -3,6 +3,7 @@ package controllers
 import (
 	"town-planning-backend/config"
 	"town-planning-backend/stands/services"
+	"town-planning-backend/utils/pagination"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
@@ -14,15 +15,10 @@ var DefaultPaymentFilters = map[string]bool{
 }
 
 func (sc *StandController) GetFilteredStandsController(c *fiber.Ctx) error {
-	// Parse query parameters
-	pageSize := c.QueryInt("page_size", 5)
-	if pageSize <= 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid page_size parameter"})
-	}
-
-	page := c.QueryInt("page", 1)
-	if page <= 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid page parameter"})
+	// Parse page, limit (or the uncapped page_size older clients send) and cursor
+	page, err := pagination.ParseLegacyRequest(c, 5)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
 
 	// Clean up and sanitize the query parameters
@@ -47,9 +43,6 @@ func (sc *StandController) GetFilteredStandsController(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing user_email parameter"})
 	}
 
-	// Calculate offset for pagination
-	offset := (page - 1) * pageSize
-
 	// Construct the filters map based on query parameters
 	filters := make(map[string]string)
 	if status != "" {
@@ -72,7 +65,7 @@ func (sc *StandController) GetFilteredStandsController(c *fiber.Ctx) error {
 	delete(filters, "user_email")
 
 	// Fetch paginated results based on filters
-	paginatedPayments, total, err := sc.StandRepo.GetFilteredStands(filters, true, pageSize, offset)
+	paginatedPayments, total, err := sc.StandRepo.GetFilteredStands(c.UserContext(), filters, &page)
 	if err != nil {
 		config.Logger.Error("Failed to fetch filtered stands", zap.Error(err))
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch filtered stands"})
@@ -80,17 +73,12 @@ func (sc *StandController) GetFilteredStandsController(c *fiber.Ctx) error {
 
 	// config.Logger.Info("Total", zap.Int64("Total", total))
 
-	// Calculate total pages for pagination
-	totalPages := (total + int64(pageSize) - 1) / int64(pageSize)
-	response := fiber.Map{
-		"data": paginatedPayments,
-		"meta": fiber.Map{
-			"current_page": page,
-			"page_size":    pageSize,
-			"total":        total,
-			"total_pages":  totalPages,
-		},
+	var next *pagination.Cursor
+	if len(paginatedPayments) > 0 {
+		last := paginatedPayments[len(paginatedPayments)-1]
+		next = page.NextCursor(len(paginatedPayments), last.CreatedAt, last.ID)
 	}
+	response := pagination.NewEnvelope(c, page, paginatedPayments, total, next).Map()
 
 	// Log filter values for debugging
 	isDefault := services.IsDefaultStandsFilter(filters, DefaultPaymentFilters)
@@ -99,7 +87,7 @@ func (sc *StandController) GetFilteredStandsController(c *fiber.Ctx) error {
 	// config.Logger.Info("UserEmail", zap.String("email", userEmail)) // Log the user email
 
 	// Fetch all results if filters are non-default and pageSize > 1
-	if !isDefault && pageSize > 1 {
+	if !isDefault && page.Limit > 1 {
 		allResults, totalAll, isBackground, err := sc.StandRepo.GetFilteredAllStandsResults(filters, userEmail)
 		if err != nil {
 			config.Logger.Error("Failed to fetch all filtered stands", zap.Error(err))
@@ -113,7 +101,7 @@ func (sc *StandController) GetFilteredStandsController(c *fiber.Ctx) error {
 		}
 
 		// Include all results in the response
-		totalPages := (totalAll + int64(pageSize) - 1) / int64(pageSize)
+		totalPages := (totalAll + int64(page.Limit) - 1) / int64(page.Limit)
 		// Include all results in the response
 		response["all_results"] = allResults
 		response["all_results_meta"] = fiber.Map{"total": totalAll}
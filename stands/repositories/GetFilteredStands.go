@@ -1,10 +1,12 @@
 package repositories
 
 import (
+	"context"
 	"time"
 	"town-planning-backend/config"
 	"town-planning-backend/db/models"
 	"town-planning-backend/stands/services"
+	"town-planning-backend/utils/pagination"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -63,13 +65,16 @@ func (pqb *standsQueryBuilder) Offset(offset int) *standsQueryBuilder {
 	return pqb
 }
 
-// GetFilteredStands returns filtered stands with pagination
-func (r *standRepository) GetFilteredStands(filters map[string]string, paginationEnabled bool, limit, offset int) ([]models.Stand, int64, error) {
-	pqb := newStandsQueryBuilder(r.db, filters).applyBasicStandsFilters().applyStandsDateRangeFilter().applyLatestOrder()
-	pqb2 := newStandsQueryBuilder(r.db, filters).applyBasicStandsFilters().applyStandsDateRangeFilter()
+// GetFilteredStands returns filtered stands, one page at a time unless page is nil
+func (r *standRepository) GetFilteredStands(ctx context.Context, filters map[string]string, page *pagination.Request) ([]models.Stand, int64, error) {
+	db := r.db.WithContext(ctx)
+	pqb := newStandsQueryBuilder(db, filters).applyBasicStandsFilters().applyStandsDateRangeFilter()
+	pqb2 := newStandsQueryBuilder(db, filters).applyBasicStandsFilters().applyStandsDateRangeFilter()
 
-	if paginationEnabled {
-		pqb = pqb.Limit(limit).Offset(offset)
+	if page != nil {
+		pqb.query = page.Window(pqb.query, "stands", "GREATEST(created_at, updated_at) DESC, created_at DESC")
+	} else {
+		pqb = pqb.applyLatestOrder()
 	}
 
 	var stands []models.Stand
@@ -89,7 +94,7 @@ func (r *standRepository) GetFilteredStands(filters map[string]string, paginatio
 func (r *standRepository) GetFilteredAllStandsResults(filters map[string]string, userEmail string) ([]models.Stand, int64, bool, error) {
 	startTime := time.Now()
 
-	stands, total, err := r.GetFilteredStands(filters, false, 0, 0)
+	stands, total, err := r.GetFilteredStands(context.Background(), filters, nil)
 	if err != nil {
 		return nil, 0, false, err
 	}
@@ -122,7 +127,7 @@ func (r *standRepository) processStandsBackgroundTask(filters map[string]string,
 
 // BackgroundStandsTaskFunction handles background execution for stand reports
 func (r *standRepository) BackgroundStandsTaskFunction(filters map[string]string) ([]interface{}, error) {
-	stands, _, err := r.GetFilteredStands(filters, false, 0, 0)
+	stands, _, err := r.GetFilteredStands(context.Background(), filters, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GetStandByID loads a single stand
+func (r *standRepository) GetStandByID(standID uuid.UUID) (*models.Stand, error) {
+	var stand models.Stand
+	if err := r.db.Where("id = ?", standID).First(&stand).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("stand not found")
+		}
+		return nil, err
+	}
+	return &stand, nil
+}
+
+// CreateStandPhoto adds a photo to the stand gallery. The first photo of a stand becomes its primary photo.
+func (r *standRepository) CreateStandPhoto(tx *gorm.DB, photo *models.StandPhoto) (*models.StandPhoto, error) {
+	if !photo.IsPrimary {
+		var primaryCount int64
+		if err := tx.Model(&models.StandPhoto{}).
+			Where("stand_id = ? AND is_primary = ?", photo.StandID, true).
+			Count(&primaryCount).Error; err != nil {
+			return nil, err
+		}
+		photo.IsPrimary = primaryCount == 0
+	} else if err := tx.Model(&models.StandPhoto{}).
+		Where("stand_id = ? AND is_primary = ?", photo.StandID, true).
+		Update("is_primary", false).Error; err != nil {
+		return nil, fmt.Errorf("failed to clear primary photo: %w", err)
+	}
+
+	if err := tx.Create(photo).Error; err != nil {
+		return nil, fmt.Errorf("failed to create stand photo: %w", err)
+	}
+	return photo, nil
+}
+
+// GetStandPhotos returns the gallery in the order the photos were taken, falling back to upload time
+func (r *standRepository) GetStandPhotos(standID uuid.UUID) ([]models.StandPhoto, error) {
+	var photos []models.StandPhoto
+	err := r.db.Where("stand_id = ?", standID).
+		Order("COALESCE(taken_at, created_at) ASC, created_at ASC").
+		Find(&photos).Error
+	return photos, err
+}
+
+// SetPrimaryStandPhoto makes the photo the stand's primary photo and clears the flag on the others
+func (r *standRepository) SetPrimaryStandPhoto(tx *gorm.DB, standID uuid.UUID, photoID uuid.UUID, updatedBy string) (*models.StandPhoto, error) {
+	var photo models.StandPhoto
+	if err := tx.Where("id = ? AND stand_id = ?", photoID, standID).First(&photo).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("photo not found")
+		}
+		return nil, err
+	}
+
+	if err := tx.Model(&models.StandPhoto{}).
+		Where("stand_id = ? AND id <> ? AND is_primary = ?", standID, photoID, true).
+		Updates(map[string]interface{}{"is_primary": false, "updated_by": updatedBy}).Error; err != nil {
+		return nil, fmt.Errorf("failed to clear primary photo: %w", err)
+	}
+
+	photo.IsPrimary = true
+	photo.UpdatedBy = &updatedBy
+	if err := tx.Save(&photo).Error; err != nil {
+		return nil, fmt.Errorf("failed to set primary photo: %w", err)
+	}
+	return &photo, nil
+}
@@ -1,11 +1,13 @@
 package repositories
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
 	"time"
 	"town-planning-backend/db/models"
+	"town-planning-backend/utils/pagination"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -28,11 +30,16 @@ type StandRepository interface {
 	GetStandTypeByName(name string) (*models.StandType, error) // Add this method
 	FindDuplicateStandNumbers(standNumbers []string) ([]string, error)
 	BulkCreateStands(tx *gorm.DB, stands []models.Stand) error
-	GetFilteredStands(filters map[string]string, paginationEnabled bool, limit, offset int) ([]models.Stand, int64, error)
+	GetFilteredStands(ctx context.Context, filters map[string]string, page *pagination.Request) ([]models.Stand, int64, error)
 	GetFilteredAllStandsResults(filters map[string]string, userEmail string) ([]models.Stand, int64, bool, error)
 	GetFilteredReservedStands(filters map[string]string, paginationEnabled bool, limit, offset int) ([]models.Reservation, int64, error)
 	GetFilteredAllFilteredReservedStandsResults(filters map[string]string, userEmail string) ([]models.Reservation, int64, bool, error)
 	GetAllStands() ([]models.Stand, error)
+	GetStandByID(standID uuid.UUID) (*models.Stand, error)
+	CreateStandPhoto(tx *gorm.DB, photo *models.StandPhoto) (*models.StandPhoto, error)
+	GetStandPhotos(standID uuid.UUID) ([]models.StandPhoto, error)
+	SetPrimaryStandPhoto(tx *gorm.DB, standID uuid.UUID, photoID uuid.UUID, updatedBy string) (*models.StandPhoto, error)
+	GetStandTimeline(standID uuid.UUID) ([]StandTimelineEvent, error)
 }
 
 type standRepository struct {
@@ -45,9 +52,13 @@ func NewStandRepository(db *gorm.DB) StandRepository {
 	}
 }
 
+// GetAllStands loads every stand for the search index, with only the archived_at of its
+// applications
 func (r *standRepository) GetAllStands() ([]models.Stand, error) {
 	var stands []models.Stand
-	err := r.db.Find(&stands).Error
+	err := r.db.Preload("Applications", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "stand_id", "archived_at")
+	}).Find(&stands).Error
 	return stands, err
 }
 
@@ -0,0 +1,353 @@
+package repositories
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+)
+
+type StandTimelineEventType string
+
+const (
+	StandEventOwnerRecorded        StandTimelineEventType = "OWNER_RECORDED"
+	StandEventReserved             StandTimelineEventType = "RESERVED"
+	StandEventApplicationSubmitted StandTimelineEventType = "APPLICATION_SUBMITTED"
+	StandEventApplicationApproved  StandTimelineEventType = "APPLICATION_APPROVED"
+	StandEventApplicationRejected  StandTimelineEventType = "APPLICATION_REJECTED"
+	StandEventOwnershipTransferred StandTimelineEventType = "OWNERSHIP_TRANSFERRED"
+	StandEventInspection           StandTimelineEventType = "INSPECTION"
+	StandEventPermitSuspended      StandTimelineEventType = "PERMIT_SUSPENDED"
+	StandEventPermitReinstated     StandTimelineEventType = "PERMIT_REINSTATED"
+	StandEventPermitRevoked        StandTimelineEventType = "PERMIT_REVOKED"
+	StandEventBoundaryFlagged      StandTimelineEventType = "BOUNDARY_FLAGGED"
+	StandEventDocumentUploaded     StandTimelineEventType = "DOCUMENT_UPLOADED"
+	StandEventDocumentsRequested   StandTimelineEventType = "DOCUMENTS_REQUESTED"
+)
+
+// standTimelineDisputeTypes are the events that record a dispute over the stand. There is no
+// separate dispute register: contested permits and flagged boundary checks are the disputes.
+var standTimelineDisputeTypes = map[StandTimelineEventType]bool{
+	StandEventPermitSuspended: true,
+	StandEventPermitRevoked:   true,
+	StandEventBoundaryFlagged: true,
+}
+
+// StandTimelineEvent is one entry in a stand's history. ReferenceID is the record the event
+// comes from; ApplicationID is set when the event belongs to an application on the stand.
+type StandTimelineEvent struct {
+	Type          StandTimelineEventType `json:"type"`
+	OccurredAt    time.Time              `json:"occurred_at"`
+	Description   string                 `json:"description"`
+	IsDispute     bool                   `json:"is_dispute"`
+	ReferenceID   uuid.UUID              `json:"reference_id"`
+	ApplicationID *uuid.UUID             `json:"application_id,omitempty"`
+	PlanNumber    *string                `json:"plan_number,omitempty"`
+	Actor         *string                `json:"actor,omitempty"`
+}
+
+// GetStandTimeline gathers everything recorded against a stand and its applications, oldest
+// first
+func (r *standRepository) GetStandTimeline(standID uuid.UUID) ([]StandTimelineEvent, error) {
+	events := []StandTimelineEvent{}
+	add := func(event StandTimelineEvent) {
+		event.IsDispute = standTimelineDisputeTypes[event.Type]
+		events = append(events, event)
+	}
+
+	var owners []models.AllStandOwners
+	if err := r.db.Preload("Applicant").Where("stand_id = ?", standID).Find(&owners).Error; err != nil {
+		return nil, fmt.Errorf("failed to load stand owners: %w", err)
+	}
+	for _, owner := range owners {
+		event := StandTimelineEvent{
+			Type:        StandEventOwnerRecorded,
+			OccurredAt:  owner.CreatedAt,
+			Description: "Owner recorded",
+			ReferenceID: owner.ID,
+			Actor:       standTimelineActor(owner.CreatedBy),
+		}
+		if owner.Applicant != nil {
+			event.Description = fmt.Sprintf("%s recorded as an owner", owner.Applicant.FullName)
+		}
+		add(event)
+	}
+
+	var reservations []models.Reservation
+	if err := r.db.Preload("Applicant").Where("stand_id = ?", standID).Find(&reservations).Error; err != nil {
+		return nil, fmt.Errorf("failed to load reservations: %w", err)
+	}
+	for _, reservation := range reservations {
+		event := StandTimelineEvent{
+			Type:        StandEventReserved,
+			OccurredAt:  reservation.ReservationDate,
+			Description: fmt.Sprintf("Stand reserved (%s)", reservation.Status),
+			ReferenceID: reservation.ID,
+			Actor:       standTimelineActor(reservation.CreatedBy),
+		}
+		if reservation.Applicant != nil {
+			event.Description = fmt.Sprintf("Stand reserved for %s (%s)", reservation.Applicant.FullName, reservation.Status)
+		}
+		add(event)
+	}
+
+	var applications []models.Application
+	if err := r.db.
+		Preload("Applicant").
+		Preload("CoApplicants.Applicant").
+		Where("stand_id = ?", standID).
+		Find(&applications).Error; err != nil {
+		return nil, fmt.Errorf("failed to load applications: %w", err)
+	}
+	applicationIDs := make([]uuid.UUID, 0, len(applications))
+	planNumbers := make(map[uuid.UUID]string, len(applications))
+	for _, application := range applications {
+		applicationIDs = append(applicationIDs, application.ID)
+		planNumbers[application.ID] = application.PlanNumber
+
+		applicationEvent := func(eventType StandTimelineEventType, at time.Time, description string) StandTimelineEvent {
+			return StandTimelineEvent{
+				Type:          eventType,
+				OccurredAt:    at,
+				Description:   description,
+				ReferenceID:   application.ID,
+				ApplicationID: &application.ID,
+				PlanNumber:    &application.PlanNumber,
+			}
+		}
+		submitted := applicationEvent(StandEventApplicationSubmitted, application.SubmissionDate,
+			fmt.Sprintf("Application %s submitted by %s", application.PlanNumber, application.ApplicantDisplayName()))
+		submitted.Actor = standTimelineActor(application.CreatedBy)
+		add(submitted)
+		if application.FinalApprovalDate != nil {
+			add(applicationEvent(StandEventApplicationApproved, *application.FinalApprovalDate,
+				fmt.Sprintf("Application %s approved", application.PlanNumber)))
+		}
+		if application.RejectionDate != nil {
+			add(applicationEvent(StandEventApplicationRejected, *application.RejectionDate,
+				fmt.Sprintf("Application %s rejected", application.PlanNumber)))
+		}
+	}
+
+	if len(applicationIDs) > 0 {
+		if err := r.addApplicationTimelineEvents(applicationIDs, planNumbers, add); err != nil {
+			return nil, err
+		}
+	}
+
+	var checks []models.BoundaryCheck
+	if err := r.db.Preload("ReviewedBy").
+		Where("stand_id = ? AND status NOT IN ?", standID, []models.BoundaryCheckStatus{models.BoundaryWithin, models.BoundaryNoCoordinates}).
+		Find(&checks).Error; err != nil {
+		return nil, fmt.Errorf("failed to load boundary checks: %w", err)
+	}
+	for _, check := range checks {
+		applicationID := check.ApplicationID
+		event := StandTimelineEvent{
+			Type:          StandEventBoundaryFlagged,
+			OccurredAt:    check.CheckedAt,
+			Description:   fmt.Sprintf("Boundary check flagged: %s", check.Status),
+			ReferenceID:   check.ID,
+			ApplicationID: &applicationID,
+			Actor:         standTimelineActor(check.CreatedBy),
+		}
+		if check.Message != nil {
+			event.Description = fmt.Sprintf("%s (%s)", event.Description, *check.Message)
+		}
+		if check.ReviewedBy != nil {
+			event.Description = fmt.Sprintf("%s, cleared by %s %s", event.Description, check.ReviewedBy.FirstName, check.ReviewedBy.LastName)
+		}
+		if planNumber, ok := planNumbers[applicationID]; ok {
+			event.PlanNumber = &planNumber
+		}
+		add(event)
+	}
+
+	var standDocuments []models.StandDocument
+	if err := r.db.Preload("Document.Category").Where("stand_id = ?", standID).Find(&standDocuments).Error; err != nil {
+		return nil, fmt.Errorf("failed to load stand documents: %w", err)
+	}
+	for _, standDocument := range standDocuments {
+		add(standTimelineDocumentEvent(standDocument.Document, nil, nil))
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].OccurredAt.Before(events[j].OccurredAt)
+	})
+	return events, nil
+}
+
+// addApplicationTimelineEvents adds the transfers, inspections, permit changes, documents and
+// requests for outstanding documents of the stand's applications
+func (r *standRepository) addApplicationTimelineEvents(applicationIDs []uuid.UUID, planNumbers map[uuid.UUID]string, add func(StandTimelineEvent)) error {
+	planNumber := func(applicationID uuid.UUID) *string {
+		number := planNumbers[applicationID]
+		return &number
+	}
+
+	var transfers []models.ApplicationTransfer
+	if err := r.db.
+		Preload("FromApplicant").
+		Preload("ToApplicant").
+		Preload("ReviewedBy").
+		Where("application_id IN ? AND status = ?", applicationIDs, models.TransferStatusApproved).
+		Find(&transfers).Error; err != nil {
+		return fmt.Errorf("failed to load ownership transfers: %w", err)
+	}
+	for _, transfer := range transfers {
+		applicationID := transfer.ApplicationID
+		event := StandTimelineEvent{
+			Type:          StandEventOwnershipTransferred,
+			OccurredAt:    transfer.CreatedAt,
+			Description:   "Ownership transferred",
+			ReferenceID:   transfer.ID,
+			ApplicationID: &applicationID,
+			PlanNumber:    planNumber(applicationID),
+		}
+		if transfer.ReviewedAt != nil {
+			event.OccurredAt = *transfer.ReviewedAt
+		}
+		if transfer.FromApplicant != nil && transfer.ToApplicant != nil {
+			event.Description = fmt.Sprintf("Ownership transferred from %s to %s", transfer.FromApplicant.FullName, transfer.ToApplicant.FullName)
+		}
+		if transfer.ReviewedBy != nil {
+			event.Actor = standTimelineActor(transfer.ReviewedBy.FirstName + " " + transfer.ReviewedBy.LastName)
+		}
+		add(event)
+	}
+
+	var inspections []models.Inspection
+	if err := r.db.Preload("Inspector").Where("application_id IN ?", applicationIDs).Find(&inspections).Error; err != nil {
+		return fmt.Errorf("failed to load inspections: %w", err)
+	}
+	for _, inspection := range inspections {
+		applicationID := inspection.ApplicationID
+		event := StandTimelineEvent{
+			Type:          StandEventInspection,
+			OccurredAt:    inspection.CreatedAt,
+			Description:   fmt.Sprintf("%s inspection %s", inspection.InspectionType, inspection.Status),
+			ReferenceID:   inspection.ID,
+			ApplicationID: &applicationID,
+			PlanNumber:    planNumber(applicationID),
+		}
+		switch {
+		case inspection.CompletedAt != nil:
+			event.OccurredAt = *inspection.CompletedAt
+		case inspection.StartedAt != nil:
+			event.OccurredAt = *inspection.StartedAt
+		case inspection.ScheduledDate != nil:
+			event.OccurredAt = *inspection.ScheduledDate
+		}
+		if inspection.Outcome != nil {
+			event.Description = fmt.Sprintf("%s: %s", event.Description, *inspection.Outcome)
+		}
+		if inspection.Inspector != nil {
+			event.Actor = standTimelineActor(inspection.Inspector.FirstName + " " + inspection.Inspector.LastName)
+		}
+		add(event)
+	}
+
+	var changes []models.PermitStatusChange
+	if err := r.db.
+		Preload("Permit").
+		Preload("ChangedBy").
+		Joins("JOIN permits ON permits.id = permit_status_changes.permit_id").
+		Where("permits.application_id IN ?", applicationIDs).
+		Find(&changes).Error; err != nil {
+		return fmt.Errorf("failed to load permit status changes: %w", err)
+	}
+	permitEventTypes := map[models.PermitAction]StandTimelineEventType{
+		models.PermitActionSuspend:   StandEventPermitSuspended,
+		models.PermitActionReinstate: StandEventPermitReinstated,
+		models.PermitActionRevoke:    StandEventPermitRevoked,
+	}
+	for _, change := range changes {
+		event := StandTimelineEvent{
+			Type:        permitEventTypes[change.Action],
+			OccurredAt:  change.ChangedAt,
+			Description: change.Reason,
+			ReferenceID: change.ID,
+		}
+		if change.Permit != nil {
+			applicationID := change.Permit.ApplicationID
+			event.ApplicationID = &applicationID
+			event.PlanNumber = planNumber(applicationID)
+			event.Description = fmt.Sprintf("Permit %s %s: %s", change.Permit.PermitNumber, change.ToStatus, change.Reason)
+		}
+		if change.ChangedBy != nil {
+			event.Actor = standTimelineActor(change.ChangedBy.FirstName + " " + change.ChangedBy.LastName)
+		}
+		add(event)
+	}
+
+	var applicationDocuments []models.ApplicationDocument
+	if err := r.db.
+		Preload("Document.Category").
+		Where("application_id IN ?", applicationIDs).
+		Find(&applicationDocuments).Error; err != nil {
+		return fmt.Errorf("failed to load application documents: %w", err)
+	}
+	for _, applicationDocument := range applicationDocuments {
+		applicationID := applicationDocument.ApplicationID
+		add(standTimelineDocumentEvent(applicationDocument.Document, &applicationID, planNumber(applicationID)))
+	}
+
+	var documentRequests []models.DocumentRequest
+	if err := r.db.Preload("RequestedBy").Where("application_id IN ?", applicationIDs).Find(&documentRequests).Error; err != nil {
+		return fmt.Errorf("failed to load document requests: %w", err)
+	}
+	for _, documentRequest := range documentRequests {
+		applicationID := documentRequest.ApplicationID
+		var items []struct {
+			Label string `json:"label"`
+		}
+		_ = json.Unmarshal(documentRequest.Items, &items)
+		labels := make([]string, 0, len(items))
+		for _, item := range items {
+			labels = append(labels, item.Label)
+		}
+		event := StandTimelineEvent{
+			Type:          StandEventDocumentsRequested,
+			OccurredAt:    documentRequest.CreatedAt,
+			Description:   fmt.Sprintf("Outstanding documents requested by %s: %s", strings.ToLower(string(documentRequest.Channel)), strings.Join(labels, ", ")),
+			ReferenceID:   documentRequest.ID,
+			ApplicationID: &applicationID,
+			PlanNumber:    planNumber(applicationID),
+		}
+		if documentRequest.RequestedBy != nil {
+			event.Actor = standTimelineActor(documentRequest.RequestedBy.FirstName + " " + documentRequest.RequestedBy.LastName)
+		}
+		add(event)
+	}
+	return nil
+}
+
+func standTimelineDocumentEvent(document models.Document, applicationID *uuid.UUID, planNumber *string) StandTimelineEvent {
+	description := document.FileName + " uploaded"
+	if document.Category != nil {
+		description = fmt.Sprintf("%s (%s)", description, document.Category.Name)
+	}
+	if document.Version > 1 {
+		description = fmt.Sprintf("%s, version %d", description, document.Version)
+	}
+	return StandTimelineEvent{
+		Type:          StandEventDocumentUploaded,
+		OccurredAt:    document.CreatedAt,
+		Description:   description,
+		ReferenceID:   document.ID,
+		ApplicationID: applicationID,
+		PlanNumber:    planNumber,
+		Actor:         standTimelineActor(document.CreatedBy),
+	}
+}
+
+func standTimelineActor(name string) *string {
+	if name == "" {
+		return nil
+	}
+	return &name
+}
@@ -1,9 +1,12 @@
 package routes
 
 import (
+	address_services "town-planning-backend/addresses/services"
 	indexing_repository "town-planning-backend/bleve/repositories"
+	"town-planning-backend/middleware"
 	"town-planning-backend/stands/controllers"
 	"town-planning-backend/stands/repositories"
+	"town-planning-backend/utils"
 
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
@@ -14,19 +17,31 @@ func StandRouterInit(
 	db *gorm.DB,
 	standRepository repositories.StandRepository,
 	bleveRepository indexing_repository.BleveRepositoryInterface,
+	fileStorage utils.FileStorage,
+	addressService *address_services.AddressService,
 ) {
 	standController := &controllers.StandController{
-		StandRepo: standRepository,
-		DB:        db,
-		BleveRepo: bleveRepository,
+		StandRepo:      standRepository,
+		DB:             db,
+		BleveRepo:      bleveRepository,
+		FileStorage:    fileStorage,
+		AddressService: addressService,
 	}
 
 	standRoutes := app.Group("/api/v1/stands")
 	standRoutes.Post("/stand-types", standController.AddStandTypesController)
-	standRoutes.Post("/bulk-upload-projects", standController.BulkUploadProjects)
-	standRoutes.Post("/bulk-upload-stands", standController.BulkUploadStands)
+	standRoutes.Post("/bulk-upload-projects", middleware.LongRunning(), middleware.Upload(), standController.BulkUploadProjects)
+	standRoutes.Post("/bulk-upload-stands", middleware.LongRunning(), middleware.Upload(), standController.BulkUploadStands)
 	standRoutes.Post("/create-project", standController.CreateProject)
 	standRoutes.Get("/stand-types/filtered", standController.GetFilteredStandTypesController)
 	standRoutes.Get("/projects/filtered", standController.GetFilteredProjectsController)
 	standRoutes.Get("/filtered", standController.GetFilteredStandsController)
+
+	// Site photo gallery
+	standRoutes.Post("/:id/photos", middleware.Upload(), standController.UploadStandPhotoController)
+	standRoutes.Get("/:id/photos", standController.GetStandPhotosController)
+	standRoutes.Patch("/:id/photos/:photoId/primary", standController.SetPrimaryStandPhotoController)
+
+	// Everything that happened on the stand, for planners reviewing an application
+	standRoutes.Get("/:id/timeline", standController.GetStandTimelineController)
 }
@@ -13,5 +13,6 @@ import (
 
 type Maker interface {
 	CreateToken(userID uuid.UUID, duration time.Duration) (string, error)
+	CreateApplicantToken(applicantID uuid.UUID, scopes []string, duration time.Duration) (string, error)
 	VerifyToken(token string) (*Payload, error)
 }
\ No newline at end of file
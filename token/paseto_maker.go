@@ -43,6 +43,21 @@ func (maker *PasetoMaker) CreateToken(userID uuid.UUID, duration time.Duration)
 	return token, nil
 }
 
+// CreateApplicantToken creates an applicant portal token limited to the given scopes
+func (maker *PasetoMaker) CreateApplicantToken(applicantID uuid.UUID, scopes []string, duration time.Duration) (string, error) {
+	payload, err := NewApplicantPayload(applicantID, scopes, duration)
+	if err != nil {
+		return "", fmt.Errorf("failed to create token payload: %w", err)
+	}
+
+	token, err := maker.paseto.Encrypt(maker.symmetricKey, payload, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt token: %w", err)
+	}
+
+	return token, nil
+}
+
 // VerifyToken checks if the token is valid and returns its payload
 func (maker *PasetoMaker) VerifyToken(token string) (*Payload, error) {
 	payload := &Payload{}
@@ -11,11 +11,33 @@ import (
 
 var ErrExpired = errors.New("token has expired")
 
+// Audiences keep staff and applicant identities apart. Staff tokens issued before audiences
+// were introduced have none and are treated as staff.
+const (
+	AudienceStaff     = "staff"
+	AudienceApplicant = "applicant"
+)
+
+// Scopes granted to applicant portal tokens
+const (
+	ScopeApplicationsRead = "applications:read" // The applicant's own applications
+	ScopeDocumentsUpload  = "documents:upload"  // Upload documents to their own applications
+	ScopeChatStaff        = "chat:staff"        // Message council staff about their applications
+)
+
+// ApplicantPortalScopes are the scopes an applicant signing in to the portal receives
+var ApplicantPortalScopes = []string{ScopeApplicationsRead, ScopeDocumentsUpload, ScopeChatStaff}
+
 type Payload struct {
 	ID        uuid.UUID `json:"id"`         // Token ID
 	UserID    uuid.UUID `json:"user_id"`    // User identifier
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiredAt time.Time `json:"expired_at"`
+
+	// Applicant portal tokens carry the applicant instead of a user, and the scopes granted
+	Audience    string    `json:"audience,omitempty"`
+	ApplicantID uuid.UUID `json:"applicant_id,omitempty"`
+	Scopes      []string  `json:"scopes,omitempty"`
 }
 
 func NewPayload(userID uuid.UUID, duration time.Duration) (*Payload, error) {
@@ -40,18 +62,70 @@ func NewPayload(userID uuid.UUID, duration time.Duration) (*Payload, error) {
 		UserID:    userID,
 		IssuedAt:  issuedAt,
 		ExpiredAt: expiredAt,
+		Audience:  AudienceStaff,
 	}
 	return payload, nil
 }
 
+// NewApplicantPayload creates the payload of an applicant portal token limited to the scopes
+func NewApplicantPayload(applicantID uuid.UUID, scopes []string, duration time.Duration) (*Payload, error) {
+	if applicantID == uuid.Nil {
+		return nil, errors.New("applicant ID cannot be empty")
+	}
+	if len(scopes) == 0 {
+		return nil, errors.New("applicant tokens need at least one scope")
+	}
+	if duration <= 0 {
+		return nil, errors.New("duration must be positive")
+	}
+
+	tokenID, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+
+	issuedAt := time.Now().In(utils.DateLocation)
+	return &Payload{
+		ID:          tokenID,
+		IssuedAt:    issuedAt,
+		ExpiredAt:   issuedAt.Add(duration),
+		Audience:    AudienceApplicant,
+		ApplicantID: applicantID,
+		Scopes:      scopes,
+	}, nil
+}
+
 func (payload *Payload) Valid() error {
 	// Compare against current time in the app's timezone
 	if time.Now().In(utils.DateLocation).After(payload.ExpiredAt) {
 		return ErrExpired
 	}
+	if payload.IsApplicant() && (payload.ApplicantID == uuid.Nil || payload.UserID != uuid.Nil) {
+		return errors.New("applicant token must identify only an applicant")
+	}
 	return nil
 }
 
+// IsStaff reports whether the token was issued to a staff user
+func (payload *Payload) IsStaff() bool {
+	return payload.Audience == "" || payload.Audience == AudienceStaff
+}
+
+// IsApplicant reports whether the token was issued to an applicant through the portal
+func (payload *Payload) IsApplicant() bool {
+	return payload.Audience == AudienceApplicant
+}
+
+// HasScope reports whether an applicant token was granted the scope
+func (payload *Payload) HasScope(scope string) bool {
+	for _, granted := range payload.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *Payload) String() string {
 	return fmt.Sprintf("ID: %s, UserID: %s, IssuedAt: %s, ExpiredAt: %s", 
 		p.ID, p.UserID, p.IssuedAt, p.ExpiredAt)
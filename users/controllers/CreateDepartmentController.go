@@ -12,13 +12,14 @@ import (
 
 // CreateDepartmentRequest represents the request body for creating a department
 type CreateDepartmentRequest struct {
-	Name           string  `json:"name" validate:"required,min=2,max=100"`
-	Description    *string `json:"description" validate:"max=500"`
-	IsActive       bool    `json:"is_active"`
-	Email          *string `json:"email" validate:"omitempty,email"`
-	PhoneNumber    *string `json:"phone_number" validate:"omitempty,e164"`
-	OfficeLocation *string `json:"office_location" validate:"omitempty,max=200"`
-	CreatedBy      string  `json:"created_by" validate:"required"`
+	Name           string     `json:"name" validate:"required,min=2,max=100"`
+	Description    *string    `json:"description" validate:"max=500"`
+	IsActive       bool       `json:"is_active"`
+	Email          *string    `json:"email" validate:"omitempty,email"`
+	PhoneNumber    *string    `json:"phone_number" validate:"omitempty,e164"`
+	OfficeLocation *string    `json:"office_location" validate:"omitempty,max=200"`
+	HeadUserID     *uuid.UUID `json:"head_user_id"`
+	CreatedBy      string     `json:"created_by" validate:"required"`
 }
 
 func (uc *UserController) CreateDepartmentController(c *fiber.Ctx) error {
@@ -34,7 +35,7 @@ func (uc *UserController) CreateDepartmentController(c *fiber.Ctx) error {
 	}
 
 	// --- Start Database Transaction ---
-	tx := uc.DB.Begin()
+	tx := uc.DB.WithContext(c.UserContext()).Begin()
 	if tx.Error != nil {
 		config.Logger.Error("Failed to begin database transaction", zap.Error(tx.Error))
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -61,6 +62,7 @@ func (uc *UserController) CreateDepartmentController(c *fiber.Ctx) error {
 		Email:          req.Email,
 		PhoneNumber:    req.PhoneNumber,
 		OfficeLocation: req.OfficeLocation,
+		HeadUserID:     req.HeadUserID,
 		CreatedBy:      req.CreatedBy,
 	}
 
@@ -35,7 +35,7 @@ func (uc *UserController) CreateRoleWithPermissionsController(c *fiber.Ctx) erro
 	}
 
 	// --- Start Database Transaction ---
-	tx := uc.DB.Begin()
+	tx := uc.DB.WithContext(c.UserContext()).Begin()
 	if tx.Error != nil {
 		config.Logger.Error("Failed to begin database transaction", zap.Error(tx.Error))
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
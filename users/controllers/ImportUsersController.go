@@ -0,0 +1,429 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+	"town-planning-backend/token"
+	"town-planning-backend/users/repositories"
+	"town-planning-backend/users/services"
+	"town-planning-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxHRExportSize caps the uploaded HR export
+	maxHRExportSize = 5 * 1024 * 1024
+
+	// userImportHistoryLimit is how many past imports the history lists
+	userImportHistoryLimit = 50
+)
+
+// ImportUsersController provisions staff from an HR export CSV. New staff are created with a
+// magic-link invitation, existing staff are updated, missing departments are created, and
+// HR-managed users absent from the export are deactivated. With dry_run=true nothing is changed
+// and the reconciliation report shows what the import would do.
+func (uc *UserController) ImportUsersController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "User not authenticated",
+			"data":    nil,
+			"error":   "unauthorized",
+		})
+	}
+
+	importer, err := uc.UserRepo.GetUserByID(payload.UserID.String())
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "User not found",
+			"data":    nil,
+			"error":   err.Error(),
+		})
+	}
+
+	dryRun := c.Query("dry_run") == "true" || c.FormValue("dry_run") == "true"
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "HR export file is required",
+			"data":    nil,
+			"error":   err.Error(),
+		})
+	}
+	if ext := strings.ToLower(filepath.Ext(fileHeader.Filename)); ext != ".csv" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "HR export must be a CSV file",
+			"data":    nil,
+			"error":   fmt.Sprintf("file type %s is not allowed", ext),
+		})
+	}
+	if fileHeader.Size > maxHRExportSize {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+			"message": "HR export is too large",
+			"data":    nil,
+			"error":   "file_too_large",
+		})
+	}
+
+	src, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to read HR export",
+			"data":    nil,
+			"error":   err.Error(),
+		})
+	}
+	defer src.Close()
+
+	records, rejected, err := services.ParseHRExport(src)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid HR export",
+			"data":    nil,
+			"error":   err.Error(),
+		})
+	}
+	// Without valid rows every HR-managed user would be deactivated
+	if len(records) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "The HR export has no valid rows",
+			"data":    fiber.Map{"report": rejected},
+			"error":   "no_valid_rows",
+		})
+	}
+
+	users, err := uc.UserRepo.GetAllUsers()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Internal server error: Could not retrieve users",
+			"data":    nil,
+			"error":   err.Error(),
+		})
+	}
+	roles, err := uc.UserRepo.GetAllRoles()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Internal server error: Could not retrieve roles",
+			"data":    nil,
+			"error":   err.Error(),
+		})
+	}
+	departments, err := uc.UserRepo.GetDepartmentsAll()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Internal server error: Could not retrieve departments",
+			"data":    nil,
+			"error":   err.Error(),
+		})
+	}
+
+	plan := services.PlanHRImport(records, rejected, users, roles, departments, importer.ID, importer.Email)
+	run := &models.UserImportRun{
+		FileName:  fileHeader.Filename,
+		DryRun:    dryRun,
+		TotalRows: len(records) + len(rejected),
+		CreatedBy: importer.Email,
+	}
+
+	if dryRun {
+		summarizeUserImport(run, plan)
+		if err := uc.UserRepo.CreateUserImportRun(run); err != nil {
+			config.Logger.Error("Failed to record user import dry run", zap.Error(err))
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"message": "Dry run completed, no users were changed",
+			"data":    fiber.Map{"import": run, "report": plan.Entries, "new_departments": plan.NewDepartments},
+			"error":   nil,
+		})
+	}
+
+	// --- Start Database Transaction ---
+	tx := uc.DB.WithContext(c.UserContext()).Begin()
+	if tx.Error != nil {
+		config.Logger.Error("Failed to begin database transaction", zap.Error(tx.Error))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Internal server error: Could not start database transaction",
+			"data":    nil,
+			"error":   tx.Error.Error(),
+		})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			config.Logger.Error("Panic detected, rolling back transaction", zap.Any("panic_reason", r))
+			panic(r)
+		}
+	}()
+
+	txUserRepo := repositories.NewUserRepository(tx)
+
+	newDepartmentIDs := make(map[string]uuid.UUID, len(plan.NewDepartments))
+	for _, name := range plan.NewDepartments {
+		department, err := txUserRepo.CreateDepartment(&models.Department{
+			Name:      name,
+			IsActive:  true,
+			CreatedBy: importer.Email,
+		})
+		if err != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"message": fmt.Sprintf("Failed to create department %s", name),
+				"data":    nil,
+				"error":   err.Error(),
+			})
+		}
+		newDepartmentIDs[name] = department.ID
+	}
+
+	// Each user is written behind a savepoint so one bad row is reported instead of failing
+	// the whole import
+	failRow := func(change services.HRUserChange, err error) {
+		tx.RollbackTo("hr_import_row")
+		plan.Entries[change.Entry].Action = services.HRImportError
+		plan.Entries[change.Entry].Error = err.Error()
+		config.Logger.Warn("HR import row failed",
+			zap.String("employeeNumber", plan.Entries[change.Entry].EmployeeNumber),
+			zap.Error(err))
+	}
+
+	var createdUsers []*models.User
+	for _, change := range plan.Creates {
+		if change.NewDepartment != "" {
+			departmentID := newDepartmentIDs[change.NewDepartment]
+			change.User.DepartmentID = &departmentID
+		}
+		password, err := randomImportPassword()
+		if err != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"message": "Internal server error",
+				"data":    nil,
+				"error":   "password_generation_failed",
+			})
+		}
+		change.User.Password = password
+
+		tx.SavePoint("hr_import_row")
+		created, err := txUserRepo.CreateUser(change.User)
+		if err != nil {
+			failRow(change, err)
+			continue
+		}
+		id := created.ID
+		plan.Entries[change.Entry].UserID = &id
+		createdUsers = append(createdUsers, created)
+	}
+
+	var changedUserIDs []uuid.UUID
+	for _, changes := range [][]services.HRUserChange{plan.Updates, plan.Deactivations} {
+		for _, change := range changes {
+			if change.NewDepartment != "" {
+				change.Fields["department_id"] = newDepartmentIDs[change.NewDepartment]
+			}
+			change.Fields["updated_by"] = importer.Email
+
+			tx.SavePoint("hr_import_row")
+			if err := txUserRepo.UpdateUserFields(change.User.ID, change.Fields); err != nil {
+				failRow(change, err)
+				continue
+			}
+			changedUserIDs = append(changedUserIDs, change.User.ID)
+		}
+	}
+
+	summarizeUserImport(run, plan)
+	run.DepartmentsCreated = len(newDepartmentIDs)
+	if err := txUserRepo.CreateUserImportRun(run); err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to record user import",
+			"data":    nil,
+			"error":   err.Error(),
+		})
+	}
+
+	// --- Commit Transaction ---
+	if err := tx.Commit().Error; err != nil {
+		config.Logger.Error("Failed to commit transaction", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to finalize user import",
+			"data":    nil,
+			"error":   err.Error(),
+		})
+	}
+
+	for _, userID := range changedUserIDs {
+		repositories.InvalidateUserCache(userID.String())
+	}
+	utils.InvalidateCacheAsync("user")
+	uc.indexImportedUsers(createdUsers, changedUserIDs)
+
+	// Invitations go out after commit so nobody is invited to an account that was rolled back
+	for _, change := range plan.Creates {
+		entry := &plan.Entries[change.Entry]
+		if entry.Action != services.HRImportCreate || entry.UserID == nil {
+			continue
+		}
+		if err := uc.sendImportInvitation(*entry.UserID, change.User); err != nil {
+			config.Logger.Warn("Failed to send HR import invitation",
+				zap.String("email", change.User.Email),
+				zap.Error(err))
+			continue
+		}
+		entry.InvitationSent = true
+		run.InvitationsSent++
+	}
+	if run.InvitationsSent > 0 {
+		summarizeUserImport(run, plan)
+		if err := uc.UserRepo.UpdateUserImportRun(run); err != nil {
+			config.Logger.Error("Failed to record HR import invitations", zap.Error(err))
+		}
+	}
+
+	config.Logger.Info("HR user import completed",
+		zap.String("importID", run.ID.String()),
+		zap.Int("created", run.CreatedCount),
+		zap.Int("updated", run.UpdatedCount),
+		zap.Int("deactivated", run.DeactivatedCount),
+		zap.Int("errors", run.ErrorCount),
+		zap.String("importedBy", importer.Email))
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "User import completed",
+		"data":    fiber.Map{"import": run, "report": plan.Entries, "new_departments": plan.NewDepartments},
+		"error":   nil,
+	})
+}
+
+// GetUserImportsController lists recent HR imports
+func (uc *UserController) GetUserImportsController(c *fiber.Ctx) error {
+	runs, err := uc.UserRepo.GetUserImportRuns(userImportHistoryLimit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Internal server error: Could not retrieve user imports",
+			"data":    nil,
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "User imports retrieved successfully",
+		"data":    runs,
+		"error":   nil,
+	})
+}
+
+// GetUserImportController returns one HR import with its reconciliation report
+func (uc *UserController) GetUserImportController(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if _, err := uuid.Parse(id); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid user import ID",
+			"data":    nil,
+			"error":   "invalid_uuid",
+		})
+	}
+
+	run, err := uc.UserRepo.GetUserImportRun(id)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if err.Error() == "user import not found" {
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"message": "Failed to retrieve user import",
+			"data":    nil,
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "User import retrieved successfully",
+		"data":    run,
+		"error":   nil,
+	})
+}
+
+// summarizeUserImport copies the plan's counts and report onto the import record
+func summarizeUserImport(run *models.UserImportRun, plan *services.HRImportPlan) {
+	run.CreatedCount = plan.Count(services.HRImportCreate)
+	run.UpdatedCount = plan.Count(services.HRImportUpdate)
+	run.UnchangedCount = plan.Count(services.HRImportUnchanged)
+	run.DeactivatedCount = plan.Count(services.HRImportDeactivate)
+	run.ErrorCount = plan.Count(services.HRImportError)
+	if run.DryRun {
+		run.DepartmentsCreated = len(plan.NewDepartments)
+	}
+
+	report, err := json.Marshal(plan.Entries)
+	if err != nil {
+		config.Logger.Error("Failed to encode HR import report", zap.Error(err))
+		return
+	}
+	run.Report = report
+}
+
+// indexImportedUsers brings the search index in line with the import. Failures are logged
+// rather than undoing a committed import.
+func (uc *UserController) indexImportedUsers(created []*models.User, changed []uuid.UUID) {
+	if uc.BleveRepo == nil {
+		config.Logger.Error("IndexingService is nil, cannot index imported users")
+		return
+	}
+	for _, user := range created {
+		if err := uc.BleveRepo.IndexSingleUser(*user); err != nil {
+			config.Logger.Error("Failed to index imported user", zap.Error(err), zap.String("userID", user.ID.String()))
+		}
+	}
+	for _, userID := range changed {
+		var user models.User
+		if err := uc.DB.Preload("Role").Preload("Department").First(&user, "id = ?", userID).Error; err != nil {
+			config.Logger.Error("Failed to reload imported user", zap.Error(err), zap.String("userID", userID.String()))
+			continue
+		}
+		if err := uc.BleveRepo.UpdateUser(user); err != nil {
+			config.Logger.Error("Failed to reindex imported user", zap.Error(err), zap.String("userID", userID.String()))
+		}
+	}
+}
+
+// sendImportInvitation emails a newly provisioned user a magic link to sign in for the first time
+func (uc *UserController) sendImportInvitation(userID uuid.UUID, user *models.User) error {
+	if uc.MagicLinkService == nil {
+		return fmt.Errorf("magic link service is not configured")
+	}
+	link, err := uc.MagicLinkService.GenerateInvitationLink(userID.String(), user.Email)
+	if err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf(
+		"Hello %s,\n\nAn account has been created for you on the Town Planning system.\n\n"+
+			"Use the link below to sign in. It expires on %s.\n\n%s\n\n"+
+			"After that, request a new sign-in link from the login page.",
+		user.FirstName,
+		link.ExpiresAt.Format("Jan 2, 2006 at 3:04 PM"),
+		link.URL,
+	)
+	return utils.SendEmail(user.Email, message, "Your Town Planning account", "", "")
+}
+
+// randomImportPassword gives imported users an unusable password; they sign in by magic link
+func randomImportPassword() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"town-planning-backend/config"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// SetDepartmentHeadRequest names the department head. A null head_user_id clears it.
+type SetDepartmentHeadRequest struct {
+	HeadUserID *uuid.UUID `json:"head_user_id"`
+}
+
+// SetDepartmentHeadController sets the head of a department, who overdue approval decisions
+// by the department's staff are escalated to
+func (uc *UserController) SetDepartmentHeadController(c *fiber.Ctx) error {
+	payload, ok := c.Locals("user").(*token.Payload)
+	if !ok || payload == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "User not authenticated",
+			"data":    nil,
+			"error":   "unauthorized",
+		})
+	}
+
+	departmentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid department ID",
+			"data":    nil,
+			"error":   "invalid_uuid",
+		})
+	}
+
+	var req SetDepartmentHeadRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"data":    nil,
+			"error":   err.Error(),
+		})
+	}
+
+	department, err := uc.UserRepo.SetDepartmentHead(departmentID, req.HeadUserID, payload.UserID.String())
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		switch err.Error() {
+		case "department not found":
+			status = fiber.StatusNotFound
+		case "department head not found or inactive":
+			status = fiber.StatusBadRequest
+		}
+		config.Logger.Warn("Failed to set department head",
+			zap.Error(err),
+			zap.String("departmentID", departmentID.String()))
+		return c.Status(status).JSON(fiber.Map{
+			"message": "Could not set department head",
+			"data":    nil,
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Department head updated successfully",
+		"data":    department,
+		"error":   nil,
+	})
+}
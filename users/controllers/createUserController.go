@@ -16,10 +16,11 @@ import (
 )
 
 type UserController struct {
-	UserRepo  repositories.UserRepository
-	DB        *gorm.DB
-	Ctx       context.Context
-	BleveRepo indexing_repository.BleveRepositoryInterface
+	UserRepo         repositories.UserRepository
+	DB               *gorm.DB
+	Ctx              context.Context
+	BleveRepo        indexing_repository.BleveRepositoryInterface
+	MagicLinkService *services.MagicLinkService
 }
 
 // CreateUserRequest represents the request body for user creation
@@ -118,7 +119,7 @@ func (uc *UserController) CreateUser(c *fiber.Ctx) error {
 	}
 
 	// --- Start Database Transaction ---
-	tx := uc.DB.Begin()
+	tx := uc.DB.WithContext(c.UserContext()).Begin()
 	if tx.Error != nil {
 		config.Logger.Error("Failed to begin database transaction", zap.Error(tx.Error))
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -13,7 +13,7 @@ func (uc *UserController) DeleteUserController(c *fiber.Ctx) error {
 	userID := c.Params("id")
 
 	// --- Start Database Transaction ---
-	tx := uc.DB.Begin()
+	tx := uc.DB.WithContext(c.UserContext()).Begin()
 	if tx.Error != nil {
 		config.Logger.Error("Failed to begin database transaction", zap.Error(tx.Error))
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -79,6 +79,7 @@ func (uc *UserController) DeleteUserController(c *fiber.Ctx) error {
 
 	// --- Success ---
 	utils.InvalidateCacheAsync("user")
+	repositories.InvalidateUserCache(userID)
 	return c.JSON(fiber.Map{
 		"message": "User deleted successfully",
 	})
@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"strings"
+	application_services "town-planning-backend/applications/services"
 	"town-planning-backend/config"
 	"town-planning-backend/db/models"
 	"town-planning-backend/users/repositories"
@@ -13,15 +15,17 @@ import (
 )
 
 type UpdateUserPayload struct {
-	FirstName       string `json:"first_name"`
-	LastName        string `json:"last_name"`
-	Phone           string `json:"phone"`
-	Email           string `json:"email"`
-	Role            string `json:"role"`
-	Active          bool   `json:"active"`
-	Password        string `json:"password"`         // Old password for verification
-	NewPassword     string `json:"new_password"`     // New password to set
-	ConfirmPassword string `json:"confirm_password"` // Optional confirmation
+	FirstName          string  `json:"first_name"`
+	LastName           string  `json:"last_name"`
+	Phone              string  `json:"phone"`
+	Email              string  `json:"email"`
+	Role               string  `json:"role"`
+	Active             bool    `json:"active"`
+	Password           string  `json:"password"`           // Old password for verification
+	NewPassword        string  `json:"new_password"`       // New password to set
+	ConfirmPassword    string  `json:"confirm_password"`   // Optional confirmation
+	PreferredLanguage  string  `json:"preferred_language"` // en, sn or nd
+	ResidentialAddress *string `json:"residential_address"`
 }
 
 func (uc *UserController) UpdateUserController(c *fiber.Ctx) error {
@@ -35,7 +39,7 @@ func (uc *UserController) UpdateUserController(c *fiber.Ctx) error {
 	}
 
 	// --- Start Database Transaction ---
-	tx := uc.DB.Begin()
+	tx := uc.DB.WithContext(c.UserContext()).Begin()
 	if tx.Error != nil {
 		config.Logger.Error("Failed to begin transaction", zap.Error(tx.Error))
 		return c.Status(500).JSON(fiber.Map{
@@ -97,6 +101,24 @@ func (uc *UserController) UpdateUserController(c *fiber.Ctx) error {
 	if payload.Active != existingUser.Active {
 		existingUser.Active = payload.Active
 	}
+	if payload.PreferredLanguage != "" {
+		if !application_services.SupportedLanguage(payload.PreferredLanguage) {
+			tx.Rollback()
+			return c.Status(400).JSON(fiber.Map{
+				"message": "Validation error",
+				"error":   "Unsupported preferred language",
+			})
+		}
+		existingUser.PreferredLanguage = strings.ToLower(strings.TrimSpace(payload.PreferredLanguage))
+	}
+	if payload.ResidentialAddress != nil {
+		address := strings.TrimSpace(*payload.ResidentialAddress)
+		if address == "" {
+			existingUser.ResidentialAddress = nil
+		} else {
+			existingUser.ResidentialAddress = &address
+		}
+	}
 
 	// Password update logic (same as your existing checks)
 	if payload.NewPassword != "" {
@@ -175,6 +197,7 @@ func (uc *UserController) UpdateUserController(c *fiber.Ctx) error {
 	// --- Success ---
 	utils.InvalidateCacheAsync("user:" + id)
 	utils.InvalidateCacheAsync("users")
+	repositories.InvalidateUserCache(id)
 
 	return c.JSON(fiber.Map{
 		"message": "User updated successfully",
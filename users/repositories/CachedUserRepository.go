@@ -0,0 +1,192 @@
+package repositories
+
+import (
+	"time"
+	"town-planning-backend/cache"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+)
+
+// cachedUserRepository serves user profiles, role permission reads and permission checks from
+// Redis and invalidates them on writes that go through it. GetUserByID is not cached: its callers
+// check and save the password hash and TOTP secret, which must never be written to Redis.
+type cachedUserRepository struct {
+	UserRepository
+	cache *cache.RepositoryCache
+}
+
+// cachedUser is the part of a user that is cached. It has no field for the password hash, TOTP
+// secret or login lockout state, so none of them can reach Redis. Disabled users are never
+// cached, since GetUserProfileByID refuses them.
+type cachedUser struct {
+	ID                 uuid.UUID
+	FirstName          string
+	LastName           string
+	Email              string
+	Phone              string
+	WhatsAppNumber     *string
+	AuthMethod         models.AuthMethod
+	RoleID             uuid.UUID
+	DepartmentID       *uuid.UUID
+	Active             bool
+	IsSuspended        bool
+	EmailVerified      bool
+	LastLoginAt        *time.Time
+	ProfilePictureURL  *string
+	SignatureFilePath  *string
+	PreferredLanguage  string
+	ResidentialAddress *string
+	EmployeeNumber     *string
+	CreatedBy          string
+	CreatedAt          time.Time
+	UpdatedBy          *string
+	LastUpdatedAt      time.Time
+	Role               *models.Role
+	Department         *models.Department
+}
+
+func newCachedUser(user *models.User) cachedUser {
+	return cachedUser{
+		ID:                 user.ID,
+		FirstName:          user.FirstName,
+		LastName:           user.LastName,
+		Email:              user.Email,
+		Phone:              user.Phone,
+		WhatsAppNumber:     user.WhatsAppNumber,
+		AuthMethod:         user.AuthMethod,
+		RoleID:             user.RoleID,
+		DepartmentID:       user.DepartmentID,
+		Active:             user.Active,
+		IsSuspended:        user.IsSuspended,
+		EmailVerified:      user.EmailVerified,
+		LastLoginAt:        user.LastLoginAt,
+		ProfilePictureURL:  user.ProfilePictureURL,
+		SignatureFilePath:  user.SignatureFilePath,
+		PreferredLanguage:  user.PreferredLanguage,
+		ResidentialAddress: user.ResidentialAddress,
+		EmployeeNumber:     user.EmployeeNumber,
+		CreatedBy:          user.CreatedBy,
+		CreatedAt:          user.CreatedAt,
+		UpdatedBy:          user.UpdatedBy,
+		LastUpdatedAt:      user.LastUpdatedAt,
+		Role:               user.Role,
+		Department:         user.Department,
+	}
+}
+
+func (u cachedUser) user() *models.User {
+	return &models.User{
+		ID:                 u.ID,
+		FirstName:          u.FirstName,
+		LastName:           u.LastName,
+		Email:              u.Email,
+		Phone:              u.Phone,
+		WhatsAppNumber:     u.WhatsAppNumber,
+		AuthMethod:         u.AuthMethod,
+		RoleID:             u.RoleID,
+		DepartmentID:       u.DepartmentID,
+		Active:             u.Active,
+		IsSuspended:        u.IsSuspended,
+		EmailVerified:      u.EmailVerified,
+		LastLoginAt:        u.LastLoginAt,
+		ProfilePictureURL:  u.ProfilePictureURL,
+		SignatureFilePath:  u.SignatureFilePath,
+		PreferredLanguage:  u.PreferredLanguage,
+		ResidentialAddress: u.ResidentialAddress,
+		EmployeeNumber:     u.EmployeeNumber,
+		CreatedBy:          u.CreatedBy,
+		CreatedAt:          u.CreatedAt,
+		UpdatedBy:          u.UpdatedBy,
+		LastUpdatedAt:      u.LastUpdatedAt,
+		Role:               u.Role,
+		Department:         u.Department,
+	}
+}
+
+// NewCachedUserRepository wraps a user repository with the repository cache
+func NewCachedUserRepository(repo UserRepository, repoCache *cache.RepositoryCache) UserRepository {
+	return &cachedUserRepository{UserRepository: repo, cache: repoCache}
+}
+
+func (r *cachedUserRepository) GetUserProfileByID(id string) (*models.User, error) {
+	var entry cachedUser
+	if r.cache.Get(cache.EntityUser, id, &entry) {
+		return entry.user(), nil
+	}
+
+	found, err := r.UserRepository.GetUserProfileByID(id)
+	if err != nil {
+		return nil, err
+	}
+	r.cache.Set(cache.EntityUser, id, newCachedUser(found))
+	return found, nil
+}
+
+func (r *cachedUserRepository) GetRoleWithPermissionsByID(roleID string) (*models.Role, error) {
+	var role models.Role
+	if r.cache.Get(cache.EntityRolePermissions, roleID, &role) {
+		return &role, nil
+	}
+
+	found, err := r.UserRepository.GetRoleWithPermissionsByID(roleID)
+	if err != nil {
+		return found, err
+	}
+	r.cache.Set(cache.EntityRolePermissions, roleID, found)
+	return found, nil
+}
+
+// UserHasPermission answers from the cached user and role permissions when both entities are cached,
+// so permission middleware does not hit the database on every request.
+func (r *cachedUserRepository) UserHasPermission(userID string, permissionName string) (bool, error) {
+	if !r.cache.Enabled(cache.EntityUser) || !r.cache.Enabled(cache.EntityRolePermissions) {
+		return r.UserRepository.UserHasPermission(userID, permissionName)
+	}
+
+	user, err := r.GetUserProfileByID(userID)
+	if err != nil {
+		// Disabled and missing users fall through to the query, which reports them as not allowed
+		return r.UserRepository.UserHasPermission(userID, permissionName)
+	}
+
+	role, err := r.GetRoleWithPermissionsByID(user.RoleID.String())
+	if err != nil {
+		return r.UserRepository.UserHasPermission(userID, permissionName)
+	}
+
+	for _, rolePermission := range role.Permissions {
+		if rolePermission.Permission.Name == permissionName && rolePermission.Permission.IsActive {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *cachedUserRepository) UpdateUser(user *models.User) (*models.User, error) {
+	updated, err := r.UserRepository.UpdateUser(user)
+	if err != nil {
+		return nil, err
+	}
+	invalidateUser(r.cache, user.ID.String())
+	return updated, nil
+}
+
+func (r *cachedUserRepository) DeleteUser(id string) error {
+	if err := r.UserRepository.DeleteUser(id); err != nil {
+		return err
+	}
+	invalidateUser(r.cache, id)
+	return nil
+}
+
+// InvalidateUserCache drops a cached user. Approval groups embed member user details, so
+// they are dropped too. Call it after committing user writes made outside the cached repository.
+func InvalidateUserCache(userID string) {
+	invalidateUser(cache.Shared(), userID)
+}
+
+func invalidateUser(repoCache *cache.RepositoryCache, userID string) {
+	repoCache.Invalidate(cache.EntityUser, userID)
+	repoCache.InvalidateAll(cache.EntityApprovalGroup)
+}
@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UpdateUserFields writes only the given columns, so an import does not overwrite fields the
+// HR export does not carry
+func (r *userRepository) UpdateUserFields(userID uuid.UUID, fields map[string]interface{}) error {
+	if err := r.db.Model(&models.User{}).Where("id = ?", userID).Updates(fields).Error; err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+	return nil
+}
+
+func (r *userRepository) CreateUserImportRun(run *models.UserImportRun) error {
+	if err := r.db.Create(run).Error; err != nil {
+		return fmt.Errorf("failed to record user import: %w", err)
+	}
+	return nil
+}
+
+func (r *userRepository) UpdateUserImportRun(run *models.UserImportRun) error {
+	if err := r.db.Save(run).Error; err != nil {
+		return fmt.Errorf("failed to update user import: %w", err)
+	}
+	return nil
+}
+
+// GetUserImportRuns lists recent imports without their reports, newest first
+func (r *userRepository) GetUserImportRuns(limit int) ([]models.UserImportRun, error) {
+	var runs []models.UserImportRun
+	err := r.db.Omit("report").Order("created_at DESC").Limit(limit).Find(&runs).Error
+	return runs, err
+}
+
+func (r *userRepository) GetUserImportRun(id string) (*models.UserImportRun, error) {
+	var run models.UserImportRun
+	if err := r.db.Where("id = ?", id).First(&run).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user import not found")
+		}
+		return nil, fmt.Errorf("failed to load user import: %w", err)
+	}
+	return &run, nil
+}
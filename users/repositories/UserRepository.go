@@ -14,6 +14,7 @@ import (
 type UserRepository interface {
 	CreateUser(user *models.User) (*models.User, error)
 	GetUserByID(id string) (*models.User, error)
+	GetUserProfileByID(id string) (*models.User, error)
 	GetUserByPhoneNumber(phone string) (*models.User, error)
 	GetUserByEmail(email string) (*models.User, error)
 	UpdateUser(user *models.User) (*models.User, error)
@@ -22,9 +23,18 @@ type UserRepository interface {
 	GetAllPermissions() ([]models.Permission, error)
 	GetAllRoles() ([]models.Role, error)
 	GetRoleWithPermissionsByID(roleID string) (*models.Role, error)
+	UserHasPermission(userID string, permissionName string) (bool, error)
 	CreateDepartment(department *models.Department) (*models.Department, error)
 	GetDepartmentsAll() ([]models.Department, error)
+	SetDepartmentHead(departmentID uuid.UUID, headUserID *uuid.UUID, updatedBy string) (*models.Department, error)
 	GetFilteredUsers(pageSize int, offset int, filters map[string]string) ([]models.User, int64, error)
+
+	// HR export imports
+	UpdateUserFields(userID uuid.UUID, fields map[string]interface{}) error
+	CreateUserImportRun(run *models.UserImportRun) error
+	UpdateUserImportRun(run *models.UserImportRun) error
+	GetUserImportRuns(limit int) ([]models.UserImportRun, error)
+	GetUserImportRun(id string) (*models.UserImportRun, error)
 }
 
 // Implementations
@@ -56,6 +66,7 @@ func (r *userRepository) CreateDepartment(department *models.Department) (*model
 			existing.Email = department.Email
 			existing.PhoneNumber = department.PhoneNumber
 			existing.OfficeLocation = department.OfficeLocation
+			existing.HeadUserID = department.HeadUserID
 			existing.CreatedBy = department.CreatedBy
 
 			if err := r.db.Unscoped().Save(&existing).Error; err != nil {
@@ -79,12 +90,60 @@ func (r *userRepository) CreateDepartment(department *models.Department) (*model
 	return department, nil
 }
 
+// SetDepartmentHead sets or clears the head of a department. The head must be an active user.
+func (r *userRepository) SetDepartmentHead(departmentID uuid.UUID, headUserID *uuid.UUID, updatedBy string) (*models.Department, error) {
+	var department models.Department
+	if err := r.db.Where("id = ?", departmentID).First(&department).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("department not found")
+		}
+		return nil, fmt.Errorf("failed to fetch department: %w", err)
+	}
+
+	if headUserID != nil {
+		var count int64
+		if err := r.db.Model(&models.User{}).
+			Where("id = ? AND active = ?", *headUserID, true).
+			Count(&count).Error; err != nil {
+			return nil, fmt.Errorf("failed to check department head: %w", err)
+		}
+		if count == 0 {
+			return nil, errors.New("department head not found or inactive")
+		}
+	}
+
+	if err := r.db.Model(&department).Updates(map[string]interface{}{
+		"head_user_id": headUserID,
+		"updated_by":   updatedBy,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update department head: %w", err)
+	}
+	department.HeadUserID = headUserID
+	department.UpdatedBy = &updatedBy
+	return &department, nil
+}
+
 func (r *userRepository) GetRoleWithPermissionsByID(roleID string) (*models.Role, error) {
 	var role models.Role
 	err := r.db.Preload("Permissions.Permission").Where("id = ?", roleID).First(&role).Error
 	return &role, err
 }
 
+// UserHasPermission checks whether the user's role grants an active permission by name
+func (r *userRepository) UserHasPermission(userID string, permissionName string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.RolePermission{}).
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
+		Joins("JOIN users ON users.role_id = role_permissions.role_id").
+		Where("users.id = ? AND users.deleted_at IS NULL AND users.active = ? AND users.is_suspended = ?", userID, true, false).
+		Where("permissions.name = ? AND permissions.is_active = ? AND permissions.deleted_at IS NULL", permissionName, true).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check permission %s: %w", permissionName, err)
+	}
+	return count > 0, nil
+}
+
 func (r *userRepository) GetAllRoles() ([]models.Role, error) {
 	var roles []models.Role
 	err := r.db.Find(&roles).Error
@@ -166,6 +225,9 @@ func (r *userRepository) CreateUser(user *models.User) (*models.User, error) {
 			existing.Password = hashedPassword
 			existing.Phone = user.Phone
 			existing.Role = user.Role
+			existing.RoleID = user.RoleID
+			existing.DepartmentID = user.DepartmentID
+			existing.EmployeeNumber = user.EmployeeNumber
 			existing.Active = user.Active
 			existing.CreatedBy = user.CreatedBy
 
@@ -202,6 +264,17 @@ func (r *userRepository) GetUserByID(id string) (*models.User, error) {
 	return &user, err
 }
 
+// GetUserProfileByID returns an enabled user without the password hash, TOTP secret and login
+// lockout state, for callers that only need to show or check who the user is
+func (r *userRepository) GetUserProfileByID(id string) (*models.User, error) {
+	user, err := r.GetUserByID(id)
+	if err != nil {
+		return nil, err
+	}
+	user.ClearCredentials()
+	return user, nil
+}
+
 func (r *userRepository) GetUserByPhoneNumber(phone string) (*models.User, error) {
 	var user models.User
 	err := r.db.Preload("Role").Preload("Department").First(&user, "phone = ?", phone).Error
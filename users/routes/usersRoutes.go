@@ -39,10 +39,11 @@ func InitRoutes(
 
 	// Initialize controllers
 	userController := &controllers.UserController{
-		UserRepo:  userRepo,
-		DB:        db,
-		Ctx:       ctx,
-		BleveRepo: bleveRepo,
+		UserRepo:         userRepo,
+		DB:               db,
+		Ctx:              ctx,
+		BleveRepo:        bleveRepo,
+		MagicLinkService: magicLinkService,
 	}
 
 	enhancedLoginController := controllers.NewEnhancedLoginController(
@@ -100,6 +101,11 @@ func InitRoutes(
 			// Specific routes first
 			userRoutes.Get("/filtered", userController.GetFilteredUsersController)
 
+			// HR export imports
+			userRoutes.Post("/import", middleware.LongRunning(), middleware.Upload(), middleware.RequirePermission(userRepo, "user.manage"), userController.ImportUsersController)
+			userRoutes.Get("/imports", middleware.RequirePermission(userRepo, "user.manage"), userController.GetUserImportsController)
+			userRoutes.Get("/imports/:id", middleware.RequirePermission(userRepo, "user.manage"), userController.GetUserImportController)
+
 			// General routes
 			userRoutes.Get("/", userController.GetAllUsersController)
 			userRoutes.Post("/", userController.CreateUser)
@@ -107,6 +113,7 @@ func InitRoutes(
 			userRoutes.Post("/roles", userController.CreateRoleWithPermissionsController)
 			userRoutes.Post("/departments", userController.CreateDepartmentController)
 			userRoutes.Get("/departments", userController.GetDepartmentsAllController)
+			userRoutes.Put("/departments/:id/head", middleware.RequirePermission(userRepo, "user.manage"), userController.SetDepartmentHeadController)
 			userRoutes.Get("/roles", userController.GetAllRolesController)
 
 			// ID-based routes with validation
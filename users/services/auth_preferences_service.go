@@ -68,6 +68,7 @@ func (aps *AuthPreferencesService) SetAuthMethod(userID string, method string) e
 
 	// Invalidate any existing sessions if changing auth method
 	aps.redisClient.Del(aps.ctx, "user_sessions:"+userID)
+	repositories.InvalidateUserCache(userID)
 
 	return nil
 }
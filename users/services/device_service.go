@@ -60,6 +60,7 @@ type MagicLinkData struct {
 	CreatedAt         time.Time         `json:"created_at"`
 	ExpiresAt         time.Time         `json:"expires_at"`
 	Used              bool              `json:"used"`
+	Invitation        bool              `json:"invitation,omitempty"`
 }
 
 type MagicLinkPreferences struct {
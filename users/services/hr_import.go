@@ -0,0 +1,448 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"town-planning-backend/db/models"
+
+	"github.com/google/uuid"
+)
+
+// hrExportColumns are the columns every HR export must carry. whatsapp_number is optional.
+var hrExportColumns = []string{"employee_number", "first_name", "last_name", "email", "phone", "department", "role"}
+
+var e164Regex = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// HRRecord is one staff member from the HR export
+type HRRecord struct {
+	Row            int
+	EmployeeNumber string
+	FirstName      string
+	LastName       string
+	Email          string
+	Phone          string
+	WhatsAppNumber *string
+	Department     string
+	Role           string
+}
+
+// HRImportAction is what an import does, or would do, to one user
+type HRImportAction string
+
+const (
+	HRImportCreate     HRImportAction = "CREATE"
+	HRImportUpdate     HRImportAction = "UPDATE"
+	HRImportUnchanged  HRImportAction = "UNCHANGED"
+	HRImportDeactivate HRImportAction = "DEACTIVATE"
+	HRImportError      HRImportAction = "ERROR"
+)
+
+// HRImportReportEntry is one line of the reconciliation report
+type HRImportReportEntry struct {
+	Row            int            `json:"row,omitempty"`
+	EmployeeNumber string         `json:"employee_number"`
+	Email          string         `json:"email"`
+	Name           string         `json:"name"`
+	Department     string         `json:"department,omitempty"`
+	Role           string         `json:"role,omitempty"`
+	Action         HRImportAction `json:"action"`
+	Changes        []string       `json:"changes,omitempty"`
+	Error          string         `json:"error,omitempty"`
+	UserID         *uuid.UUID     `json:"user_id,omitempty"`
+	InvitationSent bool           `json:"invitation_sent,omitempty"`
+}
+
+// HRUserChange is a user the import creates or updates. Entry indexes the user's line in the
+// report. NewDepartment names a department the import creates; the user's department ID is
+// resolved once it exists.
+type HRUserChange struct {
+	User          *models.User
+	Fields        map[string]interface{}
+	NewDepartment string
+	Entry         int
+}
+
+// HRImportPlan is the reconciliation of an HR export against the current users. Applying it
+// creates NewDepartments first, then Creates, Updates and Deactivations.
+type HRImportPlan struct {
+	Entries        []HRImportReportEntry
+	NewDepartments []string
+
+	Creates       []HRUserChange
+	Updates       []HRUserChange
+	Deactivations []HRUserChange
+}
+
+// Count returns how many report entries have the given action
+func (p *HRImportPlan) Count(action HRImportAction) int {
+	count := 0
+	for _, entry := range p.Entries {
+		if entry.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// normalizeHRHeader turns "Employee Number" and "employee-number" into "employee_number"
+func normalizeHRHeader(header string) string {
+	header = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header, "\ufeff")))
+	header = strings.NewReplacer(" ", "_", "-", "_").Replace(header)
+	return header
+}
+
+// ParseHRExport reads an HR export CSV. Rows that cannot be used are returned as error entries
+// for the reconciliation report rather than failing the whole file.
+func ParseHRExport(r io.Reader) ([]HRRecord, []HRImportReportEntry, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, errors.New("the HR export is empty")
+		}
+		return nil, nil, fmt.Errorf("failed to read HR export header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[normalizeHRHeader(name)] = i
+	}
+	var missing []string
+	for _, name := range hrExportColumns {
+		if _, ok := columns[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, nil, fmt.Errorf("the HR export is missing columns: %s", strings.Join(missing, ", "))
+	}
+
+	var records []HRRecord
+	var rejected []HRImportReportEntry
+	seenEmployees := make(map[string]int)
+	seenEmails := make(map[string]int)
+	seenPhones := make(map[string]int)
+
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read HR export: %w", err)
+		}
+		// Report the line in the file so HR can find the row
+		rowNumber, _ := reader.FieldPos(0)
+
+		field := func(name string) string {
+			index, ok := columns[name]
+			if !ok || index >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[index])
+		}
+
+		record := HRRecord{
+			Row:            rowNumber,
+			EmployeeNumber: field("employee_number"),
+			FirstName:      field("first_name"),
+			LastName:       field("last_name"),
+			Email:          strings.ToLower(field("email")),
+			Phone:          strings.ReplaceAll(field("phone"), " ", ""),
+			Department:     field("department"),
+			Role:           field("role"),
+		}
+		if whatsApp := strings.ReplaceAll(field("whatsapp_number"), " ", ""); whatsApp != "" {
+			record.WhatsAppNumber = &whatsApp
+		}
+
+		// Skip blank lines that spreadsheets leave at the end of an export
+		if strings.Join(row, "") == "" {
+			continue
+		}
+
+		reject := func(reason string) {
+			rejected = append(rejected, HRImportReportEntry{
+				Row:            record.Row,
+				EmployeeNumber: record.EmployeeNumber,
+				Email:          record.Email,
+				Name:           strings.TrimSpace(record.FirstName + " " + record.LastName),
+				Department:     record.Department,
+				Role:           record.Role,
+				Action:         HRImportError,
+				Error:          reason,
+			})
+		}
+
+		switch {
+		case record.EmployeeNumber == "":
+			reject("employee number is required")
+			continue
+		case len(record.EmployeeNumber) > 50:
+			reject("employee number must be at most 50 characters")
+			continue
+		case len(record.FirstName) < 2 || len(record.LastName) < 2:
+			reject("first and last name must be at least 2 characters")
+			continue
+		case !ValidateEmailFormat(record.Email):
+			reject("email address is not valid")
+			continue
+		case !e164Regex.MatchString(record.Phone):
+			reject("phone must be in international format, e.g. +263771234567")
+			continue
+		case record.WhatsAppNumber != nil && !e164Regex.MatchString(*record.WhatsAppNumber):
+			reject("WhatsApp number must be in international format")
+			continue
+		case record.Role == "":
+			reject("role is required")
+			continue
+		}
+
+		if previous, ok := seenEmployees[record.EmployeeNumber]; ok {
+			reject(fmt.Sprintf("employee number is repeated from row %d", previous))
+			continue
+		}
+		if previous, ok := seenEmails[record.Email]; ok {
+			reject(fmt.Sprintf("email is repeated from row %d", previous))
+			continue
+		}
+		if previous, ok := seenPhones[record.Phone]; ok {
+			reject(fmt.Sprintf("phone is repeated from row %d", previous))
+			continue
+		}
+		seenEmployees[record.EmployeeNumber] = record.Row
+		seenEmails[record.Email] = record.Row
+		seenPhones[record.Phone] = record.Row
+
+		records = append(records, record)
+	}
+
+	return records, rejected, nil
+}
+
+// PlanHRImport reconciles the HR export against the existing users. Users are matched on
+// employee number, then on email. HR-managed users missing from the export are deactivated,
+// except the importing user, so an admin cannot lock themselves out. Users on a row reported as
+// an error are left as they are.
+func PlanHRImport(
+	records []HRRecord,
+	rejected []HRImportReportEntry,
+	users []models.User,
+	roles []models.Role,
+	departments []models.Department,
+	importerID uuid.UUID,
+	createdBy string,
+) *HRImportPlan {
+	plan := &HRImportPlan{}
+
+	rolesByName := make(map[string]models.Role, len(roles))
+	for _, role := range roles {
+		if role.IsActive {
+			rolesByName[strings.ToLower(role.Name)] = role
+		}
+	}
+	departmentsByName := make(map[string]models.Department, len(departments))
+	for _, department := range departments {
+		departmentsByName[strings.ToLower(department.Name)] = department
+	}
+	newDepartments := make(map[string]string)
+
+	byEmployee := make(map[string]*models.User)
+	byEmail := make(map[string]*models.User)
+	byPhone := make(map[string]*models.User)
+	for i := range users {
+		user := &users[i]
+		if user.EmployeeNumber != nil {
+			byEmployee[*user.EmployeeNumber] = user
+		}
+		byEmail[strings.ToLower(user.Email)] = user
+		if user.Phone != "" {
+			byPhone[user.Phone] = user
+		}
+	}
+	matched := make(map[uuid.UUID]bool)
+
+	// markListed keeps the user on a row from being deactivated, whether or not the row can be
+	// imported: a typo in one column must not lock a staff member out
+	markListed := func(employeeNumber string, email string) {
+		if user := byEmployee[employeeNumber]; employeeNumber != "" && user != nil {
+			matched[user.ID] = true
+		} else if user := byEmail[email]; email != "" && user != nil {
+			matched[user.ID] = true
+		}
+	}
+	for _, entry := range rejected {
+		markListed(entry.EmployeeNumber, entry.Email)
+	}
+
+	for _, record := range records {
+		markListed(record.EmployeeNumber, record.Email)
+
+		entry := HRImportReportEntry{
+			Row:            record.Row,
+			EmployeeNumber: record.EmployeeNumber,
+			Email:          record.Email,
+			Name:           record.FirstName + " " + record.LastName,
+			Department:     record.Department,
+			Role:           record.Role,
+		}
+		fail := func(reason string) {
+			entry.Action = HRImportError
+			entry.Error = reason
+			plan.Entries = append(plan.Entries, entry)
+		}
+
+		role, ok := rolesByName[strings.ToLower(record.Role)]
+		if !ok {
+			fail(fmt.Sprintf("role %q does not exist", record.Role))
+			continue
+		}
+
+		var departmentID *uuid.UUID
+		newDepartment := ""
+		if record.Department != "" {
+			if department, ok := departmentsByName[strings.ToLower(record.Department)]; ok {
+				id := department.ID
+				departmentID = &id
+			} else {
+				key := strings.ToLower(record.Department)
+				if _, planned := newDepartments[key]; !planned {
+					newDepartments[key] = record.Department
+					plan.NewDepartments = append(plan.NewDepartments, record.Department)
+				}
+				newDepartment = newDepartments[key]
+			}
+		}
+
+		existing := byEmployee[record.EmployeeNumber]
+		if existing == nil {
+			existing = byEmail[record.Email]
+			if existing != nil && existing.EmployeeNumber != nil {
+				fail(fmt.Sprintf("email belongs to employee %s", *existing.EmployeeNumber))
+				continue
+			}
+		}
+
+		if owner, ok := byPhone[record.Phone]; ok && (existing == nil || owner.ID != existing.ID) {
+			fail(fmt.Sprintf("phone is already used by %s", owner.Email))
+			continue
+		}
+
+		if existing == nil {
+			user := &models.User{
+				FirstName:      record.FirstName,
+				LastName:       record.LastName,
+				Email:          record.Email,
+				Phone:          record.Phone,
+				WhatsAppNumber: record.WhatsAppNumber,
+				RoleID:         role.ID,
+				DepartmentID:   departmentID,
+				EmployeeNumber: &record.EmployeeNumber,
+				Active:         true,
+				AuthMethod:     models.AuthMethodMagicLink,
+				CreatedBy:      createdBy,
+			}
+			entry.Action = HRImportCreate
+			plan.Entries = append(plan.Entries, entry)
+			plan.Creates = append(plan.Creates, HRUserChange{
+				User:          user,
+				NewDepartment: newDepartment,
+				Entry:         len(plan.Entries) - 1,
+			})
+			continue
+		}
+
+		matched[existing.ID] = true
+		id := existing.ID
+		entry.UserID = &id
+
+		fields := make(map[string]interface{})
+		if existing.EmployeeNumber == nil {
+			fields["employee_number"] = record.EmployeeNumber
+			entry.Changes = append(entry.Changes, "linked to employee number")
+		}
+		if existing.Email != record.Email {
+			fields["email"] = record.Email
+			entry.Changes = append(entry.Changes, fmt.Sprintf("email: %s -> %s", existing.Email, record.Email))
+		}
+		if existing.FirstName != record.FirstName || existing.LastName != record.LastName {
+			fields["first_name"] = record.FirstName
+			fields["last_name"] = record.LastName
+			entry.Changes = append(entry.Changes, "name")
+		}
+		if existing.Phone != record.Phone {
+			fields["phone"] = record.Phone
+			entry.Changes = append(entry.Changes, "phone")
+		}
+		if record.WhatsAppNumber != nil && (existing.WhatsAppNumber == nil || *existing.WhatsAppNumber != *record.WhatsAppNumber) {
+			fields["whats_app_number"] = *record.WhatsAppNumber
+			entry.Changes = append(entry.Changes, "WhatsApp number")
+		}
+		if existing.RoleID != role.ID {
+			fields["role_id"] = role.ID
+			entry.Changes = append(entry.Changes, "role: "+role.Name)
+		}
+		if newDepartment != "" {
+			entry.Changes = append(entry.Changes, "department: "+newDepartment)
+		} else if (existing.DepartmentID == nil) != (departmentID == nil) ||
+			(departmentID != nil && *existing.DepartmentID != *departmentID) {
+			fields["department_id"] = departmentID
+			if departmentID == nil {
+				entry.Changes = append(entry.Changes, "department removed")
+			} else {
+				entry.Changes = append(entry.Changes, "department: "+record.Department)
+			}
+		}
+		if !existing.Active {
+			fields["active"] = true
+			entry.Changes = append(entry.Changes, "reactivated")
+		}
+
+		if len(entry.Changes) == 0 {
+			entry.Action = HRImportUnchanged
+			plan.Entries = append(plan.Entries, entry)
+			continue
+		}
+
+		entry.Action = HRImportUpdate
+		plan.Entries = append(plan.Entries, entry)
+		plan.Updates = append(plan.Updates, HRUserChange{
+			User:          existing,
+			Fields:        fields,
+			NewDepartment: newDepartment,
+			Entry:         len(plan.Entries) - 1,
+		})
+	}
+
+	plan.Entries = append(plan.Entries, rejected...)
+
+	for i := range users {
+		user := &users[i]
+		if user.EmployeeNumber == nil || !user.Active || matched[user.ID] || user.ID == importerID {
+			continue
+		}
+		id := user.ID
+		plan.Entries = append(plan.Entries, HRImportReportEntry{
+			EmployeeNumber: *user.EmployeeNumber,
+			Email:          user.Email,
+			Name:           user.FirstName + " " + user.LastName,
+			Action:         HRImportDeactivate,
+			Changes:        []string{"not in the latest HR export"},
+			UserID:         &id,
+		})
+		plan.Deactivations = append(plan.Deactivations, HRUserChange{
+			User:   user,
+			Fields: map[string]interface{}{"active": false},
+			Entry:  len(plan.Entries) - 1,
+		})
+	}
+
+	return plan
+}
@@ -14,6 +14,9 @@ import (
 	"go.uber.org/zap"
 )
 
+// InvitationLinkTTL is how long a new user has to accept an invitation link
+const InvitationLinkTTL = 72 * time.Hour
+
 type MagicLinkService struct {
 	redisClient     *redis.Client
 	ctx             context.Context
@@ -72,6 +75,44 @@ func (mls *MagicLinkService) GenerateMagicLink(userID, email string, deviceFinge
 	}, nil
 }
 
+// GenerateInvitationLink creates a magic link for a newly provisioned user. The invitee's device
+// is not known yet, so the link is not bound to a device fingerprint and lasts InvitationLinkTTL.
+func (mls *MagicLinkService) GenerateInvitationLink(userID, email string) (*MagicLink, error) {
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		config.Logger.Error("Failed to generate invitation link token", zap.Error(err))
+		return nil, err
+	}
+	token := base64.URLEncoding.EncodeToString(tokenBytes)
+
+	expiresAt := time.Now().Add(InvitationLinkTTL)
+	magicLinkData := MagicLinkData{
+		UserID:     userID,
+		Email:      email,
+		CreatedAt:  time.Now(),
+		ExpiresAt:  expiresAt,
+		Used:       false,
+		Invitation: true,
+	}
+
+	jsonData, err := json.Marshal(magicLinkData)
+	if err != nil {
+		return nil, err
+	}
+
+	redisKey := "magic_link:" + token
+	if err := mls.redisClient.Set(mls.ctx, redisKey, string(jsonData), InvitationLinkTTL).Err(); err != nil {
+		return nil, err
+	}
+
+	return &MagicLink{
+		Token:           token,
+		URL:             fmt.Sprintf("%s/auth/magic-login?token=%s", mls.frontendBaseURL, token),
+		VerificationURL: fmt.Sprintf("%s/api/v1/auth/magiclink/verify?token=%s", mls.baseURL, token),
+		ExpiresAt:       expiresAt,
+	}, nil
+}
+
 func (mls *MagicLinkService) ValidateMagicLink(token string, deviceFingerprint DeviceFingerprint) (*MagicLinkData, string, error) {
 
 	redisKey := "magic_link:" + token
@@ -94,7 +135,7 @@ func (mls *MagicLinkService) ValidateMagicLink(token string, deviceFingerprint D
 		return nil, "", fmt.Errorf("magic link expired")
 	}
 
-	if !mls.isDeviceFingerprintSimilar(magicLinkData.DeviceFingerprint, deviceFingerprint) {
+	if !magicLinkData.Invitation && !mls.isDeviceFingerprintSimilar(magicLinkData.DeviceFingerprint, deviceFingerprint) {
 		return nil, "", fmt.Errorf("device fingerprint mismatch")
 	}
 
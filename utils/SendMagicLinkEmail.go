@@ -1,18 +1,18 @@
 package utils
 
 import (
+	"context"
 	"fmt"
-	"os"
 	"town-planning-backend/config"
+	email_services "town-planning-backend/emails/services"
 
 	"go.uber.org/zap"
-	"gopkg.in/gomail.v2"
 )
 
 // SendMagicLinkEmail sends a styled magic link email
 func SendMagicLinkEmail(email string, magicLinkURL string, expiresIn string) error {
-	mailer := GetMailer()
-	if mailer == nil {
+	service := email_services.Default()
+	if service == nil {
 		err := fmt.Errorf("mailer is not initialized")
 		config.Logger.Error("Email send failed: mailer is not initialized",
 			zap.String("to_email", email),
@@ -22,21 +22,6 @@ func SendMagicLinkEmail(email string, magicLinkURL string, expiresIn string) err
 		return err
 	}
 
-	from := os.Getenv("SMTP_FROM")
-	if from == "" {
-		err := fmt.Errorf("SMTP_FROM environment variable not set")
-		config.Logger.Error("Email send failed: SMTP_FROM not set",
-			zap.String("to_email", email),
-			zap.Error(err),
-		)
-		return err
-	}
-
-	m := gomail.NewMessage()
-	m.SetHeader("From", from)
-	m.SetHeader("To", email)
-	m.SetHeader("Subject", "Your Magic Link")
-
 	// Plain text version
 	plainText := fmt.Sprintf(
 		"Click the link below to sign in:\n\n%s\n\nThis link expires in %s.\n\nIf you did not request this link, you can safely ignore this email.",
@@ -121,10 +106,12 @@ func SendMagicLinkEmail(email string, magicLinkURL string, expiresIn string) err
 </html>
 `, magicLinkURL, expiresIn)
 
-	m.SetBody("text/plain", plainText)
-	m.AddAlternative("text/html", htmlBody)
-
-	if err := mailer.DialAndSend(m); err != nil {
+	if _, err := service.Send(context.Background(), email_services.Email{
+		To:      email,
+		Subject: "Your Magic Link",
+		Text:    plainText,
+		HTML:    htmlBody,
+	}); err != nil {
 		config.Logger.Error("Failed to send magic link email",
 			zap.String("to_email", email),
 			zap.Error(err),
@@ -132,7 +119,7 @@ func SendMagicLinkEmail(email string, magicLinkURL string, expiresIn string) err
 		return fmt.Errorf("failed to send magic link email: %w", err)
 	}
 
-	config.Logger.Info("Magic link email sent successfully",
+	config.Logger.Info("Magic link email queued successfully",
 		zap.String("to_email", email),
 	)
 	return nil
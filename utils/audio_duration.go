@@ -0,0 +1,422 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// audioFormats maps the audio MIME types accepted for voice notes to their container
+var audioFormats = map[string]string{
+	"audio/webm":    "webm",
+	"audio/ogg":     "ogg",
+	"audio/opus":    "ogg",
+	"audio/mpeg":    "mp3",
+	"audio/mp3":     "mp3",
+	"audio/mp4":     "mp4",
+	"audio/m4a":     "mp4",
+	"audio/x-m4a":   "mp4",
+	"audio/wav":     "wav",
+	"audio/wave":    "wav",
+	"audio/x-wav":   "wav",
+	"audio/vnd.wav": "wav",
+}
+
+// NormalizeAudioMimeType strips parameters such as codecs and lowercases the type
+func NormalizeAudioMimeType(mimeType string) string {
+	if i := strings.Index(mimeType, ";"); i >= 0 {
+		mimeType = mimeType[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(mimeType))
+}
+
+// IsAudioMimeType reports whether the type is a supported voice note format
+func IsAudioMimeType(mimeType string) bool {
+	_, ok := audioFormats[NormalizeAudioMimeType(mimeType)]
+	return ok
+}
+
+// AudioDuration reads the playing time from an audio file's container headers
+func AudioDuration(data []byte, mimeType string) (time.Duration, error) {
+	format, ok := audioFormats[NormalizeAudioMimeType(mimeType)]
+	if !ok {
+		return 0, fmt.Errorf("unsupported audio type: %s", mimeType)
+	}
+
+	var seconds float64
+	var err error
+	switch format {
+	case "wav":
+		seconds, err = wavDuration(data)
+	case "ogg":
+		seconds, err = oggDuration(data)
+	case "mp4":
+		seconds, err = mp4Duration(data)
+	case "webm":
+		seconds, err = webmDuration(data)
+	case "mp3":
+		seconds, err = mp3Duration(data)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if seconds <= 0 || math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+		return 0, errors.New("audio has no playable duration")
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// wavDuration divides the data chunk size by the byte rate from the fmt chunk
+func wavDuration(data []byte) (float64, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return 0, errors.New("invalid WAV file")
+	}
+
+	var byteRate uint32
+	for offset := 12; offset+8 <= len(data); {
+		id := string(data[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		body := offset + 8
+
+		switch id {
+		case "fmt ":
+			if body+12 > len(data) {
+				return 0, errors.New("truncated WAV format chunk")
+			}
+			byteRate = binary.LittleEndian.Uint32(data[body+8 : body+12])
+		case "data":
+			if byteRate == 0 {
+				return 0, errors.New("WAV data chunk before format chunk")
+			}
+			// Recorders that stream WAV may leave the size unset
+			if size == 0 || body+size > len(data) {
+				size = len(data) - body
+			}
+			return float64(size) / float64(byteRate), nil
+		}
+
+		offset = body + size + size%2
+	}
+	return 0, errors.New("WAV file has no data chunk")
+}
+
+// oggDuration reads the granule position of the last page. Opus granules always count
+// 48kHz samples less the pre-skip; Vorbis granules count samples at the stream rate.
+func oggDuration(data []byte) (float64, error) {
+	if len(data) < 27 || string(data[0:4]) != "OggS" {
+		return 0, errors.New("invalid Ogg file")
+	}
+
+	// The first packet starts after the segment table of the first page
+	segments := int(data[26])
+	first := 27 + segments
+	if first > len(data) {
+		return 0, errors.New("truncated Ogg page")
+	}
+	packet := data[first:]
+
+	var rate float64
+	var preSkip uint64
+	switch {
+	case len(packet) >= 12 && string(packet[0:8]) == "OpusHead":
+		rate = 48000
+		preSkip = uint64(binary.LittleEndian.Uint16(packet[10:12]))
+	case len(packet) >= 16 && packet[0] == 1 && string(packet[1:7]) == "vorbis":
+		rate = float64(binary.LittleEndian.Uint32(packet[12:16]))
+	default:
+		return 0, errors.New("unsupported Ogg codec")
+	}
+	if rate == 0 {
+		return 0, errors.New("invalid Ogg sample rate")
+	}
+
+	last := bytes.LastIndex(data, []byte("OggS"))
+	if last < 0 || last+14 > len(data) {
+		return 0, errors.New("truncated Ogg page")
+	}
+	granule := binary.LittleEndian.Uint64(data[last+6 : last+14])
+	if granule == math.MaxUint64 || granule <= preSkip {
+		return 0, errors.New("Ogg stream has no final granule position")
+	}
+	return float64(granule-preSkip) / rate, nil
+}
+
+// mp4Duration reads the timescale and duration from the movie header (moov/mvhd)
+func mp4Duration(data []byte) (float64, error) {
+	moov, err := mp4Box(data, "moov")
+	if err != nil {
+		return 0, err
+	}
+	mvhd, err := mp4Box(moov, "mvhd")
+	if err != nil {
+		return 0, err
+	}
+	if len(mvhd) < 4 {
+		return 0, errors.New("truncated mvhd box")
+	}
+
+	var timescale uint32
+	var duration uint64
+	if mvhd[0] == 1 {
+		if len(mvhd) < 32 {
+			return 0, errors.New("truncated mvhd box")
+		}
+		timescale = binary.BigEndian.Uint32(mvhd[20:24])
+		duration = binary.BigEndian.Uint64(mvhd[24:32])
+	} else {
+		if len(mvhd) < 20 {
+			return 0, errors.New("truncated mvhd box")
+		}
+		timescale = binary.BigEndian.Uint32(mvhd[12:16])
+		duration = uint64(binary.BigEndian.Uint32(mvhd[16:20]))
+	}
+	if timescale == 0 {
+		return 0, errors.New("invalid MP4 timescale")
+	}
+	return float64(duration) / float64(timescale), nil
+}
+
+// mp4Box returns the body of the first box of the given type at this level
+func mp4Box(data []byte, boxType string) ([]byte, error) {
+	for offset := 0; offset+8 <= len(data); {
+		size := uint64(binary.BigEndian.Uint32(data[offset : offset+4]))
+		kind := string(data[offset+4 : offset+8])
+		header := uint64(8)
+
+		switch size {
+		case 0:
+			size = uint64(len(data) - offset)
+		case 1:
+			if offset+16 > len(data) {
+				return nil, errors.New("truncated MP4 box")
+			}
+			size = binary.BigEndian.Uint64(data[offset+8 : offset+16])
+			header = 16
+		}
+		if size < header || uint64(offset)+size > uint64(len(data)) {
+			return nil, errors.New("truncated MP4 box")
+		}
+
+		if kind == boxType {
+			return data[uint64(offset)+header : uint64(offset)+size], nil
+		}
+		offset += int(size)
+	}
+	return nil, fmt.Errorf("MP4 file has no %s box", boxType)
+}
+
+// EBML element IDs used to find a WebM file's duration
+const (
+	ebmlHeaderID     = 0x1A45DFA3
+	ebmlSegmentID    = 0x18538067
+	ebmlInfoID       = 0x1549A966
+	ebmlTimescaleID  = 0x2AD7B1
+	ebmlDurationID   = 0x4489
+	ebmlClusterID    = 0x1F43B675
+	ebmlTimecodeID   = 0xE7
+	ebmlBlockGroupID = 0xA0
+	ebmlBlockID      = 0xA1
+	ebmlSimpleID     = 0xA3
+)
+
+// webmDuration uses the Info duration when present. Browser recorders usually leave it out,
+// so otherwise the duration is the timestamp of the last block.
+func webmDuration(data []byte) (float64, error) {
+	if len(data) < 4 || binary.BigEndian.Uint32(data[0:4]) != ebmlHeaderID {
+		return 0, errors.New("invalid WebM file")
+	}
+
+	timescale := uint64(1000000) // nanoseconds per timecode tick
+	var infoDuration float64
+	var clusterTimecode, lastTimecode int64
+	sawBlock := false
+
+	// Master elements are walked into rather than skipped, which also copes with the
+	// unknown sizes live recorders write for segments and clusters
+	for offset := 0; offset < len(data); {
+		id, idLen, err := ebmlID(data[offset:])
+		if err != nil {
+			break
+		}
+		size, sizeLen, unknown, err := ebmlSize(data[offset+idLen:])
+		if err != nil {
+			break
+		}
+		body := offset + idLen + sizeLen
+		end := len(data)
+		if !unknown && uint64(body)+size <= uint64(len(data)) {
+			end = body + int(size)
+		}
+
+		switch id {
+		case ebmlSegmentID, ebmlInfoID, ebmlClusterID, ebmlBlockGroupID:
+			offset = body
+			continue
+		case ebmlTimescaleID:
+			timescale = ebmlUint(data[body:end])
+		case ebmlDurationID:
+			infoDuration = ebmlFloat(data[body:end])
+		case ebmlTimecodeID:
+			clusterTimecode = int64(ebmlUint(data[body:end]))
+		case ebmlSimpleID, ebmlBlockID:
+			block := data[body:end]
+			if _, trackLen, _, err := ebmlSize(block); err == nil && len(block) >= trackLen+2 {
+				relative := int64(int16(binary.BigEndian.Uint16(block[trackLen : trackLen+2])))
+				if ts := clusterTimecode + relative; ts > lastTimecode || !sawBlock {
+					lastTimecode = ts
+				}
+				sawBlock = true
+			}
+		}
+
+		if unknown {
+			break
+		}
+		offset = end
+	}
+
+	if infoDuration > 0 {
+		return infoDuration * float64(timescale) / float64(time.Second), nil
+	}
+	if sawBlock && lastTimecode > 0 {
+		return float64(lastTimecode) * float64(timescale) / float64(time.Second), nil
+	}
+	return 0, errors.New("WebM file has no duration")
+}
+
+// ebmlID reads an element ID, keeping its length marker bits
+func ebmlID(data []byte) (uint64, int, error) {
+	if len(data) == 0 || data[0] == 0 {
+		return 0, 0, errors.New("invalid EBML id")
+	}
+	length := 1
+	for mask := byte(0x80); data[0]&mask == 0; mask >>= 1 {
+		length++
+	}
+	if length > 4 || length > len(data) {
+		return 0, 0, errors.New("invalid EBML id")
+	}
+	var id uint64
+	for _, b := range data[:length] {
+		id = id<<8 | uint64(b)
+	}
+	return id, length, nil
+}
+
+// ebmlSize reads a variable length size. unknown is set when every value bit is one.
+func ebmlSize(data []byte) (uint64, int, bool, error) {
+	if len(data) == 0 || data[0] == 0 {
+		return 0, 0, false, errors.New("invalid EBML size")
+	}
+	length := 1
+	mask := byte(0x80)
+	for ; data[0]&mask == 0; mask >>= 1 {
+		length++
+	}
+	if length > 8 || length > len(data) {
+		return 0, 0, false, errors.New("invalid EBML size")
+	}
+	value := uint64(data[0] & (mask - 1))
+	allOnes := data[0]&(mask-1) == mask-1
+	for _, b := range data[1:length] {
+		value = value<<8 | uint64(b)
+		allOnes = allOnes && b == 0xFF
+	}
+	return value, length, allOnes, nil
+}
+
+func ebmlUint(data []byte) uint64 {
+	var value uint64
+	for _, b := range data {
+		value = value<<8 | uint64(b)
+	}
+	return value
+}
+
+func ebmlFloat(data []byte) float64 {
+	switch len(data) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(data)))
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(data))
+	}
+	return 0
+}
+
+// Layer III bitrates in kbps by bitrate index
+var (
+	mp3BitratesV1 = [15]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320}
+	mp3BitratesV2 = [15]int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160}
+)
+
+// mp3Duration uses the frame count from a Xing/Info or VBRI header, falling back to the
+// first frame's bitrate for constant bitrate files
+func mp3Duration(data []byte) (float64, error) {
+	offset := 0
+	if len(data) >= 10 && string(data[0:3]) == "ID3" {
+		tagSize := int(data[6]&0x7F)<<21 | int(data[7]&0x7F)<<14 | int(data[8]&0x7F)<<7 | int(data[9]&0x7F)
+		offset = 10 + tagSize
+		if data[5]&0x10 != 0 {
+			offset += 10 // footer
+		}
+	}
+
+	for ; offset+4 <= len(data); offset++ {
+		if data[offset] != 0xFF || data[offset+1]&0xE0 != 0xE0 {
+			continue
+		}
+		header := binary.BigEndian.Uint32(data[offset : offset+4])
+		version := (header >> 19) & 0x3 // 0: MPEG 2.5, 2: MPEG 2, 3: MPEG 1
+		layer := (header >> 17) & 0x3   // 1: Layer III
+		bitrateIndex := (header >> 12) & 0xF
+		rateIndex := (header >> 10) & 0x3
+		mono := (header>>6)&0x3 == 3
+		if version == 1 || layer != 1 || bitrateIndex == 0 || bitrateIndex == 15 || rateIndex == 3 {
+			continue
+		}
+
+		sampleRate := [3]int{44100, 48000, 32000}[rateIndex]
+		bitrate := mp3BitratesV1[bitrateIndex]
+		samplesPerFrame := 1152
+		sideInfo := 32
+		if mono {
+			sideInfo = 17
+		}
+		if version != 3 {
+			sampleRate /= 2
+			if version == 0 {
+				sampleRate /= 2
+			}
+			bitrate = mp3BitratesV2[bitrateIndex]
+			samplesPerFrame = 576
+			sideInfo = 17
+			if mono {
+				sideInfo = 9
+			}
+		}
+
+		frame := data[offset:]
+		if xing := 4 + sideInfo; len(frame) >= xing+12 {
+			tag := string(frame[xing : xing+4])
+			if (tag == "Xing" || tag == "Info") && binary.BigEndian.Uint32(frame[xing+4:xing+8])&1 != 0 {
+				frames := binary.BigEndian.Uint32(frame[xing+8 : xing+12])
+				return float64(frames) * float64(samplesPerFrame) / float64(sampleRate), nil
+			}
+		}
+		if len(frame) >= 36+18 && string(frame[36:40]) == "VBRI" {
+			frames := binary.BigEndian.Uint32(frame[36+14 : 36+18])
+			return float64(frames) * float64(samplesPerFrame) / float64(sampleRate), nil
+		}
+
+		audioBytes := len(data) - offset
+		if len(data) >= 128 && string(data[len(data)-128:len(data)-125]) == "TAG" {
+			audioBytes -= 128
+		}
+		return float64(audioBytes) * 8 / float64(bitrate*1000), nil
+	}
+	return 0, errors.New("no MP3 frame found")
+}
@@ -77,8 +77,9 @@ func CleanupAllExpired(fileTTL time.Duration, redisClient *redis.Client) error {
 	return nil
 }
 
-// RunScheduledCleanup runs cleanup tasks daily at 1 AM with retries and logs error messages to console on failure
-func RunScheduledCleanup(redisClient *redis.Client) {
+// RunScheduledCleanup runs cleanup tasks daily at 1 AM with retries and logs error messages to console on failure.
+// It returns once ctx is cancelled and any cleanup under way has finished.
+func RunScheduledCleanup(ctx context.Context, redisClient *redis.Client) {
 	// Create a new cron job scheduler
 	c := cron.New()
 
@@ -100,7 +101,14 @@ func RunScheduledCleanup(redisClient *redis.Client) {
 			} else {
 				log.Printf("cleanup failed: %v", err)
 				retries++
-				time.Sleep(retryDelay) // Wait before retrying
+
+				// Wait before retrying, unless the server is shutting down
+				select {
+				case <-time.After(retryDelay):
+				case <-ctx.Done():
+					log.Println("cleanup abandoned for shutdown")
+					return
+				}
 			}
 		}
 
@@ -122,8 +130,9 @@ func RunScheduledCleanup(redisClient *redis.Client) {
 	// Start the cron scheduler
 	c.Start()
 
-	// Keep the main function running to let cron jobs execute
-	select {}
+	// Keep running until shutdown, then wait for a running cleanup to finish
+	<-ctx.Done()
+	<-c.Stop().Done()
 }
 
 func CleanBankPaymentDate(dateStr string) (string, error) {
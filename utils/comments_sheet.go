@@ -72,7 +72,7 @@ func GenerateCommentsSheet(application models.Application, finalComments []Final
 	}
 
 	// Generate HTML content
-	htmlContent, err := generateHTMLCommentsSheet(sheetData)
+	htmlContent, err := generateHTMLCommentsSheet(sheetData, application.Applicant.PreferredLanguage)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate HTML comments sheet: %v", err)
 	}
@@ -220,7 +220,7 @@ func prepareCommentsSheetData(application models.Application, finalComments []Fi
 		PrintDate:           printDateFormatted,
 		PlanNumber:          application.PlanNumber,
 		StandNumber:         getStandNumber(application),
-		DeveloperName:       strings.ToUpper(application.Applicant.FullName),
+		DeveloperName:       strings.ToUpper(application.ApplicantDisplayName()),
 		DevelopmentPermitNo: application.PermitNumber,
 		StandUse:            getStandUse(application),
 		ValueSubmitted:      valueSubmitted,
@@ -281,9 +281,10 @@ func getLocation(application models.Application) string {
 	return "Unknown"
 }
 
-// generateHTMLCommentsSheet generates HTML from template
-func generateHTMLCommentsSheet(data CommentsSheetData) (string, error) {
-	tmpl, err := template.ParseFiles("templates/comments-sheet.html")
+// generateHTMLCommentsSheet generates HTML from the template in the applicant's language
+func generateHTMLCommentsSheet(data CommentsSheetData, language string) (string, error) {
+	templatePath, _ := ResolveTemplateFile("comments-sheet.html", language)
+	tmpl, err := template.ParseFiles(templatePath)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse comments sheet template: %v", err)
 	}
@@ -0,0 +1,288 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// CommitteePackSource is what a committee pack is printed from. Documents are the key
+// documents in print order; image documents carry their content as a data URI.
+type CommitteePackSource struct {
+	Application    models.Application
+	Assignments    []models.ApplicationGroupAssignment
+	Decisions      []models.MemberApprovalDecision
+	FinalApprovals []models.FinalApproval
+	Issues         []models.ApplicationIssue
+	Documents      []CommitteePackDocument
+	PreparedBy     string
+}
+
+// CommitteePackDocument is a key document of the pack. Documents without an image are listed
+// but not reproduced.
+type CommitteePackDocument struct {
+	Document models.Document
+	Image    template.URL
+}
+
+// CommitteePackData holds all data for the committee pack template
+type CommitteePackData struct {
+	LogoBase64     string
+	PrintDate      string
+	PreparedBy     string
+	PlanNumber     string
+	PermitNumber   string
+	Status         string
+	ApplicantName  string
+	StandNumber    string
+	StandUse       string
+	Category       string
+	EstimatedCost  string
+	TotalCost      string
+	PaymentStatus  string
+	RiskLevel      string
+	DateSubmitted  string
+	Architect      string
+	Groups         []CommitteePackGroupRow
+	Decisions      []CommitteePackDecisionRow
+	FinalDecisions []CommitteePackDecisionRow
+	OpenIssues     []CommitteePackIssueRow
+	Resolved       []CommitteePackIssueRow
+	Documents      []CommitteePackDocumentRow
+}
+
+type CommitteePackGroupRow struct {
+	Name       string
+	AssignedAt string
+	Progress   string
+	Completed  string
+}
+
+type CommitteePackDecisionRow struct {
+	Group    string
+	Reviewer string
+	Role     string
+	Status   string
+	Date     string
+	Comments []string
+}
+
+type CommitteePackIssueRow struct {
+	Title       string
+	Priority    string
+	Description string
+	RaisedBy    string
+	RaisedAt    string
+	AssignedTo  string
+	ResolvedBy  string
+	ResolvedAt  string
+	Resolution  string
+}
+
+type CommitteePackDocumentRow struct {
+	Category string
+	FileName string
+	Version  int
+	FileHash string
+	Image    template.URL
+	Note     string
+}
+
+// GenerateCommitteePack renders the committee pack as a single A4 PDF
+func GenerateCommitteePack(source CommitteePackSource, w io.Writer) error {
+	data := prepareCommitteePackData(source)
+
+	tmpl, err := template.ParseFiles("templates/committee-pack.html")
+	if err != nil {
+		return fmt.Errorf("failed to parse committee pack template: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("failed to execute committee pack template: %v", err)
+	}
+
+	if err := GenerateA4PDFFromHTML(buf.String(), data.LogoBase64, w); err != nil {
+		return fmt.Errorf("failed to generate PDF: %v", err)
+	}
+	return nil
+}
+
+// CommitteePackDocumentNote explains why a key document is listed without being reproduced
+func CommitteePackDocumentNote(document models.Document) string {
+	if document.MimeType == "application/pdf" {
+		return "PDF document, not reproduced. See the application file."
+	}
+	return "Not reproduced. See the application file."
+}
+
+// prepareCommitteePackData prepares the data structure for the template
+func prepareCommitteePackData(source CommitteePackSource) CommitteePackData {
+	logoBase64, err := loadMunicipalityLogo()
+	if err != nil {
+		config.Logger.Warn("Failed to load logo, using placeholder", zap.Error(err))
+		logoBase64 = createMunicipalityPlaceholderLogo()
+	}
+
+	application := source.Application
+	data := CommitteePackData{
+		LogoBase64:    logoBase64,
+		PrintDate:     formatDateFull(time.Now()),
+		PreparedBy:    source.PreparedBy,
+		PlanNumber:    application.PlanNumber,
+		PermitNumber:  committeePackText(application.PermitNumber),
+		Status:        strings.ReplaceAll(string(application.Status), "_", " "),
+		ApplicantName: strings.ToUpper(application.ApplicantDisplayName()),
+		StandNumber:   "N/A",
+		StandUse:      "N/A",
+		Category:      "N/A",
+		EstimatedCost: committeePackAmount(application.EstimatedCost),
+		TotalCost:     committeePackAmount(application.TotalCost),
+		PaymentStatus: string(application.PaymentStatus),
+		RiskLevel:     "Not assessed",
+		DateSubmitted: formatDateFull(application.SubmissionDate),
+		Architect:     "N/A",
+	}
+	if application.Stand != nil {
+		data.StandNumber = committeePackText(application.Stand.StandNumber)
+		if application.Stand.StandType != nil && application.Stand.StandType.Name != "" {
+			data.StandUse = strings.ToUpper(application.Stand.StandType.Name)
+		}
+	}
+	if application.Tariff != nil && application.Tariff.DevelopmentCategory.Name != "" {
+		data.Category = application.Tariff.DevelopmentCategory.Name
+	}
+	if application.RiskLevel != nil {
+		data.RiskLevel = string(*application.RiskLevel)
+		if application.RiskScore != nil {
+			data.RiskLevel = fmt.Sprintf("%s (score %d)", data.RiskLevel, *application.RiskScore)
+		}
+	}
+	if application.ArchitectFullName != nil && *application.ArchitectFullName != "" {
+		data.Architect = *application.ArchitectFullName
+	}
+
+	for _, assignment := range source.Assignments {
+		row := CommitteePackGroupRow{
+			Name:       assignment.Group.Name,
+			AssignedAt: formatDateFull(assignment.AssignedAt),
+			Progress: fmt.Sprintf("%d approved, %d rejected, %d pending",
+				assignment.ApprovedCount, assignment.RejectedCount, assignment.PendingCount),
+			Completed: "In progress",
+		}
+		if assignment.CompletedAt != nil {
+			row.Completed = formatDateFull(*assignment.CompletedAt)
+		}
+		data.Groups = append(data.Groups, row)
+	}
+
+	for _, decision := range source.Decisions {
+		row := CommitteePackDecisionRow{
+			Group:    decision.Assignment.Group.Name,
+			Reviewer: decision.User.FirstName + " " + decision.User.LastName,
+			Role:     string(decision.AssignedAs),
+			Status:   string(decision.Status),
+			Date:     "Pending",
+		}
+		if decision.IsFinalApproverDecision {
+			row.Role = "FINAL APPROVER"
+		}
+		if decision.DecidedAt != nil {
+			row.Date = formatDateFull(*decision.DecidedAt)
+		}
+		if decision.WasRevoked && decision.RevokedReason != nil {
+			row.Status = fmt.Sprintf("%s (revoked: %s)", row.Status, *decision.RevokedReason)
+		}
+		for _, comment := range decision.Comments {
+			row.Comments = append(row.Comments, comment.Content)
+		}
+		data.Decisions = append(data.Decisions, row)
+	}
+
+	for _, approval := range source.FinalApprovals {
+		row := CommitteePackDecisionRow{
+			Reviewer: approval.Approver.FirstName + " " + approval.Approver.LastName,
+			Role:     "FINAL APPROVER",
+			Status:   string(approval.Decision),
+			Date:     formatDateFull(approval.DecisionAt),
+		}
+		if approval.IsSystemAutoDecision {
+			row.Reviewer = "System"
+		}
+		if approval.Comment != nil && *approval.Comment != "" {
+			row.Comments = append(row.Comments, *approval.Comment)
+		}
+		if approval.OverrodeGroupDecision && approval.OverrideReason != nil {
+			row.Comments = append(row.Comments, "Overrode the group decision: "+*approval.OverrideReason)
+		}
+		data.FinalDecisions = append(data.FinalDecisions, row)
+	}
+
+	for _, issue := range source.Issues {
+		row := CommitteePackIssueRow{
+			Title:       issue.Title,
+			Priority:    issue.Priority,
+			Description: issue.Description,
+			RaisedBy:    issue.RaisedByUser.FirstName + " " + issue.RaisedByUser.LastName,
+			RaisedAt:    formatDateFull(issue.CreatedAt),
+			AssignedTo:  "Any group member",
+		}
+		if issue.AssignedToUser != nil {
+			row.AssignedTo = issue.AssignedToUser.FirstName + " " + issue.AssignedToUser.LastName
+		}
+		if !issue.IsResolved {
+			data.OpenIssues = append(data.OpenIssues, row)
+			continue
+		}
+		if issue.ResolvedByUser != nil {
+			row.ResolvedBy = issue.ResolvedByUser.FirstName + " " + issue.ResolvedByUser.LastName
+		}
+		if issue.ResolvedAt != nil {
+			row.ResolvedAt = formatDateFull(*issue.ResolvedAt)
+		}
+		if issue.Resolution != nil {
+			row.Resolution = *issue.Resolution
+		}
+		data.Resolved = append(data.Resolved, row)
+	}
+
+	for _, document := range source.Documents {
+		row := CommitteePackDocumentRow{
+			FileName: document.Document.FileName,
+			Version:  document.Document.Version,
+			FileHash: document.Document.FileHash,
+			Image:    document.Image,
+		}
+		if document.Document.Category != nil {
+			row.Category = document.Document.Category.Name
+		}
+		if document.Image == "" {
+			row.Note = CommitteePackDocumentNote(document.Document)
+		}
+		data.Documents = append(data.Documents, row)
+	}
+
+	return data
+}
+
+func committeePackText(value string) string {
+	if value == "" {
+		return "N/A"
+	}
+	return value
+}
+
+func committeePackAmount(amount *decimal.Decimal) string {
+	if amount == nil {
+		return "N/A"
+	}
+	return "$ " + amount.StringFixed(2)
+}
@@ -52,7 +52,7 @@ func GenerateDevelopmentPermitQuotation(application models.Application, filename
 	}
 
 	// Generate HTML content
-	htmlContent, err := generateHTMLQuotation(quotationData)
+	htmlContent, err := generateHTMLQuotation(quotationData, application.Applicant.PreferredLanguage)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate HTML quotation: %v", err)
 	}
@@ -100,11 +100,8 @@ func prepareQuotationData(application models.Application) (QuotationData, error)
 
 // Helper functions to extract data from application
 func getDeveloperName(application models.Application) string {
-	if application.Applicant.FullName != "" {
-		return application.Applicant.FullName
-	}
-	if application.Applicant.FullName != "" {
-		return application.Applicant.FullName
+	if name := application.ApplicantDisplayName(); name != "" {
+		return name
 	}
 	return "Unknown Developer"
 }
@@ -132,10 +129,11 @@ func getDevelopmentType(application models.Application) string {
 	return "COMMERCIAL"
 }
 
-// generateHTMLQuotation generates HTML content from the quotation template
-func generateHTMLQuotation(data QuotationData) (string, error) {
+// generateHTMLQuotation generates HTML content from the quotation template in the applicant's language
+func generateHTMLQuotation(data QuotationData, language string) (string, error) {
 	// Parse template file
-	tmpl, err := template.ParseFiles("templates/development-application-quotation.html")
+	templatePath, _ := ResolveTemplateFile("development-application-quotation.html", language)
+	tmpl, err := template.ParseFiles(templatePath)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse quotation template: %v", err)
 	}
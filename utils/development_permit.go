@@ -47,11 +47,17 @@ type DevelopmentPermitData struct {
 	ArchitectEmail       string
 	ArchitectPhone       string
 	Conditions           []string
+	PlanningSchemes      []string // Scheme versions the application was assessed under
 	GeneratedByName      string
 	GeneratedByTitle     string
 	GeneratedBySignature string
 	StampSpace           bool
 	LegalNotice          string
+
+	// Set on a permit re-issued for a minor amendment
+	AmendmentNumber      int
+	AmendmentDate        string
+	VariationDescription string
 }
 
 // GenerateDevelopmentPermit generates a PDF development permit for the application
@@ -62,8 +68,31 @@ func GenerateDevelopmentPermit(application models.Application, finalApproval mod
 		return "", fmt.Errorf("failed to prepare development permit data: %v", err)
 	}
 
+	return renderDevelopmentPermit(permitData, application.Applicant.PreferredLanguage, filename)
+}
+
+// GenerateAmendedDevelopmentPermit re-issues the development permit with a note of an approved
+// minor amendment
+func GenerateAmendedDevelopmentPermit(application models.Application, finalApproval models.FinalApproval, amendment models.ApplicationAmendment, filename string, user *models.User) (string, error) {
+	permitData, err := prepareDevelopmentPermitData(application, finalApproval, user)
+	if err != nil {
+		return "", fmt.Errorf("failed to prepare development permit data: %v", err)
+	}
+
+	amendedAt := time.Now()
+	if amendment.ReviewedAt != nil {
+		amendedAt = *amendment.ReviewedAt
+	}
+	permitData.AmendmentNumber = amendment.AmendmentNumber
+	permitData.AmendmentDate = formatDateFull(amendedAt)
+	permitData.VariationDescription = amendment.Description
+
+	return renderDevelopmentPermit(permitData, application.Applicant.PreferredLanguage, filename)
+}
+
+func renderDevelopmentPermit(permitData DevelopmentPermitData, language string, filename string) (string, error) {
 	// Generate HTML content
-	htmlContent, err := generateHTMLDevelopmentPermit(permitData)
+	htmlContent, err := generateHTMLDevelopmentPermit(permitData, language)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate HTML development permit: %v", err)
 	}
@@ -100,6 +129,12 @@ func prepareDevelopmentPermitData(application models.Application, finalApproval
 	// Get development conditions based on category
 	conditions := getDevelopmentConditions(application)
 
+	// Cite the planning scheme versions recorded with the decision
+	var planningSchemes []string
+	for _, version := range finalApproval.PlanningSchemeVersions {
+		planningSchemes = append(planningSchemes, formatPlanningSchemeReference(version))
+	}
+
 	// Format dates
 	permitGenerationDate := formatDateFull(time.Now())
 
@@ -179,7 +214,7 @@ func prepareDevelopmentPermitData(application models.Application, finalApproval
 		PrintDate:            formatDateFull(time.Now()),
 		PlanNumber:           application.PlanNumber,
 		StandNumber:          getStandNumber(application),
-		ApplicantName:        strings.ToUpper(application.Applicant.FullName),
+		ApplicantName:        strings.ToUpper(application.ApplicantDisplayName()),
 		ApplicantAddress:     applicantAddress,
 		ApplicantCity:        applicantCity,
 		PermitNumber:         application.PermitNumber,
@@ -196,6 +231,7 @@ func prepareDevelopmentPermitData(application models.Application, finalApproval
 		ArchitectEmail:       architectEmail,
 		ArchitectPhone:       architectPhone,
 		Conditions:           conditions,
+		PlanningSchemes:      planningSchemes,
 		GeneratedByName:      fmt.Sprintf("%s %s", user.FirstName, user.LastName),
 		GeneratedByTitle:     getUserTitle(user),
 		GeneratedBySignature: userSignature,
@@ -355,8 +391,22 @@ func getLegalNotice() string {
 THIS PERMIT DOES NOT CONSTITUTE APPROVAL IN TERMS OF ANY MUNICIPALITY BYE-LAWS`
 }
 
-// generateHTMLDevelopmentPermit generates HTML from template
-func generateHTMLDevelopmentPermit(data DevelopmentPermitData) (string, error) {
+// formatPlanningSchemeReference cites a scheme version, e.g. "Redcliff Local Plan, version 2
+// (in force from 1 July 2024)"
+func formatPlanningSchemeReference(version models.PlanningSchemeVersion) string {
+	name := version.Title
+	if version.Scheme != nil {
+		name = version.Scheme.Name
+	}
+	reference := fmt.Sprintf("%s, version %d (in force from %s)", name, version.VersionNumber, formatDateFull(version.EffectiveFrom))
+	if version.GazetteNotice != nil && *version.GazetteNotice != "" {
+		reference += ", " + *version.GazetteNotice
+	}
+	return reference
+}
+
+// generateHTMLDevelopmentPermit generates HTML from the template in the applicant's language
+func generateHTMLDevelopmentPermit(data DevelopmentPermitData, language string) (string, error) {
 	// Create a custom template function map
 	funcMap := template.FuncMap{
 		"add1": func(i int) int {
@@ -365,7 +415,8 @@ func generateHTMLDevelopmentPermit(data DevelopmentPermitData) (string, error) {
 	}
 
 	// Parse template with custom functions
-	tmpl, err := template.New("development-permit.html").Funcs(funcMap).ParseFiles("templates/development-permit.html")
+	templatePath, _ := ResolveTemplateFile("development-permit.html", language)
+	tmpl, err := template.New("development-permit.html").Funcs(funcMap).ParseFiles(templatePath)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse development permit template: %v", err)
 	}
@@ -0,0 +1,252 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"text/template"
+)
+
+// EmailTemplate is the subject and plain-text body of an email in one language
+type EmailTemplate struct {
+	Subject string
+	Body    string
+}
+
+// Emails sent to applicants
+const (
+	EmailCollectionConfirmation = "collection-confirmation"
+	EmailPermitStatusChange     = "permit-status-change"
+	EmailPortalSignIn           = "portal-sign-in"
+	EmailPortalNewMessage       = "portal-new-message"
+	EmailDocumentRequest        = "document-request"
+	EmailPreScreeningReturned   = "pre-screening-returned"
+)
+
+// CollectionConfirmationEmail fills the collection confirmation email. Location is empty when
+// the department has no office location on record.
+type CollectionConfirmationEmail struct {
+	ApplicantName  string
+	PlanNumber     string
+	DepartmentName string
+	Location       string
+	LongDate       string // e.g. Monday 2 January 2006, for English
+	ShortDate      string // e.g. 02/01/2006, for languages without English day and month names
+	StartTime      string
+	EndTime        string
+}
+
+// PermitStatusChangeEmail fills the email sent when a permit is suspended, reinstated or revoked.
+// Status is the new status in words, e.g. "suspended".
+type PermitStatusChangeEmail struct {
+	ApplicantName string
+	PermitNumber  string
+	PlanNumber    string
+	Status        string
+	Reason        string
+	ChangedOn     string
+}
+
+// PortalSignInEmail fills the email carrying an applicant's one-time portal sign-in link
+type PortalSignInEmail struct {
+	ApplicantName string
+	SignInURL     string
+	ExpiresIn     string // e.g. "15 minutes"
+}
+
+// PortalNewMessageEmail fills the email sent when staff reply to an applicant on the portal
+type PortalNewMessageEmail struct {
+	ApplicantName string
+	PlanNumber    string
+	StaffName     string
+	PortalURL     string
+}
+
+// DocumentRequestEmail fills the request for checklist documents missing from an application.
+// Each item carries its own portal upload link.
+type DocumentRequestEmail struct {
+	ApplicantName string
+	PlanNumber    string
+	Items         []DocumentRequestItem
+	Note          string // Optional message from staff
+	ExpiresIn     string // e.g. "7 days"
+}
+
+// DocumentRequestItem is one outstanding document and the link to upload it with
+type DocumentRequestItem struct {
+	Label     string
+	UploadURL string
+}
+
+// PreScreeningReturnedEmail fills the email sent when pre-screening returns an application to
+// the applicant. Reasons are the standard reasons in words.
+type PreScreeningReturnedEmail struct {
+	ApplicantName string
+	PlanNumber    string
+	Reasons       []string
+	Note          string // Optional message from the technician
+}
+
+// emailTemplates holds every applicant email by name and language. English is required for
+// each email; other languages fall back to it.
+var emailTemplates = map[string]map[string]EmailTemplate{
+	EmailCollectionConfirmation: {
+		TemplateLanguageEnglish: {
+			Subject: "Permit collection booked: {{.PlanNumber}}",
+			Body:    "Dear {{.ApplicantName}},\n\nYour collection of the permit for plan {{.PlanNumber}} is booked with {{.DepartmentName}}{{if .Location}} at {{.Location}}{{end}} on {{.LongDate}}, between {{.StartTime}} and {{.EndTime}}.\n\nPlease bring your identity document.",
+		},
+		TemplateLanguageShona: {
+			Subject: "Kutora pemiti kwarongwa: {{.PlanNumber}}",
+			Body:    "Mhoro {{.ApplicantName}},\n\nKutora kwenyu pemiti yeplan {{.PlanNumber}} kwarongwa ne{{.DepartmentName}}{{if .Location}} ku{{.Location}}{{end}} musi wa{{.ShortDate}}, pakati pa{{.StartTime}} na{{.EndTime}}.\n\nMunokumbirwa kuuya negwaro renyu rekuzivikanwa.",
+		},
+		TemplateLanguageNdebele: {
+			Subject: "Ukulanda imvumo kubhukiwe: {{.PlanNumber}}",
+			Body:    "Sawubona {{.ApplicantName}},\n\nUkulanda imvumo yeplani {{.PlanNumber}} kubhukiwe ku-{{.DepartmentName}}{{if .Location}} e-{{.Location}}{{end}} ngomhla ka-{{.ShortDate}}, phakathi kuka-{{.StartTime}} lo-{{.EndTime}}.\n\nSicela ulethe incwadi yakho yesazisi.",
+		},
+	},
+	EmailPermitStatusChange: {
+		TemplateLanguageEnglish: {
+			Subject: "Permit {{.PermitNumber}} has been {{.Status}}",
+			Body:    "Dear {{.ApplicantName}},\n\nThe development permit {{.PermitNumber}} for plan {{.PlanNumber}} was {{.Status}} on {{.ChangedOn}}.\n\nReason: {{.Reason}}\n\nPlease contact the Town Planning office if you have any questions.",
+		},
+	},
+	EmailPortalSignIn: {
+		TemplateLanguageEnglish: {
+			Subject: "Your sign-in link for the applicant portal",
+			Body:    "Dear {{.ApplicantName}},\n\nUse the link below to sign in and follow your applications. It can be used once and expires in {{.ExpiresIn}}.\n\n{{.SignInURL}}\n\nIf you did not ask to sign in, you can ignore this email.",
+		},
+	},
+	EmailPortalNewMessage: {
+		TemplateLanguageEnglish: {
+			Subject: "New message about plan {{.PlanNumber}}",
+			Body:    "Dear {{.ApplicantName}},\n\n{{.StaffName}} has sent you a message about plan {{.PlanNumber}}. Sign in to the applicant portal to read it and reply.\n\n{{.PortalURL}}",
+		},
+	},
+	EmailDocumentRequest: {
+		TemplateLanguageEnglish: {
+			Subject: "Documents needed for plan {{.PlanNumber}}",
+			Body:    "Dear {{.ApplicantName}},\n\nYour application for plan {{.PlanNumber}} cannot be reviewed until we receive the documents below. Use the link next to each document to upload it on the applicant portal.\n{{range .Items}}\n- {{.Label}}: {{.UploadURL}}{{end}}\n{{if .Note}}\n{{.Note}}\n{{end}}\nEach link signs you in once and expires in {{.ExpiresIn}}; after that you can request a new sign-in link on the portal.",
+		},
+	},
+	EmailPreScreeningReturned: {
+		TemplateLanguageEnglish: {
+			Subject: "Plan {{.PlanNumber}} was returned to you",
+			Body:    "Dear {{.ApplicantName}},\n\nYour application for plan {{.PlanNumber}} was checked before review and cannot go forward yet:\n{{range .Reasons}}\n- {{.}}{{end}}\n{{if .Note}}\n{{.Note}}\n{{end}}\nPlease correct these and resubmit the application at the Town Planning office.",
+		},
+	},
+}
+
+// emailPreviewData is the sample data admins see when previewing an email
+var emailPreviewData = map[string]interface{}{
+	EmailCollectionConfirmation: CollectionConfirmationEmail{
+		ApplicantName:  "Tendai Moyo",
+		PlanNumber:     "PLN-2025-0001",
+		DepartmentName: "Town Planning",
+		Location:       "Civic Centre, Room 12",
+		LongDate:       "Monday 3 March 2025",
+		ShortDate:      "03/03/2025",
+		StartTime:      "09:00",
+		EndTime:        "09:30",
+	},
+	EmailPermitStatusChange: PermitStatusChangeEmail{
+		ApplicantName: "Tendai Moyo",
+		PermitNumber:  "PERMIT/2025/03/001",
+		PlanNumber:    "PLN-2025-0001",
+		Status:        "suspended",
+		Reason:        "Construction does not follow the approved plans",
+		ChangedOn:     "Monday 3 March 2025",
+	},
+	EmailPortalSignIn: PortalSignInEmail{
+		ApplicantName: "Tendai Moyo",
+		SignInURL:     "https://planning.example.com/portal/sign-in?token=sample",
+		ExpiresIn:     "15 minutes",
+	},
+	EmailPortalNewMessage: PortalNewMessageEmail{
+		ApplicantName: "Tendai Moyo",
+		PlanNumber:    "PLN-2025-0001",
+		StaffName:     "Rudo Chikwanha",
+		PortalURL:     "https://planning.example.com/portal",
+	},
+	EmailDocumentRequest: DocumentRequestEmail{
+		ApplicantName: "Tendai Moyo",
+		PlanNumber:    "PLN-2025-0001",
+		Items: []DocumentRequestItem{
+			{Label: "TPD-1 form", UploadURL: "https://planning.example.com/portal/sign-in?token=sample"},
+			{Label: "Processed quotation", UploadURL: "https://planning.example.com/portal/sign-in?token=sample"},
+		},
+		Note:      "Please make sure the TPD-1 form is signed.",
+		ExpiresIn: "7 days",
+	},
+	EmailPreScreeningReturned: PreScreeningReturnedEmail{
+		ApplicantName: "Tendai Moyo",
+		PlanNumber:    "PLN-2025-0001",
+		Reasons:       []string{"The plans submitted cannot be read clearly", "The application fees have not been paid"},
+		Note:          "Please scan the floor plans at a higher resolution.",
+	},
+}
+
+// EmailTemplateNames lists the applicant emails, sorted by name
+func EmailTemplateNames() []string {
+	names := make([]string, 0, len(emailTemplates))
+	for name := range emailTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// EmailTemplateLanguages lists the languages an email has been translated into
+func EmailTemplateLanguages(name string) []string {
+	languages := []string{}
+	for _, language := range TemplateLanguages {
+		if _, ok := emailTemplates[name][language]; ok {
+			languages = append(languages, language)
+		}
+	}
+	return languages
+}
+
+// RenderEmailTemplate renders an email in the recipient's language, falling back to English
+// when it has not been translated. It returns the subject, body and the language used.
+func RenderEmailTemplate(name string, language string, data interface{}) (string, string, string, error) {
+	variants, ok := emailTemplates[name]
+	if !ok {
+		return "", "", "", errors.New("template not found")
+	}
+
+	language = NormalizeTemplateLanguage(language)
+	variant, ok := variants[language]
+	if !ok {
+		language = DefaultTemplateLanguage
+		variant = variants[language]
+	}
+
+	subject, err := executeEmailTemplate(name+".subject", variant.Subject, data)
+	if err != nil {
+		return "", "", "", err
+	}
+	body, err := executeEmailTemplate(name+".body", variant.Body, data)
+	if err != nil {
+		return "", "", "", err
+	}
+	return subject, body, language, nil
+}
+
+// PreviewEmailTemplate renders an email with sample data
+func PreviewEmailTemplate(name string, language string) (string, string, string, error) {
+	return RenderEmailTemplate(name, language, emailPreviewData[name])
+}
+
+func executeEmailTemplate(name string, text string, data interface{}) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse email template %s: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute email template %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
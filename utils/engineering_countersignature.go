@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"town-planning-backend/config"
+	"town-planning-backend/db/models"
+
+	"go.uber.org/zap"
+)
+
+// CountersignatureSheetData holds all data for the countersignature template
+type CountersignatureSheetData struct {
+	LogoBase64          string
+	PlanNumber          string
+	ApplicantName       string
+	CertificateFileName string
+	CertificateVersion  int
+	CertificateHash     string
+	RoutedByName        string
+	RoutedAt            string
+	EngineerName        string
+	SignedAt            string
+	ReviewComment       string
+	SignatureHash       string
+	SignatureBase64     template.URL // Data URI, typed so the template keeps it
+}
+
+// GenerateCountersignatureSheet renders the countersignature page for a signed engineering
+// certificate and saves it as a PDF. The countersignature must have its Application, Document,
+// Engineer and RoutedBy loaded.
+func GenerateCountersignatureSheet(countersignature *models.CertificateCountersignature, filename string) (string, error) {
+	sheetData := prepareCountersignatureSheetData(countersignature)
+
+	tmpl, err := template.ParseFiles("templates/engineering-countersignature.html")
+	if err != nil {
+		return "", fmt.Errorf("failed to parse countersignature template: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, sheetData); err != nil {
+		return "", fmt.Errorf("failed to execute countersignature template: %v", err)
+	}
+
+	var pdfBuffer bytes.Buffer
+	if err := GenerateA4PDFFromHTML(buf.String(), sheetData.LogoBase64, &pdfBuffer); err != nil {
+		return "", fmt.Errorf("failed to generate PDF: %v", err)
+	}
+
+	dirPath := "./public/countersignatures"
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		return "", err
+	}
+
+	fullPath := filepath.Join(dirPath, filename)
+	if err := os.WriteFile(fullPath, pdfBuffer.Bytes(), 0644); err != nil {
+		return "", err
+	}
+
+	return "public/countersignatures/" + filename, nil
+}
+
+// prepareCountersignatureSheetData prepares the data structure for the template
+func prepareCountersignatureSheetData(cs *models.CertificateCountersignature) CountersignatureSheetData {
+	logoBase64, err := loadMunicipalityLogo()
+	if err != nil {
+		config.Logger.Warn("Failed to load logo, using placeholder", zap.Error(err))
+		logoBase64 = createMunicipalityPlaceholderLogo()
+	}
+
+	data := CountersignatureSheetData{
+		LogoBase64:      logoBase64,
+		CertificateHash: cs.DocumentHash,
+		RoutedAt:        formatDateFull(cs.RoutedAt),
+		SignatureHash:   cs.SignatureHash,
+	}
+	if cs.ReviewComment != nil {
+		data.ReviewComment = *cs.ReviewComment
+	}
+	if cs.SignedAt != nil {
+		data.SignedAt = formatDateFull(*cs.SignedAt)
+	}
+	if cs.Application != nil {
+		data.PlanNumber = cs.Application.PlanNumber
+		data.ApplicantName = strings.ToUpper(cs.Application.ApplicantDisplayName())
+	}
+	if cs.Document != nil {
+		data.CertificateFileName = cs.Document.FileName
+		data.CertificateVersion = cs.Document.Version
+	}
+	if cs.RoutedBy != nil {
+		data.RoutedByName = cs.RoutedBy.FirstName + " " + cs.RoutedBy.LastName
+	}
+	if cs.Engineer != nil {
+		data.EngineerName = cs.Engineer.FirstName + " " + cs.Engineer.LastName
+		if cs.Engineer.SignatureFilePath != nil && *cs.Engineer.SignatureFilePath != "" {
+			signature, err := loadSignatureImage(*cs.Engineer.SignatureFilePath)
+			if err != nil {
+				config.Logger.Warn("Failed to load engineer signature",
+					zap.String("path", *cs.Engineer.SignatureFilePath),
+					zap.Error(err))
+			} else {
+				data.SignatureBase64 = template.URL(signature)
+			}
+		}
+	}
+
+	return data
+}
@@ -0,0 +1,118 @@
+package fieldset
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MaxFields caps how many fields a single request may name
+const MaxFields = 100
+
+// fieldPattern is one dotted path of JSON names, e.g. applicant.first_name
+var fieldPattern = regexp.MustCompile(`^[a-zA-Z0-9_]+(\.[a-zA-Z0-9_]+)*$`)
+
+// Fieldset is the set of fields a client asked for, as a tree of JSON names. Naming a relation
+// without sub-fields keeps the whole relation; naming some of its fields keeps just those. The
+// id of every object kept is always included so clients can still tell records apart.
+type Fieldset struct {
+	children map[string]*Fieldset
+}
+
+// Parse reads a comma separated list of dotted paths, e.g.
+// "plan_number,status,applicant.first_name,applicant.last_name". An empty list selects
+// everything.
+func Parse(raw string) (*Fieldset, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+
+	paths := strings.Split(raw, ",")
+	if len(paths) > MaxFields {
+		return nil, fmt.Errorf("at most %d fields can be requested", MaxFields)
+	}
+
+	root := &Fieldset{}
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		if !fieldPattern.MatchString(path) {
+			return nil, fmt.Errorf("invalid field %q", path)
+		}
+
+		node := root
+		for _, name := range strings.Split(path, ".") {
+			if node.children == nil {
+				node.children = map[string]*Fieldset{}
+			}
+			next, ok := node.children[name]
+			if !ok {
+				next = &Fieldset{}
+				node.children[name] = next
+			}
+			node = next
+		}
+	}
+	if root.children == nil {
+		return nil, nil
+	}
+	return root, nil
+}
+
+// FromQuery parses the fields query parameter. Without one the whole response is kept.
+func FromQuery(c *fiber.Ctx) (*Fieldset, error) {
+	return Parse(c.Query("fields"))
+}
+
+// Apply serializes value and prunes it to the requested fields. A nil Fieldset returns value
+// untouched. Fields that do not exist are ignored, since optional relations are left out of
+// responses when empty.
+func (f *Fieldset) Apply(value interface{}) (interface{}, error) {
+	if f == nil {
+		return value, nil
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode response: %w", err)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return f.prune(decoded), nil
+}
+
+func (f *Fieldset) prune(value interface{}) interface{} {
+	if f == nil || f.children == nil {
+		return value
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		pruned := make(map[string]interface{}, len(f.children)+1)
+		if id, ok := v["id"]; ok {
+			pruned["id"] = id
+		}
+		for name, child := range f.children {
+			if field, ok := v[name]; ok {
+				pruned[name] = child.prune(field)
+			}
+		}
+		return pruned
+	case []interface{}:
+		pruned := make([]interface{}, len(v))
+		for i, item := range v {
+			pruned[i] = f.prune(item)
+		}
+		return pruned
+	default:
+		return value
+	}
+}
@@ -0,0 +1,385 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrImageScrubUnsupported is returned for image formats the standard library cannot re-encode,
+// such as HEIC and WebP. Those files are stored with their metadata.
+var ErrImageScrubUnsupported = errors.New("image format cannot be scrubbed")
+
+// maxScrubPixels stops decompression bombs from exhausting memory while re-encoding
+const maxScrubPixels = 60_000_000
+
+// scrubbableImageTypes maps the extensions that can be re-encoded to the MIME type written
+var scrubbableImageTypes = map[string]string{
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".gif":  "image/gif",
+}
+
+// ScrubbedImage is an image re-encoded without any of its metadata. CapturedAt, Latitude and
+// Longitude keep the camera's capture time and GPS position from EXIF, when there were any, for
+// callers that store or check them separately.
+type ScrubbedImage struct {
+	Data       []byte
+	MimeType   string
+	CapturedAt *time.Time
+	Latitude   *float64
+	Longitude  *float64
+}
+
+// CanScrubImage reports whether images with this extension can be scrubbed
+func CanScrubImage(fileExt string) bool {
+	_, ok := scrubbableImageTypes[strings.ToLower(fileExt)]
+	return ok
+}
+
+// ScrubImageMetadata decodes the image and encodes only its pixels again, dropping EXIF (GPS
+// position, camera and phone details), XMP, IPTC and comments. JPEG photos are turned upright
+// first, since the orientation tag that phones rely on is dropped with the rest.
+func ScrubImageMetadata(data []byte, fileExt string) (*ScrubbedImage, error) {
+	fileExt = strings.ToLower(fileExt)
+	mimeType, ok := scrubbableImageTypes[fileExt]
+	if !ok {
+		return nil, ErrImageScrubUnsupported
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid image: %w", err)
+	}
+	if config.Width*config.Height > maxScrubPixels {
+		return nil, errors.New("image is too large to process")
+	}
+
+	scrubbed := &ScrubbedImage{MimeType: mimeType}
+	var out bytes.Buffer
+
+	switch mimeType {
+	case "image/jpeg":
+		meta := readJPEGExif(data)
+		scrubbed.CapturedAt = meta.capturedAt
+		scrubbed.Latitude = meta.latitude
+		scrubbed.Longitude = meta.longitude
+
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("invalid JPEG image: %w", err)
+		}
+		if err := jpeg.Encode(&out, orientImage(img, meta.orientation), &jpeg.Options{Quality: 92}); err != nil {
+			return nil, fmt.Errorf("failed to encode JPEG image: %w", err)
+		}
+	case "image/png":
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("invalid PNG image: %w", err)
+		}
+		if err := png.Encode(&out, img); err != nil {
+			return nil, fmt.Errorf("failed to encode PNG image: %w", err)
+		}
+	case "image/gif":
+		// Every frame is kept so animations still play
+		animation, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("invalid GIF image: %w", err)
+		}
+		if err := gif.EncodeAll(&out, animation); err != nil {
+			return nil, fmt.Errorf("failed to encode GIF image: %w", err)
+		}
+	}
+
+	scrubbed.Data = out.Bytes()
+	return scrubbed, nil
+}
+
+// ReadUploadedImage reads an uploaded image, scrubbed of its metadata when the format allows.
+// The ScrubbedImage is nil when the file is returned as uploaded.
+func ReadUploadedImage(fileHeader *multipart.FileHeader) ([]byte, *ScrubbedImage, error) {
+	src, err := fileHeader.Open()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer src.Close()
+
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	fileExt := filepath.Ext(fileHeader.Filename)
+	if !CanScrubImage(fileExt) {
+		return data, nil, nil
+	}
+	scrubbed, err := ScrubImageMetadata(data, fileExt)
+	if err != nil {
+		return nil, nil, err
+	}
+	return scrubbed.Data, scrubbed, nil
+}
+
+// jpegExif is the little of a JPEG's EXIF that survives scrubbing
+type jpegExif struct {
+	orientation int
+	capturedAt  *time.Time
+	latitude    *float64
+	longitude   *float64
+}
+
+// EXIF tags read before the metadata is dropped
+const (
+	exifTagOrientation        = 0x0112
+	exifTagDateTime           = 0x0132
+	exifTagExifIFD            = 0x8769
+	exifTagGPSIFD             = 0x8825
+	exifTagDateTimeOriginal   = 0x9003
+	exifTagOffsetTimeOriginal = 0x9011
+
+	gpsTagLatitudeRef  = 0x0001
+	gpsTagLatitude     = 0x0002
+	gpsTagLongitudeRef = 0x0003
+	gpsTagLongitude    = 0x0004
+)
+
+// readJPEGExif finds the APP1 Exif segment and reads the orientation, capture time and GPS
+// position. Broken or missing EXIF is not an error; the image is then treated as upright with
+// no capture time or position.
+func readJPEGExif(data []byte) jpegExif {
+	meta := jpegExif{orientation: 1}
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return meta
+	}
+
+	for offset := 2; offset+4 <= len(data); {
+		if data[offset] != 0xFF {
+			return meta
+		}
+		marker := data[offset+1]
+		if marker == 0xD8 || marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) {
+			offset += 2
+			continue
+		}
+		if marker == 0xDA || marker == 0xD9 {
+			// Image data starts; metadata segments all come before it
+			return meta
+		}
+
+		length := int(binary.BigEndian.Uint16(data[offset+2 : offset+4]))
+		end := offset + 2 + length
+		if length < 2 || end > len(data) {
+			return meta
+		}
+		segment := data[offset+4 : end]
+		if marker == 0xE1 && len(segment) > 6 && string(segment[:6]) == "Exif\x00\x00" {
+			parseTIFFExif(segment[6:], &meta)
+			return meta
+		}
+		offset = end
+	}
+	return meta
+}
+
+// parseTIFFExif reads IFD0 and the Exif and GPS sub-IFDs of an EXIF TIFF block
+func parseTIFFExif(tiff []byte, meta *jpegExif) {
+	if len(tiff) < 8 {
+		return
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return
+	}
+	if order.Uint16(tiff[2:4]) != 42 {
+		return
+	}
+
+	var dateTime, dateTimeOriginal, offsetTime string
+	var exifIFD, gpsIFD uint32
+
+	readIFD := func(ifdOffset uint32, visit func(tag uint16, kind uint16, count uint32, value []byte)) {
+		start := int(ifdOffset)
+		if start < 8 || start+2 > len(tiff) {
+			return
+		}
+		entries := int(order.Uint16(tiff[start : start+2]))
+		for i := 0; i < entries; i++ {
+			entry := start + 2 + i*12
+			if entry+12 > len(tiff) {
+				return
+			}
+			visit(order.Uint16(tiff[entry:entry+2]), order.Uint16(tiff[entry+2:entry+4]),
+				order.Uint32(tiff[entry+4:entry+8]), tiff[entry+8:entry+12])
+		}
+	}
+	readString := func(count uint32, value []byte) string {
+		if count <= 4 {
+			return strings.TrimRight(string(value[:count]), "\x00")
+		}
+		start := int(order.Uint32(value))
+		if start < 0 || start+int(count) > len(tiff) {
+			return ""
+		}
+		return strings.TrimRight(string(tiff[start:start+int(count)]), "\x00 ")
+	}
+
+	readIFD(order.Uint32(tiff[4:8]), func(tag uint16, kind uint16, count uint32, value []byte) {
+		switch tag {
+		case exifTagOrientation:
+			if kind == 3 && count == 1 {
+				if orientation := int(order.Uint16(value[:2])); orientation >= 1 && orientation <= 8 {
+					meta.orientation = orientation
+				}
+			}
+		case exifTagDateTime:
+			dateTime = readString(count, value)
+		case exifTagExifIFD:
+			exifIFD = order.Uint32(value)
+		case exifTagGPSIFD:
+			gpsIFD = order.Uint32(value)
+		}
+	})
+	if exifIFD != 0 {
+		readIFD(exifIFD, func(tag uint16, kind uint16, count uint32, value []byte) {
+			switch tag {
+			case exifTagDateTimeOriginal:
+				dateTimeOriginal = readString(count, value)
+			case exifTagOffsetTimeOriginal:
+				offsetTime = readString(count, value)
+			}
+		})
+	}
+
+	if gpsIFD != 0 {
+		// Degrees, minutes and seconds as three unsigned rationals
+		readDegrees := func(kind uint16, count uint32, value []byte) *float64 {
+			start := int(order.Uint32(value))
+			if kind != 5 || count != 3 || start < 0 || start+24 > len(tiff) {
+				return nil
+			}
+			var parts [3]float64
+			for i := range parts {
+				numerator := order.Uint32(tiff[start+i*8 : start+i*8+4])
+				denominator := order.Uint32(tiff[start+i*8+4 : start+i*8+8])
+				if denominator == 0 {
+					return nil
+				}
+				parts[i] = float64(numerator) / float64(denominator)
+			}
+			degrees := parts[0] + parts[1]/60 + parts[2]/3600
+			return &degrees
+		}
+
+		var latitudeRef, longitudeRef string
+		var latitude, longitude *float64
+		readIFD(gpsIFD, func(tag uint16, kind uint16, count uint32, value []byte) {
+			switch tag {
+			case gpsTagLatitudeRef:
+				latitudeRef = readString(count, value)
+			case gpsTagLatitude:
+				latitude = readDegrees(kind, count, value)
+			case gpsTagLongitudeRef:
+				longitudeRef = readString(count, value)
+			case gpsTagLongitude:
+				longitude = readDegrees(kind, count, value)
+			}
+		})
+		if latitude != nil && longitude != nil && *latitude <= 90 && *longitude <= 180 {
+			if latitudeRef == "S" {
+				*latitude = -*latitude
+			}
+			if longitudeRef == "W" {
+				*longitude = -*longitude
+			}
+			meta.latitude = latitude
+			meta.longitude = longitude
+		}
+	}
+
+	captured := dateTimeOriginal
+	if captured == "" {
+		captured = dateTime
+	}
+	meta.capturedAt = parseExifTime(captured, offsetTime)
+}
+
+// parseExifTime reads EXIF's "2006:01:02 15:04:05", in the recorded UTC offset when the camera
+// wrote one and in server local time otherwise
+func parseExifTime(value string, offset string) *time.Time {
+	if value == "" {
+		return nil
+	}
+	var parsed time.Time
+	var err error
+	if offset != "" {
+		parsed, err = time.Parse("2006:01:02 15:04:05-07:00", value+offset)
+	}
+	if offset == "" || err != nil {
+		parsed, err = time.ParseInLocation("2006:01:02 15:04:05", value, time.Local)
+	}
+	if err != nil || parsed.Year() < 1990 {
+		return nil
+	}
+	return &parsed
+}
+
+// orientImage applies an EXIF orientation (2-8) so the pixels display upright without the tag
+func orientImage(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	src := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // flip horizontally
+				dx, dy = width-1-x, y
+			case 3: // turn half way
+				dx, dy = width-1-x, height-1-y
+			case 4: // flip vertically
+				dx, dy = x, height-1-y
+			case 5: // transpose
+				dx, dy = y, x
+			case 6: // turn a quarter clockwise
+				dx, dy = height-1-y, x
+			case 7: // transverse
+				dx, dy = height-1-y, width-1-x
+			case 8: // turn a quarter anticlockwise
+				dx, dy = y, width-1-x
+			}
+			si := src.PixOffset(x, y)
+			di := dst.PixOffset(dx, dy)
+			copy(dst.Pix[di:di+4], src.Pix[si:si+4])
+		}
+	}
+	return dst
+}
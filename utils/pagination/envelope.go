@@ -0,0 +1,250 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxLimit caps how many items a single list request can return
+const MaxLimit = 100
+
+// Request is a parsed list query. Lists page by offset unless the client sends a cursor
+// parameter (empty for the first page), in which case they are keyed on created_at and id so
+// rows inserted while paging do not shift later pages.
+type Request struct {
+	Page      int
+	Limit     int
+	UseCursor bool
+	After     *Cursor
+}
+
+// Cursor marks the last item of a cursor page
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// Encode returns the opaque token handed to clients
+func (cur Cursor) Encode() string {
+	raw := cur.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + cur.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a token produced by Cursor.Encode
+func DecodeCursor(token string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return nil, errors.New("invalid cursor")
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	id, err := uuid.Parse(parts[1])
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	return &Cursor{CreatedAt: createdAt, ID: id}, nil
+}
+
+// ParseRequest reads page, limit and cursor from the query string. page_size is still
+// accepted in place of limit for older clients. Limits above MaxLimit and pages below 1 are
+// clamped rather than rejected.
+func ParseRequest(c *fiber.Ctx, defaultLimit int) (Request, error) {
+	return parseRequest(c, defaultLimit, false)
+}
+
+// ParseLegacyRequest is ParseRequest for lists that took an uncapped page_size before the
+// envelope. A page_size is honoured as sent; only clients asking by limit or cursor are held
+// to MaxLimit.
+func ParseLegacyRequest(c *fiber.Ctx, defaultLimit int) (Request, error) {
+	return parseRequest(c, defaultLimit, true)
+}
+
+// Requested reports whether the client asked for a page at all. Lists that returned everything
+// before the envelope only page for clients that do.
+func Requested(c *fiber.Ctx) bool {
+	args := c.Context().QueryArgs()
+	return args.Has("page") || args.Has("limit") || args.Has("page_size") || args.Has("cursor")
+}
+
+func parseRequest(c *fiber.Ctx, defaultLimit int, uncappedPageSize bool) (Request, error) {
+	useCursor := c.Context().QueryArgs().Has("cursor")
+
+	capped := true
+	limit := c.QueryInt("limit", 0)
+	if limit == 0 {
+		limit = c.QueryInt("page_size", defaultLimit)
+		capped = !uncappedPageSize || useCursor
+	}
+	if limit < 1 {
+		limit = defaultLimit
+	}
+	if capped && limit > MaxLimit {
+		limit = MaxLimit
+	}
+
+	request := Request{Page: 1, Limit: limit}
+	if useCursor {
+		request.UseCursor = true
+		if token := c.Query("cursor"); token != "" {
+			after, err := DecodeCursor(token)
+			if err != nil {
+				return Request{}, err
+			}
+			request.After = after
+		}
+		return request, nil
+	}
+
+	if page := c.QueryInt("page", 1); page > 1 {
+		request.Page = page
+	}
+	return request, nil
+}
+
+// Offset is the number of rows skipped for offset pagination
+func (r Request) Offset() int {
+	if r.UseCursor {
+		return 0
+	}
+	return (r.Page - 1) * r.Limit
+}
+
+// Window limits a list query to the requested page. order is the list's usual ordering and
+// table qualifies the keyset columns in cursor mode.
+func (r Request) Window(query *gorm.DB, table, order string) *gorm.DB {
+	if !r.UseCursor {
+		return query.Order(order).Limit(r.Limit).Offset(r.Offset())
+	}
+	if r.After != nil {
+		query = query.Where(
+			fmt.Sprintf("(%s.created_at, %s.id) < (?, ?)", table, table),
+			r.After.CreatedAt, r.After.ID,
+		)
+	}
+	return query.Order(table + ".created_at DESC").Order(table + ".id DESC").Limit(r.Limit)
+}
+
+// NextCursor returns the cursor after the last returned item, or nil when the page was short
+// or the request is not using cursors
+func (r Request) NextCursor(returned int, createdAt time.Time, id uuid.UUID) *Cursor {
+	if !r.UseCursor || returned < r.Limit {
+		return nil
+	}
+	return &Cursor{CreatedAt: createdAt, ID: id}
+}
+
+// Links point at neighbouring pages of the same query
+type Links struct {
+	Self string  `json:"self"`
+	Next *string `json:"next"`
+	Prev *string `json:"prev"`
+}
+
+// LegacyMeta is the page summary list endpoints returned before the envelope
+type LegacyMeta struct {
+	CurrentPage int   `json:"current_page"`
+	PageSize    int   `json:"page_size"`
+	Total       int64 `json:"total"`
+	TotalPages  int64 `json:"total_pages"`
+}
+
+// Envelope is the shared shape of list responses
+type Envelope struct {
+	Data       interface{} `json:"data"`
+	Page       int         `json:"page,omitempty"`
+	Limit      int         `json:"limit"`
+	Total      int64       `json:"total"`
+	TotalPages int64       `json:"total_pages"`
+	Links      Links       `json:"links"`
+	NextCursor *string     `json:"next_cursor,omitempty"`
+
+	// Meta keeps older clients working; new code should read the fields above
+	Meta LegacyMeta `json:"meta"`
+}
+
+// NewEnvelope wraps one page of results. next is the cursor for the following page in cursor mode.
+func NewEnvelope(c *fiber.Ctx, request Request, data interface{}, total int64, next *Cursor) Envelope {
+	totalPages := (total + int64(request.Limit) - 1) / int64(request.Limit)
+
+	envelope := Envelope{
+		Data:       data,
+		Limit:      request.Limit,
+		Total:      total,
+		TotalPages: totalPages,
+		Links:      Links{Self: pageLink(c, nil)},
+		Meta: LegacyMeta{
+			CurrentPage: request.Page,
+			PageSize:    request.Limit,
+			Total:       total,
+			TotalPages:  totalPages,
+		},
+	}
+
+	if request.UseCursor {
+		if next != nil {
+			token := next.Encode()
+			link := pageLink(c, map[string]string{"cursor": token})
+			envelope.NextCursor = &token
+			envelope.Links.Next = &link
+		}
+		return envelope
+	}
+
+	envelope.Page = request.Page
+	if int64(request.Page) < totalPages {
+		link := pageLink(c, map[string]string{"page": strconv.Itoa(request.Page + 1)})
+		envelope.Links.Next = &link
+	}
+	if request.Page > 1 {
+		link := pageLink(c, map[string]string{"page": strconv.Itoa(request.Page - 1)})
+		envelope.Links.Prev = &link
+	}
+	return envelope
+}
+
+// Map returns the envelope as a fiber.Map so endpoints can add their own legacy keys
+func (e Envelope) Map() fiber.Map {
+	m := fiber.Map{
+		"data":        e.Data,
+		"limit":       e.Limit,
+		"total":       e.Total,
+		"total_pages": e.TotalPages,
+		"links":       e.Links,
+		"meta":        e.Meta,
+	}
+	if e.Page != 0 {
+		m["page"] = e.Page
+	}
+	if e.NextCursor != nil {
+		m["next_cursor"] = *e.NextCursor
+	}
+	return m
+}
+
+// pageLink rebuilds the current URL with some query parameters replaced
+func pageLink(c *fiber.Ctx, set map[string]string) string {
+	query, _ := url.ParseQuery(string(c.Request().URI().QueryString()))
+	for key, value := range set {
+		query.Set(key, value)
+	}
+	link := fmt.Sprintf("%s://%s%s", c.Protocol(), c.Hostname(), c.Path())
+	if encoded := query.Encode(); encoded != "" {
+		link += "?" + encoded
+	}
+	return link
+}
@@ -1,80 +1,24 @@
 package utils
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"strconv"
 	"strings"
 	"town-planning-backend/config" // Import your config package to access config.Logger
+	email_services "town-planning-backend/emails/services"
+
+	"github.com/google/uuid"
 
 	// Added for InitializeDateLocation, if not already there in original
 	"go.uber.org/zap" // Import zap for structured logging fields
-	"gopkg.in/gomail.v2"
 )
 
-// Initialize the SMTP mailer once and store it in a global variable
-var mailer *gomail.Dialer
-
-// InitializeMailer sets up the mailer using environment variables
-func InitializeMailer() {
-	// No need to call LoadEnv() here, as it should be handled once at application startup (e.g., in main.go)
-	// If you still need to load it here for some reason, ensure it's not redundant.
-
-	mailHost := os.Getenv("SMTP_HOST")
-	mailPort := os.Getenv("SMTP_PORT")
-	mailUser := os.Getenv("SMTP_USER")
-	mailPassword := os.Getenv("SMTP_PASSWORD")
-	// smtpFrom := os.Getenv("SMTP_FROM") // Also retrieve SMTP_FROM here
-
-	// Basic validation for critical mailer settings
-	// if mailHost == "" || mailPort == "" || mailUser == "" || mailPassword == "" || smtpFrom == "" {
-	// 	config.Logger.Fatal("Missing one or more SMTP environment variables (SMTP_HOST, SMTP_PORT, SMTP_USER, SMTP_PASSWORD, SMTP_FROM)",
-	// 		zap.Strings("missing_vars", getMissingSMTPEnvVars(mailHost, mailPort, mailUser, mailPassword, smtpFrom)),
-	// 	)
-	// }
-
-	port, err := strconv.Atoi(mailPort)
-	if err != nil {
-		config.Logger.Error("Invalid SMTP_PORT value, defaulting to port 25",
-			zap.String("provided_port", mailPort),
-			zap.Error(err),
-		)
-		port = 25 // Fallback to a default port if conversion fails
-	}
-
-	mailer = gomail.NewDialer(mailHost, port, mailUser, mailPassword)
-	config.Logger.Info("Mailer initialized successfully")
-}
-
-// // Helper function to identify missing SMTP environment variables for logging
-// func getMissingSMTPEnvVars(host, port, user, pass, from string) []string {
-// 	missing := []string{}
-// 	if host == "" {
-// 		missing = append(missing, "SMTP_HOST")
-// 	}
-// 	if port == "" {
-// 		missing = append(missing, "SMTP_PORT")
-// 	}
-// 	if user == "" {
-// 		missing = append(missing, "SMTP_USER")
-// 	}
-// 	if pass == "" {
-// 		missing = append(missing, "SMTP_PASSWORD")
-// 	}
-// 	if from == "" {
-// 		missing = append(missing, "SMTP_FROM")
-// 	}
-// 	return missing
-// }
-
-// GetMailer returns the initialized mailer
-func GetMailer() *gomail.Dialer {
-	return mailer
-}
-
-// SendEmail sends an email with an optional OTP and attachment, and returns an error if it fails.
+// SendEmail queues an email with an optional OTP and attachment through the email service.
+// It returns an error only if the email could not be queued; delivery failures are retried and
+// recorded on the email log.
 func SendEmail(email string, message string, title string, otp string, attachmentPath string) error {
-	if mailer == nil {
+	service := email_services.Default()
+	if service == nil {
 		err := fmt.Errorf("mailer is not initialized")
 		config.Logger.Error("Email send failed: mailer is not initialized",
 			zap.String("to_email", email),
@@ -86,12 +30,11 @@ func SendEmail(email string, message string, title string, otp string, attachmen
 		return err
 	}
 
-	m := gomail.NewMessage()
-	// Using the retrieved SMTP_FROM from InitializeMailer
-	m.SetHeader("From", os.Getenv("SMTP_FROM")) // Ensure SMTP_FROM is set and valid
-	m.SetHeader("To", email)
-	m.SetHeader("Subject", title)
+	text := message
+	html := ""
 	if otp != "" {
+		text = fmt.Sprintf("%s\nYour OTP is: %s", message, otp)
+
 		lines := strings.Split(message, "\n")
 		var link string
 		for _, line := range lines {
@@ -102,8 +45,7 @@ func SendEmail(email string, message string, title string, otp string, attachmen
 		}
 
 		if link != "" {
-			m.SetBody("text/plain", fmt.Sprintf("%s\nYour OTP is: %s", message, otp))
-			m.SetBody("text/html", fmt.Sprintf(`
+			html = fmt.Sprintf(`
 				<html>
 					<head>
 						<meta charset="utf-8">
@@ -116,10 +58,9 @@ func SendEmail(email string, message string, title string, otp string, attachmen
 						<p><a href="%s" target="_blank">Click here to reset your password</a></p>
 					</body>
 				</html>
-			`, otp, link))
+			`, otp, link)
 		} else {
-			m.SetBody("text/plain", fmt.Sprintf("%s\nYour OTP is: %s", message, otp))
-			m.SetBody("text/html", fmt.Sprintf(`
+			html = fmt.Sprintf(`
 				<html>
 					<head>
 						<meta charset="utf-8">
@@ -129,42 +70,93 @@ func SendEmail(email string, message string, title string, otp string, attachmen
 						<p>Your OTP (Verification code): <strong>%s</strong></p>
 					</body>
 				</html>
-			`, otp))
-		}
-	}
-
-	// Attach file if path is provided
-	if attachmentPath != "" {
-		if _, err := os.Stat(attachmentPath); err == nil {
-			m.Attach(attachmentPath)
-			config.Logger.Debug("Attaching file to email", zap.String("filepath", attachmentPath))
-		} else {
-			// Use config.Logger for this non-fatal warning
-			config.Logger.Warn("Attachment file not found for email",
-				zap.String("filepath", attachmentPath),
-				zap.String("to_email", email),
-				zap.Error(err),
-			)
-			// Don't fail the email send just because an optional attachment isn't found
+			`, otp)
 		}
 	}
 
-	// Send the email and return any error
-	if err := mailer.DialAndSend(m); err != nil {
-		config.Logger.Error("Failed to send email via SMTP",
-			zap.String("to_email", email),
-			zap.String("subject", title),
-			zap.Bool("has_otp", otp != ""),
-			zap.Bool("has_attachment", attachmentPath != ""),
-			zap.Error(err), // Log the actual SMTP error
-		)
+	if _, err := service.Send(context.Background(), email_services.Email{
+		To:             email,
+		Subject:        title,
+		Text:           text,
+		HTML:           html,
+		AttachmentPath: attachmentPath,
+	}); err != nil {
 		return fmt.Errorf("failed to send email: %w", err)
 	}
 
-	config.Logger.Info("Email sent successfully",
+	config.Logger.Info("Email queued successfully",
 		zap.String("to_email", email),
 		zap.String("subject", title),
 		zap.Bool("has_otp", otp != ""),
 	)
-	return nil // return nil if email sent successfully
+	return nil // return nil if email was queued successfully
+}
+
+// ApplicantEmail is a rendered notification to an applicant, see RenderEmailTemplate
+type ApplicantEmail struct {
+	To            string
+	Subject       string
+	Body          string
+	Template      string // e.g. EmailPermitStatusChange
+	ApplicantID   *uuid.UUID
+	ApplicationID *uuid.UUID
+
+	// Off for emails whose links must reach the applicant unchanged, such as sign-in links
+	TrackClicks bool
+}
+
+// SendApplicantEmail queues an applicant-facing notification with open tracking, recording it
+// against the applicant and application
+func SendApplicantEmail(notification ApplicantEmail) error {
+	service := email_services.Default()
+	if service == nil {
+		return fmt.Errorf("mailer is not initialized")
+	}
+
+	template := notification.Template
+	if _, err := service.Send(context.Background(), email_services.Email{
+		To:            notification.To,
+		Subject:       notification.Subject,
+		Text:          notification.Body,
+		EmailType:     strings.ToUpper(strings.ReplaceAll(template, "-", "_")),
+		TemplateName:  &template,
+		ApplicantID:   notification.ApplicantID,
+		ApplicationID: notification.ApplicationID,
+		TrackOpens:    true,
+		TrackClicks:   notification.TrackClicks,
+	}); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// ChatDigestEmail is a batch of unread chat messages emailed to a participant, with the
+// address their reply is posted back into the thread from
+type ChatDigestEmail struct {
+	To            string
+	Subject       string
+	Body          string
+	ReplyTo       string
+	ApplicationID *uuid.UUID
+}
+
+// SendChatDigestEmail queues a chat digest. Links are left untracked so the reply address
+// and thread links reach the participant unchanged.
+func SendChatDigestEmail(digest ChatDigestEmail) error {
+	service := email_services.Default()
+	if service == nil {
+		return fmt.Errorf("mailer is not initialized")
+	}
+
+	if _, err := service.Send(context.Background(), email_services.Email{
+		To:            digest.To,
+		Subject:       digest.Subject,
+		Text:          digest.Body,
+		ReplyTo:       digest.ReplyTo,
+		EmailType:     "CHAT_DIGEST",
+		ApplicationID: digest.ApplicationID,
+	}); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
 }
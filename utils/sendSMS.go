@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	sms_services "town-planning-backend/sms/services"
+
+	"github.com/google/uuid"
+)
+
+// ApplicantSMS is a rendered text message to an applicant, see RenderSMSTemplate
+type ApplicantSMS struct {
+	To            string
+	Body          string
+	Language      string
+	Template      string // e.g. EmailPermitStatusChange
+	ApplicantID   *uuid.UUID
+	ApplicationID *uuid.UUID
+}
+
+// SMSEnabled reports whether text messages can be sent, i.e. at least one SMS provider is
+// configured
+func SMSEnabled() bool {
+	return sms_services.Default().Enabled()
+}
+
+// SendApplicantSMS queues an applicant-facing text message, recording it in the communication
+// log against the applicant and application
+func SendApplicantSMS(notification ApplicantSMS) error {
+	service := sms_services.Default()
+	if !service.Enabled() {
+		return fmt.Errorf("SMS is not configured")
+	}
+
+	template := notification.Template
+	if _, err := service.Send(context.Background(), sms_services.SMS{
+		To:            notification.To,
+		Body:          notification.Body,
+		Language:      notification.Language,
+		MessageType:   strings.ToUpper(strings.ReplaceAll(template, "-", "_")),
+		TemplateName:  &template,
+		ApplicantID:   notification.ApplicantID,
+		ApplicationID: notification.ApplicationID,
+	}); err != nil {
+		return fmt.Errorf("failed to send SMS: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"errors"
+	"sort"
+)
+
+// smsTemplates holds the text message versions of the applicant notifications, under the same
+// names and filled with the same data as the emails. English is required for each message;
+// other languages fall back to it. Sign-in links are only ever emailed, apart from the document
+// request, whose text version staff send over WhatsApp themselves.
+var smsTemplates = map[string]map[string]string{
+	EmailCollectionConfirmation: {
+		TemplateLanguageEnglish: "Permit collection for plan {{.PlanNumber}} booked on {{.ShortDate}}, {{.StartTime}}-{{.EndTime}}{{if .Location}} at {{.Location}}{{end}}. Please bring your ID.",
+		TemplateLanguageShona:   "Kutora pemiti yeplan {{.PlanNumber}} kwarongwa musi wa{{.ShortDate}}, {{.StartTime}}-{{.EndTime}}{{if .Location}} ku{{.Location}}{{end}}. Uyai negwaro rekuzivikanwa.",
+		TemplateLanguageNdebele: "Ukulanda imvumo yeplani {{.PlanNumber}} kubhukiwe ngomhla ka-{{.ShortDate}}, {{.StartTime}}-{{.EndTime}}{{if .Location}} e-{{.Location}}{{end}}. Sicela ulethe isazisi.",
+	},
+	EmailPermitStatusChange: {
+		TemplateLanguageEnglish: "Permit {{.PermitNumber}} (plan {{.PlanNumber}}) was {{.Status}} on {{.ChangedOn}}. Reason: {{.Reason}}. Contact the Town Planning office for help.",
+	},
+	EmailPortalNewMessage: {
+		TemplateLanguageEnglish: "{{.StaffName}} sent you a message about plan {{.PlanNumber}}. Read it on the applicant portal: {{.PortalURL}}",
+	},
+	EmailDocumentRequest: {
+		TemplateLanguageEnglish: "Dear {{.ApplicantName}}, plan {{.PlanNumber}} is missing documents. Upload each one on the applicant portal with its link (valid for {{.ExpiresIn}}):{{range .Items}}\n- {{.Label}}: {{.UploadURL}}{{end}}{{if .Note}}\n{{.Note}}{{end}}",
+	},
+	EmailPreScreeningReturned: {
+		TemplateLanguageEnglish: "Plan {{.PlanNumber}} was returned to you before review:{{range .Reasons}}\n- {{.}}{{end}}{{if .Note}}\n{{.Note}}{{end}}\nPlease correct and resubmit at the Town Planning office.",
+	},
+}
+
+// SMSTemplateNames lists the notifications that have a text message version, sorted by name
+func SMSTemplateNames() []string {
+	names := make([]string, 0, len(smsTemplates))
+	for name := range smsTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SMSTemplateLanguages lists the languages a text message has been translated into
+func SMSTemplateLanguages(name string) []string {
+	languages := []string{}
+	for _, language := range TemplateLanguages {
+		if _, ok := smsTemplates[name][language]; ok {
+			languages = append(languages, language)
+		}
+	}
+	return languages
+}
+
+// RenderSMSTemplate renders a text message in the recipient's language, falling back to English
+// when it has not been translated. It returns the message and the language used.
+func RenderSMSTemplate(name string, language string, data interface{}) (string, string, error) {
+	variants, ok := smsTemplates[name]
+	if !ok {
+		return "", "", errors.New("template not found")
+	}
+
+	language = NormalizeTemplateLanguage(language)
+	text, ok := variants[language]
+	if !ok {
+		language = DefaultTemplateLanguage
+		text = variants[language]
+	}
+
+	body, err := executeEmailTemplate(name+".sms", text, data)
+	if err != nil {
+		return "", "", err
+	}
+	return body, language, nil
+}
+
+// PreviewSMSTemplate renders a text message with the emails' sample data
+func PreviewSMSTemplate(name string, language string) (string, string, error) {
+	return RenderSMSTemplate(name, language, emailPreviewData[name])
+}
@@ -0,0 +1,143 @@
+package utils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"town-planning-backend/db/models"
+)
+
+// Languages that letters and emails can be sent in
+const (
+	TemplateLanguageEnglish = "en"
+	TemplateLanguageShona   = "sn"
+	TemplateLanguageNdebele = "nd"
+	DefaultTemplateLanguage = TemplateLanguageEnglish
+)
+
+// TemplateLanguages lists the supported template languages, default first
+var TemplateLanguages = []string{TemplateLanguageEnglish, TemplateLanguageShona, TemplateLanguageNdebele}
+
+// templatesDir holds the English letter templates; translations live in a sub-directory per
+// language, e.g. templates/sn/development-permit.html
+const templatesDir = "templates"
+
+// LetterTemplate is an HTML template a letter or form is rendered from
+type LetterTemplate struct {
+	Name string `json:"name"`
+	File string `json:"file"`
+}
+
+// LetterTemplates are the applicant-facing letters that have language variants
+var LetterTemplates = []LetterTemplate{
+	{Name: "development-permit", File: "development-permit.html"},
+	{Name: "comments-sheet", File: "comments-sheet.html"},
+	{Name: "development-quotation", File: "development-application-quotation.html"},
+	{Name: "tpd1-form", File: "tpd1-form.html"},
+}
+
+// NormalizeTemplateLanguage maps a stored language ("SN", "nd-ZW") to a supported template
+// language, falling back to English
+func NormalizeTemplateLanguage(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if idx := strings.IndexAny(language, "-_"); idx > 0 {
+		language = language[:idx]
+	}
+	for _, supported := range TemplateLanguages {
+		if language == supported {
+			return language
+		}
+	}
+	return DefaultTemplateLanguage
+}
+
+// ResolveTemplateFile picks the template to render a letter from. The variant for the language
+// is used when one exists, otherwise the English template. It returns the path and the language
+// of the template that will be used.
+func ResolveTemplateFile(file string, language string) (string, string) {
+	language = NormalizeTemplateLanguage(language)
+	if language != DefaultTemplateLanguage {
+		variant := filepath.Join(templatesDir, language, file)
+		if _, err := os.Stat(variant); err == nil {
+			return variant, language
+		}
+	}
+	return filepath.Join(templatesDir, file), DefaultTemplateLanguage
+}
+
+// FindLetterTemplate looks up a letter template by name
+func FindLetterTemplate(name string) (LetterTemplate, error) {
+	for _, letter := range LetterTemplates {
+		if letter.Name == name {
+			return letter, nil
+		}
+	}
+	return LetterTemplate{}, errors.New("template not found")
+}
+
+// LetterTemplateLanguages lists the languages a letter has its own template for
+func LetterTemplateLanguages(letter LetterTemplate) []string {
+	languages := []string{}
+	for _, language := range TemplateLanguages {
+		if _, resolved := ResolveTemplateFile(letter.File, language); resolved == language {
+			languages = append(languages, language)
+		}
+	}
+	return languages
+}
+
+// PreviewLetterTemplate renders a letter in the language with sample data, so admins can check
+// a translation's wording and layout. It returns the HTML and the language of the template used.
+func PreviewLetterTemplate(name string, language string) (string, string, error) {
+	letter, err := FindLetterTemplate(name)
+	if err != nil {
+		return "", "", err
+	}
+	_, resolved := ResolveTemplateFile(letter.File, language)
+
+	const (
+		samplePlanNumber = "PLN-2025-0001"
+		sampleStand      = "1234"
+		sampleApplicant  = "Tendai Moyo"
+		sampleDate       = "3 March 2025"
+	)
+
+	var html string
+	switch letter.Name {
+	case "development-permit":
+		html, err = generateHTMLDevelopmentPermit(DevelopmentPermitData{
+			PrintDate:            sampleDate,
+			PlanNumber:           samplePlanNumber,
+			StandNumber:          sampleStand,
+			ApplicantName:        sampleApplicant,
+			PermitGenerationDate: sampleDate,
+			Conditions:           getDevelopmentConditions(models.Application{}),
+			PlanningSchemes:      []string{"Redcliff Local Plan, version 2 (in force from 1 July 2024)"},
+			LegalNotice:          getLegalNotice(),
+		}, language)
+	case "comments-sheet":
+		html, err = generateHTMLCommentsSheet(CommentsSheetData{
+			PrintDate:     sampleDate,
+			PlanNumber:    samplePlanNumber,
+			StandNumber:   sampleStand,
+			DeveloperName: sampleApplicant,
+		}, language)
+	case "development-quotation":
+		html, err = generateHTMLQuotation(QuotationData{
+			DeveloperName: sampleApplicant,
+			StandNumber:   sampleStand,
+			PlanNumber:    samplePlanNumber,
+			DateReceived:  sampleDate,
+		}, language)
+	case "tpd1-form":
+		html, err = generateHTMLTPD1Form(TPD1FormData{
+			FormDate:     sampleDate,
+			DateReceived: sampleDate,
+		}, language)
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return html, resolved, nil
+}
@@ -62,7 +62,7 @@ func GenerateTPD1Form(application models.Application, filename string) (string,
 	}
 
 	// Generate HTML content
-	htmlContent, err := generateHTMLTPD1Form(formData)
+	htmlContent, err := generateHTMLTPD1Form(formData, application.Applicant.PreferredLanguage)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate HTML TPD-1 form: %v", err)
 	}
@@ -199,10 +199,11 @@ func getPropertyDescription(application models.Application) string {
 	return "N/A"
 }
 
-// generateHTMLTPD1Form generates HTML content from the TPD-1 form template
-func generateHTMLTPD1Form(data TPD1FormData) (string, error) {
+// generateHTMLTPD1Form generates HTML content from the TPD-1 form template in the applicant's language
+func generateHTMLTPD1Form(data TPD1FormData, language string) (string, error) {
 	// Parse template file
-	tmpl, err := template.ParseFiles("templates/tpd1-form.html")
+	templatePath, _ := ResolveTemplateFile("tpd1-form.html", language)
+	tmpl, err := template.ParseFiles(templatePath)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse TPD-1 form template: %v", err)
 	}
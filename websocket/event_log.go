@@ -0,0 +1,155 @@
+// websocket/event_log.go
+package websocket
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// threadEventRetention is how long thread events are kept for clients polling instead of
+	// holding a WebSocket. A client away for longer gets reset and reloads the thread.
+	threadEventRetention = 5 * time.Minute
+
+	// maxThreadEvents caps the events kept per thread however busy it gets
+	maxThreadEvents = 500
+
+	// pollerActiveWindow is how long after its last poll a fallback client still counts as
+	// having the thread open
+	pollerActiveWindow = time.Minute
+)
+
+// ThreadEvent is a thread message as delivered over the long-poll and server-sent event
+// fallbacks. Seq orders events across all threads and is the cursor clients resume from.
+type ThreadEvent struct {
+	Seq uint64 `json:"seq"`
+	WebSocketMessage
+
+	excluded []uuid.UUID // Users the broadcast skipped, e.g. the sender
+	at       time.Time
+}
+
+func (e *ThreadEvent) excludes(userID uuid.UUID) bool {
+	for _, id := range e.excluded {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// eventLog keeps recent thread broadcasts so fallback clients can fetch what they missed
+// between polls. Every append wakes the clients waiting on it.
+type eventLog struct {
+	mu      sync.Mutex
+	seq     uint64
+	threads map[string][]ThreadEvent // Oldest first
+	changed chan struct{}            // Closed and replaced on every append
+
+	// Events after these cursors may have been dropped: per thread when it overflowed, and
+	// across all threads when events aged out
+	trimmed   map[string]uint64
+	floor     uint64
+	lastSweep time.Time
+}
+
+func newEventLog() *eventLog {
+	return &eventLog{
+		threads:   make(map[string][]ThreadEvent),
+		changed:   make(chan struct{}),
+		trimmed:   make(map[string]uint64),
+		lastSweep: time.Now(),
+	}
+}
+
+// append records a thread broadcast and wakes the waiting clients
+func (l *eventLog) append(threadID string, message WebSocketMessage, excluded []uuid.UUID) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.seq++
+	events := append(l.threads[threadID], ThreadEvent{
+		Seq:              l.seq,
+		WebSocketMessage: message,
+		excluded:         excluded,
+		at:               now,
+	})
+	if len(events) > maxThreadEvents {
+		l.trimmed[threadID] = events[0].Seq
+		events = events[1:]
+	}
+	l.threads[threadID] = events
+
+	if now.Sub(l.lastSweep) > time.Minute {
+		l.sweep(now)
+	}
+	l.wakeLocked()
+}
+
+// sweep drops events older than the retention window. Callers hold l.mu.
+func (l *eventLog) sweep(now time.Time) {
+	l.lastSweep = now
+	cutoff := now.Add(-threadEventRetention)
+	for threadID, events := range l.threads {
+		kept := 0
+		for kept < len(events) && events[kept].at.Before(cutoff) {
+			if events[kept].Seq > l.floor {
+				l.floor = events[kept].Seq
+			}
+			kept++
+		}
+		if kept == len(events) {
+			delete(l.threads, threadID)
+			delete(l.trimmed, threadID)
+			continue
+		}
+		l.threads[threadID] = events[kept:]
+	}
+}
+
+// wake releases every client waiting for events, e.g. on shutdown
+func (l *eventLog) wake() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.wakeLocked()
+}
+
+func (l *eventLog) wakeLocked() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// head is the cursor of the latest event
+func (l *eventLog) head() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.seq
+}
+
+// since returns the user's events in the threads after cursor, the cursor to resume from, and
+// whether events after cursor were lost so the client must reload the threads. The channel is
+// closed when the next event arrives.
+func (l *eventLog) since(userID uuid.UUID, threadIDs []string, cursor uint64) ([]ThreadEvent, uint64, bool, <-chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// A cursor ahead of the log was issued before a restart
+	reset := cursor < l.floor || cursor > l.seq
+	var events []ThreadEvent
+	for _, threadID := range threadIDs {
+		if cursor < l.trimmed[threadID] {
+			reset = true
+		}
+		for _, event := range l.threads[threadID] {
+			if event.Seq > cursor && !event.excludes(userID) {
+				events = append(events, event)
+			}
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Seq < events[j].Seq })
+	return events, l.seq, reset, l.changed
+}
@@ -0,0 +1,305 @@
+// websocket/fallback.go
+package websocket
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"town-planning-backend/config"
+	"town-planning-backend/token"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Council networks sometimes block WebSocket upgrades. These fallbacks deliver the same thread
+// broadcasts over plain HTTP: a long poll that returns as soon as there is something new, and a
+// server-sent event stream. Both resume from the seq of the last event the client received.
+// Clients on a fallback send typing and read receipts through the REST endpoints.
+
+const (
+	defaultLongPollTimeout = 25 * time.Second
+	maxLongPollTimeout     = 55 * time.Second
+
+	// longPollDeadlineMargin is left between a poll returning and the request deadline
+	longPollDeadlineMargin = 2 * time.Second
+
+	// eventStreamHeartbeat keeps proxies from closing an idle stream
+	eventStreamHeartbeat = 25 * time.Second
+
+	// maxEventStreamDuration ends streams now and then so the client reconnects with a fresh
+	// token check
+	maxEventStreamDuration = 10 * time.Minute
+
+	// maxFallbackThreads bounds the threads one poll may watch
+	maxFallbackThreads = 20
+)
+
+type pollerKey struct {
+	threadID string
+	userID   uuid.UUID
+}
+
+// markPolling records that the user is following the threads over a fallback
+func (h *Hub) markPolling(threadIDs []string, userID uuid.UUID) {
+	h.pollMu.Lock()
+	defer h.pollMu.Unlock()
+
+	now := time.Now()
+	for key, at := range h.pollers {
+		if now.Sub(at) > pollerActiveWindow {
+			delete(h.pollers, key)
+		}
+	}
+	for _, threadID := range threadIDs {
+		h.pollers[pollerKey{threadID: threadID, userID: userID}] = now
+	}
+}
+
+// isPolling reports whether the user polled the thread within pollerActiveWindow
+func (h *Hub) isPolling(threadID string, userID uuid.UUID) bool {
+	h.pollMu.Lock()
+	defer h.pollMu.Unlock()
+
+	at, ok := h.pollers[pollerKey{threadID: threadID, userID: userID}]
+	return ok && time.Since(at) <= pollerActiveWindow
+}
+
+// ThreadEventsCursor is the cursor a client starts from to receive only new events
+func (h *Hub) ThreadEventsCursor() uint64 {
+	return h.events.head()
+}
+
+// WaitForThreadEvents returns the user's events in the threads after cursor, waiting up to
+// timeout for one to arrive. It also returns the cursor to resume from, and whether events were
+// lost since cursor so the client must reload the threads before resuming.
+func (h *Hub) WaitForThreadEvents(ctx context.Context, userID uuid.UUID, threadIDs []string, cursor uint64, timeout time.Duration) ([]ThreadEvent, uint64, bool) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		events, next, reset, changed := h.events.since(userID, threadIDs, cursor)
+		if len(events) > 0 || reset || h.isClosing() {
+			return events, next, reset
+		}
+		select {
+		case <-changed:
+		case <-timer.C:
+			return nil, next, false
+		case <-ctx.Done():
+			return nil, next, false
+		}
+	}
+}
+
+// fallbackRequest authenticates a fallback client the way HandleWebSocket does and checks they
+// take part in every thread asked for
+func (h *WsHandler) fallbackRequest(c *fiber.Ctx) (*token.Payload, []string, error) {
+	tokenStr := c.Cookies("access_token")
+	if tokenStr == "" {
+		return nil, nil, fiber.NewError(fiber.StatusUnauthorized, "Authentication required - no access token cookie found")
+	}
+	payload, err := h.auth.VerifyToken(tokenStr)
+	if err == nil && !payload.IsStaff() {
+		err = errors.New("token was not issued to staff")
+	}
+	if err != nil {
+		config.Logger.Warn("Invalid access token for chat fallback", zap.Error(err))
+		return nil, nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired token")
+	}
+
+	var threadIDs []string
+	for _, raw := range strings.Split(c.Query("threads", c.Query("thread")), ",") {
+		if raw = strings.TrimSpace(raw); raw != "" {
+			threadIDs = append(threadIDs, raw)
+		}
+	}
+	if len(threadIDs) == 0 {
+		return nil, nil, fiber.NewError(fiber.StatusBadRequest, "threads parameter is required")
+	}
+	if len(threadIDs) > maxFallbackThreads {
+		return nil, nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("at most %d threads can be followed at once", maxFallbackThreads))
+	}
+
+	for _, threadID := range threadIDs {
+		threadUUID, err := uuid.Parse(threadID)
+		if err != nil {
+			return nil, nil, fiber.NewError(fiber.StatusBadRequest, "Invalid thread ID format")
+		}
+		if _, err := h.readReceiptService.ThreadState().GetState(threadUUID, payload.UserID); err != nil {
+			return nil, nil, fiber.NewError(fiber.StatusForbidden, "You are not a participant in this thread")
+		}
+	}
+	return payload, threadIDs, nil
+}
+
+// recordFallbackSeen marks the user as following the threads, as a WebSocket ping would
+func (h *WsHandler) recordFallbackSeen(userID uuid.UUID, threadIDs []string) {
+	h.hub.markPolling(threadIDs, userID)
+
+	now := time.Now()
+	for _, threadID := range threadIDs {
+		threadUUID, err := uuid.Parse(threadID)
+		if err != nil {
+			continue
+		}
+		if err := h.readReceiptService.ThreadState().RecordSeen(threadUUID, userID, now, false); err != nil {
+			config.Logger.Warn("Failed to record participant last seen",
+				zap.Error(err),
+				zap.String("threadID", threadID),
+				zap.String("userID", userID.String()))
+		}
+	}
+}
+
+// fallbackCursor reads the cursor a client resumes from. Without one, only events from now on
+// are delivered.
+func (h *WsHandler) fallbackCursor(raw string) (uint64, error) {
+	if raw == "" {
+		return h.hub.ThreadEventsCursor(), nil
+	}
+	return strconv.ParseUint(raw, 10, 64)
+}
+
+// HandleLongPoll returns the thread events after ?cursor= as soon as there are any, or an empty
+// list after ?timeout= seconds. Clients poll again straight away with the returned cursor; when
+// reset is true they reload the threads over REST first.
+//
+//	GET /ws/poll?threads=<id>,<id>&cursor=<seq>&timeout=25
+func (h *WsHandler) HandleLongPoll(c *fiber.Ctx) error {
+	payload, threadIDs, err := h.fallbackRequest(c)
+	if err != nil {
+		return fallbackError(c, err)
+	}
+
+	cursor, err := h.fallbackCursor(c.Query("cursor"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid cursor",
+			"error":   "invalid_cursor",
+		})
+	}
+
+	timeout := defaultLongPollTimeout
+	if seconds := c.QueryInt("timeout", 0); seconds > 0 {
+		timeout = time.Duration(seconds) * time.Second
+		if timeout > maxLongPollTimeout {
+			timeout = maxLongPollTimeout
+		}
+	}
+	// Return before the request deadline so the client still gets its cursor
+	if deadline, ok := c.UserContext().Deadline(); ok {
+		if remaining := time.Until(deadline) - longPollDeadlineMargin; remaining < timeout {
+			timeout = max(remaining, 0)
+		}
+	}
+
+	if h.hub.isClosing() {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"success": false,
+			"message": "Server is shutting down",
+		})
+	}
+
+	h.recordFallbackSeen(payload.UserID, threadIDs)
+
+	h.hub.connections.Add(1)
+	events, next, reset := h.hub.WaitForThreadEvents(c.UserContext(), payload.UserID, threadIDs, cursor, timeout)
+	h.hub.connections.Add(-1)
+
+	if events == nil {
+		events = []ThreadEvent{}
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"events": events,
+			"cursor": strconv.FormatUint(next, 10),
+			"reset":  reset,
+		},
+	})
+}
+
+// HandleEventStream streams thread events as server-sent events, each with its seq as the event
+// id so browsers resume from Last-Event-ID when they reconnect. A reset event tells the client to
+// reload the threads.
+//
+//	GET /ws/events?threads=<id>,<id>
+func (h *WsHandler) HandleEventStream(c *fiber.Ctx) error {
+	payload, threadIDs, err := h.fallbackRequest(c)
+	if err != nil {
+		return fallbackError(c, err)
+	}
+
+	cursor, err := h.fallbackCursor(c.Get("Last-Event-ID", c.Query("cursor")))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid cursor",
+			"error":   "invalid_cursor",
+		})
+	}
+
+	if h.hub.isClosing() {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"success": false,
+			"message": "Server is shutting down",
+		})
+	}
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Set("X-Accel-Buffering", "no") // Stop nginx buffering the stream
+
+	userID := payload.UserID
+	h.hub.connections.Add(1)
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer h.hub.connections.Add(-1)
+
+		ended := time.Now().Add(maxEventStreamDuration)
+		for time.Now().Before(ended) && !h.hub.isClosing() {
+			h.recordFallbackSeen(userID, threadIDs)
+
+			events, next, reset := h.hub.WaitForThreadEvents(context.Background(), userID, threadIDs, cursor, eventStreamHeartbeat)
+			if reset {
+				fmt.Fprintf(w, "id: %d\nevent: reset\ndata: {}\n\n", next)
+			}
+			for _, event := range events {
+				data, err := json.Marshal(event)
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Seq, event.Type, data)
+			}
+			if !reset && len(events) == 0 {
+				fmt.Fprint(w, ": heartbeat\n\n")
+			}
+			cursor = next
+
+			// A failed flush means the client went away
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+	return nil
+}
+
+// fallbackError answers a rejected fallback request in the shape the WebSocket handler uses
+func fallbackError(c *fiber.Ctx, err error) error {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return c.Status(fiberErr.Code).JSON(fiber.Map{"error": fiberErr.Message})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
@@ -2,6 +2,7 @@
 package websocket
 
 import (
+	"errors"
 	"fmt"
 	"time"
 	applications_services "town-planning-backend/applications/services"
@@ -53,6 +54,9 @@ func (h *WsHandler) HandleWebSocket(c *fiber.Ctx) error {
 
 	// Validate the token
 	payload, err := h.auth.VerifyToken(tokenStr)
+	if err == nil && !payload.IsStaff() {
+		err = errors.New("token was not issued to staff")
+	}
 	if err != nil {
 		config.Logger.Warn("Invalid access token for WebSocket",
 			zap.Error(err),
@@ -92,6 +96,9 @@ func (h *WsHandler) HandleWebSocket(c *fiber.Ctx) error {
 
 	// Upgrade to WebSocket using Fiber's websocket package
 	return websocket.New(func(conn *websocket.Conn) {
+		h.hub.connections.Add(1)
+		defer h.hub.connections.Add(-1)
+
 		client := &Client{
 			ID:                 uuid.New(),
 			UserID:             payload.UserID,
@@ -136,6 +143,7 @@ func (c *Client) readPump() {
 	c.Conn.SetReadDeadline(time.Now().Add(60 * time.Second))
 	c.Conn.SetPongHandler(func(string) error {
 		c.Conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+		c.recordSeen()
 		return nil
 	})
 
@@ -184,6 +192,31 @@ func (c *Client) readPump() {
 	}
 }
 
+// recordSeen keeps the user's last seen time current in the threads this connection has open.
+// Pings within LastSeenResolution of the stored time are not written.
+func (c *Client) recordSeen() {
+	c.mu.RLock()
+	threadIDs := make([]string, 0, len(c.Threads))
+	for threadID := range c.Threads {
+		threadIDs = append(threadIDs, threadID)
+	}
+	c.mu.RUnlock()
+
+	now := time.Now()
+	for _, threadID := range threadIDs {
+		threadUUID, err := uuid.Parse(threadID)
+		if err != nil {
+			continue
+		}
+		if err := c.readReceiptService.ThreadState().RecordSeen(threadUUID, c.UserID, now, false); err != nil {
+			config.Logger.Debug("Failed to record participant last seen",
+				zap.Error(err),
+				zap.String("threadID", threadID),
+				zap.String("userID", c.UserID.String()))
+		}
+	}
+}
+
 // writePump sends queued messages and keeps the connection alive
 func (c *Client) writePump() {
 	ticker := time.NewTicker(30 * time.Second)
@@ -198,7 +231,11 @@ func (c *Client) writePump() {
 			c.Conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
 			if !ok {
 				// Hub closed the channel
-				c.Conn.WriteMessage(websocket.CloseMessage, []byte{})
+				closeMessage := []byte{}
+				if c.Hub.isClosing() {
+					closeMessage = websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+				}
+				c.Conn.WriteMessage(websocket.CloseMessage, closeMessage)
 				return
 			}
 
@@ -241,11 +278,20 @@ func (c *Client) handleTypingIndicator(msg WebSocketMessage) {
 	}
 
 	// Validate thread ID format
-	if _, err := uuid.Parse(threadID); err != nil {
+	threadUUID, err := uuid.Parse(threadID)
+	if err != nil {
 		c.sendError("Invalid thread ID format")
 		return
 	}
 
+	// Persist the typing state so the HTTP endpoints report the same thing
+	state, err := c.readReceiptService.ThreadState().RecordTyping(threadUUID, c.UserID, isTyping)
+	if err != nil {
+		c.sendError("Failed to record typing: " + err.Error())
+		return
+	}
+	payload["lastTypingAt"] = state.LastTypingAt
+
 	// Fetch user details from database
 	user, err := c.readReceiptService.GetUserByID(c.UserID.String()) // You'll need to add this method or access UserRepo
 	if err != nil {
@@ -302,7 +348,7 @@ func (c *Client) handleReadReceipt(msg WebSocketMessage) {
 	}
 
 	// USE THE INJECTED SERVICE: Save read receipts to database
-	processedCount, err := c.readReceiptService.ProcessReadReceipts(
+	processedCount, state, err := c.readReceiptService.ProcessReadReceipts(
 		threadID,
 		c.UserID,
 		messageIDStrings,
@@ -337,6 +383,14 @@ func (c *Client) handleReadReceipt(msg WebSocketMessage) {
 	// Broadcast to other clients in the same thread
 	c.Hub.BroadcastToThread(threadID, msg, c.UserID)
 
+	// Sync the reader's other sessions with their new read position and unread count
+	c.Hub.SendToUser(c.UserID, WebSocketMessage{
+		Type:      MessageTypeThreadState,
+		Payload:   applications_services.NewThreadStateView(state),
+		Timestamp: time.Now(),
+		ThreadID:  threadID,
+	})
+
 	config.Logger.Debug("Read receipt handled and saved to database",
 		zap.String("threadId", threadID),
 		zap.Int("messageCount", len(messageIDStrings)),
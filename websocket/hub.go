@@ -2,23 +2,38 @@
 package websocket
 
 import (
+	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	applications_services "town-planning-backend/applications/services"
+	"town-planning-backend/config"
+
 	"github.com/gofiber/websocket/v2"
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 )
 
 type MessageType string
 
 const (
-	MessageTypeChat        MessageType = "CHAT_MESSAGE"
-	MessageTypeTyping      MessageType = "TYPING_INDICATOR"
-	MessageTypeReadReceipt MessageType = "READ_RECEIPT"
-	MessageTypeMessageRead MessageType = "MESSAGE_READ"
-	MessageTypeUserStatus  MessageType = "USER_STATUS"
-	MessageTypeError       MessageType = "ERROR"
+	MessageTypeChat         MessageType = "CHAT_MESSAGE"
+	MessageTypeTyping       MessageType = "TYPING_INDICATOR"
+	MessageTypeReadReceipt  MessageType = "READ_RECEIPT"
+	MessageTypeMessageRead  MessageType = "MESSAGE_READ"
+	MessageTypeUserStatus   MessageType = "USER_STATUS"
+	MessageTypeThreadState  MessageType = "THREAD_STATE"
+	MessageTypeInvitation   MessageType = "THREAD_INVITATION"
+	MessageTypePaymentAlert MessageType = "DUPLICATE_PAYMENT_ALERT"
+	MessageTypeLinkPreview  MessageType = "LINK_PREVIEW"
+	MessageTypeError        MessageType = "ERROR"
+)
+
+// Presence statuses broadcast as USER_STATUS when a participant opens or leaves a thread
+const (
+	PresenceOnline  = "online"
+	PresenceOffline = "offline"
 )
 
 type WebSocketMessage struct {
@@ -45,6 +60,15 @@ type Hub struct {
 	register   chan *Client
 	unregister chan *Client
 	mu         sync.RWMutex
+
+	closing     bool         // Set by Shutdown; new connections are turned away
+	connections atomic.Int32 // Open connection handlers, waited on by Shutdown
+
+	// Thread broadcasts kept for clients on the long-poll and server-sent event fallbacks, and
+	// when each of them last polled a thread
+	events  *eventLog
+	pollMu  sync.Mutex
+	pollers map[pollerKey]time.Time
 }
 
 func NewHub() *Hub {
@@ -53,6 +77,8 @@ func NewHub() *Hub {
 		broadcast:  make(chan WebSocketMessage),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
+		events:     newEventLog(),
+		pollers:    make(map[pollerKey]time.Time),
 	}
 }
 
@@ -61,8 +87,15 @@ func (h *Hub) Run() {
 		select {
 		case client := <-h.register:
 			h.mu.Lock()
-			h.clients[client] = true
+			var joined []string
+			if h.closing {
+				close(client.Send)
+			} else {
+				joined = h.threadsWithoutOtherSessions(client)
+				h.clients[client] = true
+			}
 			h.mu.Unlock()
+			h.presenceChanged(client, joined, PresenceOnline)
 
 		case client := <-h.unregister:
 			h.mu.Lock()
@@ -70,7 +103,10 @@ func (h *Hub) Run() {
 				delete(h.clients, client)
 				close(client.Send)
 			}
+			// Clients dropped for falling behind are already gone, but still went offline here
+			left := h.threadsWithoutOtherSessions(client)
 			h.mu.Unlock()
+			h.presenceChanged(client, left, PresenceOffline)
 
 		case message := <-h.broadcast:
 			h.broadcastToAll(message)
@@ -78,13 +114,90 @@ func (h *Hub) Run() {
 	}
 }
 
+// threadsWithoutOtherSessions lists the client's threads that no other session of the same
+// user has open. Callers hold h.mu.
+func (h *Hub) threadsWithoutOtherSessions(client *Client) []string {
+	client.mu.RLock()
+	defer client.mu.RUnlock()
+
+	var threads []string
+	for threadID := range client.Threads {
+		alone := true
+		for other := range h.clients {
+			if other != client && other.UserID == client.UserID && other.IsSubscribedToThread(threadID) {
+				alone = false
+				break
+			}
+		}
+		if alone {
+			threads = append(threads, threadID)
+		}
+	}
+	return threads
+}
+
+// presenceChanged records the user as last seen now in the threads they joined or left, and
+// tells the other participants so they can show "online" or "last seen". It does not block the
+// hub's loop.
+func (h *Hub) presenceChanged(client *Client, threadIDs []string, status string) {
+	if len(threadIDs) == 0 {
+		return
+	}
+
+	go func() {
+		now := time.Now()
+		for _, threadID := range threadIDs {
+			threadUUID, err := uuid.Parse(threadID)
+			if err != nil {
+				continue
+			}
+			if err := client.readReceiptService.ThreadState().RecordSeen(threadUUID, client.UserID, now, true); err != nil {
+				config.Logger.Warn("Failed to record participant last seen",
+					zap.Error(err),
+					zap.String("threadID", threadID),
+					zap.String("userID", client.UserID.String()))
+			}
+
+			h.BroadcastToThread(threadID, WebSocketMessage{
+				Type: MessageTypeUserStatus,
+				Payload: map[string]interface{}{
+					"userId":     client.UserID,
+					"threadId":   threadID,
+					"status":     status,
+					"lastSeenAt": now,
+				},
+				Timestamp: now,
+				ThreadID:  threadID,
+			}, client.UserID)
+		}
+	}()
+}
+
+// IsUserInThread reports whether the user has the thread open in any session, over a
+// WebSocket or one of the fallbacks
+func (h *Hub) IsUserInThread(threadID string, userID uuid.UUID) bool {
+	h.mu.RLock()
+	for client := range h.clients {
+		if client.UserID == userID && client.IsSubscribedToThread(threadID) {
+			h.mu.RUnlock()
+			return true
+		}
+	}
+	h.mu.RUnlock()
+
+	return h.isPolling(threadID, userID)
+}
+
 // Broadcast sends a message to all connected clients
 func (h *Hub) Broadcast(message WebSocketMessage) {
 	h.broadcast <- message
 }
 
-// BroadcastToThread sends a message to clients subscribed to a specific thread
+// BroadcastToThread sends a message to clients subscribed to a specific thread, and keeps it
+// for clients on the fallbacks to collect
 func (h *Hub) BroadcastToThread(threadID string, message WebSocketMessage, excludeUserID ...uuid.UUID) {
+	h.events.append(threadID, message, excludeUserID)
+
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
@@ -115,6 +228,24 @@ func (h *Hub) BroadcastToThread(threadID string, message WebSocketMessage, exclu
 	}
 }
 
+// SendToUser sends a message to every connected session of a user
+func (h *Hub) SendToUser(userID uuid.UUID, message WebSocketMessage) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for client := range h.clients {
+		if client.UserID != userID {
+			continue
+		}
+		select {
+		case client.Send <- message:
+		default:
+			close(client.Send)
+			delete(h.clients, client)
+		}
+	}
+}
+
 // broadcastToAll sends a message to all connected clients
 func (h *Hub) broadcastToAll(message WebSocketMessage) {
 	h.mu.RLock()
@@ -130,6 +261,40 @@ func (h *Hub) broadcastToAll(message WebSocketMessage) {
 	}
 }
 
+// Shutdown closes every connection with a going-away frame so clients reconnect to another
+// instance, then waits for their handlers to return or for ctx to end
+func (h *Hub) Shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	h.closing = true
+	for client := range h.clients {
+		close(client.Send)
+		delete(h.clients, client)
+	}
+	h.mu.Unlock()
+
+	// Release long polls and end event streams
+	h.events.wake()
+
+	// Polled rather than a WaitGroup, since a late upgrade may still open a connection
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for h.connections.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// isClosing reports whether the hub is shutting down
+func (h *Hub) isClosing() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.closing
+}
+
 // GetClientCount returns the number of connected clients
 func (h *Hub) GetClientCount() int {
 	h.mu.RLock()